@@ -0,0 +1,29 @@
+package client
+
+// Acoustic signal flags (AcousticSignals.AgentSignals / FrustrationSignals)
+const (
+	SignalLongHold          = "long_hold"           // Customer left on hold (silence) for a long stretch
+	SignalDeadAir           = "dead_air"            // Much of the call is silence
+	SignalAgentInterrupts   = "agent_interrupts"    // Agent often talks over the customer
+	SignalCustomerInterrupt = "customer_interrupts" // Customer often talks over the agent
+	SignalHeavyOvertalk     = "heavy_overtalk"      // Both sides speak at once for much of the call
+	SignalCallDropped       = "call_dropped"        // Audio ends mid-speech
+)
+
+// AcousticSignals are quality signals measured from a call's audio. Overtalk
+// and interruptions need a stereo recording (agent left, customer right) and
+// are zero for mono audio.
+type AcousticSignals struct {
+	DurationSeconds       float64  `json:"duration_seconds"`
+	Channels              int      `json:"channels"`
+	SilenceRatio          float64  `json:"silence_ratio"`  // Share of the call with nobody speaking
+	OvertalkRatio         float64  `json:"overtalk_ratio"` // Share of speech with both sides speaking
+	AgentInterruptions    int      `json:"agent_interruptions"`
+	CustomerInterruptions int      `json:"customer_interruptions"`
+	HoldCount             int      `json:"hold_count"` // Silences long enough to be a hold
+	HoldSeconds           float64  `json:"hold_seconds"`
+	LongestHoldSeconds    float64  `json:"longest_hold_seconds"`
+	EndedAbruptly         bool     `json:"ended_abruptly"`
+	AgentSignals          []string `json:"agent_signals,omitempty"`       // Flags bearing on agent performance
+	FrustrationSignals    []string `json:"frustration_signals,omitempty"` // Flags suggesting customer frustration
+}
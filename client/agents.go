@@ -0,0 +1,48 @@
+package client
+
+import "time"
+
+// Leaderboard periods
+const (
+	PeriodDay   = "day"
+	PeriodWeek  = "week"
+	PeriodMonth = "month"
+)
+
+// AgentLeaderboard ranks agents by composite QA score over a period, with
+// their movement since the period before (GET /agents/leaderboard)
+type AgentLeaderboard struct {
+	Period            string          `json:"period"` // day, week or month
+	From              string          `json:"from"`
+	To                string          `json:"to"`
+	PreviousFrom      string          `json:"previous_from"`
+	PreviousTo        string          `json:"previous_to"`
+	MinCalls          int             `json:"min_calls"` // Agents with fewer calls in a period aren't ranked in it
+	Agents            []AgentStanding `json:"agents"`    // Best first
+	Unranked          int             `json:"unranked"`  // Agents below min_calls this period
+	UnattributedCalls int             `json:"unattributed_calls"`
+	GeneratedAt       time.Time       `json:"generated_at"`
+}
+
+// AgentStanding is one agent's place on the leaderboard. Component scores
+// are 0-100 and nil when the period has nothing to score them on; the
+// composite score is the mean of the ones that aren't.
+type AgentStanding struct {
+	Rank              int      `json:"rank"`
+	AgentID           string   `json:"agent_id"`
+	Score             float64  `json:"score"`
+	Calls             int      `json:"calls"`
+	PerformanceScore  *float64 `json:"performance_score,omitempty"`  // Good 100, Average 50, Poor 0
+	SatisfactionScore *float64 `json:"satisfaction_score,omitempty"` // Average seller satisfaction, 1-10 scaled
+	EscalationScore   *float64 `json:"escalation_score,omitempty"`   // Share of calls not escalated
+	CommitmentScore   *float64 `json:"commitment_score,omitempty"`   // Share of resolved commitments kept
+	EscalationRate    float64  `json:"escalation_rate"`              // 0-1
+	CommitmentsKept   int      `json:"commitments_kept"`
+	CommitmentsBroken int      `json:"commitments_broken"`
+	UpsellPitches     int      `json:"upsell_pitches"` // Made on calls in the period, for coaching; not part of the score
+	PitchesConverted  int      `json:"pitches_converted"`
+	PitchConversion   *float64 `json:"pitch_conversion_rate,omitempty"` // Converted over settled (converted + lapsed), nil with none settled
+	PreviousRank      *int     `json:"previous_rank,omitempty"`         // nil when the agent wasn't ranked the period before
+	PreviousScore     *float64 `json:"previous_score,omitempty"`
+	Movement          int      `json:"movement"` // Places climbed since the previous period, negative for dropped
+}
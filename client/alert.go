@@ -0,0 +1,40 @@
+package client
+
+import (
+	"time"
+)
+
+// Alert is a single fired alert
+type Alert struct {
+	AlertID   string                 `json:"alert_id"`
+	Type      string                 `json:"type"`     // stale_issue, ...
+	Severity  string                 `json:"severity"` // low, medium, high, critical
+	GluserID  string                 `json:"gluser_id,omitempty"`
+	IssueID   string                 `json:"issue_id,omitempty"`
+	City      string                 `json:"city,omitempty"`     // The seller's, for scoped subscriptions
+	Vertical  string                 `json:"vertical,omitempty"` // The seller's, for scoped subscriptions
+	Message   string                 `json:"message"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+// NotificationDigest is a window's worth of notifications to one webhook,
+// posted in place of them when NOTIFY_DIGEST batches their kind
+type NotificationDigest struct {
+	Kind   string                   `json:"kind"`   // alerts, tickets, transitions
+	Window string                   `json:"window"` // hourly, daily
+	From   time.Time                `json:"from"`
+	To     time.Time                `json:"to"`
+	Events int                      `json:"events"` // Notifications batched, repeats included
+	Items  []NotificationDigestItem `json:"items"`  // One per distinct notification, in the order first seen
+}
+
+// NotificationDigestItem is one distinct notification in a digest, with
+// how often it repeated in the window
+type NotificationDigestItem struct {
+	Text    string    `json:"text"`
+	Count   int       `json:"count"`
+	FirstAt time.Time `json:"first_at"`
+	LastAt  time.Time `json:"last_at"`
+	Data    any       `json:"data"` // The latest alert, ticket or transition
+}
@@ -0,0 +1,75 @@
+package client
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// Delivery channels of alert subscriptions
+const (
+	AlertChannelWebhook = "webhook" // Posted as JSON to URL, Slack-compatible
+	AlertChannelEmail   = "email"   // Emailed to Emails
+)
+
+// AlertSeverities lists alert severities, lowest first
+var AlertSeverities = []string{"low", "medium", "high", "critical"}
+
+// AlertSubscription delivers the alerts in its scope to one subscriber,
+// typically a regional manager. Cities and Verticals scope it to alerts
+// about sellers there; an alert without a seller (a pipeline alert) only
+// reaches subscriptions without either.
+type AlertSubscription struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`                   // Who receives it
+	Cities      []string  `json:"cities,omitempty"`       // Sellers' cities, any if empty
+	Verticals   []string  `json:"verticals,omitempty"`    // Sellers' verticals, any if empty
+	Types       []string  `json:"types,omitempty"`        // Alert types, any if empty
+	MinSeverity string    `json:"min_severity,omitempty"` // Lowest severity received, any if empty
+	Channel     string    `json:"channel"`                // webhook, email
+	URL         string    `json:"url,omitempty"`          // For webhook
+	Secret      string    `json:"secret,omitempty"`       // Signs webhook deliveries; never returned by the API
+	HasSecret   bool      `json:"has_secret"`
+	Emails      []string  `json:"emails,omitempty"` // For email
+	By          string    `json:"by,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Scoped reports whether the subscription is limited to some cities or verticals
+func (s AlertSubscription) Scoped() bool {
+	return len(s.Cities) > 0 || len(s.Verticals) > 0
+}
+
+// Wants reports whether the subscription receives a
+func (s AlertSubscription) Wants(a Alert) bool {
+	if len(s.Types) > 0 && !slices.Contains(s.Types, a.Type) {
+		return false
+	}
+	if s.MinSeverity != "" && slices.Index(AlertSeverities, strings.ToLower(a.Severity)) < slices.Index(AlertSeverities, s.MinSeverity) {
+		return false
+	}
+	return inScope(s.Cities, a.City) && inScope(s.Verticals, a.Vertical)
+}
+
+// inScope reports whether v is one of scope, case-insensitively; anything is
+// in an empty scope
+func inScope(scope []string, v string) bool {
+	if len(scope) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(scope, func(s string) bool { return strings.EqualFold(s, v) })
+}
+
+// AlertSubscriptionRequest is the body of POST /admin/alert-subscriptions
+type AlertSubscriptionRequest struct {
+	Name        string   `json:"name"`
+	Cities      []string `json:"cities,omitempty"`
+	Verticals   []string `json:"verticals,omitempty"`
+	Types       []string `json:"types,omitempty"`
+	MinSeverity string   `json:"min_severity,omitempty"`
+	Channel     string   `json:"channel,omitempty"` // Defaults to webhook with a URL, email with Emails
+	URL         string   `json:"url,omitempty"`
+	Secret      string   `json:"secret,omitempty"`
+	Emails      []string `json:"emails,omitempty"`
+	By          string   `json:"by,omitempty"` // Defaults to the API key's name
+}
@@ -0,0 +1,49 @@
+package client
+
+import "time"
+
+// Annotation is a QA reviewer's comment on a call, anchored to a turn of
+// its English transcript or to a span of it
+type Annotation struct {
+	ID        string    `json:"id"`
+	CallID    string    `json:"call_id"`
+	GluserID  string    `json:"gluser_id"`
+	AgentID   string    `json:"agent_id,omitempty"` // From the raw transcript, when it names the agent
+	Turn      *int      `json:"turn,omitempty"`     // 0-based line of transcript_en
+	Start     *int      `json:"start,omitempty"`    // Character offsets into transcript_en, end exclusive
+	End       *int      `json:"end,omitempty"`
+	Quote     string    `json:"quote"`              // The anchored text, as it was when annotated
+	Comment   string    `json:"comment"`            // e.g. "agent violated refund policy here"
+	Category  string    `json:"category,omitempty"` // Free-form, e.g. refund_policy; rolled up per agent
+	Author    string    `json:"author,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AnnotationRequest is the body of POST /calls/{id}/annotations: a turn, or
+// start and end offsets, and the comment
+type AnnotationRequest struct {
+	Turn     *int   `json:"turn,omitempty"`
+	Start    *int   `json:"start,omitempty"`
+	End      *int   `json:"end,omitempty"`
+	Comment  string `json:"comment"`
+	Category string `json:"category,omitempty"`
+	Author   string `json:"author,omitempty"` // Defaults to the name of the API key, if one is sent
+}
+
+// AgentAnnotations counts the annotations on one agent's calls, for coaching
+type AgentAnnotations struct {
+	AgentID    string         `json:"agent_id"`
+	Total      int            `json:"total"`
+	Calls      int            `json:"calls"`       // Distinct calls annotated
+	ByCategory map[string]int `json:"by_category"` // "uncategorized" for annotations without one
+}
+
+// AnnotationsReport is the response of GET /annotations. The rollup covers
+// every annotation matching the filters, the list only the newest.
+type AnnotationsReport struct {
+	Annotations []Annotation       `json:"annotations"`
+	Count       int                `json:"count"`
+	Total       int                `json:"total"`
+	ByAgent     []AgentAnnotations `json:"by_agent"` // Most annotated first
+	GeneratedAt time.Time          `json:"generated_at"`
+}
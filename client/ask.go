@@ -0,0 +1,51 @@
+package client
+
+import "time"
+
+// AskRequest is a plain-language question about the call analytics (POST /ask)
+type AskRequest struct {
+	Question string `json:"question"`
+}
+
+// AskResponse answers a question with the queries it was translated into
+// and their results, so the numbers behind the answer can be checked
+type AskResponse struct {
+	Question    string        `json:"question"`
+	Answer      string        `json:"answer,omitempty"`
+	AnswerError string        `json:"answer_error,omitempty"` // Set when the results are in but phrasing the answer failed
+	Results     []QueryResult `json:"results"`
+	GeneratedAt time.Time     `json:"generated_at"`
+}
+
+// AnalyticsQuery is a structured query over the analyzed calls in a date
+// range: one metric, optionally grouped and filtered. Empty fields don't
+// filter.
+type AnalyticsQuery struct {
+	Metric       string `json:"metric"`             // calls, issues, sellers, avg_satisfaction, negative_sentiment_rate, high_churn_rate, upsell_rate
+	GroupBy      string `json:"group_by,omitempty"` // date, city, vertical, customer_type, bucket, severity, agent_performance, sentiment, churn_risk, seller; empty for one total
+	From         string `json:"from"`
+	To           string `json:"to"`
+	Bucket       string `json:"bucket,omitempty"`
+	Severity     string `json:"severity,omitempty"`
+	Sentiment    string `json:"sentiment,omitempty"`
+	ChurnRisk    string `json:"churn_risk,omitempty"`
+	City         string `json:"city,omitempty"`
+	Vertical     string `json:"vertical,omitempty"`
+	CustomerType string `json:"customer_type,omitempty"`
+	Order        string `json:"order,omitempty"` // desc (default) or asc by value
+	Limit        int    `json:"limit,omitempty"`
+}
+
+// QueryResult is an AnalyticsQuery's rows, ordered by value
+type QueryResult struct {
+	Query AnalyticsQuery `json:"query"`
+	Calls int            `json:"calls"` // Calls matching the filters
+	Rows  []QueryRow     `json:"rows"`
+}
+
+// QueryRow is one group's value
+type QueryRow struct {
+	Group string  `json:"group"` // "all" without a group_by
+	Value float64 `json:"value"`
+	Calls int     `json:"calls"`
+}
@@ -0,0 +1,56 @@
+package client
+
+import "time"
+
+// Attention queue states
+const (
+	AttentionOpen         = "open"         // Flagged and not yet handled
+	AttentionAcknowledged = "acknowledged" // Handled until the flag changes
+	AttentionSnoozed      = "snoozed"      // Set aside until SnoozedUntil or the flag changes
+)
+
+// AttentionAck is an operator's acknowledgment or snooze of a seller's
+// attention flag. It only covers the Reason it was given for; once the
+// seller's attention reason changes or the flag clears, it no longer applies.
+type AttentionAck struct {
+	GluserID     string     `json:"gluser_id"`
+	State        string     `json:"state"`  // AttentionAcknowledged or AttentionSnoozed
+	Reason       string     `json:"reason"` // Attention reason at the time
+	Note         string     `json:"note,omitempty"`
+	By           string     `json:"by,omitempty"`
+	SnoozedUntil *time.Time `json:"snoozed_until,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// AttentionItem is a flagged seller in the attention queue (GET /attention)
+type AttentionItem struct {
+	GluserID     string        `json:"gluser_id"`
+	CustomerType string        `json:"customer_type"`
+	CityName     string        `json:"city_name"`
+	Reason       string        `json:"reason"`
+	Urgency      int           `json:"urgency"` // Higher is more urgent, the queue order
+	HealthScore  int           `json:"health_score"`
+	HealthLabel  string        `json:"health_label"`
+	ChurnRisk    string        `json:"churn_risk"`
+	OpenIssues   int           `json:"open_issues"`
+	LastCallAt   time.Time     `json:"last_call_at"`
+	State        string        `json:"state"`         // AttentionOpen, AttentionAcknowledged or AttentionSnoozed
+	Ack          *AttentionAck `json:"ack,omitempty"` // The acknowledgment or snooze in effect
+}
+
+// AttentionQueue is the response for GET /attention
+type AttentionQueue struct {
+	Items        []AttentionItem `json:"items"`
+	Open         int             `json:"open"`
+	Acknowledged int             `json:"acknowledged"`
+	Snoozed      int             `json:"snoozed"`
+	GeneratedAt  time.Time       `json:"generated_at"`
+}
+
+// AttentionAckRequest acknowledges or snoozes a seller's attention flag
+// (POST /attention/{gluser_id}/acknowledge or /snooze)
+type AttentionAckRequest struct {
+	Note  string     `json:"note,omitempty"`
+	By    string     `json:"by,omitempty"`    // Defaults to the API key name, if one is sent
+	Until *time.Time `json:"until,omitempty"` // Snooze only, default 24h from now
+}
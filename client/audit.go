@@ -0,0 +1,54 @@
+package client
+
+import "time"
+
+// Audit actions
+const (
+	AuditTranscriptRead    = "transcript.read"
+	AuditPIIRehydrate      = "pii.rehydrate"   // Transcript served with the PII vault's values in place of tokens
+	AuditRecordingLink     = "recording.link"  // Signed recording URL issued
+	AuditRecordingRead     = "recording.read"  // Audio served through a signed URL
+	AuditRecordingFetch    = "recording.fetch" // Audio downloaded on request
+	AuditLLMRawRead        = "llm_raw.read"    // Raw Gemini responses of a call
+	AuditSellerExport      = "seller.export"   // Profile, analyses and transcripts of a seller
+	AuditSellersExport     = "sellers.export"  // Every seller's export at once (GET /export)
+	AuditSellerImport      = "seller.import"
+	AuditSellerUpdate      = "seller.update"         // Manual profile correction, the change as the reason
+	AuditSellerData        = "seller.data_inventory" // Everything stored about a seller, for access requests
+	AuditAnalysesImport    = "analyses.import"       // Analyses from before adopting the service
+	AuditSellerSummary     = "seller.summary"        // Seller-facing case summary, for the seller portal
+	AuditTicketUpdate      = "ticket.update"         // Status set by a bulk update, the change and note as the reason
+	AuditTicketsBulkUpdate = "tickets.bulk_update"   // A bulk update as a whole, only audited when refused
+	AuditSampleCreate      = "sample.create"         // Anonymized sample generated from a call
+	AuditSampleReview      = "sample.review"         // Sample approved, rejected or deleted, the decision as the reason
+	AuditSamplesUnapproved = "samples.unapproved"    // Pending or rejected samples listed, with their source calls
+	AuditReclassify        = "taxonomy.reclassify"   // Stored data migrated to a changed bucket taxonomy, the run ID as the reason
+	AuditCallChat          = "call.chat"             // Question about a call answered from its transcript, or the conversation read
+	AuditChurnOutcomes     = "churn.outcomes"        // Sellers' renewals and cancellations recorded, how many as the reason
+	AuditKeyUsageRead      = "key_usage.read"        // An API key's usage and quota
+	AuditWebhooksAdmin     = "webhooks.admin"        // Webhook subscribed or removed, the change as the reason
+	AuditFlagsAdmin        = "flags.admin"           // Feature flag set or returned to its default, the setting as the reason
+	AuditRolloutsAdmin     = "rollouts.admin"        // Canary rollout started or changed, the change as the reason
+	AuditJobRun            = "job.run"               // Background job run by hand
+	AuditAlertSubsAdmin    = "alerts.admin"          // Alert subscription added or removed, the change as the reason
+	AuditOwnersAdmin       = "owners.admin"          // Bucket owner set or removed, the change as the reason
+	AuditKBAdmin           = "kb.admin"              // Knowledge base document uploaded or removed, the change as the reason
+)
+
+// Audit outcomes
+const (
+	AuditAllowed = "allowed"
+	AuditDenied  = "denied"
+)
+
+// AuditEntry records an access to sensitive data
+type AuditEntry struct {
+	AuditID    string    `json:"audit_id"`
+	Timestamp  time.Time `json:"timestamp"`
+	Actor      string    `json:"actor"` // API key name, "anonymous" without a valid key
+	Action     string    `json:"action"`
+	Resource   string    `json:"resource"` // e.g. the call ID
+	Outcome    string    `json:"outcome"`
+	Reason     string    `json:"reason,omitempty"`
+	RemoteAddr string    `json:"remote_addr,omitempty"`
+}
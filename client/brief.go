@@ -0,0 +1,31 @@
+package client
+
+import "time"
+
+// SellerBrief is a one-page prep brief for an outbound retention call
+// (GET /sellers/{gluser_id}/brief). The facts are the profile's; who,
+// talking_points and avoid are written by the LLM from them.
+type SellerBrief struct {
+	GluserID      string `json:"gluser_id"`
+	CustomerType  string `json:"customer_type"`
+	CityName      string `json:"city_name"`
+	Vertical      string `json:"vertical"`
+	VintageMonths int    `json:"vintage_months"`
+	JourneyStage  string `json:"journey_stage,omitempty"`
+	HealthScore   int    `json:"health_score"`
+	HealthLabel   string `json:"health_label"`
+	ChurnRisk     string `json:"churn_risk"`
+	Sentiment     string `json:"sentiment"`
+	TotalCalls    int    `json:"total_calls"`
+
+	LastCall   *CallSummary   `json:"last_call,omitempty"`
+	OpenIssues []TrackedIssue `json:"open_issues"` // The seller's grievances still open
+	Promises   []Commitment   `json:"promises"`    // Made on the last call, and earlier ones still open or broken; newest first
+
+	Who           string   `json:"who,omitempty"`         // Who the seller is, in a few sentences
+	TalkingPoints []string `json:"talking_points"`        // What to raise, in order
+	Avoid         []string `json:"avoid"`                 // What not to say
+	BriefError    string   `json:"brief_error,omitempty"` // Set when the LLM brief failed; the facts are still there
+
+	GeneratedAt time.Time `json:"generated_at"`
+}
@@ -0,0 +1,53 @@
+package client
+
+import "time"
+
+// TicketBurndown measures the auto-ticketing loop over a date range: how
+// many tickets were open and resolved day by day, how long each bucket's
+// tickets took to resolve, and which tickets have been open longest
+// (GET /analytics/tickets/burndown)
+type TicketBurndown struct {
+	From       string                   `json:"from"`
+	To         string                   `json:"to"`
+	Days       []TicketBurndownDay      `json:"days"`
+	Buckets    []TicketBucketResolution `json:"buckets"`     // Buckets of the tickets created in the range, most tickets first
+	OldestOpen []OpenTicketAge          `json:"oldest_open"` // Tickets still open at the end of the range, oldest first
+
+	// Resolved tickets without a resolution time, resolved before it was
+	// recorded: counted in their bucket's resolved tickets, left out of the
+	// days and the median resolution times
+	UntimedResolved int `json:"untimed_resolved,omitempty"`
+
+	GeneratedAt time.Time `json:"generated_at"`
+}
+
+// TicketBurndownDay is the state of the tickets at the end of one day
+type TicketBurndownDay struct {
+	Date          string `json:"date"`
+	Created       int    `json:"created"`        // Tickets created that day
+	Resolved      int    `json:"resolved"`       // Tickets resolved that day
+	Open          int    `json:"open"`           // Tickets open or in progress at the end of the day
+	ResolvedTotal int    `json:"resolved_total"` // Tickets resolved by the end of the day
+}
+
+// TicketBucketResolution is how the tickets of one bucket created in the range fared
+type TicketBucketResolution struct {
+	FeatureBucket         string  `json:"feature_bucket"`
+	Tickets               int     `json:"tickets"`
+	Resolved              int     `json:"resolved"`
+	Open                  int     `json:"open"`                              // Open or in progress
+	ResolutionRate        float64 `json:"resolution_rate"`                   // Resolved out of tickets (0-1)
+	MedianResolutionHours float64 `json:"median_resolution_hours,omitempty"` // From creation to resolution, of those with a resolution time
+}
+
+// OpenTicketAge is a ticket still open, with how long it's been open
+type OpenTicketAge struct {
+	TicketID      string    `json:"ticket_id"`
+	Date          string    `json:"date"`
+	FeatureBucket string    `json:"feature_bucket"`
+	Severity      string    `json:"severity"`
+	Status        string    `json:"status"` // open, in_progress
+	IssueURL      string    `json:"issue_url,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	AgeDays       float64   `json:"age_days"` // At the end of the range, or now if earlier
+}
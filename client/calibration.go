@@ -0,0 +1,47 @@
+package client
+
+import "time"
+
+// Severity calibration verdicts
+const (
+	CalibrationOver         = "over"         // Rated more severe than the outcomes bear out
+	CalibrationUnder        = "under"        // Turned out worse than rated
+	CalibrationCalibrated   = "calibrated"   // Ratings match the outcomes
+	CalibrationInsufficient = "insufficient" // Too few issues to tell
+)
+
+// SeverityOutcome is what became of the issues of one bucket the LLM gave
+// one severity
+type SeverityOutcome struct {
+	Issues           int     `json:"issues"`
+	ResolvedRate     float64 `json:"resolved_rate"`
+	AvgDaysToResolve float64 `json:"avg_days_to_resolve"` // Of the resolved ones
+	RepeatRate       float64 `json:"repeat_rate"`         // Reopened or raised on another call
+	ChurnRate        float64 `json:"churn_rate"`          // Sellers now at high churn risk
+}
+
+// BucketCalibration compares the severities of a bucket's issues with
+// their outcomes. Scores are averages on a 1 (low) to 4 (critical) scale.
+type BucketCalibration struct {
+	Bucket        string                     `json:"bucket"`
+	Issues        int                        `json:"issues"`
+	AssignedScore float64                    `json:"assigned_score"` // The LLM's severities
+	OutcomeScore  float64                    `json:"outcome_score"`  // The severities the outcomes suggest
+	Skew          float64                    `json:"skew"`           // Assigned minus outcome: positive is over-severity
+	Calibration   string                     `json:"calibration"`    // over, under, calibrated or insufficient
+	Adjustment    int                        `json:"adjustment"`     // Suggested shift in severity levels: -1, 0 or +1
+	Hint          string                     `json:"hint,omitempty"` // Guidance for the extraction prompt
+	BySeverity    map[string]SeverityOutcome `json:"by_severity"`
+}
+
+// SeverityCalibration is a run of the severity calibration job over the
+// issues first reported From to To (GET /analytics/severity-calibration)
+type SeverityCalibration struct {
+	Date        string              `json:"date"` // Run date
+	From        string              `json:"from"`
+	To          string              `json:"to"`
+	Issues      int                 `json:"issues"`
+	Buckets     []BucketCalibration `json:"buckets"` // Largest skew first
+	PromptHints []string            `json:"prompt_hints,omitempty"`
+	GeneratedAt time.Time           `json:"generated_at"`
+}
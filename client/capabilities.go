@@ -0,0 +1,50 @@
+package client
+
+import "time"
+
+// Capabilities describes what this deployment has turned on, for the
+// dashboard and integrators to adapt to (GET /capabilities)
+type Capabilities struct {
+	Storage               string               `json:"storage"` // mongodb or local
+	MongoDB               bool                 `json:"mongodb"`
+	Model                 string               `json:"model"`                   // Gemini model analyzing calls
+	PromptVersion         string               `json:"prompt_version"`          // Fingerprint of the analysis prompts, as on recorded interactions
+	BucketTaxonomyVersion string               `json:"bucket_taxonomy_version"` // Fingerprint of the feature buckets and the verticals' own
+	FeatureBuckets        []string             `json:"feature_buckets"`
+	SpeechToText          bool                 `json:"speech_to_text"` // Always false: calls arrive transcribed
+	Embeddings            CapabilityEmbeddings `json:"embeddings"`
+	Webhooks              CapabilityWebhooks   `json:"webhooks"`
+	Email                 bool                 `json:"email"`            // SMTP is configured
+	GitHubSync            bool                 `json:"github_sync"`      // Tickets are projected onto GitHub issues
+	PIIVault              bool                 `json:"pii_vault"`        // Transcripts are tokenized (PII_VAULT_KEY)
+	RecordingFetch        bool                 `json:"recording_fetch"`  // Audio is downloaded as calls are processed
+	AcousticSignals       bool                 `json:"acoustic_signals"` // Built in and turned on
+	ShadowAnalysis        bool                 `json:"shadow_analysis"`  // SHADOW_PERCENT is set
+	LLMRecording          bool                 `json:"llm_recording"`    // Analysis requests are kept (LLM_RECORD)
+	Providers             []CapabilityProvider `json:"providers"`
+	Flags                 []FeatureFlag        `json:"flags"`
+	GeneratedAt           time.Time            `json:"generated_at"`
+}
+
+// CapabilityEmbeddings describes the Gemini embeddings behind the knowledge
+// base, systemic issues and insight themes
+type CapabilityEmbeddings struct {
+	Model             string `json:"model"`
+	KnowledgePassages int    `json:"knowledge_passages"` // Passages loaded for grounding, 0 without a knowledge base
+}
+
+// CapabilityWebhooks describes where notifications are posted
+type CapabilityWebhooks struct {
+	Alerts        bool              `json:"alerts"`            // ALERT_WEBHOOK_URL is set
+	Tickets       bool              `json:"tickets"`           // TICKET_WEBHOOK_URL is set; bucket owners can have their own
+	Subscriptions int               `json:"subscriptions"`     // Profile transition subscriptions
+	Digests       map[string]string `json:"digests,omitempty"` // Window per batched kind (NOTIFY_DIGEST)
+}
+
+// CapabilityProvider is the external service, or local fallback, doing one job
+type CapabilityProvider struct {
+	Purpose    string `json:"purpose"`        // analysis, embeddings, storage, archive, recordings, email, issues
+	Name       string `json:"name,omitempty"` // gemini, mongodb, s3, local, smtp, github
+	Configured bool   `json:"configured"`
+	Detail     string `json:"detail,omitempty"` // e.g. the model
+}
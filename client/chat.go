@@ -0,0 +1,36 @@
+package client
+
+import "time"
+
+// Who wrote a call chat message (ChatMessage.Role)
+const (
+	ChatRoleReviewer  = "reviewer"
+	ChatRoleAssistant = "assistant" // Gemini's answer
+)
+
+// CallChatRequest is the body of POST /calls/{id}/chat
+type CallChatRequest struct {
+	SessionID string `json:"session_id,omitempty"` // The conversation to continue, a new one when empty
+	Message   string `json:"message"`
+}
+
+// CallChat is a reviewer's conversation with Gemini about one call,
+// answered from its transcript and analysis
+type CallChat struct {
+	SessionID         string        `json:"session_id"`
+	CallID            string        `json:"call_id"`
+	GluserID          string        `json:"gluser_id,omitempty"`
+	Reviewer          string        `json:"reviewer"`                     // The API key that opened it, the only one that can continue or read it
+	Messages          []ChatMessage `json:"messages"`                     // Oldest first, so the latest answer is last
+	TranscriptTrimmed bool          `json:"transcript_trimmed,omitempty"` // The transcript was cut to fit the prompt, so answers may miss the middle of the call
+	CreatedAt         time.Time     `json:"created_at"`
+	UpdatedAt         time.Time     `json:"updated_at"`
+	ExpiresAt         time.Time     `json:"expires_at"`
+}
+
+// ChatMessage is a question or answer in a call chat
+type ChatMessage struct {
+	Role string    `json:"role"` // reviewer, assistant
+	Text string    `json:"text"`
+	At   time.Time `json:"at"`
+}
@@ -0,0 +1,32 @@
+package client
+
+import "time"
+
+// ChurnReasonReport counts why at-risk sellers might leave over a date range
+// (GET /analytics/churn-reasons). Only calls with medium or high churn risk
+// are counted.
+type ChurnReasonReport struct {
+	From        string             `json:"from"`
+	To          string             `json:"to"`
+	AtRiskCalls int                `json:"at_risk_calls"`
+	Unspecified int                `json:"unspecified"` // At-risk calls without a churn reason
+	Categories  []ChurnReasonCount `json:"categories"`  // Every category, most common first
+	Days        []ChurnReasonDay   `json:"days"`
+	GeneratedAt time.Time          `json:"generated_at"`
+}
+
+// ChurnReasonCount is one taxonomy category over the range
+type ChurnReasonCount struct {
+	Category string   `json:"category"`
+	Count    int      `json:"count"`
+	HighRisk int      `json:"high_risk"` // Of Count, calls with high churn risk
+	Share    float64  `json:"share"`     // Of at-risk calls with a reason (0-1)
+	Examples []string `json:"examples,omitempty"`
+}
+
+// ChurnReasonDay is one day's at-risk calls by category
+type ChurnReasonDay struct {
+	Date        string         `json:"date"`
+	AtRiskCalls int            `json:"at_risk_calls"`
+	Counts      map[string]int `json:"counts"`
+}
@@ -0,0 +1,121 @@
+package client
+
+import "time"
+
+// What became of a seller's subscription (ChurnOutcome.Outcome)
+const (
+	OutcomeRenewed   = "renewed"
+	OutcomeCancelled = "cancelled" // Cancelled, or let lapse without renewing
+)
+
+// ChurnOutcome is a seller's actual renewal or cancellation, as reported by
+// the billing system (POST /churn-outcomes). A seller has at most one per date.
+type ChurnOutcome struct {
+	GluserID   string    `json:"gluser_id"`
+	Outcome    string    `json:"outcome"` // renewed, cancelled
+	Date       string    `json:"date"`    // YYYY-MM-DD the subscription renewed or ended
+	Source     string    `json:"source,omitempty"`
+	RecordedBy string    `json:"recorded_by,omitempty"` // The API key that sent it
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+// ChurnOutcomeItemResult is how one outcome of a POST /churn-outcomes went
+type ChurnOutcomeItemResult struct {
+	Line     int    `json:"line,omitempty"` // CSV line, for CSV uploads
+	GluserID string `json:"gluser_id"`
+	Date     string `json:"date,omitempty"`
+	OK       bool   `json:"ok"`
+	Replaced bool   `json:"replaced,omitempty"` // The seller already had an outcome on the date
+	Code     string `json:"code,omitempty"`     // Error code, as in error responses
+	Error    string `json:"error,omitempty"`
+}
+
+// ChurnOutcomeResult is the response of POST /churn-outcomes, with a result
+// per outcome in request order
+type ChurnOutcomeResult struct {
+	Recorded int                      `json:"recorded"`
+	Failed   int                      `json:"failed"`
+	Results  []ChurnOutcomeItemResult `json:"results"`
+}
+
+// Churn accuracy verdicts (ChurnVersionAccuracy.Verdict)
+const (
+	ChurnAccuracyUseful       = "useful"       // Sellers it puts at risk cancel at least CHURN_ACCURACY_MIN_LIFT times as often as all sellers
+	ChurnAccuracyWeak         = "weak"         // Better than chance, but not by that much
+	ChurnAccuracyNoBetter     = "no_better"    // Sellers it puts at risk cancel no more often than the rest
+	ChurnAccuracyInsufficient = "insufficient" // Too few outcomes, or no cancellations, to tell
+)
+
+// ChurnVersionUnrecorded is the version of Gemini analyses made before
+// analyses recorded their model and prompt version
+const ChurnVersionUnrecorded = "unrecorded"
+
+// ChurnAccuracyReport checks churn predictions against the renewals and
+// cancellations that followed them (GET /analytics/churn-accuracy). Each
+// outcome From to To is matched with the seller's latest prediction in the
+// WindowDays before it.
+type ChurnAccuracyReport struct {
+	From        string                 `json:"from"`
+	To          string                 `json:"to"`
+	WindowDays  int                    `json:"window_days"`
+	Outcomes    int                    `json:"outcomes"`
+	Matched     int                    `json:"matched"`
+	Unmatched   int                    `json:"unmatched"` // Outcomes without a prediction in the window
+	Versions    []ChurnVersionAccuracy `json:"versions"`  // Most matched outcomes first
+	GeneratedAt time.Time              `json:"generated_at"`
+}
+
+// ChurnVersionAccuracy is how one prediction version's churn risks held up.
+// A version is the model and prompt version of Gemini analyses (e.g.
+// "gemini-2.0-flash@3f9a2c1b0d4e"), "rules" for the keyword classifier or
+// "unrecorded" for analyses from before versions were recorded.
+type ChurnVersionAccuracy struct {
+	Version           string                `json:"version"`
+	Model             string                `json:"model,omitempty"`
+	PromptVersion     string                `json:"prompt_version,omitempty"`
+	Rollouts          []string              `json:"rollouts,omitempty"` // Canary rollouts whose candidate made some of the predictions
+	Outcomes          int                   `json:"outcomes"`
+	Cancelled         int                   `json:"cancelled"`
+	Renewed           int                   `json:"renewed"`
+	CancelRate        float64               `json:"cancel_rate"`         // Of all the outcomes, the base rate predictions are compared to
+	Thresholds        []ChurnThresholdScore `json:"thresholds"`          // Predicting churn at high risk, and at medium or high
+	ByRisk            []ChurnRiskOutcome    `json:"by_risk"`             // low, medium, high
+	Calibration       []ChurnCalibrationBin `json:"calibration"`         // Predicted renewal probability against the actual renewal rate
+	BrierScore        float64               `json:"brier_score"`         // Mean squared error of renewal_probability, 0 is perfect and 0.25 is a coin flip
+	Lift              float64               `json:"lift"`                // Cancel rate of sellers predicted at medium or high risk over the base rate
+	Verdict           string                `json:"verdict"`             // useful, weak, no_better or insufficient
+	FirstPredictionAt time.Time             `json:"first_prediction_at"` // Of the matched predictions
+	LastPredictionAt  time.Time             `json:"last_prediction_at"`
+}
+
+// ChurnThresholdScore is precision and recall of cancellations when the
+// sellers predicted at MinRisk or above are taken to churn
+type ChurnThresholdScore struct {
+	MinRisk        string  `json:"min_risk"` // high, medium
+	TruePositives  int     `json:"true_positives"`
+	FalsePositives int     `json:"false_positives"`
+	FalseNegatives int     `json:"false_negatives"`
+	TrueNegatives  int     `json:"true_negatives"`
+	Precision      float64 `json:"precision"` // Of sellers predicted to churn, the share that cancelled; 0 when none were
+	Recall         float64 `json:"recall"`    // Of sellers that cancelled, the share predicted to churn
+	F1             float64 `json:"f1"`
+}
+
+// ChurnRiskOutcome is what became of the sellers predicted at one churn risk
+type ChurnRiskOutcome struct {
+	Risk       string  `json:"risk"`
+	Outcomes   int     `json:"outcomes"`
+	Cancelled  int     `json:"cancelled"`
+	CancelRate float64 `json:"cancel_rate"`
+}
+
+// ChurnCalibrationBin is one band of the predicted renewal probability, a
+// point on the calibration curve. A calibrated model's bins have a renewal
+// rate close to their mean prediction.
+type ChurnCalibrationBin struct {
+	Low           float64 `json:"low"`
+	High          float64 `json:"high"` // Exclusive, except for the last bin
+	Outcomes      int     `json:"outcomes"`
+	MeanPredicted float64 `json:"mean_predicted"`
+	RenewalRate   float64 `json:"renewal_rate"`
+}
@@ -0,0 +1,2025 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ==================== HTTP CLIENT ====================
+
+// Client is a typed client for the Voice AI HTTP API
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	header     http.Header
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithHTTPClient sets the underlying HTTP client (default: 30s timeout)
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.httpClient = hc }
+}
+
+// WithHeader adds a header to every request (e.g. an API key)
+func WithHeader(key, value string) Option {
+	return func(c *Client) { c.header.Set(key, value) }
+}
+
+// New returns a client for the server at baseURL (e.g. "http://voice-ai:8080")
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		header:     make(http.Header),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+type idempotencyKeyCtx struct{}
+
+// WithIdempotencyKey returns a context whose requests carry key as their
+// Idempotency-Key, so the server answers a retry of /ingest, /analyze/trigger
+// or /aggregate with the first response instead of running it again. Use a
+// new key per logical request, the same one for its retries.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyCtx{}, key)
+}
+
+type journeyStageCtx struct{}
+
+// WithJourneyStage returns a context whose /analytics requests only cover
+// sellers in a journey stage (StageOnboarding, ...), passed as their stage
+// query parameter
+func WithJourneyStage(ctx context.Context, stage string) context.Context {
+	return context.WithValue(ctx, journeyStageCtx{}, stage)
+}
+
+// Error codes of the API's problem+json error responses
+const (
+	ErrorValidationFailed    = "validation_failed"    // 400, 422: the request is malformed or invalid
+	ErrorUnauthorized        = "unauthorized"         // 401: missing or unknown API key
+	ErrorForbidden           = "forbidden"            // 403: the API key lacks the scope
+	ErrorNotFound            = "not_found"            // 404
+	ErrorMethodNotAllowed    = "method_not_allowed"   // 405
+	ErrorConflict            = "conflict"             // 409
+	ErrorGone                = "gone"                 // 410: deleted, e.g. audio past retention
+	ErrorQuotaExceeded       = "quota_exceeded"       // 429: Gemini's quota or rate limit is spent, retry later
+	ErrorInternal            = "internal_error"       // 500
+	ErrorLLMUnavailable      = "llm_unavailable"      // 502: Gemini failed or couldn't be reached
+	ErrorUpstreamUnavailable = "upstream_unavailable" // 502: another dependency failed (recording host, GitHub)
+	ErrorStorageUnavailable  = "storage_unavailable"  // 503: MongoDB or the audit log can't be reached, retry later
+	ErrorTimeout             = "timeout"              // 504: the request outlived its deadline
+)
+
+// APIError is returned for non-2xx responses
+type APIError struct {
+	StatusCode int
+	Code       string // One of the Error* codes, empty from servers that predate them
+	Message    string
+	RequestID  string // The server's X-Request-ID, to find the request in its logs
+}
+
+func (e *APIError) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("voice api: %d: %s", e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("voice api: %d %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+// Ingest submits a transcript (POST /ingest)
+func (c *Client) Ingest(ctx context.Context, in IngestRequest) (*IngestResponse, error) {
+	var out IngestResponse
+	if err := c.do(ctx, http.MethodPost, "/ingest", nil, in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetIngestJob returns an async ingestion's job, with the analysis once
+// it's completed (GET /ingest/jobs/{id})
+func (c *Client) GetIngestJob(ctx context.Context, jobID string) (*IngestJob, error) {
+	var out IngestJob
+	if err := c.do(ctx, http.MethodGet, "/ingest/jobs/"+url.PathEscape(jobID), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// PreviewAnalysis returns the analysis ingesting a transcript would produce,
+// without storing anything (POST /analyze with structured set)
+func (c *Client) PreviewAnalysis(ctx context.Context, in AnalyzeRequest) (*AnalyzeResponse, error) {
+	in.Structured = true
+	var out AnalyzeResponse
+	if err := c.do(ctx, http.MethodPost, "/analyze", nil, in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetProvisionalQueue lists the calls analyzed provisionally while Gemini
+// was unavailable, oldest first (GET /analyze/provisional)
+func (c *Client) GetProvisionalQueue(ctx context.Context) (*ProvisionalQueue, error) {
+	var out ProvisionalQueue
+	if err := c.do(ctx, http.MethodGet, "/analyze/provisional", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpgradeProvisional replaces provisional analyses with full ones without
+// waiting for the scheduled upgrade (POST /analyze/provisional/upgrade)
+func (c *Client) UpgradeProvisional(ctx context.Context) (*ProvisionalUpgrade, error) {
+	var out ProvisionalUpgrade
+	if err := c.do(ctx, http.MethodPost, "/analyze/provisional/upgrade", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetSeller fetches a seller profile (GET /sellers/{gluser_id})
+func (c *Client) GetSeller(ctx context.Context, gluserID string) (*SellerProfile, error) {
+	var out SellerProfile
+	if err := c.do(ctx, http.MethodGet, "/sellers/"+url.PathEscape(gluserID), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// PatchSellerProfile corrects parts of a seller profile, needing an API key
+// with the profiles scope (PATCH /sellers/{gluser_id})
+func (c *Client) PatchSellerProfile(ctx context.Context, gluserID string, patch SellerProfilePatch) (*SellerProfile, error) {
+	var out SellerProfile
+	if err := c.do(ctx, http.MethodPatch, "/sellers/"+url.PathEscape(gluserID), nil, patch, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DiffSellerCalls compares two of a seller's calls (GET /sellers/{gluser_id}/diff).
+// With narrative set, the server also asks the LLM to describe the change.
+func (c *Client) DiffSellerCalls(ctx context.Context, gluserID, callA, callB string, narrative bool) (*CallDiff, error) {
+	q := url.Values{}
+	q.Set("call_a", callA)
+	q.Set("call_b", callB)
+	if !narrative {
+		q.Set("narrative", "false")
+	}
+	var out CallDiff
+	if err := c.do(ctx, http.MethodGet, "/sellers/"+url.PathEscape(gluserID)+"/diff", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetSellerBrief gets the prep brief for an outbound retention call to a
+// seller (GET /sellers/{gluser_id}/brief)
+func (c *Client) GetSellerBrief(ctx context.Context, gluserID string) (*SellerBrief, error) {
+	var out SellerBrief
+	if err := c.do(ctx, http.MethodGet, "/sellers/"+url.PathEscape(gluserID)+"/brief", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// TrendQuery selects the range and granularity for GetSellerTrends
+type TrendQuery struct {
+	Granularity string // GranularityCall, GranularityDay (default), GranularityWeek or GranularityMonth
+	From        string // YYYY-MM-DD, inclusive
+	To          string // YYYY-MM-DD, inclusive
+}
+
+func (q TrendQuery) query() url.Values {
+	v := url.Values{}
+	if q.Granularity != "" {
+		v.Set("granularity", q.Granularity)
+	}
+	if q.From != "" {
+		v.Set("from", q.From)
+	}
+	if q.To != "" {
+		v.Set("to", q.To)
+	}
+	return v
+}
+
+// ExportSeller fetches everything stored about a seller, for ImportSellers
+// in another environment (GET /sellers/{id}/export). Needs the migrate scope.
+func (c *Client) ExportSeller(ctx context.Context, gluserID string) (*SellerExport, error) {
+	var out SellerExport
+	if err := c.do(ctx, http.MethodGet, "/sellers/"+url.PathEscape(gluserID)+"/export", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ExportSellers calls fn with every seller's export as the server streams
+// them (GET /export), stopping at fn's first error. Needs the migrate scope.
+func (c *Client) ExportSellers(ctx context.Context, fn func(*SellerExport) error) error {
+	return c.stream(ctx, "/export", nil, func(line []byte) error {
+		var export SellerExport
+		if err := json.Unmarshal(line, &export); err != nil {
+			return err
+		}
+		return fn(&export)
+	})
+}
+
+// GetSellerDataInventory lists everything stored about a seller, for access
+// requests (GET /sellers/{id}/data-inventory). Needs the migrate scope.
+func (c *Client) GetSellerDataInventory(ctx context.Context, gluserID string) (*DataInventory, error) {
+	var out DataInventory
+	if err := c.do(ctx, http.MethodGet, "/sellers/"+url.PathEscape(gluserID)+"/data-inventory", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DownloadSellerData writes everything stored about a seller to w as a zip
+// archive (GET /sellers/{id}/data-inventory?format=zip)
+func (c *Client) DownloadSellerData(ctx context.Context, gluserID string, w io.Writer) error {
+	return c.do(ctx, http.MethodGet, "/sellers/"+url.PathEscape(gluserID)+"/data-inventory", url.Values{"format": {"zip"}}, nil, w)
+}
+
+// ImportSeller imports one exported seller (POST /sellers/import). Needs
+// the migrate scope.
+func (c *Client) ImportSeller(ctx context.Context, export *SellerExport, opts SellerImportOptions) (*SellerImportResult, error) {
+	var out SellerImportResult
+	if err := c.do(ctx, http.MethodPost, "/sellers/import", opts.query(), export, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ImportSellers imports exported sellers in one NDJSON request (POST
+// /sellers/import). opts.SellerID can't be used here.
+func (c *Client) ImportSellers(ctx context.Context, exports []SellerExport, opts SellerImportOptions) (*SellerImportResult, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf) // One export per line
+	for i := range exports {
+		if err := enc.Encode(&exports[i]); err != nil {
+			return nil, fmt.Errorf("failed to encode export %d: %w", i+1, err)
+		}
+	}
+	var out SellerImportResult
+	if err := c.do(ctx, http.MethodPost, "/sellers/import", opts.query(), ndjsonBody(buf.Bytes()), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ImportAnalyses imports analyses produced before adopting the service, read
+// from r as NDJSON (POST /import/analyses)
+func (c *Client) ImportAnalyses(ctx context.Context, r io.Reader, dryRun bool) (*AnalysisImportResult, error) {
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read analyses: %w", err)
+	}
+	query := url.Values{}
+	if dryRun {
+		query.Set("dry_run", "true")
+	}
+	var out AnalysisImportResult
+	if err := c.do(ctx, http.MethodPost, "/import/analyses", query, ndjsonBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetSellerTrends fetches a seller's trend history (GET /sellers/{gluser_id}/trends)
+func (c *Client) GetSellerTrends(ctx context.Context, gluserID string, q TrendQuery) (*TrendSeries, error) {
+	var out TrendSeries
+	if err := c.do(ctx, http.MethodGet, "/sellers/"+url.PathEscape(gluserID)+"/trends", q.query(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetSellerTimeline returns a seller's call history across hot storage and
+// the archive, newest first; from and to (YYYY-MM-DD, inclusive) may be
+// empty. While ArchiveStatus is loading, ask again for the archived calls
+// (GET /sellers/{id}/timeline).
+func (c *Client) GetSellerTimeline(ctx context.Context, gluserID, from, to string) (*SellerTimeline, error) {
+	q := url.Values{}
+	if from != "" {
+		q.Set("from", from)
+	}
+	if to != "" {
+		q.Set("to", to)
+	}
+	var out SellerTimeline
+	if err := c.do(ctx, http.MethodGet, "/sellers/"+url.PathEscape(gluserID)+"/timeline", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ExplainSellerRisk breaks a seller's health score and churn risk down into
+// their factors (GET /sellers/{id}/risk-explanation)
+func (c *Client) ExplainSellerRisk(ctx context.Context, gluserID string) (*RiskExplanation, error) {
+	var out RiskExplanation
+	if err := c.do(ctx, http.MethodGet, "/sellers/"+url.PathEscape(gluserID)+"/risk-explanation", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CallFilter selects analyzed calls for ListCalls
+type CallFilter struct {
+	SellerID  string
+	From      string // YYYY-MM-DD, inclusive
+	To        string // YYYY-MM-DD, inclusive
+	Sentiment string
+	Bucket    string
+	System    string // Source system the transcript came from
+	Region    string
+	Channel   string // voice, chat or whatsapp
+	Escalated *bool
+	Page      int // 1-based
+	PageSize  int
+}
+
+func (f CallFilter) query() url.Values {
+	q := url.Values{}
+	if f.SellerID != "" {
+		q.Set("seller", f.SellerID)
+	}
+	if f.From != "" {
+		q.Set("from", f.From)
+	}
+	if f.To != "" {
+		q.Set("to", f.To)
+	}
+	if f.Sentiment != "" {
+		q.Set("sentiment", f.Sentiment)
+	}
+	if f.Bucket != "" {
+		q.Set("bucket", f.Bucket)
+	}
+	if f.System != "" {
+		q.Set("system", f.System)
+	}
+	if f.Region != "" {
+		q.Set("region", f.Region)
+	}
+	if f.Channel != "" {
+		q.Set("channel", f.Channel)
+	}
+	if f.Escalated != nil {
+		q.Set("escalated", strconv.FormatBool(*f.Escalated))
+	}
+	if f.Page > 0 {
+		q.Set("page", strconv.Itoa(f.Page))
+	}
+	if f.PageSize > 0 {
+		q.Set("page_size", strconv.Itoa(f.PageSize))
+	}
+	return q
+}
+
+// ListCalls returns one page of analyzed calls, newest first (GET /calls)
+func (c *Client) ListCalls(ctx context.Context, f CallFilter) (*CallPage, error) {
+	var out CallPage
+	if err := c.do(ctx, http.MethodGet, "/calls", f.query(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// StreamCalls calls fn with every analyzed call matching the filter, newest
+// first, as the server streams them (GET /calls as NDJSON); paging is
+// ignored. It stops at fn's first error. Long streams need an HTTP client
+// without the default 30s timeout (see WithHTTPClient).
+func (c *Client) StreamCalls(ctx context.Context, f CallFilter, fn func(CallListItem) error) error {
+	return c.stream(ctx, "/calls", f.query(), func(line []byte) error {
+		var item CallListItem
+		if err := json.Unmarshal(line, &item); err != nil {
+			return err
+		}
+		return fn(item)
+	})
+}
+
+// SellerFilter selects sellers for ListSellers
+type SellerFilter struct {
+	Prefix         string // Seller IDs starting with this
+	CustomerType   string
+	City           string
+	Vertical       string
+	Stage          string // Journey stage
+	ChurnRisk      string
+	HealthLabel    string
+	NeedsAttention *bool
+	CustomFields   map[string]string // Custom field values, by name (cf.<name>=)
+	Sort           string            // SellerSortLastCall (default), SellerSortHealth or SellerSortOpenIssues
+	Page           int               // 1-based
+	PageSize       int
+}
+
+func (f SellerFilter) query() url.Values {
+	q := url.Values{}
+	for key, v := range map[string]string{
+		"q": f.Prefix, "customer_type": f.CustomerType, "city": f.City, "vertical": f.Vertical,
+		"stage": f.Stage, "churn_risk": f.ChurnRisk, "health_label": f.HealthLabel, "sort": f.Sort,
+	} {
+		if v != "" {
+			q.Set(key, v)
+		}
+	}
+	if f.NeedsAttention != nil {
+		q.Set("needs_attention", strconv.FormatBool(*f.NeedsAttention))
+	}
+	for name, v := range f.CustomFields {
+		q.Set("cf."+name, v)
+	}
+	if f.Page > 0 {
+		q.Set("page", strconv.Itoa(f.Page))
+	}
+	if f.PageSize > 0 {
+		q.Set("page_size", strconv.Itoa(f.PageSize))
+	}
+	return q
+}
+
+// ListSellers returns one page of seller summaries (GET /sellers)
+func (c *Client) ListSellers(ctx context.Context, f SellerFilter) (*SellerPage, error) {
+	var out SellerPage
+	if err := c.do(ctx, http.MethodGet, "/sellers", f.query(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// StreamSellers calls fn with every seller summary matching the filter as
+// the server streams them (GET /sellers as NDJSON); paging is ignored. It
+// stops at fn's first error.
+func (c *Client) StreamSellers(ctx context.Context, f SellerFilter, fn func(SellerSummary) error) error {
+	return c.stream(ctx, "/sellers", f.query(), func(line []byte) error {
+		var s SellerSummary
+		if err := json.Unmarshal(line, &s); err != nil {
+			return err
+		}
+		return fn(s)
+	})
+}
+
+// GetCallTranscript fetches a call's full transcripts (GET /calls/{id}/transcript).
+// The client needs an API key with the transcripts scope (see WithHeader).
+func (c *Client) GetCallTranscript(ctx context.Context, callID string) (*CallTranscript, error) {
+	var out CallTranscript
+	if err := c.do(ctx, http.MethodGet, "/calls/"+url.PathEscape(callID)+"/transcript", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetRehydratedCallTranscript fetches a call's full transcripts with the
+// PII vault's values in place of their tokens
+// (GET /calls/{id}/transcript?rehydrate=true). Needs the transcripts and pii scopes.
+func (c *Client) GetRehydratedCallTranscript(ctx context.Context, callID string) (*CallTranscript, error) {
+	var out CallTranscript
+	q := url.Values{"rehydrate": {"true"}}
+	if err := c.do(ctx, http.MethodGet, "/calls/"+url.PathEscape(callID)+"/transcript", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetFollowUpDraft fetches the message drafted for the agent to send the
+// seller after a call (GET /calls/{id}/draft-followup)
+func (c *Client) GetFollowUpDraft(ctx context.Context, callID string) (*FollowUpDraft, error) {
+	var out FollowUpDraft
+	if err := c.do(ctx, http.MethodGet, "/calls/"+url.PathEscape(callID)+"/draft-followup", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetCallLLMRaw fetches the raw Gemini responses behind a call's analysis
+// (GET /calls/{id}/llm-raw), until they expire. Needs the transcripts scope.
+func (c *Client) GetCallLLMRaw(ctx context.Context, callID string) (*LLMRawResponse, error) {
+	var out LLMRawResponse
+	if err := c.do(ctx, http.MethodGet, "/calls/"+url.PathEscape(callID)+"/llm-raw", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetCallRecordingLink returns a short-lived signed URL for a call's audio
+// (GET /calls/{id}/recording). Needs the transcripts scope, like GetCallTranscript.
+func (c *Client) GetCallRecordingLink(ctx context.Context, callID string) (*RecordingLink, error) {
+	var out RecordingLink
+	if err := c.do(ctx, http.MethodGet, "/calls/"+url.PathEscape(callID)+"/recording", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// FetchCallRecording downloads a call's audio from its call_recording_url
+// into the recording store now (POST /calls/{id}/recording)
+func (c *Client) FetchCallRecording(ctx context.Context, callID string) (*Recording, error) {
+	var out Recording
+	if err := c.do(ctx, http.MethodPost, "/calls/"+url.PathEscape(callID)+"/recording", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListTicketSuppressions returns the ticket suppression rules, with expired
+// ones when includeExpired is set (GET /admin/ticket-suppressions)
+func (c *Client) ListTicketSuppressions(ctx context.Context, includeExpired bool) ([]TicketSuppression, error) {
+	q := url.Values{}
+	if includeExpired {
+		q.Set("expired", "true")
+	}
+	var out struct {
+		Suppressions []TicketSuppression `json:"suppressions"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/admin/ticket-suppressions", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Suppressions, nil
+}
+
+// CreateTicketSuppression adds a ticket suppression rule (POST /admin/ticket-suppressions)
+func (c *Client) CreateTicketSuppression(ctx context.Context, in TicketSuppressionRequest) (*TicketSuppression, error) {
+	var out TicketSuppression
+	if err := c.do(ctx, http.MethodPost, "/admin/ticket-suppressions", nil, in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteTicketSuppression removes a ticket suppression rule (DELETE /admin/ticket-suppressions/{id})
+func (c *Client) DeleteTicketSuppression(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, "/admin/ticket-suppressions/"+url.PathEscape(id), nil, nil, nil)
+}
+
+// ListWebhookSubscriptions returns the subscriptions to seller profile
+// transitions, without their secrets (GET /admin/webhooks)
+func (c *Client) ListWebhookSubscriptions(ctx context.Context) ([]WebhookSubscription, error) {
+	var out struct {
+		Webhooks []WebhookSubscription `json:"webhooks"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/admin/webhooks", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Webhooks, nil
+}
+
+// CreateWebhookSubscription subscribes a URL to seller profile transitions (POST /admin/webhooks)
+func (c *Client) CreateWebhookSubscription(ctx context.Context, in WebhookSubscriptionRequest) (*WebhookSubscription, error) {
+	var out WebhookSubscription
+	if err := c.do(ctx, http.MethodPost, "/admin/webhooks", nil, in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteWebhookSubscription removes a webhook subscription (DELETE /admin/webhooks/{id})
+func (c *Client) DeleteWebhookSubscription(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, "/admin/webhooks/"+url.PathEscape(id), nil, nil, nil)
+}
+
+// ListAlertSubscriptions returns the alert subscriptions, without their
+// secrets (GET /admin/alert-subscriptions)
+func (c *Client) ListAlertSubscriptions(ctx context.Context) ([]AlertSubscription, error) {
+	var out struct {
+		Subscriptions []AlertSubscription `json:"subscriptions"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/admin/alert-subscriptions", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Subscriptions, nil
+}
+
+// CreateAlertSubscription subscribes someone to the alerts in their cities
+// and verticals (POST /admin/alert-subscriptions)
+func (c *Client) CreateAlertSubscription(ctx context.Context, in AlertSubscriptionRequest) (*AlertSubscription, error) {
+	var out AlertSubscription
+	if err := c.do(ctx, http.MethodPost, "/admin/alert-subscriptions", nil, in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetAlertSubscription returns one alert subscription (GET /admin/alert-subscriptions/{id})
+func (c *Client) GetAlertSubscription(ctx context.Context, id string) (*AlertSubscription, error) {
+	var out AlertSubscription
+	if err := c.do(ctx, http.MethodGet, "/admin/alert-subscriptions/"+url.PathEscape(id), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteAlertSubscription removes an alert subscription (DELETE /admin/alert-subscriptions/{id})
+func (c *Client) DeleteAlertSubscription(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, "/admin/alert-subscriptions/"+url.PathEscape(id), nil, nil, nil)
+}
+
+// SelfTest runs synthetic calls through the pipeline and reports each stage
+// (POST /admin/selftest). The keyword classifier analyzes them unless gemini
+// is set. A failing stage is reported, not returned as an error.
+func (c *Client) SelfTest(ctx context.Context, gemini bool) (*SelfTestReport, error) {
+	var query url.Values
+	if gemini {
+		query = url.Values{"llm": {"gemini"}}
+	}
+	var out SelfTestReport
+	if err := c.do(ctx, http.MethodPost, "/admin/selftest", query, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListBucketOwners returns the teams owning feature buckets (GET /admin/bucket-owners)
+func (c *Client) ListBucketOwners(ctx context.Context) ([]BucketOwner, error) {
+	var out struct {
+		Owners []BucketOwner `json:"owners"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/admin/bucket-owners", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Owners, nil
+}
+
+// SetBucketOwner makes a team the owner of a feature bucket, replacing its
+// previous owner (PUT /admin/bucket-owners/{bucket})
+func (c *Client) SetBucketOwner(ctx context.Context, bucket string, in BucketOwnerRequest) (*BucketOwner, error) {
+	var out BucketOwner
+	if err := c.do(ctx, http.MethodPut, "/admin/bucket-owners/"+url.PathEscape(bucket), nil, in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteBucketOwner leaves a feature bucket without an owner (DELETE /admin/bucket-owners/{bucket})
+func (c *Client) DeleteBucketOwner(ctx context.Context, bucket string) error {
+	return c.do(ctx, http.MethodDelete, "/admin/bucket-owners/"+url.PathEscape(bucket), nil, nil, nil)
+}
+
+// ListFeatureFlags returns every feature flag's current setting (GET /admin/flags)
+func (c *Client) ListFeatureFlags(ctx context.Context) ([]FeatureFlag, error) {
+	var out struct {
+		Flags []FeatureFlag `json:"flags"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/admin/flags", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Flags, nil
+}
+
+// SetFeatureFlag turns a feature flag on or off, for a share of calls or
+// some origins (PUT /admin/flags/{name})
+func (c *Client) SetFeatureFlag(ctx context.Context, name string, in FeatureFlagRequest) (*FeatureFlag, error) {
+	var out FeatureFlag
+	if err := c.do(ctx, http.MethodPut, "/admin/flags/"+url.PathEscape(name), nil, in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteFeatureFlag returns a feature flag to FEATURE_FLAGS or the default (DELETE /admin/flags/{name})
+func (c *Client) DeleteFeatureFlag(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodDelete, "/admin/flags/"+url.PathEscape(name), nil, nil, nil)
+}
+
+// ListCustomFields returns the custom field definitions of an entity
+// (CustomFieldProfile or CustomFieldTicket), every entity's when it's empty
+// (GET /admin/custom-fields)
+func (c *Client) ListCustomFields(ctx context.Context, entity string) ([]CustomFieldDefinition, error) {
+	q := url.Values{}
+	if entity != "" {
+		q.Set("entity", entity)
+	}
+	var out struct {
+		Fields []CustomFieldDefinition `json:"fields"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/admin/custom-fields", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Fields, nil
+}
+
+// SetCustomField defines a custom field on an entity, or changes its
+// definition (PUT /admin/custom-fields/{entity}/{name})
+func (c *Client) SetCustomField(ctx context.Context, entity, name string, in CustomFieldRequest) (*CustomFieldDefinition, error) {
+	var out CustomFieldDefinition
+	path := "/admin/custom-fields/" + url.PathEscape(entity) + "/" + url.PathEscape(name)
+	if err := c.do(ctx, http.MethodPut, path, nil, in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteCustomField removes a custom field's definition; values already set
+// are kept (DELETE /admin/custom-fields/{entity}/{name})
+func (c *Client) DeleteCustomField(ctx context.Context, entity, name string) error {
+	return c.do(ctx, http.MethodDelete, "/admin/custom-fields/"+url.PathEscape(entity)+"/"+url.PathEscape(name), nil, nil, nil)
+}
+
+// ListRollouts returns the canary rollouts of prompt/model changes, newest
+// first (GET /admin/rollouts)
+func (c *Client) ListRollouts(ctx context.Context) ([]Rollout, error) {
+	var out struct {
+		Rollouts []Rollout `json:"rollouts"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/admin/rollouts", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Rollouts, nil
+}
+
+// CreateRollout routes a share of analyses to a candidate model and/or
+// prompt registry (POST /admin/rollouts)
+func (c *Client) CreateRollout(ctx context.Context, in RolloutRequest) (*Rollout, error) {
+	var out Rollout
+	if err := c.do(ctx, http.MethodPost, "/admin/rollouts", nil, in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetRollout returns a rollout with its metrics as of now (GET /admin/rollouts/{id})
+func (c *Client) GetRollout(ctx context.Context, id string) (*Rollout, error) {
+	var out Rollout
+	if err := c.do(ctx, http.MethodGet, "/admin/rollouts/"+url.PathEscape(id), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateRollout changes a rollout's percent or status (PATCH /admin/rollouts/{id})
+func (c *Client) UpdateRollout(ctx context.Context, id string, in RolloutUpdate) (*Rollout, error) {
+	var out Rollout
+	if err := c.do(ctx, http.MethodPatch, "/admin/rollouts/"+url.PathEscape(id), nil, in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetEvalHistory returns the eval runs created from to to (YYYY-MM-DD,
+// inclusive, either may be empty) and each version's metrics across them;
+// a non-empty version keeps the versions containing it (GET /admin/eval/history)
+func (c *Client) GetEvalHistory(ctx context.Context, from, to, version string) (*EvalHistory, error) {
+	q := url.Values{}
+	if from != "" {
+		q.Set("from", from)
+	}
+	if to != "" {
+		q.Set("to", to)
+	}
+	if version != "" {
+		q.Set("version", version)
+	}
+	var out EvalHistory
+	if err := c.do(ctx, http.MethodGet, "/admin/eval/history", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RunEval records every version's metrics now, off schedule (POST /admin/eval/run)
+func (c *Client) RunEval(ctx context.Context) (*EvalRun, error) {
+	var out EvalRun
+	if err := c.do(ctx, http.MethodPost, "/admin/eval/run", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListKBDocuments returns the knowledge base documents, without their
+// content (GET /admin/kb)
+func (c *Client) ListKBDocuments(ctx context.Context) ([]KBDocument, error) {
+	var out struct {
+		Documents []KBDocument `json:"documents"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/admin/kb", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Documents, nil
+}
+
+// GetKBDocument returns a knowledge base document with its passages (GET /admin/kb/{id})
+func (c *Client) GetKBDocument(ctx context.Context, id string) (*KBDocument, error) {
+	var out KBDocument
+	if err := c.do(ctx, http.MethodGet, "/admin/kb/"+url.PathEscape(id), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UploadKBDocument adds a document to the knowledge base, replacing the one
+// with the same title (POST /admin/kb)
+func (c *Client) UploadKBDocument(ctx context.Context, in KBDocumentRequest) (*KBDocument, error) {
+	var out KBDocument
+	if err := c.do(ctx, http.MethodPost, "/admin/kb", nil, in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteKBDocument removes a knowledge base document (DELETE /admin/kb/{id})
+func (c *Client) DeleteKBDocument(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, "/admin/kb/"+url.PathEscape(id), nil, nil, nil)
+}
+
+// PreviewAggregation returns the aggregate and tickets aggregating date
+// would save, without saving them (POST /aggregates/preview)
+func (c *Client) PreviewAggregation(ctx context.Context, date string) (*AggregatePreview, error) {
+	in := struct {
+		Date string `json:"date,omitempty"`
+	}{date}
+	var out AggregatePreview
+	if err := c.do(ctx, http.MethodPost, "/aggregates/preview", nil, in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetComputedDashboard returns a date's dashboard as first computed after
+// the day ended, unchanged by later re-aggregation
+// (GET /dashboard?as_computed=true). Dates without a snapshot fail with a 404.
+func (c *Client) GetComputedDashboard(ctx context.Context, date string) (*DashboardResponse, error) {
+	q := url.Values{"date": {date}, "as_computed": {"true"}}
+	var out DashboardResponse
+	if err := c.do(ctx, http.MethodGet, "/dashboard", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetTierAggregates returns a date's aggregate split by customer tier (GET /aggregates/{date}/by-tier)
+func (c *Client) GetTierAggregates(ctx context.Context, date string) (*TierAggregates, error) {
+	var out TierAggregates
+	if err := c.do(ctx, http.MethodGet, "/aggregates/"+url.PathEscape(date)+"/by-tier", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CheckAggregates flags the recent aggregates whose analyses changed since
+// they were built, aggregating them again with recompute (POST /aggregates/check)
+func (c *Client) CheckAggregates(ctx context.Context, recompute bool) (*StalenessReport, error) {
+	in := struct {
+		Recompute bool `json:"recompute"`
+	}{recompute}
+	var out StalenessReport
+	if err := c.do(ctx, http.MethodPost, "/aggregates/check", nil, in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListTickets returns the tickets generated for a date, YYYY-MM-DD (GET /tickets/{date})
+func (c *Client) ListTickets(ctx context.Context, date string) ([]Ticket, error) {
+	var out struct {
+		Tickets []Ticket `json:"tickets"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/tickets/"+url.PathEscape(date), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Tickets, nil
+}
+
+// FindTickets returns a date's tickets with the given custom field values,
+// by name (GET /tickets/{date}?cf.<name>=)
+func (c *Client) FindTickets(ctx context.Context, date string, customFields map[string]string) ([]Ticket, error) {
+	q := url.Values{}
+	for name, v := range customFields {
+		q.Set("cf."+name, v)
+	}
+	var out struct {
+		Tickets []Ticket `json:"tickets"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/tickets/"+url.PathEscape(date), q, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Tickets, nil
+}
+
+// UpdateTicketStatus sets a ticket's status (PATCH /tickets/{date}/{id}).
+// Resolving it closes the ticket's GitHub issue when GitHub sync is on.
+func (c *Client) UpdateTicketStatus(ctx context.Context, date, ticketID, status string) (*Ticket, error) {
+	return c.UpdateTicket(ctx, date, ticketID, TicketStatusUpdate{Status: status})
+}
+
+// UpdateTicket sets a ticket's status, resolution note and custom fields
+// (PATCH /tickets/{date}/{id})
+func (c *Client) UpdateTicket(ctx context.Context, date, ticketID string, u TicketStatusUpdate) (*Ticket, error) {
+	var out Ticket
+	path := "/tickets/" + url.PathEscape(date) + "/" + url.PathEscape(ticketID)
+	if err := c.do(ctx, http.MethodPatch, path, nil, u, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// BulkUpdateTickets sets the status of many tickets in one request, with a
+// result per item (POST /tickets/bulk-update, needs an API key with the
+// tickets scope). Items failing doesn't fail the call; check each result.
+func (c *Client) BulkUpdateTickets(ctx context.Context, items []TicketBulkItem) (*TicketBulkResult, error) {
+	var out TicketBulkResult
+	if err := c.do(ctx, http.MethodPost, "/tickets/bulk-update", nil, items, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteTicket moves a ticket to the trash (DELETE /tickets/{date}/{id})
+func (c *Client) DeleteTicket(ctx context.Context, date, ticketID, reason string) (*TrashItem, error) {
+	var out TrashItem
+	path := "/tickets/" + url.PathEscape(date) + "/" + url.PathEscape(ticketID)
+	if err := c.do(ctx, http.MethodDelete, path, reasonQuery(reason), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteCall moves a call's analysis to the trash (DELETE /calls/{id})
+func (c *Client) DeleteCall(ctx context.Context, callID, reason string) (*TrashItem, error) {
+	var out TrashItem
+	if err := c.do(ctx, http.MethodDelete, "/calls/"+url.PathEscape(callID), reasonQuery(reason), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListTrash returns the deleted analyses and tickets, kind "" for both (GET /trash)
+func (c *Client) ListTrash(ctx context.Context, kind string) (*TrashList, error) {
+	var query url.Values
+	if kind != "" {
+		query = url.Values{"kind": {kind}}
+	}
+	var out TrashList
+	if err := c.do(ctx, http.MethodGet, "/trash", query, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RestoreFromTrash puts a deleted item back (POST /trash/{kind}/{id}/restore)
+func (c *Client) RestoreFromTrash(ctx context.Context, kind, id string) (*TrashItem, error) {
+	var out TrashItem
+	path := "/trash/" + url.PathEscape(kind) + "/" + url.PathEscape(id) + "/restore"
+	if err := c.do(ctx, http.MethodPost, path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func reasonQuery(reason string) url.Values {
+	if reason == "" {
+		return nil
+	}
+	return url.Values{"reason": {reason}}
+}
+
+// GetCapabilities returns what the deployment has turned on (GET /capabilities)
+func (c *Client) GetCapabilities(ctx context.Context) (*Capabilities, error) {
+	var out Capabilities
+	if err := c.do(ctx, http.MethodGet, "/capabilities", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetPipelineStats returns the transcript backlog and processing capacity (GET /admin/pipeline/stats)
+func (c *Client) GetPipelineStats(ctx context.Context) (*PipelineStats, error) {
+	var out PipelineStats
+	if err := c.do(ctx, http.MethodGet, "/admin/pipeline/stats", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetDataQuality sums up the quality of the transcripts received between
+// from and to (YYYY-MM-DD, inclusive, empty for the last 7 days) per source
+// (GET /admin/data-quality)
+func (c *Client) GetDataQuality(ctx context.Context, from, to string) (*DataQualityReport, error) {
+	q := url.Values{}
+	if from != "" {
+		q.Set("from", from)
+	}
+	if to != "" {
+		q.Set("to", to)
+	}
+	var out DataQualityReport
+	if err := c.do(ctx, http.MethodGet, "/admin/data-quality", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetLeaderStatus returns the instance's leader election role (GET /admin/leader)
+func (c *Client) GetLeaderStatus(ctx context.Context) (*LeaderStatus, error) {
+	var out LeaderStatus
+	if err := c.do(ctx, http.MethodGet, "/admin/leader", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetKeyUsage returns an API key's usage in a month (YYYY-MM, empty for
+// this month) against its quota (GET /admin/keys/{id}/usage)
+func (c *Client) GetKeyUsage(ctx context.Context, keyName, month string) (*KeyUsageReport, error) {
+	query := url.Values{}
+	if month != "" {
+		query.Set("month", month)
+	}
+	var out KeyUsageReport
+	if err := c.do(ctx, http.MethodGet, "/admin/keys/"+url.PathEscape(keyName)+"/usage", query, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetJobSchedule lists the background jobs with their next and last runs (GET /admin/jobs/schedule)
+func (c *Client) GetJobSchedule(ctx context.Context) (*JobSchedule, error) {
+	var out JobSchedule
+	if err := c.do(ctx, http.MethodGet, "/admin/jobs/schedule", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RunJob starts a background job now without waiting for it (POST /admin/jobs/{name}/run).
+// GetJobSchedule shows how it went.
+func (c *Client) RunJob(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodPost, "/admin/jobs/"+url.PathEscape(name)+"/run", nil, nil, nil)
+}
+
+// GetJob returns a long-running operation's record (GET /jobs/{id})
+func (c *Client) GetJob(ctx context.Context, id string) (*Job, error) {
+	var out Job
+	if err := c.do(ctx, http.MethodGet, "/jobs/"+url.PathEscape(id), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListJobs lists long-running operations, newest first, optionally of one
+// type and status (GET /jobs)
+func (c *Client) ListJobs(ctx context.Context, jobType, status string) (*JobList, error) {
+	q := url.Values{}
+	if jobType != "" {
+		q.Set("type", jobType)
+	}
+	if status != "" {
+		q.Set("status", status)
+	}
+	var out JobList
+	if err := c.do(ctx, http.MethodGet, "/jobs", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Drain marks the instance unready and returns once it has waited DRAIN_DELAY
+// for load balancers to stop routing to it (POST /admin/drain)
+func (c *Client) Drain(ctx context.Context) (*ReadinessStatus, error) {
+	var out ReadinessStatus
+	if err := c.do(ctx, http.MethodPost, "/admin/drain", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetAttentionQueue lists sellers needing attention, most urgent first
+// (GET /attention). state filters to AttentionOpen, AttentionAcknowledged or
+// AttentionSnoozed; empty lists all of them.
+func (c *Client) GetAttentionQueue(ctx context.Context, state string) (*AttentionQueue, error) {
+	q := url.Values{}
+	if state != "" {
+		q.Set("state", state)
+	}
+	var out AttentionQueue
+	if err := c.do(ctx, http.MethodGet, "/attention", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AcknowledgeAttention acknowledges a seller's attention flag, silencing its
+// alerts until the reason changes (POST /attention/{gluser_id}/acknowledge)
+func (c *Client) AcknowledgeAttention(ctx context.Context, gluserID string, in AttentionAckRequest) (*AttentionAck, error) {
+	var out AttentionAck
+	if err := c.do(ctx, http.MethodPost, "/attention/"+url.PathEscape(gluserID)+"/acknowledge", nil, in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SnoozeAttention sets a seller's attention flag aside until in.Until
+// (POST /attention/{gluser_id}/snooze)
+func (c *Client) SnoozeAttention(ctx context.Context, gluserID string, in AttentionAckRequest) (*AttentionAck, error) {
+	var out AttentionAck
+	if err := c.do(ctx, http.MethodPost, "/attention/"+url.PathEscape(gluserID)+"/snooze", nil, in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetSellerCaseSummary returns the seller-safe summary of a seller's cases
+// and follow-ups, for the seller portal (GET /portal/sellers/{gluser_id},
+// needs an API key with the portal scope)
+func (c *Client) GetSellerCaseSummary(ctx context.Context, gluserID string) (*SellerCaseSummary, error) {
+	var out SellerCaseSummary
+	if err := c.do(ctx, http.MethodGet, "/portal/sellers/"+url.PathEscape(gluserID), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetOnboardingFunnel follows the sellers who called in their first 90 days
+// between from and to (YYYY-MM-DD, inclusive, empty for the last 90 days)
+// (GET /analytics/onboarding)
+func (c *Client) GetOnboardingFunnel(ctx context.Context, from, to string) (*OnboardingFunnel, error) {
+	q := url.Values{}
+	if from != "" {
+		q.Set("from", from)
+	}
+	if to != "" {
+		q.Set("to", to)
+	}
+	var out OnboardingFunnel
+	if err := c.do(ctx, http.MethodGet, "/analytics/onboarding", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetTicketBurndown reports open vs resolved tickets day by day, median
+// resolution time per bucket and the oldest open tickets between from and
+// to (YYYY-MM-DD, inclusive, empty for the last 30 days)
+// (GET /analytics/tickets/burndown)
+func (c *Client) GetTicketBurndown(ctx context.Context, from, to string) (*TicketBurndown, error) {
+	q := url.Values{}
+	if from != "" {
+		q.Set("from", from)
+	}
+	if to != "" {
+		q.Set("to", to)
+	}
+	var out TicketBurndown
+	if err := c.do(ctx, http.MethodGet, "/analytics/tickets/burndown", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetChurnReasons counts medium/high churn risk calls by reason category
+// between from and to (YYYY-MM-DD, inclusive, empty for the last 30 days)
+// (GET /analytics/churn-reasons)
+func (c *Client) GetChurnReasons(ctx context.Context, from, to string) (*ChurnReasonReport, error) {
+	q := url.Values{}
+	if from != "" {
+		q.Set("from", from)
+	}
+	if to != "" {
+		q.Set("to", to)
+	}
+	var out ChurnReasonReport
+	if err := c.do(ctx, http.MethodGet, "/analytics/churn-reasons", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RecordChurnOutcomes records sellers' actual renewals and cancellations,
+// replacing a seller's outcome on the same date (POST /churn-outcomes)
+func (c *Client) RecordChurnOutcomes(ctx context.Context, outcomes []ChurnOutcome) (*ChurnOutcomeResult, error) {
+	var out ChurnOutcomeResult
+	if err := c.do(ctx, http.MethodPost, "/churn-outcomes", nil, outcomes, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RecordChurnOutcomesCSV records outcomes from a CSV export with a header
+// row naming its gluser_id, outcome and date columns (POST /churn-outcomes)
+func (c *Client) RecordChurnOutcomesCSV(ctx context.Context, r io.Reader) (*ChurnOutcomeResult, error) {
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read outcomes: %w", err)
+	}
+	var out ChurnOutcomeResult
+	if err := c.do(ctx, http.MethodPost, "/churn-outcomes", nil, csvBody(body), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetChurnAccuracy checks churn predictions against the outcomes recorded
+// between from and to (YYYY-MM-DD, inclusive, empty for the last 180 days),
+// per prediction version; windowDays 0 is the server's default
+// (GET /analytics/churn-accuracy)
+func (c *Client) GetChurnAccuracy(ctx context.Context, from, to string, windowDays int) (*ChurnAccuracyReport, error) {
+	q := url.Values{}
+	if from != "" {
+		q.Set("from", from)
+	}
+	if to != "" {
+		q.Set("to", to)
+	}
+	if windowDays > 0 {
+		q.Set("window_days", strconv.Itoa(windowDays))
+	}
+	var out ChurnAccuracyReport
+	if err := c.do(ctx, http.MethodGet, "/analytics/churn-accuracy", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetResolutionDurability rates the issue resolutions made between from and
+// to (YYYY-MM-DD, inclusive, empty for all) by whether they held, per bucket
+// and agent (GET /analytics/resolution-durability)
+func (c *Client) GetResolutionDurability(ctx context.Context, from, to string) (*ResolutionDurabilityReport, error) {
+	q := url.Values{}
+	if from != "" {
+		q.Set("from", from)
+	}
+	if to != "" {
+		q.Set("to", to)
+	}
+	var out ResolutionDurabilityReport
+	if err := c.do(ctx, http.MethodGet, "/analytics/resolution-durability", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetCrossCheck sums up how the keyword classifier's readings of calls
+// differed from Gemini's analyses between from and to (YYYY-MM-DD,
+// inclusive, empty for the last 30 days) (GET /analytics/cross-check)
+func (c *Client) GetCrossCheck(ctx context.Context, from, to string) (*CrossCheckReport, error) {
+	q := url.Values{}
+	if from != "" {
+		q.Set("from", from)
+	}
+	if to != "" {
+		q.Set("to", to)
+	}
+	var out CrossCheckReport
+	if err := c.do(ctx, http.MethodGet, "/analytics/cross-check", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetSatisfactionDrivers ranks the factors behind low satisfaction between
+// from and to (YYYY-MM-DD, inclusive, empty for the last 90 days)
+// (GET /analytics/drivers)
+func (c *Client) GetSatisfactionDrivers(ctx context.Context, from, to string) (*SatisfactionDriverReport, error) {
+	q := url.Values{}
+	if from != "" {
+		q.Set("from", from)
+	}
+	if to != "" {
+		q.Set("to", to)
+	}
+	var out SatisfactionDriverReport
+	if err := c.do(ctx, http.MethodGet, "/analytics/drivers", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetAgentLeaderboard ranks agents by composite QA score over the day, week
+// or month ending on end (YYYY-MM-DD, empty for today; empty period is a
+// week), with each agent's movement since the period before. minCalls 0
+// uses the server's default. (GET /agents/leaderboard)
+func (c *Client) GetAgentLeaderboard(ctx context.Context, period, end string, minCalls int) (*AgentLeaderboard, error) {
+	q := url.Values{}
+	if period != "" {
+		q.Set("period", period)
+	}
+	if end != "" {
+		q.Set("end", end)
+	}
+	if minCalls > 0 {
+		q.Set("min_calls", strconv.Itoa(minCalls))
+	}
+	var out AgentLeaderboard
+	if err := c.do(ctx, http.MethodGet, "/agents/leaderboard", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Ask answers a plain-language question from the stored analytics, with
+// the queries it was translated into and their results (POST /ask)
+func (c *Client) Ask(ctx context.Context, question string) (*AskResponse, error) {
+	var out AskResponse
+	if err := c.do(ctx, http.MethodPost, "/ask", nil, AskRequest{Question: question}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetUpsellPipeline groups upsell opportunities by product SKU between from
+// and to (YYYY-MM-DD, inclusive, empty for the last 30 days)
+// (GET /analytics/upsell-pipeline)
+func (c *Client) GetUpsellPipeline(ctx context.Context, from, to string) (*UpsellPipeline, error) {
+	q := url.Values{}
+	if from != "" {
+		q.Set("from", from)
+	}
+	if to != "" {
+		q.Set("to", to)
+	}
+	var out UpsellPipeline
+	if err := c.do(ctx, http.MethodGet, "/analytics/upsell-pipeline", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// IssueFilter selects tracked issues for ListIssues
+type IssueFilter struct {
+	GluserID string
+	Bucket   string
+	Status   string
+	Severity string
+	Cursor   string // NextCursor of the previous page
+	Limit    int
+}
+
+func (f IssueFilter) query() url.Values {
+	q := url.Values{}
+	if f.GluserID != "" {
+		q.Set("gluser_id", f.GluserID)
+	}
+	if f.Bucket != "" {
+		q.Set("bucket", f.Bucket)
+	}
+	if f.Status != "" {
+		q.Set("status", f.Status)
+	}
+	if f.Severity != "" {
+		q.Set("severity", f.Severity)
+	}
+	if f.Cursor != "" {
+		q.Set("cursor", f.Cursor)
+	}
+	if f.Limit > 0 {
+		q.Set("limit", strconv.Itoa(f.Limit))
+	}
+	return q
+}
+
+// ListIssues returns one page of tracked issues across sellers (GET /issues)
+func (c *Client) ListIssues(ctx context.Context, f IssueFilter) (*IssuePage, error) {
+	var out IssuePage
+	if err := c.do(ctx, http.MethodGet, "/issues", f.query(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CommitmentFilter selects commitments for ListCommitments
+type CommitmentFilter struct {
+	GluserID string
+	AgentID  string
+	Status   string // open, kept, broken
+	Limit    int
+}
+
+// ListCommitments returns the newest commitments agents made sellers, with
+// broken counts per agent and per seller (GET /commitments)
+func (c *Client) ListCommitments(ctx context.Context, f CommitmentFilter) (*CommitmentsReport, error) {
+	q := url.Values{}
+	if f.GluserID != "" {
+		q.Set("gluser_id", f.GluserID)
+	}
+	if f.AgentID != "" {
+		q.Set("agent_id", f.AgentID)
+	}
+	if f.Status != "" {
+		q.Set("status", f.Status)
+	}
+	if f.Limit > 0 {
+		q.Set("limit", strconv.Itoa(f.Limit))
+	}
+	var out CommitmentsReport
+	if err := c.do(ctx, http.MethodGet, "/commitments", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// PitchFilter selects upsell pitches for ListUpsellPitches
+type PitchFilter struct {
+	GluserID string
+	AgentID  string
+	Status   string // open, converted, lapsed
+	Limit    int
+}
+
+// ListUpsellPitches returns the newest upsell pitches agents made sellers,
+// with conversions per agent, seller response and product (GET /upsell-pitches)
+func (c *Client) ListUpsellPitches(ctx context.Context, f PitchFilter) (*UpsellPitchReport, error) {
+	q := url.Values{}
+	if f.GluserID != "" {
+		q.Set("gluser_id", f.GluserID)
+	}
+	if f.AgentID != "" {
+		q.Set("agent_id", f.AgentID)
+	}
+	if f.Status != "" {
+		q.Set("status", f.Status)
+	}
+	if f.Limit > 0 {
+		q.Set("limit", strconv.Itoa(f.Limit))
+	}
+	var out UpsellPitchReport
+	if err := c.do(ctx, http.MethodGet, "/upsell-pitches", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// QuoteFilter selects seller quotes for ListQuotes
+type QuoteFilter struct {
+	Bucket    string
+	Sentiment string // Positive, Neutral, Negative
+	GluserID  string
+	From      string // YYYY-MM-DD, default 89 days before To
+	To        string // YYYY-MM-DD, default today
+	Limit     int
+}
+
+// ListQuotes returns redacted seller quotes, one per seller and bucket, most
+// severe and newest first (GET /quotes)
+func (c *Client) ListQuotes(ctx context.Context, f QuoteFilter) (*QuoteLibrary, error) {
+	q := url.Values{}
+	if f.Bucket != "" {
+		q.Set("bucket", f.Bucket)
+	}
+	if f.Sentiment != "" {
+		q.Set("sentiment", f.Sentiment)
+	}
+	if f.GluserID != "" {
+		q.Set("gluser_id", f.GluserID)
+	}
+	if f.From != "" {
+		q.Set("from", f.From)
+	}
+	if f.To != "" {
+		q.Set("to", f.To)
+	}
+	if f.Limit > 0 {
+		q.Set("limit", strconv.Itoa(f.Limit))
+	}
+	var out QuoteLibrary
+	if err := c.do(ctx, http.MethodGet, "/quotes", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// OptOutOfQuotes records that a seller withdrew consent to be quoted,
+// deleting their quotes (POST /quotes/opt-outs)
+func (c *Client) OptOutOfQuotes(ctx context.Context, in QuoteOptOutRequest) (*QuoteOptOut, error) {
+	var out QuoteOptOut
+	if err := c.do(ctx, http.MethodPost, "/quotes/opt-outs", nil, in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SampleFilter selects call samples for ListSamples
+type SampleFilter struct {
+	Bucket    string
+	Sentiment string // Positive, Neutral, Negative
+	Status    string // Default approved; pending and rejected need the transcripts scope
+	Limit     int
+}
+
+// ListSamples returns anonymized example calls, newest first (GET /samples)
+func (c *Client) ListSamples(ctx context.Context, f SampleFilter) (*SampleList, error) {
+	q := url.Values{}
+	if f.Bucket != "" {
+		q.Set("bucket", f.Bucket)
+	}
+	if f.Sentiment != "" {
+		q.Set("sentiment", f.Sentiment)
+	}
+	if f.Status != "" {
+		q.Set("status", f.Status)
+	}
+	if f.Limit > 0 {
+		q.Set("limit", strconv.Itoa(f.Limit))
+	}
+	var out SampleList
+	if err := c.do(ctx, http.MethodGet, "/samples", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateSample generates a pending anonymized sample from a call
+// (POST /samples)
+func (c *Client) CreateSample(ctx context.Context, in SampleRequest) (*CallSample, error) {
+	var out CallSample
+	if err := c.do(ctx, http.MethodPost, "/samples", nil, in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ReviewSample approves or rejects a sample (PATCH /samples/{id})
+func (c *Client) ReviewSample(ctx context.Context, id string, in SampleReview) (*CallSample, error) {
+	var out CallSample
+	if err := c.do(ctx, http.MethodPatch, "/samples/"+url.PathEscape(id), nil, in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteSample removes a sample (DELETE /samples/{id})
+func (c *Client) DeleteSample(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, "/samples/"+url.PathEscape(id), nil, nil, nil)
+}
+
+// Reclassify moves stored analyses, tracked issues, aggregates and tickets
+// from retired buckets to the current taxonomy, or reports what it would
+// move with DryRun (POST /admin/reclassifications). It waits for the run.
+func (c *Client) Reclassify(ctx context.Context, in ReclassifyRequest) (*Reclassification, error) {
+	var out Reclassification
+	if err := c.do(ctx, http.MethodPost, "/admin/reclassifications", nil, in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListReclassifications returns the taxonomy migration runs, newest first
+// (GET /admin/reclassifications)
+func (c *Client) ListReclassifications(ctx context.Context) ([]Reclassification, error) {
+	var out struct {
+		Reclassifications []Reclassification `json:"reclassifications"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/admin/reclassifications", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Reclassifications, nil
+}
+
+// GetReclassification returns a run with up to limit of the records it
+// moved of a kind, all kinds when empty (GET /admin/reclassifications/{id})
+func (c *Client) GetReclassification(ctx context.Context, id, kind string, limit int) (*Reclassification, error) {
+	q := url.Values{}
+	if kind != "" {
+		q.Set("kind", kind)
+	}
+	if limit > 0 {
+		q.Set("limit", strconv.Itoa(limit))
+	}
+	var out Reclassification
+	if err := c.do(ctx, http.MethodGet, "/admin/reclassifications/"+url.PathEscape(id), q, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AnnotateCall anchors a QA reviewer's comment to a call's transcript
+// (POST /calls/{id}/annotations)
+func (c *Client) AnnotateCall(ctx context.Context, callID string, in AnnotationRequest) (*Annotation, error) {
+	var out Annotation
+	if err := c.do(ctx, http.MethodPost, "/calls/"+url.PathEscape(callID)+"/annotations", nil, in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ChatAboutCall asks Gemini a question about a call, in a new session when
+// in has no session_id (POST /calls/{id}/chat). The answer is the last of
+// the session's messages. Needs the transcripts scope.
+func (c *Client) ChatAboutCall(ctx context.Context, callID string, in CallChatRequest) (*CallChat, error) {
+	var out CallChat
+	if err := c.do(ctx, http.MethodPost, "/calls/"+url.PathEscape(callID)+"/chat", nil, in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetCallChat fetches a session opened with the same API key
+// (GET /calls/{id}/chat/{session_id}). Needs the transcripts scope.
+func (c *Client) GetCallChat(ctx context.Context, callID, sessionID string) (*CallChat, error) {
+	var out CallChat
+	if err := c.do(ctx, http.MethodGet, "/calls/"+url.PathEscape(callID)+"/chat/"+url.PathEscape(sessionID), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CallVersions returns a call's current analysis version and the analyses
+// it replaced when its transcript was rewritten (GET /calls/{id}/versions)
+func (c *Client) CallVersions(ctx context.Context, callID string) (*CallVersions, error) {
+	var out CallVersions
+	if err := c.do(ctx, http.MethodGet, "/calls/"+url.PathEscape(callID)+"/versions", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetCallShadow returns the candidate's analysis of a shadowed call and how
+// it compares with the primary (GET /calls/{id}/shadow)
+func (c *Client) GetCallShadow(ctx context.Context, callID string) (*ShadowAnalysis, error) {
+	var out ShadowAnalysis
+	if err := c.do(ctx, http.MethodGet, "/calls/"+url.PathEscape(callID)+"/shadow", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetShadowReport compares the candidate's analyses of calls between from
+// and to (YYYY-MM-DD, inclusive, empty for the last 7 days) with the primary
+// ones. An empty candidate is the one being shadowed now. (GET /shadow/report)
+func (c *Client) GetShadowReport(ctx context.Context, from, to, candidate string) (*ShadowReport, error) {
+	q := url.Values{}
+	if from != "" {
+		q.Set("from", from)
+	}
+	if to != "" {
+		q.Set("to", to)
+	}
+	if candidate != "" {
+		q.Set("candidate", candidate)
+	}
+	var out ShadowReport
+	if err := c.do(ctx, http.MethodGet, "/shadow/report", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListCallAnnotations returns a call's annotations, newest first (GET /calls/{id}/annotations)
+func (c *Client) ListCallAnnotations(ctx context.Context, callID string) ([]Annotation, error) {
+	var out []Annotation
+	if err := c.do(ctx, http.MethodGet, "/calls/"+url.PathEscape(callID)+"/annotations", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DeleteAnnotation removes one of a call's annotations (DELETE /calls/{id}/annotations/{annotation_id})
+func (c *Client) DeleteAnnotation(ctx context.Context, callID, id string) error {
+	return c.do(ctx, http.MethodDelete, "/calls/"+url.PathEscape(callID)+"/annotations/"+url.PathEscape(id), nil, nil, nil)
+}
+
+// AnnotationFilter selects annotations for ListAnnotations
+type AnnotationFilter struct {
+	GluserID string
+	AgentID  string
+	Category string
+	Limit    int
+}
+
+// ListAnnotations returns the newest annotations, with a coaching rollup
+// per agent over every match (GET /annotations)
+func (c *Client) ListAnnotations(ctx context.Context, f AnnotationFilter) (*AnnotationsReport, error) {
+	q := url.Values{}
+	if f.GluserID != "" {
+		q.Set("gluser_id", f.GluserID)
+	}
+	if f.AgentID != "" {
+		q.Set("agent_id", f.AgentID)
+	}
+	if f.Category != "" {
+		q.Set("category", f.Category)
+	}
+	if f.Limit > 0 {
+		q.Set("limit", strconv.Itoa(f.Limit))
+	}
+	var out AnnotationsReport
+	if err := c.do(ctx, http.MethodGet, "/annotations", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListSystemicIssues returns problems reported across sellers from the last
+// clustering run, most sellers first (GET /systemic-issues). bucket and
+// minSellers are optional filters.
+func (c *Client) ListSystemicIssues(ctx context.Context, bucket string, minSellers int) ([]SystemicIssue, error) {
+	q := url.Values{}
+	if bucket != "" {
+		q.Set("bucket", bucket)
+	}
+	if minSellers > 0 {
+		q.Set("min_sellers", strconv.Itoa(minSellers))
+	}
+	var out struct {
+		SystemicIssues []SystemicIssue `json:"systemic_issues"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/systemic-issues", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.SystemicIssues, nil
+}
+
+// RunSystemicClustering re-clusters open issues now (POST /systemic-issues/trigger)
+func (c *Client) RunSystemicClustering(ctx context.Context) ([]SystemicIssue, error) {
+	var out struct {
+		SystemicIssues []SystemicIssue `json:"systemic_issues"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/systemic-issues/trigger", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.SystemicIssues, nil
+}
+
+// GetInsightThemes returns the key insight themes of the week ending on date
+// (YYYY-MM-DD, empty for the latest week) (GET /analytics/themes)
+func (c *Client) GetInsightThemes(ctx context.Context, date string) (*ThemeReport, error) {
+	q := url.Values{}
+	if date != "" {
+		q.Set("date", date)
+	}
+	var out ThemeReport
+	if err := c.do(ctx, http.MethodGet, "/analytics/themes", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RunInsightThemes re-clusters the key insights of the week ending on date
+// (empty for yesterday) now (POST /analytics/themes/trigger)
+func (c *Client) RunInsightThemes(ctx context.Context, date string) (*ThemeReport, error) {
+	in := map[string]string{"date": date}
+	var out ThemeReport
+	if err := c.do(ctx, http.MethodPost, "/analytics/themes/trigger", nil, in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetSeverityCalibration returns the severity calibration run of date
+// (YYYY-MM-DD, empty for the latest run) (GET /analytics/severity-calibration)
+func (c *Client) GetSeverityCalibration(ctx context.Context, date string) (*SeverityCalibration, error) {
+	q := url.Values{}
+	if date != "" {
+		q.Set("date", date)
+	}
+	var out SeverityCalibration
+	if err := c.do(ctx, http.MethodGet, "/analytics/severity-calibration", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RunSeverityCalibration calibrates issue severities as of date (empty for
+// today) now (POST /analytics/severity-calibration/trigger)
+func (c *Client) RunSeverityCalibration(ctx context.Context, date string) (*SeverityCalibration, error) {
+	in := map[string]string{"date": date}
+	var out SeverityCalibration
+	if err := c.do(ctx, http.MethodPost, "/analytics/severity-calibration/trigger", nil, in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// EventFilter selects events for ListEvents and StreamEvents
+type EventFilter struct {
+	Type     string
+	GluserID string
+	CallID   string
+	Since    time.Time
+	Cursor   string
+	Limit    int
+}
+
+func (f EventFilter) query() url.Values {
+	q := url.Values{}
+	if f.Type != "" {
+		q.Set("type", f.Type)
+	}
+	if f.GluserID != "" {
+		q.Set("gluser_id", f.GluserID)
+	}
+	if f.CallID != "" {
+		q.Set("call_id", f.CallID)
+	}
+	if !f.Since.IsZero() {
+		q.Set("since", f.Since.Format(time.RFC3339Nano))
+	}
+	if f.Cursor != "" {
+		q.Set("cursor", f.Cursor)
+	}
+	if f.Limit > 0 {
+		q.Set("limit", strconv.Itoa(f.Limit))
+	}
+	return q
+}
+
+// ListEvents returns one page of the event log (GET /events)
+func (c *Client) ListEvents(ctx context.Context, f EventFilter) (*EventPage, error) {
+	var out EventPage
+	if err := c.do(ctx, http.MethodGet, "/events", f.query(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// StreamEvents delivers matching events in order until ctx is cancelled,
+// polling the event log every pollInterval (default 2s) once caught up.
+// Transient errors are retried; the events channel is closed on return.
+func (c *Client) StreamEvents(ctx context.Context, f EventFilter, pollInterval time.Duration) <-chan Event {
+	if pollInterval <= 0 {
+		pollInterval = 2 * time.Second
+	}
+	events := make(chan Event)
+
+	go func() {
+		defer close(events)
+		for {
+			page, err := c.ListEvents(ctx, f)
+			if err == nil {
+				for _, e := range page.Events {
+					select {
+					case events <- e:
+					case <-ctx.Done():
+						return
+					}
+				}
+				if page.NextCursor != "" {
+					f.Cursor = page.NextCursor
+				}
+				if page.HasMore {
+					continue
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(pollInterval):
+			}
+		}
+	}()
+
+	return events
+}
+
+// stream sends a GET asking for NDJSON and calls fn with each line of the
+// response as it arrives. An error line from the server ends the stream.
+func (c *Client) stream(ctx context.Context, path string, query url.Values, fn func(line []byte) error) error {
+	pr, pw := io.Pipe()
+	ctx, cancel := context.WithCancel(context.WithValue(ctx, acceptCtx{}, "application/x-ndjson"))
+	defer cancel()
+
+	errc := make(chan error, 1)
+	go func() {
+		err := c.do(ctx, http.MethodGet, path, query, nil, pw)
+		pw.CloseWithError(err)
+		errc <- err
+	}()
+
+	dec := json.NewDecoder(pr)
+	for {
+		var line json.RawMessage
+		err := dec.Decode(&line)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			cancel()
+			pr.CloseWithError(err)
+			<-errc
+			return err
+		}
+		var streamErr struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(line, &streamErr) == nil && streamErr.Error != "" {
+			err = fmt.Errorf("stream failed: %s", streamErr.Error)
+		} else {
+			err = fn(line)
+		}
+		if err != nil {
+			cancel()
+			pr.CloseWithError(err)
+			<-errc
+			return err
+		}
+	}
+	return <-errc
+}
+
+type acceptCtx struct{}
+
+// do sends a JSON request and decodes a JSON response into out
+// ndjsonBody is a request body sent as is, as application/x-ndjson
+type ndjsonBody []byte
+
+// csvBody is a request body sent as is, as text/csv
+type csvBody []byte
+
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, in, out interface{}) error {
+	if stage, ok := ctx.Value(journeyStageCtx{}).(string); ok && stage != "" && strings.HasPrefix(path, "/analytics/") {
+		query = maps.Clone(query)
+		if query == nil {
+			query = url.Values{}
+		}
+		query.Set("stage", stage)
+	}
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	var body io.Reader
+	contentType := "application/json"
+	if nd, ok := in.(ndjsonBody); ok {
+		body = bytes.NewReader(nd)
+		contentType = "application/x-ndjson"
+	} else if cb, ok := in.(csvBody); ok {
+		body = bytes.NewReader(cb)
+		contentType = "text/csv"
+	} else if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		body = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return err
+	}
+	for k, v := range c.header {
+		req.Header[k] = v
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if accept, ok := ctx.Value(acceptCtx{}).(string); ok {
+		req.Header.Set("Accept", accept)
+	} else {
+		req.Header.Set("Accept", "application/json")
+	}
+	if key, ok := ctx.Value(idempotencyKeyCtx{}).(string); ok && key != "" {
+		req.Header.Set("Idempotency-Key", key)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+		var problem struct {
+			Detail    string `json:"detail"`
+			Error     string `json:"error"`
+			Code      string `json:"code"`
+			RequestID string `json:"request_id"`
+		}
+		apiErr := &APIError{
+			StatusCode: resp.StatusCode,
+			Message:    strings.TrimSpace(string(b)),
+			RequestID:  resp.Header.Get("X-Request-ID"),
+		}
+		if json.Unmarshal(b, &problem) == nil {
+			apiErr.Code = problem.Code
+			if problem.Detail != "" {
+				apiErr.Message = problem.Detail
+			} else if problem.Error != "" {
+				apiErr.Message = problem.Error
+			}
+			if problem.RequestID != "" {
+				apiErr.RequestID = problem.RequestID
+			}
+		}
+		return apiErr
+	}
+
+	if out == nil {
+		return nil
+	}
+	if w, ok := out.(io.Writer); ok {
+		_, err := io.Copy(w, resp.Body)
+		return err
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package client
+
+import "time"
+
+// Commitment statuses
+const (
+	CommitmentOpen   = "open"
+	CommitmentKept   = "kept"
+	CommitmentBroken = "broken"
+)
+
+// ExtractedCommitment is a promise the agent made on a call, as extracted
+type ExtractedCommitment struct {
+	Promise string `json:"promise"`            // What the agent committed to, e.g. "call back with the refund status"
+	Kind    string `json:"kind"`               // callback, credit, refund, fix, visit, other
+	DueDate string `json:"due_date,omitempty"` // YYYY-MM-DD; empty when the agent gave no date
+	Quote   string `json:"quote,omitempty"`
+}
+
+// Commitment is a promise an agent made a seller, tracked until a later call
+// with the seller shows it kept or broken, or it goes past its due date
+type Commitment struct {
+	ID        string    `json:"id"` // <call_id>-<n>, so re-analyzing a call updates its commitments
+	GluserID  string    `json:"gluser_id"`
+	AgentID   string    `json:"agent_id,omitempty"`
+	CallID    string    `json:"call_id"`
+	CallTime  time.Time `json:"call_time"`
+	Promise   string    `json:"promise"`
+	Kind      string    `json:"kind"`
+	DueDate   string    `json:"due_date"`             // YYYY-MM-DD in the business timezone
+	DueStated bool      `json:"due_stated,omitempty"` // False when the agent gave no date and COMMITMENT_DUE_DAYS applied
+	Quote     string    `json:"quote,omitempty"`
+
+	Status         string     `json:"status"`                     // open, kept, broken
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty"`      // When it was found kept or broken
+	ResolvedCallID string     `json:"resolved_call_id,omitempty"` // The later call that showed it; empty when it went past due
+	Evidence       string     `json:"evidence,omitempty"`         // What that call said about it
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CommitmentTally counts one agent's or one seller's commitments
+type CommitmentTally struct {
+	AgentID    string  `json:"agent_id,omitempty"`
+	GluserID   string  `json:"gluser_id,omitempty"`
+	Total      int     `json:"total"`
+	Open       int     `json:"open"`
+	Kept       int     `json:"kept"`
+	Broken     int     `json:"broken"`
+	BrokenRate float64 `json:"broken_rate"` // Broken over settled (kept + broken)
+}
+
+// CommitmentsReport is the response of GET /commitments. The tallies cover
+// every commitment matching the filters, the list only the newest.
+type CommitmentsReport struct {
+	Commitments []Commitment      `json:"commitments"`
+	Count       int               `json:"count"`
+	Total       int               `json:"total"`
+	ByAgent     []CommitmentTally `json:"by_agent"`  // Most broken first
+	BySeller    []CommitmentTally `json:"by_seller"` // Most broken first
+	GeneratedAt time.Time         `json:"generated_at"`
+}
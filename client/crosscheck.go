@@ -0,0 +1,49 @@
+package client
+
+import "time"
+
+// RulesCheck is how the keyword classifier's reading of a call differs from
+// Gemini's analysis (AnalysisResult.RulesCheck). Only buckets the rules
+// cover are compared.
+type RulesCheck struct {
+	RulesSentiment    string   `json:"rules_sentiment"`
+	SentimentConflict bool     `json:"sentiment_conflict"`           // One side Positive and the other Negative
+	AgreedBuckets     []string `json:"agreed_buckets,omitempty"`     // Raised by both
+	LLMOnlyBuckets    []string `json:"llm_only_buckets,omitempty"`   // Raised by Gemini, not matched by the rules
+	RulesOnlyBuckets  []string `json:"rules_only_buckets,omitempty"` // Matched by the rules, not raised by Gemini
+	Disagrees         bool     `json:"disagrees"`                    // A sentiment conflict or a bucket only one side raised
+}
+
+// CrossCheckReport sums up the rules checks of the calls analyzed over a
+// date range (GET /analytics/cross-check). Rates are 0-1 over the checked
+// calls.
+type CrossCheckReport struct {
+	From                  string             `json:"from"`
+	To                    string             `json:"to"`
+	Checked               int                `json:"checked"` // Calls with a rules check
+	Disagreed             int                `json:"disagreed"`
+	DisagreementRate      float64            `json:"disagreement_rate"`
+	SentimentConflicts    int                `json:"sentiment_conflicts"`
+	SentimentConflictRate float64            `json:"sentiment_conflict_rate"`
+	Buckets               []CrossCheckBucket `json:"buckets"` // Most disagreement first
+	Days                  []CrossCheckDay    `json:"days"`
+	GeneratedAt           time.Time          `json:"generated_at"`
+}
+
+// CrossCheckBucket is how often the two sides agreed on a bucket
+type CrossCheckBucket struct {
+	Bucket    string  `json:"bucket"`
+	Agreed    int     `json:"agreed"`
+	LLMOnly   int     `json:"llm_only"`
+	RulesOnly int     `json:"rules_only"`
+	Agreement float64 `json:"agreement"` // Agreed over the calls either side raised it on (0-1)
+}
+
+// CrossCheckDay is one day's checked calls
+type CrossCheckDay struct {
+	Date               string  `json:"date"`
+	Checked            int     `json:"checked"`
+	Disagreed          int     `json:"disagreed"`
+	DisagreementRate   float64 `json:"disagreement_rate"`
+	SentimentConflicts int     `json:"sentiment_conflicts"`
+}
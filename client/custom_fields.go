@@ -0,0 +1,82 @@
+package client
+
+import (
+	"strconv"
+	"time"
+)
+
+// Records custom fields can be defined on
+const (
+	CustomFieldProfile = "profile" // Seller profiles, and their rows in GET /sellers
+	CustomFieldTicket  = "ticket"
+)
+
+// CustomFieldEntities lists the records custom fields can be defined on
+var CustomFieldEntities = []string{CustomFieldProfile, CustomFieldTicket}
+
+// Custom field types
+const (
+	CustomFieldString = "string"
+	CustomFieldNumber = "number"
+	CustomFieldBool   = "bool"
+	CustomFieldEnum   = "enum" // One of the definition's values
+	CustomFieldDate   = "date" // YYYY-MM-DD
+)
+
+// CustomFieldTypes lists the custom field types
+var CustomFieldTypes = []string{CustomFieldString, CustomFieldNumber, CustomFieldBool, CustomFieldEnum, CustomFieldDate}
+
+// CustomFieldDefinition is an operator-defined field on seller profiles or
+// tickets, set through their PATCH endpoints under custom_fields and
+// filtered on with cf.<name>=<value>
+type CustomFieldDefinition struct {
+	Entity      string    `json:"entity"` // profile or ticket
+	Name        string    `json:"name"`   // Lowercase letters, digits and underscores
+	Label       string    `json:"label,omitempty"`
+	Type        string    `json:"type"`             // string, number, bool, enum, date
+	Values      []string  `json:"values,omitempty"` // An enum's allowed values
+	Description string    `json:"description,omitempty"`
+	By          string    `json:"by,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// CustomFieldRequest is the body of PUT /admin/custom-fields/{entity}/{name}
+type CustomFieldRequest struct {
+	Label       string   `json:"label,omitempty"`
+	Type        string   `json:"type"`
+	Values      []string `json:"values,omitempty"` // Required for enums
+	Description string   `json:"description,omitempty"`
+	By          string   `json:"by,omitempty"` // Defaults to the API key's name
+}
+
+// CustomFieldsMatch reports whether fields has every filter's value. Numbers
+// compare by value whatever their Go type.
+func CustomFieldsMatch(fields, filters map[string]any) bool {
+	for name, want := range filters {
+		have, ok := fields[name]
+		if !ok || customFieldKey(have) != customFieldKey(want) {
+			return false
+		}
+	}
+	return true
+}
+
+func customFieldKey(v any) string {
+	switch v := v.(type) {
+	case string:
+		return "s:" + v
+	case bool:
+		return "b:" + strconv.FormatBool(v)
+	case float64:
+		return "n:" + strconv.FormatFloat(v, 'g', -1, 64)
+	case int:
+		return "n:" + strconv.FormatFloat(float64(v), 'g', -1, 64)
+	case int32:
+		return "n:" + strconv.FormatFloat(float64(v), 'g', -1, 64)
+	case int64:
+		return "n:" + strconv.FormatFloat(float64(v), 'g', -1, 64)
+	default:
+		return ""
+	}
+}
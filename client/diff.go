@@ -0,0 +1,24 @@
+package client
+
+// Churn changes between two calls
+const (
+	ChurnIncreased = "increased"
+	ChurnDecreased = "decreased"
+	ChurnUnchanged = "unchanged"
+)
+
+// CallDiff compares two analyzed calls of one seller, from call A to call B
+// (GET /sellers/{gluser_id}/diff)
+type CallDiff struct {
+	GluserID          string       `json:"gluser_id"`
+	CallA             CallListItem `json:"call_a"`
+	CallB             CallListItem `json:"call_b"`
+	IssuesGained      []Issue      `json:"issues_gained"`      // Issues in buckets B raised and A didn't
+	IssuesLost        []Issue      `json:"issues_lost"`        // Issues in buckets A raised and B didn't
+	IssuesPersisting  []string     `json:"issues_persisting"`  // Buckets both calls raised
+	SentimentDelta    float64      `json:"sentiment_delta"`    // B - A, sentiment on the 0-1 trend scale
+	SatisfactionDelta int          `json:"satisfaction_delta"` // B - A
+	ChurnChange       string       `json:"churn_change"`       // increased, decreased, unchanged
+	Narrative         string       `json:"narrative,omitempty"`
+	NarrativeError    string       `json:"narrative_error,omitempty"` // Set when the LLM narrative failed
+}
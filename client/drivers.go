@@ -0,0 +1,28 @@
+package client
+
+import "time"
+
+// SatisfactionDriverReport ranks what goes with low satisfaction over a date
+// range (GET /analytics/drivers). Only calls with a satisfaction score count.
+type SatisfactionDriverReport struct {
+	From             string               `json:"from"`
+	To               string               `json:"to"`
+	Calls            int                  `json:"calls"`
+	AvgSatisfaction  float64              `json:"avg_satisfaction"`  // 1-10
+	DissatisfiedRate float64              `json:"dissatisfied_rate"` // Share of calls scored at or below the dissatisfied threshold (0-1)
+	Drivers          []SatisfactionFactor `json:"drivers"`           // Factors below average with enough calls, biggest impact first
+	Factors          []SatisfactionFactor `json:"factors"`           // Every factor, by dimension then call count
+	GeneratedAt      time.Time            `json:"generated_at"`
+}
+
+// SatisfactionFactor is the satisfaction of calls sharing one value of a dimension
+type SatisfactionFactor struct {
+	Dimension        string  `json:"dimension"` // bucket, agent_performance, resolution, customer_type
+	Value            string  `json:"value"`
+	Calls            int     `json:"calls"`
+	AvgSatisfaction  float64 `json:"avg_satisfaction"`
+	Delta            float64 `json:"delta"` // AvgSatisfaction minus the overall average
+	Dissatisfied     int     `json:"dissatisfied"`
+	DissatisfiedRate float64 `json:"dissatisfied_rate"`
+	Impact           float64 `json:"impact"` // Points of overall average satisfaction lost to the factor
+}
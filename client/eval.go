@@ -0,0 +1,103 @@
+package client
+
+import "time"
+
+// What started an eval run (EvalRun.Trigger)
+const (
+	EvalTriggerWeekly        = "weekly"         // A week since the last run
+	EvalTriggerVersionChange = "version_change" // A model or prompt version analyzed calls the last run didn't see
+	EvalTriggerManual        = "manual"         // POST /admin/eval/run
+)
+
+// How a version made its analyses (EvalVersion.Role)
+const (
+	EvalPrimary = "primary" // Production analyses outside any rollout
+	EvalCanary  = "canary"  // A canary rollout's candidate
+	EvalShadow  = "shadow"  // A shadow candidate, compared call by call with the primary
+)
+
+// EvalVersionUnrecorded is the version of analyses recorded before analyzed
+// events named their model and prompt version
+const EvalVersionUnrecorded = "unrecorded"
+
+// EvalRun is one scheduled measurement of every model and prompt version
+// that analyzed calls From to To. Primary and canary versions are summed up
+// from their analyzed events, as rollouts are judged; shadow candidates by
+// their agreement with the primary.
+type EvalRun struct {
+	ID        string        `json:"id"`
+	Trigger   string        `json:"trigger"` // weekly, version_change, manual
+	From      string        `json:"from"`
+	To        string        `json:"to"`
+	Versions  []EvalVersion `json:"versions"`
+	Drift     []string      `json:"drift,omitempty"` // Primary versions' metrics that moved past the rollout or accuracy thresholds since their last run
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+// EvalVersion is one version's metrics in a run. A version is the model and
+// prompt version of Gemini analyses (e.g. "gemini-2.0-flash@3f9a2c1b0d4e"),
+// or a shadow candidate's label.
+type EvalVersion struct {
+	Version  string         `json:"version"`
+	Role     string         `json:"role"`               // primary, canary, shadow
+	Rollout  string         `json:"rollout,omitempty"`  // Canary rollout's ID
+	Arm      *RolloutArm    `json:"arm,omitempty"`      // Primary and canary versions
+	Shadow   *EvalAgreement `json:"shadow,omitempty"`   // Shadow candidates
+	Accuracy *EvalAccuracy  `json:"accuracy,omitempty"` // Versions running when the run was made, with approved samples
+}
+
+// EvalAccuracy is how a version's analyses of the approved call samples'
+// transcripts compare with the reviewed analyses kept on the samples
+type EvalAccuracy struct {
+	Samples                  int     `json:"samples"`
+	Failed                   int     `json:"failed"` // Errored, blocked or unparseable analyses
+	Scored                   int     `json:"scored"`
+	SentimentAccuracy        float64 `json:"sentiment_accuracy"`
+	ChurnAccuracy            float64 `json:"churn_accuracy"`
+	AgentPerformanceAccuracy float64 `json:"agent_performance_accuracy"`
+	BucketPrecision          float64 `json:"bucket_precision"` // Share of the buckets found that the samples have
+	BucketRecall             float64 `json:"bucket_recall"`    // Share of the samples' buckets found
+	MeanAbsSatisfactionError float64 `json:"mean_abs_satisfaction_error"`
+}
+
+// EvalAgreement is how often a shadow candidate agreed with the primary on
+// the calls both analyzed, as in GET /admin/shadow/report
+type EvalAgreement struct {
+	Calls                     int     `json:"calls"`
+	Failed                    int     `json:"failed"`
+	Compared                  int     `json:"compared"`
+	SentimentAgreement        float64 `json:"sentiment_agreement"`
+	ChurnAgreement            float64 `json:"churn_agreement"`
+	AgentPerformanceAgreement float64 `json:"agent_performance_agreement"`
+	EscalationAgreement       float64 `json:"escalation_agreement"`
+	MeanAbsSatisfactionDelta  float64 `json:"mean_abs_satisfaction_delta"`
+	MeanBucketOverlap         float64 `json:"mean_bucket_overlap"`
+}
+
+// EvalHistory is the response of GET /admin/eval/history: the runs created
+// From to To, and each version's metrics across them for charting
+type EvalHistory struct {
+	From   string       `json:"from"`
+	To     string       `json:"to"`
+	Runs   []EvalRun    `json:"runs"`   // Newest first
+	Series []EvalSeries `json:"series"` // By version and role
+}
+
+// EvalSeries is one version's metrics run by run, oldest first
+type EvalSeries struct {
+	Version string      `json:"version"`
+	Role    string      `json:"role"`
+	Rollout string      `json:"rollout,omitempty"`
+	Points  []EvalPoint `json:"points"`
+}
+
+// EvalPoint is a version's metrics in one run
+type EvalPoint struct {
+	RunID     string         `json:"run_id"`
+	From      string         `json:"from"`
+	To        string         `json:"to"`
+	Arm       *RolloutArm    `json:"arm,omitempty"`
+	Shadow    *EvalAgreement `json:"shadow,omitempty"`
+	Accuracy  *EvalAccuracy  `json:"accuracy,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+}
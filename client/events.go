@@ -0,0 +1,129 @@
+package client
+
+import "time"
+
+// Event types
+const (
+	EventIngested           = "ingested"
+	EventAnalyzed           = "analyzed"
+	EventProfileUpdated     = "profile_updated"
+	EventTicketCreated      = "ticket_created"
+	EventAlertFired         = "alert_fired"
+	EventAnalysisFailed     = "analysis_failed"
+	EventContactAttempt     = "contact_attempt"
+	EventTranscriptReceived = "transcript_received"
+)
+
+// Event is a single entry in the activity stream
+type Event struct {
+	EventID   string      `json:"event_id"`
+	Type      string      `json:"type"`
+	Timestamp time.Time   `json:"timestamp"`
+	CallID    string      `json:"call_id,omitempty"`
+	GluserID  string      `json:"gluser_id,omitempty"`
+	TicketID  string      `json:"ticket_id,omitempty"`
+	Payload   interface{} `json:"payload,omitempty"`
+}
+
+// IngestedPayload is the payload of an "ingested" event
+type IngestedPayload struct {
+	Source     string      `json:"source"` // api, watcher
+	Language   string      `json:"language,omitempty"`
+	DurationMS int         `json:"duration_ms,omitempty"`
+	Origin     *CallOrigin `json:"origin,omitempty"` // The watched source's system and region
+}
+
+// AnalyzedPayload is the payload of an "analyzed" event
+type AnalyzedPayload struct {
+	Sentiment         string    `json:"sentiment"`
+	SatisfactionScore int       `json:"satisfaction_score"`
+	ChurnRisk         string    `json:"churn_risk"`
+	IssueCount        int       `json:"issue_count"`
+	Buckets           []string  `json:"buckets,omitempty"`
+	UpsellOpportunity bool      `json:"upsell_opportunity"`
+	ParseFailed       bool      `json:"parse_failed,omitempty"`   // The LLM response couldn't be parsed
+	Provisional       bool      `json:"provisional,omitempty"`    // By the keyword classifier, Gemini being unavailable
+	Rollout           string    `json:"rollout,omitempty"`        // Canary rollout whose candidate made the analysis
+	Engine            string    `json:"engine,omitempty"`         // EngineRules for the keyword classifier
+	Model             string    `json:"model,omitempty"`          // Gemini model that made the analysis
+	PromptVersion     string    `json:"prompt_version,omitempty"` // Fingerprint of its prompts
+	LLMUsage          *LLMUsage `json:"llm_usage,omitempty"`
+}
+
+// AnalysisFailedPayload is the payload of an "analysis_failed" event. The
+// watcher records a transcript's first failed attempt and the one it gives
+// up after, not every retry.
+type AnalysisFailedPayload struct {
+	Source      string      `json:"source"`         // api, watcher
+	File        string      `json:"file,omitempty"` // The watched file's ID
+	Stage       string      `json:"stage"`          // read, parse, contact_attempt, analysis
+	Error       string      `json:"error"`
+	Attempts    int         `json:"attempts"`
+	Quarantined bool        `json:"quarantined,omitempty"` // Moved to FAILED_DIR
+	Origin      *CallOrigin `json:"origin,omitempty"`      // The watched source's system and region
+}
+
+// ContactAttemptPayload is the payload of a "contact_attempt" event, a missed
+// or zero-length call recorded without analysis
+type ContactAttemptPayload struct {
+	Source     string      `json:"source"` // watcher
+	Reason     string      `json:"reason"` // missed, zero_duration
+	Direction  string      `json:"direction,omitempty"`
+	Status     string      `json:"status,omitempty"`
+	RawStatus  string      `json:"raw_status,omitempty"` // call_status as the source spelled it
+	OnTimeline bool        `json:"on_timeline"`          // Added to the seller's profile; sellers without one get only the event
+	Origin     *CallOrigin `json:"origin,omitempty"`
+}
+
+// TranscriptReceivedPayload is the payload of a "transcript_received" event, the
+// quality of a transcript as it arrived, before anything is skipped or
+// analyzed (GET /admin/data-quality). The watcher records a file once, not
+// on every retry.
+type TranscriptReceivedPayload struct {
+	Source          string      `json:"source"` // api, watcher
+	Origin          *CallOrigin `json:"origin,omitempty"`
+	Revised         bool        `json:"revised,omitempty"`          // A new version of a transcript received before
+	MissingFields   []string    `json:"missing_fields,omitempty"`   // Expected fields left empty
+	EmptyTranscript bool        `json:"empty_transcript,omitempty"` // No transcript text
+	UnparseableDate bool        `json:"unparseable_date,omitempty"` // call_entered_on in no known format
+	TranscriptChars int         `json:"transcript_chars"`
+	Checksum        string      `json:"transcript_checksum,omitempty"` // Of the transcript text, to spot the same call under another ID
+}
+
+// ProfileUpdatedPayload is the payload of a "profile_updated" event
+type ProfileUpdatedPayload struct {
+	PreviousHealthScore int    `json:"previous_health_score"`
+	HealthScore         int    `json:"health_score"`
+	HealthLabel         string `json:"health_label"`
+	ChurnRisk           string `json:"churn_risk"`
+	OpenIssues          int    `json:"open_issues"`
+	IssuesResolved      int    `json:"issues_resolved"`
+	NeedsAttention      bool   `json:"needs_attention"`
+	TotalCalls          int    `json:"total_calls"`
+}
+
+// TicketCreatedPayload is the payload of a "ticket_created" event
+type TicketCreatedPayload struct {
+	Date          string `json:"date"`
+	FeatureBucket string `json:"feature_bucket"`
+	Priority      int    `json:"priority"`
+	Severity      string `json:"severity"`
+	AffectedCount int    `json:"affected_count"`
+	OwnerTeam     string `json:"owner_team,omitempty"` // The team owning the bucket, if any
+}
+
+// AlertFiredPayload is the payload of an "alert_fired" event
+type AlertFiredPayload struct {
+	AlertID   string `json:"alert_id"`
+	AlertType string `json:"alert_type"`
+	Severity  string `json:"severity"`
+	Message   string `json:"message"`
+}
+
+// EventPage is one page of events
+type EventPage struct {
+	Events     []Event `json:"events"`
+	Count      int     `json:"count"`
+	NextCursor string  `json:"next_cursor,omitempty"`
+	HasMore    bool    `json:"has_more"`
+}
@@ -0,0 +1,69 @@
+package client
+
+import "time"
+
+// CallExtraction is the first analysis pass: what the call said, without
+// judgments about the seller. The scoring pass turns it into churn, upsell
+// and satisfaction scores, and can be re-run on it without the transcript.
+type CallExtraction struct {
+	CallID           string            `json:"call_id"`
+	SellerID         string            `json:"seller_id"`
+	Timestamp        time.Time         `json:"timestamp"`
+	TranscriptEn     string            `json:"transcript_en"`
+	CallSummary      string            `json:"call_summary"`
+	Issues           []Issue           `json:"issues"`
+	Sentiment        string            `json:"sentiment"` // Positive, Neutral, Negative
+	PromptResolution bool              `json:"prompt_resolution"`
+	AgentPerformance string            `json:"agent_performance,omitempty"` // Good, Average, Poor
+	Facts            CallFacts         `json:"facts"`
+	KeyInsights      []string          `json:"key_insights,omitempty"`
+	VerticalPrompt   string            `json:"vertical_prompt,omitempty"` // Prompt registry block used, if any
+	Acoustic         *AcousticSignals  `json:"acoustic,omitempty"`
+	SafetyBlock      *SafetyBlock      `json:"safety_block,omitempty"` // Set when the extraction comes from a redacted retry
+	PromptBudget     *PromptBudget     `json:"prompt_budget,omitempty"`
+	TranslationCheck *TranslationCheck `json:"translation_check,omitempty"` // Set when transcript_en failed the translation check
+	ExtractedAt      time.Time         `json:"extracted_at"`
+}
+
+// CallFacts are the churn and upsell signals stated in a call
+type CallFacts struct {
+	CancellationThreatened bool     `json:"cancellation_threatened"` // Said they'd stop, not renew or want their money back
+	RenewalDiscussed       bool     `json:"renewal_discussed"`
+	RefundRequested        bool     `json:"refund_requested"`
+	PricingComplaint       bool     `json:"pricing_complaint"`
+	CompetitorsMentioned   []string `json:"competitors_mentioned,omitempty"`
+	RequestedFeatures      []string `json:"requested_features,omitempty"` // IndiaMART products or features asked about
+	BudgetSignals          string   `json:"budget_signals,omitempty"`     // What was said about spending more or less
+	GrowthSignals          string   `json:"growth_signals,omitempty"`     // New products, cities, capacity, hiring
+	EscalationRequested    bool     `json:"escalation_requested"`
+	FollowUpPromised       bool     `json:"follow_up_promised"`
+	Quotes                 []string `json:"quotes,omitempty"` // Short quotes backing the facts above
+
+	PriceMentions  []PriceMention  `json:"price_mentions,omitempty"`
+	BillingDispute *BillingDispute `json:"billing_dispute,omitempty"` // Set when the seller was billed an amount other than agreed
+
+	Commitments []ExtractedCommitment `json:"commitments,omitempty"`  // Promises the agent made, tracked at /commitments
+	UpsellPitch *UpsellPitch          `json:"upsell_pitch,omitempty"` // Set when the agent pitched an upsell, tracked at /upsell-pitches
+}
+
+// PriceMention is an amount of money said on a call, checked against the
+// plan catalog when it's for a catalog product
+type PriceMention struct {
+	Amount       float64 `json:"amount"`            // Rs
+	Product      string  `json:"product,omitempty"` // As said
+	SKU          string  `json:"sku,omitempty"`     // Catalog product it maps to
+	Kind         string  `json:"kind,omitempty"`    // paid, charged, quoted, refund, other
+	Period       string  `json:"period,omitempty"`  // month, year, multi_year, one_time; empty when not said
+	Quote        string  `json:"quote,omitempty"`
+	CatalogPrice int     `json:"catalog_price,omitempty"` // The SKU's annual list price, for annual amounts
+	OffCatalog   bool    `json:"off_catalog,omitempty"`   // Amount is more than 10% off CatalogPrice
+}
+
+// BillingDispute is a seller charged a different amount than they agreed to or were quoted
+type BillingDispute struct {
+	Product      string  `json:"product,omitempty"`
+	SKU          string  `json:"sku,omitempty"`
+	Expected     float64 `json:"expected"` // Rs agreed or quoted
+	Charged      float64 `json:"charged"`  // Rs billed or debited
+	CatalogPrice int     `json:"catalog_price,omitempty"`
+}
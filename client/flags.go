@@ -0,0 +1,44 @@
+package client
+
+import "time"
+
+// Feature flags gating capabilities per call
+const (
+	FlagKBRetrieval    = "kb_retrieval"    // Knowledge base passages matched by embedding ground the prompts
+	FlagSeverityHints  = "severity_hints"  // Severity calibration hints in the extraction prompt
+	FlagFollowUpDrafts = "followup_drafts" // The scoring pass drafts a follow-up message
+	FlagShadow         = "shadow"          // Sampled calls are also analyzed by the shadow candidate
+)
+
+// FeatureFlags lists the flags, in the order they're listed
+var FeatureFlags = []string{FlagKBRetrieval, FlagSeverityHints, FlagFollowUpDrafts, FlagShadow}
+
+// Where a flag's setting comes from
+const (
+	FlagSourceDefault = "default" // On for every call
+	FlagSourceEnv     = "env"     // FEATURE_FLAGS
+	FlagSourceStored  = "stored"  // Set through PUT /admin/flags/{name}
+)
+
+// FeatureFlag gates a capability per call. A disabled flag is off for
+// every call. An enabled one is on for calls from the listed origins and
+// for Percent of the rest, picked by a hash of the flag name and call ID.
+// A capability still needs its own configuration (a knowledge base,
+// SEVERITY_CALIBRATION_HINTS, FOLLOWUP_DRAFTS, SHADOW_PERCENT) to run.
+type FeatureFlag struct {
+	Name      string    `json:"name"`
+	Enabled   bool      `json:"enabled"`
+	Percent   int       `json:"percent"`           // Share of calls (0-100) the flag is on for
+	Origins   []string  `json:"origins,omitempty"` // Source systems ("crm") or systems and regions ("crm:north") always on
+	Source    string    `json:"source"`            // default, env or stored
+	By        string    `json:"by,omitempty"`
+	UpdatedAt time.Time `json:"updated_at,omitempty"`
+}
+
+// FeatureFlagRequest is the body of PUT /admin/flags/{name}
+type FeatureFlagRequest struct {
+	Enabled bool     `json:"enabled"`
+	Percent *int     `json:"percent,omitempty"` // 100 if omitted
+	Origins []string `json:"origins,omitempty"`
+	By      string   `json:"by,omitempty"` // Defaults to the API key's name
+}
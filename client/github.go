@@ -0,0 +1,20 @@
+package client
+
+import "time"
+
+// GitHubIssue links a feature bucket to the GitHub issue tracking it. One
+// issue follows a bucket across days: later tickets for the bucket update
+// and comment on it rather than opening another.
+type GitHubIssue struct {
+	FeatureBucket string    `json:"feature_bucket"`
+	Repo          string    `json:"repo"` // org/repo
+	Number        int       `json:"number"`
+	URL           string    `json:"url"`
+	State         string    `json:"state"`     // open or closed
+	TicketID      string    `json:"ticket_id"` // Latest ticket projected onto the issue
+	Date          string    `json:"date"`      // That ticket's date
+	Count         int       `json:"count"`     // That ticket's issue count when last synced
+	Sellers       int       `json:"sellers"`   // Distinct sellers when last synced
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
@@ -0,0 +1,52 @@
+package client
+
+import "time"
+
+// Ingest job statuses (IngestJob.Status)
+const (
+	IngestJobQueued    = "queued"
+	IngestJobRunning   = "running"
+	IngestJobCompleted = "completed"
+	IngestJobFailed    = "failed"
+)
+
+// Callback delivery statuses (IngestCallbackDelivery.Status)
+const (
+	CallbackPending   = "pending"
+	CallbackDelivered = "delivered"
+	CallbackFailed    = "failed"
+)
+
+// IngestJob is the background analysis of a transcript ingested with async
+// (GET /ingest/jobs/{id})
+type IngestJob struct {
+	JobID          string                  `json:"job_id"`
+	CallID         string                  `json:"call_id"`
+	Status         string                  `json:"status"` // queued, running, completed, failed
+	Error          string                  `json:"error,omitempty"`
+	Analysis       *AnalysisResult         `json:"analysis,omitempty"` // Once completed
+	CallbackURL    string                  `json:"callback_url,omitempty"`
+	CallbackSecret string                  `json:"callback_secret,omitempty"` // Never returned by the API
+	Callback       *IngestCallbackDelivery `json:"callback,omitempty"`
+	CreatedAt      time.Time               `json:"created_at"`
+	StartedAt      *time.Time              `json:"started_at,omitempty"`
+	FinishedAt     *time.Time              `json:"finished_at,omitempty"`
+	ExpiresAt      time.Time               `json:"expires_at"` // The job is forgotten after this
+}
+
+// IngestCallbackDelivery is how posting a finished job to its callback_url went
+type IngestCallbackDelivery struct {
+	Status      string     `json:"status"` // pending, delivered, failed
+	Attempts    int        `json:"attempts"`
+	LastError   string     `json:"last_error,omitempty"`
+	DeliveredAt *time.Time `json:"delivered_at,omitempty"`
+}
+
+// IngestCallback is the body posted to a job's callback_url once it finishes
+type IngestCallback struct {
+	JobID    string          `json:"job_id"`
+	CallID   string          `json:"call_id"`
+	Status   string          `json:"status"` // completed or failed
+	Error    string          `json:"error,omitempty"`
+	Analysis *AnalysisResult `json:"analysis,omitempty"`
+}
@@ -0,0 +1,33 @@
+package client
+
+import "time"
+
+// DataInventory lists everything stored about a seller, for data-subject
+// access requests (GET /sellers/{id}/data-inventory)
+type DataInventory struct {
+	GluserID    string         `json:"gluser_id"`
+	Categories  []DataCategory `json:"categories"` // Only the ones holding something
+	TotalItems  int            `json:"total_items"`
+	TotalBytes  int64          `json:"total_bytes"`
+	Errors      []string       `json:"errors,omitempty"` // Stores that couldn't be read; the inventory is incomplete without them
+	GeneratedAt time.Time      `json:"generated_at"`
+}
+
+// DataCategory is one kind of data held about a seller and where it's kept
+type DataCategory struct {
+	Name     string       `json:"name"`     // e.g. analyses, events
+	Location string       `json:"location"` // mongodb:<collection>, local:<dir>, or the archive or recording store
+	Items    int          `json:"items"`
+	Bytes    int64        `json:"bytes"`
+	Oldest   *time.Time   `json:"oldest,omitempty"`
+	Newest   *time.Time   `json:"newest,omitempty"`
+	Records  []DataRecord `json:"records"`
+}
+
+// DataRecord is one stored item. Bytes is its size as JSON, or the audio
+// size for recordings.
+type DataRecord struct {
+	ID        string    `json:"id"` // Call, event, ticket or issue ID
+	Timestamp time.Time `json:"timestamp"`
+	Bytes     int64     `json:"bytes"`
+}
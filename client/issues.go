@@ -0,0 +1,45 @@
+package client
+
+import "time"
+
+// SellerIssue is a tracked issue together with the seller it belongs to,
+// as stored in the issues collection
+type SellerIssue struct {
+	GluserID string `json:"gluser_id"`
+	TrackedIssue
+}
+
+// IssuePage is one page of tracked issues, newest first
+type IssuePage struct {
+	Issues     []SellerIssue `json:"issues"`
+	Count      int           `json:"count"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+	HasMore    bool          `json:"has_more"`
+}
+
+// ResolutionDurabilityReport is the response of GET
+// /analytics/resolution-durability: how many verified resolutions held,
+// per bucket and per the agent whose call resolved them
+type ResolutionDurabilityReport struct {
+	From        string              `json:"from,omitempty"` // Resolutions from this date, inclusive
+	To          string              `json:"to,omitempty"`   // and to this one, inclusive
+	WindowDays  int                 `json:"window_days"`    // RESOLUTION_VERIFY_DAYS
+	Resolutions int                 `json:"resolutions"`
+	Pending     int                 `json:"pending"` // Still inside their window, not in the rates
+	Durable     int                 `json:"durable"`
+	NotDurable  int                 `json:"not_durable"`
+	DurableRate float64             `json:"durable_rate"` // durable / (durable + not_durable), 0 with neither
+	Buckets     []DurabilitySummary `json:"buckets"`      // Most resolutions first
+	Agents      []DurabilitySummary `json:"agents"`       // Most resolutions first
+	GeneratedAt time.Time           `json:"generated_at"`
+}
+
+// DurabilitySummary is the resolution durability of one bucket or agent
+type DurabilitySummary struct {
+	Key         string  `json:"key"` // The bucket, or the agent ID
+	Resolutions int     `json:"resolutions"`
+	Pending     int     `json:"pending"`
+	Durable     int     `json:"durable"`
+	NotDurable  int     `json:"not_durable"`
+	DurableRate float64 `json:"durable_rate"`
+}
@@ -0,0 +1,90 @@
+package client
+
+import "time"
+
+// Job run triggers
+const (
+	JobTriggerSchedule = "schedule"
+	JobTriggerManual   = "manual"
+)
+
+// JobSchedule lists the background jobs (GET /admin/jobs/schedule)
+type JobSchedule struct {
+	Jobs        []ScheduledJob `json:"jobs"`
+	Leader      bool           `json:"leader"`   // Scheduled runs happen on the leader only
+	Timezone    string         `json:"timezone"` // Schedules are read in this zone
+	GeneratedAt time.Time      `json:"generated_at"`
+}
+
+// ScheduledJob is a background job and its schedule
+type ScheduledJob struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Schedule    string     `json:"schedule"` // Cron expression, "off" when disabled
+	Enabled     bool       `json:"enabled"`
+	NextRun     *time.Time `json:"next_run,omitempty"`
+	Running     bool       `json:"running"`
+	LastRun     *JobRun    `json:"last_run,omitempty"` // On this instance
+}
+
+// JobRun is one run of a job
+type JobRun struct {
+	Trigger    string     `json:"trigger"` // schedule or manual
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"` // Unset while running
+	DurationMS int64      `json:"duration_ms"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// Job types (Job.Type)
+const (
+	JobTypeAggregation = "aggregation" // A date's aggregate and tickets
+	JobTypeBackfill    = "backfill"    // Seller metrics rebuilt from stored analyses
+	JobTypeRebuild     = "rebuild"     // A replay of every raw transcript
+	JobTypeExport      = "export"      // The bulk seller export
+	JobTypeAnalysis    = "analysis"    // An async ingestion's analysis
+	JobTypeReclassify  = "reclassify"  // Stored data migrated to a changed bucket taxonomy
+)
+
+// JobTypes lists the job types, for validating filters
+var JobTypes = []string{JobTypeAggregation, JobTypeBackfill, JobTypeRebuild, JobTypeExport, JobTypeAnalysis, JobTypeReclassify}
+
+// Job statuses (Job.Status)
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobCompleted = "completed"
+	JobFailed    = "failed"
+)
+
+// JobStatuses lists the job statuses, for validating filters
+var JobStatuses = []string{JobQueued, JobRunning, JobCompleted, JobFailed}
+
+// Job is a long-running operation's record (GET /jobs/{id})
+type Job struct {
+	ID         string       `json:"id"`
+	Type       string       `json:"type"`              // aggregation, backfill, rebuild, export, analysis
+	Subject    string       `json:"subject,omitempty"` // What it runs on, e.g. the date or call ID
+	Status     string       `json:"status"`            // queued, running, completed, failed
+	Progress   *JobProgress `json:"progress,omitempty"`
+	Error      string       `json:"error,omitempty"`
+	Result     string       `json:"result,omitempty"` // Where to read the outcome, e.g. /aggregates/2024-01-15
+	CreatedAt  time.Time    `json:"created_at"`
+	StartedAt  *time.Time   `json:"started_at,omitempty"`
+	FinishedAt *time.Time   `json:"finished_at,omitempty"`
+	DurationMS int64        `json:"duration_ms,omitempty"` // Once finished
+	UpdatedAt  time.Time    `json:"updated_at"`            // A running job not updated for long was stopped with its instance
+	ExpiresAt  time.Time    `json:"expires_at"`            // The record is forgotten after this
+}
+
+// JobProgress is how far a job has got, in the items it works through
+type JobProgress struct {
+	Done  int `json:"done"`
+	Total int `json:"total"`
+}
+
+// JobList is the response of GET /jobs
+type JobList struct {
+	Jobs  []Job `json:"jobs"` // Newest first
+	Count int   `json:"count"`
+}
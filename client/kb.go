@@ -0,0 +1,32 @@
+package client
+
+import "time"
+
+// KBDocument is a product, pricing or policy document in the knowledge base
+// analyses are grounded in (/admin/kb). It's split into chunks, each
+// embedded so the passages relevant to a call can be put in its prompt.
+type KBDocument struct {
+	ID         string    `json:"id"`
+	Title      string    `json:"title"`
+	Content    string    `json:"content,omitempty"` // Left out of listings
+	Chunks     []KBChunk `json:"chunks,omitempty"`  // Left out of listings
+	ChunkCount int       `json:"chunk_count"`
+	By         string    `json:"by,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// KBChunk is one embedded passage of a knowledge base document
+type KBChunk struct {
+	Index  int       `json:"index"`
+	Text   string    `json:"text"`
+	Vector []float64 `json:"vector,omitempty"` // Only in storage
+}
+
+// KBDocumentRequest uploads a knowledge base document. A document with the
+// same title (ignoring case) is replaced.
+type KBDocumentRequest struct {
+	Title   string `json:"title"`
+	Content string `json:"content"`
+	By      string `json:"by,omitempty"`
+}
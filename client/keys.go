@@ -0,0 +1,39 @@
+package client
+
+import "time"
+
+// KeyUsageCounts is what an API key used over a period
+type KeyUsageCounts struct {
+	Requests     int     `json:"requests"`      // Requests served, rejected ones included
+	Rejected     int     `json:"rejected"`      // Requests refused for being over quota
+	Analyses     int     `json:"analyses"`      // Analyses run by POST /ingest, /analyze and /analyze/trigger
+	LLMRequests  int     `json:"llm_requests"`  // Gemini requests made while serving the key's requests
+	PromptTokens int     `json:"prompt_tokens"` // Of those requests
+	OutputTokens int     `json:"output_tokens"`
+	CostUSD      float64 `json:"cost_usd"` // Estimated from GEMINI_INPUT_PRICE and GEMINI_OUTPUT_PRICE
+}
+
+// KeyUsage is an API key's usage in a calendar month (business timezone)
+type KeyUsage struct {
+	KeyName string `json:"key_name"`
+	Month   string `json:"month"` // YYYY-MM
+	KeyUsageCounts
+	Days      map[string]KeyUsageCounts `json:"days"` // By date, days without requests left out
+	UpdatedAt time.Time                 `json:"updated_at"`
+}
+
+// KeyQuota caps an API key's monthly usage; zero limits are unlimited
+type KeyQuota struct {
+	Requests int     `json:"requests,omitempty"`
+	Analyses int     `json:"analyses,omitempty"`
+	CostUSD  float64 `json:"cost_usd,omitempty"`
+}
+
+// KeyUsageReport is an API key's usage in a month against its quota
+// (GET /admin/keys/{id}/usage)
+type KeyUsageReport struct {
+	KeyUsage
+	Quota    *KeyQuota `json:"quota,omitempty"` // nil without one
+	Exceeded []string  `json:"exceeded"`        // Limits reached: requests, analyses, cost_usd
+	ResetsAt time.Time `json:"resets_at"`       // Start of the next month
+}
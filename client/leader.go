@@ -0,0 +1,21 @@
+package client
+
+import "time"
+
+// Leader election backends
+const (
+	LeaderBackendMongo  = "mongo"  // Lease in the leases collection
+	LeaderBackendSingle = "single" // No election, this instance always leads
+)
+
+// LeaderStatus is this instance's role in leader election (GET /admin/leader).
+// Only the leader runs the transcript watcher and the schedulers.
+type LeaderStatus struct {
+	Identity       string     `json:"identity"`
+	Backend        string     `json:"backend"`
+	Leader         bool       `json:"leader"`
+	Holder         string     `json:"holder,omitempty"` // Current lease holder, possibly another instance
+	LeaderSince    *time.Time `json:"leader_since,omitempty"`
+	LeaseExpiresAt *time.Time `json:"lease_expires_at,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+}
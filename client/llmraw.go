@@ -0,0 +1,33 @@
+package client
+
+import "time"
+
+// LLMRawResponse holds the raw Gemini responses behind a call's analysis.
+// They're stored apart from the analysis and expire after
+// LLM_RAW_RETENTION_DAYS (GET /calls/{id}/llm-raw).
+type LLMRawResponse struct {
+	CallID    string            `json:"call_id"`
+	Responses map[string]string `json:"responses"` // By pass: extraction, scoring; "response" for analyses from before the two passes
+	CreatedAt time.Time         `json:"created_at"`
+	ExpiresAt time.Time         `json:"expires_at"`
+}
+
+// LLMInteraction is an analysis request sent to Gemini with its response,
+// recorded with LLM_RECORD=true so the response can be parsed again later
+// (imvoicectl replay-llm). Phone numbers and email addresses are masked.
+type LLMInteraction struct {
+	CallID         string    `json:"call_id"`
+	PromptVersion  string    `json:"prompt_version"` // Fingerprint of the prompt templates and registry
+	Pass           string    `json:"pass"`           // extraction, scoring
+	Model          string    `json:"model"`
+	VerticalPrompt string    `json:"vertical_prompt,omitempty"`
+	SellerID       string    `json:"seller_id,omitempty"`
+	CallTime       time.Time `json:"call_time"`
+	Language       string    `json:"language,omitempty"`
+	DraftLanguage  string    `json:"draft_language,omitempty"` // Scoring: the language follow-up drafts were asked for in
+	SystemPrompt   string    `json:"system_prompt"`
+	Prompt         string    `json:"prompt"`
+	Response       string    `json:"response,omitempty"`
+	Error          string    `json:"error,omitempty"` // The request failed or was blocked
+	RecordedAt     time.Time `json:"recorded_at"`
+}
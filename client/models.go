@@ -0,0 +1,596 @@
+// Package client contains the request/response models of the Voice AI API
+// and a typed HTTP client for it.
+package client
+
+import "time"
+
+// ==================== INPUT MODELS ====================
+
+// RawTranscript represents an incoming call transcript
+type RawTranscript struct {
+	CallID       string                 `json:"call_id"`
+	Timestamp    time.Time              `json:"timestamp"`
+	SellerID     string                 `json:"seller_id"`
+	AgentID      string                 `json:"agent_id,omitempty"`
+	Language     string                 `json:"language,omitempty"`
+	DurationMS   int                    `json:"duration_ms,omitempty"`
+	Transcript   string                 `json:"transcript_text"`
+	CustomerType string                 `json:"customer_type,omitempty"`
+	Vintage      int                    `json:"vintage,omitempty"`
+	Metadata     map[string]interface{} `json:"metadata,omitempty"`
+	Acoustic     *AcousticSignals       `json:"acoustic,omitempty"` // From the call audio, when acoustic signals are enabled
+	Origin       *CallOrigin            `json:"origin,omitempty"`   // The watched source's system and region, for feature flags
+	Channel      string                 `json:"channel,omitempty"`  // voice, chat or whatsapp; empty is voice
+}
+
+// CallTranscript is the full text of a call (GET /calls/{id}/transcript)
+type CallTranscript struct {
+	CallID       string    `json:"call_id"`
+	SellerID     string    `json:"seller_id"`
+	Timestamp    time.Time `json:"timestamp"`
+	Language     string    `json:"language,omitempty"`
+	Transcript   string    `json:"transcript_text,omitempty"` // As received; empty once the raw file is gone
+	TranscriptEn string    `json:"transcript_en,omitempty"`   // English translation from the analysis
+	RecordingURL string    `json:"recording_url,omitempty"`
+	Rehydrated   bool      `json:"rehydrated,omitempty"` // PII vault tokens replaced with their values
+}
+
+// ==================== ANALYSIS MODELS ====================
+
+// Issue represents a single problem extracted from the call
+type Issue struct {
+	Problem           string   `json:"problem"`
+	Bucket            string   `json:"bucket"`
+	VerticalBucket    string   `json:"vertical_bucket,omitempty"` // The vertical's own bucket it was filed under, rolled up to Bucket
+	Severity          string   `json:"severity"`                  // low, medium, high, critical
+	ActionableSummary string   `json:"actionable_summary"`
+	Keywords          []string `json:"keywords,omitempty"`
+	ReopenedIssueID   string   `json:"reopened_issue_id,omitempty"` // Resolved tracked issue this mention reopened, set on the profile update
+
+	Type    string          `json:"type,omitempty"`    // IssueTypeBillingDispute, empty for other issues
+	Dispute *BillingDispute `json:"dispute,omitempty"` // The amounts, for billing disputes
+
+	Evidence []IssueEvidence `json:"evidence,omitempty"` // Transcript quotes the issue rests on
+}
+
+// IssueEvidence is a quote from the call backing an issue, with where it is
+// in the analysis's transcript_en. Positions use the same turns and
+// character offsets as annotations.
+type IssueEvidence struct {
+	Quote   string `json:"quote"`
+	Turn    *int   `json:"turn,omitempty"`  // 0-based line of transcript_en
+	Start   *int   `json:"start,omitempty"` // Character offsets of the quote, end exclusive, when found verbatim
+	End     *int   `json:"end,omitempty"`
+	Located bool   `json:"located"` // The quote was found in the transcript; otherwise turn is the model's estimate
+}
+
+// IssueTypeBillingDispute marks the issue of a seller billed an amount other
+// than agreed. It's always in Billing & Renewal, at high severity or above.
+const IssueTypeBillingDispute = "billing_dispute"
+
+// SellerIntent captures the seller's mood and experience
+type SellerIntent struct {
+	Sentiment         string `json:"sentiment"`          // Positive, Neutral, Negative
+	SatisfactionScore int    `json:"satisfaction_score"` // 1-5
+	PromptResolution  bool   `json:"prompt_resolution"`  // Was issue resolved quickly?
+	OverallExperience string `json:"overall_experience"` // Good, Average, Poor
+}
+
+// ChurnPrediction predicts likelihood of seller leaving
+type ChurnPrediction struct {
+	IsLikelyToChurn      string  `json:"is_likely_to_churn"` // low, medium, high
+	RenewalAtRisk        bool    `json:"renewal_at_risk"`
+	DissatisfactionLevel string  `json:"dissatisfaction_level"` // low, medium, high
+	ChurnReason          string  `json:"churn_reason,omitempty"`
+	ChurnReasonCategory  string  `json:"churn_reason_category,omitempty"` // pricing, lead_quality, competitor, service_experience, business_closed, other
+	RenewalProbability   float64 `json:"renewal_probability"`             // 0.0 - 1.0
+}
+
+// UpsellScore captures upsell opportunities
+type UpsellScore struct {
+	HasOpportunity      bool     `json:"has_opportunity"`
+	Score               int      `json:"score"`                 // 1-10
+	WillingnessToInvest string   `json:"willingness_to_invest"` // low, medium, high
+	IsGrowthOriented    bool     `json:"is_growth_oriented"`
+	InterestedFeatures  []string `json:"interested_features,omitempty"`
+	SKUs                []string `json:"skus,omitempty"` // Products the interested features map to (mdc, trustseal, maximiser, star_pro, leader_pro)
+	UpsellReason        string   `json:"upsell_reason,omitempty"`
+}
+
+// EngineRules marks analyses made by the keyword classifier (AnalysisResult.Engine)
+const EngineRules = "rules"
+
+// AnalysisResult is the complete analysis of a single call
+type AnalysisResult struct {
+	CallID           string                 `json:"call_id"`
+	SellerID         string                 `json:"seller_id"`
+	AgentID          string                 `json:"agent_id,omitempty"` // From the raw transcript, when it names the agent
+	Timestamp        time.Time              `json:"timestamp"`
+	TranscriptEn     string                 `json:"transcript_en"` // English translation
+	OriginalLang     string                 `json:"original_language"`
+	Issues           []Issue                `json:"issues"`
+	Intent           SellerIntent           `json:"intent"`
+	Churn            ChurnPrediction        `json:"churn"`
+	Upsell           UpsellScore            `json:"upsell"`
+	CallSummary      string                 `json:"call_summary"`
+	AgentPerformance string                 `json:"agent_performance,omitempty"` // Good, Average, Poor
+	LLMRaw           map[string]interface{} `json:"llm_raw_response,omitempty"`
+	Acoustic         *AcousticSignals       `json:"acoustic,omitempty"`            // Audio signals the analysis took into account
+	Blocked          bool                   `json:"blocked,omitempty"`             // Gemini's safety filters withheld the analysis; only the transcript is stored
+	Provisional      bool                   `json:"provisional,omitempty"`         // Made by the keyword classifier while Gemini was unavailable, replaced once it's back
+	Engine           string                 `json:"engine,omitempty"`              // EngineRules when made by the keyword classifier, unset for Gemini
+	RulesCheck       *RulesCheck            `json:"rules_check,omitempty"`         // How the keyword classifier's reading differs, RULES_CROSS_CHECK
+	SafetyBlock      *SafetyBlock           `json:"safety_block,omitempty"`        // Why the transcript was blocked, also set when a redacted retry succeeded
+	SellerContext    *SellerContextUsed     `json:"seller_context,omitempty"`      // What the seller context given to the analysis was built from
+	PromptBudget     *PromptBudget          `json:"prompt_budget,omitempty"`       // Estimated extraction prompt size and what was trimmed to fit
+	ScoringBudget    *PromptBudget          `json:"scoring_budget,omitempty"`      // The same for the scoring prompt
+	TranslationCheck *TranslationCheck      `json:"translation_check,omitempty"`   // Set when transcript_en failed the translation check
+	FollowUpDraft    *FollowUpDraft         `json:"follow_up_draft,omitempty"`     // Message for the agent to send the seller, with FOLLOWUP_DRAFTS on
+	PriceMentions    []PriceMention         `json:"price_mentions,omitempty"`      // Amounts said on the call, checked against the plan catalog
+	UpsellPitch      *UpsellPitch           `json:"upsell_pitch,omitempty"`        // Upsell the agent pitched and the seller's response
+	Origin           *CallOrigin            `json:"origin,omitempty"`              // Source system and region of the transcript
+	Call             *CallChannel           `json:"call,omitempty"`                // Normalized direction and status, from the transcript
+	Channel          string                 `json:"channel,omitempty"`             // voice, chat or whatsapp; empty for voice calls analyzed before channels were recorded
+	Onboarding       *OnboardingTag         `json:"onboarding,omitempty"`          // Set for calls in the seller's first 90 days
+	JourneyStage     string                 `json:"journey_stage,omitempty"`       // The seller's journey stage after the call
+	Checksum         string                 `json:"transcript_checksum,omitempty"` // Of the transcript text analyzed, see HackathonTranscript.Checksum
+	Version          int                    `json:"version,omitempty"`             // Counts analyses of the call, raised each time its transcript is rewritten; unset is 1
+	Rollout          string                 `json:"rollout,omitempty"`             // Canary rollout whose candidate made the analysis
+	Model            string                 `json:"model,omitempty"`               // Gemini model that scored the call, unset for the keyword classifier
+	PromptVersion    string                 `json:"prompt_version,omitempty"`      // Fingerprint of the prompts it was scored with, as in GET /capabilities
+	SourceTicket     *SourceTicket          `json:"source_ticket,omitempty"`       // IndiaMART ticket the call was logged against, from the transcript
+	LLMUsage         *LLMUsage              `json:"llm_usage,omitempty"`           // Gemini requests the analysis took
+	AnalyzedAt       time.Time              `json:"analyzed_at"`
+}
+
+// LLMUsage adds up the Gemini requests made for one analysis
+type LLMUsage struct {
+	Requests     int `json:"requests"`
+	LatencyMS    int `json:"latency_ms"` // Summed over the requests
+	PromptTokens int `json:"prompt_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// FollowUpDraft is a short seller-facing message summarizing a call's
+// resolution and next steps (GET /calls/{id}/draft-followup)
+type FollowUpDraft struct {
+	Language string `json:"language"` // Hindi or English, after the call's language
+	Message  string `json:"message"`
+}
+
+// SellerContextUsed records the composition of the seller context an
+// analysis was given, under the policy that applied (SELLER_CONTEXT_POLICY)
+type SellerContextUsed struct {
+	Policy         string `json:"policy"`                 // "default", or the source system whose policy applied
+	RecentCalls    int    `json:"recent_calls"`           // Calls included
+	ActiveIssues   int    `json:"active_issues"`          // Open issues listed
+	ResolvedIssues int    `json:"resolved_issues"`        // Resolved issues listed
+	Notes          int    `json:"notes"`                  // QA reviewers' notes on earlier calls
+	Interventions  int    `json:"interventions"`          // Agents' promises to the seller
+	Tokens         int    `json:"tokens"`                 // Estimated size of the context
+	TokenBudget    int    `json:"token_budget,omitempty"` // The policy's limit, unset for none
+	Truncated      bool   `json:"truncated,omitempty"`    // Items were left out to stay within the budget
+}
+
+// PromptBudget records the token preflight of an analysis prompt
+type PromptBudget struct {
+	Budget          int          `json:"budget"`           // PROMPT_TOKEN_BUDGET at the time
+	EstimatedTokens int          `json:"estimated_tokens"` // Estimate of the prompt as sent
+	OriginalTokens  int          `json:"original_tokens"`  // Estimate before trimming
+	Trims           []PromptTrim `json:"trims,omitempty"`
+}
+
+// PromptTrim is one part of the prompt cut down to fit the budget
+type PromptTrim struct {
+	Part       string `json:"part"` // seller_context, vertical or transcript
+	FromTokens int    `json:"from_tokens"`
+	ToTokens   int    `json:"to_tokens"`
+}
+
+// SafetyBlock records a transcript Gemini's safety filters blocked
+type SafetyBlock struct {
+	Reason        string   `json:"reason"`                   // SAFETY, BLOCKLIST, PROHIBITED_CONTENT, SPII or OTHER
+	Categories    []string `json:"categories,omitempty"`     // Harm categories that tripped, e.g. HARM_CATEGORY_HARASSMENT
+	RedactedTerms int      `json:"redacted_terms,omitempty"` // Abusive terms masked for the retry
+	RedactedRetry bool     `json:"redacted_retry,omitempty"` // The analysis comes from the redacted transcript
+}
+
+// TranslationCheck records a transcript_en that came back from the
+// extraction pass still in Hindi or cut short
+type TranslationCheck struct {
+	Reason       string  `json:"reason"`       // untranslated or truncated
+	HindiShare   float64 `json:"hindi_share"`  // Share of its words in Devanagari or common romanized Hindi
+	Coverage     float64 `json:"coverage"`     // Its words per word of the transcript
+	Retranslated bool    `json:"retranslated"` // transcript_en comes from a translation-only retry that passed
+}
+
+// ==================== AGGREGATION MODELS ====================
+
+// BucketSummary summarizes issues for a single feature bucket
+type BucketSummary struct {
+	Bucket            string         `json:"bucket"`
+	TotalCount        int            `json:"total_count"`
+	AffectedSellers   int            `json:"affected_sellers"`
+	AffectedSellerIDs []string       `json:"affected_seller_ids,omitempty"`
+	TopProblems       []ProblemCount `json:"top_problems"`
+	SeverityBreakdown map[string]int `json:"severity_breakdown"`
+	Examples          []string       `json:"examples,omitempty"`
+	Reopened          int            `json:"reopened,omitempty"`    // Issues that reopened a seller's resolved issue
+	ReopenRate        float64        `json:"reopen_rate,omitempty"` // Reopened over TotalCount
+}
+
+// VerticalBucketSummary counts the issues filed under a vertical's own
+// bucket, which roll up to Parent in FeatureBuckets
+type VerticalBucketSummary struct {
+	Bucket          string `json:"bucket"`
+	Parent          string `json:"parent"`
+	TotalCount      int    `json:"total_count"`
+	AffectedSellers int    `json:"affected_sellers"`
+}
+
+// ProblemCount tracks problem frequency
+type ProblemCount struct {
+	Problem  string `json:"problem"`
+	Count    int    `json:"count"`
+	Severity string `json:"severity"`
+}
+
+// DailyAggregate is the daily intelligence dashboard data. Shift
+// aggregates have the same form, with Shift and its window set.
+type DailyAggregate struct {
+	Date                 string                           `json:"date"`
+	Shift                string                           `json:"shift,omitempty"`        // Shift name, empty for the daily rollup
+	WindowStart          *time.Time                       `json:"window_start,omitempty"` // A shift's calls are from WindowStart up to WindowEnd
+	WindowEnd            *time.Time                       `json:"window_end,omitempty"`
+	TotalCalls           int                              `json:"total_calls"`
+	BlockedCalls         int                              `json:"blocked_calls,omitempty"`     // Calls Gemini's safety filters withheld an analysis for
+	ProvisionalCalls     int                              `json:"provisional_calls,omitempty"` // Calls with only a provisional analysis, made while Gemini was unavailable
+	TotalIssues          int                              `json:"total_issues"`
+	ReopenedIssues       int                              `json:"reopened_issues,omitempty"`  // Issues that reopened a seller's resolved issue
+	BillingDisputes      int                              `json:"billing_disputes,omitempty"` // Calls with a billing amount dispute
+	DisputedAmount       float64                          `json:"disputed_amount,omitempty"`  // Rs charged over what was agreed, summed over those calls
+	FeatureBuckets       map[string]BucketSummary         `json:"feature_buckets"`
+	VerticalBuckets      map[string]VerticalBucketSummary `json:"vertical_buckets,omitempty"` // Issues filed under vertical-specific buckets, also counted under their parent above
+	SentimentBreakdown   map[string]int                   `json:"sentiment_breakdown"`
+	ChurnRiskBreakdown   map[string]int                   `json:"churn_risk_breakdown"`
+	ChurnReasonBreakdown map[string]int                   `json:"churn_reason_breakdown,omitempty"` // Medium/high churn risk calls by reason category
+	UpsellOpportunities  int                              `json:"upsell_opportunities"`
+	UpsellSKUBreakdown   map[string]int                   `json:"upsell_sku_breakdown,omitempty"`  // Upsell opportunities by product SKU
+	SystemBreakdown      map[string]int                   `json:"system_breakdown,omitempty"`      // Calls by source system, for calls with an origin
+	RegionBreakdown      map[string]int                   `json:"region_breakdown,omitempty"`      // Calls by source region, for calls with an origin
+	DirectionBreakdown   map[string]int                   `json:"direction_breakdown,omitempty"`   // Calls by normalized direction (inbound, outbound, unknown)
+	CallStatusBreakdown  map[string]int                   `json:"call_status_breakdown,omitempty"` // Calls by normalized status (answered, missed, voicemail, unknown)
+	UnmappedCallValues   map[string]int                   `json:"unmapped_call_values,omitempty"`  // Source flag_in_out and call_status values not recognized, as field=value
+	ChannelBreakdown     map[string]int                   `json:"channel_breakdown,omitempty"`     // Contacts by channel (voice, chat, whatsapp)
+	ChannelBuckets       map[string]map[string]int        `json:"channel_buckets,omitempty"`       // Issues per bucket by channel, when there are chats
+	AvgSatisfaction      float64                          `json:"avg_satisfaction_score"`
+	WeeklyThemes         []InsightTheme                   `json:"weekly_themes,omitempty"`     // Themes of the key insights of the week ending on the date, from the nightly themes job
+	Processing           *ProcessingMetrics               `json:"processing,omitempty"`        // How the pipeline did on the date, for data completeness
+	TopMovers            *TopMovers                       `json:"top_movers,omitempty"`        // What changed most since the day before
+	Suppressed           []SuppressedIssues               `json:"suppressed,omitempty"`        // Issues ticket suppression rules kept out of tickets, still counted above
+	InputFingerprint     string                           `json:"input_fingerprint,omitempty"` // Hash of the analyses it was built from
+	Stale                bool                             `json:"stale,omitempty"`             // Its analyses changed since; re-aggregate the date to refresh it
+	StaleSince           *time.Time                       `json:"stale_since,omitempty"`
+	GeneratedAt          time.Time                        `json:"generated_at"`
+}
+
+// ProcessingMetrics is how the pipeline did on a day, from its analyzed and
+// analysis_failed events: analyses attempted and failed, Gemini latency,
+// tokens and estimated cost. It covers what was processed that day, whatever
+// date the calls were from.
+type ProcessingMetrics struct {
+	Attempted       int     `json:"attempted"`          // Calls whose analysis was attempted
+	Succeeded       int     `json:"succeeded"`          // Calls analyzed, blocked ones included
+	Failed          int     `json:"failed"`             // Calls every attempt failed for
+	Quarantined     int     `json:"quarantined"`        // Transcripts the watcher gave up on and moved to FAILED_DIR
+	ParseFailures   int     `json:"parse_failures"`     // Analyses whose Gemini response couldn't be parsed
+	Provisional     int     `json:"provisional"`        // Calls analyzed provisionally while Gemini was unavailable, counted in succeeded
+	LLMRequests     int     `json:"llm_requests"`       // Gemini requests made by the analyses
+	AvgLLMLatencyMS int     `json:"avg_llm_latency_ms"` // Per analysis, over all its requests
+	PromptTokens    int     `json:"prompt_tokens"`
+	OutputTokens    int     `json:"output_tokens"`
+	CostUSD         float64 `json:"cost_usd"` // Estimated from the tokens at GEMINI_INPUT_PRICE and GEMINI_OUTPUT_PRICE
+}
+
+// TopMovers is what changed most on a day against the day before: the
+// buckets whose issues rose most, the sellers whose health fell most and the
+// agents whose QA score moved most. Each list is biggest change first.
+type TopMovers struct {
+	PreviousDate string        `json:"previous_date"`
+	Buckets      []BucketMover `json:"buckets"` // Empty without an aggregate for the day before
+	Sellers      []SellerMover `json:"sellers"`
+	Agents       []AgentMover  `json:"agents"`
+}
+
+// BucketMover is a feature bucket whose issue count rose since the day before
+type BucketMover struct {
+	Bucket        string   `json:"bucket"`
+	Count         int      `json:"count"`
+	PreviousCount int      `json:"previous_count"`
+	Change        int      `json:"change"`
+	ChangePct     *float64 `json:"change_pct,omitempty"` // Absent for buckets with no issues the day before
+}
+
+// SellerMover is a seller whose health score fell over the day's profile
+// updates, from before the first to after the last
+type SellerMover struct {
+	GluserID            string `json:"gluser_id"`
+	HealthScore         int    `json:"health_score"`
+	PreviousHealthScore int    `json:"previous_health_score"`
+	Change              int    `json:"change"` // Negative
+	Calls               int    `json:"calls"`  // Profile updates on the day
+}
+
+// AgentMover is an agent whose leaderboard score changed since the day
+// before, among agents ranked on both days
+type AgentMover struct {
+	AgentID       string  `json:"agent_id"`
+	Score         float64 `json:"score"`
+	PreviousScore float64 `json:"previous_score"`
+	Change        float64 `json:"change"`
+	Calls         int     `json:"calls"`
+}
+
+// StalenessReport is the result of checking recent aggregates against their
+// analyses (POST /aggregates/check)
+type StalenessReport struct {
+	Checked    int       `json:"checked"`    // Aggregates with a fingerprint to compare
+	Stale      []string  `json:"stale"`      // Dates whose analyses changed since they were aggregated
+	Recomputed []string  `json:"recomputed"` // Stale dates aggregated again
+	Failed     []string  `json:"failed,omitempty"`
+	CheckedAt  time.Time `json:"checked_at"`
+}
+
+// AggregatePreview is what aggregating a date would save, computed without
+// saving anything (POST /aggregates/preview)
+type AggregatePreview struct {
+	Date         string          `json:"date"`
+	Aggregate    *DailyAggregate `json:"aggregate"`
+	Tickets      []Ticket        `json:"tickets"`        // Stored tickets keep their status, as in a real run
+	NewTicketIDs []string        `json:"new_ticket_ids"` // Tickets not stored for the date yet
+	GeneratedAt  time.Time       `json:"generated_at"`
+}
+
+// Customer tiers of GET /aggregates/{date}/by-tier, from the seller's customer_type
+const (
+	TierFree    = "FREE"
+	TierCatalog = "CATALOG"
+	TierStar    = "STAR"
+	TierLeader  = "LEADER"
+	TierOther   = "OTHER"   // Customer types outside the tiers, e.g. TEMPBLOCK
+	TierUnknown = "UNKNOWN" // Sellers without a profile or customer type
+)
+
+// TierAggregates is a date's aggregate split by customer tier, so problems
+// of paying sellers can be compared with free ones (GET /aggregates/{date}/by-tier)
+type TierAggregates struct {
+	Date        string          `json:"date"`
+	Tiers       []TierAggregate `json:"tiers"` // FREE, CATALOG, STAR, LEADER, OTHER, UNKNOWN; tiers without calls left out
+	GeneratedAt time.Time       `json:"generated_at"`
+}
+
+// TierAggregate is the aggregate of one tier's calls
+type TierAggregate struct {
+	Tier          string         `json:"tier"`
+	Sellers       int            `json:"sellers"`
+	CustomerTypes map[string]int `json:"customer_types,omitempty"` // Calls by the customer_type mapped into the tier
+	IssuesPerCall float64        `json:"issues_per_call"`
+	NegativeRate  float64        `json:"negative_sentiment_rate"` // Share of calls with Negative sentiment
+	HighChurnRate float64        `json:"high_churn_rate"`         // Share of calls with high churn risk
+	Aggregate     DailyAggregate `json:"aggregate"`
+}
+
+// ==================== TICKET MODELS ====================
+
+// Ticket represents an auto-generated issue ticket
+type Ticket struct {
+	TicketID        string         `json:"ticket_id"`
+	Date            string         `json:"date"`
+	FeatureBucket   string         `json:"feature_bucket"`
+	Priority        int            `json:"priority"` // 1 = highest
+	Title           string         `json:"title"`
+	Description     string         `json:"description"`
+	TopProblems     []ProblemCount `json:"top_problems"`
+	AffectedCount   int            `json:"affected_count"`
+	AffectedSellers []string       `json:"affected_sellers,omitempty"`
+	Examples        []string       `json:"examples"`
+	Severity        string         `json:"severity"`
+	BaseSeverity    string         `json:"base_severity,omitempty"`     // Severity from the issue count, when seller value raised it
+	Status          string         `json:"status"`                      // open, in_progress, resolved
+	ResolutionNote  string         `json:"resolution_note,omitempty"`   // Why it was resolved, cleared when it's reopened
+	ResolvedAt      *time.Time     `json:"resolved_at,omitempty"`       // When it was resolved, cleared when it's reopened
+	Evidence        []TicketChart  `json:"evidence,omitempty"`          // Trend charts for ticketing systems to render
+	IssueURL        string         `json:"issue_url,omitempty"`         // GitHub issue tracking the ticket's bucket, when GitHub sync is on
+	SystemicIssueID string         `json:"systemic_issue_id,omitempty"` // Set on tickets for a systemic issue, which aren't synced to GitHub
+	Owner           *TicketOwner   `json:"owner,omitempty"`             // The bucket's owner at the last aggregation, if it has one
+	SourceTicketIDs []string       `json:"source_ticket_ids,omitempty"` // IndiaMART tickets of the calls raising the bucket's issues
+	Impact          *RevenueImpact `json:"impact,omitempty"`            // Revenue the affected sellers put at risk
+	CustomFields    map[string]any `json:"custom_fields,omitempty"`     // Values of the ticket fields defined through /admin/custom-fields
+	CreatedAt       time.Time      `json:"created_at"`
+}
+
+// RevenueImpact estimates the revenue a problem puts at risk from the
+// affected sellers' annual plan values
+type RevenueImpact struct {
+	RevenueAtRisk   int `json:"revenue_at_risk"`            // Rs: each seller's plan value times their churn risk's weight
+	PlanValue       int `json:"plan_value"`                 // Rs: the affected sellers' plan values in full
+	ValuedSellers   int `json:"valued_sellers"`             // Sellers with a known plan value, free ones included
+	UnvaluedSellers int `json:"unvalued_sellers,omitempty"` // Without a profile or a plan value, left out of the sums
+}
+
+// Ticket statuses
+const (
+	TicketOpen       = "open"
+	TicketInProgress = "in_progress"
+	TicketResolved   = "resolved"
+)
+
+// TicketStatusUpdate is the body of PATCH /tickets/{date}/{id}
+type TicketStatusUpdate struct {
+	Status         string         `json:"status"` // May be left out when only setting custom fields
+	ResolutionNote string         `json:"resolution_note,omitempty"`
+	CustomFields   map[string]any `json:"custom_fields,omitempty"` // Values to set, null to remove one; other fields are kept
+}
+
+// TicketBulkItem is one ticket's update in POST /tickets/bulk-update
+type TicketBulkItem struct {
+	TicketID       string `json:"ticket_id"`
+	Date           string `json:"date,omitempty"` // The ticket's date, default the date its ID starts with
+	Status         string `json:"status"`
+	ResolutionNote string `json:"resolution_note,omitempty"`
+}
+
+// TicketBulkItemResult is how one update of a bulk update went
+type TicketBulkItemResult struct {
+	TicketID       string `json:"ticket_id"`
+	OK             bool   `json:"ok"`
+	PreviousStatus string `json:"previous_status,omitempty"`
+	Status         string `json:"status,omitempty"` // The ticket's status afterwards
+	Code           string `json:"code,omitempty"`   // Error code, as in error responses
+	Error          string `json:"error,omitempty"`
+}
+
+// TicketBulkResult is the response of POST /tickets/bulk-update, with a
+// result per item in request order
+type TicketBulkResult struct {
+	Updated int                    `json:"updated"`
+	Failed  int                    `json:"failed"`
+	Results []TicketBulkItemResult `json:"results"`
+}
+
+// Ticket evidence charts
+const (
+	ChartBucketCount     = "bucket_count"     // Issues in the ticket's bucket per day
+	ChartAffectedSellers = "affected_sellers" // Distinct sellers affected so far in the window, per day
+)
+
+// TicketChart is a small pre-computed series attached to a ticket as
+// evidence, one point per day ending on the ticket's date
+type TicketChart struct {
+	Chart  string       `json:"chart"`
+	Title  string       `json:"title"`
+	Kind   string       `json:"kind"` // line or bar, a rendering hint
+	Unit   string       `json:"unit"`
+	Points []ChartPoint `json:"points"`
+}
+
+// ChartPoint is one day of a TicketChart. Days without an aggregate have
+// NoData set so gaps aren't drawn as zero counts (cumulative charts carry
+// the previous value).
+type ChartPoint struct {
+	Date   string  `json:"date"`
+	Value  float64 `json:"value"`
+	NoData bool    `json:"no_data,omitempty"`
+}
+
+// ==================== API REQUEST MODELS ====================
+
+// IngestRequest is the body of POST /ingest
+type IngestRequest struct {
+	CallID       string `json:"call_id,omitempty"`
+	SellerID     string `json:"seller_id,omitempty"`
+	GluserID     string `json:"gluser_id,omitempty"` // Alternative for seller_id (UI uses this)
+	AgentID      string `json:"agent_id,omitempty"`
+	Transcript   string `json:"transcript_text,omitempty"`
+	CallText     string `json:"call_text,omitempty"` // Alternative for transcript_text (UI uses this)
+	Language     string `json:"language,omitempty"`
+	DurationMS   int    `json:"duration_ms,omitempty"`
+	CustomerType string `json:"customer_type,omitempty"`
+	Vintage      int    `json:"vintage,omitempty"`
+	Channel      string `json:"channel,omitempty"` // voice (the default), chat or whatsapp
+	Analyze      bool   `json:"analyze,omitempty"` // If true, analyze immediately
+
+	// With analyze, answer 202 with a job at once and analyze in the
+	// background, posting the outcome to CallbackURL when one is given
+	Async          bool   `json:"async,omitempty"`
+	CallbackURL    string `json:"callback_url,omitempty"`    // Implies async
+	CallbackSecret string `json:"callback_secret,omitempty"` // Signs the callback like webhook deliveries
+}
+
+// AnalyzeRequest is the body of POST /analyze
+type AnalyzeRequest struct {
+	Transcript string `json:"transcript"`
+	Structured bool   `json:"structured,omitempty"` // Return the AnalysisResult ingestion would produce instead of free text
+
+	// With structured, the call as /ingest takes it; all optional
+	CallID       string `json:"call_id,omitempty"`
+	SellerID     string `json:"seller_id,omitempty"`
+	GluserID     string `json:"gluser_id,omitempty"` // Alternative for seller_id
+	AgentID      string `json:"agent_id,omitempty"`
+	Language     string `json:"language,omitempty"`
+	DurationMS   int    `json:"duration_ms,omitempty"`
+	CustomerType string `json:"customer_type,omitempty"`
+	Vintage      int    `json:"vintage,omitempty"`
+	Channel      string `json:"channel,omitempty"`
+}
+
+// ==================== API RESPONSE MODELS ====================
+
+// IngestResponse is returned after ingesting a transcript
+type IngestResponse struct {
+	CallID   string          `json:"call_id"`
+	File     string          `json:"file,omitempty"`
+	Status   string          `json:"status"`
+	Message  string          `json:"message,omitempty"`
+	Analyzed bool            `json:"analyzed"`
+	Analysis *AnalysisResult `json:"analysis,omitempty"`
+	JobID    string          `json:"job_id,omitempty"` // Async ingestion: poll GET /ingest/jobs/{job_id}
+}
+
+// AnalyzeResponse is returned after analyzing a transcript
+type AnalyzeResponse struct {
+	CallID   string          `json:"call_id"`
+	Analysis *AnalysisResult `json:"analysis,omitempty"`
+	Error    string          `json:"error,omitempty"`
+}
+
+// DashboardResponse is the daily intelligence dashboard
+type DashboardResponse struct {
+	Date       string           `json:"date"`
+	Shift      string           `json:"shift,omitempty"` // Set when Aggregate is a shift's
+	Aggregate  *DailyAggregate  `json:"aggregate"`
+	TopTickets []Ticket         `json:"top_tickets"`           // Tickets are daily, also for a shift
+	Shifts     []DailyAggregate `json:"shifts,omitempty"`      // The date's shift aggregates, when shifts are configured
+	ComputedAt *time.Time       `json:"computed_at,omitempty"` // With as_computed, when the snapshot served was taken
+}
+
+// DashboardSnapshot is a date's aggregate and tickets as its first
+// aggregation after the day ended computed them, kept unchanged for
+// GET /dashboard?as_computed=true
+type DashboardSnapshot struct {
+	Date       string          `json:"date"`
+	Aggregate  *DailyAggregate `json:"aggregate"`
+	Tickets    []Ticket        `json:"tickets"`
+	ComputedAt time.Time       `json:"computed_at"`
+}
+
+// ==================== CALL LISTING MODELS ====================
+
+// CallListItem is the compact form of a call analysis returned by GET /calls
+type CallListItem struct {
+	CallID            string       `json:"call_id"`
+	SellerID          string       `json:"seller_id"`
+	AgentID           string       `json:"agent_id,omitempty"`
+	Timestamp         time.Time    `json:"timestamp"`
+	Summary           string       `json:"summary"`
+	Sentiment         string       `json:"sentiment"`
+	SatisfactionScore int          `json:"satisfaction_score"`
+	ChurnRisk         string       `json:"churn_risk"`
+	IssueCount        int          `json:"issue_count"`
+	Buckets           []string     `json:"buckets"`
+	WasEscalated      bool         `json:"was_escalated"`
+	AgentPerformance  string       `json:"agent_performance,omitempty"`
+	Origin            *CallOrigin  `json:"origin,omitempty"`
+	Call              *CallChannel `json:"call,omitempty"`
+	Channel           string       `json:"channel,omitempty"`
+}
+
+// CallPage is one page of GET /calls, newest first
+type CallPage struct {
+	Calls    []CallListItem `json:"calls"`
+	Count    int            `json:"count"`
+	Total    int            `json:"total"` // Calls matching the filters
+	Page     int            `json:"page"`  // 1-based
+	PageSize int            `json:"page_size"`
+	HasMore  bool           `json:"has_more"`
+}
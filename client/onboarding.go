@@ -0,0 +1,47 @@
+package client
+
+import "time"
+
+// OnboardingTag marks a call from a seller in their first 90 days on
+// IndiaMART (vintage under 3 months). Setup is set when the call raised a
+// setup-related bucket (catalog, verification, documentation, training,
+// account), which makes it an onboarding call.
+type OnboardingTag struct {
+	VintageMonths int      `json:"vintage_months"`
+	Setup         bool     `json:"setup"`
+	SetupBuckets  []string `json:"setup_buckets,omitempty"`
+}
+
+// OnboardingFunnel follows the sellers who called in their first 90 days
+// over a date range (GET /analytics/onboarding)
+type OnboardingFunnel struct {
+	From            string            `json:"from"`
+	To              string            `json:"to"`
+	NewSellers      int               `json:"new_sellers"`      // Sellers with a call in their first 90 days
+	NewSellerCalls  int               `json:"new_seller_calls"` // Their calls in the range
+	OnboardingCalls int               `json:"onboarding_calls"` // Of those, calls raising a setup-related bucket
+	Stages          []OnboardingStage `json:"stages"`           // new_sellers, setup_issue, issue_resolved, low_churn_risk
+
+	CatalogIssueSellers int            `json:"catalog_issue_sellers"` // New sellers raising Catalog / Storefront Setup
+	CatalogIssueRate    float64        `json:"catalog_issue_rate"`    // Of new sellers (0-1)
+	SetupBuckets        map[string]int `json:"setup_buckets"`         // New sellers raising each setup-related bucket
+
+	ResolvedSellers             int     `json:"resolved_sellers"`                // New sellers with an issue resolved since their first call in the range
+	AvgDaysToFirstResolution    float64 `json:"avg_days_to_first_resolution"`    // From that call to their first resolution
+	MedianDaysToFirstResolution float64 `json:"median_days_to_first_resolution"` // Likewise
+
+	ChurnSignalSellers int            `json:"churn_signal_sellers"` // New sellers with a medium or high churn risk call
+	ChurnSignalRate    float64        `json:"churn_signal_rate"`    // Of new sellers (0-1)
+	HighChurnSellers   int            `json:"high_churn_sellers"`   // New sellers with a high churn risk call
+	ChurnReasons       map[string]int `json:"churn_reasons"`        // Their at-risk calls by churn reason category
+
+	GeneratedAt time.Time `json:"generated_at"`
+}
+
+// OnboardingStage is one step of the onboarding funnel. Each stage's sellers
+// are among the previous stage's.
+type OnboardingStage struct {
+	Stage   string  `json:"stage"`
+	Sellers int     `json:"sellers"`
+	Rate    float64 `json:"rate"` // Of the previous stage's sellers (0-1), 1 for the first
+}
@@ -0,0 +1,32 @@
+package client
+
+import "time"
+
+// BucketOwner is the team that owns a feature bucket. Tickets in the bucket
+// carry the owner and new ones are sent to it.
+type BucketOwner struct {
+	Bucket       string    `json:"bucket"`
+	Team         string    `json:"team"`
+	Emails       []string  `json:"emails,omitempty"`        // Emailed each new ticket (needs SMTP_HOST)
+	SlackChannel string    `json:"slack_channel,omitempty"` // Sent as the webhook payload's channel
+	WebhookURL   string    `json:"webhook_url,omitempty"`   // Posted each new ticket, TICKET_WEBHOOK_URL if empty
+	By           string    `json:"by,omitempty"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// BucketOwnerRequest is the body of PUT /admin/bucket-owners/{bucket}
+type BucketOwnerRequest struct {
+	Team         string   `json:"team"`
+	Emails       []string `json:"emails,omitempty"`
+	SlackChannel string   `json:"slack_channel,omitempty"`
+	WebhookURL   string   `json:"webhook_url,omitempty"`
+	By           string   `json:"by,omitempty"` // Defaults to the API key's name
+}
+
+// TicketOwner is who a ticket was routed to: its bucket's owner, without
+// the webhook URL
+type TicketOwner struct {
+	Team         string   `json:"team"`
+	Emails       []string `json:"emails,omitempty"`
+	SlackChannel string   `json:"slack_channel,omitempty"`
+}
@@ -0,0 +1,36 @@
+package client
+
+import "time"
+
+// SellerCaseSummary is what a seller may see of their own support history
+// (GET /portal/sellers/{gluser_id}): their open and recently resolved
+// issues and what was promised to them. Health, churn and upsell scoring,
+// severities and internal notes are left out.
+type SellerCaseSummary struct {
+	GluserID         string           `json:"gluser_id"`
+	OpenCases        []SellerCase     `json:"open_cases"`        // Most recently updated first
+	RecentlyResolved []SellerCase     `json:"recently_resolved"` // Resolved in the last 30 days, newest first
+	FollowUps        []SellerFollowUp `json:"follow_ups"`        // Open promises first, then the latest kept or missed ones
+	LastContactAt    *time.Time       `json:"last_contact_at,omitempty"`
+	GeneratedAt      time.Time        `json:"generated_at"`
+}
+
+// SellerCase is one of a seller's issues as the seller sees it
+type SellerCase struct {
+	CaseID     string     `json:"case_id"` // The tracked issue's ID
+	Problem    string     `json:"problem"`
+	Category   string     `json:"category"`
+	Status     string     `json:"status"` // open, in_progress, reopened, resolved
+	ReportedAt time.Time  `json:"reported_at"`
+	UpdatedAt  time.Time  `json:"updated_at"` // Last raised on a call
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+	TicketID   string     `json:"ticket_id,omitempty"` // The IndiaMART ticket it was logged against
+}
+
+// SellerFollowUp is a promise made to the seller on a call
+type SellerFollowUp struct {
+	Promise    string    `json:"promise"`
+	PromisedAt time.Time `json:"promised_at"`
+	DueDate    string    `json:"due_date"` // YYYY-MM-DD
+	Status     string    `json:"status"`   // open, kept, broken
+}
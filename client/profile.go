@@ -0,0 +1,332 @@
+package client
+
+import (
+	"maps"
+	"slices"
+	"time"
+)
+
+// ==================== SELLER PROFILE MODELS ====================
+// These models are designed to be dashboard-ready with clear structure
+
+// SellerProfile is the master record for a seller - always updated, never duplicated
+type SellerProfile struct {
+	// === IDENTITY ===
+	GluserID      string `json:"gluser_id"`
+	CustomerType  string `json:"customer_type"` // CATALOG, STAR, LEADER, etc.
+	CityName      string `json:"city_name"`
+	Vertical      string `json:"vertical"`
+	VintageMonths int    `json:"vintage_months"`
+	JourneyStage  string `json:"journey_stage,omitempty"` // onboarding, activation, steady-state, renewal-window, win-back; as of the latest call
+
+	// === CURRENT STATUS (Dashboard Header) ===
+	CurrentStatus SellerStatus `json:"current_status"`
+
+	// === CALL HISTORY (Timeline for Dashboard) ===
+	TotalCalls      int              `json:"total_calls"`
+	CallHistory     []CallSummary    `json:"call_history"`               // Most recent first
+	ContactAttempts []ContactAttempt `json:"contact_attempts,omitempty"` // Missed and zero-length calls, most recent first; not in total_calls
+
+	// === ISSUE TRACKING (Issue Panel for Dashboard) ===
+	ActiveIssues   []TrackedIssue  `json:"active_issues"`   // Unresolved issues
+	ResolvedIssues []TrackedIssue  `json:"resolved_issues"` // Historical resolved issues
+	IssueStats     IssueStatistics `json:"issue_stats"`
+	Case           *CaseLifecycle  `json:"case,omitempty"` // Absent on profiles from before cases, which count as open
+
+	// === TRENDS (Charts for Dashboard) ===
+	Trends SellerTrends `json:"trends"`
+
+	// === BUSINESS CONTEXT ===
+	SellerCategories []string `json:"seller_categories"` // Product categories they sell
+
+	// === MANUAL CORRECTIONS (PATCH /sellers/{id}) ===
+	CorrectedFields   []string        `json:"corrected_fields,omitempty"`   // Identity fields later calls no longer overwrite
+	ChurnOverride     *StatusOverride `json:"churn_override,omitempty"`     // Replaces current_status.churn_risk from the latest call
+	SentimentOverride *StatusOverride `json:"sentiment_override,omitempty"` // Replaces current_status.sentiment from the latest call
+	CustomFields      map[string]any  `json:"custom_fields,omitempty"`      // Values of the profile fields defined through /admin/custom-fields
+
+	// === METADATA ===
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	LastCallAt time.Time `json:"last_call_at"`
+}
+
+// Journey stages of a seller (SellerProfile.JourneyStage)
+const (
+	StageOnboarding    = "onboarding"     // First 90 days on IndiaMART
+	StageActivation    = "activation"     // Up to 6 months in, getting value from the platform
+	StageSteadyState   = "steady-state"   // Established, nothing pending
+	StageRenewalWindow = "renewal-window" // A paid plan's renewal is near or in question
+	StageWinBack       = "win-back"       // Lapsed or leaving: downgraded, defaulted or about to go
+)
+
+// JourneyStages lists the journey stages in the order sellers move through them
+var JourneyStages = []string{StageOnboarding, StageActivation, StageSteadyState, StageRenewalWindow, StageWinBack}
+
+// StatusOverride is an account manager's value for a status field the LLM
+// got wrong, used in place of the model's until cleared or expired
+type StatusOverride struct {
+	Value      string     `json:"value"`
+	Reason     string     `json:"reason"`
+	By         string     `json:"by,omitempty"`
+	At         time.Time  `json:"at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`  // Without it, the override stands until cleared
+	ModelValue string     `json:"model_value,omitempty"` // From the latest call, restored when the override ends
+}
+
+// Active reports whether the override still applies at now
+func (o *StatusOverride) Active(now time.Time) bool {
+	return o != nil && (o.ExpiresAt == nil || now.Before(*o.ExpiresAt))
+}
+
+// Seller case statuses (CaseLifecycle.Status)
+const (
+	CaseOpen   = "open"
+	CaseClosed = "closed"
+)
+
+// CaseLifecycle is the lifecycle of a seller's account management case. It
+// closes once the seller has no open issues and nothing negative for
+// CASE_CLOSE_DAYS, after which their health score decays toward neutral,
+// and reopens with the next Negative call or open issue.
+type CaseLifecycle struct {
+	Status      string     `json:"status"`
+	OpenedAt    time.Time  `json:"opened_at"`            // Opened, or last reopened
+	ClosedAt    *time.Time `json:"closed_at,omitempty"`  // While closed
+	DecayedAt   *time.Time `json:"decayed_at,omitempty"` // The health score was last decayed, while closed
+	ReopenCount int        `json:"reopen_count,omitempty"`
+	ReopenedBy  string     `json:"reopened_by,omitempty"` // Call that last reopened it
+}
+
+// Closed reports whether the case is closed; a profile without one is open
+func (c *CaseLifecycle) Closed() bool {
+	return c != nil && c.Status == CaseClosed
+}
+
+// SellerProfilePatch corrects parts of a seller profile (PATCH /sellers/{id}).
+// Fields left out are kept.
+type SellerProfilePatch struct {
+	CustomerType           *string         `json:"customer_type,omitempty"`
+	CityName               *string         `json:"city_name,omitempty"`
+	Vertical               *string         `json:"vertical,omitempty"`
+	ChurnOverride          *StatusOverride `json:"churn_override,omitempty"`     // value, reason and optional expires_at; the server sets the rest
+	SentimentOverride      *StatusOverride `json:"sentiment_override,omitempty"` // Likewise
+	ClearChurnOverride     bool            `json:"clear_churn_override,omitempty"`
+	ClearSentimentOverride bool            `json:"clear_sentiment_override,omitempty"`
+	CustomFields           map[string]any  `json:"custom_fields,omitempty"` // Values to set, null to remove one; other fields are kept
+}
+
+// SellerStatus represents current state - perfect for dashboard header cards
+type SellerStatus struct {
+	HealthScore       int     `json:"health_score"`       // 0-100, composite score
+	HealthLabel       string  `json:"health_label"`       // "Healthy", "At Risk", "Critical"
+	ChurnRisk         string  `json:"churn_risk"`         // low, medium, high
+	ChurnProbability  float64 `json:"churn_probability"`  // 0.0-1.0
+	Sentiment         string  `json:"sentiment"`          // Current sentiment
+	SatisfactionScore int     `json:"satisfaction_score"` // Latest 1-10
+	OpenIssueCount    int     `json:"open_issue_count"`   // Active issues
+	UpsellPotential   string  `json:"upsell_potential"`   // low, medium, high
+	NeedsAttention    bool    `json:"needs_attention"`    // Flag for immediate action
+	AttentionReason   string  `json:"attention_reason,omitempty"`
+}
+
+// CallSummary is a compact record of each call - for timeline display
+type CallSummary struct {
+	CallID           string    `json:"call_id"`
+	Timestamp        time.Time `json:"timestamp"`
+	Duration         int       `json:"duration_seconds"`
+	Direction        string    `json:"direction"`         // inbound or outbound, empty when not known
+	Channel          string    `json:"channel,omitempty"` // voice, chat or whatsapp; empty is voice
+	Summary          string    `json:"summary"`           // 1-2 sentence summary
+	Sentiment        string    `json:"sentiment"`
+	IssuesRaised     int       `json:"issues_raised"`
+	IssuesResolved   int       `json:"issues_resolved"`
+	AgentPerformance string    `json:"agent_performance"`
+	WasEscalated     bool      `json:"was_escalated"`
+	FollowUpNeeded   bool      `json:"follow_up_needed"`
+}
+
+// Contact attempt reasons
+const (
+	ContactMissed       = "missed"        // The call's status was missed
+	ContactZeroDuration = "zero_duration" // The call lasted no time
+)
+
+// ContactAttempt is a call that never became a conversation, kept on the
+// seller's timeline without an analysis or any effect on their health
+type ContactAttempt struct {
+	CallID    string    `json:"call_id"`
+	Timestamp time.Time `json:"timestamp"`
+	Duration  int       `json:"duration_seconds"`
+	Direction string    `json:"direction,omitempty"` // inbound or outbound, empty when not known
+	Status    string    `json:"status,omitempty"`    // Normalized call status, empty when not known
+	Reason    string    `json:"reason"`              // missed, zero_duration
+}
+
+// TrackedIssue represents an issue with lifecycle tracking
+type TrackedIssue struct {
+	IssueID        string `json:"issue_id"` // Unique ID for tracking
+	Problem        string `json:"problem"`
+	Bucket         string `json:"bucket"`
+	VerticalBucket string `json:"vertical_bucket,omitempty"` // The vertical's own bucket, rolled up to Bucket
+	Severity       string `json:"severity"`
+	BaseSeverity   string `json:"base_severity,omitempty"` // The analysis's severity, when seller value raised it
+	ActionRequired string `json:"action_required"`
+
+	Type    string          `json:"type,omitempty"`    // IssueTypeBillingDispute, empty for other issues
+	Dispute *BillingDispute `json:"dispute,omitempty"` // Amounts from the latest call that raised the dispute
+
+	// Lifecycle
+	Status          string     `json:"status"` // open, in_progress, resolved, reopened
+	FirstReportedAt time.Time  `json:"first_reported_at"`
+	LastMentionedAt time.Time  `json:"last_mentioned_at"`
+	ResolvedAt      *time.Time `json:"resolved_at,omitempty"`
+	EscalatedAt     *time.Time `json:"escalated_at,omitempty"` // Set when auto-escalated for staleness
+
+	// Reopening: a resolved issue mentioned again goes back to the active issues
+	ReopenCount        int         `json:"reopen_count,omitempty"`
+	ReopenedAt         *time.Time  `json:"reopened_at,omitempty"`          // Latest reopening
+	PreviousResolvedAt []time.Time `json:"previous_resolved_at,omitempty"` // Earlier resolutions, oldest first
+
+	// Verification: each resolution is checked RESOLUTION_VERIFY_DAYS later
+	ResolutionChecks     []ResolutionCheck `json:"resolution_checks,omitempty"`      // One per resolution, oldest first; the latest may be pending
+	ResolutionNotDurable bool              `json:"resolution_not_durable,omitempty"` // A resolution didn't hold: the seller raised the bucket again inside its window
+
+	// Recurrence tracking
+	MentionCount int      `json:"mention_count"`      // How many calls mentioned this
+	CallIDs      []string `json:"call_ids"`           // Which calls mentioned this
+	IsRecurring  bool     `json:"is_recurring"`       // Mentioned in 2+ calls
+	Channels     []string `json:"channels,omitempty"` // Contact channels it was raised on, in the order first raised
+
+	SourceTickets []SourceTicket `json:"source_tickets,omitempty"` // IndiaMART tickets of the calls that mentioned this
+}
+
+// Resolution check statuses
+const (
+	ResolutionPending    = "pending"     // Inside the verification window
+	ResolutionDurable    = "durable"     // The window passed without the seller raising the bucket again
+	ResolutionNotDurable = "not_durable" // Raised again inside the window, so the issue was reopened
+)
+
+// ResolutionCheck is the verification checkpoint of one resolution of a
+// tracked issue
+type ResolutionCheck struct {
+	ResolvedAt   time.Time  `json:"resolved_at"`
+	CallID       string     `json:"call_id,omitempty"`  // The call that resolved it
+	AgentID      string     `json:"agent_id,omitempty"` // That call's agent
+	DueAt        time.Time  `json:"due_at"`
+	Status       string     `json:"status"` // pending, durable, not_durable
+	CheckedAt    *time.Time `json:"checked_at,omitempty"`
+	ReopenCallID string     `json:"reopen_call_id,omitempty"` // The call raising it again, when not durable
+}
+
+// Source ticket statuses
+const (
+	SourceTicketOpen   = "open"
+	SourceTicketClosed = "closed"
+)
+
+// SourceTicket is a ticket in IndiaMART's own ticketing system, as the
+// latest call carrying it reported it (customer_ticket_id and
+// customer_ticket_status on the transcript)
+type SourceTicket struct {
+	TicketID  string    `json:"ticket_id"`
+	Status    string    `json:"status,omitempty"`     // open or closed, empty when the source's status isn't recognized
+	RawStatus string    `json:"raw_status,omitempty"` // As the source spelled it
+	CallID    string    `json:"call_id"`
+	UpdatedAt time.Time `json:"updated_at"` // When that call happened
+}
+
+// IssueStatistics for dashboard stats panel
+type IssueStatistics struct {
+	TotalIssuesEver   int            `json:"total_issues_ever"`
+	CurrentOpenCount  int            `json:"current_open_count"`
+	ResolvedCount     int            `json:"resolved_count"`
+	RecurringCount    int            `json:"recurring_count"` // Issues that came back
+	ReopenedCount     int            `json:"reopened_count"`  // Issues reopened after being resolved, at least once
+	ReopenRate        float64        `json:"reopen_rate"`     // ReopenedCount over issues ever resolved
+	AvgResolutionDays float64        `json:"avg_resolution_days"`
+	TopBuckets        []BucketCount  `json:"top_buckets"` // Most common issue categories
+	BucketReopens     []BucketReopen `json:"bucket_reopens,omitempty"`
+	SeverityBreakdown map[string]int `json:"severity_breakdown"`
+}
+
+// BucketReopen is how often a bucket's resolved issues came back
+type BucketReopen struct {
+	Bucket   string  `json:"bucket"`
+	Resolved int     `json:"resolved"` // Issues resolved at least once
+	Reopened int     `json:"reopened"` // Of those, reopened at least once
+	Rate     float64 `json:"rate"`
+}
+
+// BucketCount for issue category ranking
+type BucketCount struct {
+	Bucket string `json:"bucket"`
+	Count  int    `json:"count"`
+}
+
+// SellerTrends for dashboard charts
+type SellerTrends struct {
+	// Sentiment over time (for line chart)
+	SentimentHistory []TrendPoint `json:"sentiment_history"`
+
+	// Satisfaction over time (for line chart)
+	SatisfactionHistory []TrendPoint `json:"satisfaction_history"`
+
+	// Issue count over time (for bar chart)
+	IssueHistory []TrendPoint `json:"issue_history"`
+
+	// Computed trends
+	SentimentTrend    string `json:"sentiment_trend"`    // improving, stable, declining
+	SatisfactionTrend string `json:"satisfaction_trend"` // improving, stable, declining
+	OverallTrend      string `json:"overall_trend"`      // improving, stable, declining
+
+	// Churn risk evolution
+	ChurnRiskHistory []TrendPoint `json:"churn_risk_history"`
+}
+
+// TrendPoint for time-series data
+type TrendPoint struct {
+	Date   string  `json:"date"` // "2025-12-12"
+	Value  float64 `json:"value"`
+	Label  string  `json:"label,omitempty"` // Optional label like "Negative"
+	CallID string  `json:"call_id,omitempty"`
+	Count  int     `json:"count,omitempty"` // Calls averaged into this point (rollups only)
+}
+
+// Clone returns a deep copy of the profile
+func (p *SellerProfile) Clone() *SellerProfile {
+	if p == nil {
+		return nil
+	}
+	c := *p
+	c.CallHistory = slices.Clone(p.CallHistory)
+	c.ActiveIssues = cloneTrackedIssues(p.ActiveIssues)
+	c.ResolvedIssues = cloneTrackedIssues(p.ResolvedIssues)
+	c.IssueStats.TopBuckets = slices.Clone(p.IssueStats.TopBuckets)
+	c.IssueStats.SeverityBreakdown = maps.Clone(p.IssueStats.SeverityBreakdown)
+	c.Trends.SentimentHistory = slices.Clone(p.Trends.SentimentHistory)
+	c.Trends.SatisfactionHistory = slices.Clone(p.Trends.SatisfactionHistory)
+	c.Trends.IssueHistory = slices.Clone(p.Trends.IssueHistory)
+	c.Trends.ChurnRiskHistory = slices.Clone(p.Trends.ChurnRiskHistory)
+	c.SellerCategories = slices.Clone(p.SellerCategories)
+	return &c
+}
+
+func cloneTrackedIssues(issues []TrackedIssue) []TrackedIssue {
+	out := slices.Clone(issues)
+	for i := range out {
+		if t := out[i].ResolvedAt; t != nil {
+			resolved := *t
+			out[i].ResolvedAt = &resolved
+		}
+		if t := out[i].EscalatedAt; t != nil {
+			escalated := *t
+			out[i].EscalatedAt = &escalated
+		}
+		out[i].CallIDs = slices.Clone(out[i].CallIDs)
+		out[i].Channels = slices.Clone(out[i].Channels)
+		out[i].ResolutionChecks = slices.Clone(out[i].ResolutionChecks)
+	}
+	return out
+}
@@ -0,0 +1,43 @@
+package client
+
+import "time"
+
+// Why a call was analyzed provisionally (ProvisionalCall.Reason)
+const (
+	ProvisionalUnavailable = "unavailable"    // Gemini couldn't be reached or returned an error
+	ProvisionalQuota       = "quota_exceeded" // Gemini's quota or rate limit was spent
+	ProvisionalTimeout     = "timeout"        // Gemini didn't answer within the analysis deadline
+	ProvisionalDegraded    = "degraded"       // Gemini wasn't tried, it failed moments before
+)
+
+// ProvisionalCall is a call analyzed provisionally, queued for the full
+// analysis once Gemini is back
+type ProvisionalCall struct {
+	CallID        string               `json:"call_id"`
+	GluserID      string               `json:"gluser_id,omitempty"`
+	Reason        string               `json:"reason"`                        // unavailable, quota_exceeded, timeout or degraded
+	Checksum      string               `json:"transcript_checksum,omitempty"` // Of the transcript as received, kept on the full analysis
+	Transcript    *HackathonTranscript `json:"transcript,omitempty"`          // Calls with a seller, PII tokenized; others are read back from the raw transcripts
+	QueuedAt      time.Time            `json:"queued_at"`
+	Attempts      int                  `json:"attempts"` // Upgrades tried
+	LastAttemptAt *time.Time           `json:"last_attempt_at,omitempty"`
+	LastError     string               `json:"last_error,omitempty"` // Why the last upgrade failed: one of the reasons, or failed
+}
+
+// ProvisionalQueue is the calls waiting for a full analysis (GET /provisional)
+type ProvisionalQueue struct {
+	Degraded      bool              `json:"degraded"` // This instance is analyzing provisionally
+	DegradedSince *time.Time        `json:"degraded_since,omitempty"`
+	Reason        string            `json:"reason,omitempty"` // Of the failure that started it
+	Calls         []ProvisionalCall `json:"calls"`            // Oldest first, without transcripts
+	Count         int               `json:"count"`
+}
+
+// ProvisionalUpgrade is the outcome of upgrading provisional analyses
+// (POST /provisional/upgrade)
+type ProvisionalUpgrade struct {
+	Upgraded  int    `json:"upgraded"`
+	Failed    int    `json:"failed"`
+	Remaining int    `json:"remaining"`
+	Stopped   string `json:"stopped,omitempty"` // Why the upgrade stopped early: Gemini is still unavailable
+}
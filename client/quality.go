@@ -0,0 +1,32 @@
+package client
+
+import "time"
+
+// DataQualityReport sums up the quality of the transcripts received over a
+// date range, per source (GET /admin/data-quality). Rates are 0-1 over the
+// transcripts received from the source.
+type DataQualityReport struct {
+	From        string          `json:"from"`
+	To          string          `json:"to"`
+	Received    int             `json:"received"`
+	Sources     []SourceQuality `json:"sources"` // Most transcripts first
+	GeneratedAt time.Time       `json:"generated_at"`
+}
+
+// SourceQuality is the ingestion quality of one source: "api", "watcher"
+// for TRANSCRIPTS_DIR, or a watched system and region as "system:region"
+type SourceQuality struct {
+	Source              string             `json:"source"`
+	Received            int                `json:"received"`
+	Revised             int                `json:"revised"` // New versions of transcripts received before
+	EmptyTranscripts    int                `json:"empty_transcripts"`
+	EmptyRate           float64            `json:"empty_rate"`
+	UnparseableDates    int                `json:"unparseable_dates"`
+	UnparseableDateRate float64            `json:"unparseable_date_rate"`
+	Duplicates          int                `json:"duplicates"` // The same call ID or text received again as a new transcript
+	DuplicateRate       float64            `json:"duplicate_rate"`
+	Unreadable          int                `json:"unreadable"` // Files that couldn't be read or parsed as JSON
+	AvgTranscriptChars  int                `json:"avg_transcript_chars"`
+	MissingFields       map[string]int     `json:"missing_fields"`
+	MissingFieldRates   map[string]float64 `json:"missing_field_rates"`
+}
@@ -0,0 +1,57 @@
+package client
+
+import "time"
+
+// SellerQuote is a seller's own words backing an issue on a call, redacted,
+// for the voice-of-seller library (GET /quotes)
+type SellerQuote struct {
+	ID            string    `json:"id"` // Call ID and the issue's position on the call
+	CallID        string    `json:"call_id"`
+	GluserID      string    `json:"gluser_id"`
+	FeatureBucket string    `json:"feature_bucket"`
+	Problem       string    `json:"problem"`
+	Severity      string    `json:"severity"`
+	Sentiment     string    `json:"sentiment"` // The call's: Positive, Neutral, Negative
+	Quote         string    `json:"quote"`     // From transcript_en, with personal details masked
+	Date          string    `json:"date"`      // Call date, YYYY-MM-DD
+	CallTime      time.Time `json:"call_time"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// QuoteLibrary is a selection of seller quotes: at most one per seller and
+// bucket, most severe and newest first
+type QuoteLibrary struct {
+	From        string             `json:"from"`
+	To          string             `json:"to"`
+	Quotes      []SellerQuote      `json:"quotes"`
+	Count       int                `json:"count"`
+	Total       int                `json:"total"`     // Quotes matching the filters, repeats by a seller included
+	ByBucket    []QuoteBucketCount `json:"by_bucket"` // Of the matching quotes, most quotes first
+	GeneratedAt time.Time          `json:"generated_at"`
+}
+
+// QuoteBucketCount counts a bucket's matching quotes and the sellers behind them
+type QuoteBucketCount struct {
+	FeatureBucket string `json:"feature_bucket"`
+	Quotes        int    `json:"quotes"`
+	Sellers       int    `json:"sellers"`
+}
+
+// QuoteOptOut records a seller who withdrew consent to be quoted. Their
+// quotes and call samples are deleted and none are taken from their later
+// calls.
+type QuoteOptOut struct {
+	GluserID       string    `json:"gluser_id"`
+	Reason         string    `json:"reason,omitempty"`
+	By             string    `json:"by,omitempty"`
+	At             time.Time `json:"at"`
+	Deleted        int       `json:"deleted,omitempty"`         // Quotes deleted, in the opt-out's response
+	SamplesDeleted int       `json:"samples_deleted,omitempty"` // Call samples deleted, in the opt-out's response
+}
+
+// QuoteOptOutRequest is the body of POST /quotes/opt-outs
+type QuoteOptOutRequest struct {
+	GluserID string `json:"gluser_id"`
+	Reason   string `json:"reason,omitempty"`
+	By       string `json:"by,omitempty"` // Defaults to the API key's name
+}
@@ -0,0 +1,29 @@
+package client
+
+import "time"
+
+// Readiness checks gating startup
+const (
+	CheckStorage = "storage" // Local storage directories created
+	CheckMongoDB = "mongodb" // Connected, when MONGODB_URI is set
+	CheckLLM     = "llm"     // Gemini answered a request with the key and model
+	CheckCache   = "cache"   // The dashboard's data preloaded, unless CACHE_PRIME=false
+)
+
+// ReadinessStatus is whether the instance should receive traffic (GET /ready,
+// 503 while not ready). It turns ready once every startup check has passed
+// and unready again for good when the instance starts draining.
+type ReadinessStatus struct {
+	Ready    bool             `json:"ready"`
+	Draining bool             `json:"draining"`
+	Checks   []ReadinessCheck `json:"checks"`
+}
+
+// ReadinessCheck is one startup dependency
+type ReadinessCheck struct {
+	Name      string     `json:"name"`
+	OK        bool       `json:"ok"`
+	Degraded  bool       `json:"degraded,omitempty"` // Let through failing, LLM_STARTUP_CHECK=degraded; Error has why
+	Error     string     `json:"error,omitempty"`    // Last failure, pending checks have neither
+	CheckedAt *time.Time `json:"checked_at,omitempty"`
+}
@@ -0,0 +1,97 @@
+package client
+
+import "time"
+
+// Reclassification run states
+const (
+	ReclassifyRunning   = "running"
+	ReclassifyCompleted = "completed"
+	ReclassifyFailed    = "failed" // Stopped part way; its changes list what was migrated
+)
+
+// How a reclassified issue's new bucket was chosen (ReclassifyChange.Method)
+const (
+	ReclassifyByMapping = "mapping" // The bucket maps to a single new one
+	ReclassifyByKeyword = "keyword" // A split target's keyword matched
+	ReclassifyByLLM     = "llm"     // Gemini picked the split target
+	ReclassifyByDefault = "default" // Nothing placed it, so the split's default
+)
+
+// Kinds of record a reclassification changes (ReclassifyChange.Kind)
+const (
+	ReclassifyAnalysisIssue = "analysis_issue"
+	ReclassifyTrackedIssue  = "tracked_issue"
+	ReclassifyAggregate     = "aggregate"
+	ReclassifyTicket        = "ticket"
+)
+
+// BucketMapping moves every issue of a retired bucket to another one: a
+// rename, or one side of a merge
+type BucketMapping struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// BucketSplit divides a retired bucket's issues among several buckets
+type BucketSplit struct {
+	From    string        `json:"from"`
+	Into    []SplitTarget `json:"into"`
+	Default string        `json:"default,omitempty"` // For issues nothing places, the first target when empty
+}
+
+// SplitTarget is one of the buckets a split divides issues among
+type SplitTarget struct {
+	Bucket      string   `json:"bucket"`
+	Keywords    []string `json:"keywords,omitempty"`    // An issue whose problem or keywords contain one goes here, targets checked in order
+	Description string   `json:"description,omitempty"` // What belongs here, for Gemini with use_llm
+}
+
+// ReclassifyRequest is the body of POST /admin/reclassifications. Every
+// bucket moved to must be in the current taxonomy, and a bucket moved from
+// can't also be moved to.
+type ReclassifyRequest struct {
+	Mappings []BucketMapping `json:"mappings,omitempty"`
+	Splits   []BucketSplit   `json:"splits,omitempty"`
+	UseLLM   bool            `json:"use_llm,omitempty"` // Gemini places split issues no keyword matched
+	DryRun   bool            `json:"dry_run,omitempty"` // Report the changes without making them
+	Reason   string          `json:"reason,omitempty"`
+	By       string          `json:"by,omitempty"` // Defaults to the API key's name
+}
+
+// Reclassification is a run migrating stored data to a changed bucket
+// taxonomy, and its audit trail
+type Reclassification struct {
+	ID               string             `json:"id"`
+	Mappings         []BucketMapping    `json:"mappings,omitempty"`
+	Splits           []BucketSplit      `json:"splits,omitempty"`
+	UseLLM           bool               `json:"use_llm,omitempty"`
+	DryRun           bool               `json:"dry_run,omitempty"`
+	Reason           string             `json:"reason,omitempty"`
+	By               string             `json:"by,omitempty"`
+	Status           string             `json:"status"`           // running, completed, failed
+	JobID            string             `json:"job_id,omitempty"` // The job tracking it, unset for dry runs
+	Analyses         int                `json:"analyses"`         // Analyses with an issue moved
+	Issues           int                `json:"issues"`           // Analysis issues moved
+	Profiles         int                `json:"profiles"`         // Seller profiles with a tracked issue moved
+	TrackedIssues    int                `json:"tracked_issues"`
+	Aggregates       int                `json:"aggregates"`
+	Tickets          int                `json:"tickets"`
+	Methods          map[string]int     `json:"methods,omitempty"` // Analysis and tracked issues moved, by how the bucket was chosen
+	Changes          []ReclassifyChange `json:"changes,omitempty"` // With GET /admin/reclassifications/{id}, and for dry runs
+	ChangeCount      int                `json:"change_count"`
+	ChangesTruncated bool               `json:"changes_truncated,omitempty"` // Changes stops short of ChangeCount
+	Error            string             `json:"error,omitempty"`
+	StartedAt        time.Time          `json:"started_at"`
+	FinishedAt       *time.Time         `json:"finished_at,omitempty"`
+}
+
+// ReclassifyChange is one record a reclassification moved to a new bucket
+type ReclassifyChange struct {
+	RunID   string `json:"run_id,omitempty"`
+	Kind    string `json:"kind"`   // analysis_issue, tracked_issue, aggregate, ticket
+	Record  string `json:"record"` // Call ID, gluser_id/issue_id, date, or date/ticket_id
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Method  string `json:"method,omitempty"`  // How an issue's bucket was chosen
+	Problem string `json:"problem,omitempty"` // The issue's problem, for issues
+}
@@ -0,0 +1,34 @@
+package client
+
+import "time"
+
+// Recording is a call's audio, downloaded from its call_recording_url and
+// linked to the analysis by call ID
+type Recording struct {
+	CallID      string     `json:"call_id"`
+	SellerID    string     `json:"seller_id"`
+	Timestamp   time.Time  `json:"timestamp"` // When the call happened; retention counts from here
+	SourceURL   string     `json:"source_url"`
+	Key         string     `json:"key"` // Object key in the recording store
+	ContentType string     `json:"content_type"`
+	SizeBytes   int64      `json:"size_bytes"`
+	FetchedAt   time.Time  `json:"fetched_at"`
+	PurgedAt    *time.Time `json:"purged_at,omitempty"` // Audio deleted by retention; the record stays
+}
+
+// RecordingLink is a short-lived signed URL for a call's audio (GET /calls/{id}/recording)
+type RecordingLink struct {
+	CallID      string    `json:"call_id"`
+	URL         string    `json:"url"` // Relative to the API base URL, usable without an API key until ExpiresAt
+	ExpiresAt   time.Time `json:"expires_at"`
+	ContentType string    `json:"content_type"`
+	SizeBytes   int64     `json:"size_bytes"`
+}
+
+// RecordingPurgeResult summarizes a retention run (POST /recordings/purge)
+type RecordingPurgeResult struct {
+	Cutoff     time.Time `json:"cutoff"`
+	Purged     int       `json:"purged"`
+	BytesFreed int64     `json:"bytes_freed"`
+	Failed     int       `json:"failed"`
+}
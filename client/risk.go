@@ -0,0 +1,84 @@
+package client
+
+import "time"
+
+// Health score factors (RiskFactor.Factor), in the order they're applied
+const (
+	FactorSentiment       = "sentiment"
+	FactorSatisfaction    = "satisfaction"
+	FactorChurnRisk       = "churn_risk"
+	FactorOpenIssues      = "open_issues"
+	FactorRecurringIssues = "recurring_issues"
+	FactorTrend           = "trend"
+	FactorCaseClosed      = "case_closed"
+)
+
+// RiskExplanation breaks a seller's health score and churn risk down into
+// what produced them (GET /sellers/{id}/risk-explanation)
+type RiskExplanation struct {
+	GluserID           string           `json:"gluser_id"`
+	HealthScore        int              `json:"health_score"`
+	HealthLabel        string           `json:"health_label"`
+	BaseScore          int              `json:"base_score"`      // Every seller starts here
+	Factors            []RiskFactor     `json:"factors"`         // Added to the base score, in order
+	UnclampedScore     int              `json:"unclamped_score"` // Base plus factors, before clamping to 0-100
+	Stale              bool             `json:"stale,omitempty"` // The factors don't give health_score: weights changed since it was scored
+	Issues             []RiskIssue      `json:"issues"`          // The open issues behind open_issues and recurring_issues, heaviest first
+	Churn              ChurnExplanation `json:"churn"`
+	NeedsAttention     bool             `json:"needs_attention"`
+	AttentionReason    string           `json:"attention_reason,omitempty"`
+	AttentionThreshold int              `json:"attention_threshold"` // Health score below which the seller's journey stage needs attention
+	CallID             string           `json:"call_id,omitempty"`   // The latest call, which sentiment, satisfaction and churn come from
+	GeneratedAt        time.Time        `json:"generated_at"`
+}
+
+// RiskFactor is one factor's contribution to the health score
+type RiskFactor struct {
+	Factor string  `json:"factor"`
+	Value  string  `json:"value"`            // As on the profile: sentiment, score, churn risk, issue count or trend
+	Weight float64 `json:"weight,omitempty"` // Points per satisfaction point, or the summed weights of the issues counted
+	Points float64 `json:"points"`           // Added to the score; negative lowers it
+	Cap    float64 `json:"cap,omitempty"`    // Most points the factor can take off
+	Detail string  `json:"detail"`
+}
+
+// RiskIssue is an open issue's share of the health score penalty. Its
+// weight is its bucket weight times its severity multiplier
+// (HEALTH_BUCKET_WEIGHTS, HEALTH_SEVERITY_MULTIPLIERS).
+type RiskIssue struct {
+	IssueID   string  `json:"issue_id"`
+	Bucket    string  `json:"bucket"`
+	Problem   string  `json:"problem"`
+	Severity  string  `json:"severity"`
+	Recurring bool    `json:"recurring"`
+	Weight    float64 `json:"weight"`
+	Points    float64 `json:"points"` // -5 per unit of weight, -10 more when recurring, before the open_issues cap
+}
+
+// ChurnExplanation is where a seller's churn risk comes from. The scoring
+// pass weighs the latest call's signals itself, so they're listed as found
+// rather than with points.
+type ChurnExplanation struct {
+	Risk               string          `json:"risk"`                 // low, medium, high
+	Source             string          `json:"source"`               // model or override
+	ModelRisk          string          `json:"model_risk,omitempty"` // The latest call's, when overridden
+	Override           *StatusOverride `json:"override,omitempty"`
+	RenewalProbability float64         `json:"renewal_probability"`
+	RenewalAtRisk      bool            `json:"renewal_at_risk"`
+	Dissatisfaction    string          `json:"dissatisfaction_level,omitempty"`
+	Reason             string          `json:"reason,omitempty"`
+	ReasonCategory     string          `json:"reason_category,omitempty"`
+	Signals            []RiskSignal    `json:"signals"` // Stated on the latest call, from its extraction
+}
+
+// Churn risk sources (ChurnExplanation.Source)
+const (
+	ChurnFromModel    = "model"
+	ChurnFromOverride = "override"
+)
+
+// RiskSignal is a structured churn signal the extraction found on a call
+type RiskSignal struct {
+	Signal string `json:"signal"` // e.g. cancellation_threatened, competitors_mentioned
+	Value  string `json:"value"`  // true, or what was said
+}
@@ -0,0 +1,81 @@
+package client
+
+import "time"
+
+// Rollout statuses
+const (
+	RolloutActive     = "active"
+	RolloutPaused     = "paused"
+	RolloutRolledBack = "rolled_back"
+	RolloutCompleted  = "completed"
+)
+
+// Rollout is a canary of a prompt/model change: a share of production
+// analyses is made by the candidate, and the rollout rolls back on its own
+// if the candidate's analyses go wrong (/admin/rollouts)
+type Rollout struct {
+	ID             string            `json:"id"`
+	Model          string            `json:"model,omitempty"`           // Candidate Gemini model, empty for the primary's
+	PromptRegistry string            `json:"prompt_registry,omitempty"` // Candidate prompt registry path, empty for the primary's
+	Percent        int               `json:"percent"`                   // Share of analyses routed to the candidate
+	Status         string            `json:"status"`                    // active, paused, rolled_back, completed
+	Thresholds     RolloutThresholds `json:"thresholds"`
+	Metrics        *RolloutMetrics   `json:"metrics,omitempty"` // As of the last evaluation
+	Reason         string            `json:"reason,omitempty"`  // Why it was rolled back, paused or completed
+	By             string            `json:"by,omitempty"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+	EndedAt        *time.Time        `json:"ended_at,omitempty"`
+}
+
+// RolloutThresholds are the limits that roll a rollout back. Deltas are
+// the candidate's analyses minus the primary's over the same time, in
+// either direction.
+type RolloutThresholds struct {
+	MinCalls              int     `json:"min_calls"`                // Candidate analyses needed before the rollout is judged
+	MaxParseFailureRate   float64 `json:"max_parse_failure_rate"`   // 0-1
+	MaxSatisfactionDelta  float64 `json:"max_satisfaction_delta"`   // Mean satisfaction, 1-10
+	MaxIssueCountDelta    float64 `json:"max_issue_count_delta"`    // Mean issues per call
+	MaxNegativeShareDelta float64 `json:"max_negative_share_delta"` // Share of negative-sentiment calls, 0-1
+}
+
+// RolloutMetrics compares the candidate's analyses with the primary's
+// since the rollout was created
+type RolloutMetrics struct {
+	Candidate          RolloutArm `json:"candidate"`
+	Primary            RolloutArm `json:"primary"`
+	SatisfactionDelta  float64    `json:"satisfaction_delta"`
+	IssueCountDelta    float64    `json:"issue_count_delta"`
+	NegativeShareDelta float64    `json:"negative_share_delta"`
+	Breaches           []string   `json:"breaches,omitempty"` // Thresholds exceeded
+	EvaluatedAt        time.Time  `json:"evaluated_at"`
+}
+
+// RolloutArm sums up the analyses made by one side of a rollout
+type RolloutArm struct {
+	Calls            int     `json:"calls"`
+	ParseFailures    int     `json:"parse_failures"`
+	ParseFailureRate float64 `json:"parse_failure_rate"`
+	MeanSatisfaction float64 `json:"mean_satisfaction"` // Over parsed analyses
+	MeanIssueCount   float64 `json:"mean_issue_count"`
+	NegativeShare    float64 `json:"negative_share"`
+}
+
+// RolloutRequest is the body of POST /admin/rollouts. Thresholds left out,
+// or zero, take the defaults.
+type RolloutRequest struct {
+	Model          string             `json:"model,omitempty"`
+	PromptRegistry string             `json:"prompt_registry,omitempty"`
+	Percent        int                `json:"percent"`
+	Thresholds     *RolloutThresholds `json:"thresholds,omitempty"`
+	By             string             `json:"by,omitempty"` // Defaults to the API key's name
+}
+
+// RolloutUpdate is the body of PATCH /admin/rollouts/{id}: a new share of
+// analyses, and/or a status to move to
+type RolloutUpdate struct {
+	Percent *int   `json:"percent,omitempty"`
+	Status  string `json:"status,omitempty"` // active, paused, rolled_back or completed
+	Reason  string `json:"reason,omitempty"`
+	By      string `json:"by,omitempty"`
+}
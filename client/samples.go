@@ -0,0 +1,80 @@
+package client
+
+import "time"
+
+// Sample review states
+const (
+	SamplePending  = "pending" // Generated, waiting for a reviewer
+	SampleApproved = "approved"
+	SampleRejected = "rejected"
+)
+
+// SampleStatuses lists the sample review states
+var SampleStatuses = []string{SamplePending, SampleApproved, SampleRejected}
+
+// CallSample is an anonymized example call for training support agents and
+// demoing the product (GET /samples). Its transcript and analysis text went
+// through the redaction pipeline, and it's only served once a reviewer
+// approved it.
+type CallSample struct {
+	ID            string         `json:"id"`
+	CallID        string         `json:"call_id,omitempty"`   // Source call, only shown to reviewers
+	GluserID      string         `json:"gluser_id,omitempty"` // Source seller, only shown to reviewers
+	FeatureBucket string         `json:"feature_bucket"`      // Of the call's most severe issue, empty for calls without issues
+	Sentiment     string         `json:"sentiment"`           // Positive, Neutral, Negative
+	Channel       string         `json:"channel,omitempty"`
+	CustomerType  string         `json:"customer_type,omitempty"`
+	Vertical      string         `json:"vertical,omitempty"`
+	Language      string         `json:"original_language,omitempty"`
+	Transcript    string         `json:"transcript"` // transcript_en with personal details masked
+	Analysis      SampleAnalysis `json:"analysis"`
+	Redactions    int            `json:"redactions"` // Details masked across the transcript and analysis
+	Status        string         `json:"status"`     // pending, approved, rejected
+	Note          string         `json:"note,omitempty"`
+	CreatedBy     string         `json:"created_by,omitempty"`
+	CreatedAt     time.Time      `json:"created_at"`
+	ReviewedBy    string         `json:"reviewed_by,omitempty"`
+	ReviewedAt    *time.Time     `json:"reviewed_at,omitempty"`
+}
+
+// SampleAnalysis is the part of a call's analysis a sample keeps, text
+// redacted
+type SampleAnalysis struct {
+	Summary           string        `json:"summary"`
+	Issues            []SampleIssue `json:"issues"`
+	Sentiment         string        `json:"sentiment"`
+	SatisfactionScore int           `json:"satisfaction_score"`
+	PromptResolution  bool          `json:"prompt_resolution"`
+	ChurnRisk         string        `json:"churn_risk,omitempty"` // low, medium, high
+	AgentPerformance  string        `json:"agent_performance,omitempty"`
+}
+
+// SampleIssue is an issue of a sample's call, text redacted
+type SampleIssue struct {
+	Bucket            string   `json:"bucket"`
+	Severity          string   `json:"severity"`
+	Problem           string   `json:"problem"`
+	ActionableSummary string   `json:"actionable_summary,omitempty"`
+	Evidence          []string `json:"evidence,omitempty"` // Quotes from the redacted transcript
+}
+
+// SampleRequest is the body of POST /samples
+type SampleRequest struct {
+	CallID string `json:"call_id"`
+	Note   string `json:"note,omitempty"`
+	By     string `json:"by,omitempty"` // Defaults to the API key's name
+}
+
+// SampleReview is the body of PATCH /samples/{id}
+type SampleReview struct {
+	Status string `json:"status"` // approved, rejected, or pending to take back a decision
+	Note   string `json:"note,omitempty"`
+	By     string `json:"by,omitempty"` // Defaults to the API key's name
+}
+
+// SampleList is the response of GET /samples
+type SampleList struct {
+	Status  string       `json:"status"`
+	Samples []CallSample `json:"samples"`
+	Count   int          `json:"count"`
+}
@@ -0,0 +1,38 @@
+package client
+
+// Self-test stages, in the order they run
+const (
+	SelfTestIngest    = "ingest"    // Synthetic transcripts stored and read back
+	SelfTestAnalysis  = "analysis"  // Analyzed, stored and read back
+	SelfTestProfile   = "profile"   // The synthetic seller's profile built from the analyses
+	SelfTestAggregate = "aggregate" // The reserved date aggregated, stored and read back
+	SelfTestTicket    = "ticket"    // Tickets generated from the aggregate, stored and read back
+	SelfTestCleanup   = "cleanup"   // Everything the test stored removed
+)
+
+// Self-test stage statuses
+const (
+	SelfTestPass = "pass"
+	SelfTestFail = "fail"
+	SelfTestSkip = "skip" // An earlier stage failed
+)
+
+// SelfTestReport is the result of POST /admin/selftest: synthetic calls run
+// through the pipeline, stage by stage, to verify a deployment
+type SelfTestReport struct {
+	OK         bool            `json:"ok"`  // Every stage passed
+	LLM        string          `json:"llm"` // rules (the keyword classifier) or gemini
+	SellerID   string          `json:"seller_id"`
+	Date       string          `json:"date"` // The reserved date the calls are placed on
+	Stages     []SelfTestStage `json:"stages"`
+	DurationMS int64           `json:"duration_ms"`
+}
+
+// SelfTestStage is one stage of a self-test
+type SelfTestStage struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"` // pass, fail, skip
+	Detail     string `json:"detail,omitempty"`
+	Error      string `json:"error,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+}
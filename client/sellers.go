@@ -0,0 +1,63 @@
+package client
+
+import (
+	"maps"
+	"time"
+)
+
+// SellerSummary is a seller's row in GET /sellers, kept up to date with
+// every profile write so the listing doesn't read full profiles
+type SellerSummary struct {
+	GluserID       string         `json:"gluser_id"`
+	CustomerType   string         `json:"customer_type"`
+	CityName       string         `json:"city_name,omitempty"`
+	Vertical       string         `json:"vertical,omitempty"`
+	JourneyStage   string         `json:"journey_stage,omitempty"`
+	TotalCalls     int            `json:"total_calls"`
+	HealthScore    int            `json:"health_score"`
+	HealthLabel    string         `json:"health_label"`
+	ChurnRisk      string         `json:"churn_risk"`
+	OpenIssues     int            `json:"open_issues"`
+	NeedsAttention bool           `json:"needs_attention"`
+	LastCallAt     time.Time      `json:"last_call_at"`
+	UpdatedAt      time.Time      `json:"updated_at"` // When the profile was last written
+	CustomFields   map[string]any `json:"custom_fields,omitempty"`
+}
+
+// Summary returns the profile's seller list row
+func (p *SellerProfile) Summary() SellerSummary {
+	return SellerSummary{
+		GluserID:       p.GluserID,
+		CustomerType:   p.CustomerType,
+		CityName:       p.CityName,
+		Vertical:       p.Vertical,
+		JourneyStage:   p.JourneyStage,
+		TotalCalls:     p.TotalCalls,
+		HealthScore:    p.CurrentStatus.HealthScore,
+		HealthLabel:    p.CurrentStatus.HealthLabel,
+		ChurnRisk:      p.CurrentStatus.ChurnRisk,
+		OpenIssues:     p.CurrentStatus.OpenIssueCount,
+		NeedsAttention: p.CurrentStatus.NeedsAttention,
+		LastCallAt:     p.LastCallAt.UTC(),
+		UpdatedAt:      p.UpdatedAt.UTC(),
+		CustomFields:   maps.Clone(p.CustomFields),
+	}
+}
+
+// Seller list orders
+const (
+	SellerSortLastCall   = "last_call"   // Most recently called first (the default)
+	SellerSortHealth     = "health"      // Lowest health score first
+	SellerSortOpenIssues = "open_issues" // Most open issues first
+)
+
+// SellerPage is one page of GET /sellers
+type SellerPage struct {
+	Sellers             []SellerSummary `json:"sellers"`
+	Count               int             `json:"count"`
+	TotalCount          int             `json:"total_count"`           // Sellers matching the filters
+	NeedsAttentionCount int             `json:"needs_attention_count"` // Of those, sellers needing attention
+	Page                int             `json:"page"`                  // 1-based
+	PageSize            int             `json:"page_size"`
+	HasMore             bool            `json:"has_more"`
+}
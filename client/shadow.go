@@ -0,0 +1,62 @@
+package client
+
+import "time"
+
+// ShadowAnalysis is a call analyzed a second time by the candidate model or
+// prompts under evaluation (GET /calls/{id}/shadow). It's stored apart from
+// the call's analysis and never touches the seller's profile.
+type ShadowAnalysis struct {
+	CallID     string            `json:"call_id"`
+	SellerID   string            `json:"seller_id"`
+	Timestamp  time.Time         `json:"timestamp"` // When the call happened
+	Candidate  string            `json:"candidate"` // SHADOW_VERSION, the candidate's model if unset
+	Model      string            `json:"model"`
+	Analysis   *AnalysisResult   `json:"analysis,omitempty"`
+	Comparison *ShadowComparison `json:"comparison,omitempty"`
+	Error      string            `json:"error,omitempty"` // Set instead of the analysis when the candidate failed
+	AnalyzedAt time.Time         `json:"analyzed_at"`
+}
+
+// ShadowComparison is how a candidate analysis differs from the primary one
+type ShadowComparison struct {
+	SentimentMatch        bool     `json:"sentiment_match"`
+	ChurnMatch            bool     `json:"churn_match"` // Same churn risk level
+	AgentPerformanceMatch bool     `json:"agent_performance_match"`
+	EscalationMatch       bool     `json:"escalation_match"`
+	SatisfactionDelta     int      `json:"satisfaction_delta"`       // Candidate - primary
+	IssueCountDelta       int      `json:"issue_count_delta"`        // Candidate - primary
+	BucketOverlap         float64  `json:"bucket_overlap"`           // Jaccard similarity of the buckets raised, 1 when neither raised any
+	BucketsAdded          []string `json:"buckets_added,omitempty"`  // Raised only by the candidate
+	BucketsMissed         []string `json:"buckets_missed,omitempty"` // Raised only by the primary
+}
+
+// ShadowReport sums up how a candidate's analyses compare with the primary
+// ones over a date range (GET /shadow/report). Rates are 0-1 over the
+// compared calls.
+type ShadowReport struct {
+	From                      string              `json:"from"`
+	To                        string              `json:"to"`
+	Candidate                 string              `json:"candidate,omitempty"` // Empty when the report covers every candidate
+	Enabled                   bool                `json:"enabled"`             // Calls are being shadowed now
+	Percent                   int                 `json:"percent"`             // Share of calls shadowed, SHADOW_PERCENT
+	Calls                     int                 `json:"calls"`
+	Failed                    int                 `json:"failed"` // Candidate analyses that errored
+	Compared                  int                 `json:"compared"`
+	SentimentAgreement        float64             `json:"sentiment_agreement"`
+	ChurnAgreement            float64             `json:"churn_agreement"`
+	AgentPerformanceAgreement float64             `json:"agent_performance_agreement"`
+	EscalationAgreement       float64             `json:"escalation_agreement"`
+	MeanSatisfactionDelta     float64             `json:"mean_satisfaction_delta"`
+	MeanAbsSatisfactionDelta  float64             `json:"mean_abs_satisfaction_delta"`
+	MeanIssueCountDelta       float64             `json:"mean_issue_count_delta"`
+	MeanBucketOverlap         float64             `json:"mean_bucket_overlap"`
+	Buckets                   []ShadowBucketCount `json:"buckets"` // Most disagreement first
+	GeneratedAt               time.Time           `json:"generated_at"`
+}
+
+// ShadowBucketCount is how many compared calls each side raised a bucket on
+type ShadowBucketCount struct {
+	Bucket    string `json:"bucket"`
+	Primary   int    `json:"primary"`
+	Candidate int    `json:"candidate"`
+}
@@ -0,0 +1,42 @@
+package client
+
+import "time"
+
+// TicketSuppression mutes ticket generation for a feature bucket, for issues
+// whose problem matches a pattern, or for issues in a bucket matching a
+// pattern when both are set. Muted issues still count in the daily
+// aggregate and are listed in its Suppressed.
+type TicketSuppression struct {
+	ID        string    `json:"id"`
+	Bucket    string    `json:"bucket,omitempty"`  // Feature bucket, every bucket if empty
+	Pattern   string    `json:"pattern,omitempty"` // Case-insensitive regular expression matched against issue problems
+	Until     string    `json:"until,omitempty"`   // Last business date (YYYY-MM-DD) muted, no end if empty
+	Reason    string    `json:"reason,omitempty"`
+	By        string    `json:"by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ActiveOn reports whether the rule applies to an aggregation of date
+func (s TicketSuppression) ActiveOn(date string) bool {
+	return s.Until == "" || date <= s.Until
+}
+
+// TicketSuppressionRequest is the body of POST /admin/ticket-suppressions
+type TicketSuppressionRequest struct {
+	Bucket  string `json:"bucket,omitempty"`
+	Pattern string `json:"pattern,omitempty"`
+	Until   string `json:"until,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+	By      string `json:"by,omitempty"` // Defaults to the API key's name
+}
+
+// SuppressedIssues is what one suppression rule kept out of a day's tickets
+type SuppressedIssues struct {
+	SuppressionID  string         `json:"suppression_id"`
+	Bucket         string         `json:"bucket,omitempty"`
+	Pattern        string         `json:"pattern,omitempty"`
+	Issues         int            `json:"issues"`
+	Sellers        int            `json:"sellers"`
+	SystemicIssues int            `json:"systemic_issues,omitempty"` // Systemic issues not ticketed
+	TopProblems    []ProblemCount `json:"top_problems,omitempty"`
+}
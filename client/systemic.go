@@ -0,0 +1,31 @@
+package client
+
+import "time"
+
+// SystemicIssue is a problem many sellers reported in their own words,
+// found by clustering open issues across sellers (GET /systemic-issues)
+type SystemicIssue struct {
+	ID              string           `json:"id"`    // "sys_" + the oldest member's issue_id, stable while that issue stays open
+	Title           string           `json:"title"` // e.g. "TrustSEAL badge not showing after renewal — 43 sellers"
+	Problem         string           `json:"problem"`
+	Bucket          string           `json:"bucket"`   // Most common bucket among the members
+	Severity        string           `json:"severity"` // Highest among the members
+	SellerCount     int              `json:"seller_count"`
+	IssueCount      int              `json:"issue_count"`
+	MentionCount    int              `json:"mention_count"` // Calls mentioning any member
+	Members         []SystemicMember `json:"members"`
+	Impact          *RevenueImpact   `json:"impact,omitempty"` // As of clustering
+	FirstReportedAt time.Time        `json:"first_reported_at"`
+	LastMentionedAt time.Time        `json:"last_mentioned_at"`
+	GeneratedAt     time.Time        `json:"generated_at"`
+}
+
+// SystemicMember links a systemic issue to one seller's tracked issue
+type SystemicMember struct {
+	GluserID   string  `json:"gluser_id"`
+	IssueID    string  `json:"issue_id"`
+	Problem    string  `json:"problem"`
+	Severity   string  `json:"severity"`
+	Status     string  `json:"status"`
+	Similarity float64 `json:"similarity"` // Cosine similarity to the cluster centroid
+}
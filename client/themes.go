@@ -0,0 +1,25 @@
+package client
+
+import "time"
+
+// InsightTheme is a point made across many calls' key insights, e.g.
+// "sellers confused about new lead pricing"
+type InsightTheme struct {
+	Theme    string   `json:"theme"`              // The insight closest to the theme's center
+	Calls    int      `json:"calls"`              // Calls with an insight in the theme
+	Sellers  int      `json:"sellers"`            // Distinct sellers on those calls
+	Insights int      `json:"insights"`           // Insights in the theme
+	Examples []string `json:"examples,omitempty"` // Other ways calls put it, up to 3
+	CallIDs  []string `json:"call_ids"`           // Supporting calls, newest first, up to 20
+}
+
+// ThemeReport is the themes of a week's key insights, the week ending To
+// (GET /analytics/themes)
+type ThemeReport struct {
+	From        string         `json:"from"`
+	To          string         `json:"to"`
+	Calls       int            `json:"calls"`    // Calls with key insights
+	Insights    int            `json:"insights"` // Insights clustered
+	Themes      []InsightTheme `json:"themes"`   // Most calls first
+	GeneratedAt time.Time      `json:"generated_at"`
+}
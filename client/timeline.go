@@ -0,0 +1,41 @@
+package client
+
+import "time"
+
+// Where a timeline entry was read from
+const (
+	TimelineHot     = "hot"     // The analyses store (MongoDB or local files)
+	TimelineArchive = "archive" // The Parquet cold archive
+)
+
+// Archive states of a seller timeline
+const (
+	ArchiveComplete    = "complete"    // Archived calls in the range are included
+	ArchiveLoading     = "loading"     // Too many archive files to read at once; they're being read, ask again
+	ArchiveFailed      = "failed"      // Reading the archive failed; only hot calls are listed
+	ArchiveUnavailable = "unavailable" // No archive is configured
+)
+
+// SellerTimeline is a seller's call history across hot and cold storage,
+// newest first (GET /sellers/{id}/timeline)
+type SellerTimeline struct {
+	GluserID        string          `json:"gluser_id"`
+	From            string          `json:"from,omitempty"`
+	To              string          `json:"to,omitempty"`
+	Entries         []TimelineEntry `json:"entries"`
+	Count           int             `json:"count"`
+	Hot             int             `json:"hot"`
+	Archived        int             `json:"archived"`
+	Channels        map[string]int  `json:"channels"` // Entries by contact channel (voice, chat, whatsapp)
+	ArchiveStatus   string          `json:"archive_status"`
+	ArchiveError    string          `json:"archive_error,omitempty"`
+	ArchiveFiles    int             `json:"archive_files,omitempty"`    // Archive files in the range
+	ArchiveBoundary string          `json:"archive_boundary,omitempty"` // Calls before this date are archived by now (ARCHIVE_AFTER_DAYS)
+	GeneratedAt     time.Time       `json:"generated_at"`
+}
+
+// TimelineEntry is one call on a seller timeline
+type TimelineEntry struct {
+	CallSummary
+	Source string `json:"source"` // hot or archive
+}
@@ -0,0 +1,101 @@
+package client
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// HackathonTranscript represents the actual transcript structure from CSV
+type HackathonTranscript struct {
+	ClickToCallID        string           `json:"click_to_call_id"`
+	GluserID             string           `json:"gluser_id"`
+	VintageMonths        int              `json:"vintage_months"`
+	BLDauOct             int              `json:"bl_dau_oct"`
+	CustomerType         string           `json:"customer_type"`
+	CityName             string           `json:"city_name"`
+	IILVerticalName      string           `json:"iil_vertical_name"`
+	CustomerTicketID     string           `json:"customer_ticket_id"`
+	CustomerTicketStatus string           `json:"customer_ticket_status"`
+	IsTicketRepeat60d    string           `json:"is_ticket_repeat60d"`
+	Transcript           string           `json:"transcript"`
+	Summary              string           `json:"summary"`
+	CallEnteredOn        string           `json:"call_entered_on"`
+	FlagInOut            string           `json:"flag_in_out"`
+	CallStatus           string           `json:"call_status"`
+	CallDuration         int              `json:"call_duration"`
+	CallRecordingURL     string           `json:"call_recording_url"`
+	UCID                 string           `json:"ucid"`
+	SellerCategories     []SellerCategory `json:"seller_categories"`
+	Origin               *CallOrigin      `json:"origin,omitempty"`  // Filled in from the watched source's tags
+	Channel              string           `json:"channel,omitempty"` // voice (the default), chat or whatsapp, as the source spells it
+}
+
+// Checksum identifies the transcript's text. A file rewritten with the same
+// text, e.g. with other metadata corrected, keeps its checksum.
+func (ht *HackathonTranscript) Checksum() string {
+	sum := sha256.Sum256([]byte(ht.Transcript))
+	return hex.EncodeToString(sum[:])
+}
+
+// CallOrigin tags a call with where its transcript came from
+type CallOrigin struct {
+	System string `json:"system,omitempty"` // Upstream source system, e.g. crm
+	Region string `json:"region,omitempty"`
+}
+
+func (o CallOrigin) String() string {
+	if o.Region == "" {
+		return o.System
+	}
+	return o.System + "/" + o.Region
+}
+
+// Normalized call directions and statuses, see CallChannel
+const (
+	DirectionInbound  = "inbound"
+	DirectionOutbound = "outbound"
+
+	CallAnswered  = "answered"
+	CallMissed    = "missed"
+	CallVoicemail = "voicemail"
+)
+
+// Contact channels a transcript can come from. Chat and WhatsApp
+// transcripts are support conversations written rather than spoken.
+const (
+	ChannelVoice    = "voice"
+	ChannelChat     = "chat"
+	ChannelWhatsApp = "whatsapp"
+)
+
+// ContactChannels lists the contact channels
+var ContactChannels = []string{ChannelVoice, ChannelChat, ChannelWhatsApp}
+
+// IsChatChannel reports whether channel is a written one
+func IsChatChannel(channel string) bool {
+	return channel == ChannelChat || channel == ChannelWhatsApp
+}
+
+// ContactChannel returns channel, ChannelVoice when it's empty (analyses
+// stored before channels were recorded)
+func ContactChannel(channel string) string {
+	if channel == "" {
+		return ChannelVoice
+	}
+	return channel
+}
+
+// CallChannel is which way a call went and whether it connected,
+// normalized from the transcript's flag_in_out and call_status
+type CallChannel struct {
+	Direction    string `json:"direction,omitempty"`     // inbound or outbound, empty when RawDirection isn't recognized
+	Status       string `json:"status,omitempty"`        // answered, missed or voicemail, empty when RawStatus isn't recognized
+	RawDirection string `json:"raw_direction,omitempty"` // As the source spelled them
+	RawStatus    string `json:"raw_status,omitempty"`
+}
+
+// SellerCategory represents product category
+type SellerCategory struct {
+	McatID   string `json:"mcat_id"`
+	McatName string `json:"mcat_name"`
+}
@@ -0,0 +1,101 @@
+package client
+
+import (
+	"net/url"
+	"time"
+)
+
+// SellerExportVersion is the SellerExport format written by this version
+const SellerExportVersion = 1
+
+// SellerExport is everything stored about a seller, for moving it between
+// environments (GET /sellers/{id}/export, POST /sellers/import)
+type SellerExport struct {
+	Version     int              `json:"version"`
+	ExportedAt  time.Time        `json:"exported_at"`
+	Profile     *SellerProfile   `json:"profile"` // With its active and resolved issues
+	Analyses    []AnalysisResult `json:"analyses"`
+	Extractions []CallExtraction `json:"extractions,omitempty"`
+}
+
+// SellerImportOptions controls how exports are written into this
+// environment. IDs are remapped before anything is saved.
+type SellerImportOptions struct {
+	SellerID     string // Import under this gluser_id instead (single imports only)
+	SellerPrefix string // Prepended to every gluser_id, e.g. "demo_"
+	CallPrefix   string // Prepended to every call_id, so imported calls can't collide with local ones
+	NewIssueIDs  bool   // Give tracked issues new IDs; always done when the gluser_id changes
+	Overwrite    bool   // Replace sellers that already exist instead of skipping them
+	DryRun       bool   // Report what would be imported without saving
+}
+
+func (o SellerImportOptions) query() url.Values {
+	q := url.Values{}
+	if o.SellerID != "" {
+		q.Set("seller_id", o.SellerID)
+	}
+	if o.SellerPrefix != "" {
+		q.Set("seller_prefix", o.SellerPrefix)
+	}
+	if o.CallPrefix != "" {
+		q.Set("call_prefix", o.CallPrefix)
+	}
+	if o.NewIssueIDs {
+		q.Set("new_issue_ids", "true")
+	}
+	if o.Overwrite {
+		q.Set("overwrite", "true")
+	}
+	if o.DryRun {
+		q.Set("dry_run", "true")
+	}
+	return q
+}
+
+// Seller import outcomes
+const (
+	ImportImported = "imported"
+	ImportSkipped  = "skipped" // Seller already exists and Overwrite is off
+	ImportFailed   = "failed"
+)
+
+// SellerImportResult reports an import, one entry per export in the request
+type SellerImportResult struct {
+	Imported int                `json:"imported"`
+	Skipped  int                `json:"skipped"`
+	Failed   int                `json:"failed"`
+	DryRun   bool               `json:"dry_run,omitempty"`
+	Sellers  []SellerImportItem `json:"sellers"`
+}
+
+// SellerImportItem is the outcome of importing one export
+type SellerImportItem struct {
+	Line        int    `json:"line,omitempty"` // Position in an NDJSON body
+	SourceID    string `json:"source_id"`      // gluser_id in the export
+	GluserID    string `json:"gluser_id"`      // gluser_id it was imported as
+	Status      string `json:"status"`
+	Analyses    int    `json:"analyses"`
+	Extractions int    `json:"extractions"`
+	Issues      int    `json:"issues"`
+	Error       string `json:"error,omitempty"`
+}
+
+// AnalysisImportResult reports a bulk analysis import (POST /import/analyses)
+type AnalysisImportResult struct {
+	Lines    int                   `json:"lines"`
+	Imported int                   `json:"imported"`
+	Skipped  int                   `json:"skipped"` // Calls already analyzed, or repeated in the body
+	Failed   int                   `json:"failed"`
+	DryRun   bool                  `json:"dry_run,omitempty"`
+	Sellers  int                   `json:"sellers"` // Profiles built or updated
+	Dates    []string              `json:"dates"`   // Dates re-aggregated
+	Problems []AnalysisImportIssue `json:"problems"`
+}
+
+// AnalysisImportIssue is a line that was skipped or failed
+type AnalysisImportIssue struct {
+	Line   int    `json:"line"`
+	CallID string `json:"call_id,omitempty"`
+	Status string `json:"status"` // skipped, failed
+	Error  string `json:"error"`
+}
@@ -0,0 +1,33 @@
+package client
+
+import "time"
+
+// Kinds of soft-deleted items
+const (
+	TrashAnalysis = "analysis"
+	TrashTicket   = "ticket"
+)
+
+// TrashItem is a soft-deleted analysis or ticket, restorable until PurgeAt
+type TrashItem struct {
+	Kind      string          `json:"kind"` // analysis or ticket
+	ID        string          `json:"id"`   // Call ID, or date_ticketID for tickets
+	DeletedAt time.Time       `json:"deleted_at"`
+	Reason    string          `json:"reason,omitempty"`
+	PurgeAt   time.Time       `json:"purge_at"` // When the retention job deletes it for good
+	GluserID  string          `json:"gluser_id,omitempty"`
+	Date      string          `json:"date,omitempty"` // Ticket date
+	Analysis  *AnalysisResult `json:"analysis,omitempty"`
+	Ticket    *Ticket         `json:"ticket,omitempty"`
+}
+
+// TrashList is the response of GET /trash, most recently deleted first
+type TrashList struct {
+	Items []TrashItem `json:"items"`
+	Total int         `json:"total"`
+}
+
+// TicketTrashID returns the trash ID of a ticket
+func TicketTrashID(date, ticketID string) string {
+	return date + "_" + ticketID
+}
@@ -0,0 +1,42 @@
+package client
+
+import "time"
+
+// Trend granularities for GET /sellers/{gluser_id}/trends
+const (
+	GranularityCall  = "call"
+	GranularityDay   = "day"
+	GranularityWeek  = "week"
+	GranularityMonth = "month"
+)
+
+// SellerMetric is the trend data of a single call. Profiles only keep recent
+// calls at full resolution; every call's metric is kept in seller_metrics.
+type SellerMetric struct {
+	GluserID       string    `json:"gluser_id"`
+	CallID         string    `json:"call_id"`
+	Timestamp      time.Time `json:"timestamp"`
+	Date           string    `json:"date"`            // "2025-12-12"
+	Sentiment      float64   `json:"sentiment"`       // 0 negative, 0.5 neutral, 1 positive
+	SentimentLabel string    `json:"sentiment_label"` // Positive, Neutral, Negative
+	Satisfaction   float64   `json:"satisfaction"`    // 1-10
+	IssueCount     float64   `json:"issue_count"`
+	ChurnRisk      float64   `json:"churn_risk"` // 0 low, 0.5 medium, 1 high
+	ChurnLabel     string    `json:"churn_label"`
+	RecordedAt     time.Time `json:"recorded_at"` // Latest recording wins when a call is re-analyzed
+}
+
+// TrendSeries is a seller's trend history at one granularity. Day, week and
+// month points average the calls in the bucket, with Count set to the number
+// of calls; their Date is the first day of the bucket.
+type TrendSeries struct {
+	GluserID            string       `json:"gluser_id"`
+	Granularity         string       `json:"granularity"`
+	From                string       `json:"from,omitempty"` // Requested range, YYYY-MM-DD
+	To                  string       `json:"to,omitempty"`
+	Calls               int          `json:"calls"`
+	SentimentHistory    []TrendPoint `json:"sentiment_history"`
+	SatisfactionHistory []TrendPoint `json:"satisfaction_history"`
+	IssueHistory        []TrendPoint `json:"issue_history"`
+	ChurnRiskHistory    []TrendPoint `json:"churn_risk_history"`
+}
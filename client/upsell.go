@@ -0,0 +1,118 @@
+package client
+
+import "time"
+
+// UpsellPipeline groups upsell opportunities by product SKU over a date
+// range (GET /analytics/upsell-pipeline). Values are in rupees.
+type UpsellPipeline struct {
+	From          string            `json:"from"`
+	To            string            `json:"to"`
+	Opportunities int               `json:"opportunities"` // Calls with an upsell opportunity
+	Sellers       int               `json:"sellers"`
+	PipelineValue int               `json:"pipeline_value"` // Sum over SKUs of sellers x deal value
+	WeightedValue int               `json:"weighted_value"` // The same, each seller weighted by upsell score / 10
+	SKUs          []UpsellSKU       `json:"skus"`           // Every product, highest weighted value first
+	Unmapped      []UnmappedFeature `json:"unmapped"`       // Interested features that named no product, most common first
+	GeneratedAt   time.Time         `json:"generated_at"`
+}
+
+// UpsellSKU is the pipeline for one product
+type UpsellSKU struct {
+	SKU           string       `json:"sku"`
+	Name          string       `json:"name"`
+	DealValue     int          `json:"deal_value"` // Annual price; 0 when the product has no configured price
+	Opportunities int          `json:"opportunities"`
+	Sellers       int          `json:"sellers"`
+	PipelineValue int          `json:"pipeline_value"`
+	WeightedValue int          `json:"weighted_value"`
+	TopSellers    []UpsellLead `json:"top_sellers,omitempty"` // Highest upsell score first
+}
+
+// UpsellLead is a seller interested in a product
+type UpsellLead struct {
+	GluserID    string    `json:"gluser_id"`
+	Score       int       `json:"score"`       // Best upsell score in the range
+	Willingness string    `json:"willingness"` // Willingness to invest on that call
+	LastCallAt  time.Time `json:"last_call_at"`
+}
+
+// UnmappedFeature is a feature mention the product mapping doesn't cover
+type UnmappedFeature struct {
+	Feature string `json:"feature"`
+	Count   int    `json:"count"`
+}
+
+// Seller responses to an upsell pitch
+const (
+	PitchAccepted   = "accepted"    // Agreed to buy or upgrade
+	PitchInterested = "interested"  // Asked for details or a quote
+	PitchDeferred   = "deferred"    // Not now, e.g. after the season or at renewal
+	PitchDeclined   = "declined"    // Said no
+	PitchNoResponse = "no_response" // Didn't take it up either way
+)
+
+// Upsell pitch statuses
+const (
+	PitchOpen      = "open"
+	PitchConverted = "converted" // The seller moved to a higher tier within UPSELL_CONVERSION_DAYS
+	PitchLapsed    = "lapsed"    // They didn't
+)
+
+// UpsellPitch is an upsell the agent pitched on a call and how the seller
+// took it, as extracted
+type UpsellPitch struct {
+	Products []string `json:"products"`       // Products or plans pitched, as said
+	SKUs     []string `json:"skus,omitempty"` // Catalog products they map to
+	Response string   `json:"response"`       // accepted, interested, deferred, declined, no_response
+	Quote    string   `json:"quote,omitempty"`
+}
+
+// TrackedPitch is an upsell pitch followed until the seller's customer type
+// moves to a higher tier on a later call (converted), or
+// UPSELL_CONVERSION_DAYS pass without it (lapsed)
+type TrackedPitch struct {
+	ID           string    `json:"id"` // The call ID, so re-analyzing a call updates its pitch
+	GluserID     string    `json:"gluser_id"`
+	AgentID      string    `json:"agent_id,omitempty"`
+	CallID       string    `json:"call_id"`
+	CallTime     time.Time `json:"call_time"`
+	Products     []string  `json:"products"`
+	SKUs         []string  `json:"skus,omitempty"`
+	Response     string    `json:"response"`
+	Quote        string    `json:"quote,omitempty"`
+	CustomerType string    `json:"customer_type,omitempty"` // The seller's when pitched
+	Tier         string    `json:"tier"`                    // Its customer tier
+
+	Status          string     `json:"status"`                      // open, converted, lapsed
+	SettledAt       *time.Time `json:"settled_at,omitempty"`        // When it converted or lapsed
+	ConvertedCallID string     `json:"converted_call_id,omitempty"` // The later call showing the new customer type
+	ConvertedTo     string     `json:"converted_to,omitempty"`      // That customer type
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PitchTally counts the pitches of one agent, seller response or product
+type PitchTally struct {
+	AgentID  string `json:"agent_id,omitempty"`
+	Response string `json:"response,omitempty"`
+	SKU      string `json:"sku,omitempty"`
+
+	Pitches             int     `json:"pitches"`
+	Open                int     `json:"open"`
+	Converted           int     `json:"converted"`
+	Lapsed              int     `json:"lapsed"`
+	ConversionRate      float64 `json:"conversion_rate"`                  // Converted over settled (converted + lapsed)
+	MedianDaysToConvert float64 `json:"median_days_to_convert,omitempty"` // From the pitch to the call showing the upgrade
+}
+
+// UpsellPitchReport is the response of GET /upsell-pitches. The tallies
+// cover every pitch matching the filters, the list only the newest.
+type UpsellPitchReport struct {
+	Pitches     []TrackedPitch `json:"pitches"`
+	Count       int            `json:"count"`
+	Total       int            `json:"total"`
+	ByAgent     []PitchTally   `json:"by_agent"`    // Most conversions first
+	ByResponse  []PitchTally   `json:"by_response"` // Most conversions first
+	BySKU       []PitchTally   `json:"by_sku"`      // Most conversions first; pitches naming no catalog product under "unmapped"
+	GeneratedAt time.Time      `json:"generated_at"`
+}
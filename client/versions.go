@@ -0,0 +1,20 @@
+package client
+
+import "time"
+
+// AnalysisVersion is an earlier analysis of a call, superseded when its
+// transcript was rewritten and analyzed again (GET /calls/{id}/versions)
+type AnalysisVersion struct {
+	CallID       string         `json:"call_id"`
+	Version      int            `json:"version"`
+	Checksum     string         `json:"transcript_checksum,omitempty"` // Empty for analyses from before checksums
+	SupersededAt time.Time      `json:"superseded_at"`
+	Analysis     AnalysisResult `json:"analysis"`
+}
+
+// CallVersions is a call's current analysis version and the ones it replaced
+type CallVersions struct {
+	CallID         string            `json:"call_id"`
+	CurrentVersion int               `json:"current_version"`
+	Versions       []AnalysisVersion `json:"versions"` // Oldest first
+}
@@ -0,0 +1,96 @@
+package client
+
+import "time"
+
+// Watcher aggregation date modes (AGGREGATE_DATE)
+const (
+	AggregateDateAnalysis = "analysis" // Each analysis counts towards its own date (default)
+	AggregateDateToday    = "today"    // Every analysis counts towards today
+)
+
+// Analysis fields daily aggregates group by (AGGREGATE_DATE_FIELD)
+const (
+	AggregateFieldCall     = "call"     // timestamp, when the call happened (default)
+	AggregateFieldAnalyzed = "analyzed" // analyzed_at, when it was analyzed
+)
+
+// Watcher aggregation triggers
+const (
+	AggregateTriggerThreshold = "threshold"
+	AggregateTriggerDebounce  = "debounce"
+)
+
+// WatcherStatus is the transcript watcher's aggregation state (GET /admin/watcher)
+type WatcherStatus struct {
+	Running         bool               `json:"running"`
+	Threshold       int                `json:"threshold"`
+	Debounce        string             `json:"debounce"` // "0s" when disabled
+	DateMode        string             `json:"date_mode"`
+	DateField       string             `json:"date_field"`
+	MoveFiles       bool               `json:"move_files"`   // Processed and failed transcripts move out of the watched directories
+	MaxAttempts     int                `json:"max_attempts"` // Failed attempts before a transcript moves to the failed folder
+	Pending         []PendingAggregate `json:"pending"`      // Oldest date first
+	LastAggregation *AggregationRun    `json:"last_aggregation,omitempty"`
+	Sources         []WatchedSource    `json:"sources"` // TRANSCRIPTS_DIR first, then TRANSCRIPT_SOURCES
+}
+
+// WatchedSource is a directory the watcher picks transcripts up from
+type WatchedSource struct {
+	Dir    string `json:"dir"`
+	System string `json:"system,omitempty"`
+	Region string `json:"region,omitempty"`
+}
+
+// PendingAggregate counts the new analyses of a date not yet aggregated
+type PendingAggregate struct {
+	Date           string     `json:"date"`
+	NewAnalyses    int        `json:"new_analyses"`
+	LastAnalysisAt time.Time  `json:"last_analysis_at"`
+	DueAt          *time.Time `json:"due_at,omitempty"` // When the debounce fires, unset if disabled
+}
+
+// AggregationRun is an aggregation the watcher triggered
+type AggregationRun struct {
+	Date        string    `json:"date"`
+	Trigger     string    `json:"trigger"` // threshold, debounce
+	NewAnalyses int       `json:"new_analyses"`
+	StartedAt   time.Time `json:"started_at"`
+	Duration    string    `json:"duration"`
+	Error       string    `json:"error,omitempty"`
+}
+
+// PipelineStats is the transcript backlog and processing capacity (GET /admin/pipeline/stats).
+// Averages cover the last PIPELINE_STATS_WINDOW transcripts and LLM requests.
+type PipelineStats struct {
+	WatcherRunning       bool            `json:"watcher_running"`
+	PendingTranscripts   int             `json:"pending_transcripts"`
+	OldestPendingAt      *time.Time      `json:"oldest_pending_at,omitempty"` // Modification time of the oldest unprocessed file
+	OldestPendingSeconds float64         `json:"oldest_pending_age_seconds"`
+	ArrivalsLastHour     int             `json:"arrivals_last_hour"` // Transcript files written in the last hour, processed or not
+	Processed            int             `json:"processed"`          // Since startup
+	Failed               int             `json:"failed"`             // Since startup; failed files are retried
+	LastProcessedAt      *time.Time      `json:"last_processed_at,omitempty"`
+	RecentAttempts       int             `json:"recent_attempts"`     // Pipeline runs within PIPELINE_FAILURE_WINDOW
+	RecentFailureRate    float64         `json:"recent_failure_rate"` // Share of those that failed
+	AvgProcessingSeconds float64         `json:"avg_processing_seconds"`
+	CapacityPerHour      float64         `json:"capacity_per_hour"` // Transcripts per hour at the average processing time
+	LLMRequests          int             `json:"llm_requests"`
+	LLMErrors            int             `json:"llm_errors"`
+	LLMErrorRate         float64         `json:"llm_error_rate"`
+	CatchUpSeconds       *float64        `json:"catch_up_seconds,omitempty"` // Unset until a transcript has been timed, or while falling behind
+	FallingBehind        bool            `json:"falling_behind"`             // Arrivals outpace capacity
+	FaultsInjected       map[string]int  `json:"faults_injected,omitempty"`  // By fault since startup, with CHAOS_FAULTS set
+	LLMParsing           []LLMParseStats `json:"llm_parsing,omitempty"`      // How analysis responses parsed since startup, per model
+	GeneratedAt          time.Time       `json:"generated_at"`
+}
+
+// LLMParseStats is how one model's analysis responses parsed since startup
+type LLMParseStats struct {
+	Model       string         `json:"model"`
+	Responses   int            `json:"responses"`
+	Repaired    int            `json:"repaired"` // Parsed after a JSON repair or continuation request
+	Failed      int            `json:"failed"`   // Unparseable even after repairs
+	RepairRate  float64        `json:"repair_rate"`
+	FailureRate float64        `json:"failure_rate"`
+	Repairs     map[string]int `json:"repairs,omitempty"` // By repair: trailing_comma, unescaped_quote, continued
+}
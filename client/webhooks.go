@@ -0,0 +1,71 @@
+package client
+
+import "time"
+
+// Seller profile transitions webhook subscriptions can receive
+const (
+	TransitionHealthLabelChanged = "health_label_changed" // Health label moved, e.g. Healthy → At Risk
+	TransitionChurnRiskEscalated = "churn_risk_escalated" // Churn risk went up, e.g. low → high
+	TransitionNewSeller          = "new_seller"           // First call from a seller without a profile
+	TransitionIssueResolved      = "issue_resolved"       // A tracked issue was resolved on a call
+)
+
+// ProfileTransitions lists every transition type
+var ProfileTransitions = []string{TransitionHealthLabelChanged, TransitionChurnRiskEscalated, TransitionNewSeller, TransitionIssueResolved}
+
+// ProfileTransition is a change in a seller's state caused by a call,
+// posted to the webhooks subscribed to its type
+type ProfileTransition struct {
+	ID       string `json:"id"` // Unique per transition, for deduplicating redeliveries
+	Type     string `json:"type"`
+	GluserID string `json:"gluser_id"`
+	CallID   string `json:"call_id"`
+	From     string `json:"from,omitempty"` // Previous health label or churn risk
+	To       string `json:"to,omitempty"`   // New health label or churn risk
+
+	// Resolved issue, for issue_resolved
+	IssueID string `json:"issue_id,omitempty"`
+	Bucket  string `json:"bucket,omitempty"`
+	Problem string `json:"problem,omitempty"`
+
+	HealthScore int       `json:"health_score"` // After the call
+	HealthLabel string    `json:"health_label"`
+	ChurnRisk   string    `json:"churn_risk"`
+	OccurredAt  time.Time `json:"occurred_at"`
+}
+
+// WebhookSubscription posts the seller profile transitions it subscribes to
+// as JSON to URL. With a Secret, each delivery is signed with HMAC-SHA256
+// in the X-Webhook-Signature header.
+type WebhookSubscription struct {
+	ID          string    `json:"id"`
+	URL         string    `json:"url"`
+	Transitions []string  `json:"transitions,omitempty"` // Transition types received, every type if empty
+	Secret      string    `json:"secret,omitempty"`      // Never returned by the API
+	HasSecret   bool      `json:"has_secret"`
+	Description string    `json:"description,omitempty"`
+	By          string    `json:"by,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Wants reports whether the subscription receives transitions of type t
+func (s WebhookSubscription) Wants(t string) bool {
+	if len(s.Transitions) == 0 {
+		return true
+	}
+	for _, want := range s.Transitions {
+		if want == t {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookSubscriptionRequest is the body of POST /admin/webhooks
+type WebhookSubscriptionRequest struct {
+	URL         string   `json:"url"`
+	Transitions []string `json:"transitions,omitempty"`
+	Secret      string   `json:"secret,omitempty"`
+	Description string   `json:"description,omitempty"`
+	By          string   `json:"by,omitempty"` // Defaults to the API key's name
+}
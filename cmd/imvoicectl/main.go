@@ -0,0 +1,421 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/llm"
+	"im-ai-voice/internal/profile"
+	"im-ai-voice/internal/ratelimit"
+	"im-ai-voice/internal/service"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== imvoicectl ====================
+// Operator commands for the voice AI server's data:
+//
+//	imvoicectl replay [--dry-run] [--offline | --rescore] [--throttle] --yes
+//	imvoicectl replay --resume [--dry-run] [--offline | --rescore] [--throttle]
+//	imvoicectl backfill-metrics [--dry-run]
+//	imvoicectl migrate-issues [--dry-run]
+//	imvoicectl recompute-health [--dry-run]
+//	imvoicectl migrate-llm-raw [--dry-run]
+//	imvoicectl replay-llm [--call <id>] [--prompt-version <version>]
+//	imvoicectl reclassify --mapping <file> [--llm] [--dry-run] [--reason <text>]
+//
+// Build with `go build -o imvoicectl ./cmd/imvoicectl`.
+
+// ctlCommands maps command names to their entry points
+var ctlCommands = map[string]func(args []string) int{
+	"replay":           runReplayCommand,
+	"backfill-metrics": runBackfillMetricsCommand,
+	"migrate-issues":   runMigrateIssuesCommand,
+	"recompute-health": runRecomputeHealthCommand,
+	"migrate-llm-raw":  runMigrateLLMRawCommand,
+	"replay-llm":       runReplayLLMCommand,
+	"reclassify":       runReclassifyCommand,
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	cmd, ok := ctlCommands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+	os.Exit(cmd(os.Args[2:]))
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: imvoicectl <command> [flags]")
+	fmt.Fprintln(os.Stderr, "Commands:")
+	fmt.Fprintln(os.Stderr, "  replay            Rebuild analyses, profiles, aggregates and tickets from raw transcripts")
+	fmt.Fprintln(os.Stderr, "  backfill-metrics  Record seller_metrics for analyzed calls that predate it")
+	fmt.Fprintln(os.Stderr, "  migrate-issues    Give tracked issues ULIDs and move them to the issues collection")
+	fmt.Fprintln(os.Stderr, "  recompute-health  Rescore seller health with the current HEALTH_* weights")
+	fmt.Fprintln(os.Stderr, "  migrate-llm-raw   Move raw LLM responses out of stored analyses")
+	fmt.Fprintln(os.Stderr, "  replay-llm        Re-parse recorded Gemini interactions and diff them against stored analyses")
+	fmt.Fprintln(os.Stderr, "  reclassify        Move stored analyses, issues, aggregates and tickets to a changed bucket taxonomy")
+}
+
+func runReplayCommand(args []string) int {
+	fs := flag.NewFlagSet("replay", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "report what would be replayed without changing anything")
+	offline := fs.Bool("offline", false, "never call the LLM; skip calls without a cached analysis")
+	rescore := fs.Bool("rescore", false, "re-run the scoring pass on stored extractions instead of reusing their analyses")
+	throttle := fs.Bool("throttle", false, "only run in BACKFILL_HOURS, pausing while live traffic is busy or analyses fail")
+	resume := fs.Bool("resume", false, "carry on from where a stopped replay got, started with the same --offline or --rescore, without wiping")
+	yes := fs.Bool("yes", false, "confirm wiping analyses, profiles, aggregates and tickets")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: imvoicectl replay [--dry-run] [--offline | --rescore] [--throttle] --yes")
+		fmt.Fprintln(fs.Output(), "       imvoicectl replay --resume [--dry-run] [--offline | --rescore] [--throttle]")
+		fmt.Fprintln(fs.Output(), "Wipes derived data and reprocesses all raw transcripts in timestamp order,")
+		fmt.Fprintln(fs.Output(), "reusing existing analyses as cached LLM output, or with --rescore only")
+		fmt.Fprintln(fs.Output(), "re-running the scoring pass on calls with a stored extraction. Progress")
+		fmt.Fprintln(fs.Output(), "is checkpointed, so a stopped replay can be resumed.")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	var backfill *service.BackfillThrottle
+	if *throttle {
+		var err error
+		if backfill, err = service.BackfillThrottleFromEnv(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+	}
+	if !*dryRun && !*yes && !*resume {
+		fmt.Fprintln(os.Stderr, "Refusing to wipe derived data without --yes (use --dry-run to preview)")
+		return 2
+	}
+	if *offline && *rescore {
+		fmt.Fprintln(os.Stderr, "--rescore calls the LLM, it can't be combined with --offline")
+		return 2
+	}
+
+	if err := storage.InitStorageDirs(); err != nil {
+		log.Printf("Failed to initialize storage: %v", err)
+		return 1
+	}
+	if err := storage.InitMongoDB(); err != nil {
+		log.Printf("MongoDB initialization failed: %v", err)
+		return 1
+	}
+	if storage.IsMongoEnabled() {
+		defer storage.MongoDB.Close()
+	}
+
+	var ai *llm.AIClient
+	if !*offline && !*dryRun {
+		var err error
+		if ai, err = llm.NewAIClientFromEnv(); err != nil {
+			log.Printf("Failed to initialize AI client (use --offline to replay from cache only): %v", err)
+			return 1
+		}
+		defer ai.Close()
+		if l := ratelimit.FromEnv(); l != nil {
+			ai.SetRateLimiter(l) // Replays share the limit with running servers
+		}
+	}
+	svc := service.NewService(ai)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	if err := svc.LoadKnowledgeBase(ctx); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+
+	result, err := svc.Replay(ctx, service.ReplayOptions{DryRun: *dryRun, Offline: *offline, Rescore: *rescore, Resume: *resume, Throttle: backfill})
+	if err != nil {
+		log.Printf("Replay failed: %v", err)
+		return 1
+	}
+
+	out, _ := json.MarshalIndent(result, "", "  ")
+	fmt.Println(string(out))
+	return 0
+}
+
+func runBackfillMetricsCommand(args []string) int {
+	fs := flag.NewFlagSet("backfill-metrics", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "count missing metrics without writing them")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: imvoicectl backfill-metrics [--dry-run]")
+		fmt.Fprintln(fs.Output(), "Records a seller_metrics entry for every stored analysis that doesn't have one.")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	if err := storage.InitStorageDirs(); err != nil {
+		log.Printf("Failed to initialize storage: %v", err)
+		return 1
+	}
+	if err := storage.InitMongoDB(); err != nil {
+		log.Printf("MongoDB initialization failed: %v", err)
+		return 1
+	}
+	if storage.IsMongoEnabled() {
+		defer storage.MongoDB.Close()
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	result, err := service.NewService(nil).BackfillSellerMetrics(ctx, *dryRun)
+	if err != nil {
+		log.Printf("Backfill failed: %v", err)
+		return 1
+	}
+
+	out, _ := json.MarshalIndent(result, "", "  ")
+	fmt.Println(string(out))
+	return 0
+}
+
+func runMigrateIssuesCommand(args []string) int {
+	fs := flag.NewFlagSet("migrate-issues", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "count changes without saving profiles")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: imvoicectl migrate-issues [--dry-run]")
+		fmt.Fprintln(fs.Output(), "Gives every tracked issue with a legacy or duplicate ID a new ULID, and")
+		fmt.Fprintln(fs.Output(), "moves issues still embedded in MongoDB profiles to the issues collection.")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	if err := storage.InitStorageDirs(); err != nil {
+		log.Printf("Failed to initialize storage: %v", err)
+		return 1
+	}
+	if err := storage.InitMongoDB(); err != nil {
+		log.Printf("MongoDB initialization failed: %v", err)
+		return 1
+	}
+	if storage.IsMongoEnabled() {
+		defer storage.MongoDB.Close()
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	result, err := profile.MigrateIssues(ctx, *dryRun)
+	if err != nil {
+		log.Printf("Migration failed: %v", err)
+		return 1
+	}
+
+	out, _ := json.MarshalIndent(result, "", "  ")
+	fmt.Println(string(out))
+	return 0
+}
+
+func runRecomputeHealthCommand(args []string) int {
+	fs := flag.NewFlagSet("recompute-health", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "count changed scores without saving profiles")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: imvoicectl recompute-health [--dry-run]")
+		fmt.Fprintln(fs.Output(), "Rescores every seller profile with HEALTH_BUCKET_WEIGHTS and")
+		fmt.Fprintln(fs.Output(), "HEALTH_SEVERITY_MULTIPLIERS, without reanalyzing any calls.")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	if err := storage.InitStorageDirs(); err != nil {
+		log.Printf("Failed to initialize storage: %v", err)
+		return 1
+	}
+	if err := storage.InitMongoDB(); err != nil {
+		log.Printf("MongoDB initialization failed: %v", err)
+		return 1
+	}
+	if storage.IsMongoEnabled() {
+		defer storage.MongoDB.Close()
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	result, err := profile.RecomputeHealth(ctx, *dryRun)
+	if err != nil {
+		log.Printf("Recompute failed: %v", err)
+		return 1
+	}
+
+	out, _ := json.MarshalIndent(result, "", "  ")
+	fmt.Println(string(out))
+	return 0
+}
+
+func runMigrateLLMRawCommand(args []string) int {
+	fs := flag.NewFlagSet("migrate-llm-raw", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "count analyses with raw responses without changing them")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: imvoicectl migrate-llm-raw [--dry-run]")
+		fmt.Fprintln(fs.Output(), "Moves the raw Gemini responses in stored analyses to llm_raw_responses,")
+		fmt.Fprintln(fs.Output(), "dropping those already older than LLM_RAW_RETENTION_DAYS.")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	if err := storage.InitStorageDirs(); err != nil {
+		log.Printf("Failed to initialize storage: %v", err)
+		return 1
+	}
+	if err := storage.InitMongoDB(); err != nil {
+		log.Printf("MongoDB initialization failed: %v", err)
+		return 1
+	}
+	if storage.IsMongoEnabled() {
+		defer storage.MongoDB.Close()
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	result, err := storage.MigrateLLMRaw(ctx, *dryRun)
+	if err != nil {
+		log.Printf("Migration failed: %v", err)
+		return 1
+	}
+
+	out, _ := json.MarshalIndent(result, "", "  ")
+	fmt.Println(string(out))
+	return 0
+}
+
+func runReplayLLMCommand(args []string) int {
+	fs := flag.NewFlagSet("replay-llm", flag.ContinueOnError)
+	callID := fs.String("call", "", "only replay this call's interactions")
+	promptVersion := fs.String("prompt-version", "", "only replay interactions recorded with this prompt version")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: imvoicectl replay-llm [--call <id>] [--prompt-version <version>]")
+		fmt.Fprintln(fs.Output(), "Parses the Gemini interactions recorded with LLM_RECORD=true again with the")
+		fmt.Fprintln(fs.Output(), "current parsing code and lists the calls that fail to parse or now parse")
+		fmt.Fprintln(fs.Output(), "differently from their stored analysis. Gemini isn't called and nothing is written.")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	if err := storage.InitStorageDirs(); err != nil {
+		log.Printf("Failed to initialize storage: %v", err)
+		return 1
+	}
+	if err := storage.InitMongoDB(); err != nil {
+		log.Printf("MongoDB initialization failed: %v", err)
+		return 1
+	}
+	if storage.IsMongoEnabled() {
+		defer storage.MongoDB.Close()
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	result, err := service.NewService(nil).ReplayLLMInteractions(ctx, storage.LLMInteractionQuery{CallID: *callID, PromptVersion: *promptVersion})
+	if err != nil {
+		log.Printf("LLM replay failed: %v", err)
+		return 1
+	}
+
+	out, _ := json.MarshalIndent(result, "", "  ")
+	fmt.Println(string(out))
+	return 0
+}
+
+func runReclassifyCommand(args []string) int {
+	fs := flag.NewFlagSet("reclassify", flag.ContinueOnError)
+	mapping := fs.String("mapping", "", "JSON file with the mappings and splits, as POST /admin/reclassifications takes them")
+	useLLM := fs.Bool("llm", false, "have Gemini place split issues no keyword matches")
+	dryRun := fs.Bool("dry-run", false, "report what would move without changing anything")
+	reason := fs.String("reason", "", "why the taxonomy changed, kept with the run")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: imvoicectl reclassify --mapping <file> [--llm] [--dry-run] [--reason <text>]")
+		fmt.Fprintln(fs.Output(), "Moves stored analyses, tracked issues, aggregates and tickets from retired")
+		fmt.Fprintln(fs.Output(), "buckets to the current taxonomy, recording every record moved. The file is")
+		fmt.Fprintln(fs.Output(), `{"mappings": [{"from", "to"}], "splits": [{"from", "into": [{"bucket", "keywords", "description"}], "default"}]}.`)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *mapping == "" {
+		fs.Usage()
+		return 2
+	}
+	b, err := os.ReadFile(*mapping)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	var in client.ReclassifyRequest
+	if err := json.Unmarshal(b, &in); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid mapping file: %v\n", err)
+		return 2
+	}
+	in.UseLLM, in.DryRun, in.By = *useLLM, *dryRun, os.Getenv("USER")
+	if *reason != "" {
+		in.Reason = *reason
+	}
+
+	if err := storage.InitStorageDirs(); err != nil {
+		log.Printf("Failed to initialize storage: %v", err)
+		return 1
+	}
+	if err := storage.InitMongoDB(); err != nil {
+		log.Printf("MongoDB initialization failed: %v", err)
+		return 1
+	}
+	if storage.IsMongoEnabled() {
+		defer storage.MongoDB.Close()
+	}
+
+	var ai *llm.AIClient
+	if *useLLM {
+		if ai, err = llm.NewAIClientFromEnv(); err != nil {
+			log.Printf("Failed to initialize AI client (leave out --llm to place split issues by keyword): %v", err)
+			return 1
+		}
+		defer ai.Close()
+		if l := ratelimit.FromEnv(); l != nil {
+			ai.SetRateLimiter(l)
+		}
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	result, err := service.NewService(ai).Reclassify(ctx, in)
+	if result != nil {
+		out, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(out))
+	}
+	if err != nil {
+		log.Printf("Reclassification failed: %v", err)
+		return 1
+	}
+	return 0
+}
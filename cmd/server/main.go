@@ -0,0 +1,420 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"im-ai-voice/client"
+
+	"im-ai-voice/internal/acoustic"
+	"im-ai-voice/internal/api"
+	"im-ai-voice/internal/archive"
+	"im-ai-voice/internal/classifier"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/github"
+	"im-ai-voice/internal/leader"
+	"im-ai-voice/internal/lifecycle"
+	"im-ai-voice/internal/llm"
+	"im-ai-voice/internal/notify"
+	"im-ai-voice/internal/ratelimit"
+	"im-ai-voice/internal/recording"
+	"im-ai-voice/internal/scheduler"
+	"im-ai-voice/internal/service"
+	"im-ai-voice/internal/storage"
+	"im-ai-voice/internal/watcher"
+)
+
+func main() {
+	// Stopped by SIGINT/SIGTERM, including while waiting on dependencies
+	stop, stopSignals := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stopSignals()
+
+	// Serve the probes straight away so an orchestrator sees "not ready"
+	// rather than connection refused while dependencies come up
+	lifecycle.Expect(client.CheckStorage)
+	if os.Getenv("MONGODB_URI") != "" {
+		lifecycle.Expect(client.CheckMongoDB)
+	}
+	rulesOnly := classifier.RulesOnly()
+	if !rulesOnly {
+		lifecycle.Expect(client.CheckLLM)
+	}
+	primeCache := service.CachePrimeEnabled()
+	if primeCache {
+		lifecycle.Expect(client.CheckCache)
+	}
+	api.RegisterProbes()
+	srv := &http.Server{Addr: config.SERVER_LISTEN_ADDR, Handler: api.WithRequestID(api.Compress(api.Localize(api.TrackKeyUsage(http.DefaultServeMux))))}
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.ListenAndServe()
+	}()
+
+	// Initialize storage directories
+	if err := storage.InitStorageDirs(); err != nil {
+		log.Fatalf("Failed to initialize storage: %v", err)
+	}
+	lifecycle.Pass(client.CheckStorage)
+	log.Println("Storage directories initialized")
+
+	// Initialize MongoDB (optional - if MONGODB_URI is set)
+	if !connectMongo(stop) {
+		log.Println("Shutting down...")
+		return
+	}
+	if storage.IsMongoEnabled() {
+		defer storage.MongoDB.Close()
+	}
+
+	// Initialize cold archive (local by default, S3 if ARCHIVE_S3_BUCKET is set)
+	if err := archive.Init(); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+
+	// Initialize recording store (local by default, S3 if RECORDINGS_S3_BUCKET is set)
+	if err := recording.Init(); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+
+	if err := github.Init(); err != nil {
+		log.Printf("Warning: GitHub issue sync disabled: %v", err)
+	}
+
+	if acoustic.Requested() && !acoustic.Built {
+		log.Printf("Warning: ACOUSTIC_SIGNALS=true but %v", acoustic.ErrNotBuilt)
+	} else if acoustic.Enabled() {
+		log.Println("🔊 Acoustic signals enabled for calls with recordings")
+	}
+
+	// Initialize AI client (Gemini), unless calls are analyzed by the
+	// keyword classifier alone
+	var ai *llm.AIClient
+	if rulesOnly {
+		log.Println("🧮 ANALYSIS_ENGINE=rules: calls analyzed by the keyword classifier, Gemini disabled")
+	} else {
+		var err error
+		if ai, err = llm.NewAIClientFromEnv(); err != nil {
+			log.Fatalf("Failed to initialize AI client: %v", err)
+		}
+		defer ai.Close()
+		if l := ratelimit.FromEnv(); l != nil {
+			ai.SetRateLimiter(l)
+		}
+		if llm.RecordingFromEnv() {
+			ai.SetRecorder(storage.SaveLLMInteraction)
+			log.Printf("🎞️ Recording analysis requests under prompt version %s", ai.PromptVersion())
+		}
+		log.Println("AI client initialized (Gemini)")
+	}
+
+	// Initialize service
+	svc := service.NewService(ai)
+
+	// Background work runs until the HTTP server has drained
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Confirm Gemini works before taking calls, rather than finding out from
+	// the first analysis
+	if ai != nil {
+		checkLLM(ctx, ai, svc)
+	}
+
+	// Evict cached seller profiles written by other instances
+	storage.StartProfileChangeStream(ctx)
+
+	// Write the seller summaries of profiles saved before there were any
+	go func() {
+		if _, err := storage.SyncSellerSummaries(ctx, false); err != nil {
+			log.Printf("Warning: seller summaries not synced: %v", err)
+		}
+	}()
+
+	// Preload the dashboard's data so the first load after a restart is warm
+	if primeCache {
+		go func() {
+			svc.PrimeCache(ctx)
+			lifecycle.Pass(client.CheckCache)
+		}()
+	}
+
+	// Ground analyses in the uploaded knowledge base, reloaded on every instance
+	svc.StartKnowledgeBaseRefresher(ctx)
+
+	// Batch webhook notifications into digests (NOTIFY_DIGEST)
+	digestsDone := notify.StartDigests(ctx)
+
+	// Transcript watcher (event-driven analysis)
+	tw := watcher.NewTranscriptWatcher(svc, config.TRANSCRIPTS_DIR)
+
+	// Background jobs run on their schedules on the leader, and by hand anywhere
+	jobs := scheduler.New(ctx)
+	svc.RegisterJobs(jobs)
+	tw.RegisterJobs(jobs)
+
+	// Start the watcher and scheduled jobs on the elected leader only -
+	// unless DEMO_MODE is set
+	leaderDone := make(chan struct{})
+	if os.Getenv("DEMO_MODE") != "true" {
+		go func() {
+			defer close(leaderDone)
+			leader.Run(ctx, func(ctx context.Context) {
+				tw.Start()
+				jobs.Start(ctx)
+				<-ctx.Done()
+				tw.Stop()
+			})
+		}()
+	} else {
+		close(leaderDone)
+		log.Println("🎬 DEMO MODE: Watcher disabled, using existing MongoDB data")
+	}
+
+	// Initialize router
+	router := api.NewRouter(svc, tw, jobs)
+	router.RegisterRoutes()
+
+	// Print startup info
+	fmt.Println("=========================================")
+	fmt.Println("  IndiaMART Voice AI Analysis Server")
+	fmt.Println("=========================================")
+	fmt.Printf("Server running on http://localhost%s\n", config.SERVER_LISTEN_ADDR)
+	fmt.Println()
+	fmt.Println("🎯 DASHBOARD UI:")
+	fmt.Printf("   Open http://localhost%s in your browser\n", config.SERVER_LISTEN_ADDR)
+	fmt.Println()
+	fmt.Println("🤖 EVENT-DRIVEN AUTOMATED FLOW:")
+	fmt.Println("   1. New transcript in data/transcripts/ → Auto-analyze")
+	fmt.Println("   2. Seller profile updated (data/profiles/seller_{id}.json)")
+	policy := tw.Status()
+	trigger := fmt.Sprintf("After %d new analyses of a date", policy.Threshold)
+	if policy.Debounce != "0s" {
+		trigger += fmt.Sprintf(" (or %s without new ones)", policy.Debounce)
+	}
+	fmt.Printf("   3. %s → Auto-aggregate + tickets\n", trigger)
+	fmt.Println()
+
+	// MongoDB status
+	if storage.IsMongoEnabled() {
+		fmt.Println("💾 MongoDB: ✅ PRIMARY STORAGE")
+		fmt.Printf("   Database: %s\n", storage.DB_NAME)
+		fmt.Println("   Collections: seller_profiles, call_analyses, tickets, daily_aggregates")
+		fmt.Println("   Mode: MongoDB-first (no local files)")
+	} else {
+		fmt.Println("💾 MongoDB: ❌ DISABLED (set MONGODB_URI to enable)")
+		fmt.Println("   Mode: Local JSON files only")
+	}
+	fmt.Println()
+
+	fmt.Println("API Endpoints:")
+	fmt.Println("  POST /ingest              - Ingest call transcript")
+	fmt.Println("  POST /analyze             - Analyze transcript directly (structured: true for the full analysis)")
+	fmt.Println("  POST /analyze/trigger     - Process all unprocessed")
+	fmt.Println("  GET  /analyze/provisional - Calls awaiting their full analysis")
+	fmt.Println("  POST /analyze/provisional/upgrade - Upgrade provisional analyses")
+	fmt.Println("  GET  /calls               - List calls (?seller=&from=&to=&sentiment=&bucket=&escalated=&page=; NDJSON stream with Accept: application/x-ndjson)")
+	fmt.Println("  GET  /calls/{id}          - Get call analysis")
+	fmt.Println("  GET  /calls/{id}/transcript - Full transcript (API key with transcripts scope, audited)")
+	fmt.Println("  GET  /calls/{id}/recording  - Signed audio URL (transcripts scope, audited); POST downloads it now")
+	fmt.Println("  GET  /calls/{id}/draft-followup - Drafted follow-up message to the seller (FOLLOWUP_DRAFTS=true)")
+	fmt.Println("  GET  /calls/{id}/llm-raw - Raw Gemini responses until they expire (scope: transcripts)")
+	fmt.Println("  GET  /calls/{id}/versions - Analyses replaced after the transcript was rewritten")
+	fmt.Println("  GET  /calls/{id}/shadow   - Candidate model/prompt analysis of a shadowed call")
+	fmt.Println("  DELETE /calls/{id}        - Move a call's analysis to the trash (?reason=)")
+	fmt.Println("  GET  /calls/{id}/annotations - QA reviewers' comments on the transcript (POST to add, DELETE /{annotation_id})")
+	fmt.Println("  POST /calls/{id}/chat     - Ask Gemini about the call, session kept per API key (scope: transcripts, audited)")
+	fmt.Println("  GET  /annotations         - Annotations with a coaching rollup per agent (?agent_id=&gluser_id=&category=)")
+	fmt.Println()
+	fmt.Println("  📊 SELLER PROFILES (Dashboard-Ready):")
+	fmt.Println("  GET  /sellers             - Paginated seller summaries with status (NDJSON stream with Accept: application/x-ndjson)")
+	fmt.Println("  GET  /sellers/{gluser_id} - Get full seller profile")
+	fmt.Println("  PATCH /sellers/{gluser_id} - Correct identity fields, override churn risk or sentiment, set custom fields (scope: profiles)")
+	fmt.Println("  GET  /sellers/{id}/report - Seller health report (?format=pdf|html)")
+	fmt.Println("  GET  /sellers/{id}/diff   - Compare two calls (?call_a=&call_b=&narrative=false)")
+	fmt.Println("  GET  /sellers/{id}/brief  - Prep brief for an outbound retention call")
+	fmt.Println("  GET  /sellers/{id}/trends - Full trend history (?granularity=call|day|week|month&from=&to=)")
+	fmt.Println("  GET  /sellers/{id}/export - Profile, issues, analyses and extractions (scope: migrate)")
+	fmt.Println("  GET  /export              - Every seller's export, streamed as NDJSON (scope: migrate)")
+	fmt.Println("  GET  /sellers/{id}/data-inventory - Everything stored about a seller, for access requests (?format=zip; scope: migrate)")
+	fmt.Println("  POST /sellers/import      - Import exports, NDJSON for many (?seller_prefix=&call_prefix=&overwrite=; scope: migrate)")
+	fmt.Println("  POST /import/analyses     - Import existing analyses as NDJSON and build profiles from them (?dry_run=; scope: migrate)")
+	fmt.Println()
+	fmt.Println("  GET  /aggregates          - List aggregates")
+	fmt.Println("  GET  /aggregates/{date}   - Get daily aggregate")
+	fmt.Println("  POST /aggregates/trigger  - Run aggregation manually")
+	fmt.Println("  POST /aggregates/preview  - Aggregate and tickets for a date, without saving")
+	fmt.Println("  POST /aggregates/check    - Flag aggregates whose analyses changed (recompute=true re-aggregates them)")
+	fmt.Println("  GET  /aggregates/{date}/shifts - Shift aggregates (AGGREGATION_SHIFTS)")
+	fmt.Println("  GET  /tickets             - List ticket dates")
+	fmt.Println("  GET  /tickets/{date}      - Get tickets for date (?cf.<name>= to filter on custom fields)")
+	fmt.Println("  PATCH /tickets/{date}/{id} - Set ticket status (resolving closes its GitHub issue) and custom fields")
+	fmt.Println("  POST /tickets/bulk-update - Set the status of many tickets, result per item (scope: tickets)")
+	fmt.Println("  POST /churn-outcomes      - Record sellers' renewals and cancellations, JSON or CSV (scope: profiles)")
+	fmt.Println("  DELETE /tickets/{date}/{id} - Move a ticket to the trash (?reason=)")
+	fmt.Println("  GET  /trash               - Deleted analyses and tickets (?kind=analysis|ticket)")
+	fmt.Println("  POST /trash/{kind}/{id}/restore - Restore a deleted analysis or ticket")
+	fmt.Println("  GET  /dashboard?date=...  - Get daily dashboard (&shift= for a shift's, &as_computed=true as first computed)")
+	fmt.Println("  GET  /analytics/heatmap   - City/vertical heatmap (?dimension=&metric=&from=&to=)")
+	fmt.Println("  GET  /analytics/issue-aging - Open issue age buckets")
+	fmt.Println("  GET  /analytics/resolution-durability - Share of issue resolutions that held, per bucket and agent")
+	fmt.Println("  GET  /analytics/churn-reasons - At-risk calls by churn reason category (?from=&to=)")
+	fmt.Println("  GET  /analytics/churn-accuracy - Churn predictions against actual renewals, per prediction version (?from=&to=&window_days=)")
+	fmt.Println("  GET  /analytics/cross-check - Keyword rules vs Gemini disagreement rates (?from=&to=)")
+	fmt.Println("  GET  /analytics/upsell-pipeline - Upsell opportunities by product SKU with deal value (?from=&to=)")
+	fmt.Println("  GET  /analytics/drivers   - Drivers of dissatisfaction ranked by impact (?from=&to=)")
+	fmt.Println("  GET  /analytics/ticket-reconciliation - Issues out of step with their IndiaMART tickets (?kind=&bucket=)")
+	fmt.Println("  GET  /agents/leaderboard  - Agents ranked by QA score with movement (?period=week)")
+	fmt.Println("  GET  /shadow/report       - Candidate analyses compared with the primary (?from=&to=)")
+	fmt.Println("  POST /ask                 - Answer a plain-language question from the analytics")
+	fmt.Println("  GET  /events?type=&since= - Pipeline event log (paginated)")
+	fmt.Println("  GET  /issues              - Tracked issues across sellers (?bucket=&status=&severity=)")
+	fmt.Println("  GET  /commitments         - Agent promises and which were broken, per agent and seller (?gluser_id=&agent_id=&status=)")
+	fmt.Println("  GET  /samples             - Approved anonymized example calls for training and demos (?bucket=&sentiment=&status=)")
+	fmt.Println("  POST /samples             - Generate a sample from a call for review (scope: transcripts; PATCH/DELETE /{id})")
+	fmt.Println("  GET  /systemic-issues     - Problems reported across sellers, clustered nightly (?bucket=&min_sellers=)")
+	fmt.Println("  POST /systemic-issues/trigger - Re-cluster open issues now")
+	fmt.Println("  GET  /analytics/themes    - Key insight themes of the week, clustered nightly (?date=)")
+	fmt.Println("  POST /analytics/themes/trigger - Re-cluster a week's key insights now")
+	fmt.Println("  GET  /attention           - Sellers needing attention, most urgent first (?state=open|acknowledged|snoozed)")
+	fmt.Println("  POST /attention/{id}/acknowledge|snooze - Silence a seller's attention alerts until the reason changes")
+	fmt.Println("  GET  /portal/sellers/{id} - Seller-safe case status for the seller portal (scope: portal)")
+	fmt.Println("  GET  /digest?date=...     - Daily digest (?format=pdf|html)")
+	fmt.Println("  POST /digest/send         - Regenerate and email digest")
+	fmt.Println("  GET  /reports/weekly      - Weekly executive report (?date=&format=pdf|html|json)")
+	fmt.Println("  POST /reports/weekly/send - Regenerate and email weekly report")
+	fmt.Println("  POST /archive/trigger     - Archive old analyses to Parquet")
+	fmt.Println("  GET  /archive/sellers/{id} - Archived seller timeline")
+	fmt.Println("  POST /recordings/purge    - Delete call audio past RECORDING_RETENTION_DAYS")
+	fmt.Println("  GET  /admin/watcher       - Watcher aggregation policy and pending counters")
+	fmt.Println("  GET  /admin/pipeline/stats - Transcript backlog, processing time, LLM error rate, catch-up estimate")
+	fmt.Println("  GET  /admin/leader        - Leader election role (only the leader runs the watcher and schedulers)")
+	fmt.Println("  GET  /admin/keys/{id}/usage - API key usage and quota for a month (?month=YYYY-MM; scope: admin)")
+	fmt.Println("  GET  /admin/jobs/schedule - Background jobs with their cron schedules, next and last runs")
+	fmt.Println("  POST /admin/jobs/{name}/run - Run a job now, in the background (scope: admin)")
+	fmt.Println("  GET  /admin/ticket-suppressions - Ticket mute rules (POST to add, DELETE /{id} to remove)")
+	fmt.Println("  GET  /admin/webhooks - Seller profile transition webhooks (POST to subscribe, DELETE /{id} to remove; scope: admin)")
+	fmt.Println("  GET  /admin/alert-subscriptions - Alert subscriptions scoped by city and vertical (POST to subscribe, GET/DELETE /{id}; scope: admin)")
+	fmt.Println("  GET  /admin/bucket-owners - Teams owning feature buckets (PUT/DELETE /{bucket}; scope: admin)")
+	fmt.Println("  GET  /admin/custom-fields - Custom fields on profiles and tickets (?entity=; PUT/DELETE /{entity}/{name})")
+	fmt.Println("  GET  /admin/rollouts      - Canary rollouts of prompt/model changes (POST to start, GET/PATCH /{id}; scope: admin)")
+	fmt.Println("  GET  /admin/eval/history  - Metrics per model/prompt version across eval runs (?from=&to=&version=; POST /admin/eval/run to record now)")
+	fmt.Println("  GET  /admin/kb            - Knowledge base documents (POST to upload, GET/DELETE /{id}; scope: admin)")
+	fmt.Println("  POST /admin/selftest      - Run synthetic calls through the pipeline, pass/fail per stage (?llm=gemini)")
+	fmt.Println("  GET  /health              - Liveness check")
+	fmt.Println("  GET  /ready               - Readiness check (503 until dependencies are up, and while draining)")
+	fmt.Println("  POST /admin/drain         - Report unready and wait DRAIN_DELAY (preStop hook)")
+	fmt.Println()
+	fmt.Printf("Using LLM: Google Gemini (%s)\n", llm.GeminiModel)
+	fmt.Printf("Environment: %s\n", config.Env)
+	fmt.Printf("Data directory: %s\n", config.STORAGE_BASE)
+	fmt.Println("=========================================")
+
+	select {
+	case err := <-serveErr:
+		log.Fatalf("Server failed: %v", err)
+	case <-stop.Done():
+	}
+
+	// Graceful shutdown: stop receiving traffic, let in-flight requests
+	// finish, then stop the watcher and release the leader lease
+	log.Println("Shutting down...")
+	lifecycle.Drain()
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(),
+		config.EnvDuration("SHUTDOWN_TIMEOUT", config.DEFAULT_SHUTDOWN_TIMEOUT))
+	defer cancelShutdown()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Warning: in-flight requests cut off: %v", err)
+	}
+	cancel()
+	<-leaderDone
+	<-digestsDone
+}
+
+// connectMongo connects to MongoDB when MONGODB_URI is set, retrying until it
+// answers. It returns false if the process is stopped first.
+func connectMongo(stop context.Context) bool {
+	retry := config.EnvDuration("READY_RETRY_INTERVAL", config.DEFAULT_READY_RETRY)
+	for {
+		err := storage.InitMongoDB()
+		if err == nil {
+			if storage.IsMongoEnabled() {
+				lifecycle.Pass(client.CheckMongoDB)
+			}
+			return true
+		}
+		lifecycle.Fail(client.CheckMongoDB, err)
+		log.Printf("Warning: MongoDB initialization failed, retrying in %v: %v", retry, err)
+
+		select {
+		case <-stop.Done():
+			return false
+		case <-time.After(retry):
+		}
+	}
+}
+
+// checkLLM checks Gemini takes the key and serves the model where the server
+// runs before the instance takes calls, retrying in the background while it
+// fails. Meanwhile, per LLM_STARTUP_CHECK, the instance stays unready
+// ("wait"), exits on a problem retrying can't fix ("fail"), or turns ready
+// with calls analyzed provisionally ("degraded").
+func checkLLM(ctx context.Context, ai *llm.AIClient, svc *service.Service) {
+	mode := llm.StartupCheckFromEnv()
+	retry := config.EnvDuration("READY_RETRY_INTERVAL", config.DEFAULT_READY_RETRY)
+	if checkLLMOnce(ctx, ai, svc, mode, retry) {
+		return
+	}
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(retry):
+			}
+			if checkLLMOnce(ctx, ai, svc, mode, retry) {
+				return
+			}
+		}
+	}()
+}
+
+// checkLLMOnce runs one Gemini check and records its outcome, reporting
+// whether it passed or there's nothing left to check
+func checkLLMOnce(ctx context.Context, ai *llm.AIClient, svc *service.Service, mode string, retry time.Duration) bool {
+	checkCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	err := ai.Check(checkCtx)
+	cancel()
+	if err == nil {
+		lifecycle.Pass(client.CheckLLM)
+		svc.LLMCheckPassed()
+		log.Println("AI client check passed (key, model and region)")
+		return true
+	}
+	if ctx.Err() != nil {
+		return true
+	}
+
+	var checkErr *llm.CheckError
+	if mode == llm.StartupFail && errors.As(err, &checkErr) && checkErr.Permanent() {
+		log.Fatalf("❌ %v (LLM_STARTUP_CHECK=fail)", err)
+	}
+	switch {
+	case mode == llm.StartupDegraded && svc.LLMCheckFailed():
+		lifecycle.Degrade(client.CheckLLM, err)
+		log.Printf("Warning: %v - serving degraded, checking again in %v", err, retry)
+	case mode == llm.StartupDegraded:
+		lifecycle.Fail(client.CheckLLM, err)
+		log.Printf("Warning: %v - not ready, LLM_STARTUP_CHECK=degraded needs DEGRADED_MODE; retrying in %v", err, retry)
+	default:
+		lifecycle.Fail(client.CheckLLM, err)
+		log.Printf("Warning: %v - not ready, retrying in %v", err, retry)
+	}
+	return false
+}
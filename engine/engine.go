@@ -0,0 +1,173 @@
+// Package engine embeds the call analysis pipeline in other Go programs, for
+// batch jobs that would rather not go through the HTTP API. It analyzes,
+// profiles and aggregates exactly as the server does, with the same storage,
+// and takes the rest of its configuration (STORAGE_BASE, prompts, timeouts,
+// ...) from the environment like the server.
+//
+// Storage is process-wide, so a program should create one Engine. Tests can
+// use enginetest.Mock, which keeps everything in memory and needs no Gemini.
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/classifier"
+	"im-ai-voice/internal/llm"
+	"im-ai-voice/internal/service"
+	"im-ai-voice/internal/storage"
+)
+
+// ErrNotFound is returned for calls and aggregates that aren't stored
+var ErrNotFound = errors.New("not found")
+
+// Analyzer analyzes call transcripts
+type Analyzer interface {
+	// AnalyzeTranscript analyzes an export transcript with its seller's
+	// profile as context, updates the profile and stores the analysis, as
+	// the server's watcher does. The profile is nil for blocked calls.
+	AnalyzeTranscript(ctx context.Context, ht *client.HackathonTranscript) (*client.SellerProfile, *client.AnalysisResult, error)
+	// PreviewAnalysis analyzes a transcript without storing anything
+	PreviewAnalysis(ctx context.Context, rt client.RawTranscript) (*client.AnalysisResult, error)
+	// GetCallAnalysis returns a stored analysis, ErrNotFound when there's none
+	GetCallAnalysis(ctx context.Context, callID string) (*client.AnalysisResult, error)
+}
+
+// ProfileStore reads seller profiles
+type ProfileStore interface {
+	// GetSellerProfile returns a seller's profile, nil when they have none
+	GetSellerProfile(ctx context.Context, gluserID string) (*client.SellerProfile, error)
+	ListSellerIDs(ctx context.Context) ([]string, error)
+}
+
+// Aggregator builds and reads daily aggregates
+type Aggregator interface {
+	// RunAggregation aggregates a date's analyses (YYYY-MM-DD, business
+	// days) and generates its tickets
+	RunAggregation(ctx context.Context, date string) (*client.DailyAggregate, error)
+	// GetDailyAggregate returns a date's stored aggregate, ErrNotFound when
+	// the date wasn't aggregated
+	GetDailyAggregate(ctx context.Context, date string) (*client.DailyAggregate, error)
+}
+
+// Engine is the analysis engine
+type Engine interface {
+	Analyzer
+	ProfileStore
+	Aggregator
+	Close() error
+}
+
+// Option configures New
+type Option func(*options)
+
+type options struct {
+	apiKey    string
+	model     string
+	mongoURI  string
+	rulesOnly bool
+}
+
+// WithGeminiAPIKey sets the Gemini API key (default: GEMINI_API_KEY)
+func WithGeminiAPIKey(key string) Option {
+	return func(o *options) { o.apiKey = key }
+}
+
+// WithGeminiModel sets the Gemini model calls are analyzed with (default: the server's)
+func WithGeminiModel(model string) Option {
+	return func(o *options) { o.model = model }
+}
+
+// WithRulesOnly analyzes calls with the keyword classifier alone, without
+// Gemini or an API key (default: ANALYSIS_ENGINE=rules)
+func WithRulesOnly() Option {
+	return func(o *options) { o.rulesOnly = true }
+}
+
+// WithMongoURI stores data in MongoDB at uri (default: MONGODB_URI); empty
+// keeps it in local JSON files only
+func WithMongoURI(uri string) Option {
+	return func(o *options) { o.mongoURI = uri }
+}
+
+// New creates the storage directories, connects to MongoDB if configured
+// and returns the engine
+func New(opts ...Option) (Engine, error) {
+	o := options{apiKey: os.Getenv("GEMINI_API_KEY"), mongoURI: os.Getenv("MONGODB_URI"), rulesOnly: classifier.RulesOnly()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	var ai *llm.AIClient
+	if !o.rulesOnly {
+		var err error
+		if ai, err = llm.NewAIClient(o.apiKey, o.model); err != nil {
+			return nil, err
+		}
+	}
+	if err := storage.InitStorageDirs(); err != nil {
+		return nil, fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	if err := storage.ConnectMongoDB(o.mongoURI); err != nil {
+		return nil, err
+	}
+	return &engine{svc: service.NewService(ai), ai: ai}, nil
+}
+
+// engine is the Engine backed by the server's service
+type engine struct {
+	svc *service.Service
+	ai  *llm.AIClient // nil with WithRulesOnly
+}
+
+func (e *engine) AnalyzeTranscript(ctx context.Context, ht *client.HackathonTranscript) (*client.SellerProfile, *client.AnalysisResult, error) {
+	return e.svc.ProcessHackathonTranscript(ctx, ht)
+}
+
+func (e *engine) PreviewAnalysis(ctx context.Context, rt client.RawTranscript) (*client.AnalysisResult, error) {
+	return e.svc.PreviewAnalysis(ctx, rt)
+}
+
+func (e *engine) GetCallAnalysis(ctx context.Context, callID string) (*client.AnalysisResult, error) {
+	ar, err := e.svc.GetCallAnalysis(ctx, callID)
+	return ar, notFound(err, "call "+callID)
+}
+
+func (e *engine) GetSellerProfile(ctx context.Context, gluserID string) (*client.SellerProfile, error) {
+	return storage.LoadSellerProfile(ctx, gluserID)
+}
+
+func (e *engine) ListSellerIDs(ctx context.Context) ([]string, error) {
+	return storage.ListAllSellerIDs(ctx)
+}
+
+func (e *engine) RunAggregation(ctx context.Context, date string) (*client.DailyAggregate, error) {
+	return e.svc.RunAggregation(ctx, date)
+}
+
+func (e *engine) GetDailyAggregate(ctx context.Context, date string) (*client.DailyAggregate, error) {
+	agg, err := e.svc.GetDailyAggregate(ctx, date)
+	return agg, notFound(err, "aggregate for "+date)
+}
+
+// notFound turns a missing file into ErrNotFound
+func notFound(err error, what string) error {
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%w: %s", ErrNotFound, what)
+	}
+	return err
+}
+
+// Close disconnects from MongoDB
+func (e *engine) Close() error {
+	if e.ai != nil {
+		e.ai.Close()
+	}
+	if storage.IsMongoEnabled() {
+		return storage.MongoDB.Close()
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+// Package enginetest provides an in-memory engine.Engine, for testing
+// programs that embed the analysis engine without Gemini or storage
+package enginetest
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/engine"
+	"im-ai-voice/internal/aggregate"
+	"im-ai-voice/internal/config"
+)
+
+// Mock is an engine.Engine keeping analyses, profiles and aggregates in
+// memory. Profiles only track calls and the latest call's status, and
+// aggregates are built from the stored analyses like the server's, without
+// tickets. It's safe for concurrent use.
+type Mock struct {
+	// AnalyzeFunc analyzes a transcript in place of Gemini; nil gives every
+	// call a neutral analysis with no issues
+	AnalyzeFunc func(ctx context.Context, rt client.RawTranscript) (*client.AnalysisResult, error)
+
+	mu         sync.Mutex
+	analyses   map[string]*client.AnalysisResult
+	profiles   map[string]*client.SellerProfile
+	aggregates map[string]*client.DailyAggregate
+}
+
+var _ engine.Engine = (*Mock)(nil)
+
+// NewMock returns an empty Mock
+func NewMock() *Mock {
+	return &Mock{
+		analyses:   make(map[string]*client.AnalysisResult),
+		profiles:   make(map[string]*client.SellerProfile),
+		aggregates: make(map[string]*client.DailyAggregate),
+	}
+}
+
+// analyze runs AnalyzeFunc or the neutral analysis
+func (m *Mock) analyze(ctx context.Context, rt client.RawTranscript) (*client.AnalysisResult, error) {
+	if m.AnalyzeFunc != nil {
+		return m.AnalyzeFunc(ctx, rt)
+	}
+	return &client.AnalysisResult{
+		CallID:       rt.CallID,
+		SellerID:     rt.SellerID,
+		Timestamp:    rt.Timestamp,
+		TranscriptEn: rt.Transcript,
+		OriginalLang: rt.Language,
+		Issues:       []client.Issue{},
+		Intent:       client.SellerIntent{Sentiment: "neutral", SatisfactionScore: 5},
+		Churn:        client.ChurnPrediction{IsLikelyToChurn: "low"},
+		CallSummary:  "Mock analysis",
+	}, nil
+}
+
+func (m *Mock) AnalyzeTranscript(ctx context.Context, ht *client.HackathonTranscript) (*client.SellerProfile, *client.AnalysisResult, error) {
+	rt := client.RawTranscript{
+		CallID:     ht.ClickToCallID,
+		SellerID:   ht.GluserID,
+		Transcript: ht.Transcript,
+		Language:   "hi-en",
+		DurationMS: ht.CallDuration * 1000,
+		Timestamp:  time.Now(),
+	}
+	ar, err := m.analyze(ctx, rt)
+	if err != nil {
+		return nil, nil, fmt.Errorf("analysis failed: %w", err)
+	}
+	ar.CallID, ar.SellerID = rt.CallID, rt.SellerID
+	if ar.Timestamp.IsZero() {
+		ar.Timestamp = rt.Timestamp
+	}
+	ar.Checksum = ht.Checksum()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored := *ar
+	m.analyses[ar.CallID] = &stored
+	if ar.Blocked {
+		return nil, ar, nil
+	}
+
+	sp := m.profiles[ht.GluserID]
+	if sp == nil {
+		sp = &client.SellerProfile{GluserID: ht.GluserID, CreatedAt: time.Now(), CallHistory: []client.CallSummary{}}
+		m.profiles[ht.GluserID] = sp
+	}
+	sp.CustomerType, sp.CityName, sp.Vertical, sp.VintageMonths = ht.CustomerType, ht.CityName, ht.IILVerticalName, ht.VintageMonths
+	sp.CallHistory = append([]client.CallSummary{{
+		CallID:           ar.CallID,
+		Timestamp:        ar.Timestamp,
+		Duration:         ht.CallDuration,
+		Summary:          ar.CallSummary,
+		Sentiment:        ar.Intent.Sentiment,
+		IssuesRaised:     len(ar.Issues),
+		AgentPerformance: ar.AgentPerformance,
+	}}, sp.CallHistory...)
+	sp.TotalCalls++
+	sp.LastCallAt = ar.Timestamp
+	sp.CurrentStatus.Sentiment = ar.Intent.Sentiment
+	sp.CurrentStatus.SatisfactionScore = ar.Intent.SatisfactionScore
+	sp.CurrentStatus.ChurnRisk = ar.Churn.IsLikelyToChurn
+	sp.UpdatedAt = time.Now()
+
+	profile := *sp
+	profile.CallHistory = slices.Clone(sp.CallHistory)
+	return &profile, ar, nil
+}
+
+func (m *Mock) PreviewAnalysis(ctx context.Context, rt client.RawTranscript) (*client.AnalysisResult, error) {
+	if rt.CallID == "" {
+		rt.CallID = "preview"
+	}
+	if rt.Timestamp.IsZero() {
+		rt.Timestamp = time.Now()
+	}
+	return m.analyze(ctx, rt)
+}
+
+func (m *Mock) GetCallAnalysis(ctx context.Context, callID string) (*client.AnalysisResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ar, ok := m.analyses[callID]
+	if !ok {
+		return nil, fmt.Errorf("%w: call %s", engine.ErrNotFound, callID)
+	}
+	out := *ar
+	return &out, nil
+}
+
+func (m *Mock) GetSellerProfile(ctx context.Context, gluserID string) (*client.SellerProfile, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sp, ok := m.profiles[gluserID]
+	if !ok {
+		return nil, nil
+	}
+	out := *sp
+	out.CallHistory = slices.Clone(sp.CallHistory)
+	return &out, nil
+}
+
+func (m *Mock) ListSellerIDs(ctx context.Context) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ids := make([]string, 0, len(m.profiles))
+	for id := range m.profiles {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+func (m *Mock) RunAggregation(ctx context.Context, date string) (*client.DailyAggregate, error) {
+	if _, err := config.ParseBusinessDate(date); err != nil {
+		return nil, fmt.Errorf("invalid date %q: %w", date, err)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var analyses []client.AnalysisResult
+	for _, ar := range m.analyses {
+		if config.BusinessDate(ar.Timestamp) == date {
+			analyses = append(analyses, *ar)
+		}
+	}
+	sort.Slice(analyses, func(i, j int) bool { return strings.Compare(analyses[i].CallID, analyses[j].CallID) < 0 })
+	agg := aggregate.Build(date, analyses)
+	m.aggregates[date] = agg
+	out := *agg
+	return &out, nil
+}
+
+func (m *Mock) GetDailyAggregate(ctx context.Context, date string) (*client.DailyAggregate, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	agg, ok := m.aggregates[date]
+	if !ok {
+		return nil, fmt.Errorf("%w: aggregate for %s", engine.ErrNotFound, date)
+	}
+	out := *agg
+	return &out, nil
+}
+
+// Close does nothing
+func (m *Mock) Close() error { return nil }
@@ -0,0 +1,35 @@
+package acoustic
+
+import (
+	"errors"
+	"os"
+)
+
+// ==================== ACOUSTIC SIGNALS ====================
+// Optional quality signals measured from call audio: silence, holds,
+// overtalk, interruptions and calls that end mid-speech. They're passed to
+// the LLM next to the transcript and stored with the analysis, so agent
+// performance and frustration can draw on how the call sounded, not only
+// what was said.
+//
+// The extractor is only compiled with the acoustic build tag
+// (go build -tags acoustic) and only runs with ACOUSTIC_SIGNALS=true, so
+// text-only deployments neither ship nor run it. It reads WAV audio (PCM,
+// float, G.711 mu-law/A-law); stereo recordings are taken as agent on the
+// left channel and customer on the right.
+
+var (
+	ErrNotBuilt          = errors.New("acoustic signals not built in (build with -tags acoustic)")
+	ErrUnsupportedFormat = errors.New("unsupported audio format (WAV only)")
+)
+
+// Enabled reports whether acoustic signals are extracted for calls with audio
+func Enabled() bool {
+	return Built && os.Getenv("ACOUSTIC_SIGNALS") == "true"
+}
+
+// Requested reports whether ACOUSTIC_SIGNALS asks for acoustic signals,
+// whether or not this binary can extract them
+func Requested() bool {
+	return os.Getenv("ACOUSTIC_SIGNALS") == "true"
+}
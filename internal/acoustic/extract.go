@@ -0,0 +1,319 @@
+//go:build acoustic
+
+package acoustic
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+
+	"im-ai-voice/client"
+)
+
+// Built reports whether the extractor is compiled in
+const Built = true
+
+const (
+	frameSeconds    = 0.02 // Energy is measured per 20ms frame
+	hangoverFrames  = 10   // Gaps under 200ms (between words) still count as speech
+	holdMinFrames   = 500  // Mid-call silence of 10s or more is a hold
+	interruptFrames = 25   // Starting to speak after the other side has spoken for 500ms interrupts them
+	minSpeechRMS    = 0.005
+
+	longHoldSeconds       = 60
+	longHoldTotalSeconds  = 120
+	deadAirRatio          = 0.4
+	heavyOvertalkRatio    = 0.15
+	agentInterruptsPerMin = 2.0
+	custInterruptsPerMin  = 3.0
+)
+
+// Extract measures acoustic signals from WAV audio
+func Extract(data []byte) (*client.AcousticSignals, error) {
+	w, err := decodeWAV(data)
+	if err != nil {
+		return nil, err
+	}
+
+	frameLen := int(float64(w.rate) * frameSeconds)
+	frames := w.samples / frameLen
+	if frames < int(1/frameSeconds) {
+		return nil, fmt.Errorf("audio too short (%d samples)", w.samples)
+	}
+
+	active := make([][]bool, w.channels)
+	for ch := range active {
+		active[ch] = voiceActivity(frameRMS(w, ch, frameLen, frames))
+	}
+
+	s := &client.AcousticSignals{
+		DurationSeconds: float64(frames) * frameSeconds,
+		Channels:        w.channels,
+	}
+
+	// Silence and holds, over all channels
+	speech := make([]bool, frames)
+	silent := 0
+	for f := range speech {
+		for ch := range active {
+			speech[f] = speech[f] || active[ch][f]
+		}
+		if !speech[f] {
+			silent++
+		}
+	}
+	s.SilenceRatio = round2(float64(silent) / float64(frames))
+	s.EndedAbruptly = speech[frames-1]
+
+	firstSpeech, lastSpeech := -1, -1
+	for f := range speech {
+		if speech[f] {
+			if firstSpeech < 0 {
+				firstSpeech = f
+			}
+			lastSpeech = f
+		}
+	}
+	for f := firstSpeech; firstSpeech >= 0 && f <= lastSpeech; {
+		if speech[f] {
+			f++
+			continue
+		}
+		start := f
+		for f <= lastSpeech && !speech[f] {
+			f++
+		}
+		if n := f - start; n >= holdMinFrames {
+			secs := float64(n) * frameSeconds
+			s.HoldCount++
+			s.HoldSeconds += secs
+			s.LongestHoldSeconds = math.Max(s.LongestHoldSeconds, secs)
+		}
+	}
+
+	// Overtalk and interruptions, agent (left) against customer (right)
+	if w.channels >= 2 {
+		agent, customer := active[0], active[1]
+		both, spoken := 0, 0
+		agentRun, customerRun := 0, 0
+		for f := 0; f < frames; f++ {
+			if agent[f] && customer[f] {
+				both++
+			}
+			if agent[f] || customer[f] {
+				spoken++
+			}
+			if f > 0 && agent[f] && !agent[f-1] && customerRun >= interruptFrames {
+				s.AgentInterruptions++
+			}
+			if f > 0 && customer[f] && !customer[f-1] && agentRun >= interruptFrames {
+				s.CustomerInterruptions++
+			}
+			agentRun = runLength(agent[f], agentRun)
+			customerRun = runLength(customer[f], customerRun)
+		}
+		if spoken > 0 {
+			s.OvertalkRatio = round2(float64(both) / float64(spoken))
+		}
+	}
+
+	deriveSignals(s)
+	return s, nil
+}
+
+func runLength(active bool, run int) int {
+	if active {
+		return run + 1
+	}
+	return 0
+}
+
+// deriveSignals flags the measurements that bear on agent performance and
+// customer frustration
+func deriveSignals(s *client.AcousticSignals) {
+	minutes := math.Max(s.DurationSeconds/60, 1)
+	if s.LongestHoldSeconds >= longHoldSeconds || s.HoldSeconds >= longHoldTotalSeconds {
+		s.AgentSignals = append(s.AgentSignals, client.SignalLongHold)
+	}
+	if s.SilenceRatio >= deadAirRatio {
+		s.AgentSignals = append(s.AgentSignals, client.SignalDeadAir)
+	}
+	if float64(s.AgentInterruptions)/minutes >= agentInterruptsPerMin {
+		s.AgentSignals = append(s.AgentSignals, client.SignalAgentInterrupts)
+	}
+	if float64(s.CustomerInterruptions)/minutes >= custInterruptsPerMin {
+		s.FrustrationSignals = append(s.FrustrationSignals, client.SignalCustomerInterrupt)
+	}
+	if s.OvertalkRatio >= heavyOvertalkRatio {
+		s.FrustrationSignals = append(s.FrustrationSignals, client.SignalHeavyOvertalk)
+	}
+	if s.EndedAbruptly {
+		s.FrustrationSignals = append(s.FrustrationSignals, client.SignalCallDropped)
+	}
+}
+
+// frameRMS returns the RMS level of each frame of one channel
+func frameRMS(w *wav, ch, frameLen, frames int) []float64 {
+	rms := make([]float64, frames)
+	for f := 0; f < frames; f++ {
+		sum := 0.0
+		for i := f * frameLen; i < (f+1)*frameLen; i++ {
+			v := w.sample(i, ch)
+			sum += v * v
+		}
+		rms[f] = math.Sqrt(sum / float64(frameLen))
+	}
+	return rms
+}
+
+// voiceActivity marks frames well above the channel's noise floor as speech,
+// bridging short gaps between words
+func voiceActivity(rms []float64) []bool {
+	sorted := append([]float64(nil), rms...)
+	sort.Float64s(sorted)
+	noise := sorted[len(sorted)/10]
+	peak := sorted[len(sorted)*95/100]
+	threshold := math.Max(minSpeechRMS, noise+(peak-noise)*0.1)
+
+	active := make([]bool, len(rms))
+	last := -1
+	for f, v := range rms {
+		if v < threshold {
+			continue
+		}
+		active[f] = true
+		if last >= 0 && f-last <= hangoverFrames {
+			for g := last + 1; g < f; g++ {
+				active[g] = true
+			}
+		}
+		last = f
+	}
+	return active
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// ==================== WAV DECODING ====================
+
+// WAV format codes
+const (
+	wavPCM        = 1
+	wavFloat      = 3
+	wavALaw       = 6
+	wavMuLaw      = 7
+	wavExtensible = 0xFFFE
+)
+
+// wav is decoded lazily: sample converts on read
+type wav struct {
+	format   int
+	channels int
+	rate     int
+	bits     int
+	samples  int // Per channel
+	data     []byte
+}
+
+func decodeWAV(data []byte) (*wav, error) {
+	if len(data) < 12 || !bytes.Equal(data[0:4], []byte("RIFF")) || !bytes.Equal(data[8:12], []byte("WAVE")) {
+		return nil, ErrUnsupportedFormat
+	}
+
+	w := &wav{}
+	for off := 12; off+8 <= len(data); {
+		id := string(data[off : off+4])
+		size := int(binary.LittleEndian.Uint32(data[off+4 : off+8]))
+		body := data[off+8:]
+		if size > len(body) || size < 0 {
+			size = len(body) // Streamed WAVs leave the size unset
+		}
+		body = body[:size]
+
+		switch id {
+		case "fmt ":
+			if len(body) < 16 {
+				return nil, fmt.Errorf("malformed WAV fmt chunk")
+			}
+			w.format = int(binary.LittleEndian.Uint16(body[0:2]))
+			w.channels = int(binary.LittleEndian.Uint16(body[2:4]))
+			w.rate = int(binary.LittleEndian.Uint32(body[4:8]))
+			w.bits = int(binary.LittleEndian.Uint16(body[14:16]))
+			if w.format == wavExtensible && len(body) >= 26 {
+				w.format = int(binary.LittleEndian.Uint16(body[24:26]))
+			}
+		case "data":
+			w.data = body
+		}
+		off += 8 + size + size%2
+	}
+
+	if w.channels == 0 || w.rate == 0 || w.data == nil {
+		return nil, fmt.Errorf("malformed WAV: missing fmt or data chunk")
+	}
+	switch {
+	case w.format == wavPCM && (w.bits == 8 || w.bits == 16 || w.bits == 24 || w.bits == 32),
+		w.format == wavFloat && w.bits == 32,
+		(w.format == wavALaw || w.format == wavMuLaw) && w.bits == 8:
+	default:
+		return nil, fmt.Errorf("%w: WAV format %d, %d bits", ErrUnsupportedFormat, w.format, w.bits)
+	}
+	w.samples = len(w.data) / (w.channels * w.bits / 8)
+	return w, nil
+}
+
+// sample returns sample i of channel ch, scaled to [-1, 1]
+func (w *wav) sample(i, ch int) float64 {
+	width := w.bits / 8
+	b := w.data[(i*w.channels+ch)*width:]
+	switch w.format {
+	case wavFloat:
+		return float64(math.Float32frombits(binary.LittleEndian.Uint32(b)))
+	case wavMuLaw:
+		return muLaw(b[0])
+	case wavALaw:
+		return aLaw(b[0])
+	}
+	switch width {
+	case 1:
+		return (float64(b[0]) - 128) / 128 // 8-bit PCM is unsigned
+	case 2:
+		return float64(int16(binary.LittleEndian.Uint16(b))) / 32768
+	case 3:
+		return float64(int32(uint32(b[0])<<8|uint32(b[1])<<16|uint32(b[2])<<24)>>8) / (1 << 23)
+	default:
+		return float64(int32(binary.LittleEndian.Uint32(b))) / (1 << 31)
+	}
+}
+
+// muLaw decodes a G.711 mu-law byte
+func muLaw(b byte) float64 {
+	u := ^b
+	mag := ((int(u&0x0F) << 3) + 0x84) << ((u >> 4) & 0x07)
+	mag -= 0x84
+	if u&0x80 != 0 {
+		return -float64(mag) / 32768
+	}
+	return float64(mag) / 32768
+}
+
+// aLaw decodes a G.711 A-law byte
+func aLaw(b byte) float64 {
+	a := b ^ 0x55
+	mant := int(a & 0x0F)
+	exp := (a >> 4) & 0x07
+	var mag int
+	if exp == 0 {
+		mag = mant<<4 + 8
+	} else {
+		mag = (mant<<4 + 0x108) << (exp - 1)
+	}
+	if a&0x80 == 0 {
+		return -float64(mag) / 32768
+	}
+	return float64(mag) / 32768
+}
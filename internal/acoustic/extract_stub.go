@@ -0,0 +1,13 @@
+//go:build !acoustic
+
+package acoustic
+
+import "im-ai-voice/client"
+
+// Built reports whether the extractor is compiled in
+const Built = false
+
+// Extract is unavailable without the acoustic build tag
+func Extract(data []byte) (*client.AcousticSignals, error) {
+	return nil, ErrNotBuilt
+}
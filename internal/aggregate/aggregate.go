@@ -0,0 +1,245 @@
+package aggregate
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"im-ai-voice/client"
+)
+
+// ErrInvalidQuery wraps the errors of analytics reports asked for with
+// invalid parameters, such as a date range that's too large
+var ErrInvalidQuery = errors.New("invalid analytics query")
+
+// ==================== DAILY AGGREGATION ====================
+
+// Build creates a DailyAggregate from analysis results
+func Build(date string, analyses []client.AnalysisResult) *client.DailyAggregate {
+	agg := &client.DailyAggregate{
+		Date:                 date,
+		TotalCalls:           len(analyses),
+		FeatureBuckets:       make(map[string]client.BucketSummary),
+		VerticalBuckets:      make(map[string]client.VerticalBucketSummary),
+		SentimentBreakdown:   make(map[string]int),
+		ChurnRiskBreakdown:   make(map[string]int),
+		ChurnReasonBreakdown: make(map[string]int),
+		UpsellSKUBreakdown:   make(map[string]int),
+		SystemBreakdown:      make(map[string]int),
+		RegionBreakdown:      make(map[string]int),
+		DirectionBreakdown:   make(map[string]int),
+		CallStatusBreakdown:  make(map[string]int),
+		ChannelBreakdown:     make(map[string]int),
+		UnmappedCallValues:   make(map[string]int),
+		GeneratedAt:          time.Now(),
+	}
+
+	// Track unique sellers per bucket
+	bucketSellers := make(map[string]map[string]bool)
+	// Track problems per bucket
+	bucketProblems := make(map[string]map[string]int)
+	// Track severity per bucket
+	bucketSeverity := make(map[string]map[string]int)
+	// Track examples per bucket
+	bucketExamples := make(map[string][]string)
+	// Track issues reopening a resolved tracked issue per bucket, out of all the bucket's issues
+	bucketReopened := make(map[string]int)
+	bucketIssues := make(map[string]int)
+	// Track unique sellers per vertical bucket
+	verticalSellers := make(map[string]map[string]bool)
+	// Track issues per bucket by contact channel
+	channelBuckets := make(map[string]map[string]int)
+
+	totalSatisfaction := 0
+	satisfactionCount := 0
+
+	for _, a := range analyses {
+		if a.Blocked {
+			agg.BlockedCalls++
+			continue
+		}
+		if a.Provisional {
+			agg.ProvisionalCalls++
+		}
+
+		// Sentiment breakdown
+		if a.Intent.Sentiment != "" {
+			agg.SentimentBreakdown[a.Intent.Sentiment]++
+		}
+
+		// Source breakdowns
+		if a.Origin != nil {
+			if a.Origin.System != "" {
+				agg.SystemBreakdown[a.Origin.System]++
+			}
+			if a.Origin.Region != "" {
+				agg.RegionBreakdown[a.Origin.Region]++
+			}
+		}
+
+		// Channel breakdown; analyses from before channels were recorded are calls
+		channel := client.ContactChannel(a.Channel)
+		agg.ChannelBreakdown[channel]++
+
+		// Direction and status breakdowns, for calls from transcripts giving them
+		if c := a.Call; c != nil {
+			agg.DirectionBreakdown[orUnknownCall(c.Direction)]++
+			agg.CallStatusBreakdown[orUnknownCall(c.Status)]++
+			if c.RawDirection != "" && c.Direction == "" {
+				agg.UnmappedCallValues["flag_in_out="+c.RawDirection]++
+			}
+			if c.RawStatus != "" && c.Status == "" {
+				agg.UnmappedCallValues["call_status="+c.RawStatus]++
+			}
+		}
+
+		// Churn risk breakdown
+		if a.Churn.IsLikelyToChurn != "" {
+			agg.ChurnRiskBreakdown[a.Churn.IsLikelyToChurn]++
+		}
+		if category := churnReason(a); category != "" && atChurnRisk(a) {
+			agg.ChurnReasonBreakdown[category]++
+		}
+
+		// Upsell opportunities
+		if a.Upsell.HasOpportunity {
+			agg.UpsellOpportunities++
+			for _, sku := range upsellSKUs(a) {
+				agg.UpsellSKUBreakdown[sku]++
+			}
+		}
+
+		// Satisfaction score
+		if a.Intent.SatisfactionScore > 0 {
+			totalSatisfaction += a.Intent.SatisfactionScore
+			satisfactionCount++
+		}
+
+		// Process issues
+		for _, issue := range a.Issues {
+			agg.TotalIssues++
+			bucket := issue.Bucket
+
+			// Initialize maps if needed
+			if bucketSellers[bucket] == nil {
+				bucketSellers[bucket] = make(map[string]bool)
+			}
+			if bucketProblems[bucket] == nil {
+				bucketProblems[bucket] = make(map[string]int)
+			}
+			if bucketSeverity[bucket] == nil {
+				bucketSeverity[bucket] = make(map[string]int)
+			}
+
+			bucketSellers[bucket][a.SellerID] = true
+			bucketProblems[bucket][issue.Problem]++
+			bucketSeverity[bucket][issue.Severity]++
+			bucketIssues[bucket]++
+			if channelBuckets[channel] == nil {
+				channelBuckets[channel] = make(map[string]int)
+			}
+			channelBuckets[channel][bucket]++
+			if issue.ReopenedIssueID != "" {
+				bucketReopened[bucket]++
+				agg.ReopenedIssues++
+			}
+			if issue.Type == client.IssueTypeBillingDispute && issue.Dispute != nil {
+				agg.BillingDisputes++
+				if over := issue.Dispute.Charged - issue.Dispute.Expected; over > 0 {
+					agg.DisputedAmount += over
+				}
+			}
+
+			if vb := issue.VerticalBucket; vb != "" {
+				summary := agg.VerticalBuckets[vb]
+				summary.Bucket, summary.Parent = vb, bucket
+				summary.TotalCount++
+				if verticalSellers[vb] == nil {
+					verticalSellers[vb] = make(map[string]bool)
+				}
+				verticalSellers[vb][a.SellerID] = true
+				summary.AffectedSellers = len(verticalSellers[vb])
+				agg.VerticalBuckets[vb] = summary
+			}
+
+			// Store example (limit to 3 per bucket)
+			if len(bucketExamples[bucket]) < 3 {
+				bucketExamples[bucket] = append(bucketExamples[bucket], issue.ActionableSummary)
+			}
+		}
+	}
+
+	// Calculate average satisfaction
+	if satisfactionCount > 0 {
+		agg.AvgSatisfaction = float64(totalSatisfaction) / float64(satisfactionCount)
+	}
+
+	// Split buckets by channel only when the day had chats - for calls alone
+	// it would repeat FeatureBuckets
+	if agg.ChannelBreakdown[client.ChannelChat]+agg.ChannelBreakdown[client.ChannelWhatsApp] > 0 {
+		agg.ChannelBuckets = channelBuckets
+	}
+
+	// Build bucket summaries
+	for bucket, problems := range bucketProblems {
+		// Sort problems by count
+		type kv struct {
+			Problem string
+			Count   int
+		}
+		var sorted []kv
+		for p, c := range problems {
+			sorted = append(sorted, kv{p, c})
+		}
+		sort.Slice(sorted, func(i, j int) bool {
+			return sorted[i].Count > sorted[j].Count
+		})
+
+		// Get top problems (max 5)
+		topProblems := make([]client.ProblemCount, 0)
+		totalCount := 0
+		for i, kv := range sorted {
+			if i >= 5 {
+				break
+			}
+			topProblems = append(topProblems, client.ProblemCount{
+				Problem:  kv.Problem,
+				Count:    kv.Count,
+				Severity: "medium", // Default, could be improved
+			})
+			totalCount += kv.Count
+		}
+
+		// Get seller IDs list
+		sellerIDs := make([]string, 0, len(bucketSellers[bucket]))
+		for sellerID := range bucketSellers[bucket] {
+			sellerIDs = append(sellerIDs, sellerID)
+		}
+
+		summary := client.BucketSummary{
+			Bucket:            bucket,
+			TotalCount:        totalCount,
+			AffectedSellers:   len(bucketSellers[bucket]),
+			AffectedSellerIDs: sellerIDs,
+			TopProblems:       topProblems,
+			SeverityBreakdown: bucketSeverity[bucket],
+			Examples:          bucketExamples[bucket],
+			Reopened:          bucketReopened[bucket],
+		}
+		if summary.Reopened > 0 {
+			summary.ReopenRate = math.Round(float64(summary.Reopened)/float64(bucketIssues[bucket])*1000) / 1000
+		}
+		agg.FeatureBuckets[bucket] = summary
+	}
+
+	return agg
+}
+
+// orUnknownCall is the breakdown key of a call direction or status, "unknown" when unrecognized
+func orUnknownCall(v string) string {
+	if v == "" {
+		return "unknown"
+	}
+	return v
+}
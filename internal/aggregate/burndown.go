@@ -0,0 +1,168 @@
+package aggregate
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== TICKET BURNDOWN ====================
+// How well the auto-ticketing loop closes what it opens. A ticket counts
+// from the day it was raised for (its date) until it's resolved; tickets
+// from before the range still open in it count as open. Resolution time
+// runs from a ticket's creation to its resolution, recorded since tickets
+// carry resolved_at: those resolved earlier count as resolved in their
+// bucket but aren't placed on a day.
+
+const (
+	burndownMaxDays    = 366
+	burndownOldestOpen = 20
+)
+
+// BuildTicketBurndown reports open and resolved tickets over [from, to]
+func BuildTicketBurndown(ctx context.Context, from, to time.Time) (*client.TicketBurndown, error) {
+	if to.Before(from) {
+		return nil, fmt.Errorf("%w: to date is before from date", ErrInvalidQuery)
+	}
+	if days := int(to.Sub(from).Hours()/24) + 1; days > burndownMaxDays {
+		return nil, fmt.Errorf("%w: date range too large (%d days, max %d)", ErrInvalidQuery, days, burndownMaxDays)
+	}
+
+	fromDate, toDate := from.Format(config.DateLayout), to.Format(config.DateLayout)
+	tickets, err := storage.LoadTicketsThrough(ctx, toDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tickets: %w", err)
+	}
+
+	report := &client.TicketBurndown{
+		From:        fromDate,
+		To:          toDate,
+		Days:        []client.TicketBurndownDay{},
+		Buckets:     []client.TicketBucketResolution{},
+		OldestOpen:  []client.OpenTicketAge{},
+		GeneratedAt: time.Now(),
+	}
+	for _, t := range tickets {
+		if t.Status == client.TicketResolved && t.ResolvedAt == nil {
+			report.UntimedResolved++
+		}
+	}
+
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		date := d.Format(config.DateLayout)
+		start := time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, config.BusinessTZ)
+		end := start.AddDate(0, 0, 1)
+		day := client.TicketBurndownDay{Date: date}
+		for _, t := range tickets {
+			if t.Date > date || (t.Status == client.TicketResolved && t.ResolvedAt == nil) {
+				continue
+			}
+			if t.Date == date {
+				day.Created++
+			}
+			switch {
+			case !resolvedBy(t, end):
+				day.Open++
+			default:
+				day.ResolvedTotal++
+				if !t.ResolvedAt.Before(start) {
+					day.Resolved++
+				}
+			}
+		}
+		report.Days = append(report.Days, day)
+	}
+
+	buckets := make(map[string]*client.TicketBucketResolution)
+	hours := make(map[string][]float64)
+	for _, t := range tickets {
+		if t.Date < fromDate {
+			continue
+		}
+		b := buckets[t.FeatureBucket]
+		if b == nil {
+			b = &client.TicketBucketResolution{FeatureBucket: t.FeatureBucket}
+			buckets[t.FeatureBucket] = b
+		}
+		b.Tickets++
+		if t.Status != client.TicketResolved {
+			b.Open++
+			continue
+		}
+		b.Resolved++
+		if t.ResolvedAt != nil {
+			hours[t.FeatureBucket] = append(hours[t.FeatureBucket], t.ResolvedAt.Sub(ticketCreatedAt(t)).Hours())
+		}
+	}
+	for bucket, b := range buckets {
+		b.ResolutionRate = share(b.Resolved, b.Tickets)
+		if h := hours[bucket]; len(h) > 0 {
+			sort.Float64s(h)
+			median := h[len(h)/2]
+			if len(h)%2 == 0 {
+				median = (h[len(h)/2-1] + h[len(h)/2]) / 2
+			}
+			b.MedianResolutionHours = round1(median)
+		}
+		report.Buckets = append(report.Buckets, *b)
+	}
+	sort.Slice(report.Buckets, func(i, j int) bool {
+		if report.Buckets[i].Tickets != report.Buckets[j].Tickets {
+			return report.Buckets[i].Tickets > report.Buckets[j].Tickets
+		}
+		return report.Buckets[i].FeatureBucket < report.Buckets[j].FeatureBucket
+	})
+
+	rangeEnd := time.Date(to.Year(), to.Month(), to.Day()+1, 0, 0, 0, 0, config.BusinessTZ)
+	asOf := rangeEnd
+	if now := time.Now(); now.Before(asOf) {
+		asOf = now
+	}
+	for _, t := range tickets {
+		if (t.Status == client.TicketResolved && t.ResolvedAt == nil) || resolvedBy(t, rangeEnd) {
+			continue
+		}
+		status := t.Status
+		if status == client.TicketResolved {
+			status = client.TicketOpen // Resolved after the range
+		}
+		created := ticketCreatedAt(t)
+		report.OldestOpen = append(report.OldestOpen, client.OpenTicketAge{
+			TicketID:      t.TicketID,
+			Date:          t.Date,
+			FeatureBucket: t.FeatureBucket,
+			Severity:      t.Severity,
+			Status:        status,
+			IssueURL:      t.IssueURL,
+			CreatedAt:     created,
+			AgeDays:       round1(max(asOf.Sub(created).Hours()/24, 0)),
+		})
+	}
+	sort.Slice(report.OldestOpen, func(i, j int) bool {
+		return report.OldestOpen[i].CreatedAt.Before(report.OldestOpen[j].CreatedAt)
+	})
+	if len(report.OldestOpen) > burndownOldestOpen {
+		report.OldestOpen = report.OldestOpen[:burndownOldestOpen]
+	}
+	return report, nil
+}
+
+// resolvedBy reports whether a ticket with a resolution time was resolved before end
+func resolvedBy(t client.Ticket, end time.Time) bool {
+	return t.Status == client.TicketResolved && t.ResolvedAt != nil && t.ResolvedAt.Before(end)
+}
+
+// ticketCreatedAt is when a ticket was created, the start of its date for
+// those without a creation time
+func ticketCreatedAt(t client.Ticket) time.Time {
+	if !t.CreatedAt.IsZero() {
+		return t.CreatedAt
+	}
+	created, _ := config.ParseBusinessDate(t.Date)
+	return created
+}
@@ -0,0 +1,182 @@
+package aggregate
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+)
+
+// ==================== SEVERITY CALIBRATION ====================
+// The severities the LLM gives issues, checked against what became of them.
+// Each tracked issue first reported in the window gets an outcome severity:
+// low, raised a level for each of being slow to resolve (not resolved
+// within SEVERITY_CALIBRATION_SLOW_DAYS), repeating (reopened or raised on
+// another call) and the seller being at high churn risk now. A bucket whose
+// issues are rated SEVERITY_CALIBRATION_SKEW levels above their outcomes on
+// average is over-rated, one rated that far below is under-rated, and both
+// get a hint for the extraction prompt.
+
+// severityLevels ranks severities, low to critical
+var severityLevels = map[string]int{"low": 1, "medium": 2, "high": 3, "critical": 4}
+
+// calibrationIssue is one issue's rating and outcome
+type calibrationIssue struct {
+	severity       string
+	resolved       bool
+	daysToResolve  float64
+	slow, repeated bool
+	churn          bool
+}
+
+// outcomeLevel is the severity the issue's outcome suggests
+func (i calibrationIssue) outcomeLevel() int {
+	level := 1
+	for _, bad := range []bool{i.slow, i.repeated, i.churn} {
+		if bad {
+			level++
+		}
+	}
+	return level
+}
+
+// BuildSeverityCalibration compares the severities of the sellers' issues
+// first reported from to to with their outcomes as of now
+func BuildSeverityCalibration(date string, profiles []*client.SellerProfile, from, to, now time.Time) *client.SeverityCalibration {
+	byBucket := make(map[string][]calibrationIssue)
+	for _, p := range profiles {
+		churn := p.CurrentStatus.ChurnRisk == "high"
+		for _, issues := range [][]client.TrackedIssue{p.ActiveIssues, p.ResolvedIssues} {
+			for _, issue := range issues {
+				if issue.FirstReportedAt.Before(from) || !issue.FirstReportedAt.Before(to) || issue.Bucket == "" {
+					continue
+				}
+				byBucket[issue.Bucket] = append(byBucket[issue.Bucket], calibrationOutcome(issue, churn, now))
+			}
+		}
+	}
+
+	report := &client.SeverityCalibration{
+		Date:        date,
+		From:        from.Format(config.DateLayout),
+		To:          to.AddDate(0, 0, -1).Format(config.DateLayout),
+		Buckets:     []client.BucketCalibration{},
+		GeneratedAt: now,
+	}
+	for bucket, issues := range byBucket {
+		report.Issues += len(issues)
+		bc := calibrateBucket(bucket, issues)
+		if bc.Hint != "" {
+			report.PromptHints = append(report.PromptHints, bc.Hint)
+		}
+		report.Buckets = append(report.Buckets, bc)
+	}
+	sort.Slice(report.Buckets, func(i, j int) bool {
+		a, b := report.Buckets[i], report.Buckets[j]
+		if (a.Calibration == client.CalibrationInsufficient) != (b.Calibration == client.CalibrationInsufficient) {
+			return b.Calibration == client.CalibrationInsufficient
+		}
+		if math.Abs(a.Skew) != math.Abs(b.Skew) {
+			return math.Abs(a.Skew) > math.Abs(b.Skew)
+		}
+		return a.Bucket < b.Bucket
+	})
+	sort.Strings(report.PromptHints)
+	return report
+}
+
+// calibrationOutcome reads an issue's rating and outcome; its first
+// resolution counts, even if it was reopened since
+func calibrationOutcome(issue client.TrackedIssue, churn bool, now time.Time) calibrationIssue {
+	severity := strings.ToLower(issue.BaseSeverity) // The LLM's, before seller value raised it
+	if severity == "" {
+		severity = strings.ToLower(issue.Severity)
+	}
+	if _, ok := severityLevels[severity]; !ok {
+		severity = "medium"
+	}
+	ci := calibrationIssue{
+		severity: severity,
+		repeated: issue.ReopenCount > 0 || issue.IsRecurring,
+		churn:    churn,
+	}
+
+	var resolvedAt *time.Time
+	if len(issue.PreviousResolvedAt) > 0 {
+		resolvedAt = &issue.PreviousResolvedAt[0]
+	} else if issue.ResolvedAt != nil {
+		resolvedAt = issue.ResolvedAt
+	}
+	slowAfter := time.Duration(config.SEVERITY_CALIBRATION_SLOW_DAYS) * 24 * time.Hour
+	if resolvedAt != nil {
+		ci.resolved = true
+		ci.daysToResolve = resolvedAt.Sub(issue.FirstReportedAt).Hours() / 24
+		ci.slow = resolvedAt.Sub(issue.FirstReportedAt) > slowAfter
+	} else {
+		ci.slow = now.Sub(issue.FirstReportedAt) > slowAfter
+	}
+	return ci
+}
+
+func calibrateBucket(bucket string, issues []calibrationIssue) client.BucketCalibration {
+	bc := client.BucketCalibration{
+		Bucket:     bucket,
+		Issues:     len(issues),
+		BySeverity: make(map[string]client.SeverityOutcome),
+	}
+	var assigned, outcome int
+	bySeverity := make(map[string][]calibrationIssue)
+	for _, i := range issues {
+		assigned += severityLevels[i.severity]
+		outcome += i.outcomeLevel()
+		bySeverity[i.severity] = append(bySeverity[i.severity], i)
+	}
+	n := float64(len(issues))
+	bc.AssignedScore = round3(float64(assigned) / n)
+	bc.OutcomeScore = round3(float64(outcome) / n)
+	bc.Skew = round3(bc.AssignedScore - bc.OutcomeScore)
+
+	for severity, list := range bySeverity {
+		var so client.SeverityOutcome
+		var days float64
+		var resolved, repeated, churned int
+		for _, i := range list {
+			if i.resolved {
+				resolved++
+				days += i.daysToResolve
+			}
+			if i.repeated {
+				repeated++
+			}
+			if i.churn {
+				churned++
+			}
+		}
+		so.Issues = len(list)
+		so.ResolvedRate = share(resolved, len(list))
+		so.RepeatRate = share(repeated, len(list))
+		so.ChurnRate = share(churned, len(list))
+		if resolved > 0 {
+			so.AvgDaysToResolve = round1(days / float64(resolved))
+		}
+		bc.BySeverity[severity] = so
+	}
+
+	switch {
+	case len(issues) < config.SEVERITY_CALIBRATION_MIN_ISSUES:
+		bc.Calibration = client.CalibrationInsufficient
+	case bc.Skew >= config.SEVERITY_CALIBRATION_SKEW:
+		bc.Calibration, bc.Adjustment = client.CalibrationOver, -1
+		bc.Hint = fmt.Sprintf("%q issues have been rated more severe than they turned out: most were resolved promptly, didn't come back and their sellers stayed. Keep high and critical for %s issues that stop the seller's business or put their renewal at risk.", bucket, bucket)
+	case bc.Skew <= -config.SEVERITY_CALIBRATION_SKEW:
+		bc.Calibration, bc.Adjustment = client.CalibrationUnder, 1
+		bc.Hint = fmt.Sprintf("%q issues have turned out worse than rated: slow to resolve, raised again or with sellers at churn risk. Don't rate %s issues low unless they were resolved on the call.", bucket, bucket)
+	default:
+		bc.Calibration = client.CalibrationCalibrated
+	}
+	return bc
+}
@@ -0,0 +1,105 @@
+package aggregate
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== CHURN REASONS ====================
+// At-risk calls (medium or high churn risk) counted by churn reason category.
+// Analyses from before the taxonomy only have the free-text churn_reason and
+// are classified by keyword (config.ClassifyChurnReason).
+
+const (
+	churnReasonMaxDays  = 92
+	churnReasonExamples = 3
+)
+
+// atChurnRisk reports whether a call counts towards churn reasons
+func atChurnRisk(a client.AnalysisResult) bool {
+	return a.Churn.IsLikelyToChurn == "medium" || a.Churn.IsLikelyToChurn == "high"
+}
+
+// inStage reports whether a call's seller was in a journey stage at the
+// time, every call for an empty stage. Calls analyzed before stages were
+// recorded have none.
+func inStage(a client.AnalysisResult, stage string) bool {
+	return stage == "" || a.JourneyStage == stage
+}
+
+// churnReason returns a call's churn reason category, "" without a reason
+func churnReason(a client.AnalysisResult) string {
+	return config.ClassifyChurnReason(a.Churn.ChurnReasonCategory, a.Churn.ChurnReason)
+}
+
+// BuildChurnReasons counts at-risk calls by churn reason over [from, to],
+// of sellers in stage (all for "")
+func BuildChurnReasons(ctx context.Context, from, to time.Time, stage string) (*client.ChurnReasonReport, error) {
+	if to.Before(from) {
+		return nil, fmt.Errorf("%w: to date is before from date", ErrInvalidQuery)
+	}
+	if days := int(to.Sub(from).Hours()/24) + 1; days > churnReasonMaxDays {
+		return nil, fmt.Errorf("%w: date range too large (%d days, max %d)", ErrInvalidQuery, days, churnReasonMaxDays)
+	}
+
+	counts := make(map[string]*client.ChurnReasonCount, len(config.ChurnReasonCategories))
+	for _, c := range config.ChurnReasonCategories {
+		counts[c] = &client.ChurnReasonCount{Category: c}
+	}
+	report := &client.ChurnReasonReport{GeneratedAt: time.Now()}
+
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		date := d.Format(config.DateLayout)
+		analyses, err := storage.LoadAnalysesForDate(ctx, date)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load analyses for %s: %w", date, err)
+		}
+
+		day := client.ChurnReasonDay{Date: date, Counts: make(map[string]int)}
+		for _, a := range analyses {
+			if !atChurnRisk(a) || !inStage(a, stage) {
+				continue
+			}
+			day.AtRiskCalls++
+			category := churnReason(a)
+			if category == "" {
+				report.Unspecified++
+				continue
+			}
+			day.Counts[category]++
+
+			c := counts[category]
+			c.Count++
+			if a.Churn.IsLikelyToChurn == "high" {
+				c.HighRisk++
+			}
+			if len(c.Examples) < churnReasonExamples && a.Churn.ChurnReason != "" {
+				c.Examples = append(c.Examples, a.Churn.ChurnReason)
+			}
+		}
+		report.AtRiskCalls += day.AtRiskCalls
+		report.Days = append(report.Days, day)
+	}
+	report.From = report.Days[0].Date
+	report.To = report.Days[len(report.Days)-1].Date
+
+	withReason := report.AtRiskCalls - report.Unspecified
+	for _, name := range config.ChurnReasonCategories {
+		c := counts[name]
+		if withReason > 0 {
+			c.Share = math.Round(float64(c.Count)/float64(withReason)*1000) / 1000
+		}
+		report.Categories = append(report.Categories, *c)
+	}
+	sort.SliceStable(report.Categories, func(i, j int) bool {
+		return report.Categories[i].Count > report.Categories[j].Count
+	})
+	return report, nil
+}
@@ -0,0 +1,232 @@
+package aggregate
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+)
+
+// ==================== CHURN ACCURACY ====================
+// Churn predictions checked against what sellers actually did. Each
+// renewal or cancellation is matched with the seller's latest prediction
+// (the churn risk and renewal probability of an analyzed call) from the
+// window of days before it, and the pairs are scored per prediction
+// version, so a prompt or model change can be judged on its own outcomes.
+// Sellers predicted at risk who cancelled are true positives; lift compares
+// their cancel rate with that of all the sellers.
+
+// churnRiskLevels ranks churn risks, low to high
+var churnRiskLevels = map[string]int{"low": 1, "medium": 2, "high": 3}
+
+// churnPair is one outcome and the prediction it was matched with
+type churnPair struct {
+	risk        string
+	probability float64
+	cancelled   bool
+	predictedAt time.Time
+	rollout     string
+}
+
+// PredictionVersion names what made an analysis's churn prediction: model
+// and prompt version for Gemini, "rules" for the keyword classifier
+func PredictionVersion(a client.AnalysisResult) (version, model, promptVersion string) {
+	switch {
+	case a.Engine == client.EngineRules:
+		return client.EngineRules, "", ""
+	case a.PromptVersion == "":
+		return client.ChurnVersionUnrecorded, a.Model, ""
+	}
+	return a.Model + "@" + a.PromptVersion, a.Model, a.PromptVersion
+}
+
+// latestPrediction returns the newest analysis with a churn prediction made
+// in the window before the outcome's day; analyses are oldest first
+func latestPrediction(analyses []client.AnalysisResult, outcomeDay time.Time, windowDays int) *client.AnalysisResult {
+	earliest := outcomeDay.AddDate(0, 0, -windowDays)
+	for i := len(analyses) - 1; i >= 0; i-- {
+		a := &analyses[i]
+		if !a.Timestamp.Before(outcomeDay) {
+			continue
+		}
+		if a.Timestamp.Before(earliest) {
+			return nil
+		}
+		if _, ok := churnRiskLevels[a.Churn.IsLikelyToChurn]; ok && !a.Blocked {
+			return a
+		}
+	}
+	return nil
+}
+
+// BuildChurnAccuracy matches the outcomes with their sellers' predictions
+// (analyses by seller, oldest first) and scores each prediction version
+func BuildChurnAccuracy(outcomes []client.ChurnOutcome, analyses map[string][]client.AnalysisResult, from, to string, windowDays int, now time.Time) *client.ChurnAccuracyReport {
+	report := &client.ChurnAccuracyReport{
+		From:        from,
+		To:          to,
+		WindowDays:  windowDays,
+		Outcomes:    len(outcomes),
+		Versions:    []client.ChurnVersionAccuracy{},
+		GeneratedAt: now,
+	}
+
+	byVersion := make(map[string][]churnPair)
+	names := make(map[string][2]string)
+	for _, o := range outcomes {
+		day, err := config.ParseBusinessDate(o.Date)
+		if err != nil {
+			report.Unmatched++
+			continue
+		}
+		a := latestPrediction(analyses[o.GluserID], day, windowDays)
+		if a == nil {
+			report.Unmatched++
+			continue
+		}
+		report.Matched++
+		version, model, prompt := PredictionVersion(*a)
+		names[version] = [2]string{model, prompt}
+		byVersion[version] = append(byVersion[version], churnPair{
+			risk:        a.Churn.IsLikelyToChurn,
+			probability: math.Min(math.Max(a.Churn.RenewalProbability, 0), 1),
+			cancelled:   o.Outcome == client.OutcomeCancelled,
+			predictedAt: a.Timestamp,
+			rollout:     a.Rollout,
+		})
+	}
+
+	for version, pairs := range byVersion {
+		va := scoreChurnVersion(pairs)
+		va.Version = version
+		va.Model, va.PromptVersion = names[version][0], names[version][1]
+		report.Versions = append(report.Versions, va)
+	}
+	sort.Slice(report.Versions, func(i, j int) bool {
+		a, b := report.Versions[i], report.Versions[j]
+		if a.Outcomes != b.Outcomes {
+			return a.Outcomes > b.Outcomes
+		}
+		return a.Version < b.Version
+	})
+	return report
+}
+
+// scoreChurnVersion scores one version's matched predictions
+func scoreChurnVersion(pairs []churnPair) client.ChurnVersionAccuracy {
+	va := client.ChurnVersionAccuracy{Outcomes: len(pairs)}
+	rollouts := make(map[string]bool)
+	var brier float64
+	for _, p := range pairs {
+		if p.cancelled {
+			va.Cancelled++
+		}
+		renewed := 1.0
+		if p.cancelled {
+			renewed = 0
+		}
+		brier += (p.probability - renewed) * (p.probability - renewed)
+		if va.FirstPredictionAt.IsZero() || p.predictedAt.Before(va.FirstPredictionAt) {
+			va.FirstPredictionAt = p.predictedAt
+		}
+		if p.predictedAt.After(va.LastPredictionAt) {
+			va.LastPredictionAt = p.predictedAt
+		}
+		if p.rollout != "" && !rollouts[p.rollout] {
+			rollouts[p.rollout] = true
+			va.Rollouts = append(va.Rollouts, p.rollout)
+		}
+	}
+	sort.Strings(va.Rollouts)
+	va.Renewed = va.Outcomes - va.Cancelled
+	va.CancelRate = share(va.Cancelled, va.Outcomes)
+	va.BrierScore = round3(brier / float64(len(pairs)))
+
+	for _, minRisk := range []string{"high", "medium"} {
+		va.Thresholds = append(va.Thresholds, churnThreshold(pairs, minRisk))
+	}
+	for _, risk := range []string{"low", "medium", "high"} {
+		ro := client.ChurnRiskOutcome{Risk: risk}
+		for _, p := range pairs {
+			if p.risk == risk {
+				ro.Outcomes++
+				if p.cancelled {
+					ro.Cancelled++
+				}
+			}
+		}
+		ro.CancelRate = share(ro.Cancelled, ro.Outcomes)
+		va.ByRisk = append(va.ByRisk, ro)
+	}
+	va.Calibration = churnCalibration(pairs)
+
+	atRisk := va.Thresholds[1] // Medium or high
+	if va.CancelRate > 0 {
+		va.Lift = round3(atRisk.Precision / va.CancelRate)
+	}
+	switch {
+	case va.Outcomes < config.CHURN_ACCURACY_MIN_OUTCOMES || va.Cancelled == 0 || va.Renewed == 0:
+		va.Verdict = client.ChurnAccuracyInsufficient
+	case va.Lift >= config.CHURN_ACCURACY_MIN_LIFT:
+		va.Verdict = client.ChurnAccuracyUseful
+	case va.Lift > 1:
+		va.Verdict = client.ChurnAccuracyWeak
+	default:
+		va.Verdict = client.ChurnAccuracyNoBetter
+	}
+	return va
+}
+
+// churnThreshold scores predicting churn for the sellers at minRisk or above
+func churnThreshold(pairs []churnPair, minRisk string) client.ChurnThresholdScore {
+	ts := client.ChurnThresholdScore{MinRisk: minRisk}
+	for _, p := range pairs {
+		predicted := churnRiskLevels[p.risk] >= churnRiskLevels[minRisk]
+		switch {
+		case predicted && p.cancelled:
+			ts.TruePositives++
+		case predicted:
+			ts.FalsePositives++
+		case p.cancelled:
+			ts.FalseNegatives++
+		default:
+			ts.TrueNegatives++
+		}
+	}
+	ts.Precision = share(ts.TruePositives, ts.TruePositives+ts.FalsePositives)
+	ts.Recall = share(ts.TruePositives, ts.TruePositives+ts.FalseNegatives)
+	if ts.Precision+ts.Recall > 0 {
+		ts.F1 = round3(2 * ts.Precision * ts.Recall / (ts.Precision + ts.Recall))
+	}
+	return ts
+}
+
+// churnCalibration bands the predicted renewal probabilities, the last band
+// taking 1.0
+func churnCalibration(pairs []churnPair) []client.ChurnCalibrationBin {
+	n := config.CHURN_ACCURACY_CALIBRATION_BINS
+	bins := make([]client.ChurnCalibrationBin, n)
+	sums := make([]float64, n)
+	renewed := make([]int, n)
+	for i := range bins {
+		bins[i].Low = round3(float64(i) / float64(n))
+		bins[i].High = round3(float64(i+1) / float64(n))
+	}
+	for _, p := range pairs {
+		i := min(int(p.probability*float64(n)+1e-9), n-1) // A probability on a band edge goes in the band above, rounding errors aside
+		bins[i].Outcomes++
+		sums[i] += p.probability
+		if !p.cancelled {
+			renewed[i]++
+		}
+	}
+	for i := range bins {
+		if bins[i].Outcomes > 0 {
+			bins[i].MeanPredicted = round3(sums[i] / float64(bins[i].Outcomes))
+			bins[i].RenewalRate = share(renewed[i], bins[i].Outcomes)
+		}
+	}
+	return bins
+}
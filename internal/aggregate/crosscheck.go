@@ -0,0 +1,105 @@
+package aggregate
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== RULES CROSS-CHECK ====================
+// The rules checks kept on Gemini analyses (see service/crosscheck.go)
+// summed up over a date range, overall, per bucket and per day. Analyses
+// from before the check, by the classifier itself or blocked have none and
+// aren't counted.
+
+const crossCheckMaxDays = 92
+
+// BuildCrossCheck sums up the rules checks of the calls over [from, to]
+func BuildCrossCheck(ctx context.Context, from, to time.Time) (*client.CrossCheckReport, error) {
+	if to.Before(from) {
+		return nil, fmt.Errorf("%w: to date is before from date", ErrInvalidQuery)
+	}
+	if days := int(to.Sub(from).Hours()/24) + 1; days > crossCheckMaxDays {
+		return nil, fmt.Errorf("%w: date range too large (%d days, max %d)", ErrInvalidQuery, days, crossCheckMaxDays)
+	}
+
+	report := &client.CrossCheckReport{
+		From:        from.Format(config.DateLayout),
+		To:          to.Format(config.DateLayout),
+		Buckets:     []client.CrossCheckBucket{},
+		GeneratedAt: time.Now(),
+	}
+	buckets := make(map[string]*client.CrossCheckBucket)
+	count := func(b string) *client.CrossCheckBucket {
+		if buckets[b] == nil {
+			buckets[b] = &client.CrossCheckBucket{Bucket: b}
+		}
+		return buckets[b]
+	}
+
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		date := d.Format(config.DateLayout)
+		analyses, err := storage.LoadAnalysesForDate(ctx, date)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load analyses for %s: %w", date, err)
+		}
+
+		day := client.CrossCheckDay{Date: date}
+		for _, a := range analyses {
+			c := a.RulesCheck
+			if c == nil {
+				continue
+			}
+			day.Checked++
+			if c.Disagrees {
+				day.Disagreed++
+			}
+			if c.SentimentConflict {
+				day.SentimentConflicts++
+			}
+			for _, b := range c.AgreedBuckets {
+				count(b).Agreed++
+			}
+			for _, b := range c.LLMOnlyBuckets {
+				count(b).LLMOnly++
+			}
+			for _, b := range c.RulesOnlyBuckets {
+				count(b).RulesOnly++
+			}
+		}
+		day.DisagreementRate = ratio(day.Disagreed, day.Checked)
+		report.Checked += day.Checked
+		report.Disagreed += day.Disagreed
+		report.SentimentConflicts += day.SentimentConflicts
+		report.Days = append(report.Days, day)
+	}
+	report.DisagreementRate = ratio(report.Disagreed, report.Checked)
+	report.SentimentConflictRate = ratio(report.SentimentConflicts, report.Checked)
+
+	for _, b := range buckets {
+		b.Agreement = ratio(b.Agreed, b.Agreed+b.LLMOnly+b.RulesOnly)
+		report.Buckets = append(report.Buckets, *b)
+	}
+	sort.Slice(report.Buckets, func(i, j int) bool {
+		a, b := report.Buckets[i], report.Buckets[j]
+		if a.LLMOnly+a.RulesOnly != b.LLMOnly+b.RulesOnly {
+			return a.LLMOnly+a.RulesOnly > b.LLMOnly+b.RulesOnly
+		}
+		return a.Bucket < b.Bucket
+	})
+	return report, nil
+}
+
+// ratio is n/of rounded to 3 places, 0 when of is
+func ratio(n, of int) float64 {
+	if of == 0 {
+		return 0
+	}
+	return math.Round(float64(n)/float64(of)*1000) / 1000
+}
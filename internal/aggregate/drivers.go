@@ -0,0 +1,172 @@
+package aggregate
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== SATISFACTION DRIVERS ====================
+// Calls with a satisfaction score grouped by bucket raised, agent
+// performance, whether the issue was resolved promptly and the seller's
+// customer type. A factor's impact is how much higher the overall average
+// would be if its calls had scored the average: calls x gap / all calls.
+// Customer type comes from the seller profiles, so sellers without one land
+// in "Unknown".
+
+const (
+	driversMaxDays      = 92
+	driverMinCalls      = 5 // Fewer calls than this is too noisy to rank
+	dissatisfiedScore   = 4 // Scores at or below this (out of 10) are dissatisfied
+	driverDimBucket     = "bucket"
+	driverDimAgent      = "agent_performance"
+	driverDimResolution = "resolution"
+	driverDimCustomer   = "customer_type"
+)
+
+var driverDimensions = []string{driverDimBucket, driverDimAgent, driverDimResolution, driverDimCustomer}
+
+// driverFactor accumulates the scores of one dimension value
+type driverFactor struct {
+	calls        int
+	total        int
+	dissatisfied int
+}
+
+func (f *driverFactor) add(score int) {
+	f.calls++
+	f.total += score
+	if score <= dissatisfiedScore {
+		f.dissatisfied++
+	}
+}
+
+// BuildSatisfactionDrivers ranks the factors of scored calls over [from, to]
+// by their drag on satisfaction, for sellers in stage (all for "")
+func BuildSatisfactionDrivers(ctx context.Context, from, to time.Time, stage string) (*client.SatisfactionDriverReport, error) {
+	if to.Before(from) {
+		return nil, fmt.Errorf("%w: to date is before from date", ErrInvalidQuery)
+	}
+	if days := int(to.Sub(from).Hours()/24) + 1; days > driversMaxDays {
+		return nil, fmt.Errorf("%w: date range too large (%d days, max %d)", ErrInvalidQuery, days, driversMaxDays)
+	}
+
+	profiles, err := storage.LoadAllSellerProfiles(ctx)
+	if err != nil {
+		log.Printf("⚠️ Satisfaction drivers: failed to load seller profiles: %v", err)
+	}
+	customerTypes := make(map[string]string, len(profiles))
+	for _, p := range profiles {
+		if p.CustomerType != "" {
+			customerTypes[p.GluserID] = p.CustomerType
+		}
+	}
+
+	factors := make(map[string]map[string]*driverFactor, len(driverDimensions))
+	for _, dim := range driverDimensions {
+		factors[dim] = make(map[string]*driverFactor)
+	}
+	add := func(dim, value string, score int) {
+		f := factors[dim][value]
+		if f == nil {
+			f = &driverFactor{}
+			factors[dim][value] = f
+		}
+		f.add(score)
+	}
+
+	report := &client.SatisfactionDriverReport{
+		From:        from.Format(config.DateLayout),
+		To:          to.Format(config.DateLayout),
+		Drivers:     []client.SatisfactionFactor{},
+		Factors:     []client.SatisfactionFactor{},
+		GeneratedAt: time.Now(),
+	}
+	var overall driverFactor
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		date := d.Format(config.DateLayout)
+		analyses, err := storage.LoadAnalysesForDate(ctx, date)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load analyses for %s: %w", date, err)
+		}
+		for _, a := range analyses {
+			score := a.Intent.SatisfactionScore
+			if a.Blocked || score <= 0 || !inStage(a, stage) {
+				continue
+			}
+			overall.add(score)
+
+			seen := make(map[string]bool, len(a.Issues))
+			for _, issue := range a.Issues {
+				if issue.Bucket != "" && !seen[issue.Bucket] {
+					seen[issue.Bucket] = true
+					add(driverDimBucket, issue.Bucket, score)
+				}
+			}
+			if a.AgentPerformance != "" {
+				add(driverDimAgent, a.AgentPerformance, score)
+			}
+			if a.Intent.PromptResolution {
+				add(driverDimResolution, "prompt", score)
+			} else {
+				add(driverDimResolution, "not_prompt", score)
+			}
+			customerType, ok := customerTypes[a.SellerID]
+			if !ok {
+				customerType = "Unknown"
+			}
+			add(driverDimCustomer, customerType, score)
+		}
+	}
+	if overall.calls == 0 {
+		return report, nil
+	}
+
+	round := func(v float64) float64 { return math.Round(v*1000) / 1000 }
+	avg := float64(overall.total) / float64(overall.calls)
+	report.Calls = overall.calls
+	report.AvgSatisfaction = round(avg)
+	report.DissatisfiedRate = round(float64(overall.dissatisfied) / float64(overall.calls))
+
+	for _, dim := range driverDimensions {
+		var values []client.SatisfactionFactor
+		for value, f := range factors[dim] {
+			n := float64(f.calls)
+			factorAvg := float64(f.total) / n
+			values = append(values, client.SatisfactionFactor{
+				Dimension:        dim,
+				Value:            value,
+				Calls:            f.calls,
+				AvgSatisfaction:  round(factorAvg),
+				Delta:            round(factorAvg - avg),
+				Dissatisfied:     f.dissatisfied,
+				DissatisfiedRate: round(float64(f.dissatisfied) / n),
+				Impact:           round((avg - factorAvg) * n / float64(overall.calls)),
+			})
+		}
+		sort.Slice(values, func(i, j int) bool {
+			if values[i].Calls != values[j].Calls {
+				return values[i].Calls > values[j].Calls
+			}
+			return values[i].Value < values[j].Value
+		})
+		report.Factors = append(report.Factors, values...)
+	}
+
+	for _, f := range report.Factors {
+		if f.Delta < 0 && f.Calls >= driverMinCalls {
+			report.Drivers = append(report.Drivers, f)
+		}
+	}
+	sort.SliceStable(report.Drivers, func(i, j int) bool {
+		return report.Drivers[i].Impact > report.Drivers[j].Impact
+	})
+	return report, nil
+}
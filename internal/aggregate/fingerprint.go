@@ -0,0 +1,34 @@
+package aggregate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+
+	"im-ai-voice/client"
+)
+
+// Fingerprint hashes the analyses an aggregate is built from, so a call
+// re-analyzed, added or deleted afterwards changes it. Order doesn't matter,
+// and neither do legacy raw Gemini responses, which moving out of the
+// analysis would otherwise count as a change.
+func Fingerprint(analyses []client.AnalysisResult) string {
+	sums := make([]string, 0, len(analyses))
+	for _, a := range analyses {
+		a.LLMRaw = nil
+		b, err := json.Marshal(a)
+		if err != nil {
+			b = []byte(a.CallID)
+		}
+		sum := sha256.Sum256(b)
+		sums = append(sums, hex.EncodeToString(sum[:]))
+	}
+	sort.Strings(sums)
+
+	h := sha256.New()
+	for _, s := range sums {
+		h.Write([]byte(s))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,233 @@
+package aggregate
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== HEATMAP ANALYTICS ====================
+// Aggregates a per-call metric across cities or verticals over a date range.
+// Seller location/vertical come from the seller profiles (the analyses don't
+// carry them), so calls from sellers without a profile land in "Unknown".
+
+const heatmapMaxDays = 92
+
+// HeatmapMetrics lists the supported metrics and how they are computed
+var HeatmapMetrics = map[string]string{
+	"calls":                   "Number of analyzed calls",
+	"issues":                  "Total issues raised",
+	"issues_per_call":         "Average issues raised per call",
+	"negative_sentiment_rate": "Share of calls with Negative sentiment (0-1)",
+	"high_churn_rate":         "Share of calls with high churn risk (0-1)",
+	"avg_satisfaction":        "Average satisfaction score",
+	"upsell_rate":             "Share of calls with an upsell opportunity (0-1)",
+}
+
+// HeatmapResponse is a dimension x date matrix; Values[i][j] is the metric
+// for Rows[i] on Columns[j], and nil where there were no calls
+type HeatmapResponse struct {
+	Dimension string       `json:"dimension"`
+	Metric    string       `json:"metric"`
+	From      string       `json:"from"`
+	To        string       `json:"to"`
+	Rows      []string     `json:"rows"`
+	Columns   []string     `json:"columns"`
+	Values    [][]*float64 `json:"values"`
+	RowTotals []*float64   `json:"row_totals"`
+	Calls     [][]int      `json:"calls"`
+	Min       float64      `json:"min"`
+	Max       float64      `json:"max"`
+}
+
+// heatmapCell accumulates raw counts for one (row, date) pair
+type heatmapCell struct {
+	calls        int
+	issues       int
+	negative     int
+	highChurn    int
+	upsell       int
+	satisfaction int
+}
+
+func (c *heatmapCell) add(a client.AnalysisResult) {
+	c.calls++
+	c.issues += len(a.Issues)
+	if a.Intent.Sentiment == "Negative" {
+		c.negative++
+	}
+	if a.Churn.IsLikelyToChurn == "high" {
+		c.highChurn++
+	}
+	if a.Upsell.HasOpportunity {
+		c.upsell++
+	}
+	c.satisfaction += a.Intent.SatisfactionScore
+}
+
+func (c *heatmapCell) merge(o *heatmapCell) {
+	c.calls += o.calls
+	c.issues += o.issues
+	c.negative += o.negative
+	c.highChurn += o.highChurn
+	c.upsell += o.upsell
+	c.satisfaction += o.satisfaction
+}
+
+func (c *heatmapCell) value(metric string) *float64 {
+	if c == nil || c.calls == 0 {
+		return nil
+	}
+	n := float64(c.calls)
+	var v float64
+	switch metric {
+	case "calls":
+		v = n
+	case "issues":
+		v = float64(c.issues)
+	case "issues_per_call":
+		v = float64(c.issues) / n
+	case "negative_sentiment_rate":
+		v = float64(c.negative) / n
+	case "high_churn_rate":
+		v = float64(c.highChurn) / n
+	case "avg_satisfaction":
+		v = float64(c.satisfaction) / n
+	case "upsell_rate":
+		v = float64(c.upsell) / n
+	}
+	v = math.Round(v*1000) / 1000
+	return &v
+}
+
+// BuildHeatmap computes the heatmap for dimension ("city" or "vertical") and
+// metric over [from, to], of sellers in stage (all for "")
+func BuildHeatmap(ctx context.Context, dimension, metric string, from, to time.Time, stage string) (*HeatmapResponse, error) {
+	if dimension != "city" && dimension != "vertical" {
+		return nil, fmt.Errorf("%w: invalid dimension %q (use city or vertical)", ErrInvalidQuery, dimension)
+	}
+	if _, ok := HeatmapMetrics[metric]; !ok {
+		names := make([]string, 0, len(HeatmapMetrics))
+		for name := range HeatmapMetrics {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("%w: invalid metric %q (use one of %s)", ErrInvalidQuery, metric, strings.Join(names, ", "))
+	}
+	if to.Before(from) {
+		return nil, fmt.Errorf("%w: to date is before from date", ErrInvalidQuery)
+	}
+	if days := int(to.Sub(from).Hours()/24) + 1; days > heatmapMaxDays {
+		return nil, fmt.Errorf("%w: date range too large (%d days, max %d)", ErrInvalidQuery, days, heatmapMaxDays)
+	}
+
+	// Seller -> dimension value
+	profiles, err := storage.LoadAllSellerProfiles(ctx)
+	if err != nil {
+		log.Printf("⚠️ Heatmap: failed to load seller profiles: %v", err)
+	}
+	sellerDim := make(map[string]string, len(profiles))
+	for _, p := range profiles {
+		v := p.CityName
+		if dimension == "vertical" {
+			v = p.Vertical
+		}
+		if v != "" {
+			sellerDim[p.GluserID] = v
+		}
+	}
+
+	var dates []string
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		dates = append(dates, d.Format("2006-01-02"))
+	}
+
+	cells := make(map[string][]*heatmapCell) // row -> cell per date
+	for j, date := range dates {
+		analyses, err := storage.LoadAnalysesForDate(ctx, date)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load analyses for %s: %w", date, err)
+		}
+		for _, a := range analyses {
+			if !inStage(a, stage) {
+				continue
+			}
+			row, ok := sellerDim[a.SellerID]
+			if !ok {
+				row = "Unknown"
+			}
+			if cells[row] == nil {
+				cells[row] = make([]*heatmapCell, len(dates))
+			}
+			if cells[row][j] == nil {
+				cells[row][j] = &heatmapCell{}
+			}
+			cells[row][j].add(a)
+		}
+	}
+
+	resp := &HeatmapResponse{
+		Dimension: dimension,
+		Metric:    metric,
+		From:      dates[0],
+		To:        dates[len(dates)-1],
+		Columns:   dates,
+		Rows:      []string{},
+		Values:    [][]*float64{},
+		RowTotals: []*float64{},
+		Calls:     [][]int{},
+	}
+
+	// Rows ordered by call volume so the busiest cities/verticals come first
+	totals := make(map[string]*heatmapCell, len(cells))
+	for row, rowCells := range cells {
+		total := &heatmapCell{}
+		for _, c := range rowCells {
+			if c != nil {
+				total.merge(c)
+			}
+		}
+		totals[row] = total
+		resp.Rows = append(resp.Rows, row)
+	}
+	sort.Slice(resp.Rows, func(i, j int) bool {
+		ci, cj := totals[resp.Rows[i]].calls, totals[resp.Rows[j]].calls
+		if ci != cj {
+			return ci > cj
+		}
+		return resp.Rows[i] < resp.Rows[j]
+	})
+
+	first := true
+	for _, row := range resp.Rows {
+		values := make([]*float64, len(dates))
+		calls := make([]int, len(dates))
+		for j, c := range cells[row] {
+			values[j] = c.value(metric)
+			if c != nil {
+				calls[j] = c.calls
+			}
+			if v := values[j]; v != nil {
+				if first || *v < resp.Min {
+					resp.Min = *v
+				}
+				if first || *v > resp.Max {
+					resp.Max = *v
+				}
+				first = false
+			}
+		}
+		resp.Values = append(resp.Values, values)
+		resp.Calls = append(resp.Calls, calls)
+		resp.RowTotals = append(resp.RowTotals, totals[row].value(metric))
+	}
+
+	return resp, nil
+}
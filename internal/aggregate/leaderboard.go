@@ -0,0 +1,262 @@
+package aggregate
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== AGENT LEADERBOARD ====================
+// Agents ranked by a composite QA score over a day, week or month: the mean
+// of their agent performance ratings, the satisfaction of their calls, the
+// share of calls not escalated and the share of commitments kept, each 0-100.
+// The same ranking over the period before gives each agent's movement. The
+// upsell pitches made in the period and how many converted are reported for
+// coaching without counting towards the score.
+// Analyses from before agent_id was stored take it from the raw transcript;
+// calls that name no agent (watcher transcripts) aren't ranked.
+
+// DefaultLeaderboardMinCalls is how many calls an agent needs in a period to be ranked in it
+const DefaultLeaderboardMinCalls = 3
+
+var leaderboardPeriodDays = map[string]int{
+	client.PeriodDay:   1,
+	client.PeriodWeek:  7,
+	client.PeriodMonth: 30,
+}
+
+var performancePoints = map[string]float64{"Good": 100, "Average": 50, "Poor": 0}
+
+// agentTally accumulates one agent's calls and commitments over a period
+type agentTally struct {
+	calls        int
+	escalated    int
+	perfTotal    float64
+	perfCalls    int
+	satTotal     int
+	satCalls     int
+	kept, broken int
+	pitches      int
+	converted    int
+	lapsed       int
+}
+
+func (t *agentTally) standing(agentID string) client.AgentStanding {
+	s := client.AgentStanding{
+		AgentID:           agentID,
+		Calls:             t.calls,
+		CommitmentsKept:   t.kept,
+		CommitmentsBroken: t.broken,
+		UpsellPitches:     t.pitches,
+		PitchesConverted:  t.converted,
+	}
+	if t.converted+t.lapsed > 0 {
+		rate := math.Round(float64(t.converted)/float64(t.converted+t.lapsed)*1000) / 1000
+		s.PitchConversion = &rate
+	}
+	var components []float64
+	add := func(v float64) *float64 {
+		v = round1(v)
+		components = append(components, v)
+		return &v
+	}
+	if t.perfCalls > 0 {
+		s.PerformanceScore = add(t.perfTotal / float64(t.perfCalls))
+	}
+	if t.satCalls > 0 {
+		s.SatisfactionScore = add((float64(t.satTotal)/float64(t.satCalls) - 1) / 9 * 100)
+	}
+	if t.calls > 0 {
+		s.EscalationRate = math.Round(float64(t.escalated)/float64(t.calls)*1000) / 1000
+		s.EscalationScore = add((1 - float64(t.escalated)/float64(t.calls)) * 100)
+	}
+	if t.kept+t.broken > 0 {
+		s.CommitmentScore = add(float64(t.kept) / float64(t.kept+t.broken) * 100)
+	}
+	if len(components) > 0 {
+		var total float64
+		for _, c := range components {
+			total += c
+		}
+		s.Score = round1(total / float64(len(components)))
+	}
+	return s
+}
+
+// BuildAgentLeaderboard ranks agents over the period ending on end, against the period before it
+func BuildAgentLeaderboard(ctx context.Context, period string, end time.Time, minCalls int) (*client.AgentLeaderboard, error) {
+	days, ok := leaderboardPeriodDays[period]
+	if !ok {
+		return nil, fmt.Errorf("%w: invalid period %q (use day, week or month)", ErrInvalidQuery, period)
+	}
+	if minCalls < 1 {
+		minCalls = 1
+	}
+	from := end.AddDate(0, 0, 1-days)
+	prevTo := from.AddDate(0, 0, -1)
+	prevFrom := prevTo.AddDate(0, 0, 1-days)
+
+	commitments, err := storage.LoadCommitments(ctx, storage.CommitmentQuery{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load commitments: %w", err)
+	}
+
+	pitches, err := storage.LoadPitches(ctx, storage.PitchQuery{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load upsell pitches: %w", err)
+	}
+
+	current, unattributed, err := tallyAgents(ctx, from, end, commitments, pitches)
+	if err != nil {
+		return nil, err
+	}
+	previous, _, err := tallyAgents(ctx, prevFrom, prevTo, commitments, pitches)
+	if err != nil {
+		return nil, err
+	}
+
+	board := &client.AgentLeaderboard{
+		Period:            period,
+		From:              from.Format(config.DateLayout),
+		To:                end.Format(config.DateLayout),
+		PreviousFrom:      prevFrom.Format(config.DateLayout),
+		PreviousTo:        prevTo.Format(config.DateLayout),
+		MinCalls:          minCalls,
+		UnattributedCalls: unattributed,
+		GeneratedAt:       time.Now(),
+	}
+	prevStandings := make(map[string]client.AgentStanding)
+	for _, s := range rankAgents(previous, minCalls) {
+		prevStandings[s.AgentID] = s
+	}
+	board.Agents = rankAgents(current, minCalls)
+	for i := range board.Agents {
+		s := &board.Agents[i]
+		if prev, ok := prevStandings[s.AgentID]; ok {
+			rank, score := prev.Rank, prev.Score
+			s.PreviousRank = &rank
+			s.PreviousScore = &score
+			s.Movement = rank - s.Rank
+		}
+	}
+	board.Unranked = len(current) - len(board.Agents)
+	return board, nil
+}
+
+// tallyAgents adds up each agent's calls, commitments and upsell pitches over [from, to], counting the calls naming no agent
+func tallyAgents(ctx context.Context, from, to time.Time, commitments []client.Commitment, pitches []client.TrackedPitch) (map[string]*agentTally, int, error) {
+	tallies := make(map[string]*agentTally)
+	tally := func(agent string) *agentTally {
+		t := tallies[agent]
+		if t == nil {
+			t = &agentTally{}
+			tallies[agent] = t
+		}
+		return t
+	}
+
+	unattributed := 0
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		date := d.Format(config.DateLayout)
+		analyses, err := storage.LoadAnalysesForDate(ctx, date)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to load analyses for %s: %w", date, err)
+		}
+		for _, a := range analyses {
+			if a.Blocked {
+				continue
+			}
+			agent := a.AgentID
+			if agent == "" {
+				if rt, err := storage.LoadRawTranscript(a.CallID); err == nil {
+					agent = rt.AgentID
+				}
+			}
+			if agent == "" {
+				unattributed++
+				continue
+			}
+			t := tally(agent)
+			t.calls++
+			if esc, _ := a.LLMRaw["escalation_required"].(bool); esc {
+				t.escalated++
+			}
+			if p, ok := performancePoints[a.AgentPerformance]; ok {
+				t.perfTotal += p
+				t.perfCalls++
+			}
+			if s := a.Intent.SatisfactionScore; s > 0 {
+				t.satTotal += min(s, 10)
+				t.satCalls++
+			}
+		}
+	}
+
+	first, last := from.Format(config.DateLayout), to.Format(config.DateLayout)
+	for _, c := range commitments {
+		if c.AgentID == "" {
+			continue
+		}
+		if date := config.BusinessDate(c.CallTime); date < first || date > last {
+			continue
+		}
+		switch c.Status {
+		case client.CommitmentKept:
+			tally(c.AgentID).kept++
+		case client.CommitmentBroken:
+			tally(c.AgentID).broken++
+		}
+	}
+	for _, p := range pitches {
+		if p.AgentID == "" {
+			continue
+		}
+		if date := config.BusinessDate(p.CallTime); date < first || date > last {
+			continue
+		}
+		t := tally(p.AgentID)
+		t.pitches++
+		switch p.Status {
+		case client.PitchConverted:
+			t.converted++
+		case client.PitchLapsed:
+			t.lapsed++
+		}
+	}
+	return tallies, unattributed, nil
+}
+
+// rankAgents ranks the agents with at least minCalls calls: best score first,
+// then most calls, then by ID
+func rankAgents(tallies map[string]*agentTally, minCalls int) []client.AgentStanding {
+	standings := make([]client.AgentStanding, 0, len(tallies))
+	for agent, t := range tallies {
+		if t.calls >= minCalls {
+			standings = append(standings, t.standing(agent))
+		}
+	}
+	sort.Slice(standings, func(i, j int) bool {
+		a, b := standings[i], standings[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.Calls != b.Calls {
+			return a.Calls > b.Calls
+		}
+		return a.AgentID < b.AgentID
+	})
+	for i := range standings {
+		standings[i].Rank = i + 1
+	}
+	return standings
+}
+
+func round1(v float64) float64 {
+	return math.Round(v*10) / 10
+}
@@ -0,0 +1,207 @@
+package aggregate
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== ONBOARDING FUNNEL ====================
+// New sellers are those with a call tagged onboarding in the range: from
+// their first 90 days on IndiaMART. The funnel narrows them down to those
+// who raised a setup-related issue, then those with an issue resolved
+// since their first call in the range (from their profile), then those
+// whose latest early call carried low churn risk. Analyses from before the
+// tag aren't counted until replayed.
+
+const (
+	onboardingMaxDays  = 366
+	catalogSetupBucket = "Catalog / Storefront Setup"
+)
+
+// onboardingSeller is what the range says about one new seller
+type onboardingSeller struct {
+	firstCall  time.Time
+	latestCall time.Time
+	latestRisk string
+	setup      bool
+	catalog    bool
+	churn      bool
+	highChurn  bool
+}
+
+// BuildOnboardingFunnel follows the sellers who called in their first 90 days over [from, to]
+func BuildOnboardingFunnel(ctx context.Context, from, to time.Time) (*client.OnboardingFunnel, error) {
+	if to.Before(from) {
+		return nil, fmt.Errorf("%w: to date is before from date", ErrInvalidQuery)
+	}
+	if days := int(to.Sub(from).Hours()/24) + 1; days > onboardingMaxDays {
+		return nil, fmt.Errorf("%w: date range too large (%d days, max %d)", ErrInvalidQuery, days, onboardingMaxDays)
+	}
+
+	report := &client.OnboardingFunnel{
+		From:         from.Format(config.DateLayout),
+		To:           to.Format(config.DateLayout),
+		SetupBuckets: make(map[string]int),
+		ChurnReasons: make(map[string]int),
+		GeneratedAt:  time.Now(),
+	}
+	sellers := make(map[string]*onboardingSeller)
+	bucketSellers := make(map[string]map[string]bool)
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		date := d.Format(config.DateLayout)
+		analyses, err := storage.LoadAnalysesForDate(ctx, date)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load analyses for %s: %w", date, err)
+		}
+		for _, a := range analyses {
+			tag := a.Onboarding
+			if tag == nil || a.Blocked {
+				continue
+			}
+			report.NewSellerCalls++
+			s := sellers[a.SellerID]
+			if s == nil {
+				s = &onboardingSeller{firstCall: a.Timestamp}
+				sellers[a.SellerID] = s
+			}
+			if a.Timestamp.Before(s.firstCall) {
+				s.firstCall = a.Timestamp
+			}
+			if !a.Timestamp.Before(s.latestCall) {
+				s.latestCall, s.latestRisk = a.Timestamp, a.Churn.IsLikelyToChurn
+			}
+
+			if tag.Setup {
+				report.OnboardingCalls++
+				s.setup = true
+			}
+			for _, bucket := range tag.SetupBuckets {
+				if bucketSellers[bucket] == nil {
+					bucketSellers[bucket] = make(map[string]bool)
+				}
+				bucketSellers[bucket][a.SellerID] = true
+				if bucket == catalogSetupBucket {
+					s.catalog = true
+				}
+			}
+			if atChurnRisk(a) {
+				s.churn = true
+				s.highChurn = s.highChurn || a.Churn.IsLikelyToChurn == "high"
+				if category := churnReason(a); category != "" {
+					report.ChurnReasons[category]++
+				}
+			}
+		}
+	}
+	for bucket, ids := range bucketSellers {
+		report.SetupBuckets[bucket] = len(ids)
+	}
+
+	profiles := make(map[string]*client.SellerProfile)
+	if len(sellers) > 0 {
+		all, err := storage.LoadAllSellerProfiles(ctx)
+		if err != nil {
+			log.Printf("⚠️ Onboarding funnel: failed to load seller profiles: %v", err)
+		}
+		for _, p := range all {
+			if _, ok := sellers[p.GluserID]; ok {
+				profiles[p.GluserID] = p
+			}
+		}
+	}
+
+	var setupSellers, resolvedSetup, retained int
+	var resolutionDays []float64
+	for id, s := range sellers {
+		report.NewSellers++
+		if s.catalog {
+			report.CatalogIssueSellers++
+		}
+		if s.churn {
+			report.ChurnSignalSellers++
+		}
+		if s.highChurn {
+			report.HighChurnSellers++
+		}
+
+		resolved, ok := firstResolution(profiles[id], s.firstCall)
+		if ok {
+			report.ResolvedSellers++
+			resolutionDays = append(resolutionDays, resolved.Sub(s.firstCall).Hours()/24)
+		}
+		if s.setup {
+			setupSellers++
+			if ok {
+				resolvedSetup++
+				if s.latestRisk == "low" {
+					retained++
+				}
+			}
+		}
+	}
+
+	report.CatalogIssueRate = share(report.CatalogIssueSellers, report.NewSellers)
+	report.ChurnSignalRate = share(report.ChurnSignalSellers, report.NewSellers)
+	if n := len(resolutionDays); n > 0 {
+		sort.Float64s(resolutionDays)
+		var total float64
+		for _, d := range resolutionDays {
+			total += d
+		}
+		report.AvgDaysToFirstResolution = round1(total / float64(n))
+		median := resolutionDays[n/2]
+		if n%2 == 0 {
+			median = (resolutionDays[n/2-1] + resolutionDays[n/2]) / 2
+		}
+		report.MedianDaysToFirstResolution = round1(median)
+	}
+
+	report.Stages = []client.OnboardingStage{
+		{Stage: "new_sellers", Sellers: report.NewSellers, Rate: 1},
+		{Stage: "setup_issue", Sellers: setupSellers, Rate: share(setupSellers, report.NewSellers)},
+		{Stage: "issue_resolved", Sellers: resolvedSetup, Rate: share(resolvedSetup, setupSellers)},
+		{Stage: "low_churn_risk", Sellers: retained, Rate: share(retained, resolvedSetup)},
+	}
+	return report, nil
+}
+
+// firstResolution is when the seller first had an issue resolved at or
+// after since, reopened issues' earlier resolutions included
+func firstResolution(p *client.SellerProfile, since time.Time) (time.Time, bool) {
+	if p == nil {
+		return time.Time{}, false
+	}
+	var first time.Time
+	consider := func(t time.Time) {
+		if !t.Before(since) && (first.IsZero() || t.Before(first)) {
+			first = t
+		}
+	}
+	for _, issues := range [][]client.TrackedIssue{p.ActiveIssues, p.ResolvedIssues} {
+		for _, issue := range issues {
+			if issue.ResolvedAt != nil {
+				consider(*issue.ResolvedAt)
+			}
+			for _, t := range issue.PreviousResolvedAt {
+				consider(t)
+			}
+		}
+	}
+	return first, !first.IsZero()
+}
+
+// share is n out of total (0-1), 0 without a total
+func share(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(n)/float64(total)*1000) / 1000
+}
@@ -0,0 +1,325 @@
+package aggregate
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== ANALYTICS QUERIES ====================
+// A small structured query language over the analyzed calls, for POST /ask:
+// the LLM translates a question into AnalyticsQuery values, which run here
+// against stored analyses the same way the heatmap does. City, vertical and
+// customer type come from the seller profiles, so sellers without one land
+// in "Unknown". Bucket and severity filter issues: a call matches if any of
+// its issues do, and only matching issues are counted.
+
+const (
+	queryMaxDays      = 92
+	queryDefaultDays  = 7
+	queryDefaultLimit = 20
+	queryMaxLimit     = 100
+)
+
+// QueryMetrics lists the supported metrics and how they are computed
+var QueryMetrics = map[string]string{
+	"calls":                   "Number of analyzed calls",
+	"issues":                  "Number of issues raised",
+	"sellers":                 "Number of distinct sellers who called",
+	"avg_satisfaction":        "Average satisfaction score (1-10) of scored calls",
+	"negative_sentiment_rate": "Share of calls with Negative sentiment (0-1)",
+	"high_churn_rate":         "Share of calls with high churn risk (0-1)",
+	"upsell_rate":             "Share of calls with an upsell opportunity (0-1)",
+}
+
+// QueryGroupBys lists what results can be grouped by
+var QueryGroupBys = map[string]string{
+	"date":              "Business date of the call (YYYY-MM-DD)",
+	"city":              "Seller's city",
+	"vertical":          "Seller's business vertical",
+	"customer_type":     "Seller's customer type (CATALOG, STAR, LEADER, ...)",
+	"bucket":            "Feature bucket of the issues raised",
+	"severity":          "Severity of the issues raised (low, medium, high, critical)",
+	"agent_performance": "Agent performance on the call (Good, Average, Poor)",
+	"sentiment":         "Seller sentiment (Positive, Neutral, Negative)",
+	"churn_risk":        "Churn risk (low, medium, high)",
+	"seller":            "Seller (gluser ID)",
+}
+
+// QuerySchema describes the query language for the LLM translating questions into it
+func QuerySchema() string {
+	var b strings.Builder
+	b.WriteString("METRICS:\n")
+	for _, name := range sortedKeys(QueryMetrics) {
+		fmt.Fprintf(&b, "- %s: %s\n", name, QueryMetrics[name])
+	}
+	b.WriteString("\nGROUP_BY (optional):\n")
+	for _, name := range sortedKeys(QueryGroupBys) {
+		fmt.Fprintf(&b, "- %s: %s\n", name, QueryGroupBys[name])
+	}
+	b.WriteString("\nFILTERS (optional, exact values):\n")
+	fmt.Fprintf(&b, "- bucket: one of %s\n", strings.Join(config.FeatureBuckets, " | "))
+	b.WriteString("- severity: low | medium | high | critical\n")
+	b.WriteString("- sentiment: Positive | Neutral | Negative\n")
+	b.WriteString("- churn_risk: low | medium | high\n")
+	b.WriteString("- city, vertical, customer_type: as the seller profiles spell them\n")
+	fmt.Fprintf(&b, "\nDATES: from and to are YYYY-MM-DD, inclusive, at most %d days apart. Today is %s (%s).\n",
+		queryMaxDays, config.Today(), time.Now().In(config.BusinessTZ).Weekday())
+	fmt.Fprintf(&b, "ORDER: desc (default) or asc by value. LIMIT: rows to return, default %d, max %d.\n", queryDefaultLimit, queryMaxLimit)
+	return b.String()
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// queryGroup accumulates raw counts for one group
+type queryGroup struct {
+	calls        int
+	issues       int
+	sellers      map[string]bool
+	scored       int
+	satisfaction int
+	negative     int
+	highChurn    int
+	upsell       int
+}
+
+func (g *queryGroup) value(metric string) float64 {
+	n := float64(g.calls)
+	var v float64
+	switch metric {
+	case "calls":
+		v = n
+	case "issues":
+		v = float64(g.issues)
+	case "sellers":
+		v = float64(len(g.sellers))
+	case "avg_satisfaction":
+		if g.scored > 0 {
+			v = float64(g.satisfaction) / float64(g.scored)
+		}
+	case "negative_sentiment_rate":
+		v = float64(g.negative) / n
+	case "high_churn_rate":
+		v = float64(g.highChurn) / n
+	case "upsell_rate":
+		v = float64(g.upsell) / n
+	}
+	return math.Round(v*1000) / 1000
+}
+
+// NormalizeQuery fills in defaults and checks q, returning the date range it covers
+func NormalizeQuery(q *client.AnalyticsQuery) (from, to time.Time, err error) {
+	if _, ok := QueryMetrics[q.Metric]; !ok {
+		return from, to, fmt.Errorf("invalid metric %q (use one of %s)", q.Metric, strings.Join(sortedKeys(QueryMetrics), ", "))
+	}
+	if _, ok := QueryGroupBys[q.GroupBy]; q.GroupBy != "" && !ok {
+		return from, to, fmt.Errorf("invalid group_by %q (use one of %s)", q.GroupBy, strings.Join(sortedKeys(QueryGroupBys), ", "))
+	}
+	if q.Bucket != "" {
+		bucket := ""
+		for _, b := range config.FeatureBuckets {
+			if strings.EqualFold(b, q.Bucket) {
+				bucket = b
+			}
+		}
+		if bucket == "" {
+			return from, to, fmt.Errorf("unknown bucket %q", q.Bucket)
+		}
+		q.Bucket = bucket
+	}
+	switch q.Order = strings.ToLower(q.Order); q.Order {
+	case "":
+		q.Order = "desc"
+	case "asc", "desc":
+	default:
+		return from, to, fmt.Errorf("invalid order %q (use asc or desc)", q.Order)
+	}
+	if q.Limit <= 0 {
+		q.Limit = queryDefaultLimit
+	}
+	q.Limit = min(q.Limit, queryMaxLimit)
+
+	to = time.Now().In(config.BusinessTZ)
+	from = to.AddDate(0, 0, 1-queryDefaultDays)
+	if q.From != "" {
+		if from, err = config.ParseBusinessDate(q.From); err != nil {
+			return from, to, fmt.Errorf("invalid from date %q (use YYYY-MM-DD)", q.From)
+		}
+	}
+	if q.To != "" {
+		if to, err = config.ParseBusinessDate(q.To); err != nil {
+			return from, to, fmt.Errorf("invalid to date %q (use YYYY-MM-DD)", q.To)
+		}
+	}
+	from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	to = time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	if to.Before(from) {
+		return from, to, fmt.Errorf("to date is before from date")
+	}
+	if days := int(to.Sub(from).Hours()/24) + 1; days > queryMaxDays {
+		return from, to, fmt.Errorf("date range too large (%d days, max %d)", days, queryMaxDays)
+	}
+	q.From, q.To = from.Format(config.DateLayout), to.Format(config.DateLayout)
+	return from, to, nil
+}
+
+// RunQuery runs q against the analyses in its date range
+func RunQuery(ctx context.Context, q client.AnalyticsQuery) (*client.QueryResult, error) {
+	from, to, err := NormalizeQuery(&q)
+	if err != nil {
+		return nil, err
+	}
+
+	var profiles []*client.SellerProfile
+	if q.City != "" || q.Vertical != "" || q.CustomerType != "" ||
+		q.GroupBy == "city" || q.GroupBy == "vertical" || q.GroupBy == "customer_type" {
+		if profiles, err = storage.LoadAllSellerProfiles(ctx); err != nil {
+			log.Printf("⚠️ Analytics query: failed to load seller profiles: %v", err)
+		}
+	}
+	sellers := make(map[string]*client.SellerProfile, len(profiles))
+	for _, p := range profiles {
+		sellers[p.GluserID] = p
+	}
+	sellerField := func(sellerID, field string) string {
+		p := sellers[sellerID]
+		v := ""
+		if p != nil {
+			switch field {
+			case "city":
+				v = p.CityName
+			case "vertical":
+				v = p.Vertical
+			case "customer_type":
+				v = p.CustomerType
+			}
+		}
+		return orUnknown(v)
+	}
+	matches := func(want, got string) bool {
+		return want == "" || strings.EqualFold(want, got)
+	}
+
+	result := &client.QueryResult{Query: q, Rows: []client.QueryRow{}}
+	groups := make(map[string]*queryGroup)
+	group := func(key string) *queryGroup {
+		g := groups[key]
+		if g == nil {
+			g = &queryGroup{sellers: make(map[string]bool)}
+			groups[key] = g
+		}
+		return g
+	}
+
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		date := d.Format(config.DateLayout)
+		analyses, err := storage.LoadAnalysesForDate(ctx, date)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load analyses for %s: %w", date, err)
+		}
+		for _, a := range analyses {
+			if a.Blocked ||
+				!matches(q.Sentiment, a.Intent.Sentiment) || !matches(q.ChurnRisk, a.Churn.IsLikelyToChurn) ||
+				!matches(q.City, sellerField(a.SellerID, "city")) ||
+				!matches(q.Vertical, sellerField(a.SellerID, "vertical")) ||
+				!matches(q.CustomerType, sellerField(a.SellerID, "customer_type")) {
+				continue
+			}
+			var issues []client.Issue
+			for _, issue := range a.Issues {
+				if matches(q.Bucket, issue.Bucket) && matches(q.Severity, issue.Severity) {
+					issues = append(issues, issue)
+				}
+			}
+			if (q.Bucket != "" || q.Severity != "") && len(issues) == 0 {
+				continue
+			}
+			result.Calls++
+
+			// Issue counts per group; grouping by an issue field puts the
+			// call in each group its issues fall in
+			keys := make(map[string]int)
+			switch q.GroupBy {
+			case "bucket", "severity":
+				for _, issue := range issues {
+					key := issue.Bucket
+					if q.GroupBy == "severity" {
+						key = issue.Severity
+					}
+					keys[orUnknown(key)]++
+				}
+			case "":
+				keys["all"] = len(issues)
+			case "date":
+				keys[date] = len(issues)
+			case "city", "vertical", "customer_type":
+				keys[sellerField(a.SellerID, q.GroupBy)] = len(issues)
+			case "agent_performance":
+				keys[orUnknown(a.AgentPerformance)] = len(issues)
+			case "sentiment":
+				keys[orUnknown(a.Intent.Sentiment)] = len(issues)
+			case "churn_risk":
+				keys[orUnknown(a.Churn.IsLikelyToChurn)] = len(issues)
+			case "seller":
+				keys[orUnknown(a.SellerID)] = len(issues)
+			}
+
+			for key, n := range keys {
+				g := group(key)
+				g.calls++
+				g.issues += n
+				g.sellers[a.SellerID] = true
+				if score := a.Intent.SatisfactionScore; score > 0 {
+					g.scored++
+					g.satisfaction += score
+				}
+				if a.Intent.Sentiment == "Negative" {
+					g.negative++
+				}
+				if a.Churn.IsLikelyToChurn == "high" {
+					g.highChurn++
+				}
+				if a.Upsell.HasOpportunity {
+					g.upsell++
+				}
+			}
+		}
+	}
+
+	for key, g := range groups {
+		result.Rows = append(result.Rows, client.QueryRow{Group: key, Value: g.value(q.Metric), Calls: g.calls})
+	}
+	sort.Slice(result.Rows, func(i, j int) bool {
+		ri, rj := result.Rows[i], result.Rows[j]
+		if ri.Value != rj.Value {
+			return (ri.Value > rj.Value) == (q.Order == "desc")
+		}
+		return ri.Group < rj.Group
+	})
+	if len(result.Rows) > q.Limit {
+		result.Rows = result.Rows[:q.Limit]
+	}
+	return result, nil
+}
+
+func orUnknown(v string) string {
+	if v == "" {
+		return "Unknown"
+	}
+	return v
+}
@@ -0,0 +1,122 @@
+package aggregate
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+)
+
+// ==================== SHIFT AGGREGATION ====================
+// Support teams review their own shift's calls, so besides the daily
+// rollup each business day can be split into shifts with aggregates of
+// their own. AGGREGATION_SHIFTS names each shift and the local time it
+// starts; a shift runs until the next one starts:
+//
+//	AGGREGATION_SHIFTS="morning=06:00,evening=14:00,night=22:00"
+//
+// A shift belongs to the day it starts on, so the night shift above runs
+// until 06:00 the next day, and calls before 06:00 count towards the
+// previous day's night shift. Unset, there are no shift aggregates.
+
+// Shift is a named sub-daily aggregation window
+type Shift struct {
+	Name  string
+	Start time.Duration // Since local midnight
+}
+
+// ShiftWindow is one shift on one business day
+type ShiftWindow struct {
+	Date  string // Business day the shift starts on
+	Shift string
+	Start time.Time
+	End   time.Time
+}
+
+// Shifts are the configured shifts, in the order they start each day
+var Shifts = loadShifts()
+
+var shiftNamePattern = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+// loadShifts parses AGGREGATION_SHIFTS, skipping malformed entries
+func loadShifts() []Shift {
+	v := os.Getenv("AGGREGATION_SHIFTS")
+	if v == "" {
+		return nil
+	}
+	var shifts []Shift
+	seen := make(map[string]bool)
+	starts := make(map[time.Duration]bool)
+	for _, pair := range strings.Split(v, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		name = strings.ToLower(strings.TrimSpace(name))
+		start, err := time.Parse("15:04", strings.TrimSpace(value))
+		offset := time.Duration(start.Hour())*time.Hour + time.Duration(start.Minute())*time.Minute
+		if !ok || !shiftNamePattern.MatchString(name) || err != nil || seen[name] || starts[offset] {
+			log.Printf("⚠️ Ignoring invalid AGGREGATION_SHIFTS entry %q", pair)
+			continue
+		}
+		seen[name], starts[offset] = true, true
+		shifts = append(shifts, Shift{Name: name, Start: offset})
+	}
+	sort.Slice(shifts, func(i, j int) bool { return shifts[i].Start < shifts[j].Start })
+	for _, s := range shifts {
+		log.Printf("🕐 Shift aggregation: %s from %02d:%02d", s.Name, int(s.Start.Hours()), int(s.Start.Minutes())%60)
+	}
+	return shifts
+}
+
+// ShiftWindows returns the windows of the shifts starting on date
+func ShiftWindows(date string) ([]ShiftWindow, error) {
+	day, err := config.ParseBusinessDate(date)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date %q: %w", date, err)
+	}
+	at := func(days int, offset time.Duration) time.Time {
+		// time.Date rather than Add, so DST changes don't shift the wall clock
+		return time.Date(day.Year(), day.Month(), day.Day()+days, int(offset.Hours()), int(offset.Minutes())%60, 0, 0, config.BusinessTZ)
+	}
+
+	windows := make([]ShiftWindow, len(Shifts))
+	for i, s := range Shifts {
+		end := at(1, Shifts[0].Start)
+		if i+1 < len(Shifts) {
+			end = at(0, Shifts[i+1].Start)
+		}
+		windows[i] = ShiftWindow{Date: date, Shift: s.Name, Start: at(0, s.Start), End: end}
+	}
+	return windows, nil
+}
+
+// ShiftByName returns the configured shift with name
+func ShiftByName(name string) (Shift, bool) {
+	for _, s := range Shifts {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return Shift{}, false
+}
+
+// BuildShift creates the aggregate of a shift window from analyses, using
+// only those whose call falls inside it
+func BuildShift(w ShiftWindow, analyses []client.AnalysisResult) *client.DailyAggregate {
+	var inWindow []client.AnalysisResult
+	for _, a := range analyses {
+		if !a.Timestamp.Before(w.Start) && a.Timestamp.Before(w.End) {
+			inWindow = append(inWindow, a)
+		}
+	}
+	agg := Build(w.Date, inWindow)
+	start, end := w.Start, w.End
+	agg.Shift = w.Shift
+	agg.WindowStart = &start
+	agg.WindowEnd = &end
+	return agg
+}
@@ -0,0 +1,182 @@
+package aggregate
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"im-ai-voice/client"
+)
+
+// ==================== SYSTEMIC ISSUES ====================
+// Open issues from all sellers are clustered by the similarity of their
+// embeddings, so the same problem described differently by each seller
+// ("TrustSEAL badge missing", "badge gone after renewing") lands in one
+// cluster. Clusters spanning enough sellers become systemic issues.
+
+// IssueEmbedding is an open issue and the embedding of its text
+type IssueEmbedding struct {
+	Issue  client.SellerIssue
+	Vector []float64
+}
+
+type vectorCluster struct {
+	sum      []float64 // Sum of the members' unit vectors
+	centroid []float64 // sum as a unit vector
+	members  []int
+}
+
+// clusterVectors assigns unit vectors, in order, to the cluster whose
+// centroid they're most similar to, at least threshold, or to a new one
+func clusterVectors(vectors [][]float64, threshold float64) []*vectorCluster {
+	var clusters []*vectorCluster
+	for i, v := range vectors {
+		var best *vectorCluster
+		bestSim := threshold
+		for _, c := range clusters {
+			if len(c.centroid) != len(v) {
+				continue
+			}
+			if sim := dot(c.centroid, v); sim >= bestSim {
+				best, bestSim = c, sim
+			}
+		}
+		if best == nil {
+			best = &vectorCluster{sum: make([]float64, len(v))}
+			clusters = append(clusters, best)
+		}
+		for k, x := range v {
+			best.sum[k] += x
+		}
+		best.centroid = normalize(best.sum)
+		best.members = append(best.members, i)
+	}
+	return clusters
+}
+
+// ClusterIssues groups issues whose embeddings have a cosine similarity of
+// at least threshold to a cluster's centroid, and returns the clusters with
+// issues from minSellers or more sellers, most sellers first. Issues are
+// assigned oldest first, so the same issues cluster the same way each run.
+func ClusterIssues(items []IssueEmbedding, threshold float64, minSellers int) []client.SystemicIssue {
+	sorted := make([]IssueEmbedding, 0, len(items))
+	for _, it := range items {
+		if unit := normalize(it.Vector); unit != nil {
+			sorted = append(sorted, IssueEmbedding{Issue: it.Issue, Vector: unit})
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Issue.IssueID < sorted[j].Issue.IssueID })
+
+	vectors := make([][]float64, len(sorted))
+	for i, it := range sorted {
+		vectors[i] = it.Vector
+	}
+
+	now := time.Now()
+	var systemic []client.SystemicIssue
+	for _, c := range clusterVectors(vectors, threshold) {
+		if s, ok := systemicIssue(sorted, c, minSellers, now); ok {
+			systemic = append(systemic, s)
+		}
+	}
+	sort.Slice(systemic, func(i, j int) bool {
+		if systemic[i].SellerCount != systemic[j].SellerCount {
+			return systemic[i].SellerCount > systemic[j].SellerCount
+		}
+		return systemic[i].IssueCount > systemic[j].IssueCount
+	})
+	return systemic
+}
+
+// systemicIssue summarizes a cluster, or reports false when it spans fewer than minSellers sellers
+func systemicIssue(items []IssueEmbedding, c *vectorCluster, minSellers int, now time.Time) (client.SystemicIssue, bool) {
+	sellers := make(map[string]bool)
+	for _, m := range c.members {
+		sellers[items[m].Issue.GluserID] = true
+	}
+	if len(sellers) < minSellers {
+		return client.SystemicIssue{}, false
+	}
+
+	oldest := items[c.members[0]].Issue // Members are in issue_id order
+	s := client.SystemicIssue{
+		ID:              "sys_" + oldest.IssueID,
+		SellerCount:     len(sellers),
+		IssueCount:      len(c.members),
+		FirstReportedAt: oldest.FirstReportedAt,
+		GeneratedAt:     now,
+	}
+	buckets := make(map[string]int)
+	bestSim := -1.0
+	for _, m := range c.members {
+		issue := items[m].Issue
+		sim := dot(c.centroid, items[m].Vector)
+		s.Members = append(s.Members, client.SystemicMember{
+			GluserID:   issue.GluserID,
+			IssueID:    issue.IssueID,
+			Problem:    issue.Problem,
+			Severity:   issue.Severity,
+			Status:     issue.Status,
+			Similarity: math.Round(sim*1000) / 1000,
+		})
+		if sim > bestSim { // The member closest to the centroid names the cluster
+			s.Problem, bestSim = issue.Problem, sim
+		}
+		buckets[issue.Bucket]++
+		if severityRank(issue.Severity) > severityRank(s.Severity) {
+			s.Severity = issue.Severity
+		}
+		s.MentionCount += issue.MentionCount
+		if issue.FirstReportedAt.Before(s.FirstReportedAt) {
+			s.FirstReportedAt = issue.FirstReportedAt
+		}
+		if issue.LastMentionedAt.After(s.LastMentionedAt) {
+			s.LastMentionedAt = issue.LastMentionedAt
+		}
+	}
+	for b, n := range buckets {
+		if n > buckets[s.Bucket] || (n == buckets[s.Bucket] && b < s.Bucket) {
+			s.Bucket = b
+		}
+	}
+	sort.Slice(s.Members, func(i, j int) bool { return s.Members[i].Similarity > s.Members[j].Similarity })
+	s.Title = fmt.Sprintf("%s — %d sellers", s.Problem, s.SellerCount)
+	return s, true
+}
+
+func severityRank(sev string) int {
+	switch sev {
+	case "critical":
+		return 4
+	case "high":
+		return 3
+	case "medium":
+		return 2
+	case "low":
+		return 1
+	default:
+		return 0
+	}
+}
+
+// normalize returns v scaled to unit length, or nil for an empty or zero vector
+func normalize(v []float64) []float64 {
+	norm := math.Sqrt(dot(v, v))
+	if norm == 0 {
+		return nil
+	}
+	out := make([]float64, len(v))
+	for i, x := range v {
+		out[i] = x / norm
+	}
+	return out
+}
+
+func dot(a, b []float64) float64 {
+	sum := 0.0
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
@@ -0,0 +1,133 @@
+package aggregate
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"im-ai-voice/client"
+)
+
+// ==================== KEY INSIGHT THEMES ====================
+// Each analysis ends with a few key insights. Across a week's calls the same
+// point comes up in different words ("seller unsure why lead price went up",
+// "confused by new BuyLead pricing"), so insights are clustered by embedding
+// like systemic issues, and clusters supported by enough calls become themes.
+
+// Per-theme caps on what's kept of its members
+const (
+	themeExamples = 3
+	themeCallIDs  = 20
+)
+
+// InsightEmbedding is one key insight of a call and the embedding of its text
+type InsightEmbedding struct {
+	CallID    string
+	SellerID  string
+	Timestamp time.Time
+	Text      string
+	Vector    []float64
+}
+
+// ClusterInsights groups insights whose embeddings have a cosine similarity
+// of at least threshold to a cluster's centroid, and returns up to max
+// clusters with insights from minCalls or more calls, most calls first.
+// Insights are assigned in call order, so the same week clusters the same
+// way each run.
+func ClusterInsights(items []InsightEmbedding, threshold float64, minCalls, max int) []client.InsightTheme {
+	sorted := make([]InsightEmbedding, 0, len(items))
+	for _, it := range items {
+		if unit := normalize(it.Vector); unit != nil {
+			it.Vector = unit
+			sorted = append(sorted, it)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].CallID != sorted[j].CallID {
+			return sorted[i].CallID < sorted[j].CallID
+		}
+		return sorted[i].Text < sorted[j].Text
+	})
+
+	vectors := make([][]float64, len(sorted))
+	for i, it := range sorted {
+		vectors[i] = it.Vector
+	}
+
+	themes := []client.InsightTheme{}
+	for _, c := range clusterVectors(vectors, threshold) {
+		if t, ok := insightTheme(sorted, c, minCalls); ok {
+			themes = append(themes, t)
+		}
+	}
+	sort.SliceStable(themes, func(i, j int) bool {
+		if themes[i].Calls != themes[j].Calls {
+			return themes[i].Calls > themes[j].Calls
+		}
+		return themes[i].Sellers > themes[j].Sellers
+	})
+	if max > 0 && len(themes) > max {
+		themes = themes[:max]
+	}
+	return themes
+}
+
+// insightTheme summarizes a cluster, or reports false when it spans fewer than minCalls calls
+func insightTheme(items []InsightEmbedding, c *vectorCluster, minCalls int) (client.InsightTheme, bool) {
+	calls := make(map[string]time.Time)
+	sellers := make(map[string]bool)
+	for _, m := range c.members {
+		it := items[m]
+		if ts, ok := calls[it.CallID]; !ok || it.Timestamp.After(ts) {
+			calls[it.CallID] = it.Timestamp
+		}
+		if it.SellerID != "" {
+			sellers[it.SellerID] = true
+		}
+	}
+	if len(calls) < minCalls {
+		return client.InsightTheme{}, false
+	}
+
+	t := client.InsightTheme{
+		Calls:    len(calls),
+		Sellers:  len(sellers),
+		Insights: len(c.members),
+	}
+
+	// The member closest to the centroid names the theme, the next closest
+	// distinct phrasings are its examples
+	members := append([]int(nil), c.members...)
+	sort.SliceStable(members, func(i, j int) bool {
+		return dot(c.centroid, items[members[i]].Vector) > dot(c.centroid, items[members[j]].Vector)
+	})
+	seen := make(map[string]bool)
+	for _, m := range members {
+		text := strings.TrimSpace(items[m].Text)
+		key := strings.ToLower(text)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if t.Theme == "" {
+			t.Theme = text
+		} else if len(t.Examples) < themeExamples {
+			t.Examples = append(t.Examples, text)
+		}
+	}
+
+	for id := range calls {
+		t.CallIDs = append(t.CallIDs, id)
+	}
+	sort.Slice(t.CallIDs, func(i, j int) bool {
+		a, b := calls[t.CallIDs[i]], calls[t.CallIDs[j]]
+		if !a.Equal(b) {
+			return a.After(b)
+		}
+		return t.CallIDs[i] < t.CallIDs[j]
+	})
+	if len(t.CallIDs) > themeCallIDs {
+		t.CallIDs = t.CallIDs[:themeCallIDs]
+	}
+	return t, true
+}
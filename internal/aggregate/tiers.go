@@ -0,0 +1,109 @@
+package aggregate
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"im-ai-voice/client"
+)
+
+// ==================== CUSTOMER TIERS ====================
+// A date's calls split by the seller's customer tier, so leadership can see
+// whether premium sellers run into more or fewer problems than free ones.
+// The tier comes from the customer_type on the seller's profile, as the
+// analyses don't carry it: LEADER and STAR as they are, any catalog type
+// (CATALOG, TSCATALOG, catalog defaulters) as CATALOG, and free listings and
+// free catalog pages (FREELIST, FCP variants) as FREE.
+
+// tierOrder is the order tiers are reported in, free to premium
+var tierOrder = []string{client.TierFree, client.TierCatalog, client.TierStar, client.TierLeader, client.TierOther, client.TierUnknown}
+
+// CustomerTier maps a profile's customer_type onto its tier
+func CustomerTier(customerType string) string {
+	t := strings.ToUpper(strings.TrimSpace(customerType))
+	switch {
+	case t == "":
+		return client.TierUnknown
+	case t == client.TierLeader, t == client.TierStar:
+		return t
+	case strings.Contains(t, "CATALOG"):
+		return client.TierCatalog
+	case strings.Contains(t, "NOFCP"):
+		return client.TierOther
+	case strings.HasPrefix(t, "FREE"), strings.Contains(t, "FCP"):
+		return client.TierFree
+	}
+	return client.TierOther
+}
+
+// paidRank orders the tiers a seller can upgrade through
+var paidRank = map[string]int{client.TierFree: 1, client.TierCatalog: 2, client.TierStar: 3, client.TierLeader: 4}
+
+// TierUpgrade reports whether moving from one customer_type to another is
+// an upgrade, to a higher tier of free, catalog, Star and Leader
+func TierUpgrade(from, to string) bool {
+	f, t := paidRank[CustomerTier(from)], paidRank[CustomerTier(to)]
+	return f > 0 && t > f
+}
+
+// BuildByTier aggregates a date's analyses per customer tier, with
+// customerTypes the customer_type of each seller
+func BuildByTier(date string, analyses []client.AnalysisResult, customerTypes map[string]string) *client.TierAggregates {
+	byTier := make(map[string][]client.AnalysisResult)
+	rawTypes := make(map[string]map[string]int)
+	for _, a := range analyses {
+		customerType := strings.TrimSpace(customerTypes[a.SellerID])
+		tier := CustomerTier(customerType)
+		byTier[tier] = append(byTier[tier], a)
+		if customerType != "" {
+			if rawTypes[tier] == nil {
+				rawTypes[tier] = make(map[string]int)
+			}
+			rawTypes[tier][customerType]++
+		}
+	}
+
+	out := &client.TierAggregates{Date: date, Tiers: []client.TierAggregate{}, GeneratedAt: time.Now()}
+	for _, tier := range tierOrder {
+		calls := byTier[tier]
+		if len(calls) == 0 {
+			continue
+		}
+		ta := client.TierAggregate{
+			Tier:          tier,
+			CustomerTypes: rawTypes[tier],
+			Aggregate:     *Build(date, calls),
+		}
+
+		sellers := make(map[string]bool)
+		var analyzed, issues, negative, highChurn int
+		for _, a := range calls {
+			sellers[a.SellerID] = true
+			if a.Blocked {
+				continue
+			}
+			analyzed++
+			issues += len(a.Issues)
+			if a.Intent.Sentiment == "Negative" {
+				negative++
+			}
+			if a.Churn.IsLikelyToChurn == "high" {
+				highChurn++
+			}
+		}
+		ta.Sellers = len(sellers)
+		if analyzed > 0 {
+			n := float64(analyzed)
+			ta.IssuesPerCall = round3(float64(issues) / n)
+			ta.NegativeRate = round3(float64(negative) / n)
+			ta.HighChurnRate = round3(float64(highChurn) / n)
+		}
+		out.Tiers = append(out.Tiers, ta)
+	}
+	return out
+}
+
+func round3(v float64) float64 {
+	return math.Round(v*1000) / 1000
+}
@@ -0,0 +1,154 @@
+package aggregate
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== UPSELL PIPELINE ====================
+// Upsell opportunities grouped by the product their interested features map
+// to (config.MatchProducts). A seller counts once per product however many
+// calls mention it, valued at the product's annual price; the weighted value
+// scales that by the seller's best upsell score out of 10.
+
+const (
+	upsellPipelineMaxDays = 92
+	upsellTopSellers      = 20
+	upsellUnmappedLimit   = 20
+)
+
+// upsellSKUs returns the products a call's upsell interest maps to. Analyses
+// from before the mapping only have the free-text features.
+func upsellSKUs(a client.AnalysisResult) []string {
+	if len(a.Upsell.SKUs) > 0 {
+		return a.Upsell.SKUs
+	}
+	return config.MatchProducts(a.Upsell.InterestedFeatures)
+}
+
+// BuildUpsellPipeline groups upsell opportunities by SKU over [from, to],
+// of sellers in stage (all for "")
+func BuildUpsellPipeline(ctx context.Context, from, to time.Time, stage string) (*client.UpsellPipeline, error) {
+	if to.Before(from) {
+		return nil, fmt.Errorf("%w: to date is before from date", ErrInvalidQuery)
+	}
+	if days := int(to.Sub(from).Hours()/24) + 1; days > upsellPipelineMaxDays {
+		return nil, fmt.Errorf("%w: date range too large (%d days, max %d)", ErrInvalidQuery, days, upsellPipelineMaxDays)
+	}
+
+	leads := make(map[string]map[string]*client.UpsellLead) // SKU -> seller -> best lead
+	opportunities := make(map[string]int)
+	unmapped := make(map[string]int)
+	sellers := make(map[string]bool)
+	pipeline := &client.UpsellPipeline{
+		From:        from.Format(config.DateLayout),
+		To:          to.Format(config.DateLayout),
+		SKUs:        []client.UpsellSKU{},
+		Unmapped:    []client.UnmappedFeature{},
+		GeneratedAt: time.Now(),
+	}
+
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		date := d.Format(config.DateLayout)
+		analyses, err := storage.LoadAnalysesForDate(ctx, date)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load analyses for %s: %w", date, err)
+		}
+
+		for _, a := range analyses {
+			if !a.Upsell.HasOpportunity || !inStage(a, stage) {
+				continue
+			}
+			pipeline.Opportunities++
+			sellers[a.SellerID] = true
+
+			for _, f := range a.Upsell.InterestedFeatures {
+				if config.MatchProduct(f) == "" {
+					unmapped[strings.ToLower(strings.TrimSpace(f))]++
+				}
+			}
+			for _, sku := range upsellSKUs(a) {
+				opportunities[sku]++
+				if leads[sku] == nil {
+					leads[sku] = make(map[string]*client.UpsellLead)
+				}
+				lead := leads[sku][a.SellerID]
+				if lead == nil {
+					lead = &client.UpsellLead{GluserID: a.SellerID}
+					leads[sku][a.SellerID] = lead
+				}
+				if a.Upsell.Score >= lead.Score {
+					lead.Score = a.Upsell.Score
+					lead.Willingness = a.Upsell.WillingnessToInvest
+				}
+				if a.Timestamp.After(lead.LastCallAt) {
+					lead.LastCallAt = a.Timestamp
+				}
+			}
+		}
+	}
+	pipeline.Sellers = len(sellers)
+
+	for _, p := range config.Products {
+		s := client.UpsellSKU{
+			SKU:           p.SKU,
+			Name:          p.Name,
+			DealValue:     p.AnnualPrice,
+			Opportunities: opportunities[p.SKU],
+			Sellers:       len(leads[p.SKU]),
+		}
+		weighted := 0.0
+		for _, lead := range leads[p.SKU] {
+			weighted += float64(p.AnnualPrice) * float64(min(max(lead.Score, 0), 10)) / 10
+			s.TopSellers = append(s.TopSellers, *lead)
+		}
+		s.PipelineValue = s.Sellers * p.AnnualPrice
+		s.WeightedValue = int(weighted)
+
+		sort.Slice(s.TopSellers, func(i, j int) bool {
+			a, b := s.TopSellers[i], s.TopSellers[j]
+			if a.Score != b.Score {
+				return a.Score > b.Score
+			}
+			return a.LastCallAt.After(b.LastCallAt)
+		})
+		if len(s.TopSellers) > upsellTopSellers {
+			s.TopSellers = s.TopSellers[:upsellTopSellers]
+		}
+
+		pipeline.PipelineValue += s.PipelineValue
+		pipeline.WeightedValue += s.WeightedValue
+		pipeline.SKUs = append(pipeline.SKUs, s)
+	}
+	sort.SliceStable(pipeline.SKUs, func(i, j int) bool {
+		a, b := pipeline.SKUs[i], pipeline.SKUs[j]
+		if a.WeightedValue != b.WeightedValue {
+			return a.WeightedValue > b.WeightedValue
+		}
+		return a.Sellers > b.Sellers
+	})
+
+	for f, n := range unmapped {
+		if f != "" {
+			pipeline.Unmapped = append(pipeline.Unmapped, client.UnmappedFeature{Feature: f, Count: n})
+		}
+	}
+	sort.Slice(pipeline.Unmapped, func(i, j int) bool {
+		a, b := pipeline.Unmapped[i], pipeline.Unmapped[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Feature < b.Feature
+	})
+	if len(pipeline.Unmapped) > upsellUnmappedLimit {
+		pipeline.Unmapped = pipeline.Unmapped[:upsellUnmappedLimit]
+	}
+	return pipeline, nil
+}
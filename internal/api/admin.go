@@ -0,0 +1,1034 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/chaos"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/leader"
+	"im-ai-voice/internal/scheduler"
+	"im-ai-voice/internal/service"
+)
+
+// ==================== CAPABILITIES ====================
+
+// GET /capabilities - What this deployment has turned on: storage, providers, webhooks, prompt and bucket taxonomy versions, flags
+func (r *Router) handleCapabilities(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	caps, err := r.service.Capabilities(req.Context())
+	if err != nil {
+		serverError(w, err)
+		return
+	}
+	jsonResponse(w, caps)
+}
+
+// POST /admin/selftest?llm=gemini - Run synthetic calls through ingest, analysis, profile, aggregate and ticket, reporting each stage
+func (r *Router) handleSelfTest(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var gemini bool
+	switch llm := req.URL.Query().Get("llm"); llm {
+	case "", client.EngineRules:
+	case "gemini":
+		gemini = true
+	default:
+		jsonError(w, "llm must be rules or gemini", http.StatusBadRequest)
+		return
+	}
+
+	report, err := r.service.SelfTest(req.Context(), gemini)
+	switch {
+	case errors.Is(err, service.ErrInvalidSelfTest):
+		jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, service.ErrSelfTestRunning):
+		jsonError(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		serverError(w, err)
+		return
+	}
+	jsonResponse(w, report)
+}
+
+// ==================== ADMIN ====================
+
+// GET /admin/watcher - Watcher aggregation policy and pending per-date counters
+func (r *Router) handleWatcherStatus(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	jsonResponse(w, r.watcher.Status())
+}
+
+// GET /admin/pipeline/stats - Transcript backlog, processing time, LLM error and JSON repair rates and projected catch-up
+func (r *Router) handlePipelineStats(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	stats := r.watcher.Stats()
+	stats.LLMRequests, stats.LLMErrors = r.service.LLMStats()
+	if stats.LLMRequests > 0 {
+		stats.LLMErrorRate = math.Round(float64(stats.LLMErrors)/float64(stats.LLMRequests)*1000) / 1000
+	}
+	stats.LLMParsing = r.service.LLMParseStats()
+	stats.FaultsInjected = chaos.Injected()
+	jsonResponse(w, stats)
+}
+
+// GET /admin/data-quality?from=&to= - Quality of the transcripts received per source (default last 7 days)
+func (r *Router) handleDataQuality(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	from, to, ok := dateRange(w, req.URL.Query(), 7)
+	if !ok {
+		return
+	}
+
+	report, err := r.service.DataQualityReport(req.Context(), from, to)
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	jsonResponse(w, report)
+}
+
+// GET /admin/leader - This instance's leader election role
+func (r *Router) handleLeaderStatus(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	jsonResponse(w, leader.Status())
+}
+
+// GET /admin/keys/{id}/usage?month=YYYY-MM - An API key's usage in a month against its quota (defaults to this month; scope: admin)
+func (r *Router) handleKeyUsage(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	month := req.URL.Query().Get("month")
+	if month == "" {
+		month, _ = usageMonth(time.Now())
+	} else if _, err := time.Parse("2006-01", month); err != nil {
+		jsonError(w, "Invalid month (use YYYY-MM)", http.StatusBadRequest)
+		return
+	}
+
+	name := req.PathValue("id")
+	usage, err := keyUsageReport(req.Context(), name, month)
+	if err != nil {
+		serverError(w, err)
+		return
+	}
+	if usage == nil {
+		jsonError(w, "Unknown API key", http.StatusNotFound)
+		return
+	}
+
+	if err := auditAllowed(req, client.AuditKeyUsageRead, name); err != nil {
+		log.Printf("⚠️ Refusing usage of API key %s, audit log unavailable: %v", name, err)
+		jsonError(w, "Audit log unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	jsonResponse(w, usage)
+}
+
+// GET /jobs?type=&status=&limit= - Long-running operations, newest first
+func (r *Router) handleJobs(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := req.URL.Query()
+	jobs, err := r.service.ListJobs(req.Context(), q.Get("type"), q.Get("status"), q.Get("limit"))
+	switch {
+	case errors.Is(err, service.ErrInvalidJobQuery):
+		jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		serverError(w, err)
+		return
+	}
+
+	jsonResponse(w, client.JobList{Jobs: jobs, Count: len(jobs)})
+}
+
+// GET /jobs/{id} - A long-running operation's state, progress, timing and result link
+func (r *Router) handleJob(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	job, err := r.service.GetJob(req.Context(), req.PathValue("id"))
+	switch {
+	case errors.Is(err, service.ErrJobNotFound):
+		jsonError(w, err.Error(), http.StatusNotFound)
+		return
+	case err != nil:
+		serverError(w, err)
+		return
+	}
+
+	jsonResponse(w, job)
+}
+
+// GET /admin/jobs/schedule - Background jobs with their schedules, next and last runs
+func (r *Router) handleJobSchedule(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	jsonResponse(w, r.jobs.Status())
+}
+
+// POST /admin/jobs/{name}/run - Run a job now, in the background
+func (r *Router) handleRunJob(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := req.PathValue("name")
+	if !auditAdminChange(w, req, client.AuditJobRun, name, "") {
+		return
+	}
+	err := r.jobs.Trigger(name)
+	switch {
+	case errors.Is(err, scheduler.ErrUnknownJob):
+		jsonError(w, "Unknown job: "+name, http.StatusNotFound)
+		return
+	case errors.Is(err, scheduler.ErrJobRunning):
+		jsonError(w, "Job "+name+" is already running", http.StatusConflict)
+		return
+	case err != nil:
+		serverError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	jsonResponse(w, map[string]any{
+		"job":     name,
+		"started": true,
+	})
+}
+
+// GET /admin/ticket-suppressions?expired=true - Ticket mute rules, oldest first
+// POST /admin/ticket-suppressions - Add a rule muting a bucket and/or problems matching a pattern
+func (r *Router) handleTicketSuppressions(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+		rules, err := r.service.ListTicketSuppressions(req.Context(), req.URL.Query().Get("expired") == "true")
+		if err != nil {
+			serverError(w, err)
+			return
+		}
+		jsonResponse(w, map[string]any{
+			"suppressions": rules,
+			"count":        len(rules),
+		})
+
+	case http.MethodPost:
+		var body client.TicketSuppressionRequest
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			jsonError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if body.By == "" {
+			if key := lookupAPIKey(req); key != nil {
+				body.By = key.name
+			}
+		}
+		rule, err := r.service.CreateTicketSuppression(req.Context(), body)
+		switch {
+		case errors.Is(err, service.ErrInvalidSuppression):
+			jsonError(w, err.Error(), http.StatusBadRequest)
+			return
+		case err != nil:
+			serverError(w, err)
+			return
+		}
+		jsonResponse(w, rule)
+
+	default:
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// DELETE /admin/ticket-suppressions/{id} - Remove a ticket mute rule
+func (r *Router) handleTicketSuppression(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodDelete {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := req.PathValue("id")
+	err := r.service.DeleteTicketSuppression(req.Context(), id)
+	switch {
+	case errors.Is(err, service.ErrSuppressionNotFound):
+		jsonError(w, err.Error(), http.StatusNotFound)
+		return
+	case err != nil:
+		serverError(w, err)
+		return
+	}
+
+	jsonResponse(w, map[string]any{
+		"deleted": id,
+	})
+}
+
+// GET /admin/webhooks - Subscriptions to seller profile transitions, oldest first
+// POST /admin/webhooks - Subscribe a URL to some or all transitions
+func (r *Router) handleWebhooks(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+		subs, err := r.service.ListWebhookSubscriptions(req.Context())
+		if err != nil {
+			serverError(w, err)
+			return
+		}
+		jsonResponse(w, map[string]any{
+			"webhooks":    subs,
+			"count":       len(subs),
+			"transitions": client.ProfileTransitions,
+		})
+
+	case http.MethodPost:
+		var body client.WebhookSubscriptionRequest
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			jsonError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if body.By == "" {
+			body.By = actorFromContext(req.Context())
+		}
+		if !auditAdminChange(w, req, client.AuditWebhooksAdmin, "webhooks", "subscribed "+body.URL) {
+			return
+		}
+		sub, err := r.service.CreateWebhookSubscription(req.Context(), body)
+		switch {
+		case errors.Is(err, service.ErrInvalidWebhook):
+			jsonError(w, err.Error(), http.StatusBadRequest)
+			return
+		case err != nil:
+			serverError(w, err)
+			return
+		}
+		jsonResponse(w, sub)
+
+	default:
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// DELETE /admin/webhooks/{id} - Unsubscribe a webhook
+func (r *Router) handleWebhook(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodDelete {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := req.PathValue("id")
+	if !auditAdminChange(w, req, client.AuditWebhooksAdmin, id, "removed") {
+		return
+	}
+	err := r.service.DeleteWebhookSubscription(req.Context(), id)
+	switch {
+	case errors.Is(err, service.ErrWebhookNotFound):
+		jsonError(w, err.Error(), http.StatusNotFound)
+		return
+	case err != nil:
+		serverError(w, err)
+		return
+	}
+
+	jsonResponse(w, map[string]any{
+		"deleted": id,
+	})
+}
+
+// GET /admin/alert-subscriptions - Who receives which alerts, oldest first
+// POST /admin/alert-subscriptions - Subscribe someone to the alerts in their cities and verticals
+func (r *Router) handleAlertSubscriptions(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+		subs, err := r.service.ListAlertSubscriptions(req.Context())
+		if err != nil {
+			serverError(w, err)
+			return
+		}
+		jsonResponse(w, map[string]any{
+			"subscriptions": subs,
+			"count":         len(subs),
+		})
+
+	case http.MethodPost:
+		var body client.AlertSubscriptionRequest
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			jsonError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if body.By == "" {
+			body.By = actorFromContext(req.Context())
+		}
+		if !auditAdminChange(w, req, client.AuditAlertSubsAdmin, "alert-subscriptions", "subscribed "+body.Name) {
+			return
+		}
+		sub, err := r.service.CreateAlertSubscription(req.Context(), body)
+		switch {
+		case errors.Is(err, service.ErrInvalidAlertSubscription):
+			jsonError(w, err.Error(), http.StatusBadRequest)
+			return
+		case err != nil:
+			serverError(w, err)
+			return
+		}
+		jsonResponse(w, sub)
+
+	default:
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// GET /admin/alert-subscriptions/{id} - One alert subscription
+// DELETE /admin/alert-subscriptions/{id} - Unsubscribe
+func (r *Router) handleAlertSubscription(w http.ResponseWriter, req *http.Request) {
+	id := req.PathValue("id")
+	switch req.Method {
+	case http.MethodGet:
+		sub, err := r.service.GetAlertSubscription(req.Context(), id)
+		switch {
+		case errors.Is(err, service.ErrAlertSubscriptionNotFound):
+			jsonError(w, err.Error(), http.StatusNotFound)
+			return
+		case err != nil:
+			serverError(w, err)
+			return
+		}
+		jsonResponse(w, sub)
+
+	case http.MethodDelete:
+		if !auditAdminChange(w, req, client.AuditAlertSubsAdmin, id, "removed") {
+			return
+		}
+		err := r.service.DeleteAlertSubscription(req.Context(), id)
+		switch {
+		case errors.Is(err, service.ErrAlertSubscriptionNotFound):
+			jsonError(w, err.Error(), http.StatusNotFound)
+			return
+		case err != nil:
+			serverError(w, err)
+			return
+		}
+		jsonResponse(w, map[string]any{
+			"deleted": id,
+		})
+
+	default:
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// GET /admin/bucket-owners - The teams owning feature buckets, which tickets are routed to
+func (r *Router) handleBucketOwners(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	owners, err := r.service.ListBucketOwners(req.Context())
+	if err != nil {
+		serverError(w, err)
+		return
+	}
+	jsonResponse(w, map[string]any{
+		"owners": owners,
+		"count":  len(owners),
+	})
+}
+
+// PUT /admin/bucket-owners/{bucket} - Set a bucket's owner (bucket URL-escaped, e.g. Billing%20%26%20Renewal)
+// DELETE /admin/bucket-owners/{bucket} - Leave a bucket without an owner
+func (r *Router) handleBucketOwner(w http.ResponseWriter, req *http.Request) {
+	bucket := req.PathValue("bucket")
+	switch req.Method {
+	case http.MethodPut:
+		var body client.BucketOwnerRequest
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			jsonError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if body.By == "" {
+			body.By = actorFromContext(req.Context())
+		}
+		if !auditAdminChange(w, req, client.AuditOwnersAdmin, bucket, "team="+body.Team) {
+			return
+		}
+		owner, err := r.service.SetBucketOwner(req.Context(), bucket, body)
+		switch {
+		case errors.Is(err, service.ErrInvalidBucketOwner):
+			jsonError(w, err.Error(), http.StatusBadRequest)
+			return
+		case err != nil:
+			serverError(w, err)
+			return
+		}
+		jsonResponse(w, owner)
+
+	case http.MethodDelete:
+		if !auditAdminChange(w, req, client.AuditOwnersAdmin, bucket, "removed") {
+			return
+		}
+		err := r.service.DeleteBucketOwner(req.Context(), bucket)
+		switch {
+		case errors.Is(err, service.ErrBucketOwnerNotFound):
+			jsonError(w, err.Error(), http.StatusNotFound)
+			return
+		case err != nil:
+			serverError(w, err)
+			return
+		}
+		jsonResponse(w, map[string]any{
+			"deleted": bucket,
+		})
+
+	default:
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// GET /admin/reclassifications - Taxonomy migration runs, newest first
+// POST /admin/reclassifications (scope: migrate) - Move stored data from retired buckets to the current taxonomy
+func (r *Router) handleReclassifications(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+		runs, err := r.service.ListReclassifications(req.Context())
+		if err != nil {
+			serverError(w, err)
+			return
+		}
+		jsonResponse(w, map[string]any{
+			"reclassifications": runs,
+			"count":             len(runs),
+		})
+
+	case http.MethodPost:
+		var body client.ReclassifyRequest
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			jsonError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		requireScope(scopeMigrate, client.AuditReclassify, "reclassifications", func(w http.ResponseWriter, req *http.Request) {
+			if body.By == "" {
+				body.By = actorFromContext(req.Context())
+			}
+			if !body.DryRun {
+				if err := auditChange(req, client.AuditReclassify, "reclassifications", describeReclassify(body)); err != nil {
+					log.Printf("⚠️ Refusing reclassification, audit log unavailable: %v", err)
+					jsonError(w, "Audit log unavailable", http.StatusServiceUnavailable)
+					return
+				}
+			}
+			run, err := r.service.Reclassify(req.Context(), body)
+			switch {
+			case errors.Is(err, service.ErrInvalidReclassify):
+				jsonError(w, err.Error(), http.StatusBadRequest)
+				return
+			case errors.Is(err, service.ErrReclassifyRunning):
+				jsonError(w, err.Error(), http.StatusConflict)
+				return
+			case err != nil && run != nil:
+				serverError(w, fmt.Errorf("reclassification %s failed: %w", run.ID, err))
+				return
+			case err != nil:
+				serverError(w, err)
+				return
+			}
+			jsonResponse(w, run)
+		})(w, req)
+
+	default:
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// describeReclassify is a reclassification's audit entry reason
+func describeReclassify(body client.ReclassifyRequest) string {
+	var moves []string
+	for _, m := range body.Mappings {
+		moves = append(moves, m.From+" -> "+m.To)
+	}
+	for _, sp := range body.Splits {
+		into := make([]string, len(sp.Into))
+		for i, t := range sp.Into {
+			into[i] = t.Bucket
+		}
+		moves = append(moves, sp.From+" -> "+strings.Join(into, " | "))
+	}
+	return strings.Join(moves, "; ")
+}
+
+// GET /admin/reclassifications/{id}?kind=&limit= - A run with the records it moved (kind: analysis_issue,
+// tracked_issue, aggregate, ticket; limit default and max 1000)
+func (r *Router) handleReclassification(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	q := req.URL.Query()
+	limit := 0
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			jsonError(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	run, err := r.service.GetReclassification(req.Context(), req.PathValue("id"), q.Get("kind"), limit)
+	switch {
+	case errors.Is(err, service.ErrInvalidReclassify):
+		jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, service.ErrReclassifyNotFound):
+		jsonError(w, "Reclassification not found", http.StatusNotFound)
+		return
+	case err != nil:
+		serverError(w, err)
+		return
+	}
+	jsonResponse(w, run)
+}
+
+// GET /admin/flags - Every feature flag's current setting
+func (r *Router) handleFeatureFlags(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	list, err := r.service.ListFeatureFlags(req.Context())
+	if err != nil {
+		serverError(w, err)
+		return
+	}
+
+	jsonResponse(w, map[string]any{
+		"flags": list,
+		"count": len(list),
+	})
+}
+
+// PUT /admin/flags/{name} - Turn a feature flag on or off, for a share of calls or some origins
+// DELETE /admin/flags/{name} - Return a flag to FEATURE_FLAGS or the default
+func (r *Router) handleFeatureFlag(w http.ResponseWriter, req *http.Request) {
+	name := req.PathValue("name")
+	switch req.Method {
+	case http.MethodPut:
+		var body client.FeatureFlagRequest
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			jsonError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if body.By == "" {
+			body.By = actorFromContext(req.Context())
+		}
+		if !auditAdminChange(w, req, client.AuditFlagsAdmin, name, describeFlag(body)) {
+			return
+		}
+		flag, err := r.service.SetFeatureFlag(req.Context(), name, body)
+		switch {
+		case errors.Is(err, service.ErrInvalidFlag):
+			jsonError(w, err.Error(), http.StatusBadRequest)
+			return
+		case err != nil:
+			serverError(w, err)
+			return
+		}
+		jsonResponse(w, flag)
+
+	case http.MethodDelete:
+		if !auditAdminChange(w, req, client.AuditFlagsAdmin, name, "deleted") {
+			return
+		}
+		err := r.service.DeleteFeatureFlag(req.Context(), name)
+		switch {
+		case errors.Is(err, service.ErrFlagNotFound):
+			jsonError(w, err.Error(), http.StatusNotFound)
+			return
+		case err != nil:
+			serverError(w, err)
+			return
+		}
+		jsonResponse(w, map[string]any{
+			"deleted": name,
+		})
+
+	default:
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// describeFlag is a flag change's audit entry reason
+func describeFlag(body client.FeatureFlagRequest) string {
+	change := fmt.Sprintf("enabled=%t", body.Enabled)
+	if body.Percent != nil {
+		change += fmt.Sprintf(" percent=%d", *body.Percent)
+	}
+	if len(body.Origins) > 0 {
+		change += " origins=" + strings.Join(body.Origins, ",")
+	}
+	return change
+}
+
+// GET /admin/custom-fields?entity= - Custom field definitions on profiles and tickets, by entity and name
+func (r *Router) handleCustomFields(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	list, err := r.service.ListCustomFields(req.Context(), req.URL.Query().Get("entity"))
+	switch {
+	case errors.Is(err, service.ErrInvalidCustomField):
+		jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		serverError(w, err)
+		return
+	}
+
+	jsonResponse(w, map[string]any{
+		"fields": list,
+		"count":  len(list),
+	})
+}
+
+// PUT /admin/custom-fields/{entity}/{name} - Define a custom field on profiles or tickets, or change its definition
+// DELETE /admin/custom-fields/{entity}/{name} - Remove a definition; values already set are kept
+func (r *Router) handleCustomField(w http.ResponseWriter, req *http.Request) {
+	entity, name := req.PathValue("entity"), req.PathValue("name")
+	switch req.Method {
+	case http.MethodPut:
+		var body client.CustomFieldRequest
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			jsonError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if body.By == "" {
+			if key := lookupAPIKey(req); key != nil {
+				body.By = key.name
+			}
+		}
+		def, err := r.service.SetCustomField(req.Context(), entity, name, body)
+		switch {
+		case errors.Is(err, service.ErrInvalidCustomField):
+			jsonError(w, err.Error(), http.StatusBadRequest)
+			return
+		case err != nil:
+			serverError(w, err)
+			return
+		}
+		jsonResponse(w, def)
+
+	case http.MethodDelete:
+		err := r.service.DeleteCustomField(req.Context(), entity, name)
+		switch {
+		case errors.Is(err, service.ErrCustomFieldNotFound):
+			jsonError(w, err.Error(), http.StatusNotFound)
+			return
+		case err != nil:
+			serverError(w, err)
+			return
+		}
+		jsonResponse(w, map[string]any{
+			"deleted": entity + "." + name,
+		})
+
+	default:
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// customFieldFilters parses the request's cf.<name>=<value> filters on an
+// entity's custom fields, nil without any. It writes the error response and
+// returns false when one is invalid.
+func (r *Router) customFieldFilters(w http.ResponseWriter, req *http.Request, entity string) (map[string]any, bool) {
+	raw := make(map[string]string)
+	for key, values := range req.URL.Query() {
+		if name, ok := strings.CutPrefix(key, "cf."); ok && len(values) > 0 {
+			raw[name] = values[0]
+		}
+	}
+	filters, err := r.service.ParseCustomFieldFilters(req.Context(), entity, raw)
+	switch {
+	case errors.Is(err, service.ErrInvalidCustomField):
+		jsonError(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	case err != nil:
+		serverError(w, err)
+		return nil, false
+	}
+	return filters, true
+}
+
+// GET /admin/rollouts - Canary rollouts of prompt/model changes, newest first
+// POST /admin/rollouts - Route a share of analyses to a candidate model and/or prompt registry
+func (r *Router) handleRollouts(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+		rollouts, err := r.service.ListRollouts(req.Context())
+		if err != nil {
+			serverError(w, err)
+			return
+		}
+		jsonResponse(w, map[string]any{
+			"rollouts": rollouts,
+			"count":    len(rollouts),
+		})
+
+	case http.MethodPost:
+		var body client.RolloutRequest
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			jsonError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if body.By == "" {
+			body.By = actorFromContext(req.Context())
+		}
+		change := fmt.Sprintf("started: model=%s prompt_registry=%s percent=%d", body.Model, body.PromptRegistry, body.Percent)
+		if !auditAdminChange(w, req, client.AuditRolloutsAdmin, "rollouts", change) {
+			return
+		}
+		rollout, err := r.service.CreateRollout(req.Context(), body)
+		switch {
+		case errors.Is(err, service.ErrInvalidRollout):
+			jsonError(w, err.Error(), http.StatusBadRequest)
+			return
+		case errors.Is(err, service.ErrRolloutConflict):
+			jsonError(w, err.Error(), http.StatusConflict)
+			return
+		case err != nil:
+			serverError(w, err)
+			return
+		}
+		jsonResponse(w, rollout)
+
+	default:
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// GET /admin/rollouts/{id} - A rollout with its candidate and primary metrics as of now
+// PATCH /admin/rollouts/{id} - Change its percent, pause, resume, complete or roll it back
+func (r *Router) handleRollout(w http.ResponseWriter, req *http.Request) {
+	id := req.PathValue("id")
+	var rollout *client.Rollout
+	var err error
+	switch req.Method {
+	case http.MethodGet:
+		rollout, err = r.service.GetRollout(req.Context(), id)
+	case http.MethodPatch:
+		var body client.RolloutUpdate
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			jsonError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if body.By == "" {
+			body.By = actorFromContext(req.Context())
+		}
+		if !auditAdminChange(w, req, client.AuditRolloutsAdmin, id, describeRolloutUpdate(body)) {
+			return
+		}
+		rollout, err = r.service.UpdateRollout(req.Context(), id, body)
+	default:
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	switch {
+	case errors.Is(err, service.ErrInvalidRollout):
+		jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, service.ErrRolloutNotFound):
+		jsonError(w, err.Error(), http.StatusNotFound)
+		return
+	case errors.Is(err, service.ErrRolloutConflict):
+		jsonError(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		serverError(w, err)
+		return
+	}
+	jsonResponse(w, rollout)
+}
+
+// describeRolloutUpdate is a rollout change's audit entry reason
+func describeRolloutUpdate(body client.RolloutUpdate) string {
+	var changes []string
+	if body.Percent != nil {
+		changes = append(changes, fmt.Sprintf("percent=%d", *body.Percent))
+	}
+	if body.Status != "" {
+		changes = append(changes, "status="+body.Status)
+	}
+	if body.Reason != "" {
+		changes = append(changes, "reason: "+body.Reason)
+	}
+	return strings.Join(changes, " ")
+}
+
+// GET /admin/eval/history?from=&to=&version= - Eval runs and each version's metrics across them (default last 90 days)
+func (r *Router) handleEvalHistory(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := req.URL.Query()
+	from, to, ok := dateRange(w, q, config.EVAL_HISTORY_DAYS)
+	if !ok {
+		return
+	}
+
+	history, err := r.service.EvalHistory(req.Context(), from, to, q.Get("version"))
+	switch {
+	case errors.Is(err, service.ErrInvalidEvalRange):
+		jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		serverError(w, err)
+		return
+	}
+	jsonResponse(w, history)
+}
+
+// POST /admin/eval/run - Record every version's metrics now, off schedule
+func (r *Router) handleEvalRun(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	run, err := r.service.RunEval(req.Context())
+	if err != nil {
+		serverError(w, err)
+		return
+	}
+	jsonResponse(w, run)
+}
+
+// GET /admin/kb - Knowledge base documents analyses are grounded in, oldest first
+// POST /admin/kb - Upload a document, replacing the one with the same title
+func (r *Router) handleKBDocuments(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+		docs, err := r.service.ListKBDocuments(req.Context())
+		if err != nil {
+			serverError(w, err)
+			return
+		}
+		jsonResponse(w, map[string]any{
+			"documents": docs,
+			"count":     len(docs),
+		})
+
+	case http.MethodPost:
+		var body client.KBDocumentRequest
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			jsonError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if body.By == "" {
+			body.By = actorFromContext(req.Context())
+		}
+		if !auditAdminChange(w, req, client.AuditKBAdmin, "kb_documents", "uploaded "+body.Title) {
+			return
+		}
+		doc, err := r.service.SaveKBDocument(req.Context(), body)
+		switch {
+		case errors.Is(err, service.ErrInvalidKBDocument):
+			jsonError(w, err.Error(), http.StatusBadRequest)
+			return
+		case err != nil:
+			serverError(w, err)
+			return
+		}
+		jsonResponse(w, doc)
+
+	default:
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// GET /admin/kb/{id} - A knowledge base document with its passages
+// DELETE /admin/kb/{id} - Remove a document from the knowledge base
+func (r *Router) handleKBDocument(w http.ResponseWriter, req *http.Request) {
+	id := req.PathValue("id")
+	switch req.Method {
+	case http.MethodGet:
+		doc, err := r.service.GetKBDocument(req.Context(), id)
+		switch {
+		case errors.Is(err, service.ErrKBDocumentNotFound):
+			jsonError(w, err.Error(), http.StatusNotFound)
+			return
+		case err != nil:
+			serverError(w, err)
+			return
+		}
+		jsonResponse(w, doc)
+
+	case http.MethodDelete:
+		if !auditAdminChange(w, req, client.AuditKBAdmin, id, "removed") {
+			return
+		}
+		err := r.service.DeleteKBDocument(req.Context(), id)
+		switch {
+		case errors.Is(err, service.ErrKBDocumentNotFound):
+			jsonError(w, err.Error(), http.StatusNotFound)
+			return
+		case err != nil:
+			serverError(w, err)
+			return
+		}
+		jsonResponse(w, map[string]any{
+			"deleted": id,
+		})
+
+	default:
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
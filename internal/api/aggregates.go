@@ -0,0 +1,272 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/service"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== AGGREGATES ====================
+
+// GET /aggregates - List all available aggregates
+func (r *Router) handleAggregates(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// MongoDB first
+	var dates []string
+	var err error
+
+	if storage.IsMongoEnabled() {
+		dates, err = storage.ListAggregateDatesFromMongo(req.Context())
+		if err != nil {
+			log.Printf("⚠️ MongoDB list failed, falling back to local: %v", err)
+		}
+	}
+
+	// Fallback to local
+	if len(dates) == 0 {
+		dates, err = storage.ListAggregates()
+		if err != nil {
+			serverError(w, err)
+			return
+		}
+	}
+
+	jsonResponse(w, map[string]any{
+		"dates": dates,
+		"count": len(dates),
+	})
+}
+
+// GET /aggregates/{date} - Get aggregate for a specific date
+// GET /aggregates/{date}/shifts[/{shift}] - Get the date's shift aggregates, or one of them
+// GET /aggregates/{date}/by-tier - Get the date's aggregate split by customer tier
+func (r *Router) handleAggregateByDate(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	date, rest, _ := strings.Cut(strings.TrimPrefix(req.URL.Path, "/aggregates/"), "/")
+	if date == "" || date == "trigger" {
+		r.handleAggregates(w, req)
+		return
+	}
+	if rest == "by-tier" {
+		r.handleTierAggregates(w, req, date)
+		return
+	}
+	if rest != "" {
+		r.handleShiftAggregates(w, req, date, rest)
+		return
+	}
+
+	agg, err := r.service.GetDashboardAggregate(req.Context(), date)
+	if err != nil {
+		jsonError(w, "Aggregate not found: "+err.Error(), http.StatusNotFound)
+		return
+	}
+
+	jsonResponse(w, agg)
+}
+
+func (r *Router) handleTierAggregates(w http.ResponseWriter, req *http.Request, date string) {
+	if _, err := config.ParseBusinessDate(date); err != nil {
+		jsonError(w, "Invalid date, want YYYY-MM-DD", http.StatusBadRequest)
+		return
+	}
+	tiers, err := r.service.GetTierAggregates(req.Context(), date)
+	switch {
+	case errors.Is(err, service.ErrNoAnalyses):
+		jsonError(w, err.Error(), http.StatusNotFound)
+		return
+	case err != nil:
+		serverError(w, err)
+		return
+	}
+	jsonResponse(w, tiers)
+}
+
+func (r *Router) handleShiftAggregates(w http.ResponseWriter, req *http.Request, date, rest string) {
+	sub, shift, _ := strings.Cut(rest, "/")
+	if sub != "shifts" {
+		http.NotFound(w, req)
+		return
+	}
+
+	if shift == "" {
+		aggs, err := r.service.GetShiftAggregates(req.Context(), date)
+		if err != nil {
+			serverError(w, err)
+			return
+		}
+		jsonResponse(w, map[string]any{
+			"date":   date,
+			"shifts": aggs,
+			"count":  len(aggs),
+		})
+		return
+	}
+
+	agg, err := r.service.GetShiftAggregate(req.Context(), date, shift)
+	switch {
+	case errors.Is(err, service.ErrUnknownShift):
+		jsonError(w, err.Error(), http.StatusNotFound)
+		return
+	case err != nil:
+		serverError(w, err)
+		return
+	case agg == nil:
+		jsonError(w, "No "+shift+" shift aggregate for "+date, http.StatusNotFound)
+		return
+	}
+	jsonResponse(w, agg)
+}
+
+// POST /aggregates/trigger - Trigger aggregation for today (or specified date)
+func (r *Router) handleTriggerAggregation(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var body struct {
+		Date string `json:"date"` // Optional, defaults to today
+	}
+	json.NewDecoder(req.Body).Decode(&body)
+
+	date := body.Date
+	if date == "" {
+		date = config.Today()
+	}
+
+	agg, err := r.service.RunAggregation(req.Context(), date)
+	if err != nil {
+		serverError(w, err)
+		return
+	}
+
+	jsonResponse(w, map[string]any{
+		"status":    "aggregation complete",
+		"date":      date,
+		"aggregate": agg,
+	})
+}
+
+// POST /aggregates/preview - The aggregate and tickets a run for {"date"} would save, without saving them
+func (r *Router) handleAggregatePreview(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var body struct {
+		Date string `json:"date"` // Optional, defaults to today
+	}
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil && err != io.EOF {
+		jsonError(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	date := body.Date
+	if date == "" {
+		date = config.Today()
+	}
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		jsonError(w, "date must be YYYY-MM-DD", http.StatusBadRequest)
+		return
+	}
+
+	preview, err := r.service.PreviewAggregation(req.Context(), date)
+	if err != nil {
+		serverError(w, err)
+		return
+	}
+	jsonResponse(w, preview)
+}
+
+// POST /aggregates/check - Flag recent aggregates whose analyses changed, re-aggregating them with {"recompute": true}
+func (r *Router) handleCheckAggregates(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var body struct {
+		Recompute bool `json:"recompute"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil && err != io.EOF {
+		jsonError(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	report, err := r.service.CheckAggregates(req.Context(), body.Recompute)
+	if err != nil {
+		serverError(w, err)
+		return
+	}
+	jsonResponse(w, report)
+}
+
+// ==================== DASHBOARD ====================
+
+// GET /dashboard?date=YYYY-MM-DD&shift=&as_computed= - Get the daily intelligence dashboard, a shift's, or
+// with as_computed=true the date's snapshot as first computed after the day ended
+func (r *Router) handleDashboard(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	date := req.URL.Query().Get("date")
+	if date == "" {
+		date = config.Today()
+	}
+
+	shift := req.URL.Query().Get("shift")
+	asComputed := false
+	if v := req.URL.Query().Get("as_computed"); v != "" {
+		var err error
+		if asComputed, err = strconv.ParseBool(v); err != nil {
+			jsonError(w, "Invalid as_computed (use true or false)", http.StatusBadRequest)
+			return
+		}
+	}
+	if asComputed {
+		if shift != "" {
+			jsonError(w, "shift can't be combined with as_computed: snapshots keep the daily aggregate", http.StatusBadRequest)
+			return
+		}
+		dashboard, err := r.service.GetComputedDashboard(req.Context(), date)
+		switch {
+		case errors.Is(err, service.ErrNoSnapshot):
+			jsonError(w, "Dashboard not available: "+err.Error()+" (taken by the first aggregation after the day ends)", http.StatusNotFound)
+			return
+		case err != nil:
+			serverError(w, err)
+			return
+		}
+		jsonResponse(w, dashboard)
+		return
+	}
+
+	dashboard, err := r.service.GetDashboard(req.Context(), date, shift)
+	if err != nil {
+		jsonError(w, "Dashboard not available: "+err.Error(), http.StatusNotFound)
+		return
+	}
+
+	jsonResponse(w, dashboard)
+}
@@ -0,0 +1,604 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/aggregate"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/llm"
+	"im-ai-voice/internal/profile"
+	"im-ai-voice/internal/service"
+)
+
+// ==================== ANALYTICS ====================
+
+// GET /analytics/heatmap?dimension=city|vertical&metric=...&from=YYYY-MM-DD&to=YYYY-MM-DD&stage=
+func (r *Router) handleHeatmap(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := req.URL.Query()
+	dimension := q.Get("dimension")
+	if dimension == "" {
+		dimension = "city"
+	}
+	metric := q.Get("metric")
+	if metric == "" {
+		metric = "negative_sentiment_rate"
+	}
+
+	// Default range: last 7 days including today
+	from, to, ok := dateRange(w, q, 7)
+	if !ok {
+		return
+	}
+	stage, ok := journeyStage(w, q)
+	if !ok {
+		return
+	}
+
+	heatmap, err := aggregate.BuildHeatmap(req.Context(), dimension, metric, from, to, stage)
+	switch {
+	case errors.Is(err, aggregate.ErrInvalidQuery):
+		jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		serverError(w, err)
+		return
+	}
+
+	jsonResponse(w, heatmap)
+}
+
+// GET /analytics/churn-reasons?from=&to=&stage= - At-risk calls by churn reason category (default last 30 days)
+func (r *Router) handleChurnReasons(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := req.URL.Query()
+	from, to, ok := dateRange(w, q, 30)
+	if !ok {
+		return
+	}
+	stage, ok := journeyStage(w, q)
+	if !ok {
+		return
+	}
+
+	report, err := aggregate.BuildChurnReasons(req.Context(), from, to, stage)
+	switch {
+	case errors.Is(err, aggregate.ErrInvalidQuery):
+		jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		serverError(w, err)
+		return
+	}
+
+	jsonResponse(w, report)
+}
+
+// GET /analytics/churn-accuracy?from=&to=&window_days= - Churn predictions against the renewals and
+// cancellations recorded from to to, per prediction version (default last 180 days)
+func (r *Router) handleChurnAccuracy(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := req.URL.Query()
+	from, to, ok := dateRange(w, q, 180)
+	if !ok {
+		return
+	}
+	window := 0
+	if v := q.Get("window_days"); v != "" {
+		var err error
+		if window, err = strconv.Atoi(v); err != nil || window < 1 {
+			jsonError(w, "Invalid window_days (use a positive number of days)", http.StatusBadRequest)
+			return
+		}
+	}
+
+	report, err := r.service.ChurnAccuracy(req.Context(), from, to, window)
+	switch {
+	case errors.Is(err, service.ErrInvalidChurnOutcomes):
+		jsonError(w, err.Error(), http.StatusBadRequest)
+	case err != nil:
+		serverError(w, err)
+	default:
+		jsonResponse(w, report)
+	}
+}
+
+// POST /churn-outcomes - Record sellers' renewals and cancellations, a JSON array of
+// {gluser_id, outcome, date} or text/csv with a header row (scope: profiles)
+func (r *Router) handleChurnOutcomes(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	requireScope(scopeProfiles, client.AuditChurnOutcomes, "churn-outcomes", func(w http.ResponseWriter, req *http.Request) {
+		var outcomes []client.ChurnOutcome
+		var lines []int
+		mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+		if mediaType == "text/csv" {
+			var err error
+			if outcomes, lines, err = service.ParseChurnOutcomesCSV(req.Body); err != nil {
+				jsonError(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		} else if err := json.NewDecoder(req.Body).Decode(&outcomes); err != nil {
+			jsonError(w, "Invalid request body, want an array of {gluser_id, outcome, date} or text/csv", http.StatusBadRequest)
+			return
+		}
+
+		if err := auditChange(req, client.AuditChurnOutcomes, "churn-outcomes", fmt.Sprintf("%d outcomes", len(outcomes))); err != nil {
+			log.Printf("⚠️ Refusing churn outcomes, audit log unavailable: %v", err)
+			jsonError(w, "Audit log unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		result, err := r.service.RecordChurnOutcomes(req.Context(), outcomes, lines, lookupAPIKey(req).name)
+		switch {
+		case errors.Is(err, service.ErrInvalidChurnOutcomes):
+			jsonError(w, err.Error(), http.StatusBadRequest)
+		case err != nil:
+			serverError(w, err)
+		default:
+			jsonResponse(w, result)
+		}
+	})(w, req)
+}
+
+// GET /analytics/cross-check?from=&to= - How the keyword rules' readings of calls differed from Gemini's analyses (default last 30 days)
+func (r *Router) handleCrossCheck(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	from, to, ok := dateRange(w, req.URL.Query(), 30)
+	if !ok {
+		return
+	}
+
+	report, err := aggregate.BuildCrossCheck(req.Context(), from, to)
+	switch {
+	case errors.Is(err, aggregate.ErrInvalidQuery):
+		jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		serverError(w, err)
+		return
+	}
+
+	jsonResponse(w, report)
+}
+
+// GET /analytics/onboarding?from=&to= - Funnel of sellers calling in their first 90 days (default last 90 days)
+func (r *Router) handleOnboardingFunnel(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	from, to, ok := dateRange(w, req.URL.Query(), 90)
+	if !ok {
+		return
+	}
+
+	report, err := aggregate.BuildOnboardingFunnel(req.Context(), from, to)
+	switch {
+	case errors.Is(err, aggregate.ErrInvalidQuery):
+		jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		serverError(w, err)
+		return
+	}
+
+	jsonResponse(w, report)
+}
+
+// GET /analytics/tickets/burndown?from=&to= - Open vs resolved tickets over time, resolution times per bucket and the oldest open tickets (default last 30 days)
+func (r *Router) handleTicketBurndown(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	from, to, ok := dateRange(w, req.URL.Query(), 30)
+	if !ok {
+		return
+	}
+
+	report, err := aggregate.BuildTicketBurndown(req.Context(), from, to)
+	switch {
+	case errors.Is(err, aggregate.ErrInvalidQuery):
+		jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		serverError(w, err)
+		return
+	}
+
+	jsonResponse(w, report)
+}
+
+// GET /analytics/drivers?from=&to=&stage= - Factors behind low satisfaction, ranked by impact (default last 90 days)
+func (r *Router) handleSatisfactionDrivers(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := req.URL.Query()
+	from, to, ok := dateRange(w, q, 90)
+	if !ok {
+		return
+	}
+	stage, ok := journeyStage(w, q)
+	if !ok {
+		return
+	}
+
+	report, err := aggregate.BuildSatisfactionDrivers(req.Context(), from, to, stage)
+	switch {
+	case errors.Is(err, aggregate.ErrInvalidQuery):
+		jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		serverError(w, err)
+		return
+	}
+
+	jsonResponse(w, report)
+}
+
+// GET /agents/leaderboard?period=&end=&min_calls= - Agents ranked by composite QA score, with movement since the previous period
+func (r *Router) handleAgentLeaderboard(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := req.URL.Query()
+	period := q.Get("period")
+	if period == "" {
+		period = client.PeriodWeek
+	}
+	end := time.Now().In(config.BusinessTZ)
+	if v := q.Get("end"); v != "" {
+		var err error
+		if end, err = config.ParseBusinessDate(v); err != nil {
+			jsonError(w, "Invalid end date (use YYYY-MM-DD)", http.StatusBadRequest)
+			return
+		}
+	}
+	end = time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.UTC)
+	minCalls := aggregate.DefaultLeaderboardMinCalls
+	if v := q.Get("min_calls"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			jsonError(w, "Invalid min_calls", http.StatusBadRequest)
+			return
+		}
+		minCalls = n
+	}
+
+	board, err := aggregate.BuildAgentLeaderboard(req.Context(), period, end, minCalls)
+	switch {
+	case errors.Is(err, aggregate.ErrInvalidQuery):
+		jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		serverError(w, err)
+		return
+	}
+
+	jsonResponse(w, board)
+}
+
+// GET /shadow/report?from=&to=&candidate= - Candidate analyses compared with the primary ones (default last 7 days)
+func (r *Router) handleShadowReport(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := req.URL.Query()
+	from, to, ok := dateRange(w, q, 7)
+	if !ok {
+		return
+	}
+
+	report, err := r.service.ShadowReport(req.Context(), from, to, q.Get("candidate"))
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	jsonResponse(w, report)
+}
+
+// POST /ask - Answer a plain-language question from the stored analytics
+func (r *Router) handleAsk(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var body client.AskRequest
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		jsonError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := r.service.Ask(req.Context(), body.Question)
+	switch {
+	case errors.Is(err, service.ErrInvalidQuestion):
+		jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, service.ErrUnanswerableQuestion):
+		jsonError(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	case errors.Is(err, service.ErrQuestionNotPlanned):
+		if errors.Is(err, llm.ErrQuotaExceeded) {
+			problemError(w, client.ErrorQuotaExceeded, err.Error(), http.StatusTooManyRequests)
+			return
+		}
+		problemError(w, client.ErrorLLMUnavailable, err.Error(), http.StatusBadGateway)
+		return
+	case err != nil:
+		serverError(w, err)
+		return
+	}
+
+	jsonResponse(w, resp)
+}
+
+// GET /analytics/upsell-pipeline?from=&to=&stage= - Upsell opportunities by product SKU with deal values (default last 30 days)
+func (r *Router) handleUpsellPipeline(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := req.URL.Query()
+	from, to, ok := dateRange(w, q, 30)
+	if !ok {
+		return
+	}
+	stage, ok := journeyStage(w, q)
+	if !ok {
+		return
+	}
+
+	pipeline, err := aggregate.BuildUpsellPipeline(req.Context(), from, to, stage)
+	switch {
+	case errors.Is(err, aggregate.ErrInvalidQuery):
+		jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		serverError(w, err)
+		return
+	}
+
+	jsonResponse(w, pipeline)
+}
+
+// GET /analytics/issue-aging?stage= - Open issues bucketed by age across all sellers
+func (r *Router) handleIssueAging(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	stage, ok := journeyStage(w, req.URL.Query())
+	if !ok {
+		return
+	}
+
+	report, err := profile.BuildIssueAgingReport(req.Context(), stage)
+	if err != nil {
+		serverError(w, err)
+		return
+	}
+
+	jsonResponse(w, report)
+}
+
+// GET /analytics/resolution-durability?from=&to= - Share of issue resolutions that held, per bucket and agent
+func (r *Router) handleResolutionDurability(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := req.URL.Query()
+	var from, to time.Time
+	var err error
+	if v := q.Get("from"); v != "" {
+		if from, err = config.ParseBusinessDate(v); err != nil {
+			jsonError(w, "Invalid from date (use YYYY-MM-DD)", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := q.Get("to"); v != "" {
+		if to, err = config.ParseBusinessDate(v); err != nil {
+			jsonError(w, "Invalid to date (use YYYY-MM-DD)", http.StatusBadRequest)
+			return
+		}
+		to = to.AddDate(0, 0, 1) // inclusive end date
+	}
+
+	report, err := profile.BuildResolutionDurabilityReport(req.Context(), from, to)
+	if err != nil {
+		serverError(w, err)
+		return
+	}
+
+	jsonResponse(w, report)
+}
+
+// GET /analytics/ticket-reconciliation?kind=&bucket=&stage=&limit= - Tracked issues whose state disagrees with their IndiaMART ticket's
+func (r *Router) handleTicketReconciliation(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := req.URL.Query()
+	kind := q.Get("kind")
+	if kind != "" && kind != profile.GapClosedInSource && kind != profile.GapOpenInSource {
+		jsonError(w, "kind must be closed_in_source or open_in_source", http.StatusBadRequest)
+		return
+	}
+	limit := 50
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > 1000 {
+			jsonError(w, "Invalid limit (1-1000)", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+	stage, ok := journeyStage(w, q)
+	if !ok {
+		return
+	}
+
+	report, err := profile.BuildTicketReconciliation(req.Context(), kind, q.Get("bucket"), stage, limit)
+	if err != nil {
+		serverError(w, err)
+		return
+	}
+
+	jsonResponse(w, report)
+}
+
+// GET /analytics/themes?date= - Key insight themes of the week ending on date (default the latest week)
+func (r *Router) handleInsightThemes(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	date := req.URL.Query().Get("date")
+	if date != "" {
+		if _, err := config.ParseBusinessDate(date); err != nil {
+			jsonError(w, "Invalid date (use YYYY-MM-DD)", http.StatusBadRequest)
+			return
+		}
+	}
+
+	report, err := r.service.GetInsightThemes(req.Context(), date)
+	if err != nil {
+		if errors.Is(err, service.ErrNoThemes) {
+			jsonError(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		serverError(w, err)
+		return
+	}
+
+	jsonResponse(w, report)
+}
+
+// POST /analytics/themes/trigger - Re-cluster the key insights of the week ending on {"date"} (default yesterday) now
+func (r *Router) handleTriggerInsightThemes(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var body struct {
+		Date string `json:"date"` // Optional, defaults to yesterday
+	}
+	json.NewDecoder(req.Body).Decode(&body)
+
+	date := body.Date
+	if date == "" {
+		date = config.BusinessDate(time.Now().AddDate(0, 0, -1))
+	} else if _, err := config.ParseBusinessDate(date); err != nil {
+		jsonError(w, "Invalid date (use YYYY-MM-DD)", http.StatusBadRequest)
+		return
+	}
+
+	report, err := r.service.RunInsightThemes(req.Context(), date)
+	if err != nil {
+		serverError(w, err)
+		return
+	}
+
+	jsonResponse(w, report)
+}
+
+// GET /analytics/severity-calibration?date= - Issue severities against their outcomes per bucket (default the latest run)
+func (r *Router) handleSeverityCalibration(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	date := req.URL.Query().Get("date")
+	if date != "" {
+		if _, err := config.ParseBusinessDate(date); err != nil {
+			jsonError(w, "Invalid date (use YYYY-MM-DD)", http.StatusBadRequest)
+			return
+		}
+	}
+
+	report, err := r.service.GetSeverityCalibration(req.Context(), date)
+	if err != nil {
+		if errors.Is(err, service.ErrNoCalibration) {
+			jsonError(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		serverError(w, err)
+		return
+	}
+
+	jsonResponse(w, report)
+}
+
+// POST /analytics/severity-calibration/trigger - Calibrate issue severities as of {"date"} (default today) now
+func (r *Router) handleTriggerSeverityCalibration(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var body struct {
+		Date string `json:"date"` // Optional, defaults to today
+	}
+	json.NewDecoder(req.Body).Decode(&body)
+
+	date := body.Date
+	if date == "" {
+		date = config.BusinessDate(time.Now())
+	} else if _, err := config.ParseBusinessDate(date); err != nil {
+		jsonError(w, "Invalid date (use YYYY-MM-DD)", http.StatusBadRequest)
+		return
+	}
+
+	report, err := r.service.RunSeverityCalibration(req.Context(), date)
+	if err != nil {
+		serverError(w, err)
+		return
+	}
+
+	jsonResponse(w, report)
+}
@@ -0,0 +1,201 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== ACCESS CONTROL ====================
+// Most endpoints are open to anything that can reach the service. Endpoints
+// serving more sensitive data require an API key with the matching scope,
+// sent as X-API-Key or "Authorization: Bearer <key>". Keys are configured
+// with API_KEYS, comma-separated name:key:scopes entries (scopes joined by +):
+//
+//	API_KEYS="support-console:3f9c0e...:transcripts,ops:a81d44...:transcripts"
+//
+// Without API_KEYS, scoped endpoints refuse every request. Refusals are
+// written to the audit log, as are the accesses the handlers let through.
+
+// Scopes
+const (
+	scopeTranscripts = "transcripts" // Full call transcripts and recording URLs
+	scopeMigrate     = "migrate"     // Seller export (transcripts included), import and data inventory
+	scopeProfiles    = "profiles"    // Manual seller profile corrections and renewal outcomes
+	scopePortal      = "portal"      // Seller-safe case summaries for the seller portal
+	scopeTickets     = "tickets"     // Bulk ticket status updates from external ticketing systems
+	scopePII         = "pii"         // PII vault values in place of their tokens, on top of transcripts
+	scopeAdmin       = "admin"       // API keys' usage and quotas, webhook subscriptions, feature flags, rollouts, job runs, alert subscriptions, bucket owners, the knowledge base
+)
+
+type apiKey struct {
+	name   string
+	key    string
+	scopes map[string]bool
+	quota  *client.KeyQuota // From API_KEY_QUOTAS, nil for none
+}
+
+var apiKeys = loadAPIKeys()
+
+// loadAPIKeys parses API_KEYS, skipping malformed entries
+func loadAPIKeys() []apiKey {
+	var keys []apiKey
+	for _, entry := range strings.Split(os.Getenv("API_KEYS"), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, ":", 3)
+		if len(parts) != 3 || parts[0] == "" || parts[1] == "" {
+			log.Printf("⚠️ Ignoring malformed API_KEYS entry (want name:key:scopes)")
+			continue
+		}
+		k := apiKey{name: parts[0], key: parts[1], scopes: make(map[string]bool)}
+		for _, s := range strings.Split(parts[2], "+") {
+			if s = strings.TrimSpace(s); s != "" {
+				k.scopes[s] = true
+			}
+		}
+		keys = append(keys, k)
+	}
+	applyKeyQuotas(keys)
+	return keys
+}
+
+// lookupAPIKey returns the configured key matching the request, if any
+func lookupAPIKey(req *http.Request) *apiKey {
+	presented := req.Header.Get("X-API-Key")
+	if presented == "" {
+		if auth := req.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+			presented = strings.TrimPrefix(auth, "Bearer ")
+		}
+	}
+	if presented == "" {
+		return nil
+	}
+
+	var found *apiKey
+	for i := range apiKeys {
+		// Compare against every key so timing doesn't reveal which one matched
+		if subtle.ConstantTimeCompare([]byte(presented), []byte(apiKeys[i].key)) == 1 {
+			found = &apiKeys[i]
+		}
+	}
+	return found
+}
+
+type actorKey struct{}
+
+// actorFromContext returns the API key name that requireScope let through
+func actorFromContext(ctx context.Context) string {
+	if name, ok := ctx.Value(actorKey{}).(string); ok {
+		return name
+	}
+	return "anonymous"
+}
+
+// requireScope wraps h so it only runs for API keys holding scope. Refusals
+// are audited as action on resource; h records its own entry on success.
+func requireScope(scope, action, resource string, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		key := lookupAPIKey(req)
+		switch {
+		case key == nil:
+			auditDenied(req, "anonymous", action, resource, "missing or unknown API key")
+			jsonError(w, "API key required", http.StatusUnauthorized)
+			return
+		case !key.scopes[scope]:
+			auditDenied(req, key.name, action, resource, "missing scope "+scope)
+			jsonError(w, "API key lacks the "+scope+" scope", http.StatusForbidden)
+			return
+		}
+
+		h(w, req.WithContext(context.WithValue(req.Context(), actorKey{}, key.name)))
+	}
+}
+
+func auditDenied(req *http.Request, actor, action, resource, reason string) {
+	err := storage.RecordAudit(req.Context(), client.AuditEntry{
+		Actor:      actor,
+		Action:     action,
+		Resource:   resource,
+		Outcome:    client.AuditDenied,
+		Reason:     reason,
+		RemoteAddr: req.RemoteAddr,
+	})
+	if err != nil {
+		log.Printf("⚠️ Failed to audit denied %s by %s: %v", action, actor, err)
+	}
+}
+
+// auditAllowed records an access granted by requireScope. Handlers must not
+// serve the data if it fails.
+func auditAllowed(req *http.Request, action, resource string) error {
+	return auditChange(req, action, resource, "")
+}
+
+// auditChange records a change granted by requireScope, described in the
+// entry's reason. Handlers must not make the change if it fails.
+func auditChange(req *http.Request, action, resource, change string) error {
+	return storage.RecordAudit(req.Context(), client.AuditEntry{
+		Actor:      actorFromContext(req.Context()),
+		Action:     action,
+		Resource:   resource,
+		Outcome:    client.AuditAllowed,
+		Reason:     change,
+		RemoteAddr: req.RemoteAddr,
+	})
+}
+
+// auditAdminChange records an admin change granted by requireScope, and
+// answers 503 when that fails, in which case the change mustn't be made
+func auditAdminChange(w http.ResponseWriter, req *http.Request, action, resource, change string) bool {
+	if err := auditChange(req, action, resource, change); err != nil {
+		log.Printf("⚠️ Refusing %s on %s, audit log unavailable: %v", action, resource, err)
+		jsonError(w, "Audit log unavailable", http.StatusServiceUnavailable)
+		return false
+	}
+	return true
+}
+
+// transcriptsAllowed reports whether analyses served to the request may
+// keep their transcripts: its API key holds the transcripts scope and the
+// read of resource was audited as a transcript read. Endpoints serving
+// analyses to anyone else strip them (see withoutTranscript).
+func transcriptsAllowed(req *http.Request, resource string) bool {
+	key := lookupAPIKey(req)
+	if key == nil || !key.scopes[scopeTranscripts] {
+		return false
+	}
+	req = req.WithContext(context.WithValue(req.Context(), actorKey{}, key.name))
+	if err := auditAllowed(req, client.AuditTranscriptRead, resource); err != nil {
+		log.Printf("⚠️ Leaving transcripts out for %s, audit log unavailable: %v", key.name, err)
+		return false
+	}
+	return true
+}
+
+// withoutTranscript returns a copy of an analysis without transcript_en or
+// the raw extraction response, which quotes the transcript in full
+func withoutTranscript(a *client.AnalysisResult) *client.AnalysisResult {
+	if a == nil {
+		return nil
+	}
+	out := *a
+	out.TranscriptEn = ""
+	if _, ok := a.LLMRaw["raw_extraction"]; ok {
+		out.LLMRaw = make(map[string]interface{}, len(a.LLMRaw))
+		for k, v := range a.LLMRaw {
+			if k != "raw_extraction" {
+				out.LLMRaw[k] = v
+			}
+		}
+	}
+	return &out
+}
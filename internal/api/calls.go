@@ -0,0 +1,624 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/llm"
+	"im-ai-voice/internal/recording"
+	"im-ai-voice/internal/service"
+	"im-ai-voice/internal/storage"
+	"im-ai-voice/internal/vault"
+)
+
+// ==================== CALLS ====================
+
+// GET /calls/{id} - Get analysis for a specific call
+// GET /calls/{id}/transcript?rehydrate=true - Full transcripts (scope: transcripts, and pii to rehydrate)
+// POST /calls/{id}/chat - See handleCallChat
+// GET /calls/{id}/draft-followup - Drafted message to the seller, if the analysis has one
+// GET /calls/{id}/llm-raw - Raw Gemini responses, until they expire (scope: transcripts)
+// GET /calls/{id}/versions - See handleCallVersions
+// GET /calls/{id}/shadow - See handleCallShadow
+// GET|POST /calls/{id}/recording - See handleCallRecording
+// GET /calls?seller=&from=&to=&sentiment=&bucket=&escalated=&page=&page_size= - Filtered call listing
+// DELETE /calls/{id}?reason= - Move the call's analysis to the trash
+func (r *Router) handleCalls(w http.ResponseWriter, req *http.Request) {
+	// Extract call ID (and optional sub-resource) from path
+	path := strings.Trim(strings.TrimPrefix(req.URL.Path, "/calls"), "/")
+	callID, sub, _ := strings.Cut(path, "/")
+	if req.Method == http.MethodDelete && callID != "" && sub == "" {
+		r.handleDeleteCall(w, req, callID)
+		return
+	}
+	if req.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if callID == "" {
+		r.handleListCalls(w, req)
+		return
+	}
+
+	switch sub {
+	case "", "draft-followup":
+	case "versions":
+		r.handleCallVersions(w, req, callID)
+		return
+	case "shadow":
+		r.handleCallShadow(w, req, callID)
+		return
+	case "transcript":
+		h := func(w http.ResponseWriter, req *http.Request) {
+			r.handleCallTranscript(w, req, callID)
+		}
+		if req.URL.Query().Get("rehydrate") == "true" {
+			h = requireScope(scopePII, client.AuditPIIRehydrate, callID, h)
+		}
+		requireScope(scopeTranscripts, client.AuditTranscriptRead, callID, h)(w, req)
+		return
+	case "llm-raw":
+		requireScope(scopeTranscripts, client.AuditLLMRawRead, callID, func(w http.ResponseWriter, req *http.Request) {
+			r.handleCallLLMRaw(w, req, callID)
+		})(w, req)
+		return
+	default:
+		jsonError(w, "Unknown call resource: "+sub, http.StatusNotFound)
+		return
+	}
+
+	// Get specific call analysis
+	analysis, err := r.service.GetCallAnalysis(req.Context(), callID)
+	if err != nil {
+		jsonError(w, "Call not found: "+err.Error(), http.StatusNotFound)
+		return
+	}
+
+	if sub == "draft-followup" {
+		if analysis.FollowUpDraft == nil {
+			jsonError(w, "No follow-up draft for call "+callID, http.StatusNotFound)
+			return
+		}
+		jsonResponse(w, analysis.FollowUpDraft)
+		return
+	}
+
+	annotations, err := r.service.CallAnnotations(req.Context(), callID)
+	if err != nil {
+		log.Printf("⚠️ Failed to load annotations of %s: %v", callID, err)
+	}
+	if !transcriptsAllowed(req, callID) {
+		analysis = withoutTranscript(analysis)
+	}
+	jsonResponse(w, struct {
+		*client.AnalysisResult
+		Annotations []client.Annotation `json:"annotations,omitempty"` // QA reviewers' comments, newest first
+	}{analysis, annotations})
+}
+
+// GET /calls/{id}/versions - The call's current analysis version and the analyses it replaced
+func (r *Router) handleCallVersions(w http.ResponseWriter, req *http.Request, callID string) {
+	versions, err := r.service.CallVersions(req.Context(), callID)
+	switch {
+	case errors.Is(err, service.ErrCallNotFound):
+		jsonError(w, err.Error(), http.StatusNotFound)
+		return
+	case err != nil:
+		serverError(w, err)
+		return
+	}
+	if !transcriptsAllowed(req, callID) {
+		for i := range versions.Versions {
+			versions.Versions[i].Analysis = *withoutTranscript(&versions.Versions[i].Analysis)
+		}
+	}
+	jsonResponse(w, versions)
+}
+
+// GET /calls/{id}/shadow - The candidate's analysis of the call and how it compares, for shadowed calls
+func (r *Router) handleCallShadow(w http.ResponseWriter, req *http.Request, callID string) {
+	shadow, err := r.service.CallShadowAnalysis(req.Context(), callID)
+	switch {
+	case errors.Is(err, service.ErrCallNotFound):
+		jsonError(w, err.Error(), http.StatusNotFound)
+		return
+	case err != nil:
+		serverError(w, err)
+		return
+	}
+	if !transcriptsAllowed(req, callID) {
+		shadow.Analysis = withoutTranscript(shadow.Analysis)
+	}
+	jsonResponse(w, shadow)
+}
+
+// GET /calls/{id}/annotations - QA reviewers' comments on the call, newest first
+// POST /calls/{id}/annotations - Anchor a comment to a turn, or to start/end offsets, of transcript_en
+func (r *Router) handleCallAnnotations(w http.ResponseWriter, req *http.Request) {
+	callID := req.PathValue("id")
+	switch req.Method {
+	case http.MethodGet:
+		annotations, err := r.service.CallAnnotations(req.Context(), callID)
+		if err != nil {
+			serverError(w, err)
+			return
+		}
+		jsonResponse(w, annotations)
+	case http.MethodPost:
+		var body client.AnnotationRequest
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			jsonError(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if body.Author == "" {
+			if key := lookupAPIKey(req); key != nil {
+				body.Author = key.name
+			}
+		}
+		a, err := r.service.AnnotateCall(req.Context(), callID, body)
+		switch {
+		case errors.Is(err, service.ErrInvalidAnnotation):
+			jsonError(w, err.Error(), http.StatusBadRequest)
+			return
+		case errors.Is(err, service.ErrCallNotFound):
+			jsonError(w, err.Error(), http.StatusNotFound)
+			return
+		case err != nil:
+			serverError(w, err)
+			return
+		}
+		jsonResponse(w, a)
+	default:
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// POST /calls/{id}/chat - Ask a question about the call, in a new session or
+// the caller's session_id (scope: transcripts). The answers quote the
+// transcript, so every question is audited; none is answered if that fails.
+func (r *Router) handleCallChat(w http.ResponseWriter, req *http.Request) {
+	callID := req.PathValue("id")
+	requireScope(scopeTranscripts, client.AuditCallChat, callID, func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		var body client.CallChatRequest
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			jsonError(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := auditAllowed(req, client.AuditCallChat, callID); err != nil {
+			log.Printf("⚠️ Refusing chat about %s, audit log unavailable: %v", callID, err)
+			jsonError(w, "Audit log unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		chat, err := r.service.ChatAboutCall(req.Context(), callID, lookupAPIKey(req).name, body)
+		switch {
+		case errors.Is(err, service.ErrInvalidChat):
+			jsonError(w, err.Error(), http.StatusBadRequest)
+			return
+		case errors.Is(err, service.ErrCallNotFound), errors.Is(err, service.ErrChatNotFound):
+			jsonError(w, err.Error(), http.StatusNotFound)
+			return
+		case errors.Is(err, service.ErrChatFull), errors.Is(err, service.ErrChatBusy):
+			jsonError(w, err.Error(), http.StatusConflict)
+			return
+		case errors.Is(err, service.ErrChatNotAnswered):
+			if errors.Is(err, llm.ErrQuotaExceeded) {
+				problemError(w, client.ErrorQuotaExceeded, err.Error(), http.StatusTooManyRequests)
+				return
+			}
+			problemError(w, client.ErrorLLMUnavailable, err.Error(), http.StatusBadGateway)
+			return
+		case err != nil:
+			serverError(w, err)
+			return
+		}
+		jsonResponse(w, chat)
+	})(w, req)
+}
+
+// GET /calls/{id}/chat/{session_id} - The caller's conversation about the call (scope: transcripts, audited)
+func (r *Router) handleCallChatSession(w http.ResponseWriter, req *http.Request) {
+	callID := req.PathValue("id")
+	requireScope(scopeTranscripts, client.AuditCallChat, callID, func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		chat, err := r.service.GetCallChat(req.Context(), callID, req.PathValue("session_id"), lookupAPIKey(req).name)
+		switch {
+		case errors.Is(err, service.ErrChatNotFound):
+			jsonError(w, err.Error(), http.StatusNotFound)
+			return
+		case err != nil:
+			serverError(w, err)
+			return
+		}
+		if err := auditAllowed(req, client.AuditCallChat, callID); err != nil {
+			log.Printf("⚠️ Refusing chat about %s, audit log unavailable: %v", callID, err)
+			jsonError(w, "Audit log unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		jsonResponse(w, chat)
+	})(w, req)
+}
+
+// DELETE /calls/{id}/annotations/{annotation_id} - Remove an annotation
+func (r *Router) handleCallAnnotation(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodDelete {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	id := req.PathValue("annotation_id")
+	err := r.service.DeleteAnnotation(req.Context(), req.PathValue("id"), id)
+	switch {
+	case errors.Is(err, service.ErrAnnotationNotFound):
+		jsonError(w, err.Error(), http.StatusNotFound)
+		return
+	case err != nil:
+		serverError(w, err)
+		return
+	}
+
+	jsonResponse(w, map[string]any{
+		"deleted": id,
+	})
+}
+
+// GET /annotations?agent_id=&gluser_id=&category=&limit= - Annotations, newest first, with a coaching rollup per agent
+func (r *Router) handleAnnotations(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := req.URL.Query()
+	query := storage.AnnotationQuery{
+		GluserID: q.Get("gluser_id"),
+		AgentID:  q.Get("agent_id"),
+		Category: q.Get("category"),
+	}
+	limit := 0
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			jsonError(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	report, err := r.service.ListAnnotations(req.Context(), query, limit)
+	if err != nil {
+		serverError(w, err)
+		return
+	}
+	jsonResponse(w, report)
+}
+
+// handleDeleteCall moves a call's analysis to the trash
+func (r *Router) handleDeleteCall(w http.ResponseWriter, req *http.Request, callID string) {
+	item, err := r.service.DeleteCallAnalysis(req.Context(), callID, req.URL.Query().Get("reason"))
+	switch {
+	case errors.Is(err, service.ErrCallNotFound):
+		jsonError(w, err.Error(), http.StatusNotFound)
+		return
+	case err != nil:
+		serverError(w, err)
+		return
+	}
+	if !transcriptsAllowed(req, callID) {
+		item.Analysis = withoutTranscript(item.Analysis)
+	}
+	jsonResponse(w, item)
+}
+
+// handleCallTranscript serves the raw and English transcripts of a call.
+// Every access is audited; nothing is served if the audit write fails.
+func (r *Router) handleCallTranscript(w http.ResponseWriter, req *http.Request, callID string) {
+	transcript, err := r.service.GetCallTranscript(req.Context(), callID)
+	if err != nil {
+		jsonError(w, "Transcript not found: "+err.Error(), http.StatusNotFound)
+		return
+	}
+
+	action := client.AuditTranscriptRead
+	if req.URL.Query().Get("rehydrate") == "true" {
+		action = client.AuditPIIRehydrate
+		err := r.service.RehydrateTranscript(req.Context(), transcript)
+		switch {
+		case errors.Is(err, vault.ErrDisabled):
+			jsonError(w, err.Error(), http.StatusConflict)
+			return
+		case err != nil:
+			serverError(w, err)
+			return
+		}
+	}
+
+	if err := auditAllowed(req, action, callID); err != nil {
+		log.Printf("⚠️ Refusing transcript %s, audit log unavailable: %v", callID, err)
+		jsonError(w, "Audit log unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	jsonResponse(w, transcript)
+}
+
+// handleCallLLMRaw serves the raw Gemini responses of a call. They quote the
+// transcript, so access is audited like transcripts.
+func (r *Router) handleCallLLMRaw(w http.ResponseWriter, req *http.Request, callID string) {
+	raw, err := r.service.GetCallLLMRaw(req.Context(), callID)
+	if err != nil {
+		serverError(w, fmt.Errorf("failed to load raw responses: %w", err))
+		return
+	}
+	if raw == nil {
+		jsonError(w, "No raw responses for call "+callID+" (none stored, or expired)", http.StatusNotFound)
+		return
+	}
+
+	if err := auditAllowed(req, client.AuditLLMRawRead, callID); err != nil {
+		log.Printf("⚠️ Refusing raw responses of %s, audit log unavailable: %v", callID, err)
+		jsonError(w, "Audit log unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	jsonResponse(w, raw)
+}
+
+// GET  /calls/{id}/recording - Signed URL for the call's audio (scope: transcripts)
+// GET  /calls/{id}/recording?expires=&signature= - The audio, through a signed URL
+// POST /calls/{id}/recording - Download the audio from its call_recording_url now (scope: transcripts)
+func (r *Router) handleCallRecording(w http.ResponseWriter, req *http.Request) {
+	callID := req.PathValue("id")
+	switch req.Method {
+	case http.MethodGet:
+		if req.URL.Query().Has("signature") {
+			r.serveRecording(w, req, callID)
+			return
+		}
+		requireScope(scopeTranscripts, client.AuditRecordingLink, callID, func(w http.ResponseWriter, req *http.Request) {
+			r.handleRecordingLink(w, req, callID)
+		})(w, req)
+	case http.MethodPost:
+		requireScope(scopeTranscripts, client.AuditRecordingFetch, callID, func(w http.ResponseWriter, req *http.Request) {
+			r.handleFetchRecording(w, req, callID)
+		})(w, req)
+	default:
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleRecordingLink issues a signed URL for a call's stored audio
+func (r *Router) handleRecordingLink(w http.ResponseWriter, req *http.Request, callID string) {
+	rec, err := storage.LoadRecording(req.Context(), callID)
+	if err != nil {
+		serverError(w, err)
+		return
+	}
+	if rec == nil {
+		jsonError(w, recording.ErrNoRecording.Error(), http.StatusNotFound)
+		return
+	}
+	if rec.PurgedAt != nil {
+		jsonError(w, recording.ErrPurged.Error(), http.StatusGone)
+		return
+	}
+
+	if err := auditAllowed(req, client.AuditRecordingLink, callID); err != nil {
+		log.Printf("⚠️ Refusing recording link %s, audit log unavailable: %v", callID, err)
+		jsonError(w, "Audit log unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	link, expires := recording.SignedURL(callID)
+	jsonResponse(w, client.RecordingLink{
+		CallID:      callID,
+		URL:         link,
+		ExpiresAt:   expires,
+		ContentType: rec.ContentType,
+		SizeBytes:   rec.SizeBytes,
+	})
+}
+
+// serveRecording streams a call's audio to a signed URL holder. Every
+// request is audited (players send several Range requests per playback).
+func (r *Router) serveRecording(w http.ResponseWriter, req *http.Request, callID string) {
+	q := req.URL.Query()
+	if err := recording.Verify(callID, q.Get("expires"), q.Get("signature")); err != nil {
+		auditDenied(req, "signed-url", client.AuditRecordingRead, callID, err.Error())
+		jsonError(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	rec, data, err := recording.Open(req.Context(), callID)
+	switch {
+	case errors.Is(err, recording.ErrNoRecording):
+		jsonError(w, err.Error(), http.StatusNotFound)
+		return
+	case errors.Is(err, recording.ErrPurged):
+		jsonError(w, err.Error(), http.StatusGone)
+		return
+	case err != nil:
+		serverError(w, err)
+		return
+	}
+
+	req = req.WithContext(context.WithValue(req.Context(), actorKey{}, "signed-url"))
+	if err := auditAllowed(req, client.AuditRecordingRead, callID); err != nil {
+		log.Printf("⚠️ Refusing recording %s, audit log unavailable: %v", callID, err)
+		jsonError(w, "Audit log unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", rec.ContentType)
+	w.Header().Set("Cache-Control", "private, no-store")
+	http.ServeContent(w, req, "", rec.FetchedAt, bytes.NewReader(data))
+}
+
+// handleFetchRecording downloads a call's audio on request (e.g. for calls
+// processed before RECORDING_FETCH was enabled)
+func (r *Router) handleFetchRecording(w http.ResponseWriter, req *http.Request, callID string) {
+	if err := auditAllowed(req, client.AuditRecordingFetch, callID); err != nil {
+		log.Printf("⚠️ Refusing recording fetch %s, audit log unavailable: %v", callID, err)
+		jsonError(w, "Audit log unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	rec, err := r.service.FetchCallRecording(req.Context(), callID)
+	switch {
+	case errors.Is(err, service.ErrNoRecordingURL), errors.Is(err, os.ErrNotExist):
+		jsonError(w, err.Error(), http.StatusNotFound)
+		return
+	case err != nil:
+		jsonError(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	jsonResponse(w, rec)
+}
+
+// handleListCalls serves the paginated call listing, newest first
+func (r *Router) handleListCalls(w http.ResponseWriter, req *http.Request) {
+	q := req.URL.Query()
+	query := storage.CallQuery{
+		SellerID:  q.Get("seller"),
+		Sentiment: q.Get("sentiment"),
+		Bucket:    q.Get("bucket"),
+		System:    q.Get("system"),
+		Region:    q.Get("region"),
+		Direction: q.Get("direction"),
+		Status:    q.Get("status"),
+	}
+
+	var err error
+	if v := q.Get("from"); v != "" {
+		if query.From, err = config.ParseBusinessDate(v); err != nil {
+			jsonError(w, "Invalid from date (use YYYY-MM-DD)", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := q.Get("to"); v != "" {
+		if query.To, err = config.ParseBusinessDate(v); err != nil {
+			jsonError(w, "Invalid to date (use YYYY-MM-DD)", http.StatusBadRequest)
+			return
+		}
+		query.To = query.To.AddDate(0, 0, 1) // inclusive end date
+	}
+	if v := q.Get("escalated"); v != "" {
+		escalated, err := strconv.ParseBool(v)
+		if err != nil {
+			jsonError(w, "Invalid escalated (use true or false)", http.StatusBadRequest)
+			return
+		}
+		query.Escalated = &escalated
+	}
+	if v := q.Get("channel"); v != "" {
+		channel, ok := service.NormalizeContactChannel(v)
+		if !ok {
+			jsonError(w, "Invalid channel (use voice, chat or whatsapp)", http.StatusBadRequest)
+			return
+		}
+		query.Channel = channel
+	}
+	if wantsNDJSON(req) {
+		stream := newNDJSONStream(w)
+		err := storage.EachCall(req.Context(), query, func(item client.CallListItem) error {
+			return stream.Send(item)
+		})
+		if err == nil {
+			err = stream.Flush()
+		}
+		if err != nil {
+			stream.Fail(err)
+		}
+		return
+	}
+	if v := q.Get("page"); v != "" {
+		if query.Page, err = strconv.Atoi(v); err != nil || query.Page <= 0 {
+			jsonError(w, "Invalid page", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := q.Get("page_size"); v != "" {
+		if query.PageSize, err = strconv.Atoi(v); err != nil || query.PageSize <= 0 {
+			jsonError(w, "Invalid page_size", http.StatusBadRequest)
+			return
+		}
+	}
+
+	page, err := storage.QueryCalls(req.Context(), query)
+	if err != nil {
+		serverError(w, err)
+		return
+	}
+
+	jsonResponse(w, page)
+}
+
+// ==================== TRASH ====================
+
+// GET /trash?kind=analysis|ticket - Deleted analyses and tickets, most recently deleted first
+func (r *Router) handleTrash(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	list, err := r.service.ListTrash(req.Context(), req.URL.Query().Get("kind"))
+	switch {
+	case errors.Is(err, service.ErrInvalidTrashKind):
+		jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		serverError(w, err)
+		return
+	}
+	if !transcriptsAllowed(req, "trash") {
+		for i := range list.Items {
+			list.Items[i].Analysis = withoutTranscript(list.Items[i].Analysis)
+		}
+	}
+	jsonResponse(w, list)
+}
+
+// POST /trash/{kind}/{id}/restore - Put a deleted analysis or ticket back
+func (r *Router) handleTrashRestore(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	item, err := r.service.RestoreFromTrash(req.Context(), req.PathValue("kind"), req.PathValue("id"))
+	switch {
+	case errors.Is(err, service.ErrInvalidTrashKind):
+		jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, service.ErrNotInTrash):
+		jsonError(w, err.Error(), http.StatusNotFound)
+		return
+	case errors.Is(err, service.ErrRestoreConflict):
+		jsonError(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		serverError(w, err)
+		return
+	}
+	if !transcriptsAllowed(req, item.ID) {
+		item.Analysis = withoutTranscript(item.Analysis)
+	}
+	jsonResponse(w, item)
+}
@@ -0,0 +1,232 @@
+package api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"im-ai-voice/internal/config"
+
+	"github.com/andybalholm/brotli"
+)
+
+// ==================== RESPONSE COMPRESSION ====================
+// Profiles, aggregates and listings are large JSON documents, so responses
+// are compressed with brotli or gzip, whichever the client's
+// Accept-Encoding prefers (brotli when it weighs them the same). Responses
+// under COMPRESS_MIN_BYTES, already compressed media (audio, zips, PDFs)
+// and partial content go out as they are.
+
+// Content codings offered
+const (
+	encodingBrotli = "br"
+	encodingGzip   = "gzip"
+)
+
+// brotliLevel trades ratio for speed on responses made per request; the
+// higher levels are meant for static assets compressed once
+const brotliLevel = 5
+
+var compressMinBytes = func() int {
+	if v := os.Getenv("COMPRESS_MIN_BYTES"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			return n
+		}
+		log.Printf("⚠️ Invalid COMPRESS_MIN_BYTES=%q, using %d", v, config.DEFAULT_COMPRESS_MIN_BYTES)
+	}
+	return config.DEFAULT_COMPRESS_MIN_BYTES
+}()
+
+// encoder is a pooled gzip or brotli writer
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+var encoders = map[string]*sync.Pool{
+	encodingGzip:   {New: func() any { return gzip.NewWriter(nil) }},
+	encodingBrotli: {New: func() any { return brotli.NewWriterLevel(nil, brotliLevel) }},
+}
+
+// Compress wraps h so its responses are compressed for clients that accept it
+func Compress(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		encoding := negotiateEncoding(req.Header.Get("Accept-Encoding"))
+		if req.Method == http.MethodHead || encoding == "" {
+			h.ServeHTTP(w, req)
+			return
+		}
+
+		cw := &compressWriter{w: w, encoding: encoding}
+		h.ServeHTTP(cw, req)
+		cw.Close()
+	})
+}
+
+// negotiateEncoding returns the offered coding an Accept-Encoding header
+// weighs highest, brotli on a tie, or "" when it accepts neither. A coding
+// it doesn't name takes the weight of "*", if any.
+func negotiateEncoding(header string) string {
+	weights := make(map[string]float64)
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding == "" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		weights[coding] = q
+	}
+
+	best, bestQ := "", 0.0
+	for _, coding := range []string{encodingBrotli, encodingGzip} {
+		q, ok := weights[coding]
+		if !ok {
+			q = weights["*"]
+		}
+		if q > bestQ {
+			best, bestQ = coding, q
+		}
+	}
+	return best
+}
+
+// compressWriter holds back the start of a response until it knows whether
+// the response is worth compressing: once it passes compressMinBytes, or
+// the handler flushes or finishes
+type compressWriter struct {
+	w        http.ResponseWriter
+	encoding string // Negotiated coding, br or gzip
+	buf      bytes.Buffer
+	code     int
+	decided  bool
+	enc      encoder // Set when compressing
+}
+
+func (cw *compressWriter) Header() http.Header { return cw.w.Header() }
+
+func (cw *compressWriter) WriteHeader(code int) {
+	if cw.code != 0 || cw.decided {
+		return
+	}
+	cw.code = code
+}
+
+func (cw *compressWriter) Write(p []byte) (int, error) {
+	if cw.code == 0 {
+		cw.code = http.StatusOK
+	}
+	if !cw.decided {
+		cw.buf.Write(p)
+		if cw.buf.Len() < compressMinBytes {
+			return len(p), nil
+		}
+		if err := cw.decide(true); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+	if cw.enc != nil {
+		return cw.enc.Write(p)
+	}
+	return cw.w.Write(p)
+}
+
+// Flush sends what was written so far. A stream is compressed from its
+// start, however short that is, since it may grow.
+func (cw *compressWriter) Flush() {
+	if !cw.decided {
+		cw.decide(true)
+	}
+	if cw.enc != nil {
+		cw.enc.Flush()
+	}
+	http.NewResponseController(cw.w).Flush()
+}
+
+// Close sends the rest of the response once the handler is done
+func (cw *compressWriter) Close() {
+	if !cw.decided {
+		cw.decide(cw.buf.Len() >= compressMinBytes)
+	}
+	if cw.enc != nil {
+		cw.enc.Close()
+		encoders[cw.encoding].Put(cw.enc)
+		cw.enc = nil
+	}
+}
+
+func (cw *compressWriter) Unwrap() http.ResponseWriter { return cw.w }
+
+// decide sends the header, compressing if big is set and the response
+// suits it, then the buffered body
+func (cw *compressWriter) decide(big bool) error {
+	cw.decided = true
+	if cw.code == 0 {
+		cw.code = http.StatusOK
+	}
+	if cw.w.Header().Get("Content-Type") == "" && cw.buf.Len() > 0 {
+		// Sniff it here, the server would sniff the compressed bytes
+		cw.w.Header().Set("Content-Type", http.DetectContentType(cw.buf.Bytes()))
+	}
+	if big && compressible(cw.code, cw.w.Header()) {
+		h := cw.w.Header()
+		h.Del("Content-Length")
+		h.Set("Content-Encoding", cw.encoding)
+		cw.enc = encoders[cw.encoding].Get().(encoder)
+		cw.enc.Reset(cw.w)
+	}
+	cw.w.WriteHeader(cw.code)
+	if cw.buf.Len() == 0 {
+		return nil
+	}
+	var err error
+	if cw.enc != nil {
+		_, err = cw.enc.Write(cw.buf.Bytes())
+	} else {
+		_, err = cw.w.Write(cw.buf.Bytes())
+	}
+	cw.buf.Reset()
+	return err
+}
+
+// compressible reports whether a response is worth compressing: it has a body,
+// isn't partial or already encoded, and isn't compressed media
+func compressible(code int, h http.Header) bool {
+	if code < http.StatusOK || code == http.StatusNoContent || code == http.StatusNotModified || code == http.StatusPartialContent {
+		return false
+	}
+	if h.Get("Content-Encoding") != "" || h.Get("Content-Range") != "" {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(h.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	switch {
+	case strings.HasPrefix(mediaType, "text/"),
+		mediaType == "application/json",
+		mediaType == ndjsonContentType,
+		mediaType == "application/javascript",
+		mediaType == "image/svg+xml",
+		strings.HasSuffix(mediaType, "+json"),
+		strings.HasSuffix(mediaType, "+xml"),
+		mediaType == "application/xml":
+		return true
+	}
+	return false
+}
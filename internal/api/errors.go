@@ -0,0 +1,120 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/llm"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== ERROR RESPONSES ====================
+// Errors are RFC 7807 problem+json documents with a machine-readable code
+// (client.Error*) and the request's ID. jsonError picks the code from the
+// status; serverError tells LLM, quota and storage failures apart from bugs.
+
+// problemTitles are the short, fixed summaries of each code
+var problemTitles = map[string]string{
+	client.ErrorValidationFailed:    "Validation failed",
+	client.ErrorUnauthorized:        "Unauthorized",
+	client.ErrorForbidden:           "Forbidden",
+	client.ErrorNotFound:            "Not found",
+	client.ErrorMethodNotAllowed:    "Method not allowed",
+	client.ErrorConflict:            "Conflict",
+	client.ErrorGone:                "Gone",
+	client.ErrorQuotaExceeded:       "LLM quota exceeded",
+	client.ErrorInternal:            "Internal error",
+	client.ErrorLLMUnavailable:      "LLM unavailable",
+	client.ErrorUpstreamUnavailable: "Upstream service unavailable",
+	client.ErrorStorageUnavailable:  "Storage unavailable",
+	client.ErrorTimeout:             "Request timed out",
+}
+
+// statusCodes are the codes of errors that don't name one
+var statusCodes = map[int]string{
+	http.StatusBadRequest:          client.ErrorValidationFailed,
+	http.StatusUnauthorized:        client.ErrorUnauthorized,
+	http.StatusForbidden:           client.ErrorForbidden,
+	http.StatusNotFound:            client.ErrorNotFound,
+	http.StatusMethodNotAllowed:    client.ErrorMethodNotAllowed,
+	http.StatusConflict:            client.ErrorConflict,
+	http.StatusGone:                client.ErrorGone,
+	http.StatusUnprocessableEntity: client.ErrorValidationFailed,
+	http.StatusTooManyRequests:     client.ErrorQuotaExceeded,
+	http.StatusInternalServerError: client.ErrorInternal,
+	http.StatusBadGateway:          client.ErrorUpstreamUnavailable,
+	http.StatusServiceUnavailable:  client.ErrorStorageUnavailable,
+	http.StatusGatewayTimeout:      client.ErrorTimeout,
+}
+
+// problem is an RFC 7807 error document
+type problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail"`
+	Code      string `json:"code"`
+	RequestID string `json:"request_id,omitempty"`
+	Error     string `json:"error"` // Detail again, for clients from before problem+json
+}
+
+// jsonError writes an error response, its code picked by the status
+func jsonError(w http.ResponseWriter, message string, status int) {
+	code, ok := statusCodes[status]
+	if !ok {
+		code = client.ErrorInternal
+	}
+	problemError(w, code, message, status)
+}
+
+// problemError writes an error response with the given code
+func problemError(w http.ResponseWriter, code, message string, status int) {
+	if status >= http.StatusInternalServerError {
+		log.Printf("⚠️ %d %s [%s]: %s", status, code, w.Header().Get(requestIDHeader), message)
+	}
+	writeProblem(w, code, message, status)
+}
+
+func writeProblem(w http.ResponseWriter, code, message string, status int) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.Header().Del("Content-Length")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(problem{
+		Type:      "urn:im-ai-voice:error:" + code,
+		Title:     problemTitles[code],
+		Status:    status,
+		Detail:    message,
+		Code:      code,
+		RequestID: w.Header().Get(requestIDHeader),
+		Error:     message,
+	})
+}
+
+// serverErrorDetails are the fixed details of serverError's responses: the
+// error itself can carry upstream URLs and responses, so it's only logged
+var serverErrorDetails = map[string]string{
+	client.ErrorQuotaExceeded:      "The LLM's quota or rate limit is spent; retry later",
+	client.ErrorLLMUnavailable:     "The LLM failed or couldn't be reached; retry later",
+	client.ErrorStorageUnavailable: "Storage couldn't be reached; retry later",
+	client.ErrorInternal:           "The request failed; the server log has the cause under the request ID",
+}
+
+// serverError writes the response for an error a handler can't recover
+// from: dependencies that are down or out of quota get their own codes,
+// anything else is an internal error. The error is logged, not sent.
+func serverError(w http.ResponseWriter, err error) {
+	code, status := client.ErrorInternal, http.StatusInternalServerError
+	switch {
+	case errors.Is(err, llm.ErrQuotaExceeded):
+		code, status = client.ErrorQuotaExceeded, http.StatusTooManyRequests
+	case errors.Is(err, llm.ErrUnavailable):
+		code, status = client.ErrorLLMUnavailable, http.StatusBadGateway
+	case storage.IsUnavailable(err):
+		code, status = client.ErrorStorageUnavailable, http.StatusServiceUnavailable
+	}
+	log.Printf("⚠️ %d %s [%s]: %v", status, code, w.Header().Get(requestIDHeader), err)
+	writeProblem(w, code, serverErrorDetails[code], status)
+}
@@ -0,0 +1,158 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"im-ai-voice/internal/i18n"
+)
+
+// ==================== LOCALIZED LABELS ====================
+// A request whose Accept-Language names English or Hindi gets a display
+// string next to each enum value in its JSON response: "sentiment":
+// "Negative" gains "sentiment_display": "नकारात्मक" for hi. The values
+// themselves never change, and requests without the header get responses
+// exactly as before. Streams and non-JSON responses aren't touched.
+
+const displaySuffix = "_display"
+
+// Localize wraps h so JSON responses carry display labels in the request's language
+func Localize(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Add("Vary", "Accept-Language")
+		lang := i18n.Negotiate(req.Header.Get("Accept-Language"))
+		if lang == "" || req.Method == http.MethodHead {
+			h.ServeHTTP(w, req)
+			return
+		}
+
+		lw := &localizeWriter{w: w, lang: lang}
+		h.ServeHTTP(lw, req)
+		lw.Close()
+	})
+}
+
+// localizeWriter holds back a JSON response until the handler is done, to
+// add the labels; anything else goes straight through
+type localizeWriter struct {
+	w       http.ResponseWriter
+	lang    string
+	buf     bytes.Buffer
+	code    int
+	decided bool
+	hold    bool // Set when buffering a JSON response
+}
+
+func (lw *localizeWriter) Header() http.Header { return lw.w.Header() }
+
+func (lw *localizeWriter) WriteHeader(code int) {
+	if lw.code != 0 {
+		return
+	}
+	lw.code = code
+}
+
+func (lw *localizeWriter) Write(p []byte) (int, error) {
+	if lw.code == 0 {
+		lw.code = http.StatusOK
+	}
+	if !lw.decided {
+		lw.decide()
+	}
+	if lw.hold {
+		return lw.buf.Write(p)
+	}
+	return lw.w.Write(p)
+}
+
+// Flush sends a held response as it is, since the handler is streaming
+func (lw *localizeWriter) Flush() {
+	if !lw.decided {
+		lw.decide()
+	}
+	if lw.hold {
+		lw.hold = false
+		lw.w.WriteHeader(lw.code)
+		lw.w.Write(lw.buf.Bytes())
+		lw.buf.Reset()
+	}
+	http.NewResponseController(lw.w).Flush()
+}
+
+func (lw *localizeWriter) Unwrap() http.ResponseWriter { return lw.w }
+
+// decide holds the response if it's JSON, otherwise sends the header
+func (lw *localizeWriter) decide() {
+	lw.decided = true
+	if lw.code == 0 {
+		lw.code = http.StatusOK
+	}
+	mediaType, _, _ := mime.ParseMediaType(lw.w.Header().Get("Content-Type"))
+	lw.hold = mediaType == "application/json"
+	if !lw.hold {
+		lw.w.WriteHeader(lw.code)
+	}
+}
+
+// Close sends a held response with its labels added
+func (lw *localizeWriter) Close() {
+	if !lw.decided {
+		if lw.code != 0 {
+			lw.w.WriteHeader(lw.code)
+		}
+		return
+	}
+	if !lw.hold {
+		return
+	}
+
+	body := lw.buf.Bytes()
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err == nil && addLabels(v, lw.lang) {
+		var out bytes.Buffer
+		if err := json.NewEncoder(&out).Encode(v); err == nil {
+			body = out.Bytes()
+			lw.w.Header().Set("Content-Language", lw.lang)
+		}
+	}
+	if lw.w.Header().Get("Content-Length") != "" {
+		lw.w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	}
+	lw.w.WriteHeader(lw.code)
+	lw.w.Write(body)
+}
+
+// addLabels adds a display label next to every enum value in v, reporting
+// whether it added any
+func addLabels(v any, lang string) bool {
+	added := false
+	switch t := v.(type) {
+	case map[string]any:
+		labels := make(map[string]string)
+		for k, x := range t {
+			if s, ok := x.(string); ok {
+				if label, ok := i18n.Label(lang, k, s); ok {
+					labels[k+displaySuffix] = label
+				}
+			} else if addLabels(x, lang) {
+				added = true
+			}
+		}
+		for k, label := range labels {
+			t[k] = label
+			added = true
+		}
+	case []any:
+		for _, x := range t {
+			if addLabels(x, lang) {
+				added = true
+			}
+		}
+	}
+	return added
+}
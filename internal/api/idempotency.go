@@ -0,0 +1,212 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== IDEMPOTENCY KEYS ====================
+// POSTs that start expensive work (/ingest, /analyze/trigger, /aggregate)
+// accept an Idempotency-Key header. The first request with a key runs and
+// its response is kept for IDEMPOTENCY_TTL; a retry with the same key and
+// body gets that response back, marked Idempotent-Replayed, without running
+// again. A retry while the first is still running gets 409, and the same key
+// with a different request 422. 5xx responses aren't kept, so a retry after
+// a server error runs again. Keys are shared between instances through
+// MongoDB; without it, or while it's unreachable, each instance keeps its
+// own in memory.
+
+const maxIdempotencyKeyLen = 255
+
+var idempotencyTTL = config.EnvDuration("IDEMPOTENCY_TTL", config.DEFAULT_IDEMPOTENCY_TTL)
+
+// idempotent wraps h so requests carrying an Idempotency-Key run at most once
+// per key. It goes inside withDeadline, whose deadline bounds the claim.
+func idempotent(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		key := req.Header.Get("Idempotency-Key")
+		if key == "" || req.Method != http.MethodPost {
+			h(w, req)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLen {
+			jsonError(w, "Idempotency-Key is longer than 255 characters", http.StatusBadRequest)
+			return
+		}
+
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			jsonError(w, "Failed to read request body", http.StatusBadRequest)
+			return
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+
+		sum := sha256.New()
+		io.WriteString(sum, req.Method+" "+req.URL.Path+"?"+req.URL.RawQuery+"\n")
+		sum.Write(body)
+		fingerprint := hex.EncodeToString(sum.Sum(nil))
+		id := req.URL.Path + " " + key
+
+		// Held until the handler's deadline, so a crashed instance's claim frees up
+		hold := idempotencyTTL
+		if deadline, ok := req.Context().Deadline(); ok {
+			hold = time.Until(deadline) + time.Minute
+		}
+		rec, claimed, shared := idempotencyKeys.claim(req.Context(), id, fingerprint, hold)
+		if !claimed {
+			switch {
+			case rec.Fingerprint != fingerprint:
+				jsonError(w, "Idempotency-Key was already used with a different request", http.StatusUnprocessableEntity)
+			case !rec.Done:
+				jsonError(w, "A request with this Idempotency-Key is still in progress", http.StatusConflict)
+			default:
+				if rec.ContentType != "" {
+					w.Header().Set("Content-Type", rec.ContentType)
+				}
+				w.Header().Set("Idempotent-Replayed", "true")
+				w.WriteHeader(rec.StatusCode)
+				w.Write(rec.Body)
+			}
+			return
+		}
+
+		rw := &recordingWriter{ResponseWriter: w}
+		kept := false
+		defer func() {
+			// Server errors and panics leave the key free for a retry
+			if !kept {
+				idempotencyKeys.release(req.Context(), id, fingerprint, shared)
+			}
+		}()
+		h(rw, req)
+
+		if rw.code == 0 {
+			rw.code = http.StatusOK
+		}
+		if rw.code >= 500 {
+			return
+		}
+		kept = true
+		rec.Done = true
+		rec.StatusCode = rw.code
+		rec.ContentType = w.Header().Get("Content-Type")
+		rec.Body = rw.body.Bytes()
+		idempotencyKeys.save(req.Context(), rec, shared)
+	}
+}
+
+// recordingWriter passes a response through and keeps a copy of it
+type recordingWriter struct {
+	http.ResponseWriter
+	code int
+	body bytes.Buffer
+}
+
+func (rw *recordingWriter) WriteHeader(code int) {
+	if rw.code == 0 {
+		rw.code = code
+	}
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+func (rw *recordingWriter) Write(p []byte) (int, error) {
+	if rw.code == 0 {
+		rw.code = http.StatusOK
+	}
+	rw.body.Write(p) // Kept even past the deadline, when the client got a 504
+	return rw.ResponseWriter.Write(p)
+}
+
+// idempotencyStore claims keys in MongoDB, falling back to memory
+type idempotencyStore struct {
+	mu       sync.Mutex
+	local    map[string]*storage.IdempotencyRecord
+	degraded bool // Last shared claim failed, so the local keys are in use
+}
+
+var idempotencyKeys = &idempotencyStore{local: make(map[string]*storage.IdempotencyRecord)}
+
+// claim returns the key's record, whether this request claimed it and
+// whether it's in MongoDB rather than memory
+func (s *idempotencyStore) claim(ctx context.Context, id, fingerprint string, hold time.Duration) (rec *storage.IdempotencyRecord, claimed, shared bool) {
+	if storage.IsMongoEnabled() {
+		rec, ok, err := storage.ClaimIdempotencyKey(ctx, id, fingerprint, hold)
+		s.setDegraded(err)
+		if err == nil {
+			return rec, ok, true
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for k, r := range s.local {
+		if now.After(r.ExpiresAt) {
+			delete(s.local, k)
+		}
+	}
+	if r := s.local[id]; r != nil {
+		existing := *r
+		return &existing, false, false
+	}
+	s.local[id] = &storage.IdempotencyRecord{ID: id, Fingerprint: fingerprint, CreatedAt: now, ExpiresAt: now.Add(hold)}
+	claimedRec := *s.local[id]
+	return &claimedRec, true, false
+}
+
+// save keeps the response to a claimed key for IDEMPOTENCY_TTL
+func (s *idempotencyStore) save(ctx context.Context, rec *storage.IdempotencyRecord, shared bool) {
+	if shared {
+		if err := storage.SaveIdempotentResponse(ctx, rec, idempotencyTTL); err != nil {
+			log.Printf("⚠️ Failed to save response for idempotency key %q: %v", rec.ID, err)
+		}
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r := s.local[rec.ID]; r != nil && r.Fingerprint == rec.Fingerprint {
+		saved := *rec
+		saved.ExpiresAt = time.Now().Add(idempotencyTTL)
+		s.local[rec.ID] = &saved
+	}
+}
+
+// release frees a claim whose request failed
+func (s *idempotencyStore) release(ctx context.Context, id, fingerprint string, shared bool) {
+	if shared {
+		if err := storage.ReleaseIdempotencyKey(ctx, id, fingerprint); err != nil {
+			log.Printf("⚠️ Failed to release idempotency key %q: %v", id, err)
+		}
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r := s.local[id]; r != nil && r.Fingerprint == fingerprint && !r.Done {
+		delete(s.local, id)
+	}
+}
+
+// setDegraded switches between the shared and the local keys, logging the change
+func (s *idempotencyStore) setDegraded(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case err != nil && !s.degraded:
+		log.Printf("⚠️ Idempotency keys unavailable, keeping them in memory: %v", err)
+	case err == nil && s.degraded:
+		log.Println("✅ Idempotency keys shared through MongoDB again")
+	}
+	s.degraded = err != nil
+}
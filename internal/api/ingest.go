@@ -0,0 +1,237 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/service"
+)
+
+// ==================== INGESTION ====================
+
+// POST /ingest - Ingest a new call transcript
+func (r *Router) handleIngest(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var body client.IngestRequest
+
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		jsonError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Support both field names
+	transcript := body.Transcript
+	if transcript == "" {
+		transcript = body.CallText
+	}
+	sellerID := body.SellerID
+	if sellerID == "" {
+		sellerID = body.GluserID
+	}
+
+	if transcript == "" {
+		jsonError(w, "transcript_text or call_text is required", http.StatusBadRequest)
+		return
+	}
+	channel, ok := service.NormalizeContactChannel(body.Channel)
+	if !ok {
+		jsonError(w, "channel must be voice, chat or whatsapp", http.StatusBadRequest)
+		return
+	}
+
+	rt := client.RawTranscript{
+		CallID:       body.CallID,
+		SellerID:     sellerID,
+		AgentID:      body.AgentID,
+		Transcript:   transcript,
+		Language:     body.Language,
+		DurationMS:   body.DurationMS,
+		CustomerType: body.CustomerType,
+		Vintage:      body.Vintage,
+		Channel:      channel,
+		Timestamp:    time.Now(),
+	}
+
+	// Async: answer with a job now, analyze in the background
+	if body.Analyze && (body.Async || body.CallbackURL != "") {
+		response, err := r.service.StartIngestJob(req.Context(), rt, body.CallbackURL, body.CallbackSecret, r.watcher.CountAnalysis)
+		switch {
+		case errors.Is(err, service.ErrInvalidCallback):
+			jsonError(w, err.Error(), http.StatusBadRequest)
+			return
+		case err != nil:
+			serverError(w, err)
+			return
+		}
+		countAnalyses(req, 1)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		jsonResponse(w, response)
+		return
+	}
+
+	response, err := r.service.IngestTranscript(req.Context(), rt, body.Analyze)
+	if err != nil {
+		serverError(w, err)
+		return
+	}
+	if response.Analyzed {
+		countAnalyses(req, 1)
+		r.watcher.CountAnalysis(response.Analysis)
+	}
+
+	jsonResponse(w, response)
+}
+
+// GET /ingest/jobs/{id} - An async ingestion's job, with the analysis once completed
+func (r *Router) handleIngestJob(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	job, err := r.service.GetIngestJob(req.Context(), req.PathValue("id"))
+	switch {
+	case errors.Is(err, service.ErrIngestJobNotFound):
+		jsonError(w, err.Error(), http.StatusNotFound)
+		return
+	case err != nil:
+		serverError(w, err)
+		return
+	}
+
+	if job.Analysis != nil && !transcriptsAllowed(req, job.CallID) {
+		job.Analysis = withoutTranscript(job.Analysis)
+	}
+	jsonResponse(w, job)
+}
+
+// ==================== ANALYSIS ====================
+
+// POST /analyze - Analyze a transcript directly (without storing); with
+// "structured": true, the AnalysisResult ingestion would produce
+func (r *Router) handleAnalyze(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var body client.AnalyzeRequest
+
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		jsonError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if body.Structured {
+		if strings.TrimSpace(body.Transcript) == "" {
+			jsonError(w, "transcript is required", http.StatusBadRequest)
+			return
+		}
+		channel, ok := service.NormalizeContactChannel(body.Channel)
+		if !ok {
+			jsonError(w, "channel must be voice, chat or whatsapp", http.StatusBadRequest)
+			return
+		}
+		sellerID := body.SellerID
+		if sellerID == "" {
+			sellerID = body.GluserID
+		}
+		rt := client.RawTranscript{
+			CallID:       body.CallID,
+			SellerID:     sellerID,
+			AgentID:      body.AgentID,
+			Transcript:   body.Transcript,
+			Language:     body.Language,
+			DurationMS:   body.DurationMS,
+			CustomerType: body.CustomerType,
+			Vintage:      body.Vintage,
+			Channel:      channel,
+			Timestamp:    time.Now(),
+		}
+		analysis, err := r.service.PreviewAnalysis(req.Context(), rt)
+		if err != nil {
+			serverError(w, err)
+			return
+		}
+		countAnalyses(req, 1)
+		jsonResponse(w, client.AnalyzeResponse{CallID: analysis.CallID, Analysis: analysis})
+		return
+	}
+
+	result, err := r.service.AnalyzeTranscript(req.Context(), body.Transcript)
+	if err != nil {
+		serverError(w, err)
+		return
+	}
+	countAnalyses(req, 1)
+
+	jsonResponse(w, map[string]any{
+		"analysis": result,
+	})
+}
+
+// POST /analyze/trigger - Trigger analysis of all unprocessed transcripts
+func (r *Router) handleTriggerAnalysis(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	processed, errors := r.service.ProcessAllUnprocessed(req.Context())
+	countAnalyses(req, len(processed))
+	for _, analysis := range processed {
+		r.watcher.CountAnalysis(analysis)
+	}
+
+	errMsgs := make([]string, len(errors))
+	for i, e := range errors {
+		errMsgs[i] = e.Error()
+	}
+
+	jsonResponse(w, map[string]any{
+		"processed": len(processed),
+		"errors":    errMsgs,
+	})
+}
+
+// GET /analyze/provisional - Calls analyzed provisionally while Gemini was
+// unavailable, waiting for their full analysis
+func (r *Router) handleProvisionalQueue(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	queue, err := r.service.ProvisionalQueue(req.Context())
+	if err != nil {
+		serverError(w, err)
+		return
+	}
+
+	jsonResponse(w, queue)
+}
+
+// POST /analyze/provisional/upgrade - Replace provisional analyses with full ones now
+func (r *Router) handleUpgradeProvisional(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	result, err := r.service.UpgradeProvisional(req.Context())
+	if err != nil {
+		serverError(w, err)
+		return
+	}
+
+	jsonResponse(w, result)
+}
@@ -0,0 +1,459 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/profile"
+	"im-ai-voice/internal/service"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== ISSUES ====================
+
+// GET /issues?bucket=&status=&severity=&gluser_id=&cursor=&limit= - Tracked issues across sellers (newest first)
+func (r *Router) handleIssues(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := req.URL.Query()
+	query := storage.IssueQuery{
+		GluserID: q.Get("gluser_id"),
+		Bucket:   q.Get("bucket"),
+		Status:   q.Get("status"),
+		Severity: q.Get("severity"),
+		Cursor:   q.Get("cursor"),
+	}
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			jsonError(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		query.Limit = limit
+	}
+
+	page, err := storage.QueryIssues(req.Context(), query)
+	if err != nil {
+		serverError(w, err)
+		return
+	}
+
+	jsonResponse(w, page)
+}
+
+// GET /commitments?gluser_id=&agent_id=&status=&limit= - Agent promises, newest first, with broken counts per agent and seller
+func (r *Router) handleCommitments(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := req.URL.Query()
+	query := storage.CommitmentQuery{
+		GluserID: q.Get("gluser_id"),
+		AgentID:  q.Get("agent_id"),
+		Status:   q.Get("status"),
+	}
+	switch query.Status {
+	case "", client.CommitmentOpen, client.CommitmentKept, client.CommitmentBroken:
+	default:
+		jsonError(w, "Invalid status (want open, kept or broken)", http.StatusBadRequest)
+		return
+	}
+	limit := 0
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			jsonError(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	report, err := r.service.ListCommitments(req.Context(), query, limit)
+	if err != nil {
+		serverError(w, err)
+		return
+	}
+	jsonResponse(w, report)
+}
+
+// GET /upsell-pitches?gluser_id=&agent_id=&status=&limit= - Agents' upsell pitches, newest first, with conversions per agent, seller response and product
+func (r *Router) handleUpsellPitches(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := req.URL.Query()
+	query := storage.PitchQuery{
+		GluserID: q.Get("gluser_id"),
+		AgentID:  q.Get("agent_id"),
+		Status:   q.Get("status"),
+	}
+	switch query.Status {
+	case "", client.PitchOpen, client.PitchConverted, client.PitchLapsed:
+	default:
+		jsonError(w, "Invalid status (want open, converted or lapsed)", http.StatusBadRequest)
+		return
+	}
+	limit := 0
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			jsonError(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	report, err := r.service.ListPitches(req.Context(), query, limit)
+	if err != nil {
+		serverError(w, err)
+		return
+	}
+	jsonResponse(w, report)
+}
+
+// GET /quotes?bucket=&sentiment=&gluser_id=&from=&to=&limit= - Redacted seller quotes, one per seller and bucket, most severe and newest first
+func (r *Router) handleQuotes(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := req.URL.Query()
+	from, to, ok := dateRange(w, q, 90)
+	if !ok {
+		return
+	}
+	if to.Before(from) {
+		jsonError(w, "to date is before from date", http.StatusBadRequest)
+		return
+	}
+	query := storage.QuoteQuery{
+		Bucket:    q.Get("bucket"),
+		Sentiment: q.Get("sentiment"),
+		GluserID:  q.Get("gluser_id"),
+		From:      from.Format(config.DateLayout),
+		To:        to.Format(config.DateLayout),
+	}
+	limit := 0
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			jsonError(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	lib, err := r.service.ListQuotes(req.Context(), query, limit)
+	if err != nil {
+		serverError(w, err)
+		return
+	}
+	jsonResponse(w, lib)
+}
+
+// POST /quotes/opt-outs - Record that a seller withdrew consent to be quoted, deleting their quotes
+func (r *Router) handleQuoteOptOuts(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var body client.QuoteOptOutRequest
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		jsonError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if body.By == "" {
+		if key := lookupAPIKey(req); key != nil {
+			body.By = key.name
+		}
+	}
+	optOut, err := r.service.OptOutOfQuotes(req.Context(), body)
+	switch {
+	case errors.Is(err, service.ErrInvalidQuoteOptOut):
+		jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		serverError(w, err)
+		return
+	}
+	jsonResponse(w, optOut)
+}
+
+// GET /samples?bucket=&sentiment=&status=&limit= - Approved anonymized example calls, newest first; other statuses need the transcripts scope
+// POST /samples - Generate a pending sample from a call (transcripts scope)
+func (r *Router) handleSamples(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+		q := req.URL.Query()
+		query := storage.SampleQuery{
+			Status:    q.Get("status"),
+			Bucket:    q.Get("bucket"),
+			Sentiment: q.Get("sentiment"),
+		}
+		limit := 0
+		if v := q.Get("limit"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n <= 0 {
+				jsonError(w, "Invalid limit", http.StatusBadRequest)
+				return
+			}
+			limit = n
+		}
+		list := func(w http.ResponseWriter, req *http.Request) {
+			samples, err := r.service.ListSamples(req.Context(), query, limit)
+			switch {
+			case errors.Is(err, service.ErrInvalidSample):
+				jsonError(w, err.Error(), http.StatusBadRequest)
+				return
+			case err != nil:
+				serverError(w, err)
+				return
+			}
+			jsonResponse(w, samples)
+		}
+		if query.Status == "" || query.Status == client.SampleApproved {
+			list(w, req)
+			return
+		}
+		// Unapproved samples may still hold what redaction missed, and name their call
+		requireScope(scopeTranscripts, client.AuditSamplesUnapproved, "samples", func(w http.ResponseWriter, req *http.Request) {
+			if err := auditAllowed(req, client.AuditSamplesUnapproved, "samples"); err != nil {
+				log.Printf("⚠️ Refusing unapproved samples, audit log unavailable: %v", err)
+				jsonError(w, "Audit log unavailable", http.StatusServiceUnavailable)
+				return
+			}
+			list(w, req)
+		})(w, req)
+
+	case http.MethodPost:
+		var body client.SampleRequest
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			jsonError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		requireScope(scopeTranscripts, client.AuditSampleCreate, body.CallID, func(w http.ResponseWriter, req *http.Request) {
+			if body.By == "" {
+				body.By = actorFromContext(req.Context())
+			}
+			if err := auditAllowed(req, client.AuditSampleCreate, body.CallID); err != nil {
+				log.Printf("⚠️ Refusing sample of %s, audit log unavailable: %v", body.CallID, err)
+				jsonError(w, "Audit log unavailable", http.StatusServiceUnavailable)
+				return
+			}
+			sample, err := r.service.CreateSample(req.Context(), body)
+			switch {
+			case errors.Is(err, service.ErrInvalidSample):
+				jsonError(w, err.Error(), http.StatusBadRequest)
+				return
+			case errors.Is(err, service.ErrSampleExists):
+				jsonError(w, err.Error(), http.StatusConflict)
+				return
+			case err != nil:
+				serverError(w, err)
+				return
+			}
+			jsonResponse(w, sample)
+		})(w, req)
+
+	default:
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// PATCH /samples/{id} - Approve or reject a sample, or put it back to pending (transcripts scope)
+// DELETE /samples/{id} - Delete a sample (transcripts scope)
+func (r *Router) handleSample(w http.ResponseWriter, req *http.Request) {
+	id := req.PathValue("id")
+	switch req.Method {
+	case http.MethodPatch:
+		var body client.SampleReview
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			jsonError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		requireScope(scopeTranscripts, client.AuditSampleReview, id, func(w http.ResponseWriter, req *http.Request) {
+			if body.By == "" {
+				body.By = actorFromContext(req.Context())
+			}
+			if err := auditChange(req, client.AuditSampleReview, id, "status="+body.Status); err != nil {
+				log.Printf("⚠️ Refusing review of sample %s, audit log unavailable: %v", id, err)
+				jsonError(w, "Audit log unavailable", http.StatusServiceUnavailable)
+				return
+			}
+			sample, err := r.service.ReviewSample(req.Context(), id, body)
+			switch {
+			case errors.Is(err, service.ErrInvalidSample):
+				jsonError(w, err.Error(), http.StatusBadRequest)
+				return
+			case errors.Is(err, service.ErrSampleNotFound):
+				jsonError(w, "Sample not found", http.StatusNotFound)
+				return
+			case err != nil:
+				serverError(w, err)
+				return
+			}
+			jsonResponse(w, sample)
+		})(w, req)
+
+	case http.MethodDelete:
+		requireScope(scopeTranscripts, client.AuditSampleReview, id, func(w http.ResponseWriter, req *http.Request) {
+			if err := auditChange(req, client.AuditSampleReview, id, "deleted"); err != nil {
+				log.Printf("⚠️ Refusing deletion of sample %s, audit log unavailable: %v", id, err)
+				jsonError(w, "Audit log unavailable", http.StatusServiceUnavailable)
+				return
+			}
+			err := r.service.DeleteSample(req.Context(), id)
+			switch {
+			case errors.Is(err, service.ErrSampleNotFound):
+				jsonError(w, "Sample not found", http.StatusNotFound)
+				return
+			case err != nil:
+				serverError(w, err)
+				return
+			}
+			jsonResponse(w, map[string]any{
+				"deleted": id,
+			})
+		})(w, req)
+
+	default:
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// GET /systemic-issues?bucket=&min_sellers= - Problems reported across sellers, most sellers first
+func (r *Router) handleSystemicIssues(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := req.URL.Query()
+	minSellers := 0
+	if v := q.Get("min_sellers"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			jsonError(w, "Invalid min_sellers", http.StatusBadRequest)
+			return
+		}
+		minSellers = n
+	}
+
+	issues, err := r.service.GetSystemicIssues(req.Context(), q.Get("bucket"), minSellers)
+	if err != nil {
+		serverError(w, err)
+		return
+	}
+
+	jsonResponse(w, map[string]any{
+		"systemic_issues": issues,
+		"count":           len(issues),
+	})
+}
+
+// POST /systemic-issues/trigger - Re-cluster open issues now instead of waiting for the nightly run
+func (r *Router) handleTriggerSystemicIssues(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	issues, err := r.service.RunSystemicClustering(req.Context())
+	if err != nil {
+		serverError(w, err)
+		return
+	}
+
+	jsonResponse(w, map[string]any{
+		"systemic_issues": issues,
+		"count":           len(issues),
+	})
+}
+
+// ==================== ATTENTION ====================
+
+// GET /attention?state=open|acknowledged|snoozed - Flagged sellers, most urgent first
+func (r *Router) handleAttention(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	state := req.URL.Query().Get("state")
+	switch state {
+	case "", client.AttentionOpen, client.AttentionAcknowledged, client.AttentionSnoozed:
+	default:
+		jsonError(w, "state must be open, acknowledged or snoozed", http.StatusBadRequest)
+		return
+	}
+
+	queue, err := profile.BuildAttentionQueue(req.Context(), state)
+	if err != nil {
+		serverError(w, err)
+		return
+	}
+
+	jsonResponse(w, queue)
+}
+
+// POST /attention/{gluser_id}/acknowledge|snooze - Silence a seller's attention alerts until its reason changes
+func (r *Router) handleAttentionAck(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var body client.AttentionAckRequest
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil && err != io.EOF {
+		jsonError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if body.By == "" {
+		if key := lookupAPIKey(req); key != nil {
+			body.By = key.name
+		}
+	}
+
+	var ack *client.AttentionAck
+	var err error
+	switch req.PathValue("action") {
+	case "acknowledge":
+		ack, err = r.service.AcknowledgeAttention(req.Context(), req.PathValue("id"), body)
+	case "snooze":
+		ack, err = r.service.SnoozeAttention(req.Context(), req.PathValue("id"), body)
+	default:
+		http.NotFound(w, req)
+		return
+	}
+	switch {
+	case errors.Is(err, service.ErrSellerNotFound):
+		jsonError(w, err.Error(), http.StatusNotFound)
+		return
+	case errors.Is(err, service.ErrNotFlagged):
+		jsonError(w, err.Error(), http.StatusConflict)
+		return
+	case errors.Is(err, service.ErrSnoozeInPast):
+		jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		serverError(w, err)
+		return
+	}
+
+	jsonResponse(w, ack)
+}
@@ -0,0 +1,192 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/ulid"
+)
+
+// ==================== REQUEST IDS ====================
+// Every response carries an X-Request-ID: the caller's, if it sent a usable
+// one, or a new ULID. Error responses and server-side error logs repeat it,
+// so a failure a client reports can be found in the logs.
+
+const requestIDHeader = "X-Request-ID"
+
+// WithRequestID wraps h so every request has an ID
+func WithRequestID(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		id := req.Header.Get(requestIDHeader)
+		if !validRequestID(id) {
+			id = ulid.New()
+		}
+		w.Header().Set(requestIDHeader, id)
+		h.ServeHTTP(w, req)
+	})
+}
+
+// validRequestID accepts up to 128 printable ASCII characters, so a caller's
+// ID can't forge log lines
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// ==================== REQUEST DEADLINES ====================
+// Every API handler runs under a deadline picked by its endpoint class. The
+// deadline is set on the request context, so LLM and MongoDB calls made by
+// the handler are cancelled with it, and the client gets a 504 JSON error
+// as soon as it passes instead of holding the connection open. Streaming
+// handlers flush to send what they wrote so far; past the first flush the
+// response goes straight to the client, and the deadline ends the stream.
+
+// endpointClass selects the deadline for a route
+type endpointClass int
+
+const (
+	classShort endpointClass = iota // GETs and quick writes
+	classLong                       // Waits on Gemini or slow storage (S3)
+	classBatch                      // Bulk jobs (analyze all, aggregation, archive)
+)
+
+var classTimeouts = map[endpointClass]time.Duration{
+	classShort: config.EnvDuration("REQUEST_TIMEOUT_SHORT", config.DEFAULT_REQUEST_TIMEOUT_SHORT),
+	classLong:  config.EnvDuration("REQUEST_TIMEOUT_LONG", config.DEFAULT_REQUEST_TIMEOUT_LONG),
+	classBatch: config.EnvDuration("REQUEST_TIMEOUT_BATCH", config.DEFAULT_REQUEST_TIMEOUT_BATCH),
+}
+
+// withDeadline wraps h so it runs under the class deadline
+func withDeadline(class endpointClass, h http.HandlerFunc) http.HandlerFunc {
+	timeout := classTimeouts[class]
+
+	return func(w http.ResponseWriter, req *http.Request) {
+		timeout := timeout
+		if class == classShort && wantsNDJSON(req) {
+			// A streamed listing grows with the data, not the page size
+			timeout = classTimeouts[classLong]
+		}
+		ctx, cancel := context.WithTimeout(req.Context(), timeout)
+		defer cancel()
+
+		tw := &timeoutWriter{w: w, header: w.Header().Clone()}
+		done := make(chan struct{})
+		panicked := make(chan any, 1)
+
+		go func() {
+			defer func() {
+				if p := recover(); p != nil {
+					panicked <- p
+				}
+			}()
+			h(tw, req.WithContext(ctx))
+			close(done)
+		}()
+
+		select {
+		case p := <-panicked:
+			panic(p)
+		case <-done:
+			tw.mu.Lock()
+			defer tw.mu.Unlock()
+			if tw.streaming {
+				return
+			}
+			dst := w.Header()
+			for k, v := range tw.header {
+				dst[k] = v
+			}
+			if tw.code == 0 {
+				tw.code = http.StatusOK
+			}
+			w.WriteHeader(tw.code)
+			w.Write(tw.buf.Bytes())
+		case <-ctx.Done():
+			tw.mu.Lock()
+			defer tw.mu.Unlock()
+			tw.timedOut = true
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				log.Printf("⏱️ %s %s [%s] timed out after %v", req.Method, req.URL.Path, w.Header().Get(requestIDHeader), timeout)
+				if !tw.streaming {
+					jsonError(w, fmt.Sprintf("request timed out after %v", timeout), http.StatusGatewayTimeout)
+				}
+			}
+		}
+	}
+}
+
+// timeoutWriter buffers a handler's response until it finishes in time, or
+// until it flushes; writes after the deadline fail with http.ErrHandlerTimeout
+type timeoutWriter struct {
+	mu        sync.Mutex
+	w         http.ResponseWriter
+	header    http.Header
+	buf       bytes.Buffer
+	code      int
+	timedOut  bool
+	streaming bool // Flushed: writes go to w
+}
+
+func (tw *timeoutWriter) Header() http.Header { return tw.header }
+
+func (tw *timeoutWriter) Write(p []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if tw.code == 0 {
+		tw.code = http.StatusOK
+	}
+	if tw.streaming {
+		return tw.w.Write(p)
+	}
+	return tw.buf.Write(p)
+}
+
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut || tw.code != 0 {
+		return
+	}
+	tw.code = code
+}
+
+// Flush sends the header and everything written so far to the client, and
+// streams later writes
+func (tw *timeoutWriter) Flush() {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return
+	}
+	if !tw.streaming {
+		dst := tw.w.Header()
+		for k, v := range tw.header {
+			dst[k] = v
+		}
+		if tw.code == 0 {
+			tw.code = http.StatusOK
+		}
+		tw.w.WriteHeader(tw.code)
+		tw.w.Write(tw.buf.Bytes())
+		tw.buf.Reset()
+		tw.streaming = true
+	}
+	http.NewResponseController(tw.w).Flush()
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"im-ai-voice/internal/lifecycle"
+)
+
+// ==================== PROBES ====================
+// Registered before the rest of the routes, while dependencies are still
+// coming up. On Kubernetes, point the liveness probe at /health, the
+// readiness probe at /ready, and run POST /admin/drain from a preStop hook.
+
+// RegisterProbes registers the health, readiness and drain endpoints
+func RegisterProbes() {
+	http.HandleFunc("/health", withDeadline(classShort, handleHealth))
+	http.HandleFunc("/ready", withDeadline(classShort, handleReady))
+	http.HandleFunc("/admin/drain", withDeadline(classLong, handleDrain)) // Waits DRAIN_DELAY
+}
+
+func handleHealth(w http.ResponseWriter, req *http.Request) {
+	jsonResponse(w, map[string]any{
+		"status":    "healthy",
+		"timestamp": time.Now().Format(time.RFC3339),
+	})
+}
+
+// handleReady answers 503 until every startup check has passed, and again
+// once the instance is draining
+func handleReady(w http.ResponseWriter, req *http.Request) {
+	status := lifecycle.Status()
+	if !status.Ready {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	jsonResponse(w, status)
+}
+
+// handleDrain marks the instance unready and returns once load balancers have
+// had DRAIN_DELAY to stop routing to it
+func handleDrain(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	lifecycle.Drain()
+	jsonResponse(w, lifecycle.Status())
+}
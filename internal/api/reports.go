@@ -0,0 +1,318 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/archive"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/recording"
+	"im-ai-voice/internal/report"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== EVENTS ====================
+
+// GET /events?type=&gluser_id=&call_id=&since=&cursor=&limit= - Paginated activity stream (oldest first)
+func (r *Router) handleEvents(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := req.URL.Query()
+	query := storage.EventQuery{
+		Type:     q.Get("type"),
+		GluserID: q.Get("gluser_id"),
+		CallID:   q.Get("call_id"),
+		Cursor:   q.Get("cursor"),
+	}
+
+	if v := q.Get("since"); v != "" {
+		since, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			if since, err = config.ParseBusinessDate(v); err != nil {
+				jsonError(w, "Invalid since (use RFC3339 or YYYY-MM-DD)", http.StatusBadRequest)
+				return
+			}
+		}
+		query.Since = since
+	}
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			jsonError(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		query.Limit = limit
+	}
+
+	page, err := storage.QueryEvents(req.Context(), query)
+	if err != nil {
+		serverError(w, err)
+		return
+	}
+
+	jsonResponse(w, page)
+}
+
+// ==================== DIGEST ====================
+
+// GET /digest?date=YYYY-MM-DD&format=html|pdf - Render the daily digest (defaults to yesterday)
+func (r *Router) handleDigest(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	date := req.URL.Query().Get("date")
+	if date == "" {
+		date = config.BusinessDate(time.Now().AddDate(0, 0, -1))
+	}
+
+	digest, err := r.service.BuildDailyDigest(req.Context(), date)
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	switch req.URL.Query().Get("format") {
+	case "pdf":
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=\"digest_%s.pdf\"", date))
+		w.Write(report.RenderDigestPDF(digest))
+	case "", "html":
+		body, err := report.RenderDigestHTML(digest)
+		if err != nil {
+			serverError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(body)
+	default:
+		jsonError(w, "Invalid format (use pdf or html)", http.StatusBadRequest)
+	}
+}
+
+// POST /digest/send - Regenerate the digest for a date and email it
+func (r *Router) handleSendDigest(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var body struct {
+		Date string `json:"date"` // Optional, defaults to yesterday
+	}
+	json.NewDecoder(req.Body).Decode(&body)
+
+	date := body.Date
+	if date == "" {
+		date = config.BusinessDate(time.Now().AddDate(0, 0, -1))
+	}
+
+	digest, recipients, err := r.service.SendDailyDigest(req.Context(), date)
+	if err != nil {
+		serverError(w, err)
+		return
+	}
+
+	jsonResponse(w, map[string]any{
+		"status":     "sent",
+		"date":       date,
+		"recipients": recipients,
+		"tickets":    len(digest.NewTickets),
+		"at_risk":    digest.AtRiskCount,
+	})
+}
+
+// ==================== WEEKLY REPORT ====================
+
+// GET /reports/weekly?date=YYYY-MM-DD&format=html|pdf|json - Render the executive report of the week ending on date (defaults to yesterday)
+func (r *Router) handleWeeklyReport(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	date := req.URL.Query().Get("date")
+	if date == "" {
+		date = config.BusinessDate(time.Now().AddDate(0, 0, -1))
+	}
+	format := req.URL.Query().Get("format")
+	if format != "" && format != "html" && format != "pdf" && format != "json" {
+		jsonError(w, "Invalid format (use html, pdf or json)", http.StatusBadRequest)
+		return
+	}
+
+	weekly, err := r.service.BuildWeeklyReport(req.Context(), date)
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	switch format {
+	case "pdf":
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=\"weekly_%s.pdf\"", date))
+		w.Write(report.RenderWeeklyPDF(weekly))
+	case "json":
+		jsonResponse(w, weekly)
+	default:
+		body, err := report.RenderWeeklyHTML(weekly)
+		if err != nil {
+			serverError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(body)
+	}
+}
+
+// POST /reports/weekly/send - Regenerate the weekly report for the week ending on date and email it
+func (r *Router) handleSendWeeklyReport(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var body struct {
+		Date string `json:"date"` // Last day of the week, optional, defaults to yesterday
+	}
+	json.NewDecoder(req.Body).Decode(&body)
+
+	date := body.Date
+	if date == "" {
+		date = config.BusinessDate(time.Now().AddDate(0, 0, -1))
+	}
+
+	weekly, recipients, err := r.service.SendWeeklyReport(req.Context(), date)
+	if err != nil {
+		serverError(w, err)
+		return
+	}
+
+	jsonResponse(w, map[string]any{
+		"status":          "sent",
+		"from":            weekly.From,
+		"to":              weekly.To,
+		"recipients":      recipients,
+		"narrative":       weekly.Narrative != "",
+		"systemic_issues": len(weekly.SystemicIssues),
+	})
+}
+
+// ==================== ARCHIVE ====================
+
+// POST /archive/trigger - Compact old analyses into the Parquet archive
+func (r *Router) handleTriggerArchive(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var body struct {
+		OlderThanDays int `json:"older_than_days"` // Optional, defaults to ARCHIVE_AFTER_DAYS
+	}
+	json.NewDecoder(req.Body).Decode(&body)
+
+	days := body.OlderThanDays
+	if days <= 0 {
+		days = archive.AfterDays()
+	}
+	cutoff := time.Now().AddDate(0, 0, -days)
+
+	result, err := archive.Run(req.Context(), cutoff)
+	if err != nil {
+		serverError(w, err)
+		return
+	}
+
+	jsonResponse(w, result)
+}
+
+// POST /recordings/purge - Delete call audio past the recording retention age
+func (r *Router) handleTriggerRecordingPurge(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var body struct {
+		OlderThanDays int `json:"older_than_days"` // Optional, defaults to RECORDING_RETENTION_DAYS
+	}
+	json.NewDecoder(req.Body).Decode(&body)
+
+	days := body.OlderThanDays
+	if days <= 0 {
+		days = recording.RetentionDays()
+	}
+	cutoff := time.Now().AddDate(0, 0, -days)
+
+	result, err := recording.Purge(req.Context(), cutoff)
+	if err != nil {
+		serverError(w, err)
+		return
+	}
+
+	jsonResponse(w, result)
+}
+
+// GET /archive/sellers/{gluser_id}?from=YYYY-MM-DD&to=YYYY-MM-DD - Historical timeline from the archive
+func (r *Router) handleArchivedTimeline(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	gluserID := strings.TrimPrefix(req.URL.Path, "/archive/sellers/")
+	if gluserID == "" {
+		jsonError(w, "gluser_id is required", http.StatusBadRequest)
+		return
+	}
+
+	var from, to time.Time
+	var err error
+	if v := req.URL.Query().Get("from"); v != "" {
+		if from, err = config.ParseBusinessDate(v); err != nil {
+			jsonError(w, "Invalid from date (use YYYY-MM-DD)", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := req.URL.Query().Get("to"); v != "" {
+		if to, err = config.ParseBusinessDate(v); err != nil {
+			jsonError(w, "Invalid to date (use YYYY-MM-DD)", http.StatusBadRequest)
+			return
+		}
+		to = to.AddDate(0, 0, 1) // inclusive end date
+	}
+
+	analyses, err := archive.QueryAnalyses(req.Context(), gluserID, from, to)
+	if err != nil {
+		serverError(w, err)
+		return
+	}
+
+	timeline := make([]client.CallSummary, 0, len(analyses))
+	for _, a := range analyses {
+		timeline = append(timeline, client.CallSummary{
+			CallID:           a.CallID,
+			Timestamp:        a.Timestamp,
+			Summary:          a.CallSummary,
+			Sentiment:        a.Intent.Sentiment,
+			IssuesRaised:     len(a.Issues),
+			AgentPerformance: a.AgentPerformance,
+		})
+	}
+
+	jsonResponse(w, map[string]any{
+		"gluser_id": gluserID,
+		"timeline":  timeline,
+		"count":     len(timeline),
+	})
+}
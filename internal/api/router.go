@@ -0,0 +1,234 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/profile"
+	"im-ai-voice/internal/scheduler"
+	"im-ai-voice/internal/service"
+	"im-ai-voice/internal/watcher"
+)
+
+type Router struct {
+	service *service.Service
+	watcher *watcher.TranscriptWatcher
+	jobs    *scheduler.Scheduler
+}
+
+func NewRouter(s *service.Service, tw *watcher.TranscriptWatcher, jobs *scheduler.Scheduler) *Router {
+	return &Router{service: s, watcher: tw, jobs: jobs}
+}
+
+func (r *Router) RegisterRoutes() {
+	// Static files (Dashboard UI)
+	fs := http.FileServer(http.Dir(config.STATIC_DIR))
+	http.Handle("/static/", http.StripPrefix("/static/", fs))
+
+	// Root - serve dashboard
+	http.HandleFunc("/", r.handleRoot)
+
+	// Ingestion
+	http.HandleFunc("/ingest", withDeadline(classLong, idempotent(r.handleIngest)))
+	http.HandleFunc("/ingest/jobs/{id}", withDeadline(classShort, r.handleIngestJob))
+
+	// Analysis
+	http.HandleFunc("/analyze", withDeadline(classLong, r.handleAnalyze))
+	http.HandleFunc("/analyze/trigger", withDeadline(classBatch, idempotent(r.handleTriggerAnalysis)))
+	http.HandleFunc("/analyze/provisional", withDeadline(classShort, r.handleProvisionalQueue))
+	http.HandleFunc("/analyze/provisional/upgrade", withDeadline(classBatch, r.handleUpgradeProvisional)) // Analyzes queued calls with Gemini
+
+	// Calls
+	http.HandleFunc("/calls", withDeadline(classShort, r.handleCalls))
+	http.HandleFunc("/calls/", withDeadline(classShort, r.handleCalls))
+	http.HandleFunc("/calls/{id}/recording", withDeadline(classLong, r.handleCallRecording)) // Downloads or reads audio from S3
+	http.HandleFunc("/calls/{id}/annotations", withDeadline(classShort, r.handleCallAnnotations))
+	http.HandleFunc("/calls/{id}/annotations/{annotation_id}", withDeadline(classShort, r.handleCallAnnotation))
+	http.HandleFunc("/calls/{id}/chat", withDeadline(classLong, r.handleCallChat)) // Waits on Gemini for the answer
+	http.HandleFunc("/calls/{id}/chat/{session_id}", withDeadline(classShort, r.handleCallChatSession))
+	http.HandleFunc("/annotations", withDeadline(classShort, r.handleAnnotations))
+
+	// Seller Profiles (Dashboard-ready)
+	http.HandleFunc("/sellers", withDeadline(classShort, r.handleListSellers))
+	http.HandleFunc("/sellers/", withDeadline(classShort, r.handleSellerProfile))
+	http.HandleFunc("/sellers/{id}/diff", withDeadline(classLong, r.handleSellerDiff))   // Waits on Gemini for the narrative
+	http.HandleFunc("/sellers/{id}/brief", withDeadline(classLong, r.handleSellerBrief)) // Waits on Gemini for the brief
+	http.HandleFunc("/sellers/import", withDeadline(classBatch, r.handleSellerImport))
+	http.HandleFunc("/export", withDeadline(classBatch, requireScope(scopeMigrate, client.AuditSellersExport, "*", r.handleExport)))
+	http.HandleFunc("/import/analyses", withDeadline(classBatch, r.handleAnalysesImport))
+
+	// Aggregates
+	http.HandleFunc("/aggregates", withDeadline(classShort, r.handleAggregates))
+	http.HandleFunc("/aggregates/", withDeadline(classShort, r.handleAggregateByDate))
+	http.HandleFunc("/aggregate", withDeadline(classBatch, idempotent(r.handleTriggerAggregation))) // POST to trigger aggregation
+	http.HandleFunc("/aggregates/preview", withDeadline(classBatch, r.handleAggregatePreview))
+	http.HandleFunc("/aggregates/check", withDeadline(classBatch, r.handleCheckAggregates)) // Re-aggregates with recompute
+
+	// Tickets
+	http.HandleFunc("/tickets", withDeadline(classShort, r.handleTickets))
+	http.HandleFunc("/tickets/", withDeadline(classShort, r.handleTicketsByDate))
+	http.HandleFunc("/tickets/{date}/{id}", withDeadline(classLong, r.handleTicketStatus)) // Waits on GitHub when sync is on
+	http.HandleFunc("/tickets/bulk-update", withDeadline(classBatch, r.handleTicketsBulkUpdate))
+
+	// Sellers' actual renewals and cancellations, scored against churn predictions
+	http.HandleFunc("/churn-outcomes", withDeadline(classBatch, r.handleChurnOutcomes))
+
+	// Trash (soft-deleted analyses and tickets)
+	http.HandleFunc("/trash", withDeadline(classShort, r.handleTrash))
+	http.HandleFunc("/trash/{kind}/{id}/restore", withDeadline(classShort, r.handleTrashRestore))
+
+	// Dashboard API
+	http.HandleFunc("/dashboard", withDeadline(classShort, r.handleDashboard))
+
+	// Analytics
+	http.HandleFunc("/analytics/heatmap", withDeadline(classShort, r.handleHeatmap))
+	http.HandleFunc("/analytics/issue-aging", withDeadline(classShort, r.handleIssueAging))
+	http.HandleFunc("/analytics/resolution-durability", withDeadline(classShort, r.handleResolutionDurability))
+	http.HandleFunc("/analytics/churn-reasons", withDeadline(classShort, r.handleChurnReasons))
+	http.HandleFunc("/analytics/churn-accuracy", withDeadline(classLong, r.handleChurnAccuracy)) // Reads the analyses of every seller with an outcome
+	http.HandleFunc("/analytics/cross-check", withDeadline(classShort, r.handleCrossCheck))
+	http.HandleFunc("/analytics/upsell-pipeline", withDeadline(classShort, r.handleUpsellPipeline))
+	http.HandleFunc("/analytics/drivers", withDeadline(classShort, r.handleSatisfactionDrivers))
+	http.HandleFunc("/analytics/onboarding", withDeadline(classShort, r.handleOnboardingFunnel))
+	http.HandleFunc("/analytics/tickets/burndown", withDeadline(classShort, r.handleTicketBurndown))
+	http.HandleFunc("/analytics/ticket-reconciliation", withDeadline(classShort, r.handleTicketReconciliation))
+	http.HandleFunc("/analytics/themes", withDeadline(classShort, r.handleInsightThemes))
+	http.HandleFunc("/analytics/themes/trigger", withDeadline(classBatch, r.handleTriggerInsightThemes)) // Embeds a week of key insights
+	http.HandleFunc("/analytics/severity-calibration", withDeadline(classShort, r.handleSeverityCalibration))
+	http.HandleFunc("/analytics/severity-calibration/trigger", withDeadline(classBatch, r.handleTriggerSeverityCalibration)) // Reads every seller profile
+	http.HandleFunc("/agents/leaderboard", withDeadline(classShort, r.handleAgentLeaderboard))
+	http.HandleFunc("/shadow/report", withDeadline(classShort, r.handleShadowReport))
+	http.HandleFunc("/ask", withDeadline(classLong, r.handleAsk)) // Two Gemini requests around the queries
+
+	// Event log
+	http.HandleFunc("/events", withDeadline(classShort, r.handleEvents))
+
+	// Tracked issues across sellers
+	http.HandleFunc("/issues", withDeadline(classShort, r.handleIssues))
+	http.HandleFunc("/commitments", withDeadline(classShort, r.handleCommitments))
+	http.HandleFunc("/upsell-pitches", withDeadline(classShort, r.handleUpsellPitches))
+	http.HandleFunc("/quotes", withDeadline(classShort, r.handleQuotes))
+	http.HandleFunc("/quotes/opt-outs", withDeadline(classShort, r.handleQuoteOptOuts))
+	http.HandleFunc("/samples", withDeadline(classShort, r.handleSamples))
+	http.HandleFunc("/samples/{id}", withDeadline(classShort, r.handleSample))
+	http.HandleFunc("/systemic-issues", withDeadline(classShort, r.handleSystemicIssues))
+	http.HandleFunc("/systemic-issues/trigger", withDeadline(classBatch, r.handleTriggerSystemicIssues)) // Embeds every open issue
+
+	// Attention queue
+	http.HandleFunc("/attention", withDeadline(classShort, r.handleAttention))
+	http.HandleFunc("/attention/{id}/{action}", withDeadline(classShort, r.handleAttentionAck))
+
+	// Seller portal
+	http.HandleFunc("/portal/sellers/{id}", withDeadline(classShort, r.handlePortalSeller))
+
+	// Daily digest
+	http.HandleFunc("/digest", withDeadline(classLong, r.handleDigest))
+	http.HandleFunc("/digest/send", withDeadline(classLong, r.handleSendDigest))
+
+	// Weekly executive report
+	http.HandleFunc("/reports/weekly", withDeadline(classLong, r.handleWeeklyReport))
+	http.HandleFunc("/reports/weekly/send", withDeadline(classLong, r.handleSendWeeklyReport))
+
+	// Cold archive
+	http.HandleFunc("/archive/trigger", withDeadline(classBatch, r.handleTriggerArchive))
+	http.HandleFunc("/archive/sellers/", withDeadline(classLong, r.handleArchivedTimeline))
+
+	// Recording retention
+	http.HandleFunc("/recordings/purge", withDeadline(classBatch, r.handleTriggerRecordingPurge))
+
+	// Deployment
+	http.HandleFunc("/capabilities", withDeadline(classShort, r.handleCapabilities))
+	http.HandleFunc("/admin/selftest", withDeadline(classBatch, r.handleSelfTest)) // Analyzes with Gemini with ?llm=gemini
+
+	// Admin
+	http.HandleFunc("/admin/watcher", withDeadline(classShort, r.handleWatcherStatus))
+	http.HandleFunc("/admin/pipeline/stats", withDeadline(classShort, r.handlePipelineStats))
+	http.HandleFunc("/admin/data-quality", withDeadline(classShort, r.handleDataQuality))
+	http.HandleFunc("/admin/leader", withDeadline(classShort, r.handleLeaderStatus))
+	http.HandleFunc("/admin/keys/{id}/usage", withDeadline(classShort, requireScope(scopeAdmin, client.AuditKeyUsageRead, "*", r.handleKeyUsage)))
+	http.HandleFunc("/jobs", withDeadline(classShort, r.handleJobs))
+	http.HandleFunc("/jobs/{id}", withDeadline(classShort, r.handleJob))
+	http.HandleFunc("/admin/jobs/schedule", withDeadline(classShort, r.handleJobSchedule))
+	http.HandleFunc("/admin/jobs/{name}/run", withDeadline(classShort, requireScope(scopeAdmin, client.AuditJobRun, "jobs", r.handleRunJob))) // Starts the job, doesn't wait for it
+	http.HandleFunc("/admin/ticket-suppressions", withDeadline(classShort, r.handleTicketSuppressions))
+	http.HandleFunc("/admin/ticket-suppressions/{id}", withDeadline(classShort, r.handleTicketSuppression))
+	http.HandleFunc("/admin/webhooks", withDeadline(classShort, requireScope(scopeAdmin, client.AuditWebhooksAdmin, "webhooks", r.handleWebhooks)))
+	http.HandleFunc("/admin/webhooks/{id}", withDeadline(classShort, requireScope(scopeAdmin, client.AuditWebhooksAdmin, "webhooks", r.handleWebhook)))
+	http.HandleFunc("/admin/alert-subscriptions", withDeadline(classShort, requireScope(scopeAdmin, client.AuditAlertSubsAdmin, "alert-subscriptions", r.handleAlertSubscriptions)))
+	http.HandleFunc("/admin/alert-subscriptions/{id}", withDeadline(classShort, requireScope(scopeAdmin, client.AuditAlertSubsAdmin, "alert-subscriptions", r.handleAlertSubscription)))
+	http.HandleFunc("/admin/bucket-owners", withDeadline(classShort, requireScope(scopeAdmin, client.AuditOwnersAdmin, "bucket-owners", r.handleBucketOwners)))
+	http.HandleFunc("/admin/bucket-owners/{bucket}", withDeadline(classShort, requireScope(scopeAdmin, client.AuditOwnersAdmin, "bucket-owners", r.handleBucketOwner)))
+	http.HandleFunc("/admin/reclassifications", withDeadline(classBatch, r.handleReclassifications)) // A run rewrites every stored analysis
+	http.HandleFunc("/admin/reclassifications/{id}", withDeadline(classShort, r.handleReclassification))
+	http.HandleFunc("/admin/rollouts", withDeadline(classShort, requireScope(scopeAdmin, client.AuditRolloutsAdmin, "rollouts", r.handleRollouts)))
+	http.HandleFunc("/admin/rollouts/{id}", withDeadline(classShort, requireScope(scopeAdmin, client.AuditRolloutsAdmin, "rollouts", r.handleRollout)))
+	http.HandleFunc("/admin/eval/history", withDeadline(classShort, r.handleEvalHistory))
+	http.HandleFunc("/admin/eval/run", withDeadline(classBatch, r.handleEvalRun)) // Analyzes the approved samples with every running version
+	http.HandleFunc("/admin/flags", withDeadline(classShort, requireScope(scopeAdmin, client.AuditFlagsAdmin, "flags", r.handleFeatureFlags)))
+	http.HandleFunc("/admin/flags/{name}", withDeadline(classShort, requireScope(scopeAdmin, client.AuditFlagsAdmin, "flags", r.handleFeatureFlag)))
+	http.HandleFunc("/admin/custom-fields", withDeadline(classShort, r.handleCustomFields))
+	http.HandleFunc("/admin/custom-fields/{entity}/{name}", withDeadline(classShort, r.handleCustomField))
+	http.HandleFunc("/admin/kb", withDeadline(classLong, requireScope(scopeAdmin, client.AuditKBAdmin, "kb_documents", r.handleKBDocuments))) // Uploads embed the document
+	http.HandleFunc("/admin/kb/{id}", withDeadline(classShort, requireScope(scopeAdmin, client.AuditKBAdmin, "kb_documents", r.handleKBDocument)))
+}
+
+// handleRoot serves the dashboard UI
+func (r *Router) handleRoot(w http.ResponseWriter, req *http.Request) {
+	if req.URL.Path != "/" {
+		http.NotFound(w, req)
+		return
+	}
+	http.ServeFile(w, req, filepath.Join(config.STATIC_DIR, "index.html"))
+}
+
+// ==================== HELPERS ====================
+
+func jsonResponse(w http.ResponseWriter, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		log.Printf("Failed to encode response: %v", err)
+	}
+}
+
+// dateRange reads the from/to query dates as UTC midnights, defaulting to the
+// last `days` business days including today. It writes the error response
+// for a bad date.
+func dateRange(w http.ResponseWriter, q url.Values, days int) (from, to time.Time, ok bool) {
+	to = time.Now().In(config.BusinessTZ)
+	from = to.AddDate(0, 0, 1-days)
+	var err error
+	if v := q.Get("from"); v != "" {
+		if from, err = config.ParseBusinessDate(v); err != nil {
+			jsonError(w, "Invalid from date (use YYYY-MM-DD)", http.StatusBadRequest)
+			return from, to, false
+		}
+	}
+	if v := q.Get("to"); v != "" {
+		if to, err = config.ParseBusinessDate(v); err != nil {
+			jsonError(w, "Invalid to date (use YYYY-MM-DD)", http.StatusBadRequest)
+			return from, to, false
+		}
+	}
+	from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	to = time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return from, to, true
+}
+
+// journeyStage reads the stage query parameter segmenting analytics by
+// journey stage, empty for all sellers. It writes the error response for an
+// unknown stage.
+func journeyStage(w http.ResponseWriter, q url.Values) (string, bool) {
+	stage := q.Get("stage")
+	if stage != "" && !profile.ValidJourneyStage(stage) {
+		jsonError(w, "Invalid stage (use "+strings.Join(client.JourneyStages, ", ")+")", http.StatusBadRequest)
+		return "", false
+	}
+	return stage, true
+}
@@ -0,0 +1,678 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"maps"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/profile"
+	"im-ai-voice/internal/report"
+	"im-ai-voice/internal/service"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== SELLER PROFILES ====================
+
+// GET /sellers?q=&customer_type=&city=&vertical=&stage=&churn_risk=&health_label=&needs_attention=&cf.<name>=&sort=&page=&page_size= -
+// Seller summaries, most recently called first unless sorted otherwise
+func (r *Router) handleListSellers(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := req.URL.Query()
+	stage, ok := journeyStage(w, q)
+	if !ok {
+		return
+	}
+	query := storage.SellerQuery{
+		Prefix:       q.Get("q"),
+		CustomerType: q.Get("customer_type"),
+		City:         q.Get("city"),
+		Vertical:     q.Get("vertical"),
+		Stage:        stage,
+		ChurnRisk:    q.Get("churn_risk"),
+		HealthLabel:  q.Get("health_label"),
+		Sort:         q.Get("sort"),
+	}
+	switch query.Sort {
+	case "", client.SellerSortLastCall, client.SellerSortHealth, client.SellerSortOpenIssues:
+	default:
+		jsonError(w, "Invalid sort (use last_call, health or open_issues)", http.StatusBadRequest)
+		return
+	}
+	var err error
+	if v := q.Get("needs_attention"); v != "" {
+		attention, err := strconv.ParseBool(v)
+		if err != nil {
+			jsonError(w, "Invalid needs_attention (use true or false)", http.StatusBadRequest)
+			return
+		}
+		query.NeedsAttention = &attention
+	}
+	if query.CustomFields, ok = r.customFieldFilters(w, req, client.CustomFieldProfile); !ok {
+		return
+	}
+
+	if wantsNDJSON(req) {
+		stream := newNDJSONStream(w)
+		err := storage.EachSellerSummary(req.Context(), query, func(s client.SellerSummary) error {
+			return stream.Send(s)
+		})
+		if err == nil {
+			err = stream.Flush()
+		}
+		if err != nil {
+			stream.Fail(err)
+		}
+		return
+	}
+	if v := q.Get("page"); v != "" {
+		if query.Page, err = strconv.Atoi(v); err != nil || query.Page <= 0 {
+			jsonError(w, "Invalid page", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := q.Get("page_size"); v != "" {
+		if query.PageSize, err = strconv.Atoi(v); err != nil || query.PageSize <= 0 {
+			jsonError(w, "Invalid page_size", http.StatusBadRequest)
+			return
+		}
+	}
+
+	page, err := storage.QuerySellerSummaries(req.Context(), query)
+	if err != nil {
+		serverError(w, err)
+		return
+	}
+
+	jsonResponse(w, page)
+}
+
+// GET /sellers/{gluser_id} - Get full seller profile (dashboard-ready)
+func (r *Router) handleSellerProfile(w http.ResponseWriter, req *http.Request) {
+	// Extract gluser_id (and optional sub-resource) from path
+	path := strings.TrimPrefix(req.URL.Path, "/sellers/")
+	gluserID, sub, _ := strings.Cut(path, "/")
+
+	if req.Method == http.MethodPatch && sub == "" && gluserID != "" {
+		requireScope(scopeProfiles, client.AuditSellerUpdate, gluserID, func(w http.ResponseWriter, req *http.Request) {
+			r.handlePatchSellerProfile(w, req, gluserID)
+		})(w, req)
+		return
+	}
+	if req.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if gluserID == "" {
+		jsonError(w, "gluser_id is required", http.StatusBadRequest)
+		return
+	}
+
+	switch sub {
+	case "":
+	case "report":
+		r.handleSellerReport(w, req, gluserID)
+		return
+	case "trends":
+		r.handleSellerTrends(w, req, gluserID)
+		return
+	case "timeline":
+		r.handleSellerTimeline(w, req, gluserID)
+		return
+	case "risk-explanation":
+		r.handleSellerRiskExplanation(w, req, gluserID)
+		return
+	case "export":
+		requireScope(scopeMigrate, client.AuditSellerExport, gluserID, func(w http.ResponseWriter, req *http.Request) {
+			r.handleSellerExport(w, req, gluserID)
+		})(w, req)
+		return
+	case "data-inventory":
+		requireScope(scopeMigrate, client.AuditSellerData, gluserID, func(w http.ResponseWriter, req *http.Request) {
+			r.handleSellerDataInventory(w, req, gluserID)
+		})(w, req)
+		return
+	default:
+		jsonError(w, "Unknown seller resource: "+sub, http.StatusNotFound)
+		return
+	}
+
+	profile, err := storage.LoadSellerProfile(req.Context(), gluserID)
+	if err != nil {
+		serverError(w, fmt.Errorf("error loading profile: %w", err))
+		return
+	}
+
+	if profile == nil {
+		jsonError(w, "Seller not found", http.StatusNotFound)
+		return
+	}
+
+	// Return full profile - it's already dashboard-ready
+	jsonResponse(w, profile)
+}
+
+// GET /sellers/{gluser_id}/timeline?from=YYYY-MM-DD&to=YYYY-MM-DD - Call history across hot storage and the archive, newest first
+func (r *Router) handleSellerTimeline(w http.ResponseWriter, req *http.Request, gluserID string) {
+	var from, to time.Time
+	var err error
+	if v := req.URL.Query().Get("from"); v != "" {
+		if from, err = config.ParseBusinessDate(v); err != nil {
+			jsonError(w, "Invalid from date (use YYYY-MM-DD)", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := req.URL.Query().Get("to"); v != "" {
+		if to, err = config.ParseBusinessDate(v); err != nil {
+			jsonError(w, "Invalid to date (use YYYY-MM-DD)", http.StatusBadRequest)
+			return
+		}
+		to = to.AddDate(0, 0, 1) // inclusive end date
+	}
+	if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+		jsonError(w, "to date is before from date", http.StatusBadRequest)
+		return
+	}
+
+	tl, err := r.service.SellerTimeline(req.Context(), gluserID, from, to)
+	switch {
+	case errors.Is(err, service.ErrSellerNotFound):
+		jsonError(w, "Seller not found", http.StatusNotFound)
+		return
+	case err != nil:
+		serverError(w, err)
+		return
+	}
+	jsonResponse(w, tl)
+}
+
+// GET /sellers/{gluser_id}/risk-explanation - The health score and churn risk broken down into their factors
+func (r *Router) handleSellerRiskExplanation(w http.ResponseWriter, req *http.Request, gluserID string) {
+	e, err := r.service.ExplainSellerRisk(req.Context(), gluserID)
+	switch {
+	case errors.Is(err, service.ErrSellerNotFound):
+		jsonError(w, "Seller not found", http.StatusNotFound)
+		return
+	case err != nil:
+		serverError(w, err)
+		return
+	}
+	jsonResponse(w, e)
+}
+
+// PATCH /sellers/{gluser_id} - Correct customer_type, city_name or vertical, override churn risk or sentiment, or set custom fields (scope: profiles)
+// The change is audited before it's made; fields left out are kept.
+func (r *Router) handlePatchSellerProfile(w http.ResponseWriter, req *http.Request, gluserID string) {
+	var patch client.SellerProfilePatch
+	dec := json.NewDecoder(req.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&patch); err != nil {
+		jsonError(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := service.ValidateProfilePatch(&patch); err != nil {
+		jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	switch err := r.service.ValidateCustomFields(req.Context(), client.CustomFieldProfile, patch.CustomFields); {
+	case errors.Is(err, service.ErrInvalidCustomField):
+		jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		serverError(w, err)
+		return
+	}
+
+	if err := auditChange(req, client.AuditSellerUpdate, gluserID, describeProfilePatch(patch)); err != nil {
+		log.Printf("⚠️ Refusing update of %s, audit log unavailable: %v", gluserID, err)
+		jsonError(w, "Audit log unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	p, err := r.service.PatchSellerProfile(req.Context(), gluserID, patch, actorFromContext(req.Context()))
+	switch {
+	case errors.Is(err, service.ErrSellerNotFound):
+		jsonError(w, "Seller not found", http.StatusNotFound)
+		return
+	case err != nil:
+		serverError(w, fmt.Errorf("update failed: %w", err))
+		return
+	}
+	jsonResponse(w, p)
+}
+
+// describeProfilePatch renders a patch for the audit log
+func describeProfilePatch(patch client.SellerProfilePatch) string {
+	var parts []string
+	for _, f := range []struct {
+		name string
+		v    *string
+	}{
+		{"customer_type", patch.CustomerType},
+		{"city_name", patch.CityName},
+		{"vertical", patch.Vertical},
+	} {
+		if f.v != nil {
+			parts = append(parts, fmt.Sprintf("%s=%q", f.name, *f.v))
+		}
+	}
+	for _, o := range []struct {
+		name  string
+		set   *client.StatusOverride
+		clear bool
+	}{
+		{"churn_override", patch.ChurnOverride, patch.ClearChurnOverride},
+		{"sentiment_override", patch.SentimentOverride, patch.ClearSentimentOverride},
+	} {
+		switch {
+		case o.set != nil && o.set.ExpiresAt != nil:
+			parts = append(parts, fmt.Sprintf("%s=%s until %s (%s)", o.name, o.set.Value, o.set.ExpiresAt.Format(time.RFC3339), o.set.Reason))
+		case o.set != nil:
+			parts = append(parts, fmt.Sprintf("%s=%s (%s)", o.name, o.set.Value, o.set.Reason))
+		case o.clear:
+			parts = append(parts, o.name+" cleared")
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(patch.CustomFields)) {
+		if v := patch.CustomFields[name]; v != nil {
+			parts = append(parts, fmt.Sprintf("custom_fields.%s=%v", name, v))
+		} else {
+			parts = append(parts, "custom_fields."+name+" removed")
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// GET /sellers/{gluser_id}/export - Profile, issues, analyses and extractions for POST /sellers/import (scope: migrate)
+func (r *Router) handleSellerExport(w http.ResponseWriter, req *http.Request, gluserID string) {
+	export, err := r.service.ExportSeller(req.Context(), gluserID)
+	if err != nil {
+		serverError(w, fmt.Errorf("export failed: %w", err))
+		return
+	}
+	if export == nil {
+		jsonError(w, "Seller not found", http.StatusNotFound)
+		return
+	}
+
+	if err := auditAllowed(req, client.AuditSellerExport, gluserID); err != nil {
+		log.Printf("⚠️ Refusing export of %s, audit log unavailable: %v", gluserID, err)
+		jsonError(w, "Audit log unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="seller_%s_export.json"`, storage.Sanitize(gluserID)))
+	jsonResponse(w, export)
+}
+
+// GET /export - Every seller's export as NDJSON, the bulk file POST /sellers/import takes (scope: migrate)
+// Exports are streamed one per line as they're built; the response is NDJSON whatever the Accept header.
+func (r *Router) handleExport(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ids, err := storage.ListAllSellerIDs(req.Context())
+	if err != nil {
+		serverError(w, err)
+		return
+	}
+	if err := auditAllowed(req, client.AuditSellersExport, "*"); err != nil {
+		log.Printf("⚠️ Refusing bulk export, audit log unavailable: %v", err)
+		jsonError(w, "Audit log unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	job := r.service.StartJob(req.Context(), client.JobTypeExport, "sellers")
+	w.Header().Set("Content-Disposition", `attachment; filename="sellers_export.ndjson"`)
+	w.Header().Set("X-Job-ID", job.ID())
+	stream := newNDJSONStream(w)
+	for i, id := range ids {
+		export, err := r.service.ExportSeller(req.Context(), id)
+		if err != nil {
+			err = fmt.Errorf("export of %s failed: %w", id, err)
+			job.Finish(req.Context(), "", err)
+			stream.Fail(err)
+			return
+		}
+		job.Progress(req.Context(), i+1, len(ids))
+		if export == nil {
+			continue // Deleted since listing
+		}
+		// Exports carry every transcript of the seller, send each on as it's done
+		err = stream.Send(export)
+		if err == nil {
+			err = stream.Flush()
+		}
+		if err != nil {
+			job.Finish(req.Context(), "", err)
+			stream.Fail(err)
+			return
+		}
+	}
+	err = stream.Flush()
+	job.Finish(req.Context(), "", err)
+	if err != nil {
+		stream.Fail(err)
+	}
+}
+
+// GET /sellers/{gluser_id}/data-inventory?format=zip - Everything stored about the seller (scope: migrate)
+// Lists records with sizes and timestamps, or with format=zip returns them all as one archive.
+func (r *Router) handleSellerDataInventory(w http.ResponseWriter, req *http.Request, gluserID string) {
+	format := req.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "zip" {
+		jsonError(w, "format must be json or zip", http.StatusBadRequest)
+		return
+	}
+	if err := auditAllowed(req, client.AuditSellerData, gluserID); err != nil {
+		log.Printf("⚠️ Refusing data inventory of %s, audit log unavailable: %v", gluserID, err)
+		jsonError(w, "Audit log unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	if format != "zip" {
+		inv, err := r.service.SellerDataInventory(req.Context(), gluserID)
+		if err != nil {
+			serverError(w, fmt.Errorf("inventory failed: %w", err))
+			return
+		}
+		jsonResponse(w, inv)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="seller_%s_data_%s.zip"`, storage.Sanitize(gluserID), time.Now().Format("20060102")))
+	if err := r.service.WriteSellerDataArchive(req.Context(), gluserID, w); err != nil {
+		// The client has gone or has part of the zip by now, so it can only be logged
+		log.Printf("⚠️ Data archive of %s failed: %v", gluserID, err)
+	}
+}
+
+// POST /sellers/import?seller_id=&seller_prefix=&call_prefix=&new_issue_ids=&overwrite=&dry_run= (scope: migrate)
+// The body is one export, or with Content-Type application/x-ndjson one export per line.
+func (r *Router) handleSellerImport(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	requireScope(scopeMigrate, client.AuditSellerImport, "sellers/import", func(w http.ResponseWriter, req *http.Request) {
+		q := req.URL.Query()
+		opts := client.SellerImportOptions{
+			SellerID:     q.Get("seller_id"),
+			SellerPrefix: q.Get("seller_prefix"),
+			CallPrefix:   q.Get("call_prefix"),
+			NewIssueIDs:  q.Get("new_issue_ids") == "true",
+			Overwrite:    q.Get("overwrite") == "true",
+			DryRun:       q.Get("dry_run") == "true",
+		}
+		mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+		bulk := mediaType == "application/x-ndjson"
+		if bulk && opts.SellerID != "" {
+			jsonError(w, "seller_id only applies to single imports, use seller_prefix for NDJSON", http.StatusBadRequest)
+			return
+		}
+
+		if err := auditAllowed(req, client.AuditSellerImport, "sellers/import"); err != nil {
+			log.Printf("⚠️ Refusing import, audit log unavailable: %v", err)
+			jsonError(w, "Audit log unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		result := &client.SellerImportResult{DryRun: opts.DryRun, Sellers: []client.SellerImportItem{}}
+		dec := json.NewDecoder(req.Body)
+		for line := 1; ; line++ {
+			var export client.SellerExport
+			err := dec.Decode(&export)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				// The rest of the body can't be located reliably, so stop here
+				jsonError(w, fmt.Sprintf("Invalid export at line %d: %v (%d sellers imported before it)", line, err, result.Imported), http.StatusBadRequest)
+				return
+			}
+			if !bulk && line > 1 {
+				jsonError(w, "Multiple exports need Content-Type application/x-ndjson", http.StatusBadRequest)
+				return
+			}
+
+			item := r.service.ImportSeller(req.Context(), &export, opts)
+			if bulk {
+				item.Line = line
+			}
+			switch item.Status {
+			case client.ImportImported:
+				result.Imported++
+			case client.ImportSkipped:
+				result.Skipped++
+			default:
+				result.Failed++
+			}
+			result.Sellers = append(result.Sellers, item)
+		}
+		if len(result.Sellers) == 0 {
+			jsonError(w, "Request body has no exports", http.StatusBadRequest)
+			return
+		}
+		jsonResponse(w, result)
+	})(w, req)
+}
+
+// POST /import/analyses?dry_run= (scope: migrate) - NDJSON analyses from before adopting the
+// service, replayed through profile building
+func (r *Router) handleAnalysesImport(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	requireScope(scopeMigrate, client.AuditAnalysesImport, "import/analyses", func(w http.ResponseWriter, req *http.Request) {
+		if err := auditAllowed(req, client.AuditAnalysesImport, "import/analyses"); err != nil {
+			log.Printf("⚠️ Refusing import, audit log unavailable: %v", err)
+			jsonError(w, "Audit log unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		result, err := r.service.ImportAnalyses(req.Context(), req.Body, req.URL.Query().Get("dry_run") == "true")
+		switch {
+		case errors.Is(err, service.ErrInvalidAnalysisImport):
+			jsonError(w, err.Error(), http.StatusBadRequest)
+		case err != nil:
+			serverError(w, fmt.Errorf("import failed: %w", err))
+		default:
+			jsonResponse(w, result)
+		}
+	})(w, req)
+}
+
+// GET /sellers/{gluser_id}/report?format=pdf|html - Shareable one-page seller health report
+func (r *Router) handleSellerReport(w http.ResponseWriter, req *http.Request, gluserID string) {
+	profile, err := storage.LoadSellerProfile(req.Context(), gluserID)
+	if err != nil {
+		serverError(w, fmt.Errorf("error loading profile: %w", err))
+		return
+	}
+	if profile == nil {
+		jsonError(w, "Seller not found", http.StatusNotFound)
+		return
+	}
+
+	sr := report.BuildSellerReport(profile)
+	filename := fmt.Sprintf("seller_%s_report_%s", storage.Sanitize(gluserID), sr.GeneratedAt.Format("20060102"))
+
+	switch req.URL.Query().Get("format") {
+	case "pdf":
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="%s.pdf"`, filename))
+		w.Write(report.RenderSellerReportPDF(sr))
+	case "", "html":
+		page, err := report.RenderSellerReportHTML(sr)
+		if err != nil {
+			serverError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(page)
+	default:
+		jsonError(w, "format must be pdf or html", http.StatusBadRequest)
+	}
+}
+
+// GET /sellers/{gluser_id}/diff?call_a=&call_b=&narrative=false
+// Compares two of the seller's calls, with an LLM narrative unless narrative=false
+func (r *Router) handleSellerDiff(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	gluserID := req.PathValue("id")
+	query := req.URL.Query()
+	callA, callB := query.Get("call_a"), query.Get("call_b")
+	if callA == "" || callB == "" {
+		jsonError(w, "call_a and call_b are required", http.StatusBadRequest)
+		return
+	}
+	if callA == callB {
+		jsonError(w, "call_a and call_b must be different calls", http.StatusBadRequest)
+		return
+	}
+	narrative := true
+	if v := query.Get("narrative"); v != "" {
+		var err error
+		if narrative, err = strconv.ParseBool(v); err != nil {
+			jsonError(w, "Invalid narrative (use true or false)", http.StatusBadRequest)
+			return
+		}
+	}
+
+	diff, err := r.service.DiffSellerCalls(req.Context(), gluserID, callA, callB, narrative)
+	if errors.Is(err, service.ErrCallNotFromSeller) {
+		jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		jsonError(w, "Call not found: "+err.Error(), http.StatusNotFound)
+		return
+	}
+
+	jsonResponse(w, diff)
+}
+
+// GET /sellers/{gluser_id}/brief - Prep brief for an outbound retention call
+func (r *Router) handleSellerBrief(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	brief, err := r.service.SellerBrief(req.Context(), req.PathValue("id"))
+	switch {
+	case errors.Is(err, service.ErrSellerNotFound):
+		jsonError(w, "Seller not found", http.StatusNotFound)
+		return
+	case err != nil:
+		serverError(w, err)
+		return
+	}
+	jsonResponse(w, brief)
+}
+
+// GET /sellers/{gluser_id}/trends?granularity=call|day|week|month&from=YYYY-MM-DD&to=YYYY-MM-DD
+// Trend history from the seller_metrics time series (default: day, all time)
+func (r *Router) handleSellerTrends(w http.ResponseWriter, req *http.Request, gluserID string) {
+	query := req.URL.Query()
+	granularity := query.Get("granularity")
+	if granularity == "" {
+		granularity = client.GranularityDay
+	}
+
+	q := storage.MetricQuery{GluserID: gluserID}
+	var err error
+	if v := query.Get("from"); v != "" {
+		if q.From, err = config.ParseBusinessDate(v); err != nil {
+			jsonError(w, "Invalid from date (use YYYY-MM-DD)", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := query.Get("to"); v != "" {
+		if q.To, err = config.ParseBusinessDate(v); err != nil {
+			jsonError(w, "Invalid to date (use YYYY-MM-DD)", http.StatusBadRequest)
+			return
+		}
+		q.To = q.To.AddDate(0, 0, 1) // inclusive end date
+	}
+	if !q.From.IsZero() && !q.To.IsZero() && !q.From.Before(q.To) {
+		jsonError(w, "from must not be after to", http.StatusBadRequest)
+		return
+	}
+
+	metrics, err := storage.LoadSellerMetrics(req.Context(), q)
+	if err != nil {
+		serverError(w, fmt.Errorf("error loading metrics: %w", err))
+		return
+	}
+	if len(metrics) == 0 {
+		sp, err := storage.LoadSellerProfile(req.Context(), gluserID)
+		if err != nil {
+			serverError(w, fmt.Errorf("error loading profile: %w", err))
+			return
+		}
+		if sp == nil {
+			jsonError(w, "Seller not found", http.StatusNotFound)
+			return
+		}
+	}
+
+	series, err := profile.BuildTrendSeries(gluserID, metrics, granularity)
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	series.From = query.Get("from")
+	series.To = query.Get("to")
+
+	jsonResponse(w, series)
+}
+
+// ==================== SELLER PORTAL ====================
+
+// GET /portal/sellers/{id} - A seller's open cases, recent resolutions and follow-ups, without internal scoring (scope: portal)
+func (r *Router) handlePortalSeller(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	gluserID := req.PathValue("id")
+	requireScope(scopePortal, client.AuditSellerSummary, gluserID, func(w http.ResponseWriter, req *http.Request) {
+		summary, err := r.service.SellerCaseSummary(req.Context(), gluserID)
+		switch {
+		case errors.Is(err, service.ErrSellerNotFound):
+			jsonError(w, err.Error(), http.StatusNotFound)
+			return
+		case err != nil:
+			serverError(w, err)
+			return
+		}
+
+		if err := auditAllowed(req, client.AuditSellerSummary, gluserID); err != nil {
+			log.Printf("⚠️ Refusing seller summary %s, audit log unavailable: %v", gluserID, err)
+			jsonError(w, "Audit log unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		jsonResponse(w, summary)
+	})(w, req)
+}
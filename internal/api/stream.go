@@ -0,0 +1,84 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// ==================== NDJSON STREAMING ====================
+// List endpoints with big result sets answer Accept: application/x-ndjson
+// with one JSON object per line, written out as rows are read, so clients
+// process rows as they arrive and the server holds a few rows per request
+// instead of the whole result. A stream that fails part way ends with an
+// {"error": ...} line, since the 200 has already been sent.
+
+const ndjsonContentType = "application/x-ndjson"
+
+// ndjsonFlushBytes is how much a stream buffers before sending it on
+const ndjsonFlushBytes = 32 << 10
+
+// wantsNDJSON reports whether the request's Accept header asks for NDJSON
+func wantsNDJSON(req *http.Request) bool {
+	for _, part := range strings.Split(req.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err == nil && mediaType == ndjsonContentType {
+			return true
+		}
+	}
+	return false
+}
+
+// ndjsonStream writes rows to a response, one per line
+type ndjsonStream struct {
+	w       http.ResponseWriter
+	buf     bytes.Buffer
+	enc     *json.Encoder
+	started bool // Something was sent, the status can't change
+}
+
+func newNDJSONStream(w http.ResponseWriter) *ndjsonStream {
+	w.Header().Set("Content-Type", ndjsonContentType)
+	s := &ndjsonStream{w: w}
+	s.enc = json.NewEncoder(&s.buf)
+	return s
+}
+
+// Send writes v as a line, sending the buffered lines on once there are enough
+func (s *ndjsonStream) Send(v any) error {
+	if err := s.enc.Encode(v); err != nil {
+		return err
+	}
+	if s.buf.Len() < ndjsonFlushBytes {
+		return nil
+	}
+	return s.Flush()
+}
+
+// Flush sends the buffered lines to the client
+func (s *ndjsonStream) Flush() error {
+	s.started = true
+	if s.buf.Len() > 0 {
+		_, err := s.w.Write(s.buf.Bytes())
+		s.buf.Reset()
+		if err != nil {
+			return err
+		}
+	}
+	return http.NewResponseController(s.w).Flush()
+}
+
+// Fail ends the stream on err: with an error response if nothing was sent yet, otherwise
+// with an error line after the rows sent so far
+func (s *ndjsonStream) Fail(err error) {
+	if !s.started {
+		serverError(s.w, err)
+		return
+	}
+	log.Printf("⚠️ NDJSON stream failed part way: %v", err)
+	s.enc.Encode(map[string]string{"error": err.Error()})
+	s.Flush()
+}
@@ -0,0 +1,177 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/service"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== TICKETS ====================
+
+// GET /tickets - List all ticket dates
+func (r *Router) handleTickets(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// MongoDB first
+	var dates []string
+	var err error
+
+	if storage.IsMongoEnabled() {
+		dates, err = storage.ListTicketDatesFromMongo(req.Context())
+		if err != nil {
+			log.Printf("⚠️ MongoDB list failed, falling back to local: %v", err)
+		}
+	}
+
+	// Fallback to local
+	if len(dates) == 0 {
+		dates, err = storage.ListTicketDates()
+		if err != nil {
+			serverError(w, err)
+			return
+		}
+	}
+
+	jsonResponse(w, map[string]any{
+		"dates": dates,
+		"count": len(dates),
+	})
+}
+
+// GET /tickets/{date}?cf.<name>= - Get tickets for a specific date
+func (r *Router) handleTicketsByDate(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	date := strings.TrimPrefix(req.URL.Path, "/tickets/")
+	if date == "" {
+		r.handleTickets(w, req)
+		return
+	}
+
+	filters, ok := r.customFieldFilters(w, req, client.CustomFieldTicket)
+	if !ok {
+		return
+	}
+	tickets, err := r.service.GetDashboardTickets(req.Context(), date)
+	if err != nil {
+		jsonError(w, "Tickets not found: "+err.Error(), http.StatusNotFound)
+		return
+	}
+	if filters != nil {
+		matched := []client.Ticket{}
+		for _, t := range tickets {
+			if client.CustomFieldsMatch(t.CustomFields, filters) {
+				matched = append(matched, t)
+			}
+		}
+		tickets = matched
+	}
+
+	jsonResponse(w, map[string]any{
+		"date":    date,
+		"tickets": tickets,
+		"count":   len(tickets),
+	})
+}
+
+// PATCH /tickets/{date}/{id} - Set a ticket's status (open, in_progress, resolved) and/or custom fields
+// DELETE /tickets/{date}/{id}?reason= - Move the ticket to the trash
+func (r *Router) handleTicketStatus(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPatch && req.Method != http.MethodDelete {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	date := req.PathValue("date")
+	if _, err := config.ParseBusinessDate(date); err != nil {
+		jsonError(w, "Invalid date, want YYYY-MM-DD", http.StatusBadRequest)
+		return
+	}
+
+	if req.Method == http.MethodDelete {
+		item, err := r.service.DeleteTicket(req.Context(), date, req.PathValue("id"), req.URL.Query().Get("reason"))
+		switch {
+		case errors.Is(err, service.ErrTicketNotFound):
+			jsonError(w, err.Error(), http.StatusNotFound)
+			return
+		case err != nil:
+			serverError(w, err)
+			return
+		}
+		jsonResponse(w, item)
+		return
+	}
+
+	var body client.TicketStatusUpdate
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		jsonError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	t, err := r.service.UpdateTicketStatus(req.Context(), date, req.PathValue("id"), body)
+	switch {
+	case errors.Is(err, service.ErrInvalidTicketStatus), errors.Is(err, service.ErrInvalidCustomField):
+		jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, service.ErrTicketNotFound):
+		jsonError(w, err.Error(), http.StatusNotFound)
+		return
+	case err != nil:
+		serverError(w, err)
+		return
+	}
+
+	jsonResponse(w, t)
+}
+
+// POST /tickets/bulk-update - Set the status of many tickets, e.g. from a ticketing system's nightly sync (scope: tickets)
+func (r *Router) handleTicketsBulkUpdate(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	requireScope(scopeTickets, client.AuditTicketsBulkUpdate, "tickets", func(w http.ResponseWriter, req *http.Request) {
+		var items []client.TicketBulkItem
+		if err := json.NewDecoder(req.Body).Decode(&items); err != nil {
+			jsonError(w, "Invalid request body, want an array of {ticket_id, status, resolution_note}", http.StatusBadRequest)
+			return
+		}
+
+		// Each change is audited before it's made; one the audit log refuses isn't
+		audit := func(item client.TicketBulkItem, previous string) error {
+			change := fmt.Sprintf("status %s -> %s", previous, item.Status)
+			if item.ResolutionNote != "" {
+				change += ": " + item.ResolutionNote
+			}
+			if err := auditChange(req, client.AuditTicketUpdate, item.TicketID, change); err != nil {
+				log.Printf("⚠️ Refusing update of ticket %s, audit log unavailable: %v", item.TicketID, err)
+				return fmt.Errorf("%w: %v", service.ErrAuditUnavailable, err)
+			}
+			return nil
+		}
+		result, err := r.service.BulkUpdateTickets(req.Context(), items, audit)
+		switch {
+		case errors.Is(err, service.ErrTooManyTicketItems):
+			jsonError(w, err.Error(), http.StatusBadRequest)
+			return
+		case err != nil:
+			serverError(w, err)
+			return
+		}
+		jsonResponse(w, result)
+	})(w, req)
+}
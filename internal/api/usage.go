@@ -0,0 +1,288 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/llm"
+	"im-ai-voice/internal/service"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== API KEY USAGE ====================
+// Requests sent with a configured API key are counted against the key, on
+// any endpoint: requests, the analyses they ran (handlers report them with
+// countAnalyses), and the Gemini requests, tokens and estimated cost of
+// serving them. API_KEY_QUOTAS caps a key's monthly usage, as
+// comma-separated name:limits entries (limits joined by +):
+//
+//	API_KEY_QUOTAS="support-console:requests=50000+analyses=2000+cost_usd=25"
+//
+// A key over its requests quota is refused everything until the month ends,
+// one over its analyses quota analysis triggers, and one over its cost quota
+// every metered endpoint. Quotas are checked before serving, so concurrent
+// requests may overshoot them slightly. With any quota set, the metered
+// endpoints refuse requests without a configured key, which no quota would
+// hold back.
+
+// analysisTriggers are the endpoints whose POSTs can run analyses, which
+// the analyses quota refuses
+var analysisTriggers = map[string]bool{"/ingest": true, "/analyze": true, "/analyze/trigger": true}
+
+// meteredEndpoints are the endpoints that trigger analyses or make Gemini
+// requests, the usage the analyses and cost quotas cap; the cost quota
+// refuses them all
+var meteredEndpoints = newEndpointSet(
+	"POST /ingest",
+	"POST /analyze",
+	"POST /analyze/trigger",
+	"POST /analyze/provisional/upgrade",
+	"POST /calls/{id}/chat",
+	"GET /sellers/{id}/diff",
+	"GET /sellers/{id}/brief",
+	"POST /ask",
+	"GET /reports/weekly",
+	"POST /reports/weekly/send",
+	"POST /analytics/themes/trigger",
+	"POST /systemic-issues/trigger",
+	"POST /admin/selftest",
+	"POST /admin/reclassifications",
+	"POST /admin/kb",
+	"POST /admin/jobs/{name}/run",
+)
+
+// newEndpointSet returns a mux matching the patterns, for telling whether a
+// request is for one of them
+func newEndpointSet(patterns ...string) *http.ServeMux {
+	mux := http.NewServeMux()
+	for _, p := range patterns {
+		mux.Handle(p, http.NotFoundHandler())
+	}
+	return mux
+}
+
+// isMetered reports whether the request is for a metered endpoint
+func isMetered(req *http.Request) bool {
+	_, pattern := meteredEndpoints.Handler(req)
+	return pattern != ""
+}
+
+// quotasConfigured reports whether API_KEY_QUOTAS set a quota on any key
+func quotasConfigured() bool {
+	for i := range apiKeys {
+		if apiKeys[i].quota != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// applyKeyQuotas sets the quotas in API_KEY_QUOTAS on keys, skipping malformed entries
+func applyKeyQuotas(keys []apiKey) {
+	for _, entry := range strings.Split(os.Getenv("API_KEY_QUOTAS"), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, limits, ok := strings.Cut(entry, ":")
+		quota, err := parseKeyQuota(limits)
+		if !ok || err != nil {
+			log.Printf("⚠️ Ignoring malformed API_KEY_QUOTAS entry for %q (want name:requests=N+analyses=N+cost_usd=X)", name)
+			continue
+		}
+		found := false
+		for i := range keys {
+			if keys[i].name == name {
+				keys[i].quota, found = quota, true
+			}
+		}
+		if !found {
+			log.Printf("⚠️ API_KEY_QUOTAS names unknown API key %q", name)
+		}
+	}
+}
+
+func parseKeyQuota(limits string) (*client.KeyQuota, error) {
+	q := &client.KeyQuota{}
+	for _, limit := range strings.Split(limits, "+") {
+		name, v, ok := strings.Cut(strings.TrimSpace(limit), "=")
+		if !ok {
+			return nil, fmt.Errorf("malformed limit %q", limit)
+		}
+		var err error
+		switch name {
+		case "requests":
+			q.Requests, err = strconv.Atoi(v)
+		case "analyses":
+			q.Analyses, err = strconv.Atoi(v)
+		case "cost_usd":
+			q.CostUSD, err = strconv.ParseFloat(v, 64)
+		default:
+			return nil, fmt.Errorf("unknown limit %q", name)
+		}
+		if err != nil || q.Requests < 0 || q.Analyses < 0 || q.CostUSD < 0 {
+			return nil, fmt.Errorf("invalid %s limit %q", name, v)
+		}
+	}
+	return q, nil
+}
+
+// exceededLimits returns the limits of q that usage has reached
+func exceededLimits(q *client.KeyQuota, usage client.KeyUsageCounts) []string {
+	exceeded := []string{}
+	if q == nil {
+		return exceeded
+	}
+	if q.Requests > 0 && usage.Requests >= q.Requests {
+		exceeded = append(exceeded, "requests")
+	}
+	if q.Analyses > 0 && usage.Analyses >= q.Analyses {
+		exceeded = append(exceeded, "analyses")
+	}
+	if q.CostUSD > 0 && usage.CostUSD >= q.CostUSD {
+		exceeded = append(exceeded, "cost_usd")
+	}
+	return exceeded
+}
+
+// usageMonth returns the month (YYYY-MM, business timezone) of t and when the next one starts
+func usageMonth(t time.Time) (string, time.Time) {
+	t = t.In(config.BusinessTZ)
+	return t.Format("2006-01"), time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, config.BusinessTZ)
+}
+
+// overQuota returns the quota limit that refuses the request, "" when none
+// does: requests refuses every request, analyses analysis triggers and
+// cost_usd metered requests. Usage that can't be read doesn't refuse requests.
+func (k *apiKey) overQuota(ctx context.Context, now time.Time, trigger, metered bool) string {
+	if k.quota == nil {
+		return ""
+	}
+	month, _ := usageMonth(now)
+	usage, err := storage.LoadKeyUsage(ctx, k.name, month)
+	if err != nil {
+		log.Printf("⚠️ Failed to load usage of API key %s, not enforcing its quota: %v", k.name, err)
+		return ""
+	}
+	if usage == nil {
+		return ""
+	}
+	for _, limit := range exceededLimits(k.quota, usage.KeyUsageCounts) {
+		switch {
+		case limit == "requests",
+			limit == "analyses" && trigger,
+			limit == "cost_usd" && metered:
+			return limit
+		}
+	}
+	return ""
+}
+
+// TrackKeyUsage wraps h so requests with an API key are counted against it
+// and refused with a 429 once it's over quota. With quotas configured,
+// metered endpoints refuse requests without a key with a 401.
+func TrackKeyUsage(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		key := lookupAPIKey(req)
+		if key == nil {
+			if quotasConfigured() && isMetered(req) {
+				jsonError(w, "API key required: this endpoint counts against API key quotas", http.StatusUnauthorized)
+				return
+			}
+			h.ServeHTTP(w, req)
+			return
+		}
+
+		now := time.Now()
+		trigger := req.Method == http.MethodPost && analysisTriggers[req.URL.Path]
+		if limit := key.overQuota(req.Context(), now, trigger, isMetered(req)); limit != "" {
+			recordKeyUsage(req.Context(), key.name, now, client.KeyUsageCounts{Requests: 1, Rejected: 1})
+			_, resets := usageMonth(now)
+			w.Header().Set("Retry-After", strconv.Itoa(int(time.Until(resets).Seconds())+1))
+			jsonError(w, fmt.Sprintf("API key %s is over its monthly %s quota", key.name, limit), http.StatusTooManyRequests)
+			return
+		}
+
+		ctx, llmUsage := llm.MeterUsage(req.Context())
+		analyses := new(atomic.Int64)
+		h.ServeHTTP(w, req.WithContext(context.WithValue(ctx, analysesKey{}, analyses)))
+
+		delta := client.KeyUsageCounts{Requests: 1, Analyses: int(analyses.Load())}
+		if u := llmUsage(); u != nil {
+			delta.LLMRequests = u.Requests
+			delta.PromptTokens = u.PromptTokens
+			delta.OutputTokens = u.OutputTokens
+			delta.CostUSD = service.LLMCostUSD(u.PromptTokens, u.OutputTokens)
+		}
+		recordKeyUsage(req.Context(), key.name, now, delta)
+	})
+}
+
+// analysesKey is the context key of a request's count of analyses run
+type analysesKey struct{}
+
+// countAnalyses notes that serving the request ran n analyses, counted
+// against its API key
+func countAnalyses(req *http.Request, n int) {
+	if c, ok := req.Context().Value(analysesKey{}).(*atomic.Int64); ok {
+		c.Add(int64(n))
+	}
+}
+
+// recordKeyUsage adds to a key's usage, even once the request is cancelled
+func recordKeyUsage(ctx context.Context, name string, at time.Time, delta client.KeyUsageCounts) {
+	if err := storage.RecordKeyUsage(context.WithoutCancel(ctx), name, at, delta); err != nil {
+		log.Printf("⚠️ Failed to record usage of API key %s: %v", name, err)
+	}
+}
+
+func roundCost(usd float64) float64 {
+	return math.Round(usd*10000) / 10000
+}
+
+// keyUsageReport returns a configured key's usage in a month against its
+// quota, nil when no key has the name
+func keyUsageReport(ctx context.Context, name, month string) (*client.KeyUsageReport, error) {
+	var key *apiKey
+	for i := range apiKeys {
+		if apiKeys[i].name == name {
+			key = &apiKeys[i]
+		}
+	}
+	if key == nil {
+		return nil, nil
+	}
+
+	usage, err := storage.LoadKeyUsage(ctx, name, month)
+	if err != nil {
+		return nil, err
+	}
+	if usage == nil {
+		usage = &client.KeyUsage{KeyName: name, Month: month}
+	}
+	usage.CostUSD = roundCost(usage.CostUSD)
+	days := make(map[string]client.KeyUsageCounts, len(usage.Days))
+	for date, c := range usage.Days {
+		c.CostUSD = roundCost(c.CostUSD)
+		days[date] = c
+	}
+	usage.Days = days
+	start, _ := time.ParseInLocation("2006-01", month, config.BusinessTZ)
+	_, resets := usageMonth(start)
+	return &client.KeyUsageReport{
+		KeyUsage: *usage,
+		Quota:    key.quota,
+		Exceeded: exceededLimits(key.quota, usage.KeyUsageCounts),
+		ResetsAt: resets,
+	}, nil
+}
@@ -0,0 +1,413 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== COLD ARCHIVE ====================
+// Old call analyses are compacted into monthly-partitioned Parquet files
+// (local disk or S3) and removed from hot storage, keeping Mongo lean.
+// Layout: analyses/month=YYYY-MM/part-<unix_nanos>.parquet
+
+// ArchiveStore is where archived Parquet files live
+type ArchiveStore interface {
+	Put(ctx context.Context, key string, data []byte) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	List(ctx context.Context, prefix string) ([]string, error)
+	Name() string
+}
+
+// Global archive store (local by default, S3 if ARCHIVE_S3_BUCKET is set)
+var Archive ArchiveStore
+
+// archiveColumns is the flat schema of archived analyses. Key columns are
+// kept separate so scans can filter without decoding the full JSON.
+var archiveColumns = []ParquetColumn{
+	{Name: "call_id", Kind: ParquetString},
+	{Name: "seller_id", Kind: ParquetString},
+	{Name: "timestamp", Kind: ParquetTimestamp},
+	{Name: "sentiment", Kind: ParquetString},
+	{Name: "churn_risk", Kind: ParquetString},
+	{Name: "satisfaction_score", Kind: ParquetInt64},
+	{Name: "issue_count", Kind: ParquetInt64},
+	{Name: "analysis_json", Kind: ParquetString},
+}
+
+// Init configures the archive store from the environment
+func Init() error {
+	if bucket := os.Getenv("ARCHIVE_S3_BUCKET"); bucket != "" {
+		client, err := NewS3ClientFromEnv(bucket)
+		if err != nil {
+			Archive = &LocalArchiveStore{dir: config.ARCHIVE_DIR}
+			return fmt.Errorf("S3 archive disabled: %w", err)
+		}
+		Archive = &S3ArchiveStore{client: client}
+		log.Printf("🧊 Cold archive: s3://%s", bucket)
+		return nil
+	}
+
+	if err := os.MkdirAll(config.ARCHIVE_DIR, 0755); err != nil {
+		return fmt.Errorf("failed to create archive directory: %w", err)
+	}
+	Archive = &LocalArchiveStore{dir: config.ARCHIVE_DIR}
+	log.Printf("🧊 Cold archive: %s", config.ARCHIVE_DIR)
+	return nil
+}
+
+// AfterDays returns the age after which analyses are archived
+func AfterDays() int {
+	if v := os.Getenv("ARCHIVE_AFTER_DAYS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return n
+		}
+	}
+	return config.DEFAULT_ARCHIVE_AFTER_DAYS
+}
+
+// LocalArchiveStore keeps archive files on local disk
+type LocalArchiveStore struct {
+	dir string
+}
+
+func (l *LocalArchiveStore) Name() string { return "local:" + l.dir }
+
+func (l *LocalArchiveStore) Put(ctx context.Context, key string, data []byte) error {
+	path := filepath.Join(l.dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	// Write to a temp file first so readers never see a partial Parquet file
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (l *LocalArchiveStore) Get(ctx context.Context, key string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(l.dir, filepath.FromSlash(key)))
+}
+
+func (l *LocalArchiveStore) List(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	root := filepath.Join(l.dir, filepath.FromSlash(prefix))
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() || !strings.HasSuffix(path, ".parquet") {
+			return nil
+		}
+		rel, err := filepath.Rel(l.dir, path)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, filepath.ToSlash(rel))
+		return nil
+	})
+	sort.Strings(keys)
+	return keys, err
+}
+
+// S3ArchiveStore keeps archive files in an S3 bucket
+type S3ArchiveStore struct {
+	client *S3Client
+}
+
+func (s *S3ArchiveStore) Name() string { return "s3:" + s.client.bucket }
+
+func (s *S3ArchiveStore) Put(ctx context.Context, key string, data []byte) error {
+	return s.client.PutObject(ctx, key, data)
+}
+
+func (s *S3ArchiveStore) Get(ctx context.Context, key string) ([]byte, error) {
+	return s.client.GetObject(ctx, key)
+}
+
+func (s *S3ArchiveStore) List(ctx context.Context, prefix string) ([]string, error) {
+	keys, err := s.client.ListObjects(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// ==================== COMPACTION JOB ====================
+
+// ArchiveResult summarizes a compaction run
+type ArchiveResult struct {
+	Cutoff        string   `json:"cutoff"`
+	Archived      int      `json:"archived"`
+	FilesWritten  []string `json:"files_written"`
+	RemovedFromDB int      `json:"removed_from_hot_storage"`
+	Store         string   `json:"store"`
+}
+
+// Run moves analyses older than cutoff into the cold archive.
+// Hot copies are only removed after the written file reads back intact.
+func Run(ctx context.Context, cutoff time.Time) (*ArchiveResult, error) {
+	if Archive == nil {
+		return nil, fmt.Errorf("archive not initialized")
+	}
+
+	result := &ArchiveResult{
+		Cutoff:       config.BusinessDate(cutoff),
+		FilesWritten: []string{},
+		Store:        Archive.Name(),
+	}
+
+	// Load old analyses - MongoDB first
+	var analyses []client.AnalysisResult
+	var localPaths map[string]string
+	var err error
+	if storage.IsMongoEnabled() {
+		analyses, err = storage.GetAnalysesBeforeFromMongo(ctx, cutoff)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load analyses from MongoDB: %w", err)
+		}
+	} else {
+		analyses, localPaths, err = storage.LoadAnalysesBefore(cutoff)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load local analyses: %w", err)
+		}
+	}
+
+	if len(analyses) == 0 {
+		log.Printf("🧊 Archive: nothing older than %s", result.Cutoff)
+		return result, nil
+	}
+
+	// Partition by month of the call
+	byMonth := make(map[string][]client.AnalysisResult)
+	for _, a := range analyses {
+		month := a.Timestamp.Format("2006-01")
+		byMonth[month] = append(byMonth[month], a)
+	}
+
+	months := make([]string, 0, len(byMonth))
+	for m := range byMonth {
+		months = append(months, m)
+	}
+	sort.Strings(months)
+
+	for _, month := range months {
+		batch := byMonth[month]
+		key := fmt.Sprintf("analyses/month=%s/part-%d.parquet", month, time.Now().UnixNano())
+
+		data, err := encodeArchiveBatch(batch)
+		if err != nil {
+			return result, fmt.Errorf("failed to encode %s: %w", month, err)
+		}
+		if err := Archive.Put(ctx, key, data); err != nil {
+			return result, fmt.Errorf("failed to write %s: %w", key, err)
+		}
+
+		// Verify before dropping hot copies
+		written, err := Archive.Get(ctx, key)
+		if err != nil {
+			return result, fmt.Errorf("failed to verify %s: %w", key, err)
+		}
+		if _, rows, err := readParquet(written, "call_id"); err != nil || len(rows) != len(batch) {
+			return result, fmt.Errorf("verification failed for %s: %v", key, err)
+		}
+
+		result.FilesWritten = append(result.FilesWritten, key)
+		result.Archived += len(batch)
+
+		callIDs := make([]string, 0, len(batch))
+		for _, a := range batch {
+			callIDs = append(callIDs, a.CallID)
+		}
+
+		if storage.IsMongoEnabled() {
+			removed, err := storage.DeleteAnalysesFromMongo(ctx, callIDs)
+			if err != nil {
+				log.Printf("⚠️ Archived %s but failed to remove from MongoDB: %v", key, err)
+			}
+			result.RemovedFromDB += int(removed)
+		} else {
+			for _, id := range callIDs {
+				if path, ok := localPaths[id]; ok {
+					if err := os.Remove(path); err == nil {
+						result.RemovedFromDB++
+					}
+				}
+			}
+		}
+
+		log.Printf("   🧊 Archived %d analyses → %s", len(batch), key)
+	}
+
+	log.Printf("🧊 Archive complete: %d analyses older than %s in %d files",
+		result.Archived, result.Cutoff, len(result.FilesWritten))
+	return result, nil
+}
+
+// encodeArchiveBatch converts analyses into Parquet rows
+func encodeArchiveBatch(batch []client.AnalysisResult) ([]byte, error) {
+	rows := make([][]interface{}, 0, len(batch))
+	for _, a := range batch {
+		js, err := json.Marshal(a)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, []interface{}{
+			a.CallID,
+			a.SellerID,
+			a.Timestamp.UnixMilli(),
+			a.Intent.Sentiment,
+			a.Churn.IsLikelyToChurn,
+			int64(a.Intent.SatisfactionScore),
+			int64(len(a.Issues)),
+			string(js),
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := writeParquet(&buf, archiveColumns, rows); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ==================== ARCHIVE QUERIES ====================
+
+// QueryAnalyses returns archived analyses for a seller within
+// [from, to). Zero times leave that side of the range open. Month
+// partitions outside the range are skipped without being read.
+func QueryAnalyses(ctx context.Context, sellerID string, from, to time.Time) ([]client.AnalysisResult, error) {
+	if Archive == nil {
+		return nil, fmt.Errorf("archive not initialized")
+	}
+
+	keys, err := Archive.List(ctx, "analyses/")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list archive: %w", err)
+	}
+
+	var results []client.AnalysisResult
+	for _, key := range keys {
+		if !archivePartitionInRange(key, from, to) {
+			continue
+		}
+
+		data, err := Archive.Get(ctx, key)
+		if err != nil {
+			log.Printf("⚠️ Failed to read archive file %s: %v", key, err)
+			continue
+		}
+
+		// Cheap pass on the key column first
+		cols, rows, err := readParquet(data, "seller_id")
+		if err != nil {
+			log.Printf("⚠️ Corrupt archive file %s: %v", key, err)
+			continue
+		}
+		sellerIdx := archiveColumnIndex(cols, "seller_id")
+		found := false
+		for _, row := range rows {
+			if row[sellerIdx] == sellerID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			continue
+		}
+
+		cols, rows, err = readParquet(data)
+		if err != nil {
+			continue
+		}
+		jsonIdx := archiveColumnIndex(cols, "analysis_json")
+		for _, row := range rows {
+			if row[sellerIdx] != sellerID {
+				continue
+			}
+			js, _ := row[jsonIdx].(string)
+			var ar client.AnalysisResult
+			if err := json.Unmarshal([]byte(js), &ar); err != nil {
+				continue
+			}
+			if !from.IsZero() && ar.Timestamp.Before(from) {
+				continue
+			}
+			if !to.IsZero() && !ar.Timestamp.Before(to) {
+				continue
+			}
+			results = append(results, ar)
+		}
+	}
+
+	// Most recent first, matching CallHistory order
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Timestamp.After(results[j].Timestamp)
+	})
+	return results, nil
+}
+
+// FilesInRange counts the archive files QueryAnalyses would read for
+// [from, to), to tell how long a query will take before running it
+func FilesInRange(ctx context.Context, from, to time.Time) (int, error) {
+	if Archive == nil {
+		return 0, fmt.Errorf("archive not initialized")
+	}
+	keys, err := Archive.List(ctx, "analyses/")
+	if err != nil {
+		return 0, fmt.Errorf("failed to list archive: %w", err)
+	}
+	n := 0
+	for _, key := range keys {
+		if archivePartitionInRange(key, from, to) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// archivePartitionInRange checks a key's month=YYYY-MM partition against a range
+func archivePartitionInRange(key string, from, to time.Time) bool {
+	idx := strings.Index(key, "month=")
+	if idx < 0 || len(key) < idx+13 {
+		return true
+	}
+	month, err := time.Parse("2006-01", key[idx+6:idx+13])
+	if err != nil {
+		return true
+	}
+	monthEnd := month.AddDate(0, 1, 0)
+	if !from.IsZero() && !monthEnd.After(from) {
+		return false
+	}
+	if !to.IsZero() && !month.Before(to) {
+		return false
+	}
+	return true
+}
+
+func archiveColumnIndex(cols []ParquetColumn, name string) int {
+	for i, c := range cols {
+		if c.Name == name {
+			return i
+		}
+	}
+	return -1
+}
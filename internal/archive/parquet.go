@@ -0,0 +1,562 @@
+package archive
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// ==================== MINIMAL PARQUET CODEC ====================
+// A small, dependency-free Parquet writer/reader used by the cold archive.
+// It supports exactly what the archive needs: a flat schema of REQUIRED
+// columns, a single row group per file, one PLAIN-encoded uncompressed data
+// page per column. Files follow the Parquet format spec so external tools
+// (DuckDB, Spark, pyarrow) can query them; the reader only handles this subset.
+
+// ParquetKind is the physical type of an archive column
+type ParquetKind int
+
+const (
+	ParquetString    ParquetKind = iota // BYTE_ARRAY (UTF8)
+	ParquetInt64                        // INT64
+	ParquetDouble                       // DOUBLE
+	ParquetTimestamp                    // INT64 (TIMESTAMP_MILLIS)
+)
+
+// ParquetColumn describes one column in a flat schema
+type ParquetColumn struct {
+	Name string
+	Kind ParquetKind
+}
+
+// Parquet physical types / enums (parquet.thrift)
+const (
+	pqTypeInt64     = 2
+	pqTypeDouble    = 5
+	pqTypeByteArray = 6
+
+	pqConvertedUTF8            = 0
+	pqConvertedTimestampMillis = 9
+
+	pqRepetitionRequired = 0
+	pqEncodingPlain      = 0
+	pqEncodingRLE        = 3
+	pqCodecUncompressed  = 0
+	pqPageTypeData       = 0
+)
+
+var parquetMagic = []byte("PAR1")
+
+func (k ParquetKind) physicalType() int32 {
+	switch k {
+	case ParquetString:
+		return pqTypeByteArray
+	case ParquetDouble:
+		return pqTypeDouble
+	default:
+		return pqTypeInt64
+	}
+}
+
+// writeParquet encodes rows (one []interface{} per row, in column order)
+// into a Parquet file. Strings must be string, Int64/Timestamp int64,
+// Double float64.
+func writeParquet(w io.Writer, cols []ParquetColumn, rows [][]interface{}) error {
+	var buf bytes.Buffer
+	buf.Write(parquetMagic)
+
+	type chunkInfo struct {
+		offset int64
+		size   int64
+	}
+	chunks := make([]chunkInfo, len(cols))
+
+	for ci, col := range cols {
+		// Page data: PLAIN values, no levels (all columns REQUIRED)
+		var page bytes.Buffer
+		for ri, row := range rows {
+			if len(row) != len(cols) {
+				return fmt.Errorf("row %d has %d values, expected %d", ri, len(row), len(cols))
+			}
+			if err := writePlainValue(&page, col.Kind, row[ci]); err != nil {
+				return fmt.Errorf("column %s row %d: %w", col.Name, ri, err)
+			}
+		}
+
+		var header bytes.Buffer
+		tw := &thriftWriter{w: &header}
+		tw.structBegin()
+		tw.fieldI32(1, pqPageTypeData)
+		tw.fieldI32(2, int32(page.Len()))
+		tw.fieldI32(3, int32(page.Len()))
+		tw.fieldStructBegin(5)
+		tw.fieldI32(1, int32(len(rows)))
+		tw.fieldI32(2, pqEncodingPlain)
+		tw.fieldI32(3, pqEncodingRLE)
+		tw.fieldI32(4, pqEncodingRLE)
+		tw.structEnd()
+		tw.structEnd()
+
+		chunks[ci] = chunkInfo{offset: int64(buf.Len()), size: int64(header.Len() + page.Len())}
+		buf.Write(header.Bytes())
+		buf.Write(page.Bytes())
+	}
+
+	// File metadata
+	var meta bytes.Buffer
+	tw := &thriftWriter{w: &meta}
+	tw.structBegin()
+	tw.fieldI32(1, 1) // version
+
+	tw.fieldListBegin(2, thriftTypeStruct, len(cols)+1)
+	tw.structBegin() // root schema element
+	tw.fieldString(4, "schema")
+	tw.fieldI32(5, int32(len(cols)))
+	tw.structEnd()
+	for _, col := range cols {
+		tw.structBegin()
+		tw.fieldI32(1, col.Kind.physicalType())
+		tw.fieldI32(3, pqRepetitionRequired)
+		tw.fieldString(4, col.Name)
+		switch col.Kind {
+		case ParquetString:
+			tw.fieldI32(6, pqConvertedUTF8)
+		case ParquetTimestamp:
+			tw.fieldI32(6, pqConvertedTimestampMillis)
+		}
+		tw.structEnd()
+	}
+
+	tw.fieldI64(3, int64(len(rows)))
+
+	var totalSize int64
+	for _, c := range chunks {
+		totalSize += c.size
+	}
+	tw.fieldListBegin(4, thriftTypeStruct, 1)
+	tw.structBegin() // RowGroup
+	tw.fieldListBegin(1, thriftTypeStruct, len(cols))
+	for ci, col := range cols {
+		tw.structBegin() // ColumnChunk
+		tw.fieldI64(2, chunks[ci].offset)
+		tw.fieldStructBegin(3) // ColumnMetaData
+		tw.fieldI32(1, col.Kind.physicalType())
+		tw.fieldListBegin(2, thriftTypeI32, 2)
+		tw.writeVarint(zigzag32(pqEncodingPlain))
+		tw.writeVarint(zigzag32(pqEncodingRLE))
+		tw.fieldListBegin(3, thriftTypeBinary, 1)
+		tw.writeBinary(col.Name)
+		tw.fieldI32(4, pqCodecUncompressed)
+		tw.fieldI64(5, int64(len(rows)))
+		tw.fieldI64(6, chunks[ci].size)
+		tw.fieldI64(7, chunks[ci].size)
+		tw.fieldI64(9, chunks[ci].offset)
+		tw.structEnd()
+		tw.structEnd()
+	}
+	tw.fieldI64(2, totalSize)
+	tw.fieldI64(3, int64(len(rows)))
+	tw.structEnd()
+
+	tw.fieldString(6, "im-ai-voice archive")
+	tw.structEnd()
+
+	buf.Write(meta.Bytes())
+	var lenBuf [4]byte
+	binary.LittleEndian.PutUint32(lenBuf[:], uint32(meta.Len()))
+	buf.Write(lenBuf[:])
+	buf.Write(parquetMagic)
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func writePlainValue(w *bytes.Buffer, kind ParquetKind, v interface{}) error {
+	switch kind {
+	case ParquetString:
+		s, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("expected string, got %T", v)
+		}
+		var l [4]byte
+		binary.LittleEndian.PutUint32(l[:], uint32(len(s)))
+		w.Write(l[:])
+		w.WriteString(s)
+	case ParquetInt64, ParquetTimestamp:
+		n, ok := v.(int64)
+		if !ok {
+			return fmt.Errorf("expected int64, got %T", v)
+		}
+		var b [8]byte
+		binary.LittleEndian.PutUint64(b[:], uint64(n))
+		w.Write(b[:])
+	case ParquetDouble:
+		f, ok := v.(float64)
+		if !ok {
+			return fmt.Errorf("expected float64, got %T", v)
+		}
+		var b [8]byte
+		binary.LittleEndian.PutUint64(b[:], math.Float64bits(f))
+		w.Write(b[:])
+	}
+	return nil
+}
+
+// readParquet decodes a file produced by writeParquet. If only is non-empty,
+// values are decoded just for the named columns (others are nil), which keeps
+// archive scans cheap when filtering on a key column.
+func readParquet(data []byte, only ...string) ([]ParquetColumn, [][]interface{}, error) {
+	if len(data) < 12 || !bytes.Equal(data[:4], parquetMagic) || !bytes.Equal(data[len(data)-4:], parquetMagic) {
+		return nil, nil, fmt.Errorf("not a parquet file")
+	}
+	metaLen := int(binary.LittleEndian.Uint32(data[len(data)-8 : len(data)-4]))
+	metaStart := len(data) - 8 - metaLen
+	if metaStart < 4 {
+		return nil, nil, fmt.Errorf("invalid parquet footer length %d", metaLen)
+	}
+
+	tr := &thriftReader{data: data[metaStart : len(data)-8]}
+	meta, err := tr.readStruct()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decode file metadata: %w", err)
+	}
+
+	numRows := int(thriftInt(meta[3]))
+	schemaList, _ := meta[2].([]interface{})
+	var cols []ParquetColumn
+	for i, el := range schemaList {
+		if i == 0 {
+			continue // root
+		}
+		s, _ := el.(map[int16]interface{})
+		name, _ := s[4].([]byte)
+		col := ParquetColumn{Name: string(name)}
+		switch thriftInt(s[1]) {
+		case pqTypeByteArray:
+			col.Kind = ParquetString
+		case pqTypeDouble:
+			col.Kind = ParquetDouble
+		case pqTypeInt64:
+			col.Kind = ParquetInt64
+			if c, ok := s[6]; ok && thriftInt(c) == pqConvertedTimestampMillis {
+				col.Kind = ParquetTimestamp
+			}
+		default:
+			return nil, nil, fmt.Errorf("unsupported physical type for column %s", col.Name)
+		}
+		cols = append(cols, col)
+	}
+
+	want := make(map[string]bool)
+	for _, n := range only {
+		want[n] = true
+	}
+
+	rows := make([][]interface{}, numRows)
+	for i := range rows {
+		rows[i] = make([]interface{}, len(cols))
+	}
+
+	rowGroups, _ := meta[4].([]interface{})
+	rowBase := 0
+	for _, rgv := range rowGroups {
+		rg, _ := rgv.(map[int16]interface{})
+		rgRows := int(thriftInt(rg[3]))
+		chunkList, _ := rg[1].([]interface{})
+		for ci, chv := range chunkList {
+			if ci >= len(cols) {
+				break
+			}
+			if len(want) > 0 && !want[cols[ci].Name] {
+				continue
+			}
+			ch, _ := chv.(map[int16]interface{})
+			cmd, _ := ch[3].(map[int16]interface{})
+			if thriftInt(cmd[4]) != pqCodecUncompressed {
+				return nil, nil, fmt.Errorf("column %s: compressed pages are not supported", cols[ci].Name)
+			}
+			offset := int(thriftInt(cmd[9]))
+			if offset <= 0 || offset >= metaStart {
+				return nil, nil, fmt.Errorf("column %s: invalid page offset", cols[ci].Name)
+			}
+
+			hr := &thriftReader{data: data[offset:metaStart]}
+			header, err := hr.readStruct()
+			if err != nil {
+				return nil, nil, fmt.Errorf("column %s: bad page header: %w", cols[ci].Name, err)
+			}
+			pageSize := int(thriftInt(header[3]))
+			pageStart := offset + hr.pos
+			if pageStart+pageSize > metaStart {
+				return nil, nil, fmt.Errorf("column %s: page overruns file", cols[ci].Name)
+			}
+			page := data[pageStart : pageStart+pageSize]
+
+			pos := 0
+			for r := 0; r < rgRows && rowBase+r < numRows; r++ {
+				v, n, err := readPlainValue(page[pos:], cols[ci].Kind)
+				if err != nil {
+					return nil, nil, fmt.Errorf("column %s row %d: %w", cols[ci].Name, r, err)
+				}
+				rows[rowBase+r][ci] = v
+				pos += n
+			}
+		}
+		rowBase += rgRows
+	}
+
+	return cols, rows, nil
+}
+
+func readPlainValue(b []byte, kind ParquetKind) (interface{}, int, error) {
+	switch kind {
+	case ParquetString:
+		if len(b) < 4 {
+			return nil, 0, io.ErrUnexpectedEOF
+		}
+		l := int(binary.LittleEndian.Uint32(b))
+		if len(b) < 4+l {
+			return nil, 0, io.ErrUnexpectedEOF
+		}
+		return string(b[4 : 4+l]), 4 + l, nil
+	case ParquetDouble:
+		if len(b) < 8 {
+			return nil, 0, io.ErrUnexpectedEOF
+		}
+		return math.Float64frombits(binary.LittleEndian.Uint64(b)), 8, nil
+	default:
+		if len(b) < 8 {
+			return nil, 0, io.ErrUnexpectedEOF
+		}
+		return int64(binary.LittleEndian.Uint64(b)), 8, nil
+	}
+}
+
+// ==================== THRIFT COMPACT PROTOCOL ====================
+
+const (
+	thriftTypeI32    = 5
+	thriftTypeI64    = 6
+	thriftTypeBinary = 8
+	thriftTypeList   = 9
+	thriftTypeStruct = 12
+)
+
+type thriftWriter struct {
+	w       *bytes.Buffer
+	lastIDs []int16
+	lastID  int16
+}
+
+func zigzag32(n int32) uint64 { return uint64(uint32((n << 1) ^ (n >> 31))) }
+
+func zigzag64(n int64) uint64 { return uint64((n << 1) ^ (n >> 63)) }
+
+func (t *thriftWriter) writeVarint(v uint64) {
+	for v >= 0x80 {
+		t.w.WriteByte(byte(v) | 0x80)
+		v >>= 7
+	}
+	t.w.WriteByte(byte(v))
+}
+
+func (t *thriftWriter) fieldHeader(id int16, typ byte) {
+	delta := id - t.lastID
+	if delta > 0 && delta <= 15 {
+		t.w.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		t.w.WriteByte(typ)
+		t.writeVarint(zigzag32(int32(id)))
+	}
+	t.lastID = id
+}
+
+func (t *thriftWriter) structBegin() {
+	t.lastIDs = append(t.lastIDs, t.lastID)
+	t.lastID = 0
+}
+
+func (t *thriftWriter) structEnd() {
+	t.w.WriteByte(0)
+	t.lastID = t.lastIDs[len(t.lastIDs)-1]
+	t.lastIDs = t.lastIDs[:len(t.lastIDs)-1]
+}
+
+func (t *thriftWriter) fieldStructBegin(id int16) {
+	t.fieldHeader(id, thriftTypeStruct)
+	t.structBegin()
+}
+
+func (t *thriftWriter) fieldI32(id int16, v int32) {
+	t.fieldHeader(id, thriftTypeI32)
+	t.writeVarint(zigzag32(v))
+}
+
+func (t *thriftWriter) fieldI64(id int16, v int64) {
+	t.fieldHeader(id, thriftTypeI64)
+	t.writeVarint(zigzag64(v))
+}
+
+func (t *thriftWriter) writeBinary(s string) {
+	t.writeVarint(uint64(len(s)))
+	t.w.WriteString(s)
+}
+
+func (t *thriftWriter) fieldString(id int16, s string) {
+	t.fieldHeader(id, thriftTypeBinary)
+	t.writeBinary(s)
+}
+
+// fieldListBegin writes a list field header; elements follow directly
+// (structs via structBegin/structEnd, scalars via writeVarint/writeBinary).
+func (t *thriftWriter) fieldListBegin(id int16, elemType byte, size int) {
+	t.fieldHeader(id, thriftTypeList)
+	if size < 15 {
+		t.w.WriteByte(byte(size)<<4 | elemType)
+	} else {
+		t.w.WriteByte(0xF0 | elemType)
+		t.writeVarint(uint64(size))
+	}
+}
+
+// thriftReader decodes compact-protocol structs into generic maps keyed by
+// field id. Binary values are returned as []byte, integers as int64.
+type thriftReader struct {
+	data []byte
+	pos  int
+}
+
+func (t *thriftReader) readByte() (byte, error) {
+	if t.pos >= len(t.data) {
+		return 0, io.ErrUnexpectedEOF
+	}
+	b := t.data[t.pos]
+	t.pos++
+	return b, nil
+}
+
+func (t *thriftReader) readVarint() (uint64, error) {
+	var v uint64
+	for shift := uint(0); shift < 64; shift += 7 {
+		b, err := t.readByte()
+		if err != nil {
+			return 0, err
+		}
+		v |= uint64(b&0x7F) << shift
+		if b&0x80 == 0 {
+			return v, nil
+		}
+	}
+	return 0, fmt.Errorf("varint overflow")
+}
+
+func (t *thriftReader) readZigzag() (int64, error) {
+	v, err := t.readVarint()
+	if err != nil {
+		return 0, err
+	}
+	return int64(v>>1) ^ -int64(v&1), nil
+}
+
+func (t *thriftReader) readValue(typ byte) (interface{}, error) {
+	switch typ {
+	case 1:
+		return true, nil
+	case 2:
+		return false, nil
+	case 3:
+		b, err := t.readByte()
+		return int64(int8(b)), err
+	case 4, thriftTypeI32, thriftTypeI64:
+		return t.readZigzag()
+	case 7:
+		if t.pos+8 > len(t.data) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		v := math.Float64frombits(binary.LittleEndian.Uint64(t.data[t.pos:]))
+		t.pos += 8
+		return v, nil
+	case thriftTypeBinary:
+		l, err := t.readVarint()
+		if err != nil {
+			return nil, err
+		}
+		if t.pos+int(l) > len(t.data) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		v := t.data[t.pos : t.pos+int(l)]
+		t.pos += int(l)
+		return v, nil
+	case thriftTypeList, 10:
+		h, err := t.readByte()
+		if err != nil {
+			return nil, err
+		}
+		size := int(h >> 4)
+		elemType := h & 0x0F
+		if size == 15 {
+			s, err := t.readVarint()
+			if err != nil {
+				return nil, err
+			}
+			size = int(s)
+		}
+		list := make([]interface{}, 0, size)
+		for i := 0; i < size; i++ {
+			var v interface{}
+			var err error
+			if elemType == 1 || elemType == 2 {
+				b, e := t.readByte()
+				v, err = b == 1, e
+			} else {
+				v, err = t.readValue(elemType)
+			}
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		return list, nil
+	case thriftTypeStruct:
+		return t.readStruct()
+	default:
+		return nil, fmt.Errorf("unsupported thrift type %d", typ)
+	}
+}
+
+func (t *thriftReader) readStruct() (map[int16]interface{}, error) {
+	fields := make(map[int16]interface{})
+	var lastID int16
+	for {
+		h, err := t.readByte()
+		if err != nil {
+			return nil, err
+		}
+		if h == 0 {
+			return fields, nil
+		}
+		typ := h & 0x0F
+		delta := int16(h >> 4)
+		id := lastID + delta
+		if delta == 0 {
+			v, err := t.readZigzag()
+			if err != nil {
+				return nil, err
+			}
+			id = int16(v)
+		}
+		v, err := t.readValue(typ)
+		if err != nil {
+			return nil, err
+		}
+		fields[id] = v
+		lastID = id
+	}
+}
+
+func thriftInt(v interface{}) int64 {
+	n, _ := v.(int64)
+	return n
+}
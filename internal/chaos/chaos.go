@@ -0,0 +1,183 @@
+package chaos
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"im-ai-voice/internal/config"
+)
+
+// ==================== FAULT INJECTION ====================
+// For resilience testing in staging: CHAOS_FAULTS makes dependencies fail
+// at random, so the watcher's retries and the MongoDB-first, local-fallback
+// reads can be watched handling it before production relies on them. Each
+// fault has a probability between 0 and 1:
+//
+//	CHAOS_FAULTS="gemini_429=0.2,gemini_timeout=0.05,mongo_write=0.1,slow_disk=0.5"
+//
+// gemini_429 answers Gemini requests with a rate-limit response without
+// sending them, gemini_timeout holds them until the caller's deadline
+// passes, mongo_write fails MongoDB writes before they're sent, and
+// slow_disk delays local file writes by CHAOS_DISK_DELAY. Unset, nothing is
+// injected and every check is a map lookup.
+
+// Faults
+const (
+	GeminiRateLimit = "gemini_429"
+	GeminiTimeout   = "gemini_timeout"
+	MongoWrite      = "mongo_write"
+	SlowDisk        = "slow_disk"
+)
+
+var knownFaults = map[string]bool{GeminiRateLimit: true, GeminiTimeout: true, MongoWrite: true, SlowDisk: true}
+
+var (
+	probabilities = loadFaults()
+	diskDelay     = loadDiskDelay()
+
+	mu       sync.Mutex
+	injected = map[string]int{}
+)
+
+// loadFaults parses CHAOS_FAULTS, skipping malformed entries
+func loadFaults() map[string]float64 {
+	v := os.Getenv("CHAOS_FAULTS")
+	if v == "" {
+		return nil
+	}
+	faults := make(map[string]float64)
+	for _, pair := range strings.Split(v, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		name = strings.ToLower(strings.TrimSpace(name))
+		p, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if !ok || !knownFaults[name] || err != nil || p < 0 || p > 1 {
+			log.Printf("⚠️ Ignoring invalid CHAOS_FAULTS entry %q", pair)
+			continue
+		}
+		if p > 0 {
+			faults[name] = p
+		}
+	}
+	for name, p := range faults {
+		log.Printf("💥 Fault injection on: %s with probability %.2f", name, p)
+	}
+	return faults
+}
+
+func loadDiskDelay() time.Duration {
+	if v := os.Getenv("CHAOS_DISK_DELAY"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			return d
+		}
+		log.Printf("⚠️ Invalid CHAOS_DISK_DELAY=%q, using %v", v, config.DEFAULT_CHAOS_DISK_DELAY)
+	}
+	return config.DEFAULT_CHAOS_DISK_DELAY
+}
+
+// Enabled reports whether any fault is configured
+func Enabled() bool {
+	return len(probabilities) > 0
+}
+
+// Should reports whether to inject fault this time, and counts it if so
+func Should(fault string) bool {
+	p := probabilities[fault]
+	if p == 0 || rand.Float64() >= p {
+		return false
+	}
+	mu.Lock()
+	injected[fault]++
+	mu.Unlock()
+	return true
+}
+
+// Injected returns how many times each fault was injected since startup, nil when none are configured
+func Injected() map[string]int {
+	if !Enabled() {
+		return nil
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	counts := make(map[string]int, len(probabilities))
+	for name := range probabilities {
+		counts[name] = injected[name]
+	}
+	return counts
+}
+
+// Error is returned in place of an operation's real outcome
+type Error struct {
+	Fault string
+	Op    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("injected fault %s: %s", e.Fault, e.Op)
+}
+
+// MongoWriteError returns an injected failure for a MongoDB write, or nil
+func MongoWriteError(op string) error {
+	if !Should(MongoWrite) {
+		return nil
+	}
+	log.Printf("💥 Injected MongoDB write failure: %s", op)
+	return &Error{Fault: MongoWrite, Op: op}
+}
+
+// DelayDisk sleeps before a local file write when slow_disk is injected
+func DelayDisk() {
+	if Should(SlowDisk) {
+		time.Sleep(diskDelay)
+	}
+}
+
+// Transport wraps Gemini's HTTP transport with the gemini_* faults
+func Transport(base http.RoundTripper) http.RoundTripper {
+	if probabilities[GeminiRateLimit] == 0 && probabilities[GeminiTimeout] == 0 {
+		return base
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return geminiTransport{base: base}
+}
+
+type geminiTransport struct {
+	base http.RoundTripper
+}
+
+// rateLimitBody is what Gemini sends with a 429
+const rateLimitBody = `{"error":{"code":429,"message":"Resource has been exhausted (injected fault)","status":"RESOURCE_EXHAUSTED"}}`
+
+func (t geminiTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodPost {
+		return t.base.RoundTrip(req) // Key checks at startup aren't faulted
+	}
+	if Should(GeminiRateLimit) {
+		log.Printf("💥 Injected Gemini 429")
+		return &http.Response{
+			Status:     "429 Too Many Requests",
+			StatusCode: http.StatusTooManyRequests,
+			Proto:      "HTTP/1.1", ProtoMajor: 1, ProtoMinor: 1,
+			Header:        http.Header{"Content-Type": []string{"application/json"}, "Retry-After": []string{"1"}},
+			Body:          io.NopCloser(bytes.NewReader([]byte(rateLimitBody))),
+			ContentLength: int64(len(rateLimitBody)),
+			Request:       req,
+		}, nil
+	}
+	if Should(GeminiTimeout) {
+		log.Printf("💥 Injected Gemini timeout")
+		<-req.Context().Done() // The client's timeout or the caller's deadline
+		return nil, req.Context().Err()
+	}
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,348 @@
+package classifier
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+)
+
+// ==================== KEYWORD CLASSIFIER ====================
+// A deterministic analysis that needs no LLM: issues by the buckets whose
+// keywords or patterns the transcript matches, sentiment by positive and
+// negative cues, and churn risk by cancellation cues. Transcripts are mostly
+// Hinglish, so the built-in rules give cues in English and romanized Hindi.
+// CLASSIFIER_RULES replaces them with a JSON file of the same shape:
+//
+//	{
+//	  "buckets": [{"bucket": "Lead Quality", "keywords": ["fake lead"], "patterns": ["\\bspam(my)?\\b"], "severity": "high"}],
+//	  "negative": {"keywords": ["bakwas"]},
+//	  "positive": {"keywords": ["thank"]},
+//	  "churn": {"keywords": ["cancel"]}
+//	}
+//
+// Keywords match in the lowercased transcript at the start of a word, so
+// stems match their endings; patterns are regular expressions, matched
+// ignoring case. It's rough by design: the analysis Gemini is down for
+// (degraded mode), the only one with ANALYSIS_ENGINE=rules, and the baseline
+// LLM analyses are checked against.
+
+// Cues are what a rule matches: keywords and regular expressions
+type Cues struct {
+	Keywords []string `json:"keywords,omitempty"`
+	Patterns []string `json:"patterns,omitempty"`
+}
+
+// BucketRule files an issue under a bucket when its cues match
+type BucketRule struct {
+	Bucket   string `json:"bucket"`
+	Severity string `json:"severity,omitempty"` // Of the issue, medium when unset; a call with churn cues makes it high
+	Cues
+}
+
+// Rules configure the classifier
+type Rules struct {
+	Buckets  []BucketRule `json:"buckets"`
+	Negative Cues         `json:"negative"`
+	Positive Cues         `json:"positive"`
+	Churn    Cues         `json:"churn"`
+}
+
+// DefaultRules are the built-in rules
+func DefaultRules() Rules {
+	return Rules{
+		Buckets: []BucketRule{
+			{Bucket: "Lead Quality", Cues: Cues{Keywords: []string{"fake lead", "fake enquir", "fake inquir", "irrelevant", "wrong lead", "bekar lead", "faltu lead", "not genuine", "genuine nahi", "spam"}}},
+			{Bucket: "Lead Quantity", Cues: Cues{Keywords: []string{"no lead", "lead nahi", "leads nahi", "enquiry nahi", "inquiry nahi", "kam lead", "less lead", "fewer lead", "koi enquiry", "koi inquiry"}}},
+			{Bucket: "Lead Management", Cues: Cues{Keywords: []string{"lms", "lead manager", "missed call", "call forward", "pns"}}},
+			{Bucket: "Promoted Listing / Lead Priority", Cues: Cues{Keywords: []string{"promoted listing", "maximiser", "maximizer", "priority listing"}}},
+			{Bucket: "Visibility / Ranking", Cues: Cues{Keywords: []string{"ranking", "visibility", "not showing", "dikh nahi", "search me nahi", "search mein nahi", "listing nahi"}}},
+			{Bucket: "TrustSEAL / Verification", Cues: Cues{Keywords: []string{"trustseal", "trust seal"}}},
+			{Bucket: "Catalog / Storefront Setup", Cues: Cues{Keywords: []string{"catalog", "catalogue", "product add", "photo upload", "image upload", "storefront"}}},
+			{Bucket: "Buyer Interaction", Cues: Cues{Keywords: []string{"buyer reply", "buyer response", "buyer not respond", "buyer ne jawab", "buyer phone nahi"}}},
+			{Bucket: "BizInsight Analytics", Cues: Cues{Keywords: []string{"bizinsight", "biz insight", "analytics"}}},
+			{Bucket: "Billing & Renewal", Cues: Cues{Keywords: []string{"renewal", "invoice", "billing", "overcharg", "extra charge", "deduct", "paise kat", "refund"}}},
+			{Bucket: "Payments", Cues: Cues{Keywords: []string{"payment", "upi", "emi", "cheque", "transaction"}}},
+			{Bucket: "App / Platform Usability", Cues: Cues{Keywords: []string{"app crash", "app nahi", "not working", "kaam nahi kar", "login", "otp", "error aa", "bug"}}},
+			{Bucket: "Support / Training", Cues: Cues{Keywords: []string{"no callback", "call back nahi", "callback nahi", "no response", "koi response nahi", "training"}}},
+			{Bucket: "Seller Verification", Cues: Cues{Keywords: []string{"gst", "kyc", "verification pending"}}},
+			{Bucket: "Compliance / Documentation", Cues: Cues{Keywords: []string{"document", "agreement", "compliance", "certificate"}}},
+			{Bucket: "Category-City Targeting", Cues: Cues{Keywords: []string{"wrong city", "city change", "wrong category", "category change", "mcat"}}},
+			{Bucket: "Account / Dashboard", Cues: Cues{Keywords: []string{"dashboard", "password", "account block", "account band"}}},
+		},
+		Negative: Cues{Keywords: []string{"not happy", "unhappy", "disappointed", "worst", "bakwas", "bekar", "pareshan", "frustrat", "cheat", "fraud", "waste", "complaint", "gussa", "angry", "koi fayda nahi", "no benefit"}},
+		Positive: Cues{Keywords: []string{"thank", "dhanyavad", "dhanyawad", "shukriya", "happy", "satisfied", "khush", "great", "helpful", "badhiya", "accha laga", "achha laga", "resolved"}},
+		Churn:    Cues{Keywords: []string{"cancel", "band kar", "discontinue", "refund", "not renew", "renew nahi", "chhod", "switch to", "tradeindia", "justdial"}},
+	}
+}
+
+// matcher is compiled Cues
+type matcher struct {
+	keywords []string
+	patterns []*regexp.Regexp
+}
+
+func compileCues(c Cues) (matcher, error) {
+	m := matcher{}
+	for _, k := range c.Keywords {
+		if k = strings.ToLower(strings.TrimSpace(k)); k != "" {
+			m.keywords = append(m.keywords, k)
+		}
+	}
+	for _, p := range c.Patterns {
+		re, err := regexp.Compile("(?i)" + p)
+		if err != nil {
+			return m, fmt.Errorf("pattern %q: %w", p, err)
+		}
+		m.patterns = append(m.patterns, re)
+	}
+	return m, nil
+}
+
+// match returns the keywords found in text (lowercased) at the start of a
+// word, in the order given, then the first match of each pattern
+func (m matcher) match(text string) []string {
+	var matched []string
+	for _, cue := range m.keywords {
+		for rest, offset := text, 0; ; {
+			i := strings.Index(rest, cue)
+			if i < 0 {
+				break
+			}
+			if at := offset + i; at == 0 || !unicode.IsLetter(lastRune(text[:at])) {
+				matched = append(matched, cue)
+				break
+			}
+			rest, offset = rest[i+len(cue):], offset+i+len(cue)
+		}
+	}
+	for _, re := range m.patterns {
+		if s := re.FindString(text); s != "" && !slices.Contains(matched, s) {
+			matched = append(matched, s)
+		}
+	}
+	return matched
+}
+
+func lastRune(s string) rune {
+	r, _ := utf8.DecodeLastRuneInString(s)
+	return r
+}
+
+// Classifier analyzes transcripts by Rules
+type Classifier struct {
+	buckets  []bucketMatcher
+	negative matcher
+	positive matcher
+	churn    matcher
+}
+
+type bucketMatcher struct {
+	bucket, severity string
+	matcher
+}
+
+// New compiles rules, which must name known buckets and severities
+func New(r Rules) (*Classifier, error) {
+	c := &Classifier{}
+	seen := make(map[string]bool, len(r.Buckets))
+	for _, b := range r.Buckets {
+		switch {
+		case !slices.Contains(config.FeatureBuckets, b.Bucket):
+			return nil, fmt.Errorf("unknown bucket %q", b.Bucket)
+		case seen[b.Bucket]:
+			return nil, fmt.Errorf("bucket %q has two rules", b.Bucket)
+		case b.Severity != "" && !slices.Contains([]string{"low", "medium", "high", "critical"}, b.Severity):
+			return nil, fmt.Errorf("bucket %q: invalid severity %q", b.Bucket, b.Severity)
+		}
+		seen[b.Bucket] = true
+		m, err := compileCues(b.Cues)
+		if err != nil {
+			return nil, fmt.Errorf("bucket %q: %w", b.Bucket, err)
+		}
+		c.buckets = append(c.buckets, bucketMatcher{bucket: b.Bucket, severity: b.Severity, matcher: m})
+	}
+	for _, cues := range []struct {
+		name string
+		in   Cues
+		out  *matcher
+	}{
+		{"negative", r.Negative, &c.negative},
+		{"positive", r.Positive, &c.positive},
+		{"churn", r.Churn, &c.churn},
+	} {
+		m, err := compileCues(cues.in)
+		if err != nil {
+			return nil, fmt.Errorf("%s cues: %w", cues.name, err)
+		}
+		*cues.out = m
+	}
+	return c, nil
+}
+
+// Default returns the classifier of the built-in rules
+func Default() *Classifier {
+	c, err := New(DefaultRules())
+	if err != nil {
+		panic(err) // The built-in rules are fixed
+	}
+	return c
+}
+
+// Load reads rules from a JSON file. A missing file gives the built-in rules.
+func Load(path string) (*Classifier, error) {
+	b, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return Default(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var r Rules
+	if err := json.Unmarshal(b, &r); err != nil {
+		return nil, fmt.Errorf("invalid classifier rules %s: %w", path, err)
+	}
+	c, err := New(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid classifier rules %s: %w", path, err)
+	}
+	log.Printf("🧮 Classifier rules: %d buckets from %s", len(r.Buckets), path)
+	return c, nil
+}
+
+// FromEnv loads CLASSIFIER_RULES, with the built-in rules if it's invalid
+func FromEnv() *Classifier {
+	path := os.Getenv("CLASSIFIER_RULES")
+	if path == "" {
+		path = config.DEFAULT_CLASSIFIER_RULES
+	}
+	c, err := Load(path)
+	if err != nil {
+		log.Printf("⚠️ %v - using the built-in rules", err)
+		return Default()
+	}
+	return c
+}
+
+// RulesOnly reports whether ANALYSIS_ENGINE has calls analyzed by the
+// classifier alone, without Gemini
+func RulesOnly() bool {
+	v := os.Getenv("ANALYSIS_ENGINE")
+	if v == "" {
+		v = config.DEFAULT_ANALYSIS_ENGINE
+	}
+	switch strings.ToLower(v) {
+	case client.EngineRules:
+		return true
+	case "gemini":
+		return false
+	}
+	log.Printf("⚠️ Invalid ANALYSIS_ENGINE=%q, using %s", v, config.DEFAULT_ANALYSIS_ENGINE)
+	return config.DEFAULT_ANALYSIS_ENGINE == client.EngineRules
+}
+
+// Classify analyzes a transcript by the rules. The analysis has what the
+// rules can tell, issues, sentiment and churn risk, with Engine set to
+// client.EngineRules.
+func (c *Classifier) Classify(rt client.RawTranscript) *client.AnalysisResult {
+	text := strings.ToLower(rt.Transcript)
+
+	negative, positive := c.negative.match(text), c.positive.match(text)
+	churn := c.churn.match(text)
+	sentiment, satisfaction, experience := "Neutral", 5, "Average"
+	switch {
+	case len(negative)+len(churn) > len(positive):
+		sentiment, satisfaction, experience = "Negative", 3, "Poor"
+	case len(positive) > 0 && len(negative) == 0:
+		sentiment, satisfaction, experience = "Positive", 7, "Good"
+	}
+	churnRisk, renewal := "low", 0.8
+	switch {
+	case len(churn) > 0:
+		churnRisk, renewal = "high", 0.3
+	case sentiment == "Negative":
+		churnRisk, renewal = "medium", 0.5
+	}
+
+	issues := []client.Issue{}
+	for _, b := range c.buckets {
+		matched := b.match(text)
+		if len(matched) == 0 {
+			continue
+		}
+		severity := b.severity
+		if severity == "" {
+			severity = "medium"
+		}
+		if churnRisk == "high" && (severity == "low" || severity == "medium") {
+			severity = "high"
+		}
+		issues = append(issues, client.Issue{
+			Problem:           fmt.Sprintf("Mentioned %s (keyword match)", strings.Join(matched, ", ")),
+			Bucket:            b.bucket,
+			Severity:          severity,
+			ActionableSummary: "Review the call for the details",
+			Keywords:          matched,
+		})
+	}
+
+	a := &client.AnalysisResult{
+		CallID: rt.CallID, SellerID: rt.SellerID, Timestamp: rt.Timestamp, Channel: rt.Channel,
+		TranscriptEn: rt.Transcript, OriginalLang: rt.Language,
+		Issues: issues,
+		Intent: client.SellerIntent{Sentiment: sentiment, SatisfactionScore: satisfaction, OverallExperience: experience},
+		Churn: client.ChurnPrediction{
+			IsLikelyToChurn:      churnRisk,
+			RenewalAtRisk:        churnRisk == "high",
+			DissatisfactionLevel: churnRisk,
+			RenewalProbability:   renewal,
+		},
+		CallSummary: fmt.Sprintf("Keyword analysis: %d issue(s) matched, %s sentiment", len(issues), strings.ToLower(sentiment)),
+		Acoustic:    rt.Acoustic,
+		Engine:      client.EngineRules,
+		AnalyzedAt:  time.Now(),
+	}
+	if len(churn) > 0 {
+		a.Churn.ChurnReason = "Mentioned " + strings.Join(churn, ", ")
+	}
+	return a
+}
+
+// CrossCheck compares an LLM analysis of rt with the rules' reading of it.
+// Buckets without a rule can't disagree, so only those with one are compared.
+func (c *Classifier) CrossCheck(rt client.RawTranscript, analysis *client.AnalysisResult) *client.RulesCheck {
+	rules := c.Classify(rt)
+	check := &client.RulesCheck{RulesSentiment: rules.Intent.Sentiment}
+	s := map[string]bool{analysis.Intent.Sentiment: true, rules.Intent.Sentiment: true}
+	check.SentimentConflict = s["Positive"] && s["Negative"]
+
+	raised := make(map[string]bool)
+	for _, issue := range analysis.Issues {
+		raised[issue.Bucket] = true
+	}
+	matched := make(map[string]bool)
+	for _, issue := range rules.Issues {
+		matched[issue.Bucket] = true
+	}
+	for _, b := range c.buckets {
+		switch {
+		case raised[b.bucket] && matched[b.bucket]:
+			check.AgreedBuckets = append(check.AgreedBuckets, b.bucket)
+		case raised[b.bucket]:
+			check.LLMOnlyBuckets = append(check.LLMOnlyBuckets, b.bucket)
+		case matched[b.bucket]:
+			check.RulesOnlyBuckets = append(check.RulesOnlyBuckets, b.bucket)
+		}
+	}
+	check.Disagrees = check.SentimentConflict || len(check.LLMOnlyBuckets) > 0 || len(check.RulesOnlyBuckets) > 0
+	return check
+}
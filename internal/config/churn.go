@@ -0,0 +1,51 @@
+package config
+
+import "strings"
+
+// Churn reason taxonomy - the LLM picks one per call alongside the free-text
+// churn_reason, so reasons can be counted
+var ChurnReasonCategories = []string{
+	"pricing",            // Subscription cost, renewal price, no return on spend
+	"lead_quality",       // Irrelevant, fake or too few BuyLeads and enquiries
+	"competitor",         // Moving to another marketplace or channel
+	"service_experience", // Support, account manager or platform problems
+	"business_closed",    // Shutting down, pausing or changing business
+	"other",
+}
+
+// churnReasonKeywords classify free-text reasons from analyses made before
+// the taxonomy existed, or when the LLM's category isn't one of ours
+var churnReasonKeywords = map[string][]string{
+	"pricing":            {"price", "pricing", "cost", "expensive", "afford", "fees", "renewal amount", "return on", "money"},
+	"lead_quality":       {"lead", "enquir", "inquir", "buyer", "fake", "irrelevant", "spam"},
+	"competitor":         {"competitor", "tradeindia", "justdial", "udaan", "amazon", "alibaba", "other platform", "another platform", "switch"},
+	"service_experience": {"support", "service", "response", "callback", "agent", "account manager", "unresolved", "technical"},
+	"business_closed":    {"closed", "closing", "shut", "shutting", "retire", "stopped business", "wind up", "winding"},
+}
+
+// ClassifyChurnReason returns the taxonomy category for a churn prediction:
+// the LLM's category if it's one of ours, otherwise a keyword match on the
+// free text, "other" if nothing matches, and "" when there's no reason at all
+func ClassifyChurnReason(category, reason string) string {
+	category = strings.ToLower(strings.TrimSpace(category))
+	category = strings.NewReplacer(" ", "_", "-", "_").Replace(category)
+	for _, c := range ChurnReasonCategories {
+		if category == c {
+			return c
+		}
+	}
+
+	text := strings.ToLower(strings.TrimSpace(reason))
+	switch text {
+	case "", "none", "n/a", "na", "-":
+		return ""
+	}
+	for _, c := range ChurnReasonCategories {
+		for _, kw := range churnReasonKeywords[c] {
+			if strings.Contains(text, kw) {
+				return c
+			}
+		}
+	}
+	return "other"
+}
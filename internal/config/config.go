@@ -0,0 +1,567 @@
+package config
+
+import (
+	"log"
+	"os"
+	"time"
+	_ "time/tzdata" // Business timezone lookups work without system zoneinfo
+)
+
+// Data directories, under STORAGE_BASE unless moved on their own with the
+// environment variable of the same name (e.g. TRANSCRIPTS_DIR=/mnt/incoming)
+var (
+	TRANSCRIPTS_DIR      = dataDir("TRANSCRIPTS_DIR", "transcripts")
+	ANALYSIS_DIR         = dataDir("ANALYSIS_DIR", "analysis")
+	AGGREGATES_DIR       = dataDir("AGGREGATES_DIR", "aggregates")
+	TICKETS_DIR          = dataDir("TICKETS_DIR", "tickets")
+	ARCHIVE_DIR          = dataDir("ARCHIVE_DIR", "archive")
+	ALERTS_DIR           = dataDir("ALERTS_DIR", "alerts")
+	EVENTS_DIR           = dataDir("EVENTS_DIR", "events")
+	PROFILES_DIR         = dataDir("PROFILES_DIR", "profiles")
+	METRICS_DIR          = dataDir("METRICS_DIR", "metrics")
+	AUDIT_DIR            = dataDir("AUDIT_DIR", "audit")
+	RECORDINGS_DIR       = dataDir("RECORDINGS_DIR", "recordings")
+	GITHUB_DIR           = dataDir("GITHUB_DIR", "github")                     // Bucket to GitHub issue links
+	ATTENTION_DIR        = dataDir("ATTENTION_DIR", "attention")               // Acknowledged and snoozed attention flags
+	LLM_CACHE_DIR        = dataDir("LLM_CACHE_DIR", "llm_cache")               // Analyses kept as LLM output for replays
+	EXTRACTIONS_DIR      = dataDir("EXTRACTIONS_DIR", "extractions")           // First-pass extractions, rescored by replays
+	SYSTEMIC_DIR         = dataDir("SYSTEMIC_DIR", "systemic")                 // Latest cross-seller issue clusters
+	LLM_RAW_DIR          = dataDir("LLM_RAW_DIR", "llm_raw")                   // Raw Gemini responses, split off analyses
+	LLM_INTERACTIONS_DIR = dataDir("LLM_INTERACTIONS_DIR", "llm_interactions") // Recorded analysis requests and responses, by prompt version
+	SUPPRESSIONS_DIR     = dataDir("SUPPRESSIONS_DIR", "suppressions")         // Ticket mute rules
+	KB_DIR               = dataDir("KB_DIR", "kb")                             // Knowledge base documents and their embeddings
+	COMMITMENTS_DIR      = dataDir("COMMITMENTS_DIR", "commitments")           // Agent promises and whether they were kept
+	PITCHES_DIR          = dataDir("PITCHES_DIR", "pitches")                   // Agents' upsell pitches and whether they converted
+	QUOTES_DIR           = dataDir("QUOTES_DIR", "quotes")                     // Redacted seller quotes per call, and sellers who opted out
+	TRASH_DIR            = dataDir("TRASH_DIR", "trash")                       // Soft-deleted analyses and tickets
+	ANNOTATIONS_DIR      = dataDir("ANNOTATIONS_DIR", "annotations")           // QA reviewers' comments on call transcripts
+	OWNERS_DIR           = dataDir("OWNERS_DIR", "owners")                     // Feature bucket owners tickets are routed to
+	FLAGS_DIR            = dataDir("FLAGS_DIR", "flags")                       // Feature flags set through /admin/flags
+	CUSTOM_FIELDS_DIR    = dataDir("CUSTOM_FIELDS_DIR", "custom_fields")       // Custom field definitions set through /admin/custom-fields
+	SNAPSHOTS_DIR        = dataDir("SNAPSHOTS_DIR", "dashboard_snapshots")     // Dashboards as first computed after their day ended, by date
+	SAMPLES_DIR          = dataDir("SAMPLES_DIR", "samples")                   // Anonymized example calls for training and demos
+	RECLASSIFY_DIR       = dataDir("RECLASSIFY_DIR", "reclassifications")      // Taxonomy migration runs and the records each changed
+	CALL_CHATS_DIR       = dataDir("CALL_CHATS_DIR", "call_chats")             // Reviewers' conversations about calls, until they expire
+	CHURN_OUTCOMES_DIR   = dataDir("CHURN_OUTCOMES_DIR", "churn_outcomes")     // Sellers' actual renewals and cancellations
+	PROCESSED_DIR        = dataDir("PROCESSED_DIR", "processed")               // Watched transcripts once processed, by date
+	VERSIONS_DIR         = dataDir("VERSIONS_DIR", "versions")                 // Analyses superseded when their transcript was rewritten
+	FAILED_DIR           = dataDir("FAILED_DIR", "failed")                     // Watched transcripts that couldn't be processed, with an error sidecar
+	SHADOW_DIR           = dataDir("SHADOW_DIR", "shadow")                     // Candidate model/prompt analyses of sampled calls, by date
+	ROLLOUTS_DIR         = dataDir("ROLLOUTS_DIR", "rollouts")                 // Canary rollouts of prompt/model changes
+	THEMES_DIR           = dataDir("THEMES_DIR", "themes")                     // Weekly themes of calls' key insights, by week end date
+	CALIBRATION_DIR      = dataDir("CALIBRATION_DIR", "calibration")           // Severity calibration runs, by run date
+	KEY_USAGE_DIR        = dataDir("KEY_USAGE_DIR", "key_usage")               // Monthly usage counters per API key
+	WEBHOOKS_DIR         = dataDir("WEBHOOKS_DIR", "webhooks")                 // Subscriptions to seller profile transitions
+	ALERT_SUBS_DIR       = dataDir("ALERT_SUBS_DIR", "alert_subscriptions")    // Alert subscriptions scoped to cities and verticals
+	VAULT_DIR            = dataDir("VAULT_DIR", "vault")                       // Encrypted PII values behind transcript tokens
+	INGEST_JOBS_DIR      = dataDir("INGEST_JOBS_DIR", "ingest_jobs")           // Async ingestions' analysis jobs and their callbacks
+	JOBS_DIR             = dataDir("JOBS_DIR", "jobs")                         // Records of long-running operations
+	PROVISIONAL_DIR      = dataDir("PROVISIONAL_DIR", "provisional")           // Calls analyzed provisionally, waiting for Gemini
+	EVAL_DIR             = dataDir("EVAL_DIR", "eval")                         // Eval runs' metrics per model and prompt version
+	CALL_INDEX_DIR       = dataDir("CALL_INDEX_DIR", "call_index")             // GET /calls listing fields of the analysis files
+)
+
+const (
+	SERVER_LISTEN_ADDR = ":8080"
+
+	DEFAULT_ARCHIVE_AFTER_DAYS  = 90 // Override with ARCHIVE_AFTER_DAYS
+	DEFAULT_ESCALATION_AGE_DAYS = 14 // High-severity issues open longer are escalated, override with ESCALATION_AGE_DAYS
+	DEFAULT_DIGEST_HOUR         = 8  // Local hour for the morning digest, override with DIGEST_HOUR
+	DEFAULT_WEEKLY_REPORT_HOUR  = 9  // Local hour on Mondays for the weekly report, override with WEEKLY_REPORT_HOUR
+	DEFAULT_NOTIFY_DIGEST_HOUR  = 9  // Local hour daily notification digests are posted, override with NOTIFY_DIGEST_HOUR
+	DEFAULT_SYSTEMIC_HOUR       = 2  // Local hour of the nightly systemic issue clustering, override with SYSTEMIC_HOUR
+
+	DEFAULT_SYSTEMIC_SIMILARITY  = 0.85 // Cosine similarity for an issue to join a cluster, override with SYSTEMIC_SIMILARITY
+	DEFAULT_SYSTEMIC_MIN_SELLERS = 5    // Sellers a cluster needs to be systemic, override with SYSTEMIC_MIN_SELLERS
+	SYSTEMIC_MAX_TICKETS         = 5    // Systemic issues turned into tickets per aggregation, most sellers first
+
+	DEFAULT_TICKET_RANKING       = "impact"                      // Tickets ranked by revenue at risk ("impact") or issue count ("count"), override with TICKET_RANKING
+	DEFAULT_IMPACT_CHURN_WEIGHTS = "low=0.1,medium=0.3,high=0.6" // Share of a seller's plan value at risk per churn risk, override with IMPACT_CHURN_WEIGHTS
+
+	DEFAULT_NOTIFY_DIGEST_BYPASS = "critical"  // Severity from which alerts and tickets skip digests, override with NOTIFY_DIGEST_BYPASS
+	NOTIFY_DIGEST_MAX_LINES      = 25          // Notifications listed in a digest's text; the rest are only in its items
+	NOTIFY_DIGEST_CHECK_INTERVAL = time.Minute // How often windows are checked for digests due
+
+	DEFAULT_THEMES_HOUR      = 3    // Local hour of the nightly key insight themes run, override with THEMES_HOUR
+	DEFAULT_THEME_SIMILARITY = 0.85 // Cosine similarity for an insight to join a theme, override with THEME_SIMILARITY
+	DEFAULT_THEME_MIN_CALLS  = 3    // Calls a theme needs, override with THEME_MIN_CALLS
+	THEME_WINDOW_DAYS        = 7    // Days of insights in a week's themes, ending on the date
+	THEMES_MAX               = 10   // Themes kept per week, most calls first
+
+	SEVERITY_CALIBRATION_DAYS          = 90  // Issues first reported in this many days before the run are calibrated
+	SEVERITY_CALIBRATION_MATURITY_DAYS = 14  // ... except the latest ones, whose outcomes aren't in yet
+	SEVERITY_CALIBRATION_SLOW_DAYS     = 14  // An issue resolved later than this, or not at all, was slow
+	SEVERITY_CALIBRATION_MIN_ISSUES    = 20  // Issues a bucket needs to be calibrated
+	SEVERITY_CALIBRATION_SKEW          = 0.5 // Average severity levels between rating and outcome that make a bucket over- or under-rated
+
+	CHURN_OUTCOMES_MAX                 = 5000 // Outcomes a single POST /churn-outcomes may record
+	DEFAULT_CHURN_ACCURACY_WINDOW_DAYS = 90   // Days before an outcome its seller's prediction may be from, override with CHURN_ACCURACY_WINDOW_DAYS
+	CHURN_ACCURACY_MAX_DAYS            = 366  // Longest outcome date range of one churn accuracy report
+	CHURN_ACCURACY_MIN_OUTCOMES        = 30   // Outcomes a prediction version needs for a verdict
+	CHURN_ACCURACY_MIN_LIFT            = 1.5  // Cancel rate of sellers put at risk over the base rate that makes a version useful
+	CHURN_ACCURACY_CALIBRATION_BINS    = 10   // Bands of renewal probability in a calibration curve
+
+	DEFAULT_GEMINI_INPUT_PRICE  = 0.10 // USD per million prompt tokens, override with GEMINI_INPUT_PRICE
+	DEFAULT_GEMINI_OUTPUT_PRICE = 0.40 // USD per million output tokens, override with GEMINI_OUTPUT_PRICE
+
+	DEFAULT_MONGO_OP_TIMEOUT    = 5 * time.Second  // Single-document reads/writes, override with MONGO_OP_TIMEOUT
+	DEFAULT_MONGO_QUERY_TIMEOUT = 10 * time.Second // Multi-document queries, override with MONGO_QUERY_TIMEOUT
+	DEFAULT_MONGO_SCAN_TIMEOUT  = 60 * time.Second // Full-collection scans, override with MONGO_SCAN_TIMEOUT
+
+	DEFAULT_PROFILE_CACHE_SIZE = 1000             // Seller profiles kept in memory, override with PROFILE_CACHE_SIZE (0 disables)
+	DEFAULT_PROFILE_CACHE_TTL  = 5 * time.Minute  // Max age of a cached profile, override with PROFILE_CACHE_TTL
+	DEFAULT_KNOWN_SELLERS_TTL  = 10 * time.Minute // Max age of the known-sellers filter before it is reloaded, override with KNOWN_SELLERS_TTL ("0" disables the filter)
+
+	DEFAULT_DASHBOARD_CACHE_TTL = time.Minute      // Max age of a cached dashboard aggregate or ticket list, override with DASHBOARD_CACHE_TTL ("0" disables)
+	DEFAULT_CACHE_PRIME         = true             // Preload the dashboard's data before reporting ready, override with CACHE_PRIME
+	DEFAULT_CACHE_PRIME_SELLERS = 200              // Most recently active seller profiles preloaded, override with CACHE_PRIME_SELLERS
+	DEFAULT_CACHE_PRIME_TIMEOUT = 30 * time.Second // Priming gives up after this long and the instance turns ready anyway, override with CACHE_PRIME_TIMEOUT
+
+	DEFAULT_AGGREGATE_THRESHOLD = 10              // New analyses for a date that trigger its aggregation, override with AGGREGATE_THRESHOLD
+	DEFAULT_AGGREGATE_DEBOUNCE  = 2 * time.Minute // Quiet period after which a date's pending analyses are aggregated anyway, override with AGGREGATE_DEBOUNCE ("0" disables)
+
+	DEFAULT_ANALYSIS_TIMEOUT        = 1 * time.Minute  // Deadline of a call's analysis before its transcript's length is added, override with ANALYSIS_TIMEOUT
+	DEFAULT_ANALYSIS_TIMEOUT_PER_KB = 5 * time.Second  // Added per 1,000 transcript characters, override with ANALYSIS_TIMEOUT_PER_KB
+	DEFAULT_ANALYSIS_TIMEOUT_MAX    = 10 * time.Minute // Longest analysis deadline, and a single Gemini request's, override with ANALYSIS_TIMEOUT_MAX
+
+	DEFAULT_TRANSCRIPT_MAX_ATTEMPTS = 3 // Failed attempts before a watched transcript moves to FAILED_DIR, override with TRANSCRIPT_MAX_ATTEMPTS
+
+	DEFAULT_DEGRADED_MODE           = true        // Analyze calls provisionally while Gemini is unavailable instead of failing them, override with DEGRADED_MODE
+	DEFAULT_DEGRADED_PROBE_INTERVAL = time.Minute // While degraded, calls skip Gemini for this long after it last failed, override with DEGRADED_PROBE_INTERVAL
+	PROVISIONAL_UPGRADE_BATCH       = 200         // Provisional analyses upgraded per run, oldest first
+	PROVISIONAL_MAX_ATTEMPTS        = 5           // Upgrades of a call failing for other reasons than Gemini being unavailable before it's given up
+	PROVISIONAL_LIST_LIMIT          = 100         // Queued calls listed by GET /analyze/provisional
+
+	DEFAULT_ANALYSIS_ENGINE  = "gemini"                    // "rules" analyzes calls with the keyword classifier alone, without Gemini, override with ANALYSIS_ENGINE
+	DEFAULT_CLASSIFIER_RULES = "./prompts/classifier.json" // The keyword classifier's rules, the built-in ones when missing, override with CLASSIFIER_RULES
+
+	DEFAULT_SELLER_CONTEXT_POLICY = "./prompts/seller_context.json" // What seller contexts are built from, per source system; the defaults below when missing, override with SELLER_CONTEXT_POLICY
+	DEFAULT_CONTEXT_RECENT_CALLS  = 3                               // Latest calls in a seller context
+	DEFAULT_CONTEXT_ACTIVE_ISSUES = 5                               // Open issues in a seller context, the rest counted
+
+	SHADOW_CONCURRENCY     = 2  // Shadow analyses in flight at once; sampled calls beyond that aren't shadowed
+	SHADOW_REPORT_MAX_DAYS = 92 // Longest date range of a shadow comparison report
+
+	DATA_QUALITY_MAX_DAYS = 92 // Longest date range of a data quality report
+
+	TIMELINE_SYNC_ARCHIVE_FILES = 6                // Archive files a seller timeline reads before answering; more are loaded in the background
+	TIMELINE_ARCHIVE_TTL        = 10 * time.Minute // How long a background archive load is kept for the timeline polling it
+	TIMELINE_ARCHIVE_TIMEOUT    = 5 * time.Minute  // Longest background archive load
+
+	ROLLOUT_REFRESH_INTERVAL          = 30 * time.Second // How often each instance rereads the active rollout
+	FLAG_REFRESH_INTERVAL             = 30 * time.Second // How often each instance rereads the feature flags
+	DEFAULT_ROLLOUT_MIN_CALLS         = 20               // Candidate analyses before a rollout is judged
+	DEFAULT_ROLLOUT_MAX_PARSE_FAILURE = 0.05             // Candidate parse-failure rate that rolls back
+	DEFAULT_ROLLOUT_MAX_SATISFACTION  = 1.0              // Mean satisfaction delta (1-10) that rolls back
+	DEFAULT_ROLLOUT_MAX_ISSUE_COUNT   = 1.0              // Mean issues-per-call delta that rolls back
+	DEFAULT_ROLLOUT_MAX_NEGATIVE      = 0.15             // Negative-sentiment share delta that rolls back
+
+	EVAL_WINDOW_DAYS          = 7   // Days of analyses, ending yesterday, an eval run measures
+	EVAL_INTERVAL_DAYS        = 7   // Days between scheduled eval runs when no version changes
+	EVAL_HISTORY_DAYS         = 90  // Default range of GET /admin/eval/history
+	EVAL_HISTORY_MAX_DAYS     = 366 // Longest range of GET /admin/eval/history
+	EVAL_SAMPLE_LIMIT         = 50  // Approved call samples, newest first, each version analyzes per run
+	EVAL_ACCURACY_MIN_SAMPLES = 10  // Scored samples before a version's accuracy is compared between runs
+	EVAL_MAX_ACCURACY_DROP    = 0.1 // Fall in an accuracy, precision or recall between runs that counts as drift
+
+	CONTACT_ATTEMPTS_MAX = 50 // Missed and zero-length calls kept on a seller's timeline, most recent first
+
+	ONBOARDING_VINTAGE_MONTHS = 3 // Sellers under this many months on IndiaMART (their first 90 days) are onboarding
+	JOURNEY_ACTIVATION_MONTHS = 6 // Sellers past onboarding but under this many months are in activation
+	JOURNEY_RENEWAL_MONTHS    = 2 // Paid sellers this many months before their yearly anniversary are in the renewal window
+
+	DEFAULT_ATTENTION_HEALTH = 40 // Profiles scoring below this need attention, override per journey stage with JOURNEY_ATTENTION_HEALTH
+
+	DEFAULT_TREND_MAX_POINTS = 60 // Per-call trend points kept in a profile before older calls roll up by day, override with TREND_MAX_POINTS
+
+	DEFAULT_ISSUE_REOPEN_DAYS      = 90 // A resolved issue mentioned again within this many days is reopened rather than tracked anew, override with ISSUE_REOPEN_DAYS (0 disables)
+	DEFAULT_RESOLUTION_VERIFY_DAYS = 14 // A resolution is durable if the seller doesn't raise the bucket again within this many days, override with RESOLUTION_VERIFY_DAYS (0 disables)
+
+	DEFAULT_RECORDING_RETENTION_DAYS = 30               // Audio older than this is deleted (transcripts are kept), override with RECORDING_RETENTION_DAYS
+	DEFAULT_RECORDING_URL_TTL        = 15 * time.Minute // Lifetime of signed recording URLs, override with RECORDING_URL_TTL
+	DEFAULT_RECORDING_MAX_BYTES      = 100 << 20        // Larger recordings are not downloaded, override with RECORDING_MAX_BYTES
+
+	DEFAULT_LLM_RAW_RETENTION_DAYS = 30 // Raw Gemini responses are deleted after this, override with LLM_RAW_RETENTION_DAYS
+
+	DEFAULT_TRASH_RETENTION_DAYS = 30 // Deleted analyses and tickets can be restored this long, override with TRASH_RETENTION_DAYS
+
+	DEFAULT_AGGREGATE_STALE_DAYS = 7 // Days of aggregates checked against their analyses, override with AGGREGATE_STALE_DAYS
+
+	TICKET_EVIDENCE_DAYS = 14  // Days of bucket history charted on each generated ticket
+	TICKETS_BULK_MAX     = 500 // Tickets a single POST /tickets/bulk-update may change
+
+	CUSTOM_FIELDS_MAX      = 50  // Custom fields definable per entity
+	CUSTOM_FIELD_MAX_CHARS = 200 // Longest custom field string value
+
+	DEFAULT_TICKET_EXAMPLES          = 3   // Call examples quoted per ticket, override with TICKET_EXAMPLES
+	DEFAULT_TICKET_EXAMPLE_MAX_CHARS = 200 // Characters per example before it's cut, override with TICKET_EXAMPLE_MAX_CHARS
+
+	DEFAULT_GITHUB_API_URL = "https://api.github.com" // Override with GITHUB_API_URL (GitHub Enterprise)
+
+	PIPELINE_STATS_WINDOW = 100 // Recent transcripts and LLM requests behind the pipeline stats averages
+
+	DEFAULT_PIPELINE_STALL_AFTER          = 15 * time.Minute // Alert when nothing was processed this long while transcripts wait, override with PIPELINE_STALL_AFTER ("0" disables)
+	DEFAULT_PIPELINE_FAILURE_RATE         = 0.5              // Alert when this share of transcript attempts in the window fails, override with PIPELINE_FAILURE_RATE ("0" disables)
+	DEFAULT_PIPELINE_FAILURE_WINDOW       = 15 * time.Minute // Window of the failure rate, override with PIPELINE_FAILURE_WINDOW
+	DEFAULT_PIPELINE_FAILURE_MIN_ATTEMPTS = 5                // Attempts the window needs before its failure rate counts, override with PIPELINE_FAILURE_MIN_ATTEMPTS
+
+	DEFAULT_BACKFILL_HOURS          = "00:00-07:00"    // Off-peak hours a throttled replay runs in, in BUSINESS_TIMEZONE, override with BACKFILL_HOURS
+	DEFAULT_BACKFILL_MAX_LIVE_CALLS = 20               // Live transcripts ingested in the window that pause a throttled replay, override with BACKFILL_MAX_LIVE_CALLS ("0" disables)
+	DEFAULT_BACKFILL_MAX_ERROR_RATE = 0.2              // Share of analyses failing in the window that pauses a throttled replay, override with BACKFILL_MAX_ERROR_RATE ("0" disables)
+	DEFAULT_BACKFILL_WINDOW         = 15 * time.Minute // Window of the live volume and error rate, override with BACKFILL_WINDOW
+	BACKFILL_CHECK_INTERVAL         = time.Minute      // A paused replay checks again this often
+
+	DEFAULT_LEADER_LEASE_TTL = 15 * time.Second // Leader lease lifetime, renewed every third of it, override with LEADER_LEASE_TTL
+
+	DEFAULT_PROMPT_REGISTRY     = "./prompts/registry.json" // Vertical prompt blocks, override with PROMPT_REGISTRY
+	DEFAULT_PROMPT_TOKEN_BUDGET = 1_000_000                 // Estimated prompt tokens, under gemini-2.0-flash's 1,048,576 input window, override with PROMPT_TOKEN_BUDGET
+
+	DEFAULT_GEMINI_RATE_LIMIT = 0 // Gemini requests a minute across all instances, 0 is unlimited, override with GEMINI_RATE_LIMIT
+
+	DEFAULT_TRANSLATION_MAX_HINDI    = 0.15 // Share of transcript_en words still in Hindi that fails the translation check, override with TRANSLATION_MAX_HINDI
+	DEFAULT_TRANSLATION_MIN_COVERAGE = 0.6  // transcript_en words per word of the transcript below which it counts as cut short, override with TRANSLATION_MIN_COVERAGE
+	TRANSLATION_CHECK_MIN_WORDS      = 30   // Transcripts shorter than this aren't checked for coverage
+
+	DEFAULT_KB_TOP_K          = 5               // Knowledge base passages put in each analysis prompt, override with KB_TOP_K
+	DEFAULT_KB_MIN_SIMILARITY = 0.4             // Cosine similarity a passage needs to the call to be included, override with KB_MIN_SIMILARITY
+	KB_REFRESH_INTERVAL       = 5 * time.Minute // Each instance reloads the knowledge base this often, to pick up other instances' uploads
+
+	DEFAULT_COMMITMENT_DUE_DAYS = 7 // Due date of commitments made without one, days after the call, override with COMMITMENT_DUE_DAYS
+
+	DEFAULT_CASE_CLOSE_DAYS           = 30 // Days without open issues or a Negative call after which a seller's case closes, override with CASE_CLOSE_DAYS
+	DEFAULT_CASE_DECAY_HALF_LIFE_DAYS = 14 // A closed case's health score moves halfway to neutral in this many days, override with CASE_DECAY_HALF_LIFE_DAYS
+
+	DEFAULT_UPSELL_CONVERSION_DAYS = 90 // Days after a pitch an upgrade still counts as its conversion, override with UPSELL_CONVERSION_DAYS
+
+	QUOTE_MIN_CHARS = 20  // Shorter seller quotes say too little to be worth keeping
+	QUOTE_MAX_CHARS = 300 // Longer ones aren't quotable; they're left out rather than cut
+
+	TOP_MOVERS_LIMIT = 5 // Buckets, sellers and agents listed in an aggregate's top movers
+
+	PORTAL_RESOLVED_DAYS = 30 // Resolved issues shown in the seller portal summary, by days since resolution
+	PORTAL_FOLLOW_UPS    = 20 // Promises shown in the seller portal summary
+
+	DEFAULT_DRAIN_DELAY       = 5 * time.Second  // Unready time before the HTTP server stops, override with DRAIN_DELAY
+	DEFAULT_SHUTDOWN_TIMEOUT  = 25 * time.Second // In-flight requests get this long to finish, override with SHUTDOWN_TIMEOUT
+	DEFAULT_READY_RETRY       = 10 * time.Second // Between failed MongoDB connects and Gemini checks at startup, override with READY_RETRY_INTERVAL
+	DEFAULT_LLM_STARTUP_CHECK = "wait"           // When the Gemini check at startup fails: "wait" unready until it passes, "fail" exit on a key, model or region problem, "degraded" ready with calls analyzed provisionally; override with LLM_STARTUP_CHECK
+
+	DEFAULT_CHAOS_DISK_DELAY = 500 * time.Millisecond // Injected delay per slow_disk fault, override with CHAOS_DISK_DELAY
+
+	DEFAULT_BUSINESS_TIMEZONE = "Asia/Kolkata" // Timezone business days are counted in, override with BUSINESS_TIMEZONE
+
+	DEFAULT_REQUEST_TIMEOUT_SHORT = 15 * time.Second // Reads and quick writes, override with REQUEST_TIMEOUT_SHORT
+	DEFAULT_REQUEST_TIMEOUT_LONG  = 2 * time.Minute  // Endpoints that wait on Gemini, override with REQUEST_TIMEOUT_LONG
+	DEFAULT_REQUEST_TIMEOUT_BATCH = 30 * time.Minute // Bulk triggers (analyze all, archive), override with REQUEST_TIMEOUT_BATCH
+
+	DEFAULT_IDEMPOTENCY_TTL = 24 * time.Hour // Responses kept for retries with the same Idempotency-Key, override with IDEMPOTENCY_TTL
+
+	DEFAULT_INGEST_JOB_TTL       = 7 * 24 * time.Hour // Async ingestion jobs kept for polling, override with INGEST_JOB_TTL
+	DEFAULT_INGEST_CONCURRENCY   = 4                  // Async analyses in flight at once, more wait queued, override with INGEST_CONCURRENCY
+	INGEST_CALLBACK_MAX_ATTEMPTS = 5                  // Posts of a finished job to its callback_url before giving up
+	INGEST_CALLBACK_RETRY_DELAY  = 10 * time.Second   // Before the first retry, doubling after each
+
+	DEFAULT_CALL_CHAT_TTL   = 24 * time.Hour // Call chat sessions kept after their last message, override with CALL_CHAT_TTL
+	DEFAULT_CALL_CHAT_TURNS = 20             // Questions one call chat session may ask, override with CALL_CHAT_TURNS
+	CALL_CHAT_MAX_CHARS     = 2000           // Longest call chat question
+
+	DEFAULT_JOB_TTL       = 30 * 24 * time.Hour // Long-running operations' records kept, override with JOB_TTL
+	JOB_PROGRESS_INTERVAL = 5 * time.Second     // Most often a running job's progress is saved
+	JOBS_LIST_LIMIT       = 100                 // Jobs listed by default
+	JOBS_LIST_MAX         = 1000                // Most jobs listed at once
+
+	DEFAULT_COMPRESS_MIN_BYTES = 1024 // Smaller responses are sent uncompressed, override with COMPRESS_MIN_BYTES
+)
+
+// EnvDuration reads a duration (e.g. "3s") from the environment, falling back to def
+func EnvDuration(name string, def time.Duration) time.Duration {
+	if v := os.Getenv(name); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			return d
+		}
+		log.Printf("⚠️ Invalid %s=%q, using %v", name, v, def)
+	}
+	return def
+}
+
+// ==================== BUSINESS DAYS ====================
+// Daily aggregates, dashboard dates, trend buckets and every other YYYY-MM-DD
+// date are business days in BusinessTZ, not server-local or UTC days. Use
+// these helpers rather than formatting times directly.
+
+const DateLayout = "2006-01-02"
+
+// BusinessTZ is the timezone of business days (BUSINESS_TIMEZONE)
+var BusinessTZ = loadBusinessTZ()
+
+func loadBusinessTZ() *time.Location {
+	name := os.Getenv("BUSINESS_TIMEZONE")
+	if name == "" {
+		name = DEFAULT_BUSINESS_TIMEZONE
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		log.Printf("⚠️ Invalid BUSINESS_TIMEZONE=%q, using %s", name, DEFAULT_BUSINESS_TIMEZONE)
+		loc, _ = time.LoadLocation(DEFAULT_BUSINESS_TIMEZONE)
+	}
+	return loc
+}
+
+// BusinessDate returns the business day t falls on, as YYYY-MM-DD
+func BusinessDate(t time.Time) string {
+	return t.In(BusinessTZ).Format(DateLayout)
+}
+
+// Today returns the current business day, as YYYY-MM-DD
+func Today() string {
+	return BusinessDate(time.Now())
+}
+
+// ParseBusinessDate parses a YYYY-MM-DD date as the start of that business day
+func ParseBusinessDate(date string) (time.Time, error) {
+	return time.ParseInLocation(DateLayout, date, BusinessTZ)
+}
+
+// Feature buckets for problem categorization
+var FeatureBuckets = []string{
+	"Lead Management",
+	"Lead Quality",
+	"Lead Quantity",
+	"Promoted Listing / Lead Priority",
+	"Visibility / Ranking",
+	"TrustSEAL / Verification",
+	"Catalog / Storefront Setup",
+	"Buyer Interaction",
+	"BizInsight Analytics",
+	"Billing & Renewal",
+	"Payments",
+	"App / Platform Usability",
+	"Support / Training",
+	"Seller Verification",
+	"Compliance / Documentation",
+	"Category-City Targeting",
+	"Communication",
+	"Account / Dashboard",
+	"Other",
+}
+
+// OnboardingBuckets are the feature buckets of getting set up, which make a
+// new seller's call an onboarding call
+var OnboardingBuckets = []string{
+	"Catalog / Storefront Setup",
+	"Seller Verification",
+	"TrustSEAL / Verification",
+	"Compliance / Documentation",
+	"Support / Training",
+	"Account / Dashboard",
+}
+
+// IndiaMART Business Context - Comprehensive knowledge base for AI analysis
+const IndiaMARTContext = `
+=== INDIAMART BUSINESS OVERVIEW ===
+
+IndiaMART is India's LARGEST B2B online marketplace, founded in 1996 by Dinesh Chandra Agarwal.
+- Mission: "Make doing business easy" and democratize business opportunities for all
+- Platform connects 86+ lakh (8.6 million) suppliers with 21.9+ crore (219 million) registered buyers
+- Headquarters: Noida, India (Listed company: IndiaMART InterMESH Ltd.)
+- Focus: Digital and financial inclusion for MSMEs (Micro, Small & Medium Enterprises)
+
+=== PAID SUBSCRIPTION PRODUCTS (Most Important for Call Analysis) ===
+
+1. MDC (Mini Dynamic Catalogue) - Entry Level
+   - Price: Rs.4,000/month | Rs.35,000/year | Rs.60,000/2yr | Rs.72,000/3yr
+   - Benefits:
+     * 7 weekly BuyLeads (monthly plan) / 10 weekly (yearly plan) + 1 daily bonus
+     * Higher listing vs free suppliers
+     * Lead Management System (LMS) with instant alerts
+     * Zero missed calls - Call forwarding (PNS) to up to 5 numbers
+     * Professional catalog designed by experts
+
+2. TrustSEAL - Trust & Verification Badge
+   - Price: Rs.50,000/year | Rs.80,000/2yr | Rs.100,000/3yr
+   - Benefits:
+     * TrustSEAL logo and stamp on catalog (credibility badge)
+     * TrustSEAL certificate (physical + e-copy)
+     * 20 domestic BuyLeads/week + 2 daily bonus BuyLeads
+     * Higher listing than MDC suppliers
+     * Document verification by IndiaMART team
+   - Common Issues: Badge not displaying, verification delays, document pending
+
+3. Maximiser - Premium Catalog Service
+   - Price: Rs.75,000/year | Rs.1,20,000/2yr | Rs.1,50,000/3yr
+   - Benefits:
+     * Personal domain (.com/.net/.in/.co.in) with server access
+     * 4 corporate email IDs
+     * Up to 10,000 products in catalog
+     * TrustSEAL badge included
+     * 30 weekly domestic BuyLeads + 2 daily bonus
+     * Mobile-responsive templates
+     * PDF catalog with 360-degree visibility
+
+4. IM Star Pro - Premium Visibility
+   - Benefits:
+     * Star Supplier label/badge
+     * Dynamic cities visibility (behavior-based districts)
+     * Premium listing in dynamic cities & preferred locations
+     * NO limitation on BuyLeads - unlimited leads
+     * Higher visibility in local cities and chosen categories
+
+5. IM Leader Pro - Top Tier
+   - Benefits:
+     * Leading Supplier label
+     * AI-based targeting for business acceleration
+     * Premium listing with highest visibility
+     * Dynamic cities + preferred locations
+     * Unlimited BuyLeads
+     * Top visibility in local cities and categories
+
+=== KEY FEATURES & TOOLS FOR SELLERS ===
+
+1. BuyLeads System
+   - BuyLeads are buyer inquiries/requirements posted by buyers
+   - Sellers receive BuyLeads based on their subscription
+   - Types: Daily bonus BuyLeads, Weekly domestic BuyLeads
+   - Quality concern: Fake/spam inquiries from competitors or students
+
+2. Lead Management System (LMS)
+   - 24x7 instant alerts for new leads
+   - Smart lead tracking and management
+   - Contact buyer directly via call/message
+
+3. Preferred Number Service (PNS)
+   - Call forwarding to up to 5 numbers
+   - Zero missed calls feature
+   - Available in paid plans
+
+4. Catalog/Storefront Features
+   - Product listing with images, prices, descriptions
+   - Response rate tracking (affects ranking)
+   - Catalog optimization by IndiaMART team
+   - Last updated date visible (affects algorithm)
+
+5. Ranking Algorithm Factors
+   - Response rate to inquiries (CRITICAL - should be >80%)
+   - Catalog update frequency
+   - Subscription level (paid > free)
+   - TrustSEAL/Star/Leader badge
+   - Business verification status
+
+6. BizInsight Analytics
+   - Dashboard showing business metrics
+   - Lead conversion tracking
+   - Visibility reports
+
+=== COMMON SELLER ISSUES (Categorize calls into these) ===
+
+1. Lead Quality Issues
+   - Fake inquiries from competitors
+   - Students doing market research
+   - Non-serious buyers
+   - Spam leads affecting response rate
+
+2. Lead Quantity Issues
+   - Not receiving promised number of leads
+   - Leads stopped coming
+   - Daily/weekly quota not met
+
+3. Visibility/Ranking Issues
+   - Products not on first page
+   - Dropped from top rankings
+   - Competitors showing above despite same/lower subscription
+
+4. TrustSEAL/Verification Issues
+   - Badge not displaying after payment
+   - Document verification pending/delayed
+   - Certificate not received
+   - Badge removed unexpectedly
+
+5. Billing & Renewal Issues
+   - Payment charged but subscription not activated
+   - Renewal pricing disputes
+   - Refund requests
+   - Auto-renewal complaints
+   - Package downgrade issues
+
+6. Technical/Platform Issues
+   - App not working
+   - Dashboard errors
+   - Leads not syncing
+   - Unable to respond to inquiries
+   - Login problems
+
+7. Catalog Issues
+   - Products not uploaded
+   - Images not displaying
+   - Category mismatch
+   - Catalog not updated by team
+
+8. Communication Issues
+   - Multiple calls with no resolution
+   - Promised callback not received
+   - Escalation not happening
+   - Agent commitments not honored
+
+=== COMPETITOR CONTEXT ===
+Main competitors sellers may threaten to switch to:
+- TradeIndia
+- JustDial
+- Amazon Business
+- Alibaba
+- IndiaBizClub
+
+=== SELLER PSYCHOLOGY & BUSINESS IMPACT ===
+
+Understand these seller concerns:
+1. ROI Focus: Sellers pay substantial amounts (Rs.35K-1.5L/year) and expect measurable returns
+2. Lead Conversion: Quality leads = actual orders = business survival for MSMEs
+3. Trust: TrustSEAL is identity/credibility marker - its absence affects buyer trust
+4. Competition: Visibility directly impacts business - ranking drop = lost revenue
+5. Cash Flow: Small businesses have tight margins - billing issues are critical
+6. Time Sensitivity: Business inquiries need quick response - delays = lost deals
+
+=== AGENT PERFORMANCE MARKERS ===
+
+Good Agent Behavior:
+- Acknowledges problem genuinely
+- Checks account/system before responding
+- Provides specific solutions, not generic advice
+- Takes ownership with follow-up commitment
+- Offers compensation/credit when appropriate
+- Escalates when needed
+
+Poor Agent Behavior:
+- Generic responses without checking account
+- Blaming seller (update catalog, improve response rate)
+- No ownership or follow-up commitment
+- Multiple transfers without resolution
+- Ignoring escalation requests
+- Making promises without capability to deliver
+
+=== CHURN RISK INDICATORS ===
+
+High Churn Risk:
+- Mentions competitor names (TradeIndia, JustDial)
+- Multiple unresolved complaints
+- Says "last chance" or "final warning"
+- Threatens cancellation/refund
+- Been a customer for 2+ years with recent issues
+- Premium customer (TrustSEAL/Maximiser) with problems
+
+Low Churn Risk:
+- New customer learning the platform
+- Single minor issue
+- Expresses satisfaction with resolution
+- Asks about upgrading services
+- Shows interest in additional products
+
+=== UPSELL OPPORTUNITIES ===
+
+Look for these signals:
+- Seller mentions competitor's features they want
+- Asks about premium services
+- Wants more leads/visibility
+- Business is growing
+- Interested in website/domain
+- Needs better credibility (→ TrustSEAL)
+- Wants unlimited leads (→ IM Star/Leader Pro)
+`
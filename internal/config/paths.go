@@ -0,0 +1,78 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strings"
+)
+
+// ==================== ENVIRONMENTS AND PATHS ====================
+// APP_ENV picks the defaults of an environment. dev keeps data next to the
+// working directory, as before; staging and prod keep it under
+// /var/lib/im-ai-voice, so a container can mount a volume there and run
+// with a read-only root filesystem. STORAGE_BASE moves every data directory
+// at once, and each one can be moved on its own (see dataDir).
+
+const (
+	EnvDev     = "dev"
+	EnvStaging = "staging"
+	EnvProd    = "prod"
+
+	DEFAULT_STATIC_DIR = "./static" // Dashboard UI, override with STATIC_DIR
+)
+
+// envStorageBase is the default STORAGE_BASE of each environment
+var envStorageBase = map[string]string{
+	EnvDev:     "./data",
+	EnvStaging: "/var/lib/im-ai-voice",
+	EnvProd:    "/var/lib/im-ai-voice",
+}
+
+// Env is the environment the service runs in (APP_ENV), dev by default
+var Env = loadEnv()
+
+// STORAGE_BASE is the directory data directories are kept under
+var STORAGE_BASE = envOr("STORAGE_BASE", envStorageBase[Env])
+
+// STATIC_DIR holds the dashboard UI
+var STATIC_DIR = envOr("STATIC_DIR", DEFAULT_STATIC_DIR)
+
+// DataDir is a data directory with the environment variable that moves it
+type DataDir struct {
+	Env  string
+	Path string
+}
+
+// DataDirs lists every data directory, for creating and checking them at startup
+var DataDirs []DataDir
+
+func loadEnv() string {
+	env := strings.ToLower(strings.TrimSpace(os.Getenv("APP_ENV")))
+	switch env {
+	case "", "development":
+		return EnvDev
+	case "production":
+		return EnvProd
+	}
+	if _, ok := envStorageBase[env]; !ok {
+		log.Printf("⚠️ Invalid APP_ENV=%q (want dev, staging or prod), using %s", env, EnvDev)
+		return EnvDev
+	}
+	return env
+}
+
+// dataDir is the directory named by the environment variable name, sub
+// under STORAGE_BASE when unset
+func dataDir(name, sub string) string {
+	d := DataDir{Env: name, Path: envOr(name, STORAGE_BASE+"/"+sub)}
+	DataDirs = append(DataDirs, d)
+	return d.Path
+}
+
+// envOr is the environment variable name, def when unset
+func envOr(name, def string) string {
+	if v := strings.TrimSpace(os.Getenv(name)); v != "" {
+		return v
+	}
+	return def
+}
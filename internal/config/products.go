@@ -0,0 +1,139 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Product is a sellable IndiaMART subscription that upsell interest maps to
+type Product struct {
+	SKU         string   `json:"sku"`
+	Name        string   `json:"name"`
+	AnnualPrice int      `json:"annual_price"` // Rs, the estimated deal value; 0 when unpriced
+	names       []string // The product's own names, matched first
+	features    []string // What sellers ask for when they want it
+}
+
+// CatalogPriceTolerance is how far, as a fraction of the list price, an
+// annual amount said on a call can be from it and still be on catalog
+const CatalogPriceTolerance = 0.10
+
+// Products are the paid plans from IndiaMARTContext, cheapest first. Star Pro
+// and Leader Pro have no list price there; set UPSELL_SKU_VALUES (sku=rupees,
+// comma-separated) to value them, or to override the others.
+var Products = loadProducts()
+
+func loadProducts() []Product {
+	products := []Product{
+		{
+			SKU: "mdc", Name: "MDC (Mini Dynamic Catalogue)", AnnualPrice: 35000,
+			names:    []string{"mdc", "mini dynamic catalog"},
+			features: []string{"call forwarding", "pns", "missed call", "lead management", "lms", "weekly buylead", "paid listing", "professional catalog"},
+		},
+		{
+			SKU: "trustseal", Name: "TrustSEAL", AnnualPrice: 50000,
+			names:    []string{"trustseal", "trust seal"},
+			features: []string{"verification", "verified", "badge", "certificate", "credibility", "trust"},
+		},
+		{
+			SKU: "maximiser", Name: "Maximiser", AnnualPrice: 75000,
+			names:    []string{"maximiser", "maximizer"},
+			features: []string{"website", "domain", "email id", "corporate email", "pdf catalog", "more products", "10,000 products"},
+		},
+		{
+			SKU: "star_pro", Name: "IM Star Pro",
+			names:    []string{"star pro", "im star", "star supplier"},
+			features: []string{"unlimited lead", "unlimited buylead", "dynamic cit", "preferred location", "local visibility", "more visibility", "higher ranking", "premium listing"},
+		},
+		{
+			SKU: "leader_pro", Name: "IM Leader Pro",
+			names:    []string{"leader pro", "leading supplier", "im leader"},
+			features: []string{"ai targeting", "ai based targeting", "top visibility", "top listing", "highest visibility"},
+		},
+	}
+
+	for _, entry := range strings.Split(os.Getenv("UPSELL_SKU_VALUES"), ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		sku, v, _ := strings.Cut(entry, "=")
+		price, err := strconv.Atoi(strings.TrimSpace(v))
+		i := productIndex(products, strings.TrimSpace(sku))
+		if err != nil || price < 0 || i < 0 {
+			log.Printf("⚠️ Ignoring malformed UPSELL_SKU_VALUES entry %q (want sku=rupees)", entry)
+			continue
+		}
+		products[i].AnnualPrice = price
+	}
+	return products
+}
+
+func productIndex(products []Product, sku string) int {
+	for i, p := range products {
+		if p.SKU == sku {
+			return i
+		}
+	}
+	return -1
+}
+
+// ProductBySKU returns the product for a SKU
+func ProductBySKU(sku string) (Product, bool) {
+	if i := productIndex(Products, sku); i >= 0 {
+		return Products[i], true
+	}
+	return Product{}, false
+}
+
+// MatchProduct maps a free-text feature mention to a product SKU, "" when it
+// names none. Product names win over feature descriptions, so "TrustSEAL
+// badge" is TrustSEAL even though badges also come with Star Pro.
+func MatchProduct(mention string) string {
+	text := matchText(mention)
+	for _, p := range Products {
+		for _, n := range p.names {
+			if strings.Contains(text, matchText(n)) {
+				return p.SKU
+			}
+		}
+	}
+	for _, p := range Products {
+		for _, f := range p.features {
+			if strings.Contains(text, matchText(f)) {
+				return p.SKU
+			}
+		}
+	}
+	return ""
+}
+
+// matchText lowercases s and turns punctuation into spaces, with a leading
+// space so terms only match from the start of a word ("lms" isn't in "films")
+func matchText(s string) string {
+	return " " + strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == ',' {
+			return unicode.ToLower(r)
+		}
+		return ' '
+	}, s)
+}
+
+// MatchProducts maps feature mentions to their distinct SKUs, in catalog order
+func MatchProducts(mentions []string) []string {
+	found := make(map[string]bool)
+	for _, m := range mentions {
+		if sku := MatchProduct(m); sku != "" {
+			found[sku] = true
+		}
+	}
+	var skus []string
+	for _, p := range Products {
+		if found[p.SKU] {
+			skus = append(skus, p.SKU)
+		}
+	}
+	return skus
+}
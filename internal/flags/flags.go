@@ -0,0 +1,172 @@
+package flags
+
+import (
+	"context"
+	"hash/fnv"
+	"log"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== FEATURE FLAGS ====================
+// Flags let a risky capability ship dark and be turned on gradually, for
+// some upstream systems (the closest thing to tenants calls have) or a
+// share of calls, without a deploy. Each flag is on for every call unless
+// FEATURE_FLAGS sets it otherwise:
+//
+//	FEATURE_FLAGS="kb_retrieval=off,shadow=25"
+//
+// and a flag set through /admin/flags overrides both. Each instance rereads
+// the stored flags every FLAG_REFRESH_INTERVAL, keeping the last ones when
+// that fails, and picks calls by a hash of the flag name and call ID, so
+// every instance decides a call the same way and raising a flag's percent
+// only adds calls.
+
+type cache struct {
+	mu       sync.Mutex
+	loadedAt time.Time
+	stored   map[string]client.FeatureFlag
+}
+
+var (
+	flags    cache
+	envOnce  sync.Once
+	envFlags map[string]client.FeatureFlag
+)
+
+// Known reports whether name is one of the feature flags
+func Known(name string) bool {
+	return slices.Contains(client.FeatureFlags, name)
+}
+
+// Enabled reports whether a flag is on for a call. origin may be nil.
+func Enabled(ctx context.Context, name, callID string, origin *client.CallOrigin) bool {
+	f := lookup(ctx, name)
+	if !f.Enabled {
+		return false
+	}
+	if origin != nil && matchesOrigin(f.Origins, *origin) {
+		return true
+	}
+	return picks(name, callID, f.Percent)
+}
+
+// List returns every flag's current setting, read fresh from storage
+func List(ctx context.Context) ([]client.FeatureFlag, error) {
+	stored, err := storage.LoadFeatureFlags(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]client.FeatureFlag, len(stored))
+	for _, f := range stored {
+		byName[f.Name] = f
+	}
+	list := make([]client.FeatureFlag, 0, len(client.FeatureFlags))
+	for _, name := range client.FeatureFlags {
+		f, ok := byName[name]
+		if !ok {
+			f = fallback(name)
+		}
+		list = append(list, f)
+	}
+	return list, nil
+}
+
+// Reload makes the next check on this instance reread the stored flags
+func Reload() {
+	flags.mu.Lock()
+	flags.loadedAt = time.Time{}
+	flags.mu.Unlock()
+}
+
+// lookup returns a flag's setting: stored, from FEATURE_FLAGS or the default
+func lookup(ctx context.Context, name string) client.FeatureFlag {
+	flags.mu.Lock()
+	defer flags.mu.Unlock()
+	if time.Since(flags.loadedAt) >= config.FLAG_REFRESH_INTERVAL {
+		stored, err := storage.LoadFeatureFlags(ctx)
+		if err != nil {
+			log.Printf("⚠️ Failed to load feature flags, keeping the last ones: %v", err)
+		} else {
+			flags.stored = make(map[string]client.FeatureFlag, len(stored))
+			for _, f := range stored {
+				flags.stored[f.Name] = f
+			}
+		}
+		flags.loadedAt = time.Now()
+	}
+	if f, ok := flags.stored[name]; ok {
+		return f
+	}
+	return fallback(name)
+}
+
+// fallback is a flag's setting from FEATURE_FLAGS, on for every call if unset
+func fallback(name string) client.FeatureFlag {
+	envOnce.Do(func() { envFlags = loadEnvFlags() })
+	if f, ok := envFlags[name]; ok {
+		return f
+	}
+	return client.FeatureFlag{Name: name, Enabled: true, Percent: 100, Source: client.FlagSourceDefault}
+}
+
+// loadEnvFlags reads FEATURE_FLAGS, skipping malformed entries
+func loadEnvFlags() map[string]client.FeatureFlag {
+	byName := make(map[string]client.FeatureFlag)
+	for _, entry := range strings.Split(os.Getenv("FEATURE_FLAGS"), ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		name, v, _ := strings.Cut(entry, "=")
+		name, v = strings.TrimSpace(name), strings.ToLower(strings.TrimSpace(v))
+		f := client.FeatureFlag{Name: name, Enabled: true, Source: client.FlagSourceEnv}
+		switch v {
+		case "on":
+			f.Percent = 100
+		case "off":
+			f.Enabled = false
+		default:
+			n, err := strconv.Atoi(strings.TrimSuffix(v, "%"))
+			if err != nil || n < 0 || n > 100 {
+				log.Printf("⚠️ Ignoring FEATURE_FLAGS entry %q (want name=on, off or a percent)", entry)
+				continue
+			}
+			f.Percent = n
+		}
+		if !Known(name) {
+			log.Printf("⚠️ Ignoring FEATURE_FLAGS entry %q (unknown flag)", entry)
+			continue
+		}
+		byName[name] = f
+	}
+	return byName
+}
+
+// matchesOrigin reports whether a call's origin is one of origins, each a
+// system or system:region
+func matchesOrigin(origins []string, o client.CallOrigin) bool {
+	for _, want := range origins {
+		system, region, hasRegion := strings.Cut(want, ":")
+		if strings.EqualFold(system, o.System) && (!hasRegion || strings.EqualFold(region, o.Region)) {
+			return true
+		}
+	}
+	return false
+}
+
+func picks(name, callID string, percent int) bool {
+	if percent >= 100 {
+		return true
+	}
+	h := fnv.New32a()
+	h.Write([]byte(name + "/" + callID))
+	return int(h.Sum32()%100) < percent
+}
@@ -0,0 +1,328 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== GITHUB ISSUES ====================
+// Generated tickets are projected onto issues in GITHUB_REPO (org/repo), one
+// issue per feature bucket, labelled through GITHUB_LABEL_MAP. Later tickets
+// for the bucket update the issue and comment when its count grows or it's
+// reported again on a new day; resolving the ticket the issue tracks closes
+// it. Sync is on when GITHUB_TOKEN and GITHUB_REPO are both set.
+//
+//	GITHUB_LABEL_MAP="Payments=payments+billing,Lead Quality=leads"
+//
+// Buckets missing from the map are labelled with their own name.
+
+const (
+	stateOpen   = "open"
+	stateClosed = "closed"
+)
+
+type settings struct {
+	token    string
+	repo     string
+	apiURL   string
+	labels   map[string][]string // Bucket to labels
+	common   []string            // Labels on every issue (GITHUB_LABELS)
+	http     *http.Client
+	issueAPI string // <apiURL>/repos/<org>/<repo>/issues
+}
+
+// Global settings, nil while sync is off
+var gh *settings
+
+// Init configures GitHub sync from the environment
+func Init() error {
+	token, repo := os.Getenv("GITHUB_TOKEN"), os.Getenv("GITHUB_REPO")
+	if token == "" || repo == "" {
+		return nil
+	}
+	org, name, ok := strings.Cut(repo, "/")
+	if !ok || org == "" || name == "" || strings.Contains(name, "/") {
+		return fmt.Errorf("GITHUB_REPO must be org/repo, got %q", repo)
+	}
+
+	apiURL := strings.TrimRight(os.Getenv("GITHUB_API_URL"), "/")
+	if apiURL == "" {
+		apiURL = config.DEFAULT_GITHUB_API_URL
+	}
+	gh = &settings{
+		token:    token,
+		repo:     repo,
+		apiURL:   apiURL,
+		labels:   parseLabelMap(os.Getenv("GITHUB_LABEL_MAP")),
+		common:   splitLabels(os.Getenv("GITHUB_LABELS"), ","),
+		http:     &http.Client{Timeout: 15 * time.Second},
+		issueAPI: fmt.Sprintf("%s/repos/%s/%s/issues", apiURL, org, name),
+	}
+	log.Printf("🐙 GitHub issue sync: %s (%d bucket label mappings)", repo, len(gh.labels))
+	return nil
+}
+
+// Enabled reports whether tickets are synced to GitHub
+func Enabled() bool {
+	return gh != nil
+}
+
+// parseLabelMap reads bucket=label+label entries, skipping malformed ones
+func parseLabelMap(v string) map[string][]string {
+	m := make(map[string][]string)
+	for _, entry := range strings.Split(v, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		bucket, labels, ok := strings.Cut(entry, "=")
+		bucket = strings.TrimSpace(bucket)
+		if !ok || bucket == "" {
+			log.Printf("⚠️ Ignoring malformed GITHUB_LABEL_MAP entry %q (want bucket=label+label)", entry)
+			continue
+		}
+		m[bucket] = splitLabels(labels, "+")
+	}
+	return m
+}
+
+func splitLabels(v, sep string) []string {
+	var labels []string
+	for _, l := range strings.Split(v, sep) {
+		if l = strings.TrimSpace(l); l != "" {
+			labels = append(labels, l)
+		}
+	}
+	return labels
+}
+
+// Labels returns the labels for a bucket's issue
+func Labels(bucket string) []string {
+	mapped, ok := gh.labels[bucket]
+	if !ok {
+		mapped = []string{bucket}
+	}
+	return append(append([]string(nil), gh.common...), mapped...)
+}
+
+// ==================== TICKET SYNC ====================
+
+// Sync opens or updates the GitHub issue for a ticket's bucket and sets the
+// ticket's IssueURL. Resolved tickets, and tickets older than the one the
+// issue already tracks, leave the issue alone.
+func Sync(ctx context.Context, t *client.Ticket) error {
+	if gh == nil {
+		return nil
+	}
+	link, err := storage.LoadGitHubIssue(ctx, gh.repo, t.FeatureBucket)
+	if err != nil {
+		return fmt.Errorf("failed to load issue link: %w", err)
+	}
+	if link != nil {
+		t.IssueURL = link.URL
+	}
+	if t.Status == client.TicketResolved || (link != nil && t.Date < link.Date) {
+		return nil
+	}
+
+	now := time.Now()
+	sellers := len(t.AffectedSellers)
+
+	if link == nil {
+		var iss issue
+		err := gh.call(ctx, http.MethodPost, "", map[string]any{
+			"title":  t.Title,
+			"body":   issueBody(t),
+			"labels": Labels(t.FeatureBucket),
+		}, &iss)
+		if err != nil {
+			return err
+		}
+		link = &client.GitHubIssue{
+			FeatureBucket: t.FeatureBucket,
+			Repo:          gh.repo,
+			Number:        iss.Number,
+			URL:           iss.HTMLURL,
+			CreatedAt:     now,
+		}
+		log.Printf("   🐙 Opened GitHub issue #%d for %s", iss.Number, t.FeatureBucket)
+	} else {
+		sameTicket := link.TicketID == t.TicketID
+		if sameTicket && link.State == stateOpen && link.Count == t.AffectedCount && link.Sellers == sellers {
+			return nil // Nothing new since the last sync
+		}
+
+		var comment string
+		switch {
+		case link.State == stateClosed:
+			comment = fmt.Sprintf("Reopened: %s is %s with **%d issues from %d sellers** on %s.",
+				ticketRef(t), t.Status, t.AffectedCount, sellers, t.Date)
+		case !sameTicket:
+			comment = fmt.Sprintf("Reported again on %s: **%d issues from %d sellers** (%s).",
+				t.Date, t.AffectedCount, sellers, ticketRef(t))
+		case t.AffectedCount > link.Count:
+			comment = fmt.Sprintf("Count grew on %s: **%d → %d issues**, %d → %d sellers.",
+				t.Date, link.Count, t.AffectedCount, link.Sellers, sellers)
+		}
+
+		err := gh.call(ctx, http.MethodPatch, fmt.Sprintf("/%d", link.Number), map[string]any{
+			"title": t.Title,
+			"body":  issueBody(t),
+			"state": stateOpen,
+		}, nil)
+		if err != nil {
+			return err
+		}
+		// Labels are added rather than replaced, keeping any set by hand
+		if err := gh.call(ctx, http.MethodPost, fmt.Sprintf("/%d/labels", link.Number), map[string]any{
+			"labels": Labels(t.FeatureBucket),
+		}, nil); err != nil {
+			log.Printf("   ⚠️ Failed to label GitHub issue #%d: %v", link.Number, err)
+		}
+		if comment != "" {
+			if err := gh.comment(ctx, link.Number, comment); err != nil {
+				log.Printf("   ⚠️ Failed to comment on GitHub issue #%d: %v", link.Number, err)
+			}
+		}
+		log.Printf("   🐙 Updated GitHub issue #%d for %s", link.Number, t.FeatureBucket)
+	}
+
+	link.State = stateOpen
+	link.TicketID = t.TicketID
+	link.Date = t.Date
+	link.Count = t.AffectedCount
+	link.Sellers = sellers
+	link.UpdatedAt = now
+	t.IssueURL = link.URL
+	return storage.SaveGitHubIssue(ctx, link)
+}
+
+// Resolve closes the GitHub issue tracking a resolved ticket. Resolving an
+// older ticket for the bucket doesn't close the issue.
+func Resolve(ctx context.Context, t *client.Ticket) error {
+	if gh == nil {
+		return nil
+	}
+	link, err := storage.LoadGitHubIssue(ctx, gh.repo, t.FeatureBucket)
+	if err != nil {
+		return fmt.Errorf("failed to load issue link: %w", err)
+	}
+	if link == nil || link.State == stateClosed || link.TicketID != t.TicketID {
+		return nil
+	}
+
+	msg := fmt.Sprintf("Resolved: %s was marked resolved.", ticketRef(t))
+	if t.ResolutionNote != "" {
+		msg += "\n\n> " + strings.ReplaceAll(t.ResolutionNote, "\n", "\n> ")
+	}
+	if err := gh.comment(ctx, link.Number, msg); err != nil {
+		log.Printf("   ⚠️ Failed to comment on GitHub issue #%d: %v", link.Number, err)
+	}
+	err = gh.call(ctx, http.MethodPatch, fmt.Sprintf("/%d", link.Number), map[string]any{
+		"state":        stateClosed,
+		"state_reason": "completed",
+	}, nil)
+	if err != nil {
+		return err
+	}
+
+	link.State = stateClosed
+	link.UpdatedAt = time.Now()
+	log.Printf("   🐙 Closed GitHub issue #%d for %s", link.Number, t.FeatureBucket)
+	return storage.SaveGitHubIssue(ctx, link)
+}
+
+func ticketRef(t *client.Ticket) string {
+	return "ticket `" + t.TicketID + "`"
+}
+
+// issueBody is the ticket description with its bucket trend appended
+func issueBody(t *client.Ticket) string {
+	var b strings.Builder
+	b.WriteString(t.Description)
+	for _, chart := range t.Evidence {
+		if chart.Chart != client.ChartBucketCount || len(chart.Points) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n\n## %s\n`%s` %s → %s",
+			chart.Title, sparkline(chart.Points), chart.Points[0].Date, chart.Points[len(chart.Points)-1].Date)
+	}
+	fmt.Fprintf(&b, "\n\n---\n_Synced from ticket `%s` (%s)._", t.TicketID, t.Date)
+	return b.String()
+}
+
+// sparkline draws a series as block characters, · for days without data
+func sparkline(points []client.ChartPoint) string {
+	blocks := []rune("▁▂▃▄▅▆▇█")
+	peak := 0.0
+	for _, p := range points {
+		if p.Value > peak {
+			peak = p.Value
+		}
+	}
+	out := make([]rune, len(points))
+	for i, p := range points {
+		switch {
+		case p.NoData:
+			out[i] = '·'
+		case peak == 0:
+			out[i] = blocks[0]
+		default:
+			out[i] = blocks[int(p.Value/peak*float64(len(blocks)-1))]
+		}
+	}
+	return string(out)
+}
+
+// ==================== REST API ====================
+
+type issue struct {
+	Number  int    `json:"number"`
+	HTMLURL string `json:"html_url"`
+}
+
+func (s *settings) comment(ctx context.Context, number int, body string) error {
+	return s.call(ctx, http.MethodPost, fmt.Sprintf("/%d/comments", number), map[string]any{"body": body}, nil)
+}
+
+// call sends a request to the repo's issues API, path relative to /issues
+func (s *settings) call(ctx context.Context, method, path string, in, out any) error {
+	b, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, s.issueAPI+path, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.token)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("GitHub %s issues%s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("GitHub %s issues%s: %s: %s", method, path, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
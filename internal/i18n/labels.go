@@ -0,0 +1,106 @@
+package i18n
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ==================== DISPLAY LABELS ====================
+// API responses keep their enum values (sentiment "Negative", severity
+// "high", trend "declining") as they are, since clients and stored data
+// match on them. For dashboards, the display string of each value in the
+// reader's language is looked up here, by the JSON field it appears in.
+
+// Supported languages
+const (
+	English = "en"
+	Hindi   = "hi"
+)
+
+// Enum kinds with display labels
+const (
+	kindHealth    = "health"
+	kindSentiment = "sentiment"
+	kindLevel     = "level" // Severity and churn risk
+	kindTrend     = "trend"
+)
+
+// fields maps the JSON fields holding an enum to its kind
+var fields = map[string]string{
+	"health_label":       kindHealth,
+	"sentiment":          kindSentiment,
+	"severity":           kindLevel,
+	"base_severity":      kindLevel,
+	"churn_risk":         kindLevel,
+	"sentiment_trend":    kindTrend,
+	"satisfaction_trend": kindTrend,
+	"overall_trend":      kindTrend,
+}
+
+// labels holds each kind's display strings by language, keyed by the lowercased value
+var labels = map[string]map[string]map[string]string{
+	kindHealth: {
+		English: {"healthy": "Healthy", "at risk": "At Risk", "critical": "Critical"},
+		Hindi:   {"healthy": "स्वस्थ", "at risk": "जोखिम में", "critical": "गंभीर"},
+	},
+	kindSentiment: {
+		English: {"positive": "Positive", "neutral": "Neutral", "negative": "Negative"},
+		Hindi:   {"positive": "सकारात्मक", "neutral": "तटस्थ", "negative": "नकारात्मक"},
+	},
+	kindLevel: {
+		English: {"low": "Low", "medium": "Medium", "high": "High", "critical": "Critical"},
+		Hindi:   {"low": "कम", "medium": "मध्यम", "high": "उच्च", "critical": "अति गंभीर"},
+	},
+	kindTrend: {
+		English: {"improving": "Improving", "stable": "Stable", "declining": "Declining"},
+		Hindi:   {"improving": "सुधार", "stable": "स्थिर", "declining": "गिरावट"},
+	},
+}
+
+// Label returns the display string in lang of the value of a JSON field,
+// reporting false when the field isn't an enum or the value is unknown
+func Label(lang, field, value string) (string, bool) {
+	kind, ok := fields[field]
+	if !ok {
+		return "", false
+	}
+	s, ok := labels[kind][lang][strings.ToLower(strings.TrimSpace(value))]
+	return s, ok
+}
+
+// Negotiate picks the supported language an Accept-Language header prefers
+// most, or "" when it names none of them. A region ("hi-IN") matches its
+// language, "*" matches English.
+func Negotiate(header string) string {
+	type choice struct {
+		lang string
+		q    float64
+	}
+	var choices []choice
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = f
+		}
+		lang, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+		if lang == "*" {
+			lang = English
+		}
+		if (lang != English && lang != Hindi) || q <= 0 {
+			continue
+		}
+		choices = append(choices, choice{lang: lang, q: q})
+	}
+	if len(choices) == 0 {
+		return ""
+	}
+	// Equal weights keep the header's order
+	sort.SliceStable(choices, func(i, j int) bool { return choices[i].q > choices[j].q })
+	return choices[0].lang
+}
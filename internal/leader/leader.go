@@ -0,0 +1,151 @@
+package leader
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== LEADER ELECTION ====================
+// With several replicas sharing MongoDB, one of them (the leader) runs the
+// transcript watcher and the schedulers while the others serve API traffic
+// as warm standbys. Leadership is a lease in the leases collection, renewed
+// every third of LEADER_LEASE_TTL. A leader that fails to renew steps down
+// straight away; a standby takes over once the lease expires, or at once if
+// the leader released it on shutdown.
+//
+// Without MongoDB there's nothing shared to elect through, so the instance
+// always leads, as it does with LEADER_ELECTION=false. Instances are named by
+// LEADER_ID, defaulting to hostname-pid (the pod name on Kubernetes).
+
+const leaseName = "scheduler"
+
+var (
+	mu     sync.Mutex
+	status = client.LeaderStatus{Identity: identity()}
+)
+
+func identity() string {
+	if id := os.Getenv("LEADER_ID"); id != "" {
+		return id
+	}
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
+
+// Status returns this instance's role
+func Status() client.LeaderStatus {
+	mu.Lock()
+	defer mu.Unlock()
+	return status
+}
+
+// Run campaigns for leadership until ctx is done, running lead while this
+// instance is the leader. lead must return soon after its context is
+// cancelled, which happens when leadership is lost; Run waits for it before
+// campaigning again, so the leader's work never overlaps itself.
+func Run(ctx context.Context, lead func(ctx context.Context)) {
+	if !storage.IsMongoEnabled() || os.Getenv("LEADER_ELECTION") == "false" {
+		now := time.Now()
+		mu.Lock()
+		status.Backend = client.LeaderBackendSingle
+		status.Leader = true
+		status.Holder = status.Identity
+		status.LeaderSince = &now
+		mu.Unlock()
+		log.Printf("👑 Leader election off, %s runs the watcher and schedulers", status.Identity)
+		lead(ctx)
+		return
+	}
+
+	id := status.Identity
+	ttl := config.EnvDuration("LEADER_LEASE_TTL", config.DEFAULT_LEADER_LEASE_TTL)
+	mu.Lock()
+	status.Backend = client.LeaderBackendMongo
+	mu.Unlock()
+	log.Printf("🗳️ Leader election: %s campaigning (lease TTL %v)", id, ttl)
+
+	var leadCancel context.CancelFunc
+	var leadDone chan struct{}
+	stepDown := func(reason string) {
+		if leadCancel == nil {
+			return
+		}
+		leadCancel()
+		<-leadDone
+		leadCancel = nil
+		log.Printf("🪑 Stepped down as leader: %s", reason)
+	}
+
+	ticker := time.NewTicker(ttl / 3)
+	defer ticker.Stop()
+	for {
+		lease, ok, err := storage.AcquireLease(ctx, leaseName, id, ttl)
+		switch {
+		case ctx.Err() != nil:
+		case err != nil:
+			log.Printf("⚠️ Leader lease renewal failed: %v", err)
+			stepDown("lease renewal failed")
+		case !ok:
+			stepDown("lease taken over")
+		case leadCancel == nil:
+			log.Printf("👑 Elected leader: %s runs the watcher and schedulers", id)
+			var leadCtx context.Context
+			leadCtx, leadCancel = context.WithCancel(ctx)
+			leadDone = make(chan struct{})
+			go func() {
+				defer close(leadDone)
+				lead(leadCtx)
+			}()
+		}
+		setStatus(lease, leadCancel != nil, err)
+
+		select {
+		case <-ctx.Done():
+			stepDown("shutting down")
+			releaseCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			if err := storage.ReleaseLease(releaseCtx, leaseName, id); err != nil {
+				log.Printf("⚠️ Failed to release leader lease: %v", err)
+			}
+			cancel()
+			setStatus(nil, false, nil)
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func setStatus(lease *storage.Lease, leading bool, err error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if leading && !status.Leader {
+		now := time.Now()
+		status.LeaderSince = &now
+	}
+	if !leading {
+		status.LeaderSince = nil
+	}
+	status.Leader = leading
+	status.Holder = ""
+	status.LeaseExpiresAt = nil
+	if lease != nil {
+		status.Holder = lease.Holder
+		expires := lease.ExpiresAt
+		status.LeaseExpiresAt = &expires
+	}
+	status.LastError = ""
+	if err != nil {
+		status.LastError = err.Error()
+	}
+}
@@ -0,0 +1,157 @@
+package lifecycle
+
+import (
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+)
+
+// ==================== READINESS ====================
+// The instance reports not ready (GET /ready answers 503) until each check
+// main expects at startup - storage directories, MongoDB when configured,
+// the first Gemini check - has passed. Once ready it stays ready, since a
+// dependency that blips later is shared by every replica and pulling them all
+// out of the load balancer would turn a degraded service into an outage.
+// A check main lets through degraded (LLM_STARTUP_CHECK=degraded) counts as
+// passed, keeping its error until it really passes.
+//
+// Draining is one-way: it marks the instance unready and gives load
+// balancers DRAIN_DELAY to stop routing to it before the HTTP server shuts
+// down. Kubernetes runs it from a preStop hook (POST /admin/drain); SIGTERM
+// drains too when the hook isn't set up.
+
+var (
+	mu       sync.Mutex
+	checks   []client.ReadinessCheck
+	draining bool
+)
+
+// Expect registers startup checks that must pass before the instance is ready
+func Expect(names ...string) {
+	mu.Lock()
+	defer mu.Unlock()
+	for _, name := range names {
+		if find(name) == nil {
+			checks = append(checks, client.ReadinessCheck{Name: name})
+		}
+	}
+}
+
+// Pass records a check as passed
+func Pass(name string) {
+	set(name, nil, false)
+}
+
+// Fail records a failed check attempt
+func Fail(name string, err error) {
+	set(name, err, false)
+}
+
+// Degrade records a failed check attempt but lets the check through, for a
+// dependency the instance can serve without. A later Pass clears the error.
+func Degrade(name string, err error) {
+	set(name, err, true)
+}
+
+func set(name string, err error, degrade bool) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	c := find(name)
+	if c == nil {
+		checks = append(checks, client.ReadinessCheck{Name: name})
+		c = &checks[len(checks)-1]
+	}
+	if c.OK && (!c.Degraded || err != nil) {
+		return // Passed checks stay passed, degraded ones until they pass
+	}
+	now := time.Now()
+	c.CheckedAt = &now
+	c.OK = err == nil || degrade
+	c.Degraded = err != nil && degrade
+	c.Error = ""
+	if err != nil {
+		c.Error = err.Error()
+	}
+	if c.OK && ready() {
+		if degraded := degradedChecks(); len(degraded) > 0 {
+			log.Printf("🟡 Ready, degraded: %s check failing", strings.Join(degraded, ", "))
+		} else {
+			log.Println("🟢 Ready: all startup checks passed")
+		}
+	}
+}
+
+// find returns the named check, mu must be held
+func find(name string) *client.ReadinessCheck {
+	for i := range checks {
+		if checks[i].Name == name {
+			return &checks[i]
+		}
+	}
+	return nil
+}
+
+// degradedChecks names the checks let through failing, mu must be held
+func degradedChecks() []string {
+	var names []string
+	for _, c := range checks {
+		if c.Degraded {
+			names = append(names, c.Name)
+		}
+	}
+	return names
+}
+
+// ready reports whether every check has passed, mu must be held
+func ready() bool {
+	if draining {
+		return false
+	}
+	for _, c := range checks {
+		if !c.OK {
+			return false
+		}
+	}
+	return true
+}
+
+// Status returns the readiness checks
+func Status() client.ReadinessStatus {
+	mu.Lock()
+	defer mu.Unlock()
+	return client.ReadinessStatus{
+		Ready:    ready(),
+		Draining: draining,
+		Checks:   append([]client.ReadinessCheck(nil), checks...),
+	}
+}
+
+// Ready reports whether the instance should receive traffic
+func Ready() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return ready()
+}
+
+// ==================== DRAIN ====================
+
+// Drain marks the instance unready and waits DRAIN_DELAY for load balancers
+// to notice. Only the first call waits; later ones return at once.
+func Drain() {
+	mu.Lock()
+	first := !draining
+	draining = true
+	mu.Unlock()
+	if !first {
+		return
+	}
+
+	delay := config.EnvDuration("DRAIN_DELAY", config.DEFAULT_DRAIN_DELAY)
+	log.Printf("🚰 Draining: reporting unready, waiting %v for traffic to stop", delay)
+	time.Sleep(delay)
+}
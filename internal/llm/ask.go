@@ -0,0 +1,73 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"im-ai-voice/client"
+)
+
+// maxPlannedQueries caps how many queries one question may be translated into
+const maxPlannedQueries = 3
+
+// QuestionPlan is a question translated into analytics queries. Unanswerable
+// is set instead when the queries can't answer it.
+type QuestionPlan struct {
+	Queries      []client.AnalyticsQuery `json:"queries"`
+	Unanswerable string                  `json:"unanswerable"`
+}
+
+// PlanQuestion translates a plain-language question into queries in the
+// language schema describes
+func (a *AIClient) PlanQuestion(ctx context.Context, question, schema string) (*QuestionPlan, error) {
+	systemPrompt := `You translate questions about IndiaMART seller support calls into structured analytics queries.
+Use only the metrics, group_by values and filters listed in the schema. Resolve relative dates ("last week", "yesterday") against today's date.
+Use at most ` + fmt.Sprint(maxPlannedQueries) + ` queries. If the question can't be answered with them, return no queries and say why in "unanswerable".
+Respond with JSON only:
+{"queries": [{"metric": "...", "group_by": "...", "from": "YYYY-MM-DD", "to": "YYYY-MM-DD", "bucket": "...", "severity": "...", "sentiment": "...", "churn_risk": "...", "city": "...", "vertical": "...", "customer_type": "...", "order": "desc", "limit": 10}], "unanswerable": ""}
+Leave out the fields you don't need.`
+
+	userPrompt := fmt.Sprintf("SCHEMA:\n%s\nQUESTION: %s", schema, question)
+	response, err := a.sendRequest(ctx, TaskQuery, systemPrompt, userPrompt)
+	if err != nil {
+		return nil, fmt.Errorf("LLM request failed: %w", err)
+	}
+
+	var plan QuestionPlan
+	if err := json.Unmarshal([]byte(sanitizeJSONString(extractJSON(response))), &plan); err != nil {
+		return nil, fmt.Errorf("failed to parse LLM response: %w", err)
+	}
+	if len(plan.Queries) > maxPlannedQueries {
+		plan.Queries = plan.Queries[:maxPlannedQueries]
+	}
+	return &plan, nil
+}
+
+// AnswerQuestion phrases the answer to a question from the results of the queries it was planned into
+func (a *AIClient) AnswerQuestion(ctx context.Context, question string, results []client.QueryResult) (string, error) {
+	systemPrompt := `You are an analyst at IndiaMART answering a question about seller support calls from query results.
+Answer in 1-3 plain sentences, quoting the numbers that answer it. Use only the results given; if they don't settle the question, say so.
+Rates are between 0 and 1; give them as percentages. Respond with plain text only. No markdown, no bullet points, no JSON.`
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("QUESTION: %s\n\n", question))
+	for i, r := range results {
+		q, _ := json.Marshal(r.Query)
+		sb.WriteString(fmt.Sprintf("QUERY %d: %s\nMatching calls: %d\n", i+1, q, r.Calls))
+		if len(r.Rows) == 0 {
+			sb.WriteString("  (no rows)\n")
+		}
+		for _, row := range r.Rows {
+			sb.WriteString(fmt.Sprintf("  %s: %g (%d calls)\n", row.Group, row.Value, row.Calls))
+		}
+		sb.WriteString("\n")
+	}
+
+	response, err := a.sendRequest(ctx, TaskSummary, systemPrompt, sb.String())
+	if err != nil {
+		return "", fmt.Errorf("LLM request failed: %w", err)
+	}
+	return strings.TrimSpace(response), nil
+}
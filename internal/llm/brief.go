@@ -0,0 +1,55 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// CallBrief is Gemini's part of a seller's call prep brief
+type CallBrief struct {
+	Who           string   `json:"who"`
+	TalkingPoints []string `json:"talking_points"`
+	Avoid         []string `json:"avoid"`
+}
+
+const maxBriefItems = 5
+
+// WriteCallBrief writes the prep brief for an outbound retention call from
+// what's known about the seller, given as plain text
+func (a *AIClient) WriteCallBrief(ctx context.Context, facts string) (*CallBrief, error) {
+	systemPrompt := `You are a senior retention agent at IndiaMART preparing a colleague for an outbound call to a seller.
+From the seller's profile, open issues, past calls and the promises made to them, write:
+- "who": 2-3 plain sentences on who the seller is and where the relationship stands.
+- "talking_points": 3-5 points to raise, in order, each one sentence. Start with the promises due or broken and the grievances that matter most to the seller.
+- "avoid": 2-4 things not to say or do on this call, each one sentence, e.g. promises that were already broken, topics that upset them, offers they turned down.
+Use only the facts given. Don't invent offers, dates or amounts.
+Respond with JSON only:
+{"who": "...", "talking_points": ["...", "..."], "avoid": ["...", "..."]}`
+
+	response, err := a.sendRequest(ctx, TaskReport, systemPrompt, facts)
+	if err != nil {
+		return nil, fmt.Errorf("LLM request failed: %w", err)
+	}
+
+	var brief CallBrief
+	if err := json.Unmarshal([]byte(sanitizeJSONString(extractJSON(response))), &brief); err != nil {
+		return nil, fmt.Errorf("failed to parse LLM response: %w", err)
+	}
+	brief.Who = strings.TrimSpace(brief.Who)
+	brief.TalkingPoints = briefItems(brief.TalkingPoints)
+	brief.Avoid = briefItems(brief.Avoid)
+	return &brief, nil
+}
+
+// briefItems drops empty items and keeps the first maxBriefItems
+func briefItems(items []string) []string {
+	out := []string{}
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" && len(out) < maxBriefItems {
+			out = append(out, item)
+		}
+	}
+	return out
+}
@@ -0,0 +1,151 @@
+package llm
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+)
+
+// ==================== PROMPT BUDGET ====================
+// Before each request the prompt is estimated against PROMPT_TOKEN_BUDGET.
+// Over budget, the seller context is cut first (from its end, whole lines),
+// then the vertical block is dropped, and the transcript is cut last (from
+// its middle, keeping how the call opened and ended). What was cut is
+// recorded on the analysis as prompt_budget.
+
+const (
+	trimmedContextNote    = "  ... (seller profile trimmed to fit the prompt)\n"
+	trimmedTranscriptNote = "\n[... transcript trimmed to fit the prompt ...]\n"
+)
+
+// promptParts are the variable-size parts of a pass's prompt, and how the
+// pass assembles them
+type promptParts struct {
+	sellerContext string
+	vertical      string
+	transcript    string
+	build         func(promptParts) string
+}
+
+// promptTokenBudget returns PROMPT_TOKEN_BUDGET, or the default when unset or invalid
+func promptTokenBudget() int {
+	if v := os.Getenv("PROMPT_TOKEN_BUDGET"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return n
+		}
+		log.Printf("⚠️ Invalid PROMPT_TOKEN_BUDGET=%q, using %d", v, config.DEFAULT_PROMPT_TOKEN_BUDGET)
+	}
+	return config.DEFAULT_PROMPT_TOKEN_BUDGET
+}
+
+// EstimateTokens approximates Gemini's tokenizer: short ASCII words are one
+// token and long ones a token per six letters, digits and punctuation are a
+// token each, and other scripts (Devanagari) a token per character. It errs
+// on the high side.
+func EstimateTokens(s string) int {
+	tokens, word := 0, 0
+	flush := func() {
+		if word > 0 {
+			tokens += 1 + (word-1)/6
+			word = 0
+		}
+	}
+	for _, r := range s {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || r == '\''):
+			word++
+		case unicode.IsSpace(r):
+			flush()
+		default:
+			flush()
+			tokens++
+		}
+	}
+	flush()
+	return tokens
+}
+
+// fitPrompt builds a pass's prompt within the token budget
+func (a *AIClient) fitPrompt(systemPrompt string, p promptParts) (string, *client.PromptBudget) {
+	systemTokens := EstimateTokens(systemPrompt)
+	total := func() int { return systemTokens + EstimateTokens(p.build(p)) }
+
+	b := &client.PromptBudget{Budget: a.tokenBudget, OriginalTokens: total()}
+	over := b.OriginalTokens - a.tokenBudget
+
+	if over > 0 && p.sellerContext != "" {
+		from := EstimateTokens(p.sellerContext)
+		p.sellerContext = trimLines(p.sellerContext, from-over)
+		b.Trims = append(b.Trims, client.PromptTrim{Part: "seller_context", FromTokens: from, ToTokens: EstimateTokens(p.sellerContext)})
+		over = total() - a.tokenBudget
+	}
+	if over > 0 && p.vertical != "" {
+		b.Trims = append(b.Trims, client.PromptTrim{Part: "vertical", FromTokens: EstimateTokens(p.vertical)})
+		p.vertical = ""
+		over = total() - a.tokenBudget
+	}
+	if over > 0 && p.transcript != "" {
+		from := EstimateTokens(p.transcript)
+		p.transcript = trimMiddle(p.transcript, from-over)
+		b.Trims = append(b.Trims, client.PromptTrim{Part: "transcript", FromTokens: from, ToTokens: EstimateTokens(p.transcript)})
+	}
+
+	prompt := p.build(p)
+	b.EstimatedTokens = systemTokens + EstimateTokens(prompt)
+	if len(b.Trims) > 0 {
+		log.Printf("   ✂️ Prompt trimmed from ~%d to ~%d tokens (budget %d)", b.OriginalTokens, b.EstimatedTokens, b.Budget)
+	}
+	return prompt, b
+}
+
+// trimLines keeps whole lines from the start of s within maxTokens. Without
+// room for more than the heading, nothing is kept.
+func trimLines(s string, maxTokens int) string {
+	maxTokens -= EstimateTokens(trimmedContextNote)
+	lines := strings.SplitAfter(s, "\n")
+	var sb strings.Builder
+	kept, used := 0, 0
+	for _, line := range lines {
+		n := EstimateTokens(line)
+		if used+n > maxTokens {
+			break
+		}
+		sb.WriteString(line)
+		used += n
+		if strings.TrimSpace(line) != "" {
+			kept++
+		}
+	}
+	if kept < 2 {
+		return ""
+	}
+	sb.WriteString(trimmedContextNote)
+	return sb.String()
+}
+
+// trimMiddle cuts the middle out of s until it fits in maxTokens, keeping
+// two thirds of what's left from the start and a third from the end
+func trimMiddle(s string, maxTokens int) string {
+	runes := []rune(s)
+	from := EstimateTokens(s)
+	if from == 0 {
+		return s
+	}
+	keep := len(runes) * maxTokens / from
+	for {
+		if keep <= 0 {
+			return strings.TrimSpace(trimmedTranscriptNote)
+		}
+		head := keep * 2 / 3
+		out := string(runes[:head]) + trimmedTranscriptNote + string(runes[len(runes)-(keep-head):])
+		if EstimateTokens(out) <= maxTokens {
+			return out
+		}
+		keep = keep * 9 / 10
+	}
+}
@@ -0,0 +1,62 @@
+package llm
+
+import (
+	"fmt"
+)
+
+// ==================== CANDIDATE CLIENTS ====================
+// Clients for a candidate model or prompt registry, tried on live calls
+// before switching over: in the shadow (analyses compared, not used) or as
+// a canary rollout (analyses used for a share of calls). They share the
+// primary's HTTP client, API key, rate limit and knowledge base; their
+// requests aren't counted in the primary's stats.
+
+// NewCandidateClient returns a client like a that analyzes with model and
+// the prompt registry at registryPath; empty keeps a's
+func NewCandidateClient(a *AIClient, model, registryPath string) (*AIClient, error) {
+	prompts := a.prompts
+	if registryPath != "" {
+		reg, err := LoadPromptRegistry(registryPath)
+		if err != nil {
+			return nil, err
+		}
+		prompts = reg
+	}
+	if model == "" {
+		model = a.model
+	}
+	if model == a.model && registryPath == "" {
+		return nil, fmt.Errorf("candidate is the same as the primary (set a different model or prompt registry)")
+	}
+	return &AIClient{
+		httpClient:     a.httpClient,
+		apiKey:         a.apiKey,
+		model:          model,
+		prompts:        prompts,
+		safety:         a.safety,
+		redactor:       a.redactor,
+		tokenBudget:    a.tokenBudget,
+		generation:     a.generation,
+		followUpDrafts: a.followUpDrafts,
+		translation:    a.translation,
+		severityHints:  a.severityHints,
+		knowledge:      a.knowledge,
+		limiter:        a.limiter,
+	}, nil
+}
+
+// NewShadowClient is NewCandidateClient for shadow analyses, which don't
+// draft follow-ups: drafts come from the analysis the call is stored with
+func NewShadowClient(a *AIClient, model, registryPath string) (*AIClient, error) {
+	c, err := NewCandidateClient(a, model, registryPath)
+	if err != nil {
+		return nil, err
+	}
+	c.followUpDrafts = false
+	return c, nil
+}
+
+// Model returns the Gemini model the client analyzes with
+func (a *AIClient) Model() string {
+	return a.model
+}
@@ -0,0 +1,45 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"im-ai-voice/client"
+)
+
+// AnswerAboutCall answers a reviewer's question about one call from its
+// transcript, what its analysis found (given as plain text) and the
+// conversation so far. When the prompt is over the token budget the middle
+// of the transcript is cut, and trimmed is set.
+func (a *AIClient) AnswerAboutCall(ctx context.Context, transcript, analysis string, history []client.ChatMessage, question string) (answer string, trimmed bool, err error) {
+	systemPrompt := `You help a quality reviewer at IndiaMART investigate one seller support call.
+Answer the reviewer's questions from the call's transcript and analysis only. When the transcript settles the question, quote the line or lines that do.
+If the transcript doesn't say, answer that it doesn't; never guess what was said. Bracketed tokens like [PHONE_3f9a2c1b0d4e] are masked personal details, don't try to recover them.
+Answer in 1-5 plain sentences. Respond with plain text only. No markdown, no bullet points, no JSON.`
+
+	build := func(transcript string) string {
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "TRANSCRIPT:\n%s\n\nANALYSIS:\n%s\n", transcript, analysis)
+		if len(history) > 0 {
+			sb.WriteString("\nCONVERSATION SO FAR:\n")
+			for _, m := range history {
+				fmt.Fprintf(&sb, "%s: %s\n", strings.ToUpper(m.Role), m.Text)
+			}
+		}
+		fmt.Fprintf(&sb, "\nQUESTION: %s", question)
+		return sb.String()
+	}
+
+	prompt := build(transcript)
+	if over := EstimateTokens(systemPrompt) + EstimateTokens(prompt) - a.tokenBudget; over > 0 {
+		prompt = build(trimMiddle(transcript, EstimateTokens(transcript)-over))
+		trimmed = true
+	}
+
+	response, err := a.sendRequest(ctx, TaskChat, systemPrompt, prompt)
+	if err != nil {
+		return "", trimmed, fmt.Errorf("LLM request failed: %w", err)
+	}
+	return strings.TrimSpace(response), trimmed, nil
+}
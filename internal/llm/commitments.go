@@ -0,0 +1,86 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"im-ai-voice/client"
+)
+
+// ==================== COMMITMENTS ====================
+// The extraction pass lists what the agent promised, with due dates resolved
+// against the call date. When a seller calls again, their open commitments
+// are checked against the new call's transcript.
+
+var commitmentKinds = map[string]bool{"callback": true, "credit": true, "refund": true, "fix": true, "visit": true, "other": true}
+
+func normalizeCommitments(ext *client.CallExtraction) {
+	commitments := ext.Facts.Commitments[:0]
+	for _, c := range ext.Facts.Commitments {
+		if c.Promise = strings.TrimSpace(c.Promise); c.Promise == "" {
+			continue
+		}
+		if c.Kind = strings.ToLower(strings.TrimSpace(c.Kind)); !commitmentKinds[c.Kind] {
+			c.Kind = "other"
+		}
+		if _, err := time.Parse("2006-01-02", strings.TrimSpace(c.DueDate)); err != nil {
+			c.DueDate = "" // Left to the default
+		} else {
+			c.DueDate = strings.TrimSpace(c.DueDate)
+		}
+		commitments = append(commitments, c)
+	}
+	ext.Facts.Commitments = commitments
+}
+
+// CommitmentCheck is what a later call shows about an open commitment
+type CommitmentCheck struct {
+	ID       string `json:"id"`
+	Status   string `json:"status"` // kept, broken
+	Evidence string `json:"evidence"`
+}
+
+// CheckCommitments reports which of a seller's open commitments a later call
+// shows kept or broken. Commitments the call says nothing about are left out.
+func (a *AIClient) CheckCommitments(ctx context.Context, transcript string, open []client.Commitment) ([]CommitmentCheck, error) {
+	systemPrompt := `You check whether promises an IndiaMART support agent made a seller were kept, using a later call with the same seller.
+A promise is kept when the call shows it was done: the callback is this call or the seller confirms it, the credit or refund arrived, the fix works.
+It is broken when the seller says it wasn't done or the agent admits it. Leave out promises the call doesn't settle.
+Respond with JSON only:
+{"checks": [{"id": "...", "status": "kept|broken", "evidence": "one sentence from the call"}]}`
+
+	var sb strings.Builder
+	sb.WriteString("OPEN PROMISES:\n")
+	for _, c := range open {
+		fmt.Fprintf(&sb, "- id %s, made %s, due %s (%s): %s\n", c.ID, c.CallTime.Format("2006-01-02"), c.DueDate, c.Kind, c.Promise)
+	}
+	fmt.Fprintf(&sb, "\nLATER CALL TRANSCRIPT:\n%s", transcript)
+
+	response, err := a.sendRequest(ctx, TaskCommitments, systemPrompt, sb.String())
+	if err != nil {
+		return nil, fmt.Errorf("LLM request failed: %w", err)
+	}
+
+	var parsed struct {
+		Checks []CommitmentCheck `json:"checks"`
+	}
+	if err := json.Unmarshal([]byte(sanitizeJSONString(extractJSON(response))), &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse LLM response: %w", err)
+	}
+
+	known := make(map[string]bool, len(open))
+	for _, c := range open {
+		known[c.ID] = true
+	}
+	checks := parsed.Checks[:0]
+	for _, ch := range parsed.Checks {
+		ch.Status = strings.ToLower(strings.TrimSpace(ch.Status))
+		if known[ch.ID] && (ch.Status == client.CommitmentKept || ch.Status == client.CommitmentBroken) {
+			checks = append(checks, ch)
+		}
+	}
+	return checks, nil
+}
@@ -0,0 +1,63 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+)
+
+// DescribeCallDiff writes a short plain-text narrative of what changed for
+// a seller between call a and call b
+func (a *AIClient) DescribeCallDiff(ctx context.Context, diff *client.CallDiff, callA, callB *client.AnalysisResult) (string, error) {
+	systemPrompt := `You are a retention analyst at IndiaMART reviewing two support calls from the same seller.
+Write 3-5 plain sentences for an account manager on how things changed from call A to call B: what got better, what got worse, and what to do next.
+Respond with plain text only. No markdown, no bullet points, no JSON.`
+
+	var sb strings.Builder
+	for _, c := range []struct {
+		label string
+		ar    *client.AnalysisResult
+	}{{"CALL A", callA}, {"CALL B", callB}} {
+		sb.WriteString(fmt.Sprintf("%s - %s\n", c.label, config.BusinessDate(c.ar.Timestamp)))
+		sb.WriteString(fmt.Sprintf("Summary: %s\n", c.ar.CallSummary))
+		sb.WriteString(fmt.Sprintf("Sentiment: %s, satisfaction %d, churn risk %s\n",
+			c.ar.Intent.Sentiment, c.ar.Intent.SatisfactionScore, c.ar.Churn.IsLikelyToChurn))
+		if c.ar.Churn.ChurnReason != "" {
+			sb.WriteString(fmt.Sprintf("Churn reason: %s\n", c.ar.Churn.ChurnReason))
+		}
+		for _, issue := range c.ar.Issues {
+			sb.WriteString(fmt.Sprintf("  - [%s, %s] %s\n", issue.Bucket, issue.Severity, issue.Problem))
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("COMPUTED CHANGES (A -> B):\n")
+	sb.WriteString(fmt.Sprintf("New issue buckets: %s\n", issueBuckets(diff.IssuesGained)))
+	sb.WriteString(fmt.Sprintf("Issue buckets no longer raised: %s\n", issueBuckets(diff.IssuesLost)))
+	sb.WriteString(fmt.Sprintf("Issue buckets raised in both: %s\n", orNone(diff.IssuesPersisting)))
+	sb.WriteString(fmt.Sprintf("Satisfaction change: %+d, churn risk %s\n", diff.SatisfactionDelta, diff.ChurnChange))
+
+	response, err := a.sendRequest(ctx, TaskSummary, systemPrompt, sb.String())
+	if err != nil {
+		return "", fmt.Errorf("LLM request failed: %w", err)
+	}
+	return strings.TrimSpace(response), nil
+}
+
+func issueBuckets(issues []client.Issue) string {
+	buckets := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		buckets = append(buckets, issue.Bucket)
+	}
+	return orNone(buckets)
+}
+
+func orNone(items []string) string {
+	if len(items) == 0 {
+		return "none"
+	}
+	return strings.Join(items, ", ")
+}
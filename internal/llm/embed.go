@@ -0,0 +1,112 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// ==================== EMBEDDINGS ====================
+
+const (
+	GeminiEmbeddingModel = "text-embedding-004"
+	embedBatchSize       = 100 // Most texts batchEmbedContents takes in one request
+)
+
+type geminiEmbedRequest struct {
+	Requests []geminiEmbedContent `json:"requests"`
+}
+
+type geminiEmbedContent struct {
+	Model    string        `json:"model"`
+	Content  geminiContent `json:"content"`
+	TaskType string        `json:"taskType,omitempty"`
+}
+
+type geminiEmbedResponse struct {
+	Embeddings []struct {
+		Values []float64 `json:"values"`
+	} `json:"embeddings"`
+	Error *geminiError `json:"error,omitempty"`
+}
+
+// Embedding task types, which tune the embeddings for their use
+const (
+	embedClustering        = "CLUSTERING"
+	embedRetrievalDocument = "RETRIEVAL_DOCUMENT"
+	embedRetrievalQuery    = "RETRIEVAL_QUERY"
+)
+
+// EmbedTexts returns an embedding per text, in order, for clustering
+func (a *AIClient) EmbedTexts(ctx context.Context, texts []string) ([][]float64, error) {
+	return a.embed(ctx, texts, embedClustering)
+}
+
+func (a *AIClient) embed(ctx context.Context, texts []string, taskType string) ([][]float64, error) {
+	vectors := make([][]float64, 0, len(texts))
+	for start := 0; start < len(texts); start += embedBatchSize {
+		end := min(start+embedBatchSize, len(texts))
+		batch, err := a.embedBatch(ctx, texts[start:end], taskType)
+		a.stats.record(err != nil)
+		if err != nil {
+			return nil, err
+		}
+		vectors = append(vectors, batch...)
+	}
+	return vectors, nil
+}
+
+func (a *AIClient) embedBatch(ctx context.Context, texts []string, taskType string) ([][]float64, error) {
+	reqBody := geminiEmbedRequest{Requests: make([]geminiEmbedContent, len(texts))}
+	for i, t := range texts {
+		reqBody.Requests[i] = geminiEmbedContent{
+			Model:    "models/" + GeminiEmbeddingModel,
+			Content:  geminiContent{Parts: []geminiPart{{Text: t}}},
+			TaskType: taskType,
+		}
+	}
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	if err := a.waitForRate(ctx); err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s/%s:batchEmbedContents", GeminiBaseURL, GeminiEmbeddingModel)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-goog-api-key", a.apiKey)
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return nil, sendError(ctx, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError(resp.StatusCode, string(body))
+	}
+	var embedResp geminiEmbedResponse
+	if err := json.Unmarshal(body, &embedResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if embedResp.Error != nil {
+		return nil, fmt.Errorf("Gemini API error: %s", embedResp.Error.Message)
+	}
+	if len(embedResp.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("Gemini returned %d embeddings for %d texts", len(embedResp.Embeddings), len(texts))
+	}
+	vectors := make([][]float64, len(texts))
+	for i, e := range embedResp.Embeddings {
+		vectors[i] = e.Values
+	}
+	return vectors, nil
+}
@@ -0,0 +1,47 @@
+package llm
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"im-ai-voice/client"
+)
+
+// maxIssueEvidence is how many quotes an issue keeps
+const maxIssueEvidence = 3
+
+// normalizeEvidence places each issue's quotes in the English transcript.
+// Quotes found verbatim (ignoring case) get their turn and character span;
+// the others keep the turn the model gave, if it's a line of the
+// transcript, and are marked not located. Empty quotes are dropped.
+func normalizeEvidence(ext *client.CallExtraction) {
+	transcript := ext.TranscriptEn
+	lower := strings.ToLower(transcript)
+	turns := strings.Count(transcript, "\n") + 1
+
+	for i := range ext.Issues {
+		evidence := ext.Issues[i].Evidence[:0]
+		for _, e := range ext.Issues[i].Evidence {
+			e.Quote = strings.Trim(strings.TrimSpace(e.Quote), `"'“”`)
+			if e.Quote == "" {
+				continue
+			}
+			e.Start, e.End, e.Located = nil, nil, false
+			// Offsets only carry over when lowercasing kept the byte lengths
+			quote := strings.ToLower(e.Quote)
+			if at := strings.Index(lower, quote); at >= 0 && len(lower) == len(transcript) {
+				turn := strings.Count(transcript[:at], "\n")
+				start := utf8.RuneCountInString(transcript[:at])
+				end := start + utf8.RuneCountInString(transcript[at:at+len(quote)])
+				e.Turn, e.Start, e.End, e.Located = &turn, &start, &end, true
+			} else if e.Turn != nil && (*e.Turn < 0 || *e.Turn >= turns) {
+				e.Turn = nil
+			}
+			evidence = append(evidence, e)
+			if len(evidence) == maxIssueEvidence {
+				break
+			}
+		}
+		ext.Issues[i].Evidence = evidence
+	}
+}
@@ -0,0 +1,36 @@
+package llm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ==================== FOLLOW-UP DRAFTS ====================
+// With FOLLOWUP_DRAFTS=true the scoring pass also drafts a short message for
+// the agent to send the seller after the call, summarizing what was resolved
+// and what happens next. It's stored on the analysis as follow_up_draft and
+// served at /calls/{id}/draft-followup.
+
+// draftLanguage picks the draft's language from the call's: Hindi for calls
+// in Hindi or Hinglish (hi, hi-en), English otherwise
+func draftLanguage(callLanguage string) string {
+	if strings.HasPrefix(strings.ToLower(callLanguage), "hi") {
+		return "Hindi"
+	}
+	return "English"
+}
+
+// buildFollowUpDraftSection asks for the draft in the scoring response
+func buildFollowUpDraftSection(language string) string {
+	script := ""
+	if language == "Hindi" {
+		script = ` in Devanagari script, addressing the seller as "aap" and keeping product names (BuyLeads, Star Pro, etc.) in English`
+	}
+	return fmt.Sprintf(`
+FOLLOW-UP MESSAGE: Also draft a message the agent can send the seller on WhatsApp or by email after this call.
+- Write it in %s%s, in a warm, polite and professional tone
+- 3-5 short sentences: thank them, restate what was resolved, and give the next steps with who does what
+- Promise only what the extracted facts support; never mention scores, churn risk or upsell
+- No placeholders other than [Agent Name]
+`, language, script)
+}
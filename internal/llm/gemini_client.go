@@ -0,0 +1,313 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/chaos"
+	"im-ai-voice/internal/config"
+)
+
+const (
+	GeminiBaseURL = "https://generativelanguage.googleapis.com/v1beta/models"
+	GeminiModel   = "gemini-2.0-flash"
+)
+
+type AIClient struct {
+	httpClient     *http.Client
+	apiKey         string
+	model          string
+	stats          requestStats
+	prompts        *PromptRegistry
+	safety         []geminiSafetySetting
+	redactor       *regexp.Regexp // Masks abusive terms for the retry of a blocked transcript, nil when off
+	tokenBudget    int
+	generation     map[Task]geminiGenerationConfig
+	followUpDrafts bool // FOLLOWUP_DRAFTS: the scoring pass drafts a message to the seller
+	translation    translationCheck
+	severityHints  *promptHints // From severity calibration, empty when off
+	knowledge      *knowledgeStore
+	limiter        RateLimiter         // Paces requests across instances, nil when unlimited
+	recorder       InteractionRecorder // Keeps analysis requests and responses (LLM_RECORD), nil when off
+}
+
+var (
+	// ErrUnavailable wraps failures to get an answer out of Gemini: it
+	// couldn't be reached or returned an error status
+	ErrUnavailable = errors.New("LLM unavailable")
+	// ErrQuotaExceeded wraps Gemini's 429s, sent once the project's quota or
+	// rate limit is spent
+	ErrQuotaExceeded = errors.New("LLM quota exceeded")
+)
+
+// statusError is the error for a Gemini response with a status other than 200
+func statusError(code int, body string) error {
+	sentinel := ErrUnavailable
+	if code == http.StatusTooManyRequests {
+		sentinel = ErrQuotaExceeded
+	}
+	return fmt.Errorf("%w: Gemini returned status %d: %s", sentinel, code, body)
+}
+
+// sendError is the error for a request Gemini didn't answer: the caller's
+// cancellation or deadline when that's what stopped it, so it isn't taken
+// for Gemini being down
+func sendError(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return fmt.Errorf("Gemini request stopped: %w", ctxErr)
+	}
+	return fmt.Errorf("%w: failed to send request to Gemini: %w", ErrUnavailable, err)
+}
+
+// RateLimiter paces Gemini requests
+type RateLimiter interface {
+	Wait(ctx context.Context) error
+}
+
+// SetRateLimiter paces every generation and embedding request through l
+func (a *AIClient) SetRateLimiter(l RateLimiter) {
+	a.limiter = l
+}
+
+// waitForRate blocks until the rate limit allows another request
+func (a *AIClient) waitForRate(ctx context.Context) error {
+	if a.limiter == nil {
+		return nil
+	}
+	if err := a.limiter.Wait(ctx); err != nil {
+		return fmt.Errorf("waiting for the Gemini rate limit: %w", err)
+	}
+	return nil
+}
+
+type geminiRequest struct {
+	Contents         []geminiContent         `json:"contents"`
+	GenerationConfig *geminiGenerationConfig `json:"generationConfig,omitempty"`
+	SafetySettings   []geminiSafetySetting   `json:"safetySettings,omitempty"`
+}
+
+type geminiContent struct {
+	Parts []geminiPart `json:"parts"`
+	Role  string       `json:"role,omitempty"`
+}
+
+type geminiPart struct {
+	Text string `json:"text"`
+}
+
+type geminiResponse struct {
+	Candidates     []geminiCandidate     `json:"candidates"`
+	PromptFeedback *geminiPromptFeedback `json:"promptFeedback,omitempty"`
+	UsageMetadata  *geminiUsageMetadata  `json:"usageMetadata,omitempty"`
+	Error          *geminiError          `json:"error,omitempty"`
+}
+
+type geminiCandidate struct {
+	Content       geminiContent        `json:"content"`
+	FinishReason  string               `json:"finishReason"`
+	SafetyRatings []geminiSafetyRating `json:"safetyRatings,omitempty"`
+}
+
+type geminiError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Status  string `json:"status"`
+}
+
+// requestTimeout caps a single Gemini request for callers without a
+// deadline of their own. Call analyses have theirs (ANALYSIS_TIMEOUT), up to
+// ANALYSIS_TIMEOUT_MAX, which the cap mustn't cut short.
+func requestTimeout() time.Duration {
+	return config.EnvDuration("ANALYSIS_TIMEOUT_MAX", config.DEFAULT_ANALYSIS_TIMEOUT_MAX)
+}
+
+func NewAIClientFromEnv() (*AIClient, error) {
+	return NewAIClient(os.Getenv("GEMINI_API_KEY"), "")
+}
+
+// NewAIClient returns a client analyzing with model (GeminiModel when empty)
+// under apiKey; everything else is configured from the environment
+func NewAIClient(apiKey, model string) (*AIClient, error) {
+	if apiKey == "" {
+		return nil, fmt.Errorf("GEMINI_API_KEY environment variable is required. Get one at https://aistudio.google.com/app/apikey")
+	}
+	if model == "" {
+		model = GeminiModel
+	}
+	return &AIClient{
+		httpClient:     &http.Client{Timeout: requestTimeout(), Transport: chaos.Transport(nil)},
+		apiKey:         apiKey,
+		model:          model,
+		prompts:        loadPromptRegistry(),
+		safety:         loadSafetySettings(),
+		redactor:       loadRedactor(),
+		tokenBudget:    promptTokenBudget(),
+		generation:     loadGenerationConfigs(),
+		followUpDrafts: os.Getenv("FOLLOWUP_DRAFTS") == "true",
+		translation:    loadTranslationCheck(),
+		severityHints:  &promptHints{},
+		knowledge:      newKnowledgeStore(),
+	}, nil
+}
+
+func (a *AIClient) sendRequest(ctx context.Context, task Task, systemPrompt, userPrompt string) (string, error) {
+	start := time.Now()
+	text, usage, err := a.doRequest(ctx, task, systemPrompt, userPrompt)
+	meterRequest(ctx, time.Since(start), usage)
+	// A blocked response is Gemini working as configured, not a failure
+	var blocked *BlockedError
+	a.stats.record(err != nil && !errors.As(err, &blocked))
+	return text, err
+}
+
+// doRequest sends a generation request, returning the response text and,
+// once Gemini answered, its token counts
+func (a *AIClient) doRequest(ctx context.Context, task Task, systemPrompt, userPrompt string) (string, *geminiUsageMetadata, error) {
+	combinedPrompt := fmt.Sprintf("%s\n\n%s", systemPrompt, userPrompt)
+	generation := a.generation[task]
+	reqBody := geminiRequest{
+		Contents:         []geminiContent{{Parts: []geminiPart{{Text: combinedPrompt}}}},
+		GenerationConfig: &generation,
+		SafetySettings:   a.safety,
+	}
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	if err := a.waitForRate(ctx); err != nil {
+		return "", nil, err
+	}
+	url := fmt.Sprintf("%s/%s:generateContent", GeminiBaseURL, a.model)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	// In a header rather than the query, so request errors don't carry it
+	req.Header.Set("x-goog-api-key", a.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return "", nil, sendError(ctx, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", nil, sendError(ctx, fmt.Errorf("failed to read response: %w", err))
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, statusError(resp.StatusCode, string(body))
+	}
+	var geminiResp geminiResponse
+	if err := json.Unmarshal(body, &geminiResp); err != nil {
+		return "", nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	usage := geminiResp.UsageMetadata
+	if geminiResp.Error != nil {
+		return "", usage, fmt.Errorf("Gemini API error: %s", geminiResp.Error.Message)
+	}
+	if fb := geminiResp.PromptFeedback; fb != nil && fb.BlockReason != "" {
+		return "", usage, newBlockedError(fb.BlockReason, fb.SafetyRatings)
+	}
+	if len(geminiResp.Candidates) > 0 && blockedFinishReasons[geminiResp.Candidates[0].FinishReason] {
+		c := geminiResp.Candidates[0]
+		return "", usage, newBlockedError(c.FinishReason, c.SafetyRatings)
+	}
+	if len(geminiResp.Candidates) == 0 || len(geminiResp.Candidates[0].Content.Parts) == 0 {
+		return "", usage, fmt.Errorf("no response from Gemini")
+	}
+	if geminiResp.Candidates[0].FinishReason == "MAX_TOKENS" {
+		log.Printf("⚠️ Gemini %s response cut off at %d output tokens, raise max_output_tokens in GEMINI_GENERATION_%s",
+			task, generation.MaxOutputTokens, strings.ToUpper(string(task)))
+	}
+	return geminiResp.Candidates[0].Content.Parts[0].Text, usage, nil
+}
+
+func (a *AIClient) AnalyzeText(ctx context.Context, text string) (string, error) {
+	return a.sendRequest(ctx, TaskText, "You are an AI model that analyzes call transcripts.", text)
+}
+
+// buildAcousticSection describes signals measured from the call audio, or
+// returns "" for text-only analysis
+func buildAcousticSection(a *client.AcousticSignals) string {
+	if a == nil {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("\nAUDIO SIGNALS (measured from the recording, not the transcript):\n")
+	fmt.Fprintf(&b, "- Duration %.0fs, silent %.0f%% of the call\n", a.DurationSeconds, a.SilenceRatio*100)
+	if a.HoldCount > 0 {
+		fmt.Fprintf(&b, "- %d hold(s), %.0fs in total, longest %.0fs\n", a.HoldCount, a.HoldSeconds, a.LongestHoldSeconds)
+	}
+	if a.Channels >= 2 {
+		fmt.Fprintf(&b, "- Both sides talking at once for %.0f%% of the speech; agent interrupted %d time(s), customer interrupted %d time(s)\n",
+			a.OvertalkRatio*100, a.AgentInterruptions, a.CustomerInterruptions)
+	}
+	if a.EndedAbruptly {
+		b.WriteString("- The recording ends mid-speech (possible dropped call)\n")
+	}
+	if len(a.AgentSignals) > 0 {
+		fmt.Fprintf(&b, "- Agent flags: %s\n", strings.Join(a.AgentSignals, ", "))
+	}
+	if len(a.FrustrationSignals) > 0 {
+		fmt.Fprintf(&b, "- Customer frustration flags: %s\n", strings.Join(a.FrustrationSignals, ", "))
+	}
+	b.WriteString("Weigh these in your ratings; long holds, dead air and talking over the customer count against the agent.\n")
+	return b.String()
+}
+
+func extractJSON(response string) string {
+	response = strings.TrimSpace(response)
+	response = strings.TrimPrefix(response, "```json")
+	response = strings.TrimPrefix(response, "```")
+	response = strings.TrimSuffix(response, "```")
+	response = strings.TrimSpace(response)
+	start := strings.Index(response, "{")
+	end := strings.LastIndex(response, "}")
+	if start >= 0 && end > start {
+		return response[start : end+1]
+	}
+	return response
+}
+
+func sanitizeJSONString(jsonStr string) string {
+	var result strings.Builder
+	inString, escaped := false, false
+	for i := 0; i < len(jsonStr); i++ {
+		c := jsonStr[i]
+		if escaped {
+			result.WriteByte(c)
+			escaped = false
+			continue
+		}
+		if c == '\\' {
+			result.WriteByte(c)
+			escaped = true
+			continue
+		}
+		if c == '"' {
+			inString = !inString
+			result.WriteByte(c)
+			continue
+		}
+		if inString && (c == '\n' || c == '\r') {
+			result.WriteByte(' ')
+			continue
+		}
+		result.WriteByte(c)
+	}
+	return result.String()
+}
+
+func (a *AIClient) Close() error { return nil }
@@ -0,0 +1,125 @@
+package llm
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ==================== GENERATION CONFIG ====================
+// Sampling and output limits per task. GEMINI_GENERATION overrides every
+// task, and GEMINI_GENERATION_<TASK> (e.g. GEMINI_GENERATION_EXTRACTION)
+// one task on top of that, as comma-separated settings:
+//
+//	GEMINI_GENERATION_EXTRACTION="temperature=0.1,max_output_tokens=8192"
+//
+// Settings are temperature, top_p, top_k and max_output_tokens.
+
+// Task is a kind of Gemini request with its own generation config
+type Task string
+
+const (
+	TaskExtraction  Task = "extraction"  // First analysis pass, long output (transcript_en)
+	TaskScoring     Task = "scoring"     // Second analysis pass
+	TaskSummary     Task = "summary"     // Plain-text narratives (call diffs, /ask answers)
+	TaskText        Task = "text"        // Free-form POST /analyze requests
+	TaskQuery       Task = "query"       // Translating /ask questions into analytics queries
+	TaskCommitments Task = "commitments" // Checking a seller's open commitments against a later call
+	TaskReport      Task = "report"      // Weekly executive narratives and recommendations, call prep briefs
+	TaskTranslation Task = "translation" // Retranslating a transcript_en that failed the translation check
+	TaskReclassify  Task = "reclassify"  // Placing issues of a split bucket in its new buckets
+	TaskChat        Task = "chat"        // Reviewers' questions about a call (POST /calls/{id}/chat)
+)
+
+var tasks = []Task{TaskExtraction, TaskScoring, TaskSummary, TaskText, TaskQuery, TaskCommitments, TaskReport, TaskTranslation, TaskReclassify, TaskChat}
+
+type geminiGenerationConfig struct {
+	Temperature     *float64 `json:"temperature,omitempty"` // Pointers, so 0 is sent rather than dropped
+	TopP            *float64 `json:"topP,omitempty"`
+	TopK            int      `json:"topK,omitempty"`
+	MaxOutputTokens int      `json:"maxOutputTokens,omitempty"`
+}
+
+// defaultGeneration is each task's config before env overrides. Extraction,
+// translation and free-form text get gemini-2.0-flash's full 8192 output
+// tokens, long calls need them for the translated transcript.
+func defaultGeneration(task Task) geminiGenerationConfig {
+	g := geminiGenerationConfig{Temperature: floatPtr(0.3), TopP: floatPtr(0.95), TopK: 40, MaxOutputTokens: 8192}
+	switch task {
+	case TaskScoring:
+		g.MaxOutputTokens = 2048
+	case TaskSummary, TaskChat:
+		g.MaxOutputTokens = 1024
+	case TaskReport:
+		g.MaxOutputTokens = 2048
+	case TaskQuery:
+		g.Temperature = floatPtr(0) // The same question should get the same queries
+		g.MaxOutputTokens = 1024
+	case TaskCommitments:
+		g.Temperature = floatPtr(0)
+		g.MaxOutputTokens = 1024
+	case TaskTranslation:
+		g.Temperature = floatPtr(0.1)
+	case TaskReclassify:
+		g.Temperature = floatPtr(0) // A migration rerun should place issues the same way
+		g.MaxOutputTokens = 2048
+	}
+	return g
+}
+
+func floatPtr(v float64) *float64 { return &v }
+
+// loadGenerationConfigs reads each task's config from the environment
+func loadGenerationConfigs() map[Task]geminiGenerationConfig {
+	configs := make(map[Task]geminiGenerationConfig, len(tasks))
+	for i, task := range tasks {
+		g := defaultGeneration(task)
+		applyGenerationEnv(&g, "GEMINI_GENERATION", i == 0) // Warn about shared settings once
+		applyGenerationEnv(&g, "GEMINI_GENERATION_"+strings.ToUpper(string(task)), true)
+		configs[task] = g
+	}
+	return configs
+}
+
+// applyGenerationEnv applies the settings in an env var, skipping malformed ones
+func applyGenerationEnv(g *geminiGenerationConfig, name string, warn bool) {
+	v := os.Getenv(name)
+	if v == "" {
+		return
+	}
+	for _, pair := range strings.Split(v, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if (!ok || !setGeneration(g, strings.TrimSpace(key), strings.TrimSpace(value))) && warn {
+			log.Printf("⚠️ Ignoring invalid %s entry %q", name, pair)
+		}
+	}
+}
+
+func setGeneration(g *geminiGenerationConfig, key, value string) bool {
+	switch key {
+	case "temperature", "top_p":
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil || f < 0 || (key == "temperature" && f > 2) || (key == "top_p" && f > 1) {
+			return false
+		}
+		if key == "temperature" {
+			g.Temperature = &f
+		} else {
+			g.TopP = &f
+		}
+	case "top_k", "max_output_tokens":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return false
+		}
+		if key == "top_k" {
+			g.TopK = n
+		} else {
+			g.MaxOutputTokens = n
+		}
+	default:
+		return false
+	}
+	return true
+}
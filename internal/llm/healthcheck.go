@@ -0,0 +1,182 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"im-ai-voice/internal/config"
+)
+
+// ==================== STARTUP CHECK ====================
+// Before the instance takes calls, a one-token generation request confirms
+// Gemini accepts the API key, serves the model to the key's project and
+// answers from where the server runs - the three things that otherwise only
+// show up as failed analyses. A failure is classified so the log and
+// GET /ready say what to fix. LLM_STARTUP_CHECK picks what a failing check
+// does: "wait" keeps the instance unready and retries, "fail" exits on a
+// problem retrying can't fix (key, permission, model, region), and
+// "degraded" turns ready with calls analyzed provisionally until it passes.
+
+// What LLM_STARTUP_CHECK does with a failing check
+const (
+	StartupWait     = "wait"
+	StartupFail     = "fail"
+	StartupDegraded = "degraded"
+)
+
+// Problems a failed check can point to (CheckError.Problem)
+const (
+	ProblemKey         = "key_invalid"        // Gemini rejected the API key
+	ProblemPermission  = "permission_denied"  // The key's project can't use the Gemini API
+	ProblemModel       = "model_not_found"    // The model isn't served to the key's project
+	ProblemRegion      = "region_unsupported" // Gemini isn't offered where requests come from
+	ProblemQuota       = "quota_exceeded"
+	ProblemUnreachable = "unreachable" // No answer from Gemini at all
+	ProblemUnavailable = "unavailable" // Any other failure, Gemini's own
+)
+
+// CheckError is a failed startup check, with what to do about it
+type CheckError struct {
+	Problem string
+	Model   string
+	Status  int    // Gemini's HTTP status, 0 when it didn't answer
+	Detail  string // Gemini's error message, or the transport error
+}
+
+func (e *CheckError) Error() string {
+	detail := strings.TrimRight(e.Detail, ". ")
+	if e.Status == 0 {
+		return fmt.Sprintf("Gemini check failed (%s): %s. %s", e.Problem, detail, e.Remediation())
+	}
+	return fmt.Sprintf("Gemini check failed (%s, status %d): %s. %s", e.Problem, e.Status, detail, e.Remediation())
+}
+
+// Permanent reports whether retrying can't fix the problem without a
+// configuration change
+func (e *CheckError) Permanent() bool {
+	switch e.Problem {
+	case ProblemKey, ProblemPermission, ProblemModel, ProblemRegion:
+		return true
+	}
+	return false
+}
+
+// Remediation says how to fix the problem
+func (e *CheckError) Remediation() string {
+	switch e.Problem {
+	case ProblemKey:
+		return "Check GEMINI_API_KEY is complete and hasn't been deleted or expired, or create one at https://aistudio.google.com/app/apikey"
+	case ProblemPermission:
+		return "Enable the Generative Language API for the key's Google Cloud project, or lift the key's API and IP restrictions"
+	case ProblemModel:
+		return fmt.Sprintf("Model %s isn't served to this key's project; check the name against GET %s, or whether it was retired", e.Model, GeminiBaseURL)
+	case ProblemRegion:
+		return "Gemini isn't offered where this server's requests come from; run it in a supported region or send Gemini traffic through a proxy in one (HTTPS_PROXY)"
+	case ProblemQuota:
+		return "The project's quota or rate limit is spent; it's retried, or raise the limit in the Google Cloud console"
+	case ProblemUnreachable:
+		return "Check DNS, firewall and proxy settings for outbound HTTPS to generativelanguage.googleapis.com"
+	}
+	return "Gemini may be having an outage; it's retried every READY_RETRY_INTERVAL"
+}
+
+// Check sends Gemini a one-token request with the configured key and model,
+// returning a *CheckError when it fails. It isn't counted in the request
+// stats or the token usage.
+func (a *AIClient) Check(ctx context.Context) error {
+	reqBody := geminiRequest{
+		Contents:         []geminiContent{{Parts: []geminiPart{{Text: "Reply with OK."}}}},
+		GenerationConfig: &geminiGenerationConfig{Temperature: floatPtr(0), MaxOutputTokens: 1},
+	}
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+	if err := a.waitForRate(ctx); err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s/%s:generateContent", GeminiBaseURL, a.model), bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	// In a header rather than the query, so errors surfaced on /ready don't carry it
+	req.Header.Set("x-goog-api-key", a.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil && !errors.Is(ctxErr, context.DeadlineExceeded) {
+			return ctxErr
+		}
+		return &CheckError{Problem: ProblemUnreachable, Model: a.model, Detail: err.Error()}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	e := classifyCheck(resp.StatusCode, body)
+	e.Model = a.model
+	return e
+}
+
+// classifyCheck turns Gemini's error response into a CheckError
+func classifyCheck(code int, body []byte) *CheckError {
+	var parsed struct {
+		Error struct {
+			Message string `json:"message"`
+			Status  string `json:"status"`
+			Details []struct {
+				Reason string `json:"reason"`
+			} `json:"details"`
+		} `json:"error"`
+	}
+	e := &CheckError{Problem: ProblemUnavailable, Status: code, Detail: strings.TrimSpace(string(body))}
+	if json.Unmarshal(body, &parsed) != nil || parsed.Error.Message == "" {
+		if code == http.StatusTooManyRequests {
+			e.Problem = ProblemQuota
+		}
+		return e
+	}
+	e.Detail = parsed.Error.Message
+	reasons := map[string]bool{}
+	for _, d := range parsed.Error.Details {
+		reasons[d.Reason] = true
+	}
+	msg := strings.ToLower(parsed.Error.Message)
+
+	switch {
+	case reasons["API_KEY_INVALID"] || code == http.StatusUnauthorized || strings.Contains(msg, "api key not valid") || strings.Contains(msg, "api key expired"):
+		e.Problem = ProblemKey
+	case strings.Contains(msg, "location is not supported"):
+		e.Problem = ProblemRegion
+	case code == http.StatusNotFound || parsed.Error.Status == "NOT_FOUND":
+		e.Problem = ProblemModel
+	case code == http.StatusForbidden || parsed.Error.Status == "PERMISSION_DENIED" || reasons["SERVICE_DISABLED"] || reasons["API_KEY_SERVICE_BLOCKED"]:
+		e.Problem = ProblemPermission
+	case code == http.StatusTooManyRequests:
+		e.Problem = ProblemQuota
+	}
+	return e
+}
+
+// StartupCheckFromEnv reads LLM_STARTUP_CHECK
+func StartupCheckFromEnv() string {
+	v := os.Getenv("LLM_STARTUP_CHECK")
+	if v == "" {
+		return config.DEFAULT_LLM_STARTUP_CHECK
+	}
+	switch mode := strings.ToLower(v); mode {
+	case StartupWait, StartupFail, StartupDegraded:
+		return mode
+	}
+	log.Printf("⚠️ Invalid LLM_STARTUP_CHECK=%q, using %s", v, config.DEFAULT_LLM_STARTUP_CHECK)
+	return config.DEFAULT_LLM_STARTUP_CHECK
+}
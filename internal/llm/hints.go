@@ -0,0 +1,42 @@
+package llm
+
+import (
+	"strings"
+	"sync"
+)
+
+// ==================== SEVERITY HINTS ====================
+// Guidance from the latest severity calibration run on the buckets whose
+// issues the extraction pass rates too high or too low, put in the
+// extraction prompt next to the vertical block. The service hands them over
+// with SEVERITY_CALIBRATION_HINTS=true.
+
+// promptHints is shared by a client and its candidates
+type promptHints struct {
+	mu    sync.RWMutex
+	hints []string
+}
+
+// SetSeverityHints replaces the severity guidance in extraction prompts;
+// none removes it
+func (a *AIClient) SetSeverityHints(hints []string) {
+	a.severityHints.mu.Lock()
+	defer a.severityHints.mu.Unlock()
+	a.severityHints.hints = append([]string(nil), hints...)
+}
+
+// severitySection is the severity guidance for the extraction prompt, "" without any
+func (a *AIClient) severitySection() string {
+	a.severityHints.mu.RLock()
+	defer a.severityHints.mu.RUnlock()
+	if len(a.severityHints.hints) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("\nSEVERITY CALIBRATION (from the outcomes of past issues):\n")
+	for _, h := range a.severityHints.hints {
+		b.WriteString("- " + h + "\n")
+	}
+	b.WriteString("\n")
+	return b.String()
+}
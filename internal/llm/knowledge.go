@@ -0,0 +1,223 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"unicode/utf8"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/flags"
+)
+
+// ==================== KNOWLEDGE BASE ====================
+// Product, pricing and policy documents uploaded through /admin/kb are split
+// into passages and embedded. Each analysis pass embeds what it's about (the
+// transcript for extraction, the extracted facts for scoring) and gets the
+// KB_TOP_K most similar passages in place of the built-in IndiaMART context.
+// With no documents uploaded, or when retrieval fails, the prompts fall back
+// to config.IndiaMARTContext.
+
+const (
+	kbChunkChars    = 1500 // Passages are packed up to this many bytes
+	kbQueryMaxChars = 8000 // Characters, roughly text-embedding-004's 2,048 input tokens
+)
+
+// knowledgeBase is the embedded passages of every document, as unit vectors
+type knowledgeBase struct {
+	passages []kbPassage
+}
+
+type kbPassage struct {
+	title  string
+	text   string
+	vector []float64
+}
+
+// knowledgeStore holds the client's knowledge base, swapped whole on reload
+type knowledgeStore struct {
+	kb            atomic.Pointer[knowledgeBase]
+	topK          int
+	minSimilarity float64
+}
+
+func newKnowledgeStore() *knowledgeStore {
+	return &knowledgeStore{topK: kbTopK(), minSimilarity: kbMinSimilarity()}
+}
+
+// kbTopK returns KB_TOP_K, or the default when unset or invalid
+func kbTopK() int {
+	if v := os.Getenv("KB_TOP_K"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return n
+		}
+		log.Printf("⚠️ Invalid KB_TOP_K=%q, using %d", v, config.DEFAULT_KB_TOP_K)
+	}
+	return config.DEFAULT_KB_TOP_K
+}
+
+// kbMinSimilarity returns KB_MIN_SIMILARITY, or the default when unset or invalid
+func kbMinSimilarity() float64 {
+	if v := os.Getenv("KB_MIN_SIMILARITY"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil && f >= 0 && f <= 1 {
+			return f
+		}
+		log.Printf("⚠️ Invalid KB_MIN_SIMILARITY=%q, using %g", v, config.DEFAULT_KB_MIN_SIMILARITY)
+	}
+	return config.DEFAULT_KB_MIN_SIMILARITY
+}
+
+// ChunkDocument splits a document into passages of up to kbChunkChars,
+// keeping paragraphs whole where they fit
+func ChunkDocument(content string) []string {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	var chunks []string
+	var current strings.Builder
+	flush := func() {
+		if s := strings.TrimSpace(current.String()); s != "" {
+			chunks = append(chunks, s)
+		}
+		current.Reset()
+	}
+	for _, para := range strings.Split(content, "\n\n") {
+		para = strings.TrimSpace(para)
+		if para == "" {
+			continue
+		}
+		if current.Len() > 0 && current.Len()+2+len(para) > kbChunkChars {
+			flush()
+		}
+		for len(para) > kbChunkChars {
+			cut := strings.LastIndexAny(para[:kbChunkChars], " \n")
+			if cut <= 0 {
+				cut = kbChunkChars
+				for cut > 0 && !utf8.RuneStart(para[cut]) {
+					cut-- // Not inside a character
+				}
+			}
+			current.WriteString(para[:cut])
+			flush()
+			para = strings.TrimSpace(para[cut:])
+		}
+		if current.Len() > 0 {
+			current.WriteString("\n\n")
+		}
+		current.WriteString(para)
+	}
+	flush()
+	return chunks
+}
+
+// EmbedKBChunks embeds a document's passages for retrieval. The title goes
+// with each passage, so passages that only make sense in context match too.
+func (a *AIClient) EmbedKBChunks(ctx context.Context, title string, chunks []string) ([][]float64, error) {
+	texts := make([]string, len(chunks))
+	for i, c := range chunks {
+		texts[i] = title + "\n\n" + c
+	}
+	return a.embed(ctx, texts, embedRetrievalDocument)
+}
+
+// SetKnowledgeBase replaces the passages analyses are grounded in. Chunks
+// without a vector are skipped.
+func (a *AIClient) SetKnowledgeBase(docs []client.KBDocument) {
+	kb := &knowledgeBase{}
+	for _, doc := range docs {
+		for _, c := range doc.Chunks {
+			if unit := unitVector(c.Vector); unit != nil {
+				kb.passages = append(kb.passages, kbPassage{title: doc.Title, text: c.Text, vector: unit})
+			}
+		}
+	}
+	a.knowledge.kb.Store(kb)
+}
+
+// KnowledgePassages returns how many passages the knowledge base has
+func (a *AIClient) KnowledgePassages() int {
+	if kb := a.knowledge.kb.Load(); kb != nil {
+		return len(kb.passages)
+	}
+	return 0
+}
+
+// groundingContext returns the IndiaMART knowledge for a prompt about query:
+// the most relevant knowledge base passages, or the built-in context when
+// there's no knowledge base, the kb_retrieval flag is off for the call or
+// retrieval fails
+func (a *AIClient) groundingContext(ctx context.Context, rt client.RawTranscript, query string) string {
+	kb := a.knowledge.kb.Load()
+	if kb == nil || len(kb.passages) == 0 || !flags.Enabled(ctx, client.FlagKBRetrieval, rt.CallID, rt.Origin) {
+		return config.IndiaMARTContext
+	}
+	callID := rt.CallID
+	if runes := []rune(query); len(runes) > kbQueryMaxChars {
+		query = string(runes[:kbQueryMaxChars])
+	}
+	vectors, err := a.embed(ctx, []string{query}, embedRetrievalQuery)
+	if err != nil {
+		log.Printf("   ⚠️ Call %s: knowledge base retrieval failed, using the built-in context: %v", callID, err)
+		return config.IndiaMARTContext
+	}
+	q := unitVector(vectors[0])
+
+	type scored struct {
+		p   *kbPassage
+		sim float64
+	}
+	var matches []scored
+	for i := range kb.passages {
+		p := &kb.passages[i]
+		if len(p.vector) != len(q) {
+			continue
+		}
+		if sim := dot(p.vector, q); sim >= a.knowledge.minSimilarity {
+			matches = append(matches, scored{p, sim})
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].sim > matches[j].sim })
+	if len(matches) > a.knowledge.topK {
+		matches = matches[:a.knowledge.topK]
+	}
+	log.Printf("   📚 Call %s: %d knowledge base passages", callID, len(matches))
+
+	var b strings.Builder
+	b.WriteString("=== INDIAMART KNOWLEDGE (passages from product, pricing and policy documents relevant to this call) ===\n")
+	if len(matches) == 0 {
+		b.WriteString("\nNo passage matched this call. Don't assume product details, prices or policies that aren't stated.\n")
+	}
+	for _, m := range matches {
+		fmt.Fprintf(&b, "\n[%s]\n%s\n", m.p.title, m.p.text)
+	}
+	return b.String()
+}
+
+func unitVector(v []float64) []float64 {
+	var sum float64
+	for _, x := range v {
+		sum += x * x
+	}
+	if sum == 0 {
+		return nil
+	}
+	norm := math.Sqrt(sum)
+	unit := make([]float64, len(v))
+	for i, x := range v {
+		unit[i] = x / norm
+	}
+	return unit
+}
+
+func dot(a, b []float64) float64 {
+	var sum float64
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
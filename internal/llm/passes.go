@@ -0,0 +1,564 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/flags"
+)
+
+// ==================== TWO-PASS ANALYSIS ====================
+// A call is analyzed in two requests. Extraction reads the transcript and
+// reports issues and facts against a strict schema, without judging the
+// seller. Scoring never sees the transcript: it rates churn, upsell and
+// satisfaction from the extraction plus the seller's profile and account
+// data. Extractions are stored (storage.SaveExtraction), so scoring can be
+// re-run on its own when the scoring prompt changes.
+
+// AnalyzeCall runs both passes on a transcript. The extraction is nil when
+// there's none worth keeping: the call was blocked or the response
+// couldn't be parsed. The analysis carries the Gemini usage of both passes.
+func (a *AIClient) AnalyzeCall(ctx context.Context, rt client.RawTranscript, sellerContext string) (*client.AnalysisResult, *client.CallExtraction, error) {
+	ctx, meter := withUsageMeter(ctx)
+	analysis, ext, err := a.analyzeCall(ctx, rt, sellerContext)
+	if analysis != nil {
+		analysis.LLMUsage = meter.total()
+	}
+	return analysis, ext, err
+}
+
+func (a *AIClient) analyzeCall(ctx context.Context, rt client.RawTranscript, sellerContext string) (*client.AnalysisResult, *client.CallExtraction, error) {
+	ext, raw, err := a.extract(ctx, rt)
+	var blocked *blockedCall
+	if errors.As(err, &blocked) {
+		return blockedAnalysis(rt, blocked.safety, blocked.budget), nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if ext == nil {
+		return unparsedAnalysis(rt, raw), nil, nil
+	}
+
+	analysis, err := a.ScoreCall(ctx, rt, ext, sellerContext)
+	if err != nil {
+		return nil, nil, err
+	}
+	if analysis.LLMRaw != nil {
+		analysis.LLMRaw["raw_extraction"] = raw // Split off into llm_raw_responses on save
+	}
+	return analysis, ext, nil
+}
+
+// blockedCall carries what's known about a blocked extraction back to AnalyzeCall
+type blockedCall struct {
+	safety *client.SafetyBlock
+	budget *client.PromptBudget
+}
+
+func (b *blockedCall) Error() string { return "blocked by Gemini safety filters" }
+
+// extract runs the extraction pass. A response that can't be parsed returns
+// a nil extraction with the raw response.
+func (a *AIClient) extract(ctx context.Context, rt client.RawTranscript) (*client.CallExtraction, string, error) {
+	vertical := transcriptVertical(rt)
+	verticalPrompt := a.prompts.ForVertical(vertical)
+	systemPrompt := buildExtractionSystemPrompt(a.groundingContext(ctx, rt, rt.Transcript))
+	verticalSection := buildVerticalSection(vertical, verticalPrompt)
+	channelSection := buildChannelSection(rt.Channel)
+	if flags.Enabled(ctx, client.FlagSeverityHints, rt.CallID, rt.Origin) {
+		verticalSection += a.severitySection()
+	}
+	parts := promptParts{
+		vertical:   verticalSection,
+		transcript: rt.Transcript,
+		build: func(p promptParts) string {
+			return buildExtractionPrompt(p.transcript, channelSection+p.vertical, issueCategories(verticalPrompt), rt.Timestamp, rt.Acoustic)
+		},
+	}
+	prompt, budget := a.fitPrompt(systemPrompt, parts)
+	response, err := a.sendRequest(ctx, TaskExtraction, systemPrompt, prompt)
+
+	var blocked *BlockedError
+	var safety *client.SafetyBlock
+	if errors.As(err, &blocked) {
+		safety = &client.SafetyBlock{Reason: blocked.Reason, Categories: blocked.Categories}
+		log.Printf("   🚫 Call %s: %v", rt.CallID, blocked)
+		redacted, n := "", 0
+		if a.redactor != nil {
+			redacted, n = redactTranscript(a.redactor, rt.Transcript)
+		}
+		if n == 0 {
+			return nil, "", &blockedCall{safety: safety, budget: budget}
+		}
+		safety.RedactedTerms = n
+		parts.transcript = redacted
+		prompt, budget = a.fitPrompt(systemPrompt, parts)
+		response, err = a.sendRequest(ctx, TaskExtraction, systemPrompt, prompt)
+		if errors.As(err, &blocked) {
+			log.Printf("   🚫 Call %s: redacted retry blocked too: %v", rt.CallID, blocked)
+			return nil, "", &blockedCall{safety: safety, budget: budget}
+		}
+		safety.RedactedRetry = err == nil
+	}
+	if err != nil {
+		a.record(ctx, TaskExtraction, rt, verticalPrompt, "", systemPrompt, prompt, response, err)
+		return nil, "", fmt.Errorf("extraction request failed: %w", err)
+	}
+
+	var ext *client.CallExtraction
+	response, err = a.parseResponse(ctx, TaskExtraction, rt.CallID, systemPrompt, prompt, response, func(response string) (repairs []string, err error) {
+		ext, repairs, err = parseExtraction(response, rt)
+		return repairs, err
+	})
+	a.record(ctx, TaskExtraction, rt, verticalPrompt, "", systemPrompt, prompt, response, nil)
+	if err != nil {
+		log.Printf("WARNING: Failed to parse extraction for call %s: %v", rt.CallID, err)
+		return nil, response, nil
+	}
+	if verticalPrompt != nil {
+		ext.VerticalPrompt = verticalPrompt.Name
+		rollUpBuckets(ext.Issues, verticalPrompt)
+	}
+	ext.SafetyBlock = safety
+	ext.PromptBudget = budget
+	a.checkTranslation(ctx, rt, ext)
+	return ext, response, nil
+}
+
+// ScoreCall runs the scoring pass on an extraction and assembles the
+// call's analysis
+func (a *AIClient) ScoreCall(ctx context.Context, rt client.RawTranscript, ext *client.CallExtraction, sellerContext string) (*client.AnalysisResult, error) {
+	vertical := transcriptVertical(rt)
+	verticalPrompt := a.prompts.ForVertical(vertical)
+	draftLang := ""
+	if a.followUpDrafts && flags.Enabled(ctx, client.FlagFollowUpDrafts, rt.CallID, rt.Origin) {
+		draftLang = draftLanguage(rt.Language)
+	}
+	facts, err := scoringFacts(ext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal extraction: %w", err)
+	}
+	systemPrompt := buildScoringSystemPrompt(a.groundingContext(ctx, rt, facts))
+	channelSection := buildChannelSection(rt.Channel)
+	parts := promptParts{
+		sellerContext: sellerContext,
+		vertical:      buildVerticalSection(vertical, verticalPrompt),
+		build: func(p promptParts) string {
+			return buildScoringPrompt(facts, p.sellerContext, buildSellerDataSection(rt.Metadata), channelSection+p.vertical, ext.Acoustic, draftLang)
+		},
+	}
+	prompt, budget := a.fitPrompt(systemPrompt, parts)
+	response, err := a.sendRequest(ctx, TaskScoring, systemPrompt, prompt)
+	if err != nil {
+		a.record(ctx, TaskScoring, rt, verticalPrompt, draftLang, systemPrompt, prompt, response, err)
+	}
+
+	var blocked *BlockedError
+	if errors.As(err, &blocked) {
+		log.Printf("   🚫 Call %s: scoring %v", rt.CallID, blocked)
+		return blockedAnalysis(rt, &client.SafetyBlock{Reason: blocked.Reason, Categories: blocked.Categories}, ext.PromptBudget), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("scoring request failed: %w", err)
+	}
+
+	analysis := analysisFromExtraction(rt, ext)
+	analysis.ScoringBudget = budget
+	analysis.Model, analysis.PromptVersion = a.model, a.PromptVersion() // Which predictions churn accuracy is reported by
+	response, err = a.parseResponse(ctx, TaskScoring, rt.CallID, systemPrompt, prompt, response, func(response string) ([]string, error) {
+		return applyScores(analysis, response, draftLang)
+	})
+	a.record(ctx, TaskScoring, rt, verticalPrompt, draftLang, systemPrompt, prompt, response, nil)
+	analysis.LLMRaw["raw_scoring"] = response
+	if err != nil {
+		log.Printf("WARNING: Failed to parse scores for call %s: %v", rt.CallID, err)
+		analysis.LLMRaw["parse_error"] = err.Error()
+	}
+	return analysis, nil
+}
+
+// blockedAnalysis stands in for the analysis of a transcript Gemini
+// wouldn't analyze, so the call is stored rather than retried forever
+func blockedAnalysis(rt client.RawTranscript, safety *client.SafetyBlock, budget *client.PromptBudget) *client.AnalysisResult {
+	return &client.AnalysisResult{
+		CallID: rt.CallID, SellerID: rt.SellerID, Timestamp: rt.Timestamp, Channel: rt.Channel,
+		TranscriptEn: rt.Transcript, OriginalLang: rt.Language,
+		CallSummary:  "Not analyzed: blocked by Gemini safety filters",
+		LLMRaw:       map[string]interface{}{"blocked": safety.Reason},
+		Acoustic:     rt.Acoustic,
+		Blocked:      true,
+		SafetyBlock:  safety,
+		PromptBudget: budget,
+		AnalyzedAt:   time.Now(),
+	}
+}
+
+// unparsedAnalysis keeps the raw extraction response of a call whose
+// extraction couldn't be parsed
+func unparsedAnalysis(rt client.RawTranscript, response string) *client.AnalysisResult {
+	return &client.AnalysisResult{
+		CallID: rt.CallID, SellerID: rt.SellerID, Timestamp: rt.Timestamp, Channel: rt.Channel,
+		TranscriptEn: rt.Transcript, OriginalLang: rt.Language,
+		LLMRaw:     map[string]interface{}{"raw_extraction": response, "parse_error": "extraction could not be parsed"},
+		Acoustic:   rt.Acoustic,
+		AnalyzedAt: time.Now(),
+	}
+}
+
+// ==================== EXTRACTION PASS ====================
+
+// buildExtractionSystemPrompt grounds the extraction pass in knowledge, the
+// IndiaMART context relevant to the call
+func buildExtractionSystemPrompt(knowledge string) string {
+	return fmt.Sprintf(`You are an expert customer service analyst for IndiaMART, India's largest B2B marketplace.
+
+%s
+
+YOUR TASK: Extract what was said in a seller support call. Report facts only; churn risk, upsell potential and satisfaction are scored separately from your output.
+
+EXTRACTION GUIDELINES:
+1. Identify ALL issues mentioned - even subtle ones
+2. Map issues to correct buckets based on IndiaMART's product knowledge
+3. Set a fact only when the call supports it; otherwise leave it false or empty
+4. Quotes are short (under 20 words), in English, with abusive words left out
+5. Evaluate agent performance against IndiaMART standards
+6. Amounts are plain rupee numbers: "50k" is 50000, "1.2 lakh" is 120000
+7. A billing dispute is only when the seller was charged or debited a different amount than they agreed to or were quoted, not a complaint that the price is high
+8. Commitments are explicit promises by the agent ("will call back by Friday", "will credit 10 BuyLeads"), not general reassurance; resolve relative due dates against the call date
+9. Every issue cites 1-3 pieces of evidence: words copied exactly from your transcript_en, with the 0-based line (turn) of transcript_en they're on
+10. An upsell pitch is the agent proposing the seller buy or upgrade to a paid product or plan (Star, Leader, TrustSEAL, more BuyLeads), not answering a question the seller asked about one; the response is how the seller took it by the end of the call
+
+IMPORTANT: Respond with ONLY valid JSON. No markdown, no code blocks, no explanations.`, knowledge)
+}
+
+func buildExtractionPrompt(transcript string, verticalSection string, bucketList string, callTime time.Time, audio *client.AcousticSignals) string {
+	audioSection := buildAcousticSection(audio)
+	if callTime.IsZero() {
+		callTime = time.Now()
+	}
+	callDate := callTime.In(config.BusinessTZ).Format("2006-01-02 (Monday)")
+
+	return fmt.Sprintf(`%sCALL DATE: %s
+
+EXTRACT FROM THIS CALL TRANSCRIPT:
+
+%s
+%s
+ISSUE CATEGORIES (use these exact names): %s
+
+RESPOND WITH THIS EXACT JSON STRUCTURE:
+{
+  "transcript_en": "English translation/cleaned version of transcript",
+  "call_summary": "2-3 sentence summary of what happened in the call",
+  "issues": [
+    {
+      "problem": "Specific issue description",
+      "bucket": "Category from list above",
+      "severity": "low|medium|high|critical",
+      "actionable_summary": "What IndiaMART should do to fix this",
+      "evidence": [{"quote": "words copied exactly from transcript_en", "turn": 0}]
+    }
+  ],
+  "sentiment": "Positive|Neutral|Negative",
+  "prompt_resolution": true/false,
+  "agent_performance": "Good|Average|Poor",
+  "facts": {
+    "cancellation_threatened": true/false,
+    "renewal_discussed": true/false,
+    "refund_requested": true/false,
+    "pricing_complaint": true/false,
+    "competitors_mentioned": ["competitor names"],
+    "requested_features": ["IndiaMART products or features the seller asked about"],
+    "budget_signals": "What the seller said about spending more or less, empty if nothing",
+    "growth_signals": "New products, cities, capacity or hiring mentioned, empty if nothing",
+    "escalation_requested": true/false,
+    "follow_up_promised": true/false,
+    "quotes": ["short quotes backing the facts above"],
+    "price_mentions": [
+      {
+        "amount": 50000,
+        "product": "Product or plan the amount is for, as said",
+        "kind": "paid|charged|quoted|refund|other",
+        "period": "month|year|multi_year|one_time, empty if not said",
+        "quote": "short quote with the amount"
+      }
+    ],
+    "billing_dispute": {"product": "Product or plan", "expected": 50000, "charged": 55000} or null,
+    "commitments": [
+      {
+        "promise": "What the agent committed to do",
+        "kind": "callback|credit|refund|fix|visit|other",
+        "due_date": "YYYY-MM-DD, empty if no time was given",
+        "quote": "short quote of the promise"
+      }
+    ],
+    "upsell_pitch": {
+      "products": ["Products or plans the agent pitched"],
+      "response": "accepted|interested|deferred|declined|no_response",
+      "quote": "short quote of the pitch"
+    } or null
+  },
+  "key_insights": ["insight1", "insight2"]
+}`, verticalSection, callDate, transcript, audioSection, bucketList)
+}
+
+// parseExtraction parses the extraction response, returning the JSON
+// repairs it needed
+func parseExtraction(response string, rt client.RawTranscript) (*client.CallExtraction, []string, error) {
+	var ext client.CallExtraction
+	repairs, err := decodeJSON(response, &ext)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse LLM response: %w", err)
+	}
+	ext.CallID, ext.SellerID, ext.Timestamp = rt.CallID, rt.SellerID, rt.Timestamp
+	ext.Acoustic = rt.Acoustic
+	ext.ExtractedAt = time.Now()
+	if ext.TranscriptEn == "" {
+		ext.TranscriptEn = rt.Transcript
+	}
+	normalizeExtraction(&ext)
+	return &ext, repairs, nil
+}
+
+// normalizeExtraction maps the model's spellings of sentiment and severity
+// onto the values storage accepts ("positive" -> "Positive", "High" ->
+// "high"). Unknown values become Neutral and medium. Price mentions are
+// checked against the plan catalog and a billing dispute becomes an issue;
+// commitments get a known kind and a valid due date or none, and an upsell
+// pitch a known response and its products' SKUs; issue evidence is placed in
+// the transcript.
+func normalizeExtraction(ext *client.CallExtraction) {
+	ext.Sentiment = normalizeSentiment(ext.Sentiment)
+	for i := range ext.Issues {
+		ext.Issues[i].Severity = normalizeSeverity(ext.Issues[i].Severity)
+	}
+	normalizePricing(ext)
+	normalizeCommitments(ext)
+	normalizeUpsellPitch(ext)
+	normalizeEvidence(ext)
+}
+
+// NormalizeAnalysis does the same for an analysis stored before
+// normalization, leaving the empty sentiment of blocked and unparsed calls
+func NormalizeAnalysis(a *client.AnalysisResult) {
+	if a.Intent.Sentiment != "" {
+		a.Intent.Sentiment = normalizeSentiment(a.Intent.Sentiment)
+	}
+	for i := range a.Issues {
+		a.Issues[i].Severity = normalizeSeverity(a.Issues[i].Severity)
+	}
+}
+
+func normalizeSentiment(sentiment string) string {
+	switch strings.ToLower(strings.TrimSpace(sentiment)) {
+	case "positive":
+		return "Positive"
+	case "negative":
+		return "Negative"
+	default:
+		return "Neutral"
+	}
+}
+
+func normalizeSeverity(severity string) string {
+	switch sev := strings.ToLower(strings.TrimSpace(severity)); sev {
+	case "low", "medium", "high", "critical":
+		return sev
+	default:
+		return "medium"
+	}
+}
+
+// ==================== SCORING PASS ====================
+
+// buildScoringSystemPrompt grounds the scoring pass in knowledge, the
+// IndiaMART context relevant to the call
+func buildScoringSystemPrompt(knowledge string) string {
+	return fmt.Sprintf(`You are an expert customer success analyst for IndiaMART, India's largest B2B marketplace.
+
+%s
+
+YOUR TASK: Score a seller's churn risk, upsell potential and satisfaction from the facts extracted from their latest support call, their profile and their account data. You don't see the transcript.
+
+SCORING GUIDELINES:
+1. Assess churn risk from cancellation threats, complaint severity, competitor mentions and the seller's history
+2. Identify upsell opportunities from requested features, budget and growth signals, and map them to IndiaMART's products
+3. If seller history is provided, weigh recurring patterns and unresolved issues
+4. Base every score on the facts given; don't assume statements that aren't there
+
+IMPORTANT: Respond with ONLY valid JSON. No markdown, no code blocks, no explanations.`, knowledge)
+}
+
+// scoringFacts is the part of an extraction the scoring pass sees
+func scoringFacts(ext *client.CallExtraction) (string, error) {
+	b, err := json.MarshalIndent(struct {
+		CallSummary      string           `json:"call_summary"`
+		Issues           []client.Issue   `json:"issues"`
+		Sentiment        string           `json:"sentiment"`
+		PromptResolution bool             `json:"prompt_resolution"`
+		AgentPerformance string           `json:"agent_performance,omitempty"`
+		Facts            client.CallFacts `json:"facts"`
+		KeyInsights      []string         `json:"key_insights,omitempty"`
+	}{ext.CallSummary, ext.Issues, ext.Sentiment, ext.PromptResolution, ext.AgentPerformance, ext.Facts, ext.KeyInsights}, "", "  ")
+	return string(b), err
+}
+
+// buildSellerDataSection lists the account data from the transcript
+// metadata, or returns "" without any
+func buildSellerDataSection(meta map[string]interface{}) string {
+	var b strings.Builder
+	add := func(label, key string) {
+		switch v := meta[key].(type) {
+		case nil:
+		case string:
+			if v != "" {
+				fmt.Fprintf(&b, "- %s: %s\n", label, v)
+			}
+		case int:
+			if v != 0 {
+				fmt.Fprintf(&b, "- %s: %d\n", label, v)
+			}
+		case float64:
+			if v != 0 {
+				fmt.Fprintf(&b, "- %s: %g\n", label, v)
+			}
+		}
+	}
+	add("Months on IndiaMART", "vintage_months")
+	add("Customer type", "customer_type")
+	add("City", "city_name")
+	add("Vertical", "iil_vertical_name")
+	add("BuyLead active days (October)", "bl_dau_oct")
+	add("Support ticket status", "customer_ticket_status")
+	add("Repeat ticket within 60 days", "is_ticket_repeat60d")
+	if cats, ok := meta["seller_categories"].([]client.SellerCategory); ok && len(cats) > 0 {
+		names := make([]string, 0, 5)
+		for i, c := range cats {
+			if i == 5 {
+				break
+			}
+			names = append(names, c.McatName)
+		}
+		fmt.Fprintf(&b, "- Top categories: %s\n", strings.Join(names, ", "))
+	}
+	if b.Len() == 0 {
+		return ""
+	}
+	return "\nSELLER ACCOUNT DATA:\n" + b.String()
+}
+
+// buildScoringPrompt asks for a follow-up draft in draftLanguage, or for none
+// when it's empty
+func buildScoringPrompt(facts string, sellerContext string, sellerData string, verticalSection string, audio *client.AcousticSignals, draftLanguage string) string {
+	contextSection := ""
+	if sellerContext != "" {
+		contextSection = fmt.Sprintf(`
+SELLER CONTEXT (Previous Interactions):
+%s
+
+Consider the seller's history when scoring. Look for:
+- Recurring issues that need systemic fixes
+- Worsening sentiment trends indicating high churn risk
+- Repeated escalations suggesting service failures
+`, sellerContext)
+	}
+
+	draftSection, draftField := "", ""
+	if draftLanguage != "" {
+		draftSection = buildFollowUpDraftSection(draftLanguage)
+		draftField = `,
+  "follow_up_draft": "The message to the seller"`
+	}
+
+	return fmt.Sprintf(`%s%s%s
+EXTRACTED FROM THE LATEST CALL:
+%s
+%s%s
+RESPOND WITH THIS EXACT JSON STRUCTURE:
+{
+  "satisfaction_score": 1-10,
+  "overall_experience": "Good|Average|Poor",
+  "churn": {
+    "is_likely_to_churn": "low|medium|high",
+    "renewal_at_risk": true/false,
+    "dissatisfaction_level": "low|medium|high",
+    "churn_reason": "Why they might leave",
+    "churn_reason_category": "%s (the closest fit for churn_reason, empty if there is none)",
+    "renewal_probability": 0.0-1.0
+  },
+  "upsell": {
+    "has_opportunity": true/false,
+    "score": 1-10,
+    "willingness_to_invest": "low|medium|high",
+    "is_growth_oriented": true/false,
+    "interested_features": ["feature1", "feature2"],
+    "upsell_reason": "Why this opportunity exists"
+  },
+  "follow_up_needed": true/false,
+  "escalation_required": true/false%s
+}`, contextSection, sellerData, verticalSection, facts, buildAcousticSection(audio), draftSection, strings.Join(config.ChurnReasonCategories, "|"), draftField)
+}
+
+// analysisFromExtraction fills in the extracted half of an analysis
+func analysisFromExtraction(rt client.RawTranscript, ext *client.CallExtraction) *client.AnalysisResult {
+	normalizeExtraction(ext) // Extractions stored before normalization
+	analysis := &client.AnalysisResult{
+		CallID: rt.CallID, SellerID: rt.SellerID, Timestamp: rt.Timestamp, Channel: rt.Channel,
+		TranscriptEn: ext.TranscriptEn, OriginalLang: rt.Language,
+		Issues:           ext.Issues,
+		Intent:           client.SellerIntent{Sentiment: ext.Sentiment, PromptResolution: ext.PromptResolution},
+		CallSummary:      ext.CallSummary,
+		AgentPerformance: ext.AgentPerformance,
+		PriceMentions:    ext.Facts.PriceMentions,
+		UpsellPitch:      ext.Facts.UpsellPitch,
+		LLMRaw:           map[string]interface{}{"parsed": true, "key_insights": ext.KeyInsights},
+		Acoustic:         ext.Acoustic,
+		SafetyBlock:      ext.SafetyBlock,
+		PromptBudget:     ext.PromptBudget,
+		TranslationCheck: ext.TranslationCheck,
+		AnalyzedAt:       time.Now(),
+	}
+	if ext.VerticalPrompt != "" {
+		analysis.LLMRaw["vertical_prompt"] = ext.VerticalPrompt
+	}
+	return analysis
+}
+
+// applyScores parses the scoring response into the analysis, returning the
+// JSON repairs it needed
+func applyScores(analysis *client.AnalysisResult, response string, draftLanguage string) ([]string, error) {
+	var parsed struct {
+		SatisfactionScore  int                    `json:"satisfaction_score"`
+		OverallExperience  string                 `json:"overall_experience"`
+		Churn              client.ChurnPrediction `json:"churn"`
+		Upsell             client.UpsellScore     `json:"upsell"`
+		FollowUpNeeded     bool                   `json:"follow_up_needed"`
+		EscalationRequired bool                   `json:"escalation_required"`
+		FollowUpDraft      string                 `json:"follow_up_draft"`
+	}
+	repairs, err := decodeJSON(response, &parsed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse LLM response: %w", err)
+	}
+	parsed.Churn.ChurnReasonCategory = config.ClassifyChurnReason(parsed.Churn.ChurnReasonCategory, parsed.Churn.ChurnReason)
+	parsed.Upsell.SKUs = config.MatchProducts(parsed.Upsell.InterestedFeatures)
+
+	analysis.Intent.SatisfactionScore = parsed.SatisfactionScore
+	analysis.Intent.OverallExperience = parsed.OverallExperience
+	analysis.Churn = parsed.Churn
+	analysis.Upsell = parsed.Upsell
+	analysis.LLMRaw["follow_up_needed"] = parsed.FollowUpNeeded
+	analysis.LLMRaw["escalation_required"] = parsed.EscalationRequired
+	if draft := strings.TrimSpace(parsed.FollowUpDraft); draft != "" {
+		analysis.FollowUpDraft = &client.FollowUpDraft{Language: draftLanguage, Message: draft}
+	}
+	return repairs, nil
+}
@@ -0,0 +1,46 @@
+package llm
+
+import (
+	"strings"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+)
+
+// ==================== UPSELL PITCHES ====================
+// The extraction pass reports whether the agent pitched an upsell, what, and
+// how the seller responded. Pitches are tracked against the seller's later
+// customer type to see which of them convert.
+
+var pitchResponses = map[string]bool{
+	client.PitchAccepted:   true,
+	client.PitchInterested: true,
+	client.PitchDeferred:   true,
+	client.PitchDeclined:   true,
+	client.PitchNoResponse: true,
+}
+
+// normalizeUpsellPitch drops a pitch naming no product, maps its products
+// onto catalog SKUs and its response onto a known one (no_response if not)
+func normalizeUpsellPitch(ext *client.CallExtraction) {
+	p := ext.Facts.UpsellPitch
+	if p == nil {
+		return
+	}
+	products := p.Products[:0]
+	for _, product := range p.Products {
+		if product = strings.TrimSpace(product); product != "" {
+			products = append(products, product)
+		}
+	}
+	if len(products) == 0 {
+		ext.Facts.UpsellPitch = nil
+		return
+	}
+	p.Products = products
+	p.SKUs = config.MatchProducts(products)
+	if p.Response = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(p.Response)), " ", "_"); !pitchResponses[p.Response] {
+		p.Response = client.PitchNoResponse
+	}
+	p.Quote = strings.TrimSpace(p.Quote)
+}
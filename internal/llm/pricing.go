@@ -0,0 +1,104 @@
+package llm
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+)
+
+// ==================== PRICE MENTIONS ====================
+// The extraction pass lists the amounts said on a call and, when the seller
+// was charged something other than agreed, the dispute. Mentions are mapped
+// to catalog SKUs and annual ones checked against the list price; a dispute
+// becomes a Billing & Renewal issue of type billing_dispute at high severity
+// or above. It's all idempotent, so stored extractions can be normalized again.
+
+const disputeBucket = "Billing & Renewal"
+
+var priceKinds = map[string]bool{"paid": true, "charged": true, "quoted": true, "refund": true, "other": true}
+
+var pricePeriods = map[string]string{
+	"month": "month", "monthly": "month",
+	"year": "year", "yearly": "year", "annual": "year", "annually": "year",
+	"multi_year": "multi_year", "multi-year": "multi_year", "multiyear": "multi_year",
+	"one_time": "one_time", "one-time": "one_time", "once": "one_time",
+}
+
+func normalizePricing(ext *client.CallExtraction) {
+	facts := &ext.Facts
+	mentions := facts.PriceMentions[:0]
+	for _, m := range facts.PriceMentions {
+		if m.Amount <= 0 {
+			continue
+		}
+		if m.Kind = strings.ToLower(strings.TrimSpace(m.Kind)); !priceKinds[m.Kind] {
+			m.Kind = "other"
+		}
+		m.Period = pricePeriods[strings.ToLower(strings.TrimSpace(m.Period))]
+		m.SKU = config.MatchProduct(m.Product)
+		m.CatalogPrice, m.OffCatalog = 0, false
+		if p, ok := config.ProductBySKU(m.SKU); ok && p.AnnualPrice > 0 && (m.Period == "year" || m.Period == "") {
+			m.CatalogPrice = p.AnnualPrice
+			m.OffCatalog = offCatalog(m.Amount, p.AnnualPrice)
+		}
+		mentions = append(mentions, m)
+	}
+	facts.PriceMentions = mentions
+
+	d := facts.BillingDispute
+	if d != nil && (d.Expected <= 0 || d.Charged <= 0 || d.Expected == d.Charged) {
+		facts.BillingDispute, d = nil, nil // Not a difference in amounts
+	}
+	if d == nil {
+		return
+	}
+	d.SKU = config.MatchProduct(d.Product)
+	d.CatalogPrice = 0
+	if p, ok := config.ProductBySKU(d.SKU); ok {
+		d.CatalogPrice = p.AnnualPrice
+	}
+	flagDispute(ext, d)
+}
+
+// offCatalog reports whether an annual amount is further from the list price
+// than CatalogPriceTolerance
+func offCatalog(amount float64, listPrice int) bool {
+	return math.Abs(amount-float64(listPrice)) > float64(listPrice)*config.CatalogPriceTolerance
+}
+
+// flagDispute marks the call's billing issue as the dispute, adding one when
+// the model didn't list it
+func flagDispute(ext *client.CallExtraction, d *client.BillingDispute) {
+	i := -1
+	for j, issue := range ext.Issues {
+		if issue.Type == client.IssueTypeBillingDispute {
+			i = j
+			break
+		}
+		if i < 0 && issue.Bucket == disputeBucket {
+			i = j
+		}
+	}
+	if i < 0 {
+		product := d.Product
+		if product == "" {
+			product = "subscription"
+		}
+		ext.Issues = append(ext.Issues, client.Issue{
+			Problem:           fmt.Sprintf("Charged Rs %.0f for %s, expected Rs %.0f", d.Charged, product, d.Expected),
+			Bucket:            disputeBucket,
+			ActionableSummary: "Check the invoice against the agreed amount and refund or correct the difference",
+		})
+		i = len(ext.Issues) - 1
+	}
+	issue := &ext.Issues[i]
+	issue.Type = client.IssueTypeBillingDispute
+	dispute := *d
+	issue.Dispute = &dispute
+	if issue.Severity != "critical" {
+		issue.Severity = "high"
+	}
+}
@@ -0,0 +1,238 @@
+package llm
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+)
+
+// ==================== PROMPT REGISTRY ====================
+// Prompt blocks kept out of the code, in the JSON file at PROMPT_REGISTRY
+// (prompts/registry.json by default). Vertical blocks add a vertical's issue
+// vocabulary to the analysis prompt; a transcript gets the first block with
+// a match term in its iil_vertical_name (case-insensitive substring).
+// A block can also add the vertical's own issue buckets, each rolling up to
+// one of the global FeatureBuckets: the extraction offers them alongside
+// the global ones, and an issue filed under one keeps it as vertical_bucket
+// with its parent as bucket, so everything keyed by bucket sees the global set.
+//
+//	{"verticals": [{"name": "logistics", "match": ["logistics", "courier"], "prompt": "...",
+//	  "buckets": [{"name": "Shipment Tracking", "parent": "Buyer Interaction", "description": "..."}]}]}
+
+// PromptRegistry holds the configurable prompt blocks
+type PromptRegistry struct {
+	Verticals []VerticalPrompt `json:"verticals"`
+}
+
+// VerticalPrompt augments the analysis prompt for sellers in a vertical
+type VerticalPrompt struct {
+	Name    string           `json:"name"`
+	Match   []string         `json:"match"`
+	Prompt  string           `json:"prompt"`
+	Buckets []VerticalBucket `json:"buckets,omitempty"`
+}
+
+// VerticalBucket is an issue bucket specific to a vertical
+type VerticalBucket struct {
+	Name        string `json:"name"`
+	Parent      string `json:"parent"`                // Global bucket it rolls up to
+	Description string `json:"description,omitempty"` // What belongs in it, for the prompt
+}
+
+// LoadPromptRegistry reads the registry at path. A missing file is an empty
+// registry.
+func LoadPromptRegistry(path string) (*PromptRegistry, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &PromptRegistry{}, nil
+		}
+		return nil, err
+	}
+
+	var reg PromptRegistry
+	if err := json.Unmarshal(b, &reg); err != nil {
+		return nil, fmt.Errorf("invalid prompt registry %s: %w", path, err)
+	}
+	for i, v := range reg.Verticals {
+		if v.Name == "" || len(v.Match) == 0 || strings.TrimSpace(v.Prompt) == "" {
+			return nil, fmt.Errorf("invalid prompt registry %s: vertical %d needs a name, match terms and a prompt", path, i)
+		}
+		seen := make(map[string]bool, len(v.Buckets))
+		for _, b := range v.Buckets {
+			switch key := strings.ToLower(strings.TrimSpace(b.Name)); {
+			case key == "":
+				return nil, fmt.Errorf("invalid prompt registry %s: vertical %s has a bucket without a name", path, v.Name)
+			case globalBucket(b.Name) != "":
+				return nil, fmt.Errorf("invalid prompt registry %s: vertical %s bucket %q is a global bucket", path, v.Name, b.Name)
+			case seen[key]:
+				return nil, fmt.Errorf("invalid prompt registry %s: vertical %s has bucket %q twice", path, v.Name, b.Name)
+			case !slices.Contains(config.FeatureBuckets, b.Parent):
+				return nil, fmt.Errorf("invalid prompt registry %s: vertical %s bucket %q needs a global bucket as parent, not %q", path, v.Name, b.Name, b.Parent)
+			default:
+				seen[key] = true
+			}
+		}
+	}
+	return &reg, nil
+}
+
+// BucketTaxonomyVersion fingerprints the buckets issues can be filed under:
+// the global FeatureBuckets and the verticals' own buckets in the client's
+// prompt registry
+func (a *AIClient) BucketTaxonomyVersion() string {
+	h := sha256.New()
+	for _, b := range config.FeatureBuckets {
+		fmt.Fprintf(h, "%s\n", b)
+	}
+	if a.prompts != nil {
+		for _, v := range a.prompts.Verticals {
+			for _, b := range v.Buckets {
+				fmt.Fprintf(h, "%s/%s>%s\n", v.Name, b.Name, b.Parent)
+			}
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))[:12]
+}
+
+// globalBucket returns the FeatureBuckets name matching b ignoring case, "" for none
+func globalBucket(b string) string {
+	b = strings.TrimSpace(b)
+	for _, fb := range config.FeatureBuckets {
+		if strings.EqualFold(fb, b) {
+			return fb
+		}
+	}
+	return ""
+}
+
+// issueCategories lists the buckets the extraction may file issues under:
+// the vertical's own first, then the global ones
+func issueCategories(vp *VerticalPrompt) string {
+	global := strings.Join(config.FeatureBuckets, ", ")
+	if vp == nil || len(vp.Buckets) == 0 {
+		return global
+	}
+	var b strings.Builder
+	b.WriteString("\n")
+	for _, vb := range vp.Buckets {
+		if vb.Description != "" {
+			fmt.Fprintf(&b, "- %s: %s\n", vb.Name, vb.Description)
+		} else {
+			fmt.Fprintf(&b, "- %s\n", vb.Name)
+		}
+	}
+	fmt.Fprintf(&b, "Use the categories above when an issue fits one; otherwise one of: %s", global)
+	return b.String()
+}
+
+// rollUpBuckets files issues raised under a vertical bucket under its global
+// parent, keeping the vertical bucket alongside
+func rollUpBuckets(issues []client.Issue, vp *VerticalPrompt) {
+	if vp == nil {
+		return
+	}
+	for i := range issues {
+		for _, vb := range vp.Buckets {
+			if strings.EqualFold(strings.TrimSpace(issues[i].Bucket), strings.TrimSpace(vb.Name)) {
+				issues[i].VerticalBucket = vb.Name
+				issues[i].Bucket = vb.Parent
+				break
+			}
+		}
+	}
+}
+
+// PromptRegistryFromEnv loads PROMPT_REGISTRY as a new client would, for
+// tools parsing recorded responses without one
+func PromptRegistryFromEnv() *PromptRegistry {
+	return loadPromptRegistry()
+}
+
+// loadPromptRegistry loads PROMPT_REGISTRY for a new client, without
+// vertical blocks if it's invalid
+func loadPromptRegistry() *PromptRegistry {
+	path := os.Getenv("PROMPT_REGISTRY")
+	if path == "" {
+		path = config.DEFAULT_PROMPT_REGISTRY
+	}
+	reg, err := LoadPromptRegistry(path)
+	if err != nil {
+		log.Printf("⚠️ %v - analyzing without vertical prompts", err)
+		return &PromptRegistry{}
+	}
+	if len(reg.Verticals) > 0 {
+		log.Printf("📝 Prompt registry: %d vertical prompts from %s", len(reg.Verticals), path)
+	}
+	return reg
+}
+
+// ForVertical returns the block for a vertical name, nil if none matches
+func (r *PromptRegistry) ForVertical(vertical string) *VerticalPrompt {
+	vertical = strings.ToLower(vertical)
+	if r == nil || vertical == "" {
+		return nil
+	}
+	for i, v := range r.Verticals {
+		for _, m := range v.Match {
+			if m = strings.ToLower(strings.TrimSpace(m)); m != "" && strings.Contains(vertical, m) {
+				return &r.Verticals[i]
+			}
+		}
+	}
+	return nil
+}
+
+// byName returns the block named name, nil if there's none
+func (r *PromptRegistry) byName(name string) *VerticalPrompt {
+	if r == nil || name == "" {
+		return nil
+	}
+	for i, v := range r.Verticals {
+		if v.Name == name {
+			return &r.Verticals[i]
+		}
+	}
+	return nil
+}
+
+// transcriptVertical returns the seller's vertical from the transcript metadata
+func transcriptVertical(rt client.RawTranscript) string {
+	v, _ := rt.Metadata["iil_vertical_name"].(string)
+	return v
+}
+
+// buildChannelSection tells the model a transcript is a written chat rather
+// than a call, or returns "" for voice. It's kept out of the vertical block so
+// the prompt budget never drops it.
+func buildChannelSection(channel string) string {
+	if !client.IsChatChannel(channel) {
+		return ""
+	}
+	return fmt.Sprintf(`
+CONTACT CHANNEL: %s
+This is a written support chat, not a phone call. Each turn is a typed message, so:
+- There is no tone of voice, pauses or interruptions - judge sentiment from the words alone
+- Short replies, emoji and typos are normal in chat and are not a sign of frustration on their own
+- Long gaps between messages are not dropped calls; don't report call-quality issues
+- Attachments or links the seller mentions count as evidence they shared
+
+`, channel)
+}
+
+// buildVerticalSection renders a vertical block for the analysis prompt, or
+// returns "" without one
+func buildVerticalSection(vertical string, vp *VerticalPrompt) string {
+	if vp == nil {
+		return ""
+	}
+	return fmt.Sprintf("\nSELLER VERTICAL: %s\n%s\n\n", vertical, strings.TrimSpace(vp.Prompt))
+}
@@ -0,0 +1,62 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"im-ai-voice/client"
+)
+
+// ClassifySplit picks the bucket each problem belongs to among the targets
+// a retired bucket is split into. A problem Gemini can't place, or places
+// outside the targets, gets "", for the split's default.
+func (a *AIClient) ClassifySplit(ctx context.Context, from string, targets []client.SplitTarget, problems []string) ([]string, error) {
+	systemPrompt := `You reassign IndiaMART seller support issues after a feature bucket was split into narrower buckets.
+Each issue was filed under the retired bucket. Put each one in the new bucket it belongs to, using only the bucket names given.
+If an issue fits none of them, use an empty string.
+Respond with JSON only, one entry per issue number:
+{"buckets": [{"n": 1, "bucket": "..."}]}`
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "RETIRED BUCKET: %s\n\nNEW BUCKETS:\n", from)
+	for _, t := range targets {
+		if t.Description != "" {
+			fmt.Fprintf(&sb, "- %s: %s\n", t.Bucket, t.Description)
+		} else {
+			fmt.Fprintf(&sb, "- %s\n", t.Bucket)
+		}
+	}
+	sb.WriteString("\nISSUES:\n")
+	for i, p := range problems {
+		fmt.Fprintf(&sb, "%d. %s\n", i+1, strings.Join(strings.Fields(p), " "))
+	}
+
+	response, err := a.sendRequest(ctx, TaskReclassify, systemPrompt, sb.String())
+	if err != nil {
+		return nil, fmt.Errorf("LLM request failed: %w", err)
+	}
+
+	var parsed struct {
+		Buckets []struct {
+			N      int    `json:"n"`
+			Bucket string `json:"bucket"`
+		} `json:"buckets"`
+	}
+	if err := json.Unmarshal([]byte(sanitizeJSONString(extractJSON(response))), &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse LLM response: %w", err)
+	}
+
+	known := make(map[string]string, len(targets))
+	for _, t := range targets {
+		known[strings.ToLower(t.Bucket)] = t.Bucket
+	}
+	buckets := make([]string, len(problems))
+	for _, b := range parsed.Buckets {
+		if b.N >= 1 && b.N <= len(problems) {
+			buckets[b.N-1] = known[strings.ToLower(strings.TrimSpace(b.Bucket))]
+		}
+	}
+	return buckets, nil
+}
@@ -0,0 +1,141 @@
+package llm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/vault"
+)
+
+// ==================== INTERACTION RECORDER ====================
+// With LLM_RECORD=true each analysis pass's request to Gemini is kept with
+// its response, keyed by call, prompt version and pass, so a parsing bug can
+// be fixed and checked against real responses without calling Gemini again
+// (imvoicectl replay-llm). The prompt version fingerprints the passes'
+// prompt templates and the prompt registry, so recordings made with other
+// prompts aren't mixed up. A response completed by a continuation request
+// is recorded joined. Phone numbers and email addresses are masked in
+// prompts and responses; with the PII vault on, transcripts arrive
+// tokenized already. Recordings expire with raw responses.
+
+// InteractionRecorder stores a recorded interaction
+type InteractionRecorder func(ctx context.Context, in *client.LLMInteraction) error
+
+// RecordingFromEnv reports whether LLM_RECORD asks for analysis requests to be recorded
+func RecordingFromEnv() bool {
+	v := os.Getenv("LLM_RECORD")
+	if v == "" {
+		return false
+	}
+	on, err := strconv.ParseBool(v)
+	if err != nil {
+		log.Printf("⚠️ Invalid LLM_RECORD=%q, not recording", v)
+		return false
+	}
+	return on
+}
+
+// SetRecorder records every analysis pass of the client through r.
+// Candidate clients made from it don't record.
+func (a *AIClient) SetRecorder(r InteractionRecorder) {
+	a.recorder = r
+}
+
+// PromptVersion fingerprints the analysis prompt templates and the client's
+// prompt registry
+func (a *AIClient) PromptVersion() string {
+	h := sha256.New()
+	h.Write([]byte(buildExtractionSystemPrompt("")))
+	h.Write([]byte(buildExtractionPrompt("", "", "", time.Time{}, nil)))
+	h.Write([]byte(buildScoringSystemPrompt("")))
+	h.Write([]byte(buildScoringPrompt("", "", "", "", nil, "")))
+	if a.prompts != nil {
+		reg, _ := json.Marshal(a.prompts)
+		h.Write(reg)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:12]
+}
+
+// record keeps a pass's request and response when recording is on.
+// Failing to store it is only logged.
+func (a *AIClient) record(ctx context.Context, pass Task, rt client.RawTranscript, vertical *VerticalPrompt, draftLang, systemPrompt, prompt, response string, err error) {
+	if a.recorder == nil {
+		return
+	}
+	in := &client.LLMInteraction{
+		CallID:        rt.CallID,
+		PromptVersion: a.PromptVersion(),
+		Pass:          string(pass),
+		Model:         a.model,
+		SellerID:      rt.SellerID,
+		CallTime:      rt.Timestamp,
+		Language:      rt.Language,
+		DraftLanguage: draftLang,
+		SystemPrompt:  redactInteraction(systemPrompt),
+		Prompt:        redactInteraction(prompt),
+		Response:      redactInteraction(response),
+		RecordedAt:    time.Now(),
+	}
+	if vertical != nil {
+		in.VerticalPrompt = vertical.Name
+	}
+	if err != nil {
+		in.Error = err.Error()
+	}
+	if err := a.recorder(ctx, in); err != nil {
+		log.Printf("   ⚠️ Failed to record %s request for call %s: %v", pass, rt.CallID, err)
+	}
+}
+
+// redactInteraction masks phone numbers and email addresses
+func redactInteraction(text string) string {
+	text = vault.EmailPattern.ReplaceAllString(text, "[email]")
+	return vault.PhonePattern.ReplaceAllString(text, "[phone]")
+}
+
+// ReplayInteractions parses a call's recorded extraction and scoring
+// responses with the current parsing code, as AnalyzeCall would have,
+// without calling Gemini. reg supplies the vertical buckets issues roll up
+// from; scoring may be nil when only the extraction was recorded.
+func ReplayInteractions(reg *PromptRegistry, extraction, scoring *client.LLMInteraction) (*client.AnalysisResult, error) {
+	if extraction == nil {
+		return nil, fmt.Errorf("no recorded extraction")
+	}
+	if extraction.Error != "" {
+		return nil, fmt.Errorf("extraction request failed when recorded: %s", extraction.Error)
+	}
+	rt := client.RawTranscript{
+		CallID:    extraction.CallID,
+		SellerID:  extraction.SellerID,
+		Timestamp: extraction.CallTime,
+		Language:  extraction.Language,
+	}
+	ext, _, err := parseExtraction(extraction.Response, rt)
+	if err != nil {
+		return nil, fmt.Errorf("extraction: %w", err)
+	}
+	if vp := reg.byName(extraction.VerticalPrompt); vp != nil {
+		ext.VerticalPrompt = vp.Name
+		rollUpBuckets(ext.Issues, vp)
+	}
+
+	analysis := analysisFromExtraction(rt, ext)
+	if scoring == nil {
+		return analysis, nil
+	}
+	if scoring.Error != "" {
+		return analysis, fmt.Errorf("scoring request failed when recorded: %s", scoring.Error)
+	}
+	if _, err := applyScores(analysis, scoring.Response, scoring.DraftLanguage); err != nil {
+		return analysis, fmt.Errorf("scoring: %w", err)
+	}
+	return analysis, nil
+}
@@ -0,0 +1,243 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"im-ai-voice/client"
+)
+
+// ==================== JSON REPAIR ====================
+// Gemini's JSON is now and then malformed in ways that can be fixed without
+// asking again: a trailing comma before a closing bracket, or a quote inside
+// a string left unescaped ("seller said "no" twice"). Those are repaired
+// before an analysis response is given up on. A response cut off mid-JSON
+// (output token limit, dropped connection) gets one continuation request
+// asking the model to carry on from where it stopped. Parse outcomes are
+// counted per model since startup (GET /admin/pipeline/stats).
+
+// Repairs made to a response before it parsed
+const (
+	repairTrailingComma  = "trailing_comma"
+	repairUnescapedQuote = "unescaped_quote"
+	repairContinued      = "continued"
+)
+
+// decodeJSON decodes the JSON object in an LLM response into v, repairing
+// trailing commas and unescaped quotes if it's malformed. It returns the
+// repairs that were needed; v is left untouched when it fails.
+func decodeJSON(response string, v any) ([]string, error) {
+	jsonStr := sanitizeJSONString(extractJSON(response))
+	err := json.Unmarshal([]byte(jsonStr), v)
+	var syntax *json.SyntaxError
+	if !errors.As(err, &syntax) {
+		return nil, err
+	}
+	repaired, repairs := repairJSON(jsonStr)
+	if len(repairs) == 0 || json.Unmarshal([]byte(repaired), v) != nil {
+		return nil, err // The original error says more about the response
+	}
+	return repairs, nil
+}
+
+// repairJSON drops trailing commas and escapes quotes that can't end the
+// string they're in, returning the repairs made
+func repairJSON(s string) (string, []string) {
+	var b strings.Builder
+	b.Grow(len(s) + 16)
+	var commas, quotes bool
+	inString, escaped := false, false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case escaped:
+			escaped = false
+		case inString && c == '\\':
+			escaped = true
+		case inString && c == '"':
+			if !closesString(s[i+1:]) {
+				b.WriteString(`\"`)
+				quotes = true
+				continue
+			}
+			inString = false
+		case c == '"':
+			inString = true
+		case inString:
+		case c == ',':
+			if next := nextNonSpace(s[i+1:]); next == '}' || next == ']' {
+				commas = true
+				continue
+			}
+		}
+		b.WriteByte(c)
+	}
+
+	var repairs []string
+	if commas {
+		repairs = append(repairs, repairTrailingComma)
+	}
+	if quotes {
+		repairs = append(repairs, repairUnescapedQuote)
+	}
+	return b.String(), repairs
+}
+
+// closesString reports whether a quote followed by rest ends a string: it's
+// followed by a colon, a closing bracket, the end of the input, or a comma
+// and the next key or value or a closing bracket
+func closesString(rest string) bool {
+	switch nextNonSpace(rest) {
+	case 0, ':', '}', ']':
+		return true
+	case ',':
+		after := strings.TrimLeft(rest, " \t\r\n")[1:]
+		switch nextNonSpace(after) {
+		case 0, '"', '{', '[', '}', ']': // A trailing comma after the string too
+			return true
+		}
+	}
+	return false
+}
+
+// nextNonSpace is the first byte of s that isn't whitespace, 0 if none
+func nextNonSpace(s string) byte {
+	if s = strings.TrimLeft(s, " \t\r\n"); s == "" {
+		return 0
+	}
+	return s[0]
+}
+
+// truncatedJSON reports whether a response stops inside its JSON object
+func truncatedJSON(response string) bool {
+	start := strings.Index(response, "{")
+	if start < 0 {
+		return false
+	}
+	depth := 0
+	inString, escaped := false, false
+	for i := start; i < len(response); i++ {
+		c := response[i]
+		switch {
+		case escaped:
+			escaped = false
+		case inString && c == '\\':
+			escaped = true
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '{' || c == '[':
+			depth++
+		case c == '}' || c == ']':
+			if depth--; depth == 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// continuationPrompt asks for the rest of a cut-off response
+func continuationPrompt(partial string) string {
+	return `
+
+YOUR PREVIOUS RESPONSE WAS CUT OFF before the JSON was complete. Continue it from exactly the next character: return only the rest of the JSON, without repeating anything already written and without markdown fences.
+
+RESPONSE SO FAR:
+` + partial
+}
+
+// parseResponse parses an analysis pass's response with parse. A response
+// cut off mid-JSON that doesn't parse is completed with one continuation
+// request first. It returns the response parsed, continuation included,
+// and counts the outcome against the client's model.
+func (a *AIClient) parseResponse(ctx context.Context, task Task, callID, systemPrompt, prompt, response string, parse func(response string) ([]string, error)) (string, error) {
+	repairs, err := parse(response)
+	if err != nil && truncatedJSON(response) {
+		joined, cerr := a.continueResponse(ctx, task, systemPrompt, prompt, response)
+		if cerr != nil {
+			log.Printf("⚠️ Call %s: continuing the cut-off %s response failed: %v", callID, task, cerr)
+		} else if repairs, cerr = parse(joined); cerr == nil {
+			response, err = joined, nil
+			repairs = append(repairs, repairContinued)
+		}
+	}
+	if len(repairs) > 0 {
+		log.Printf("   🔧 Call %s: repaired %s response (%s)", callID, task, strings.Join(repairs, ", "))
+	}
+	parseStats.record(a.model, repairs, err)
+	return response, err
+}
+
+// continueResponse asks the model to finish a response cut off mid-JSON,
+// returning the joined response. A continuation that's a complete object
+// of its own, the model having started over, replaces the partial one.
+func (a *AIClient) continueResponse(ctx context.Context, task Task, systemPrompt, prompt, partial string) (string, error) {
+	rest, err := a.sendRequest(ctx, task, systemPrompt, prompt+continuationPrompt(partial))
+	if err != nil {
+		return "", err
+	}
+	rest = strings.TrimPrefix(strings.TrimLeft(rest, "\r\n"), "```json")
+	rest = strings.TrimSuffix(strings.TrimRight(strings.TrimPrefix(rest, "```"), " \t\r\n"), "```")
+	if trimmed := strings.TrimSpace(rest); strings.HasPrefix(trimmed, "{") && !truncatedJSON(trimmed) && json.Valid([]byte(extractJSON(trimmed))) {
+		return trimmed, nil
+	}
+	return partial + rest, nil
+}
+
+// ==================== PARSE STATS ====================
+
+var parseStats = &parseCounters{byModel: make(map[string]*client.LLMParseStats)}
+
+type parseCounters struct {
+	mu      sync.Mutex
+	byModel map[string]*client.LLMParseStats
+}
+
+func (p *parseCounters) record(model string, repairs []string, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s := p.byModel[model]
+	if s == nil {
+		s = &client.LLMParseStats{Model: model, Repairs: make(map[string]int)}
+		p.byModel[model] = s
+	}
+	s.Responses++
+	switch {
+	case err != nil:
+		s.Failed++
+	case len(repairs) > 0:
+		s.Repaired++
+		for _, r := range repairs {
+			s.Repairs[r]++
+		}
+	}
+}
+
+// ParseStats returns how the analysis responses of each model parsed since
+// startup, by model
+func ParseStats() []client.LLMParseStats {
+	parseStats.mu.Lock()
+	defer parseStats.mu.Unlock()
+	out := make([]client.LLMParseStats, 0, len(parseStats.byModel))
+	for _, s := range parseStats.byModel {
+		st := *s
+		st.Repairs = make(map[string]int, len(s.Repairs))
+		for r, n := range s.Repairs {
+			st.Repairs[r] = n
+		}
+		if st.Responses > 0 {
+			st.RepairRate = math.Round(float64(st.Repaired)/float64(st.Responses)*1000) / 1000
+			st.FailureRate = math.Round(float64(st.Failed)/float64(st.Responses)*1000) / 1000
+		}
+		out = append(out, st)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Model < out[j].Model })
+	return out
+}
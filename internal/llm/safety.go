@@ -0,0 +1,152 @@
+package llm
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// ==================== SAFETY FILTERS ====================
+// Calls with abusive language can trip Gemini's safety filters, which then
+// return no text. GEMINI_SAFETY_SETTINGS sets the block threshold per harm
+// category, e.g. "harassment=BLOCK_ONLY_HIGH,hate_speech=BLOCK_NONE";
+// categories left out keep Gemini's default. With
+// GEMINI_SAFETY_REDACT_RETRY=true a blocked transcript is analyzed once more
+// with abusive terms masked.
+
+// safetyCategories maps the short names accepted in GEMINI_SAFETY_SETTINGS to Gemini's harm categories
+var safetyCategories = map[string]string{
+	"harassment":        "HARM_CATEGORY_HARASSMENT",
+	"hate_speech":       "HARM_CATEGORY_HATE_SPEECH",
+	"sexually_explicit": "HARM_CATEGORY_SEXUALLY_EXPLICIT",
+	"dangerous_content": "HARM_CATEGORY_DANGEROUS_CONTENT",
+	"civic_integrity":   "HARM_CATEGORY_CIVIC_INTEGRITY",
+}
+
+var safetyThresholds = map[string]bool{
+	"BLOCK_NONE":             true,
+	"BLOCK_ONLY_HIGH":        true,
+	"BLOCK_MEDIUM_AND_ABOVE": true,
+	"BLOCK_LOW_AND_ABOVE":    true,
+	"OFF":                    true,
+}
+
+// blockedFinishReasons are the finish and block reasons that mean a safety
+// filter withheld the response
+var blockedFinishReasons = map[string]bool{
+	"SAFETY":             true,
+	"BLOCKLIST":          true,
+	"PROHIBITED_CONTENT": true,
+	"SPII":               true,
+	"OTHER":              true, // Only as a promptFeedback blockReason
+}
+
+type geminiSafetySetting struct {
+	Category  string `json:"category"`
+	Threshold string `json:"threshold"`
+}
+
+type geminiSafetyRating struct {
+	Category    string `json:"category"`
+	Probability string `json:"probability"`
+	Blocked     bool   `json:"blocked,omitempty"`
+}
+
+type geminiPromptFeedback struct {
+	BlockReason   string               `json:"blockReason,omitempty"`
+	SafetyRatings []geminiSafetyRating `json:"safetyRatings,omitempty"`
+}
+
+// BlockedError is returned when Gemini's safety filters withheld the response
+type BlockedError struct {
+	Reason     string   // SAFETY, BLOCKLIST, PROHIBITED_CONTENT, SPII or OTHER
+	Categories []string // Harm categories that tripped, if Gemini said
+}
+
+func (e *BlockedError) Error() string {
+	if len(e.Categories) == 0 {
+		return fmt.Sprintf("Gemini blocked the response (%s)", e.Reason)
+	}
+	return fmt.Sprintf("Gemini blocked the response (%s: %s)", e.Reason, strings.Join(e.Categories, ", "))
+}
+
+// newBlockedError collects the categories behind a block. Ratings flagged as
+// blocked are the cause; without any, HIGH and MEDIUM probabilities are.
+func newBlockedError(reason string, ratings []geminiSafetyRating) *BlockedError {
+	e := &BlockedError{Reason: reason}
+	for _, r := range ratings {
+		if r.Blocked {
+			e.Categories = append(e.Categories, r.Category)
+		}
+	}
+	if len(e.Categories) == 0 {
+		for _, r := range ratings {
+			if r.Probability == "HIGH" || r.Probability == "MEDIUM" {
+				e.Categories = append(e.Categories, r.Category)
+			}
+		}
+	}
+	return e
+}
+
+// loadSafetySettings parses GEMINI_SAFETY_SETTINGS, skipping malformed entries
+func loadSafetySettings() []geminiSafetySetting {
+	v := os.Getenv("GEMINI_SAFETY_SETTINGS")
+	if v == "" {
+		return nil
+	}
+	var settings []geminiSafetySetting
+	for _, pair := range strings.Split(v, ",") {
+		name, threshold, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		category := safetyCategories[strings.ToLower(strings.TrimSpace(name))]
+		threshold = strings.ToUpper(strings.TrimSpace(threshold))
+		if !ok || category == "" || !safetyThresholds[threshold] {
+			log.Printf("⚠️ Ignoring invalid GEMINI_SAFETY_SETTINGS entry %q", pair)
+			continue
+		}
+		settings = append(settings, geminiSafetySetting{Category: category, Threshold: threshold})
+	}
+	return settings
+}
+
+// ==================== REDACTION ====================
+
+// abusiveTerms are masked in the redacted retry. Terms match at the start of
+// a word, so inflections are covered. Extend with GEMINI_REDACT_TERMS.
+var abusiveTerms = []string{
+	"fuck", "shit", "bitch", "bastard", "asshole", "motherf",
+	"chutiy", "madarchod", "maderchod", "bhench", "behench", "bhosd",
+	"gaand", "gandu", "harami", "haramkhor", "kamina", "kamine", "randi", "lavd", "lund",
+	"kutt", "saale", "suar",
+}
+
+// loadRedactor builds the redacted-retry masker, or returns nil when
+// GEMINI_SAFETY_REDACT_RETRY is off
+func loadRedactor() *regexp.Regexp {
+	if os.Getenv("GEMINI_SAFETY_REDACT_RETRY") != "true" {
+		return nil
+	}
+	terms := append([]string{}, abusiveTerms...)
+	for _, t := range strings.Split(os.Getenv("GEMINI_REDACT_TERMS"), ",") {
+		if t = strings.ToLower(strings.TrimSpace(t)); t != "" {
+			terms = append(terms, t)
+		}
+	}
+	quoted := make([]string, len(terms))
+	for i, t := range terms {
+		quoted[i] = regexp.QuoteMeta(t)
+	}
+	return regexp.MustCompile(`(?i)\b(?:` + strings.Join(quoted, "|") + `)\w*`)
+}
+
+// redactTranscript masks abusive terms, reporting how many it masked
+func redactTranscript(re *regexp.Regexp, transcript string) (string, int) {
+	n := 0
+	out := re.ReplaceAllStringFunc(transcript, func(string) string {
+		n++
+		return "[redacted]"
+	})
+	return out, n
+}
@@ -0,0 +1,40 @@
+package llm
+
+import (
+	"sync"
+
+	"im-ai-voice/internal/config"
+)
+
+// ==================== REQUEST STATS ====================
+// Outcomes of the last PIPELINE_STATS_WINDOW Gemini requests, behind the LLM
+// error rate in GET /admin/pipeline/stats
+
+type requestStats struct {
+	mu     sync.Mutex
+	failed []bool // Ring buffer, oldest overwritten first
+	next   int
+}
+
+func (r *requestStats) record(failed bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.failed) < config.PIPELINE_STATS_WINDOW {
+		r.failed = append(r.failed, failed)
+		return
+	}
+	r.failed[r.next] = failed
+	r.next = (r.next + 1) % len(r.failed)
+}
+
+// Stats returns how many recent Gemini requests were made and how many of them failed
+func (a *AIClient) Stats() (requests, failures int) {
+	a.stats.mu.Lock()
+	defer a.stats.mu.Unlock()
+	for _, f := range a.stats.failed {
+		if f {
+			failures++
+		}
+	}
+	return len(a.stats.failed), failures
+}
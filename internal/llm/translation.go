@@ -0,0 +1,160 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+)
+
+// ==================== TRANSLATION CHECK ====================
+// The extraction pass now and then returns transcript_en still in Hindi, or
+// only the first part of the call translated. Downstream readers (search,
+// evidence, the dashboard, exports) assume English, so each transcript_en
+// is checked: the share of its words in Devanagari or common romanized
+// Hindi must stay under TRANSLATION_MAX_HINDI, and it must have at least
+// TRANSLATION_MIN_COVERAGE words per word of the transcript. One that fails
+// is retranslated on its own (the translation task), and the retry replaces
+// it when it passes. TRANSLATION_CHECK=false turns the check off.
+
+// romanizedHindi are common Hindi words written in Latin script, not
+// English words as well ("main", "to", "par" aren't among them)
+var romanizedHindi = map[string]bool{
+	"hai": true, "hain": true, "nahi": true, "nahin": true, "kya": true, "aap": true, "aapka": true,
+	"aapko": true, "mera": true, "meri": true, "mujhe": true, "hum": true, "humara": true, "karna": true,
+	"karo": true, "kiya": true, "raha": true, "rahe": true, "rahi": true, "tha": true, "thi": true,
+	"toh": true, "bhi": true, "yeh": true, "woh": true, "ka": true, "ki": true, "ke": true, "ko": true,
+	"mein": true, "lekin": true, "aur": true, "haan": true, "accha": true, "acha": true, "theek": true,
+	"thik": true, "bataiye": true, "chahiye": true, "sakte": true, "hoga": true, "diya": true, "gaya": true,
+	"kuch": true, "abhi": true, "matlab": true, "bolo": true, "dekhiye": true, "ji": true,
+}
+
+// translationCheck is the configured check, off when disabled
+type translationCheck struct {
+	enabled     bool
+	maxHindi    float64
+	minCoverage float64
+}
+
+func loadTranslationCheck() translationCheck {
+	return translationCheck{
+		enabled:     os.Getenv("TRANSLATION_CHECK") != "false",
+		maxHindi:    translationShare("TRANSLATION_MAX_HINDI", config.DEFAULT_TRANSLATION_MAX_HINDI),
+		minCoverage: translationShare("TRANSLATION_MIN_COVERAGE", config.DEFAULT_TRANSLATION_MIN_COVERAGE),
+	}
+}
+
+// translationShare reads a 0-1 setting, or returns def when unset or invalid
+func translationShare(name string, def float64) float64 {
+	if v := os.Getenv(name); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil && f >= 0 && f <= 1 {
+			return f
+		}
+		log.Printf("⚠️ Invalid %s=%q, using %g", name, v, def)
+	}
+	return def
+}
+
+// check returns why transcriptEn fails as the translation of transcript, or
+// nil when it passes. Coverage isn't checked when the transcript was trimmed
+// to fit the prompt.
+func (t translationCheck) check(transcript, transcriptEn string, trimmed bool) *client.TranslationCheck {
+	words := strings.Fields(transcriptEn)
+	hindi := 0
+	for _, w := range words {
+		if isHindiWord(w) {
+			hindi++
+		}
+	}
+	result := &client.TranslationCheck{}
+	if len(words) > 0 {
+		result.HindiShare = math.Round(float64(hindi)/float64(len(words))*1000) / 1000
+	}
+	sourceWords := len(strings.Fields(transcript))
+	if sourceWords > 0 {
+		result.Coverage = math.Round(float64(len(words))/float64(sourceWords)*1000) / 1000
+	}
+
+	switch {
+	case len(words) == 0 || result.HindiShare > t.maxHindi:
+		result.Reason = "untranslated"
+	case !trimmed && sourceWords >= config.TRANSLATION_CHECK_MIN_WORDS && result.Coverage < t.minCoverage:
+		result.Reason = "truncated"
+	default:
+		return nil
+	}
+	return result
+}
+
+// isHindiWord reports whether a word is in Devanagari or common romanized Hindi
+func isHindiWord(w string) bool {
+	for _, r := range w {
+		if unicode.Is(unicode.Devanagari, r) {
+			return true
+		}
+	}
+	w = strings.ToLower(strings.TrimFunc(w, func(r rune) bool { return !unicode.IsLetter(r) }))
+	return romanizedHindi[w]
+}
+
+// checkTranslation checks an extraction's transcript_en and retranslates
+// it when it fails
+func (a *AIClient) checkTranslation(ctx context.Context, rt client.RawTranscript, ext *client.CallExtraction) {
+	if !a.translation.enabled {
+		return
+	}
+	trimmed := false
+	if ext.PromptBudget != nil {
+		for _, trim := range ext.PromptBudget.Trims {
+			trimmed = trimmed || trim.Part == "transcript"
+		}
+	}
+	failed := a.translation.check(rt.Transcript, ext.TranscriptEn, trimmed)
+	if failed == nil {
+		return
+	}
+	ext.TranslationCheck = failed
+	log.Printf("   🌐 Call %s: transcript_en failed the translation check (%s, %.0f%% Hindi, coverage %.2f), retranslating",
+		rt.CallID, failed.Reason, failed.HindiShare*100, failed.Coverage)
+
+	translated, err := a.translate(ctx, rt.Transcript)
+	if err != nil {
+		log.Printf("⚠️ Call %s: retranslation failed, keeping the extraction's transcript_en: %v", rt.CallID, err)
+		return
+	}
+	if retry := a.translation.check(rt.Transcript, translated, trimmed); retry != nil {
+		log.Printf("⚠️ Call %s: retranslation failed the check too (%s), keeping the extraction's transcript_en", rt.CallID, retry.Reason)
+		return
+	}
+	ext.TranscriptEn = translated
+	failed.Retranslated = true
+	normalizeEvidence(ext) // Place the quotes in the new transcript_en
+}
+
+// translate asks for just the English translation of a transcript
+func (a *AIClient) translate(ctx context.Context, transcript string) (string, error) {
+	prompt := fmt.Sprintf(`Translate this IndiaMART customer support call transcript into English.
+
+RULES:
+- Translate ALL of it, from the first line to the last; don't summarize or skip anything
+- Hindi and Hinglish become plain English; keep names, product names, numbers and amounts as they are
+- Keep one line per turn and the speaker labels as in the original
+- Return only the translation as plain text, without markdown or comments
+
+TRANSCRIPT:
+%s`, transcript)
+	response, err := a.sendRequest(ctx, TaskTranslation, "You translate Indian customer support call transcripts into English.", prompt)
+	if err != nil {
+		return "", err
+	}
+	response = strings.TrimSpace(response)
+	response = strings.TrimPrefix(strings.TrimPrefix(response, "```text"), "```")
+	return strings.TrimSpace(strings.TrimSuffix(response, "```")), nil
+}
@@ -0,0 +1,72 @@
+package llm
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"im-ai-voice/client"
+)
+
+// ==================== USAGE ====================
+// The Gemini requests made for one analysis add up their latency and tokens
+// on a meter carried in the context, which AnalyzeCall puts on the analysis
+// as llm_usage. Meters nest: a request is added to every meter up the
+// context, so an API request's meter also counts the analyses it triggers.
+
+type usageKey struct{}
+
+type usageMeter struct {
+	mu     sync.Mutex
+	usage  client.LLMUsage
+	parent *usageMeter
+}
+
+type geminiUsageMetadata struct {
+	PromptTokenCount     int `json:"promptTokenCount"`
+	CandidatesTokenCount int `json:"candidatesTokenCount"`
+}
+
+// withUsageMeter returns a context whose Gemini requests are metered, and the meter
+func withUsageMeter(ctx context.Context) (context.Context, *usageMeter) {
+	parent, _ := ctx.Value(usageKey{}).(*usageMeter)
+	m := &usageMeter{parent: parent}
+	return context.WithValue(ctx, usageKey{}, m), m
+}
+
+// MeterUsage returns a context whose Gemini requests are metered, and a
+// function returning their usage so far (nil before any request)
+func MeterUsage(ctx context.Context) (context.Context, func() *client.LLMUsage) {
+	ctx, m := withUsageMeter(ctx)
+	return ctx, m.total
+}
+
+// meterRequest adds a request to the context's meters, if it has any
+func meterRequest(ctx context.Context, latency time.Duration, md *geminiUsageMetadata) {
+	m, _ := ctx.Value(usageKey{}).(*usageMeter)
+	for ; m != nil; m = m.parent {
+		m.add(latency, md)
+	}
+}
+
+func (m *usageMeter) add(latency time.Duration, md *geminiUsageMetadata) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.usage.Requests++
+	m.usage.LatencyMS += int(latency.Milliseconds())
+	if md != nil {
+		m.usage.PromptTokens += md.PromptTokenCount
+		m.usage.OutputTokens += md.CandidatesTokenCount
+	}
+}
+
+// total returns the usage so far, nil before any request
+func (m *usageMeter) total() *client.LLMUsage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.usage.Requests == 0 {
+		return nil
+	}
+	u := m.usage
+	return &u
+}
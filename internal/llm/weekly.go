@@ -0,0 +1,45 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// WeeklySummary is Gemini's executive narrative for a weekly report
+type WeeklySummary struct {
+	Narrative       string   `json:"narrative"`
+	Recommendations []string `json:"recommendations"`
+}
+
+const maxWeeklyRecommendations = 5
+
+// SummarizeWeek writes the executive narrative and recommendations of a
+// weekly report from its figures, given as plain text
+func (a *AIClient) SummarizeWeek(ctx context.Context, facts string) (*WeeklySummary, error) {
+	systemPrompt := `You are the head of seller support analytics at IndiaMART writing the weekly report for executives.
+From the week's figures, write a narrative of 4-6 plain sentences: how call volume, satisfaction and churn risk moved against the week before, which systemic issues matter most, and how agents performed.
+Then give 3-5 concrete recommendations, each one sentence naming who should act on what. Use only the figures given and quote the numbers that matter.
+Respond with JSON only:
+{"narrative": "...", "recommendations": ["...", "..."]}`
+
+	response, err := a.sendRequest(ctx, TaskReport, systemPrompt, facts)
+	if err != nil {
+		return nil, fmt.Errorf("LLM request failed: %w", err)
+	}
+
+	var summary WeeklySummary
+	if err := json.Unmarshal([]byte(sanitizeJSONString(extractJSON(response))), &summary); err != nil {
+		return nil, fmt.Errorf("failed to parse LLM response: %w", err)
+	}
+	summary.Narrative = strings.TrimSpace(summary.Narrative)
+	recommendations := summary.Recommendations[:0]
+	for _, r := range summary.Recommendations {
+		if r = strings.TrimSpace(r); r != "" && len(recommendations) < maxWeeklyRecommendations {
+			recommendations = append(recommendations, r)
+		}
+	}
+	summary.Recommendations = recommendations
+	return &summary, nil
+}
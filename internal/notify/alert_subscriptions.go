@@ -0,0 +1,98 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log"
+	"strings"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== ALERT SUBSCRIPTIONS ====================
+// ALERT_WEBHOOK_URL gets every alert, which regional managers can't act on
+// for sellers outside their region. An alert about a seller is stamped with
+// the seller's city and vertical from their profile, and delivered to every
+// subscription wanting it: posted to its webhook (signed with its secret,
+// batched like the shared webhook's alerts under NOTIFY_DIGEST) or emailed
+// to its addresses when SMTP is configured. Alerts without a seller only go
+// to subscriptions not scoped to cities or verticals. Delivery happens in
+// the background; failures are logged.
+
+// scopeAlert records the seller's city and vertical on alert
+func scopeAlert(ctx context.Context, alert *client.Alert) {
+	p, err := storage.LoadSellerProfile(ctx, alert.GluserID)
+	if err != nil || p == nil {
+		return // Only subscriptions without a scope get it
+	}
+	alert.City, alert.Vertical = p.CityName, p.Vertical
+}
+
+// notifyAlertSubscriptions delivers alert to the subscriptions wanting it
+func notifyAlertSubscriptions(ctx context.Context, alert client.Alert) {
+	subs, err := storage.LoadAlertSubscriptions(ctx)
+	if err != nil {
+		log.Printf("⚠️ Failed to load alert subscriptions, not delivering alert %s: %v", alert.AlertID, err)
+		return
+	}
+
+	text := alertText(alert)
+	var mailer *Mailer
+	for _, sub := range subs {
+		if !sub.Wants(alert) {
+			continue
+		}
+		switch sub.Channel {
+		case client.AlertChannelWebhook:
+			key := alert.Type + "|" + alert.GluserID + "|" + alert.IssueID
+			if digests.add(digestAlerts, alert.Severity, key, destination{url: sub.URL, secret: sub.Secret}, text, alert) {
+				continue
+			}
+			payload := map[string]any{
+				"text":         text,
+				"alert":        alert,
+				"subscription": sub.ID,
+			}
+			go func() {
+				if err := postSignedWebhook(context.WithoutCancel(ctx), sub.URL, sub.Secret, payload); err != nil {
+					log.Printf("⚠️ Alert subscription %s (%s) failed for alert %s: %v", sub.ID, sub.Name, alert.AlertID, err)
+				}
+			}()
+
+		case client.AlertChannelEmail:
+			if mailer == nil {
+				if mailer, err = NewMailerFromEnv(); err != nil {
+					log.Printf("⚠️ Not emailing alert %s to %s: %v", alert.AlertID, sub.Name, err)
+					continue
+				}
+			}
+			m := mailer
+			go func() {
+				if err := m.Send(sub.Emails, "[Voice AI] "+text, alertEmailHTML(alert)); err != nil {
+					log.Printf("⚠️ Alert email to %s failed for alert %s: %v", sub.Name, alert.AlertID, err)
+				}
+			}()
+		}
+	}
+}
+
+func alertEmailHTML(a client.Alert) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<h2>%s</h2>", html.EscapeString(a.Message))
+	fmt.Fprintf(&b, "<p><b>%s</b> · %s severity · %s</p>",
+		html.EscapeString(a.Type), html.EscapeString(a.Severity), a.CreatedAt.Format("2006-01-02 15:04"))
+	if a.GluserID != "" {
+		fmt.Fprintf(&b, "<p>Seller %s", html.EscapeString(a.GluserID))
+		if where := strings.Trim(a.City+" · "+a.Vertical, " ·"); where != "" {
+			fmt.Fprintf(&b, " (%s)", html.EscapeString(where))
+		}
+		b.WriteString("</p>")
+	}
+	if a.IssueID != "" {
+		fmt.Fprintf(&b, "<p>Issue %s</p>", html.EscapeString(a.IssueID))
+	}
+	fmt.Fprintf(&b, "<p>Alert %s</p>", html.EscapeString(a.AlertID))
+	return b.String()
+}
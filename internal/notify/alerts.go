@@ -0,0 +1,129 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== ALERTS ====================
+// Alerts are operational notifications (e.g. stale issue escalations).
+// Every alert is saved locally, synced to MongoDB and, if ALERT_WEBHOOK_URL
+// is set, posted as JSON (Slack-compatible "text" field included), or
+// batched into a digest (NOTIFY_DIGEST). It also goes to the alert
+// subscriptions wanting it (see alert_subscriptions.go).
+
+// FireAlert records an alert and notifies the configured webhook
+func FireAlert(ctx context.Context, alert client.Alert) {
+	if alert.CreatedAt.IsZero() {
+		alert.CreatedAt = time.Now()
+	}
+	if alert.AlertID == "" {
+		alert.AlertID = fmt.Sprintf("%s-%d", alert.Type, alert.CreatedAt.UnixNano())
+	}
+
+	if alert.GluserID != "" && alert.City == "" && alert.Vertical == "" {
+		scopeAlert(ctx, &alert)
+	}
+
+	log.Printf("🚨 ALERT [%s/%s] %s", alert.Type, alert.Severity, alert.Message)
+
+	if err := SaveAlert(alert); err != nil {
+		log.Printf("⚠️ Failed to save alert %s: %v", alert.AlertID, err)
+	}
+	storage.SyncAlert(ctx, &alert)
+
+	storage.RecordEvent(ctx, client.Event{
+		Type:     client.EventAlertFired,
+		GluserID: alert.GluserID,
+		Payload: client.AlertFiredPayload{
+			AlertID:   alert.AlertID,
+			AlertType: alert.Type,
+			Severity:  alert.Severity,
+			Message:   alert.Message,
+		},
+	})
+
+	if url := os.Getenv("ALERT_WEBHOOK_URL"); url != "" {
+		key := alert.Type + "|" + alert.GluserID + "|" + alert.IssueID
+		if !digests.add(digestAlerts, alert.Severity, key, destination{url: url}, alertText(alert), alert) {
+			go postAlertWebhook(context.WithoutCancel(ctx), url, alert)
+		}
+	}
+	notifyAlertSubscriptions(ctx, alert)
+}
+
+// SaveAlert saves an alert to disk
+func SaveAlert(alert client.Alert) error {
+	b, err := json.MarshalIndent(alert, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal alert: %w", err)
+	}
+	path := filepath.Join(config.ALERTS_DIR, alert.AlertID+".json")
+	return os.WriteFile(path, b, 0644)
+}
+
+func postAlertWebhook(ctx context.Context, url string, alert client.Alert) {
+	payload := map[string]interface{}{
+		"text":  alertText(alert),
+		"alert": alert,
+	}
+	if err := postWebhook(ctx, url, payload); err != nil {
+		log.Printf("⚠️ Alert webhook failed: %v", err)
+	}
+}
+
+func alertText(alert client.Alert) string {
+	return fmt.Sprintf("🚨 [%s] %s", alert.Severity, alert.Message)
+}
+
+// postWebhook posts payload as JSON to url
+func postWebhook(ctx context.Context, url string, payload any) error {
+	return postSignedWebhook(ctx, url, "", payload)
+}
+
+// PostCallback posts payload as JSON to a caller's callback URL, signed
+// like webhook deliveries when secret is set
+func PostCallback(ctx context.Context, url, secret string, payload any) error {
+	return postSignedWebhook(ctx, url, secret, payload)
+}
+
+// postSignedWebhook posts payload as JSON to url, signed with secret unless it's empty
+func postSignedWebhook(ctx context.Context, url, secret string, payload any) error {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if secret != "" {
+		req.Header.Set("X-Webhook-Signature", signPayload(secret, b))
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,280 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+)
+
+// ==================== NOTIFICATION DIGESTS ====================
+// During a backfill every ticket, alert and seller transition would be
+// posted on its own, flooding the Slack channels behind the webhooks.
+// NOTIFY_DIGEST batches a kind of notification into hourly or daily
+// windows instead, one digest per webhook (and Slack channel) per window:
+//
+//	NOTIFY_DIGEST="tickets=daily,alerts=hourly,transitions=hourly"
+//
+// Hourly windows close on the hour, daily ones at NOTIFY_DIGEST_HOUR local
+// time. Repeats of a notification in a window (the same alert type for the
+// same seller and issue, the same ticket, the same transition for the same
+// seller) are listed once with their count. Alerts and tickets at or above
+// NOTIFY_DIGEST_BYPASS severity (critical; "none" batches everything) are
+// still posted at once. Emails aren't batched. Digests are only kept by the
+// server: pending ones are posted when it shuts down, and elsewhere (the
+// CLI) notifications are posted as they happen.
+
+// Kinds of notification that can be batched
+const (
+	digestAlerts      = "alerts"
+	digestTickets     = "tickets"
+	digestTransitions = "transitions"
+)
+
+// Digest windows
+const (
+	windowHourly = "hourly"
+	windowDaily  = "daily"
+)
+
+var digestSeverities = map[string]int{"low": 1, "medium": 2, "high": 3, "critical": 4}
+
+// destination is where a notification is posted
+type destination struct {
+	url     string
+	secret  string
+	channel string // Slack channel, for ticket owners
+}
+
+// pendingDigest is a window's notifications to one destination
+type pendingDigest struct {
+	kind   string
+	window string
+	dest   destination
+	from   time.Time
+	due    time.Time
+	events int
+	items  []*client.NotificationDigestItem
+	byKey  map[string]*client.NotificationDigestItem
+}
+
+type digester struct {
+	mu        sync.Mutex
+	started   bool
+	windows   map[string]string // By kind
+	bypass    int               // Severity rank posted at once, 0 for none
+	dailyHour int
+	pending   map[string]*pendingDigest
+}
+
+var digests = &digester{pending: make(map[string]*pendingDigest)}
+
+// StartDigests batches notifications as NOTIFY_DIGEST says until ctx is
+// done, then posts the digests still pending. The returned channel is
+// closed once they're posted.
+func StartDigests(ctx context.Context) <-chan struct{} {
+	done := make(chan struct{})
+	windows := loadDigestWindows()
+	if len(windows) == 0 {
+		close(done)
+		return done
+	}
+
+	digests.mu.Lock()
+	digests.started = true
+	digests.windows = windows
+	digests.bypass = digestBypass()
+	digests.dailyHour = notifyDigestHour()
+	digests.mu.Unlock()
+	log.Printf("🗞️ Notification digests on: %s", os.Getenv("NOTIFY_DIGEST"))
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(config.NOTIFY_DIGEST_CHECK_INTERVAL)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				digests.flush(context.WithoutCancel(ctx), time.Time{})
+				return
+			case now := <-ticker.C:
+				digests.flush(ctx, now)
+			}
+		}
+	}()
+	return done
+}
+
+// DigestWindows returns the window each batched kind of notification is
+// held for, empty when digests aren't running
+func DigestWindows() map[string]string {
+	digests.mu.Lock()
+	defer digests.mu.Unlock()
+	windows := make(map[string]string, len(digests.windows))
+	for kind, window := range digests.windows {
+		windows[kind] = window
+	}
+	return windows
+}
+
+// loadDigestWindows reads NOTIFY_DIGEST, skipping malformed entries
+func loadDigestWindows() map[string]string {
+	windows := make(map[string]string)
+	for _, entry := range strings.Split(os.Getenv("NOTIFY_DIGEST"), ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		kind, window, _ := strings.Cut(entry, "=")
+		kind, window = strings.TrimSpace(kind), strings.TrimSpace(window)
+		switch {
+		case kind != digestAlerts && kind != digestTickets && kind != digestTransitions,
+			window != windowHourly && window != windowDaily && window != "off":
+			log.Printf("⚠️ Ignoring NOTIFY_DIGEST entry %q (want alerts, tickets or transitions=hourly, daily or off)", entry)
+		case window != "off":
+			windows[kind] = window
+		}
+	}
+	return windows
+}
+
+// digestBypass is the severity rank from NOTIFY_DIGEST_BYPASS, 0 for none
+func digestBypass() int {
+	v := strings.ToLower(strings.TrimSpace(os.Getenv("NOTIFY_DIGEST_BYPASS")))
+	switch {
+	case v == "":
+		return digestSeverities[config.DEFAULT_NOTIFY_DIGEST_BYPASS]
+	case v == "none":
+		return 0
+	case digestSeverities[v] > 0:
+		return digestSeverities[v]
+	}
+	log.Printf("⚠️ Invalid NOTIFY_DIGEST_BYPASS=%q, using %s", v, config.DEFAULT_NOTIFY_DIGEST_BYPASS)
+	return digestSeverities[config.DEFAULT_NOTIFY_DIGEST_BYPASS]
+}
+
+// notifyDigestHour returns the hour (business timezone) daily digests are posted
+func notifyDigestHour() int {
+	if v := os.Getenv("NOTIFY_DIGEST_HOUR"); v != "" {
+		if h, err := strconv.Atoi(v); err == nil && h >= 0 && h < 24 {
+			return h
+		}
+	}
+	return config.DEFAULT_NOTIFY_DIGEST_HOUR
+}
+
+// add batches a notification into its destination's digest, returning
+// false when it should be posted now instead: its kind isn't batched, its
+// severity bypasses digests or digests aren't running
+func (d *digester) add(kind, severity, key string, dest destination, text string, data any) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	window := d.windows[kind]
+	if !d.started || window == "" {
+		return false
+	}
+	if rank := digestSeverities[strings.ToLower(severity)]; d.bypass > 0 && rank >= d.bypass {
+		return false
+	}
+
+	now := time.Now()
+	id := kind + "|" + dest.url + "|" + dest.channel
+	p := d.pending[id]
+	if p == nil {
+		p = &pendingDigest{
+			kind:   kind,
+			window: window,
+			dest:   dest,
+			from:   now,
+			due:    d.windowEnd(window, now),
+			byKey:  make(map[string]*client.NotificationDigestItem),
+		}
+		d.pending[id] = p
+	}
+	p.events++
+	if item := p.byKey[key]; item != nil {
+		item.Count++
+		item.LastAt, item.Text, item.Data = now, text, data
+		return true
+	}
+	item := &client.NotificationDigestItem{Text: text, Count: 1, FirstAt: now, LastAt: now, Data: data}
+	p.items = append(p.items, item)
+	p.byKey[key] = item
+	return true
+}
+
+// windowEnd is when the window holding now closes
+func (d *digester) windowEnd(window string, now time.Time) time.Time {
+	local := now.In(config.BusinessTZ)
+	if window == windowHourly {
+		return local.Truncate(time.Hour).Add(time.Hour)
+	}
+	end := time.Date(local.Year(), local.Month(), local.Day(), d.dailyHour, 0, 0, 0, config.BusinessTZ)
+	if !end.After(local) {
+		end = end.AddDate(0, 0, 1)
+	}
+	return end
+}
+
+// flush posts the digests due by now, all of them for a zero now
+func (d *digester) flush(ctx context.Context, now time.Time) {
+	d.mu.Lock()
+	var due []*pendingDigest
+	for id, p := range d.pending {
+		if now.IsZero() || !now.Before(p.due) {
+			due = append(due, p)
+			delete(d.pending, id)
+		}
+	}
+	d.mu.Unlock()
+
+	for _, p := range due {
+		to := p.due
+		if now.IsZero() {
+			to = time.Now()
+		}
+		digest := client.NotificationDigest{Kind: p.kind, Window: p.window, From: p.from, To: to, Events: p.events}
+		for _, item := range p.items {
+			digest.Items = append(digest.Items, *item)
+		}
+		payload := map[string]any{
+			"text":   digestText(digest),
+			"digest": digest,
+		}
+		if p.dest.channel != "" {
+			payload["channel"] = p.dest.channel
+		}
+		if err := postSignedWebhook(ctx, p.dest.url, p.dest.secret, payload); err != nil {
+			log.Printf("⚠️ %s digest webhook failed (%d notifications): %v", p.window, p.events, err)
+			continue
+		}
+		log.Printf("🗞️ Posted %s %s digest: %d notifications, %d distinct", p.window, p.kind, p.events, len(p.items))
+	}
+}
+
+// digestText lists a digest's notifications, repeats counted, for Slack
+func digestText(d client.NotificationDigest) string {
+	var b strings.Builder
+	period := "hour"
+	if d.Window == windowDaily {
+		period = "day"
+	}
+	fmt.Fprintf(&b, "🗞️ %d %s in the last %s", d.Events, d.Kind, period)
+	for i, item := range d.Items {
+		if i == config.NOTIFY_DIGEST_MAX_LINES {
+			fmt.Fprintf(&b, "\n… and %d more", len(d.Items)-i)
+			break
+		}
+		b.WriteString("\n• " + item.Text)
+		if item.Count > 1 {
+			fmt.Fprintf(&b, " (×%d)", item.Count)
+		}
+	}
+	return b.String()
+}
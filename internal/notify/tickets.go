@@ -0,0 +1,86 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log"
+	"os"
+	"strings"
+
+	"im-ai-voice/client"
+)
+
+// ==================== TICKET NOTIFICATIONS ====================
+// New tickets go to their bucket's owner: posted as JSON to the owner's
+// webhook, or TICKET_WEBHOOK_URL for owners without one and for buckets
+// nobody owns, with the owner's Slack channel as "channel" (Slack-compatible
+// "text" field included) or batched into a digest (NOTIFY_DIGEST), and
+// emailed to the owner's addresses when SMTP is configured. Delivery happens
+// in the background; failures are logged.
+
+// NotifyTicket sends a new ticket to owner, nil for an unowned bucket
+func NotifyTicket(ctx context.Context, t client.Ticket, owner *client.BucketOwner) {
+	url := os.Getenv("TICKET_WEBHOOK_URL")
+	var channel string
+	var emails []string
+	if owner != nil {
+		if owner.WebhookURL != "" {
+			url = owner.WebhookURL
+		}
+		channel = owner.SlackChannel
+		emails = owner.Emails
+	}
+
+	if url != "" && !digests.add(digestTickets, t.Severity, t.TicketID, destination{url: url, channel: channel}, ticketText(t), t) {
+		payload := map[string]any{
+			"text":   ticketText(t),
+			"ticket": t,
+		}
+		if channel != "" {
+			payload["channel"] = channel
+		}
+		go func() {
+			if err := postWebhook(context.WithoutCancel(ctx), url, payload); err != nil {
+				log.Printf("⚠️ Ticket webhook failed for %s: %v", t.TicketID, err)
+			}
+		}()
+	}
+
+	if len(emails) > 0 {
+		mailer, err := NewMailerFromEnv()
+		if err != nil {
+			log.Printf("⚠️ Not emailing ticket %s to its owner: %v", t.TicketID, err)
+			return
+		}
+		go func() {
+			if err := mailer.Send(emails, "[Voice AI] "+ticketText(t), ticketEmailHTML(t)); err != nil {
+				log.Printf("⚠️ Ticket email failed for %s: %v", t.TicketID, err)
+			}
+		}()
+	}
+}
+
+func ticketText(t client.Ticket) string {
+	return fmt.Sprintf("🎫 [P%d %s] %s (%d sellers)", t.Priority, t.Severity, t.Title, t.AffectedCount)
+}
+
+func ticketEmailHTML(t client.Ticket) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<h2>%s</h2>", html.EscapeString(t.Title))
+	fmt.Fprintf(&b, "<p><b>%s</b> · priority %d · %s severity · %d sellers affected · %s</p>",
+		html.EscapeString(t.FeatureBucket), t.Priority, html.EscapeString(t.Severity), t.AffectedCount, t.Date)
+	fmt.Fprintf(&b, "<p>%s</p>", html.EscapeString(t.Description))
+	if len(t.TopProblems) > 0 {
+		b.WriteString("<ul>")
+		for _, p := range t.TopProblems {
+			fmt.Fprintf(&b, "<li>%s (%d)</li>", html.EscapeString(p.Problem), p.Count)
+		}
+		b.WriteString("</ul>")
+	}
+	if t.IssueURL != "" {
+		fmt.Fprintf(&b, `<p><a href="%s">GitHub issue</a></p>`, html.EscapeString(t.IssueURL))
+	}
+	fmt.Fprintf(&b, "<p>Ticket %s</p>", html.EscapeString(t.TicketID))
+	return b.String()
+}
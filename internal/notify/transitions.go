@@ -0,0 +1,77 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== PROFILE TRANSITIONS ====================
+// Seller state changes caused by a call (health label changes, churn risk
+// escalations, new sellers, resolved issues) are posted as JSON to every
+// webhook subscription wanting their type (Slack-compatible "text" field
+// included), one request per transition unless NOTIFY_DIGEST batches them
+// into a digest per subscription. Subscriptions with a secret get
+// the HMAC-SHA256 of the body, hex-encoded, in X-Webhook-Signature as
+// "sha256=<hex>". Delivery happens in the background; failures are logged.
+
+// NotifyTransitions posts transitions to the subscribed webhooks
+func NotifyTransitions(ctx context.Context, transitions []client.ProfileTransition) {
+	if len(transitions) == 0 {
+		return
+	}
+	subs, err := storage.LoadWebhookSubscriptions(ctx)
+	if err != nil {
+		log.Printf("⚠️ Failed to load webhook subscriptions, not posting %d transitions: %v", len(transitions), err)
+		return
+	}
+
+	for _, t := range transitions {
+		text := transitionText(t)
+		payload := map[string]any{
+			"text":       text,
+			"transition": t,
+		}
+		for _, sub := range subs {
+			if !sub.Wants(t.Type) {
+				continue
+			}
+			if digests.add(digestTransitions, "", t.Type+"|"+t.GluserID+"|"+t.To+"|"+t.IssueID, destination{url: sub.URL, secret: sub.Secret}, text, t) {
+				continue
+			}
+			go func() {
+				if err := postSignedWebhook(context.WithoutCancel(ctx), sub.URL, sub.Secret, payload); err != nil {
+					log.Printf("⚠️ Webhook %s failed for %s transition %s: %v", sub.ID, t.Type, t.ID, err)
+				}
+			}()
+		}
+	}
+}
+
+func transitionText(t client.ProfileTransition) string {
+	switch t.Type {
+	case client.TransitionHealthLabelChanged:
+		return fmt.Sprintf("🩺 Seller %s is now %s (was %s, health %d)", t.GluserID, t.To, t.From, t.HealthScore)
+	case client.TransitionChurnRiskEscalated:
+		return fmt.Sprintf("📉 Seller %s churn risk rose from %s to %s", t.GluserID, t.From, t.To)
+	case client.TransitionNewSeller:
+		return fmt.Sprintf("👋 First call from seller %s (health %d)", t.GluserID, t.HealthScore)
+	case client.TransitionIssueResolved:
+		return fmt.Sprintf("✅ Seller %s issue resolved: %s (%s)", t.GluserID, t.Problem, t.Bucket)
+	default:
+		return fmt.Sprintf("Seller %s: %s", t.GluserID, t.Type)
+	}
+}
+
+// signPayload returns the X-Webhook-Signature value for body
+func signPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,157 @@
+package profile
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/notify"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== ATTENTION QUEUE ====================
+// Sellers flagged NeedsAttention, most urgent first. Operators acknowledge a
+// flag, or snooze it for a while, with a note. Either one covers the reason
+// the seller was flagged for: while it holds, new calls from the seller
+// don't alert again. When the reason changes or the flag clears, the
+// acknowledgment is dropped and the next flag alerts as usual.
+
+// attentionTier ranks why a seller is flagged, in the order scoreHealth
+// picks the reason
+func attentionTier(p *client.SellerProfile) int {
+	switch {
+	case p.CurrentStatus.HealthScore < 40:
+		return 4
+	case p.CurrentStatus.ChurnRisk == "high":
+		return 3
+	}
+	for _, issue := range p.ActiveIssues {
+		if issue.IsRecurring {
+			return 2
+		}
+	}
+	return 1 // Declining trend
+}
+
+// attentionUrgency orders the queue: the reason's tier first, then the
+// lower health score
+func attentionUrgency(p *client.SellerProfile) int {
+	return attentionTier(p)*100 + (100 - p.CurrentStatus.HealthScore)
+}
+
+// attentionSeverity is the alert severity for a tier
+func attentionSeverity(tier int) string {
+	return [...]string{"low", "low", "medium", "high", "critical"}[tier]
+}
+
+// ackState returns the state an acknowledgment puts a seller in, open if it
+// doesn't apply to the current reason or its snooze is over
+func ackState(ack *client.AttentionAck, reason string, now time.Time) string {
+	switch {
+	case ack == nil || ack.Reason != reason:
+		return client.AttentionOpen
+	case ack.State == client.AttentionSnoozed && (ack.SnoozedUntil == nil || !now.Before(*ack.SnoozedUntil)):
+		return client.AttentionOpen
+	}
+	return ack.State
+}
+
+// BuildAttentionQueue lists flagged sellers, most urgent first. state filters
+// to one queue state; empty lists them all, open sellers first.
+func BuildAttentionQueue(ctx context.Context, state string) (*client.AttentionQueue, error) {
+	profiles, err := storage.LoadAllSellerProfiles(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load seller profiles: %w", err)
+	}
+	acks, err := storage.LoadAttentionAcks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load attention acks: %w", err)
+	}
+
+	now := time.Now()
+	queue := &client.AttentionQueue{Items: []client.AttentionItem{}, GeneratedAt: now}
+	for _, p := range profiles {
+		s := p.CurrentStatus
+		if !s.NeedsAttention {
+			continue
+		}
+
+		item := client.AttentionItem{
+			GluserID:     p.GluserID,
+			CustomerType: p.CustomerType,
+			CityName:     p.CityName,
+			Reason:       s.AttentionReason,
+			Urgency:      attentionUrgency(p),
+			HealthScore:  s.HealthScore,
+			HealthLabel:  s.HealthLabel,
+			ChurnRisk:    s.ChurnRisk,
+			OpenIssues:   len(p.ActiveIssues),
+			LastCallAt:   p.LastCallAt,
+			State:        ackState(acks[p.GluserID], s.AttentionReason, now),
+		}
+		switch item.State {
+		case client.AttentionOpen:
+			queue.Open++
+		case client.AttentionAcknowledged:
+			queue.Acknowledged++
+			item.Ack = acks[p.GluserID]
+		case client.AttentionSnoozed:
+			queue.Snoozed++
+			item.Ack = acks[p.GluserID]
+		}
+		if state == "" || item.State == state {
+			queue.Items = append(queue.Items, item)
+		}
+	}
+
+	sort.SliceStable(queue.Items, func(i, j int) bool {
+		a, b := queue.Items[i], queue.Items[j]
+		if (a.State == client.AttentionOpen) != (b.State == client.AttentionOpen) {
+			return a.State == client.AttentionOpen
+		}
+		if a.Urgency != b.Urgency {
+			return a.Urgency > b.Urgency
+		}
+		return a.LastCallAt.After(b.LastCallAt)
+	})
+	return queue, nil
+}
+
+// NotifyAttention alerts on a flagged seller after a call updated its
+// profile, unless an acknowledgment or snooze covers the current reason.
+// Acknowledgments that no longer apply are dropped.
+func NotifyAttention(ctx context.Context, p *client.SellerProfile) {
+	s := p.CurrentStatus
+	ack, err := storage.LoadAttentionAck(ctx, p.GluserID)
+	if err != nil {
+		log.Printf("⚠️ Failed to load attention ack for %s: %v", p.GluserID, err)
+	}
+
+	if ack != nil && (!s.NeedsAttention || ack.Reason != s.AttentionReason) {
+		if err := storage.DeleteAttentionAck(ctx, p.GluserID); err != nil {
+			log.Printf("⚠️ Failed to drop attention ack for %s: %v", p.GluserID, err)
+		}
+		ack = nil
+	}
+	if !s.NeedsAttention || ackState(ack, s.AttentionReason, time.Now()) != client.AttentionOpen {
+		return
+	}
+
+	tier := attentionTier(p)
+	notify.FireAlert(ctx, client.Alert{
+		Type:     "needs_attention",
+		Severity: attentionSeverity(tier),
+		GluserID: p.GluserID,
+		Message:  fmt.Sprintf("Seller %s needs attention: %s (health %d)", p.GluserID, s.AttentionReason, s.HealthScore),
+		Details: map[string]interface{}{
+			"reason":       s.AttentionReason,
+			"health_score": s.HealthScore,
+			"churn_risk":   s.ChurnRisk,
+			"open_issues":  len(p.ActiveIssues),
+			"urgency":      attentionUrgency(p),
+		},
+	})
+}
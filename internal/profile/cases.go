@@ -0,0 +1,164 @@
+package profile
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"strconv"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== SELLER CASES ====================
+// A seller's case is open while there's something to act on. Once they've
+// had no open issues, no resolution and no Negative call for CASE_CLOSE_DAYS
+// it closes, and their health score decays toward neutral, halfway every
+// CASE_DECAY_HALF_LIFE_DAYS, so the last calls of a long-settled seller
+// don't hold the score down (or up) for good. The next Negative call, or a
+// call leaving an open issue, reopens it.
+
+// caseCloseDays returns CASE_CLOSE_DAYS, or the default when unset or invalid
+func caseCloseDays() int {
+	if v := os.Getenv("CASE_CLOSE_DAYS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return n
+		}
+		log.Printf("⚠️ Invalid CASE_CLOSE_DAYS=%q, using %d", v, config.DEFAULT_CASE_CLOSE_DAYS)
+	}
+	return config.DEFAULT_CASE_CLOSE_DAYS
+}
+
+// caseDecayHalfLifeDays returns CASE_DECAY_HALF_LIFE_DAYS, or the default
+// when unset or invalid
+func caseDecayHalfLifeDays() int {
+	if v := os.Getenv("CASE_DECAY_HALF_LIFE_DAYS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return n
+		}
+		log.Printf("⚠️ Invalid CASE_DECAY_HALF_LIFE_DAYS=%q, using %d", v, config.DEFAULT_CASE_DECAY_HALF_LIFE_DAYS)
+	}
+	return config.DEFAULT_CASE_DECAY_HALF_LIFE_DAYS
+}
+
+// caseDecay returns the share (0-1) of the way to neutral a closed case's
+// health score has decayed, and over how many days: from the later of its
+// closing and the last call to when it was last decayed, so rescoring in
+// between gives the same score
+func caseDecay(p *client.SellerProfile) (float64, float64) {
+	c := p.Case
+	if !c.Closed() || c.ClosedAt == nil || c.DecayedAt == nil {
+		return 0, 0
+	}
+	from := *c.ClosedAt
+	if p.LastCallAt.After(from) {
+		from = p.LastCallAt
+	}
+	days := c.DecayedAt.Sub(from).Hours() / 24
+	if days <= 0 {
+		return 0, 0
+	}
+	return 1 - math.Pow(0.5, days/float64(caseDecayHalfLifeDays())), days
+}
+
+// updateCase opens the case of a profile without one and reopens a closed
+// one when the call is Negative or leaves open issues. A closed case that
+// stays closed is decayed up to now.
+func updateCase(p *client.SellerProfile, analysis *client.AnalysisResult, now time.Time) {
+	if p.Case == nil {
+		p.Case = &client.CaseLifecycle{Status: client.CaseOpen, OpenedAt: p.CreatedAt}
+		return
+	}
+	if !p.Case.Closed() {
+		return
+	}
+	if analysis.Intent.Sentiment != "Negative" && len(p.ActiveIssues) == 0 {
+		p.Case.DecayedAt = &now
+		return
+	}
+	p.Case.Status = client.CaseOpen
+	p.Case.OpenedAt = analysis.Timestamp
+	p.Case.ClosedAt, p.Case.DecayedAt = nil, nil
+	p.Case.ReopenCount++
+	p.Case.ReopenedBy = analysis.CallID
+	log.Printf("📂 Case of %s reopened by call %s", p.GluserID, analysis.CallID)
+}
+
+// settledSince returns when the seller last had something to act on: the
+// case opening, an issue resolution or a Negative call
+func settledSince(p *client.SellerProfile) time.Time {
+	since := p.CreatedAt
+	if p.Case != nil {
+		since = p.Case.OpenedAt
+	}
+	for _, issue := range p.ResolvedIssues {
+		if issue.ResolvedAt != nil && issue.ResolvedAt.After(since) {
+			since = *issue.ResolvedAt
+		}
+	}
+	for _, call := range p.CallHistory { // Newest first
+		if call.Sentiment == "Negative" {
+			if call.Timestamp.After(since) {
+				since = call.Timestamp
+			}
+			break
+		}
+	}
+	return since
+}
+
+// closeCase closes the profile's case when it has had no open issues and
+// nothing negative for closeDays, and reports whether it did
+func closeCase(p *client.SellerProfile, closeDays int, now time.Time) bool {
+	if p.Case.Closed() || len(p.ActiveIssues) > 0 || p.CurrentStatus.Sentiment == "Negative" {
+		return false
+	}
+	if now.Sub(settledSince(p)) < time.Duration(closeDays)*24*time.Hour {
+		return false
+	}
+	if p.Case == nil {
+		p.Case = &client.CaseLifecycle{OpenedAt: p.CreatedAt}
+	}
+	p.Case.Status = client.CaseClosed
+	p.Case.ClosedAt, p.Case.DecayedAt = &now, &now
+	return true
+}
+
+// RunCaseClosure closes the cases settled for CASE_CLOSE_DAYS and decays
+// the health scores of closed ones, returning how many cases it closed
+func RunCaseClosure(ctx context.Context) (int, error) {
+	profiles, err := storage.LoadAllSellerProfiles(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load seller profiles: %w", err)
+	}
+
+	now := time.Now()
+	closeDays := caseCloseDays()
+	closed, decayed := 0, 0
+	for _, p := range profiles {
+		if err := ctx.Err(); err != nil {
+			return closed, err
+		}
+		switch {
+		case closeCase(p, closeDays, now):
+			closed++
+		case p.Case.Closed():
+			p.Case.DecayedAt = &now
+			decayed++
+		default:
+			continue
+		}
+		scoreHealth(p)
+		if err := storage.SaveSellerProfile(ctx, p); err != nil {
+			return closed, fmt.Errorf("failed to save profile %s: %w", p.GluserID, err)
+		}
+	}
+	if closed > 0 || decayed > 0 {
+		log.Printf("📁 Closed %d seller cases, decayed %d closed ones", closed, decayed)
+	}
+	return closed, nil
+}
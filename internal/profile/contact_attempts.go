@@ -0,0 +1,39 @@
+package profile
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/storage"
+)
+
+// RecordContactAttempt puts a missed or zero-length call on an existing
+// seller's timeline, leaving their health, trends and issues alone. It
+// reports whether the seller had a profile: one isn't created for a contact
+// attempt, since a profile without conversations would rank as unhealthy.
+func RecordContactAttempt(ctx context.Context, gluserID string, attempt client.ContactAttempt) (bool, error) {
+	profile, err := storage.LoadSellerProfile(ctx, gluserID)
+	if err != nil {
+		return false, fmt.Errorf("failed to load profile: %w", err)
+	}
+	if profile == nil {
+		return false, nil
+	}
+
+	// A file processed again (e.g. after a restart) is the same attempt
+	if slices.ContainsFunc(profile.ContactAttempts, func(a client.ContactAttempt) bool { return a.CallID == attempt.CallID }) {
+		return true, nil
+	}
+	profile.ContactAttempts = append([]client.ContactAttempt{attempt}, profile.ContactAttempts...)
+	if len(profile.ContactAttempts) > config.CONTACT_ATTEMPTS_MAX {
+		profile.ContactAttempts = profile.ContactAttempts[:config.CONTACT_ATTEMPTS_MAX]
+	}
+
+	if err := storage.SaveSellerProfile(ctx, profile); err != nil {
+		return true, fmt.Errorf("failed to save profile: %w", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,247 @@
+package profile
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/llm"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== LLM SELLER CONTEXT ====================
+
+// BuildSellerContextFromProfile creates context string for LLM from existing
+// profile, composed as the seller context policy for the call's origin says
+// (origin may be nil). Returns the composition for the analysis to record,
+// nil without a context.
+func BuildSellerContextFromProfile(ctx context.Context, gluserID string, origin *client.CallOrigin) (string, *client.SellerContextUsed) {
+	if !storage.MayHaveSellerProfile(gluserID) {
+		return "", nil // First call from a new seller, nothing to read
+	}
+	profile, err := storage.LoadSellerProfile(ctx, gluserID)
+	if err != nil || profile == nil || profile.TotalCalls == 0 {
+		return "", nil // New seller, no context
+	}
+	policy, name := contextPolicies().For(origin)
+	used := &client.SellerContextUsed{Policy: name, TokenBudget: policy.TokenBudget}
+	cb := &contextBuilder{budget: policy.TokenBudget, used: used}
+
+	cb.header(fmt.Sprintf("\n=== SELLER PROFILE (Previous %d calls) ===\n", profile.TotalCalls))
+
+	// Current status
+	cb.header(fmt.Sprintf("Health Score: %d%% (%s)\n",
+		profile.CurrentStatus.HealthScore, profile.CurrentStatus.HealthLabel))
+	cb.header(fmt.Sprintf("Churn Risk: %s\n", profile.CurrentStatus.ChurnRisk))
+	cb.header(fmt.Sprintf("Overall Trend: %s\n", profile.Trends.OverallTrend))
+
+	// Active issues
+	if len(profile.ActiveIssues) > 0 && policy.ActiveIssues > 0 {
+		cb.section(fmt.Sprintf("\nACTIVE ISSUES (%d):\n", len(profile.ActiveIssues)))
+		for i, issue := range profile.ActiveIssues {
+			if i >= policy.ActiveIssues {
+				cb.add(fmt.Sprintf("  ... and %d more\n", len(profile.ActiveIssues)-policy.ActiveIssues))
+				break
+			}
+			recurring := ""
+			if issue.ReopenCount > 0 {
+				recurring = " [REOPENED after being resolved]"
+			} else if issue.IsRecurring {
+				recurring = " [RECURRING]"
+			}
+			if !cb.add(fmt.Sprintf("  - [%s] %s%s (mentioned %d times)\n",
+				issue.Bucket, issue.Problem, recurring, issue.MentionCount)) {
+				break
+			}
+			used.ActiveIssues++
+		}
+	}
+
+	// Recent call history
+	if len(profile.CallHistory) > 0 && policy.RecentCalls > 0 {
+		cb.section("\nRECENT CALLS:\n")
+		for _, call := range profile.CallHistory[:min(policy.RecentCalls, len(profile.CallHistory))] {
+			via := ""
+			if client.IsChatChannel(call.Channel) {
+				via = " via " + call.Channel
+			}
+			if !cb.add(fmt.Sprintf("  - %s%s: %s (Sentiment: %s, Issues: %d)\n",
+				config.BusinessDate(call.Timestamp), via, call.Summary, call.Sentiment, call.IssuesRaised)) {
+				break
+			}
+			used.RecentCalls++
+		}
+	}
+
+	// Sentiment trend
+	if profile.Trends.SentimentTrend != "stable" {
+		cb.add(fmt.Sprintf("\n⚠️ Sentiment is %s over recent calls\n", profile.Trends.SentimentTrend))
+	}
+
+	// Resolved issues, latest first
+	if len(profile.ResolvedIssues) > 0 && policy.ResolvedIssues > 0 {
+		resolved := slices.Clone(profile.ResolvedIssues)
+		sort.Slice(resolved, func(i, j int) bool { return resolvedAt(resolved[i]).After(resolvedAt(resolved[j])) })
+		cb.section("\nRESOLVED ISSUES:\n")
+		for _, issue := range resolved[:min(policy.ResolvedIssues, len(resolved))] {
+			if !cb.add(fmt.Sprintf("  - [%s] %s (resolved %s)\n",
+				issue.Bucket, issue.Problem, config.BusinessDate(resolvedAt(issue)))) {
+				break
+			}
+			used.ResolvedIssues++
+		}
+	}
+
+	// Agents' promises to the seller
+	if policy.Interventions > 0 {
+		promises, err := storage.LoadCommitments(ctx, storage.CommitmentQuery{GluserID: gluserID})
+		if err != nil {
+			log.Printf("   ⚠️ Seller context for %s without interventions: %v", gluserID, err)
+		}
+		sort.Slice(promises, func(i, j int) bool { return promises[i].CallTime.After(promises[j].CallTime) })
+		if len(promises) > 0 {
+			cb.section("\nAGENT PROMISES:\n")
+		}
+		for _, c := range promises[:min(policy.Interventions, len(promises))] {
+			if !cb.add(fmt.Sprintf("  - %s: %s [%s, due %s]\n",
+				config.BusinessDate(c.CallTime), c.Promise, c.Status, c.DueDate)) {
+				break
+			}
+			used.Interventions++
+		}
+	}
+
+	// QA reviewers' notes on earlier calls
+	if policy.Notes > 0 {
+		notes, err := storage.LoadAnnotations(ctx, storage.AnnotationQuery{GluserID: gluserID})
+		if err != nil {
+			log.Printf("   ⚠️ Seller context for %s without notes: %v", gluserID, err)
+		}
+		sort.Slice(notes, func(i, j int) bool { return notes[i].CreatedAt.After(notes[j].CreatedAt) })
+		if len(notes) > 0 {
+			cb.section("\nREVIEWER NOTES:\n")
+		}
+		for _, n := range notes[:min(policy.Notes, len(notes))] {
+			if !cb.add(fmt.Sprintf("  - %s (call %s): %s\n",
+				config.BusinessDate(n.CreatedAt), n.CallID, n.Comment)) {
+				break
+			}
+			used.Notes++
+		}
+	}
+
+	cb.header("=== END SELLER PROFILE ===\n")
+	used.Tokens = llm.EstimateTokens(cb.sb.String())
+	return cb.sb.String(), used
+}
+
+// contextBuilder writes a seller context within a token budget. Once an
+// item doesn't fit, nothing more is added; section headings are only
+// written along with their first item.
+type contextBuilder struct {
+	sb      strings.Builder
+	budget  int // 0 for none
+	tokens  int
+	pending string // Heading of the section being started
+	used    *client.SellerContextUsed
+}
+
+// header writes a line the context always has, closing line included
+func (cb *contextBuilder) header(line string) {
+	cb.sb.WriteString(line)
+	cb.tokens += llm.EstimateTokens(line)
+}
+
+// section starts a section with heading, written with its first item
+func (cb *contextBuilder) section(heading string) {
+	cb.pending = heading
+}
+
+// add writes an item, reporting false when it's over the budget
+func (cb *contextBuilder) add(line string) bool {
+	if cb.used.Truncated {
+		return false
+	}
+	n := llm.EstimateTokens(cb.pending + line)
+	if cb.budget > 0 && cb.tokens+n+endTokens > cb.budget {
+		cb.used.Truncated = true
+		return false
+	}
+	cb.sb.WriteString(cb.pending)
+	cb.sb.WriteString(line)
+	cb.tokens += n
+	cb.pending = ""
+	return true
+}
+
+// endTokens is kept free for the closing line
+var endTokens = llm.EstimateTokens("=== END SELLER PROFILE ===\n")
+
+func resolvedAt(issue client.TrackedIssue) time.Time {
+	if issue.ResolvedAt != nil {
+		return *issue.ResolvedAt
+	}
+	return issue.LastMentionedAt
+}
+
+// BuildSellerContext creates a context summary of previous interactions for a seller
+func BuildSellerContext(gluserID string) string {
+	analyses, err := storage.LoadAnalysesForGluser(gluserID)
+	if err != nil || len(analyses) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("\n=== SELLER HISTORY (Previous %d calls) ===\n", len(analyses)))
+
+	// Collect all previous issues
+	issueFrequency := make(map[string]int)
+	var unresolvedIssues []string
+	sentimentTrend := []string{}
+
+	for _, a := range analyses {
+		for _, issue := range a.Issues {
+			issueFrequency[issue.Bucket]++
+			// Use severity as proxy - high/critical issues may be unresolved
+			if issue.Severity == "high" || issue.Severity == "critical" {
+				unresolvedIssues = append(unresolvedIssues, issue.Problem)
+			}
+		}
+		sentimentTrend = append(sentimentTrend, a.Intent.Sentiment)
+	}
+
+	sb.WriteString(fmt.Sprintf("Total Previous Calls: %d\n", len(analyses)))
+
+	if len(issueFrequency) > 0 {
+		sb.WriteString("Recurring Issue Categories:\n")
+		for bucket, count := range issueFrequency {
+			if count > 1 {
+				sb.WriteString(fmt.Sprintf("  - %s: %d times\n", bucket, count))
+			}
+		}
+	}
+
+	if len(unresolvedIssues) > 0 {
+		sb.WriteString(fmt.Sprintf("Critical/High Severity Issues from Past: %d\n", len(unresolvedIssues)))
+		for i, issue := range unresolvedIssues {
+			if i >= 3 { // Limit to 3 examples
+				sb.WriteString(fmt.Sprintf("  ... and %d more\n", len(unresolvedIssues)-3))
+				break
+			}
+			sb.WriteString(fmt.Sprintf("  - %s\n", issue))
+		}
+	}
+
+	if len(sentimentTrend) > 0 {
+		sb.WriteString(fmt.Sprintf("Sentiment History: %s\n", strings.Join(sentimentTrend, " → ")))
+	}
+
+	sb.WriteString("=== END SELLER HISTORY ===\n")
+	return sb.String()
+}
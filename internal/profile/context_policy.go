@@ -0,0 +1,134 @@
+package profile
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+)
+
+// ==================== SELLER CONTEXT POLICY ====================
+// How much of a seller's history goes into the analysis prompt differs by
+// deployment: a CRM sending long calls wants fewer past calls and a tight
+// budget, a support desk wants its reviewers' notes. SELLER_CONTEXT_POLICY
+// points at a JSON file with a default policy and overrides per source
+// system (the closest thing to tenants calls have), each field of an
+// override falling back to the default:
+//
+//	{
+//	  "default": {"recent_calls": 3, "active_issues": 5, "resolved_issues": 2, "token_budget": 1500},
+//	  "systems": {"crm": {"notes": 3, "interventions": 5}}
+//	}
+//
+// Counts of 0 leave a section out. A missing file keeps the built-in policy
+// (3 recent calls, 5 active issues, nothing else, no budget); an invalid one
+// is logged and ignored. The file is read once per process.
+
+// ContextPolicy says what a seller context is built from
+type ContextPolicy struct {
+	RecentCalls    int `json:"recent_calls"`
+	ActiveIssues   int `json:"active_issues"`
+	ResolvedIssues int `json:"resolved_issues"`
+	Notes          int `json:"notes"`         // QA reviewers' annotations on the seller's calls, newest first
+	Interventions  int `json:"interventions"` // Agents' promises to the seller (commitments), newest first
+	TokenBudget    int `json:"token_budget"`  // Estimated tokens, 0 for no limit
+}
+
+// ContextPolicies are the default policy and the source systems' own
+type ContextPolicies struct {
+	Default ContextPolicy
+	Systems map[string]ContextPolicy // By lowercased system
+}
+
+// DefaultContextPolicy is the built-in policy
+func DefaultContextPolicy() ContextPolicy {
+	return ContextPolicy{RecentCalls: config.DEFAULT_CONTEXT_RECENT_CALLS, ActiveIssues: config.DEFAULT_CONTEXT_ACTIVE_ISSUES}
+}
+
+// For returns the policy for a call's origin, which may be nil, and its name
+func (p *ContextPolicies) For(origin *client.CallOrigin) (ContextPolicy, string) {
+	if origin != nil && origin.System != "" {
+		if sp, ok := p.Systems[strings.ToLower(origin.System)]; ok {
+			return sp, strings.ToLower(origin.System)
+		}
+	}
+	return p.Default, "default"
+}
+
+func (p ContextPolicy) validate() error {
+	for name, n := range map[string]int{
+		"recent_calls": p.RecentCalls, "active_issues": p.ActiveIssues, "resolved_issues": p.ResolvedIssues,
+		"notes": p.Notes, "interventions": p.Interventions, "token_budget": p.TokenBudget,
+	} {
+		if n < 0 {
+			return fmt.Errorf("%s is negative", name)
+		}
+	}
+	return nil
+}
+
+// LoadContextPolicies reads a policy file, the built-in policy when it's missing
+func LoadContextPolicies(path string) (*ContextPolicies, error) {
+	policies := &ContextPolicies{Default: DefaultContextPolicy(), Systems: map[string]ContextPolicy{}}
+	b, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return policies, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var file struct {
+		Default json.RawMessage            `json:"default"`
+		Systems map[string]json.RawMessage `json:"systems"`
+	}
+	if err := json.Unmarshal(b, &file); err != nil {
+		return nil, fmt.Errorf("invalid seller context policy %s: %w", path, err)
+	}
+	if file.Default != nil {
+		if err := json.Unmarshal(file.Default, &policies.Default); err != nil {
+			return nil, fmt.Errorf("invalid seller context policy %s: default: %w", path, err)
+		}
+	}
+	if err := policies.Default.validate(); err != nil {
+		return nil, fmt.Errorf("invalid seller context policy %s: default: %w", path, err)
+	}
+	for system, raw := range file.Systems {
+		p := policies.Default
+		if err := json.Unmarshal(raw, &p); err != nil {
+			return nil, fmt.Errorf("invalid seller context policy %s: %s: %w", path, system, err)
+		}
+		if err := p.validate(); err != nil {
+			return nil, fmt.Errorf("invalid seller context policy %s: %s: %w", path, system, err)
+		}
+		policies.Systems[strings.ToLower(system)] = p
+	}
+	log.Printf("🧾 Seller context policy from %s: %d source systems with their own", path, len(policies.Systems))
+	return policies, nil
+}
+
+var (
+	policiesOnce sync.Once
+	policies     *ContextPolicies
+)
+
+// contextPolicies returns SELLER_CONTEXT_POLICY's policies, loaded on first use
+func contextPolicies() *ContextPolicies {
+	policiesOnce.Do(func() {
+		path := os.Getenv("SELLER_CONTEXT_POLICY")
+		if path == "" {
+			path = config.DEFAULT_SELLER_CONTEXT_POLICY
+		}
+		var err error
+		if policies, err = LoadContextPolicies(path); err != nil {
+			log.Printf("⚠️ %v - using the built-in seller context policy", err)
+			policies = &ContextPolicies{Default: DefaultContextPolicy()}
+		}
+	})
+	return policies
+}
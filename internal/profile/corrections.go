@@ -0,0 +1,156 @@
+package profile
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== MANUAL CORRECTIONS ====================
+// Operators fix what call metadata got wrong through PATCH /sellers/{id}.
+// Corrected identity fields stick: later calls no longer overwrite them.
+// Account managers who know the LLM misjudged a seller override its churn
+// risk or sentiment; the override stands in for the model's value in the
+// status, so dashboards, health scores and attention alerts follow it, while
+// the model's value is still tracked for comparison and put back when the
+// override is cleared or expires.
+
+// ApplyCorrections applies a validated patch to the profile, by the given
+// actor, returning the JSON names of the fields it changed
+func ApplyCorrections(p *client.SellerProfile, patch client.SellerProfilePatch, by string, now time.Time) []string {
+	var changed []string
+	correct := func(field string, dst *string, v *string) {
+		if v == nil || *dst == *v {
+			return
+		}
+		*dst = *v
+		changed = append(changed, field)
+		if !slices.Contains(p.CorrectedFields, field) {
+			p.CorrectedFields = append(p.CorrectedFields, field)
+		}
+	}
+	correct("customer_type", &p.CustomerType, patch.CustomerType)
+	correct("city_name", &p.CityName, patch.CityName)
+	correct("vertical", &p.Vertical, patch.Vertical)
+
+	status := &p.CurrentStatus
+	override := func(field string, cur **client.StatusOverride, set *client.StatusOverride, clear bool, value *string) {
+		switch {
+		case set != nil:
+			model := *value
+			if *cur != nil {
+				model = (*cur).ModelValue
+			}
+			*cur = &client.StatusOverride{
+				Value:      set.Value,
+				Reason:     set.Reason,
+				By:         by,
+				At:         now,
+				ExpiresAt:  set.ExpiresAt,
+				ModelValue: model,
+			}
+			*value = set.Value
+		case clear && *cur != nil:
+			*value = (*cur).ModelValue
+			*cur = nil
+		default:
+			return
+		}
+		changed = append(changed, field)
+	}
+	override("churn_override", &p.ChurnOverride, patch.ChurnOverride, patch.ClearChurnOverride, &status.ChurnRisk)
+	override("sentiment_override", &p.SentimentOverride, patch.SentimentOverride, patch.ClearSentimentOverride, &status.Sentiment)
+
+	if len(changed) > 0 {
+		scoreHealth(p)
+	}
+	return changed
+}
+
+// applyOverrides puts the model's churn risk and sentiment, just set on the
+// status, into the profile's overrides and the overrides' values in their
+// place. Expired overrides are dropped.
+func applyOverrides(p *client.SellerProfile, now time.Time) {
+	status := &p.CurrentStatus
+	for _, o := range []struct {
+		cur   **client.StatusOverride
+		value *string
+	}{
+		{&p.ChurnOverride, &status.ChurnRisk},
+		{&p.SentimentOverride, &status.Sentiment},
+	} {
+		if *o.cur == nil {
+			continue
+		}
+		if !(*o.cur).Active(now) {
+			*o.cur = nil
+			continue
+		}
+		(*o.cur).ModelValue = *o.value
+		*o.value = (*o.cur).Value
+	}
+}
+
+// expireOverrides ends the profile's overrides past their expiry, restoring
+// the model's values, and reports whether any ended
+func expireOverrides(p *client.SellerProfile, now time.Time) bool {
+	status := &p.CurrentStatus
+	expired := false
+	for _, o := range []struct {
+		cur   **client.StatusOverride
+		value *string
+	}{
+		{&p.ChurnOverride, &status.ChurnRisk},
+		{&p.SentimentOverride, &status.Sentiment},
+	} {
+		if *o.cur == nil || (*o.cur).Active(now) {
+			continue
+		}
+		*o.value = (*o.cur).ModelValue
+		*o.cur = nil
+		expired = true
+	}
+	if expired {
+		scoreHealth(p)
+	}
+	return expired
+}
+
+// RunOverrideExpiry ends the churn and sentiment overrides past their expiry,
+// returning how many profiles went back to the model's values
+func RunOverrideExpiry(ctx context.Context) (int, error) {
+	profiles, err := storage.LoadAllSellerProfiles(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load seller profiles: %w", err)
+	}
+
+	now := time.Now()
+	expired := 0
+	for _, p := range profiles {
+		if err := ctx.Err(); err != nil {
+			return expired, err
+		}
+		if !expireOverrides(p, now) {
+			continue
+		}
+		if err := storage.SaveSellerProfile(ctx, p); err != nil {
+			return expired, fmt.Errorf("failed to save profile %s: %w", p.GluserID, err)
+		}
+		expired++
+	}
+	if expired > 0 {
+		log.Printf("⏳ Overrides expired on %d seller profiles", expired)
+	}
+	return expired, nil
+}
+
+// isCorrected reports whether an operator corrected the field, so call
+// metadata mustn't overwrite it
+func isCorrected(p *client.SellerProfile, field string) bool {
+	return slices.Contains(p.CorrectedFields, field)
+}
@@ -0,0 +1,64 @@
+package profile
+
+import (
+	"im-ai-voice/client"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== CALL DIFF ====================
+// Compares two analyses of the same seller for retention reviews. Issues are
+// matched by bucket: the LLM words the same problem differently from call to
+// call, so comparing problem text would report every issue as new.
+
+// DiffCalls compares call a with call b (b is treated as the later state)
+func DiffCalls(gluserID string, a, b *client.AnalysisResult) *client.CallDiff {
+	diff := &client.CallDiff{
+		GluserID:         gluserID,
+		CallA:            storage.NewCallListItem(a),
+		CallB:            storage.NewCallListItem(b),
+		IssuesGained:     issuesNotIn(b.Issues, a.Issues),
+		IssuesLost:       issuesNotIn(a.Issues, b.Issues),
+		IssuesPersisting: []string{},
+	}
+
+	for _, bucket := range diff.CallB.Buckets {
+		for _, issue := range a.Issues {
+			if issue.Bucket == bucket {
+				diff.IssuesPersisting = append(diff.IssuesPersisting, bucket)
+				break
+			}
+		}
+	}
+
+	ma, mb := NewSellerMetric(gluserID, a), NewSellerMetric(gluserID, b)
+	diff.SentimentDelta = mb.Sentiment - ma.Sentiment
+	diff.SatisfactionDelta = b.Intent.SatisfactionScore - a.Intent.SatisfactionScore
+	switch {
+	case mb.ChurnRisk > ma.ChurnRisk:
+		diff.ChurnChange = client.ChurnIncreased
+	case mb.ChurnRisk < ma.ChurnRisk:
+		diff.ChurnChange = client.ChurnDecreased
+	default:
+		diff.ChurnChange = client.ChurnUnchanged
+	}
+
+	return diff
+}
+
+// issuesNotIn returns the issues whose bucket none of other has
+func issuesNotIn(issues, other []client.Issue) []client.Issue {
+	out := []client.Issue{}
+	for _, issue := range issues {
+		found := false
+		for _, o := range other {
+			if o.Bucket == issue.Bucket {
+				found = true
+				break
+			}
+		}
+		if !found {
+			out = append(out, issue)
+		}
+	}
+	return out
+}
@@ -0,0 +1,130 @@
+package profile
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== HEALTH SCORE WEIGHTS ====================
+// Open and recurring issues lower a seller's health score. Some buckets hurt
+// more than others, so each issue's penalty is scaled by its bucket weight
+// and its severity multiplier, both 1 unless overridden:
+//
+//	HEALTH_BUCKET_WEIGHTS="Billing & Renewal=2,Payments=1.5,Other=0.5"
+//	HEALTH_SEVERITY_MULTIPLIERS="critical=2,high=1.5,low=0.5"
+//
+// Scores are only recalculated as calls come in; after changing weights,
+// `imvoicectl recompute-health` rescores every profile.
+
+// HealthWeights are the per-bucket and per-severity issue penalty scales
+type HealthWeights struct {
+	Buckets    map[string]float64 `json:"buckets"`
+	Severities map[string]float64 `json:"severities"`
+}
+
+var healthWeights = loadHealthWeights()
+
+func loadHealthWeights() HealthWeights {
+	w := HealthWeights{
+		Buckets:    parseWeights("HEALTH_BUCKET_WEIGHTS", os.Getenv("HEALTH_BUCKET_WEIGHTS")),
+		Severities: parseWeights("HEALTH_SEVERITY_MULTIPLIERS", strings.ToLower(os.Getenv("HEALTH_SEVERITY_MULTIPLIERS"))),
+	}
+	for bucket := range w.Buckets {
+		if !slices.Contains(config.FeatureBuckets, bucket) {
+			log.Printf("⚠️ HEALTH_BUCKET_WEIGHTS names unknown bucket %q", bucket)
+		}
+	}
+	return w
+}
+
+// parseWeights reads name=number entries, skipping malformed and negative ones
+func parseWeights(env, v string) map[string]float64 {
+	m := make(map[string]float64)
+	for _, entry := range strings.Split(v, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		name, num, ok := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		f, err := strconv.ParseFloat(strings.TrimSpace(num), 64)
+		if !ok || name == "" || err != nil || f < 0 {
+			log.Printf("⚠️ Ignoring malformed %s entry %q (want name=number)", env, entry)
+			continue
+		}
+		m[name] = f
+	}
+	return m
+}
+
+// issueWeight scales an issue's health penalty
+func (w HealthWeights) issueWeight(issue client.TrackedIssue) float64 {
+	weight := 1.0
+	if b, ok := w.Buckets[issue.Bucket]; ok {
+		weight *= b
+	}
+	if s, ok := w.Severities[strings.ToLower(issue.Severity)]; ok {
+		weight *= s
+	}
+	return weight
+}
+
+// ==================== HEALTH RECOMPUTE ====================
+
+// HealthRecomputeResult summarizes a health score recompute
+type HealthRecomputeResult struct {
+	Weights   HealthWeights `json:"weights"`
+	Profiles  int           `json:"profiles"`
+	Changed   int           `json:"changed"`   // Profiles whose score, label or attention flag changed
+	Worsened  int           `json:"worsened"`  // Of those, profiles whose score dropped
+	Attention int           `json:"attention"` // Profiles needing attention after the recompute
+}
+
+// RecomputeHealth rescores every seller profile with the current weights,
+// from the status, issues and trend already on the profile
+func RecomputeHealth(ctx context.Context, dryRun bool) (*HealthRecomputeResult, error) {
+	profiles, err := storage.LoadAllSellerProfiles(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load seller profiles: %w", err)
+	}
+
+	result := &HealthRecomputeResult{Weights: healthWeights, Profiles: len(profiles)}
+	for _, p := range profiles {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		before := p.CurrentStatus
+		scoreHealth(p)
+		after := p.CurrentStatus
+		if after.NeedsAttention {
+			result.Attention++
+		}
+		if after.HealthScore == before.HealthScore && after.HealthLabel == before.HealthLabel &&
+			after.NeedsAttention == before.NeedsAttention && after.AttentionReason == before.AttentionReason {
+			continue
+		}
+		result.Changed++
+		if after.HealthScore < before.HealthScore {
+			result.Worsened++
+		}
+
+		if dryRun {
+			continue
+		}
+		if err := storage.SaveSellerProfile(ctx, p); err != nil {
+			return result, fmt.Errorf("failed to save profile %s: %w", p.GluserID, err)
+		}
+	}
+
+	log.Printf("🩺 Health recompute: %d of %d profiles changed (%d worsened)", result.Changed, result.Profiles, result.Worsened)
+	return result, nil
+}
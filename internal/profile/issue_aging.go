@@ -0,0 +1,214 @@
+package profile
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/notify"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== ISSUE AGING ====================
+// Age of open TrackedIssues across all sellers, plus escalation of
+// high-severity issues that stay open past ESCALATION_AGE_DAYS.
+
+// Age buckets (days since first reported)
+var issueAgeBuckets = []struct {
+	Label   string
+	MaxDays int // inclusive, -1 = unbounded
+}{
+	{"0-7", 7},
+	{"8-30", 30},
+	{"30+", -1},
+}
+
+const issueAgingOldestLimit = 20
+
+// AgedIssue is an open issue with its age and owner
+type AgedIssue struct {
+	GluserID string              `json:"gluser_id"`
+	AgeDays  int                 `json:"age_days"`
+	Issue    client.TrackedIssue `json:"issue"`
+}
+
+// IssueAgeBucket summarizes open issues in one age range
+type IssueAgeBucket struct {
+	Label             string         `json:"label"`
+	Count             int            `json:"count"`
+	SeverityBreakdown map[string]int `json:"severity_breakdown"`
+	FeatureBuckets    map[string]int `json:"feature_buckets"`
+}
+
+// IssueAgingReport is the response for GET /analytics/issue-aging
+type IssueAgingReport struct {
+	GeneratedAt    time.Time        `json:"generated_at"`
+	TotalOpen      int              `json:"total_open"`
+	AvgAgeDays     float64          `json:"avg_age_days"`
+	Buckets        []IssueAgeBucket `json:"buckets"`
+	Oldest         []AgedIssue      `json:"oldest"`
+	EscalationDays int              `json:"escalation_age_days"`
+	Escalated      int              `json:"escalated_open"`
+}
+
+// issueAgeDays returns whole days since an issue was first reported
+func issueAgeDays(issue client.TrackedIssue, now time.Time) int {
+	return int(now.Sub(issue.FirstReportedAt).Hours() / 24)
+}
+
+// escalationAgeDays returns the age after which high-severity issues escalate
+func escalationAgeDays() int {
+	if v := os.Getenv("ESCALATION_AGE_DAYS"); v != "" {
+		if d, err := strconv.Atoi(v); err == nil && d > 0 {
+			return d
+		}
+	}
+	return config.DEFAULT_ESCALATION_AGE_DAYS
+}
+
+// BuildIssueAgingReport buckets every open issue by age, of sellers now in
+// stage (all for "")
+func BuildIssueAgingReport(ctx context.Context, stage string) (*IssueAgingReport, error) {
+	profiles, err := storage.LoadAllSellerProfiles(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load seller profiles: %w", err)
+	}
+
+	now := time.Now()
+	report := &IssueAgingReport{
+		GeneratedAt:    now,
+		Oldest:         []AgedIssue{},
+		EscalationDays: escalationAgeDays(),
+	}
+	for _, b := range issueAgeBuckets {
+		report.Buckets = append(report.Buckets, IssueAgeBucket{
+			Label:             b.Label,
+			SeverityBreakdown: make(map[string]int),
+			FeatureBuckets:    make(map[string]int),
+		})
+	}
+
+	var all []AgedIssue
+	totalAge := 0
+	for _, p := range profiles {
+		if stage != "" && p.JourneyStage != stage {
+			continue
+		}
+		for _, issue := range p.ActiveIssues {
+			age := issueAgeDays(issue, now)
+			all = append(all, AgedIssue{GluserID: p.GluserID, AgeDays: age, Issue: issue})
+			totalAge += age
+			if issue.EscalatedAt != nil {
+				report.Escalated++
+			}
+
+			for i, b := range issueAgeBuckets {
+				if b.MaxDays < 0 || age <= b.MaxDays {
+					report.Buckets[i].Count++
+					report.Buckets[i].SeverityBreakdown[issue.Severity]++
+					report.Buckets[i].FeatureBuckets[issue.Bucket]++
+					break
+				}
+			}
+		}
+	}
+
+	report.TotalOpen = len(all)
+	if len(all) > 0 {
+		report.AvgAgeDays = float64(totalAge) / float64(len(all))
+	}
+
+	sort.Slice(all, func(i, j int) bool { return all[i].AgeDays > all[j].AgeDays })
+	if len(all) > issueAgingOldestLimit {
+		all = all[:issueAgingOldestLimit]
+	}
+	report.Oldest = append(report.Oldest, all...)
+
+	return report, nil
+}
+
+// ==================== STALE ISSUE ESCALATION ====================
+
+// escalateSeverity bumps a severity one level (critical stays critical)
+func escalateSeverity(sev string) string {
+	switch sev {
+	case "low":
+		return "medium"
+	case "medium":
+		return "high"
+	default:
+		return "critical"
+	}
+}
+
+// RunStaleIssueEscalation escalates high-severity issues open longer than the
+// configured age. Each issue is escalated once; returns the number escalated.
+func RunStaleIssueEscalation(ctx context.Context) (int, error) {
+	profiles, err := storage.LoadAllSellerProfiles(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load seller profiles: %w", err)
+	}
+
+	now := time.Now()
+	maxAge := escalationAgeDays()
+	escalated := 0
+
+	for _, p := range profiles {
+		if ctx.Err() != nil {
+			return escalated, ctx.Err()
+		}
+
+		changed := false
+		for i := range p.ActiveIssues {
+			issue := &p.ActiveIssues[i]
+			if issue.EscalatedAt != nil || severityLevel(issue.Severity) < severityLevel("high") {
+				continue
+			}
+			age := issueAgeDays(*issue, now)
+			if age < maxAge {
+				continue
+			}
+
+			previous := issue.Severity
+			issue.Severity = escalateSeverity(issue.Severity)
+			escalatedAt := now
+			issue.EscalatedAt = &escalatedAt
+			changed = true
+			escalated++
+
+			notify.FireAlert(ctx, client.Alert{
+				Type:     "stale_issue",
+				Severity: issue.Severity,
+				GluserID: p.GluserID,
+				IssueID:  issue.IssueID,
+				Message: fmt.Sprintf("Seller %s: %s issue open for %d days (%s -> %s): %s",
+					p.GluserID, issue.Bucket, age, previous, issue.Severity, issue.Problem),
+				Details: map[string]interface{}{
+					"bucket":            issue.Bucket,
+					"age_days":          age,
+					"previous_severity": previous,
+					"first_reported_at": issue.FirstReportedAt,
+					"mention_count":     issue.MentionCount,
+				},
+			})
+		}
+
+		if changed {
+			updateIssueStats(p)
+			if err := storage.SaveSellerProfile(ctx, p); err != nil {
+				log.Printf("⚠️ Failed to save escalated profile %s: %v", p.GluserID, err)
+			}
+		}
+	}
+
+	if escalated > 0 {
+		log.Printf("⏫ Escalated %d stale issues (open > %d days)", escalated, maxAge)
+	}
+	return escalated, nil
+}
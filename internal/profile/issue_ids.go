@@ -0,0 +1,86 @@
+package profile
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/storage"
+	"im-ai-voice/internal/ulid"
+)
+
+// ==================== ISSUE MIGRATION ====================
+// Tracked issues used to be named "{gluser}-{call}-{len(ActiveIssues)}",
+// which repeats once issues are resolved and new ones take their slot.
+// New issues get ULIDs; this rewrites the old IDs (and any duplicates) in
+// place, using the issue's first report time so IDs still sort by age.
+//
+// With MongoDB it also re-saves profiles that still embed their issues,
+// which moves the issues into the issues collection.
+
+// IssueMigrationResult summarizes an issue migration
+type IssueMigrationResult struct {
+	Profiles  int `json:"profiles"`
+	Updated   int `json:"updated"`   // Profiles with at least one rewritten ID
+	Rewritten int `json:"rewritten"` // Issue IDs replaced
+	Moved     int `json:"moved"`     // Profiles whose issues moved to the issues collection
+}
+
+// MigrateIssues gives every tracked issue without a unique ULID a new one
+// and moves embedded issues into the issues collection
+func MigrateIssues(ctx context.Context, dryRun bool) (*IssueMigrationResult, error) {
+	embeddedIDs, err := storage.ListSellersWithEmbeddedIssues(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list profiles with embedded issues: %w", err)
+	}
+	embedded := make(map[string]bool, len(embeddedIDs))
+	for _, id := range embeddedIDs {
+		embedded[id] = true
+	}
+
+	profiles, err := storage.LoadAllSellerProfiles(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load seller profiles: %w", err)
+	}
+
+	result := &IssueMigrationResult{Profiles: len(profiles)}
+	for _, p := range profiles {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		seen := make(map[string]bool)
+		rewritten := rewriteIssueIDs(p.ActiveIssues, seen) + rewriteIssueIDs(p.ResolvedIssues, seen)
+		if rewritten > 0 {
+			result.Updated++
+			result.Rewritten += rewritten
+		}
+		if embedded[p.GluserID] {
+			result.Moved++
+		}
+
+		if dryRun || (rewritten == 0 && !embedded[p.GluserID]) {
+			continue
+		}
+		if err := storage.SaveSellerProfile(ctx, p); err != nil {
+			return result, fmt.Errorf("failed to save profile %s: %w", p.GluserID, err)
+		}
+	}
+
+	log.Printf("🔑 Issue migration: %d IDs rewritten across %d profiles, %d profiles moved to %s", result.Rewritten, result.Updated, result.Moved, storage.COLLECTION_ISSUES)
+	return result, nil
+}
+
+// rewriteIssueIDs replaces legacy and already-seen IDs, returning how many changed
+func rewriteIssueIDs(issues []client.TrackedIssue, seen map[string]bool) int {
+	rewritten := 0
+	for i := range issues {
+		if id := issues[i].IssueID; !ulid.IsValid(id) || seen[id] {
+			issues[i].IssueID = ulid.NewAt(issues[i].FirstReportedAt)
+			rewritten++
+		}
+		seen[issues[i].IssueID] = true
+	}
+	return rewritten
+}
@@ -0,0 +1,105 @@
+package profile
+
+import (
+	"log"
+	"math"
+	"os"
+	"slices"
+	"strings"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/aggregate"
+	"im-ai-voice/internal/config"
+)
+
+// ==================== JOURNEY STAGE ====================
+// Where a seller is in their life on IndiaMART, from their vintage, what
+// their latest call was about and their renewal signals, checked in order:
+//
+//	win-back        downgraded from a paid plan to free, a defaulter or
+//	                blocked customer type, or a high churn risk call about a
+//	                competitor or closing down; stays until a low churn risk call
+//	onboarding      under ONBOARDING_VINTAGE_MONTHS (their first 90 days)
+//	renewal-window  renewal at risk or billing and renewal raised on the
+//	                call, or a paid seller in the last JOURNEY_RENEWAL_MONTHS
+//	                before their yearly anniversary
+//	activation      under JOURNEY_ACTIVATION_MONTHS
+//	steady-state    otherwise
+//
+// The stage sets the health score below which a seller needs attention:
+// sellers early on or near renewal are flagged sooner. Override per stage:
+//
+//	JOURNEY_ATTENTION_HEALTH="onboarding=55,renewal-window=50"
+
+const renewalBucket = "Billing & Renewal"
+
+// winBackChurnReasons are churn reasons of sellers on their way out
+var winBackChurnReasons = []string{"competitor", "business_closed"}
+
+// stageAttentionHealth are the default attention thresholds differing from DEFAULT_ATTENTION_HEALTH
+var stageAttentionHealth = map[string]int{
+	client.StageOnboarding:    50,
+	client.StageRenewalWindow: 50,
+}
+
+var attentionHealth = loadAttentionHealth()
+
+func loadAttentionHealth() map[string]int {
+	m := make(map[string]int, len(client.JourneyStages))
+	for _, stage := range client.JourneyStages {
+		m[stage] = config.DEFAULT_ATTENTION_HEALTH
+		if v, ok := stageAttentionHealth[stage]; ok {
+			m[stage] = v
+		}
+	}
+	for stage, v := range parseWeights("JOURNEY_ATTENTION_HEALTH", os.Getenv("JOURNEY_ATTENTION_HEALTH")) {
+		if _, ok := m[stage]; !ok || v != math.Trunc(v) || v > 100 {
+			log.Printf("⚠️ Ignoring JOURNEY_ATTENTION_HEALTH entry %q (want a journey stage and a score up to 100)", stage)
+			continue
+		}
+		m[stage] = int(v)
+	}
+	return m
+}
+
+// AttentionHealthFor is the health score below which a seller in stage needs attention
+func AttentionHealthFor(stage string) int {
+	if v, ok := attentionHealth[stage]; ok {
+		return v
+	}
+	return config.DEFAULT_ATTENTION_HEALTH
+}
+
+// journeyStage classifies the seller after analysis, their latest call;
+// previousType is their customer type before the call
+func journeyStage(p *client.SellerProfile, previousType string, analysis *client.AnalysisResult) string {
+	customerType := strings.ToUpper(p.CustomerType)
+	tier := aggregate.CustomerTier(p.CustomerType)
+	churn := analysis.Churn.IsLikelyToChurn
+
+	switch {
+	case previousType != "" && isPaidTier(aggregate.CustomerTier(previousType)) && tier == client.TierFree,
+		strings.Contains(customerType, "DEFAULTER"), customerType == "TEMPBLOCK",
+		churn == "high" && slices.Contains(winBackChurnReasons, config.ClassifyChurnReason(analysis.Churn.ChurnReasonCategory, analysis.Churn.ChurnReason)),
+		p.JourneyStage == client.StageWinBack && churn != "low":
+		return client.StageWinBack
+	case p.VintageMonths < config.ONBOARDING_VINTAGE_MONTHS:
+		return client.StageOnboarding
+	case analysis.Churn.RenewalAtRisk,
+		slices.ContainsFunc(analysis.Issues, func(i client.Issue) bool { return i.Bucket == renewalBucket }),
+		isPaidTier(tier) && p.VintageMonths%12 >= 12-config.JOURNEY_RENEWAL_MONTHS:
+		return client.StageRenewalWindow
+	case p.VintageMonths < config.JOURNEY_ACTIVATION_MONTHS:
+		return client.StageActivation
+	}
+	return client.StageSteadyState
+}
+
+func isPaidTier(tier string) bool {
+	return tier == client.TierCatalog || tier == client.TierStar || tier == client.TierLeader
+}
+
+// ValidJourneyStage reports whether stage is a journey stage
+func ValidJourneyStage(stage string) bool {
+	return slices.Contains(client.JourneyStages, stage)
+}
@@ -0,0 +1,758 @@
+package profile
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/storage"
+	"im-ai-voice/internal/ulid"
+)
+
+// ==================== PROFILE UPDATE LOGIC ====================
+
+// UpdateSellerProfile updates or creates a seller profile with new call
+// analysis, returning the transitions in the seller's state the call caused
+func UpdateSellerProfile(ctx context.Context, gluserID string, analysis *client.AnalysisResult, ht *client.HackathonTranscript) (*client.SellerProfile, []client.ProfileTransition, error) {
+	// Load existing profile or create new
+	profile, err := storage.LoadSellerProfile(ctx, gluserID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load profile: %w", err)
+	}
+
+	isNew := profile == nil
+	if isNew {
+		// Create new profile
+		profile = &client.SellerProfile{
+			GluserID:       gluserID,
+			CreatedAt:      time.Now(),
+			CallHistory:    []client.CallSummary{},
+			ActiveIssues:   []client.TrackedIssue{},
+			ResolvedIssues: []client.TrackedIssue{},
+			Trends: client.SellerTrends{
+				SentimentHistory:    []client.TrendPoint{},
+				SatisfactionHistory: []client.TrendPoint{},
+				IssueHistory:        []client.TrendPoint{},
+				ChurnRiskHistory:    []client.TrendPoint{},
+			},
+			IssueStats: client.IssueStatistics{
+				SeverityBreakdown: make(map[string]int),
+				TopBuckets:        []client.BucketCount{},
+			},
+		}
+	}
+
+	previousHealth := profile.CurrentStatus.HealthScore
+	before := captureState(profile, isNew)
+
+	previousType := profile.CustomerType
+
+	// Update basic info from transcript
+	if ht != nil {
+		if !isCorrected(profile, "customer_type") {
+			profile.CustomerType = ht.CustomerType
+		}
+		if !isCorrected(profile, "city_name") {
+			profile.CityName = ht.CityName
+		}
+		if !isCorrected(profile, "vertical") {
+			profile.Vertical = ht.IILVerticalName
+		}
+		profile.VintageMonths = ht.VintageMonths
+
+		// Update seller categories
+		categories := make([]string, 0, len(ht.SellerCategories))
+		for _, cat := range ht.SellerCategories {
+			categories = append(categories, cat.McatName)
+		}
+		profile.SellerCategories = categories
+	}
+
+	// Add call to history
+	callSummary := SummarizeCall(analysis)
+	if ht != nil {
+		callSummary.Duration = ht.CallDuration
+	}
+
+	// Prepend to call history (most recent first)
+	profile.CallHistory = append([]client.CallSummary{callSummary}, profile.CallHistory...)
+	profile.TotalCalls++
+	profile.LastCallAt = analysis.Timestamp
+
+	// Process issues - track new and update existing
+	issuesResolved := processIssues(profile, analysis)
+	callSummary.IssuesResolved = issuesResolved
+	profile.CallHistory[0].IssuesResolved = issuesResolved // Update the just-added call
+
+	// Open, reopen or decay the seller's case
+	updateCase(profile, analysis, time.Now())
+
+	// Update trends
+	metric := NewSellerMetric(gluserID, analysis)
+	updateTrends(profile, metric)
+
+	// Journey stage, before the status as it sets the attention threshold
+	profile.JourneyStage = journeyStage(profile, previousType, analysis)
+	analysis.JourneyStage = profile.JourneyStage
+
+	// Recalculate current status
+	calculateCurrentStatus(profile, analysis)
+
+	// Update issue statistics
+	updateIssueStats(profile)
+
+	// Save updated profile
+	if err := storage.SaveSellerProfile(ctx, profile); err != nil {
+		return nil, nil, fmt.Errorf("failed to save profile: %w", err)
+	}
+	storage.RecordSellerMetric(ctx, metric)
+
+	storage.RecordEvent(ctx, client.Event{
+		Type:     client.EventProfileUpdated,
+		CallID:   analysis.CallID,
+		GluserID: gluserID,
+		Payload: client.ProfileUpdatedPayload{
+			PreviousHealthScore: previousHealth,
+			HealthScore:         profile.CurrentStatus.HealthScore,
+			HealthLabel:         profile.CurrentStatus.HealthLabel,
+			ChurnRisk:           profile.CurrentStatus.ChurnRisk,
+			OpenIssues:          len(profile.ActiveIssues),
+			IssuesResolved:      issuesResolved,
+			NeedsAttention:      profile.CurrentStatus.NeedsAttention,
+			TotalCalls:          profile.TotalCalls,
+		},
+	})
+
+	return profile, detectTransitions(before, profile, analysis), nil
+}
+
+// processIssues handles issue tracking - matching, updating, resolving
+func processIssues(profile *client.SellerProfile, analysis *client.AnalysisResult) int {
+	// Use the call time so replays rebuild identical issue lifecycles
+	now := analysis.Timestamp
+	if now.IsZero() {
+		now = time.Now()
+	}
+	resolvedCount := 0
+	boost := SellerValueBoost(profile.CustomerType, profile.VintageMonths)
+
+	// Track which active issues were mentioned in this call
+	mentionedIssues := make(map[string]bool)
+
+	for n, issue := range analysis.Issues {
+		severity := RaiseSeverity(issue.Severity, boost)
+		// Try to find matching existing issue
+		matchedIdx := -1
+		for i, active := range profile.ActiveIssues {
+			if isSameIssue(active, issue) {
+				matchedIdx = i
+				break
+			}
+		}
+		if matchedIdx < 0 {
+			matchedIdx = reopenIssue(profile, issue, analysis.CallID, now)
+			if matchedIdx >= 0 {
+				analysis.Issues[n].ReopenedIssueID = profile.ActiveIssues[matchedIdx].IssueID
+			}
+		}
+
+		if matchedIdx >= 0 {
+			// Update existing issue
+			existing := &profile.ActiveIssues[matchedIdx]
+			existing.LastMentionedAt = now
+			existing.MentionCount++
+			existing.CallIDs = append(existing.CallIDs, analysis.CallID)
+			existing.IsRecurring = existing.MentionCount >= 2
+			if len(existing.Channels) == 0 {
+				existing.Channels = []string{client.ChannelVoice} // Tracked before channels were, so raised on calls
+			}
+			if channel := client.ContactChannel(analysis.Channel); !slices.Contains(existing.Channels, channel) {
+				existing.Channels = append(existing.Channels, channel)
+			}
+
+			// Update severity if it increased
+			if severityLevel(severity) > severityLevel(existing.Severity) {
+				existing.Severity = severity
+				existing.BaseSeverity = baseSeverity(issue.Severity, severity)
+			}
+			if issue.Type != "" {
+				existing.Type, existing.Dispute = issue.Type, issue.Dispute
+			}
+
+			mentionedIssues[existing.IssueID] = true
+		} else {
+			// Create new tracked issue
+			newIssue := client.TrackedIssue{
+				IssueID:         ulid.NewAt(now),
+				Problem:         issue.Problem,
+				Bucket:          issue.Bucket,
+				VerticalBucket:  issue.VerticalBucket,
+				Severity:        severity,
+				BaseSeverity:    baseSeverity(issue.Severity, severity),
+				ActionRequired:  issue.ActionableSummary,
+				Type:            issue.Type,
+				Dispute:         issue.Dispute,
+				Status:          "open",
+				FirstReportedAt: now,
+				LastMentionedAt: now,
+				MentionCount:    1,
+				CallIDs:         []string{analysis.CallID},
+				Channels:        []string{client.ContactChannel(analysis.Channel)},
+				IsRecurring:     false,
+			}
+			profile.ActiveIssues = append(profile.ActiveIssues, newIssue)
+			mentionedIssues[newIssue.IssueID] = true
+		}
+	}
+
+	// Check for resolved issues (not mentioned in this call + prompt_resolution was true)
+	if analysis.Intent.PromptResolution && len(profile.ActiveIssues) > 0 {
+		var stillActive []client.TrackedIssue
+		for _, active := range profile.ActiveIssues {
+			if !mentionedIssues[active.IssueID] {
+				// Issue wasn't mentioned and call had resolution - mark as resolved
+				active.Status = "resolved"
+				active.ResolvedAt = &now
+				scheduleResolutionCheck(&active, analysis, now)
+				profile.ResolvedIssues = append(profile.ResolvedIssues, active)
+				resolvedCount++
+			} else {
+				stillActive = append(stillActive, active)
+			}
+		}
+		profile.ActiveIssues = stillActive
+	}
+
+	linkSourceTicket(profile, analysis.SourceTicket, mentionedIssues)
+	return resolvedCount
+}
+
+// reopenIssue moves the latest resolved issue matching issue back to the
+// active issues, if it was resolved within the reopen window or is still
+// being verified, and returns its index there. Under verification any issue
+// of the same bucket reopens it, failing its resolution check. The caller
+// records the mention. Returns -1 if there's no such issue.
+func reopenIssue(profile *client.SellerProfile, issue client.Issue, callID string, now time.Time) int {
+	cutoff := now.AddDate(0, 0, -issueReopenDays)
+	for i := len(profile.ResolvedIssues) - 1; i >= 0; i-- { // Most recently resolved first
+		resolved := profile.ResolvedIssues[i]
+		verifying := underVerification(resolved, issue, now)
+		if !verifying {
+			if !isSameIssue(resolved, issue) {
+				continue
+			}
+			if issueReopenDays <= 0 || resolved.ResolvedAt == nil || resolved.ResolvedAt.Before(cutoff) {
+				return -1 // Older resolutions of the bucket are older still
+			}
+		}
+
+		settleResolutionCheck(&resolved, verifying, callID, now)
+		resolved.PreviousResolvedAt = append(resolved.PreviousResolvedAt, *resolved.ResolvedAt)
+		resolved.ResolvedAt = nil
+		reopenedAt := now
+		resolved.ReopenedAt = &reopenedAt
+		resolved.ReopenCount++
+		resolved.Status = "reopened"
+		resolved.IsRecurring = true
+		profile.ResolvedIssues = append(profile.ResolvedIssues[:i], profile.ResolvedIssues[i+1:]...)
+		profile.ActiveIssues = append(profile.ActiveIssues, resolved)
+		return len(profile.ActiveIssues) - 1
+	}
+	return -1
+}
+
+// issueReopenDays is how long after resolution an issue can be reopened
+var issueReopenDays = envIssueReopenDays()
+
+func envIssueReopenDays() int {
+	if v := os.Getenv("ISSUE_REOPEN_DAYS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			return n
+		}
+		log.Printf("⚠️ Invalid ISSUE_REOPEN_DAYS=%q, using %d", v, config.DEFAULT_ISSUE_REOPEN_DAYS)
+	}
+	return config.DEFAULT_ISSUE_REOPEN_DAYS
+}
+
+// isSameIssue checks if two issues are about the same problem
+func isSameIssue(tracked client.TrackedIssue, new client.Issue) bool {
+	// Same bucket is a strong signal
+	if tracked.Bucket != new.Bucket || tracked.VerticalBucket != new.VerticalBucket {
+		return false
+	}
+
+	// Simple keyword matching - could be enhanced with embeddings
+	// For now, consider same bucket + similar severity as same issue type
+	return true // Same bucket = same general issue category
+}
+
+// severityLevel converts severity string to numeric level
+func severityLevel(sev string) int {
+	switch sev {
+	case "critical":
+		return 4
+	case "high":
+		return 3
+	case "medium":
+		return 2
+	case "low":
+		return 1
+	default:
+		return 0
+	}
+}
+
+// NewSellerMetric extracts the trend values of one call
+func NewSellerMetric(gluserID string, analysis *client.AnalysisResult) client.SellerMetric {
+	m := client.SellerMetric{
+		GluserID:       gluserID,
+		CallID:         analysis.CallID,
+		Timestamp:      analysis.Timestamp,
+		Date:           config.BusinessDate(analysis.Timestamp),
+		SentimentLabel: analysis.Intent.Sentiment,
+		Satisfaction:   float64(analysis.Intent.SatisfactionScore),
+		IssueCount:     float64(len(analysis.Issues)),
+		ChurnLabel:     analysis.Churn.IsLikelyToChurn,
+	}
+
+	switch analysis.Intent.Sentiment {
+	case "Positive":
+		m.Sentiment = 1.0
+	case "Neutral":
+		m.Sentiment = 0.5
+	case "Negative":
+		m.Sentiment = 0.0
+	}
+
+	switch analysis.Churn.IsLikelyToChurn {
+	case "high":
+		m.ChurnRisk = 1.0
+	case "medium":
+		m.ChurnRisk = 0.5
+	case "low":
+		m.ChurnRisk = 0.0
+	}
+
+	return m
+}
+
+// updateTrends updates trend data with new call
+func updateTrends(profile *client.SellerProfile, m client.SellerMetric) {
+	profile.Trends.SentimentHistory = append(profile.Trends.SentimentHistory, client.TrendPoint{
+		Date:   m.Date,
+		Value:  m.Sentiment,
+		Label:  m.SentimentLabel,
+		CallID: m.CallID,
+	})
+
+	profile.Trends.SatisfactionHistory = append(profile.Trends.SatisfactionHistory, client.TrendPoint{
+		Date:   m.Date,
+		Value:  m.Satisfaction,
+		CallID: m.CallID,
+	})
+
+	profile.Trends.IssueHistory = append(profile.Trends.IssueHistory, client.TrendPoint{
+		Date:   m.Date,
+		Value:  m.IssueCount,
+		CallID: m.CallID,
+	})
+
+	profile.Trends.ChurnRiskHistory = append(profile.Trends.ChurnRiskHistory, client.TrendPoint{
+		Date:   m.Date,
+		Value:  m.ChurnRisk,
+		Label:  m.ChurnLabel,
+		CallID: m.CallID,
+	})
+
+	// Keep the profile document bounded - full history is in seller_metrics
+	rollUpTrends(&profile.Trends, trendMaxPoints)
+
+	// Calculate trend directions
+	profile.Trends.SentimentTrend = calculateTrendDirection(profile.Trends.SentimentHistory)
+	profile.Trends.SatisfactionTrend = calculateTrendDirection(profile.Trends.SatisfactionHistory)
+
+	// For issues, declining (fewer issues) is good
+	issueTrend := calculateTrendDirection(profile.Trends.IssueHistory)
+	if issueTrend == "declining" {
+		profile.Trends.OverallTrend = "improving"
+	} else if issueTrend == "improving" {
+		profile.Trends.OverallTrend = "declining"
+	} else {
+		profile.Trends.OverallTrend = profile.Trends.SentimentTrend
+	}
+}
+
+// calculateTrendDirection determines if trend is improving, stable, or declining
+func calculateTrendDirection(points []client.TrendPoint) string {
+	if len(points) < 2 {
+		return "stable"
+	}
+
+	// Compare last 3 points (or fewer if not available)
+	n := len(points)
+	start := n - 3
+	if start < 0 {
+		start = 0
+	}
+
+	recentPoints := points[start:]
+
+	// Calculate average of first half vs second half
+	mid := len(recentPoints) / 2
+	if mid == 0 {
+		mid = 1
+	}
+
+	var firstHalf, secondHalf float64
+	for i, p := range recentPoints {
+		if i < mid {
+			firstHalf += p.Value
+		} else {
+			secondHalf += p.Value
+		}
+	}
+	firstHalf /= float64(mid)
+	secondHalf /= float64(len(recentPoints) - mid)
+
+	diff := secondHalf - firstHalf
+	if diff > 0.1 {
+		return "improving"
+	} else if diff < -0.1 {
+		return "declining"
+	}
+	return "stable"
+}
+
+// calculateCurrentStatus computes the current status for dashboard header
+func calculateCurrentStatus(profile *client.SellerProfile, analysis *client.AnalysisResult) {
+	status := &profile.CurrentStatus
+
+	// Current sentiment and satisfaction from latest call
+	status.Sentiment = analysis.Intent.Sentiment
+	status.SatisfactionScore = analysis.Intent.SatisfactionScore
+	status.ChurnRisk = analysis.Churn.IsLikelyToChurn
+	status.ChurnProbability = analysis.Churn.RenewalProbability
+	applyOverrides(profile, time.Now())
+
+	// Open issue count
+	status.OpenIssueCount = len(profile.ActiveIssues)
+
+	// Upsell potential
+	if analysis.Upsell.HasOpportunity {
+		status.UpsellPotential = analysis.Upsell.WillingnessToInvest
+	} else {
+		status.UpsellPotential = "low"
+	}
+
+	scoreHealth(profile)
+}
+
+// Health score factors' points
+const (
+	HealthBase            = 50 // Every score starts at neutral
+	healthSentimentPoints = 20 // Positive adds it, Negative takes it off
+	healthSatisfactionPer = 4  // Per satisfaction point above or below 5
+	healthChurnLowPoints  = 15
+	healthChurnHighPoints = 25
+	healthIssuePoints     = 5 // Per open issue, times its weight
+	healthIssueCap        = 30
+	healthRecurringPoints = 10 // More per recurring issue, times its weight
+	healthTrendPoints     = 10
+)
+
+// ExplainHealth breaks a profile's health score down the way scoreHealth
+// computes it: each factor's points, each open issue's penalty (heaviest
+// first) and the score before clamping to 0-100
+func ExplainHealth(profile *client.SellerProfile) ([]client.RiskFactor, []client.RiskIssue, int) {
+	status := &profile.CurrentStatus
+	score := HealthBase
+
+	// Sentiment impact (-20 to +20)
+	sentiment := client.RiskFactor{Factor: client.FactorSentiment, Value: status.Sentiment}
+	switch status.Sentiment {
+	case "Positive":
+		sentiment.Points = healthSentimentPoints
+	case "Negative":
+		sentiment.Points = -healthSentimentPoints
+	}
+	sentiment.Detail = fmt.Sprintf("Positive +%d, Negative -%d, otherwise 0", healthSentimentPoints, healthSentimentPoints)
+	if profile.SentimentOverride != nil {
+		sentiment.Detail += fmt.Sprintf("; overridden from %q", profile.SentimentOverride.ModelValue)
+	}
+
+	// Satisfaction impact (1-10 scale, normalized to -20 to +20)
+	satisfaction := client.RiskFactor{
+		Factor: client.FactorSatisfaction,
+		Value:  strconv.Itoa(status.SatisfactionScore),
+		Weight: healthSatisfactionPer,
+		Points: float64((status.SatisfactionScore - 5) * healthSatisfactionPer),
+		Detail: fmt.Sprintf("%d points per point above or below 5 on the latest call", healthSatisfactionPer),
+	}
+
+	// Churn risk impact
+	churn := client.RiskFactor{Factor: client.FactorChurnRisk, Value: status.ChurnRisk}
+	switch status.ChurnRisk {
+	case "low":
+		churn.Points = healthChurnLowPoints
+	case "high":
+		churn.Points = -healthChurnHighPoints
+	}
+	churn.Detail = fmt.Sprintf("low +%d, high -%d, otherwise 0", healthChurnLowPoints, healthChurnHighPoints)
+	if profile.ChurnOverride != nil {
+		churn.Detail += fmt.Sprintf("; overridden from %q", profile.ChurnOverride.ModelValue)
+	}
+
+	// Open issues impact (-5 per open issue, max -30), recurring issues
+	// are worse (-10 more each). Both scale with the issue's bucket weight
+	// and severity multiplier.
+	var issueImpact, recurringImpact, openWeight, recurringWeight float64
+	recurringCount := 0
+	issues := make([]client.RiskIssue, 0, len(profile.ActiveIssues))
+	for _, issue := range profile.ActiveIssues {
+		weight := healthWeights.issueWeight(issue)
+		penalty := healthIssuePoints * weight
+		issueImpact += penalty
+		openWeight += weight
+		if issue.IsRecurring {
+			recurringCount++
+			recurringImpact += healthRecurringPoints * weight
+			recurringWeight += weight
+			penalty += healthRecurringPoints * weight
+		}
+		issues = append(issues, client.RiskIssue{
+			IssueID:   issue.IssueID,
+			Bucket:    issue.Bucket,
+			Problem:   issue.Problem,
+			Severity:  issue.Severity,
+			Recurring: issue.IsRecurring,
+			Weight:    weight,
+			Points:    -penalty,
+		})
+	}
+	sort.SliceStable(issues, func(i, j int) bool { return issues[i].Points < issues[j].Points })
+	open := client.RiskFactor{
+		Factor: client.FactorOpenIssues,
+		Value:  strconv.Itoa(len(profile.ActiveIssues)),
+		Weight: openWeight,
+		Points: -math.Min(issueImpact, healthIssueCap),
+		Cap:    healthIssueCap,
+		Detail: fmt.Sprintf("-%d per open issue times its weight, at most -%d", healthIssuePoints, healthIssueCap),
+	}
+	recurring := client.RiskFactor{
+		Factor: client.FactorRecurringIssues,
+		Value:  strconv.Itoa(recurringCount),
+		Weight: recurringWeight,
+		Points: -recurringImpact,
+		Detail: fmt.Sprintf("-%d more per recurring issue times its weight, uncapped", healthRecurringPoints),
+	}
+
+	// Trend impact
+	trend := client.RiskFactor{Factor: client.FactorTrend, Value: profile.Trends.OverallTrend}
+	switch profile.Trends.OverallTrend {
+	case "improving":
+		trend.Points = healthTrendPoints
+	case "declining":
+		trend.Points = -healthTrendPoints
+	}
+	trend.Detail = fmt.Sprintf("improving +%d, declining -%d, otherwise 0", healthTrendPoints, healthTrendPoints)
+
+	// The issue penalties are rounded together
+	score += int(sentiment.Points + satisfaction.Points + churn.Points + trend.Points)
+	score -= int(math.Round(math.Min(issueImpact, healthIssueCap) + recurringImpact))
+
+	// A closed case takes back part of everything above, toward neutral
+	caseFactor := client.RiskFactor{Factor: client.FactorCaseClosed, Value: client.CaseOpen}
+	caseFactor.Detail = fmt.Sprintf("closed cases move halfway to %d every %d days", HealthBase, caseDecayHalfLifeDays())
+	if profile.Case.Closed() {
+		decay, days := caseDecay(profile)
+		caseFactor.Value = client.CaseClosed
+		caseFactor.Points = -math.Round(float64(score-HealthBase) * decay)
+		caseFactor.Detail = fmt.Sprintf("%.0f%% of the way to %d after %.0f days settled; ", decay*100, HealthBase, days) + caseFactor.Detail
+		score += int(caseFactor.Points)
+	}
+	return []client.RiskFactor{sentiment, satisfaction, churn, open, recurring, trend, caseFactor}, issues, score
+}
+
+// scoreHealth computes the health score, label and attention flag from the
+// current status, active issues, trend and case. A closed case's churn risk
+// and trend are what it settled on, so they don't flag it for attention.
+func scoreHealth(profile *client.SellerProfile) {
+	status := &profile.CurrentStatus
+
+	_, issues, score := ExplainHealth(profile)
+	recurringCount := 0
+	for _, issue := range issues {
+		if issue.Recurring {
+			recurringCount++
+		}
+	}
+
+	// Clamp score
+	if score < 0 {
+		score = 0
+	}
+	if score > 100 {
+		score = 100
+	}
+	status.HealthScore = score
+
+	// Health label
+	if score >= 70 {
+		status.HealthLabel = "Healthy"
+	} else if score >= 40 {
+		status.HealthLabel = "At Risk"
+	} else {
+		status.HealthLabel = "Critical"
+	}
+
+	// Needs attention flag
+	status.NeedsAttention = false
+	status.AttentionReason = ""
+
+	if status.HealthScore < 40 {
+		status.NeedsAttention = true
+		status.AttentionReason = "Critical health score"
+	} else if threshold := AttentionHealthFor(profile.JourneyStage); status.HealthScore < threshold {
+		status.NeedsAttention = true
+		status.AttentionReason = fmt.Sprintf("Health score below %d in %s stage", threshold, profile.JourneyStage)
+	} else if status.ChurnRisk == "high" && !profile.Case.Closed() {
+		status.NeedsAttention = true
+		status.AttentionReason = "High churn risk"
+	} else if recurringCount > 0 {
+		status.NeedsAttention = true
+		status.AttentionReason = fmt.Sprintf("%d recurring unresolved issues", recurringCount)
+	} else if profile.Trends.OverallTrend == "declining" && !profile.Case.Closed() {
+		status.NeedsAttention = true
+		status.AttentionReason = "Declining trend detected"
+	}
+}
+
+// updateIssueStats recalculates issue statistics
+func updateIssueStats(profile *client.SellerProfile) {
+	stats := &profile.IssueStats
+
+	stats.TotalIssuesEver = len(profile.ActiveIssues) + len(profile.ResolvedIssues)
+	stats.CurrentOpenCount = len(profile.ActiveIssues)
+	stats.ResolvedCount = len(profile.ResolvedIssues)
+
+	// Count recurring
+	stats.RecurringCount = 0
+	for _, issue := range profile.ActiveIssues {
+		if issue.IsRecurring {
+			stats.RecurringCount++
+		}
+	}
+
+	// Reopen rate, overall and by bucket, over issues resolved at least once
+	stats.ReopenedCount, stats.ReopenRate = 0, 0
+	stats.BucketReopens = nil
+	reopens := make(map[string]*client.BucketReopen)
+	var everResolved int
+	for _, issues := range [][]client.TrackedIssue{profile.ActiveIssues, profile.ResolvedIssues} {
+		for _, issue := range issues {
+			if issue.ResolvedAt == nil && issue.ReopenCount == 0 {
+				continue
+			}
+			br := reopens[issue.Bucket]
+			if br == nil {
+				br = &client.BucketReopen{Bucket: issue.Bucket}
+				reopens[issue.Bucket] = br
+			}
+			br.Resolved++
+			everResolved++
+			if issue.ReopenCount > 0 {
+				br.Reopened++
+				stats.ReopenedCount++
+			}
+		}
+	}
+	if everResolved > 0 {
+		stats.ReopenRate = math.Round(float64(stats.ReopenedCount)/float64(everResolved)*1000) / 1000
+	}
+	for _, br := range reopens {
+		br.Rate = math.Round(float64(br.Reopened)/float64(br.Resolved)*1000) / 1000
+		stats.BucketReopens = append(stats.BucketReopens, *br)
+	}
+	sort.Slice(stats.BucketReopens, func(i, j int) bool {
+		if stats.BucketReopens[i].Rate != stats.BucketReopens[j].Rate {
+			return stats.BucketReopens[i].Rate > stats.BucketReopens[j].Rate
+		}
+		return stats.BucketReopens[i].Bucket < stats.BucketReopens[j].Bucket
+	})
+
+	// Calculate avg resolution time
+	if len(profile.ResolvedIssues) > 0 {
+		var totalDays float64
+		for _, issue := range profile.ResolvedIssues {
+			if issue.ResolvedAt != nil {
+				days := issue.ResolvedAt.Sub(issue.FirstReportedAt).Hours() / 24
+				totalDays += days
+			}
+		}
+		stats.AvgResolutionDays = totalDays / float64(len(profile.ResolvedIssues))
+	}
+
+	// Count by bucket
+	bucketCounts := make(map[string]int)
+	for _, issue := range profile.ActiveIssues {
+		bucketCounts[issue.Bucket]++
+	}
+	for _, issue := range profile.ResolvedIssues {
+		bucketCounts[issue.Bucket]++
+	}
+
+	// Sort buckets by count
+	stats.TopBuckets = []client.BucketCount{}
+	for bucket, count := range bucketCounts {
+		stats.TopBuckets = append(stats.TopBuckets, client.BucketCount{Bucket: bucket, Count: count})
+	}
+	sort.Slice(stats.TopBuckets, func(i, j int) bool {
+		return stats.TopBuckets[i].Count > stats.TopBuckets[j].Count
+	})
+	// Keep top 5
+	if len(stats.TopBuckets) > 5 {
+		stats.TopBuckets = stats.TopBuckets[:5]
+	}
+
+	// Severity breakdown
+	stats.SeverityBreakdown = make(map[string]int)
+	for _, issue := range profile.ActiveIssues {
+		stats.SeverityBreakdown[issue.Severity]++
+	}
+}
+
+// SummarizeCall is an analysis's entry in a call history, without the
+// duration, which comes from the transcript
+func SummarizeCall(analysis *client.AnalysisResult) client.CallSummary {
+	summary := client.CallSummary{
+		CallID:           analysis.CallID,
+		Timestamp:        analysis.Timestamp,
+		Summary:          analysis.CallSummary,
+		Sentiment:        analysis.Intent.Sentiment,
+		IssuesRaised:     len(analysis.Issues),
+		AgentPerformance: analysis.AgentPerformance,
+		Channel:          analysis.Channel,
+	}
+	if analysis.Call != nil {
+		summary.Direction = analysis.Call.Direction
+	}
+
+	// Check for escalation and follow-up from LLMRaw
+	if analysis.LLMRaw != nil {
+		if esc, ok := analysis.LLMRaw["escalation_required"].(bool); ok {
+			summary.WasEscalated = esc
+		}
+		if fu, ok := analysis.LLMRaw["follow_up_needed"].(bool); ok {
+			summary.FollowUpNeeded = fu
+		}
+	}
+	return summary
+}
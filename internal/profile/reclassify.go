@@ -0,0 +1,22 @@
+package profile
+
+import "im-ai-voice/client"
+
+// ReclassifyIssues calls rebucket with each of a profile's tracked issues,
+// active and resolved, to move it to a new bucket, reporting whether it
+// did. The issue stats are recalculated when any moved. It returns how many
+// issues moved.
+func ReclassifyIssues(profile *client.SellerProfile, rebucket func(issue *client.TrackedIssue) bool) int {
+	moved := 0
+	for _, issues := range [][]client.TrackedIssue{profile.ActiveIssues, profile.ResolvedIssues} {
+		for i := range issues {
+			if rebucket(&issues[i]) {
+				moved++
+			}
+		}
+	}
+	if moved > 0 {
+		updateIssueStats(profile)
+	}
+	return moved
+}
@@ -0,0 +1,168 @@
+package profile
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== TICKET RECONCILIATION ====================
+// Transcripts carry the ticket IndiaMART's own ticketing logged the call
+// against. Each TrackedIssue keeps the tickets of the calls that mentioned
+// it, with their status as of the latest call carrying them, and the
+// reconciliation report lists issues whose state disagrees with their
+// ticket's: open here but closed in the source, or resolved here but still
+// open there.
+
+// Reconciliation gap kinds
+const (
+	GapClosedInSource = "closed_in_source" // Open here, its ticket is closed in the source
+	GapOpenInSource   = "open_in_source"   // Resolved here, its ticket is still open in the source
+)
+
+// linkSourceTicket adds a call's source ticket to the issues it mentioned,
+// and refreshes the status of that ticket on every other issue it's linked to
+func linkSourceTicket(profile *client.SellerProfile, t *client.SourceTicket, mentioned map[string]bool) {
+	if t == nil {
+		return
+	}
+	link := func(issues []client.TrackedIssue) {
+		for i := range issues {
+			issue := &issues[i]
+			found := false
+			for j := range issue.SourceTickets {
+				st := &issue.SourceTickets[j]
+				if st.TicketID != t.TicketID {
+					continue
+				}
+				found = true
+				if !t.UpdatedAt.Before(st.UpdatedAt) {
+					*st = *t
+				}
+			}
+			if !found && mentioned[issue.IssueID] {
+				issue.SourceTickets = append(issue.SourceTickets, *t)
+			}
+		}
+	}
+	link(profile.ActiveIssues)
+	link(profile.ResolvedIssues)
+}
+
+// latestSourceTicket is the issue's most recently reported source ticket
+func latestSourceTicket(issue client.TrackedIssue) *client.SourceTicket {
+	var latest *client.SourceTicket
+	for i := range issue.SourceTickets {
+		if latest == nil || issue.SourceTickets[i].UpdatedAt.After(latest.UpdatedAt) {
+			latest = &issue.SourceTickets[i]
+		}
+	}
+	return latest
+}
+
+// ReconciliationGap is an issue whose state disagrees with its source ticket's
+type ReconciliationGap struct {
+	Kind            string              `json:"kind"` // closed_in_source or open_in_source
+	GluserID        string              `json:"gluser_id"`
+	IssueID         string              `json:"issue_id"`
+	Bucket          string              `json:"bucket"`
+	Problem         string              `json:"problem"`
+	Severity        string              `json:"severity"`
+	Status          string              `json:"status"`
+	LastMentionedAt time.Time           `json:"last_mentioned_at"`
+	SourceTicket    client.SourceTicket `json:"source_ticket"`
+}
+
+// TicketReconciliation is the response for GET /analytics/ticket-reconciliation
+type TicketReconciliation struct {
+	GeneratedAt    time.Time           `json:"generated_at"`
+	Issues         int                 `json:"issues"`        // Tracked issues, active and resolved
+	Linked         int                 `json:"linked"`        // With a source ticket
+	UnlinkedOpen   int                 `json:"unlinked_open"` // Active issues without one
+	Agreed         int                 `json:"agreed"`        // Linked issues in the same state as their ticket
+	ClosedInSource int                 `json:"closed_in_source"`
+	OpenInSource   int                 `json:"open_in_source"`
+	UnknownStatus  int                 `json:"unknown_status"` // Linked issues whose ticket status wasn't recognized
+	GapsByBucket   map[string]int      `json:"gaps_by_bucket"`
+	Gaps           []ReconciliationGap `json:"gaps"` // Matching the filters, most recently mentioned first, up to limit
+}
+
+// BuildTicketReconciliation compares every tracked issue with its latest
+// source ticket. kind and bucket filter the gaps listed, not the counts;
+// stage limits everything to sellers now in that journey stage.
+func BuildTicketReconciliation(ctx context.Context, kind, bucket, stage string, limit int) (*TicketReconciliation, error) {
+	profiles, err := storage.LoadAllSellerProfiles(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load seller profiles: %w", err)
+	}
+
+	report := &TicketReconciliation{
+		GeneratedAt:  time.Now(),
+		GapsByBucket: make(map[string]int),
+		Gaps:         []ReconciliationGap{},
+	}
+	var gaps []ReconciliationGap
+	check := func(p *client.SellerProfile, issue client.TrackedIssue, resolved bool) {
+		report.Issues++
+		t := latestSourceTicket(issue)
+		if t == nil {
+			if !resolved {
+				report.UnlinkedOpen++
+			}
+			return
+		}
+		report.Linked++
+
+		gap := ""
+		switch {
+		case t.Status == "":
+			report.UnknownStatus++
+			return
+		case !resolved && t.Status == client.SourceTicketClosed:
+			gap = GapClosedInSource
+			report.ClosedInSource++
+		case resolved && t.Status == client.SourceTicketOpen:
+			gap = GapOpenInSource
+			report.OpenInSource++
+		default:
+			report.Agreed++
+			return
+		}
+		report.GapsByBucket[issue.Bucket]++
+		if (kind == "" || kind == gap) && (bucket == "" || bucket == issue.Bucket) {
+			gaps = append(gaps, ReconciliationGap{
+				Kind:            gap,
+				GluserID:        p.GluserID,
+				IssueID:         issue.IssueID,
+				Bucket:          issue.Bucket,
+				Problem:         issue.Problem,
+				Severity:        issue.Severity,
+				Status:          issue.Status,
+				LastMentionedAt: issue.LastMentionedAt,
+				SourceTicket:    *t,
+			})
+		}
+	}
+	for _, p := range profiles {
+		if stage != "" && p.JourneyStage != stage {
+			continue
+		}
+		for _, issue := range p.ActiveIssues {
+			check(p, issue, false)
+		}
+		for _, issue := range p.ResolvedIssues {
+			check(p, issue, true)
+		}
+	}
+
+	sort.Slice(gaps, func(i, j int) bool { return gaps[i].LastMentionedAt.After(gaps[j].LastMentionedAt) })
+	if len(gaps) > limit {
+		gaps = gaps[:limit]
+	}
+	report.Gaps = append(report.Gaps, gaps...)
+	return report, nil
+}
@@ -0,0 +1,233 @@
+package profile
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== RESOLUTION VERIFICATION ====================
+// A resolved issue gets a checkpoint RESOLUTION_VERIFY_DAYS out. If the
+// seller raises the same bucket again before it, the issue is reopened and
+// the resolution flagged as not durable; once it passes quietly, the
+// resolution_verification job marks it durable. The durability report rates
+// resolutions per bucket and per the agent whose call resolved them.
+
+// resolutionVerifyDays is how long a resolution has to hold
+var resolutionVerifyDays = envResolutionVerifyDays()
+
+func envResolutionVerifyDays() int {
+	if v := os.Getenv("RESOLUTION_VERIFY_DAYS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			return n
+		}
+		log.Printf("⚠️ Invalid RESOLUTION_VERIFY_DAYS=%q, using %d", v, config.DEFAULT_RESOLUTION_VERIFY_DAYS)
+	}
+	return config.DEFAULT_RESOLUTION_VERIFY_DAYS
+}
+
+// ResolutionVerifyDays returns the verification window in days, 0 when
+// resolutions aren't verified
+func ResolutionVerifyDays() int {
+	return resolutionVerifyDays
+}
+
+// scheduleResolutionCheck adds a pending check to an issue the analysis's
+// call just resolved
+func scheduleResolutionCheck(issue *client.TrackedIssue, analysis *client.AnalysisResult, now time.Time) {
+	if resolutionVerifyDays <= 0 {
+		return
+	}
+	issue.ResolutionChecks = append(issue.ResolutionChecks, client.ResolutionCheck{
+		ResolvedAt: now,
+		CallID:     analysis.CallID,
+		AgentID:    analysis.AgentID,
+		DueAt:      now.AddDate(0, 0, resolutionVerifyDays),
+		Status:     client.ResolutionPending,
+	})
+}
+
+// pendingCheck returns the issue's pending resolution check, nil without one
+func pendingCheck(issue *client.TrackedIssue) *client.ResolutionCheck {
+	if n := len(issue.ResolutionChecks); n > 0 && issue.ResolutionChecks[n-1].Status == client.ResolutionPending {
+		return &issue.ResolutionChecks[n-1]
+	}
+	return nil
+}
+
+// underVerification reports whether raising issue now fails resolved's
+// pending check: the same bucket, before the check is due
+func underVerification(resolved client.TrackedIssue, issue client.Issue, now time.Time) bool {
+	check := pendingCheck(&resolved)
+	return check != nil && resolved.ResolvedAt != nil && resolved.Bucket == issue.Bucket && now.Before(check.DueAt)
+}
+
+// settleResolutionCheck settles the pending check of an issue being
+// reopened by callID: not durable while it was being verified, durable if
+// its window had passed before the job got to it
+func settleResolutionCheck(issue *client.TrackedIssue, verifying bool, callID string, now time.Time) {
+	check := pendingCheck(issue)
+	if check == nil {
+		return
+	}
+	checkedAt := now
+	check.CheckedAt = &checkedAt
+	if !verifying {
+		check.Status = client.ResolutionDurable
+		return
+	}
+	check.Status = client.ResolutionNotDurable
+	check.ReopenCallID = callID
+	issue.ResolutionNotDurable = true
+	log.Printf("   🔁 %s resolution of issue %s didn't hold: raised again on call %s, %d days in",
+		issue.Bucket, issue.IssueID, callID, int(now.Sub(check.ResolvedAt).Hours()/24))
+}
+
+// RunResolutionVerification marks pending resolution checks past their due
+// date durable, returning how many it marked
+func RunResolutionVerification(ctx context.Context) (int, error) {
+	profiles, err := storage.LoadAllSellerProfiles(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load seller profiles: %w", err)
+	}
+
+	now := time.Now()
+	verified := 0
+	for _, p := range profiles {
+		if ctx.Err() != nil {
+			return verified, ctx.Err()
+		}
+
+		changed := false
+		for i := range p.ResolvedIssues {
+			check := pendingCheck(&p.ResolvedIssues[i])
+			if check == nil || now.Before(check.DueAt) {
+				continue
+			}
+			checkedAt := now
+			check.Status, check.CheckedAt = client.ResolutionDurable, &checkedAt
+			changed = true
+			verified++
+		}
+
+		if changed {
+			if err := storage.SaveSellerProfile(ctx, p); err != nil {
+				log.Printf("⚠️ Failed to save verified profile %s: %v", p.GluserID, err)
+			}
+		}
+	}
+
+	if verified > 0 {
+		log.Printf("✅ Verified %d durable resolutions (held %d days)", verified, resolutionVerifyDays)
+	}
+	return verified, nil
+}
+
+// BuildResolutionDurabilityReport rates the resolutions made in [from, to)
+// (zero times leave that side open) by whether they held, overall, per
+// bucket and per agent. Checks past due that the job hasn't reached yet
+// count as durable.
+func BuildResolutionDurabilityReport(ctx context.Context, from, to time.Time) (*client.ResolutionDurabilityReport, error) {
+	profiles, err := storage.LoadAllSellerProfiles(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load seller profiles: %w", err)
+	}
+
+	now := time.Now()
+	report := &client.ResolutionDurabilityReport{
+		WindowDays:  resolutionVerifyDays,
+		Buckets:     []client.DurabilitySummary{},
+		Agents:      []client.DurabilitySummary{},
+		GeneratedAt: now,
+	}
+	if !from.IsZero() {
+		report.From = config.BusinessDate(from)
+	}
+	if !to.IsZero() {
+		report.To = config.BusinessDate(to.Add(-time.Nanosecond))
+	}
+
+	total := &client.DurabilitySummary{}
+	buckets := make(map[string]*client.DurabilitySummary)
+	agents := make(map[string]*client.DurabilitySummary)
+	tally := func(m map[string]*client.DurabilitySummary, key, status string) {
+		s := m[key]
+		if s == nil {
+			s = &client.DurabilitySummary{Key: key}
+			m[key] = s
+		}
+		countDurability(s, status)
+	}
+	for _, p := range profiles {
+		for _, issues := range [][]client.TrackedIssue{p.ActiveIssues, p.ResolvedIssues} {
+			for _, issue := range issues {
+				for _, check := range issue.ResolutionChecks {
+					if (!from.IsZero() && check.ResolvedAt.Before(from)) || (!to.IsZero() && !check.ResolvedAt.Before(to)) {
+						continue
+					}
+					status := check.Status
+					if status == client.ResolutionPending && !now.Before(check.DueAt) {
+						status = client.ResolutionDurable
+					}
+					countDurability(total, status)
+					tally(buckets, issue.Bucket, status)
+					if check.AgentID != "" {
+						tally(agents, check.AgentID, status)
+					}
+				}
+			}
+		}
+	}
+
+	report.Resolutions, report.Pending = total.Resolutions, total.Pending
+	report.Durable, report.NotDurable = total.Durable, total.NotDurable
+	report.DurableRate = durableRate(total)
+	report.Buckets = durabilitySummaries(buckets)
+	report.Agents = durabilitySummaries(agents)
+	return report, nil
+}
+
+func countDurability(s *client.DurabilitySummary, status string) {
+	s.Resolutions++
+	switch status {
+	case client.ResolutionDurable:
+		s.Durable++
+	case client.ResolutionNotDurable:
+		s.NotDurable++
+	default:
+		s.Pending++
+	}
+}
+
+func durableRate(s *client.DurabilitySummary) float64 {
+	if verified := s.Durable + s.NotDurable; verified > 0 {
+		return math.Round(float64(s.Durable)/float64(verified)*1000) / 1000
+	}
+	return 0
+}
+
+// durabilitySummaries lists the summaries with their rates, most
+// resolutions first
+func durabilitySummaries(m map[string]*client.DurabilitySummary) []client.DurabilitySummary {
+	out := make([]client.DurabilitySummary, 0, len(m))
+	for _, s := range m {
+		s.DurableRate = durableRate(s)
+		out = append(out, *s)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Resolutions != out[j].Resolutions {
+			return out[i].Resolutions > out[j].Resolutions
+		}
+		return out[i].Key < out[j].Key
+	})
+	return out
+}
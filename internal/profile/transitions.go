@@ -0,0 +1,81 @@
+package profile
+
+import (
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/ulid"
+)
+
+// ==================== PROFILE TRANSITIONS ====================
+// The seller state changes a call caused, for notify.NotifyTransitions.
+// They're found by comparing the profile before and after the call.
+
+// profileState is what transitions are detected from, captured before a call updates the profile
+type profileState struct {
+	isNew       bool
+	healthLabel string
+	churnRisk   string
+	active      map[string]bool // Active issue IDs
+}
+
+func captureState(p *client.SellerProfile, isNew bool) profileState {
+	st := profileState{
+		isNew:       isNew,
+		healthLabel: p.CurrentStatus.HealthLabel,
+		churnRisk:   p.CurrentStatus.ChurnRisk,
+		active:      make(map[string]bool, len(p.ActiveIssues)),
+	}
+	for _, issue := range p.ActiveIssues {
+		st.active[issue.IssueID] = true
+	}
+	return st
+}
+
+// detectTransitions returns the transitions between before and p, as of the call analysis
+func detectTransitions(before profileState, p *client.SellerProfile, analysis *client.AnalysisResult) []client.ProfileTransition {
+	at := analysis.Timestamp
+	if at.IsZero() {
+		at = time.Now()
+	}
+	s := p.CurrentStatus
+	newTransition := func(typ string) client.ProfileTransition {
+		return client.ProfileTransition{
+			ID:          ulid.New(),
+			Type:        typ,
+			GluserID:    p.GluserID,
+			CallID:      analysis.CallID,
+			HealthScore: s.HealthScore,
+			HealthLabel: s.HealthLabel,
+			ChurnRisk:   s.ChurnRisk,
+			OccurredAt:  at,
+		}
+	}
+
+	var transitions []client.ProfileTransition
+	if before.isNew {
+		transitions = append(transitions, newTransition(client.TransitionNewSeller))
+	} else {
+		if before.healthLabel != "" && before.healthLabel != s.HealthLabel {
+			t := newTransition(client.TransitionHealthLabelChanged)
+			t.From, t.To = before.healthLabel, s.HealthLabel
+			transitions = append(transitions, t)
+		}
+		if before.churnRisk != "" && severityLevel(s.ChurnRisk) > severityLevel(before.churnRisk) {
+			t := newTransition(client.TransitionChurnRiskEscalated)
+			t.From, t.To = before.churnRisk, s.ChurnRisk
+			transitions = append(transitions, t)
+		}
+	}
+
+	// Issues only leave the active ones when resolved, so resolved issues
+	// that were active are the ones this call resolved
+	for _, issue := range p.ResolvedIssues {
+		if before.active[issue.IssueID] {
+			t := newTransition(client.TransitionIssueResolved)
+			t.IssueID, t.Bucket, t.Problem = issue.IssueID, issue.Bucket, issue.Problem
+			transitions = append(transitions, t)
+		}
+	}
+	return transitions
+}
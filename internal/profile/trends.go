@@ -0,0 +1,152 @@
+package profile
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+)
+
+// ==================== TREND ROLLUPS ====================
+// Profiles keep the latest trendMaxPoints calls as individual trend points.
+// Older calls are averaged into one point per day (Count = calls in the day),
+// so a chatty seller's profile grows by at most one point per day instead of
+// one per call. Per-call values stay available from storage.LoadSellerMetrics.
+
+var trendMaxPoints = envTrendMaxPoints()
+
+// envTrendMaxPoints reads TREND_MAX_POINTS. The trend direction looks at the
+// last 3 calls, so fewer than that isn't allowed.
+func envTrendMaxPoints() int {
+	if v := os.Getenv("TREND_MAX_POINTS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 3 {
+			return n
+		}
+	}
+	return config.DEFAULT_TREND_MAX_POINTS
+}
+
+// rollUpTrends rolls up every trend history of the profile
+func rollUpTrends(trends *client.SellerTrends, maxPoints int) {
+	trends.SentimentHistory = rollUpTrendPoints(trends.SentimentHistory, maxPoints)
+	trends.SatisfactionHistory = rollUpTrendPoints(trends.SatisfactionHistory, maxPoints)
+	trends.IssueHistory = rollUpTrendPoints(trends.IssueHistory, maxPoints)
+	trends.ChurnRiskHistory = rollUpTrendPoints(trends.ChurnRiskHistory, maxPoints)
+}
+
+// rollUpTrendPoints folds per-call points beyond the newest maxPoints into
+// daily rollups. Histories are oldest first: rollups, then per-call points.
+func rollUpTrendPoints(points []client.TrendPoint, maxPoints int) []client.TrendPoint {
+	split := 0
+	for split < len(points) && points[split].Count > 0 {
+		split++
+	}
+	overflow := len(points) - split - maxPoints
+	if overflow <= 0 {
+		return points
+	}
+
+	out := make([]client.TrendPoint, split, len(points)-overflow+1)
+	copy(out, points[:split])
+	for _, p := range points[split : split+overflow] {
+		if n := len(out); n > 0 && out[n-1].Date == p.Date {
+			out[n-1] = mergeTrendPoints(out[n-1], p)
+			continue
+		}
+		out = append(out, client.TrendPoint{Date: p.Date, Value: p.Value, Count: 1})
+	}
+	return append(out, points[split+overflow:]...)
+}
+
+// mergeTrendPoints adds a per-call point to a rollup, keeping the average
+func mergeTrendPoints(rollup, p client.TrendPoint) client.TrendPoint {
+	total := rollup.Value*float64(rollup.Count) + p.Value
+	rollup.Count++
+	rollup.Value = total / float64(rollup.Count)
+	return rollup
+}
+
+// ==================== TREND SERIES ====================
+
+// BuildTrendSeries turns a seller's metrics (oldest first) into trend
+// histories at the requested granularity. Buckets follow the call's business
+// date (BUSINESS_TIMEZONE), the same one the profile's trend points use.
+// Metrics recorded before the timezone was configurable are re-dated from
+// their timestamp.
+func BuildTrendSeries(gluserID string, metrics []client.SellerMetric, granularity string) (*client.TrendSeries, error) {
+	for i := range metrics {
+		if !metrics[i].Timestamp.IsZero() {
+			metrics[i].Date = config.BusinessDate(metrics[i].Timestamp)
+		}
+	}
+
+	var bucketOf func(m client.SellerMetric) string
+	switch granularity {
+	case client.GranularityCall:
+	case client.GranularityDay:
+		bucketOf = func(m client.SellerMetric) string { return m.Date }
+	case client.GranularityWeek:
+		bucketOf = func(m client.SellerMetric) string { return weekStart(m.Date) }
+	case client.GranularityMonth:
+		bucketOf = func(m client.SellerMetric) string { return monthStart(m.Date) }
+	default:
+		return nil, fmt.Errorf("granularity must be %s, %s, %s or %s", client.GranularityCall, client.GranularityDay, client.GranularityWeek, client.GranularityMonth)
+	}
+
+	series := &client.TrendSeries{
+		GluserID:            gluserID,
+		Granularity:         granularity,
+		Calls:               len(metrics),
+		SentimentHistory:    []client.TrendPoint{},
+		SatisfactionHistory: []client.TrendPoint{},
+		IssueHistory:        []client.TrendPoint{},
+		ChurnRiskHistory:    []client.TrendPoint{},
+	}
+
+	if bucketOf == nil {
+		for _, m := range metrics {
+			series.SentimentHistory = append(series.SentimentHistory, client.TrendPoint{Date: m.Date, Value: m.Sentiment, Label: m.SentimentLabel, CallID: m.CallID})
+			series.SatisfactionHistory = append(series.SatisfactionHistory, client.TrendPoint{Date: m.Date, Value: m.Satisfaction, CallID: m.CallID})
+			series.IssueHistory = append(series.IssueHistory, client.TrendPoint{Date: m.Date, Value: m.IssueCount, CallID: m.CallID})
+			series.ChurnRiskHistory = append(series.ChurnRiskHistory, client.TrendPoint{Date: m.Date, Value: m.ChurnRisk, Label: m.ChurnLabel, CallID: m.CallID})
+		}
+		return series, nil
+	}
+
+	for _, m := range metrics {
+		bucket := bucketOf(m)
+		if n := len(series.SentimentHistory); n == 0 || series.SentimentHistory[n-1].Date != bucket {
+			series.SentimentHistory = append(series.SentimentHistory, client.TrendPoint{Date: bucket})
+			series.SatisfactionHistory = append(series.SatisfactionHistory, client.TrendPoint{Date: bucket})
+			series.IssueHistory = append(series.IssueHistory, client.TrendPoint{Date: bucket})
+			series.ChurnRiskHistory = append(series.ChurnRiskHistory, client.TrendPoint{Date: bucket})
+		}
+		n := len(series.SentimentHistory) - 1
+		series.SentimentHistory[n] = mergeTrendPoints(series.SentimentHistory[n], client.TrendPoint{Value: m.Sentiment})
+		series.SatisfactionHistory[n] = mergeTrendPoints(series.SatisfactionHistory[n], client.TrendPoint{Value: m.Satisfaction})
+		series.IssueHistory[n] = mergeTrendPoints(series.IssueHistory[n], client.TrendPoint{Value: m.IssueCount})
+		series.ChurnRiskHistory[n] = mergeTrendPoints(series.ChurnRiskHistory[n], client.TrendPoint{Value: m.ChurnRisk})
+	}
+	return series, nil
+}
+
+// weekStart returns the Monday of the date's week as YYYY-MM-DD
+func weekStart(date string) string {
+	t, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return date
+	}
+	offset := (int(t.Weekday()) + 6) % 7
+	return t.AddDate(0, 0, -offset).Format("2006-01-02")
+}
+
+// monthStart returns the first day of the date's month as YYYY-MM-DD
+func monthStart(date string) string {
+	if len(date) < len("2006-01") {
+		return date
+	}
+	return date[:len("2006-01")] + "-01"
+}
@@ -0,0 +1,83 @@
+package profile
+
+import (
+	"log"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ==================== SELLER VALUE SEVERITY ====================
+// The same problem matters more to a paying seller than to a free one, so
+// issue severity can be raised by the seller's value: levels added per
+// customer type, and a level more for long-standing sellers:
+//
+//	SELLER_VALUE_TIERS="LEADER=2,Maximiser=2,STAR=1,BL Paid FCP=1"
+//	SELLER_VALUE_VINTAGE_MONTHS="36"
+//
+// Customer types match the profile's case-insensitively. Severity stops at
+// critical. Tracked issues are raised when a call reports them, tickets when
+// a date is aggregated; neither is raised without these set.
+
+// SellerValueWeights are the severity levels added for a seller's value
+type SellerValueWeights struct {
+	CustomerTypes map[string]int `json:"customer_types"` // Keyed by upper-cased customer type
+	VintageMonths int            `json:"vintage_months"` // Months on IndiaMART that add a level, 0 for none
+}
+
+var sellerValue = loadSellerValue()
+
+func loadSellerValue() SellerValueWeights {
+	w := SellerValueWeights{CustomerTypes: make(map[string]int)}
+	for name, levels := range parseWeights("SELLER_VALUE_TIERS", os.Getenv("SELLER_VALUE_TIERS")) {
+		if levels != math.Trunc(levels) {
+			log.Printf("⚠️ Ignoring SELLER_VALUE_TIERS entry %q: levels must be a whole number", name)
+			continue
+		}
+		w.CustomerTypes[strings.ToUpper(name)] = int(levels)
+	}
+	if v := os.Getenv("SELLER_VALUE_VINTAGE_MONTHS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			log.Printf("⚠️ Ignoring invalid SELLER_VALUE_VINTAGE_MONTHS=%q", v)
+		} else {
+			w.VintageMonths = n
+		}
+	}
+	return w
+}
+
+// SellerValueEnabled reports whether seller value raises severities
+func SellerValueEnabled() bool {
+	return len(sellerValue.CustomerTypes) > 0 || sellerValue.VintageMonths > 0
+}
+
+// SellerValueBoost is the number of severity levels a seller's issues are raised by
+func SellerValueBoost(customerType string, vintageMonths int) int {
+	boost := sellerValue.CustomerTypes[strings.ToUpper(strings.TrimSpace(customerType))]
+	if sellerValue.VintageMonths > 0 && vintageMonths >= sellerValue.VintageMonths {
+		boost++
+	}
+	return boost
+}
+
+// RaiseSeverity raises a severity by levels, stopping at critical. Unknown
+// severities are left alone.
+func RaiseSeverity(sev string, levels int) string {
+	if severityLevel(sev) == 0 {
+		return sev
+	}
+	for ; levels > 0 && sev != "critical"; levels-- {
+		sev = escalateSeverity(sev)
+	}
+	return sev
+}
+
+// baseSeverity is the analysis's severity when seller value raised it, empty otherwise
+func baseSeverity(reported, raised string) string {
+	if reported == raised {
+		return ""
+	}
+	return reported
+}
@@ -0,0 +1,129 @@
+package ratelimit
+
+import (
+	"context"
+	"log"
+	"math/rand/v2"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== SHARED RATE LIMIT ====================
+// Paces Gemini requests to GEMINI_RATE_LIMIT a minute across every instance
+// sharing MongoDB, through a token bucket in the rate_limits collection.
+// Without MongoDB, or while it's unreachable, each instance paces itself to
+// the whole limit with a bucket in memory, as if it were alone.
+
+const bucketName = "gemini"
+
+// Limiter paces requests to a rate shared by every instance. A nil Limiter
+// doesn't limit.
+type Limiter struct {
+	rate  float64 // Tokens a second
+	burst float64
+	local localBucket
+
+	mu       sync.Mutex
+	degraded bool // Last shared take failed, so the local bucket is in use
+}
+
+// FromEnv returns the limiter GEMINI_RATE_LIMIT and GEMINI_RATE_BURST
+// configure, nil when there's no limit
+func FromEnv() *Limiter {
+	perMinute := config.DEFAULT_GEMINI_RATE_LIMIT
+	if v := os.Getenv("GEMINI_RATE_LIMIT"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			perMinute = n
+		} else {
+			log.Printf("⚠️ Invalid GEMINI_RATE_LIMIT=%q, using %d", v, perMinute)
+		}
+	}
+	if perMinute == 0 {
+		return nil
+	}
+
+	rate := float64(perMinute) / 60
+	burst := max(1, rate) // A second's worth
+	if v := os.Getenv("GEMINI_RATE_BURST"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			burst = float64(n)
+		} else {
+			log.Printf("⚠️ Invalid GEMINI_RATE_BURST=%q, using %g", v, burst)
+		}
+	}
+	log.Printf("🚦 Gemini requests limited to %d a minute (burst %g) across instances", perMinute, burst)
+	return &Limiter{rate: rate, burst: burst, local: localBucket{tokens: burst}}
+}
+
+// Wait blocks until a request may be sent, or ctx is done
+func (l *Limiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	for {
+		wait := l.take(ctx)
+		if wait == 0 {
+			return nil
+		}
+		wait += time.Duration(rand.Int64N(int64(wait)/10 + 1)) // So instances woken together don't collide again
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// take takes a token from the shared bucket, or the local one when that fails
+func (l *Limiter) take(ctx context.Context) time.Duration {
+	if storage.IsMongoEnabled() {
+		wait, err := storage.TakeToken(ctx, bucketName, l.rate, l.burst)
+		l.setDegraded(err)
+		if err == nil {
+			return wait
+		}
+	}
+	return l.local.take(l.rate, l.burst)
+}
+
+// setDegraded logs when the shared bucket stops and starts working again
+func (l *Limiter) setDegraded(err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	switch {
+	case err != nil && !l.degraded:
+		log.Printf("⚠️ Shared Gemini rate limit unavailable, pacing this instance alone: %v", err)
+	case err == nil && l.degraded:
+		log.Println("🚦 Shared Gemini rate limit available again")
+	}
+	l.degraded = err != nil
+}
+
+// localBucket is a token bucket for this instance alone
+type localBucket struct {
+	mu      sync.Mutex
+	tokens  float64
+	updated time.Time
+}
+
+func (b *localBucket) take(rate, burst float64) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+	if !b.updated.IsZero() {
+		b.tokens = min(burst, b.tokens+now.Sub(b.updated).Seconds()*rate)
+	}
+	b.updated = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / rate * float64(time.Second))
+}
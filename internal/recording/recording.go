@@ -0,0 +1,279 @@
+package recording
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/archive"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== CALL RECORDINGS ====================
+// Call audio is downloaded from the transcript's call_recording_url into a
+// recording store (local disk or S3) and served through short-lived signed
+// URLs. Audio has its own retention, RECORDING_RETENTION_DAYS counted from the
+// call: transcripts and analyses outlive it. Downloads happen as calls are
+// processed when RECORDING_FETCH=true, or on request.
+// Layout: calls/<call_id>.<ext>
+
+var (
+	ErrNoRecording = errors.New("call has no stored recording")
+	ErrPurged      = errors.New("recording deleted by retention")
+)
+
+// BlobStore is where recording audio lives
+type BlobStore interface {
+	Put(ctx context.Context, key string, data []byte) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	Delete(ctx context.Context, key string) error
+	Name() string
+}
+
+// Global recording store (local by default, S3 if RECORDINGS_S3_BUCKET is set)
+var Store BlobStore
+
+var fetchClient = &http.Client{Timeout: 5 * time.Minute}
+
+// Init configures the recording store from the environment
+func Init() error {
+	if bucket := os.Getenv("RECORDINGS_S3_BUCKET"); bucket != "" {
+		client, err := archive.NewS3ClientFromEnv(bucket)
+		if err != nil {
+			Store = &LocalBlobStore{dir: filepath.Join(config.RECORDINGS_DIR, "audio")}
+			return fmt.Errorf("S3 recording store disabled: %w", err)
+		}
+		Store = &S3BlobStore{client: client}
+		log.Printf("🎙️ Recording store: s3://%s", bucket)
+		return nil
+	}
+
+	dir := filepath.Join(config.RECORDINGS_DIR, "audio")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create recording directory: %w", err)
+	}
+	Store = &LocalBlobStore{dir: dir}
+	log.Printf("🎙️ Recording store: %s", dir)
+	return nil
+}
+
+// FetchEnabled reports whether recordings are downloaded as calls are processed
+func FetchEnabled() bool {
+	return os.Getenv("RECORDING_FETCH") == "true"
+}
+
+// RetentionDays returns the call age after which audio is deleted
+func RetentionDays() int {
+	if v := os.Getenv("RECORDING_RETENTION_DAYS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return n
+		}
+	}
+	return config.DEFAULT_RECORDING_RETENTION_DAYS
+}
+
+func maxBytes() int64 {
+	if v := os.Getenv("RECORDING_MAX_BYTES"); v != "" {
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil && n > 0 {
+			return n
+		}
+	}
+	return config.DEFAULT_RECORDING_MAX_BYTES
+}
+
+// LocalBlobStore keeps audio on local disk
+type LocalBlobStore struct {
+	dir string
+}
+
+func (l *LocalBlobStore) Name() string { return "local:" + l.dir }
+
+func (l *LocalBlobStore) Put(ctx context.Context, key string, data []byte) error {
+	path := filepath.Join(l.dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (l *LocalBlobStore) Get(ctx context.Context, key string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(l.dir, filepath.FromSlash(key)))
+}
+
+func (l *LocalBlobStore) Delete(ctx context.Context, key string) error {
+	err := os.Remove(filepath.Join(l.dir, filepath.FromSlash(key)))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// S3BlobStore keeps audio in an S3 bucket
+type S3BlobStore struct {
+	client *archive.S3Client
+}
+
+func (s *S3BlobStore) Name() string { return "s3:" + s.client.Bucket() }
+
+func (s *S3BlobStore) Put(ctx context.Context, key string, data []byte) error {
+	return s.client.PutObject(ctx, key, data)
+}
+
+func (s *S3BlobStore) Get(ctx context.Context, key string) ([]byte, error) {
+	return s.client.GetObject(ctx, key)
+}
+
+func (s *S3BlobStore) Delete(ctx context.Context, key string) error {
+	return s.client.DeleteObject(ctx, key)
+}
+
+// ==================== FETCH ====================
+
+// Fetch downloads a call's audio from sourceURL into the recording store and
+// records it. ts is when the call happened.
+func Fetch(ctx context.Context, callID, sellerID string, ts time.Time, sourceURL string) (*client.Recording, error) {
+	u, err := url.Parse(sourceURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid recording URL for call %s", callID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sourceURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := fetchClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("recording download failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("recording download returned status %d", resp.StatusCode)
+	}
+
+	limit := maxBytes()
+	if resp.ContentLength > limit {
+		return nil, fmt.Errorf("recording is %d bytes, over the %d byte limit", resp.ContentLength, limit)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("recording download failed: %w", err)
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("recording is over the %d byte limit", limit)
+	}
+
+	contentType, ext := audioType(resp.Header.Get("Content-Type"), u.Path)
+	rec := &client.Recording{
+		CallID:      callID,
+		SellerID:    sellerID,
+		Timestamp:   ts,
+		SourceURL:   sourceURL,
+		Key:         "calls/" + storage.Sanitize(callID) + ext,
+		ContentType: contentType,
+		SizeBytes:   int64(len(data)),
+		FetchedAt:   time.Now(),
+	}
+	if err := Store.Put(ctx, rec.Key, data); err != nil {
+		return nil, fmt.Errorf("failed to store recording: %w", err)
+	}
+	if err := storage.SaveRecording(ctx, rec); err != nil {
+		return nil, err
+	}
+
+	log.Printf("🎙️ Stored recording for call %s (%d bytes, %s)", callID, rec.SizeBytes, Store.Name())
+	return rec, nil
+}
+
+// audioType picks the content type and file extension for downloaded audio,
+// preferring the response's Content-Type and falling back to the URL's extension
+func audioType(header, urlPath string) (string, string) {
+	contentType, _, _ := mime.ParseMediaType(header)
+	ext := strings.ToLower(path.Ext(urlPath))
+	if contentType == "" || contentType == "application/octet-stream" {
+		if t := mime.TypeByExtension(ext); t != "" {
+			contentType, _, _ = mime.ParseMediaType(t)
+		}
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	if ext == "" {
+		if exts, _ := mime.ExtensionsByType(contentType); len(exts) > 0 {
+			ext = exts[0]
+		}
+	}
+	return contentType, ext
+}
+
+// Open returns a call's recording record and audio
+func Open(ctx context.Context, callID string) (*client.Recording, []byte, error) {
+	rec, err := storage.LoadRecording(ctx, callID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if rec == nil {
+		return nil, nil, ErrNoRecording
+	}
+	if rec.PurgedAt != nil {
+		return rec, nil, ErrPurged
+	}
+
+	data, err := Store.Get(ctx, rec.Key)
+	if err != nil {
+		return rec, nil, fmt.Errorf("failed to read recording: %w", err)
+	}
+	return rec, data, nil
+}
+
+// ==================== RETENTION ====================
+
+// Purge deletes the audio of calls before cutoff, keeping their records
+func Purge(ctx context.Context, cutoff time.Time) (*client.RecordingPurgeResult, error) {
+	recs, err := storage.ListRecordingsToPurge(ctx, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recordings: %w", err)
+	}
+
+	result := &client.RecordingPurgeResult{Cutoff: cutoff}
+	for i := range recs {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		rec := &recs[i]
+		if err := Store.Delete(ctx, rec.Key); err != nil {
+			log.Printf("⚠️ Failed to delete recording %s: %v", rec.CallID, err)
+			result.Failed++
+			continue
+		}
+		now := time.Now()
+		rec.PurgedAt = &now
+		if err := storage.SaveRecording(ctx, rec); err != nil {
+			log.Printf("⚠️ Deleted recording %s but failed to mark it purged: %v", rec.CallID, err)
+			result.Failed++
+			continue
+		}
+		result.Purged++
+		result.BytesFreed += rec.SizeBytes
+	}
+
+	log.Printf("🎙️ Recording retention: %d purged (%d bytes), %d failed, calls before %s", result.Purged, result.BytesFreed, result.Failed, cutoff.Format(time.RFC3339))
+	return result, nil
+}
@@ -0,0 +1,72 @@
+package recording
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"im-ai-voice/internal/config"
+)
+
+// ==================== SIGNED URLS ====================
+// Recording URLs carry an expiry and an HMAC-SHA256 signature over the call
+// ID and expiry, so they can be handed to a browser's <audio> element without
+// an API key. RECORDING_URL_SECRET must be shared by every instance; without
+// it each process signs with a random secret and its URLs only work there.
+
+var ErrBadSignature = errors.New("invalid or expired recording URL")
+
+var urlSecret = loadURLSecret()
+
+func loadURLSecret() []byte {
+	if v := os.Getenv("RECORDING_URL_SECRET"); v != "" {
+		return []byte(v)
+	}
+	secret := make([]byte, 32)
+	rand.Read(secret)
+	log.Printf("⚠️ RECORDING_URL_SECRET not set, recording URLs only work on this instance until restart")
+	return secret
+}
+
+// URLTTL returns how long signed recording URLs stay valid
+func URLTTL() time.Duration {
+	return config.EnvDuration("RECORDING_URL_TTL", config.DEFAULT_RECORDING_URL_TTL)
+}
+
+// SignedURL returns the signed audio URL of a call, relative to the API base URL
+func SignedURL(callID string) (string, time.Time) {
+	expires := time.Now().Add(URLTTL()).Truncate(time.Second)
+	exp := strconv.FormatInt(expires.Unix(), 10)
+
+	q := url.Values{}
+	q.Set("expires", exp)
+	q.Set("signature", sign(callID, exp))
+	return fmt.Sprintf("/calls/%s/recording?%s", url.PathEscape(callID), q.Encode()), expires
+}
+
+// Verify checks a signed URL's expires and signature parameters for callID
+func Verify(callID, expires, signature string) error {
+	exp, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > exp {
+		return ErrBadSignature
+	}
+	want := sign(callID, expires)
+	if !hmac.Equal([]byte(signature), []byte(want)) {
+		return ErrBadSignature
+	}
+	return nil
+}
+
+func sign(callID, expires string) string {
+	mac := hmac.New(sha256.New, urlSecret)
+	mac.Write([]byte(callID + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,147 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"time"
+
+	"im-ai-voice/client"
+)
+
+// ==================== DAILY DIGEST RENDERING ====================
+
+// DailyDigest is the view model for a digest
+type DailyDigest struct {
+	Date        string                  `json:"date"`
+	Aggregate   *client.DailyAggregate  `json:"aggregate,omitempty"`
+	NewTickets  []client.Ticket         `json:"new_tickets"`
+	AtRisk      []*client.SellerProfile `json:"-"`
+	AtRiskCount int                     `json:"at_risk_count"`
+	TopBuckets  []client.BucketCount    `json:"top_buckets"`
+	Themes      []client.InsightTheme   `json:"themes"` // Themes of the week ending on Date
+	GeneratedAt time.Time               `json:"generated_at"`
+}
+
+// ==================== DIGEST RENDERING ====================
+
+// RenderDigestHTML renders the digest as an email-friendly HTML page
+func RenderDigestHTML(d *DailyDigest) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := digestTemplate.Execute(&buf, d); err != nil {
+		return nil, fmt.Errorf("failed to render digest: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// RenderDigestPDF renders the digest as a PDF
+func RenderDigestPDF(dg *DailyDigest) []byte {
+	d := NewPDFDocument()
+	margin := 40.0
+	width := pdfPageWidth - 2*margin
+
+	d.SetFillColor(0.1, 0.1, 0.1)
+	d.Text(margin, 50, 18, true, "Daily Digest - "+dg.Date)
+	d.SetFillColor(0.4, 0.4, 0.4)
+	d.Text(margin, 66, 9, false, "Generated "+dg.GeneratedAt.Format("2006-01-02 15:04"))
+
+	y := 95.0
+	if agg := dg.Aggregate; agg != nil {
+		d.SetFillColor(0.96, 0.96, 0.96)
+		d.Rect(margin, y-15, width, 40, true)
+		d.SetFillColor(0.2, 0.2, 0.2)
+		d.Text(margin+10, y, 10, true, fmt.Sprintf("Calls: %d    Issues: %d    Upsell: %d    Avg satisfaction: %.1f",
+			agg.TotalCalls, agg.TotalIssues, agg.UpsellOpportunities, agg.AvgSatisfaction))
+		d.Text(margin+10, y+15, 9, false, fmt.Sprintf("Sentiment  +%d / =%d / -%d     Churn risk  high %d / medium %d / low %d",
+			agg.SentimentBreakdown["Positive"], agg.SentimentBreakdown["Neutral"], agg.SentimentBreakdown["Negative"],
+			agg.ChurnRiskBreakdown["high"], agg.ChurnRiskBreakdown["medium"], agg.ChurnRiskBreakdown["low"]))
+		y += 45
+
+		values := make([]float64, 0, len(dg.TopBuckets))
+		for _, b := range dg.TopBuckets {
+			values = append(values, float64(b.Count))
+		}
+		pdfBarChart(d, margin, y+10, width/2, 70, "Top issue buckets", values, 0, [3]float64{0.80, 0.30, 0.20})
+		ly := y + 16
+		for i, b := range dg.TopBuckets {
+			d.SetFillColor(0.2, 0.2, 0.2)
+			d.Text(margin+width/2+20, ly+float64(i)*13, 9, false, fmt.Sprintf("%d. %s (%d)", i+1, b.Bucket, b.Count))
+		}
+		y += 100
+	} else {
+		d.SetFillColor(0.4, 0.4, 0.4)
+		d.Text(margin, y, 9, false, "No aggregate available for this date")
+		y += 20
+	}
+
+	if len(dg.Themes) > 0 {
+		d.SetFillColor(0.1, 0.1, 0.1)
+		d.Text(margin, y, 12, true, "Themes of the Week")
+		y += 16
+		for i, t := range dg.Themes {
+			d.SetFillColor(0.2, 0.2, 0.2)
+			y = d.TextWrapped(margin, y, 9, width, 2, fmt.Sprintf("%d. %s (%d calls, %d sellers)", i+1, t.Theme, t.Calls, t.Sellers))
+			y += 3
+		}
+		y += 10
+	}
+
+	d.SetFillColor(0.1, 0.1, 0.1)
+	d.Text(margin, y, 12, true, fmt.Sprintf("New Tickets (%d)", len(dg.NewTickets)))
+	y += 16
+	for _, t := range dg.NewTickets {
+		d.SetFillColor(0.2, 0.2, 0.2)
+		y = d.TextWrapped(margin, y, 9, width, 2, fmt.Sprintf("P%d [%s] %s", t.Priority, t.Severity, t.Title))
+		y += 3
+	}
+
+	y += 10
+	d.SetFillColor(0.1, 0.1, 0.1)
+	d.Text(margin, y, 12, true, fmt.Sprintf("Top At-Risk Sellers (%d flagged)", dg.AtRiskCount))
+	y += 16
+	for _, p := range dg.AtRisk {
+		if y > pdfPageHeight-50 {
+			break
+		}
+		c := HealthColor(p.CurrentStatus.HealthLabel)
+		d.SetFillColor(c[0], c[1], c[2])
+		d.Text(margin, y, 9, true, fmt.Sprintf("%3d", p.CurrentStatus.HealthScore))
+		d.SetFillColor(0.2, 0.2, 0.2)
+		d.Text(margin+30, y, 9, false, fmt.Sprintf("%s (%s, %s) - churn %s - %s",
+			p.GluserID, OrDash(p.CustomerType), OrDash(p.CityName), OrDash(p.CurrentStatus.ChurnRisk), p.CurrentStatus.AttentionReason))
+		y += 13
+	}
+
+	return d.Bytes()
+}
+
+var digestTemplate = template.Must(template.New("digest").Funcs(template.FuncMap{
+	"dash": OrDash,
+	"health": func(label string) string {
+		c := HealthColor(label)
+		return fmt.Sprintf("rgb(%.0f,%.0f,%.0f)", c[0]*255, c[1]*255, c[2]*255)
+	},
+}).Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>Daily Digest {{.Date}}</title></head>
+<body style="font-family:Helvetica,Arial,sans-serif;color:#222;max-width:720px;margin:0 auto;font-size:14px">
+<h2 style="margin-bottom:4px">Daily Digest - {{.Date}}</h2>
+<div style="color:#777">Generated {{.GeneratedAt.Format "2006-01-02 15:04"}}</div>
+{{with .Aggregate}}
+<table cellpadding="8" style="background:#f5f5f5;margin:12px 0;width:100%">
+<tr><td><b>{{.TotalCalls}}</b><br>calls</td><td><b>{{.TotalIssues}}</b><br>issues</td>
+<td><b>{{.UpsellOpportunities}}</b><br>upsell opportunities</td><td><b>{{printf "%.1f" .AvgSatisfaction}}</b><br>avg satisfaction</td></tr>
+</table>
+<p>Sentiment: {{index .SentimentBreakdown "Positive"}} positive / {{index .SentimentBreakdown "Neutral"}} neutral / {{index .SentimentBreakdown "Negative"}} negative<br>
+Churn risk: {{index .ChurnRiskBreakdown "high"}} high / {{index .ChurnRiskBreakdown "medium"}} medium / {{index .ChurnRiskBreakdown "low"}} low</p>
+{{else}}<p style="color:#777">No aggregate available for this date.</p>{{end}}
+{{if .TopBuckets}}<h3>Top Issue Buckets</h3><ol>{{range .TopBuckets}}<li>{{.Bucket}} ({{.Count}})</li>{{end}}</ol>{{end}}
+{{if .Themes}}<h3>Themes of the Week</h3><ol>{{range .Themes}}<li>{{.Theme}} <span style="color:#777">({{.Calls}} calls, {{.Sellers}} sellers)</span>{{if .Examples}}<br><span style="color:#777;font-size:12px">{{range $i, $e := .Examples}}{{if $i}} · {{end}}“{{$e}}”{{end}}</span>{{end}}</li>{{end}}</ol>{{end}}
+<h3>New Tickets ({{len .NewTickets}})</h3>
+{{range .NewTickets}}<p><b>P{{.Priority}} [{{.Severity}}]</b> {{.Title}}</p>{{else}}<p style="color:#777">No tickets generated.</p>{{end}}
+<h3>Top At-Risk Sellers ({{.AtRiskCount}} flagged)</h3>
+<table cellpadding="6" style="border-collapse:collapse;width:100%">
+{{range .AtRisk}}<tr style="border-bottom:1px solid #eee"><td style="color:{{health .CurrentStatus.HealthLabel}};font-weight:bold">{{.CurrentStatus.HealthScore}}</td>
+<td>{{.GluserID}}</td><td>{{dash .CustomerType}}</td><td>{{dash .CityName}}</td><td>churn {{dash .CurrentStatus.ChurnRisk}}</td><td>{{.CurrentStatus.AttentionReason}}</td></tr>
+{{else}}<tr><td style="color:#777">No sellers flagged.</td></tr>{{end}}
+</table>
+</body></html>`))
@@ -0,0 +1,207 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// ==================== MINIMAL PDF WRITER ====================
+// Dependency-free PDF 1.4 generator for one-page reports: text in the
+// standard Helvetica fonts (no embedding), filled rectangles and lines.
+// Coordinates are in points with the origin at the TOP-left of the page to
+// keep layout code readable; they are flipped when written.
+
+const (
+	pdfPageWidth  = 595.0 // A4
+	pdfPageHeight = 842.0
+)
+
+// PDFDocument accumulates pages of drawing commands
+type PDFDocument struct {
+	pages []*bytes.Buffer
+	cur   *bytes.Buffer
+}
+
+// NewPDFDocument creates a document with one empty A4 page
+func NewPDFDocument() *PDFDocument {
+	d := &PDFDocument{}
+	d.AddPage()
+	return d
+}
+
+// AddPage starts a new page; subsequent drawing goes to it
+func (d *PDFDocument) AddPage() {
+	d.cur = &bytes.Buffer{}
+	d.pages = append(d.pages, d.cur)
+}
+
+// Text draws a single line of text with its baseline at (x, y)
+func (d *PDFDocument) Text(x, y, size float64, bold bool, s string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(d.cur, "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n",
+		font, size, x, pdfPageHeight-y, pdfEscape(s))
+}
+
+// TextWrapped draws text wrapped to maxWidth, returning the y after the last line.
+// At most maxLines lines are drawn; overflow is replaced with an ellipsis.
+func (d *PDFDocument) TextWrapped(x, y, size, maxWidth float64, maxLines int, s string) float64 {
+	lines := pdfWrap(s, size, maxWidth)
+	if maxLines > 0 && len(lines) > maxLines {
+		lines = lines[:maxLines]
+		lines[maxLines-1] = strings.TrimRight(lines[maxLines-1], " .") + "..."
+	}
+	for _, line := range lines {
+		d.Text(x, y, size, false, line)
+		y += size * 1.35
+	}
+	return y
+}
+
+// SetFillColor sets the RGB fill color (0-1 components) for text and shapes
+func (d *PDFDocument) SetFillColor(r, g, b float64) {
+	fmt.Fprintf(d.cur, "%.3f %.3f %.3f rg\n", r, g, b)
+}
+
+// SetStrokeColor sets the RGB stroke color for lines
+func (d *PDFDocument) SetStrokeColor(r, g, b float64) {
+	fmt.Fprintf(d.cur, "%.3f %.3f %.3f RG\n", r, g, b)
+}
+
+// Rect draws a rectangle with its top-left corner at (x, y)
+func (d *PDFDocument) Rect(x, y, w, h float64, fill bool) {
+	op := "S"
+	if fill {
+		op = "f"
+	}
+	fmt.Fprintf(d.cur, "%.2f %.2f %.2f %.2f re %s\n", x, pdfPageHeight-y-h, w, h, op)
+}
+
+// Line draws a straight line
+func (d *PDFDocument) Line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(d.cur, "%.2f w %.2f %.2f m %.2f %.2f l S\n",
+		width, x1, pdfPageHeight-y1, x2, pdfPageHeight-y2)
+}
+
+// Bytes serializes the document
+func (d *PDFDocument) Bytes() []byte {
+	var out bytes.Buffer
+	var offsets []int
+
+	writeObj := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xE2\xE3\xCF\xD3\n")
+
+	// Object layout: 1 catalog, 2 pages, 3-4 fonts, then (page, content) pairs
+	var kids []string
+	for i := range d.pages {
+		kids = append(kids, fmt.Sprintf("%d 0 R", 5+i*2))
+	}
+	writeObj("<< /Type /Catalog /Pages 2 0 R >>")
+	writeObj(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	writeObj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	writeObj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, page := range d.pages {
+		writeObj(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] "+
+			"/Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, 6+i*2))
+		writeObj(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.Len(), page.String()))
+	}
+
+	xrefStart := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xrefStart)
+	return out.Bytes()
+}
+
+// pdfEscape escapes PDF string delimiters and drops characters the standard
+// fonts can't render (e.g. Devanagari) so the output stays valid
+func pdfEscape(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		case r == '₹':
+			sb.WriteString("Rs.")
+		case r == '\n' || r == '\t':
+			sb.WriteByte(' ')
+		case r >= 32 && r < 127:
+			sb.WriteRune(r)
+		default:
+			sb.WriteByte('?')
+		}
+	}
+	return sb.String()
+}
+
+// pdfWrap splits text into lines that fit maxWidth using an average
+// Helvetica glyph width (~0.5em), which is close enough for report layout
+func pdfWrap(s string, size, maxWidth float64) []string {
+	maxChars := int(maxWidth / (size * 0.5))
+	if maxChars < 10 {
+		maxChars = 10
+	}
+	var lines []string
+	var cur strings.Builder
+	for _, word := range strings.Fields(s) {
+		if cur.Len() > 0 && cur.Len()+1+len(word) > maxChars {
+			lines = append(lines, cur.String())
+			cur.Reset()
+		}
+		if cur.Len() > 0 {
+			cur.WriteByte(' ')
+		}
+		cur.WriteString(word)
+	}
+	if cur.Len() > 0 {
+		lines = append(lines, cur.String())
+	}
+	return lines
+}
+
+// pdfBarChart draws a simple bar chart of values (scaled to maxValue) in the box
+func pdfBarChart(d *PDFDocument, x, y, w, h float64, title string, values []float64, maxValue float64, color [3]float64) {
+	d.SetFillColor(0.2, 0.2, 0.2)
+	d.Text(x, y-4, 9, true, title)
+	d.SetStrokeColor(0.8, 0.8, 0.8)
+	d.Line(x, y+h, x+w, y+h, 0.5)
+
+	if len(values) == 0 {
+		d.SetFillColor(0.5, 0.5, 0.5)
+		d.Text(x, y+h/2, 8, false, "No data yet")
+		return
+	}
+	if maxValue <= 0 {
+		for _, v := range values {
+			if v > maxValue {
+				maxValue = v
+			}
+		}
+		if maxValue <= 0 {
+			maxValue = 1
+		}
+	}
+
+	slot := w / float64(len(values))
+	barW := slot * 0.7
+	d.SetFillColor(color[0], color[1], color[2])
+	for i, v := range values {
+		bh := (v / maxValue) * (h - 6)
+		if bh < 1 {
+			bh = 1
+		}
+		d.Rect(x+float64(i)*slot+(slot-barW)/2, y+h-bh, barW, bh, true)
+	}
+}
@@ -0,0 +1,245 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"strings"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+)
+
+// ==================== SELLER HEALTH REPORT ====================
+// One-page shareable seller report (status card, trend charts, open issues,
+// recent call summaries) for account managers, rendered as HTML or PDF.
+
+const (
+	reportTrendPoints = 15 // Most recent points shown per chart
+	reportMaxIssues   = 8
+	reportMaxCalls    = 5
+)
+
+// SellerReport is the view model shared by the HTML and PDF renderers
+type SellerReport struct {
+	Profile      *client.SellerProfile
+	GeneratedAt  time.Time
+	Satisfaction []float64
+	Sentiment    []float64
+	Issues       []client.TrackedIssue
+	RecentCalls  []client.CallSummary
+}
+
+// BuildSellerReport prepares report data from a profile
+func BuildSellerReport(profile *client.SellerProfile) *SellerReport {
+	rep := &SellerReport{
+		Profile:      profile,
+		GeneratedAt:  time.Now(),
+		Satisfaction: lastTrendValues(profile.Trends.SatisfactionHistory, reportTrendPoints),
+		Sentiment:    lastTrendValues(profile.Trends.SentimentHistory, reportTrendPoints),
+		Issues:       profile.ActiveIssues,
+		RecentCalls:  profile.CallHistory,
+	}
+	if len(rep.Issues) > reportMaxIssues {
+		rep.Issues = rep.Issues[:reportMaxIssues]
+	}
+	if len(rep.RecentCalls) > reportMaxCalls {
+		rep.RecentCalls = rep.RecentCalls[:reportMaxCalls]
+	}
+	return rep
+}
+
+func lastTrendValues(points []client.TrendPoint, n int) []float64 {
+	start := len(points) - n
+	if start < 0 {
+		start = 0
+	}
+	values := make([]float64, 0, len(points)-start)
+	for _, p := range points[start:] {
+		values = append(values, p.Value)
+	}
+	return values
+}
+
+// HealthColor maps a health label to an RGB color (0-1)
+func HealthColor(label string) [3]float64 {
+	switch label {
+	case "Healthy":
+		return [3]float64{0.13, 0.59, 0.33}
+	case "At Risk":
+		return [3]float64{0.90, 0.55, 0.10}
+	default:
+		return [3]float64{0.80, 0.16, 0.16}
+	}
+}
+
+// ==================== PDF RENDERING ====================
+
+// RenderSellerReportPDF renders the report as a one-page PDF
+func RenderSellerReportPDF(rep *SellerReport) []byte {
+	p := rep.Profile
+	d := NewPDFDocument()
+	margin := 40.0
+	width := pdfPageWidth - 2*margin
+
+	// Header
+	d.SetFillColor(0.1, 0.1, 0.1)
+	d.Text(margin, 50, 18, true, fmt.Sprintf("Seller Health Report - %s", p.GluserID))
+	d.SetFillColor(0.4, 0.4, 0.4)
+	d.Text(margin, 66, 9, false, fmt.Sprintf("%s | %s | %s | %d months on IndiaMART | Generated %s",
+		OrDash(p.CustomerType), OrDash(p.CityName), OrDash(p.Vertical), p.VintageMonths,
+		rep.GeneratedAt.Format("2006-01-02 15:04")))
+
+	// Status card
+	s := p.CurrentStatus
+	c := HealthColor(s.HealthLabel)
+	d.SetFillColor(0.96, 0.96, 0.96)
+	d.Rect(margin, 80, width, 70, true)
+	d.SetFillColor(c[0], c[1], c[2])
+	d.Rect(margin, 80, 6, 70, true)
+	d.Text(margin+18, 112, 26, true, fmt.Sprintf("%d", s.HealthScore))
+	d.Text(margin+18, 134, 11, true, s.HealthLabel)
+
+	d.SetFillColor(0.2, 0.2, 0.2)
+	col1, col2 := margin+130, margin+300
+	d.Text(col1, 100, 9, false, "Churn risk: "+OrDash(s.ChurnRisk))
+	d.Text(col1, 114, 9, false, "Sentiment: "+OrDash(s.Sentiment))
+	d.Text(col1, 128, 9, false, fmt.Sprintf("Satisfaction: %d/10", s.SatisfactionScore))
+	d.Text(col2, 100, 9, false, fmt.Sprintf("Open issues: %d", s.OpenIssueCount))
+	d.Text(col2, 114, 9, false, "Upsell potential: "+OrDash(s.UpsellPotential))
+	d.Text(col2, 128, 9, false, fmt.Sprintf("Total calls: %d | Trend: %s", p.TotalCalls, OrDash(p.Trends.OverallTrend)))
+	if s.NeedsAttention {
+		d.SetFillColor(0.80, 0.16, 0.16)
+		d.Text(col1, 143, 9, true, "Needs attention: "+s.AttentionReason)
+	}
+
+	// Trend charts
+	chartW := (width - 20) / 2
+	pdfBarChart(d, margin, 180, chartW, 80, "Satisfaction (last calls, 1-10)", rep.Satisfaction, 10, [3]float64{0.20, 0.45, 0.80})
+	pdfBarChart(d, margin+chartW+20, 180, chartW, 80, "Sentiment (0 neg - 1 pos)", rep.Sentiment, 1, [3]float64{0.13, 0.59, 0.33})
+
+	// Open issues
+	y := 290.0
+	d.SetFillColor(0.1, 0.1, 0.1)
+	d.Text(margin, y, 12, true, fmt.Sprintf("Open Issues (%d)", len(p.ActiveIssues)))
+	y += 16
+	if len(rep.Issues) == 0 {
+		d.SetFillColor(0.4, 0.4, 0.4)
+		d.Text(margin, y, 9, false, "No open issues")
+		y += 14
+	}
+	for _, issue := range rep.Issues {
+		d.SetFillColor(0.2, 0.2, 0.2)
+		recurring := ""
+		if issue.IsRecurring {
+			recurring = fmt.Sprintf(" - recurring x%d", issue.MentionCount)
+		}
+		d.Text(margin, y, 9, true, fmt.Sprintf("[%s] %s%s", strings.ToUpper(issue.Severity), issue.Bucket, recurring))
+		y = d.TextWrapped(margin+10, y+12, 8.5, width-10, 2, issue.Problem)
+		y += 4
+	}
+
+	// Recent calls
+	y += 10
+	d.SetFillColor(0.1, 0.1, 0.1)
+	d.Text(margin, y, 12, true, "Recent Calls")
+	y += 16
+	for _, call := range rep.RecentCalls {
+		if y > pdfPageHeight-60 {
+			break
+		}
+		d.SetFillColor(0.2, 0.2, 0.2)
+		d.Text(margin, y, 9, true, fmt.Sprintf("%s  |  %s  |  %d issues  |  agent: %s",
+			config.BusinessDate(call.Timestamp), OrDash(call.Sentiment), call.IssuesRaised, OrDash(call.AgentPerformance)))
+		y = d.TextWrapped(margin+10, y+12, 8.5, width-10, 3, call.Summary)
+		y += 6
+	}
+
+	d.SetFillColor(0.5, 0.5, 0.5)
+	d.Text(margin, pdfPageHeight-30, 7.5, false, "IndiaMART Voice AI - confidential, for internal account reviews")
+	return d.Bytes()
+}
+
+func OrDash(s string) string {
+	if strings.TrimSpace(s) == "" {
+		return "-"
+	}
+	return s
+}
+
+// ==================== HTML RENDERING ====================
+
+// RenderSellerReportHTML renders the report as a self-contained HTML page
+func RenderSellerReportHTML(rep *SellerReport) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := sellerReportTemplate.Execute(&buf, rep); err != nil {
+		return nil, fmt.Errorf("failed to render report: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// svgBarChart renders values as an inline SVG bar chart
+func svgBarChart(values []float64, maxValue float64, color string) template.HTML {
+	const w, h = 260.0, 80.0
+	if len(values) == 0 {
+		return template.HTML(`<p class="muted">No data yet</p>`)
+	}
+	if maxValue <= 0 {
+		maxValue = 1
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<svg width="%.0f" height="%.0f" viewBox="0 0 %.0f %.0f">`, w, h, w, h)
+	slot := w / float64(len(values))
+	for i, v := range values {
+		bh := v / maxValue * (h - 4)
+		if bh < 1 {
+			bh = 1
+		}
+		fmt.Fprintf(&sb, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"/>`,
+			float64(i)*slot+slot*0.15, h-bh, slot*0.7, bh, color)
+	}
+	sb.WriteString(`</svg>`)
+	return template.HTML(sb.String())
+}
+
+var sellerReportTemplate = template.Must(template.New("seller_report").Funcs(template.FuncMap{
+	"bars":  svgBarChart,
+	"dash":  OrDash,
+	"date":  config.BusinessDate,
+	"upper": strings.ToUpper,
+	"health": func(label string) string {
+		c := HealthColor(label)
+		return fmt.Sprintf("rgb(%.0f,%.0f,%.0f)", c[0]*255, c[1]*255, c[2]*255)
+	},
+}).Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>Seller Health Report - {{.Profile.GluserID}}</title>
+<style>
+body{font-family:Helvetica,Arial,sans-serif;color:#222;max-width:800px;margin:24px auto;font-size:13px}
+h1{font-size:22px;margin:0}h2{font-size:15px;margin:20px 0 8px}.muted{color:#777}
+.card{display:flex;gap:24px;background:#f5f5f5;border-left:6px solid {{health .Profile.CurrentStatus.HealthLabel}};padding:12px 16px;margin-top:12px}
+.score{font-size:34px;font-weight:bold;color:{{health .Profile.CurrentStatus.HealthLabel}}}
+.charts{display:flex;gap:24px}.issue,.call{margin-bottom:8px}.alert{color:#c62828;font-weight:bold}
+</style></head><body>
+{{with .Profile}}
+<h1>Seller Health Report - {{.GluserID}}</h1>
+<div class="muted">{{dash .CustomerType}} | {{dash .CityName}} | {{dash .Vertical}} | {{.VintageMonths}} months on IndiaMART | Generated {{$.GeneratedAt.Format "2006-01-02 15:04"}}</div>
+<div class="card">
+  <div><div class="score">{{.CurrentStatus.HealthScore}}</div><b>{{.CurrentStatus.HealthLabel}}</b></div>
+  <div>Churn risk: {{dash .CurrentStatus.ChurnRisk}}<br>Sentiment: {{dash .CurrentStatus.Sentiment}}<br>Satisfaction: {{.CurrentStatus.SatisfactionScore}}/10</div>
+  <div>Open issues: {{.CurrentStatus.OpenIssueCount}}<br>Upsell potential: {{dash .CurrentStatus.UpsellPotential}}<br>Total calls: {{.TotalCalls}} | Trend: {{dash .Trends.OverallTrend}}</div>
+</div>
+{{if .CurrentStatus.NeedsAttention}}<p class="alert">Needs attention: {{.CurrentStatus.AttentionReason}}</p>{{end}}
+{{end}}
+<div class="charts">
+  <div><h2>Satisfaction (1-10)</h2>{{bars .Satisfaction 10 "#3373cc"}}</div>
+  <div><h2>Sentiment (0 neg - 1 pos)</h2>{{bars .Sentiment 1 "#219653"}}</div>
+</div>
+<h2>Open Issues ({{len .Profile.ActiveIssues}})</h2>
+{{range .Issues}}<div class="issue"><b>[{{upper .Severity}}] {{.Bucket}}</b>{{if .IsRecurring}} - recurring x{{.MentionCount}}{{end}}<br>{{.Problem}}</div>
+{{else}}<p class="muted">No open issues</p>{{end}}
+<h2>Recent Calls</h2>
+{{range .RecentCalls}}<div class="call"><b>{{date .Timestamp}} | {{dash .Sentiment}} | {{.IssuesRaised}} issues | agent: {{dash .AgentPerformance}}</b><br>{{.Summary}}</div>
+{{else}}<p class="muted">No calls recorded</p>{{end}}
+<p class="muted">IndiaMART Voice AI - confidential, for internal account reviews</p>
+</body></html>`))
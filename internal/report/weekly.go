@@ -0,0 +1,270 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"time"
+
+	"im-ai-voice/client"
+)
+
+// ==================== WEEKLY EXECUTIVE REPORT ====================
+
+// WeeklyReport is the view model for the weekly executive report: the week's
+// daily aggregates against the week before, the largest systemic issues,
+// how churn reasons moved, the best and worst ranked agents, and Gemini's
+// narrative and recommendations over all of it
+type WeeklyReport struct {
+	From            string                 `json:"from"`
+	To              string                 `json:"to"`
+	Days            []WeeklyDay            `json:"days"` // Every day of the week, oldest first
+	Current         WeeklyTotals           `json:"current"`
+	Previous        WeeklyTotals           `json:"previous"` // The 7 days before From
+	TopBuckets      []client.BucketCount   `json:"top_buckets"`
+	SystemicIssues  []client.SystemicIssue `json:"systemic_issues"` // Most sellers first, without members
+	ChurnMovement   []ChurnMovement        `json:"churn_movement"`  // Largest change first
+	TopAgents       []client.AgentStanding `json:"top_agents"`
+	BottomAgents    []client.AgentStanding `json:"bottom_agents"` // Worst last
+	RankedAgents    int                    `json:"ranked_agents"`
+	Narrative       string                 `json:"narrative,omitempty"` // Empty when Gemini isn't configured or failed
+	Recommendations []string               `json:"recommendations"`
+	GeneratedAt     time.Time              `json:"generated_at"`
+}
+
+// WeeklyDay is one day of the week's charts; days without an aggregate are zero
+type WeeklyDay struct {
+	Date            string  `json:"date"`
+	Calls           int     `json:"calls"`
+	Issues          int     `json:"issues"`
+	AvgSatisfaction float64 `json:"avg_satisfaction_score"`
+	AtRiskCalls     int     `json:"at_risk_calls"` // Medium or high churn risk
+}
+
+// WeeklyTotals sums a week of daily aggregates
+type WeeklyTotals struct {
+	Days                int     `json:"days"` // Days with an aggregate
+	Calls               int     `json:"calls"`
+	Issues              int     `json:"issues"`
+	NegativeCalls       int     `json:"negative_calls"`
+	HighChurnCalls      int     `json:"high_churn_calls"`
+	AtRiskCalls         int     `json:"at_risk_calls"`
+	UpsellOpportunities int     `json:"upsell_opportunities"`
+	AvgSatisfaction     float64 `json:"avg_satisfaction_score"` // Weighted by calls
+}
+
+// ChurnMovement is the change in at-risk calls with a churn reason category
+type ChurnMovement struct {
+	Category string `json:"category"`
+	Calls    int    `json:"calls"`
+	Previous int    `json:"previous"`
+	Delta    int    `json:"delta"`
+}
+
+// RenderWeeklyHTML renders the weekly report as an email-friendly HTML page
+func RenderWeeklyHTML(w *WeeklyReport) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := weeklyTemplate.Execute(&buf, w); err != nil {
+		return nil, fmt.Errorf("failed to render weekly report: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// RenderWeeklyPDF renders the weekly report as a PDF, two pages at most
+func RenderWeeklyPDF(wr *WeeklyReport) []byte {
+	d := NewPDFDocument()
+	margin := 40.0
+	width := pdfPageWidth - 2*margin
+
+	d.SetFillColor(0.1, 0.1, 0.1)
+	d.Text(margin, 50, 18, true, fmt.Sprintf("Weekly Executive Report - %s to %s", wr.From, wr.To))
+	d.SetFillColor(0.4, 0.4, 0.4)
+	d.Text(margin, 66, 9, false, "Generated "+wr.GeneratedAt.Format("2006-01-02 15:04"))
+
+	y := 95.0
+	cur, prev := wr.Current, wr.Previous
+	d.SetFillColor(0.96, 0.96, 0.96)
+	d.Rect(margin, y-15, width, 40, true)
+	d.SetFillColor(0.2, 0.2, 0.2)
+	d.Text(margin+10, y, 10, true, fmt.Sprintf("Calls: %d (%s)    Issues: %d (%s)    Avg satisfaction: %.1f (%s)",
+		cur.Calls, signed(cur.Calls-prev.Calls), cur.Issues, signed(cur.Issues-prev.Issues),
+		cur.AvgSatisfaction, signedFloat(cur.AvgSatisfaction-prev.AvgSatisfaction)))
+	d.Text(margin+10, y+15, 9, false, fmt.Sprintf("At-risk calls: %d (%s)    High churn: %d (%s)    Negative: %d (%s)    vs the week before",
+		cur.AtRiskCalls, signed(cur.AtRiskCalls-prev.AtRiskCalls), cur.HighChurnCalls, signed(cur.HighChurnCalls-prev.HighChurnCalls),
+		cur.NegativeCalls, signed(cur.NegativeCalls-prev.NegativeCalls)))
+	y += 45
+
+	calls := make([]float64, len(wr.Days))
+	satisfaction := make([]float64, len(wr.Days))
+	atRisk := make([]float64, len(wr.Days))
+	for i, day := range wr.Days {
+		calls[i] = float64(day.Calls)
+		satisfaction[i] = day.AvgSatisfaction
+		atRisk[i] = float64(day.AtRiskCalls)
+	}
+	chartW := (width - 40) / 3
+	pdfBarChart(d, margin, y+10, chartW, 70, "Calls per day", calls, 0, [3]float64{0.20, 0.45, 0.80})
+	pdfBarChart(d, margin+chartW+20, y+10, chartW, 70, "Avg satisfaction (1-10)", satisfaction, 10, [3]float64{0.13, 0.59, 0.33})
+	pdfBarChart(d, margin+2*(chartW+20), y+10, chartW, 70, "At-risk calls", atRisk, 0, [3]float64{0.80, 0.30, 0.20})
+	y += 105
+
+	d.SetFillColor(0.1, 0.1, 0.1)
+	d.Text(margin, y, 12, true, "Executive Summary")
+	y += 16
+	d.SetFillColor(0.2, 0.2, 0.2)
+	if wr.Narrative != "" {
+		y = d.TextWrapped(margin, y, 9.5, width, 12, wr.Narrative)
+	} else {
+		d.SetFillColor(0.4, 0.4, 0.4)
+		d.Text(margin, y, 9, false, "Narrative unavailable")
+		y += 13
+	}
+	if len(wr.Recommendations) > 0 {
+		y += 8
+		d.SetFillColor(0.1, 0.1, 0.1)
+		d.Text(margin, y, 12, true, "Recommendations")
+		y += 16
+		for i, r := range wr.Recommendations {
+			d.SetFillColor(0.2, 0.2, 0.2)
+			y = d.TextWrapped(margin, y, 9, width, 3, fmt.Sprintf("%d. %s", i+1, r))
+			y += 3
+		}
+	}
+
+	y += 10
+	d.SetFillColor(0.1, 0.1, 0.1)
+	d.Text(margin, y, 12, true, fmt.Sprintf("Systemic Issues (%d)", len(wr.SystemicIssues)))
+	y += 16
+	for _, s := range wr.SystemicIssues {
+		d.SetFillColor(0.2, 0.2, 0.2)
+		y = d.TextWrapped(margin, y, 9, width, 2, fmt.Sprintf("[%s] %s - %d sellers, %d calls", s.Severity, s.Title, s.SellerCount, s.MentionCount))
+		y += 3
+	}
+	if len(wr.SystemicIssues) == 0 {
+		d.SetFillColor(0.4, 0.4, 0.4)
+		d.Text(margin, y, 9, false, "No systemic issues")
+		y += 13
+	}
+
+	// Churn and agents go on a second page when the first is full
+	if y > pdfPageHeight-260 {
+		d.AddPage()
+		y = 50
+	} else {
+		y += 10
+	}
+
+	d.SetFillColor(0.1, 0.1, 0.1)
+	d.Text(margin, y, 12, true, "Churn Movement")
+	y += 16
+	for _, m := range wr.ChurnMovement {
+		d.SetFillColor(0.2, 0.2, 0.2)
+		d.Text(margin, y, 9, false, fmt.Sprintf("%s: %d at-risk calls (%s, %d the week before)", m.Category, m.Calls, signed(m.Delta), m.Previous))
+		y += 13
+	}
+	if len(wr.ChurnMovement) == 0 {
+		d.SetFillColor(0.4, 0.4, 0.4)
+		d.Text(margin, y, 9, false, "No at-risk calls with a churn reason")
+		y += 13
+	}
+
+	y += 10
+	d.SetFillColor(0.1, 0.1, 0.1)
+	d.Text(margin, y, 12, true, fmt.Sprintf("Agent Performance (%d ranked)", wr.RankedAgents))
+	y += 16
+	for _, group := range []struct {
+		title  string
+		agents []client.AgentStanding
+	}{{"Top", wr.TopAgents}, {"Bottom", wr.BottomAgents}} {
+		if len(group.agents) == 0 {
+			continue
+		}
+		d.SetFillColor(0.2, 0.2, 0.2)
+		d.Text(margin, y, 9, true, group.title)
+		y += 13
+		for _, a := range group.agents {
+			d.Text(margin+10, y, 9, false, fmt.Sprintf("#%d %s - score %.1f, %d calls, moved %s",
+				a.Rank, a.AgentID, a.Score, a.Calls, signed(a.Movement)))
+			y += 13
+		}
+	}
+	if wr.RankedAgents == 0 {
+		d.SetFillColor(0.4, 0.4, 0.4)
+		d.Text(margin, y, 9, false, "No agents ranked this week")
+	}
+
+	return d.Bytes()
+}
+
+// signed formats a change with its sign, "0" for none
+func signed(n int) string {
+	if n > 0 {
+		return fmt.Sprintf("+%d", n)
+	}
+	return fmt.Sprintf("%d", n)
+}
+
+func signedFloat(f float64) string {
+	return fmt.Sprintf("%+.1f", f)
+}
+
+var weeklyTemplate = template.Must(template.New("weekly").Funcs(template.FuncMap{
+	"signed":      signed,
+	"signedFloat": signedFloat,
+	"sub":         func(a, b int) int { return a - b },
+	"subf":        func(a, b float64) float64 { return a - b },
+	"bars": func(days []WeeklyDay, field string) template.HTML {
+		values := make([]float64, len(days))
+		maxValue, color := 0.0, "#cc4d33"
+		for i, day := range days {
+			switch field {
+			case "calls":
+				values[i], color = float64(day.Calls), "#3373cc"
+			case "satisfaction":
+				values[i], color, maxValue = day.AvgSatisfaction, "#219653", 10
+			default:
+				values[i] = float64(day.AtRiskCalls)
+			}
+			if field != "satisfaction" && values[i] > maxValue {
+				maxValue = values[i]
+			}
+		}
+		return svgBarChart(values, maxValue, color)
+	},
+}).Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>Weekly Executive Report {{.From}} to {{.To}}</title></head>
+<body style="font-family:Helvetica,Arial,sans-serif;color:#222;max-width:720px;margin:0 auto;font-size:14px">
+<h2 style="margin-bottom:4px">Weekly Executive Report - {{.From}} to {{.To}}</h2>
+<div style="color:#777">Generated {{.GeneratedAt.Format "2006-01-02 15:04"}}</div>
+{{with .Current}}
+<table cellpadding="8" style="background:#f5f5f5;margin:12px 0;width:100%">
+<tr><td><b>{{.Calls}}</b> ({{signed (sub .Calls $.Previous.Calls)}})<br>calls</td>
+<td><b>{{.Issues}}</b> ({{signed (sub .Issues $.Previous.Issues)}})<br>issues</td>
+<td><b>{{printf "%.1f" .AvgSatisfaction}}</b> ({{signedFloat (subf .AvgSatisfaction $.Previous.AvgSatisfaction)}})<br>avg satisfaction</td>
+<td><b>{{.AtRiskCalls}}</b> ({{signed (sub .AtRiskCalls $.Previous.AtRiskCalls)}})<br>at-risk calls</td></tr>
+</table>
+<p style="color:#777">Changes are against {{$.Previous.Calls}} calls the week before. High churn risk: {{.HighChurnCalls}}, negative sentiment: {{.NegativeCalls}}, upsell opportunities: {{.UpsellOpportunities}}.</p>
+{{end}}
+<table style="width:100%"><tr>
+<td><b>Calls per day</b><br>{{bars .Days "calls"}}</td>
+<td><b>Avg satisfaction (1-10)</b><br>{{bars .Days "satisfaction"}}</td>
+<td><b>At-risk calls</b><br>{{bars .Days "at_risk"}}</td>
+</tr></table>
+<h3>Executive Summary</h3>
+{{if .Narrative}}<p>{{.Narrative}}</p>{{else}}<p style="color:#777">Narrative unavailable.</p>{{end}}
+{{if .Recommendations}}<h3>Recommendations</h3><ol>{{range .Recommendations}}<li>{{.}}</li>{{end}}</ol>{{end}}
+{{if .TopBuckets}}<h3>Top Issue Buckets</h3><ol>{{range .TopBuckets}}<li>{{.Bucket}} ({{.Count}})</li>{{end}}</ol>{{end}}
+<h3>Systemic Issues ({{len .SystemicIssues}})</h3>
+{{range .SystemicIssues}}<p><b>[{{.Severity}}]</b> {{.Title}} <span style="color:#777">({{.SellerCount}} sellers, {{.MentionCount}} calls)</span></p>{{else}}<p style="color:#777">No systemic issues.</p>{{end}}
+<h3>Churn Movement</h3>
+<table cellpadding="6" style="border-collapse:collapse;width:100%">
+{{range .ChurnMovement}}<tr style="border-bottom:1px solid #eee"><td>{{.Category}}</td><td>{{.Calls}} at-risk calls</td><td>{{signed .Delta}}</td><td style="color:#777">{{.Previous}} the week before</td></tr>
+{{else}}<tr><td style="color:#777">No at-risk calls with a churn reason.</td></tr>{{end}}
+</table>
+<h3>Agent Performance ({{.RankedAgents}} ranked)</h3>
+<table cellpadding="6" style="border-collapse:collapse;width:100%">
+{{range .TopAgents}}<tr style="border-bottom:1px solid #eee"><td>#{{.Rank}}</td><td>{{.AgentID}}</td><td>score {{printf "%.1f" .Score}}</td><td>{{.Calls}} calls</td><td>moved {{signed .Movement}}</td></tr>{{end}}
+{{range .BottomAgents}}<tr style="border-bottom:1px solid #eee;color:#c62828"><td>#{{.Rank}}</td><td>{{.AgentID}}</td><td>score {{printf "%.1f" .Score}}</td><td>{{.Calls}} calls</td><td>moved {{signed .Movement}}</td></tr>{{end}}
+{{if not .RankedAgents}}<tr><td style="color:#777">No agents ranked this week.</td></tr>{{end}}
+</table>
+</body></html>`))
@@ -0,0 +1,250 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+)
+
+// ==================== JOB SCHEDULER ====================
+// Background jobs (aggregation, retention, archiving, digests, ...) run on
+// cron schedules read in BUSINESS_TIMEZONE, on the leader only. Each job has
+// a default schedule; SCHEDULE_<JOB> overrides it (SCHEDULE_ARCHIVE="0 3 * * *")
+// or turns the job off (SCHEDULE_ARCHIVE=off). Any instance can run a job
+// by hand. A job never overlaps itself: a run that comes due while the last
+// one is still going is skipped.
+
+var (
+	ErrUnknownJob = errors.New("unknown job")
+	ErrJobRunning = errors.New("job already running")
+)
+
+// Job is a background job. Run should return soon after ctx is done.
+type Job struct {
+	Name        string // Lowercase with underscores, e.g. recording_retention
+	Description string
+	Spec        string // Default schedule, "off" for none
+	Run         func(ctx context.Context) error
+}
+
+type entry struct {
+	job      Job
+	spec     string
+	schedule Schedule  // nil when off
+	next     time.Time // Next scheduled run while started
+	running  bool
+	last     *client.JobRun
+}
+
+// Scheduler runs jobs on their schedules while started, and on request
+type Scheduler struct {
+	base    context.Context // Manual runs stop with it
+	mu      sync.Mutex
+	entries []*entry
+	leading bool
+}
+
+// New returns a scheduler whose manual runs stop when ctx is done
+func New(ctx context.Context) *Scheduler {
+	return &Scheduler{base: ctx}
+}
+
+// Add registers a job, taking its schedule from SCHEDULE_<NAME> when set
+func (s *Scheduler) Add(job Job) {
+	e := &entry{job: job, spec: job.Spec}
+	env := "SCHEDULE_" + strings.ToUpper(job.Name)
+	if v := strings.TrimSpace(os.Getenv(env)); v != "" {
+		if _, err := parseSpec(v); err == nil {
+			e.spec = v
+		} else {
+			log.Printf("⚠️ Invalid %s=%q (%v), using %q", env, v, err, job.Spec)
+		}
+	}
+	schedule, err := parseSpec(e.spec)
+	if err != nil {
+		log.Printf("⚠️ Job %s has an invalid schedule %q, turning it off: %v", job.Name, e.spec, err)
+		e.spec, schedule = "off", nil
+	}
+	e.schedule = schedule
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, e)
+}
+
+// parseSpec parses a schedule, nil for off
+func parseSpec(spec string) (Schedule, error) {
+	if strings.EqualFold(spec, "off") {
+		return nil, nil
+	}
+	return Parse(spec)
+}
+
+// Start runs jobs on their schedules until ctx is done
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	s.leading = true
+	now := time.Now().In(config.BusinessTZ)
+	for _, e := range s.entries {
+		if e.schedule == nil {
+			log.Printf("🗓️ Job %s is off", e.job.Name)
+			continue
+		}
+		e.next = e.schedule.Next(now)
+		log.Printf("🗓️ Job %s scheduled %q, next run %s", e.job.Name, e.spec, e.next.Format(time.RFC3339))
+	}
+	s.mu.Unlock()
+
+	go func() {
+		defer func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			s.leading = false
+			for _, e := range s.entries {
+				e.next = time.Time{}
+			}
+		}()
+		for {
+			var wake <-chan time.Time
+			var timer *time.Timer
+			if soonest := s.soonest(); !soonest.IsZero() {
+				timer = time.NewTimer(time.Until(soonest))
+				wake = timer.C
+			}
+			select {
+			case <-ctx.Done():
+				if timer != nil {
+					timer.Stop()
+				}
+				log.Println("Job scheduler stopped")
+				return
+			case <-wake:
+			}
+			s.runDue(ctx, time.Now().In(config.BusinessTZ))
+		}
+	}()
+}
+
+// soonest returns the earliest next run, zero if no job is scheduled
+func (s *Scheduler) soonest() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var soonest time.Time
+	for _, e := range s.entries {
+		if !e.next.IsZero() && (soonest.IsZero() || e.next.Before(soonest)) {
+			soonest = e.next
+		}
+	}
+	return soonest
+}
+
+// runDue launches the jobs due by now and schedules their next runs
+func (s *Scheduler) runDue(ctx context.Context, now time.Time) {
+	s.mu.Lock()
+	var due []*entry
+	for _, e := range s.entries {
+		if !e.next.IsZero() && !e.next.After(now) {
+			e.next = e.schedule.Next(now)
+			due = append(due, e)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, e := range due {
+		if err := s.launch(ctx, e, client.JobTriggerSchedule); err != nil {
+			log.Printf("🗓️ Skipping scheduled run of %s: %v", e.job.Name, err)
+		}
+	}
+}
+
+// Trigger runs the named job now, in the background
+func (s *Scheduler) Trigger(name string) error {
+	s.mu.Lock()
+	e := s.find(name)
+	s.mu.Unlock()
+	if e == nil {
+		return fmt.Errorf("%w: %s", ErrUnknownJob, name)
+	}
+	return s.launch(s.base, e, client.JobTriggerManual)
+}
+
+func (s *Scheduler) find(name string) *entry {
+	for _, e := range s.entries {
+		if e.job.Name == name {
+			return e
+		}
+	}
+	return nil
+}
+
+// launch starts a run of e unless one is going
+func (s *Scheduler) launch(ctx context.Context, e *entry, trigger string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e.running {
+		return fmt.Errorf("%w: %s", ErrJobRunning, e.job.Name)
+	}
+	run := &client.JobRun{Trigger: trigger, StartedAt: time.Now()}
+	e.running, e.last = true, run
+
+	go func() {
+		log.Printf("🗓️ Running job %s (%s)", e.job.Name, trigger)
+		err := e.job.Run(ctx)
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		finished := time.Now()
+		run.FinishedAt = &finished
+		run.DurationMS = finished.Sub(run.StartedAt).Milliseconds()
+		if err != nil {
+			run.Error = err.Error()
+			log.Printf("⚠️ Job %s failed after %v: %v", e.job.Name, finished.Sub(run.StartedAt).Round(time.Millisecond), err)
+		}
+		e.running = false
+	}()
+	return nil
+}
+
+// Status lists the jobs with their next and last runs
+func (s *Scheduler) Status() client.JobSchedule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().In(config.BusinessTZ)
+	out := client.JobSchedule{Jobs: []client.ScheduledJob{}, Leader: s.leading, Timezone: config.BusinessTZ.String(), GeneratedAt: time.Now()}
+	for _, e := range s.entries {
+		j := client.ScheduledJob{
+			Name:        e.job.Name,
+			Description: e.job.Description,
+			Schedule:    e.spec,
+			Enabled:     e.schedule != nil,
+			Running:     e.running,
+		}
+		if e.schedule != nil {
+			next := e.next
+			if next.IsZero() {
+				next = e.schedule.Next(now) // When it would run here, as a standby
+			}
+			if !next.IsZero() {
+				j.NextRun = &next
+			}
+		}
+		if e.last != nil {
+			last := *e.last
+			if last.FinishedAt == nil {
+				last.DurationMS = time.Since(last.StartedAt).Milliseconds()
+			}
+			j.LastRun = &last
+		}
+		out.Jobs = append(out.Jobs, j)
+	}
+	return out
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/storage"
+	"im-ai-voice/internal/ulid"
+)
+
+// ==================== ALERT SUBSCRIPTIONS ====================
+
+var (
+	ErrInvalidAlertSubscription  = errors.New("invalid alert subscription")
+	ErrAlertSubscriptionNotFound = errors.New("alert subscription not found")
+)
+
+// ListAlertSubscriptions returns the alert subscriptions, oldest first, without their secrets
+func (s *Service) ListAlertSubscriptions(ctx context.Context) ([]client.AlertSubscription, error) {
+	subs, err := storage.LoadAlertSubscriptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range subs {
+		subs[i].Secret = ""
+	}
+	return subs, nil
+}
+
+// GetAlertSubscription returns one alert subscription, without its secret
+func (s *Service) GetAlertSubscription(ctx context.Context, id string) (*client.AlertSubscription, error) {
+	subs, err := s.ListAlertSubscriptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range subs {
+		if subs[i].ID == id {
+			return &subs[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrAlertSubscriptionNotFound, id)
+}
+
+// CreateAlertSubscription subscribes someone to the alerts in their scope,
+// delivered from the next alert on
+func (s *Service) CreateAlertSubscription(ctx context.Context, in client.AlertSubscriptionRequest) (*client.AlertSubscription, error) {
+	in.Name = strings.TrimSpace(in.Name)
+	if in.Name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidAlertSubscription)
+	}
+	in.URL = strings.TrimSpace(in.URL)
+	emails := cleanList(in.Emails)
+	if in.Channel == "" {
+		in.Channel = client.AlertChannelWebhook
+		if in.URL == "" && len(emails) > 0 {
+			in.Channel = client.AlertChannelEmail
+		}
+	}
+
+	var host string
+	switch in.Channel {
+	case client.AlertChannelWebhook:
+		u, err := url.Parse(in.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("%w: url must be an absolute http(s) URL", ErrInvalidAlertSubscription)
+		}
+		host, emails = u.Host, nil
+	case client.AlertChannelEmail:
+		if len(emails) == 0 {
+			return nil, fmt.Errorf("%w: emails are required for the email channel", ErrInvalidAlertSubscription)
+		}
+		for _, e := range emails {
+			if !strings.Contains(e, "@") {
+				return nil, fmt.Errorf("%w: invalid email %q", ErrInvalidAlertSubscription, e)
+			}
+		}
+		host, in.URL, in.Secret = strings.Join(emails, ", "), "", ""
+	default:
+		return nil, fmt.Errorf("%w: unknown channel %q (want %s or %s)", ErrInvalidAlertSubscription, in.Channel, client.AlertChannelWebhook, client.AlertChannelEmail)
+	}
+
+	in.MinSeverity = strings.ToLower(strings.TrimSpace(in.MinSeverity))
+	if in.MinSeverity != "" && !slices.Contains(client.AlertSeverities, in.MinSeverity) {
+		return nil, fmt.Errorf("%w: unknown min_severity %q (want one of %s)", ErrInvalidAlertSubscription, in.MinSeverity, strings.Join(client.AlertSeverities, ", "))
+	}
+
+	now := time.Now()
+	sub := &client.AlertSubscription{
+		ID:          ulid.NewAt(now),
+		Name:        in.Name,
+		Cities:      cleanList(in.Cities),
+		Verticals:   cleanList(in.Verticals),
+		Types:       cleanList(in.Types),
+		MinSeverity: in.MinSeverity,
+		Channel:     in.Channel,
+		URL:         in.URL,
+		Secret:      in.Secret,
+		HasSecret:   in.Secret != "",
+		Emails:      emails,
+		By:          in.By,
+		CreatedAt:   now,
+	}
+	if err := storage.SaveAlertSubscription(ctx, sub); err != nil {
+		return nil, err
+	}
+	log.Printf("🔔 Alert subscription %s for %s added by %s: %s to %s", sub.ID, sub.Name, orAnonymous(sub.By), alertScope(sub), host)
+	out := *sub
+	out.Secret = ""
+	return &out, nil
+}
+
+// DeleteAlertSubscription removes an alert subscription
+func (s *Service) DeleteAlertSubscription(ctx context.Context, id string) error {
+	found, err := storage.DeleteAlertSubscription(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("%w: %s", ErrAlertSubscriptionNotFound, id)
+	}
+	log.Printf("🔔 Alert subscription %s removed", id)
+	return nil
+}
+
+// cleanList trims values and drops empty and repeated ones, ignoring case
+func cleanList(values []string) []string {
+	var out []string
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v != "" && !slices.ContainsFunc(out, func(o string) bool { return strings.EqualFold(o, v) }) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func alertScope(sub *client.AlertSubscription) string {
+	var parts []string
+	if len(sub.Cities) > 0 {
+		parts = append(parts, strings.Join(sub.Cities, "/"))
+	}
+	if len(sub.Verticals) > 0 {
+		parts = append(parts, strings.Join(sub.Verticals, "/"))
+	}
+	if len(parts) == 0 {
+		return "every alert"
+	}
+	return "alerts in " + strings.Join(parts, ", ")
+}
@@ -0,0 +1,258 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/llm"
+	"im-ai-voice/internal/profile"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== ANALYSIS IMPORT ====================
+// Analyses produced before adopting this service (e.g. by an older script)
+// can be imported in bulk, one JSON analysis per line. Each is validated and
+// normalized to the current schema, then they're replayed oldest first
+// through profile building, as if the calls had just been analyzed, and
+// the dates they fall on are re-aggregated. Calls already analyzed are
+// skipped, so an import can be re-run after fixing the lines that failed.
+
+const (
+	analysisImportMaxLines     = 100_000
+	analysisImportMaxLineBytes = 8 << 20
+)
+
+var ErrInvalidAnalysisImport = errors.New("invalid analysis import")
+
+// importedAnalysis is an analysis line, accepting gluser_id for seller_id as the ingest API does
+type importedAnalysis struct {
+	client.AnalysisResult
+	GluserID string `json:"gluser_id"`
+}
+
+type importItem struct {
+	line     int
+	analysis client.AnalysisResult
+	ht       *client.HackathonTranscript // Seller attributes recovered from user_info, nil without them
+}
+
+// ImportAnalyses imports NDJSON analyses from r. A line that fails doesn't
+// stop the import; a body that can't be read does.
+func (s *Service) ImportAnalyses(ctx context.Context, r io.Reader, dryRun bool) (*client.AnalysisImportResult, error) {
+	result := &client.AnalysisImportResult{DryRun: dryRun, Dates: []string{}, Problems: []client.AnalysisImportIssue{}}
+	problem := func(line int, callID, status, msg string) {
+		result.Problems = append(result.Problems, client.AnalysisImportIssue{Line: line, CallID: callID, Status: status, Error: msg})
+		if status == client.ImportSkipped {
+			result.Skipped++
+		} else {
+			result.Failed++
+		}
+	}
+
+	var items []importItem
+	seen := make(map[string]int)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64<<10), analysisImportMaxLineBytes)
+	now := time.Now()
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		if result.Lines++; result.Lines > analysisImportMaxLines {
+			return nil, fmt.Errorf("%w: more than %d analyses, split the file", ErrInvalidAnalysisImport, analysisImportMaxLines)
+		}
+
+		var in importedAnalysis
+		if err := json.Unmarshal([]byte(text), &in); err != nil {
+			problem(line, "", client.ImportFailed, "invalid JSON: "+err.Error())
+			continue
+		}
+		ar := in.AnalysisResult
+		if ar.SellerID == "" {
+			ar.SellerID = in.GluserID
+		}
+		if err := normalizeImportedAnalysis(&ar, now); err != nil {
+			problem(line, ar.CallID, client.ImportFailed, err.Error())
+			continue
+		}
+		if first, ok := seen[ar.CallID]; ok {
+			problem(line, ar.CallID, client.ImportSkipped, fmt.Sprintf("call already on line %d", first))
+			continue
+		}
+		seen[ar.CallID] = line
+		if storage.AnalysisExistsInMongo(ctx, ar.CallID) || storage.AnalysisExists(fmt.Sprintf("gluser_%s_call_%s", ar.SellerID, ar.CallID)) {
+			problem(line, ar.CallID, client.ImportSkipped, "call already analyzed")
+			continue
+		}
+		items = append(items, importItem{line: line, analysis: ar, ht: importedSeller(&ar)})
+	}
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return nil, fmt.Errorf("%w: line %d is longer than %d MB", ErrInvalidAnalysisImport, result.Lines+1, analysisImportMaxLineBytes>>20)
+		}
+		return nil, fmt.Errorf("failed to read body: %w", err)
+	}
+	if result.Lines == 0 {
+		return nil, fmt.Errorf("%w: request body has no analyses", ErrInvalidAnalysisImport)
+	}
+
+	// Profiles are built call by call, so they're replayed in the order the calls happened
+	sort.SliceStable(items, func(i, j int) bool { return items[i].analysis.Timestamp.Before(items[j].analysis.Timestamp) })
+
+	sellers := make(map[string]bool)
+	dates := make(map[string]bool)
+	for _, it := range items {
+		if ctx.Err() != nil {
+			return result, ctx.Err()
+		}
+		ar := it.analysis
+		if !dryRun {
+			if err := importAnalysis(ctx, &ar, it.ht); err != nil {
+				problem(it.line, ar.CallID, client.ImportFailed, err.Error())
+				continue
+			}
+		}
+		result.Imported++
+		sellers[ar.SellerID] = true
+		dates[config.BusinessDate(ar.Timestamp)] = true
+	}
+	result.Sellers = len(sellers)
+	sort.Slice(result.Problems, func(i, j int) bool { return result.Problems[i].Line < result.Problems[j].Line })
+
+	for date := range dates {
+		result.Dates = append(result.Dates, date)
+	}
+	sort.Strings(result.Dates)
+	if !dryRun {
+		for _, date := range result.Dates {
+			if _, err := s.RunAggregation(ctx, date); err != nil {
+				log.Printf("   ⚠️ Aggregation after import failed for %s: %v", date, err)
+			}
+		}
+		log.Printf("📥 Imported %d analyses (%d skipped, %d failed) for %d sellers over %d dates",
+			result.Imported, result.Skipped, result.Failed, result.Sellers, len(result.Dates))
+	}
+	return result, nil
+}
+
+// importAnalysis builds the call into its seller's profile and stores it,
+// as the watcher does for a freshly analyzed call
+func importAnalysis(ctx context.Context, ar *client.AnalysisResult, ht *client.HackathonTranscript) error {
+	if ar.Call == nil && ht != nil {
+		ar.Call = callChannel(ht)
+	}
+	if !ar.Blocked {
+		if _, _, err := profile.UpdateSellerProfile(ctx, ar.SellerID, ar, ht); err != nil {
+			return fmt.Errorf("failed to update seller profile: %w", err)
+		}
+	}
+	if err := storage.SaveAnalysisWithGluserID(ctx, *ar, ar.SellerID, ar.CallID); err != nil {
+		return fmt.Errorf("failed to save analysis: %w", err)
+	}
+	return nil
+}
+
+// normalizeImportedAnalysis checks an imported analysis has what storage
+// requires and maps older spellings onto the current schema
+func normalizeImportedAnalysis(ar *client.AnalysisResult, now time.Time) error {
+	ar.CallID = strings.TrimSpace(ar.CallID)
+	ar.SellerID = strings.TrimSpace(ar.SellerID)
+	switch {
+	case ar.CallID == "":
+		return fmt.Errorf("call_id is required")
+	case ar.SellerID == "":
+		return fmt.Errorf("seller_id (or gluser_id) is required")
+	case ar.Timestamp.IsZero():
+		return fmt.Errorf("timestamp is required")
+	case ar.Timestamp.After(now.Add(time.Hour)):
+		return fmt.Errorf("timestamp %s is in the future", ar.Timestamp.Format(time.RFC3339))
+	}
+
+	if !ar.Blocked && strings.TrimSpace(ar.Intent.Sentiment) == "" {
+		ar.Intent.Sentiment = "Neutral" // Only blocked calls go without
+	}
+	llm.NormalizeAnalysis(ar)
+	for i := range ar.Issues {
+		ar.Issues[i].Bucket = canonicalBucket(ar.Issues[i].Bucket)
+		if strings.TrimSpace(ar.Issues[i].Problem) == "" {
+			ar.Issues[i].Problem = ar.Issues[i].Bucket
+		}
+	}
+
+	ar.Churn.IsLikelyToChurn = normalizeLevel(ar.Churn.IsLikelyToChurn)
+	ar.Churn.DissatisfactionLevel = normalizeLevel(ar.Churn.DissatisfactionLevel)
+	if p := ar.Churn.RenewalProbability; p > 1 && p <= 100 {
+		ar.Churn.RenewalProbability = p / 100 // Given as a percentage
+	}
+	ar.Churn.RenewalProbability = min(max(ar.Churn.RenewalProbability, 0), 1)
+	ar.Intent.SatisfactionScore = min(max(ar.Intent.SatisfactionScore, 0), 10)
+
+	if ar.AnalyzedAt.IsZero() {
+		ar.AnalyzedAt = ar.Timestamp
+	}
+	if ar.LLMRaw == nil {
+		ar.LLMRaw = make(map[string]interface{})
+	}
+	ar.LLMRaw["imported_at"] = now
+	return nil
+}
+
+// canonicalBucket matches a bucket name to FeatureBuckets ignoring case, Other when it names none
+func canonicalBucket(bucket string) string {
+	bucket = strings.TrimSpace(bucket)
+	for _, b := range config.FeatureBuckets {
+		if strings.EqualFold(b, bucket) {
+			return b
+		}
+	}
+	return "Other"
+}
+
+// normalizeLevel maps a low/medium/high level onto its lowercase spelling, empty when unknown
+func normalizeLevel(level string) string {
+	switch l := strings.ToLower(strings.TrimSpace(level)); l {
+	case "low", "medium", "high":
+		return l
+	default:
+		return ""
+	}
+}
+
+// importedSeller recovers the seller attributes the watcher records in
+// llm_raw_response.user_info, so imported profiles get their city, vertical
+// and customer type
+func importedSeller(ar *client.AnalysisResult) *client.HackathonTranscript {
+	info, ok := ar.LLMRaw["user_info"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	b, err := json.Marshal(info)
+	if err != nil {
+		return nil
+	}
+	var ht client.HackathonTranscript
+	var typeErr *json.UnmarshalTypeError
+	if err := json.Unmarshal(b, &ht); err != nil && !errors.As(err, &typeErr) {
+		return nil // Fields of another type are left out, the rest still apply
+	}
+	ht.GluserID, ht.ClickToCallID = ar.SellerID, ar.CallID
+	if categories, ok := ar.LLMRaw["seller_categories"].([]interface{}); ok {
+		for _, c := range categories {
+			if name, ok := c.(string); ok {
+				ht.SellerCategories = append(ht.SellerCategories, client.SellerCategory{McatName: name})
+			}
+		}
+	}
+	return &ht
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/storage"
+	"im-ai-voice/internal/ulid"
+)
+
+// ==================== ANNOTATIONS ====================
+// QA reviewers annotate calls: a comment anchored to a turn (line) of the
+// analysis's English transcript, or to a character span of it. The anchored
+// text is kept as the quote, so the annotation still reads right after the
+// call is re-analyzed. Annotations are served with the analysis and rolled
+// up per agent for coaching.
+
+var (
+	ErrInvalidAnnotation  = errors.New("invalid annotation")
+	ErrAnnotationNotFound = errors.New("annotation not found")
+)
+
+const (
+	annotationsDefaultLimit = 100
+	annotationsMaxLimit     = 1000
+	maxAnnotationComment    = 2000
+)
+
+// AnnotateCall anchors a reviewer's comment to the call's transcript
+func (s *Service) AnnotateCall(ctx context.Context, callID string, in client.AnnotationRequest) (*client.Annotation, error) {
+	in.Comment = strings.TrimSpace(in.Comment)
+	in.Category = strings.TrimSpace(in.Category)
+	switch {
+	case in.Comment == "":
+		return nil, fmt.Errorf("%w: comment is required", ErrInvalidAnnotation)
+	case len(in.Comment) > maxAnnotationComment:
+		return nil, fmt.Errorf("%w: comment is longer than %d characters", ErrInvalidAnnotation, maxAnnotationComment)
+	}
+
+	ar, err := s.GetCallAnalysis(ctx, callID)
+	if err != nil || ar == nil {
+		return nil, fmt.Errorf("%w: %s", ErrCallNotFound, callID)
+	}
+	quote, err := annotationQuote(ar.TranscriptEn, in)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	a := &client.Annotation{
+		ID:        ulid.NewAt(now),
+		CallID:    callID,
+		GluserID:  ar.SellerID,
+		Turn:      in.Turn,
+		Start:     in.Start,
+		End:       in.End,
+		Quote:     quote,
+		Comment:   in.Comment,
+		Category:  in.Category,
+		Author:    in.Author,
+		CreatedAt: now,
+	}
+	if rt, err := storage.LoadRawTranscript(callID); err == nil {
+		a.AgentID = rt.AgentID
+	}
+	if err := storage.SaveAnnotation(ctx, a); err != nil {
+		return nil, err
+	}
+	log.Printf("📝 Call %s annotated by %s", callID, orAnonymous(a.Author))
+	return a, nil
+}
+
+// annotationQuote returns the transcript text the request anchors to: a
+// turn, or a span given by start and end
+func annotationQuote(transcript string, in client.AnnotationRequest) (string, error) {
+	switch {
+	case in.Turn != nil && (in.Start != nil || in.End != nil):
+		return "", fmt.Errorf("%w: anchor to a turn or to start and end, not both", ErrInvalidAnnotation)
+	case in.Turn != nil:
+		turns := strings.Split(transcript, "\n")
+		if *in.Turn < 0 || *in.Turn >= len(turns) {
+			return "", fmt.Errorf("%w: turn must be 0-%d", ErrInvalidAnnotation, len(turns)-1)
+		}
+		return strings.TrimSpace(turns[*in.Turn]), nil
+	case in.Start != nil && in.End != nil:
+		text := []rune(transcript)
+		if *in.Start < 0 || *in.End <= *in.Start || *in.End > len(text) {
+			return "", fmt.Errorf("%w: start and end must satisfy 0 <= start < end <= %d", ErrInvalidAnnotation, len(text))
+		}
+		return string(text[*in.Start:*in.End]), nil
+	default:
+		return "", fmt.Errorf("%w: turn, or start and end, is required", ErrInvalidAnnotation)
+	}
+}
+
+// CallAnnotations returns a call's annotations, newest first
+func (s *Service) CallAnnotations(ctx context.Context, callID string) ([]client.Annotation, error) {
+	return storage.LoadAnnotations(ctx, storage.AnnotationQuery{CallID: callID})
+}
+
+// DeleteAnnotation removes one of a call's annotations
+func (s *Service) DeleteAnnotation(ctx context.Context, callID, id string) error {
+	found, err := storage.DeleteAnnotation(ctx, callID, id)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("%w: %s", ErrAnnotationNotFound, id)
+	}
+	log.Printf("📝 Annotation %s of call %s removed", id, callID)
+	return nil
+}
+
+// ListAnnotations returns the newest annotations matching q, with a
+// coaching rollup per agent over all of them
+func (s *Service) ListAnnotations(ctx context.Context, q storage.AnnotationQuery, limit int) (*client.AnnotationsReport, error) {
+	if limit <= 0 {
+		limit = annotationsDefaultLimit
+	}
+	if limit > annotationsMaxLimit {
+		limit = annotationsMaxLimit
+	}
+
+	all, err := storage.LoadAnnotations(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &client.AnnotationsReport{Annotations: []client.Annotation{}, Total: len(all), GeneratedAt: time.Now()}
+	agents := make(map[string]*client.AgentAnnotations)
+	calls := make(map[string]map[string]bool)
+	for _, a := range all {
+		agent := a.AgentID
+		if agent == "" {
+			agent = "unknown" // Watcher transcripts don't name the agent
+		}
+		t := agents[agent]
+		if t == nil {
+			t = &client.AgentAnnotations{AgentID: agent, ByCategory: make(map[string]int)}
+			agents[agent] = t
+			calls[agent] = make(map[string]bool)
+		}
+		t.Total++
+		category := a.Category
+		if category == "" {
+			category = "uncategorized"
+		}
+		t.ByCategory[category]++
+		if !calls[agent][a.CallID] {
+			calls[agent][a.CallID] = true
+			t.Calls++
+		}
+	}
+	report.ByAgent = make([]client.AgentAnnotations, 0, len(agents))
+	for _, t := range agents {
+		report.ByAgent = append(report.ByAgent, *t)
+	}
+	sort.Slice(report.ByAgent, func(i, j int) bool {
+		a, b := report.ByAgent[i], report.ByAgent[j]
+		if a.Total != b.Total {
+			return a.Total > b.Total
+		}
+		return a.AgentID < b.AgentID
+	})
+
+	if len(all) > limit {
+		all = all[:limit]
+	}
+	report.Annotations = append(report.Annotations, all...)
+	report.Count = len(report.Annotations)
+	return report, nil
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/aggregate"
+	"im-ai-voice/internal/llm"
+)
+
+// ==================== ASK ====================
+
+const maxQuestionLength = 500
+
+var (
+	ErrInvalidQuestion      = errors.New("invalid question")
+	ErrUnanswerableQuestion = errors.New("question can't be answered from the stored analytics")
+	ErrQuestionNotPlanned   = errors.New("question could not be translated, the LLM request failed") // The LLM error can carry Gemini's URL and response
+)
+
+// Ask answers a plain-language question: the LLM translates it into
+// analytics queries, which run against the stored analyses, and then
+// phrases the answer from their results. A failed answer doesn't fail the
+// request, the results are still returned.
+func (s *Service) Ask(ctx context.Context, question string) (*client.AskResponse, error) {
+	question = strings.TrimSpace(question)
+	switch {
+	case question == "":
+		return nil, fmt.Errorf("%w: question is required", ErrInvalidQuestion)
+	case len(question) > maxQuestionLength:
+		return nil, fmt.Errorf("%w: longer than %d characters", ErrInvalidQuestion, maxQuestionLength)
+	case s.ai == nil:
+		return nil, fmt.Errorf("AI client not configured")
+	}
+
+	plan, err := s.ai.PlanQuestion(ctx, question, aggregate.QuerySchema())
+	if err != nil {
+		log.Printf("⚠️ Failed to translate question %q: %v", question, err)
+		if errors.Is(err, llm.ErrQuotaExceeded) {
+			return nil, fmt.Errorf("%w: %w", ErrQuestionNotPlanned, llm.ErrQuotaExceeded)
+		}
+		return nil, ErrQuestionNotPlanned
+	}
+	if len(plan.Queries) == 0 {
+		reason := plan.Unanswerable
+		if reason == "" {
+			reason = "no queries apply"
+		}
+		return nil, fmt.Errorf("%w: %s", ErrUnanswerableQuestion, reason)
+	}
+
+	for i := range plan.Queries {
+		if _, _, err := aggregate.NormalizeQuery(&plan.Queries[i]); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrUnanswerableQuestion, err)
+		}
+	}
+
+	resp := &client.AskResponse{Question: question, GeneratedAt: time.Now()}
+	for _, q := range plan.Queries {
+		result, err := aggregate.RunQuery(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		resp.Results = append(resp.Results, *result)
+	}
+
+	if resp.Answer, err = s.ai.AnswerQuestion(ctx, question, resp.Results); err != nil {
+		log.Printf("⚠️ Failed to answer question %q: %v", question, err)
+		resp.AnswerError = "answer unavailable, the LLM request failed"
+	}
+	log.Printf("💬 Answered %q with %d queries", question, len(resp.Results))
+	return resp, nil
+}
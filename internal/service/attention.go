@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== ATTENTION ACKNOWLEDGMENTS ====================
+
+var (
+	ErrSellerNotFound = errors.New("seller not found")
+	ErrNotFlagged     = errors.New("seller does not need attention")
+	ErrSnoozeInPast   = errors.New("until must be in the future")
+)
+
+// defaultSnooze is how long a snooze without an until lasts
+const defaultSnooze = 24 * time.Hour
+
+// AcknowledgeAttention acknowledges a seller's current attention flag,
+// silencing its alerts until the reason changes
+func (s *Service) AcknowledgeAttention(ctx context.Context, gluserID string, in client.AttentionAckRequest) (*client.AttentionAck, error) {
+	return s.setAttentionAck(ctx, gluserID, client.AttentionAcknowledged, in)
+}
+
+// SnoozeAttention sets a seller's current attention flag aside until
+// in.Until, 24 hours by default, or until the reason changes
+func (s *Service) SnoozeAttention(ctx context.Context, gluserID string, in client.AttentionAckRequest) (*client.AttentionAck, error) {
+	return s.setAttentionAck(ctx, gluserID, client.AttentionSnoozed, in)
+}
+
+func (s *Service) setAttentionAck(ctx context.Context, gluserID, state string, in client.AttentionAckRequest) (*client.AttentionAck, error) {
+	p, err := storage.LoadSellerProfile(ctx, gluserID)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, fmt.Errorf("%w: %s", ErrSellerNotFound, gluserID)
+	}
+	if !p.CurrentStatus.NeedsAttention {
+		return nil, fmt.Errorf("%w: %s", ErrNotFlagged, gluserID)
+	}
+
+	now := time.Now()
+	ack := &client.AttentionAck{
+		GluserID:  gluserID,
+		State:     state,
+		Reason:    p.CurrentStatus.AttentionReason,
+		Note:      in.Note,
+		By:        in.By,
+		CreatedAt: now,
+	}
+	if state == client.AttentionSnoozed {
+		until := now.Add(defaultSnooze)
+		if in.Until != nil {
+			if !in.Until.After(now) {
+				return nil, ErrSnoozeInPast
+			}
+			until = *in.Until
+		}
+		ack.SnoozedUntil = &until
+	}
+
+	if err := storage.SaveAttentionAck(ctx, ack); err != nil {
+		return nil, err
+	}
+	log.Printf("🔕 Attention flag for %s %s by %s: %s", gluserID, state, orAnonymous(ack.By), ack.Reason)
+	return ack, nil
+}
+
+func orAnonymous(by string) string {
+	if by == "" {
+		return "anonymous"
+	}
+	return by
+}
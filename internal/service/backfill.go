@@ -0,0 +1,245 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+)
+
+// ==================== THROTTLED BACKFILL ====================
+// A replay re-analyzes every call it has no cached analysis for, which
+// competes with live traffic for Gemini quota. Throttled, it only works in
+// the off-peak BACKFILL_HOURS and pauses while live ingestion is busy
+// (BACKFILL_MAX_LIVE_CALLS ingested in the last BACKFILL_WINDOW) or
+// analyses are failing (BACKFILL_MAX_ERROR_RATE of the live and replayed
+// analyses in the window), checking again every minute. Live load is read
+// from the event log, so it covers every instance. The replay's position
+// is checkpointed after each call, so a replay stopped while paused carries
+// on from there with Resume.
+
+// BackfillThrottle decides when a throttled replay may run
+type BackfillThrottle struct {
+	StartMinute  int // Minutes after midnight in BUSINESS_TIMEZONE the off-peak hours start
+	EndMinute    int // And end; before the start, the hours run past midnight
+	MaxLiveCalls int // Live transcripts ingested in the window that pause the replay, 0 disables
+	MaxErrorRate float64
+	Window       time.Duration
+	MinAttempts  int // Analyses the window needs before its error rate counts
+
+	outcomes []attemptOutcome // The replay's own analyses in the window
+	paused   string           // Why the replay is paused, empty while running
+}
+
+// attemptOutcome is one of the replay's analyses
+type attemptOutcome struct {
+	at     time.Time
+	failed bool
+}
+
+// BackfillThrottleFromEnv reads BACKFILL_HOURS, BACKFILL_MAX_LIVE_CALLS,
+// BACKFILL_MAX_ERROR_RATE and BACKFILL_WINDOW
+func BackfillThrottleFromEnv() (*BackfillThrottle, error) {
+	hours := os.Getenv("BACKFILL_HOURS")
+	if hours == "" {
+		hours = config.DEFAULT_BACKFILL_HOURS
+	}
+	start, end, err := parseBackfillHours(hours)
+	if err != nil {
+		return nil, fmt.Errorf("invalid BACKFILL_HOURS=%q: %w", hours, err)
+	}
+	t := &BackfillThrottle{
+		StartMinute:  start,
+		EndMinute:    end,
+		MaxLiveCalls: config.DEFAULT_BACKFILL_MAX_LIVE_CALLS,
+		MaxErrorRate: config.DEFAULT_BACKFILL_MAX_ERROR_RATE,
+		Window:       config.EnvDuration("BACKFILL_WINDOW", config.DEFAULT_BACKFILL_WINDOW),
+		MinAttempts:  config.DEFAULT_PIPELINE_FAILURE_MIN_ATTEMPTS,
+	}
+	if v := os.Getenv("BACKFILL_MAX_LIVE_CALLS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			t.MaxLiveCalls = n
+		} else {
+			log.Printf("⚠️ Invalid BACKFILL_MAX_LIVE_CALLS=%q, using %d", v, t.MaxLiveCalls)
+		}
+	}
+	if v := os.Getenv("BACKFILL_MAX_ERROR_RATE"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil && f >= 0 && f <= 1 {
+			t.MaxErrorRate = f
+		} else {
+			log.Printf("⚠️ Invalid BACKFILL_MAX_ERROR_RATE=%q, using %g", v, t.MaxErrorRate)
+		}
+	}
+	return t, nil
+}
+
+// parseBackfillHours parses "HH:MM-HH:MM" into minutes after midnight
+func parseBackfillHours(s string) (int, int, error) {
+	from, to, ok := strings.Cut(s, "-")
+	if !ok {
+		return 0, 0, errors.New("want HH:MM-HH:MM")
+	}
+	var minutes [2]int
+	for i, v := range []string{from, to} {
+		t, err := time.Parse("15:04", strings.TrimSpace(v))
+		if err != nil {
+			return 0, 0, errors.New("want HH:MM-HH:MM")
+		}
+		minutes[i] = t.Hour()*60 + t.Minute()
+	}
+	if minutes[0] == minutes[1] {
+		return 0, 0, errors.New("the hours are empty")
+	}
+	return minutes[0], minutes[1], nil
+}
+
+// offPeak reports whether now is within the off-peak hours
+func (t *BackfillThrottle) offPeak(now time.Time) bool {
+	local := now.In(config.BusinessTZ)
+	m := local.Hour()*60 + local.Minute()
+	if t.StartMinute < t.EndMinute {
+		return m >= t.StartMinute && m < t.EndMinute
+	}
+	return m >= t.StartMinute || m < t.EndMinute
+}
+
+// record adds one of the replay's analyses to the error rate window
+func (t *BackfillThrottle) record(failed bool) {
+	t.outcomes = append(t.outcomes, attemptOutcome{at: time.Now(), failed: failed})
+}
+
+// holdReason returns why the replay mustn't run now, empty when it may
+func (t *BackfillThrottle) holdReason(ctx context.Context, now time.Time) (string, error) {
+	if !t.offPeak(now) {
+		return "outside BACKFILL_HOURS", nil
+	}
+	if t.MaxLiveCalls == 0 && t.MaxErrorRate == 0 {
+		return "", nil
+	}
+
+	since := now.Add(-t.Window)
+	live, attempts, failed := 0, 0, 0
+	count := func(typ string, n *int) error {
+		return dayEvents(ctx, typ, since, now, func(client.Event) { *n++ })
+	}
+	for _, c := range []struct {
+		typ string
+		n   *int
+	}{
+		{client.EventIngested, &live},
+		{client.EventAnalyzed, &attempts},
+		{client.EventAnalysisFailed, &failed},
+	} {
+		if err := count(c.typ, c.n); err != nil {
+			return "", err
+		}
+	}
+	attempts += failed
+
+	kept := t.outcomes[:0]
+	for _, o := range t.outcomes {
+		if o.at.Before(since) {
+			continue
+		}
+		kept = append(kept, o)
+		attempts++
+		if o.failed {
+			failed++
+		}
+	}
+	t.outcomes = kept
+
+	if t.MaxLiveCalls > 0 && live >= t.MaxLiveCalls {
+		return fmt.Sprintf("%d live transcripts in the last %s", live, t.Window), nil
+	}
+	if t.MaxErrorRate > 0 && attempts >= t.MinAttempts && float64(failed)/float64(attempts) >= t.MaxErrorRate {
+		return fmt.Sprintf("%d of %d analyses failed in the last %s", failed, attempts, t.Window), nil
+	}
+	return "", nil
+}
+
+// wait blocks until the replay may run, logging when it pauses and resumes.
+// Failing to read the event log counts as a reason to hold off.
+func (t *BackfillThrottle) wait(ctx context.Context) error {
+	for {
+		reason, err := t.holdReason(ctx, time.Now())
+		if err != nil {
+			reason = err.Error()
+		}
+		if reason == "" {
+			if t.paused != "" {
+				log.Printf("▶️ Replay resumed")
+				t.paused = ""
+			}
+			return nil
+		}
+		if reason != t.paused {
+			log.Printf("⏸️ Replay paused: %s", reason)
+			t.paused = reason
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(config.BACKFILL_CHECK_INTERVAL):
+		}
+	}
+}
+
+// replayCheckpoint is how far a replay got: the last call it reached, in
+// replay order, and the dates it touched, still to be aggregated
+type replayCheckpoint struct {
+	Options   ReplayOptions `json:"options"`
+	StartedAt time.Time     `json:"started_at"`
+	CallID    string        `json:"call_id"`
+	Timestamp time.Time     `json:"timestamp"`
+	Done      int           `json:"done"`
+	Dates     []string      `json:"dates"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+// replayCheckpointPath is kept beside the LLM cache, which a resumed replay
+// reads its analyses back from
+func replayCheckpointPath() string {
+	return filepath.Join(config.LLM_CACHE_DIR, "replay.checkpoint")
+}
+
+// passed reports whether a replay item is at or before the checkpoint
+func (c *replayCheckpoint) passed(it replayItem) bool {
+	if !it.timestamp.Equal(c.Timestamp) {
+		return it.timestamp.Before(c.Timestamp)
+	}
+	return it.callID <= c.CallID
+}
+
+func loadReplayCheckpoint() (*replayCheckpoint, error) {
+	b, err := os.ReadFile(replayCheckpointPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var c replayCheckpoint
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func saveReplayCheckpoint(c *replayCheckpoint) error {
+	c.UpdatedAt = time.Now()
+	b, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(replayCheckpointPath(), b, 0644)
+}
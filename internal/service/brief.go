@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== CALL PREP BRIEF ====================
+// Before an outbound retention call, the agent gets a one-pager: the
+// seller's standing, open grievances and promises from the profile and
+// commitments, and who they are, what to raise and what not to say from the
+// LLM. A failed LLM brief doesn't fail the request, the facts still help.
+
+// briefCalls is how many of the latest calls the LLM is told about
+const briefCalls = 3
+
+// SellerBrief builds a seller's call prep brief
+func (s *Service) SellerBrief(ctx context.Context, gluserID string) (*client.SellerBrief, error) {
+	p, err := storage.LoadSellerProfile(ctx, gluserID)
+	if err != nil {
+		return nil, fmt.Errorf("error loading profile: %w", err)
+	}
+	if p == nil {
+		return nil, fmt.Errorf("%w: %s", ErrSellerNotFound, gluserID)
+	}
+
+	status := p.CurrentStatus
+	b := &client.SellerBrief{
+		GluserID:      p.GluserID,
+		CustomerType:  p.CustomerType,
+		CityName:      p.CityName,
+		Vertical:      p.Vertical,
+		VintageMonths: p.VintageMonths,
+		JourneyStage:  p.JourneyStage,
+		HealthScore:   status.HealthScore,
+		HealthLabel:   status.HealthLabel,
+		ChurnRisk:     status.ChurnRisk,
+		Sentiment:     status.Sentiment,
+		TotalCalls:    p.TotalCalls,
+		OpenIssues:    append([]client.TrackedIssue{}, p.ActiveIssues...),
+		Promises:      []client.Commitment{},
+		TalkingPoints: []string{},
+		Avoid:         []string{},
+		GeneratedAt:   time.Now(),
+	}
+	if len(p.CallHistory) > 0 {
+		b.LastCall = &p.CallHistory[0]
+	}
+
+	commitments, err := storage.LoadCommitments(ctx, storage.CommitmentQuery{GluserID: gluserID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load commitments: %w", err)
+	}
+	for _, c := range commitments {
+		if c.Status != client.CommitmentKept || (b.LastCall != nil && c.CallID == b.LastCall.CallID) {
+			b.Promises = append(b.Promises, c)
+		}
+	}
+	sort.Slice(b.Promises, func(i, j int) bool {
+		if !b.Promises[i].CallTime.Equal(b.Promises[j].CallTime) {
+			return b.Promises[i].CallTime.After(b.Promises[j].CallTime)
+		}
+		return b.Promises[i].ID < b.Promises[j].ID
+	})
+
+	if s.ai == nil {
+		b.BriefError = "brief unavailable, AI client not configured"
+		return b, nil
+	}
+	churnReason := ""
+	if b.LastCall != nil {
+		if a, err := s.GetCallAnalysis(ctx, b.LastCall.CallID); err == nil && a != nil {
+			churnReason = a.Churn.ChurnReason
+		}
+	}
+	written, err := s.ai.WriteCallBrief(ctx, briefFacts(b, p, churnReason))
+	if err != nil {
+		log.Printf("⚠️ Call brief failed for %s: %v", gluserID, err)
+		b.BriefError = "brief unavailable, the LLM request failed" // err can carry Gemini's URL and response
+		return b, nil
+	}
+	b.Who, b.TalkingPoints, b.Avoid = written.Who, written.TalkingPoints, written.Avoid
+	return b, nil
+}
+
+// briefFacts renders what the brief is written from as plain text
+func briefFacts(b *client.SellerBrief, p *client.SellerProfile, churnReason string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "SELLER %s: %s plan, %s, %s, %d months on IndiaMART\n", b.GluserID, b.CustomerType, b.Vertical, b.CityName, b.VintageMonths)
+	if b.JourneyStage != "" {
+		fmt.Fprintf(&sb, "Journey stage: %s\n", b.JourneyStage)
+	}
+	fmt.Fprintf(&sb, "Health: %d/100 (%s), churn risk %s, sentiment %s, %d calls so far\n", b.HealthScore, b.HealthLabel, b.ChurnRisk, b.Sentiment, b.TotalCalls)
+	if churnReason != "" {
+		fmt.Fprintf(&sb, "Churn reason on the last call: %s\n", churnReason)
+	}
+	if len(p.SellerCategories) > 0 {
+		fmt.Fprintf(&sb, "Sells: %s\n", strings.Join(p.SellerCategories, ", "))
+	}
+
+	sb.WriteString("\nLATEST CALLS (newest first):\n")
+	if len(p.CallHistory) == 0 {
+		sb.WriteString("  none\n")
+	}
+	for _, c := range p.CallHistory[:min(len(p.CallHistory), briefCalls)] {
+		fmt.Fprintf(&sb, "  %s, %s: %s\n", config.BusinessDate(c.Timestamp), c.Sentiment, c.Summary)
+	}
+
+	sb.WriteString("\nOPEN ISSUES:\n")
+	if len(b.OpenIssues) == 0 {
+		sb.WriteString("  none\n")
+	}
+	for _, issue := range b.OpenIssues {
+		fmt.Fprintf(&sb, "  [%s, %s] %s - raised %s, mentioned on %d calls", issue.Bucket, issue.Severity, issue.Problem,
+			config.BusinessDate(issue.FirstReportedAt), issue.MentionCount)
+		if issue.ReopenCount > 0 {
+			fmt.Fprintf(&sb, ", reopened %d times", issue.ReopenCount)
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("\nPROMISES MADE TO THE SELLER:\n")
+	if len(b.Promises) == 0 {
+		sb.WriteString("  none\n")
+	}
+	for _, c := range b.Promises {
+		fmt.Fprintf(&sb, "  %s (made %s, due %s): %s", c.Status, config.BusinessDate(c.CallTime), c.DueDate, c.Promise)
+		if c.Evidence != "" {
+			fmt.Fprintf(&sb, " - %s", c.Evidence)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
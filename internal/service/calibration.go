@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/aggregate"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== SEVERITY CALIBRATION ====================
+// A weekly run compares the severities of the issues first reported over
+// the last SEVERITY_CALIBRATION_DAYS (leaving out the latest two weeks,
+// whose outcomes aren't in yet) with how they turned out, per bucket (see
+// aggregate.BuildSeverityCalibration). With SEVERITY_CALIBRATION_HINTS=true
+// the latest run's hints go into extraction prompts, reloaded with the
+// knowledge base on every instance.
+
+// ErrNoCalibration is returned when the calibration job hasn't run yet
+var ErrNoCalibration = errors.New("no severity calibration")
+
+// RunSeverityCalibration calibrates issue severities as of date and saves the run
+func (s *Service) RunSeverityCalibration(ctx context.Context, date string) (*client.SeverityCalibration, error) {
+	day, err := config.ParseBusinessDate(date)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date %q: %w", date, err)
+	}
+	to := day.AddDate(0, 0, 1-config.SEVERITY_CALIBRATION_MATURITY_DAYS)
+	from := day.AddDate(0, 0, 1-config.SEVERITY_CALIBRATION_DAYS)
+
+	profiles, err := storage.LoadAllSellerProfiles(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load seller profiles: %w", err)
+	}
+	report := aggregate.BuildSeverityCalibration(date, profiles, from, to, time.Now())
+	if err := storage.SaveSeverityCalibration(ctx, report); err != nil {
+		return nil, fmt.Errorf("failed to save severity calibration: %w", err)
+	}
+
+	var over, under int
+	for _, b := range report.Buckets {
+		switch b.Calibration {
+		case client.CalibrationOver:
+			over++
+		case client.CalibrationUnder:
+			under++
+		}
+	}
+	log.Printf("⚖️ Calibrated the severities of %d issues (%s to %s): %d buckets over-rated, %d under-rated",
+		report.Issues, report.From, report.To, over, under)
+	s.applySeverityHints(report)
+	return report, nil
+}
+
+// GetSeverityCalibration returns the calibration run of date, or the latest
+// run when date is empty
+func (s *Service) GetSeverityCalibration(ctx context.Context, date string) (*client.SeverityCalibration, error) {
+	var report *client.SeverityCalibration
+	var err error
+	if date == "" {
+		report, err = storage.LoadLatestSeverityCalibration(ctx)
+	} else {
+		report, err = storage.LoadSeverityCalibration(ctx, date)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if report == nil {
+		if date == "" {
+			return nil, ErrNoCalibration
+		}
+		return nil, fmt.Errorf("%w: for %s", ErrNoCalibration, date)
+	}
+	return report, nil
+}
+
+// severityHintsEnabled reports whether SEVERITY_CALIBRATION_HINTS puts calibration hints in prompts
+func severityHintsEnabled() bool {
+	return os.Getenv("SEVERITY_CALIBRATION_HINTS") == "true"
+}
+
+// applySeverityHints hands a calibration run's hints to the AI client
+func (s *Service) applySeverityHints(report *client.SeverityCalibration) {
+	if s.ai == nil || !severityHintsEnabled() || report == nil {
+		return
+	}
+	s.ai.SetSeverityHints(report.PromptHints)
+}
+
+// reloadSeverityHints hands the latest calibration run's hints to the AI client
+func (s *Service) reloadSeverityHints(ctx context.Context) {
+	if s.ai == nil || !severityHintsEnabled() {
+		return
+	}
+	report, err := storage.LoadLatestSeverityCalibration(ctx)
+	if err != nil {
+		log.Printf("⚠️ Failed to load severity calibration: %v", err)
+		return
+	}
+	s.applySeverityHints(report)
+}
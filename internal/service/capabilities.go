@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/acoustic"
+	"im-ai-voice/internal/archive"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/flags"
+	"im-ai-voice/internal/github"
+	"im-ai-voice/internal/llm"
+	"im-ai-voice/internal/notify"
+	"im-ai-voice/internal/recording"
+	"im-ai-voice/internal/storage"
+	"im-ai-voice/internal/vault"
+)
+
+// ==================== CAPABILITIES ====================
+// Deployments differ in what they have configured: MongoDB or local files,
+// webhooks, SMTP, GitHub, the PII vault, recordings. Capabilities reports
+// it from what this instance is running with, so the dashboard and
+// integrators can hide what isn't there instead of guessing from errors.
+
+// Capabilities describes what this deployment has turned on
+func (s *Service) Capabilities(ctx context.Context) (*client.Capabilities, error) {
+	subs, err := storage.LoadWebhookSubscriptions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load webhook subscriptions: %w", err)
+	}
+	flagList, err := flags.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	_, mailErr := notify.NewMailerFromEnv()
+
+	c := &client.Capabilities{
+		Storage:        "local",
+		MongoDB:        storage.IsMongoEnabled(),
+		FeatureBuckets: config.FeatureBuckets,
+		Embeddings:     client.CapabilityEmbeddings{Model: llm.GeminiEmbeddingModel},
+		Webhooks: client.CapabilityWebhooks{
+			Alerts:        os.Getenv("ALERT_WEBHOOK_URL") != "",
+			Tickets:       os.Getenv("TICKET_WEBHOOK_URL") != "",
+			Subscriptions: len(subs),
+			Digests:       notify.DigestWindows(),
+		},
+		Email:           mailErr == nil,
+		GitHubSync:      github.Enabled(),
+		PIIVault:        vault.Enabled(),
+		RecordingFetch:  recording.FetchEnabled(),
+		AcousticSignals: acoustic.Enabled(),
+		ShadowAnalysis:  s.shadow != nil,
+		LLMRecording:    llm.RecordingFromEnv(),
+		Flags:           flagList,
+		GeneratedAt:     time.Now(),
+	}
+	if c.MongoDB {
+		c.Storage = "mongodb"
+	}
+	if s.ai != nil {
+		c.Model = s.ai.Model()
+		c.PromptVersion = s.ai.PromptVersion()
+		c.BucketTaxonomyVersion = s.ai.BucketTaxonomyVersion()
+		c.Embeddings.KnowledgePassages = s.ai.KnowledgePassages()
+	}
+
+	analysis := client.CapabilityProvider{Purpose: "analysis", Name: "gemini", Configured: true, Detail: c.Model}
+	if s.ai == nil {
+		analysis = client.CapabilityProvider{Purpose: "analysis", Name: client.EngineRules, Configured: true, Detail: "keyword classifier"} // ANALYSIS_ENGINE=rules
+	}
+	c.Providers = []client.CapabilityProvider{
+		analysis,
+		{Purpose: "embeddings", Name: "gemini", Configured: s.ai != nil, Detail: llm.GeminiEmbeddingModel},
+		{Purpose: "storage", Name: c.Storage, Configured: true},
+		storeProvider("archive", archive.Archive),
+		storeProvider("recordings", recording.Store),
+		{Purpose: "email", Name: "smtp", Configured: c.Email},
+		{Purpose: "issues", Name: "github", Configured: c.GitHubSync},
+	}
+	return c, nil
+}
+
+// storeProvider describes an archive or recording store by its kind (s3 or
+// local), leaving out its bucket or directory
+func storeProvider(purpose string, store interface{ Name() string }) client.CapabilityProvider {
+	p := client.CapabilityProvider{Purpose: purpose}
+	if store == nil {
+		return p
+	}
+	p.Name, _, _ = strings.Cut(store.Name(), ":")
+	p.Configured = true
+	return p
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/llm"
+	"im-ai-voice/internal/storage"
+	"im-ai-voice/internal/ulid"
+)
+
+// ==================== CALL CHAT ====================
+// A reviewer investigating a call asks Gemini follow-up questions about it
+// ("did the agent ever confirm the refund amount?"). Each question is sent
+// with the call's transcript (transcript_en, the raw one when there's none),
+// what its analysis found and the conversation so far, since Gemini keeps no
+// state between requests. Sessions belong to the API key that opened them,
+// hold up to CALL_CHAT_TURNS questions and expire CALL_CHAT_TTL after their
+// last message.
+
+var (
+	ErrInvalidChat     = errors.New("invalid chat message")
+	ErrChatNotFound    = errors.New("chat session not found")
+	ErrChatFull        = errors.New("chat session has no questions left")
+	ErrChatBusy        = errors.New("chat session is still answering a question")
+	ErrChatNotAnswered = errors.New("question could not be answered, the LLM request failed") // The LLM error can carry Gemini's URL and response
+)
+
+// chatsAnswering holds the sessions with a question in flight on this
+// instance, so two at once can't overwrite each other's turn
+var chatsAnswering sync.Map
+
+// ChatAboutCall answers a reviewer's question about a call, opening a
+// session when in has none or continuing the reviewer's session
+func (s *Service) ChatAboutCall(ctx context.Context, callID, reviewer string, in client.CallChatRequest) (*client.CallChat, error) {
+	question := strings.TrimSpace(in.Message)
+	switch {
+	case question == "":
+		return nil, fmt.Errorf("%w: message is required", ErrInvalidChat)
+	case len(question) > config.CALL_CHAT_MAX_CHARS:
+		return nil, fmt.Errorf("%w: message is longer than %d characters", ErrInvalidChat, config.CALL_CHAT_MAX_CHARS)
+	case s.ai == nil:
+		return nil, fmt.Errorf("AI client not configured")
+	}
+
+	now := time.Now()
+	chat := &client.CallChat{SessionID: ulid.NewAt(now), CallID: callID, Reviewer: reviewer, Messages: []client.ChatMessage{}, CreatedAt: now}
+	if in.SessionID != "" {
+		var err error
+		if chat, err = s.GetCallChat(ctx, callID, in.SessionID, reviewer); err != nil {
+			return nil, err
+		}
+		if turns := callChatTurns(); chatQuestions(chat) >= turns {
+			return nil, fmt.Errorf("%w: it asked %d, start a new one", ErrChatFull, turns)
+		}
+	}
+	if _, busy := chatsAnswering.LoadOrStore(chat.SessionID, true); busy {
+		return nil, ErrChatBusy
+	}
+	defer chatsAnswering.Delete(chat.SessionID)
+
+	ar, err := s.GetCallAnalysis(ctx, callID)
+	if err != nil || ar == nil {
+		return nil, fmt.Errorf("%w: %s", ErrCallNotFound, callID)
+	}
+	chat.GluserID = ar.SellerID
+	transcript := ar.TranscriptEn
+	if transcript == "" {
+		if rt, err := storage.LoadRawTranscript(callID); err == nil {
+			transcript = rt.Transcript
+		}
+	}
+
+	answer, trimmed, err := s.ai.AnswerAboutCall(ctx, transcript, callChatFacts(ar), chat.Messages, question)
+	if err != nil {
+		log.Printf("⚠️ Failed to answer a question about call %s: %v", callID, err)
+		if errors.Is(err, llm.ErrQuotaExceeded) {
+			return nil, fmt.Errorf("%w: %w", ErrChatNotAnswered, llm.ErrQuotaExceeded)
+		}
+		return nil, ErrChatNotAnswered
+	}
+
+	answered := time.Now()
+	chat.Messages = append(chat.Messages,
+		client.ChatMessage{Role: client.ChatRoleReviewer, Text: question, At: now},
+		client.ChatMessage{Role: client.ChatRoleAssistant, Text: answer, At: answered})
+	chat.TranscriptTrimmed = chat.TranscriptTrimmed || trimmed
+	chat.UpdatedAt = answered
+	chat.ExpiresAt = answered.Add(config.EnvDuration("CALL_CHAT_TTL", config.DEFAULT_CALL_CHAT_TTL))
+	if err := storage.SaveCallChat(ctx, chat); err != nil {
+		return nil, fmt.Errorf("failed to save chat session: %w", err)
+	}
+	log.Printf("💬 Question %d about call %s answered for %s", chatQuestions(chat), callID, orAnonymous(reviewer))
+	return chat, nil
+}
+
+// GetCallChat returns a reviewer's session about a call. Sessions of other
+// reviewers or calls, and expired ones, aren't found.
+func (s *Service) GetCallChat(ctx context.Context, callID, sessionID, reviewer string) (*client.CallChat, error) {
+	chat, err := storage.LoadCallChat(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load chat session: %w", err)
+	}
+	if chat == nil || chat.CallID != callID || chat.Reviewer != reviewer {
+		return nil, fmt.Errorf("%w: %s", ErrChatNotFound, sessionID)
+	}
+	return chat, nil
+}
+
+// chatQuestions counts the questions a session asked
+func chatQuestions(chat *client.CallChat) int {
+	n := 0
+	for _, m := range chat.Messages {
+		if m.Role == client.ChatRoleReviewer {
+			n++
+		}
+	}
+	return n
+}
+
+// callChatTurns returns CALL_CHAT_TURNS, or the default when unset or invalid
+func callChatTurns() int {
+	if v := os.Getenv("CALL_CHAT_TURNS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return n
+		}
+		log.Printf("⚠️ Invalid CALL_CHAT_TURNS=%q, using %d", v, config.DEFAULT_CALL_CHAT_TURNS)
+	}
+	return config.DEFAULT_CALL_CHAT_TURNS
+}
+
+// callChatFacts describes what a call's analysis found, for Gemini
+func callChatFacts(ar *client.AnalysisResult) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Call %s with seller %s on %s", ar.CallID, ar.SellerID, ar.Timestamp.In(config.BusinessTZ).Format("2006-01-02 15:04"))
+	if ar.OriginalLang != "" {
+		fmt.Fprintf(&sb, ", in %s", ar.OriginalLang)
+	}
+	sb.WriteString("\n")
+	if ar.Provisional {
+		sb.WriteString("(Provisional analysis by keyword rules, Gemini was unavailable)\n")
+	}
+	if ar.CallSummary != "" {
+		fmt.Fprintf(&sb, "Summary: %s\n", ar.CallSummary)
+	}
+	fmt.Fprintf(&sb, "Sentiment: %s, satisfaction %d/5, resolved promptly: %t\n", ar.Intent.Sentiment, ar.Intent.SatisfactionScore, ar.Intent.PromptResolution)
+	if ar.Churn.IsLikelyToChurn != "" {
+		fmt.Fprintf(&sb, "Churn risk: %s", ar.Churn.IsLikelyToChurn)
+		if ar.Churn.ChurnReason != "" {
+			fmt.Fprintf(&sb, " (%s)", ar.Churn.ChurnReason)
+		}
+		sb.WriteString("\n")
+	}
+	if ar.AgentPerformance != "" {
+		fmt.Fprintf(&sb, "Agent performance: %s\n", ar.AgentPerformance)
+	}
+	if len(ar.Issues) > 0 {
+		sb.WriteString("Issues:\n")
+		for _, issue := range ar.Issues {
+			fmt.Fprintf(&sb, "- [%s, %s] %s", issue.Bucket, issue.Severity, issue.Problem)
+			if issue.ActionableSummary != "" {
+				fmt.Fprintf(&sb, " Action: %s", issue.ActionableSummary)
+			}
+			for _, e := range issue.Evidence {
+				fmt.Fprintf(&sb, " Quote: %q", e.Quote)
+			}
+			sb.WriteString("\n")
+		}
+	}
+	if len(ar.PriceMentions) > 0 {
+		sb.WriteString("Amounts said:\n")
+		for _, p := range ar.PriceMentions {
+			fmt.Fprintf(&sb, "- Rs %g", p.Amount)
+			if p.Kind != "" {
+				fmt.Fprintf(&sb, " %s", p.Kind)
+			}
+			if p.Product != "" {
+				fmt.Fprintf(&sb, " for %s", p.Product)
+			}
+			if p.Quote != "" {
+				fmt.Fprintf(&sb, ": %q", p.Quote)
+			}
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/aggregate"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== CHURN OUTCOMES ====================
+// The billing system reports which sellers renewed and which cancelled,
+// as JSON or a CSV export, and GET /analytics/churn-accuracy scores the
+// churn predictions made before those outcomes (see
+// aggregate.BuildChurnAccuracy). Predictions are told apart by the model
+// and prompt version recorded on their analyses.
+
+var (
+	ErrInvalidChurnOutcomes = errors.New("invalid churn outcomes")
+	ErrInvalidChurnOutcome  = errors.New("invalid churn outcome")
+)
+
+// Where outcomes came from, unless they say (ChurnOutcome.Source)
+const (
+	outcomeSourceAPI = "api"
+	outcomeSourceCSV = "csv"
+)
+
+// ParseChurnOutcomesCSV reads outcomes from CSV with a header row naming
+// its gluser_id, outcome and date columns, in any order; a source column is
+// optional. lines holds each outcome's line in the file.
+func ParseChurnOutcomesCSV(r io.Reader) (outcomes []client.ChurnOutcome, lines []int, err error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, nil, fmt.Errorf("%w: the CSV is empty", ErrInvalidChurnOutcomes)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidChurnOutcomes, err)
+	}
+	columns := map[string]int{"source": -1}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, required := range []string{"gluser_id", "outcome", "date"} {
+		if _, ok := columns[required]; !ok {
+			return nil, nil, fmt.Errorf("%w: the CSV header has no %s column", ErrInvalidChurnOutcomes, required)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		if i := columns[name]; i >= 0 && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", ErrInvalidChurnOutcomes, err)
+		}
+		if len(record) == 1 && strings.TrimSpace(record[0]) == "" {
+			continue // Blank line
+		}
+		if len(outcomes) == config.CHURN_OUTCOMES_MAX {
+			return nil, nil, fmt.Errorf("%w: more than %d outcomes, split the file", ErrInvalidChurnOutcomes, config.CHURN_OUTCOMES_MAX)
+		}
+		line, _ := cr.FieldPos(0)
+		source := field(record, "source")
+		if source == "" {
+			source = outcomeSourceCSV
+		}
+		outcomes = append(outcomes, client.ChurnOutcome{
+			GluserID: field(record, "gluser_id"),
+			Outcome:  field(record, "outcome"),
+			Date:     field(record, "date"),
+			Source:   source,
+		})
+		lines = append(lines, line)
+	}
+	return outcomes, lines, nil
+}
+
+// RecordChurnOutcomes validates and stores outcomes sent by the API key
+// named by, each on its own: one that fails doesn't stop the rest. lines,
+// when set, are the outcomes' CSV lines.
+func (s *Service) RecordChurnOutcomes(ctx context.Context, outcomes []client.ChurnOutcome, lines []int, by string) (*client.ChurnOutcomeResult, error) {
+	if len(outcomes) > config.CHURN_OUTCOMES_MAX {
+		return nil, fmt.Errorf("%w: more than %d outcomes, split them", ErrInvalidChurnOutcomes, config.CHURN_OUTCOMES_MAX)
+	}
+
+	today := time.Now().In(config.BusinessTZ).Format(config.DateLayout)
+	result := &client.ChurnOutcomeResult{Results: make([]client.ChurnOutcomeItemResult, 0, len(outcomes))}
+	for i, o := range outcomes {
+		o.GluserID = strings.TrimSpace(o.GluserID)
+		o.Date = strings.TrimSpace(o.Date)
+		r := client.ChurnOutcomeItemResult{GluserID: o.GluserID, Date: o.Date}
+		if i < len(lines) {
+			r.Line = lines[i]
+		}
+
+		var err error
+		o.Outcome, err = normalizeChurnOutcome(o, today)
+		if err == nil {
+			if o.Source == "" {
+				o.Source = outcomeSourceAPI
+			}
+			o.RecordedBy, o.RecordedAt = by, time.Now()
+			r.Replaced, err = storage.SaveChurnOutcome(ctx, &o)
+		}
+		if err != nil {
+			r.Code, r.Error = churnOutcomeErrorCode(err), err.Error()
+			result.Failed++
+		} else {
+			r.OK = true
+			result.Recorded++
+		}
+		result.Results = append(result.Results, r)
+	}
+	log.Printf("📉 Churn outcomes from %s: %d recorded, %d failed", orAnonymous(by), result.Recorded, result.Failed)
+	return result, nil
+}
+
+// normalizeChurnOutcome checks an outcome, returning its outcome as renewed
+// or cancelled. Outcomes can't be dated after today.
+func normalizeChurnOutcome(o client.ChurnOutcome, today string) (string, error) {
+	if o.GluserID == "" {
+		return "", fmt.Errorf("%w: gluser_id is required", ErrInvalidChurnOutcome)
+	}
+	if _, err := config.ParseBusinessDate(o.Date); err != nil {
+		return "", fmt.Errorf("%w: date must be YYYY-MM-DD", ErrInvalidChurnOutcome)
+	}
+	if o.Date > today {
+		return "", fmt.Errorf("%w: date %s is in the future", ErrInvalidChurnOutcome, o.Date)
+	}
+	switch strings.ToLower(strings.TrimSpace(o.Outcome)) {
+	case client.OutcomeRenewed, "renewal":
+		return client.OutcomeRenewed, nil
+	case client.OutcomeCancelled, "canceled", "churned", "lapsed":
+		return client.OutcomeCancelled, nil
+	}
+	return "", fmt.Errorf("%w: outcome must be renewed or cancelled", ErrInvalidChurnOutcome)
+}
+
+// churnOutcomeErrorCode is the error code of an outcome that wasn't recorded
+func churnOutcomeErrorCode(err error) string {
+	switch {
+	case errors.Is(err, ErrInvalidChurnOutcome):
+		return client.ErrorValidationFailed
+	case storage.IsUnavailable(err):
+		return client.ErrorStorageUnavailable
+	default:
+		return client.ErrorInternal
+	}
+}
+
+// ChurnAccuracy scores the churn predictions made before the outcomes dated
+// from to to (inclusive), each matched with its seller's latest prediction
+// in the windowDays before it; 0 is CHURN_ACCURACY_WINDOW_DAYS
+func (s *Service) ChurnAccuracy(ctx context.Context, from, to time.Time, windowDays int) (*client.ChurnAccuracyReport, error) {
+	if to.Before(from) {
+		return nil, fmt.Errorf("%w: to date is before from date", ErrInvalidChurnOutcomes)
+	}
+	if days := int(to.Sub(from).Hours()/24) + 1; days > config.CHURN_ACCURACY_MAX_DAYS {
+		return nil, fmt.Errorf("%w: date range too large (%d days, max %d)", ErrInvalidChurnOutcomes, days, config.CHURN_ACCURACY_MAX_DAYS)
+	}
+	if windowDays == 0 {
+		windowDays = churnAccuracyWindow()
+	}
+
+	fromDate, toDate := from.Format(config.DateLayout), to.Format(config.DateLayout)
+	outcomes, err := storage.LoadChurnOutcomes(ctx, fromDate, toDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load churn outcomes: %w", err)
+	}
+	analyses := make(map[string][]client.AnalysisResult)
+	for _, o := range outcomes {
+		if _, ok := analyses[o.GluserID]; ok {
+			continue
+		}
+		list, err := storage.LoadSellerAnalyses(ctx, o.GluserID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load analyses of seller %s: %w", o.GluserID, err)
+		}
+		analyses[o.GluserID] = list
+	}
+	return aggregate.BuildChurnAccuracy(outcomes, analyses, fromDate, toDate, windowDays, time.Now()), nil
+}
+
+// churnAccuracyWindow returns CHURN_ACCURACY_WINDOW_DAYS, or the default
+// when unset or invalid
+func churnAccuracyWindow() int {
+	if v := os.Getenv("CHURN_ACCURACY_WINDOW_DAYS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return n
+		}
+		log.Printf("⚠️ Invalid CHURN_ACCURACY_WINDOW_DAYS=%q, using %d", v, config.DEFAULT_CHURN_ACCURACY_WINDOW_DAYS)
+	}
+	return config.DEFAULT_CHURN_ACCURACY_WINDOW_DAYS
+}
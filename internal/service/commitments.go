@@ -0,0 +1,232 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== COMMITMENTS ====================
+// Promises agents make on calls are stored as commitments, due on the date
+// the agent gave or COMMITMENT_DUE_DAYS after the call. Each later call with
+// the seller is checked against their open commitments, marking the ones it
+// shows kept or broken; the leader marks the rest broken once past due.
+
+const (
+	commitmentsDefaultLimit = 100
+	commitmentsMaxLimit     = 1000
+)
+
+// commitmentDueDays returns COMMITMENT_DUE_DAYS, or the default when unset or invalid
+func commitmentDueDays() int {
+	if v := os.Getenv("COMMITMENT_DUE_DAYS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return n
+		}
+		log.Printf("⚠️ Invalid COMMITMENT_DUE_DAYS=%q, using %d", v, config.DEFAULT_COMMITMENT_DUE_DAYS)
+	}
+	return config.DEFAULT_COMMITMENT_DUE_DAYS
+}
+
+// trackCommitments settles the seller's earlier commitments the call speaks
+// to, then stores the ones made on it. Failures are logged, they don't fail
+// the analysis.
+func (s *Service) trackCommitments(ctx context.Context, rt client.RawTranscript, ext *client.CallExtraction) {
+	if rt.SellerID == "" {
+		return
+	}
+
+	open, err := storage.LoadCommitments(ctx, storage.CommitmentQuery{GluserID: rt.SellerID, Status: client.CommitmentOpen})
+	if err != nil {
+		log.Printf("   ⚠️ Failed to load commitments for %s: %v", rt.SellerID, err)
+		return
+	}
+	earlier := open[:0]
+	for _, c := range open {
+		if c.CallID != rt.CallID && c.CallTime.Before(rt.Timestamp) {
+			earlier = append(earlier, c)
+		}
+	}
+	if len(earlier) > 0 {
+		s.checkCommitments(ctx, rt, ext, earlier)
+	}
+
+	now := time.Now()
+	for i, ec := range ext.Facts.Commitments {
+		c := client.Commitment{
+			ID:        fmt.Sprintf("%s-%d", rt.CallID, i+1),
+			GluserID:  rt.SellerID,
+			AgentID:   rt.AgentID,
+			CallID:    rt.CallID,
+			CallTime:  rt.Timestamp,
+			Promise:   ec.Promise,
+			Kind:      ec.Kind,
+			DueDate:   ec.DueDate,
+			DueStated: ec.DueDate != "",
+			Quote:     ec.Quote,
+			Status:    client.CommitmentOpen,
+			CreatedAt: now,
+		}
+		if c.DueDate == "" {
+			c.DueDate = rt.Timestamp.In(config.BusinessTZ).AddDate(0, 0, commitmentDueDays()).Format("2006-01-02")
+		}
+		// A re-analyzed call keeps what later calls found
+		if existing, err := storage.LoadCommitment(ctx, c.ID); err == nil && existing != nil {
+			c.Status, c.ResolvedAt, c.ResolvedCallID, c.Evidence = existing.Status, existing.ResolvedAt, existing.ResolvedCallID, existing.Evidence
+			c.CreatedAt = existing.CreatedAt
+		}
+		if err := storage.SaveCommitment(ctx, &c); err != nil {
+			log.Printf("   ⚠️ Failed to save commitment %s: %v", c.ID, err)
+		}
+	}
+	if n := len(ext.Facts.Commitments); n > 0 {
+		log.Printf("   🤝 Call %s: %d commitments to %s", rt.CallID, n, rt.SellerID)
+	}
+}
+
+// checkCommitments asks the LLM which open commitments the call shows kept or broken
+func (s *Service) checkCommitments(ctx context.Context, rt client.RawTranscript, ext *client.CallExtraction, open []client.Commitment) {
+	transcript := ext.TranscriptEn
+	if transcript == "" {
+		transcript = rt.Transcript
+	}
+	checks, err := s.ai.CheckCommitments(ctx, transcript, open)
+	if err != nil {
+		log.Printf("   ⚠️ Call %s: failed to check %d open commitments: %v", rt.CallID, len(open), err)
+		return
+	}
+
+	byID := make(map[string]*client.Commitment, len(open))
+	for i := range open {
+		byID[open[i].ID] = &open[i]
+	}
+	for _, ch := range checks {
+		c := byID[ch.ID]
+		resolvedAt := rt.Timestamp
+		c.Status, c.ResolvedAt, c.ResolvedCallID, c.Evidence = ch.Status, &resolvedAt, rt.CallID, ch.Evidence
+		if err := storage.SaveCommitment(ctx, c); err != nil {
+			log.Printf("   ⚠️ Failed to save commitment %s: %v", c.ID, err)
+			continue
+		}
+		log.Printf("   🤝 Call %s: commitment %s %s", rt.CallID, c.ID, c.Status)
+	}
+}
+
+// RunCommitmentExpiry marks open commitments past their due date broken,
+// returning how many
+func RunCommitmentExpiry(ctx context.Context) (int, error) {
+	open, err := storage.LoadCommitments(ctx, storage.CommitmentQuery{Status: client.CommitmentOpen})
+	if err != nil {
+		return 0, fmt.Errorf("failed to load commitments: %w", err)
+	}
+
+	now := time.Now()
+	today := now.In(config.BusinessTZ).Format("2006-01-02")
+	expired := 0
+	for i := range open {
+		if ctx.Err() != nil {
+			return expired, ctx.Err()
+		}
+		c := &open[i]
+		if c.DueDate >= today {
+			continue
+		}
+		c.Status, c.ResolvedAt = client.CommitmentBroken, &now
+		c.Evidence = "No later call showed it done by the due date"
+		if err := storage.SaveCommitment(ctx, c); err != nil {
+			log.Printf("⚠️ Failed to save expired commitment %s: %v", c.ID, err)
+			continue
+		}
+		expired++
+	}
+
+	if expired > 0 {
+		log.Printf("🤝 Marked %d commitments past due broken", expired)
+	}
+	return expired, nil
+}
+
+// ListCommitments returns the newest commitments matching q with broken
+// commitment counts per agent and per seller over all of them
+func (s *Service) ListCommitments(ctx context.Context, q storage.CommitmentQuery, limit int) (*client.CommitmentsReport, error) {
+	if limit <= 0 {
+		limit = commitmentsDefaultLimit
+	}
+	if limit > commitmentsMaxLimit {
+		limit = commitmentsMaxLimit
+	}
+
+	all, err := storage.LoadCommitments(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &client.CommitmentsReport{Commitments: []client.Commitment{}, Total: len(all), GeneratedAt: time.Now()}
+	agents := make(map[string]*client.CommitmentTally)
+	sellers := make(map[string]*client.CommitmentTally)
+	for _, c := range all {
+		agent := c.AgentID
+		if agent == "" {
+			agent = "unknown" // Watcher transcripts don't name the agent
+		}
+		if agents[agent] == nil {
+			agents[agent] = &client.CommitmentTally{AgentID: agent}
+		}
+		if sellers[c.GluserID] == nil {
+			sellers[c.GluserID] = &client.CommitmentTally{GluserID: c.GluserID}
+		}
+		tallyCommitment(agents[agent], c.Status)
+		tallyCommitment(sellers[c.GluserID], c.Status)
+	}
+	report.ByAgent = sortedTallies(agents)
+	report.BySeller = sortedTallies(sellers)
+
+	if len(all) > limit {
+		all = all[:limit]
+	}
+	report.Commitments = append(report.Commitments, all...)
+	report.Count = len(report.Commitments)
+	return report, nil
+}
+
+func tallyCommitment(t *client.CommitmentTally, status string) {
+	t.Total++
+	switch status {
+	case client.CommitmentKept:
+		t.Kept++
+	case client.CommitmentBroken:
+		t.Broken++
+	default:
+		t.Open++
+	}
+	if settled := t.Kept + t.Broken; settled > 0 {
+		t.BrokenRate = float64(t.Broken) / float64(settled)
+	}
+}
+
+// sortedTallies orders tallies by broken commitments, then broken rate
+func sortedTallies(m map[string]*client.CommitmentTally) []client.CommitmentTally {
+	tallies := make([]client.CommitmentTally, 0, len(m))
+	for _, t := range m {
+		tallies = append(tallies, *t)
+	}
+	sort.Slice(tallies, func(i, j int) bool {
+		a, b := tallies[i], tallies[j]
+		if a.Broken != b.Broken {
+			return a.Broken > b.Broken
+		}
+		if a.BrokenRate != b.BrokenRate {
+			return a.BrokenRate > b.BrokenRate
+		}
+		return a.AgentID+a.GluserID < b.AgentID+b.GluserID
+	})
+	return tallies
+}
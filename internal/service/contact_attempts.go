@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/profile"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== CONTACT ATTEMPTS ====================
+// Missed calls and calls that lasted no time have nothing to analyze, and
+// their analyses only dragged down satisfaction and health. The watcher
+// records them as contact attempts instead: a contact_attempt event, and an
+// entry on the seller's timeline, without Gemini or any profile metrics.
+
+// contactAttemptReason is why a watcher transcript is a contact attempt
+// rather than a conversation, empty for a conversation. Zero duration only
+// counts in exports giving the call's status or direction, as files without
+// call metadata leave call_duration out altogether. Chats are always
+// conversations: they have no call status, and no duration to speak of.
+func contactAttemptReason(ht *client.HackathonTranscript) string {
+	status := callStatuses[normalizeCallValue(strings.TrimSpace(ht.CallStatus))]
+	channel, _ := NormalizeContactChannel(ht.Channel)
+	switch {
+	case client.IsChatChannel(channel):
+		return ""
+	case status == client.CallMissed:
+		return client.ContactMissed
+	case ht.CallDuration <= 0 && (ht.CallStatus != "" || ht.FlagInOut != ""):
+		return client.ContactZeroDuration
+	}
+	return ""
+}
+
+// RecordContactAttempt records a missed or zero-length watcher call without
+// analyzing it, returning nil when the call was a conversation to analyze
+func (s *Service) RecordContactAttempt(ctx context.Context, ht *client.HackathonTranscript) (*client.ContactAttempt, error) {
+	reason := contactAttemptReason(ht)
+	if reason == "" {
+		return nil, nil
+	}
+
+	attempt := client.ContactAttempt{
+		CallID:    ht.ClickToCallID,
+		Timestamp: callTimestamp(ht, time.Now()),
+		Duration:  ht.CallDuration,
+		Direction: callDirections[normalizeCallValue(strings.TrimSpace(ht.FlagInOut))],
+		Status:    callStatuses[normalizeCallValue(strings.TrimSpace(ht.CallStatus))],
+		Reason:    reason,
+	}
+	onTimeline, err := profile.RecordContactAttempt(ctx, ht.GluserID, attempt)
+	if err != nil {
+		return nil, err
+	}
+	if !onTimeline {
+		log.Printf("   ℹ️ No profile for gluser_%s, contact attempt only recorded as an event", ht.GluserID)
+	}
+
+	storage.RecordEvent(ctx, client.Event{
+		Type:     client.EventContactAttempt,
+		CallID:   attempt.CallID,
+		GluserID: ht.GluserID,
+		Payload: client.ContactAttemptPayload{
+			Source:     "watcher",
+			Reason:     reason,
+			Direction:  attempt.Direction,
+			Status:     attempt.Status,
+			RawStatus:  strings.TrimSpace(ht.CallStatus),
+			OnTimeline: onTimeline,
+			Origin:     ht.Origin,
+		},
+	})
+	return &attempt, nil
+}
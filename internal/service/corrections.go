@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/profile"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== PROFILE CORRECTIONS ====================
+
+var ErrInvalidProfilePatch = errors.New("invalid profile patch")
+
+const maxProfileFieldLen = 100
+
+// ValidateProfilePatch checks a patch before it's applied, trimming its
+// string fields
+func ValidateProfilePatch(patch *client.SellerProfilePatch) error {
+	empty := true
+	for _, f := range []struct {
+		name string
+		v    *string
+	}{
+		{"customer_type", patch.CustomerType},
+		{"city_name", patch.CityName},
+		{"vertical", patch.Vertical},
+	} {
+		if f.v == nil {
+			continue
+		}
+		empty = false
+		*f.v = strings.TrimSpace(*f.v)
+		if *f.v == "" || len(*f.v) > maxProfileFieldLen {
+			return fmt.Errorf("%w: %s must be 1-%d characters", ErrInvalidProfilePatch, f.name, maxProfileFieldLen)
+		}
+	}
+
+	for _, o := range []struct {
+		name   string
+		set    *client.StatusOverride
+		clear  bool
+		values []string
+	}{
+		{"churn_override", patch.ChurnOverride, patch.ClearChurnOverride, []string{"low", "medium", "high"}},
+		{"sentiment_override", patch.SentimentOverride, patch.ClearSentimentOverride, []string{"Positive", "Neutral", "Negative"}},
+	} {
+		if o.clear {
+			empty = false
+		}
+		if o.set == nil {
+			continue
+		}
+		empty = false
+		if o.clear {
+			return fmt.Errorf("%w: %s and clear_%s together", ErrInvalidProfilePatch, o.name, o.name)
+		}
+		if !slices.Contains(o.values, o.set.Value) {
+			return fmt.Errorf("%w: %s.value must be one of %s", ErrInvalidProfilePatch, o.name, strings.Join(o.values, ", "))
+		}
+		if o.set.Reason = strings.TrimSpace(o.set.Reason); o.set.Reason == "" {
+			return fmt.Errorf("%w: %s.reason is required", ErrInvalidProfilePatch, o.name)
+		}
+		if o.set.ExpiresAt != nil && !o.set.ExpiresAt.After(time.Now()) {
+			return fmt.Errorf("%w: %s.expires_at must be in the future", ErrInvalidProfilePatch, o.name)
+		}
+	}
+	if len(patch.CustomFields) > 0 {
+		empty = false // Checked against their definitions by ValidateCustomFields
+	}
+	if empty {
+		return fmt.Errorf("%w: nothing to update", ErrInvalidProfilePatch)
+	}
+	return nil
+}
+
+// PatchSellerProfile applies a patch checked by ValidateProfilePatch and
+// ValidateCustomFields to a seller's profile on behalf of by
+func (s *Service) PatchSellerProfile(ctx context.Context, gluserID string, patch client.SellerProfilePatch, by string) (*client.SellerProfile, error) {
+	p, err := storage.LoadSellerProfile(ctx, gluserID)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, fmt.Errorf("%w: %s", ErrSellerNotFound, gluserID)
+	}
+
+	changed := profile.ApplyCorrections(p, patch, by, time.Now())
+	for _, name := range setCustomFields(&p.CustomFields, patch.CustomFields) {
+		changed = append(changed, "custom_fields."+name)
+	}
+	if len(changed) == 0 {
+		return p, nil
+	}
+	if err := storage.SaveSellerProfile(ctx, p); err != nil {
+		return nil, fmt.Errorf("failed to save profile: %w", err)
+	}
+	log.Printf("✏️ Seller %s profile corrected by %s: %s", gluserID, by, strings.Join(changed, ", "))
+	return p, nil
+}
@@ -0,0 +1,30 @@
+package service
+
+import (
+	"os"
+
+	"im-ai-voice/client"
+)
+
+// ==================== RULES CROSS-CHECK ====================
+// Each Gemini analysis is compared with the keyword classifier's reading of
+// the transcript, which costs no request: the buckets only one side raised
+// and sentiments of opposite polarity are kept on the analysis as its
+// rules_check, and /analytics/cross-check sums them up over a date range.
+// A disagreement rate moving over time points at one of the two drifting,
+// Gemini after a model or prompt change, the rules as sellers' vocabulary
+// moves on. RULES_CROSS_CHECK=false turns it off.
+
+// rulesCrossCheckEnabled reports whether analyses are cross-checked
+func rulesCrossCheckEnabled() bool {
+	return os.Getenv("RULES_CROSS_CHECK") != "false"
+}
+
+// crossCheck records on a Gemini analysis of rt how the rules' reading
+// differs. Blocked analyses have nothing to compare.
+func (s *Service) crossCheck(rt client.RawTranscript, analysis *client.AnalysisResult) {
+	if analysis.Blocked || analysis.Engine == client.EngineRules || !rulesCrossCheckEnabled() {
+		return
+	}
+	analysis.RulesCheck = s.rules.CrossCheck(rt, analysis)
+}
@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== CUSTOM FIELDS ====================
+// Operators define extra fields on profiles and tickets (an account
+// manager, a region code, a contract tier) without a code change. Values
+// are checked against their definition when written, returned with the
+// record and filtered on with cf.<name>=<value>. There's one registry per
+// deployment: source systems are the only notion of tenant, and a seller's
+// calls can come from several.
+
+var (
+	ErrInvalidCustomField  = errors.New("invalid custom field")
+	ErrCustomFieldNotFound = errors.New("custom field not defined")
+)
+
+var customFieldName = regexp.MustCompile(`^[a-z][a-z0-9_]{0,39}$`)
+
+// ListCustomFields returns the field definitions of an entity, every
+// entity's when it's empty
+func (s *Service) ListCustomFields(ctx context.Context, entity string) ([]client.CustomFieldDefinition, error) {
+	if entity != "" && !slices.Contains(client.CustomFieldEntities, entity) {
+		return nil, fmt.Errorf("%w: entity must be one of %s", ErrInvalidCustomField, strings.Join(client.CustomFieldEntities, ", "))
+	}
+	return storage.LoadCustomFields(ctx, entity)
+}
+
+// SetCustomField defines a field, or changes its definition. Values already
+// set aren't rechecked against a changed one.
+func (s *Service) SetCustomField(ctx context.Context, entity, name string, in client.CustomFieldRequest) (*client.CustomFieldDefinition, error) {
+	if !slices.Contains(client.CustomFieldEntities, entity) {
+		return nil, fmt.Errorf("%w: entity must be one of %s", ErrInvalidCustomField, strings.Join(client.CustomFieldEntities, ", "))
+	}
+	if !customFieldName.MatchString(name) {
+		return nil, fmt.Errorf("%w: name must be 1-40 lowercase letters, digits and underscores, starting with a letter", ErrInvalidCustomField)
+	}
+	if !slices.Contains(client.CustomFieldTypes, in.Type) {
+		return nil, fmt.Errorf("%w: type must be one of %s", ErrInvalidCustomField, strings.Join(client.CustomFieldTypes, ", "))
+	}
+	var values []string
+	for _, v := range in.Values {
+		if v = strings.TrimSpace(v); v != "" && !slices.Contains(values, v) {
+			values = append(values, v)
+		}
+	}
+	switch {
+	case in.Type == client.CustomFieldEnum && len(values) == 0:
+		return nil, fmt.Errorf("%w: an enum needs values", ErrInvalidCustomField)
+	case in.Type != client.CustomFieldEnum && len(values) > 0:
+		return nil, fmt.Errorf("%w: only enums have values", ErrInvalidCustomField)
+	}
+
+	defs, err := storage.LoadCustomFields(ctx, entity)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	d := &client.CustomFieldDefinition{
+		Entity:      entity,
+		Name:        name,
+		Label:       strings.TrimSpace(in.Label),
+		Type:        in.Type,
+		Values:      values,
+		Description: strings.TrimSpace(in.Description),
+		By:          in.By,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if i := slices.IndexFunc(defs, func(d client.CustomFieldDefinition) bool { return d.Name == name }); i >= 0 {
+		d.CreatedAt = defs[i].CreatedAt
+	} else if len(defs) >= config.CUSTOM_FIELDS_MAX {
+		return nil, fmt.Errorf("%w: at most %d %s fields", ErrInvalidCustomField, config.CUSTOM_FIELDS_MAX, entity)
+	}
+	if err := storage.SaveCustomField(ctx, d); err != nil {
+		return nil, err
+	}
+	log.Printf("🏷️ Custom %s field %s defined as %s (by %s)", entity, name, in.Type, orAnonymous(d.By))
+	return d, nil
+}
+
+// DeleteCustomField drops a field's definition. Values already set stay on
+// their records but can't be written or filtered on.
+func (s *Service) DeleteCustomField(ctx context.Context, entity, name string) error {
+	found, err := storage.DeleteCustomField(ctx, entity, name)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("%w: %s.%s", ErrCustomFieldNotFound, entity, name)
+	}
+	log.Printf("🏷️ Custom %s field %s removed", entity, name)
+	return nil
+}
+
+// ValidateCustomFields checks values to write to an entity's custom fields
+// against their definitions, converting each to its type's JSON form. A
+// nil value removes the field and is always allowed.
+func (s *Service) ValidateCustomFields(ctx context.Context, entity string, values map[string]any) error {
+	if len(values) == 0 {
+		return nil
+	}
+	defs, err := s.customFieldDefs(ctx, entity)
+	if err != nil {
+		return err
+	}
+	for name, v := range values {
+		d, ok := defs[name]
+		if !ok {
+			return fmt.Errorf("%w: %s has no field %q", ErrInvalidCustomField, entity, name)
+		}
+		if v == nil {
+			continue
+		}
+		if values[name], err = customFieldValue(d, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ParseCustomFieldFilters parses the cf.<name>=<value> filters of an
+// entity's list endpoint, by name, into values to match
+func (s *Service) ParseCustomFieldFilters(ctx context.Context, entity string, raw map[string]string) (map[string]any, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	defs, err := s.customFieldDefs(ctx, entity)
+	if err != nil {
+		return nil, err
+	}
+	filters := make(map[string]any, len(raw))
+	for name, v := range raw {
+		d, ok := defs[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s has no field %q", ErrInvalidCustomField, entity, name)
+		}
+		if filters[name], err = customFieldValue(d, v); err != nil {
+			return nil, err
+		}
+	}
+	return filters, nil
+}
+
+func (s *Service) customFieldDefs(ctx context.Context, entity string) (map[string]client.CustomFieldDefinition, error) {
+	list, err := storage.LoadCustomFields(ctx, entity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load custom fields: %w", err)
+	}
+	defs := make(map[string]client.CustomFieldDefinition, len(list))
+	for _, d := range list {
+		defs[d.Name] = d
+	}
+	return defs, nil
+}
+
+// customFieldValue converts v to d's type. Numbers and booleans may be
+// given as strings, as query parameters are.
+func customFieldValue(d client.CustomFieldDefinition, v any) (any, error) {
+	invalid := func(want string) error {
+		return fmt.Errorf("%w: %s must be %s", ErrInvalidCustomField, d.Name, want)
+	}
+	str, isString := v.(string)
+	if isString {
+		str = strings.TrimSpace(str)
+	}
+	switch d.Type {
+	case client.CustomFieldNumber:
+		switch n := v.(type) {
+		case float64:
+			return n, nil
+		case string:
+			if f, err := strconv.ParseFloat(str, 64); err == nil {
+				return f, nil
+			}
+		}
+		return nil, invalid("a number")
+	case client.CustomFieldBool:
+		switch b := v.(type) {
+		case bool:
+			return b, nil
+		case string:
+			if parsed, err := strconv.ParseBool(str); err == nil {
+				return parsed, nil
+			}
+		}
+		return nil, invalid("true or false")
+	case client.CustomFieldEnum:
+		if !isString || !slices.Contains(d.Values, str) {
+			return nil, invalid("one of " + strings.Join(d.Values, ", "))
+		}
+		return str, nil
+	case client.CustomFieldDate:
+		if _, err := time.Parse(config.DateLayout, str); !isString || err != nil {
+			return nil, invalid("a YYYY-MM-DD date")
+		}
+		return str, nil
+	default:
+		if !isString || str == "" || len(str) > config.CUSTOM_FIELD_MAX_CHARS {
+			return nil, invalid(fmt.Sprintf("a string of 1-%d characters", config.CUSTOM_FIELD_MAX_CHARS))
+		}
+		return str, nil
+	}
+}
+
+// setCustomFields applies validated values to fields, removing those set to
+// nil, and returns the names of the fields that changed
+func setCustomFields(fields *map[string]any, values map[string]any) []string {
+	var changed []string
+	for name, v := range values {
+		cur, ok := (*fields)[name]
+		switch {
+		case v == nil && ok:
+			delete(*fields, name)
+		case v != nil && (!ok || !client.CustomFieldsMatch(map[string]any{name: cur}, map[string]any{name: v})):
+			if *fields == nil {
+				*fields = make(map[string]any)
+			}
+			(*fields)[name] = v
+		default:
+			continue
+		}
+		changed = append(changed, name)
+	}
+	if len(*fields) == 0 {
+		*fields = nil
+	}
+	slices.Sort(changed)
+	return changed
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/profile"
+)
+
+// ==================== CALL DIFF ====================
+
+// ErrCallNotFromSeller is returned when a diffed call belongs to another seller
+var ErrCallNotFromSeller = errors.New("call does not belong to seller")
+
+// DiffSellerCalls compares two of a seller's calls and, if narrative is set,
+// has the LLM describe what changed. A failed narrative doesn't fail the diff.
+func (s *Service) DiffSellerCalls(ctx context.Context, gluserID, callA, callB string, narrative bool) (*client.CallDiff, error) {
+	a, err := s.GetCallAnalysis(ctx, callA)
+	if err != nil {
+		return nil, fmt.Errorf("call %s: %w", callA, err)
+	}
+	b, err := s.GetCallAnalysis(ctx, callB)
+	if err != nil {
+		return nil, fmt.Errorf("call %s: %w", callB, err)
+	}
+	for _, ar := range []*client.AnalysisResult{a, b} {
+		if ar.SellerID != gluserID {
+			return nil, fmt.Errorf("%w: call %s is from seller %s", ErrCallNotFromSeller, ar.CallID, ar.SellerID)
+		}
+	}
+
+	diff := profile.DiffCalls(gluserID, a, b)
+	if !narrative {
+		return diff, nil
+	}
+
+	if s.ai == nil {
+		diff.NarrativeError = "narrative unavailable, AI client not configured"
+		return diff, nil
+	}
+	if diff.Narrative, err = s.ai.DescribeCallDiff(ctx, diff, a, b); err != nil {
+		log.Printf("⚠️ Diff narrative failed for %s (%s vs %s): %v", gluserID, callA, callB, err)
+		diff.NarrativeError = "narrative unavailable, the LLM request failed" // err can carry Gemini's URL and response
+	}
+	return diff, nil
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/notify"
+	"im-ai-voice/internal/report"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== DAILY DIGEST ====================
+// Morning digest of the previous day's aggregate, new tickets, top at-risk
+// sellers and the week's insight themes, rendered to HTML/PDF and emailed to
+// DIGEST_RECIPIENTS.
+
+const digestTopSellers = 10
+
+// BuildDailyDigest collects the data for a digest date
+func (s *Service) BuildDailyDigest(ctx context.Context, date string) (*report.DailyDigest, error) {
+	digest := &report.DailyDigest{
+		Date:        date,
+		NewTickets:  []client.Ticket{},
+		TopBuckets:  []client.BucketCount{},
+		Themes:      []client.InsightTheme{},
+		GeneratedAt: time.Now(),
+	}
+
+	agg, err := s.GetDailyAggregate(ctx, date)
+	if err == nil && agg != nil {
+		digest.Aggregate = agg
+		for bucket, summary := range agg.FeatureBuckets {
+			digest.TopBuckets = append(digest.TopBuckets, client.BucketCount{Bucket: bucket, Count: summary.TotalCount})
+		}
+		sort.Slice(digest.TopBuckets, func(i, j int) bool {
+			return digest.TopBuckets[i].Count > digest.TopBuckets[j].Count
+		})
+		if len(digest.TopBuckets) > 5 {
+			digest.TopBuckets = digest.TopBuckets[:5]
+		}
+	}
+	if themes := weeklyThemes(ctx, date); len(themes) > 0 {
+		digest.Themes = themes
+	} else if agg != nil && len(agg.WeeklyThemes) > 0 {
+		digest.Themes = agg.WeeklyThemes
+	}
+
+	if tickets, err := s.GetTicketsForDate(ctx, date); err == nil {
+		digest.NewTickets = tickets
+	}
+
+	if digest.Aggregate == nil && len(digest.NewTickets) == 0 {
+		return nil, fmt.Errorf("no aggregate or tickets found for %s", date)
+	}
+
+	// Top at-risk sellers: flagged for attention, lowest health first
+	profiles, err := storage.LoadAllSellerProfiles(ctx)
+	if err != nil {
+		log.Printf("⚠️ Digest: failed to load seller profiles: %v", err)
+	}
+	for _, p := range profiles {
+		if p.CurrentStatus.NeedsAttention {
+			digest.AtRisk = append(digest.AtRisk, p)
+		}
+	}
+	sort.Slice(digest.AtRisk, func(i, j int) bool {
+		return digest.AtRisk[i].CurrentStatus.HealthScore < digest.AtRisk[j].CurrentStatus.HealthScore
+	})
+	digest.AtRiskCount = len(digest.AtRisk)
+	if len(digest.AtRisk) > digestTopSellers {
+		digest.AtRisk = digest.AtRisk[:digestTopSellers]
+	}
+
+	return digest, nil
+}
+
+// SendDailyDigest builds the digest for date and emails it (HTML body + PDF attachment)
+func (s *Service) SendDailyDigest(ctx context.Context, date string) (*report.DailyDigest, []string, error) {
+	recipients := notify.SplitList(os.Getenv("DIGEST_RECIPIENTS"))
+	if len(recipients) == 0 {
+		return nil, nil, fmt.Errorf("DIGEST_RECIPIENTS not set")
+	}
+
+	mailer, err := notify.NewMailerFromEnv()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	digest, err := s.BuildDailyDigest(ctx, date)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	body, err := report.RenderDigestHTML(digest)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	subject := fmt.Sprintf("IndiaMART Voice AI - Daily Digest %s", date)
+	attachment := notify.EmailAttachment{
+		Filename:    fmt.Sprintf("digest_%s.pdf", date),
+		ContentType: "application/pdf",
+		Data:        report.RenderDigestPDF(digest),
+	}
+	if err := mailer.Send(recipients, subject, string(body), attachment); err != nil {
+		return nil, nil, err
+	}
+
+	log.Printf("📧 Daily digest for %s sent to %d recipients", date, len(recipients))
+	return digest, recipients, nil
+}
+
+// ==================== DIGEST SCHEDULER ====================
+
+// digestHour returns the hour (business timezone) at which the morning digest is sent
+func digestHour() int {
+	if v := os.Getenv("DIGEST_HOUR"); v != "" {
+		if h, err := strconv.Atoi(v); err == nil && h >= 0 && h < 24 {
+			return h
+		}
+	}
+	return config.DEFAULT_DIGEST_HOUR
+}
@@ -0,0 +1,494 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/llm"
+	"im-ai-voice/internal/notify"
+	"im-ai-voice/internal/storage"
+	"im-ai-voice/internal/ulid"
+)
+
+// ==================== EVAL RUNS ====================
+// The rollout and shadow comparisons, kept as a history. An eval run sums
+// up the analyzed events of the last EVAL_WINDOW_DAYS full days by model
+// and prompt version, as rollouts are judged (rolloutArm), and each shadow
+// candidate's agreement with the primary (ShadowReport). The daily eval job
+// stores a run once a week, or sooner when a version analyzed calls the
+// last run didn't see, so a prompt or model change is measured as soon as
+// it has analyses. A stored run also has the versions running then analyze
+// the approved call samples, whose reviewed analyses are the labeled set,
+// for their extraction accuracy. A primary version whose metrics moved past
+// the rollout thresholds, or whose accuracy fell, since its previous run
+// raises an alert: the same model and prompts answering differently is the
+// provider changing the model.
+
+// AlertEvalDrift is the alert type of a primary version's metrics drifting
+const AlertEvalDrift = "eval_drift"
+
+var ErrInvalidEvalRange = errors.New("invalid eval history range")
+
+// evalKey tells a run's versions apart
+func evalKey(v client.EvalVersion) string {
+	return v.Role + "|" + v.Version + "|" + v.Rollout
+}
+
+// analyzedVersion is the version that made an analyzed event's analysis,
+// empty for the keyword classifier's
+func analyzedVersion(p client.AnalyzedPayload) string {
+	switch {
+	case p.Provisional || p.Engine == client.EngineRules:
+		return ""
+	case p.PromptVersion == "":
+		return client.EvalVersionUnrecorded
+	}
+	return p.Model + "@" + p.PromptVersion
+}
+
+// MeasureEval sums up the analyses of the EVAL_WINDOW_DAYS full days before
+// today by version, without storing the run
+func (s *Service) MeasureEval(ctx context.Context, trigger string) (*client.EvalRun, error) {
+	end, _ := config.ParseBusinessDate(config.Today())
+	start := end.AddDate(0, 0, -config.EVAL_WINDOW_DAYS)
+	run := &client.EvalRun{
+		ID:        "ev_" + ulid.New(),
+		Trigger:   trigger,
+		From:      config.BusinessDate(start),
+		To:        config.BusinessDate(end.AddDate(0, 0, -1)),
+		Versions:  []client.EvalVersion{},
+		CreatedAt: time.Now().UTC(),
+	}
+
+	arms := make(map[string]*rolloutArm)
+	versions := make(map[string]client.EvalVersion)
+	q := storage.EventQuery{Type: client.EventAnalyzed, Since: start, Limit: 1000}
+	for {
+		page, err := storage.QueryEvents(ctx, q)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read analyzed events: %w", err)
+		}
+		for _, e := range page.Events {
+			if !e.Timestamp.Before(end) {
+				continue
+			}
+			var p client.AnalyzedPayload
+			b, err := json.Marshal(e.Payload)
+			if err != nil || json.Unmarshal(b, &p) != nil {
+				continue
+			}
+			version := analyzedVersion(p)
+			if version == "" {
+				continue
+			}
+			v := client.EvalVersion{Version: version, Role: client.EvalPrimary}
+			if p.Rollout != "" {
+				v.Role, v.Rollout = client.EvalCanary, p.Rollout
+			}
+			k := evalKey(v)
+			if arms[k] == nil {
+				arms[k] = &rolloutArm{}
+				versions[k] = v
+			}
+			arms[k].add(p)
+		}
+		if !page.HasMore {
+			break
+		}
+		q.Cursor = page.NextCursor
+	}
+	for k, v := range versions {
+		arm := arms[k].summary()
+		v.Arm = &arm
+		run.Versions = append(run.Versions, v)
+	}
+
+	shadows, err := storage.LoadShadowAnalyses(ctx, start, end.AddDate(0, 0, -1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load shadow analyses: %w", err)
+	}
+	candidates := make(map[string]bool)
+	for _, sa := range shadows {
+		candidates[sa.Candidate] = true
+	}
+	for candidate := range candidates {
+		r, err := s.ShadowReport(ctx, start, end.AddDate(0, 0, -1), candidate)
+		if err != nil {
+			return nil, err
+		}
+		run.Versions = append(run.Versions, client.EvalVersion{
+			Version: candidate,
+			Role:    client.EvalShadow,
+			Shadow: &client.EvalAgreement{
+				Calls:                     r.Calls,
+				Failed:                    r.Failed,
+				Compared:                  r.Compared,
+				SentimentAgreement:        r.SentimentAgreement,
+				ChurnAgreement:            r.ChurnAgreement,
+				AgentPerformanceAgreement: r.AgentPerformanceAgreement,
+				EscalationAgreement:       r.EscalationAgreement,
+				MeanAbsSatisfactionDelta:  r.MeanAbsSatisfactionDelta,
+				MeanBucketOverlap:         r.MeanBucketOverlap,
+			},
+		})
+	}
+
+	sort.Slice(run.Versions, func(i, j int) bool { return evalKey(run.Versions[i]) < evalKey(run.Versions[j]) })
+	return run, nil
+}
+
+// RunEval measures and stores a run now, whatever the schedule says
+func (s *Service) RunEval(ctx context.Context) (*client.EvalRun, error) {
+	run, err := s.MeasureEval(ctx, client.EvalTriggerManual)
+	if err != nil {
+		return nil, err
+	}
+	return run, s.saveEvalRun(ctx, run)
+}
+
+// ScheduledEval stores a run when EVAL_INTERVAL_DAYS have passed since the
+// last one, or a version analyzed calls it didn't see; nil when neither
+func (s *Service) ScheduledEval(ctx context.Context) (*client.EvalRun, error) {
+	latest, err := storage.LoadLatestEvalRun(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the last eval run: %w", err)
+	}
+	run, err := s.MeasureEval(ctx, client.EvalTriggerWeekly)
+	if err != nil {
+		return nil, err
+	}
+	if latest != nil && time.Since(latest.CreatedAt) < time.Duration(config.EVAL_INTERVAL_DAYS)*24*time.Hour-time.Hour {
+		seen := make(map[string]bool)
+		for _, v := range latest.Versions {
+			seen[evalKey(v)] = true
+		}
+		changed := false
+		for _, v := range run.Versions {
+			changed = changed || !seen[evalKey(v)]
+		}
+		if !changed {
+			return nil, nil
+		}
+		run.Trigger = client.EvalTriggerVersionChange
+	}
+	return run, s.saveEvalRun(ctx, run)
+}
+
+// saveEvalRun compares the run's primary versions with their previous runs,
+// alerting on drift, and stores it
+func (s *Service) saveEvalRun(ctx context.Context, run *client.EvalRun) error {
+	if err := s.measureAccuracy(ctx, run); err != nil {
+		return err
+	}
+	previous, err := storage.LoadEvalRuns(ctx, run.CreatedAt.AddDate(0, 0, -config.EVAL_HISTORY_DAYS), run.CreatedAt, 0)
+	if err != nil {
+		return fmt.Errorf("failed to load earlier eval runs: %w", err)
+	}
+	for _, v := range run.Versions {
+		if v.Role != client.EvalPrimary {
+			continue
+		}
+		if before := previousArm(previous, v); before != nil && v.Arm != nil {
+			for _, d := range evalDrift(*before, *v.Arm) {
+				run.Drift = append(run.Drift, v.Version+": "+d)
+			}
+		}
+		if before := previousAccuracy(previous, v); before != nil && v.Accuracy != nil {
+			for _, d := range accuracyDrift(*before, *v.Accuracy) {
+				run.Drift = append(run.Drift, v.Version+": "+d)
+			}
+		}
+	}
+
+	if err := storage.SaveEvalRun(ctx, run); err != nil {
+		return err
+	}
+	log.Printf("🧪 Eval run %s (%s, %s to %s): %d versions, %d drifted", run.ID, run.Trigger, run.From, run.To, len(run.Versions), len(run.Drift))
+	if len(run.Drift) > 0 {
+		notify.FireAlert(ctx, client.Alert{
+			Type:     AlertEvalDrift,
+			Severity: "medium",
+			Message:  fmt.Sprintf("Analyses drifted with no model or prompt change: %s", strings.Join(run.Drift, "; ")),
+			Details: map[string]interface{}{
+				"eval_run_id": run.ID,
+				"from":        run.From,
+				"to":          run.To,
+				"drift":       run.Drift,
+			},
+		})
+	}
+	return nil
+}
+
+// previousArm returns the version's metrics in the newest earlier run that
+// measured it on enough calls, nil if none did
+func previousArm(runs []client.EvalRun, v client.EvalVersion) *client.RolloutArm {
+	for _, r := range runs {
+		for _, pv := range r.Versions {
+			if evalKey(pv) == evalKey(v) && pv.Arm != nil && pv.Arm.Calls >= config.DEFAULT_ROLLOUT_MIN_CALLS {
+				return pv.Arm
+			}
+		}
+	}
+	return nil
+}
+
+// previousAccuracy returns the version's accuracy in the newest earlier run
+// that scored it on enough samples, nil if none did
+func previousAccuracy(runs []client.EvalRun, v client.EvalVersion) *client.EvalAccuracy {
+	for _, r := range runs {
+		for _, pv := range r.Versions {
+			if evalKey(pv) == evalKey(v) && pv.Accuracy != nil && pv.Accuracy.Scored >= config.EVAL_ACCURACY_MIN_SAMPLES {
+				return pv.Accuracy
+			}
+		}
+	}
+	return nil
+}
+
+// evalDrift lists the metrics that moved past the rollout thresholds
+// between two runs of the same version
+func evalDrift(before, after client.RolloutArm) []string {
+	if after.Calls < config.DEFAULT_ROLLOUT_MIN_CALLS {
+		return nil
+	}
+	var drift []string
+	if d := after.ParseFailureRate - before.ParseFailureRate; d > config.DEFAULT_ROLLOUT_MAX_PARSE_FAILURE {
+		drift = append(drift, fmt.Sprintf("parse failure rate up %.1f points", d*100))
+	}
+	if d := after.MeanSatisfaction - before.MeanSatisfaction; math.Abs(d) > config.DEFAULT_ROLLOUT_MAX_SATISFACTION {
+		drift = append(drift, fmt.Sprintf("mean satisfaction %+.2f", d))
+	}
+	if d := after.MeanIssueCount - before.MeanIssueCount; math.Abs(d) > config.DEFAULT_ROLLOUT_MAX_ISSUE_COUNT {
+		drift = append(drift, fmt.Sprintf("mean issue count %+.2f", d))
+	}
+	if d := after.NegativeShare - before.NegativeShare; math.Abs(d) > config.DEFAULT_ROLLOUT_MAX_NEGATIVE {
+		drift = append(drift, fmt.Sprintf("negative share %+.3f", d))
+	}
+	return drift
+}
+
+// accuracyDrift lists the accuracies that fell by more than
+// EVAL_MAX_ACCURACY_DROP between two runs of the same version
+func accuracyDrift(before, after client.EvalAccuracy) []string {
+	if after.Scored < config.EVAL_ACCURACY_MIN_SAMPLES {
+		return nil
+	}
+	var drift []string
+	for _, m := range []struct {
+		name          string
+		before, after float64
+	}{
+		{"sentiment accuracy", before.SentimentAccuracy, after.SentimentAccuracy},
+		{"churn accuracy", before.ChurnAccuracy, after.ChurnAccuracy},
+		{"agent performance accuracy", before.AgentPerformanceAccuracy, after.AgentPerformanceAccuracy},
+		{"bucket precision", before.BucketPrecision, after.BucketPrecision},
+		{"bucket recall", before.BucketRecall, after.BucketRecall},
+	} {
+		if d := m.after - m.before; d < -config.EVAL_MAX_ACCURACY_DROP {
+			drift = append(drift, fmt.Sprintf("%s down %.1f points", m.name, -d*100))
+		}
+	}
+	return drift
+}
+
+// evalTarget is a client running now whose accuracy a run measures
+type evalTarget struct {
+	version client.EvalVersion
+	ai      *llm.AIClient
+}
+
+// evalTargets returns the primary, the active rollout's candidate and the
+// shadow candidate, labelled as their analyses are
+func (s *Service) evalTargets(ctx context.Context) []evalTarget {
+	if s.ai == nil {
+		return nil
+	}
+	targets := []evalTarget{{
+		version: client.EvalVersion{Version: s.ai.Model() + "@" + s.ai.PromptVersion(), Role: client.EvalPrimary},
+		ai:      s.ai,
+	}}
+	r, err := storage.LoadActiveRollout(ctx)
+	if err != nil {
+		log.Printf("⚠️ Eval run leaves out the active rollout, failed to load it: %v", err)
+	} else if r != nil {
+		if c, err := llm.NewCandidateClient(s.ai, r.Model, r.PromptRegistry); err != nil {
+			log.Printf("⚠️ Eval run leaves out rollout %s: %v", r.ID, err)
+		} else {
+			targets = append(targets, evalTarget{
+				version: client.EvalVersion{Version: c.Model() + "@" + c.PromptVersion(), Role: client.EvalCanary, Rollout: r.ID},
+				ai:      c,
+			})
+		}
+	}
+	if s.shadow != nil {
+		targets = append(targets, evalTarget{
+			version: client.EvalVersion{Version: s.shadow.candidate, Role: client.EvalShadow},
+			ai:      s.shadow.ai,
+		})
+	}
+	return targets
+}
+
+// measureAccuracy has every version running now analyze the newest
+// EVAL_SAMPLE_LIMIT approved call samples and sets their accuracy on the
+// run, adding the versions that analyzed no calls in its window
+func (s *Service) measureAccuracy(ctx context.Context, run *client.EvalRun) error {
+	targets := s.evalTargets(ctx)
+	if len(targets) == 0 {
+		return nil
+	}
+	samples, err := storage.LoadCallSamples(ctx, storage.SampleQuery{Status: client.SampleApproved})
+	if err != nil {
+		return fmt.Errorf("failed to load approved call samples: %w", err)
+	}
+	if len(samples) == 0 {
+		return nil
+	}
+	samples = samples[:min(len(samples), config.EVAL_SAMPLE_LIMIT)]
+
+	for _, t := range targets {
+		acc := sampleAccuracy(ctx, t.ai, samples)
+		found := false
+		for i := range run.Versions {
+			if evalKey(run.Versions[i]) == evalKey(t.version) {
+				run.Versions[i].Accuracy, found = acc, true
+			}
+		}
+		if !found {
+			v := t.version
+			v.Accuracy = acc
+			run.Versions = append(run.Versions, v)
+		}
+	}
+	sort.Slice(run.Versions, func(i, j int) bool { return evalKey(run.Versions[i]) < evalKey(run.Versions[j]) })
+	return nil
+}
+
+// sampleAccuracy analyzes the samples' transcripts with ai and scores the
+// analyses against the samples' reviewed ones
+func sampleAccuracy(ctx context.Context, ai *llm.AIClient, samples []client.CallSample) *client.EvalAccuracy {
+	acc := &client.EvalAccuracy{Samples: len(samples)}
+	var sentiment, churn, agent, satisfaction float64
+	var found, labelled, matched int
+	for _, sample := range samples {
+		rt := client.RawTranscript{
+			CallID:       sample.ID,
+			Timestamp:    sample.CreatedAt,
+			Language:     "en", // The transcript is the analysis's translation
+			Transcript:   sample.Transcript,
+			CustomerType: sample.CustomerType,
+			Channel:      sample.Channel,
+		}
+		analyzeCtx, cancel := withAnalysisDeadline(ctx, rt)
+		a, _, err := ai.AnalyzeCall(analyzeCtx, rt, "")
+		cancel()
+		if err != nil || a.Blocked || a.LLMRaw["parse_error"] != nil {
+			if err != nil {
+				log.Printf("   ⚠️ Eval analysis of sample %s failed: %v", sample.ID, err)
+			}
+			acc.Failed++
+			continue
+		}
+		want := sample.Analysis
+		acc.Scored++
+		sentiment += boolFloat(strings.EqualFold(a.Intent.Sentiment, want.Sentiment))
+		churn += boolFloat(strings.EqualFold(a.Churn.IsLikelyToChurn, want.ChurnRisk))
+		agent += boolFloat(strings.EqualFold(a.AgentPerformance, want.AgentPerformance))
+		satisfaction += math.Abs(float64(a.Intent.SatisfactionScore - want.SatisfactionScore))
+
+		labels := make(map[string]bool, len(want.Issues))
+		for _, issue := range want.Issues {
+			labels[issue.Bucket] = true
+		}
+		got := issueBucketSet(a)
+		for b := range got {
+			if labels[b] {
+				matched++
+			}
+		}
+		found += len(got)
+		labelled += len(labels)
+	}
+	if acc.Scored == 0 {
+		return acc
+	}
+	n := float64(acc.Scored)
+	acc.SentimentAccuracy = math.Round(sentiment/n*1000) / 1000
+	acc.ChurnAccuracy = math.Round(churn/n*1000) / 1000
+	acc.AgentPerformanceAccuracy = math.Round(agent/n*1000) / 1000
+	acc.MeanAbsSatisfactionError = math.Round(satisfaction/n*100) / 100
+	acc.BucketPrecision, acc.BucketRecall = 1, 1
+	if found > 0 {
+		acc.BucketPrecision = math.Round(float64(matched)/float64(found)*1000) / 1000
+	}
+	if labelled > 0 {
+		acc.BucketRecall = math.Round(float64(matched)/float64(labelled)*1000) / 1000
+	}
+	return acc
+}
+
+// EvalHistory returns the runs created on the dates from to to, and each
+// version's metrics across them; a version filters the series to the
+// versions containing it
+func (s *Service) EvalHistory(ctx context.Context, from, to time.Time, version string) (*client.EvalHistory, error) {
+	if to.Before(from) {
+		return nil, fmt.Errorf("%w: to date is before from date", ErrInvalidEvalRange)
+	}
+	if days := int(to.Sub(from).Hours()/24) + 1; days > config.EVAL_HISTORY_MAX_DAYS {
+		return nil, fmt.Errorf("%w: date range too large (%d days, max %d)", ErrInvalidEvalRange, days, config.EVAL_HISTORY_MAX_DAYS)
+	}
+	start, _ := config.ParseBusinessDate(from.Format(config.DateLayout))
+	end, _ := config.ParseBusinessDate(to.AddDate(0, 0, 1).Format(config.DateLayout))
+	runs, err := storage.LoadEvalRuns(ctx, start, end, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load eval runs: %w", err)
+	}
+
+	h := &client.EvalHistory{
+		From:   from.Format(config.DateLayout),
+		To:     to.Format(config.DateLayout),
+		Runs:   runs,
+		Series: []client.EvalSeries{},
+	}
+	series := make(map[string]*client.EvalSeries)
+	for i := len(runs) - 1; i >= 0; i-- {
+		r := runs[i]
+		for _, v := range r.Versions {
+			if version != "" && !strings.Contains(v.Version, version) {
+				continue
+			}
+			k := evalKey(v)
+			if series[k] == nil {
+				series[k] = &client.EvalSeries{Version: v.Version, Role: v.Role, Rollout: v.Rollout}
+			}
+			series[k].Points = append(series[k].Points, client.EvalPoint{
+				RunID:     r.ID,
+				From:      r.From,
+				To:        r.To,
+				Arm:       v.Arm,
+				Shadow:    v.Shadow,
+				Accuracy:  v.Accuracy,
+				CreatedAt: r.CreatedAt,
+			})
+		}
+	}
+	for _, sr := range series {
+		h.Series = append(h.Series, *sr)
+	}
+	sort.Slice(h.Series, func(i, j int) bool {
+		a, b := h.Series[i], h.Series[j]
+		return evalKey(client.EvalVersion{Version: a.Version, Role: a.Role, Rollout: a.Rollout}) <
+			evalKey(client.EvalVersion{Version: b.Version, Role: b.Role, Rollout: b.Rollout})
+	})
+	return h, nil
+}
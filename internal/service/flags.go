@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/flags"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== FEATURE FLAGS ====================
+
+var (
+	ErrInvalidFlag  = errors.New("invalid feature flag")
+	ErrFlagNotFound = errors.New("feature flag not set")
+)
+
+// ListFeatureFlags returns every flag's current setting
+func (s *Service) ListFeatureFlags(ctx context.Context) ([]client.FeatureFlag, error) {
+	return flags.List(ctx)
+}
+
+// SetFeatureFlag stores a flag's setting, overriding FEATURE_FLAGS. This
+// instance applies it at once, the others within FLAG_REFRESH_INTERVAL.
+func (s *Service) SetFeatureFlag(ctx context.Context, name string, in client.FeatureFlagRequest) (*client.FeatureFlag, error) {
+	if !flags.Known(name) {
+		return nil, fmt.Errorf("%w: unknown flag %q (want one of %s)", ErrInvalidFlag, name, strings.Join(client.FeatureFlags, ", "))
+	}
+	percent := 100
+	if in.Percent != nil {
+		percent = *in.Percent
+	}
+	if percent < 0 || percent > 100 {
+		return nil, fmt.Errorf("%w: percent must be 0-100", ErrInvalidFlag)
+	}
+	origins := make([]string, 0, len(in.Origins))
+	for _, o := range in.Origins {
+		if o = strings.TrimSpace(o); o == "" || strings.HasPrefix(o, ":") {
+			return nil, fmt.Errorf("%w: origins must be systems or system:region", ErrInvalidFlag)
+		}
+		origins = append(origins, o)
+	}
+
+	f := &client.FeatureFlag{
+		Name:      name,
+		Enabled:   in.Enabled,
+		Percent:   percent,
+		Origins:   origins,
+		Source:    client.FlagSourceStored,
+		By:        in.By,
+		UpdatedAt: time.Now(),
+	}
+	if err := storage.SaveFeatureFlag(ctx, f); err != nil {
+		return nil, err
+	}
+	flags.Reload()
+	log.Printf("🚩 Feature flag %s set to %s (by %s)", name, describeFlag(f), orAnonymous(f.By))
+	return f, nil
+}
+
+// DeleteFeatureFlag drops a flag's stored setting, returning it to
+// FEATURE_FLAGS or the default
+func (s *Service) DeleteFeatureFlag(ctx context.Context, name string) error {
+	found, err := storage.DeleteFeatureFlag(ctx, name)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("%w: %s", ErrFlagNotFound, name)
+	}
+	flags.Reload()
+	log.Printf("🚩 Feature flag %s reset", name)
+	return nil
+}
+
+func describeFlag(f *client.FeatureFlag) string {
+	if !f.Enabled {
+		return "off"
+	}
+	desc := fmt.Sprintf("%d%% of calls", f.Percent)
+	if len(f.Origins) > 0 {
+		desc += " and all from " + strings.Join(f.Origins, ", ")
+	}
+	return desc
+}
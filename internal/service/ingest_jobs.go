@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/notify"
+	"im-ai-voice/internal/storage"
+	"im-ai-voice/internal/ulid"
+)
+
+// ==================== ASYNC INGESTION ====================
+// Analyzing a call takes longer than most callers' HTTP timeouts allow, so
+// an async ingestion saves the transcript, answers with a job at once and
+// analyzes in the background, INGEST_CONCURRENCY calls at a time. The job
+// can be polled until INGEST_JOB_TTL after it was created; with a
+// callback_url, the outcome is also posted there, signed like webhook
+// deliveries when a callback_secret is given, and retried with backoff.
+// Jobs live in the instance that took them: one in flight when it stops is
+// left running, its transcript saved for POST /analyze/trigger.
+
+var (
+	ErrInvalidCallback   = errors.New("invalid callback")
+	ErrIngestJobNotFound = errors.New("ingest job not found")
+)
+
+// ingestConcurrency returns INGEST_CONCURRENCY, or the default when unset or invalid
+func ingestConcurrency() int {
+	if v := os.Getenv("INGEST_CONCURRENCY"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return n
+		}
+		log.Printf("⚠️ Invalid INGEST_CONCURRENCY=%q, using %d", v, config.DEFAULT_INGEST_CONCURRENCY)
+	}
+	return config.DEFAULT_INGEST_CONCURRENCY
+}
+
+// ValidateCallbackURL checks a callback_url before anything is ingested
+func ValidateCallbackURL(raw string) error {
+	if raw == "" {
+		return nil
+	}
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: callback_url must be an absolute http(s) URL", ErrInvalidCallback)
+	}
+	return nil
+}
+
+// StartIngestJob saves a transcript and analyzes it in the background,
+// returning the queued job's response. onDone, when set, is called with a
+// successful analysis before the callback is posted.
+func (s *Service) StartIngestJob(ctx context.Context, rt client.RawTranscript, callbackURL, callbackSecret string, onDone func(*client.AnalysisResult)) (*client.IngestResponse, error) {
+	if err := ValidateCallbackURL(callbackURL); err != nil {
+		return nil, err
+	}
+	response, err := s.IngestTranscript(ctx, rt, false)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	job := &client.IngestJob{
+		JobID:          ulid.NewAt(now),
+		CallID:         response.CallID,
+		Status:         client.IngestJobQueued,
+		CallbackURL:    strings.TrimSpace(callbackURL),
+		CallbackSecret: callbackSecret,
+		CreatedAt:      now,
+		ExpiresAt:      now.Add(config.EnvDuration("INGEST_JOB_TTL", config.DEFAULT_INGEST_JOB_TTL)),
+	}
+	if job.CallbackURL != "" {
+		job.Callback = &client.IngestCallbackDelivery{Status: client.CallbackPending}
+	}
+	if err := storage.SaveIngestJob(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to save ingest job: %w", err)
+	}
+
+	// The record gets an ID of its own, since the job ID reads the analysis,
+	// a millisecond back so it isn't the job ID's increment (see ulid.NewAt)
+	record := s.QueueJob(ctx, ulid.NewAt(now.Add(-time.Millisecond)), client.JobTypeAnalysis, job.CallID)
+	go s.runIngestJob(context.WithoutCancel(ctx), job, record, onDone)
+
+	response.Status = "accepted"
+	response.Message = "ingested, analysis queued"
+	response.JobID = job.JobID
+	return response, nil
+}
+
+// runIngestJob analyzes a job's call once a slot is free, then posts the
+// outcome to its callback. record is its entry among the jobs.
+func (s *Service) runIngestJob(ctx context.Context, job *client.IngestJob, record *JobTracker, onDone func(*client.AnalysisResult)) {
+	s.ingestSlots <- struct{}{}
+	started := time.Now()
+	job.Status, job.StartedAt = client.IngestJobRunning, &started
+	saveIngestJob(ctx, job)
+	record.Start(ctx)
+
+	analysis, err := s.ProcessSingleCallAndReturn(ctx, job.CallID)
+	<-s.ingestSlots
+	record.Finish(ctx, "/calls/"+job.CallID, err)
+
+	finished := time.Now()
+	job.FinishedAt = &finished
+	if err != nil {
+		job.Status, job.Error = client.IngestJobFailed, err.Error()
+		log.Printf("❌ Ingest job %s: analysis of %s failed: %v", job.JobID, job.CallID, err)
+	} else {
+		job.Status, job.Analysis = client.IngestJobCompleted, analysis
+		if onDone != nil {
+			onDone(analysis)
+		}
+	}
+	saveIngestJob(ctx, job)
+
+	if job.Callback != nil {
+		s.deliverIngestCallback(ctx, job)
+	}
+}
+
+// deliverIngestCallback posts a finished job to its callback_url, retrying
+// INGEST_CALLBACK_MAX_ATTEMPTS times with a doubling delay
+func (s *Service) deliverIngestCallback(ctx context.Context, job *client.IngestJob) {
+	payload := client.IngestCallback{
+		JobID:    job.JobID,
+		CallID:   job.CallID,
+		Status:   job.Status,
+		Error:    job.Error,
+		Analysis: job.Analysis,
+	}
+	delay := config.INGEST_CALLBACK_RETRY_DELAY
+	for job.Callback.Attempts < config.INGEST_CALLBACK_MAX_ATTEMPTS {
+		if job.Callback.Attempts > 0 {
+			time.Sleep(delay)
+			delay *= 2
+		}
+		job.Callback.Attempts++
+		err := notify.PostCallback(ctx, job.CallbackURL, job.CallbackSecret, payload)
+		if err == nil {
+			now := time.Now()
+			job.Callback.Status, job.Callback.LastError, job.Callback.DeliveredAt = client.CallbackDelivered, "", &now
+			saveIngestJob(ctx, job)
+			return
+		}
+		job.Callback.LastError = err.Error()
+		saveIngestJob(ctx, job)
+	}
+	job.Callback.Status = client.CallbackFailed
+	saveIngestJob(ctx, job)
+	log.Printf("⚠️ Ingest job %s: callback failed after %d attempts: %s", job.JobID, job.Callback.Attempts, job.Callback.LastError)
+}
+
+// saveIngestJob saves a job's progress, logging failures: the analysis
+// itself is already stored
+func saveIngestJob(ctx context.Context, job *client.IngestJob) {
+	if err := storage.SaveIngestJob(ctx, job); err != nil {
+		log.Printf("⚠️ Failed to save ingest job %s: %v", job.JobID, err)
+	}
+}
+
+// GetIngestJob returns an async ingestion's job, without its callback secret
+func (s *Service) GetIngestJob(ctx context.Context, jobID string) (*client.IngestJob, error) {
+	job, err := storage.LoadIngestJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, fmt.Errorf("%w: %s", ErrIngestJobNotFound, jobID)
+	}
+	job.CallbackSecret = ""
+	return job, nil
+}
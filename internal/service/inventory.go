@@ -0,0 +1,409 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/archive"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/recording"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== DATA INVENTORY ====================
+// For data-subject access requests: everything stored about a seller,
+// across every store that keys data by gluser_id or by one of their calls,
+// listed with sizes and timestamps or written out as one zip archive. A
+// store that can't be read is reported in the inventory's errors rather
+// than failing it, so the answer says what's missing.
+
+// sellerDataRecord is an inventory record with the value written to the archive
+type sellerDataRecord struct {
+	client.DataRecord
+	value interface{}
+	audio *client.Recording // Recordings: the audio is added to the archive too
+}
+
+type sellerDataCategory struct {
+	name     string
+	location string
+	records  []sellerDataRecord
+}
+
+type sellerData struct {
+	gluserID   string
+	categories []*sellerDataCategory
+	errors     []string
+}
+
+// category returns the named category, adding it on first use
+func (d *sellerData) category(name, location string) *sellerDataCategory {
+	for _, c := range d.categories {
+		if c.name == name {
+			return c
+		}
+	}
+	c := &sellerDataCategory{name: name, location: location}
+	d.categories = append(d.categories, c)
+	return c
+}
+
+// add records v under the category, sized as its JSON
+func (d *sellerData) add(name, location, id string, ts time.Time, v interface{}) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		d.fail(name, err)
+		return
+	}
+	c := d.category(name, location)
+	c.records = append(c.records, sellerDataRecord{DataRecord: client.DataRecord{ID: id, Timestamp: ts, Bytes: int64(len(b))}, value: v})
+}
+
+func (d *sellerData) fail(name string, err error) {
+	d.errors = append(d.errors, fmt.Sprintf("%s: %v", name, err))
+}
+
+// dataLocation names where a store keeps its data: MongoDB when enabled, local files otherwise
+func dataLocation(collection, dir string) string {
+	if storage.IsMongoEnabled() {
+		return "mongodb:" + collection
+	}
+	return "local:" + dir
+}
+
+// SellerDataInventory lists everything stored about a seller. A seller
+// nothing is stored about gets an empty inventory, not an error.
+func (s *Service) SellerDataInventory(ctx context.Context, gluserID string) (*client.DataInventory, error) {
+	d, err := collectSellerData(ctx, gluserID)
+	if err != nil {
+		return nil, err
+	}
+	return d.inventory(), nil
+}
+
+// WriteSellerDataArchive writes everything stored about a seller to w as a
+// zip: one JSON file per record under its category, recordings' audio next
+// to them, and the inventory as inventory.json
+func (s *Service) WriteSellerDataArchive(ctx context.Context, gluserID string, w io.Writer) error {
+	d, err := collectSellerData(ctx, gluserID)
+	if err != nil {
+		return err
+	}
+
+	zw := zip.NewWriter(w)
+	for _, c := range d.categories {
+		for _, r := range c.records {
+			name := path.Join(c.name, storage.Sanitize(r.ID))
+			if err := writeZipJSON(zw, name+".json", r.value); err != nil {
+				return err
+			}
+			if r.audio == nil {
+				continue
+			}
+			_, audio, err := recording.Open(ctx, r.audio.CallID)
+			if err != nil {
+				d.fail(c.name, fmt.Errorf("audio of %s: %w", r.ID, err))
+				continue
+			}
+			f, err := zw.Create(name + path.Ext(r.audio.Key))
+			if err != nil {
+				return err
+			}
+			if _, err := f.Write(audio); err != nil {
+				return err
+			}
+		}
+	}
+	// Last, so it lists the audio that couldn't be read
+	if err := writeZipJSON(zw, "inventory.json", d.inventory()); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+func writeZipJSON(zw *zip.Writer, name string, v interface{}) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func (d *sellerData) inventory() *client.DataInventory {
+	inv := &client.DataInventory{GluserID: d.gluserID, Categories: []client.DataCategory{}, Errors: d.errors, GeneratedAt: time.Now()}
+	for _, c := range d.categories {
+		if len(c.records) == 0 {
+			continue
+		}
+		dc := client.DataCategory{Name: c.name, Location: c.location, Records: make([]client.DataRecord, 0, len(c.records))}
+		for _, r := range c.records {
+			dc.Records = append(dc.Records, r.DataRecord)
+			dc.Items++
+			dc.Bytes += r.Bytes
+			if ts := r.Timestamp; !ts.IsZero() {
+				if dc.Oldest == nil || ts.Before(*dc.Oldest) {
+					dc.Oldest = &ts
+				}
+				if dc.Newest == nil || ts.After(*dc.Newest) {
+					dc.Newest = &ts
+				}
+			}
+		}
+		inv.Categories = append(inv.Categories, dc)
+		inv.TotalItems += dc.Items
+		inv.TotalBytes += dc.Bytes
+	}
+	return inv
+}
+
+// collectSellerData loads the seller's data from every store. Only a
+// cancelled ctx fails it; stores that can't be read are noted in errors.
+func collectSellerData(ctx context.Context, gluserID string) (*sellerData, error) {
+	d := &sellerData{gluserID: gluserID}
+
+	profile, err := storage.LoadSellerProfile(ctx, gluserID)
+	if err != nil {
+		d.fail("profile", err)
+	} else if profile != nil {
+		d.add("profile", dataLocation(storage.COLLECTION_PROFILES, config.PROFILES_DIR), gluserID, profile.UpdatedAt, profile)
+		for _, issues := range [][]client.TrackedIssue{profile.ActiveIssues, profile.ResolvedIssues} {
+			for _, issue := range issues {
+				d.add("issues", dataLocation(storage.COLLECTION_ISSUES, config.PROFILES_DIR), issue.IssueID, issue.FirstReportedAt, issue)
+			}
+		}
+	}
+
+	// Calls, hot and archived, key the per-call stores
+	var callIDs []string
+	analyses, err := storage.LoadSellerAnalyses(ctx, gluserID)
+	if err != nil {
+		d.fail("analyses", err)
+	}
+	for _, ar := range analyses {
+		d.add("analyses", dataLocation(storage.COLLECTION_ANALYSES, config.ANALYSIS_DIR), ar.CallID, ar.Timestamp, ar)
+		callIDs = append(callIDs, ar.CallID)
+	}
+	archived, err := archive.QueryAnalyses(ctx, gluserID, time.Time{}, time.Time{})
+	if err != nil {
+		d.fail("archived_analyses", err)
+	}
+	for _, ar := range archived {
+		d.add("archived_analyses", archive.Archive.Name(), ar.CallID, ar.Timestamp, ar)
+		if !slices.Contains(callIDs, ar.CallID) {
+			callIDs = append(callIDs, ar.CallID)
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	collectCallData(ctx, d, callIDs)
+	collectSellerRecords(ctx, d)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// collectCallData adds what's stored per call: transcripts, extractions, raw LLM output and recordings
+func collectCallData(ctx context.Context, d *sellerData, callIDs []string) {
+	for _, id := range callIDs {
+		rt, err := storage.LoadRawTranscript(id)
+		switch {
+		case err == nil:
+			d.add("transcripts", "local:"+config.TRANSCRIPTS_DIR, id, rt.Timestamp, rt)
+		case !errors.Is(err, fs.ErrNotExist):
+			d.fail("transcripts", err)
+		}
+	}
+
+	extractions, err := storage.LoadExtractionsFor(ctx, callIDs)
+	if err != nil {
+		d.fail("extractions", err)
+	}
+	for _, ext := range extractions {
+		d.add("extractions", dataLocation(storage.COLLECTION_EXTRACTIONS, config.EXTRACTIONS_DIR), ext.CallID, ext.Timestamp, ext)
+	}
+
+	for _, id := range callIDs {
+		if ctx.Err() != nil {
+			return
+		}
+		raw, err := storage.LoadLLMRaw(ctx, id)
+		if err != nil {
+			d.fail("llm_raw", fmt.Errorf("%s: %w", id, err))
+		} else if raw != nil {
+			d.add("llm_raw", dataLocation(storage.COLLECTION_LLM_RAW, config.LLM_RAW_DIR), id, raw.CreatedAt, raw)
+		}
+
+		// Replay snapshots of the analysis, local only
+		if b, err := os.ReadFile(filepath.Join(config.LLM_CACHE_DIR, storage.Sanitize(id)+".json")); err == nil {
+			var cached client.AnalysisResult
+			if err := json.Unmarshal(b, &cached); err == nil {
+				d.add("llm_cache", "local:"+config.LLM_CACHE_DIR, id, cached.Timestamp, cached)
+			}
+		}
+
+		rec, err := storage.LoadRecording(ctx, id)
+		if err != nil {
+			d.fail("recordings", fmt.Errorf("%s: %w", id, err))
+		} else if rec != nil {
+			location := dataLocation(storage.COLLECTION_RECORDINGS, config.RECORDINGS_DIR)
+			if recording.Store != nil {
+				location = recording.Store.Name()
+			}
+			r := sellerDataRecord{DataRecord: client.DataRecord{ID: id, Timestamp: rec.Timestamp}, value: rec}
+			if rec.PurgedAt == nil {
+				r.Bytes, r.audio = rec.SizeBytes, rec
+			}
+			c := d.category("recordings", location)
+			c.records = append(c.records, r)
+		}
+	}
+}
+
+// collectSellerRecords adds what's stored per seller apart from the profile
+func collectSellerRecords(ctx context.Context, d *sellerData) {
+	metrics, err := storage.LoadSellerMetrics(ctx, storage.MetricQuery{GluserID: d.gluserID})
+	if err != nil {
+		d.fail("metrics", err)
+	}
+	for _, m := range metrics {
+		d.add("metrics", dataLocation(storage.COLLECTION_SELLER_METRICS, config.METRICS_DIR), m.CallID, m.Timestamp, m)
+	}
+
+	commitments, err := storage.LoadCommitments(ctx, storage.CommitmentQuery{GluserID: d.gluserID})
+	if err != nil {
+		d.fail("commitments", err)
+	}
+	for _, c := range commitments {
+		d.add("commitments", dataLocation(storage.COLLECTION_COMMITMENTS, config.COMMITMENTS_DIR), c.ID, c.CallTime, c)
+	}
+
+	pitches, err := storage.LoadPitches(ctx, storage.PitchQuery{GluserID: d.gluserID})
+	if err != nil {
+		d.fail("upsell_pitches", err)
+	}
+	for _, p := range pitches {
+		d.add("upsell_pitches", dataLocation(storage.COLLECTION_PITCHES, config.PITCHES_DIR), p.ID, p.CallTime, p)
+	}
+
+	quotes, err := storage.LoadQuotes(ctx, storage.QuoteQuery{GluserID: d.gluserID})
+	if err != nil {
+		d.fail("quotes", err)
+	}
+	for _, q := range quotes {
+		d.add("quotes", dataLocation(storage.COLLECTION_QUOTES, config.QUOTES_DIR), q.ID, q.CallTime, q)
+	}
+	optOut, err := storage.LoadQuoteOptOut(ctx, d.gluserID)
+	if err != nil {
+		d.fail("quote_opt_out", err)
+	} else if optOut != nil {
+		d.add("quote_opt_out", dataLocation(storage.COLLECTION_QUOTE_OPT_OUTS, config.QUOTES_DIR), d.gluserID, optOut.At, optOut)
+	}
+
+	samples, err := storage.LoadCallSamples(ctx, storage.SampleQuery{GluserID: d.gluserID})
+	if err != nil {
+		d.fail("call_samples", err)
+	}
+	for _, s := range samples {
+		d.add("call_samples", dataLocation(storage.COLLECTION_SAMPLES, config.SAMPLES_DIR), s.ID, s.CreatedAt, s)
+	}
+
+	annotations, err := storage.LoadAnnotations(ctx, storage.AnnotationQuery{GluserID: d.gluserID})
+	if err != nil {
+		d.fail("annotations", err)
+	}
+	for _, a := range annotations {
+		d.add("annotations", dataLocation(storage.COLLECTION_ANNOTATIONS, config.ANNOTATIONS_DIR), a.ID, a.CreatedAt, a)
+	}
+
+	chats, err := storage.LoadSellerCallChats(ctx, d.gluserID)
+	if err != nil {
+		d.fail("call_chats", err)
+	}
+	for _, c := range chats {
+		d.add("call_chats", dataLocation(storage.COLLECTION_CALL_CHATS, config.CALL_CHATS_DIR), c.SessionID, c.CreatedAt, c)
+	}
+
+	outcomes, err := storage.LoadSellerChurnOutcomes(ctx, d.gluserID)
+	if err != nil {
+		d.fail("churn_outcomes", err)
+	}
+	for _, o := range outcomes {
+		d.add("churn_outcomes", dataLocation(storage.COLLECTION_CHURN_OUTCOMES, config.CHURN_OUTCOMES_DIR), o.Date, o.RecordedAt, o)
+	}
+
+	ack, err := storage.LoadAttentionAck(ctx, d.gluserID)
+	if err != nil {
+		d.fail("attention", err)
+	} else if ack != nil {
+		d.add("attention", dataLocation(storage.COLLECTION_ATTENTION, config.ATTENTION_DIR), d.gluserID, ack.CreatedAt, ack)
+	}
+
+	q := storage.EventQuery{GluserID: d.gluserID, Limit: 1000}
+	for ctx.Err() == nil {
+		page, err := storage.QueryEvents(ctx, q)
+		if err != nil {
+			d.fail("events", err)
+			break
+		}
+		for _, e := range page.Events {
+			d.add("events", dataLocation(storage.COLLECTION_EVENTS, config.EVENTS_DIR), e.EventID, e.Timestamp, e)
+		}
+		if !page.HasMore {
+			break
+		}
+		q.Cursor = page.NextCursor
+	}
+
+	collectSellerTickets(ctx, d)
+}
+
+// collectSellerTickets adds the tickets that list the seller as affected
+func collectSellerTickets(ctx context.Context, d *sellerData) {
+	var dates []string
+	var err error
+	if storage.IsMongoEnabled() {
+		dates, err = storage.ListTicketDatesFromMongo(ctx)
+	}
+	if len(dates) == 0 {
+		dates, err = storage.ListTicketDates()
+	}
+	if err != nil {
+		d.fail("tickets", err)
+		return
+	}
+
+	location := dataLocation(storage.COLLECTION_TICKETS, config.TICKETS_DIR)
+	for _, date := range dates {
+		if ctx.Err() != nil {
+			return
+		}
+		var tickets []client.Ticket
+		if storage.IsMongoEnabled() {
+			tickets, _ = storage.GetTicketsForDateFromMongo(ctx, date)
+		}
+		if len(tickets) == 0 {
+			tickets, _ = storage.LoadTicketsForDate(date)
+		}
+		for _, t := range tickets {
+			if slices.Contains(t.AffectedSellers, d.gluserID) {
+				d.add("tickets", location, t.Date+"_"+t.TicketID, t.CreatedAt, t)
+			}
+		}
+	}
+}
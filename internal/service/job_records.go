@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/storage"
+	"im-ai-voice/internal/ulid"
+)
+
+// ==================== JOB RECORDS ====================
+// Aggregations, backfills, rebuilds, exports and async analyses each keep a
+// job record while they run: state, progress through their items, timing,
+// and once done where to read the outcome. Records are kept for JOB_TTL.
+// Whoever runs the operation records it, the CLI included, so the records
+// cover every instance; one still running that hasn't been updated in a
+// while was stopped with its instance.
+
+var (
+	ErrInvalidJobQuery = errors.New("invalid job query")
+	ErrJobNotFound     = errors.New("job not found")
+)
+
+// JobTracker keeps an operation's job record up to date. Failing to save
+// the record never fails the operation, it's logged.
+type JobTracker struct {
+	job   client.Job
+	saved time.Time
+}
+
+// QueueJob records an operation that's waiting to start, under id
+func (s *Service) QueueJob(ctx context.Context, id, jobType, subject string) *JobTracker {
+	now := time.Now()
+	t := &JobTracker{job: client.Job{
+		ID:        id,
+		Type:      jobType,
+		Subject:   subject,
+		Status:    client.JobQueued,
+		CreatedAt: now,
+		ExpiresAt: now.Add(config.EnvDuration("JOB_TTL", config.DEFAULT_JOB_TTL)),
+	}}
+	t.save(ctx)
+	return t
+}
+
+// StartJob records an operation starting now
+func (s *Service) StartJob(ctx context.Context, jobType, subject string) *JobTracker {
+	t := s.QueueJob(ctx, ulid.New(), jobType, subject)
+	t.Start(ctx)
+	return t
+}
+
+// ID returns the job's ID
+func (t *JobTracker) ID() string {
+	return t.job.ID
+}
+
+// Start marks a queued job running
+func (t *JobTracker) Start(ctx context.Context) {
+	now := time.Now()
+	t.job.Status, t.job.StartedAt = client.JobRunning, &now
+	t.save(ctx)
+}
+
+// Progress records how many of its items the job has done, saved at most
+// every JOB_PROGRESS_INTERVAL
+func (t *JobTracker) Progress(ctx context.Context, done, total int) {
+	t.job.Progress = &client.JobProgress{Done: done, Total: total}
+	if done < total && time.Since(t.saved) < config.JOB_PROGRESS_INTERVAL {
+		return
+	}
+	t.save(ctx)
+}
+
+// Finish records the job's outcome: failed with err, or completed with
+// result, where its outcome can be read (empty when there's nowhere)
+func (t *JobTracker) Finish(ctx context.Context, result string, err error) {
+	now := time.Now()
+	t.job.FinishedAt = &now
+	if t.job.StartedAt != nil {
+		t.job.DurationMS = now.Sub(*t.job.StartedAt).Milliseconds()
+	}
+	if err != nil {
+		t.job.Status, t.job.Error = client.JobFailed, err.Error()
+	} else {
+		t.job.Status, t.job.Result = client.JobCompleted, result
+	}
+	t.save(ctx)
+}
+
+func (t *JobTracker) save(ctx context.Context) {
+	t.job.UpdatedAt = time.Now()
+	t.saved = t.job.UpdatedAt
+	job := t.job
+	if err := storage.SaveJob(context.WithoutCancel(ctx), &job); err != nil {
+		log.Printf("⚠️ Failed to save %s job %s: %v", job.Type, job.ID, err)
+	}
+}
+
+// GetJob returns a long-running operation's record
+func (s *Service) GetJob(ctx context.Context, id string) (*client.Job, error) {
+	job, err := storage.LoadJob(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, fmt.Errorf("%w: %s", ErrJobNotFound, id)
+	}
+	return job, nil
+}
+
+// ListJobs lists long-running operations, newest first, optionally of one
+// type and status. limit defaults to JOBS_LIST_LIMIT.
+func (s *Service) ListJobs(ctx context.Context, jobType, status, limit string) ([]client.Job, error) {
+	if jobType != "" && !slices.Contains(client.JobTypes, jobType) {
+		return nil, fmt.Errorf("%w: unknown type %q (want one of %s)", ErrInvalidJobQuery, jobType, strings.Join(client.JobTypes, ", "))
+	}
+	if status != "" && !slices.Contains(client.JobStatuses, status) {
+		return nil, fmt.Errorf("%w: unknown status %q (want one of %s)", ErrInvalidJobQuery, status, strings.Join(client.JobStatuses, ", "))
+	}
+	n := config.JOBS_LIST_LIMIT
+	if limit != "" {
+		var err error
+		if n, err = strconv.Atoi(limit); err != nil || n < 1 || n > config.JOBS_LIST_MAX {
+			return nil, fmt.Errorf("%w: limit must be 1-%d", ErrInvalidJobQuery, config.JOBS_LIST_MAX)
+		}
+	}
+	return storage.LoadJobs(ctx, storage.JobQuery{Type: jobType, Status: status, Limit: n})
+}
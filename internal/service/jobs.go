@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"im-ai-voice/internal/archive"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/notify"
+	"im-ai-voice/internal/profile"
+	"im-ai-voice/internal/recording"
+	"im-ai-voice/internal/scheduler"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== SCHEDULED JOBS ====================
+// The leader's background work, as jobs for the scheduler. Default
+// schedules are in BUSINESS_TIMEZONE; SCHEDULE_<JOB> overrides them.
+
+// RegisterJobs adds the service's background jobs to sched
+func (s *Service) RegisterJobs(sched *scheduler.Scheduler) {
+	sched.Add(scheduler.Job{
+		Name:        "aggregation",
+		Description: "Re-aggregate yesterday once all its calls are in",
+		Spec:        "15 0 * * *",
+		Run: func(ctx context.Context) error {
+			_, err := s.RunAggregation(ctx, config.BusinessDate(time.Now().AddDate(0, 0, -1)))
+			return err
+		},
+	})
+	sched.Add(scheduler.Job{
+		Name:        "aggregate_staleness",
+		Description: fmt.Sprintf("Flag aggregates of the last %d days whose analyses changed", AggregateStaleDays()),
+		Spec:        "20 * * * *",
+		Run: func(ctx context.Context) error {
+			_, err := s.CheckAggregates(ctx, aggregateStaleRecompute())
+			return err
+		},
+	})
+	sched.Add(scheduler.Job{
+		Name:        "archive",
+		Description: fmt.Sprintf("Move analyses older than %d days to the cold archive", archive.AfterDays()),
+		Spec:        "0 3 * * *",
+		Run: func(ctx context.Context) error {
+			_, err := archive.Run(ctx, time.Now().AddDate(0, 0, -archive.AfterDays()))
+			return err
+		},
+	})
+	sched.Add(scheduler.Job{
+		Name:        "llm_raw_purge",
+		Description: "Delete raw Gemini responses and recorded interactions past LLM_RAW_RETENTION_DAYS (MongoDB expires them itself)",
+		Spec:        "30 3 * * *",
+		Run: func(ctx context.Context) error {
+			if _, err := storage.PurgeExpiredLLMRaw(ctx); err != nil {
+				return err
+			}
+			_, err := storage.PurgeExpiredLLMInteractions(ctx)
+			return err
+		},
+	})
+	sched.Add(scheduler.Job{
+		Name:        "trash_purge",
+		Description: "Delete trashed analyses and tickets past their purge date for good",
+		Spec:        "45 3 * * *",
+		Run: func(ctx context.Context) error {
+			_, err := PurgeTrash(ctx)
+			return err
+		},
+	})
+	sched.Add(scheduler.Job{
+		Name:        "seller_summaries",
+		Description: "Rewrite every seller's summary from their profile, repairing ones that failed to save",
+		Spec:        "55 3 * * *",
+		Run: func(ctx context.Context) error {
+			_, err := storage.SyncSellerSummaries(ctx, true)
+			return err
+		},
+	})
+	sched.Add(scheduler.Job{
+		Name:        "recording_retention",
+		Description: fmt.Sprintf("Delete call audio older than %d days", recording.RetentionDays()),
+		Spec:        "0 4 * * *",
+		Run: func(ctx context.Context) error {
+			_, err := recording.Purge(ctx, time.Now().AddDate(0, 0, -recording.RetentionDays()))
+			return err
+		},
+	})
+	sched.Add(scheduler.Job{
+		Name:        "escalation",
+		Description: "Escalate high-severity issues open too long",
+		Spec:        "0 * * * *",
+		Run: func(ctx context.Context) error {
+			_, err := profile.RunStaleIssueEscalation(ctx)
+			return err
+		},
+	})
+	if profile.ResolutionVerifyDays() > 0 {
+		sched.Add(scheduler.Job{
+			Name:        "resolution_verification",
+			Description: fmt.Sprintf("Mark issue resolutions that held for %d days durable", profile.ResolutionVerifyDays()),
+			Spec:        "10 * * * *",
+			Run: func(ctx context.Context) error {
+				_, err := profile.RunResolutionVerification(ctx)
+				return err
+			},
+		})
+	}
+	sched.Add(scheduler.Job{
+		Name:        "commitments",
+		Description: "Mark open commitments past their due date broken",
+		Spec:        "30 * * * *",
+		Run: func(ctx context.Context) error {
+			_, err := RunCommitmentExpiry(ctx)
+			return err
+		},
+	})
+	sched.Add(scheduler.Job{
+		Name:        "upsell_pitches",
+		Description: "Mark open upsell pitches past the conversion window lapsed",
+		Spec:        "40 3 * * *",
+		Run: func(ctx context.Context) error {
+			_, err := RunPitchExpiry(ctx)
+			return err
+		},
+	})
+	sched.Add(scheduler.Job{
+		Name:        "severity_calibration",
+		Description: "Compare issue severities with their outcomes per bucket",
+		Spec:        "0 5 * * 1",
+		Run: func(ctx context.Context) error {
+			_, err := s.RunSeverityCalibration(ctx, config.BusinessDate(time.Now()))
+			return err
+		},
+	})
+	sched.Add(scheduler.Job{
+		Name:        "cases",
+		Description: "Close seller cases settled for CASE_CLOSE_DAYS and decay closed cases' health scores",
+		Spec:        "50 3 * * *",
+		Run: func(ctx context.Context) error {
+			_, err := profile.RunCaseClosure(ctx)
+			return err
+		},
+	})
+	sched.Add(scheduler.Job{
+		Name:        "override_expiry",
+		Description: "End churn and sentiment overrides past their expiry",
+		Spec:        "40 * * * *",
+		Run: func(ctx context.Context) error {
+			_, err := profile.RunOverrideExpiry(ctx)
+			return err
+		},
+	})
+
+	if len(notify.SplitList(os.Getenv("DIGEST_RECIPIENTS"))) > 0 {
+		sched.Add(scheduler.Job{
+			Name:        "digest",
+			Description: "Email the previous day's digest",
+			Spec:        fmt.Sprintf("0 %d * * *", digestHour()),
+			Run: func(ctx context.Context) error {
+				_, _, err := s.SendDailyDigest(ctx, config.BusinessDate(time.Now().AddDate(0, 0, -1)))
+				return err
+			},
+		})
+	} else {
+		log.Println("📧 Daily digest disabled (DIGEST_RECIPIENTS not set)")
+	}
+	if len(weeklyRecipients()) > 0 {
+		sched.Add(scheduler.Job{
+			Name:        "weekly_report",
+			Description: "Email the executive report of the week ending yesterday",
+			Spec:        fmt.Sprintf("0 %d * * 1", weeklyReportHour()),
+			Run: func(ctx context.Context) error {
+				_, _, err := s.SendWeeklyReport(ctx, config.BusinessDate(time.Now().AddDate(0, 0, -1)))
+				return err
+			},
+		})
+	} else {
+		log.Println("📧 Weekly report disabled (WEEKLY_REPORT_RECIPIENTS and DIGEST_RECIPIENTS not set)")
+	}
+
+	if s.ai != nil {
+		sched.Add(scheduler.Job{
+			Name:        "systemic",
+			Description: "Cluster open issues into systemic issues",
+			Spec:        fmt.Sprintf("0 %d * * *", systemicHour()),
+			Run: func(ctx context.Context) error {
+				_, err := s.RunSystemicClustering(ctx)
+				return err
+			},
+		})
+		sched.Add(scheduler.Job{
+			Name:        "themes",
+			Description: "Cluster the past week's key insights into themes",
+			Spec:        fmt.Sprintf("0 %d * * *", themesHour()),
+			Run: func(ctx context.Context) error {
+				_, err := s.RunInsightThemes(ctx, config.BusinessDate(time.Now().AddDate(0, 0, -1)))
+				return err
+			},
+		})
+		sched.Add(scheduler.Job{
+			Name:        "rollouts",
+			Description: "Roll back the active canary rollout if its analyses breach a threshold",
+			Spec:        "*/5 * * * *",
+			Run: func(ctx context.Context) error {
+				_, err := s.CheckRollouts(ctx)
+				return err
+			},
+		})
+		sched.Add(scheduler.Job{
+			Name:        "provisional_upgrade",
+			Description: "Replace provisional analyses made while Gemini was unavailable with full ones",
+			Spec:        "*/5 * * * *",
+			Run: func(ctx context.Context) error {
+				_, err := s.UpgradeProvisional(ctx)
+				return err
+			},
+		})
+		sched.Add(scheduler.Job{
+			Name:        "eval",
+			Description: fmt.Sprintf("Record every model and prompt version's metrics weekly, and when a version changes (last %d days)", config.EVAL_WINDOW_DAYS),
+			Spec:        "30 5 * * *",
+			Run: func(ctx context.Context) error {
+				_, err := s.ScheduledEval(ctx)
+				return err
+			},
+		})
+	} else {
+		log.Println("🧩 Systemic issue clustering and insight themes disabled (no AI client)")
+	}
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/llm"
+	"im-ai-voice/internal/storage"
+	"im-ai-voice/internal/ulid"
+)
+
+// ==================== KNOWLEDGE BASE ====================
+
+const (
+	kbMaxTitleLength   = 200
+	kbMaxDocumentBytes = 200 << 10
+)
+
+var (
+	ErrInvalidKBDocument  = errors.New("invalid knowledge base document")
+	ErrKBDocumentNotFound = errors.New("knowledge base document not found")
+)
+
+// ListKBDocuments returns the knowledge base documents, oldest first, without their content
+func (s *Service) ListKBDocuments(ctx context.Context) ([]client.KBDocument, error) {
+	docs, err := storage.LoadKBDocuments(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range docs {
+		docs[i].Content, docs[i].Chunks = "", nil
+	}
+	return docs, nil
+}
+
+// GetKBDocument returns a document with its content and passages
+func (s *Service) GetKBDocument(ctx context.Context, id string) (*client.KBDocument, error) {
+	docs, err := storage.LoadKBDocuments(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range docs {
+		if docs[i].ID == id {
+			doc := &docs[i]
+			for j := range doc.Chunks {
+				doc.Chunks[j].Vector = nil
+			}
+			return doc, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrKBDocumentNotFound, id)
+}
+
+// SaveKBDocument chunks and embeds a document and adds it to the knowledge
+// base, replacing the document with the same title. Analyses use it as soon
+// as it's saved.
+func (s *Service) SaveKBDocument(ctx context.Context, in client.KBDocumentRequest) (*client.KBDocument, error) {
+	in.Title = strings.TrimSpace(in.Title)
+	in.Content = strings.TrimSpace(in.Content)
+	switch {
+	case in.Title == "":
+		return nil, fmt.Errorf("%w: title is required", ErrInvalidKBDocument)
+	case len(in.Title) > kbMaxTitleLength:
+		return nil, fmt.Errorf("%w: title is longer than %d characters", ErrInvalidKBDocument, kbMaxTitleLength)
+	case in.Content == "":
+		return nil, fmt.Errorf("%w: content is required", ErrInvalidKBDocument)
+	case len(in.Content) > kbMaxDocumentBytes:
+		return nil, fmt.Errorf("%w: content is larger than %d KB", ErrInvalidKBDocument, kbMaxDocumentBytes>>10)
+	case s.ai == nil:
+		return nil, fmt.Errorf("AI client not configured")
+	}
+
+	texts := llm.ChunkDocument(in.Content)
+	vectors, err := s.ai.EmbedKBChunks(ctx, in.Title, texts)
+	if err != nil {
+		log.Printf("⚠️ Failed to embed knowledge base document %q: %v", in.Title, err)
+		return nil, fmt.Errorf("failed to embed document, the LLM request failed") // err can carry Gemini's URL and response
+	}
+
+	docs, err := storage.LoadKBDocuments(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	doc := &client.KBDocument{ID: ulid.NewAt(now), CreatedAt: now}
+	for _, d := range docs {
+		if strings.EqualFold(d.Title, in.Title) {
+			doc.ID, doc.CreatedAt = d.ID, d.CreatedAt
+		}
+	}
+	doc.Title = in.Title
+	doc.Content = in.Content
+	doc.ChunkCount = len(texts)
+	doc.By = in.By
+	doc.UpdatedAt = now
+	for i, text := range texts {
+		doc.Chunks = append(doc.Chunks, client.KBChunk{Index: i, Text: text, Vector: vectors[i]})
+	}
+	if err := storage.SaveKBDocument(ctx, doc); err != nil {
+		return nil, err
+	}
+	log.Printf("📚 Knowledge base document %s %q saved by %s: %d passages", doc.ID, doc.Title, orAnonymous(doc.By), doc.ChunkCount)
+	s.reloadKnowledgeBase(ctx)
+
+	doc.Content, doc.Chunks = "", nil
+	return doc, nil
+}
+
+// DeleteKBDocument removes a document from the knowledge base
+func (s *Service) DeleteKBDocument(ctx context.Context, id string) error {
+	found, err := storage.DeleteKBDocument(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("%w: %s", ErrKBDocumentNotFound, id)
+	}
+	log.Printf("📚 Knowledge base document %s removed", id)
+	s.reloadKnowledgeBase(ctx)
+	return nil
+}
+
+// LoadKnowledgeBase hands the stored documents to the AI client, so analyses are grounded in them
+func (s *Service) LoadKnowledgeBase(ctx context.Context) error {
+	if s.ai == nil {
+		return nil
+	}
+	docs, err := storage.LoadKBDocuments(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load knowledge base: %w", err)
+	}
+	s.ai.SetKnowledgeBase(docs)
+	return nil
+}
+
+func (s *Service) reloadKnowledgeBase(ctx context.Context) {
+	if err := s.LoadKnowledgeBase(ctx); err != nil {
+		log.Printf("⚠️ %v", err)
+	}
+}
+
+// StartKnowledgeBaseRefresher loads the knowledge base and reloads it every
+// KB_REFRESH_INTERVAL, so documents uploaded through another instance reach
+// this one's analyses. Severity calibration hints are reloaded with it. It
+// runs on every instance, not just the leader.
+func (s *Service) StartKnowledgeBaseRefresher(ctx context.Context) {
+	if s.ai == nil {
+		return
+	}
+	s.reloadKnowledgeBase(ctx)
+	s.reloadSeverityHints(ctx)
+	if n := s.ai.KnowledgePassages(); n > 0 {
+		log.Printf("📚 Knowledge base: %d passages", n)
+	} else {
+		log.Println("📚 Knowledge base empty, analyses use the built-in IndiaMART context")
+	}
+
+	go func() {
+		ticker := time.NewTicker(config.KB_REFRESH_INTERVAL)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.reloadKnowledgeBase(ctx)
+				s.reloadSeverityHints(ctx)
+			}
+		}
+	}()
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/llm"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== LLM INTERACTION REPLAY ====================
+// Recorded Gemini interactions (LLM_RECORD=true) are parsed again with the
+// current parsing code and compared to the stored analyses, to check a
+// parser change against real responses without calling Gemini. Nothing is
+// written back.
+
+// LLMReplayResult sums up an LLM interaction replay
+type LLMReplayResult struct {
+	Calls       int             `json:"calls"`
+	Parsed      int             `json:"parsed"`
+	ParseFailed int             `json:"parse_failed"`
+	Changed     int             `json:"changed"`
+	NoAnalysis  int             `json:"no_analysis"`
+	Differences []LLMReplayCall `json:"differences,omitempty"`
+}
+
+// LLMReplayCall is one replayed call that failed to parse or parsed
+// differently from its stored analysis
+type LLMReplayCall struct {
+	CallID        string   `json:"call_id"`
+	PromptVersion string   `json:"prompt_version"`
+	Error         string   `json:"error,omitempty"`
+	Changes       []string `json:"changes,omitempty"`
+}
+
+// ReplayLLMInteractions re-parses the recorded interactions matching q
+func (s *Service) ReplayLLMInteractions(ctx context.Context, q storage.LLMInteractionQuery) (*LLMReplayResult, error) {
+	interactions, err := storage.LoadLLMInteractions(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load LLM interactions: %w", err)
+	}
+
+	type recorded struct{ extraction, scoring *client.LLMInteraction }
+	type key struct{ callID, promptVersion string }
+	calls := make(map[key]*recorded)
+	var keys []key
+	for i := range interactions {
+		in := &interactions[i]
+		k := key{in.CallID, in.PromptVersion}
+		r := calls[k]
+		if r == nil {
+			r = &recorded{}
+			calls[k] = r
+			keys = append(keys, k)
+		}
+		switch llm.Task(in.Pass) {
+		case llm.TaskExtraction:
+			r.extraction = in
+		case llm.TaskScoring:
+			r.scoring = in
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].callID != keys[j].callID {
+			return keys[i].callID < keys[j].callID
+		}
+		return keys[i].promptVersion < keys[j].promptVersion
+	})
+
+	reg := llm.PromptRegistryFromEnv()
+	result := &LLMReplayResult{}
+	for _, k := range keys {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		result.Calls++
+		r := calls[k]
+		replayed, err := llm.ReplayInteractions(reg, r.extraction, r.scoring)
+		if err != nil {
+			result.ParseFailed++
+			result.Differences = append(result.Differences, LLMReplayCall{CallID: k.callID, PromptVersion: k.promptVersion, Error: err.Error()})
+			continue
+		}
+		result.Parsed++
+
+		stored, _ := s.GetCallAnalysis(ctx, k.callID)
+		if stored == nil {
+			result.NoAnalysis++
+			continue
+		}
+		if changes := analysisChanges(stored, replayed, r.scoring != nil); len(changes) > 0 {
+			result.Changed++
+			result.Differences = append(result.Differences, LLMReplayCall{CallID: k.callID, PromptVersion: k.promptVersion, Changes: changes})
+		}
+	}
+	return result, nil
+}
+
+// analysisChanges lists what a replayed analysis says differently from the
+// stored one; scores are only compared when the scoring pass was recorded
+func analysisChanges(stored, replayed *client.AnalysisResult, scored bool) []string {
+	var changes []string
+	diff := func(field string, was, now any) {
+		if was != now {
+			changes = append(changes, fmt.Sprintf("%s: %v -> %v", field, was, now))
+		}
+	}
+	diff("issues", len(stored.Issues), len(replayed.Issues))
+	diff("buckets", issueBuckets(stored), issueBuckets(replayed))
+	diff("summary_empty", stored.CallSummary == "", replayed.CallSummary == "")
+	if scored {
+		diff("sentiment", stored.Intent.Sentiment, replayed.Intent.Sentiment)
+		diff("satisfaction_score", stored.Intent.SatisfactionScore, replayed.Intent.SatisfactionScore)
+		diff("churn_risk", stored.Churn.IsLikelyToChurn, replayed.Churn.IsLikelyToChurn)
+		diff("churn_reason_category", stored.Churn.ChurnReasonCategory, replayed.Churn.ChurnReasonCategory)
+	}
+	return changes
+}
+
+// issueBuckets is an analysis's issue buckets, sorted and joined
+func issueBuckets(ar *client.AnalysisResult) string {
+	buckets := make([]string, 0, len(ar.Issues))
+	for _, i := range ar.Issues {
+		buckets = append(buckets, i.Bucket)
+	}
+	slices.Sort(buckets)
+	return fmt.Sprint(buckets)
+}
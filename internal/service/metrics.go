@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/profile"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== SELLER METRICS BACKFILL ====================
+// Calls processed before seller_metrics existed only have trend points in
+// the profile, and those get rolled up by day. Their metrics can be rebuilt
+// from the stored analyses without reprocessing anything.
+
+// BackfillResult summarizes a metrics backfill
+type BackfillResult struct {
+	Analyses int `json:"analyses"`
+	Sellers  int `json:"sellers"`
+	Existing int `json:"existing"` // Calls that already had a metric
+	Added    int `json:"added"`
+	Skipped  int `json:"skipped"` // Analyses without a seller ID
+}
+
+// BackfillSellerMetrics records a metric for every analyzed call that doesn't have one
+func (s *Service) BackfillSellerMetrics(ctx context.Context, dryRun bool) (*BackfillResult, error) {
+	if dryRun {
+		return s.backfillSellerMetrics(ctx, true, nil)
+	}
+	job := s.StartJob(ctx, client.JobTypeBackfill, "seller_metrics")
+	result, err := s.backfillSellerMetrics(ctx, false, job)
+	job.Finish(ctx, "", err)
+	return result, err
+}
+
+// backfillSellerMetrics runs a backfill, recording its progress in job
+// unless it's a dry run
+func (s *Service) backfillSellerMetrics(ctx context.Context, dryRun bool, job *JobTracker) (*BackfillResult, error) {
+	analyses, err := loadAllAnalyses(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load analyses: %w", err)
+	}
+
+	bySeller := make(map[string][]client.AnalysisResult)
+	result := &BackfillResult{Analyses: len(analyses)}
+	for _, ar := range analyses {
+		if ar.SellerID == "" || ar.CallID == "" {
+			result.Skipped++
+			continue
+		}
+		bySeller[ar.SellerID] = append(bySeller[ar.SellerID], ar)
+	}
+	result.Sellers = len(bySeller)
+
+	done := 0
+	for gluserID, calls := range bySeller {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		if job != nil {
+			job.Progress(ctx, done, len(bySeller))
+		}
+		done++
+
+		existing, err := storage.LoadSellerMetrics(ctx, storage.MetricQuery{GluserID: gluserID})
+		if err != nil {
+			return result, fmt.Errorf("failed to load metrics for %s: %w", gluserID, err)
+		}
+		have := make(map[string]bool, len(existing))
+		for _, m := range existing {
+			have[m.CallID] = true
+		}
+
+		for i := range calls {
+			if have[calls[i].CallID] {
+				result.Existing++
+				continue
+			}
+			if !dryRun {
+				if err := storage.SaveSellerMetric(ctx, profile.NewSellerMetric(gluserID, &calls[i])); err != nil {
+					return result, fmt.Errorf("failed to save metric for call %s: %w", calls[i].CallID, err)
+				}
+			}
+			result.Added++
+		}
+	}
+
+	log.Printf("📈 Metrics backfill: %d added, %d already present (%d sellers)", result.Added, result.Existing, result.Sellers)
+	return result, nil
+}
+
+// loadAllAnalyses reads every analysis - MongoDB first, local fallback
+func loadAllAnalyses(ctx context.Context) ([]client.AnalysisResult, error) {
+	if storage.IsMongoEnabled() {
+		return storage.GetAllAnalysesFromMongo(ctx)
+	}
+
+	files, err := storage.ListAnalysisFiles()
+	if err != nil {
+		return nil, err
+	}
+	analyses := make([]client.AnalysisResult, 0, len(files))
+	for _, f := range files {
+		if ar, err := readAnalysisFile(f); err == nil {
+			analyses = append(analyses, *ar)
+		}
+	}
+	return analyses, nil
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"log"
+	"math"
+	"sort"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/aggregate"
+	"im-ai-voice/internal/config"
+)
+
+// ==================== TOP MOVERS ====================
+// What leadership looks for first each morning: what changed since the day
+// before. Buckets are compared with the previous day's saved aggregate,
+// sellers by their profile updates on the day (like the processing metrics,
+// what was processed that day) and agents by the day leaderboard, which
+// already ranks the day before.
+
+// topMovers computes an aggregate's top movers. Sections that can't be
+// computed are left empty and logged, they don't fail the aggregation.
+func (s *Service) topMovers(ctx context.Context, agg *client.DailyAggregate) *client.TopMovers {
+	day, err := config.ParseBusinessDate(agg.Date)
+	if err != nil {
+		return nil
+	}
+	previous := day.AddDate(0, 0, -1)
+	m := &client.TopMovers{
+		PreviousDate: config.BusinessDate(previous),
+		Buckets:      []client.BucketMover{},
+		Sellers:      []client.SellerMover{},
+		Agents:       []client.AgentMover{},
+	}
+
+	if prev, err := s.GetDailyAggregate(ctx, m.PreviousDate); err == nil && prev != nil {
+		m.Buckets = bucketMovers(agg, prev)
+	}
+
+	if sellers, err := sellerMovers(ctx, agg.Date); err != nil {
+		log.Printf("⚠️ Top movers of %s without sellers: %v", agg.Date, err)
+	} else {
+		m.Sellers = sellers
+	}
+
+	board, err := aggregate.BuildAgentLeaderboard(ctx, client.PeriodDay, day, aggregate.DefaultLeaderboardMinCalls)
+	if err != nil {
+		log.Printf("⚠️ Top movers of %s without agents: %v", agg.Date, err)
+		return m
+	}
+	for _, a := range board.Agents {
+		if a.PreviousScore == nil || a.Score == *a.PreviousScore {
+			continue
+		}
+		m.Agents = append(m.Agents, client.AgentMover{
+			AgentID:       a.AgentID,
+			Score:         a.Score,
+			PreviousScore: *a.PreviousScore,
+			Change:        math.Round((a.Score-*a.PreviousScore)*10) / 10,
+			Calls:         a.Calls,
+		})
+	}
+	sort.SliceStable(m.Agents, func(i, j int) bool {
+		return math.Abs(m.Agents[i].Change) > math.Abs(m.Agents[j].Change)
+	})
+	m.Agents = m.Agents[:min(len(m.Agents), config.TOP_MOVERS_LIMIT)]
+	return m
+}
+
+// bucketMovers lists the buckets whose issue count rose since prev, by the
+// rise, then by the relative rise
+func bucketMovers(agg, prev *client.DailyAggregate) []client.BucketMover {
+	movers := []client.BucketMover{}
+	for bucket, summary := range agg.FeatureBuckets {
+		before := prev.FeatureBuckets[bucket].TotalCount
+		if summary.TotalCount <= before {
+			continue
+		}
+		mover := client.BucketMover{
+			Bucket:        bucket,
+			Count:         summary.TotalCount,
+			PreviousCount: before,
+			Change:        summary.TotalCount - before,
+		}
+		if before > 0 {
+			pct := math.Round(float64(mover.Change)/float64(before)*1000) / 10
+			mover.ChangePct = &pct
+		}
+		movers = append(movers, mover)
+	}
+	sort.Slice(movers, func(i, j int) bool {
+		a, b := movers[i], movers[j]
+		if a.Change != b.Change {
+			return a.Change > b.Change
+		}
+		// A bucket new on the day rose the most relative to before
+		if (a.ChangePct == nil) != (b.ChangePct == nil) {
+			return a.ChangePct == nil
+		}
+		if a.ChangePct != nil && *a.ChangePct != *b.ChangePct {
+			return *a.ChangePct > *b.ChangePct
+		}
+		return a.Bucket < b.Bucket
+	})
+	return movers[:min(len(movers), config.TOP_MOVERS_LIMIT)]
+}
+
+// sellerMovers lists the sellers whose health score fell most over the
+// profile updates of a day. New profiles start without a score, so their
+// first update isn't a change.
+func sellerMovers(ctx context.Context, date string) ([]client.SellerMover, error) {
+	start, err := config.ParseBusinessDate(date)
+	if err != nil {
+		return nil, err
+	}
+	movers := make(map[string]*client.SellerMover)
+	err = dayEvents(ctx, client.EventProfileUpdated, start, start.AddDate(0, 0, 1), func(e client.Event) {
+		var p client.ProfileUpdatedPayload
+		if e.GluserID == "" || !decodePayload(e, &p) {
+			return
+		}
+		m := movers[e.GluserID]
+		if m == nil {
+			if p.TotalCalls <= 1 {
+				return
+			}
+			m = &client.SellerMover{GluserID: e.GluserID, PreviousHealthScore: p.PreviousHealthScore}
+			movers[e.GluserID] = m
+		}
+		m.HealthScore = p.HealthScore
+		m.Calls++
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	out := []client.SellerMover{}
+	for _, m := range movers {
+		if m.Change = m.HealthScore - m.PreviousHealthScore; m.Change < 0 {
+			out = append(out, *m)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Change != out[j].Change {
+			return out[i].Change < out[j].Change
+		}
+		return out[i].GluserID < out[j].GluserID
+	})
+	return out[:min(len(out), config.TOP_MOVERS_LIMIT)], nil
+}
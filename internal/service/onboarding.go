@@ -0,0 +1,25 @@
+package service
+
+import (
+	"slices"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+)
+
+// onboardingTag marks the analysis of a call from a seller in their first
+// ONBOARDING_VINTAGE_MONTHS, noting the setup-related buckets it raised.
+// Older sellers' calls get nil.
+func onboardingTag(ar *client.AnalysisResult, vintageMonths int) *client.OnboardingTag {
+	if vintageMonths < 0 || vintageMonths >= config.ONBOARDING_VINTAGE_MONTHS {
+		return nil
+	}
+	tag := &client.OnboardingTag{VintageMonths: vintageMonths}
+	for _, issue := range ar.Issues {
+		if slices.Contains(config.OnboardingBuckets, issue.Bucket) && !slices.Contains(tag.SetupBuckets, issue.Bucket) {
+			tag.SetupBuckets = append(tag.SetupBuckets, issue.Bucket)
+		}
+	}
+	tag.Setup = len(tag.SetupBuckets) > 0
+	return tag
+}
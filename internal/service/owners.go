@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/mail"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/notify"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== BUCKET OWNERS ====================
+
+var (
+	ErrInvalidBucketOwner  = errors.New("invalid bucket owner")
+	ErrBucketOwnerNotFound = errors.New("bucket has no owner")
+)
+
+// ListBucketOwners returns the owners of the feature buckets that have one
+func (s *Service) ListBucketOwners(ctx context.Context) ([]client.BucketOwner, error) {
+	return storage.LoadBucketOwners(ctx)
+}
+
+// SetBucketOwner makes a team the owner of a feature bucket, replacing its
+// previous owner. Tickets pick it up from the next aggregation on.
+func (s *Service) SetBucketOwner(ctx context.Context, bucket string, in client.BucketOwnerRequest) (*client.BucketOwner, error) {
+	if !slices.Contains(config.FeatureBuckets, bucket) {
+		return nil, fmt.Errorf("%w: unknown bucket %q", ErrInvalidBucketOwner, bucket)
+	}
+	in.Team = strings.TrimSpace(in.Team)
+	in.SlackChannel = strings.TrimSpace(in.SlackChannel)
+	in.WebhookURL = strings.TrimSpace(in.WebhookURL)
+	if in.Team == "" {
+		return nil, fmt.Errorf("%w: team is required", ErrInvalidBucketOwner)
+	}
+	emails := make([]string, 0, len(in.Emails))
+	for _, e := range in.Emails {
+		addr, err := mail.ParseAddress(strings.TrimSpace(e))
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid email %q", ErrInvalidBucketOwner, e)
+		}
+		emails = append(emails, addr.Address)
+	}
+	if in.WebhookURL != "" {
+		u, err := url.Parse(in.WebhookURL)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return nil, fmt.Errorf("%w: webhook_url must be an http(s) URL", ErrInvalidBucketOwner)
+		}
+	}
+
+	owner := &client.BucketOwner{
+		Bucket:       bucket,
+		Team:         in.Team,
+		Emails:       emails,
+		SlackChannel: in.SlackChannel,
+		WebhookURL:   in.WebhookURL,
+		By:           in.By,
+		UpdatedAt:    time.Now(),
+	}
+	if err := storage.SaveBucketOwner(ctx, owner); err != nil {
+		return nil, err
+	}
+	log.Printf("👥 %s now owns %q (set by %s)", owner.Team, bucket, orAnonymous(owner.By))
+	return owner, nil
+}
+
+// DeleteBucketOwner leaves a feature bucket without an owner
+func (s *Service) DeleteBucketOwner(ctx context.Context, bucket string) error {
+	found, err := storage.DeleteBucketOwner(ctx, bucket)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("%w: %s", ErrBucketOwnerNotFound, bucket)
+	}
+	log.Printf("👥 %q no longer has an owner", bucket)
+	return nil
+}
+
+// bucketOwners maps each owned bucket to its owner. Routing is best effort,
+// so a failed load leaves tickets unowned rather than failing aggregation.
+func bucketOwners(ctx context.Context) map[string]*client.BucketOwner {
+	owners, err := storage.LoadBucketOwners(ctx)
+	if err != nil {
+		log.Printf("⚠️ Failed to load bucket owners: %v", err)
+		return nil
+	}
+	byBucket := make(map[string]*client.BucketOwner, len(owners))
+	for i := range owners {
+		byBucket[owners[i].Bucket] = &owners[i]
+	}
+	return byBucket
+}
+
+// attachOwners sets each ticket's owner from its bucket's
+func attachOwners(tickets []client.Ticket, owners map[string]*client.BucketOwner) {
+	for i := range tickets {
+		tickets[i].Owner = nil
+		if o := owners[tickets[i].FeatureBucket]; o != nil {
+			tickets[i].Owner = &client.TicketOwner{
+				Team:         o.Team,
+				Emails:       o.Emails,
+				SlackChannel: o.SlackChannel,
+			}
+		}
+	}
+}
+
+func ticketOwnerTeam(t client.Ticket) string {
+	if t.Owner == nil {
+		return ""
+	}
+	return t.Owner.Team
+}
+
+// routeNewTickets notifies each bucket's owner of its tickets created since
+// start; tickets carried over from an earlier aggregation were sent then
+func routeNewTickets(ctx context.Context, tickets []client.Ticket, start time.Time) {
+	var owners map[string]*client.BucketOwner
+	loaded := false
+	for _, t := range tickets {
+		if t.CreatedAt.Before(start) {
+			continue
+		}
+		if !loaded {
+			owners, loaded = bucketOwners(ctx), true
+		}
+		notify.NotifyTicket(ctx, t, owners[t.FeatureBucket])
+	}
+}
@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/aggregate"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== UPSELL PITCHES ====================
+// Upsells agents pitch on calls are stored with the seller's customer type
+// at the time. A later call showing the seller on a higher tier (free,
+// catalog, Star, Leader) within UPSELL_CONVERSION_DAYS converts the pitch;
+// the leader marks the rest lapsed once the window has passed. Conversion
+// rates per agent, seller response and product show which pitches work.
+
+const (
+	pitchesDefaultLimit = 100
+	pitchesMaxLimit     = 1000
+)
+
+// upsellConversionDays returns UPSELL_CONVERSION_DAYS, or the default when unset or invalid
+func upsellConversionDays() int {
+	if v := os.Getenv("UPSELL_CONVERSION_DAYS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return n
+		}
+		log.Printf("⚠️ Invalid UPSELL_CONVERSION_DAYS=%q, using %d", v, config.DEFAULT_UPSELL_CONVERSION_DAYS)
+	}
+	return config.DEFAULT_UPSELL_CONVERSION_DAYS
+}
+
+// trackUpsellPitches converts the seller's earlier pitches the call shows
+// an upgrade for, then stores the one made on it. customerType is the
+// seller's on the call. Failures are logged, they don't fail the analysis.
+func (s *Service) trackUpsellPitches(ctx context.Context, analysis *client.AnalysisResult, customerType string) {
+	if analysis.SellerID == "" {
+		return
+	}
+
+	open, err := storage.LoadPitches(ctx, storage.PitchQuery{GluserID: analysis.SellerID, Status: client.PitchOpen})
+	if err != nil {
+		log.Printf("   ⚠️ Failed to load upsell pitches for %s: %v", analysis.SellerID, err)
+		return
+	}
+	window := time.Duration(upsellConversionDays()) * 24 * time.Hour
+	for i := range open {
+		p := &open[i]
+		if p.CallID == analysis.CallID || !p.CallTime.Before(analysis.Timestamp) ||
+			analysis.Timestamp.Sub(p.CallTime) > window || !aggregate.TierUpgrade(p.CustomerType, customerType) {
+			continue
+		}
+		convertedAt := analysis.Timestamp
+		p.Status, p.SettledAt, p.ConvertedCallID, p.ConvertedTo = client.PitchConverted, &convertedAt, analysis.CallID, customerType
+		if err := storage.SavePitch(ctx, p); err != nil {
+			log.Printf("   ⚠️ Failed to save upsell pitch %s: %v", p.ID, err)
+			continue
+		}
+		log.Printf("   💰 Call %s: upsell pitch %s converted (%s → %s)", analysis.CallID, p.ID, p.CustomerType, customerType)
+	}
+
+	pitch := analysis.UpsellPitch
+	if pitch == nil {
+		return
+	}
+	p := client.TrackedPitch{
+		ID:           analysis.CallID,
+		GluserID:     analysis.SellerID,
+		AgentID:      analysis.AgentID,
+		CallID:       analysis.CallID,
+		CallTime:     analysis.Timestamp,
+		Products:     pitch.Products,
+		SKUs:         pitch.SKUs,
+		Response:     pitch.Response,
+		Quote:        pitch.Quote,
+		CustomerType: customerType,
+		Tier:         aggregate.CustomerTier(customerType),
+		Status:       client.PitchOpen,
+		CreatedAt:    time.Now(),
+	}
+	// A re-analyzed call keeps what later calls found
+	if existing, err := storage.LoadPitch(ctx, p.ID); err == nil && existing != nil {
+		p.Status, p.SettledAt, p.ConvertedCallID, p.ConvertedTo = existing.Status, existing.SettledAt, existing.ConvertedCallID, existing.ConvertedTo
+		p.CustomerType, p.Tier = existing.CustomerType, existing.Tier
+		p.CreatedAt = existing.CreatedAt
+	}
+	if err := storage.SavePitch(ctx, &p); err != nil {
+		log.Printf("   ⚠️ Failed to save upsell pitch %s: %v", p.ID, err)
+		return
+	}
+	log.Printf("   💰 Call %s: upsell pitch to %s (%s)", analysis.CallID, analysis.SellerID, p.Response)
+}
+
+// RunPitchExpiry marks open upsell pitches past the conversion window
+// lapsed, returning how many
+func RunPitchExpiry(ctx context.Context) (int, error) {
+	open, err := storage.LoadPitches(ctx, storage.PitchQuery{Status: client.PitchOpen})
+	if err != nil {
+		return 0, fmt.Errorf("failed to load upsell pitches: %w", err)
+	}
+
+	now := time.Now()
+	cutoff := now.AddDate(0, 0, -upsellConversionDays())
+	lapsed := 0
+	for i := range open {
+		if ctx.Err() != nil {
+			return lapsed, ctx.Err()
+		}
+		p := &open[i]
+		if !p.CallTime.Before(cutoff) {
+			continue
+		}
+		p.Status, p.SettledAt = client.PitchLapsed, &now
+		if err := storage.SavePitch(ctx, p); err != nil {
+			log.Printf("⚠️ Failed to save lapsed upsell pitch %s: %v", p.ID, err)
+			continue
+		}
+		lapsed++
+	}
+
+	if lapsed > 0 {
+		log.Printf("💰 Marked %d upsell pitches past the conversion window lapsed", lapsed)
+	}
+	return lapsed, nil
+}
+
+// ListPitches returns the newest upsell pitches matching q with conversion
+// counts per agent, seller response and product over all of them
+func (s *Service) ListPitches(ctx context.Context, q storage.PitchQuery, limit int) (*client.UpsellPitchReport, error) {
+	if limit <= 0 {
+		limit = pitchesDefaultLimit
+	}
+	if limit > pitchesMaxLimit {
+		limit = pitchesMaxLimit
+	}
+
+	all, err := storage.LoadPitches(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &client.UpsellPitchReport{Pitches: []client.TrackedPitch{}, Total: len(all), GeneratedAt: time.Now()}
+	agents := newPitchTallies(func(key string) client.PitchTally { return client.PitchTally{AgentID: key} })
+	responses := newPitchTallies(func(key string) client.PitchTally { return client.PitchTally{Response: key} })
+	skus := newPitchTallies(func(key string) client.PitchTally { return client.PitchTally{SKU: key} })
+	for _, p := range all {
+		agent := p.AgentID
+		if agent == "" {
+			agent = "unknown" // Watcher transcripts don't name the agent
+		}
+		agents.add(agent, p)
+		responses.add(p.Response, p)
+		if len(p.SKUs) == 0 {
+			skus.add("unmapped", p)
+		}
+		for _, sku := range p.SKUs {
+			skus.add(sku, p)
+		}
+	}
+	report.ByAgent = agents.sorted()
+	report.ByResponse = responses.sorted()
+	report.BySKU = skus.sorted()
+
+	if len(all) > limit {
+		all = all[:limit]
+	}
+	report.Pitches = append(report.Pitches, all...)
+	report.Count = len(report.Pitches)
+	return report, nil
+}
+
+// pitchTallies counts pitches by a key, with the days each took to convert
+type pitchTallies struct {
+	blank   func(key string) client.PitchTally
+	tallies map[string]*client.PitchTally
+	days    map[string][]float64
+}
+
+func newPitchTallies(blank func(key string) client.PitchTally) *pitchTallies {
+	return &pitchTallies{blank: blank, tallies: make(map[string]*client.PitchTally), days: make(map[string][]float64)}
+}
+
+func (pt *pitchTallies) add(key string, p client.TrackedPitch) {
+	t := pt.tallies[key]
+	if t == nil {
+		blank := pt.blank(key)
+		t = &blank
+		pt.tallies[key] = t
+	}
+	t.Pitches++
+	switch p.Status {
+	case client.PitchConverted:
+		t.Converted++
+		if p.SettledAt != nil {
+			pt.days[key] = append(pt.days[key], p.SettledAt.Sub(p.CallTime).Hours()/24)
+		}
+	case client.PitchLapsed:
+		t.Lapsed++
+	default:
+		t.Open++
+	}
+	if settled := t.Converted + t.Lapsed; settled > 0 {
+		t.ConversionRate = float64(t.Converted) / float64(settled)
+	}
+}
+
+// sorted orders the tallies by conversions, then conversion rate, with
+// each one's median days to convert
+func (pt *pitchTallies) sorted() []client.PitchTally {
+	tallies := make([]client.PitchTally, 0, len(pt.tallies))
+	for key, t := range pt.tallies {
+		if days := pt.days[key]; len(days) > 0 {
+			sort.Float64s(days)
+			median := days[len(days)/2]
+			if len(days)%2 == 0 {
+				median = (days[len(days)/2-1] + days[len(days)/2]) / 2
+			}
+			t.MedianDaysToConvert = math.Round(median*10) / 10
+		}
+		tallies = append(tallies, *t)
+	}
+	sort.Slice(tallies, func(i, j int) bool {
+		a, b := tallies[i], tallies[j]
+		if a.Converted != b.Converted {
+			return a.Converted > b.Converted
+		}
+		if a.ConversionRate != b.ConversionRate {
+			return a.ConversionRate > b.ConversionRate
+		}
+		return a.AgentID+a.Response+a.SKU < b.AgentID+b.Response+b.SKU
+	})
+	return tallies
+}
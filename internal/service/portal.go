@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== SELLER PORTAL ====================
+// IndiaMART's seller-facing portal shows sellers the status of their own
+// support cases. The summary is built from the profile's tracked issues and
+// the commitments made on their calls, copying only fields meant for the
+// seller: no scores, severities, suggested actions or agent details.
+
+// SellerCaseSummary returns the seller-safe view of a seller's cases
+func (s *Service) SellerCaseSummary(ctx context.Context, gluserID string) (*client.SellerCaseSummary, error) {
+	p, err := storage.LoadSellerProfile(ctx, gluserID)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, fmt.Errorf("%w: %s", ErrSellerNotFound, gluserID)
+	}
+	commitments, err := storage.LoadCommitments(ctx, storage.CommitmentQuery{GluserID: gluserID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load commitments: %w", err)
+	}
+
+	now := time.Now()
+	summary := &client.SellerCaseSummary{
+		GluserID:         gluserID,
+		OpenCases:        []client.SellerCase{},
+		RecentlyResolved: []client.SellerCase{},
+		FollowUps:        []client.SellerFollowUp{},
+		GeneratedAt:      now,
+	}
+	if !p.LastCallAt.IsZero() {
+		last := p.LastCallAt
+		summary.LastContactAt = &last
+	}
+
+	for _, issue := range p.ActiveIssues {
+		summary.OpenCases = append(summary.OpenCases, sellerCase(issue))
+	}
+	sort.SliceStable(summary.OpenCases, func(i, j int) bool {
+		return summary.OpenCases[i].UpdatedAt.After(summary.OpenCases[j].UpdatedAt)
+	})
+
+	since := now.AddDate(0, 0, -config.PORTAL_RESOLVED_DAYS)
+	for _, issue := range p.ResolvedIssues {
+		if issue.ResolvedAt != nil && issue.ResolvedAt.After(since) {
+			summary.RecentlyResolved = append(summary.RecentlyResolved, sellerCase(issue))
+		}
+	}
+	sort.SliceStable(summary.RecentlyResolved, func(i, j int) bool {
+		return summary.RecentlyResolved[i].ResolvedAt.After(*summary.RecentlyResolved[j].ResolvedAt)
+	})
+
+	// Open promises first, each group newest call first as loaded
+	sort.SliceStable(commitments, func(i, j int) bool {
+		return commitments[i].Status == client.CommitmentOpen && commitments[j].Status != client.CommitmentOpen
+	})
+	for _, c := range commitments {
+		if len(summary.FollowUps) == config.PORTAL_FOLLOW_UPS {
+			break
+		}
+		summary.FollowUps = append(summary.FollowUps, client.SellerFollowUp{
+			Promise:    c.Promise,
+			PromisedAt: c.CallTime,
+			DueDate:    c.DueDate,
+			Status:     c.Status,
+		})
+	}
+	return summary, nil
+}
+
+// sellerCase copies the seller-facing fields of a tracked issue
+func sellerCase(issue client.TrackedIssue) client.SellerCase {
+	c := client.SellerCase{
+		CaseID:     issue.IssueID,
+		Problem:    issue.Problem,
+		Category:   issue.Bucket,
+		Status:     issue.Status,
+		ReportedAt: issue.FirstReportedAt,
+		UpdatedAt:  issue.LastMentionedAt,
+		ResolvedAt: issue.ResolvedAt,
+	}
+	var latest *client.SourceTicket
+	for i := range issue.SourceTickets {
+		if latest == nil || issue.SourceTickets[i].UpdatedAt.After(latest.UpdatedAt) {
+			latest = &issue.SourceTickets[i]
+		}
+	}
+	if latest != nil {
+		c.TicketID = latest.TicketID
+	}
+	return c
+}
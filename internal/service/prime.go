@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== CACHE PRIMING ====================
+// After a restart the first dashboard load would read the aggregate, the
+// tickets and every seller profile cold. Priming reads them before the
+// instance reports ready: the latest aggregate (today's once it's built),
+// the tickets of the latest date with tickets, and the profiles of the
+// CACHE_PRIME_SELLERS sellers called most recently. Priming is best effort:
+// what fails is logged and read on first use instead.
+
+// CachePrimeEnabled reports whether CACHE_PRIME leaves priming on
+func CachePrimeEnabled() bool {
+	on := config.DEFAULT_CACHE_PRIME
+	if v := os.Getenv("CACHE_PRIME"); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			on = b
+		} else {
+			log.Printf("⚠️ Invalid CACHE_PRIME=%q, using %t", v, on)
+		}
+	}
+	return on
+}
+
+// PrimeCache preloads the dashboard's data, giving up after
+// CACHE_PRIME_TIMEOUT
+func (s *Service) PrimeCache(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, config.EnvDuration("CACHE_PRIME_TIMEOUT", config.DEFAULT_CACHE_PRIME_TIMEOUT))
+	defer cancel()
+	start := time.Now()
+
+	aggDate, err := storage.LatestAggregateDate(ctx)
+	if err != nil {
+		log.Printf("⚠️ Cache priming: failed to list aggregates: %v", err)
+	} else if aggDate != "" {
+		if _, err := storage.CachedAggregate(ctx, aggDate); err != nil {
+			log.Printf("⚠️ Cache priming: failed to load the %s aggregate: %v", aggDate, err)
+		}
+	}
+
+	var open int
+	ticketDate, err := storage.LatestTicketDate(ctx)
+	if err != nil {
+		log.Printf("⚠️ Cache priming: failed to list ticket dates: %v", err)
+	} else if ticketDate != "" {
+		tickets, err := storage.CachedTickets(ctx, ticketDate)
+		if err != nil {
+			log.Printf("⚠️ Cache priming: failed to load the %s tickets: %v", ticketDate, err)
+		}
+		for _, t := range tickets {
+			if t.Status != client.TicketResolved {
+				open++
+			}
+		}
+	}
+
+	sellers := config.DEFAULT_CACHE_PRIME_SELLERS
+	if v := os.Getenv("CACHE_PRIME_SELLERS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			sellers = n
+		} else {
+			log.Printf("⚠️ Invalid CACHE_PRIME_SELLERS=%q, using %d", v, sellers)
+		}
+	}
+	profiles, err := storage.WarmSellerProfiles(ctx, sellers)
+	if err != nil {
+		log.Printf("⚠️ Cache priming: failed to load recent seller profiles: %v", err)
+	}
+
+	log.Printf("🔥 Cache primed in %s: aggregate %s, %d open tickets of %s, %d seller profiles",
+		time.Since(start).Round(time.Millisecond), orNone(aggDate), open, orNone(ticketDate), profiles)
+}
+
+func orNone(date string) string {
+	if date == "" {
+		return "none"
+	}
+	return date
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"strconv"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== PROCESSING METRICS ====================
+// Next to the business metrics, each aggregate says how complete its day's
+// data is: how many analyses were attempted and failed, how Gemini did and
+// what it cost. They're read from the day's analyzed and analysis_failed
+// events, so they cover what the pipeline processed that day.
+
+// processingMetrics returns the pipeline metrics of the business day date,
+// or nil when its events can't be read
+func processingMetrics(ctx context.Context, date string) *client.ProcessingMetrics {
+	start, err := config.ParseBusinessDate(date)
+	if err != nil {
+		return nil
+	}
+	end := start.AddDate(0, 0, 1)
+
+	m := &client.ProcessingMetrics{}
+	attempted := make(map[string]bool)
+	succeeded := make(map[string]bool)
+	quarantined := make(map[string]bool)
+	provisional := make(map[string]bool)
+	metered := 0
+	latency := 0
+
+	err = dayEvents(ctx, client.EventAnalyzed, start, end, func(e client.Event) {
+		var p client.AnalyzedPayload
+		if !decodePayload(e, &p) {
+			return
+		}
+		attempted[e.CallID] = true
+		succeeded[e.CallID] = true
+		if p.ParseFailed {
+			m.ParseFailures++
+		}
+		if p.Provisional {
+			provisional[e.CallID] = true
+		}
+		if u := p.LLMUsage; u != nil {
+			metered++
+			latency += u.LatencyMS
+			m.LLMRequests += u.Requests
+			m.PromptTokens += u.PromptTokens
+			m.OutputTokens += u.OutputTokens
+		}
+	})
+	if err == nil {
+		err = dayEvents(ctx, client.EventAnalysisFailed, start, end, func(e client.Event) {
+			var p client.AnalysisFailedPayload
+			if !decodePayload(e, &p) {
+				return
+			}
+			// Files that couldn't be read or parsed have no call ID yet
+			key := e.CallID
+			if key == "" {
+				key = "file:" + p.File
+			}
+			attempted[key] = true
+			if p.Quarantined {
+				quarantined[key] = true
+			}
+		})
+	}
+	if err != nil {
+		log.Printf("⚠️ Failed to read processing events for %s: %v", date, err)
+		return nil
+	}
+
+	m.Attempted = len(attempted)
+	m.Succeeded = len(succeeded)
+	m.Failed = m.Attempted - m.Succeeded
+	m.Quarantined = len(quarantined)
+	m.Provisional = len(provisional)
+	if metered > 0 {
+		m.AvgLLMLatencyMS = latency / metered
+	}
+	m.CostUSD = math.Round(LLMCostUSD(m.PromptTokens, m.OutputTokens)*10000) / 10000
+	return m
+}
+
+// LLMCostUSD estimates the cost of Gemini tokens at GEMINI_INPUT_PRICE and GEMINI_OUTPUT_PRICE
+func LLMCostUSD(promptTokens, outputTokens int) float64 {
+	return float64(promptTokens)*geminiPrice("GEMINI_INPUT_PRICE", config.DEFAULT_GEMINI_INPUT_PRICE)/1e6 +
+		float64(outputTokens)*geminiPrice("GEMINI_OUTPUT_PRICE", config.DEFAULT_GEMINI_OUTPUT_PRICE)/1e6
+}
+
+// dayEvents calls fn for each event of type typ from start up to end
+func dayEvents(ctx context.Context, typ string, start, end time.Time, fn func(client.Event)) error {
+	q := storage.EventQuery{Type: typ, Since: start, Limit: 1000}
+	for {
+		page, err := storage.QueryEvents(ctx, q)
+		if err != nil {
+			return fmt.Errorf("failed to read %s events: %w", typ, err)
+		}
+		for _, e := range page.Events {
+			if !e.Timestamp.Before(end) {
+				return nil // Events come in time order
+			}
+			fn(e)
+		}
+		if !page.HasMore {
+			return nil
+		}
+		q.Cursor = page.NextCursor
+	}
+}
+
+// decodePayload decodes an event's payload into v, which is a map once read back from storage
+func decodePayload(e client.Event, v interface{}) bool {
+	b, err := json.Marshal(e.Payload)
+	return err == nil && json.Unmarshal(b, v) == nil
+}
+
+// geminiPrice returns a USD per million tokens price from env, or the default when unset or invalid
+func geminiPrice(key string, def float64) float64 {
+	if v := os.Getenv(key); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil && f >= 0 {
+			return f
+		}
+		log.Printf("⚠️ Invalid %s=%q, using %g", key, v, def)
+	}
+	return def
+}
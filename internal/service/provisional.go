@@ -0,0 +1,327 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/llm"
+	"im-ai-voice/internal/profile"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== DEGRADED MODE ====================
+// When Gemini is down, out of quota or not answering within the analysis
+// deadline, calls aren't failed: they get a provisional analysis from the
+// keyword classifier (see internal/classifier), flagged provisional, and are
+// queued for the full one. Provisional analyses count in aggregates but
+// leave seller profiles alone, so nothing has to be undone; the upgrade
+// puts the full analysis through the profile as the call would have gone.
+// Once a call found Gemini unavailable, calls skip it for
+// DEGRADED_PROBE_INTERVAL, then the next one tries again. The
+// provisional_upgrade job upgrades queued calls oldest first and stops at
+// the first one Gemini is still unavailable for. DEGRADED_MODE=false fails
+// calls as before.
+
+// degradedMode tracks whether this instance is analyzing provisionally
+type degradedMode struct {
+	enabled       bool
+	probeInterval time.Duration
+
+	mu          sync.Mutex
+	since       time.Time // Zero while Gemini answers
+	reason      string    // Of the failure that started it
+	lastFailure time.Time
+}
+
+// degradedModeFromEnv reads DEGRADED_MODE and DEGRADED_PROBE_INTERVAL
+func degradedModeFromEnv() *degradedMode {
+	d := &degradedMode{
+		enabled:       config.DEFAULT_DEGRADED_MODE,
+		probeInterval: config.EnvDuration("DEGRADED_PROBE_INTERVAL", config.DEFAULT_DEGRADED_PROBE_INTERVAL),
+	}
+	if v := os.Getenv("DEGRADED_MODE"); v != "" {
+		if on, err := strconv.ParseBool(v); err == nil {
+			d.enabled = on
+		} else {
+			log.Printf("⚠️ Invalid DEGRADED_MODE=%q, using %t", v, d.enabled)
+		}
+	}
+	return d
+}
+
+// skip reports whether a call should go straight to the provisional
+// analysis, Gemini having failed within the probe interval
+func (d *degradedMode) skip(now time.Time) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.enabled && !d.since.IsZero() && now.Sub(d.lastFailure) < d.probeInterval
+}
+
+// fail records Gemini being unavailable for reason
+func (d *degradedMode) fail(reason string, now time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.since.IsZero() {
+		d.since, d.reason = now, reason
+		log.Printf("🪫 Gemini %s, analyzing calls provisionally until it's back", reason)
+	}
+	d.lastFailure = now
+}
+
+// recover records Gemini answering again
+func (d *degradedMode) recover() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.since.IsZero() {
+		log.Printf("🔋 Gemini is back after %s, provisional analyses will be upgraded", time.Since(d.since).Round(time.Second))
+		d.since, d.reason = time.Time{}, ""
+	}
+}
+
+// LLMCheckFailed puts the instance in degraded mode after Gemini failed its
+// startup check, so calls are analyzed provisionally rather than waiting on
+// it. It reports false, calls failing instead, with DEGRADED_MODE=false.
+func (s *Service) LLMCheckFailed() bool {
+	if !s.degraded.enabled {
+		return false
+	}
+	s.degraded.fail(client.ProvisionalUnavailable, time.Now())
+	return true
+}
+
+// LLMCheckPassed ends the degraded mode a failed startup check started
+func (s *Service) LLMCheckPassed() {
+	s.degraded.recover()
+}
+
+// llmOutage returns the provisional reason for an analysis error that means
+// Gemini is unavailable, "" for other failures. ctx is the caller's, so the
+// analysis deadline passing counts and the caller going away doesn't.
+func llmOutage(ctx context.Context, err error) string {
+	switch {
+	case errors.Is(err, llm.ErrQuotaExceeded):
+		return client.ProvisionalQuota
+	case errors.Is(err, llm.ErrUnavailable):
+		return client.ProvisionalUnavailable
+	case errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil:
+		return client.ProvisionalTimeout
+	}
+	return ""
+}
+
+// analyzeOrProvisional analyzes a call, falling back to a provisional
+// analysis while Gemini is unavailable
+func (s *Service) analyzeOrProvisional(ctx context.Context, rt client.RawTranscript, sellerContext string) (*client.AnalysisResult, error) {
+	if s.degraded.skip(time.Now()) {
+		return s.provisionalAnalysis(rt, client.ProvisionalDegraded), nil
+	}
+	analysis, err := s.analyzeCall(ctx, rt, sellerContext)
+	if err == nil {
+		s.degraded.recover()
+		return analysis, nil
+	}
+	reason := llmOutage(ctx, err)
+	if reason == "" || !s.degraded.enabled {
+		return nil, err
+	}
+	s.degraded.fail(reason, time.Now())
+	return s.provisionalAnalysis(rt, reason), nil
+}
+
+// provisionalReasons describe the reasons in call summaries
+var provisionalReasons = map[string]string{
+	client.ProvisionalUnavailable: "unavailable",
+	client.ProvisionalQuota:       "quota exceeded",
+	client.ProvisionalTimeout:     "timed out",
+	client.ProvisionalDegraded:    "unavailable",
+}
+
+// provisionalAnalysis is the classifier's analysis of rt, flagged
+// provisional with the reason in LLMRaw
+func (s *Service) provisionalAnalysis(rt client.RawTranscript, reason string) *client.AnalysisResult {
+	a := s.rules.Classify(rt)
+	a.AgentID = rt.AgentID
+	a.Provisional = true
+	a.LLMRaw = map[string]interface{}{"provisional": reason}
+	a.CallSummary = fmt.Sprintf("Provisional analysis, Gemini %s. %s", provisionalReasons[reason], a.CallSummary)
+	return a
+}
+
+// saveProvisional queues a provisionally analyzed call for its upgrade and
+// stores the analysis. ht is nil for API transcripts without a seller.
+func (s *Service) saveProvisional(ctx context.Context, analysis *client.AnalysisResult, rt client.RawTranscript, ht *client.HackathonTranscript) error {
+	reason, _ := analysis.LLMRaw["provisional"].(string)
+	pc := &client.ProvisionalCall{CallID: analysis.CallID, GluserID: rt.SellerID, Reason: reason, QueuedAt: time.Now()}
+	if ht != nil {
+		tokenized := *ht
+		tokenized.Transcript = rt.Transcript
+		pc.Transcript, pc.Checksum = &tokenized, analysis.Checksum
+	}
+	if err := storage.SaveProvisionalCall(ctx, pc); err != nil {
+		return fmt.Errorf("failed to queue provisional analysis: %w", err)
+	}
+
+	storage.RecordAnalyzedEvent(ctx, analysis)
+	var err error
+	if ht != nil {
+		err = storage.SaveAnalysisWithGluserID(ctx, *analysis, ht.GluserID, ht.ClickToCallID)
+	} else {
+		err = storage.SaveAnalysis(ctx, *analysis)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save provisional analysis: %w", err)
+	}
+	log.Printf("   🪫 Call %s analyzed provisionally (%s), queued for the full analysis", analysis.CallID, reason)
+	return nil
+}
+
+// UpgradeProvisional replaces provisional analyses with full ones, oldest
+// first, stopping at the first call Gemini is still unavailable for
+func (s *Service) UpgradeProvisional(ctx context.Context) (*client.ProvisionalUpgrade, error) {
+	if s.ai == nil {
+		return nil, fmt.Errorf("AI client not configured")
+	}
+	calls, err := storage.LoadProvisionalCalls(ctx, config.PROVISIONAL_UPGRADE_BATCH)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load provisional calls: %w", err)
+	}
+
+	result := &client.ProvisionalUpgrade{}
+	for i := range calls {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		pc := &calls[i]
+		err := s.upgradeProvisional(ctx, pc)
+		if err == nil {
+			s.degraded.recover()
+			result.Upgraded++
+			continue
+		}
+
+		now := time.Now()
+		pc.Attempts++
+		pc.LastAttemptAt = &now
+		if reason := llmOutage(ctx, err); reason != "" {
+			s.degraded.fail(reason, now)
+			pc.LastError = reason
+			result.Stopped = "Gemini " + reason
+		} else {
+			pc.LastError = "failed"
+			result.Failed++
+			log.Printf("   ❌ Upgrade of provisional call %s failed: %v", pc.CallID, err)
+		}
+		if result.Stopped == "" && pc.Attempts >= config.PROVISIONAL_MAX_ATTEMPTS {
+			log.Printf("   ⚠️ Giving up on upgrading call %s after %d attempts, a replay can still analyze it", pc.CallID, pc.Attempts)
+			err = storage.DeleteProvisionalCall(ctx, pc.CallID)
+		} else {
+			err = storage.SaveProvisionalCall(ctx, pc)
+		}
+		if err != nil {
+			log.Printf("   ⚠️ Failed to update provisional call %s: %v", pc.CallID, err)
+		}
+		if result.Stopped != "" {
+			break
+		}
+	}
+
+	if result.Remaining, err = storage.CountProvisionalCalls(ctx); err != nil {
+		return result, fmt.Errorf("failed to count provisional calls: %w", err)
+	}
+	if result.Upgraded > 0 || result.Failed > 0 {
+		log.Printf("⬆️ Upgraded %d provisional analyses, %d failed, %d left", result.Upgraded, result.Failed, result.Remaining)
+	}
+	return result, nil
+}
+
+// upgradeProvisional gives a queued call its full analysis, as it would have
+// got without the outage, and takes it off the queue. A call analyzed fully
+// since, by a replay or after its transcript changed, just leaves the queue.
+func (s *Service) upgradeProvisional(ctx context.Context, pc *client.ProvisionalCall) error {
+	prev, err := s.GetCallAnalysis(ctx, pc.CallID)
+	if err == nil && prev != nil && !prev.Provisional {
+		return storage.DeleteProvisionalCall(ctx, pc.CallID)
+	}
+
+	var rt client.RawTranscript
+	ht := pc.Transcript
+	if ht != nil {
+		ts := pc.QueuedAt
+		if prev != nil {
+			ts = prev.Timestamp
+		}
+		rt = hackathonToRawTranscript(ht, callTimestamp(ht, ts))
+	} else {
+		raw, err := storage.LoadRawTranscript(pc.CallID)
+		if err != nil {
+			return fmt.Errorf("failed to load transcript: %w", err)
+		}
+		rt = *raw
+	}
+	if prev != nil {
+		rt.Acoustic = prev.Acoustic
+	}
+	if err := tokenizeTranscript(ctx, &rt); err != nil {
+		return err
+	}
+
+	var sellerContext string
+	var contextUsed *client.SellerContextUsed
+	if ht != nil {
+		sellerContext, contextUsed = profile.BuildSellerContextFromProfile(ctx, ht.GluserID, rt.Origin)
+	}
+	analysis, err := s.analyzeCall(ctx, rt, sellerContext)
+	if err != nil {
+		return err
+	}
+	withSellerContext(analysis, contextUsed)
+	if ht == nil {
+		if err := storage.SaveAnalysis(ctx, *analysis); err != nil {
+			return fmt.Errorf("failed to save analysis: %w", err)
+		}
+		storage.RecordAnalyzedEvent(ctx, analysis)
+	} else {
+		enrichAnalysis(analysis, ht)
+		if pc.Checksum != "" {
+			analysis.Checksum = pc.Checksum
+		}
+		if _, err := s.applyAnalysis(ctx, analysis, rt, ht, sellerContext); err != nil {
+			return err
+		}
+	}
+	s.markAggregateStale(ctx, analysis)
+	log.Printf("   ⬆️ Call %s upgraded from its provisional analysis", pc.CallID)
+	return storage.DeleteProvisionalCall(ctx, pc.CallID)
+}
+
+// ProvisionalQueue returns the calls waiting for a full analysis, oldest
+// first, and whether this instance is analyzing provisionally
+func (s *Service) ProvisionalQueue(ctx context.Context) (*client.ProvisionalQueue, error) {
+	calls, err := storage.LoadProvisionalCalls(ctx, config.PROVISIONAL_LIST_LIMIT)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load provisional calls: %w", err)
+	}
+	for i := range calls {
+		calls[i].Transcript = nil
+	}
+	q := &client.ProvisionalQueue{Calls: calls}
+	if q.Count, err = storage.CountProvisionalCalls(ctx); err != nil {
+		return nil, fmt.Errorf("failed to count provisional calls: %w", err)
+	}
+
+	s.degraded.mu.Lock()
+	defer s.degraded.mu.Unlock()
+	if !s.degraded.since.IsZero() {
+		since := s.degraded.since
+		q.Degraded, q.DegradedSince, q.Reason = true, &since, s.degraded.reason
+	}
+	return q, nil
+}
@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== DATA QUALITY ====================
+// Every transcript is checked as it arrives, before anything is skipped or
+// analyzed, and the result recorded as a transcript_received event: the
+// expected fields it left empty, whether it had any text, whether its call
+// date could be read and how long it was. The report adds those up per
+// source over a date range, so a system exporting broken files shows up
+// before its gaps reach the analytics. Duplicates are counted at report
+// time, as new transcripts whose call ID or text was already received in
+// the range.
+
+// RecordTranscriptReceived records the quality of a watcher transcript as
+// it arrived. revised is a new version of a file received before.
+func (s *Service) RecordTranscriptReceived(ctx context.Context, ht *client.HackathonTranscript, revised bool) {
+	var missing []string
+	for _, f := range []struct{ name, value string }{
+		{"click_to_call_id", ht.ClickToCallID},
+		{"gluser_id", ht.GluserID},
+		{"call_entered_on", ht.CallEnteredOn},
+		{"customer_type", ht.CustomerType},
+		{"city_name", ht.CityName},
+		{"iil_vertical_name", ht.IILVerticalName},
+	} {
+		if strings.TrimSpace(f.value) == "" {
+			missing = append(missing, f.name)
+		}
+	}
+	p := client.TranscriptReceivedPayload{
+		Source:          "watcher",
+		Origin:          ht.Origin,
+		Revised:         revised,
+		MissingFields:   missing,
+		EmptyTranscript: strings.TrimSpace(ht.Transcript) == "",
+		UnparseableDate: strings.TrimSpace(ht.CallEnteredOn) != "" && callTimestamp(ht, time.Time{}).IsZero(),
+		TranscriptChars: len([]rune(ht.Transcript)),
+	}
+	if !p.EmptyTranscript {
+		p.Checksum = ht.Checksum()
+	}
+	storage.RecordEvent(ctx, client.Event{
+		Type:     client.EventTranscriptReceived,
+		CallID:   ht.ClickToCallID,
+		GluserID: ht.GluserID,
+		Payload:  p,
+	})
+}
+
+// recordAPITranscriptReceived records the quality of a transcript posted to
+// POST /transcripts, saved as callID
+func recordAPITranscriptReceived(ctx context.Context, rt client.RawTranscript, callID string) {
+	var missing []string
+	for _, f := range []struct {
+		name  string
+		empty bool
+	}{
+		{"seller_id", strings.TrimSpace(rt.SellerID) == ""},
+		{"timestamp", rt.Timestamp.IsZero()},
+		{"language", strings.TrimSpace(rt.Language) == ""},
+		{"duration_ms", rt.DurationMS == 0},
+		{"customer_type", strings.TrimSpace(rt.CustomerType) == ""},
+	} {
+		if f.empty {
+			missing = append(missing, f.name)
+		}
+	}
+	p := client.TranscriptReceivedPayload{
+		Source:          "api",
+		MissingFields:   missing,
+		EmptyTranscript: strings.TrimSpace(rt.Transcript) == "",
+		TranscriptChars: len([]rune(rt.Transcript)),
+	}
+	if !p.EmptyTranscript {
+		p.Checksum = (&client.HackathonTranscript{Transcript: rt.Transcript}).Checksum()
+	}
+	storage.RecordEvent(ctx, client.Event{
+		Type:     client.EventTranscriptReceived,
+		CallID:   callID,
+		GluserID: rt.SellerID,
+		Payload:  p,
+	})
+}
+
+// qualitySource names where a transcript came from in the report
+func qualitySource(source string, origin *client.CallOrigin) string {
+	if origin == nil || origin.System == "" {
+		return source
+	}
+	if origin.Region != "" {
+		return origin.System + ":" + origin.Region
+	}
+	return origin.System
+}
+
+// DataQualityReport sums up the quality of the transcripts received on the
+// dates from to to, per source
+func (s *Service) DataQualityReport(ctx context.Context, from, to time.Time) (*client.DataQualityReport, error) {
+	if to.Before(from) {
+		return nil, fmt.Errorf("to date is before from date")
+	}
+	if days := int(to.Sub(from).Hours()/24) + 1; days > config.DATA_QUALITY_MAX_DAYS {
+		return nil, fmt.Errorf("date range too large (%d days, max %d)", days, config.DATA_QUALITY_MAX_DAYS)
+	}
+	start, err := config.ParseBusinessDate(from.Format(config.DateLayout))
+	if err != nil {
+		return nil, err
+	}
+	end, err := config.ParseBusinessDate(to.Format(config.DateLayout))
+	if err != nil {
+		return nil, err
+	}
+	end = end.AddDate(0, 0, 1)
+
+	sources := make(map[string]*client.SourceQuality)
+	chars := make(map[string]int)
+	source := func(name string) *client.SourceQuality {
+		if sources[name] == nil {
+			sources[name] = &client.SourceQuality{
+				Source:            name,
+				MissingFields:     make(map[string]int),
+				MissingFieldRates: make(map[string]float64),
+			}
+		}
+		return sources[name]
+	}
+
+	seenCalls := make(map[string]bool)
+	seenText := make(map[string]bool)
+	err = dayEvents(ctx, client.EventTranscriptReceived, start, end, func(e client.Event) {
+		var p client.TranscriptReceivedPayload
+		if !decodePayload(e, &p) {
+			return
+		}
+		name := qualitySource(p.Source, p.Origin)
+		sq := source(name)
+		sq.Received++
+		chars[name] += p.TranscriptChars
+		if p.Revised {
+			sq.Revised++
+		}
+		if p.EmptyTranscript {
+			sq.EmptyTranscripts++
+		}
+		if p.UnparseableDate {
+			sq.UnparseableDates++
+		}
+		for _, f := range p.MissingFields {
+			sq.MissingFields[f]++
+		}
+		if !p.Revised && ((e.CallID != "" && seenCalls[e.CallID]) || (p.Checksum != "" && seenText[p.Checksum])) {
+			sq.Duplicates++
+		}
+		if e.CallID != "" {
+			seenCalls[e.CallID] = true
+		}
+		if p.Checksum != "" {
+			seenText[p.Checksum] = true
+		}
+	})
+	if err == nil {
+		err = dayEvents(ctx, client.EventAnalysisFailed, start, end, func(e client.Event) {
+			var p client.AnalysisFailedPayload
+			if !decodePayload(e, &p) || p.Attempts != 1 || (p.Stage != "read" && p.Stage != "parse") {
+				return
+			}
+			source(qualitySource(p.Source, p.Origin)).Unreadable++
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	report := &client.DataQualityReport{
+		From:        from.Format(config.DateLayout),
+		To:          to.Format(config.DateLayout),
+		Sources:     []client.SourceQuality{},
+		GeneratedAt: time.Now(),
+	}
+	for name, sq := range sources {
+		report.Received += sq.Received
+		if sq.Received > 0 {
+			n := float64(sq.Received)
+			sq.EmptyRate = rate3(sq.EmptyTranscripts, n)
+			sq.UnparseableDateRate = rate3(sq.UnparseableDates, n)
+			sq.DuplicateRate = rate3(sq.Duplicates, n)
+			sq.AvgTranscriptChars = int(math.Round(float64(chars[name]) / n))
+			for f, count := range sq.MissingFields {
+				sq.MissingFieldRates[f] = rate3(count, n)
+			}
+		}
+		report.Sources = append(report.Sources, *sq)
+	}
+	sort.Slice(report.Sources, func(i, j int) bool {
+		a, b := report.Sources[i], report.Sources[j]
+		if a.Received != b.Received {
+			return a.Received > b.Received
+		}
+		return a.Source < b.Source
+	})
+	return report, nil
+}
+
+func rate3(n int, total float64) float64 {
+	return math.Round(float64(n)/total*1000) / 1000
+}
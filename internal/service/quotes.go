@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/storage"
+	"im-ai-voice/internal/ticket"
+)
+
+// ==================== SELLER QUOTES ====================
+// Product teams want sellers' own words for reviews and presentations. Each
+// analyzed call contributes, per issue, the first piece of evidence found
+// verbatim on a seller's line of transcript_en, between QUOTE_MIN_CHARS and
+// QUOTE_MAX_CHARS long, with personal details masked as in tickets. A
+// re-analyzed call's quotes replace its earlier ones. A seller who withdraws
+// consent is opted out: their quotes and call samples are deleted and none
+// are taken from their later calls.
+
+const (
+	quotesDefaultLimit = 50
+	quotesMaxLimit     = 500
+)
+
+var ErrInvalidQuoteOptOut = errors.New("invalid quote opt-out")
+
+// sellerLabels are the speaker labels of the seller's lines in transcripts
+var sellerLabels = map[string]bool{"customer": true, "seller": true}
+
+var quoteSeverities = map[string]int{"low": 1, "medium": 2, "high": 3, "critical": 4}
+
+// collectQuotes stores the quotes of an analyzed call in place of any it
+// had. Failures are logged, they don't fail the analysis.
+func (s *Service) collectQuotes(ctx context.Context, analysis *client.AnalysisResult) {
+	if analysis.SellerID == "" {
+		return
+	}
+	optOut, err := storage.LoadQuoteOptOut(ctx, analysis.SellerID)
+	if err != nil {
+		log.Printf("   ⚠️ Failed to load quote opt-out of %s: %v", analysis.SellerID, err)
+		return
+	}
+	if optOut != nil {
+		return
+	}
+
+	quotes := callQuotes(analysis)
+	if err := storage.ReplaceCallQuotes(ctx, analysis.CallID, quotes); err != nil {
+		log.Printf("   ⚠️ Failed to save quotes for %s: %v", analysis.CallID, err)
+		return
+	}
+	if len(quotes) > 0 {
+		log.Printf("   🗣️ Call %s: %d seller quotes", analysis.CallID, len(quotes))
+	}
+}
+
+// callQuotes picks a quote for each of the call's issues that has one on a seller's line
+func callQuotes(analysis *client.AnalysisResult) []client.SellerQuote {
+	lines := strings.Split(analysis.TranscriptEn, "\n")
+	now := time.Now()
+	var quotes []client.SellerQuote
+	for i, issue := range analysis.Issues {
+		for _, e := range issue.Evidence {
+			if !e.Located || e.Turn == nil || *e.Turn >= len(lines) || !sellerLine(lines[*e.Turn]) {
+				continue
+			}
+			quote := strings.TrimSpace(e.Quote)
+			if label, rest, ok := strings.Cut(quote, ":"); ok && sellerLabels[strings.ToLower(strings.TrimSpace(label))] {
+				quote = strings.TrimSpace(rest)
+			}
+			if n := utf8.RuneCountInString(quote); n < config.QUOTE_MIN_CHARS || n > config.QUOTE_MAX_CHARS {
+				continue
+			}
+			quotes = append(quotes, client.SellerQuote{
+				ID:            fmt.Sprintf("%s-%d", analysis.CallID, i),
+				CallID:        analysis.CallID,
+				GluserID:      analysis.SellerID,
+				FeatureBucket: issue.Bucket,
+				Problem:       ticket.Redact(issue.Problem),
+				Severity:      issue.Severity,
+				Sentiment:     analysis.Intent.Sentiment,
+				Quote:         ticket.Redact(quote),
+				Date:          config.BusinessDate(analysis.Timestamp),
+				CallTime:      analysis.Timestamp,
+				CreatedAt:     now,
+			})
+			break
+		}
+	}
+	return quotes
+}
+
+// sellerLine reports whether a transcript line is labelled as the seller's
+func sellerLine(line string) bool {
+	label, _, ok := strings.Cut(line, ":")
+	return ok && sellerLabels[strings.ToLower(strings.TrimSpace(label))]
+}
+
+// ListQuotes returns seller quotes matching q, at most one per seller and
+// bucket, most severe and newest first, with counts per bucket over all of
+// them
+func (s *Service) ListQuotes(ctx context.Context, q storage.QuoteQuery, limit int) (*client.QuoteLibrary, error) {
+	if limit <= 0 {
+		limit = quotesDefaultLimit
+	}
+	if limit > quotesMaxLimit {
+		limit = quotesMaxLimit
+	}
+
+	all, err := storage.LoadQuotes(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	lib := &client.QuoteLibrary{
+		From:        q.From,
+		To:          q.To,
+		Quotes:      []client.SellerQuote{},
+		Total:       len(all),
+		ByBucket:    []client.QuoteBucketCount{},
+		GeneratedAt: time.Now(),
+	}
+	counts := make(map[string]*client.QuoteBucketCount)
+	sellers := make(map[string]bool)
+	for _, quote := range all {
+		c := counts[quote.FeatureBucket]
+		if c == nil {
+			c = &client.QuoteBucketCount{FeatureBucket: quote.FeatureBucket}
+			counts[quote.FeatureBucket] = c
+		}
+		c.Quotes++
+		if key := quote.FeatureBucket + "|" + quote.GluserID; !sellers[key] {
+			sellers[key] = true
+			c.Sellers++
+		}
+	}
+	for _, c := range counts {
+		lib.ByBucket = append(lib.ByBucket, *c)
+	}
+	sort.Slice(lib.ByBucket, func(i, j int) bool {
+		if lib.ByBucket[i].Quotes != lib.ByBucket[j].Quotes {
+			return lib.ByBucket[i].Quotes > lib.ByBucket[j].Quotes
+		}
+		return lib.ByBucket[i].FeatureBucket < lib.ByBucket[j].FeatureBucket
+	})
+
+	// Newest first already; the stable sort keeps that within a severity
+	sort.SliceStable(all, func(i, j int) bool {
+		return quoteSeverities[strings.ToLower(all[i].Severity)] > quoteSeverities[strings.ToLower(all[j].Severity)]
+	})
+	picked := make(map[string]bool)
+	for _, quote := range all {
+		if len(lib.Quotes) == limit {
+			break
+		}
+		key := quote.FeatureBucket + "|" + quote.GluserID
+		if picked[key] {
+			continue
+		}
+		picked[key] = true
+		lib.Quotes = append(lib.Quotes, quote)
+	}
+	lib.Count = len(lib.Quotes)
+	return lib, nil
+}
+
+// OptOutOfQuotes records that a seller withdrew consent to be quoted and
+// deletes their quotes and call samples
+func (s *Service) OptOutOfQuotes(ctx context.Context, in client.QuoteOptOutRequest) (*client.QuoteOptOut, error) {
+	in.GluserID = strings.TrimSpace(in.GluserID)
+	if in.GluserID == "" {
+		return nil, fmt.Errorf("%w: gluser_id is required", ErrInvalidQuoteOptOut)
+	}
+
+	// Recorded first, so a call analyzed meanwhile doesn't add quotes back
+	o := &client.QuoteOptOut{
+		GluserID: in.GluserID,
+		Reason:   strings.TrimSpace(in.Reason),
+		By:       in.By,
+		At:       time.Now(),
+	}
+	if err := storage.SaveQuoteOptOut(ctx, o); err != nil {
+		return nil, err
+	}
+	deleted, err := storage.DeleteSellerQuotes(ctx, in.GluserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete quotes: %w", err)
+	}
+	o.Deleted = deleted
+	if o.SamplesDeleted, err = storage.DeleteSellerSamples(ctx, in.GluserID); err != nil {
+		return nil, fmt.Errorf("failed to delete call samples: %w", err)
+	}
+	log.Printf("🗣️ %s opted out of quotes, %d deleted with %d call samples (by %s)", in.GluserID, deleted, o.SamplesDeleted, orAnonymous(o.By))
+	return o, nil
+}
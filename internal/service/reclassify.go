@@ -0,0 +1,813 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/aggregate"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/profile"
+	"im-ai-voice/internal/storage"
+	"im-ai-voice/internal/ulid"
+)
+
+// ==================== TAXONOMY RECLASSIFICATION ====================
+// When a bucket is renamed, merged into another or split, the analyses,
+// tracked issues, aggregates and tickets stored before keep the old name and
+// trends break at the change. A reclassification moves them to the current
+// taxonomy. Mappings move every issue of a bucket to one other; a split
+// places each issue by its targets' keywords, then, with use_llm, by asking
+// Gemini, and otherwise in the split's default. Gemini is asked before
+// anything is written, so a failing request leaves the data as it was.
+//
+// An aggregate still matching its analyses has its bucket figures rebuilt
+// from the moved issues and stays fresh; one already stale, or whose
+// analyses were archived, has its bucket summaries renamed and merged in
+// place, a split bucket's going to the default. Tickets keep their IDs and
+// move whole, a split bucket's to the target most of the date's issues went
+// to. Every record moved is kept in the run's changes, the audit trail.
+
+const (
+	reclassifyLLMBatch     = 40   // Issues per Gemini request
+	reclassifyChangesLimit = 1000 // Changes returned with a run, unless asked for fewer
+	reclassifyTopProblems  = 5    // As aggregate.Build keeps
+	reclassifyTopExamples  = 3
+)
+
+// A run's job progress is counted in phases
+const (
+	reclassifyPhaseAnalyses = iota
+	reclassifyPhaseProfiles
+	reclassifyPhaseAggregates
+	reclassifyPhaseTickets
+	reclassifyPhases
+)
+
+var (
+	ErrInvalidReclassify  = errors.New("invalid reclassification")
+	ErrReclassifyNotFound = errors.New("reclassification not found")
+	ErrReclassifyRunning  = errors.New("a reclassification is already running")
+)
+
+// reclassifyRunning keeps two runs from moving the same records
+var reclassifyRunning sync.Mutex
+
+// bucketPlan is where a reclassification moves each retired bucket's issues
+type bucketPlan struct {
+	mappings map[string]string
+	splits   map[string]client.BucketSplit
+	llm      map[string]map[string]string // Gemini's target per split bucket and problem
+}
+
+// moves reports whether the plan retires bucket
+func (p *bucketPlan) moves(bucket string) bool {
+	_, mapped := p.mappings[bucket]
+	_, split := p.splits[bucket]
+	return mapped || split
+}
+
+// place returns where an issue of bucket goes and how that was chosen,
+// false when the bucket isn't retired
+func (p *bucketPlan) place(bucket, problem string, keywords []string) (string, string, bool) {
+	if to, ok := p.mappings[bucket]; ok {
+		return to, client.ReclassifyByMapping, true
+	}
+	split, ok := p.splits[bucket]
+	if !ok {
+		return "", "", false
+	}
+	if to := keywordTarget(split, problem, keywords); to != "" {
+		return to, client.ReclassifyByKeyword, true
+	}
+	if to := p.llm[bucket][problem]; to != "" {
+		return to, client.ReclassifyByLLM, true
+	}
+	return split.Default, client.ReclassifyByDefault, true
+}
+
+// target returns where a bucket's records go as a whole: its mapping, or
+// its split's default
+func (p *bucketPlan) target(bucket string) (string, bool) {
+	if to, ok := p.mappings[bucket]; ok {
+		return to, true
+	}
+	if split, ok := p.splits[bucket]; ok {
+		return split.Default, true
+	}
+	return "", false
+}
+
+// newBucketPlan validates a reclassification request
+func (s *Service) newBucketPlan(in *client.ReclassifyRequest) (*bucketPlan, error) {
+	if len(in.Mappings) == 0 && len(in.Splits) == 0 {
+		return nil, fmt.Errorf("%w: give at least one mapping or split", ErrInvalidReclassify)
+	}
+	if in.UseLLM && s.ai == nil {
+		return nil, fmt.Errorf("%w: use_llm needs Gemini (ANALYSIS_ENGINE=rules)", ErrInvalidReclassify)
+	}
+	p := &bucketPlan{mappings: make(map[string]string), splits: make(map[string]client.BucketSplit), llm: make(map[string]map[string]string)}
+	targets := make(map[string]bool)
+	target := func(bucket string) error {
+		if !slices.Contains(config.FeatureBuckets, bucket) {
+			return fmt.Errorf("%w: %q isn't a bucket of the current taxonomy", ErrInvalidReclassify, bucket)
+		}
+		targets[bucket] = true
+		return nil
+	}
+	retire := func(bucket string) error {
+		if bucket == "" {
+			return fmt.Errorf("%w: from is required", ErrInvalidReclassify)
+		}
+		if p.moves(bucket) {
+			return fmt.Errorf("%w: %q is moved more than once", ErrInvalidReclassify, bucket)
+		}
+		return nil
+	}
+
+	for i := range in.Mappings {
+		m := &in.Mappings[i]
+		m.From, m.To = strings.TrimSpace(m.From), strings.TrimSpace(m.To)
+		if err := retire(m.From); err != nil {
+			return nil, err
+		}
+		if err := target(m.To); err != nil {
+			return nil, err
+		}
+		p.mappings[m.From] = m.To
+	}
+	for i := range in.Splits {
+		sp := &in.Splits[i]
+		sp.From, sp.Default = strings.TrimSpace(sp.From), strings.TrimSpace(sp.Default)
+		if err := retire(sp.From); err != nil {
+			return nil, err
+		}
+		if len(sp.Into) < 2 {
+			return nil, fmt.Errorf("%w: split of %q needs at least two buckets, use a mapping for one", ErrInvalidReclassify, sp.From)
+		}
+		for j := range sp.Into {
+			t := &sp.Into[j]
+			t.Bucket, t.Description = strings.TrimSpace(t.Bucket), strings.TrimSpace(t.Description)
+			if err := target(t.Bucket); err != nil {
+				return nil, err
+			}
+		}
+		if sp.Default == "" {
+			sp.Default = sp.Into[0].Bucket
+		}
+		if !slices.ContainsFunc(sp.Into, func(t client.SplitTarget) bool { return t.Bucket == sp.Default }) {
+			return nil, fmt.Errorf("%w: default %q of the split of %q isn't one of its buckets", ErrInvalidReclassify, sp.Default, sp.From)
+		}
+		p.splits[sp.From] = *sp
+	}
+	for bucket := range targets {
+		if p.moves(bucket) {
+			return nil, fmt.Errorf("%w: %q is both moved from and moved to", ErrInvalidReclassify, bucket)
+		}
+	}
+	return p, nil
+}
+
+// keywordTarget returns the first of a split's targets with a keyword in
+// the problem or the issue's keywords, "" when none has
+func keywordTarget(split client.BucketSplit, problem string, keywords []string) string {
+	text := strings.ToLower(problem + " " + strings.Join(keywords, " "))
+	for _, t := range split.Into {
+		for _, kw := range t.Keywords {
+			if kw = strings.ToLower(strings.TrimSpace(kw)); kw != "" && strings.Contains(text, kw) {
+				return t.Bucket
+			}
+		}
+	}
+	return ""
+}
+
+// Reclassify migrates stored analyses, tracked issues, aggregates and
+// tickets from retired buckets to the current taxonomy. A dry run reports
+// the changes without making them. A run that fails part way is returned
+// with the error, its changes listing what was migrated.
+func (s *Service) Reclassify(ctx context.Context, in client.ReclassifyRequest) (*client.Reclassification, error) {
+	plan, err := s.newBucketPlan(&in)
+	if err != nil {
+		return nil, err
+	}
+	if !reclassifyRunning.TryLock() {
+		return nil, ErrReclassifyRunning
+	}
+	defer reclassifyRunning.Unlock()
+
+	run := &client.Reclassification{
+		ID:        ulid.New(),
+		Mappings:  in.Mappings,
+		Splits:    in.Splits,
+		UseLLM:    in.UseLLM,
+		DryRun:    in.DryRun,
+		Reason:    strings.TrimSpace(in.Reason),
+		By:        in.By,
+		Status:    client.ReclassifyRunning,
+		Methods:   make(map[string]int),
+		StartedAt: time.Now(),
+	}
+	for _, bucket := range config.FeatureBuckets {
+		if plan.moves(bucket) {
+			log.Printf("⚠️ Reclassifying %q, which is still in the taxonomy: new calls may be filed under it", bucket)
+		}
+	}
+
+	r := &reclassifier{svc: s, plan: plan, run: run}
+	if in.DryRun {
+		err = r.migrate(ctx)
+	} else {
+		r.job = s.StartJob(ctx, client.JobTypeReclassify, run.ID)
+		run.JobID = r.job.ID()
+		if err = storage.SaveReclassification(ctx, run); err != nil {
+			r.job.Finish(ctx, "", err)
+			return nil, fmt.Errorf("failed to save reclassification: %w", err)
+		}
+		err = r.migrate(ctx)
+		if ferr := r.flush(ctx); err == nil {
+			err = ferr
+		}
+	}
+
+	now := time.Now()
+	run.FinishedAt = &now
+	run.Status = client.ReclassifyCompleted
+	if err != nil {
+		run.Status, run.Error = client.ReclassifyFailed, err.Error()
+	}
+	if !in.DryRun {
+		if serr := storage.SaveReclassification(context.WithoutCancel(ctx), run); serr != nil {
+			log.Printf("⚠️ Failed to save reclassification %s: %v", run.ID, serr)
+		}
+		r.job.Finish(ctx, "/admin/reclassifications/"+run.ID, err)
+	}
+	log.Printf("🗂️ Reclassification %s %s: %d issues in %d analyses, %d tracked issues in %d profiles, %d aggregates, %d tickets (dry run: %t, by %s)",
+		run.ID, run.Status, run.Issues, run.Analyses, run.TrackedIssues, run.Profiles, run.Aggregates, run.Tickets, in.DryRun, orAnonymous(in.By))
+	return run, err
+}
+
+// ListReclassifications returns the runs, newest first, without their changes
+func (s *Service) ListReclassifications(ctx context.Context) ([]client.Reclassification, error) {
+	return storage.LoadReclassifications(ctx)
+}
+
+// GetReclassification returns a run with up to limit of its changes of a
+// kind (all kinds when empty)
+func (s *Service) GetReclassification(ctx context.Context, id, kind string, limit int) (*client.Reclassification, error) {
+	switch kind {
+	case "", client.ReclassifyAnalysisIssue, client.ReclassifyTrackedIssue, client.ReclassifyAggregate, client.ReclassifyTicket:
+	default:
+		return nil, fmt.Errorf("%w: unknown kind %q", ErrInvalidReclassify, kind)
+	}
+	if limit <= 0 || limit > reclassifyChangesLimit {
+		limit = reclassifyChangesLimit
+	}
+	run, err := storage.LoadReclassification(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if run == nil {
+		return nil, fmt.Errorf("%w: %s", ErrReclassifyNotFound, id)
+	}
+	changes, total, err := storage.LoadReclassifyChanges(ctx, id, kind, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load reclassification changes: %w", err)
+	}
+	run.Changes, run.ChangesTruncated = changes, len(changes) < total
+	return run, nil
+}
+
+// reclassifier is a run in progress
+type reclassifier struct {
+	svc     *Service
+	plan    *bucketPlan
+	run     *client.Reclassification
+	job     *JobTracker // nil for dry runs
+	pending []client.ReclassifyChange
+
+	days map[string]*reclassifyDay // Dates whose analyses had issues moved
+}
+
+// reclassifyDay is a date's analyses before and after the move
+type reclassifyDay struct {
+	before, after []client.AnalysisResult
+	moved         map[string]map[string]int // Issues moved, by retired bucket and target
+}
+
+// record adds a change to the run's trail
+func (r *reclassifier) record(c client.ReclassifyChange) {
+	r.run.ChangeCount++
+	if c.Kind == client.ReclassifyAnalysisIssue || c.Kind == client.ReclassifyTrackedIssue {
+		r.run.Methods[c.Method]++
+	}
+	if r.job != nil {
+		r.pending = append(r.pending, c)
+		return
+	}
+	if len(r.run.Changes) < reclassifyChangesLimit {
+		r.run.Changes = append(r.run.Changes, c)
+	} else {
+		r.run.ChangesTruncated = true
+	}
+}
+
+// flush stores the changes recorded since the last flush
+func (r *reclassifier) flush(ctx context.Context) error {
+	if r.job == nil {
+		return nil
+	}
+	err := storage.AppendReclassifyChanges(context.WithoutCancel(ctx), r.run.ID, r.pending)
+	r.pending = nil
+	if err != nil {
+		return fmt.Errorf("failed to save reclassification changes: %w", err)
+	}
+	return nil
+}
+
+func (r *reclassifier) progress(ctx context.Context, phase int) {
+	if r.job != nil {
+		r.job.Progress(ctx, phase, reclassifyPhases)
+	}
+}
+
+// migrate runs the phases in turn, flushing the trail after each
+func (r *reclassifier) migrate(ctx context.Context) error {
+	analyses, err := loadAllAnalyses(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load analyses: %w", err)
+	}
+	var profiles []*client.SellerProfile
+	if r.run.UseLLM {
+		if profiles, err = storage.LoadAllSellerProfiles(ctx); err != nil {
+			return fmt.Errorf("failed to load seller profiles: %w", err)
+		}
+		if err := r.askGemini(ctx, analyses, profiles); err != nil {
+			return err
+		}
+	}
+
+	for _, phase := range []func(context.Context, []client.AnalysisResult, []*client.SellerProfile) error{
+		r.migrateAnalyses, r.migrateProfiles, r.migrateAggregates, r.migrateTickets,
+	} {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		err := phase(ctx, analyses, profiles)
+		if ferr := r.flush(ctx); err == nil {
+			err = ferr
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// askGemini places the issues of split buckets no keyword places, before
+// anything is moved
+func (r *reclassifier) askGemini(ctx context.Context, analyses []client.AnalysisResult, profiles []*client.SellerProfile) error {
+	unplaced := make(map[string][]string)
+	seen := make(map[string]bool)
+	add := func(bucket, problem string, keywords []string) {
+		split, ok := r.plan.splits[bucket]
+		key := bucket + "\x00" + problem
+		if !ok || seen[key] || strings.TrimSpace(problem) == "" || keywordTarget(split, problem, keywords) != "" {
+			return
+		}
+		seen[key] = true
+		unplaced[bucket] = append(unplaced[bucket], problem)
+	}
+	for _, a := range analyses {
+		for _, issue := range a.Issues {
+			add(issue.Bucket, issue.Problem, issue.Keywords)
+		}
+	}
+	for _, p := range profiles {
+		for _, issues := range [][]client.TrackedIssue{p.ActiveIssues, p.ResolvedIssues} {
+			for _, issue := range issues {
+				add(issue.Bucket, issue.Problem, nil)
+			}
+		}
+	}
+
+	for bucket, problems := range unplaced {
+		split := r.plan.splits[bucket]
+		placed := make(map[string]string, len(problems))
+		for start := 0; start < len(problems); start += reclassifyLLMBatch {
+			batch := problems[start:min(start+reclassifyLLMBatch, len(problems))]
+			targets, err := r.svc.ai.ClassifySplit(ctx, bucket, split.Into, batch)
+			if err != nil {
+				return fmt.Errorf("failed to place issues of %q with Gemini: %w", bucket, err)
+			}
+			for i, to := range targets {
+				if to != "" {
+					placed[batch[i]] = to
+				}
+			}
+		}
+		r.plan.llm[bucket] = placed
+		log.Printf("🗂️ Gemini placed %d of %d issues of %q", len(placed), len(problems), bucket)
+	}
+	return nil
+}
+
+// moveIssues returns an analysis with its issues in retired buckets moved,
+// recording each, and whether any moved
+func (r *reclassifier) moveIssues(a client.AnalysisResult, record bool) (client.AnalysisResult, map[string]map[string]int) {
+	var moved map[string]map[string]int
+	for i, issue := range a.Issues {
+		to, method, ok := r.plan.place(issue.Bucket, issue.Problem, issue.Keywords)
+		if !ok {
+			continue
+		}
+		if moved == nil {
+			moved = make(map[string]map[string]int)
+			a.Issues = slices.Clone(a.Issues)
+		}
+		if moved[issue.Bucket] == nil {
+			moved[issue.Bucket] = make(map[string]int)
+		}
+		moved[issue.Bucket][to]++
+		if record {
+			r.record(client.ReclassifyChange{
+				Kind: client.ReclassifyAnalysisIssue, Record: a.CallID,
+				From: issue.Bucket, To: to, Method: method, Problem: issue.Problem,
+			})
+		}
+		a.Issues[i].Bucket = to
+	}
+	return a, moved
+}
+
+// migrateAnalyses moves the issues of stored analyses, keeping each date's
+// analyses before and after for its aggregate
+func (r *reclassifier) migrateAnalyses(ctx context.Context, analyses []client.AnalysisResult, _ []*client.SellerProfile) error {
+	r.progress(ctx, reclassifyPhaseAnalyses)
+	r.days = make(map[string]*reclassifyDay)
+	byDate := make(map[string][]client.AnalysisResult)
+	for _, a := range analyses {
+		date := storage.AggregationDate(&a)
+		byDate[date] = append(byDate[date], a)
+	}
+	for date, before := range byDate {
+		day := &reclassifyDay{before: before, after: make([]client.AnalysisResult, len(before)), moved: make(map[string]map[string]int)}
+		for i, a := range before {
+			after, moved := r.moveIssues(a, r.job == nil)
+			day.after[i] = after
+			for from, targets := range moved {
+				if day.moved[from] == nil {
+					day.moved[from] = make(map[string]int)
+				}
+				for to, n := range targets {
+					day.moved[from][to] += n
+				}
+			}
+			if moved != nil && r.job == nil {
+				r.countAnalysis(moved)
+			}
+		}
+		if len(day.moved) > 0 {
+			r.days[date] = day
+		}
+	}
+	if r.job == nil {
+		return nil
+	}
+
+	// Stored in place, the analyses as they are now rather than as loaded
+	_, err := storage.RewriteAnalyses(ctx, func(ar *client.AnalysisResult) bool {
+		after, moved := r.moveIssues(*ar, true)
+		if moved == nil {
+			return false
+		}
+		*ar = after
+		r.countAnalysis(moved)
+		return true
+	})
+	if err != nil {
+		return fmt.Errorf("failed to rewrite analyses: %w", err)
+	}
+	return nil
+}
+
+func (r *reclassifier) countAnalysis(moved map[string]map[string]int) {
+	r.run.Analyses++
+	for _, targets := range moved {
+		for _, n := range targets {
+			r.run.Issues += n
+		}
+	}
+}
+
+// migrateProfiles moves tracked issues. Profiles loaded for Gemini are
+// loaded again before being saved, so calls processed since aren't undone.
+func (r *reclassifier) migrateProfiles(ctx context.Context, _ []client.AnalysisResult, profiles []*client.SellerProfile) error {
+	r.progress(ctx, reclassifyPhaseProfiles)
+	if profiles == nil || r.job != nil {
+		var err error
+		if profiles, err = storage.LoadAllSellerProfiles(ctx); err != nil {
+			return fmt.Errorf("failed to load seller profiles: %w", err)
+		}
+	}
+	for _, p := range profiles {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		moved := profile.ReclassifyIssues(p, func(issue *client.TrackedIssue) bool {
+			to, method, ok := r.plan.place(issue.Bucket, issue.Problem, nil)
+			if !ok {
+				return false
+			}
+			r.record(client.ReclassifyChange{
+				Kind: client.ReclassifyTrackedIssue, Record: p.GluserID + "/" + issue.IssueID,
+				From: issue.Bucket, To: to, Method: method, Problem: issue.Problem,
+			})
+			issue.Bucket = to
+			return true
+		})
+		if moved == 0 {
+			continue
+		}
+		r.run.Profiles++
+		r.run.TrackedIssues += moved
+		if r.job != nil {
+			if err := storage.SaveSellerProfile(ctx, p); err != nil {
+				return fmt.Errorf("failed to save profile %s: %w", p.GluserID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// migrateAggregates rebuilds the bucket figures of aggregates still
+// matching their analyses and renames the buckets of the others
+func (r *reclassifier) migrateAggregates(ctx context.Context, _ []client.AnalysisResult, _ []*client.SellerProfile) error {
+	r.progress(ctx, reclassifyPhaseAggregates)
+	var dates []string
+	var err error
+	if storage.IsMongoEnabled() {
+		if dates, err = storage.ListAggregateDatesFromMongo(ctx); err != nil {
+			log.Printf("⚠️ MongoDB list failed, falling back to local: %v", err)
+		}
+	}
+	if len(dates) == 0 {
+		if dates, err = storage.ListAggregates(); err != nil {
+			return fmt.Errorf("failed to list aggregates: %w", err)
+		}
+	}
+
+	for _, date := range dates {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		agg, err := r.svc.GetDailyAggregate(ctx, date)
+		if err != nil || agg == nil {
+			continue
+		}
+		day := r.days[date]
+		var moved map[string][]string
+		if day != nil && agg.InputFingerprint != "" && agg.InputFingerprint == aggregate.Fingerprint(day.before) {
+			moved = r.rebuildBuckets(agg, day)
+		} else {
+			moved = r.renameBuckets(agg)
+		}
+		if len(moved) == 0 {
+			continue
+		}
+		for _, from := range slices.Sorted(maps.Keys(moved)) {
+			r.record(client.ReclassifyChange{Kind: client.ReclassifyAggregate, Record: date, From: from, To: strings.Join(moved[from], ", ")})
+		}
+		r.run.Aggregates++
+		if r.job == nil {
+			continue
+		}
+		if storage.IsMongoEnabled() {
+			err = storage.SaveAggregateToMongo(ctx, agg)
+		} else {
+			err = storage.SaveAggregate(*agg)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to save the %s aggregate: %w", date, err)
+		}
+	}
+
+	// Shift aggregates are rebuilt from the moved analyses as they'd be by an aggregation
+	if r.job != nil && len(aggregate.Shifts) > 0 {
+		for _, date := range slices.Sorted(maps.Keys(r.days)) {
+			r.svc.RunShiftAggregations(ctx, date) // Logs its own failures
+		}
+	}
+	return nil
+}
+
+// rebuildBuckets replaces an aggregate's bucket figures with those of its
+// date's analyses after the move, returning the targets of each retired
+// bucket it had
+func (r *reclassifier) rebuildBuckets(agg *client.DailyAggregate, day *reclassifyDay) map[string][]string {
+	moved := make(map[string][]string)
+	for from := range agg.FeatureBuckets {
+		if targets := day.moved[from]; len(targets) > 0 {
+			moved[from] = slices.Sorted(maps.Keys(targets))
+		}
+	}
+	rebuilt := aggregate.Build(agg.Date, day.after)
+	agg.FeatureBuckets = rebuilt.FeatureBuckets
+	agg.VerticalBuckets = rebuilt.VerticalBuckets
+	agg.ChannelBuckets = rebuilt.ChannelBuckets
+	agg.InputFingerprint = aggregate.Fingerprint(day.after)
+	r.renameMovers(agg)
+	return moved
+}
+
+// renameBuckets merges an aggregate's retired bucket summaries into their
+// targets, returning the target of each it had
+func (r *reclassifier) renameBuckets(agg *client.DailyAggregate) map[string][]string {
+	moved := make(map[string][]string)
+	for _, from := range slices.Sorted(maps.Keys(agg.FeatureBuckets)) {
+		to, ok := r.plan.target(from)
+		if !ok {
+			continue
+		}
+		summary := agg.FeatureBuckets[from]
+		delete(agg.FeatureBuckets, from)
+		if into, ok := agg.FeatureBuckets[to]; ok {
+			summary = mergeBucketSummaries(into, summary)
+		}
+		summary.Bucket = to
+		agg.FeatureBuckets[to] = summary
+		moved[from] = []string{to}
+	}
+	for vb, summary := range agg.VerticalBuckets {
+		if to, ok := r.plan.target(summary.Parent); ok {
+			summary.Parent = to
+			agg.VerticalBuckets[vb] = summary
+		}
+	}
+	for _, counts := range agg.ChannelBuckets {
+		for _, from := range slices.Sorted(maps.Keys(counts)) {
+			if to, ok := r.plan.target(from); ok {
+				counts[to] += counts[from]
+				delete(counts, from)
+			}
+		}
+	}
+	if len(moved) > 0 {
+		r.renameMovers(agg)
+	}
+	return moved
+}
+
+// renameMovers renames retired buckets among the aggregate's top movers.
+// They compare with the day before, so they aren't recomputed.
+func (r *reclassifier) renameMovers(agg *client.DailyAggregate) {
+	if agg.TopMovers == nil {
+		return
+	}
+	for i, m := range agg.TopMovers.Buckets {
+		if to, ok := r.plan.target(m.Bucket); ok {
+			agg.TopMovers.Buckets[i].Bucket = to
+		}
+	}
+}
+
+// mergeBucketSummaries combines two summaries of a date's issues as if
+// they'd been one bucket
+func mergeBucketSummaries(a, b client.BucketSummary) client.BucketSummary {
+	// Issues behind a reopen rate, TotalCount only counting the top problems
+	issues := func(s client.BucketSummary) float64 {
+		if s.Reopened > 0 && s.ReopenRate > 0 {
+			return float64(s.Reopened) / s.ReopenRate
+		}
+		return float64(s.TotalCount)
+	}
+	total := issues(a) + issues(b)
+
+	sellers := make(map[string]bool)
+	for _, id := range slices.Concat(a.AffectedSellerIDs, b.AffectedSellerIDs) {
+		sellers[id] = true
+	}
+	problems := make(map[string]client.ProblemCount)
+	for _, pc := range slices.Concat(a.TopProblems, b.TopProblems) {
+		merged := problems[pc.Problem]
+		merged.Problem, merged.Severity = pc.Problem, pc.Severity
+		merged.Count += pc.Count
+		problems[pc.Problem] = merged
+	}
+	severity := make(map[string]int)
+	for _, sb := range []map[string]int{a.SeverityBreakdown, b.SeverityBreakdown} {
+		for sev, n := range sb {
+			severity[sev] += n
+		}
+	}
+
+	merged := client.BucketSummary{
+		AffectedSellerIDs: slices.Sorted(maps.Keys(sellers)),
+		SeverityBreakdown: severity,
+		Examples:          slices.Concat(a.Examples, b.Examples),
+		Reopened:          a.Reopened + b.Reopened,
+	}
+	merged.AffectedSellers = len(merged.AffectedSellerIDs)
+	for _, pc := range problems {
+		merged.TopProblems = append(merged.TopProblems, pc)
+	}
+	sort.Slice(merged.TopProblems, func(i, j int) bool {
+		if merged.TopProblems[i].Count != merged.TopProblems[j].Count {
+			return merged.TopProblems[i].Count > merged.TopProblems[j].Count
+		}
+		return merged.TopProblems[i].Problem < merged.TopProblems[j].Problem
+	})
+	if len(merged.TopProblems) > reclassifyTopProblems {
+		merged.TopProblems = merged.TopProblems[:reclassifyTopProblems]
+	}
+	for _, pc := range merged.TopProblems {
+		merged.TotalCount += pc.Count
+	}
+	if len(merged.Examples) > reclassifyTopExamples {
+		merged.Examples = merged.Examples[:reclassifyTopExamples]
+	}
+	if merged.Reopened > 0 && total > 0 {
+		merged.ReopenRate = math.Round(float64(merged.Reopened)/total*1000) / 1000
+	}
+	return merged
+}
+
+// migrateTickets moves the tickets of retired buckets. Their IDs stay, so
+// a re-aggregation of the date carries their status over only to tickets
+// of buckets the date still has issues in under the same name.
+func (r *reclassifier) migrateTickets(ctx context.Context, _ []client.AnalysisResult, _ []*client.SellerProfile) error {
+	r.progress(ctx, reclassifyPhaseTickets)
+	var dates []string
+	var err error
+	if storage.IsMongoEnabled() {
+		dates, err = storage.ListTicketDatesFromMongo(ctx)
+	}
+	if len(dates) == 0 {
+		dates, err = storage.ListTicketDates()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to list ticket dates: %w", err)
+	}
+
+	for _, date := range dates {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		tickets, err := r.svc.GetTicketsForDate(ctx, date)
+		if err != nil {
+			continue
+		}
+		for i := range tickets {
+			t := &tickets[i]
+			to, ok := r.ticketTarget(date, t.FeatureBucket)
+			if !ok {
+				continue
+			}
+			r.record(client.ReclassifyChange{Kind: client.ReclassifyTicket, Record: date + "/" + t.TicketID, From: t.FeatureBucket, To: to})
+			if prefix := "[" + t.FeatureBucket + "]"; strings.HasPrefix(t.Title, prefix) {
+				t.Title = "[" + to + "]" + strings.TrimPrefix(t.Title, prefix)
+			}
+			t.FeatureBucket = to
+			r.run.Tickets++
+			if r.job == nil {
+				continue
+			}
+			if storage.IsMongoEnabled() {
+				err = storage.SaveTicketToMongo(ctx, t)
+			} else {
+				err = storage.SaveTicket(*t)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to save ticket %s: %w", t.TicketID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// ticketTarget returns where a date's ticket of bucket goes: its mapping,
+// or for a split the target most of the date's issues went to
+func (r *reclassifier) ticketTarget(date, bucket string) (string, bool) {
+	to, ok := r.plan.target(bucket)
+	if !ok {
+		return "", false
+	}
+	if _, split := r.plan.splits[bucket]; split && r.days[date] != nil {
+		most := 0
+		for _, target := range slices.Sorted(maps.Keys(r.days[date].moved[bucket])) {
+			if n := r.days[date].moved[bucket][target]; n > most {
+				to, most = target, n
+			}
+		}
+	}
+	return to, true
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/acoustic"
+	"im-ai-voice/internal/recording"
+)
+
+// ==================== CALL RECORDINGS ====================
+
+var ErrNoRecordingURL = errors.New("call has no recording URL")
+
+// FetchCallRecording downloads a call's audio from the call_recording_url
+// kept with its transcript, replacing any earlier download
+func (s *Service) FetchCallRecording(ctx context.Context, callID string) (*client.Recording, error) {
+	transcript, err := s.GetCallTranscript(ctx, callID)
+	if err != nil {
+		return nil, err
+	}
+	if transcript.RecordingURL == "" {
+		return nil, fmt.Errorf("%w: %s", ErrNoRecordingURL, callID)
+	}
+	return recording.Fetch(ctx, callID, transcript.SellerID, transcript.Timestamp, transcript.RecordingURL)
+}
+
+// fetchRecordingAsync downloads a processed call's audio in the background.
+// Failures are logged: the analysis doesn't depend on the audio.
+func fetchRecordingAsync(ctx context.Context, callID, sellerID string, ts time.Time, sourceURL string) {
+	go func() {
+		if _, err := recording.Fetch(context.WithoutCancel(ctx), callID, sellerID, ts, sourceURL); err != nil {
+			log.Printf("   ⚠️ Failed to fetch recording for call %s: %v", callID, err)
+		}
+	}()
+}
+
+// acousticSignals measures a call's audio, downloading it first if it was
+// never stored (audio deleted by retention stays deleted). Failures are
+// logged and the call is analyzed from text alone.
+func acousticSignals(ctx context.Context, callID, sellerID string, ts time.Time, sourceURL string) *client.AcousticSignals {
+	_, data, err := recording.Open(ctx, callID)
+	if errors.Is(err, recording.ErrNoRecording) {
+		if _, err = recording.Fetch(ctx, callID, sellerID, ts, sourceURL); err == nil {
+			_, data, err = recording.Open(ctx, callID)
+		}
+	}
+	if err != nil {
+		log.Printf("   ⚠️ No audio for acoustic signals on call %s: %v", callID, err)
+		return nil
+	}
+
+	signals, err := acoustic.Extract(data)
+	if err != nil {
+		log.Printf("   ⚠️ Acoustic signals failed for call %s: %v", callID, err)
+		return nil
+	}
+	return signals
+}
@@ -0,0 +1,418 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/llm"
+	"im-ai-voice/internal/profile"
+	"im-ai-voice/internal/storage"
+	"im-ai-voice/internal/watcher"
+)
+
+// ==================== REPLAY ====================
+// Rebuilds every derived record (analyses, profiles, aggregates, tickets)
+// from the raw transcripts. Existing analyses are first snapshotted into
+// LLM_CACHE_DIR and reused as the LLM output for their call, so a replay
+// only pays for Gemini on calls that were never analyzed. With Rescore,
+// calls with a stored extraction get a fresh scoring pass instead, which
+// doesn't resend the transcript. Throttled, a replay only runs off-peak
+// and can be resumed where it stopped (see backfill.go).
+
+// ReplayOptions controls a replay run
+type ReplayOptions struct {
+	DryRun  bool // Report what would happen, change nothing
+	Offline bool // Never call the LLM; calls without a cached analysis are skipped
+	Rescore bool // Re-run the scoring pass on stored extractions instead of reusing cached analyses
+	Resume  bool // Carry on from the last checkpoint instead of wiping derived data; Offline and Rescore must match it
+
+	Throttle *BackfillThrottle `json:"-"` // Only run when it allows, nil for straight through
+}
+
+// ReplayResult summarizes a replay run
+type ReplayResult struct {
+	Transcripts int      `json:"transcripts"`
+	FromCache   int      `json:"from_cache"`
+	Reanalyzed  int      `json:"reanalyzed"`
+	Rescored    int      `json:"rescored"`
+	Skipped     int      `json:"skipped"`
+	Resumed     int      `json:"resumed,omitempty"` // Calls replayed before the replay was resumed
+	Failed      int      `json:"failed"`
+	Profiles    int      `json:"profiles"`
+	Dates       []string `json:"dates"`
+	Tickets     int      `json:"tickets"`
+}
+
+// replayItem is one raw transcript queued for reprocessing
+type replayItem struct {
+	callID    string
+	gluserID  string
+	timestamp time.Time
+	raw       client.RawTranscript
+	ht        *client.HackathonTranscript // nil for transcripts ingested through the API
+}
+
+// Replay wipes derived state and reprocesses all raw transcripts in timestamp order
+func (s *Service) Replay(ctx context.Context, opts ReplayOptions) (*ReplayResult, error) {
+	if opts.DryRun {
+		return s.replay(ctx, opts, nil)
+	}
+	job := s.StartJob(ctx, client.JobTypeRebuild, "")
+	result, err := s.replay(ctx, opts, job)
+	job.Finish(ctx, "", err)
+	return result, err
+}
+
+// replay runs a replay, recording its progress in job unless it's a dry run
+func (s *Service) replay(ctx context.Context, opts ReplayOptions, job *JobTracker) (*ReplayResult, error) {
+	var checkpoint *replayCheckpoint
+	if opts.Resume {
+		var err error
+		if checkpoint, err = loadReplayCheckpoint(); err != nil {
+			return nil, fmt.Errorf("failed to read the replay checkpoint: %w", err)
+		}
+		if checkpoint == nil {
+			return nil, fmt.Errorf("no stopped replay to resume")
+		}
+		if opts.Offline != checkpoint.Options.Offline || opts.Rescore != checkpoint.Options.Rescore {
+			return nil, fmt.Errorf("the stopped replay was started with offline=%t, rescore=%t; resume it with the same", checkpoint.Options.Offline, checkpoint.Options.Rescore)
+		}
+		log.Printf("🔁 Replay: resuming after %d calls, from %s", checkpoint.Done, checkpoint.CallID)
+	}
+	if opts.Rescore && (opts.Offline || (s.ai == nil && !opts.DryRun)) {
+		return nil, fmt.Errorf("rescoring needs the LLM")
+	}
+
+	cache, err := snapshotLLMCache(ctx, opts.DryRun)
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot analyses: %w", err)
+	}
+	log.Printf("🔁 Replay: %d cached analyses available", len(cache))
+
+	items, err := loadReplayItems(cache)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load transcripts: %w", err)
+	}
+
+	// Blocked calls are analyzed again, safety settings may have changed
+	// since, and provisional ones get their full analysis
+	for id, a := range cache {
+		if a.Blocked || a.Provisional {
+			delete(cache, id)
+		}
+	}
+
+	extractions := map[string]*client.CallExtraction{}
+	if opts.Rescore {
+		if extractions, err = storage.LoadExtractions(ctx); err != nil {
+			return nil, fmt.Errorf("failed to load extractions: %w", err)
+		}
+		log.Printf("🔁 Replay: %d extractions to rescore", len(extractions))
+	}
+
+	result := &ReplayResult{Transcripts: len(items), Dates: []string{}}
+	if opts.DryRun {
+		for _, it := range items {
+			switch {
+			case checkpoint != nil && checkpoint.passed(it):
+				result.Resumed++
+			case extractions[it.callID] != nil:
+				result.Rescored++
+			case cache[it.callID] != nil:
+				result.FromCache++
+			case opts.Offline:
+				result.Skipped++
+			default:
+				result.Reanalyzed++
+			}
+		}
+		return result, nil
+	}
+
+	dates := make(map[string]bool)
+	if checkpoint == nil {
+		if err := storage.WipeDerivedData(ctx); err != nil {
+			return nil, fmt.Errorf("failed to wipe derived data: %w", err)
+		}
+		checkpoint = &replayCheckpoint{Options: opts, StartedAt: time.Now()}
+	} else {
+		for _, date := range checkpoint.Dates {
+			dates[date] = true
+		}
+	}
+
+	sellers := make(map[string]bool)
+	for i, it := range items {
+		if ctx.Err() != nil {
+			return result, ctx.Err()
+		}
+		if checkpoint.Done > 0 && checkpoint.passed(it) {
+			result.Resumed++
+			continue
+		}
+		if opts.Throttle != nil {
+			if err := opts.Throttle.wait(ctx); err != nil {
+				return result, err
+			}
+		}
+		s.replayOne(ctx, it, cache, extractions, opts, result, dates, sellers)
+
+		checkpoint.CallID, checkpoint.Timestamp = it.callID, it.timestamp
+		checkpoint.Done++
+		checkpoint.Dates = sortedKeys(dates)
+		if err := saveReplayCheckpoint(checkpoint); err != nil {
+			log.Printf("   ⚠️ Failed to save the replay checkpoint: %v", err)
+		}
+
+		job.Progress(ctx, i+1, len(items))
+		if (i+1)%50 == 0 {
+			log.Printf("   🔁 Replayed %d/%d calls", i+1, len(items))
+		}
+	}
+	result.Profiles = len(sellers)
+
+	result.Dates = sortedKeys(dates)
+	for _, date := range result.Dates {
+		if _, err := s.RunAggregation(ctx, date); err != nil {
+			log.Printf("   ⚠️ Replay aggregation failed for %s: %v", date, err)
+			continue
+		}
+		if tickets, err := s.GetTicketsForDate(ctx, date); err == nil {
+			result.Tickets += len(tickets)
+		}
+	}
+	if err := os.Remove(replayCheckpointPath()); err != nil && !os.IsNotExist(err) {
+		log.Printf("   ⚠️ Failed to remove the replay checkpoint: %v", err)
+	}
+
+	log.Printf("✅ Replay complete: %d calls (%d cached, %d re-analyzed, %d rescored, %d skipped, %d failed, %d before resuming), %d profiles, %d dates",
+		result.Transcripts, result.FromCache, result.Reanalyzed, result.Rescored, result.Skipped, result.Failed, result.Resumed, result.Profiles, len(result.Dates))
+	return result, nil
+}
+
+// replayOne reprocesses one call of a replay, counting the outcome in result
+func (s *Service) replayOne(ctx context.Context, it replayItem, cache map[string]*client.AnalysisResult, extractions map[string]*client.CallExtraction, opts ReplayOptions, result *ReplayResult, dates, sellers map[string]bool) {
+	if err := tokenizeTranscript(ctx, &it.raw); err != nil {
+		log.Printf("   ❌ Replay failed for %s: %v", it.callID, err)
+		result.Failed++
+		return
+	}
+
+	var err error
+	analysis := cache[it.callID]
+	if ext := extractions[it.callID]; ext != nil {
+		scoreCtx, cancel := withAnalysisDeadline(ctx, it.raw)
+		sellerContext, contextUsed := profile.BuildSellerContextFromProfile(ctx, it.gluserID, it.raw.Origin)
+		analysis, err = s.ai.ScoreCall(scoreCtx, it.raw, ext, sellerContext)
+		cancel()
+		if opts.Throttle != nil {
+			opts.Throttle.record(err != nil)
+		}
+		if err != nil {
+			log.Printf("   ❌ Replay scoring failed for %s: %v", it.callID, err)
+			result.Failed++
+			return
+		}
+		result.Rescored++
+		withSellerContext(analysis, contextUsed)
+		s.crossCheck(it.raw, analysis)
+	} else if analysis != nil {
+		result.FromCache++
+	} else if opts.Offline || s.ai == nil {
+		result.Skipped++
+		return
+	} else {
+		sellerContext, contextUsed := profile.BuildSellerContextFromProfile(ctx, it.gluserID, it.raw.Origin)
+		analysis, err = s.analyzeCall(ctx, it.raw, sellerContext)
+		if opts.Throttle != nil {
+			opts.Throttle.record(err != nil)
+		}
+		if err != nil {
+			log.Printf("   ❌ Replay analysis failed for %s: %v", it.callID, err)
+			result.Failed++
+			return
+		}
+		withSellerContext(analysis, contextUsed)
+		result.Reanalyzed++
+	}
+
+	analysis.CallID = it.callID
+	analysis.SellerID = it.gluserID
+	analysis.Timestamp = it.timestamp
+
+	if err := replayCall(ctx, analysis, it); err != nil {
+		log.Printf("   ❌ Replay failed for %s: %v", it.callID, err)
+		result.Failed++
+		return
+	}
+	if it.ht != nil {
+		sellers[it.gluserID] = true
+	}
+	dates[config.BusinessDate(it.timestamp)] = true
+}
+
+// sortedKeys returns a set's keys in order
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// replayCall runs the same persistence path the call originally took:
+// watcher transcripts update the seller profile, API transcripts only save the analysis
+func replayCall(ctx context.Context, analysis *client.AnalysisResult, it replayItem) error {
+	if it.ht == nil {
+		return storage.SaveAnalysis(ctx, *analysis)
+	}
+
+	enrichAnalysis(analysis, it.ht)
+	if analysis.Blocked {
+		return storage.SaveAnalysisWithGluserID(ctx, *analysis, it.gluserID, it.callID)
+	}
+	if _, _, err := profile.UpdateSellerProfile(ctx, it.gluserID, analysis, it.ht); err != nil {
+		return err
+	}
+	return storage.SaveAnalysisWithGluserID(ctx, *analysis, it.gluserID, it.callID)
+}
+
+// snapshotLLMCache copies every existing analysis into LLM_CACHE_DIR and
+// returns the cache keyed by call ID. Analyses cached by earlier replays
+// are included, so outputs survive a replay that was interrupted mid-way.
+func snapshotLLMCache(ctx context.Context, dryRun bool) (map[string]*client.AnalysisResult, error) {
+	if err := os.MkdirAll(config.LLM_CACHE_DIR, 0755); err != nil {
+		return nil, err
+	}
+
+	cache := make(map[string]*client.AnalysisResult)
+	add := func(ar client.AnalysisResult) {
+		if ar.CallID == "" {
+			return
+		}
+		a := ar
+		llm.NormalizeAnalysis(&a) // Analyses from before normalization would fail schema validation
+		cache[ar.CallID] = &a
+	}
+
+	cached, _ := filepath.Glob(filepath.Join(config.LLM_CACHE_DIR, "*.json"))
+	for _, f := range cached {
+		if ar, err := readAnalysisFile(f); err == nil {
+			add(*ar)
+		}
+	}
+
+	if storage.IsMongoEnabled() {
+		analyses, err := storage.GetAllAnalysesFromMongo(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, ar := range analyses {
+			add(ar)
+		}
+	}
+
+	files, err := storage.ListAnalysisFiles()
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		if ar, err := readAnalysisFile(f); err == nil {
+			add(*ar)
+		}
+	}
+
+	if dryRun {
+		return cache, nil
+	}
+	for callID, ar := range cache {
+		b, err := json.MarshalIndent(ar, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(filepath.Join(config.LLM_CACHE_DIR, storage.Sanitize(callID)+".json"), b, 0644); err != nil {
+			return nil, err
+		}
+	}
+	return cache, nil
+}
+
+func readAnalysisFile(path string) (*client.AnalysisResult, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var ar client.AnalysisResult
+	if err := json.Unmarshal(b, &ar); err != nil {
+		return nil, err
+	}
+	return &ar, nil
+}
+
+// loadReplayItems reads every raw transcript, in TRANSCRIPTS_DIR, the
+// TRANSCRIPT_SOURCES and PROCESSED_DIR, and orders them by call time.
+// The call time is the cached analysis timestamp when one exists, otherwise
+// the transcript's own date, otherwise the file modification time.
+func loadReplayItems(cache map[string]*client.AnalysisResult) ([]replayItem, error) {
+	var items []replayItem
+	sources := append(watcher.SourcesFromEnv(config.TRANSCRIPTS_DIR), watcher.Source{Dir: config.PROCESSED_DIR})
+	for _, f := range watcher.Scan(sources) {
+		data, err := os.ReadFile(f.Path)
+		if err != nil {
+			continue
+		}
+		info, err := os.Stat(f.Path)
+		if err != nil {
+			continue
+		}
+
+		var it replayItem
+		var ht client.HackathonTranscript
+		if json.Unmarshal(data, &ht) == nil && strings.TrimSpace(ht.Transcript) != "" {
+			f.Source.Tag(&ht)
+			ts := callTimestamp(&ht, info.ModTime())
+			it = replayItem{callID: ht.ClickToCallID, gluserID: ht.GluserID, timestamp: ts, ht: &ht}
+			it.raw = hackathonToRawTranscript(&ht, ts)
+		} else {
+			var rt client.RawTranscript
+			if err := json.Unmarshal(data, &rt); err != nil || strings.TrimSpace(rt.Transcript) == "" {
+				continue
+			}
+			if rt.CallID == "" {
+				rt.CallID = f.FileID
+			}
+			if rt.Timestamp.IsZero() {
+				rt.Timestamp = info.ModTime()
+			}
+			it = replayItem{callID: rt.CallID, gluserID: rt.SellerID, timestamp: rt.Timestamp, raw: rt}
+		}
+
+		if cached := cache[it.callID]; cached != nil && !cached.Timestamp.IsZero() {
+			it.timestamp = cached.Timestamp
+			it.raw.Timestamp = cached.Timestamp
+		}
+		// Moved transcripts no longer sit in the source that tagged them
+		if cached := cache[it.callID]; cached != nil && it.ht != nil && it.ht.Origin == nil {
+			it.ht.Origin = cached.Origin
+		}
+		items = append(items, it)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		if !items[i].timestamp.Equal(items[j].timestamp) {
+			return items[i].timestamp.Before(items[j].timestamp)
+		}
+		return items[i].callID < items[j].callID
+	})
+	return items, nil
+}
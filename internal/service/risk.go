@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/profile"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== RISK EXPLANATION ====================
+// Account managers act on health scores and churn risk they can't see into.
+// The explanation recomputes the health score from the profile factor by
+// factor, with the same weights, so its points add up to the stored score
+// unless the weights changed since (stale).
+// Churn risk is the scoring pass's judgment of the latest call, so it's
+// explained by that call's churn fields and the structured signals its
+// extraction found, alongside any override.
+
+// ExplainSellerRisk breaks a seller's current health score and churn risk
+// down into their factors
+func (s *Service) ExplainSellerRisk(ctx context.Context, gluserID string) (*client.RiskExplanation, error) {
+	p, err := storage.LoadSellerProfile(ctx, gluserID)
+	if err != nil {
+		return nil, fmt.Errorf("error loading profile: %w", err)
+	}
+	if p == nil {
+		return nil, fmt.Errorf("%w: %s", ErrSellerNotFound, gluserID)
+	}
+
+	status := p.CurrentStatus
+	factors, issues, score := profile.ExplainHealth(p)
+	e := &client.RiskExplanation{
+		GluserID:           gluserID,
+		HealthScore:        status.HealthScore,
+		HealthLabel:        status.HealthLabel,
+		BaseScore:          profile.HealthBase,
+		Factors:            factors,
+		UnclampedScore:     score,
+		Stale:              max(0, min(100, score)) != status.HealthScore,
+		Issues:             issues,
+		NeedsAttention:     status.NeedsAttention,
+		AttentionReason:    status.AttentionReason,
+		AttentionThreshold: profile.AttentionHealthFor(p.JourneyStage),
+		Churn: client.ChurnExplanation{
+			Risk:               status.ChurnRisk,
+			Source:             client.ChurnFromModel,
+			RenewalProbability: status.ChurnProbability,
+			Signals:            []client.RiskSignal{},
+		},
+		GeneratedAt: time.Now(),
+	}
+	if o := p.ChurnOverride; o.Active(time.Now()) {
+		e.Churn.Source, e.Churn.ModelRisk, e.Churn.Override = client.ChurnFromOverride, o.ModelValue, o
+	}
+
+	if len(p.CallHistory) == 0 {
+		return e, nil
+	}
+	e.CallID = p.CallHistory[0].CallID
+	if a, err := s.GetCallAnalysis(ctx, e.CallID); err == nil && a != nil {
+		e.Churn.RenewalAtRisk = a.Churn.RenewalAtRisk
+		e.Churn.Dissatisfaction = a.Churn.DissatisfactionLevel
+		e.Churn.Reason = a.Churn.ChurnReason
+		e.Churn.ReasonCategory = a.Churn.ChurnReasonCategory
+	}
+	exts, err := storage.LoadExtractionsFor(ctx, []string{e.CallID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load extraction: %w", err)
+	}
+	if len(exts) > 0 {
+		e.Churn.Signals = churnSignals(exts[0].Facts)
+	}
+	return e, nil
+}
+
+// churnSignals lists the churn signals stated in a call's facts
+func churnSignals(f client.CallFacts) []client.RiskSignal {
+	signals := []client.RiskSignal{}
+	for _, flag := range []struct {
+		name string
+		set  bool
+	}{
+		{"cancellation_threatened", f.CancellationThreatened},
+		{"refund_requested", f.RefundRequested},
+		{"pricing_complaint", f.PricingComplaint},
+		{"escalation_requested", f.EscalationRequested},
+		{"renewal_discussed", f.RenewalDiscussed},
+	} {
+		if flag.set {
+			signals = append(signals, client.RiskSignal{Signal: flag.name, Value: "true"})
+		}
+	}
+	if len(f.CompetitorsMentioned) > 0 {
+		signals = append(signals, client.RiskSignal{Signal: "competitors_mentioned", Value: strings.Join(f.CompetitorsMentioned, ", ")})
+	}
+	if f.BudgetSignals != "" {
+		signals = append(signals, client.RiskSignal{Signal: "budget_signals", Value: f.BudgetSignals})
+	}
+	if d := f.BillingDispute; d != nil {
+		signals = append(signals, client.RiskSignal{Signal: "billing_dispute", Value: fmt.Sprintf("charged Rs %.0f, expected Rs %.0f", d.Charged, d.Expected)})
+	}
+	return signals
+}
@@ -0,0 +1,400 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/llm"
+	"im-ai-voice/internal/notify"
+	"im-ai-voice/internal/storage"
+	"im-ai-voice/internal/ulid"
+)
+
+// ==================== CANARY ROLLOUTS ====================
+// A rollout routes Percent of production analyses to a candidate model
+// and/or prompt registry; those analyses are stored and used like any other,
+// marked with the rollout's ID. One rollout is active at a time. Each
+// instance rereads the active rollout every ROLLOUT_REFRESH_INTERVAL, and
+// picks calls by a hash of the rollout and call IDs, so every instance
+// routes a call the same way. Every 5 minutes the leader compares the
+// candidate's analyses since the rollout was created with the primary's
+// (from the analyzed events) and rolls it back, with an alert, once it has
+// MinCalls analyses and breaches a threshold.
+
+// AlertRolloutRolledBack is the alert type of an automatic rollback
+const AlertRolloutRolledBack = "rollout_rolled_back"
+
+var (
+	ErrInvalidRollout  = errors.New("invalid rollout")
+	ErrRolloutNotFound = errors.New("rollout not found")
+	ErrRolloutConflict = errors.New("rollout conflict")
+)
+
+// rolloutCache is this instance's copy of the active rollout and its client
+type rolloutCache struct {
+	mu       sync.Mutex
+	loadedAt time.Time
+	rollout  *client.Rollout
+	ai       *llm.AIClient // nil when the candidate client can't be built
+}
+
+// canary returns the client and rollout ID to analyze a call with when the
+// active rollout picked it, nil otherwise
+func (s *Service) canary(ctx context.Context, callID string) (*llm.AIClient, string) {
+	if s.ai == nil {
+		return nil, ""
+	}
+	c := &s.rollouts
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if time.Since(c.loadedAt) >= config.ROLLOUT_REFRESH_INTERVAL {
+		r, err := storage.LoadActiveRollout(ctx)
+		if err != nil {
+			log.Printf("⚠️ Failed to load the active rollout, keeping the last one: %v", err)
+		} else {
+			if r == nil || c.rollout == nil || r.ID != c.rollout.ID {
+				c.ai = nil
+				if r != nil {
+					if c.ai, err = llm.NewCandidateClient(s.ai, r.Model, r.PromptRegistry); err != nil {
+						log.Printf("⚠️ Rollout %s not routed on this instance: %v", r.ID, err)
+					}
+				}
+			}
+			c.rollout = r
+		}
+		c.loadedAt = time.Now()
+	}
+	if c.rollout == nil || c.ai == nil || !rolloutPicks(c.rollout, callID) {
+		return nil, ""
+	}
+	return c.ai, c.rollout.ID
+}
+
+// reloadRollout makes the next analysis reread the active rollout
+func (s *Service) reloadRollout() {
+	s.rollouts.mu.Lock()
+	s.rollouts.loadedAt = time.Time{}
+	s.rollouts.mu.Unlock()
+}
+
+func rolloutPicks(r *client.Rollout, callID string) bool {
+	h := fnv.New32a()
+	h.Write([]byte(r.ID + "/" + callID))
+	return int(h.Sum32()%100) < r.Percent
+}
+
+// ListRollouts returns every rollout, newest first
+func (s *Service) ListRollouts(ctx context.Context) ([]client.Rollout, error) {
+	return storage.LoadRollouts(ctx)
+}
+
+// GetRollout returns a rollout with its metrics evaluated now
+func (s *Service) GetRollout(ctx context.Context, id string) (*client.Rollout, error) {
+	r, err := storage.LoadRollout(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if r == nil {
+		return nil, fmt.Errorf("%w: %s", ErrRolloutNotFound, id)
+	}
+	if r.Metrics, err = evaluateRollout(ctx, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// CreateRollout starts routing a share of analyses to a candidate. It fails
+// while another rollout is active or paused.
+func (s *Service) CreateRollout(ctx context.Context, in client.RolloutRequest) (*client.Rollout, error) {
+	if s.ai == nil {
+		return nil, fmt.Errorf("%w: no AI client", ErrInvalidRollout)
+	}
+	in.Model = strings.TrimSpace(in.Model)
+	in.PromptRegistry = strings.TrimSpace(in.PromptRegistry)
+	if in.Percent < 1 || in.Percent > 100 {
+		return nil, fmt.Errorf("%w: percent must be 1-100", ErrInvalidRollout)
+	}
+	if _, err := llm.NewCandidateClient(s.ai, in.Model, in.PromptRegistry); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRollout, err)
+	}
+	thresholds, err := rolloutThresholds(in.Thresholds)
+	if err != nil {
+		return nil, err
+	}
+
+	rollouts, err := storage.LoadRollouts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rollouts {
+		if r.Status == client.RolloutActive || r.Status == client.RolloutPaused {
+			return nil, fmt.Errorf("%w: rollout %s is %s; complete or roll it back first", ErrRolloutConflict, r.ID, r.Status)
+		}
+	}
+
+	now := time.Now()
+	r := &client.Rollout{
+		ID:             "ro_" + ulid.NewAt(now),
+		Model:          in.Model,
+		PromptRegistry: in.PromptRegistry,
+		Percent:        in.Percent,
+		Status:         client.RolloutActive,
+		Thresholds:     thresholds,
+		By:             in.By,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if err := storage.SaveRollout(ctx, r); err != nil {
+		return nil, err
+	}
+	s.reloadRollout()
+	log.Printf("🐤 Rollout %s: %d%% of analyses to %s (started by %s)", r.ID, r.Percent, rolloutCandidate(r), orAnonymous(r.By))
+	return r, nil
+}
+
+// UpdateRollout changes a rollout's share of analyses and/or status. Rolled
+// back and completed rollouts are final.
+func (s *Service) UpdateRollout(ctx context.Context, id string, in client.RolloutUpdate) (*client.Rollout, error) {
+	r, err := storage.LoadRollout(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if r == nil {
+		return nil, fmt.Errorf("%w: %s", ErrRolloutNotFound, id)
+	}
+	if r.EndedAt != nil {
+		return nil, fmt.Errorf("%w: rollout %s is %s", ErrRolloutConflict, id, r.Status)
+	}
+	if in.Percent == nil && in.Status == "" {
+		return nil, fmt.Errorf("%w: set percent or status", ErrInvalidRollout)
+	}
+	if in.Percent != nil {
+		if *in.Percent < 1 || *in.Percent > 100 {
+			return nil, fmt.Errorf("%w: percent must be 1-100", ErrInvalidRollout)
+		}
+		r.Percent = *in.Percent
+	}
+
+	now := time.Now()
+	switch in.Status {
+	case "", r.Status:
+	case client.RolloutActive, client.RolloutPaused:
+		r.Status = in.Status
+	case client.RolloutRolledBack, client.RolloutCompleted:
+		r.Status = in.Status
+		r.EndedAt = &now
+	default:
+		return nil, fmt.Errorf("%w: unknown status %q", ErrInvalidRollout, in.Status)
+	}
+	if in.Reason != "" {
+		r.Reason = in.Reason
+	}
+	r.UpdatedAt = now
+	if r.Metrics, err = evaluateRollout(ctx, r); err != nil {
+		log.Printf("⚠️ Failed to evaluate rollout %s: %v", id, err)
+	}
+	if err := storage.SaveRollout(ctx, r); err != nil {
+		return nil, err
+	}
+	s.reloadRollout()
+	log.Printf("🐤 Rollout %s: %s at %d%% (by %s)", r.ID, r.Status, r.Percent, orAnonymous(in.By))
+	return r, nil
+}
+
+// CheckRollouts evaluates the active rollout and rolls it back if it breaches
+// a threshold. It returns the rollout, nil without an active one.
+func (s *Service) CheckRollouts(ctx context.Context) (*client.Rollout, error) {
+	r, err := storage.LoadActiveRollout(ctx)
+	if err != nil || r == nil {
+		return nil, err
+	}
+	if r.Metrics, err = evaluateRollout(ctx, r); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	breaches := r.Metrics.Breaches
+	if len(breaches) > 0 {
+		r.Status = client.RolloutRolledBack
+		r.Reason = "automatic rollback: " + strings.Join(breaches, "; ")
+		r.EndedAt = &now
+		r.UpdatedAt = now
+	}
+	if err := storage.SaveRollout(ctx, r); err != nil {
+		return nil, err
+	}
+	if len(breaches) > 0 {
+		s.reloadRollout()
+		notify.FireAlert(ctx, client.Alert{
+			Type:     AlertRolloutRolledBack,
+			Severity: "high",
+			Message:  fmt.Sprintf("Rollout %s of %s rolled back: %s", r.ID, rolloutCandidate(r), strings.Join(breaches, "; ")),
+			Details: map[string]interface{}{
+				"rollout_id":      r.ID,
+				"model":           r.Model,
+				"prompt_registry": r.PromptRegistry,
+				"percent":         r.Percent,
+				"breaches":        breaches,
+				"candidate_calls": r.Metrics.Candidate.Calls,
+			},
+		})
+	}
+	return r, nil
+}
+
+// rolloutThresholds fills in the defaults of the thresholds left at zero
+func rolloutThresholds(in *client.RolloutThresholds) (client.RolloutThresholds, error) {
+	t := client.RolloutThresholds{
+		MinCalls:              config.DEFAULT_ROLLOUT_MIN_CALLS,
+		MaxParseFailureRate:   config.DEFAULT_ROLLOUT_MAX_PARSE_FAILURE,
+		MaxSatisfactionDelta:  config.DEFAULT_ROLLOUT_MAX_SATISFACTION,
+		MaxIssueCountDelta:    config.DEFAULT_ROLLOUT_MAX_ISSUE_COUNT,
+		MaxNegativeShareDelta: config.DEFAULT_ROLLOUT_MAX_NEGATIVE,
+	}
+	if in == nil {
+		return t, nil
+	}
+	if in.MinCalls < 0 || in.MaxParseFailureRate < 0 || in.MaxSatisfactionDelta < 0 || in.MaxIssueCountDelta < 0 || in.MaxNegativeShareDelta < 0 {
+		return t, fmt.Errorf("%w: thresholds can't be negative", ErrInvalidRollout)
+	}
+	if in.MinCalls > 0 {
+		t.MinCalls = in.MinCalls
+	}
+	if in.MaxParseFailureRate > 0 {
+		t.MaxParseFailureRate = in.MaxParseFailureRate
+	}
+	if in.MaxSatisfactionDelta > 0 {
+		t.MaxSatisfactionDelta = in.MaxSatisfactionDelta
+	}
+	if in.MaxIssueCountDelta > 0 {
+		t.MaxIssueCountDelta = in.MaxIssueCountDelta
+	}
+	if in.MaxNegativeShareDelta > 0 {
+		t.MaxNegativeShareDelta = in.MaxNegativeShareDelta
+	}
+	return t, nil
+}
+
+func rolloutCandidate(r *client.Rollout) string {
+	var parts []string
+	if r.Model != "" {
+		parts = append(parts, "model "+r.Model)
+	}
+	if r.PromptRegistry != "" {
+		parts = append(parts, "prompts "+r.PromptRegistry)
+	}
+	return strings.Join(parts, " + ")
+}
+
+// rolloutArm accumulates the analyzed events of one side of a rollout
+type rolloutArm struct {
+	calls, parseFailures, parsed, negative, issues int
+	satTotal, satCalls                             int
+}
+
+func (a *rolloutArm) add(p client.AnalyzedPayload) {
+	a.calls++
+	if p.ParseFailed {
+		a.parseFailures++
+		return
+	}
+	a.parsed++
+	a.issues += p.IssueCount
+	if p.Sentiment == "Negative" {
+		a.negative++
+	}
+	if p.SatisfactionScore > 0 {
+		a.satTotal += p.SatisfactionScore
+		a.satCalls++
+	}
+}
+
+func (a *rolloutArm) summary() client.RolloutArm {
+	ratio := func(n, d int) float64 {
+		if d == 0 {
+			return 0
+		}
+		return math.Round(float64(n)/float64(d)*1000) / 1000
+	}
+	return client.RolloutArm{
+		Calls:            a.calls,
+		ParseFailures:    a.parseFailures,
+		ParseFailureRate: ratio(a.parseFailures, a.calls),
+		MeanSatisfaction: ratio(a.satTotal, a.satCalls),
+		MeanIssueCount:   ratio(a.issues, a.parsed),
+		NegativeShare:    ratio(a.negative, a.parsed),
+	}
+}
+
+// evaluateRollout compares the candidate's analyses since the rollout was
+// created with the primary's over the same time, and lists the thresholds
+// they breach
+func evaluateRollout(ctx context.Context, r *client.Rollout) (*client.RolloutMetrics, error) {
+	var candidate, primary rolloutArm
+	q := storage.EventQuery{Type: client.EventAnalyzed, Since: r.CreatedAt, Limit: 1000}
+	for {
+		page, err := storage.QueryEvents(ctx, q)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read analyzed events: %w", err)
+		}
+		for _, e := range page.Events {
+			if r.EndedAt != nil && e.Timestamp.After(*r.EndedAt) {
+				continue
+			}
+			var p client.AnalyzedPayload
+			b, err := json.Marshal(e.Payload)
+			if err != nil || json.Unmarshal(b, &p) != nil {
+				continue
+			}
+			switch p.Rollout {
+			case r.ID:
+				candidate.add(p)
+			case "":
+				primary.add(p)
+			}
+		}
+		if !page.HasMore {
+			break
+		}
+		q.Cursor = page.NextCursor
+	}
+
+	m := &client.RolloutMetrics{Candidate: candidate.summary(), Primary: primary.summary(), EvaluatedAt: time.Now()}
+	compared := candidate.parsed > 0 && primary.parsed > 0
+	if compared {
+		round := func(v float64) float64 { return math.Round(v*1000) / 1000 }
+		m.SatisfactionDelta = round(m.Candidate.MeanSatisfaction - m.Primary.MeanSatisfaction)
+		m.IssueCountDelta = round(m.Candidate.MeanIssueCount - m.Primary.MeanIssueCount)
+		m.NegativeShareDelta = round(m.Candidate.NegativeShare - m.Primary.NegativeShare)
+	}
+
+	t := r.Thresholds
+	if candidate.calls < t.MinCalls {
+		return m, nil
+	}
+	if m.Candidate.ParseFailureRate > t.MaxParseFailureRate {
+		m.Breaches = append(m.Breaches, fmt.Sprintf("parse failure rate %.1f%% over %.1f%%", m.Candidate.ParseFailureRate*100, t.MaxParseFailureRate*100))
+	}
+	if !compared {
+		return m, nil
+	}
+	if math.Abs(m.SatisfactionDelta) > t.MaxSatisfactionDelta {
+		m.Breaches = append(m.Breaches, fmt.Sprintf("satisfaction delta %+.2f beyond ±%.2f", m.SatisfactionDelta, t.MaxSatisfactionDelta))
+	}
+	if math.Abs(m.IssueCountDelta) > t.MaxIssueCountDelta {
+		m.Breaches = append(m.Breaches, fmt.Sprintf("issue count delta %+.2f beyond ±%.2f", m.IssueCountDelta, t.MaxIssueCountDelta))
+	}
+	if math.Abs(m.NegativeShareDelta) > t.MaxNegativeShareDelta {
+		m.Breaches = append(m.Breaches, fmt.Sprintf("negative share delta %+.3f beyond ±%.3f", m.NegativeShareDelta, t.MaxNegativeShareDelta))
+	}
+	return m, nil
+}
@@ -0,0 +1,267 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/storage"
+	"im-ai-voice/internal/ticket"
+	"im-ai-voice/internal/ulid"
+	"im-ai-voice/internal/vault"
+)
+
+// ==================== CALL SAMPLES ====================
+// Trainers and sales want real calls to show new support agents and
+// prospects. A sample is generated from an analyzed call on request: its
+// transcript_en and the text of its analysis go through the redaction
+// pipeline (vault tokens reduced to their kind, the seller's ID, agent ID
+// and city replaced, then the ticket redaction of phones, emails, names,
+// GSTINs, PANs and TICKET_REDACT_TERMS), and everything identifying is
+// dropped. The patterns miss things, so a sample waits for a reviewer to
+// approve it before GET /samples serves it, and its source call is only
+// shown to reviewers. Sellers who opted out of quotes aren't sampled, and
+// opting out deletes their samples.
+
+const (
+	samplesDefaultLimit = 50
+	samplesMaxLimit     = 500
+)
+
+var (
+	ErrInvalidSample  = errors.New("invalid sample")
+	ErrSampleNotFound = errors.New("sample not found")
+	ErrSampleExists   = errors.New("call already has a sample")
+)
+
+// Identifiers the ticket redaction doesn't cover, masked as the vault
+// tokenizes them
+var sampleIDPatterns = []sampleMask{
+	{vault.GSTINPattern, "[gstin]"},
+	{vault.PANPattern, "[pan]"},
+}
+
+// ticketMasks are what ticket.Redact puts in place of what it masks
+var ticketMasks = []string{"[email]", "[phone]", "[name]", "[redacted]"}
+
+// CreateSample generates a pending sample from an analyzed call
+func (s *Service) CreateSample(ctx context.Context, in client.SampleRequest) (*client.CallSample, error) {
+	in.CallID = strings.TrimSpace(in.CallID)
+	if in.CallID == "" {
+		return nil, fmt.Errorf("%w: call_id is required", ErrInvalidSample)
+	}
+	analysis, err := s.GetCallAnalysis(ctx, in.CallID)
+	if err != nil || analysis == nil {
+		return nil, fmt.Errorf("%w: no analysis of call %s", ErrInvalidSample, in.CallID)
+	}
+	switch {
+	case analysis.Blocked:
+		return nil, fmt.Errorf("%w: call %s was blocked by Gemini's safety filters", ErrInvalidSample, in.CallID)
+	case analysis.Provisional:
+		return nil, fmt.Errorf("%w: call %s only has a provisional analysis", ErrInvalidSample, in.CallID)
+	case strings.TrimSpace(analysis.TranscriptEn) == "":
+		return nil, fmt.Errorf("%w: call %s has no English transcript", ErrInvalidSample, in.CallID)
+	}
+
+	existing, err := storage.LoadCallSamples(ctx, storage.SampleQuery{CallID: in.CallID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load samples: %w", err)
+	}
+	if len(existing) > 0 {
+		return nil, fmt.Errorf("%w: %s is %s", ErrSampleExists, existing[0].ID, existing[0].Status)
+	}
+	var profile *client.SellerProfile
+	if analysis.SellerID != "" {
+		optOut, err := storage.LoadQuoteOptOut(ctx, analysis.SellerID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load quote opt-out: %w", err)
+		}
+		if optOut != nil {
+			return nil, fmt.Errorf("%w: the seller opted out of being quoted", ErrInvalidSample)
+		}
+		if profile, err = storage.LoadSellerProfile(ctx, analysis.SellerID); err != nil {
+			log.Printf("   ⚠️ Failed to load profile of %s for a sample: %v", analysis.SellerID, err)
+		}
+	}
+
+	sample := anonymizeCall(analysis, profile)
+	sample.ID = ulid.New()
+	sample.Status = client.SamplePending
+	sample.Note = strings.TrimSpace(in.Note)
+	sample.CreatedBy = in.By
+	sample.CreatedAt = time.Now()
+	if err := storage.SaveCallSample(ctx, sample); err != nil {
+		return nil, err
+	}
+	log.Printf("🎓 Sample %s generated from call %s, %d details masked (by %s)", sample.ID, in.CallID, sample.Redactions, orAnonymous(in.By))
+	return sample, nil
+}
+
+// ListSamples returns the samples in a review state matching q, newest
+// first. Approved ones are returned without their source call.
+func (s *Service) ListSamples(ctx context.Context, q storage.SampleQuery, limit int) (*client.SampleList, error) {
+	if q.Status == "" {
+		q.Status = client.SampleApproved
+	}
+	if !slices.Contains(client.SampleStatuses, q.Status) {
+		return nil, fmt.Errorf("%w: status must be one of %s", ErrInvalidSample, strings.Join(client.SampleStatuses, ", "))
+	}
+	if limit <= 0 {
+		limit = samplesDefaultLimit
+	}
+	if limit > samplesMaxLimit {
+		limit = samplesMaxLimit
+	}
+
+	samples, err := storage.LoadCallSamples(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	if len(samples) > limit {
+		samples = samples[:limit]
+	}
+	if q.Status == client.SampleApproved {
+		for i := range samples {
+			samples[i].CallID, samples[i].GluserID = "", ""
+		}
+	}
+	return &client.SampleList{Status: q.Status, Samples: samples, Count: len(samples)}, nil
+}
+
+// ReviewSample approves or rejects a sample, or puts it back to pending
+func (s *Service) ReviewSample(ctx context.Context, id string, in client.SampleReview) (*client.CallSample, error) {
+	if !slices.Contains(client.SampleStatuses, in.Status) {
+		return nil, fmt.Errorf("%w: status must be one of %s", ErrInvalidSample, strings.Join(client.SampleStatuses, ", "))
+	}
+	sample, err := storage.LoadCallSample(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if sample == nil {
+		return nil, fmt.Errorf("%w: %s", ErrSampleNotFound, id)
+	}
+
+	sample.Status = in.Status
+	if note := strings.TrimSpace(in.Note); note != "" {
+		sample.Note = note
+	}
+	if in.Status == client.SamplePending {
+		sample.ReviewedBy, sample.ReviewedAt = "", nil
+	} else {
+		now := time.Now()
+		sample.ReviewedBy, sample.ReviewedAt = in.By, &now
+	}
+	if err := storage.SaveCallSample(ctx, sample); err != nil {
+		return nil, err
+	}
+	log.Printf("🎓 Sample %s %s (by %s)", id, in.Status, orAnonymous(in.By))
+	return sample, nil
+}
+
+// DeleteSample removes a sample
+func (s *Service) DeleteSample(ctx context.Context, id string) error {
+	found, err := storage.DeleteCallSample(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("%w: %s", ErrSampleNotFound, id)
+	}
+	log.Printf("🎓 Sample %s deleted", id)
+	return nil
+}
+
+// anonymizeCall builds a sample's content from a call's analysis, profile
+// nil when the seller has none
+func anonymizeCall(analysis *client.AnalysisResult, profile *client.SellerProfile) *client.CallSample {
+	r := &sampleRedactor{}
+	r.literal(analysis.SellerID, "[seller]")
+	r.literal(analysis.AgentID, "[agent]")
+	sample := &client.CallSample{
+		CallID:    analysis.CallID,
+		GluserID:  analysis.SellerID,
+		Sentiment: analysis.Intent.Sentiment,
+		Channel:   analysis.Channel,
+		Language:  analysis.OriginalLang,
+	}
+	if profile != nil {
+		r.literal(profile.CityName, "[city]")
+		sample.CustomerType = profile.CustomerType
+		sample.Vertical = profile.Vertical
+	}
+
+	sample.Transcript = r.redact(analysis.TranscriptEn)
+	sample.Analysis = client.SampleAnalysis{
+		Summary:           r.redact(analysis.CallSummary),
+		Issues:            make([]client.SampleIssue, 0, len(analysis.Issues)),
+		Sentiment:         analysis.Intent.Sentiment,
+		SatisfactionScore: analysis.Intent.SatisfactionScore,
+		PromptResolution:  analysis.Intent.PromptResolution,
+		ChurnRisk:         analysis.Churn.IsLikelyToChurn,
+		AgentPerformance:  analysis.AgentPerformance,
+	}
+	top := 0
+	for _, issue := range analysis.Issues {
+		si := client.SampleIssue{
+			Bucket:            issue.Bucket,
+			Severity:          issue.Severity,
+			Problem:           r.redact(issue.Problem),
+			ActionableSummary: r.redact(issue.ActionableSummary),
+		}
+		for _, e := range issue.Evidence {
+			si.Evidence = append(si.Evidence, r.redact(e.Quote))
+		}
+		sample.Analysis.Issues = append(sample.Analysis.Issues, si)
+		if sev := quoteSeverities[strings.ToLower(issue.Severity)]; sample.FeatureBucket == "" || sev > top {
+			sample.FeatureBucket, top = issue.Bucket, sev
+		}
+	}
+	sample.Redactions = r.masked
+	return sample
+}
+
+// sampleRedactor masks personal details in a sample's text, counting them
+type sampleRedactor struct {
+	literals []sampleMask // Known values to replace, whole words ignoring case
+	masked   int
+}
+
+type sampleMask struct {
+	pattern *regexp.Regexp
+	mask    string
+}
+
+func (r *sampleRedactor) literal(value, mask string) {
+	if value = strings.TrimSpace(value); len(value) >= 3 {
+		r.literals = append(r.literals, sampleMask{regexp.MustCompile(`(?i)\b` + regexp.QuoteMeta(value) + `\b`), mask})
+	}
+}
+
+func (r *sampleRedactor) redact(text string) string {
+	text, n := vault.Mask(text)
+	r.masked += n
+	for _, p := range slices.Concat(r.literals, sampleIDPatterns) {
+		text = p.pattern.ReplaceAllStringFunc(text, func(string) string {
+			r.masked++
+			return p.mask
+		})
+	}
+	before := countMasks(text)
+	text = ticket.Redact(text)
+	r.masked += countMasks(text) - before
+	return text
+}
+
+func countMasks(text string) int {
+	n := 0
+	for _, m := range ticketMasks {
+		n += strings.Count(text, m)
+	}
+	return n
+}
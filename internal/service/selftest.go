@@ -0,0 +1,315 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/aggregate"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/profile"
+	"im-ai-voice/internal/storage"
+	"im-ai-voice/internal/ticket"
+	"im-ai-voice/internal/ulid"
+)
+
+// ==================== SELF-TEST ====================
+// After a deployment, SelfTest runs a few synthetic calls through the
+// pipeline against the live storage and reports which stage breaks: ingest,
+// analysis, profile, aggregate, ticket. The calls belong to a seller of
+// their own (selftest_...) and are placed on selfTestDate, a business day
+// long before any real data, so the aggregate and tickets built are theirs
+// alone; everything stored is removed at the end. Nothing leaves the
+// pipeline: no webhooks, alerts, GitHub issues or ticket routing. Only the
+// append-only event log and seller metrics keep a trace of the seller.
+
+var (
+	ErrInvalidSelfTest = errors.New("invalid self-test")
+	ErrSelfTestRunning = errors.New("a self-test is already running")
+)
+
+// selfTestDate is the reserved date self-test calls are placed on
+const selfTestDate = "2000-01-03"
+
+// selfTestTranscripts are the synthetic calls, each raising the same lead
+// quality complaint so the aggregate has a bucket big enough for a ticket
+var selfTestTranscripts = []string{
+	"Agent: Namaste, IndiaMART se baat kar rahe hain. Seller: Haan ji, mujhe fake leads aa rahe hain, sab irrelevant enquiry hai. Agent: Main check karta hoon.",
+	"Agent: Namaste, pichli complaint ke baare mein call kiya hai. Seller: Abhi bhi fake lead aa rahi hai, koi genuine buyer nahi hai. Agent: Team ko escalate kar diya hai.",
+	"Agent: Namaste, lead quality ke baare mein update dena tha. Seller: Kal bhi do fake lead aaye, bahut pareshan hoon. Agent: Hum filter set kar dete hain.",
+}
+
+// selfTestRunning keeps self-tests from sharing selfTestDate
+var selfTestRunning sync.Mutex
+
+// SelfTest runs the synthetic calls through the pipeline, analyzed by Gemini
+// with gemini and by the keyword classifier otherwise
+func (s *Service) SelfTest(ctx context.Context, gemini bool) (*client.SelfTestReport, error) {
+	if gemini && s.ai == nil {
+		return nil, fmt.Errorf("%w: AI client not configured (ANALYSIS_ENGINE=rules)", ErrInvalidSelfTest)
+	}
+	if !selfTestRunning.TryLock() {
+		return nil, ErrSelfTestRunning
+	}
+	defer selfTestRunning.Unlock()
+
+	start := time.Now()
+	day, err := config.ParseBusinessDate(selfTestDate)
+	if err != nil {
+		return nil, err
+	}
+	t := &selfTest{
+		svc:    s,
+		gemini: gemini,
+		seller: "selftest_" + ulid.NewAt(start),
+		day:    day,
+		report: &client.SelfTestReport{LLM: client.EngineRules, Date: selfTestDate},
+	}
+	if gemini {
+		t.report.LLM = "gemini"
+	}
+	t.report.SellerID = t.seller
+
+	// Stages run in order, each needing the one before
+	t.stage(ctx, client.SelfTestIngest, t.ingest)
+	t.stage(ctx, client.SelfTestAnalysis, t.analyze)
+	t.stage(ctx, client.SelfTestProfile, t.buildProfile)
+	t.stage(ctx, client.SelfTestAggregate, t.aggregate)
+	t.stage(ctx, client.SelfTestTicket, t.generateTickets)
+
+	// Cleanup runs whatever failed, and needs time of its own if the
+	// request's deadline is what failed
+	cleanupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+	defer cancel()
+	t.failed = false
+	t.stage(cleanupCtx, client.SelfTestCleanup, t.cleanup)
+
+	t.report.OK = !slices.ContainsFunc(t.report.Stages, func(st client.SelfTestStage) bool { return st.Status != client.SelfTestPass })
+	t.report.DurationMS = time.Since(start).Milliseconds()
+	if t.report.OK {
+		log.Printf("🩺 Self-test passed in %dms (%s)", t.report.DurationMS, t.report.LLM)
+	} else {
+		log.Printf("⚠️ Self-test failed (%s): %+v", t.report.LLM, t.report.Stages)
+	}
+	return t.report, nil
+}
+
+// selfTest is one run of SelfTest
+type selfTest struct {
+	svc    *Service
+	gemini bool
+	seller string
+	day    time.Time // Start of selfTestDate
+	report *client.SelfTestReport
+	failed bool // A stage failed, later ones are skipped
+
+	calls    []*client.RawTranscript // Stored by ingest
+	analyses []*client.AnalysisResult
+	profiled bool // The seller has a profile to remove
+	agg      *client.DailyAggregate
+	tickets  []client.Ticket
+}
+
+// stage runs fn as the named stage, skipping it once a stage failed. fn
+// returns what it verified.
+func (t *selfTest) stage(ctx context.Context, name string, fn func(context.Context) (string, error)) {
+	st := client.SelfTestStage{Name: name, Status: client.SelfTestSkip}
+	if !t.failed {
+		start := time.Now()
+		detail, err := fn(ctx)
+		st.DurationMS = time.Since(start).Milliseconds()
+		st.Status, st.Detail = client.SelfTestPass, detail
+		if err != nil {
+			st.Status, st.Error = client.SelfTestFail, err.Error()
+			t.failed = true
+		}
+	}
+	t.report.Stages = append(t.report.Stages, st)
+}
+
+// ingest stores the synthetic transcripts as the API does and reads them back
+func (t *selfTest) ingest(ctx context.Context) (string, error) {
+	for i, text := range selfTestTranscripts {
+		rt := client.RawTranscript{
+			CallID:     fmt.Sprintf("%s_%d", t.seller, i+1),
+			SellerID:   t.seller,
+			Transcript: text,
+			Language:   "hi-en",
+			DurationMS: 90_000,
+			Timestamp:  t.day.Add(10*time.Hour + time.Duration(i)*time.Hour),
+		}
+		if err := tokenizeTranscript(ctx, &rt); err != nil {
+			return "", err
+		}
+		callID, err := storage.SaveRawTranscript(rt)
+		if err != nil {
+			return "", fmt.Errorf("failed to save transcript: %w", err)
+		}
+		t.calls = append(t.calls, &rt)
+		stored, err := storage.LoadRawTranscript(callID)
+		if err != nil {
+			return "", err
+		}
+		if stored.Transcript != rt.Transcript || !stored.Timestamp.Equal(rt.Timestamp) {
+			return "", fmt.Errorf("transcript %s read back differs from the one stored", callID)
+		}
+	}
+	return fmt.Sprintf("%d transcripts stored and read back", len(t.calls)), nil
+}
+
+// analyze analyzes the transcripts one by one, each with the context of the
+// profile the previous ones built, and reads the analyses back
+func (t *selfTest) analyze(ctx context.Context) (string, error) {
+	issues := 0
+	for _, rt := range t.calls {
+		sellerContext := ""
+		if len(t.analyses) > 0 {
+			if err := t.updateProfile(ctx, t.analyses[len(t.analyses)-1]); err != nil {
+				return "", err
+			}
+			sellerContext, _ = profile.BuildSellerContextFromProfile(ctx, t.seller, nil)
+		}
+
+		var analysis *client.AnalysisResult
+		if t.gemini {
+			a, _, err := t.svc.ai.AnalyzeCall(ctx, *rt, sellerContext)
+			if err != nil {
+				log.Printf("⚠️ Self-test analysis of %s failed: %v", rt.CallID, err)
+				return "", errors.New("the LLM request failed") // err can carry Gemini's URL and response
+			}
+			analysis = a
+		} else {
+			analysis = t.svc.rules.Classify(*rt)
+		}
+		analysis.LLMRaw = nil // Kept apart and expiring otherwise, out of cleanup's reach
+
+		if err := storage.SaveAnalysisWithGluserID(ctx, *analysis, t.seller, rt.CallID); err != nil {
+			return "", fmt.Errorf("failed to save analysis: %w", err)
+		}
+		t.analyses = append(t.analyses, analysis)
+		stored, err := t.svc.GetCallAnalysis(ctx, rt.CallID)
+		if err != nil {
+			return "", fmt.Errorf("failed to read analysis %s back: %w", rt.CallID, err)
+		}
+		if stored.CallID != rt.CallID || len(stored.Issues) != len(analysis.Issues) {
+			return "", fmt.Errorf("analysis %s read back differs from the one stored", rt.CallID)
+		}
+		issues += len(analysis.Issues)
+	}
+	return fmt.Sprintf("%d calls analyzed, %d issues", len(t.analyses), issues), nil
+}
+
+// updateProfile adds an analysis to the seller's profile as the pipeline
+// does, without its notifications
+func (t *selfTest) updateProfile(ctx context.Context, analysis *client.AnalysisResult) error {
+	t.profiled = true
+	if _, _, err := profile.UpdateSellerProfile(ctx, t.seller, analysis, nil); err != nil {
+		return err
+	}
+	return nil
+}
+
+// buildProfile adds the last analysis to the profile and checks it counts
+// every call
+func (t *selfTest) buildProfile(ctx context.Context) (string, error) {
+	if err := t.updateProfile(ctx, t.analyses[len(t.analyses)-1]); err != nil {
+		return "", err
+	}
+	sp, err := storage.LoadSellerProfile(ctx, t.seller)
+	if err != nil {
+		return "", err
+	}
+	if sp == nil {
+		return "", errors.New("the profile wasn't stored")
+	}
+	if sp.TotalCalls != len(t.analyses) {
+		return "", fmt.Errorf("the profile counts %d calls, want %d", sp.TotalCalls, len(t.analyses))
+	}
+	return fmt.Sprintf("%d calls, %d active issues", sp.TotalCalls, len(sp.ActiveIssues)), nil
+}
+
+// aggregate aggregates selfTestDate, stores the aggregate and reads it back
+func (t *selfTest) aggregate(ctx context.Context) (string, error) {
+	analyses, err := t.svc.loadAnalysesForDate(ctx, selfTestDate)
+	if err != nil {
+		return "", err
+	}
+	if len(analyses) != len(t.analyses) {
+		return "", fmt.Errorf("found %d analyses for %s, want %d", len(analyses), selfTestDate, len(t.analyses))
+	}
+	t.agg = aggregate.Build(selfTestDate, analyses)
+	if storage.IsMongoEnabled() {
+		err = storage.SaveAggregateToMongo(ctx, t.agg)
+	} else {
+		err = storage.SaveAggregate(*t.agg)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to save aggregate: %w", err)
+	}
+	stored, err := t.svc.GetDailyAggregate(ctx, selfTestDate)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the aggregate back: %w", err)
+	}
+	if stored.TotalCalls != len(t.analyses) {
+		return "", fmt.Errorf("the aggregate counts %d calls, want %d", stored.TotalCalls, len(t.analyses))
+	}
+	return fmt.Sprintf("%d calls, %d issues in %d buckets", stored.TotalCalls, stored.TotalIssues, len(stored.FeatureBuckets)), nil
+}
+
+// generateTickets generates the aggregate's tickets, stores them and reads
+// them back
+func (t *selfTest) generateTickets(ctx context.Context) (string, error) {
+	t.tickets = ticket.Generate(selfTestDate, t.agg, nil, nil)
+	if len(t.tickets) == 0 {
+		return "", fmt.Errorf("no ticket generated from %d issues", t.agg.TotalIssues)
+	}
+	for i := range t.tickets {
+		var err error
+		if storage.IsMongoEnabled() {
+			err = storage.SaveTicketToMongo(ctx, &t.tickets[i])
+		} else {
+			err = storage.SaveTicket(t.tickets[i])
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to save ticket: %w", err)
+		}
+	}
+	stored, err := t.svc.GetTicketsForDate(ctx, selfTestDate)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the tickets back: %w", err)
+	}
+	if len(stored) != len(t.tickets) {
+		return "", fmt.Errorf("read back %d tickets, want %d", len(stored), len(t.tickets))
+	}
+	return fmt.Sprintf("%d tickets, first: %s", len(stored), stored[0].Title), nil
+}
+
+// cleanup removes everything the earlier stages stored, carrying on past
+// failures so as much as possible goes
+func (t *selfTest) cleanup(ctx context.Context) (string, error) {
+	var errs []error
+	for _, tk := range t.tickets {
+		errs = append(errs, storage.RemoveTicket(ctx, selfTestDate, tk.TicketID))
+	}
+	if t.agg != nil {
+		errs = append(errs, storage.RemoveAggregate(ctx, selfTestDate))
+	}
+	if t.profiled {
+		errs = append(errs, storage.RemoveSellerProfile(ctx, t.seller))
+	}
+	for _, ar := range t.analyses {
+		errs = append(errs, storage.RemoveAnalysis(ctx, &client.AnalysisResult{CallID: ar.CallID, SellerID: t.seller}))
+	}
+	for _, rt := range t.calls {
+		errs = append(errs, storage.RemoveRawTranscript(rt.CallID))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("removed %d transcripts, %d analyses, %d tickets", len(t.calls), len(t.analyses), len(t.tickets)), nil
+}
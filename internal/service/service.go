@@ -0,0 +1,599 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/aggregate"
+	"im-ai-voice/internal/classifier"
+	"im-ai-voice/internal/github"
+	"im-ai-voice/internal/llm"
+	"im-ai-voice/internal/profile"
+	"im-ai-voice/internal/storage"
+	"im-ai-voice/internal/ticket"
+)
+
+type Service struct {
+	ai       *llm.AIClient
+	shadow   *shadowRunner // Candidate analyses of sampled calls, nil when off
+	rollouts rolloutCache  // The active canary rollout, reread periodically
+
+	archiveLoads archiveLoads           // Background archive reads for long seller timelines
+	ingestSlots  chan struct{}          // Async ingestions being analyzed, INGEST_CONCURRENCY at most
+	degraded     *degradedMode          // Whether calls are being analyzed provisionally, Gemini being unavailable
+	rules        *classifier.Classifier // Analyzes calls without Gemini, and every call when ai is nil
+}
+
+func NewService(ai *llm.AIClient) *Service {
+	return &Service{
+		ai:          ai,
+		shadow:      shadowFromEnv(ai),
+		ingestSlots: make(chan struct{}, ingestConcurrency()),
+		degraded:    degradedModeFromEnv(),
+		rules:       classifier.FromEnv(),
+	}
+}
+
+// LLMStats returns how many recent LLM requests were made and how many failed
+func (s *Service) LLMStats() (requests, failures int) {
+	if s.ai == nil {
+		return 0, 0
+	}
+	return s.ai.Stats()
+}
+
+// LLMParseStats returns how each model's analysis responses parsed since startup
+func (s *Service) LLMParseStats() []client.LLMParseStats {
+	return llm.ParseStats()
+}
+
+// ==================== INGESTION ====================
+
+// IngestTranscript saves a raw transcript and optionally analyzes it
+func (s *Service) IngestTranscript(ctx context.Context, rt client.RawTranscript, analyzeNow bool) (*client.IngestResponse, error) {
+	// Save the raw transcript, its PII swapped for vault tokens
+	if err := tokenizeTranscript(ctx, &rt); err != nil {
+		return nil, err
+	}
+	callID, err := storage.SaveRawTranscript(rt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save transcript: %w", err)
+	}
+
+	storage.RecordEvent(ctx, client.Event{
+		Type:     client.EventIngested,
+		CallID:   callID,
+		GluserID: rt.SellerID,
+		Payload:  client.IngestedPayload{Source: "api", Language: rt.Language, DurationMS: rt.DurationMS},
+	})
+	recordAPITranscriptReceived(ctx, rt, callID)
+
+	response := &client.IngestResponse{
+		CallID:   callID,
+		Status:   "ingested",
+		Analyzed: false,
+	}
+
+	// Optionally analyze immediately
+	if analyzeNow {
+		rt.CallID = callID // Ensure call ID is set
+		analysis, err := s.ProcessSingleCallAndReturn(ctx, callID)
+		if err != nil {
+			response.Message = fmt.Sprintf("ingested but analysis failed: %v", err)
+		} else {
+			response.Analyzed = true
+			response.Analysis = analysis
+			response.Message = "ingested and analyzed"
+			if analysis.Provisional {
+				response.Message = "ingested and analyzed provisionally, Gemini unavailable"
+			}
+		}
+	} else {
+		response.Message = "ingested successfully, pending analysis"
+	}
+
+	return response, nil
+}
+
+// ==================== PROCESSING ====================
+
+// ProcessSingleCall analyzes a single transcript by call ID
+func (s *Service) ProcessSingleCall(ctx context.Context, callID string) error {
+	_, err := s.ProcessSingleCallAndReturn(ctx, callID)
+	return err
+}
+
+// ProcessSingleCallAndReturn analyzes a single transcript and returns the
+// analysis. A transcript naming its seller goes through the same steps as
+// the watcher's: the seller's profile is the analysis's context, and is
+// updated with it.
+func (s *Service) ProcessSingleCallAndReturn(ctx context.Context, callID string) (*client.AnalysisResult, error) {
+	// Load the raw transcript
+	rt, err := storage.LoadRawTranscript(callID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load transcript: %w", err)
+	}
+	return s.processRawTranscript(ctx, rt)
+}
+
+// processRawTranscript analyzes a stored API transcript, see ProcessSingleCallAndReturn
+func (s *Service) processRawTranscript(ctx context.Context, rt *client.RawTranscript) (*client.AnalysisResult, error) {
+	if rt.SellerID != "" {
+		_, analysis, err := s.processWithProfile(ctx, rawToHackathonTranscript(rt), *rt)
+		if err != nil {
+			recordAnalysisFailed(ctx, rt, err)
+			return nil, err
+		}
+		s.markAggregateStale(ctx, analysis)
+		return analysis, nil
+	}
+
+	// Run LLM analysis
+	analysis, err := s.analyzeOrProvisional(ctx, *rt, "")
+	if err != nil {
+		recordAnalysisFailed(ctx, rt, err)
+		return nil, fmt.Errorf("failed to analyze transcript: %w", err)
+	}
+	if analysis.Provisional {
+		if err := s.saveProvisional(ctx, analysis, *rt, nil); err != nil {
+			return nil, err
+		}
+		s.markAggregateStale(ctx, analysis)
+		return analysis, nil
+	}
+
+	// Save the analysis
+	if err := storage.SaveAnalysis(ctx, *analysis); err != nil {
+		return nil, fmt.Errorf("failed to save analysis: %w", err)
+	}
+	storage.RecordAnalyzedEvent(ctx, analysis)
+	s.markAggregateStale(ctx, analysis)
+	s.shadowAnalyze(ctx, *rt, "", analysis)
+
+	return analysis, nil
+}
+
+// recordAnalysisFailed records an API transcript's failed analysis. Unlike
+// the watcher's files, nothing retries it, so each failure is recorded.
+func recordAnalysisFailed(ctx context.Context, rt *client.RawTranscript, err error) {
+	storage.RecordEvent(ctx, client.Event{
+		Type:     client.EventAnalysisFailed,
+		CallID:   rt.CallID,
+		GluserID: rt.SellerID,
+		Payload:  client.AnalysisFailedPayload{Source: "api", Stage: "analysis", Error: err.Error(), Attempts: 1},
+	})
+}
+
+// profilePrefetchBatch is how many transcripts ProcessAllUnprocessed loads
+// at a time, prefetching their sellers' profiles
+const profilePrefetchBatch = 100
+
+// ProcessAllUnprocessed processes all transcripts that haven't been
+// analyzed, returning the analyses made
+func (s *Service) ProcessAllUnprocessed(ctx context.Context) ([]*client.AnalysisResult, []error) {
+	ids, err := storage.ListTranscriptIDs()
+	if err != nil {
+		return nil, []error{fmt.Errorf("failed to list transcripts: %w", err)}
+	}
+
+	// Deleted analyses stay deleted
+	deleted := make(map[string]bool)
+	if items, err := storage.ListTrash(ctx, client.TrashAnalysis); err == nil {
+		for _, item := range items {
+			deleted[item.ID] = true
+		}
+	}
+
+	var pending []string
+	for _, id := range ids {
+		// Skip if already analyzed
+		if storage.AnalysisExists(id) || storage.AnalysisExistsInMongo(ctx, id) || deleted[id] {
+			continue
+		}
+		pending = append(pending, id)
+	}
+
+	var processed []*client.AnalysisResult
+	var errors []error
+
+	// Transcripts are loaded a batch at a time so their sellers' profiles
+	// can be fetched together rather than one call at a time
+	for start := 0; start < len(pending); start += profilePrefetchBatch {
+		batch := pending[start:min(start+profilePrefetchBatch, len(pending))]
+		rts := make([]*client.RawTranscript, len(batch))
+		var sellers []string
+		for i, id := range batch {
+			rt, err := storage.LoadRawTranscript(id)
+			if err != nil {
+				errors = append(errors, fmt.Errorf("call %s: failed to load transcript: %w", id, err))
+				log.Printf("Failed to process %s: %v", id, err)
+				continue
+			}
+			rts[i] = rt
+			sellers = append(sellers, rt.SellerID)
+		}
+		storage.PrefetchSellerProfiles(ctx, sellers)
+
+		for i, rt := range rts {
+			if rt == nil {
+				continue
+			}
+			analysis, err := s.processRawTranscript(ctx, rt)
+			if err != nil {
+				errors = append(errors, fmt.Errorf("call %s: %w", batch[i], err))
+				log.Printf("Failed to process %s: %v", batch[i], err)
+				continue
+			}
+
+			processed = append(processed, analysis)
+			log.Printf("Processed call: %s", batch[i])
+		}
+	}
+
+	return processed, errors
+}
+
+// ==================== AGGREGATION ====================
+
+// RunAggregation generates daily aggregates and tickets for a date
+func (s *Service) RunAggregation(ctx context.Context, date string) (*client.DailyAggregate, error) {
+	job := s.StartJob(ctx, client.JobTypeAggregation, date)
+	agg, err := s.runAggregation(ctx, date)
+	job.Finish(ctx, "/aggregates/"+date, err)
+	return agg, err
+}
+
+func (s *Service) runAggregation(ctx context.Context, date string) (*client.DailyAggregate, error) {
+	start := time.Now()
+	agg, tickets, err := s.buildAggregation(ctx, date)
+	if err != nil {
+		return nil, err
+	}
+
+	// Save aggregate to MongoDB directly
+	if storage.IsMongoEnabled() {
+		if err := storage.SaveAggregateToMongo(ctx, agg); err != nil {
+			log.Printf("⚠️ Failed to save aggregate to MongoDB: %v", err)
+		}
+	} else {
+		// Fallback to local file
+		if err := storage.SaveAggregate(*agg); err != nil {
+			return nil, fmt.Errorf("failed to save aggregate: %w", err)
+		}
+	}
+
+	// Save tickets directly to MongoDB
+	for _, ticket := range tickets {
+		// GitHub issues are per bucket and kept by the bucket's own ticket
+		if ticket.SystemicIssueID == "" {
+			if err := github.Sync(ctx, &ticket); err != nil {
+				log.Printf("⚠️ Failed to sync ticket %s to GitHub: %v", ticket.TicketID, err)
+			}
+		}
+		storage.RecordEvent(ctx, client.Event{
+			Type:     client.EventTicketCreated,
+			TicketID: ticket.TicketID,
+			Payload: client.TicketCreatedPayload{
+				Date:          ticket.Date,
+				FeatureBucket: ticket.FeatureBucket,
+				Priority:      ticket.Priority,
+				Severity:      ticket.Severity,
+				AffectedCount: ticket.AffectedCount,
+				OwnerTeam:     ticketOwnerTeam(ticket),
+			},
+		})
+		if storage.IsMongoEnabled() {
+			if err := storage.SaveTicketToMongo(ctx, &ticket); err != nil {
+				log.Printf("⚠️ Failed to save ticket %s to MongoDB: %v", ticket.TicketID, err)
+			} else {
+				log.Printf("   📤 Saved ticket to MongoDB: %s", ticket.TicketID)
+			}
+		} else {
+			// Fallback to local file
+			if err := storage.SaveTicket(ticket); err != nil {
+				log.Printf("Failed to save ticket %s: %v", ticket.TicketID, err)
+			}
+		}
+	}
+
+	// Tickets keep their first CreatedAt across aggregations, so only new ones are sent
+	routeNewTickets(ctx, tickets, start)
+	s.snapshotDashboard(ctx, agg)
+
+	log.Printf("Aggregation complete for %s: %d calls, %d issues, %d tickets",
+		date, agg.TotalCalls, agg.TotalIssues, len(tickets))
+
+	if len(aggregate.Shifts) > 0 {
+		s.RunShiftAggregations(ctx, date) // Logs its own failures
+	}
+
+	return agg, nil
+}
+
+// buildAggregation computes a date's aggregate and tickets without saving
+// anything. Tickets already stored for the date keep their status.
+func (s *Service) buildAggregation(ctx context.Context, date string) (*client.DailyAggregate, []client.Ticket, error) {
+	analyses, err := s.loadAnalysesForDate(ctx, date)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(analyses) == 0 {
+		return nil, nil, fmt.Errorf("no analyses found for date %s", date)
+	}
+
+	// Build aggregate
+	agg := aggregate.Build(date, analyses)
+	agg.InputFingerprint = aggregate.Fingerprint(analyses)
+	agg.WeeklyThemes = weeklyThemes(ctx, date)
+	agg.Processing = processingMetrics(ctx, date)
+	agg.TopMovers = s.topMovers(ctx, agg)
+
+	// Generate tickets, from an aggregate without the issues suppression rules mute
+	rules, err := storage.LoadTicketSuppressions(ctx)
+	if err != nil {
+		log.Printf("⚠️ Failed to load ticket suppressions: %v", err)
+	}
+	suppressions := ticket.NewSuppressions(date, rules)
+	ticketAgg, ticketAnalyses := agg, analyses
+	if len(rules) > 0 {
+		ticketAnalyses = suppressions.Analyses(analyses)
+		ticketAgg = aggregate.Build(date, ticketAnalyses)
+	}
+	systemic, err := storage.LoadSystemicIssues(ctx)
+	if err != nil {
+		log.Printf("⚠️ Failed to load systemic issues: %v", err)
+	} else {
+		systemic = suppressions.Systemic(systemic)
+	}
+	profiles := affectedProfiles(ctx, ticketAgg, systemic)
+	values := sellerValues(profiles)
+	tickets := ticket.Generate(date, ticketAgg, sellerValueBoosts(profiles), values)
+	attachSourceTickets(tickets, ticketAnalyses)
+	if err == nil {
+		tickets = append(tickets, ticket.GenerateSystemic(date, systemic, len(tickets)+1, values)...)
+	}
+	agg.Suppressed = suppressions.Summary()
+	if deleted := trashedTickets(ctx, date); len(deleted) > 0 {
+		tickets = slices.DeleteFunc(tickets, func(t client.Ticket) bool { return deleted[t.TicketID] })
+	}
+	if len(tickets) > 0 {
+		if dates, err := ticket.EvidenceDates(date); err == nil {
+			ticket.AttachEvidence(tickets, dates, s.aggregateHistory(ctx, dates, agg))
+		}
+		s.carryOverTickets(ctx, date, tickets)
+		attachOwners(tickets, bucketOwners(ctx))
+	}
+	return agg, tickets, nil
+}
+
+// loadAnalysesForDate loads all analyses for a date - MongoDB first
+func (s *Service) loadAnalysesForDate(ctx context.Context, date string) ([]client.AnalysisResult, error) {
+	var analyses []client.AnalysisResult
+	var err error
+
+	if storage.IsMongoEnabled() {
+		analyses, err = storage.GetAllAnalysesForDateFromMongo(ctx, date)
+		if err != nil {
+			log.Printf("⚠️ MongoDB load failed, falling back to local: %v", err)
+		}
+	}
+
+	// Fallback to local files if MongoDB failed or not enabled
+	if len(analyses) == 0 {
+		analyses, err = storage.LoadAllAnalysisForDate(date)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load analyses: %w", err)
+		}
+	}
+	return analyses, nil
+}
+
+// PreviewAggregation computes what RunAggregation would save for a date,
+// without saving, syncing to GitHub or recording events
+func (s *Service) PreviewAggregation(ctx context.Context, date string) (*client.AggregatePreview, error) {
+	agg, tickets, err := s.buildAggregation(ctx, date)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &client.AggregatePreview{Date: date, Aggregate: agg, Tickets: tickets, NewTicketIDs: []string{}, GeneratedAt: time.Now()}
+	if preview.Tickets == nil {
+		preview.Tickets = []client.Ticket{}
+	}
+	existing, _ := s.GetTicketsForDate(ctx, date)
+	stored := make(map[string]bool, len(existing))
+	for _, t := range existing {
+		stored[t.TicketID] = true
+	}
+	for _, t := range tickets {
+		if !stored[t.TicketID] {
+			preview.NewTicketIDs = append(preview.NewTicketIDs, t.TicketID)
+		}
+	}
+	return preview, nil
+}
+
+// ==================== QUERY METHODS ====================
+
+// GetCallAnalysis returns the analysis for a specific call - MongoDB first
+func (s *Service) GetCallAnalysis(ctx context.Context, callID string) (*client.AnalysisResult, error) {
+	if storage.IsMongoEnabled() {
+		ar, err := storage.GetAnalysisFromMongo(ctx, callID)
+		if err == nil && ar != nil {
+			return ar, nil
+		}
+	}
+	// Fallback to local
+	return storage.LoadAnalysis(callID)
+}
+
+// GetCallTranscript returns the stored transcripts of a call. The raw
+// transcript file may be gone while the analysis still has the English text.
+func (s *Service) GetCallTranscript(ctx context.Context, callID string) (*client.CallTranscript, error) {
+	rt, rawErr := storage.LoadRawTranscript(callID)
+	ar, _ := s.GetCallAnalysis(ctx, callID)
+	if rt == nil && ar == nil {
+		return nil, rawErr
+	}
+
+	out := &client.CallTranscript{CallID: callID}
+	if rt != nil {
+		out.SellerID = rt.SellerID
+		out.Timestamp = rt.Timestamp
+		out.Language = rt.Language
+		out.Transcript = rt.Transcript
+		out.RecordingURL, _ = rt.Metadata["call_recording_url"].(string)
+	}
+	if ar != nil {
+		out.TranscriptEn = ar.TranscriptEn
+		if out.SellerID == "" {
+			out.SellerID = ar.SellerID
+			out.Timestamp = ar.Timestamp
+			out.Language = ar.OriginalLang
+		}
+	}
+	return out, nil
+}
+
+// GetCallLLMRaw returns the raw Gemini responses behind a call's analysis,
+// nil once they've expired
+func (s *Service) GetCallLLMRaw(ctx context.Context, callID string) (*client.LLMRawResponse, error) {
+	return storage.LoadLLMRaw(ctx, callID)
+}
+
+// GetDailyAggregate returns the aggregate for a specific date - MongoDB first
+func (s *Service) GetDailyAggregate(ctx context.Context, date string) (*client.DailyAggregate, error) {
+	if storage.IsMongoEnabled() {
+		agg, err := storage.GetAggregateFromMongo(ctx, date)
+		if err == nil && agg != nil {
+			return agg, nil
+		}
+	}
+	// Fallback to local
+	return storage.LoadAggregate(date)
+}
+
+// aggregateHistory loads the aggregates for dates (nil where missing), using
+// current for the last date, which was just built
+func (s *Service) aggregateHistory(ctx context.Context, dates []string, current *client.DailyAggregate) []*client.DailyAggregate {
+	history := make([]*client.DailyAggregate, len(dates))
+	for i, date := range dates {
+		if date == current.Date {
+			history[i] = current
+			continue
+		}
+		if agg, err := s.GetDailyAggregate(ctx, date); err == nil {
+			history[i] = agg
+		}
+	}
+	return history
+}
+
+// GetTicketsForDate returns all tickets for a specific date - MongoDB first
+func (s *Service) GetTicketsForDate(ctx context.Context, date string) ([]client.Ticket, error) {
+	if storage.IsMongoEnabled() {
+		tickets, err := storage.GetTicketsForDateFromMongo(ctx, date)
+		if err == nil && len(tickets) > 0 {
+			return tickets, nil
+		}
+	}
+	// Fallback to local
+	return storage.LoadTicketsForDate(date)
+}
+
+// GetDashboardAggregate returns a date's aggregate for the read endpoints,
+// possibly cached (DASHBOARD_CACHE_TTL); read GetDailyAggregate to update it
+func (s *Service) GetDashboardAggregate(ctx context.Context, date string) (*client.DailyAggregate, error) {
+	return storage.CachedAggregate(ctx, date)
+}
+
+// GetDashboardTickets returns a date's tickets for the read endpoints,
+// possibly cached (DASHBOARD_CACHE_TTL); read GetTicketsForDate to update them
+func (s *Service) GetDashboardTickets(ctx context.Context, date string) ([]client.Ticket, error) {
+	return storage.CachedTickets(ctx, date)
+}
+
+// GetDashboard returns the complete dashboard for a date - cache first, then
+// MongoDB. With a shift, the dashboard's aggregate is that shift's.
+func (s *Service) GetDashboard(ctx context.Context, date, shift string) (*client.DashboardResponse, error) {
+	agg, err := storage.CachedAggregate(ctx, date)
+	if err != nil {
+		return nil, err
+	}
+	tickets, _ := storage.CachedTickets(ctx, date)
+
+	dashboard := &client.DashboardResponse{
+		Date:       date,
+		Aggregate:  agg,
+		TopTickets: tickets,
+	}
+	if len(aggregate.Shifts) > 0 {
+		if dashboard.Shifts, err = s.GetShiftAggregates(ctx, date); err != nil {
+			log.Printf("⚠️ Failed to load shift aggregates for %s: %v", date, err)
+		}
+	}
+	if shift != "" {
+		shiftAgg, err := s.GetShiftAggregate(ctx, date, shift)
+		if err != nil {
+			return nil, err
+		}
+		if shiftAgg == nil {
+			return nil, fmt.Errorf("no %s shift aggregate for %s", shift, date)
+		}
+		dashboard.Shift = shift
+		dashboard.Aggregate = shiftAgg
+	}
+	return dashboard, nil
+}
+
+// AnalyzeTranscript is a simple analysis for backward compatibility
+func (s *Service) AnalyzeTranscript(ctx context.Context, transcript string) (string, error) {
+	if s.ai == nil {
+		return "", fmt.Errorf("AI client not configured")
+	}
+	return s.ai.AnalyzeText(ctx, transcript)
+}
+
+// PreviewAnalysis runs the analysis ingesting a transcript would, and returns
+// it without storing anything. With a seller, their profile is the context
+// and the analysis is enriched as ingestion enriches it, but the profile,
+// extraction and commitments are left alone. The primary model and prompts
+// always run, whatever rollout is active.
+func (s *Service) PreviewAnalysis(ctx context.Context, rt client.RawTranscript) (*client.AnalysisResult, error) {
+	if rt.CallID == "" {
+		rt.CallID = "preview"
+	}
+	if rt.Timestamp.IsZero() {
+		rt.Timestamp = time.Now()
+	}
+
+	if err := tokenizeTranscript(ctx, &rt); err != nil {
+		return nil, err
+	}
+
+	var sellerContext string
+	var contextUsed *client.SellerContextUsed
+	if rt.SellerID != "" {
+		sellerContext, contextUsed = profile.BuildSellerContextFromProfile(ctx, rt.SellerID, rt.Origin)
+	}
+	var analysis *client.AnalysisResult
+	if s.ai == nil {
+		analysis = s.rules.Classify(rt)
+	} else {
+		var err error
+		if analysis, _, err = s.ai.AnalyzeCall(ctx, rt, sellerContext); err != nil {
+			return nil, fmt.Errorf("analysis failed: %w", err)
+		}
+		analysis.SellerContext = contextUsed
+		s.crossCheck(rt, analysis)
+	}
+	analysis.AgentID = rt.AgentID
+	if rt.SellerID != "" {
+		enrichAnalysis(analysis, rawToHackathonTranscript(&rt))
+	}
+	return analysis, nil
+}
@@ -0,0 +1,286 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"math"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/flags"
+	"im-ai-voice/internal/llm"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== SHADOW ANALYSIS ====================
+// A share of incoming calls (SHADOW_PERCENT) is analyzed a second time by a
+// candidate: another Gemini model (SHADOW_MODEL) and/or prompt registry
+// (SHADOW_PROMPT_REGISTRY). The candidate's analysis is compared with the
+// primary's and stored apart from it, so profiles, aggregates and tickets
+// only ever see the primary. Sampling hashes the call ID, so the same calls
+// are picked on every instance. Shadow analyses run in the background after
+// the primary's is saved; when SHADOW_CONCURRENCY are already running, the
+// call isn't shadowed rather than queued.
+
+// shadowRunner analyzes sampled calls with the candidate client
+type shadowRunner struct {
+	ai        *llm.AIClient
+	candidate string // SHADOW_VERSION, the model if unset
+	percent   int
+	slots     chan struct{}
+}
+
+// shadowFromEnv reads SHADOW_PERCENT, SHADOW_MODEL, SHADOW_PROMPT_REGISTRY
+// and SHADOW_VERSION; nil when shadowing is off
+func shadowFromEnv(ai *llm.AIClient) *shadowRunner {
+	v := os.Getenv("SHADOW_PERCENT")
+	if v == "" || ai == nil {
+		return nil
+	}
+	percent, err := strconv.Atoi(v)
+	if err != nil || percent < 0 || percent > 100 {
+		log.Printf("⚠️ Invalid SHADOW_PERCENT=%q, shadow analysis off", v)
+		return nil
+	}
+	if percent == 0 {
+		return nil
+	}
+	candidate, err := llm.NewShadowClient(ai, os.Getenv("SHADOW_MODEL"), os.Getenv("SHADOW_PROMPT_REGISTRY"))
+	if err != nil {
+		log.Printf("⚠️ Shadow analysis off: %v", err)
+		return nil
+	}
+	label := os.Getenv("SHADOW_VERSION")
+	if label == "" {
+		label = candidate.Model()
+	}
+	log.Printf("👥 Shadow analysis: %d%% of calls also analyzed by candidate %q", percent, label)
+	return &shadowRunner{
+		ai:        candidate,
+		candidate: label,
+		percent:   percent,
+		slots:     make(chan struct{}, config.SHADOW_CONCURRENCY),
+	}
+}
+
+// sampled reports whether a call is in the shadowed share
+func (r *shadowRunner) sampled(callID string) bool {
+	h := fnv.New32a()
+	h.Write([]byte(callID))
+	return int(h.Sum32()%100) < r.percent
+}
+
+// shadowAnalyze analyzes a sampled call with the candidate in the background
+// and stores the result with its comparison against primary
+func (s *Service) shadowAnalyze(ctx context.Context, rt client.RawTranscript, sellerContext string, primary *client.AnalysisResult) {
+	r := s.shadow
+	if r == nil || primary.Blocked || !r.sampled(rt.CallID) || !flags.Enabled(ctx, client.FlagShadow, rt.CallID, rt.Origin) {
+		return
+	}
+	select {
+	case r.slots <- struct{}{}:
+	default:
+		log.Printf("   👥 Call %s not shadowed, %d shadow analyses already running", rt.CallID, cap(r.slots))
+		return
+	}
+	primaryCopy := *primary
+
+	go func() {
+		defer func() { <-r.slots }()
+		ctx := context.WithoutCancel(ctx)
+
+		sa := &client.ShadowAnalysis{
+			CallID:     primaryCopy.CallID,
+			SellerID:   primaryCopy.SellerID,
+			Timestamp:  primaryCopy.Timestamp,
+			Candidate:  r.candidate,
+			Model:      r.ai.Model(),
+			AnalyzedAt: time.Now(),
+		}
+		analyzeCtx, cancel := withAnalysisDeadline(ctx, rt)
+		analysis, _, err := r.ai.AnalyzeCall(analyzeCtx, rt, sellerContext)
+		cancel()
+		if err != nil {
+			log.Printf("   ⚠️ Shadow analysis failed for %s: %v", rt.CallID, err)
+			sa.Error = "candidate analysis failed" // err can carry Gemini's URL and response
+		} else {
+			delete(analysis.LLMRaw, "raw_extraction") // Only kept for the primary's replays
+			analysis.AgentID = rt.AgentID
+			analysis.SellerID = primaryCopy.SellerID
+			analysis.Origin = primaryCopy.Origin
+			sa.Analysis = analysis
+			sa.Comparison = compareShadow(&primaryCopy, analysis)
+		}
+		if err := storage.SaveShadowAnalysis(ctx, sa); err != nil {
+			log.Printf("   ⚠️ Failed to save shadow analysis for %s: %v", rt.CallID, err)
+		}
+	}()
+}
+
+// compareShadow sets out how a candidate analysis differs from the primary
+func compareShadow(primary, candidate *client.AnalysisResult) *client.ShadowComparison {
+	pb, cb := issueBucketSet(primary), issueBucketSet(candidate)
+	c := &client.ShadowComparison{
+		SentimentMatch:        primary.Intent.Sentiment == candidate.Intent.Sentiment,
+		ChurnMatch:            primary.Churn.IsLikelyToChurn == candidate.Churn.IsLikelyToChurn,
+		AgentPerformanceMatch: primary.AgentPerformance == candidate.AgentPerformance,
+		EscalationMatch:       escalated(primary) == escalated(candidate),
+		SatisfactionDelta:     candidate.Intent.SatisfactionScore - primary.Intent.SatisfactionScore,
+		IssueCountDelta:       len(candidate.Issues) - len(primary.Issues),
+		BucketOverlap:         1,
+	}
+	both := 0
+	for b := range cb {
+		if pb[b] {
+			both++
+		} else {
+			c.BucketsAdded = append(c.BucketsAdded, b)
+		}
+	}
+	for b := range pb {
+		if !cb[b] {
+			c.BucketsMissed = append(c.BucketsMissed, b)
+		}
+	}
+	slices.Sort(c.BucketsAdded)
+	slices.Sort(c.BucketsMissed)
+	if union := len(pb) + len(cb) - both; union > 0 {
+		c.BucketOverlap = math.Round(float64(both)/float64(union)*1000) / 1000
+	}
+	return c
+}
+
+func issueBucketSet(ar *client.AnalysisResult) map[string]bool {
+	set := make(map[string]bool, len(ar.Issues))
+	for _, issue := range ar.Issues {
+		set[issue.Bucket] = true
+	}
+	return set
+}
+
+func escalated(ar *client.AnalysisResult) bool {
+	esc, _ := ar.LLMRaw["escalation_required"].(bool)
+	return esc
+}
+
+// CallShadowAnalysis returns a call's shadow analysis
+func (s *Service) CallShadowAnalysis(ctx context.Context, callID string) (*client.ShadowAnalysis, error) {
+	sa, err := storage.LoadShadowAnalysis(ctx, callID)
+	if err != nil {
+		return nil, err
+	}
+	if sa == nil {
+		return nil, fmt.Errorf("%w: %s has no shadow analysis", ErrCallNotFound, callID)
+	}
+	return sa, nil
+}
+
+// ShadowReport compares the candidate's analyses of calls on the dates from
+// to to with the primary's. An empty candidate reports on the current one,
+// or every candidate when shadowing is off.
+func (s *Service) ShadowReport(ctx context.Context, from, to time.Time, candidate string) (*client.ShadowReport, error) {
+	if to.Before(from) {
+		return nil, fmt.Errorf("to date is before from date")
+	}
+	if days := int(to.Sub(from).Hours()/24) + 1; days > config.SHADOW_REPORT_MAX_DAYS {
+		return nil, fmt.Errorf("date range too large (%d days, max %d)", days, config.SHADOW_REPORT_MAX_DAYS)
+	}
+	report := &client.ShadowReport{
+		From:        from.Format(config.DateLayout),
+		To:          to.Format(config.DateLayout),
+		Buckets:     []client.ShadowBucketCount{},
+		GeneratedAt: time.Now(),
+	}
+	if s.shadow != nil {
+		report.Enabled = true
+		report.Percent = s.shadow.percent
+		if candidate == "" {
+			candidate = s.shadow.candidate
+		}
+	}
+	report.Candidate = candidate
+
+	list, err := storage.LoadShadowAnalyses(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var sentiment, churn, agent, escalation, overlap float64
+	var satDelta, absSatDelta, issueDelta int
+	buckets := make(map[string]*client.ShadowBucketCount)
+	count := func(b string) *client.ShadowBucketCount {
+		if buckets[b] == nil {
+			buckets[b] = &client.ShadowBucketCount{Bucket: b}
+		}
+		return buckets[b]
+	}
+	for _, sa := range list {
+		if candidate != "" && sa.Candidate != candidate {
+			continue
+		}
+		report.Calls++
+		if sa.Comparison == nil || sa.Analysis == nil {
+			report.Failed++
+			continue
+		}
+		// The primary's buckets are the candidate's, less those it added, plus those it missed
+		for b := range issueBucketSet(sa.Analysis) {
+			count(b).Candidate++
+			if !slices.Contains(sa.Comparison.BucketsAdded, b) {
+				count(b).Primary++
+			}
+		}
+		for _, b := range sa.Comparison.BucketsMissed {
+			count(b).Primary++
+		}
+
+		c := sa.Comparison
+		report.Compared++
+		sentiment += boolFloat(c.SentimentMatch)
+		churn += boolFloat(c.ChurnMatch)
+		agent += boolFloat(c.AgentPerformanceMatch)
+		escalation += boolFloat(c.EscalationMatch)
+		overlap += c.BucketOverlap
+		satDelta += c.SatisfactionDelta
+		absSatDelta += max(c.SatisfactionDelta, -c.SatisfactionDelta)
+		issueDelta += c.IssueCountDelta
+	}
+
+	if n := float64(report.Compared); n > 0 {
+		rate := func(v float64) float64 { return math.Round(v/n*1000) / 1000 }
+		report.SentimentAgreement = rate(sentiment)
+		report.ChurnAgreement = rate(churn)
+		report.AgentPerformanceAgreement = rate(agent)
+		report.EscalationAgreement = rate(escalation)
+		report.MeanBucketOverlap = rate(overlap)
+		report.MeanSatisfactionDelta = rate(float64(satDelta))
+		report.MeanAbsSatisfactionDelta = rate(float64(absSatDelta))
+		report.MeanIssueCountDelta = rate(float64(issueDelta))
+	}
+	for _, b := range buckets {
+		report.Buckets = append(report.Buckets, *b)
+	}
+	gap := func(b client.ShadowBucketCount) int { return max(b.Candidate-b.Primary, b.Primary-b.Candidate) }
+	sort.Slice(report.Buckets, func(i, j int) bool {
+		a, b := report.Buckets[i], report.Buckets[j]
+		if gap(a) != gap(b) {
+			return gap(a) > gap(b)
+		}
+		return a.Bucket < b.Bucket
+	})
+	return report, nil
+}
+
+func boolFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/aggregate"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== SHIFT AGGREGATION ====================
+
+var (
+	ErrNoShifts     = errors.New("no shifts configured (AGGREGATION_SHIFTS)")
+	ErrUnknownShift = errors.New("unknown shift")
+)
+
+// RunShiftAggregations aggregates every started shift that covers part of
+// date: the date's own shifts, and the previous day's last shift when it
+// runs past midnight. Each shift is saved on its own, so one failing
+// doesn't stop the others.
+func (s *Service) RunShiftAggregations(ctx context.Context, date string) ([]client.DailyAggregate, error) {
+	if len(aggregate.Shifts) == 0 {
+		return nil, ErrNoShifts
+	}
+	day, err := config.ParseBusinessDate(date)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date %q: %w", date, err)
+	}
+	previous := config.BusinessDate(day.AddDate(0, 0, -1))
+
+	var windows []aggregate.ShiftWindow
+	for _, d := range []string{previous, date} {
+		ws, err := aggregate.ShiftWindows(d)
+		if err != nil {
+			return nil, err
+		}
+		windows = append(windows, ws...)
+	}
+
+	now := time.Now()
+	analysesByDate := make(map[string][]client.AnalysisResult)
+	var aggs []client.DailyAggregate
+	for _, w := range windows {
+		if !w.End.After(day) || w.Start.After(now) {
+			continue // Over before date, or not started yet
+		}
+
+		// A window spans at most two business days
+		dates := []string{config.BusinessDate(w.Start)}
+		if last := config.BusinessDate(w.End.Add(-time.Nanosecond)); last != dates[0] {
+			dates = append(dates, last)
+		}
+		var analyses []client.AnalysisResult
+		for _, d := range dates {
+			if _, ok := analysesByDate[d]; !ok {
+				loaded, err := s.loadAnalysesForDate(ctx, d)
+				if err != nil {
+					log.Printf("⚠️ Failed to load analyses for %s shift %s: %v", w.Date, w.Shift, err)
+				}
+				analysesByDate[d] = loaded
+			}
+			analyses = append(analyses, analysesByDate[d]...)
+		}
+
+		agg := aggregate.BuildShift(w, analyses)
+		if err := storage.SaveShiftAggregate(ctx, agg); err != nil {
+			log.Printf("⚠️ Failed to save %s shift aggregate for %s: %v", w.Shift, w.Date, err)
+			continue
+		}
+		aggs = append(aggs, *agg)
+	}
+
+	log.Printf("🕐 Shift aggregation complete for %s: %d shifts", date, len(aggs))
+	return aggs, nil
+}
+
+// GetShiftAggregates returns the shift aggregates of a date, earliest first
+func (s *Service) GetShiftAggregates(ctx context.Context, date string) ([]client.DailyAggregate, error) {
+	return storage.LoadShiftAggregates(ctx, date)
+}
+
+// GetShiftAggregate returns one shift's aggregate for a date, nil if it hasn't been aggregated
+func (s *Service) GetShiftAggregate(ctx context.Context, date, shift string) (*client.DailyAggregate, error) {
+	if _, ok := aggregate.ShiftByName(shift); !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownShift, shift)
+	}
+	aggs, err := storage.LoadShiftAggregates(ctx, date)
+	if err != nil {
+		return nil, err
+	}
+	for i := range aggs {
+		if aggs[i].Shift == shift {
+			return &aggs[i], nil
+		}
+	}
+	return nil, nil
+}
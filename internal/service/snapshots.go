@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== DASHBOARD SNAPSHOTS ====================
+// GET /dashboard shows a date as it's stored now, which changes when the
+// date is re-aggregated or its calls reprocessed. The first aggregation
+// after the day ends (normally the nightly aggregation job) also keeps what
+// it computed as the date's snapshot, never replaced, which
+// ?as_computed=true serves so past reports can be reproduced.
+
+var ErrNoSnapshot = errors.New("no dashboard snapshot")
+
+// GetComputedDashboard returns a date's dashboard as its snapshot has it:
+// the aggregate and tickets as first computed after the day ended, whatever
+// happened to them since
+func (s *Service) GetComputedDashboard(ctx context.Context, date string) (*client.DashboardResponse, error) {
+	snap, err := storage.LoadDashboardSnapshot(ctx, date)
+	if err != nil {
+		return nil, err
+	}
+	if snap == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNoSnapshot, date)
+	}
+	return &client.DashboardResponse{
+		Date:       date,
+		Aggregate:  snap.Aggregate,
+		TopTickets: snap.Tickets,
+		ComputedAt: &snap.ComputedAt,
+	}, nil
+}
+
+// snapshotDashboard keeps the date's dashboard as just computed, once the
+// date's day is over and if it has no snapshot yet. Aggregations during the
+// day only see part of it, so they don't take one.
+func (s *Service) snapshotDashboard(ctx context.Context, agg *client.DailyAggregate) {
+	if agg.Date >= config.Today() {
+		return
+	}
+	tickets, err := s.GetTicketsForDate(ctx, agg.Date)
+	if err != nil {
+		log.Printf("⚠️ Failed to load %s tickets for its dashboard snapshot: %v", agg.Date, err)
+		return
+	}
+	if tickets == nil {
+		tickets = []client.Ticket{}
+	}
+	snap := &client.DashboardSnapshot{Date: agg.Date, Aggregate: agg, Tickets: tickets, ComputedAt: time.Now()}
+	saved, err := storage.SaveDashboardSnapshot(ctx, snap)
+	switch {
+	case err != nil:
+		log.Printf("⚠️ Failed to save %s dashboard snapshot: %v", agg.Date, err)
+	case saved:
+		log.Printf("📸 Dashboard snapshot taken for %s: %d calls, %d tickets", agg.Date, agg.TotalCalls, len(tickets))
+	}
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/aggregate"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== AGGREGATE STALENESS ====================
+// Each aggregate keeps a fingerprint of the analyses it was built from. A
+// call re-analyzed, deleted or restored afterwards marks its date's aggregate
+// stale straight away; the aggregate_staleness job compares the last
+// AGGREGATE_STALE_DAYS of aggregates with their analyses, catching changes
+// made behind the service's back, and clears the flag where nothing changed.
+
+// AggregateStaleDays is how many days of aggregates are checked (AGGREGATE_STALE_DAYS)
+func AggregateStaleDays() int {
+	if v := os.Getenv("AGGREGATE_STALE_DAYS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return n
+		}
+	}
+	return config.DEFAULT_AGGREGATE_STALE_DAYS
+}
+
+// aggregateStaleRecompute is whether the job re-aggregates stale dates itself
+func aggregateStaleRecompute() bool {
+	return os.Getenv("AGGREGATE_STALE_RECOMPUTE") == "true"
+}
+
+// CheckAggregates compares the recent aggregates with their analyses,
+// flagging the changed ones stale and, with recompute, aggregating them again
+func (s *Service) CheckAggregates(ctx context.Context, recompute bool) (*client.StalenessReport, error) {
+	report := &client.StalenessReport{Stale: []string{}, Recomputed: []string{}}
+	today := time.Now()
+	for i := 0; i < AggregateStaleDays(); i++ {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		date := config.BusinessDate(today.AddDate(0, 0, -i))
+		agg, err := s.GetDailyAggregate(ctx, date)
+		if err != nil || agg == nil || agg.InputFingerprint == "" {
+			continue // Not aggregated, or before fingerprints
+		}
+		report.Checked++
+
+		analyses, err := s.loadAnalysesForDate(ctx, date)
+		if err != nil {
+			log.Printf("⚠️ Failed to load analyses of %s: %v", date, err)
+			report.Failed = append(report.Failed, date)
+			continue
+		}
+		if aggregate.Fingerprint(analyses) == agg.InputFingerprint {
+			if agg.Stale {
+				if err := storage.SetAggregateStale(ctx, date, agg.InputFingerprint, nil); err != nil {
+					log.Printf("⚠️ Failed to clear the stale flag of %s: %v", date, err)
+				}
+			}
+			continue
+		}
+
+		report.Stale = append(report.Stale, date)
+		if !agg.Stale {
+			now := time.Now()
+			if err := storage.SetAggregateStale(ctx, date, agg.InputFingerprint, &now); err != nil {
+				log.Printf("⚠️ Failed to mark the aggregate of %s stale: %v", date, err)
+			}
+		}
+		if !recompute {
+			continue
+		}
+		if len(analyses) == 0 {
+			// Nothing left to aggregate; the flag stays until a call comes in
+			continue
+		}
+		if _, err := s.RunAggregation(ctx, date); err != nil {
+			log.Printf("⚠️ Failed to re-aggregate stale %s: %v", date, err)
+			report.Failed = append(report.Failed, date)
+			continue
+		}
+		report.Recomputed = append(report.Recomputed, date)
+	}
+	report.CheckedAt = time.Now()
+	if len(report.Stale) > 0 {
+		log.Printf("🧮 %d stale aggregates (%d recomputed)", len(report.Stale), len(report.Recomputed))
+	}
+	return report, nil
+}
+
+// markAggregateStale flags the aggregate of the analysis's date stale after
+// the analysis changed. The aggregate_staleness job clears it again if the
+// change turns out not to matter.
+func (s *Service) markAggregateStale(ctx context.Context, ar *client.AnalysisResult) {
+	date := storage.AggregationDate(ar)
+	agg, err := s.GetDailyAggregate(ctx, date)
+	if err != nil || agg == nil || agg.InputFingerprint == "" || agg.Stale {
+		return
+	}
+	now := time.Now()
+	if err := storage.SetAggregateStale(ctx, date, agg.InputFingerprint, &now); err != nil {
+		log.Printf("⚠️ Failed to mark the aggregate of %s stale: %v", date, err)
+	}
+}
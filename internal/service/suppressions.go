@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/storage"
+	"im-ai-voice/internal/ticket"
+	"im-ai-voice/internal/ulid"
+)
+
+// ==================== TICKET SUPPRESSIONS ====================
+
+var (
+	ErrInvalidSuppression  = errors.New("invalid ticket suppression")
+	ErrSuppressionNotFound = errors.New("ticket suppression not found")
+)
+
+// ListTicketSuppressions returns the suppression rules, oldest first.
+// Expired rules are left out unless includeExpired is set.
+func (s *Service) ListTicketSuppressions(ctx context.Context, includeExpired bool) ([]client.TicketSuppression, error) {
+	rules, err := storage.LoadTicketSuppressions(ctx)
+	if err != nil || includeExpired {
+		return rules, err
+	}
+	today := config.Today()
+	active := make([]client.TicketSuppression, 0, len(rules))
+	for _, r := range rules {
+		if r.ActiveOn(today) {
+			active = append(active, r)
+		}
+	}
+	return active, nil
+}
+
+// CreateTicketSuppression adds a suppression rule, applied from the next aggregation on
+func (s *Service) CreateTicketSuppression(ctx context.Context, in client.TicketSuppressionRequest) (*client.TicketSuppression, error) {
+	in.Bucket = strings.TrimSpace(in.Bucket)
+	in.Pattern = strings.TrimSpace(in.Pattern)
+	in.Until = strings.TrimSpace(in.Until)
+	switch {
+	case in.Bucket == "" && in.Pattern == "":
+		return nil, fmt.Errorf("%w: bucket or pattern is required", ErrInvalidSuppression)
+	case in.Bucket != "" && !slices.Contains(config.FeatureBuckets, in.Bucket):
+		return nil, fmt.Errorf("%w: unknown bucket %q", ErrInvalidSuppression, in.Bucket)
+	}
+	if in.Pattern != "" {
+		if _, err := ticket.CompileSuppressionPattern(in.Pattern); err != nil {
+			return nil, fmt.Errorf("%w: pattern: %v", ErrInvalidSuppression, err)
+		}
+	}
+	if in.Until != "" {
+		if _, err := config.ParseBusinessDate(in.Until); err != nil {
+			return nil, fmt.Errorf("%w: until must be YYYY-MM-DD", ErrInvalidSuppression)
+		}
+		if in.Until < config.Today() {
+			return nil, fmt.Errorf("%w: until is in the past", ErrInvalidSuppression)
+		}
+	}
+
+	now := time.Now()
+	rule := &client.TicketSuppression{
+		ID:        ulid.NewAt(now),
+		Bucket:    in.Bucket,
+		Pattern:   in.Pattern,
+		Until:     in.Until,
+		Reason:    in.Reason,
+		By:        in.By,
+		CreatedAt: now,
+	}
+	if err := storage.SaveTicketSuppression(ctx, rule); err != nil {
+		return nil, err
+	}
+	log.Printf("🔇 Ticket suppression %s added by %s: bucket=%q pattern=%q until=%q", rule.ID, orAnonymous(rule.By), rule.Bucket, rule.Pattern, rule.Until)
+	return rule, nil
+}
+
+// DeleteTicketSuppression removes a suppression rule
+func (s *Service) DeleteTicketSuppression(ctx context.Context, id string) error {
+	found, err := storage.DeleteTicketSuppression(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("%w: %s", ErrSuppressionNotFound, id)
+	}
+	log.Printf("🔊 Ticket suppression %s removed", id)
+	return nil
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/aggregate"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/storage"
+	"im-ai-voice/internal/ticket"
+)
+
+// ==================== SYSTEMIC ISSUES ====================
+
+// RunSystemicClustering embeds every open issue, clusters them across
+// sellers and replaces the stored systemic issues with the result
+func (s *Service) RunSystemicClustering(ctx context.Context) ([]client.SystemicIssue, error) {
+	if s.ai == nil {
+		return nil, fmt.Errorf("AI client not configured")
+	}
+
+	issues, err := openIssues(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load open issues: %w", err)
+	}
+	texts := make([]string, len(issues))
+	for i, issue := range issues {
+		texts[i] = issue.Bucket + ": " + issue.Problem
+	}
+	vectors, err := s.ai.EmbedTexts(ctx, texts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed issues: %w", err)
+	}
+	items := make([]aggregate.IssueEmbedding, len(issues))
+	for i := range issues {
+		items[i] = aggregate.IssueEmbedding{Issue: issues[i], Vector: vectors[i]}
+	}
+
+	systemic := aggregate.ClusterIssues(items, systemicSimilarity(), systemicMinSellers())
+	values := sellerValues(affectedProfiles(ctx, nil, systemic))
+	for i := range systemic {
+		systemic[i].Impact = ticket.Impact(ticket.SystemicSellers(systemic[i]), values)
+	}
+	if err := storage.SaveSystemicIssues(ctx, systemic); err != nil {
+		return nil, fmt.Errorf("failed to save systemic issues: %w", err)
+	}
+	log.Printf("🧩 Clustered %d open issues into %d systemic issues", len(issues), len(systemic))
+	return systemic, nil
+}
+
+// openIssues pages through every issue not yet resolved, across sellers
+func openIssues(ctx context.Context) ([]client.SellerIssue, error) {
+	var open []client.SellerIssue
+	q := storage.IssueQuery{Limit: 1000}
+	for {
+		page, err := storage.QueryIssues(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		for _, issue := range page.Issues {
+			if issue.Status != "resolved" {
+				open = append(open, issue)
+			}
+		}
+		if !page.HasMore {
+			return open, nil
+		}
+		q.Cursor = page.NextCursor
+	}
+}
+
+// GetSystemicIssues returns the latest systemic issues, optionally in one
+// bucket or with at least minSellers sellers
+func (s *Service) GetSystemicIssues(ctx context.Context, bucket string, minSellers int) ([]client.SystemicIssue, error) {
+	all, err := storage.LoadSystemicIssues(ctx)
+	if err != nil {
+		return nil, err
+	}
+	issues := []client.SystemicIssue{}
+	for _, issue := range all {
+		if (bucket == "" || issue.Bucket == bucket) && issue.SellerCount >= minSellers {
+			issues = append(issues, issue)
+		}
+	}
+	return issues, nil
+}
+
+// systemicSimilarity returns SYSTEMIC_SIMILARITY, or the default when unset or invalid
+func systemicSimilarity() float64 {
+	if v := os.Getenv("SYSTEMIC_SIMILARITY"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil && f > 0 && f <= 1 {
+			return f
+		}
+		log.Printf("⚠️ Invalid SYSTEMIC_SIMILARITY=%q, using %g", v, config.DEFAULT_SYSTEMIC_SIMILARITY)
+	}
+	return config.DEFAULT_SYSTEMIC_SIMILARITY
+}
+
+// systemicMinSellers returns SYSTEMIC_MIN_SELLERS, or the default when unset or invalid
+func systemicMinSellers() int {
+	if v := os.Getenv("SYSTEMIC_MIN_SELLERS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 2 {
+			return n
+		}
+		log.Printf("⚠️ Invalid SYSTEMIC_MIN_SELLERS=%q, using %d", v, config.DEFAULT_SYSTEMIC_MIN_SELLERS)
+	}
+	return config.DEFAULT_SYSTEMIC_MIN_SELLERS
+}
+
+// ==================== SYSTEMIC ISSUE SCHEDULER ====================
+
+// systemicHour returns the hour (business timezone) of the nightly clustering run
+func systemicHour() int {
+	if v := os.Getenv("SYSTEMIC_HOUR"); v != "" {
+		if h, err := strconv.Atoi(v); err == nil && h >= 0 && h < 24 {
+			return h
+		}
+	}
+	return config.DEFAULT_SYSTEMIC_HOUR
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/aggregate"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== KEY INSIGHT THEMES ====================
+// The analysis's key insights are clustered across a week of calls into
+// themes, published with the week's last aggregate and its digest.
+
+// ErrNoThemes is returned when no week's themes have been built yet
+var ErrNoThemes = errors.New("no insight themes")
+
+// RunInsightThemes clusters the key insights of the THEME_WINDOW_DAYS days
+// ending on date into themes, saves them and adds them to that date's
+// aggregate when it exists
+func (s *Service) RunInsightThemes(ctx context.Context, date string) (*client.ThemeReport, error) {
+	if s.ai == nil {
+		return nil, fmt.Errorf("AI client not configured")
+	}
+	end, err := config.ParseBusinessDate(date)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date %q: %w", date, err)
+	}
+
+	report := &client.ThemeReport{
+		From:   end.AddDate(0, 0, -(config.THEME_WINDOW_DAYS - 1)).Format(config.DateLayout),
+		To:     date,
+		Themes: []client.InsightTheme{},
+	}
+	var items []aggregate.InsightEmbedding
+	for d := 0; d < config.THEME_WINDOW_DAYS; d++ {
+		day := end.AddDate(0, 0, -d).Format(config.DateLayout)
+		analyses, err := s.loadAnalysesForDate(ctx, day)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load analyses for %s: %w", day, err)
+		}
+		for _, a := range analyses {
+			insights := keyInsights(a)
+			if a.Blocked || len(insights) == 0 {
+				continue
+			}
+			report.Calls++
+			for _, text := range insights {
+				items = append(items, aggregate.InsightEmbedding{CallID: a.CallID, SellerID: a.SellerID, Timestamp: a.Timestamp, Text: text})
+			}
+		}
+	}
+	report.Insights = len(items)
+
+	if len(items) > 0 {
+		texts := make([]string, len(items))
+		for i, it := range items {
+			texts[i] = it.Text
+		}
+		vectors, err := s.ai.EmbedTexts(ctx, texts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to embed key insights: %w", err)
+		}
+		for i := range items {
+			items[i].Vector = vectors[i]
+		}
+		report.Themes = aggregate.ClusterInsights(items, themeSimilarity(), themeMinCalls(), config.THEMES_MAX)
+	}
+	report.GeneratedAt = time.Now()
+
+	if err := storage.SaveThemeReport(ctx, report); err != nil {
+		return nil, fmt.Errorf("failed to save insight themes: %w", err)
+	}
+	log.Printf("💡 Clustered %d key insights from %d calls (%s to %s) into %d themes",
+		report.Insights, report.Calls, report.From, report.To, len(report.Themes))
+
+	// The date's aggregate carries the themes; a later aggregation keeps them
+	if agg, err := s.GetDailyAggregate(ctx, date); err == nil && agg != nil {
+		agg.WeeklyThemes = report.Themes
+		if storage.IsMongoEnabled() {
+			if err := storage.SaveAggregateToMongo(ctx, agg); err != nil {
+				log.Printf("⚠️ Failed to add themes to the %s aggregate: %v", date, err)
+			}
+		} else if err := storage.SaveAggregate(*agg); err != nil {
+			log.Printf("⚠️ Failed to add themes to the %s aggregate: %v", date, err)
+		}
+	}
+	return report, nil
+}
+
+// GetInsightThemes returns the themes of the week ending on date, or the
+// latest week's when date is empty
+func (s *Service) GetInsightThemes(ctx context.Context, date string) (*client.ThemeReport, error) {
+	var report *client.ThemeReport
+	var err error
+	if date == "" {
+		report, err = storage.LoadLatestThemeReport(ctx)
+	} else {
+		report, err = storage.LoadThemeReport(ctx, date)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if report == nil {
+		if date == "" {
+			return nil, ErrNoThemes
+		}
+		return nil, fmt.Errorf("%w: for the week ending %s", ErrNoThemes, date)
+	}
+	return report, nil
+}
+
+// weeklyThemes returns the stored themes of the week ending on date, so
+// re-aggregating a date doesn't drop them
+func weeklyThemes(ctx context.Context, date string) []client.InsightTheme {
+	report, err := storage.LoadThemeReport(ctx, date)
+	if err != nil {
+		log.Printf("⚠️ Failed to load insight themes for %s: %v", date, err)
+		return nil
+	}
+	if report == nil {
+		return nil
+	}
+	return report.Themes
+}
+
+// keyInsights returns the key insights stored with an analysis's raw LLM
+// output ([]string when fresh, []interface{} once read back from storage)
+func keyInsights(a client.AnalysisResult) []string {
+	var out []string
+	switch v := a.LLMRaw["key_insights"].(type) {
+	case []string:
+		for _, s := range v {
+			if s = strings.TrimSpace(s); s != "" {
+				out = append(out, s)
+			}
+		}
+	case []interface{}:
+		for _, x := range v {
+			if s, ok := x.(string); ok && strings.TrimSpace(s) != "" {
+				out = append(out, strings.TrimSpace(s))
+			}
+		}
+	}
+	return out
+}
+
+// themeSimilarity returns THEME_SIMILARITY, or the default when unset or invalid
+func themeSimilarity() float64 {
+	if v := os.Getenv("THEME_SIMILARITY"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil && f > 0 && f <= 1 {
+			return f
+		}
+		log.Printf("⚠️ Invalid THEME_SIMILARITY=%q, using %g", v, config.DEFAULT_THEME_SIMILARITY)
+	}
+	return config.DEFAULT_THEME_SIMILARITY
+}
+
+// themeMinCalls returns THEME_MIN_CALLS, or the default when unset or invalid
+func themeMinCalls() int {
+	if v := os.Getenv("THEME_MIN_CALLS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 1 {
+			return n
+		}
+		log.Printf("⚠️ Invalid THEME_MIN_CALLS=%q, using %d", v, config.DEFAULT_THEME_MIN_CALLS)
+	}
+	return config.DEFAULT_THEME_MIN_CALLS
+}
+
+// ==================== KEY INSIGHT THEMES SCHEDULER ====================
+
+// themesHour returns the hour (business timezone) of the nightly themes run
+func themesHour() int {
+	if v := os.Getenv("THEMES_HOUR"); v != "" {
+		if h, err := strconv.Atoi(v); err == nil && h >= 0 && h < 24 {
+			return h
+		}
+	}
+	return config.DEFAULT_THEMES_HOUR
+}
@@ -0,0 +1,270 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/github"
+	"im-ai-voice/internal/profile"
+	"im-ai-voice/internal/storage"
+	"im-ai-voice/internal/ticket"
+)
+
+// ==================== TICKET STATUS ====================
+
+var (
+	ErrTicketNotFound      = errors.New("ticket not found")
+	ErrInvalidTicketStatus = errors.New("status must be open, in_progress or resolved")
+	ErrInvalidTicketItem   = errors.New("invalid ticket update")
+	ErrTooManyTicketItems  = fmt.Errorf("at most %d tickets per bulk update", config.TICKETS_BULK_MAX)
+	ErrAuditUnavailable    = errors.New("audit log unavailable")
+)
+
+// UpdateTicketStatus sets a ticket's status and custom fields. Resolving the
+// ticket closes its GitHub issue; moving it back to open or in progress
+// reopens it. Without a status, only the custom fields change.
+func (s *Service) UpdateTicketStatus(ctx context.Context, date, ticketID string, u client.TicketStatusUpdate) (*client.Ticket, error) {
+	t, _, err := s.updateTicket(ctx, date, ticketID, u, nil)
+	return t, err
+}
+
+// updateTicket applies u to a ticket, returning it and its previous status.
+// audit, if set, is called before the change is made, and an error from it
+// leaves the ticket as it was.
+func (s *Service) updateTicket(ctx context.Context, date, ticketID string, u client.TicketStatusUpdate, audit func(previous string) error) (*client.Ticket, string, error) {
+	switch u.Status {
+	case client.TicketOpen, client.TicketInProgress, client.TicketResolved:
+	case "":
+		if len(u.CustomFields) == 0 {
+			return nil, "", ErrInvalidTicketStatus
+		}
+	default:
+		return nil, "", ErrInvalidTicketStatus
+	}
+	if err := s.ValidateCustomFields(ctx, client.CustomFieldTicket, u.CustomFields); err != nil {
+		return nil, "", err
+	}
+
+	tickets, err := s.GetTicketsForDate(ctx, date)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %s", ErrTicketNotFound, ticketID)
+	}
+	var t *client.Ticket
+	for i := range tickets {
+		if tickets[i].TicketID == ticketID {
+			t = &tickets[i]
+		}
+	}
+	if t == nil {
+		return nil, "", fmt.Errorf("%w: %s", ErrTicketNotFound, ticketID)
+	}
+	previous := t.Status
+	if audit != nil {
+		if err := audit(previous); err != nil {
+			return nil, previous, err
+		}
+	}
+
+	if changed := setCustomFields(&t.CustomFields, u.CustomFields); len(changed) > 0 {
+		log.Printf("🏷️ Ticket %s custom fields set: %s", ticketID, strings.Join(changed, ", "))
+	}
+	status := u.Status
+	if status == "" {
+		status = previous
+	}
+	t.Status = status
+	switch {
+	case status == client.TicketResolved && u.ResolutionNote != "":
+		t.ResolutionNote = u.ResolutionNote
+	case status != client.TicketResolved:
+		t.ResolutionNote = ""
+	}
+	switch {
+	case status == client.TicketResolved && previous != client.TicketResolved:
+		now := time.Now()
+		t.ResolvedAt = &now
+	case status != client.TicketResolved:
+		t.ResolvedAt = nil
+	}
+	if status == client.TicketResolved {
+		err = github.Resolve(ctx, t)
+	} else {
+		err = github.Sync(ctx, t)
+	}
+	if err != nil {
+		log.Printf("⚠️ Failed to sync ticket %s to GitHub: %v", ticketID, err)
+	}
+
+	if storage.IsMongoEnabled() {
+		err = storage.SaveTicketToMongo(ctx, t)
+	} else {
+		err = storage.SaveTicket(*t)
+	}
+	if err != nil {
+		return nil, previous, err
+	}
+	log.Printf("🎫 Ticket %s is now %s", ticketID, status)
+	return t, previous, nil
+}
+
+// BulkUpdateTickets applies each item's update in turn, for ticketing
+// systems syncing many tickets at once. One item failing doesn't stop the
+// rest; each gets its result, in request order. audit is called before each
+// change as for updateTicket.
+func (s *Service) BulkUpdateTickets(ctx context.Context, items []client.TicketBulkItem, audit func(item client.TicketBulkItem, previous string) error) (*client.TicketBulkResult, error) {
+	if len(items) > config.TICKETS_BULK_MAX {
+		return nil, ErrTooManyTicketItems
+	}
+
+	result := &client.TicketBulkResult{Results: make([]client.TicketBulkItemResult, 0, len(items))}
+	for _, item := range items {
+		r := client.TicketBulkItemResult{TicketID: item.TicketID}
+		date := item.Date
+		if date == "" && len(item.TicketID) >= len(config.DateLayout) {
+			date = item.TicketID[:len(config.DateLayout)] // Ticket IDs start with their date
+		}
+
+		var t *client.Ticket
+		var err error
+		switch _, dateErr := config.ParseBusinessDate(date); {
+		case item.TicketID == "":
+			err = fmt.Errorf("%w: ticket_id is required", ErrInvalidTicketItem)
+		case dateErr != nil:
+			err = fmt.Errorf("%w: date must be YYYY-MM-DD", ErrInvalidTicketItem)
+		default:
+			u := client.TicketStatusUpdate{Status: item.Status, ResolutionNote: item.ResolutionNote}
+			t, r.PreviousStatus, err = s.updateTicket(ctx, date, item.TicketID, u, func(previous string) error { return audit(item, previous) })
+		}
+		if err != nil {
+			r.Code, r.Error = bulkErrorCode(err), err.Error()
+			result.Failed++
+		} else {
+			r.OK, r.Status = true, t.Status
+			result.Updated++
+		}
+		result.Results = append(result.Results, r)
+	}
+	log.Printf("🎫 Bulk ticket update: %d updated, %d failed", result.Updated, result.Failed)
+	return result, nil
+}
+
+// bulkErrorCode is the error code of a failed bulk update item
+func bulkErrorCode(err error) string {
+	switch {
+	case errors.Is(err, ErrInvalidTicketStatus), errors.Is(err, ErrInvalidTicketItem), errors.Is(err, ErrInvalidCustomField):
+		return client.ErrorValidationFailed
+	case errors.Is(err, ErrTicketNotFound):
+		return client.ErrorNotFound
+	case errors.Is(err, ErrAuditUnavailable), storage.IsUnavailable(err):
+		return client.ErrorStorageUnavailable
+	default:
+		return client.ErrorInternal
+	}
+}
+
+// carryOverTickets keeps the status and custom fields of tickets regenerated
+// by a repeat aggregation of the same date, so resolved tickets stay resolved
+func (s *Service) carryOverTickets(ctx context.Context, date string, tickets []client.Ticket) {
+	existing, err := s.GetTicketsForDate(ctx, date)
+	if err != nil {
+		return
+	}
+	byID := make(map[string]client.Ticket, len(existing))
+	for _, t := range existing {
+		byID[t.TicketID] = t
+	}
+	for i := range tickets {
+		if prev, ok := byID[tickets[i].TicketID]; ok {
+			tickets[i].Status = prev.Status
+			tickets[i].ResolutionNote = prev.ResolutionNote
+			tickets[i].ResolvedAt = prev.ResolvedAt
+			tickets[i].IssueURL = prev.IssueURL
+			tickets[i].CustomFields = prev.CustomFields
+			tickets[i].CreatedAt = prev.CreatedAt
+		}
+	}
+}
+
+// affectedProfiles loads the profiles of the sellers affected by the
+// buckets of agg, when set, and by the systemic issues, nil for those
+// without one
+func affectedProfiles(ctx context.Context, agg *client.DailyAggregate, systemic []client.SystemicIssue) map[string]*client.SellerProfile {
+	profiles := make(map[string]*client.SellerProfile)
+	load := func(ids []string) {
+		for _, id := range ids {
+			if _, seen := profiles[id]; seen {
+				continue
+			}
+			p, err := storage.LoadSellerProfile(ctx, id)
+			if err != nil {
+				log.Printf("⚠️ Failed to load profile %s for seller value: %v", id, err)
+			}
+			profiles[id] = p
+		}
+	}
+	if agg != nil {
+		for _, summary := range agg.FeatureBuckets {
+			load(summary.AffectedSellerIDs)
+		}
+	}
+	for _, s := range systemic {
+		load(ticket.SystemicSellers(s))
+	}
+	return profiles
+}
+
+// sellerValueBoosts returns the severity levels each seller's value adds to
+// the tickets of the buckets they're affected by, nil when seller value
+// doesn't raise severities
+func sellerValueBoosts(profiles map[string]*client.SellerProfile) map[string]int {
+	if !profile.SellerValueEnabled() {
+		return nil
+	}
+	boosts := make(map[string]int, len(profiles))
+	for id, p := range profiles {
+		if p != nil {
+			boosts[id] = profile.SellerValueBoost(p.CustomerType, p.VintageMonths)
+		}
+	}
+	return boosts
+}
+
+// sellerValues returns what the revenue impact model needs of each seller
+// with a profile
+func sellerValues(profiles map[string]*client.SellerProfile) map[string]ticket.SellerValue {
+	values := make(map[string]ticket.SellerValue, len(profiles))
+	for id, p := range profiles {
+		if p != nil {
+			values[id] = ticket.SellerValue{CustomerType: p.CustomerType, ChurnRisk: p.CurrentStatus.ChurnRisk}
+		}
+	}
+	return values
+}
+
+// attachSourceTickets links each bucket ticket to the IndiaMART tickets of
+// the calls raising issues in its bucket
+func attachSourceTickets(tickets []client.Ticket, analyses []client.AnalysisResult) {
+	byBucket := make(map[string][]string)
+	for _, a := range analyses {
+		if a.SourceTicket == nil {
+			continue
+		}
+		for _, issue := range a.Issues {
+			if !slices.Contains(byBucket[issue.Bucket], a.SourceTicket.TicketID) {
+				byBucket[issue.Bucket] = append(byBucket[issue.Bucket], a.SourceTicket.TicketID)
+			}
+		}
+	}
+	for i := range tickets {
+		if tickets[i].SystemicIssueID == "" {
+			tickets[i].SourceTicketIDs = byBucket[tickets[i].FeatureBucket]
+		}
+	}
+}
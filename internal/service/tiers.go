@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/aggregate"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== CUSTOMER TIER AGGREGATES ====================
+
+var ErrNoAnalyses = errors.New("no analyses for date")
+
+// GetTierAggregates splits a date's aggregate by the customer tier of each
+// call's seller. It's computed from the analyses on request, so it reflects
+// the sellers' current customer types.
+func (s *Service) GetTierAggregates(ctx context.Context, date string) (*client.TierAggregates, error) {
+	if _, err := config.ParseBusinessDate(date); err != nil {
+		return nil, fmt.Errorf("invalid date %q: %w", date, err)
+	}
+	analyses, err := s.loadAnalysesForDate(ctx, date)
+	if err != nil {
+		return nil, err
+	}
+	if len(analyses) == 0 {
+		return nil, fmt.Errorf("%w %s", ErrNoAnalyses, date)
+	}
+
+	profiles, err := storage.LoadAllSellerProfiles(ctx)
+	if err != nil {
+		log.Printf("⚠️ Tier aggregates: failed to load seller profiles: %v", err)
+	}
+	customerTypes := make(map[string]string, len(profiles))
+	for _, p := range profiles {
+		if p.CustomerType != "" {
+			customerTypes[p.GluserID] = p.CustomerType
+		}
+	}
+	return aggregate.BuildByTier(date, analyses, customerTypes), nil
+}
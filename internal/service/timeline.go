@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/archive"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/profile"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== SELLER TIMELINE ====================
+// Analyses older than ARCHIVE_AFTER_DAYS leave hot storage for the Parquet
+// archive, so the analyses store alone cuts a seller's history off at the
+// retention boundary. The timeline merges both, each call marked with
+// where it was read from; a call in both (archived, then analyzed again)
+// is listed once, from hot storage. Archive files are read in full, so when
+// the range covers more than TIMELINE_SYNC_ARCHIVE_FILES of them they're
+// read in the background instead: the timeline lists the hot calls with
+// archive_status "loading", and asking again once the load is done (within
+// TIMELINE_ARCHIVE_TTL, on the same instance) gets the merged history.
+
+// archiveLoad is a background read of a seller's archived analyses
+type archiveLoad struct {
+	done     chan struct{}
+	analyses []client.AnalysisResult
+	err      error
+	finished time.Time
+}
+
+// archiveLoads are this instance's background archive reads, by seller and range
+type archiveLoads struct {
+	mu    sync.Mutex
+	loads map[string]*archiveLoad
+}
+
+// SellerTimeline returns a seller's calls in [from, to) from hot storage
+// and the archive, newest first. Zero times leave that side open.
+func (s *Service) SellerTimeline(ctx context.Context, gluserID string, from, to time.Time) (*client.SellerTimeline, error) {
+	p, err := storage.LoadSellerProfile(ctx, gluserID)
+	if err != nil {
+		return nil, fmt.Errorf("error loading profile: %w", err)
+	}
+	if p == nil {
+		return nil, fmt.Errorf("%w: %s", ErrSellerNotFound, gluserID)
+	}
+	hot, err := storage.LoadSellerAnalyses(ctx, gluserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load analyses: %w", err)
+	}
+
+	tl := &client.SellerTimeline{
+		GluserID:    gluserID,
+		Entries:     []client.TimelineEntry{},
+		Channels:    make(map[string]int),
+		GeneratedAt: time.Now(),
+	}
+	if !from.IsZero() {
+		tl.From = config.BusinessDate(from)
+	}
+	if !to.IsZero() {
+		tl.To = config.BusinessDate(to.Add(-time.Nanosecond))
+	}
+
+	durations := make(map[string]int, len(p.CallHistory))
+	for _, c := range p.CallHistory {
+		durations[c.CallID] = c.Duration
+	}
+	seen := make(map[string]bool)
+	add := func(a *client.AnalysisResult, source string) {
+		if seen[a.CallID] || (!from.IsZero() && a.Timestamp.Before(from)) || (!to.IsZero() && !a.Timestamp.Before(to)) {
+			return
+		}
+		seen[a.CallID] = true
+		summary := profile.SummarizeCall(a)
+		summary.Duration = durations[a.CallID]
+		tl.Entries = append(tl.Entries, client.TimelineEntry{CallSummary: summary, Source: source})
+		tl.Channels[client.ContactChannel(a.Channel)]++
+		if source == client.TimelineHot {
+			tl.Hot++
+		} else {
+			tl.Archived++
+		}
+	}
+	for i := range hot {
+		add(&hot[i], client.TimelineHot)
+	}
+
+	if archive.Archive == nil {
+		tl.ArchiveStatus = client.ArchiveUnavailable
+	} else {
+		tl.ArchiveBoundary = config.BusinessDate(time.Now().AddDate(0, 0, -archive.AfterDays()))
+		archived, files, err := s.archivedAnalyses(ctx, gluserID, from, to)
+		tl.ArchiveFiles = files
+		switch {
+		case errors.Is(err, errArchiveLoading):
+			tl.ArchiveStatus = client.ArchiveLoading
+		case err != nil:
+			log.Printf("⚠️ Timeline of %s without archived calls: %v", gluserID, err)
+			tl.ArchiveStatus, tl.ArchiveError = client.ArchiveFailed, "failed to read the archive"
+		default:
+			tl.ArchiveStatus = client.ArchiveComplete
+			for i := range archived {
+				add(&archived[i], client.TimelineArchive)
+			}
+		}
+	}
+
+	sort.SliceStable(tl.Entries, func(i, j int) bool {
+		return tl.Entries[i].Timestamp.After(tl.Entries[j].Timestamp)
+	})
+	tl.Count = len(tl.Entries)
+	return tl, nil
+}
+
+var errArchiveLoading = errors.New("archive load in progress")
+
+// archivedAnalyses reads a seller's archived analyses in [from, to) at
+// once when that's at most TIMELINE_SYNC_ARCHIVE_FILES files, and in the
+// background otherwise, returning errArchiveLoading until it's done
+func (s *Service) archivedAnalyses(ctx context.Context, gluserID string, from, to time.Time) ([]client.AnalysisResult, int, error) {
+	files, err := archive.FilesInRange(ctx, from, to)
+	if err != nil {
+		return nil, 0, err
+	}
+	if files <= config.TIMELINE_SYNC_ARCHIVE_FILES {
+		analyses, err := archive.QueryAnalyses(ctx, gluserID, from, to)
+		return analyses, files, err
+	}
+
+	key := fmt.Sprintf("%s|%d|%d", gluserID, from.Unix(), to.Unix())
+	l := &s.archiveLoads
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.loads == nil {
+		l.loads = make(map[string]*archiveLoad)
+	}
+	for k, load := range l.loads {
+		if !load.finished.IsZero() && time.Since(load.finished) > config.TIMELINE_ARCHIVE_TTL {
+			delete(l.loads, k)
+		}
+	}
+
+	load := l.loads[key]
+	if load == nil {
+		load = &archiveLoad{done: make(chan struct{})}
+		l.loads[key] = load
+		go func() {
+			ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), config.TIMELINE_ARCHIVE_TIMEOUT)
+			defer cancel()
+			started := time.Now()
+			analyses, err := archive.QueryAnalyses(ctx, gluserID, from, to)
+			l.mu.Lock()
+			load.analyses, load.err, load.finished = analyses, err, time.Now()
+			l.mu.Unlock()
+			close(load.done)
+			log.Printf("🧊 Loaded %d archived analyses of %s from %d files in %s", len(analyses), gluserID, files, time.Since(started).Round(time.Millisecond))
+		}()
+	}
+	select {
+	case <-load.done:
+		if load.err != nil {
+			delete(l.loads, key) // The next request tries again
+		}
+		return load.analyses, files, load.err
+	default:
+		return nil, files, errArchiveLoading
+	}
+}
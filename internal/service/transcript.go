@@ -0,0 +1,353 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/acoustic"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/notify"
+	"im-ai-voice/internal/profile"
+	"im-ai-voice/internal/recording"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== WATCHER TRANSCRIPTS ====================
+
+// ProcessHackathonTranscript analyzes a watcher transcript with the seller's
+// profile as context, updates the profile and stores the analysis
+func (s *Service) ProcessHackathonTranscript(ctx context.Context, ht *client.HackathonTranscript) (*client.SellerProfile, *client.AnalysisResult, error) {
+	// Convert to RawTranscript for analysis, dated when the call happened
+	rt := hackathonToRawTranscript(ht, callTimestamp(ht, time.Now()))
+	if err := tokenizeTranscript(ctx, &rt); err != nil {
+		return nil, nil, err
+	}
+
+	storage.RecordEvent(ctx, client.Event{
+		Type:     client.EventIngested,
+		CallID:   rt.CallID,
+		GluserID: ht.GluserID,
+		Payload:  client.IngestedPayload{Source: "watcher", Language: rt.Language, DurationMS: rt.DurationMS, Origin: ht.Origin},
+	})
+
+	return s.processWithProfile(ctx, ht, rt)
+}
+
+// processWithProfile analyzes a call with its seller's profile as context,
+// updates the profile and stores the analysis. Watcher and API transcripts
+// both end up here; rt is ht as the analysis input. A provisional analysis,
+// made while Gemini is unavailable, leaves the profile to its upgrade.
+func (s *Service) processWithProfile(ctx context.Context, ht *client.HackathonTranscript, rt client.RawTranscript) (*client.SellerProfile, *client.AnalysisResult, error) {
+	// Build seller context from existing profile
+	sellerContext, contextUsed := profile.BuildSellerContextFromProfile(ctx, ht.GluserID, rt.Origin)
+
+	// With acoustic signals the audio is needed before analysis, so it's
+	// fetched now rather than in the background afterwards
+	fetchAudio := recording.FetchEnabled() && ht.CallRecordingURL != ""
+	if acoustic.Enabled() && ht.CallRecordingURL != "" {
+		rt.Acoustic = acousticSignals(ctx, rt.CallID, ht.GluserID, rt.Timestamp, ht.CallRecordingURL)
+		fetchAudio = false
+	}
+
+	analysis, err := s.analyzeOrProvisional(ctx, rt, sellerContext)
+	if err != nil {
+		return nil, nil, fmt.Errorf("analysis failed: %w", err)
+	}
+	withSellerContext(analysis, contextUsed)
+
+	// Enrich analysis with user info
+	enrichAnalysis(analysis, ht)
+	var sp *client.SellerProfile
+	if analysis.Provisional {
+		err = s.saveProvisional(ctx, analysis, rt, ht)
+	} else {
+		sp, err = s.applyAnalysis(ctx, analysis, rt, ht, sellerContext)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if fetchAudio {
+		fetchRecordingAsync(ctx, rt.CallID, ht.GluserID, rt.Timestamp, ht.CallRecordingURL)
+	}
+	return sp, analysis, nil
+}
+
+// applyAnalysis stores an enriched analysis made with sellerContext and
+// updates the seller's profile with it, returning nil for a blocked call
+func (s *Service) applyAnalysis(ctx context.Context, analysis *client.AnalysisResult, rt client.RawTranscript, ht *client.HackathonTranscript, sellerContext string) (*client.SellerProfile, error) {
+	storage.RecordAnalyzedEvent(ctx, analysis)
+
+	// A blocked call says nothing about the seller, so the profile is left
+	// alone; the analysis is stored so the call isn't picked up again
+	if analysis.Blocked {
+		if err := storage.SaveAnalysisWithGluserID(ctx, *analysis, ht.GluserID, ht.ClickToCallID); err != nil {
+			return nil, fmt.Errorf("failed to save blocked analysis: %w", err)
+		}
+		return nil, nil
+	}
+
+	// Update seller profile (creates if new, updates if existing)
+	sp, transitions, err := profile.UpdateSellerProfile(ctx, ht.GluserID, analysis, ht)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update seller profile: %w", err)
+	}
+	profile.NotifyAttention(ctx, sp)
+	notify.NotifyTransitions(ctx, transitions)
+	s.trackUpsellPitches(ctx, analysis, sp.CustomerType)
+	s.collectQuotes(ctx, analysis)
+
+	// Also save individual analysis for aggregation purposes
+	if err := storage.SaveAnalysisWithGluserID(ctx, *analysis, ht.GluserID, ht.ClickToCallID); err != nil {
+		log.Printf("   ⚠️ Failed to save individual analysis: %v", err)
+		// Don't fail - profile was saved successfully
+	}
+	s.shadowAnalyze(ctx, rt, sellerContext, analysis)
+
+	return sp, nil
+}
+
+// analyzeCall runs both analysis passes, with the active rollout's candidate
+// if it picks the call, keeping the extraction so the call can be rescored
+// later without re-extracting, and tracks the commitments made and settled
+// on the call. Gemini is given the call's analysis deadline. Without an AI
+// client (ANALYSIS_ENGINE=rules), the keyword classifier analyzes the call.
+func (s *Service) analyzeCall(ctx context.Context, rt client.RawTranscript, sellerContext string) (*client.AnalysisResult, error) {
+	if s.ai == nil {
+		analysis := s.rules.Classify(rt)
+		analysis.AgentID = rt.AgentID
+		return analysis, nil
+	}
+	ai, rollout := s.ai, ""
+	if candidate, id := s.canary(ctx, rt.CallID); candidate != nil {
+		ai, rollout = candidate, id
+	}
+	callCtx, cancel := withAnalysisDeadline(ctx, rt)
+	defer cancel()
+	analysis, ext, err := ai.AnalyzeCall(callCtx, rt, sellerContext)
+	if err != nil {
+		return nil, err
+	}
+	analysis.AgentID = rt.AgentID
+	analysis.Rollout = rollout
+	s.crossCheck(rt, analysis)
+	if ext != nil {
+		if err := storage.SaveExtraction(ctx, ext); err != nil {
+			log.Printf("   ⚠️ Failed to save extraction for %s: %v", rt.CallID, err)
+		}
+		s.trackCommitments(callCtx, rt, ext)
+	}
+	return analysis, nil
+}
+
+// withSellerContext records the seller context an analysis was given. The
+// keyword classifier doesn't read one.
+func withSellerContext(analysis *client.AnalysisResult, used *client.SellerContextUsed) {
+	if analysis.Engine != client.EngineRules {
+		analysis.SellerContext = used
+	}
+}
+
+// analysisTimeout is how long a call's analysis may take: ANALYSIS_TIMEOUT
+// plus ANALYSIS_TIMEOUT_PER_KB for every 1,000 characters of transcript, up
+// to ANALYSIS_TIMEOUT_MAX. Set the max to ANALYSIS_TIMEOUT for a fixed deadline.
+var analysisTimeout = struct{ base, perKB, max time.Duration }{
+	base:  config.EnvDuration("ANALYSIS_TIMEOUT", config.DEFAULT_ANALYSIS_TIMEOUT),
+	perKB: config.EnvDuration("ANALYSIS_TIMEOUT_PER_KB", config.DEFAULT_ANALYSIS_TIMEOUT_PER_KB),
+	max:   config.EnvDuration("ANALYSIS_TIMEOUT_MAX", config.DEFAULT_ANALYSIS_TIMEOUT_MAX),
+}
+
+// withAnalysisDeadline bounds the analysis of rt by its transcript's length.
+// The caller's own cancellation still applies, stopping in-flight Gemini
+// requests when the watcher shuts down or an API client goes away.
+func withAnalysisDeadline(ctx context.Context, rt client.RawTranscript) (context.Context, context.CancelFunc) {
+	t := analysisTimeout
+	d := t.base + t.perKB*time.Duration(len(rt.Transcript))/1000
+	return context.WithTimeout(ctx, min(d, t.max))
+}
+
+// callEnteredOnLayouts are the call_entered_on formats seen in exports
+var callEnteredOnLayouts = []string{"1/2/2006 15:04:05", "1/2/2006 15:04", "1/2/2006", "2006-01-02 15:04:05", "2006-01-02"}
+
+// callTimestamp returns when the call happened from its call_entered_on
+// (business timezone), or fallback when that's missing or unparseable
+func callTimestamp(ht *client.HackathonTranscript, fallback time.Time) time.Time {
+	v := strings.TrimSpace(ht.CallEnteredOn)
+	for _, layout := range callEnteredOnLayouts {
+		if t, err := time.ParseInLocation(layout, v, config.BusinessTZ); err == nil {
+			return t
+		}
+	}
+	return fallback
+}
+
+// hackathonToRawTranscript converts a watcher transcript into the analysis input
+func hackathonToRawTranscript(ht *client.HackathonTranscript, ts time.Time) client.RawTranscript {
+	return client.RawTranscript{
+		CallID:     ht.ClickToCallID,
+		SellerID:   ht.GluserID,
+		Transcript: strings.ReplaceAll(ht.Transcript, "\\n", "\n"),
+		Language:   "hi-en",
+		DurationMS: ht.CallDuration * 1000,
+		Timestamp:  ts,
+		Origin:     ht.Origin,
+		Channel:    transcriptChannel(ht),
+		Metadata: map[string]interface{}{
+			"gluser_id":              ht.GluserID,
+			"vintage_months":         ht.VintageMonths,
+			"bl_dau_oct":             ht.BLDauOct,
+			"customer_type":          ht.CustomerType,
+			"city_name":              ht.CityName,
+			"iil_vertical_name":      ht.IILVerticalName,
+			"customer_ticket_id":     ht.CustomerTicketID,
+			"customer_ticket_status": ht.CustomerTicketStatus,
+			"is_ticket_repeat60d":    ht.IsTicketRepeat60d,
+			"call_entered_on":        ht.CallEnteredOn,
+			"flag_in_out":            ht.FlagInOut,
+			"call_status":            ht.CallStatus,
+			"call_recording_url":     ht.CallRecordingURL,
+			"ucid":                   ht.UCID,
+			"seller_categories":      ht.SellerCategories,
+			"original_summary":       ht.Summary,
+		},
+	}
+}
+
+// rawToHackathonTranscript describes an API-ingested transcript the way the
+// watcher's exports do, for the profile and enrichment steps. The call's
+// time goes in call_entered_on, in the business timezone.
+func rawToHackathonTranscript(rt *client.RawTranscript) *client.HackathonTranscript {
+	return &client.HackathonTranscript{
+		ClickToCallID: rt.CallID,
+		GluserID:      rt.SellerID,
+		VintageMonths: rt.Vintage,
+		CustomerType:  rt.CustomerType,
+		Transcript:    rt.Transcript,
+		CallEnteredOn: rt.Timestamp.In(config.BusinessTZ).Format("2006-01-02 15:04:05"),
+		CallDuration:  rt.DurationMS / 1000,
+		Channel:       rt.Channel,
+	}
+}
+
+// enrichAnalysis adds user metadata to the analysis result
+func enrichAnalysis(ar *client.AnalysisResult, ht *client.HackathonTranscript) {
+	ar.Origin = ht.Origin
+	ar.Checksum = ht.Checksum()
+	ar.SourceTicket = sourceTicket(ht, ar)
+	ar.Call = callChannel(ht)
+	ar.Onboarding = onboardingTag(ar, ht.VintageMonths)
+
+	// Add user info to LLMRaw for persistence
+	if ar.LLMRaw == nil {
+		ar.LLMRaw = make(map[string]interface{})
+	}
+
+	ar.LLMRaw["user_info"] = map[string]interface{}{
+		"gluser_id":             ht.GluserID,
+		"vintage_months":        ht.VintageMonths,
+		"bl_dau_oct":            ht.BLDauOct,
+		"customer_type":         ht.CustomerType,
+		"city_name":             ht.CityName,
+		"iil_vertical_name":     ht.IILVerticalName,
+		"is_ticket_repeat60d":   ht.IsTicketRepeat60d,
+		"call_duration_seconds": ht.CallDuration,
+		"call_entered_on":       ht.CallEnteredOn,
+		"flag_in_out":           ht.FlagInOut,
+		"call_status":           ht.CallStatus,
+	}
+
+	// Add seller categories
+	categories := make([]string, 0, len(ht.SellerCategories))
+	for _, cat := range ht.SellerCategories {
+		categories = append(categories, cat.McatName)
+	}
+	ar.LLMRaw["seller_categories"] = categories
+
+	// Store original summary for comparison
+	ar.LLMRaw["original_summary"] = ht.Summary
+}
+
+// Source spellings of call directions and statuses, lowercased. Exports
+// disagree on them (Incoming, IN, inbound...).
+var (
+	callDirections = map[string]string{
+		"incoming": client.DirectionInbound, "inbound": client.DirectionInbound, "in": client.DirectionInbound, "i": client.DirectionInbound,
+		"outgoing": client.DirectionOutbound, "outbound": client.DirectionOutbound, "out": client.DirectionOutbound, "o": client.DirectionOutbound,
+	}
+	callStatuses = map[string]string{
+		"answered": client.CallAnswered, "answer": client.CallAnswered, "connected": client.CallAnswered, "completed": client.CallAnswered, "success": client.CallAnswered,
+		"missed": client.CallMissed, "not answered": client.CallMissed, "no answer": client.CallMissed, "unanswered": client.CallMissed,
+		"busy": client.CallMissed, "rejected": client.CallMissed, "abandoned": client.CallMissed, "failed": client.CallMissed,
+		"voicemail": client.CallVoicemail, "voice mail": client.CallVoicemail, "vm": client.CallVoicemail,
+	}
+)
+
+// Source spellings of contact channels, lowercased with _ and - as spaces
+var contactChannels = map[string]string{
+	"": client.ChannelVoice, "voice": client.ChannelVoice, "call": client.ChannelVoice, "phone": client.ChannelVoice, "ivr": client.ChannelVoice,
+	"chat": client.ChannelChat, "web chat": client.ChannelChat, "webchat": client.ChannelChat, "live chat": client.ChannelChat, "livechat": client.ChannelChat, "in app chat": client.ChannelChat, "app chat": client.ChannelChat,
+	"whatsapp": client.ChannelWhatsApp, "whats app": client.ChannelWhatsApp, "wa": client.ChannelWhatsApp, "wa chat": client.ChannelWhatsApp,
+}
+
+// NormalizeContactChannel maps a transcript's channel onto voice, chat or
+// whatsapp, voice when it's empty; false when it isn't recognized
+func NormalizeContactChannel(v string) (string, bool) {
+	channel, ok := contactChannels[normalizeCallValue(strings.TrimSpace(v))]
+	return channel, ok
+}
+
+// transcriptChannel is a watcher transcript's normalized channel. Exports
+// are calls unless they say otherwise, so an unrecognized channel is logged
+// and taken for one.
+func transcriptChannel(ht *client.HackathonTranscript) string {
+	channel, ok := NormalizeContactChannel(ht.Channel)
+	if !ok {
+		log.Printf("   ⚠️ Unrecognized channel %q on call %s, analyzing it as a call", ht.Channel, ht.ClickToCallID)
+		return client.ChannelVoice
+	}
+	return channel
+}
+
+// callChannel normalizes a transcript's flag_in_out and call_status, nil
+// when it has neither. Values that aren't recognized are kept raw and logged.
+func callChannel(ht *client.HackathonTranscript) *client.CallChannel {
+	c := &client.CallChannel{RawDirection: strings.TrimSpace(ht.FlagInOut), RawStatus: strings.TrimSpace(ht.CallStatus)}
+	if c.RawDirection == "" && c.RawStatus == "" {
+		return nil
+	}
+	c.Direction = callDirections[normalizeCallValue(c.RawDirection)]
+	c.Status = callStatuses[normalizeCallValue(c.RawStatus)]
+	if c.RawDirection != "" && c.Direction == "" {
+		log.Printf("   ⚠️ Unrecognized flag_in_out %q on call %s", c.RawDirection, ht.ClickToCallID)
+	}
+	if c.RawStatus != "" && c.Status == "" {
+		log.Printf("   ⚠️ Unrecognized call_status %q on call %s", c.RawStatus, ht.ClickToCallID)
+	}
+	return c
+}
+
+// normalizeCallValue lowercases v and turns _ and - into spaces (NO_ANSWER, no-answer)
+func normalizeCallValue(v string) string {
+	return strings.NewReplacer("_", " ", "-", " ").Replace(strings.ToLower(v))
+}
+
+// sourceTicket is the IndiaMART ticket a transcript was logged against, nil
+// when it names none. Exports abbreviate statuses (C for closed, W for WIP).
+func sourceTicket(ht *client.HackathonTranscript, ar *client.AnalysisResult) *client.SourceTicket {
+	id := strings.TrimSpace(ht.CustomerTicketID)
+	if id == "" {
+		return nil
+	}
+	raw := strings.TrimSpace(ht.CustomerTicketStatus)
+	t := &client.SourceTicket{TicketID: id, RawStatus: raw, CallID: ar.CallID, UpdatedAt: ar.Timestamp}
+	switch strings.ToLower(raw) {
+	case "c", "closed", "close", "resolved":
+		t.Status = client.SourceTicketClosed
+	case "o", "open", "w", "wip", "in progress", "pending", "reopened":
+		t.Status = client.SourceTicketOpen
+	}
+	return t
+}
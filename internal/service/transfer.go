@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/storage"
+	"im-ai-voice/internal/ulid"
+)
+
+// ==================== SELLER EXPORT / IMPORT ====================
+// A seller's profile (with its tracked issues), analyses and extractions
+// can be exported as one document and imported into another environment,
+// to seed demos or move sellers between staging and production. Imports
+// can remap seller, call and issue IDs so they don't collide with what's
+// already there. Nothing is re-analyzed, and no events or alerts fire.
+
+// ExportSeller collects everything stored about a seller, nil if there's no such seller
+func (s *Service) ExportSeller(ctx context.Context, gluserID string) (*client.SellerExport, error) {
+	profile, err := storage.LoadSellerProfile(ctx, gluserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load profile: %w", err)
+	}
+	if profile == nil {
+		return nil, nil
+	}
+
+	analyses, err := storage.LoadSellerAnalyses(ctx, gluserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load analyses: %w", err)
+	}
+	callIDs := make([]string, len(analyses))
+	for i, ar := range analyses {
+		callIDs[i] = ar.CallID
+	}
+	extractions, err := storage.LoadExtractionsFor(ctx, callIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load extractions: %w", err)
+	}
+
+	return &client.SellerExport{
+		Version:     client.SellerExportVersion,
+		ExportedAt:  time.Now(),
+		Profile:     profile,
+		Analyses:    analyses,
+		Extractions: extractions,
+	}, nil
+}
+
+// ImportSeller writes an exported seller into this environment, remapping
+// IDs as opts say. Failures are reported in the item rather than returned.
+func (s *Service) ImportSeller(ctx context.Context, exp *client.SellerExport, opts client.SellerImportOptions) client.SellerImportItem {
+	item := client.SellerImportItem{Status: client.ImportFailed}
+	switch {
+	case exp.Profile == nil || exp.Profile.GluserID == "":
+		item.Error = "export has no seller profile"
+		return item
+	case exp.Version > client.SellerExportVersion:
+		item.Error = fmt.Sprintf("export version %d is newer than this server supports (%d)", exp.Version, client.SellerExportVersion)
+		return item
+	}
+
+	item.SourceID = exp.Profile.GluserID
+	item.GluserID = opts.SellerPrefix + item.SourceID
+	if opts.SellerID != "" {
+		item.GluserID = opts.SellerPrefix + opts.SellerID
+	}
+
+	existing, err := storage.LoadSellerProfile(ctx, item.GluserID)
+	if err != nil {
+		item.Error = "failed to check for an existing profile: " + err.Error()
+		return item
+	}
+	if existing != nil && !opts.Overwrite {
+		item.Status = client.ImportSkipped
+		item.Error = "seller " + item.GluserID + " already exists"
+		return item
+	}
+
+	// Issue IDs are unique across sellers, so a copy under a new gluser_id needs its own
+	profile := remapProfile(exp.Profile.Clone(), item.GluserID, opts.CallPrefix, opts.NewIssueIDs || item.GluserID != item.SourceID)
+	item.Analyses = len(exp.Analyses)
+	item.Extractions = len(exp.Extractions)
+	item.Issues = len(profile.ActiveIssues) + len(profile.ResolvedIssues)
+	item.Status = client.ImportImported
+	if opts.DryRun {
+		return item
+	}
+
+	// Analyses and extractions first, so a profile is never there without its calls
+	for _, ar := range exp.Analyses {
+		callID := opts.CallPrefix + ar.CallID
+		if err := storage.SaveAnalysisWithGluserID(ctx, ar, item.GluserID, callID); err != nil {
+			item.Status = client.ImportFailed
+			item.Error = fmt.Sprintf("failed to save analysis %s: %v", callID, err)
+			return item
+		}
+	}
+	for _, ext := range exp.Extractions {
+		ext.CallID = opts.CallPrefix + ext.CallID
+		ext.SellerID = item.GluserID
+		if err := storage.SaveExtraction(ctx, &ext); err != nil {
+			item.Status = client.ImportFailed
+			item.Error = fmt.Sprintf("failed to save extraction %s: %v", ext.CallID, err)
+			return item
+		}
+	}
+	if err := storage.SaveSellerProfile(ctx, profile); err != nil {
+		item.Status = client.ImportFailed
+		item.Error = "failed to save profile: " + err.Error()
+		return item
+	}
+
+	log.Printf("📥 Imported seller %s as %s: %d analyses, %d extractions, %d issues",
+		item.SourceID, item.GluserID, item.Analyses, item.Extractions, item.Issues)
+	return item
+}
+
+// remapProfile moves a profile to a new gluser_id and prefixes the call IDs it refers to
+func remapProfile(p *client.SellerProfile, gluserID, callPrefix string, newIssueIDs bool) *client.SellerProfile {
+	p.GluserID = gluserID
+	for i := range p.CallHistory {
+		p.CallHistory[i].CallID = callPrefix + p.CallHistory[i].CallID
+	}
+	for _, issues := range [][]client.TrackedIssue{p.ActiveIssues, p.ResolvedIssues} {
+		for i := range issues {
+			if newIssueIDs {
+				issues[i].IssueID = ulid.NewAt(issues[i].FirstReportedAt)
+			}
+			for j := range issues[i].CallIDs {
+				issues[i].CallIDs[j] = callPrefix + issues[i].CallIDs[j]
+			}
+		}
+	}
+	if callPrefix != "" {
+		for _, points := range [][]client.TrendPoint{p.Trends.SentimentHistory, p.Trends.SatisfactionHistory, p.Trends.IssueHistory, p.Trends.ChurnRiskHistory} {
+			for i := range points {
+				if points[i].CallID != "" {
+					points[i].CallID = callPrefix + points[i].CallID
+				}
+			}
+		}
+	}
+	return p
+}
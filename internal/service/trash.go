@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== TRASH ====================
+// Deleting an analysis or ticket moves it to the trash, from which it can be
+// restored for TRASH_RETENTION_DAYS; only the trash_purge job deletes it for
+// good. A deleted analysis's transcript isn't analyzed again, and a deleted
+// ticket isn't regenerated when its date is aggregated again. Seller
+// profiles keep what a deleted call contributed; aggregates drop it the next
+// time its date is aggregated.
+
+var (
+	ErrCallNotFound     = errors.New("call not found")
+	ErrNotInTrash       = errors.New("not in the trash")
+	ErrRestoreConflict  = errors.New("a live item with the same ID exists")
+	ErrInvalidTrashKind = errors.New("kind must be analysis or ticket")
+)
+
+// TrashRetentionDays is how long deleted items stay restorable (TRASH_RETENTION_DAYS)
+func TrashRetentionDays() int {
+	if v := os.Getenv("TRASH_RETENTION_DAYS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return n
+		}
+	}
+	return config.DEFAULT_TRASH_RETENTION_DAYS
+}
+
+func newTrashItem(kind, id, reason string) *client.TrashItem {
+	now := time.Now()
+	return &client.TrashItem{Kind: kind, ID: id, DeletedAt: now, Reason: reason, PurgeAt: now.AddDate(0, 0, TrashRetentionDays())}
+}
+
+// DeleteCallAnalysis moves a call's analysis to the trash
+func (s *Service) DeleteCallAnalysis(ctx context.Context, callID, reason string) (*client.TrashItem, error) {
+	ar, err := s.GetCallAnalysis(ctx, callID)
+	if err != nil || ar == nil {
+		return nil, fmt.Errorf("%w: %s", ErrCallNotFound, callID)
+	}
+
+	item := newTrashItem(client.TrashAnalysis, callID, reason)
+	item.GluserID, item.Analysis = ar.SellerID, ar
+	// In the trash first, so a failure can't lose the analysis
+	if err := storage.SaveTrashItem(ctx, item); err != nil {
+		return nil, err
+	}
+	if err := storage.RemoveAnalysis(ctx, ar); err != nil {
+		return nil, err
+	}
+	s.markAggregateStale(ctx, ar)
+	log.Printf("🗑️ Analysis of call %s moved to the trash", callID)
+	return item, nil
+}
+
+// DeleteTicket moves a ticket to the trash
+func (s *Service) DeleteTicket(ctx context.Context, date, ticketID, reason string) (*client.TrashItem, error) {
+	tickets, err := s.GetTicketsForDate(ctx, date)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrTicketNotFound, ticketID)
+	}
+	var t *client.Ticket
+	for i := range tickets {
+		if tickets[i].TicketID == ticketID {
+			t = &tickets[i]
+		}
+	}
+	if t == nil {
+		return nil, fmt.Errorf("%w: %s", ErrTicketNotFound, ticketID)
+	}
+
+	item := newTrashItem(client.TrashTicket, client.TicketTrashID(date, ticketID), reason)
+	item.Date, item.Ticket = date, t
+	if err := storage.SaveTrashItem(ctx, item); err != nil {
+		return nil, err
+	}
+	if err := storage.RemoveTicket(ctx, date, ticketID); err != nil {
+		return nil, err
+	}
+	log.Printf("🗑️ Ticket %s of %s moved to the trash", ticketID, date)
+	return item, nil
+}
+
+// ListTrash returns the deleted items of a kind ("" for all), most recently deleted first
+func (s *Service) ListTrash(ctx context.Context, kind string) (*client.TrashList, error) {
+	if kind != "" && kind != client.TrashAnalysis && kind != client.TrashTicket {
+		return nil, ErrInvalidTrashKind
+	}
+	items, err := storage.ListTrash(ctx, kind)
+	if err != nil {
+		return nil, err
+	}
+	if items == nil {
+		items = []client.TrashItem{}
+	}
+	return &client.TrashList{Items: items, Total: len(items)}, nil
+}
+
+// RestoreFromTrash puts a deleted item back where it was deleted from
+func (s *Service) RestoreFromTrash(ctx context.Context, kind, id string) (*client.TrashItem, error) {
+	if kind != client.TrashAnalysis && kind != client.TrashTicket {
+		return nil, ErrInvalidTrashKind
+	}
+	item, err := storage.LoadTrashItem(ctx, kind, id)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, fmt.Errorf("%w: %s %s", ErrNotInTrash, kind, id)
+	}
+
+	switch {
+	case item.Analysis != nil:
+		ar := item.Analysis
+		if live, err := s.GetCallAnalysis(ctx, ar.CallID); err == nil && live != nil {
+			return nil, fmt.Errorf("%w: call %s was analyzed again", ErrRestoreConflict, ar.CallID)
+		}
+		if err := storage.SaveAnalysisWithGluserID(ctx, *ar, ar.SellerID, ar.CallID); err != nil {
+			return nil, err
+		}
+		s.markAggregateStale(ctx, ar)
+	case item.Ticket != nil:
+		t := item.Ticket
+		live, _ := s.GetTicketsForDate(ctx, t.Date)
+		for _, l := range live {
+			if l.TicketID == t.TicketID {
+				return nil, fmt.Errorf("%w: ticket %s of %s", ErrRestoreConflict, t.TicketID, t.Date)
+			}
+		}
+		if storage.IsMongoEnabled() {
+			err = storage.SaveTicketToMongo(ctx, t)
+		} else {
+			err = storage.SaveTicket(*t)
+		}
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("trash item %s %s is empty", kind, id)
+	}
+
+	if _, err := storage.DeleteTrashItem(ctx, kind, id); err != nil {
+		return nil, fmt.Errorf("restored, but failed to remove it from the trash: %w", err)
+	}
+	log.Printf("♻️ Restored %s %s from the trash", kind, id)
+	return item, nil
+}
+
+// PurgeTrash deletes for good the items whose restore period is over,
+// returning how many were purged
+func PurgeTrash(ctx context.Context) (int, error) {
+	items, err := storage.ListTrash(ctx, "")
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	purged := 0
+	for _, item := range items {
+		if item.PurgeAt.After(now) {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return purged, err
+		}
+		if _, err := storage.DeleteTrashItem(ctx, item.Kind, item.ID); err != nil {
+			return purged, err
+		}
+		purged++
+	}
+	if purged > 0 {
+		log.Printf("🗑️ Purged %d items from the trash", purged)
+	}
+	return purged, nil
+}
+
+// trashedTickets returns the IDs of the date's deleted tickets
+func trashedTickets(ctx context.Context, date string) map[string]bool {
+	items, err := storage.ListTrash(ctx, client.TrashTicket)
+	if err != nil {
+		log.Printf("⚠️ Failed to load deleted tickets: %v", err)
+		return nil
+	}
+	ids := make(map[string]bool)
+	for _, item := range items {
+		if item.Date == date && item.Ticket != nil {
+			ids[item.Ticket.TicketID] = true
+		}
+	}
+	return ids
+}
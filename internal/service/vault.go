@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/vault"
+)
+
+// tokenizeTranscript replaces the PII in rt's transcript with vault tokens,
+// before it's stored or analyzed. Nothing is replaced with the vault off.
+func tokenizeTranscript(ctx context.Context, rt *client.RawTranscript) error {
+	text, n, err := vault.Tokenize(ctx, rt.Transcript)
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		log.Printf("   🔐 Tokenized %d PII values in call %s", n, rt.CallID)
+	}
+	rt.Transcript = text
+	return nil
+}
+
+// RehydrateTranscript puts the vault's values back in place of the tokens
+// in a call's transcripts
+func (s *Service) RehydrateTranscript(ctx context.Context, t *client.CallTranscript) error {
+	var err error
+	if t.Transcript, err = vault.Rehydrate(ctx, t.Transcript); err != nil {
+		return err
+	}
+	if t.TranscriptEn, err = vault.Rehydrate(ctx, t.TranscriptEn); err != nil {
+		return fmt.Errorf("failed to rehydrate the translation: %w", err)
+	}
+	t.Rehydrated = true
+	return nil
+}
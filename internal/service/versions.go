@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/profile"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== ANALYSIS VERSIONS ====================
+
+// ReanalyzeHackathonTranscript analyzes a watcher transcript whose text
+// changed since its call was analyzed. The new analysis replaces the call's
+// current one, which is kept as an earlier version. The seller profile keeps
+// what the first analysis contributed, as it does for deleted calls; the
+// call's date is re-aggregated with the new analysis.
+func (s *Service) ReanalyzeHackathonTranscript(ctx context.Context, ht *client.HackathonTranscript) (*client.AnalysisResult, error) {
+	prev, err := s.GetCallAnalysis(ctx, ht.ClickToCallID)
+	if err != nil || prev == nil {
+		return nil, fmt.Errorf("%w: %s has no analysis to replace", ErrCallNotFound, ht.ClickToCallID)
+	}
+	checksum := ht.Checksum()
+	if prev.Checksum == checksum {
+		return prev, nil
+	}
+
+	rt := hackathonToRawTranscript(ht, callTimestamp(ht, prev.Timestamp))
+	if err := tokenizeTranscript(ctx, &rt); err != nil {
+		return nil, err
+	}
+	storage.RecordEvent(ctx, client.Event{
+		Type:     client.EventIngested,
+		CallID:   rt.CallID,
+		GluserID: ht.GluserID,
+		Payload:  client.IngestedPayload{Source: "watcher", Language: rt.Language, DurationMS: rt.DurationMS, Origin: ht.Origin},
+	})
+
+	sellerContext, contextUsed := profile.BuildSellerContextFromProfile(ctx, ht.GluserID, rt.Origin)
+	analysis, err := s.analyzeCall(ctx, rt, sellerContext)
+	if err != nil {
+		return nil, fmt.Errorf("analysis failed: %w", err)
+	}
+	withSellerContext(analysis, contextUsed)
+	enrichAnalysis(analysis, ht)
+	analysis.Version = max(prev.Version, 1) + 1
+
+	if err := storage.SaveAnalysisVersion(ctx, &client.AnalysisVersion{
+		CallID:       prev.CallID,
+		Version:      max(prev.Version, 1),
+		Checksum:     prev.Checksum,
+		SupersededAt: time.Now(),
+		Analysis:     *prev,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to keep the previous analysis: %w", err)
+	}
+	if err := storage.SaveAnalysisWithGluserID(ctx, *analysis, ht.GluserID, ht.ClickToCallID); err != nil {
+		return nil, fmt.Errorf("failed to save analysis: %w", err)
+	}
+	storage.RecordAnalyzedEvent(ctx, analysis)
+
+	// A corrected call time can move the call to another date
+	if storage.AggregationDate(prev) != storage.AggregationDate(analysis) {
+		s.markAggregateStale(ctx, prev)
+	}
+	log.Printf("   🔂 Call %s re-analyzed after its transcript changed (version %d)", analysis.CallID, analysis.Version)
+	return analysis, nil
+}
+
+// CallVersions returns a call's current analysis version and the analyses it replaced
+func (s *Service) CallVersions(ctx context.Context, callID string) (*client.CallVersions, error) {
+	current, err := s.GetCallAnalysis(ctx, callID)
+	if err != nil || current == nil {
+		return nil, fmt.Errorf("%w: %s", ErrCallNotFound, callID)
+	}
+	versions, err := storage.LoadAnalysisVersions(ctx, callID)
+	if err != nil {
+		return nil, err
+	}
+	return &client.CallVersions{
+		CallID:         callID,
+		CurrentVersion: max(current.Version, 1),
+		Versions:       versions,
+	}, nil
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/storage"
+	"im-ai-voice/internal/ulid"
+)
+
+// ==================== WEBHOOK SUBSCRIPTIONS ====================
+
+var (
+	ErrInvalidWebhook  = errors.New("invalid webhook subscription")
+	ErrWebhookNotFound = errors.New("webhook subscription not found")
+)
+
+// ListWebhookSubscriptions returns the webhook subscriptions, oldest first, without their secrets
+func (s *Service) ListWebhookSubscriptions(ctx context.Context) ([]client.WebhookSubscription, error) {
+	subs, err := storage.LoadWebhookSubscriptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range subs {
+		subs[i].Secret = ""
+	}
+	return subs, nil
+}
+
+// CreateWebhookSubscription subscribes a URL to seller profile transitions,
+// posted from the next call on
+func (s *Service) CreateWebhookSubscription(ctx context.Context, in client.WebhookSubscriptionRequest) (*client.WebhookSubscription, error) {
+	in.URL = strings.TrimSpace(in.URL)
+	u, err := url.Parse(in.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("%w: url must be an absolute http(s) URL", ErrInvalidWebhook)
+	}
+	var transitions []string
+	for _, t := range in.Transitions {
+		t = strings.TrimSpace(t)
+		if !slices.Contains(client.ProfileTransitions, t) {
+			return nil, fmt.Errorf("%w: unknown transition %q (want one of %s)", ErrInvalidWebhook, t, strings.Join(client.ProfileTransitions, ", "))
+		}
+		if !slices.Contains(transitions, t) {
+			transitions = append(transitions, t)
+		}
+	}
+
+	now := time.Now()
+	sub := &client.WebhookSubscription{
+		ID:          ulid.NewAt(now),
+		URL:         in.URL,
+		Transitions: transitions,
+		Secret:      in.Secret,
+		HasSecret:   in.Secret != "",
+		Description: in.Description,
+		By:          in.By,
+		CreatedAt:   now,
+	}
+	if err := storage.SaveWebhookSubscription(ctx, sub); err != nil {
+		return nil, err
+	}
+	log.Printf("🪝 Webhook %s added by %s: %s for %s", sub.ID, orAnonymous(sub.By), u.Host, orAll(transitions))
+	out := *sub
+	out.Secret = ""
+	return &out, nil
+}
+
+// DeleteWebhookSubscription removes a webhook subscription
+func (s *Service) DeleteWebhookSubscription(ctx context.Context, id string) error {
+	found, err := storage.DeleteWebhookSubscription(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("%w: %s", ErrWebhookNotFound, id)
+	}
+	log.Printf("🪝 Webhook %s removed", id)
+	return nil
+}
+
+func orAll(transitions []string) string {
+	if len(transitions) == 0 {
+		return "every transition"
+	}
+	return strings.Join(transitions, ", ")
+}
@@ -0,0 +1,283 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/aggregate"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/notify"
+	"im-ai-voice/internal/report"
+)
+
+// ==================== WEEKLY EXECUTIVE REPORT ====================
+// Monday report of the week ending the day before: the week's daily
+// aggregates against the week before, systemic issues, churn movement and
+// the agent leaderboard, with a narrative and recommendations from Gemini.
+// Rendered to HTML/PDF and emailed to WEEKLY_REPORT_RECIPIENTS, or to
+// DIGEST_RECIPIENTS when that's unset.
+
+const (
+	weeklySystemicIssues = 5
+	weeklyAgents         = 3 // Top and bottom agents shown
+)
+
+// BuildWeeklyReport collects the week ending on end (YYYY-MM-DD) and asks
+// Gemini for its narrative. The report is still built without one when
+// there's no AI client or the request fails.
+func (s *Service) BuildWeeklyReport(ctx context.Context, end string) (*report.WeeklyReport, error) {
+	endDay, err := config.ParseBusinessDate(end)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date %q (use YYYY-MM-DD)", end)
+	}
+	from := endDay.AddDate(0, 0, -6)
+	rep := &report.WeeklyReport{
+		From:            config.BusinessDate(from),
+		To:              end,
+		Days:            []report.WeeklyDay{},
+		TopBuckets:      []client.BucketCount{},
+		SystemicIssues:  []client.SystemicIssue{},
+		ChurnMovement:   []report.ChurnMovement{},
+		TopAgents:       []client.AgentStanding{},
+		BottomAgents:    []client.AgentStanding{},
+		Recommendations: []string{},
+		GeneratedAt:     time.Now(),
+	}
+
+	buckets := make(map[string]int)
+	churnNow := make(map[string]int)
+	for d := from; !d.After(endDay); d = d.AddDate(0, 0, 1) {
+		date := config.BusinessDate(d)
+		day := report.WeeklyDay{Date: date}
+		if agg, err := s.GetDailyAggregate(ctx, date); err == nil && agg != nil {
+			addWeeklyTotals(&rep.Current, agg)
+			day.Calls, day.Issues, day.AvgSatisfaction = agg.TotalCalls, agg.TotalIssues, agg.AvgSatisfaction
+			day.AtRiskCalls = agg.ChurnRiskBreakdown["high"] + agg.ChurnRiskBreakdown["medium"]
+			for bucket, summary := range agg.FeatureBuckets {
+				buckets[bucket] += summary.TotalCount
+			}
+			for category, n := range agg.ChurnReasonBreakdown {
+				churnNow[category] += n
+			}
+		}
+		rep.Days = append(rep.Days, day)
+	}
+	if rep.Current.Days == 0 {
+		return nil, fmt.Errorf("no aggregates found for %s to %s", rep.From, rep.To)
+	}
+	finishWeeklyTotals(&rep.Current)
+
+	churnBefore := make(map[string]int)
+	for d := from.AddDate(0, 0, -7); d.Before(from); d = d.AddDate(0, 0, 1) {
+		if agg, err := s.GetDailyAggregate(ctx, config.BusinessDate(d)); err == nil && agg != nil {
+			addWeeklyTotals(&rep.Previous, agg)
+			for category, n := range agg.ChurnReasonBreakdown {
+				churnBefore[category] += n
+			}
+		}
+	}
+	finishWeeklyTotals(&rep.Previous)
+
+	for bucket, n := range buckets {
+		rep.TopBuckets = append(rep.TopBuckets, client.BucketCount{Bucket: bucket, Count: n})
+	}
+	sort.Slice(rep.TopBuckets, func(i, j int) bool {
+		return rep.TopBuckets[i].Count > rep.TopBuckets[j].Count
+	})
+	if len(rep.TopBuckets) > 5 {
+		rep.TopBuckets = rep.TopBuckets[:5]
+	}
+
+	for category := range churnNow {
+		if _, ok := churnBefore[category]; !ok {
+			churnBefore[category] = 0
+		}
+	}
+	for category, previous := range churnBefore {
+		calls := churnNow[category]
+		rep.ChurnMovement = append(rep.ChurnMovement, report.ChurnMovement{
+			Category: category, Calls: calls, Previous: previous, Delta: calls - previous,
+		})
+	}
+	sort.Slice(rep.ChurnMovement, func(i, j int) bool {
+		a, b := rep.ChurnMovement[i], rep.ChurnMovement[j]
+		if abs(a.Delta) != abs(b.Delta) {
+			return abs(a.Delta) > abs(b.Delta)
+		}
+		return a.Category < b.Category
+	})
+
+	if issues, err := s.GetSystemicIssues(ctx, "", 0); err != nil {
+		log.Printf("⚠️ Weekly report: failed to load systemic issues: %v", err)
+	} else {
+		sort.SliceStable(issues, func(i, j int) bool { return issues[i].SellerCount > issues[j].SellerCount })
+		for _, issue := range issues {
+			if len(rep.SystemicIssues) == weeklySystemicIssues {
+				break
+			}
+			issue.Members = nil
+			rep.SystemicIssues = append(rep.SystemicIssues, issue)
+		}
+	}
+
+	boardEnd := time.Date(endDay.Year(), endDay.Month(), endDay.Day(), 0, 0, 0, 0, time.UTC)
+	board, err := aggregate.BuildAgentLeaderboard(ctx, client.PeriodWeek, boardEnd, aggregate.DefaultLeaderboardMinCalls)
+	if err != nil {
+		log.Printf("⚠️ Weekly report: failed to build agent leaderboard: %v", err)
+	} else {
+		rep.RankedAgents = len(board.Agents)
+		top := min(weeklyAgents, len(board.Agents))
+		rep.TopAgents = append(rep.TopAgents, board.Agents[:top]...)
+		rep.BottomAgents = append(rep.BottomAgents, board.Agents[max(top, len(board.Agents)-weeklyAgents):]...)
+	}
+
+	if s.ai != nil {
+		summary, err := s.ai.SummarizeWeek(ctx, weeklyFacts(rep))
+		if err != nil {
+			log.Printf("⚠️ Weekly report: narrative failed: %v", err)
+		} else {
+			rep.Narrative, rep.Recommendations = summary.Narrative, summary.Recommendations
+		}
+	}
+	return rep, nil
+}
+
+// addWeeklyTotals adds a daily aggregate to a week's totals, summing
+// satisfaction by calls until finishWeeklyTotals averages it
+func addWeeklyTotals(t *report.WeeklyTotals, agg *client.DailyAggregate) {
+	t.Days++
+	t.Calls += agg.TotalCalls
+	t.Issues += agg.TotalIssues
+	t.NegativeCalls += agg.SentimentBreakdown["Negative"]
+	t.HighChurnCalls += agg.ChurnRiskBreakdown["high"]
+	t.AtRiskCalls += agg.ChurnRiskBreakdown["high"] + agg.ChurnRiskBreakdown["medium"]
+	t.UpsellOpportunities += agg.UpsellOpportunities
+	t.AvgSatisfaction += agg.AvgSatisfaction * float64(agg.TotalCalls)
+}
+
+func finishWeeklyTotals(t *report.WeeklyTotals) {
+	if t.Calls > 0 {
+		t.AvgSatisfaction = math.Round(t.AvgSatisfaction/float64(t.Calls)*10) / 10
+	} else {
+		t.AvgSatisfaction = 0
+	}
+}
+
+// weeklyFacts lays out a weekly report's figures for Gemini
+func weeklyFacts(rep *report.WeeklyReport) string {
+	var sb strings.Builder
+	cur, prev := rep.Current, rep.Previous
+	fmt.Fprintf(&sb, "WEEK %s to %s (%d days with data), compared with the 7 days before\n", rep.From, rep.To, cur.Days)
+	fmt.Fprintf(&sb, "Calls: %d (previous %d)\n", cur.Calls, prev.Calls)
+	fmt.Fprintf(&sb, "Issues raised: %d (previous %d)\n", cur.Issues, prev.Issues)
+	fmt.Fprintf(&sb, "Average satisfaction (1-10): %.1f (previous %.1f)\n", cur.AvgSatisfaction, prev.AvgSatisfaction)
+	fmt.Fprintf(&sb, "Negative sentiment calls: %d (previous %d)\n", cur.NegativeCalls, prev.NegativeCalls)
+	fmt.Fprintf(&sb, "Medium/high churn risk calls: %d (previous %d), high: %d (previous %d)\n",
+		cur.AtRiskCalls, prev.AtRiskCalls, cur.HighChurnCalls, prev.HighChurnCalls)
+	fmt.Fprintf(&sb, "Upsell opportunities: %d (previous %d)\n", cur.UpsellOpportunities, prev.UpsellOpportunities)
+
+	sb.WriteString("\nDAILY (calls, issues, avg satisfaction, at-risk calls):\n")
+	for _, d := range rep.Days {
+		fmt.Fprintf(&sb, "  %s: %d, %d, %.1f, %d\n", d.Date, d.Calls, d.Issues, d.AvgSatisfaction, d.AtRiskCalls)
+	}
+
+	sb.WriteString("\nTOP ISSUE BUCKETS:\n")
+	for _, b := range rep.TopBuckets {
+		fmt.Fprintf(&sb, "  %s: %d\n", b.Bucket, b.Count)
+	}
+
+	sb.WriteString("\nSYSTEMIC ISSUES (open, across sellers):\n")
+	if len(rep.SystemicIssues) == 0 {
+		sb.WriteString("  none\n")
+	}
+	for _, s := range rep.SystemicIssues {
+		fmt.Fprintf(&sb, "  [%s, %s] %s - %d sellers, %d calls\n", s.Bucket, s.Severity, s.Title, s.SellerCount, s.MentionCount)
+	}
+
+	sb.WriteString("\nCHURN REASONS (at-risk calls this week vs previous):\n")
+	if len(rep.ChurnMovement) == 0 {
+		sb.WriteString("  none\n")
+	}
+	for _, m := range rep.ChurnMovement {
+		fmt.Fprintf(&sb, "  %s: %d vs %d\n", m.Category, m.Calls, m.Previous)
+	}
+
+	fmt.Fprintf(&sb, "\nAGENTS (%d ranked by composite QA score 0-100):\n", rep.RankedAgents)
+	for _, a := range append(append([]client.AgentStanding{}, rep.TopAgents...), rep.BottomAgents...) {
+		fmt.Fprintf(&sb, "  #%d %s: score %.1f, %d calls, escalation rate %.0f%%, moved %+d places\n",
+			a.Rank, a.AgentID, a.Score, a.Calls, a.EscalationRate*100, a.Movement)
+	}
+	return sb.String()
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// SendWeeklyReport builds the report for the week ending on end and emails
+// it (HTML body + PDF attachment)
+func (s *Service) SendWeeklyReport(ctx context.Context, end string) (*report.WeeklyReport, []string, error) {
+	recipients := weeklyRecipients()
+	if len(recipients) == 0 {
+		return nil, nil, fmt.Errorf("WEEKLY_REPORT_RECIPIENTS and DIGEST_RECIPIENTS not set")
+	}
+
+	mailer, err := notify.NewMailerFromEnv()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rep, err := s.BuildWeeklyReport(ctx, end)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	body, err := report.RenderWeeklyHTML(rep)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	subject := fmt.Sprintf("IndiaMART Voice AI - Weekly Report %s to %s", rep.From, rep.To)
+	attachment := notify.EmailAttachment{
+		Filename:    fmt.Sprintf("weekly_%s.pdf", rep.To),
+		ContentType: "application/pdf",
+		Data:        report.RenderWeeklyPDF(rep),
+	}
+	if err := mailer.Send(recipients, subject, string(body), attachment); err != nil {
+		return nil, nil, err
+	}
+
+	log.Printf("📧 Weekly report for %s to %s sent to %d recipients", rep.From, rep.To, len(recipients))
+	return rep, recipients, nil
+}
+
+// ==================== WEEKLY REPORT SCHEDULER ====================
+
+// weeklyRecipients returns WEEKLY_REPORT_RECIPIENTS, or DIGEST_RECIPIENTS when unset
+func weeklyRecipients() []string {
+	if recipients := notify.SplitList(os.Getenv("WEEKLY_REPORT_RECIPIENTS")); len(recipients) > 0 {
+		return recipients
+	}
+	return notify.SplitList(os.Getenv("DIGEST_RECIPIENTS"))
+}
+
+// weeklyReportHour returns the hour (business timezone) on Mondays at which the weekly report is sent
+func weeklyReportHour() int {
+	if v := os.Getenv("WEEKLY_REPORT_HOUR"); v != "" {
+		if h, err := strconv.Atoi(v); err == nil && h >= 0 && h < 24 {
+			return h
+		}
+	}
+	return config.DEFAULT_WEEKLY_REPORT_HOUR
+}
@@ -0,0 +1,139 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ==================== ALERT SUBSCRIPTIONS ====================
+// Who receives which alerts, read whenever an alert fires. With MongoDB
+// they're in alert_subscriptions, otherwise one JSON file per subscription
+// under ALERT_SUBS_DIR. Like webhook subscriptions, WipeDerivedData leaves
+// them alone.
+
+// SaveAlertSubscription stores a subscription, replacing one with the same ID - MongoDB first, local fallback
+func SaveAlertSubscription(ctx context.Context, s *client.AlertSubscription) error {
+	if IsMongoEnabled() {
+		return saveAlertSubscriptionToMongo(ctx, s)
+	}
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal alert subscription: %w", err)
+	}
+	return writeFile(alertSubscriptionPath(s.ID), b, 0644)
+}
+
+// LoadAlertSubscriptions returns every subscription, oldest first - MongoDB first, local fallback
+func LoadAlertSubscriptions(ctx context.Context) ([]client.AlertSubscription, error) {
+	var subs []client.AlertSubscription
+	if IsMongoEnabled() {
+		var err error
+		if subs, err = getAlertSubscriptionsFromMongo(ctx); err != nil {
+			return nil, err
+		}
+	} else {
+		entries, err := os.ReadDir(config.ALERT_SUBS_DIR)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		subs = make([]client.AlertSubscription, 0, len(entries))
+		for _, e := range entries {
+			if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+				continue
+			}
+			b, err := os.ReadFile(filepath.Join(config.ALERT_SUBS_DIR, e.Name()))
+			if err != nil {
+				return nil, err
+			}
+			var sub client.AlertSubscription
+			if err := json.Unmarshal(b, &sub); err != nil {
+				continue // Skip corrupt files
+			}
+			subs = append(subs, sub)
+		}
+	}
+	sort.Slice(subs, func(i, j int) bool { return subs[i].ID < subs[j].ID }) // ULIDs, so by creation
+	return subs, nil
+}
+
+// DeleteAlertSubscription removes a subscription, reporting whether it existed - MongoDB first, local fallback
+func DeleteAlertSubscription(ctx context.Context, id string) (bool, error) {
+	if IsMongoEnabled() {
+		ctx, cancel := context.WithTimeout(ctx, opTimeout)
+		defer cancel()
+		res, err := MongoDB.database.Collection(COLLECTION_ALERT_SUBS).DeleteOne(ctx, bson.M{"id": id})
+		if err != nil {
+			return false, err
+		}
+		return res.DeletedCount > 0, nil
+	}
+	if err := os.Remove(alertSubscriptionPath(id)); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func alertSubscriptionPath(id string) string {
+	return filepath.Join(config.ALERT_SUBS_DIR, fmt.Sprintf("subscription_%s.json", Sanitize(id)))
+}
+
+// ==================== ALERT SUBSCRIPTIONS (MongoDB) ====================
+
+func saveAlertSubscriptionToMongo(ctx context.Context, s *client.AlertSubscription) error {
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	doc, err := ToBsonM(s)
+	if err != nil {
+		return fmt.Errorf("failed to marshal alert subscription: %w", err)
+	}
+
+	opts := options.Replace().SetUpsert(true)
+	if _, err := MongoDB.database.Collection(COLLECTION_ALERT_SUBS).ReplaceOne(ctx, bson.M{"id": s.ID}, doc, opts); err != nil {
+		return fmt.Errorf("failed to save alert subscription to MongoDB: %w", err)
+	}
+	return nil
+}
+
+func getAlertSubscriptionsFromMongo(ctx context.Context) ([]client.AlertSubscription, error) {
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	cursor, err := MongoDB.database.Collection(COLLECTION_ALERT_SUBS).Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	subs := []client.AlertSubscription{}
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			continue
+		}
+		jsonBytes, err := json.Marshal(doc)
+		if err != nil {
+			continue
+		}
+		var sub client.AlertSubscription
+		if err := json.Unmarshal(jsonBytes, &sub); err != nil {
+			continue
+		}
+		subs = append(subs, sub)
+	}
+	return subs, cursor.Err()
+}
@@ -0,0 +1,191 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/chaos"
+	"im-ai-voice/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ==================== AGGREGATION DATE ====================
+// Daily aggregates group analyses by the date of the call (timestamp) unless
+// AGGREGATE_DATE_FIELD=analyzed, which groups them by when they were analyzed
+// (analyzed_at), as aggregates did before timestamps carried the call date.
+
+var aggregateDateField = envAggregateDateField()
+
+func envAggregateDateField() string {
+	switch v := os.Getenv("AGGREGATE_DATE_FIELD"); v {
+	case "", client.AggregateFieldCall:
+		return client.AggregateFieldCall
+	case client.AggregateFieldAnalyzed:
+		return v
+	default:
+		log.Printf("⚠️ Invalid AGGREGATE_DATE_FIELD=%q, using %s", v, client.AggregateFieldCall)
+		return client.AggregateFieldCall
+	}
+}
+
+// AggregateDateField returns the configured field, client.AggregateFieldCall or client.AggregateFieldAnalyzed
+func AggregateDateField() string {
+	return aggregateDateField
+}
+
+// AggregationDate returns the business day an analysis is aggregated under
+func AggregationDate(ar *client.AnalysisResult) string {
+	if aggregateDateField == client.AggregateFieldAnalyzed {
+		return config.BusinessDate(ar.AnalyzedAt)
+	}
+	return config.BusinessDate(ar.Timestamp)
+}
+
+// aggregateDateKey is the analysis document key aggregateDateField refers to
+func aggregateDateKey() string {
+	if aggregateDateField == client.AggregateFieldAnalyzed {
+		return "analyzed_at"
+	}
+	return "timestamp"
+}
+
+// ==================== SELLER ANALYSES ====================
+
+// LoadAnalysesForDate loads analyses for a date - MongoDB first, local fallback
+func LoadAnalysesForDate(ctx context.Context, date string) ([]client.AnalysisResult, error) {
+	if IsMongoEnabled() {
+		analyses, err := GetAllAnalysesForDateFromMongo(ctx, date)
+		if err == nil && len(analyses) > 0 {
+			return analyses, nil
+		}
+		if err != nil {
+			log.Printf("⚠️ MongoDB load failed, falling back to local: %v", err)
+		}
+	}
+	return LoadAllAnalysisForDate(date)
+}
+
+// SaveAnalysisWithGluserID saves analysis directly to MongoDB (primary)
+// Format: gluser_{gluser_id}_call_{call_id}
+func SaveAnalysisWithGluserID(ctx context.Context, ar client.AnalysisResult, gluserID string, callID string) error {
+	if gluserID == "" {
+		gluserID = ar.SellerID
+	}
+	if gluserID == "" {
+		gluserID = "unknown"
+	}
+	if callID == "" {
+		callID = ar.CallID
+	}
+	if callID == "" {
+		callID = "unknown"
+	}
+
+	// Ensure seller_id and call_id are set in the analysis
+	ar.SellerID = gluserID
+	ar.CallID = callID
+
+	// Raw Gemini responses are kept apart and expire
+	ar, raw := splitLLMRaw(ar)
+	saveSplitLLMRaw(ctx, raw)
+
+	// MongoDB is primary storage
+	if IsMongoEnabled() {
+		return SaveAnalysisToMongo(ctx, &ar)
+	}
+
+	// Fallback to local file
+	return saveAnalysisToFile(ar, gluserID, callID)
+}
+
+// SaveAnalysisToMongo saves analysis directly to MongoDB (synchronous)
+func SaveAnalysisToMongo(ctx context.Context, ar *client.AnalysisResult) error {
+	if MongoDB == nil || !MongoDB.enabled {
+		return fmt.Errorf("MongoDB not enabled")
+	}
+
+	lean, raw := splitLLMRaw(*ar)
+	saveSplitLLMRaw(ctx, raw)
+
+	if err := chaos.MongoWriteError("save analysis " + ar.CallID); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	collection := MongoDB.database.Collection(COLLECTION_ANALYSES)
+
+	// Convert to bson.M using JSON tags
+	doc, err := ToBsonM(&lean)
+	if err != nil {
+		return fmt.Errorf("failed to marshal analysis: %w", err)
+	}
+
+	// Upsert by call_id
+	filter := bson.M{"call_id": ar.CallID}
+	opts := options.Replace().SetUpsert(true)
+
+	_, err = collection.ReplaceOne(ctx, filter, doc, opts)
+	if err != nil {
+		return fmt.Errorf("failed to save analysis to MongoDB: %w", err)
+	}
+
+	return nil
+}
+
+// saveAnalysisToFile saves analysis to local file (fallback)
+func saveAnalysisToFile(ar client.AnalysisResult, gluserID string, callID string) error {
+	filename := fmt.Sprintf("gluser_%s_call_%s.analysis.json", gluserID, callID)
+	path := filepath.Join(config.ANALYSIS_DIR, filename)
+	return writeAnalysisFile(path, ar)
+}
+
+// LoadAnalysesForGluser loads all previous analyses for a specific gluser ID
+func LoadAnalysesForGluser(gluserID string) ([]client.AnalysisResult, error) {
+	pattern := filepath.Join(config.ANALYSIS_DIR, fmt.Sprintf("gluser_%s_call_*.analysis.json", gluserID))
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	var analyses []client.AnalysisResult
+	for _, f := range files {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			continue
+		}
+
+		var ar client.AnalysisResult
+		if err := json.Unmarshal(b, &ar); err != nil {
+			continue
+		}
+		analyses = append(analyses, ar)
+	}
+
+	return analyses, nil
+}
+
+// LoadSellerAnalyses loads every analysis of a seller, oldest first - MongoDB first, local fallback
+func LoadSellerAnalyses(ctx context.Context, gluserID string) ([]client.AnalysisResult, error) {
+	var analyses []client.AnalysisResult
+	var err error
+	if IsMongoEnabled() {
+		analyses, err = GetSellerAnalysesFromMongo(ctx, gluserID)
+	} else {
+		analyses, err = LoadAnalysesForGluser(gluserID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(analyses, func(i, j int) bool { return analyses[i].Timestamp.Before(analyses[j].Timestamp) })
+	return analyses, nil
+}
@@ -0,0 +1,177 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ==================== ANNOTATIONS ====================
+// QA reviewers' comments on calls. With MongoDB they're in call_annotations,
+// otherwise one JSON file per annotation under ANNOTATIONS_DIR. They aren't
+// part of any analysis, so re-analyzing a call keeps them.
+
+// AnnotationQuery filters annotations; empty fields match everything
+type AnnotationQuery struct {
+	CallID   string
+	GluserID string
+	AgentID  string
+	Category string
+}
+
+func (q AnnotationQuery) matches(a client.Annotation) bool {
+	return (q.CallID == "" || a.CallID == q.CallID) &&
+		(q.GluserID == "" || a.GluserID == q.GluserID) &&
+		(q.AgentID == "" || a.AgentID == q.AgentID) &&
+		(q.Category == "" || a.Category == q.Category)
+}
+
+// SaveAnnotation stores an annotation - MongoDB first, local fallback
+func SaveAnnotation(ctx context.Context, a *client.Annotation) error {
+	if IsMongoEnabled() {
+		ctx, cancel := context.WithTimeout(ctx, opTimeout)
+		defer cancel()
+		doc, err := ToBsonM(a)
+		if err != nil {
+			return fmt.Errorf("failed to marshal annotation: %w", err)
+		}
+		opts := options.Replace().SetUpsert(true)
+		if _, err := MongoDB.database.Collection(COLLECTION_ANNOTATIONS).ReplaceOne(ctx, bson.M{"id": a.ID}, doc, opts); err != nil {
+			return fmt.Errorf("failed to save annotation to MongoDB: %w", err)
+		}
+		return nil
+	}
+	b, err := json.MarshalIndent(a, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal annotation: %w", err)
+	}
+	return writeFile(annotationPath(a.ID), b, 0644)
+}
+
+// LoadAnnotations returns the annotations matching q, newest first - MongoDB first, local fallback
+func LoadAnnotations(ctx context.Context, q AnnotationQuery) ([]client.Annotation, error) {
+	var list []client.Annotation
+	if IsMongoEnabled() {
+		var err error
+		if list, err = getAnnotationsFromMongo(ctx, q); err != nil {
+			return nil, err
+		}
+	} else {
+		entries, err := os.ReadDir(config.ANNOTATIONS_DIR)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		list = make([]client.Annotation, 0, len(entries))
+		for _, e := range entries {
+			if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+				continue
+			}
+			b, err := os.ReadFile(filepath.Join(config.ANNOTATIONS_DIR, e.Name()))
+			if err != nil {
+				return nil, err
+			}
+			var a client.Annotation
+			if err := json.Unmarshal(b, &a); err != nil {
+				continue // Skip corrupt files
+			}
+			if q.matches(a) {
+				list = append(list, a)
+			}
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].CreatedAt.Equal(list[j].CreatedAt) {
+			return list[i].CreatedAt.After(list[j].CreatedAt)
+		}
+		return list[i].ID > list[j].ID
+	})
+	return list, nil
+}
+
+// DeleteAnnotation removes a call's annotation, reporting whether it existed
+func DeleteAnnotation(ctx context.Context, callID, id string) (bool, error) {
+	if IsMongoEnabled() {
+		ctx, cancel := context.WithTimeout(ctx, opTimeout)
+		defer cancel()
+		res, err := MongoDB.database.Collection(COLLECTION_ANNOTATIONS).DeleteOne(ctx, bson.M{"id": id, "call_id": callID})
+		if err != nil {
+			return false, err
+		}
+		return res.DeletedCount > 0, nil
+	}
+
+	b, err := os.ReadFile(annotationPath(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	var a client.Annotation
+	if err := json.Unmarshal(b, &a); err != nil || a.CallID != callID {
+		return false, nil
+	}
+	if err := os.Remove(annotationPath(id)); err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+	return true, nil
+}
+
+func annotationPath(id string) string {
+	return filepath.Join(config.ANNOTATIONS_DIR, fmt.Sprintf("annotation_%s.json", Sanitize(id)))
+}
+
+// ==================== ANNOTATIONS (MongoDB) ====================
+
+func getAnnotationsFromMongo(ctx context.Context, q AnnotationQuery) ([]client.Annotation, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	filter := bson.M{}
+	if q.CallID != "" {
+		filter["call_id"] = q.CallID
+	}
+	if q.GluserID != "" {
+		filter["gluser_id"] = q.GluserID
+	}
+	if q.AgentID != "" {
+		filter["agent_id"] = q.AgentID
+	}
+	if q.Category != "" {
+		filter["category"] = q.Category
+	}
+
+	cursor, err := MongoDB.database.Collection(COLLECTION_ANNOTATIONS).Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	list := []client.Annotation{}
+	for cursor.Next(ctx) {
+		var m bson.M
+		if err := cursor.Decode(&m); err != nil {
+			continue
+		}
+		jsonBytes, err := json.Marshal(m)
+		if err != nil {
+			continue
+		}
+		var a client.Annotation
+		if err := json.Unmarshal(jsonBytes, &a); err != nil {
+			continue
+		}
+		list = append(list, a)
+	}
+	return list, cursor.Err()
+}
@@ -0,0 +1,172 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ==================== ATTENTION ACKNOWLEDGMENTS ====================
+// At most one per seller (see profile.NotifyAttention). With MongoDB they're
+// in attention_acks, otherwise one JSON file per seller under ATTENTION_DIR.
+
+// SaveAttentionAck stores a seller's acknowledgment, replacing any earlier one - MongoDB first, local fallback
+func SaveAttentionAck(ctx context.Context, ack *client.AttentionAck) error {
+	if IsMongoEnabled() {
+		return saveAttentionAckToMongo(ctx, ack)
+	}
+	b, err := json.MarshalIndent(ack, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal attention ack: %w", err)
+	}
+	return writeFile(attentionAckPath(ack.GluserID), b, 0644)
+}
+
+// LoadAttentionAck returns a seller's acknowledgment, nil if it has none - MongoDB first, local fallback
+func LoadAttentionAck(ctx context.Context, gluserID string) (*client.AttentionAck, error) {
+	if IsMongoEnabled() {
+		return getAttentionAckFromMongo(ctx, gluserID)
+	}
+	b, err := os.ReadFile(attentionAckPath(gluserID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var ack client.AttentionAck
+	if err := json.Unmarshal(b, &ack); err != nil {
+		return nil, err
+	}
+	return &ack, nil
+}
+
+// LoadAttentionAcks returns every acknowledgment by seller - MongoDB first, local fallback
+func LoadAttentionAcks(ctx context.Context) (map[string]*client.AttentionAck, error) {
+	if IsMongoEnabled() {
+		return getAttentionAcksFromMongo(ctx)
+	}
+
+	entries, err := os.ReadDir(config.ATTENTION_DIR)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]*client.AttentionAck{}, nil
+		}
+		return nil, err
+	}
+	acks := make(map[string]*client.AttentionAck, len(entries))
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		b, err := os.ReadFile(filepath.Join(config.ATTENTION_DIR, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var ack client.AttentionAck
+		if err := json.Unmarshal(b, &ack); err != nil {
+			continue // Skip corrupt files
+		}
+		acks[ack.GluserID] = &ack
+	}
+	return acks, nil
+}
+
+// DeleteAttentionAck removes a seller's acknowledgment, if any - MongoDB first, local fallback
+func DeleteAttentionAck(ctx context.Context, gluserID string) error {
+	if IsMongoEnabled() {
+		ctx, cancel := context.WithTimeout(ctx, opTimeout)
+		defer cancel()
+		_, err := MongoDB.database.Collection(COLLECTION_ATTENTION).DeleteOne(ctx, bson.M{"gluser_id": gluserID})
+		return err
+	}
+	if err := os.Remove(attentionAckPath(gluserID)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func attentionAckPath(gluserID string) string {
+	return filepath.Join(config.ATTENTION_DIR, fmt.Sprintf("ack_%s.json", Sanitize(gluserID)))
+}
+
+// ==================== ATTENTION ACKNOWLEDGMENTS (MongoDB) ====================
+
+func saveAttentionAckToMongo(ctx context.Context, ack *client.AttentionAck) error {
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	doc, err := ToBsonM(ack)
+	if err != nil {
+		return fmt.Errorf("failed to marshal attention ack: %w", err)
+	}
+
+	filter := bson.M{"gluser_id": ack.GluserID}
+	opts := options.Replace().SetUpsert(true)
+	if _, err := MongoDB.database.Collection(COLLECTION_ATTENTION).ReplaceOne(ctx, filter, doc, opts); err != nil {
+		return fmt.Errorf("failed to save attention ack to MongoDB: %w", err)
+	}
+	return nil
+}
+
+func getAttentionAckFromMongo(ctx context.Context, gluserID string) (*client.AttentionAck, error) {
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	var doc bson.M
+	if err := MongoDB.database.Collection(COLLECTION_ATTENTION).FindOne(ctx, bson.M{"gluser_id": gluserID}).Decode(&doc); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	jsonBytes, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	var ack client.AttentionAck
+	if err := json.Unmarshal(jsonBytes, &ack); err != nil {
+		return nil, err
+	}
+	return &ack, nil
+}
+
+func getAttentionAcksFromMongo(ctx context.Context) (map[string]*client.AttentionAck, error) {
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	cursor, err := MongoDB.database.Collection(COLLECTION_ATTENTION).Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	acks := make(map[string]*client.AttentionAck)
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			continue
+		}
+		jsonBytes, err := json.Marshal(doc)
+		if err != nil {
+			continue
+		}
+		var ack client.AttentionAck
+		if err := json.Unmarshal(jsonBytes, &ack); err != nil {
+			continue
+		}
+		acks[ack.GluserID] = &ack
+	}
+	return acks, cursor.Err()
+}
@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/ulid"
+)
+
+// ==================== AUDIT LOG ====================
+// Accesses to sensitive data (full transcripts, ...) are recorded here, in
+// the audit_log collection with MongoDB, otherwise in daily JSONL files under
+// AUDIT_DIR. Unlike the event log, writes are synchronous and errors are
+// returned: callers refuse the access when it can't be recorded.
+//
+// The audit log is not derived data, so WipeDerivedData leaves it alone.
+
+var auditFileMu sync.Mutex
+
+// RecordAudit appends an entry to the audit log - MongoDB first, local fallback
+func RecordAudit(ctx context.Context, e client.AuditEntry) error {
+	if e.Timestamp.IsZero() {
+		e.Timestamp = time.Now()
+	}
+	if e.AuditID == "" {
+		e.AuditID = ulid.NewAt(e.Timestamp)
+	}
+
+	if IsMongoEnabled() {
+		return saveAuditEntryToMongo(ctx, &e)
+	}
+	return appendAuditEntryToFile(e)
+}
+
+// appendAuditEntryToFile appends to the day's JSONL file
+func appendAuditEntryToFile(e client.AuditEntry) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit entry: %w", err)
+	}
+
+	auditFileMu.Lock()
+	defer auditFileMu.Unlock()
+
+	path := filepath.Join(config.AUDIT_DIR, fmt.Sprintf("audit-%s.jsonl", config.BusinessDate(e.Timestamp)))
+	f, err := openAppend(path, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+// ==================== AUDIT LOG (MongoDB) ====================
+
+func saveAuditEntryToMongo(ctx context.Context, e *client.AuditEntry) error {
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	doc, err := ToBsonM(e)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit entry: %w", err)
+	}
+
+	if _, err := MongoDB.database.Collection(COLLECTION_AUDIT).InsertOne(ctx, doc); err != nil {
+		return fmt.Errorf("failed to save audit entry to MongoDB: %w", err)
+	}
+	return nil
+}
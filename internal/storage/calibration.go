@@ -0,0 +1,126 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ==================== SEVERITY CALIBRATION ====================
+// One run per date. With MongoDB they're in severity_calibration,
+// otherwise calibration_{date}.json under CALIBRATION_DIR.
+
+// SaveSeverityCalibration stores a calibration run, replacing any of the same date - MongoDB first, local fallback
+func SaveSeverityCalibration(ctx context.Context, c *client.SeverityCalibration) error {
+	if IsMongoEnabled() {
+		return saveSeverityCalibrationToMongo(ctx, c)
+	}
+	b, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal severity calibration: %w", err)
+	}
+	return writeFile(severityCalibrationPath(c.Date), b, 0644)
+}
+
+// LoadSeverityCalibration returns the calibration run of date, nil if there's none - MongoDB first, local fallback
+func LoadSeverityCalibration(ctx context.Context, date string) (*client.SeverityCalibration, error) {
+	if IsMongoEnabled() {
+		return getSeverityCalibrationFromMongo(ctx, bson.M{"date": date})
+	}
+	return readSeverityCalibration(severityCalibrationPath(date))
+}
+
+// LoadLatestSeverityCalibration returns the most recent calibration run, nil if there's none
+func LoadLatestSeverityCalibration(ctx context.Context) (*client.SeverityCalibration, error) {
+	if IsMongoEnabled() {
+		return getSeverityCalibrationFromMongo(ctx, bson.M{})
+	}
+	entries, err := os.ReadDir(config.CALIBRATION_DIR)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	latest := ""
+	for _, e := range entries {
+		if name := e.Name(); strings.HasPrefix(name, "calibration_") && strings.HasSuffix(name, ".json") && name > latest {
+			latest = name
+		}
+	}
+	if latest == "" {
+		return nil, nil
+	}
+	return readSeverityCalibration(filepath.Join(config.CALIBRATION_DIR, latest))
+}
+
+func severityCalibrationPath(date string) string {
+	return filepath.Join(config.CALIBRATION_DIR, fmt.Sprintf("calibration_%s.json", Sanitize(date)))
+}
+
+func readSeverityCalibration(path string) (*client.SeverityCalibration, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var c client.SeverityCalibration
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("failed to parse severity calibration %s: %w", filepath.Base(path), err)
+	}
+	return &c, nil
+}
+
+// ==================== SEVERITY CALIBRATION (MongoDB) ====================
+
+func saveSeverityCalibrationToMongo(ctx context.Context, c *client.SeverityCalibration) error {
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	doc, err := ToBsonM(c)
+	if err != nil {
+		return fmt.Errorf("failed to marshal severity calibration: %w", err)
+	}
+
+	opts := options.Replace().SetUpsert(true)
+	if _, err := MongoDB.database.Collection(COLLECTION_CALIBRATION).ReplaceOne(ctx, bson.M{"date": c.Date}, doc, opts); err != nil {
+		return fmt.Errorf("failed to save severity calibration to MongoDB: %w", err)
+	}
+	return nil
+}
+
+// getSeverityCalibrationFromMongo returns the latest run matching filter, nil if none does
+func getSeverityCalibrationFromMongo(ctx context.Context, filter bson.M) (*client.SeverityCalibration, error) {
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	var doc bson.M
+	opts := options.FindOne().SetSort(bson.D{{Key: "date", Value: -1}})
+	if err := MongoDB.database.Collection(COLLECTION_CALIBRATION).FindOne(ctx, filter, opts).Decode(&doc); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	jsonBytes, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	var c client.SeverityCalibration
+	if err := json.Unmarshal(jsonBytes, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
@@ -0,0 +1,160 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ==================== CALL CHATS ====================
+// Reviewers' conversations about calls, kept until their expires_at. With
+// MongoDB they're in call_chats, dropped by its TTL index, otherwise one
+// JSON file per session under CALL_CHATS_DIR, removed when an expired one
+// is read.
+
+// SaveCallChat stores a session, replacing its previous state - MongoDB first, local fallback
+func SaveCallChat(ctx context.Context, chat *client.CallChat) error {
+	if IsMongoEnabled() {
+		ctx, cancel := context.WithTimeout(ctx, opTimeout)
+		defer cancel()
+
+		doc, err := ToBsonM(chat)
+		if err != nil {
+			return fmt.Errorf("failed to marshal call chat: %w", err)
+		}
+		doc["expires_at"] = chat.ExpiresAt // A date, for the TTL index
+
+		opts := options.Replace().SetUpsert(true)
+		if _, err := MongoDB.database.Collection(COLLECTION_CALL_CHATS).ReplaceOne(ctx, bson.M{"session_id": chat.SessionID}, doc, opts); err != nil {
+			return fmt.Errorf("failed to save call chat to MongoDB: %w", err)
+		}
+		return nil
+	}
+	b, err := json.MarshalIndent(chat, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal call chat: %w", err)
+	}
+	return writeFile(callChatPath(chat.SessionID), b, 0644)
+}
+
+// LoadCallChat returns a session, or nil if it doesn't exist or has expired - MongoDB first, local fallback
+func LoadCallChat(ctx context.Context, sessionID string) (*client.CallChat, error) {
+	if IsMongoEnabled() {
+		ctx, cancel := context.WithTimeout(ctx, opTimeout)
+		defer cancel()
+
+		var doc bson.M
+		err := MongoDB.database.Collection(COLLECTION_CALL_CHATS).FindOne(ctx, bson.M{"session_id": sessionID}).Decode(&doc)
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		chat, err := decodeCallChat(doc)
+		if err != nil {
+			return nil, err
+		}
+		// The TTL monitor runs once a minute, so an expired session may linger
+		if time.Now().After(chat.ExpiresAt) {
+			return nil, nil
+		}
+		return chat, nil
+	}
+
+	path := callChatPath(sessionID)
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var chat client.CallChat
+	if err := json.Unmarshal(b, &chat); err != nil {
+		return nil, fmt.Errorf("failed to parse call chat %s: %w", sessionID, err)
+	}
+	if time.Now().After(chat.ExpiresAt) {
+		os.Remove(path)
+		return nil, nil
+	}
+	return &chat, nil
+}
+
+// LoadSellerCallChats returns the unexpired sessions about a seller's calls,
+// oldest first - MongoDB first, local fallback
+func LoadSellerCallChats(ctx context.Context, gluserID string) ([]client.CallChat, error) {
+	chats := []client.CallChat{}
+	now := time.Now()
+	if IsMongoEnabled() {
+		ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+		defer cancel()
+
+		cursor, err := MongoDB.database.Collection(COLLECTION_CALL_CHATS).Find(ctx, bson.M{"gluser_id": gluserID})
+		if err != nil {
+			return nil, err
+		}
+		defer cursor.Close(ctx)
+		for cursor.Next(ctx) {
+			var doc bson.M
+			if err := cursor.Decode(&doc); err != nil {
+				continue
+			}
+			chat, err := decodeCallChat(doc)
+			if err != nil || now.After(chat.ExpiresAt) {
+				continue
+			}
+			chats = append(chats, *chat)
+		}
+		if err := cursor.Err(); err != nil {
+			return nil, err
+		}
+	} else {
+		files, err := filepath.Glob(filepath.Join(config.CALL_CHATS_DIR, "chat_*.json"))
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range files {
+			b, err := os.ReadFile(f)
+			if err != nil {
+				continue
+			}
+			var chat client.CallChat
+			if err := json.Unmarshal(b, &chat); err != nil {
+				continue // Skip corrupt files
+			}
+			if chat.GluserID == gluserID && !now.After(chat.ExpiresAt) {
+				chats = append(chats, chat)
+			}
+		}
+	}
+	sort.Slice(chats, func(i, j int) bool { return chats[i].CreatedAt.Before(chats[j].CreatedAt) })
+	return chats, nil
+}
+
+func decodeCallChat(doc bson.M) (*client.CallChat, error) {
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	var chat client.CallChat
+	if err := json.Unmarshal(b, &chat); err != nil {
+		return nil, err
+	}
+	return &chat, nil
+}
+
+func callChatPath(sessionID string) string {
+	return filepath.Join(config.CALL_CHATS_DIR, fmt.Sprintf("chat_%s.json", Sanitize(sessionID)))
+}
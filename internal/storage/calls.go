@@ -0,0 +1,573 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ==================== CALL LISTING ====================
+// Filtered, paginated listing of analyzed calls for GET /calls. MongoDB
+// answers from call_analyses indexes; without it the listing is answered
+// from an index of the analysis files (see callIndex).
+
+const (
+	callsDefaultPageSize = 50
+	callsMaxPageSize     = 500
+)
+
+// CallQuery filters GET /calls
+type CallQuery struct {
+	SellerID  string
+	From      time.Time // Inclusive, zero for no lower bound
+	To        time.Time // Exclusive, zero for no upper bound
+	Sentiment string
+	Bucket    string
+	System    string // Source system the transcript came from
+	Region    string
+	Direction string // Normalized call direction, inbound or outbound
+	Status    string // Normalized call status, answered, missed or voicemail
+	Channel   string // Contact channel, voice, chat or whatsapp
+	Escalated *bool  // nil for either
+	Page      int    // 1-based
+	PageSize  int
+}
+
+func (q CallQuery) matches(ar *client.AnalysisResult) bool {
+	if q.SellerID != "" && ar.SellerID != q.SellerID {
+		return false
+	}
+	if !q.From.IsZero() && ar.Timestamp.Before(q.From) {
+		return false
+	}
+	if !q.To.IsZero() && !ar.Timestamp.Before(q.To) {
+		return false
+	}
+	if q.Sentiment != "" && ar.Intent.Sentiment != q.Sentiment {
+		return false
+	}
+	if q.Escalated != nil && wasEscalated(ar) != *q.Escalated {
+		return false
+	}
+	if q.System != "" && (ar.Origin == nil || ar.Origin.System != q.System) {
+		return false
+	}
+	if q.Region != "" && (ar.Origin == nil || ar.Origin.Region != q.Region) {
+		return false
+	}
+	if q.Direction != "" && (ar.Call == nil || ar.Call.Direction != q.Direction) {
+		return false
+	}
+	if q.Status != "" && (ar.Call == nil || ar.Call.Status != q.Status) {
+		return false
+	}
+	if q.Channel != "" && client.ContactChannel(ar.Channel) != q.Channel {
+		return false
+	}
+	if q.Bucket != "" {
+		for _, issue := range ar.Issues {
+			if issue.Bucket == q.Bucket {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func wasEscalated(ar *client.AnalysisResult) bool {
+	esc, _ := ar.LLMRaw["escalation_required"].(bool)
+	return esc
+}
+
+// NewCallListItem builds the compact listing form of an analysis (buckets deduplicated)
+func NewCallListItem(ar *client.AnalysisResult) client.CallListItem {
+	buckets := make([]string, 0, len(ar.Issues))
+	for _, issue := range ar.Issues {
+		if !slices.Contains(buckets, issue.Bucket) {
+			buckets = append(buckets, issue.Bucket)
+		}
+	}
+	return client.CallListItem{
+		CallID:            ar.CallID,
+		SellerID:          ar.SellerID,
+		AgentID:           ar.AgentID,
+		Timestamp:         ar.Timestamp,
+		Summary:           ar.CallSummary,
+		Sentiment:         ar.Intent.Sentiment,
+		SatisfactionScore: ar.Intent.SatisfactionScore,
+		ChurnRisk:         ar.Churn.IsLikelyToChurn,
+		IssueCount:        len(ar.Issues),
+		Buckets:           buckets,
+		WasEscalated:      wasEscalated(ar),
+		AgentPerformance:  ar.AgentPerformance,
+		Origin:            ar.Origin,
+		Call:              ar.Call,
+		Channel:           ar.Channel,
+	}
+}
+
+// QueryCalls returns a page of analyzed calls, newest first - MongoDB first, local fallback
+func QueryCalls(ctx context.Context, q CallQuery) (*client.CallPage, error) {
+	if q.Page <= 0 {
+		q.Page = 1
+	}
+	if q.PageSize <= 0 {
+		q.PageSize = callsDefaultPageSize
+	}
+	if q.PageSize > callsMaxPageSize {
+		q.PageSize = callsMaxPageSize
+	}
+
+	if IsMongoEnabled() {
+		return queryCallsFromMongo(ctx, q)
+	}
+	return queryCallsFromFiles(q)
+}
+
+// queryCallsFromFiles pages through the local analyses matching the query
+func queryCallsFromFiles(q CallQuery) (*client.CallPage, error) {
+	matched, err := matchCallsFromFiles(q)
+	if err != nil {
+		return nil, err
+	}
+
+	page := newCallPage(q, len(matched))
+	start := (q.Page - 1) * q.PageSize
+	if start < len(matched) {
+		end := min(start+q.PageSize, len(matched))
+		page.Calls = append(page.Calls, matched[start:end]...)
+	}
+	page.Count = len(page.Calls)
+	page.HasMore = start+page.Count < page.Total
+	return page, nil
+}
+
+// matchCallsFromFiles lists every local analysis matching the query, newest first
+func matchCallsFromFiles(q CallQuery) ([]client.CallListItem, error) {
+	entries, err := callsIndex.refresh()
+	if err != nil {
+		return nil, err
+	}
+
+	var matched []client.CallListItem
+	for _, ar := range entries {
+		if q.matches(ar) {
+			matched = append(matched, NewCallListItem(ar))
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		if !matched[i].Timestamp.Equal(matched[j].Timestamp) {
+			return matched[i].Timestamp.After(matched[j].Timestamp)
+		}
+		return matched[i].CallID > matched[j].CallID // The index is unordered, keep pages stable
+	})
+	return matched, nil
+}
+
+// callIndex holds the listing fields of every local analysis file, keyed by
+// file name, and is kept in CALL_INDEX_DIR across restarts. It's loaded on
+// first use, reading again only the files that are new or whose size or
+// modification time changed since they were indexed. After that, analyses
+// written and removed through this package update it as they go, and a
+// query only stats the analysis directory. Analysis files are written
+// through a rename (see writeAnalysisFile), so files added, rewritten or
+// removed by another process, such as imvoicectl, change the directory's
+// modification time, which brings the index up to date with the files
+// again. Files edited in place by hand are read again on the next start.
+type callIndex struct {
+	mu      sync.Mutex
+	loaded  bool
+	dirty   bool // Changed since it was last saved
+	savedAt time.Time
+	dirMod  time.Time // ANALYSIS_DIR's modification time the index is up to date with
+	files   map[string]*callIndexEntry
+}
+
+// callIndexSaveInterval spaces out saves of the call index while analyses
+// keep changing. Changes not yet saved at shutdown are read from the files
+// on the next start.
+const callIndexSaveInterval = time.Minute
+
+type callIndexEntry struct {
+	ModTime  time.Time              `json:"mod_time"`
+	Size     int64                  `json:"size"`
+	Analysis *client.AnalysisResult `json:"analysis"` // Listing fields only, see listingFields
+}
+
+var callsIndex = &callIndex{}
+
+func callIndexPath() string {
+	return filepath.Join(config.CALL_INDEX_DIR, "calls.json")
+}
+
+// refresh brings the index up to date with the analysis files, saves it if
+// it changed and wasn't saved for callIndexSaveInterval, and returns their
+// analyses
+func (ix *callIndex) refresh() ([]*client.AnalysisResult, error) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	if !ix.loaded {
+		ix.load()
+	}
+	dir, err := os.Stat(config.ANALYSIS_DIR)
+	if err != nil {
+		return nil, err
+	}
+	if !ix.loaded || !dir.ModTime().Equal(ix.dirMod) {
+		if err := ix.sync(dir.ModTime()); err != nil {
+			return nil, err
+		}
+	}
+	if ix.dirty && time.Since(ix.savedAt) >= callIndexSaveInterval {
+		ix.save()
+	}
+
+	entries := make([]*client.AnalysisResult, 0, len(ix.files))
+	for _, e := range ix.files {
+		entries = append(entries, e.Analysis)
+	}
+	return entries, nil
+}
+
+// load reads the saved index, starting empty when there's none or it can't
+// be read. The first sync checks it against the files.
+func (ix *callIndex) load() {
+	ix.files = make(map[string]*callIndexEntry)
+	b, err := os.ReadFile(callIndexPath())
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("⚠️ Failed to read the call index, rebuilding it: %v", err)
+		}
+		return
+	}
+	var saved map[string]*callIndexEntry
+	if err := json.Unmarshal(b, &saved); err != nil {
+		log.Printf("⚠️ Call index is corrupt, rebuilding it: %v", err)
+		return
+	}
+	for name, e := range saved {
+		if e != nil && e.Analysis != nil {
+			ix.files[name] = e
+		}
+	}
+}
+
+// sync lists and stats the analysis files, and decodes those that are new
+// or changed since they were indexed. dirMod is ANALYSIS_DIR's modification
+// time, taken before listing it.
+func (ix *callIndex) sync(dirMod time.Time) error {
+	paths, err := ListAnalysisFiles()
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		name := filepath.Base(path)
+		seen[name] = true
+		if e := ix.files[name]; e != nil && e.ModTime.Equal(info.ModTime()) && e.Size == info.Size() {
+			continue
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var ar client.AnalysisResult
+		if err := json.Unmarshal(b, &ar); err != nil {
+			delete(ix.files, name) // Corrupt, tried again once it changes
+			continue
+		}
+		ix.files[name] = &callIndexEntry{ModTime: info.ModTime(), Size: info.Size(), Analysis: listingFields(&ar)}
+		ix.dirty = true
+	}
+	for name := range ix.files {
+		if !seen[name] {
+			delete(ix.files, name)
+			ix.dirty = true
+		}
+	}
+	ix.loaded = true
+	ix.dirMod = dirMod
+	return nil
+}
+
+// save writes the index to CALL_INDEX_DIR. An index that fails to save is
+// rebuilt from the files on the next start.
+func (ix *callIndex) save() {
+	b, err := json.Marshal(ix.files)
+	if err == nil {
+		err = writeFileAtomic(callIndexPath(), b, 0644)
+	}
+	ix.savedAt = time.Now()
+	if err != nil {
+		log.Printf("⚠️ Failed to save the call index: %v", err)
+		return
+	}
+	ix.dirty = false
+}
+
+// update runs change, which adds, rewrites or removes the analysis file at
+// path, and brings the index up to date with it. When the index was up to
+// date with the directory before, it stays so without another sync.
+func (ix *callIndex) update(path string, ar *client.AnalysisResult, change func() error) error {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	if !ix.loaded {
+		return change()
+	}
+
+	before, err := os.Stat(config.ANALYSIS_DIR)
+	inSync := err == nil && before.ModTime().Equal(ix.dirMod)
+	if err := change(); err != nil {
+		return err
+	}
+	name := filepath.Base(path)
+	if ar == nil {
+		delete(ix.files, name)
+	} else if info, err := os.Stat(path); err == nil {
+		ix.files[name] = &callIndexEntry{ModTime: info.ModTime(), Size: info.Size(), Analysis: listingFields(ar)}
+	}
+	ix.dirty = true
+	if after, err := os.Stat(config.ANALYSIS_DIR); err == nil && inSync {
+		ix.dirMod = after.ModTime()
+	}
+	return nil
+}
+
+// writeAnalysisFile writes an analysis to a local file through a temporary
+// file and a rename, so that the call index notices rewrites from other
+// processes as well as new files, and updates the index
+func writeAnalysisFile(path string, ar client.AnalysisResult) error {
+	b, err := json.MarshalIndent(ar, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal analysis: %w", err)
+	}
+	return callsIndex.update(path, &ar, func() error { return writeFileAtomic(path, b, 0644) })
+}
+
+// removeAnalysisFile removes a local analysis file and drops it from the call index
+func removeAnalysisFile(path string) error {
+	return callsIndex.update(path, nil, func() error { return os.Remove(path) })
+}
+
+// listingFields keeps the fields of an analysis that CallQuery filters on
+// and NewCallListItem reads, leaving the transcript and raw output behind
+func listingFields(ar *client.AnalysisResult) *client.AnalysisResult {
+	issues := make([]client.Issue, len(ar.Issues))
+	for i, issue := range ar.Issues {
+		issues[i] = client.Issue{Bucket: issue.Bucket}
+	}
+	slim := &client.AnalysisResult{
+		CallID:           ar.CallID,
+		SellerID:         ar.SellerID,
+		AgentID:          ar.AgentID,
+		Timestamp:        ar.Timestamp,
+		CallSummary:      ar.CallSummary,
+		Intent:           client.SellerIntent{Sentiment: ar.Intent.Sentiment, SatisfactionScore: ar.Intent.SatisfactionScore},
+		Churn:            client.ChurnPrediction{IsLikelyToChurn: ar.Churn.IsLikelyToChurn},
+		Issues:           issues,
+		AgentPerformance: ar.AgentPerformance,
+		Origin:           ar.Origin,
+		Call:             ar.Call,
+		Channel:          ar.Channel,
+	}
+	if wasEscalated(ar) {
+		slim.LLMRaw = map[string]interface{}{"escalation_required": true}
+	}
+	return slim
+}
+
+// EachCall calls fn with every analyzed call matching the query, newest
+// first, ignoring paging, and stops at fn's first error - MongoDB first,
+// local fallback. MongoDB reads the calls as fn takes them.
+func EachCall(ctx context.Context, q CallQuery, fn func(client.CallListItem) error) error {
+	if IsMongoEnabled() {
+		return eachCallFromMongo(ctx, q, fn)
+	}
+	matched, err := matchCallsFromFiles(q)
+	if err != nil {
+		return err
+	}
+	for _, item := range matched {
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newCallPage(q CallQuery, total int) *client.CallPage {
+	return &client.CallPage{
+		Calls:    []client.CallListItem{},
+		Total:    total,
+		Page:     q.Page,
+		PageSize: q.PageSize,
+	}
+}
+
+// ==================== CALL LISTING (MongoDB) ====================
+
+// callListProjection leaves out the transcript and most of the raw LLM output
+var callListProjection = bson.M{
+	"call_id":                              1,
+	"seller_id":                            1,
+	"agent_id":                             1,
+	"timestamp":                            1,
+	"call_summary":                         1,
+	"intent.sentiment":                     1,
+	"intent.satisfaction_score":            1,
+	"churn.is_likely_to_churn":             1,
+	"issues.bucket":                        1,
+	"agent_performance":                    1,
+	"origin":                               1,
+	"call":                                 1,
+	"channel":                              1,
+	"llm_raw_response.escalation_required": 1,
+}
+
+func queryCallsFromMongo(ctx context.Context, q CallQuery) (*client.CallPage, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	filter := callFilter(q)
+
+	collection := MongoDB.database.Collection(COLLECTION_ANALYSES)
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := options.Find().
+		SetProjection(callListProjection).
+		SetSort(bson.D{{Key: "timestamp", Value: -1}}).
+		SetSkip(int64((q.Page - 1) * q.PageSize)).
+		SetLimit(int64(q.PageSize))
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	page := newCallPage(q, int(total))
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			continue
+		}
+		jsonBytes, err := json.Marshal(doc)
+		if err != nil {
+			continue
+		}
+		var ar client.AnalysisResult
+		if err := json.Unmarshal(jsonBytes, &ar); err != nil {
+			continue
+		}
+		page.Calls = append(page.Calls, NewCallListItem(&ar))
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	page.Count = len(page.Calls)
+	page.HasMore = (q.Page-1)*q.PageSize+page.Count < page.Total
+	return page, nil
+}
+
+// callFilter translates a query into a call_analyses filter
+func callFilter(q CallQuery) bson.M {
+	filter := bson.M{}
+	if q.SellerID != "" {
+		filter["seller_id"] = q.SellerID
+	}
+	timeRange := bson.M{}
+	if !q.From.IsZero() {
+		timeRange["$gte"] = q.From.Format(time.RFC3339)
+	}
+	if !q.To.IsZero() {
+		timeRange["$lt"] = q.To.Format(time.RFC3339)
+	}
+	if len(timeRange) > 0 {
+		filter["timestamp"] = timeRange
+	}
+	if q.Sentiment != "" {
+		filter["intent.sentiment"] = q.Sentiment
+	}
+	if q.Bucket != "" {
+		filter["issues.bucket"] = q.Bucket
+	}
+	if q.System != "" {
+		filter["origin.system"] = q.System
+	}
+	if q.Region != "" {
+		filter["origin.region"] = q.Region
+	}
+	if q.Direction != "" {
+		filter["call.direction"] = q.Direction
+	}
+	if q.Status != "" {
+		filter["call.status"] = q.Status
+	}
+	if q.Channel == client.ChannelVoice {
+		filter["channel"] = bson.M{"$in": []any{client.ChannelVoice, "", nil}} // Analyses from before channels were recorded
+	} else if q.Channel != "" {
+		filter["channel"] = q.Channel
+	}
+	if q.Escalated != nil {
+		if *q.Escalated {
+			filter["llm_raw_response.escalation_required"] = true
+		} else {
+			filter["llm_raw_response.escalation_required"] = bson.M{"$ne": true}
+		}
+	}
+	return filter
+}
+
+// eachCallFromMongo runs under the caller's deadline rather than queryTimeout,
+// since fn may be writing to a slow client
+func eachCallFromMongo(ctx context.Context, q CallQuery, fn func(client.CallListItem) error) error {
+	opts := options.Find().
+		SetProjection(callListProjection).
+		SetSort(bson.D{{Key: "timestamp", Value: -1}})
+	cursor, err := MongoDB.database.Collection(COLLECTION_ANALYSES).Find(ctx, callFilter(q), opts)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			continue
+		}
+		jsonBytes, err := json.Marshal(doc)
+		if err != nil {
+			continue
+		}
+		var ar client.AnalysisResult
+		if err := json.Unmarshal(jsonBytes, &ar); err != nil {
+			continue
+		}
+		if err := fn(NewCallListItem(&ar)); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
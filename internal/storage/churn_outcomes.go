@@ -0,0 +1,133 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ==================== CHURN OUTCOMES ====================
+// Sellers' actual renewals and cancellations, one per seller and date. With
+// MongoDB they're in churn_outcomes, otherwise one JSON file each under
+// CHURN_OUTCOMES_DIR. They come from the billing system rather than any
+// analysis, so WipeDerivedData leaves them alone.
+
+// SaveChurnOutcome stores an outcome, replacing the seller's outcome on the
+// same date, and reports whether there was one - MongoDB first, local fallback
+func SaveChurnOutcome(ctx context.Context, o *client.ChurnOutcome) (replaced bool, err error) {
+	if IsMongoEnabled() {
+		ctx, cancel := context.WithTimeout(ctx, opTimeout)
+		defer cancel()
+
+		doc, err := ToBsonM(o)
+		if err != nil {
+			return false, fmt.Errorf("failed to marshal churn outcome: %w", err)
+		}
+		filter := bson.M{"gluser_id": o.GluserID, "date": o.Date}
+		res, err := MongoDB.database.Collection(COLLECTION_CHURN_OUTCOMES).ReplaceOne(ctx, filter, doc, options.Replace().SetUpsert(true))
+		if err != nil {
+			return false, fmt.Errorf("failed to save churn outcome to MongoDB: %w", err)
+		}
+		return res.MatchedCount > 0, nil
+	}
+
+	path := churnOutcomePath(o.GluserID, o.Date)
+	_, statErr := os.Stat(path)
+	b, err := json.MarshalIndent(o, "", "  ")
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal churn outcome: %w", err)
+	}
+	if err := writeFile(path, b, 0644); err != nil {
+		return false, err
+	}
+	return statErr == nil, nil
+}
+
+// LoadChurnOutcomes returns the outcomes dated from to to (YYYY-MM-DD,
+// inclusive), oldest first - MongoDB first, local fallback
+func LoadChurnOutcomes(ctx context.Context, from, to string) ([]client.ChurnOutcome, error) {
+	return loadChurnOutcomes(ctx, bson.M{"date": bson.M{"$gte": from, "$lte": to}}, func(o client.ChurnOutcome) bool {
+		return o.Date >= from && o.Date <= to
+	})
+}
+
+// LoadSellerChurnOutcomes returns a seller's outcomes, oldest first - MongoDB first, local fallback
+func LoadSellerChurnOutcomes(ctx context.Context, gluserID string) ([]client.ChurnOutcome, error) {
+	return loadChurnOutcomes(ctx, bson.M{"gluser_id": gluserID}, func(o client.ChurnOutcome) bool {
+		return o.GluserID == gluserID
+	})
+}
+
+func loadChurnOutcomes(ctx context.Context, filter bson.M, match func(client.ChurnOutcome) bool) ([]client.ChurnOutcome, error) {
+	list := []client.ChurnOutcome{}
+	if IsMongoEnabled() {
+		ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+		defer cancel()
+
+		cursor, err := MongoDB.database.Collection(COLLECTION_CHURN_OUTCOMES).Find(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		defer cursor.Close(ctx)
+		for cursor.Next(ctx) {
+			var doc bson.M
+			if err := cursor.Decode(&doc); err != nil {
+				continue
+			}
+			b, err := json.Marshal(doc)
+			if err != nil {
+				continue
+			}
+			var o client.ChurnOutcome
+			if err := json.Unmarshal(b, &o); err != nil {
+				continue
+			}
+			list = append(list, o)
+		}
+		if err := cursor.Err(); err != nil {
+			return nil, err
+		}
+	} else {
+		entries, err := os.ReadDir(config.CHURN_OUTCOMES_DIR)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		for _, e := range entries {
+			if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+				continue
+			}
+			b, err := os.ReadFile(filepath.Join(config.CHURN_OUTCOMES_DIR, e.Name()))
+			if err != nil {
+				return nil, err
+			}
+			var o client.ChurnOutcome
+			if err := json.Unmarshal(b, &o); err != nil {
+				continue // Skip corrupt files
+			}
+			if match(o) {
+				list = append(list, o)
+			}
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Date != list[j].Date {
+			return list[i].Date < list[j].Date
+		}
+		return list[i].GluserID < list[j].GluserID
+	})
+	return list, nil
+}
+
+func churnOutcomePath(gluserID, date string) string {
+	return filepath.Join(config.CHURN_OUTCOMES_DIR, fmt.Sprintf("outcome_%s_%s.json", Sanitize(gluserID), Sanitize(date)))
+}
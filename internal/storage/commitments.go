@@ -0,0 +1,193 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ==================== COMMITMENTS ====================
+// Promises agents made sellers. With MongoDB they're in commitments,
+// otherwise one JSON file per commitment under COMMITMENTS_DIR. Whether a
+// commitment was kept is found by later calls and isn't in any analysis, so
+// WipeDerivedData leaves them alone; a replay updates them in place.
+
+// CommitmentQuery filters commitments; empty fields match everything
+type CommitmentQuery struct {
+	GluserID string
+	AgentID  string
+	Status   string
+}
+
+func (q CommitmentQuery) matches(c client.Commitment) bool {
+	return (q.GluserID == "" || c.GluserID == q.GluserID) &&
+		(q.AgentID == "" || c.AgentID == q.AgentID) &&
+		(q.Status == "" || c.Status == q.Status)
+}
+
+// SaveCommitment stores a commitment, replacing one with the same ID - MongoDB first, local fallback
+func SaveCommitment(ctx context.Context, c *client.Commitment) error {
+	if IsMongoEnabled() {
+		return saveCommitmentToMongo(ctx, c)
+	}
+	b, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal commitment: %w", err)
+	}
+	return writeFile(commitmentPath(c.ID), b, 0644)
+}
+
+// LoadCommitment returns a commitment, nil if there's none with the ID - MongoDB first, local fallback
+func LoadCommitment(ctx context.Context, id string) (*client.Commitment, error) {
+	if IsMongoEnabled() {
+		return getCommitmentFromMongo(ctx, id)
+	}
+	b, err := os.ReadFile(commitmentPath(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var c client.Commitment
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// LoadCommitments returns the commitments matching q, newest call first - MongoDB first, local fallback
+func LoadCommitments(ctx context.Context, q CommitmentQuery) ([]client.Commitment, error) {
+	var list []client.Commitment
+	if IsMongoEnabled() {
+		var err error
+		if list, err = getCommitmentsFromMongo(ctx, q); err != nil {
+			return nil, err
+		}
+	} else {
+		entries, err := os.ReadDir(config.COMMITMENTS_DIR)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		list = make([]client.Commitment, 0, len(entries))
+		for _, e := range entries {
+			if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+				continue
+			}
+			b, err := os.ReadFile(filepath.Join(config.COMMITMENTS_DIR, e.Name()))
+			if err != nil {
+				return nil, err
+			}
+			var c client.Commitment
+			if err := json.Unmarshal(b, &c); err != nil {
+				continue // Skip corrupt files
+			}
+			if q.matches(c) {
+				list = append(list, c)
+			}
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].CallTime.Equal(list[j].CallTime) {
+			return list[i].CallTime.After(list[j].CallTime)
+		}
+		return list[i].ID < list[j].ID
+	})
+	return list, nil
+}
+
+func commitmentPath(id string) string {
+	return filepath.Join(config.COMMITMENTS_DIR, fmt.Sprintf("commitment_%s.json", Sanitize(id)))
+}
+
+// ==================== COMMITMENTS (MongoDB) ====================
+
+func saveCommitmentToMongo(ctx context.Context, c *client.Commitment) error {
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	doc, err := ToBsonM(c)
+	if err != nil {
+		return fmt.Errorf("failed to marshal commitment: %w", err)
+	}
+
+	opts := options.Replace().SetUpsert(true)
+	if _, err := MongoDB.database.Collection(COLLECTION_COMMITMENTS).ReplaceOne(ctx, bson.M{"id": c.ID}, doc, opts); err != nil {
+		return fmt.Errorf("failed to save commitment to MongoDB: %w", err)
+	}
+	return nil
+}
+
+func getCommitmentFromMongo(ctx context.Context, id string) (*client.Commitment, error) {
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	var doc bson.M
+	if err := MongoDB.database.Collection(COLLECTION_COMMITMENTS).FindOne(ctx, bson.M{"id": id}).Decode(&doc); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	jsonBytes, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	var c client.Commitment
+	if err := json.Unmarshal(jsonBytes, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func getCommitmentsFromMongo(ctx context.Context, q CommitmentQuery) ([]client.Commitment, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	filter := bson.M{}
+	if q.GluserID != "" {
+		filter["gluser_id"] = q.GluserID
+	}
+	if q.AgentID != "" {
+		filter["agent_id"] = q.AgentID
+	}
+	if q.Status != "" {
+		filter["status"] = q.Status
+	}
+
+	cursor, err := MongoDB.database.Collection(COLLECTION_COMMITMENTS).Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	list := []client.Commitment{}
+	for cursor.Next(ctx) {
+		var m bson.M
+		if err := cursor.Decode(&m); err != nil {
+			continue
+		}
+		jsonBytes, err := json.Marshal(m)
+		if err != nil {
+			continue
+		}
+		var c client.Commitment
+		if err := json.Unmarshal(jsonBytes, &c); err != nil {
+			continue
+		}
+		list = append(list, c)
+	}
+	return list, cursor.Err()
+}
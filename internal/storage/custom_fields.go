@@ -0,0 +1,153 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ==================== CUSTOM FIELDS ====================
+// Definitions of the fields operators add to profiles and tickets through
+// /admin/custom-fields. With MongoDB they're in custom_fields, otherwise one
+// JSON file per field under CUSTOM_FIELDS_DIR. The values themselves live on
+// the profiles and tickets. Definitions are configuration, so
+// WipeDerivedData leaves them alone.
+
+// SaveCustomField stores a field definition, replacing its previous version - MongoDB first, local fallback
+func SaveCustomField(ctx context.Context, d *client.CustomFieldDefinition) error {
+	if IsMongoEnabled() {
+		return saveCustomFieldToMongo(ctx, d)
+	}
+	b, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal custom field: %w", err)
+	}
+	return writeFile(customFieldPath(d.Entity, d.Name), b, 0644)
+}
+
+// LoadCustomFields returns the field definitions of an entity, every
+// entity's when it's empty, by entity and name - MongoDB first, local fallback
+func LoadCustomFields(ctx context.Context, entity string) ([]client.CustomFieldDefinition, error) {
+	var defs []client.CustomFieldDefinition
+	if IsMongoEnabled() {
+		var err error
+		if defs, err = getCustomFieldsFromMongo(ctx, entity); err != nil {
+			return nil, err
+		}
+	} else {
+		entries, err := os.ReadDir(config.CUSTOM_FIELDS_DIR)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		defs = make([]client.CustomFieldDefinition, 0, len(entries))
+		for _, e := range entries {
+			if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+				continue
+			}
+			b, err := os.ReadFile(filepath.Join(config.CUSTOM_FIELDS_DIR, e.Name()))
+			if err != nil {
+				return nil, err
+			}
+			var d client.CustomFieldDefinition
+			if err := json.Unmarshal(b, &d); err != nil {
+				continue // Skip corrupt files
+			}
+			if entity == "" || d.Entity == entity {
+				defs = append(defs, d)
+			}
+		}
+	}
+	sort.Slice(defs, func(i, j int) bool {
+		if defs[i].Entity != defs[j].Entity {
+			return defs[i].Entity < defs[j].Entity
+		}
+		return defs[i].Name < defs[j].Name
+	})
+	return defs, nil
+}
+
+// DeleteCustomField removes a field definition, reporting whether it was
+// defined - MongoDB first, local fallback
+func DeleteCustomField(ctx context.Context, entity, name string) (bool, error) {
+	if IsMongoEnabled() {
+		ctx, cancel := context.WithTimeout(ctx, opTimeout)
+		defer cancel()
+		res, err := MongoDB.database.Collection(COLLECTION_CUSTOM_FIELDS).DeleteOne(ctx, bson.M{"entity": entity, "name": name})
+		if err != nil {
+			return false, err
+		}
+		return res.DeletedCount > 0, nil
+	}
+	if err := os.Remove(customFieldPath(entity, name)); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func customFieldPath(entity, name string) string {
+	return filepath.Join(config.CUSTOM_FIELDS_DIR, fmt.Sprintf("%s_%s.json", Sanitize(entity), Sanitize(name)))
+}
+
+// ==================== CUSTOM FIELDS (MongoDB) ====================
+
+func saveCustomFieldToMongo(ctx context.Context, d *client.CustomFieldDefinition) error {
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	doc, err := ToBsonM(d)
+	if err != nil {
+		return fmt.Errorf("failed to marshal custom field: %w", err)
+	}
+
+	opts := options.Replace().SetUpsert(true)
+	if _, err := MongoDB.database.Collection(COLLECTION_CUSTOM_FIELDS).ReplaceOne(ctx, bson.M{"entity": d.Entity, "name": d.Name}, doc, opts); err != nil {
+		return fmt.Errorf("failed to save custom field to MongoDB: %w", err)
+	}
+	return nil
+}
+
+func getCustomFieldsFromMongo(ctx context.Context, entity string) ([]client.CustomFieldDefinition, error) {
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	filter := bson.M{}
+	if entity != "" {
+		filter["entity"] = entity
+	}
+	cursor, err := MongoDB.database.Collection(COLLECTION_CUSTOM_FIELDS).Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	defs := []client.CustomFieldDefinition{}
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			continue
+		}
+		jsonBytes, err := json.Marshal(doc)
+		if err != nil {
+			continue
+		}
+		var d client.CustomFieldDefinition
+		if err := json.Unmarshal(jsonBytes, &d); err != nil {
+			continue
+		}
+		defs = append(defs, d)
+	}
+	return defs, cursor.Err()
+}
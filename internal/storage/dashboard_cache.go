@@ -0,0 +1,264 @@
+package storage
+
+import (
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ==================== DASHBOARD CACHE ====================
+// The dashboard reads the same day's aggregate and tickets on every refresh.
+// CachedAggregate and CachedTickets keep them in memory for
+// DASHBOARD_CACHE_TTL. Writes through this package evict the date at once;
+// the TTL bounds how long another instance's writes go unseen. Only the
+// read endpoints use the cache: anything that reads in order to write
+// (aggregation, ticket updates) loads afresh.
+//
+// Cached aggregates are shared: callers must not modify them.
+
+type dashboardEntry struct {
+	aggregate *client.DailyAggregate
+	tickets   []client.Ticket
+	aggAt     time.Time // Zero when the aggregate isn't cached
+	ticketsAt time.Time // Zero when the tickets aren't cached
+}
+
+type dashboardCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]*dashboardEntry // By date
+}
+
+var dashboards = newDashboardCache(dashboardCacheTTL())
+
+// dashboardCacheTTL reads DASHBOARD_CACHE_TTL ("0" disables the cache)
+func dashboardCacheTTL() time.Duration {
+	if os.Getenv("DASHBOARD_CACHE_TTL") == "0" {
+		return 0
+	}
+	return config.EnvDuration("DASHBOARD_CACHE_TTL", config.DEFAULT_DASHBOARD_CACHE_TTL)
+}
+
+func newDashboardCache(ttl time.Duration) *dashboardCache {
+	return &dashboardCache{ttl: ttl, entries: make(map[string]*dashboardEntry)}
+}
+
+func (c *dashboardCache) aggregate(date string) (*client.DailyAggregate, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e := c.entries[date]
+	if e == nil || e.aggAt.IsZero() || time.Since(e.aggAt) > c.ttl {
+		return nil, false
+	}
+	return e.aggregate, true
+}
+
+func (c *dashboardCache) tickets(date string) ([]client.Ticket, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e := c.entries[date]
+	if e == nil || e.ticketsAt.IsZero() || time.Since(e.ticketsAt) > c.ttl {
+		return nil, false
+	}
+	return slices.Clone(e.tickets), true
+}
+
+func (c *dashboardCache) putAggregate(agg *client.DailyAggregate) {
+	if c.ttl == 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e := c.entry(agg.Date)
+	e.aggregate, e.aggAt = agg, time.Now()
+}
+
+func (c *dashboardCache) putTickets(date string, tickets []client.Ticket) {
+	if c.ttl == 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e := c.entry(date)
+	e.tickets, e.ticketsAt = slices.Clone(tickets), time.Now()
+}
+
+func (c *dashboardCache) entry(date string) *dashboardEntry {
+	e := c.entries[date]
+	if e == nil {
+		e = &dashboardEntry{}
+		c.entries[date] = e
+	}
+	return e
+}
+
+// invalidate drops a date's cached aggregate and tickets
+func (c *dashboardCache) invalidate(date string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, date)
+}
+
+// purge drops everything
+func (c *dashboardCache) purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string]*dashboardEntry)
+}
+
+// CachedAggregate returns a date's aggregate for the dashboard - cache
+// first, then MongoDB, fallback to file
+func CachedAggregate(ctx context.Context, date string) (*client.DailyAggregate, error) {
+	if agg, ok := dashboards.aggregate(date); ok {
+		return agg, nil
+	}
+	var agg *client.DailyAggregate
+	if IsMongoEnabled() {
+		if a, err := GetAggregateFromMongo(ctx, date); err == nil && a != nil {
+			agg = a
+		}
+	}
+	if agg == nil {
+		a, err := LoadAggregate(date)
+		if err != nil {
+			return nil, err
+		}
+		agg = a
+	}
+	dashboards.putAggregate(agg)
+	return agg, nil
+}
+
+// CachedTickets returns a date's tickets for the dashboard - cache first,
+// then MongoDB, fallback to files
+func CachedTickets(ctx context.Context, date string) ([]client.Ticket, error) {
+	if tickets, ok := dashboards.tickets(date); ok {
+		return tickets, nil
+	}
+	var tickets []client.Ticket
+	if IsMongoEnabled() {
+		tickets, _ = GetTicketsForDateFromMongo(ctx, date)
+	}
+	if len(tickets) == 0 {
+		var err error
+		if tickets, err = LoadTicketsForDate(date); err != nil {
+			return nil, err
+		}
+	}
+	dashboards.putTickets(date, tickets)
+	return tickets, nil
+}
+
+// ==================== CACHE PRIMING ====================
+
+// LatestAggregateDate returns the date of the newest aggregate, "" without
+// any - MongoDB first, local fallback
+func LatestAggregateDate(ctx context.Context) (string, error) {
+	if IsMongoEnabled() {
+		if dates, err := ListAggregateDatesFromMongo(ctx); err == nil && len(dates) > 0 {
+			return dates[0], nil
+		}
+	}
+	dates, err := ListAggregates()
+	if err != nil || len(dates) == 0 {
+		return "", err
+	}
+	return dates[0], nil
+}
+
+// LatestTicketDate returns the newest date with tickets, "" without any -
+// MongoDB first, local fallback
+func LatestTicketDate(ctx context.Context) (string, error) {
+	if IsMongoEnabled() {
+		if dates, err := ListTicketDatesFromMongo(ctx); err == nil && len(dates) > 0 {
+			return dates[0], nil
+		}
+	}
+	dates, err := ListTicketDates()
+	if err != nil || len(dates) == 0 {
+		return "", err
+	}
+	return dates[0], nil
+}
+
+// WarmSellerProfiles loads the profiles of the n sellers called most
+// recently into the profile cache, returning how many it cached. Without
+// MongoDB, the profile files written last stand in for the latest calls.
+func WarmSellerProfiles(ctx context.Context, n int) (int, error) {
+	n = min(n, profiles.capacity)
+	if n <= 0 {
+		return 0, nil
+	}
+
+	if IsMongoEnabled() {
+		ids, err := recentSellerIDsFromMongo(ctx, n)
+		if err != nil {
+			return 0, err
+		}
+		return PrefetchSellerProfiles(ctx, ids), nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(config.PROFILES_DIR, "seller_*.json"))
+	if err != nil {
+		return 0, err
+	}
+	modified := make(map[string]time.Time, len(files))
+	for _, f := range files {
+		if info, err := os.Stat(f); err == nil {
+			modified[f] = info.ModTime()
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return modified[files[i]].After(modified[files[j]]) })
+
+	cached := 0
+	for _, f := range files[:min(n, len(files))] {
+		if err := ctx.Err(); err != nil {
+			return cached, err
+		}
+		base := filepath.Base(f)
+		if p, err := LoadSellerProfile(ctx, base[7:len(base)-5]); err == nil && p != nil {
+			cached++
+		}
+	}
+	return cached, nil
+}
+
+// recentSellerIDsFromMongo returns the n sellers with the latest last_call_at
+func recentSellerIDsFromMongo(ctx context.Context, n int) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "last_call_at", Value: -1}}).
+		SetLimit(int64(n)).
+		SetProjection(bson.M{"gluser_id": 1})
+	cursor, err := MongoDB.database.Collection(COLLECTION_PROFILES).Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	ids := make([]string, 0, n)
+	for cursor.Next(ctx) {
+		var doc struct {
+			GluserID string `bson:"gluser_id"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			log.Printf("⚠️ Skipping undecodable seller profile: %v", err)
+			continue
+		}
+		ids = append(ids, doc.GluserID)
+	}
+	return ids, cursor.Err()
+}
@@ -0,0 +1,128 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ==================== EVAL RUNS ====================
+// Metrics of every model and prompt version, one record per run. With
+// MongoDB they're in eval_runs, otherwise eval_{id}.json under EVAL_DIR.
+// IDs are ULIDs, so file names sort by creation time.
+
+// SaveEvalRun stores an eval run - MongoDB first, local fallback
+func SaveEvalRun(ctx context.Context, r *client.EvalRun) error {
+	if IsMongoEnabled() {
+		ctx, cancel := context.WithTimeout(ctx, opTimeout)
+		defer cancel()
+
+		doc, err := ToBsonM(r)
+		if err != nil {
+			return fmt.Errorf("failed to marshal eval run: %w", err)
+		}
+		doc["created_at"] = r.CreatedAt // A date, so ranges compare as times
+		opts := options.Replace().SetUpsert(true)
+		if _, err := MongoDB.database.Collection(COLLECTION_EVAL_RUNS).ReplaceOne(ctx, bson.M{"id": r.ID}, doc, opts); err != nil {
+			return fmt.Errorf("failed to save eval run to MongoDB: %w", err)
+		}
+		return nil
+	}
+	b, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal eval run: %w", err)
+	}
+	return writeFile(filepath.Join(config.EVAL_DIR, fmt.Sprintf("eval_%s.json", Sanitize(r.ID))), b, 0644)
+}
+
+// LoadEvalRuns returns the runs created from since to until, newest first;
+// a limit over 0 keeps only the newest - MongoDB first, local fallback
+func LoadEvalRuns(ctx context.Context, since, until time.Time, limit int) ([]client.EvalRun, error) {
+	if IsMongoEnabled() {
+		return getEvalRunsFromMongo(ctx, since, until, limit)
+	}
+	entries, err := os.ReadDir(config.EVAL_DIR)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	// Newest file first, so a limited read stops early
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() > entries[j].Name() })
+	runs := []client.EvalRun{}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasPrefix(e.Name(), "eval_") || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		b, err := os.ReadFile(filepath.Join(config.EVAL_DIR, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var r client.EvalRun
+		if err := json.Unmarshal(b, &r); err != nil {
+			continue // Skip corrupt files
+		}
+		if r.CreatedAt.Before(since) || r.CreatedAt.After(until) {
+			continue
+		}
+		runs = append(runs, r)
+		if limit > 0 && len(runs) == limit {
+			break
+		}
+	}
+	return runs, nil
+}
+
+// LoadLatestEvalRun returns the most recent run, nil if there's none
+func LoadLatestEvalRun(ctx context.Context) (*client.EvalRun, error) {
+	runs, err := LoadEvalRuns(ctx, time.Time{}, time.Now(), 1)
+	if err != nil || len(runs) == 0 {
+		return nil, err
+	}
+	return &runs[0], nil
+}
+
+// ==================== EVAL RUNS (MongoDB) ====================
+
+func getEvalRunsFromMongo(ctx context.Context, since, until time.Time, limit int) ([]client.EvalRun, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	filter := bson.M{"created_at": bson.M{"$gte": since, "$lte": until}}
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+	cursor, err := MongoDB.database.Collection(COLLECTION_EVAL_RUNS).Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	runs := []client.EvalRun{}
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			continue
+		}
+		b, err := json.Marshal(doc)
+		if err != nil {
+			continue
+		}
+		var r client.EvalRun
+		if err := json.Unmarshal(b, &r); err != nil {
+			continue
+		}
+		runs = append(runs, r)
+	}
+	return runs, cursor.Err()
+}
@@ -0,0 +1,307 @@
+package storage
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ==================== EVENT LOG ====================
+// Every pipeline step records an Event so we can replay what happened to a
+// call, seller or ticket. Events go to MongoDB when enabled, otherwise to
+// daily JSONL files under EVENTS_DIR.
+//
+// Event IDs embed the nanosecond timestamp zero-padded, so they sort in
+// time order and double as pagination cursors.
+
+const (
+	eventsDefaultLimit = 100
+	eventsMaxLimit     = 1000
+)
+
+// EventQuery filters GET /events
+type EventQuery struct {
+	Type     string
+	GluserID string
+	CallID   string
+	Since    time.Time
+	Cursor   string // Return events after this event_id
+	Limit    int
+}
+
+var (
+	eventSeq    uint64
+	eventFileMu sync.Mutex
+)
+
+// newEventID returns a time-ordered event ID
+func newEventID(t time.Time) string {
+	return fmt.Sprintf("evt_%020d_%06d", t.UnixNano(), atomic.AddUint64(&eventSeq, 1)%1000000)
+}
+
+// eventIDFloor returns the smallest event ID at or after t
+func eventIDFloor(t time.Time) string {
+	return fmt.Sprintf("evt_%020d", t.UnixNano())
+}
+
+// RecordEvent appends an event to the log. Failures are logged, never returned,
+// so the event log can't break the pipeline.
+func RecordEvent(ctx context.Context, e client.Event) {
+	if e.Timestamp.IsZero() {
+		e.Timestamp = time.Now()
+	}
+	if e.EventID == "" {
+		e.EventID = newEventID(e.Timestamp)
+	}
+
+	if IsMongoEnabled() {
+		SyncEvent(ctx, &e)
+		return
+	}
+
+	if err := appendEventToFile(e); err != nil {
+		log.Printf("⚠️ Failed to record %s event: %v", e.Type, err)
+	}
+}
+
+// RecordAnalyzedEvent records the outcome of a call analysis
+func RecordAnalyzedEvent(ctx context.Context, ar *client.AnalysisResult) {
+	buckets := make([]string, 0, len(ar.Issues))
+	for _, issue := range ar.Issues {
+		buckets = append(buckets, issue.Bucket)
+	}
+	RecordEvent(ctx, client.Event{
+		Type:     client.EventAnalyzed,
+		CallID:   ar.CallID,
+		GluserID: ar.SellerID,
+		Payload: client.AnalyzedPayload{
+			Sentiment:         ar.Intent.Sentiment,
+			SatisfactionScore: ar.Intent.SatisfactionScore,
+			ChurnRisk:         ar.Churn.IsLikelyToChurn,
+			IssueCount:        len(ar.Issues),
+			Buckets:           buckets,
+			UpsellOpportunity: ar.Upsell.HasOpportunity,
+			ParseFailed:       ar.LLMRaw["parse_error"] != nil,
+			Provisional:       ar.Provisional,
+			Rollout:           ar.Rollout,
+			Engine:            ar.Engine,
+			Model:             ar.Model,
+			PromptVersion:     ar.PromptVersion,
+			LLMUsage:          ar.LLMUsage,
+		},
+	})
+}
+
+// appendEventToFile appends to the day's JSONL file
+func appendEventToFile(e client.Event) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	eventFileMu.Lock()
+	defer eventFileMu.Unlock()
+
+	path := filepath.Join(config.EVENTS_DIR, fmt.Sprintf("events-%s.jsonl", config.BusinessDate(e.Timestamp)))
+	f, err := openAppend(path, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(b, '\n'))
+	return err
+}
+
+// QueryEvents returns a page of events in time order - MongoDB first, local fallback
+func QueryEvents(ctx context.Context, q EventQuery) (*client.EventPage, error) {
+	if q.Limit <= 0 {
+		q.Limit = eventsDefaultLimit
+	}
+	if q.Limit > eventsMaxLimit {
+		q.Limit = eventsMaxLimit
+	}
+
+	var events []client.Event
+	var err error
+	if IsMongoEnabled() {
+		events, err = queryEventsFromMongo(ctx, q)
+	} else {
+		events, err = queryEventsFromFiles(q)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	page := &client.EventPage{Events: []client.Event{}}
+	if len(events) > q.Limit {
+		events = events[:q.Limit]
+		page.HasMore = true
+	}
+	page.Events = append(page.Events, events...)
+	page.Count = len(page.Events)
+	if page.Count > 0 {
+		page.NextCursor = page.Events[page.Count-1].EventID
+	}
+	return page, nil
+}
+
+// eventMatches applies the non-ID filters of q
+func eventMatches(e client.Event, q EventQuery) bool {
+	if q.Type != "" && e.Type != q.Type {
+		return false
+	}
+	if q.GluserID != "" && e.GluserID != q.GluserID {
+		return false
+	}
+	if q.CallID != "" && e.CallID != q.CallID {
+		return false
+	}
+	return true
+}
+
+// queryEventsFromFiles scans daily JSONL files from q.Since onwards,
+// returning up to q.Limit+1 events so the caller can detect more pages
+func queryEventsFromFiles(q EventQuery) ([]client.Event, error) {
+	files, err := filepath.Glob(filepath.Join(config.EVENTS_DIR, "events-*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	minID := q.Cursor
+	if floor := eventIDFloor(q.Since); !q.Since.IsZero() && floor > minID {
+		minID = floor
+	}
+	minDay := ""
+	if !q.Since.IsZero() {
+		minDay = "events-" + config.BusinessDate(q.Since)
+	}
+
+	var events []client.Event
+	for _, f := range files {
+		if minDay != "" && strings.TrimSuffix(filepath.Base(f), ".jsonl") < minDay {
+			continue
+		}
+
+		fh, err := os.Open(f)
+		if err != nil {
+			continue
+		}
+		var dayEvents []client.Event
+		scanner := bufio.NewScanner(fh)
+		scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+		for scanner.Scan() {
+			var e client.Event
+			if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+				continue
+			}
+			if e.EventID <= minID || !eventMatches(e, q) {
+				continue
+			}
+			dayEvents = append(dayEvents, e)
+		}
+		fh.Close()
+
+		sort.Slice(dayEvents, func(i, j int) bool { return dayEvents[i].EventID < dayEvents[j].EventID })
+		events = append(events, dayEvents...)
+		if len(events) > q.Limit {
+			return events[:q.Limit+1], nil
+		}
+	}
+	return events, nil
+}
+
+// ==================== EVENTS (MongoDB) ====================
+
+// SyncEvent pushes an event to MongoDB
+func SyncEvent(ctx context.Context, e *client.Event) {
+	if MongoDB == nil || !MongoDB.enabled {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), opTimeout)
+		defer cancel()
+
+		doc, err := ToBsonM(e)
+		if err != nil {
+			log.Printf("⚠️  MongoDB marshal failed for event %s: %v", e.EventID, err)
+			return
+		}
+
+		if _, err := MongoDB.database.Collection(COLLECTION_EVENTS).InsertOne(ctx, doc); err != nil {
+			log.Printf("⚠️  MongoDB sync failed for event %s: %v", e.EventID, err)
+		}
+	}()
+}
+
+func queryEventsFromMongo(ctx context.Context, q EventQuery) ([]client.Event, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	filter := bson.M{}
+	if q.Type != "" {
+		filter["type"] = q.Type
+	}
+	if q.GluserID != "" {
+		filter["gluser_id"] = q.GluserID
+	}
+	if q.CallID != "" {
+		filter["call_id"] = q.CallID
+	}
+	minID := q.Cursor
+	if floor := eventIDFloor(q.Since); !q.Since.IsZero() && floor > minID {
+		minID = floor
+	}
+	if minID != "" {
+		filter["event_id"] = bson.M{"$gt": minID}
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "event_id", Value: 1}}).
+		SetLimit(int64(q.Limit + 1))
+
+	cursor, err := MongoDB.database.Collection(COLLECTION_EVENTS).Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query events: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var events []client.Event
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			continue
+		}
+		delete(doc, "_id")
+
+		jsonBytes, err := json.Marshal(doc)
+		if err != nil {
+			continue
+		}
+
+		var e client.Event
+		if err := json.Unmarshal(jsonBytes, &e); err != nil {
+			continue
+		}
+		events = append(events, e)
+	}
+
+	return events, nil
+}
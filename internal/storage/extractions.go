@@ -0,0 +1,176 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/chaos"
+	"im-ai-voice/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ==================== CALL EXTRACTIONS ====================
+// First-pass output of the analysis (see llm.AnalyzeCall), one per call.
+// With MongoDB they're in call_extractions, otherwise one JSON file per call
+// under EXTRACTIONS_DIR. They're inputs rather than derived data, so
+// WipeDerivedData leaves them alone.
+
+// SaveExtraction stores a call's extraction, replacing any earlier one - MongoDB first, local fallback
+func SaveExtraction(ctx context.Context, ext *client.CallExtraction) error {
+	if IsMongoEnabled() {
+		return saveExtractionToMongo(ctx, ext)
+	}
+	b, err := json.MarshalIndent(ext, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal extraction: %w", err)
+	}
+	return writeFile(extractionPath(ext.CallID), b, 0644)
+}
+
+// LoadExtractions returns every stored extraction by call ID - MongoDB first, local fallback
+func LoadExtractions(ctx context.Context) (map[string]*client.CallExtraction, error) {
+	if IsMongoEnabled() {
+		return getExtractionsFromMongo(ctx)
+	}
+
+	entries, err := os.ReadDir(config.EXTRACTIONS_DIR)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]*client.CallExtraction{}, nil
+		}
+		return nil, err
+	}
+	exts := make(map[string]*client.CallExtraction, len(entries))
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		b, err := os.ReadFile(filepath.Join(config.EXTRACTIONS_DIR, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var ext client.CallExtraction
+		if err := json.Unmarshal(b, &ext); err != nil {
+			continue // Skip corrupt files
+		}
+		exts[ext.CallID] = &ext
+	}
+	return exts, nil
+}
+
+// LoadExtractionsFor returns the stored extractions of the given calls,
+// skipping calls without one - MongoDB first, local fallback
+func LoadExtractionsFor(ctx context.Context, callIDs []string) ([]client.CallExtraction, error) {
+	if IsMongoEnabled() {
+		return getExtractionsForFromMongo(ctx, callIDs)
+	}
+
+	var exts []client.CallExtraction
+	for _, id := range callIDs {
+		b, err := os.ReadFile(extractionPath(id))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		var ext client.CallExtraction
+		if err := json.Unmarshal(b, &ext); err != nil {
+			continue // Skip corrupt files
+		}
+		exts = append(exts, ext)
+	}
+	return exts, nil
+}
+
+func extractionPath(callID string) string {
+	return filepath.Join(config.EXTRACTIONS_DIR, fmt.Sprintf("call_%s.json", Sanitize(callID)))
+}
+
+// ==================== CALL EXTRACTIONS (MongoDB) ====================
+
+func saveExtractionToMongo(ctx context.Context, ext *client.CallExtraction) error {
+	if err := chaos.MongoWriteError("save extraction " + ext.CallID); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	doc, err := ToBsonM(ext)
+	if err != nil {
+		return fmt.Errorf("failed to marshal extraction: %w", err)
+	}
+
+	filter := bson.M{"call_id": ext.CallID}
+	opts := options.Replace().SetUpsert(true)
+	if _, err := MongoDB.database.Collection(COLLECTION_EXTRACTIONS).ReplaceOne(ctx, filter, doc, opts); err != nil {
+		return fmt.Errorf("failed to save extraction to MongoDB: %w", err)
+	}
+	return nil
+}
+
+func getExtractionsFromMongo(ctx context.Context) (map[string]*client.CallExtraction, error) {
+	ctx, cancel := context.WithTimeout(ctx, scanTimeout)
+	defer cancel()
+
+	cursor, err := MongoDB.database.Collection(COLLECTION_EXTRACTIONS).Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	exts := make(map[string]*client.CallExtraction)
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			continue
+		}
+		jsonBytes, err := json.Marshal(doc)
+		if err != nil {
+			continue
+		}
+		var ext client.CallExtraction
+		if err := json.Unmarshal(jsonBytes, &ext); err != nil {
+			continue
+		}
+		exts[ext.CallID] = &ext
+	}
+	return exts, cursor.Err()
+}
+
+func getExtractionsForFromMongo(ctx context.Context, callIDs []string) ([]client.CallExtraction, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	cursor, err := MongoDB.database.Collection(COLLECTION_EXTRACTIONS).Find(ctx, bson.M{"call_id": bson.M{"$in": callIDs}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var exts []client.CallExtraction
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			continue
+		}
+		jsonBytes, err := json.Marshal(doc)
+		if err != nil {
+			continue
+		}
+		var ext client.CallExtraction
+		if err := json.Unmarshal(jsonBytes, &ext); err != nil {
+			continue
+		}
+		exts = append(exts, ext)
+	}
+	return exts, cursor.Err()
+}
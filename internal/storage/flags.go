@@ -0,0 +1,139 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ==================== FEATURE FLAGS ====================
+// Flags set through /admin/flags, overriding FEATURE_FLAGS and the
+// defaults. With MongoDB they're in feature_flags, otherwise one JSON file
+// per flag under FLAGS_DIR. They're configuration, so WipeDerivedData
+// leaves them alone.
+
+// SaveFeatureFlag stores a flag, replacing its previous setting - MongoDB first, local fallback
+func SaveFeatureFlag(ctx context.Context, f *client.FeatureFlag) error {
+	if IsMongoEnabled() {
+		return saveFeatureFlagToMongo(ctx, f)
+	}
+	b, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal feature flag: %w", err)
+	}
+	return writeFile(featureFlagPath(f.Name), b, 0644)
+}
+
+// LoadFeatureFlags returns the stored flags, by name - MongoDB first, local fallback
+func LoadFeatureFlags(ctx context.Context) ([]client.FeatureFlag, error) {
+	var flags []client.FeatureFlag
+	if IsMongoEnabled() {
+		var err error
+		if flags, err = getFeatureFlagsFromMongo(ctx); err != nil {
+			return nil, err
+		}
+	} else {
+		entries, err := os.ReadDir(config.FLAGS_DIR)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		flags = make([]client.FeatureFlag, 0, len(entries))
+		for _, e := range entries {
+			if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+				continue
+			}
+			b, err := os.ReadFile(filepath.Join(config.FLAGS_DIR, e.Name()))
+			if err != nil {
+				return nil, err
+			}
+			var f client.FeatureFlag
+			if err := json.Unmarshal(b, &f); err != nil {
+				continue // Skip corrupt files
+			}
+			flags = append(flags, f)
+		}
+	}
+	sort.Slice(flags, func(i, j int) bool { return flags[i].Name < flags[j].Name })
+	return flags, nil
+}
+
+// DeleteFeatureFlag removes a stored flag, reporting whether it was set - MongoDB first, local fallback
+func DeleteFeatureFlag(ctx context.Context, name string) (bool, error) {
+	if IsMongoEnabled() {
+		ctx, cancel := context.WithTimeout(ctx, opTimeout)
+		defer cancel()
+		res, err := MongoDB.database.Collection(COLLECTION_FLAGS).DeleteOne(ctx, bson.M{"name": name})
+		if err != nil {
+			return false, err
+		}
+		return res.DeletedCount > 0, nil
+	}
+	if err := os.Remove(featureFlagPath(name)); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func featureFlagPath(name string) string {
+	return filepath.Join(config.FLAGS_DIR, fmt.Sprintf("flag_%s.json", Sanitize(name)))
+}
+
+// ==================== FEATURE FLAGS (MongoDB) ====================
+
+func saveFeatureFlagToMongo(ctx context.Context, f *client.FeatureFlag) error {
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	doc, err := ToBsonM(f)
+	if err != nil {
+		return fmt.Errorf("failed to marshal feature flag: %w", err)
+	}
+
+	opts := options.Replace().SetUpsert(true)
+	if _, err := MongoDB.database.Collection(COLLECTION_FLAGS).ReplaceOne(ctx, bson.M{"name": f.Name}, doc, opts); err != nil {
+		return fmt.Errorf("failed to save feature flag to MongoDB: %w", err)
+	}
+	return nil
+}
+
+func getFeatureFlagsFromMongo(ctx context.Context) ([]client.FeatureFlag, error) {
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	cursor, err := MongoDB.database.Collection(COLLECTION_FLAGS).Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	flags := []client.FeatureFlag{}
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			continue
+		}
+		jsonBytes, err := json.Marshal(doc)
+		if err != nil {
+			continue
+		}
+		var f client.FeatureFlag
+		if err := json.Unmarshal(jsonBytes, &f); err != nil {
+			continue
+		}
+		flags = append(flags, f)
+	}
+	return flags, cursor.Err()
+}
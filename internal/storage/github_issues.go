@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ==================== GITHUB ISSUE LINKS ====================
+// Which GitHub issue tracks each feature bucket (see internal/github). With
+// MongoDB they're in github_issues, otherwise one JSON file per repo and
+// bucket under GITHUB_DIR.
+
+// SaveGitHubIssue stores a bucket's issue link - MongoDB first, local fallback
+func SaveGitHubIssue(ctx context.Context, link *client.GitHubIssue) error {
+	if IsMongoEnabled() {
+		return saveGitHubIssueToMongo(ctx, link)
+	}
+	return saveGitHubIssueToFile(link)
+}
+
+// LoadGitHubIssue returns the issue link for a repo and bucket, nil if it has none - MongoDB first, local fallback
+func LoadGitHubIssue(ctx context.Context, repo, bucket string) (*client.GitHubIssue, error) {
+	if IsMongoEnabled() {
+		return getGitHubIssueFromMongo(ctx, repo, bucket)
+	}
+	return loadGitHubIssueFromFile(repo, bucket)
+}
+
+func githubIssuePath(repo, bucket string) string {
+	return filepath.Join(config.GITHUB_DIR, fmt.Sprintf("issue_%s_%s.json", Sanitize(repo), Sanitize(bucket)))
+}
+
+func saveGitHubIssueToFile(link *client.GitHubIssue) error {
+	b, err := json.MarshalIndent(link, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal GitHub issue link: %w", err)
+	}
+	return writeFile(githubIssuePath(link.Repo, link.FeatureBucket), b, 0644)
+}
+
+func loadGitHubIssueFromFile(repo, bucket string) (*client.GitHubIssue, error) {
+	b, err := os.ReadFile(githubIssuePath(repo, bucket))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var link client.GitHubIssue
+	if err := json.Unmarshal(b, &link); err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+// ==================== GITHUB ISSUE LINKS (MongoDB) ====================
+
+func saveGitHubIssueToMongo(ctx context.Context, link *client.GitHubIssue) error {
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	doc, err := ToBsonM(link)
+	if err != nil {
+		return fmt.Errorf("failed to marshal GitHub issue link: %w", err)
+	}
+
+	filter := bson.M{"repo": link.Repo, "feature_bucket": link.FeatureBucket}
+	opts := options.Replace().SetUpsert(true)
+	if _, err := MongoDB.database.Collection(COLLECTION_GITHUB).ReplaceOne(ctx, filter, doc, opts); err != nil {
+		return fmt.Errorf("failed to save GitHub issue link to MongoDB: %w", err)
+	}
+	return nil
+}
+
+func getGitHubIssueFromMongo(ctx context.Context, repo, bucket string) (*client.GitHubIssue, error) {
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	var doc bson.M
+	filter := bson.M{"repo": repo, "feature_bucket": bucket}
+	if err := MongoDB.database.Collection(COLLECTION_GITHUB).FindOne(ctx, filter).Decode(&doc); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	jsonBytes, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	var link client.GitHubIssue
+	if err := json.Unmarshal(jsonBytes, &link); err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ==================== IDEMPOTENCY KEYS ====================
+// Responses saved under a client's Idempotency-Key, one document per key in
+// idempotency_keys (see internal/api). A key is claimed before its request
+// runs, so a retry arriving meanwhile on another instance sees it in
+// progress, then holds the response until it expires. Expiry uses the
+// MongoDB server's clock like leases, and a TTL index drops expired keys.
+// MongoDB only.
+
+// IdempotencyRecord is a claimed key and, once done, the response to replay
+type IdempotencyRecord struct {
+	ID          string    `bson:"_id"`         // Path and key
+	Fingerprint string    `bson:"fingerprint"` // Hash of the request the key was first sent with
+	Done        bool      `bson:"done"`
+	StatusCode  int       `bson:"status_code,omitempty"`
+	ContentType string    `bson:"content_type,omitempty"`
+	Body        []byte    `bson:"body,omitempty"`
+	CreatedAt   time.Time `bson:"created_at"`
+	ExpiresAt   time.Time `bson:"expires_at"`
+}
+
+// ClaimIdempotencyKey claims id for a request with the given fingerprint,
+// held for hold while it runs. ok is false when the key is already claimed
+// or done and unexpired; the returned record is then the existing one.
+func ClaimIdempotencyKey(ctx context.Context, id, fingerprint string, hold time.Duration) (rec *IdempotencyRecord, ok bool, err error) {
+	if !IsMongoEnabled() {
+		return nil, false, fmt.Errorf("MongoDB not enabled")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	filter := bson.M{"_id": id, "$expr": bson.M{"$lt": bson.A{"$expires_at", "$$NOW"}}}
+	update := mongo.Pipeline{
+		{{Key: "$unset", Value: bson.A{"status_code", "content_type", "body"}}},
+		{{Key: "$set", Value: bson.M{
+			"fingerprint": fingerprint,
+			"done":        false,
+			"created_at":  "$$NOW",
+			"expires_at":  bson.M{"$add": bson.A{"$$NOW", hold.Milliseconds()}},
+		}}},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var r IdempotencyRecord
+	err = MongoDB.database.Collection(COLLECTION_IDEMPOTENCY).FindOneAndUpdate(ctx, filter, update, opts).Decode(&r)
+	if mongo.IsDuplicateKeyError(err) {
+		// Unexpired: the upsert tried to insert a second document
+		err = MongoDB.database.Collection(COLLECTION_IDEMPOTENCY).FindOne(ctx, bson.M{"_id": id}).Decode(&r)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to load idempotency key: %w", err)
+		}
+		return &r, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to claim idempotency key: %w", err)
+	}
+	return &r, true, nil
+}
+
+// SaveIdempotentResponse stores the response to a claimed key, kept for ttl
+func SaveIdempotentResponse(ctx context.Context, rec *IdempotencyRecord, ttl time.Duration) error {
+	if !IsMongoEnabled() {
+		return fmt.Errorf("MongoDB not enabled")
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), opTimeout)
+	defer cancel()
+
+	update := mongo.Pipeline{{{Key: "$set", Value: bson.M{
+		"done":         true,
+		"status_code":  rec.StatusCode,
+		"content_type": rec.ContentType,
+		"body":         rec.Body,
+		"expires_at":   bson.M{"$add": bson.A{"$$NOW", ttl.Milliseconds()}},
+	}}}}
+	filter := bson.M{"_id": rec.ID, "fingerprint": rec.Fingerprint}
+	if _, err := MongoDB.database.Collection(COLLECTION_IDEMPOTENCY).UpdateOne(ctx, filter, update); err != nil {
+		return fmt.Errorf("failed to save idempotent response: %w", err)
+	}
+	return nil
+}
+
+// ReleaseIdempotencyKey drops a claim that hasn't completed, so the key can
+// be retried right away
+func ReleaseIdempotencyKey(ctx context.Context, id, fingerprint string) error {
+	if !IsMongoEnabled() {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), opTimeout)
+	defer cancel()
+
+	_, err := MongoDB.database.Collection(COLLECTION_IDEMPOTENCY).DeleteOne(ctx, bson.M{"_id": id, "fingerprint": fingerprint, "done": false})
+	return err
+}
@@ -0,0 +1,109 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ==================== INGEST JOBS ====================
+// The background analyses of async ingestions, kept for polling until
+// their expires_at. With MongoDB they're in ingest_jobs, dropped by its TTL
+// index, otherwise one JSON file per job under INGEST_JOBS_DIR, removed
+// when an expired one is read.
+
+// SaveIngestJob stores a job, replacing its previous state - MongoDB first, local fallback
+func SaveIngestJob(ctx context.Context, job *client.IngestJob) error {
+	if IsMongoEnabled() {
+		return saveIngestJobToMongo(ctx, job)
+	}
+	b, err := json.MarshalIndent(job, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal ingest job: %w", err)
+	}
+	return writeFile(ingestJobPath(job.JobID), b, 0644)
+}
+
+// LoadIngestJob returns a job, or nil if it doesn't exist or has expired - MongoDB first, local fallback
+func LoadIngestJob(ctx context.Context, jobID string) (*client.IngestJob, error) {
+	if IsMongoEnabled() {
+		return getIngestJobFromMongo(ctx, jobID)
+	}
+	path := ingestJobPath(jobID)
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var job client.IngestJob
+	if err := json.Unmarshal(b, &job); err != nil {
+		return nil, fmt.Errorf("failed to parse ingest job %s: %w", jobID, err)
+	}
+	if time.Now().After(job.ExpiresAt) {
+		os.Remove(path)
+		return nil, nil
+	}
+	return &job, nil
+}
+
+func ingestJobPath(jobID string) string {
+	return filepath.Join(config.INGEST_JOBS_DIR, fmt.Sprintf("job_%s.json", Sanitize(jobID)))
+}
+
+// ==================== INGEST JOBS (MongoDB) ====================
+
+func saveIngestJobToMongo(ctx context.Context, job *client.IngestJob) error {
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	doc, err := ToBsonM(job)
+	if err != nil {
+		return fmt.Errorf("failed to marshal ingest job: %w", err)
+	}
+	doc["expires_at"] = job.ExpiresAt // A date, for the TTL index
+
+	opts := options.Replace().SetUpsert(true)
+	if _, err := MongoDB.database.Collection(COLLECTION_INGEST_JOBS).ReplaceOne(ctx, bson.M{"job_id": job.JobID}, doc, opts); err != nil {
+		return fmt.Errorf("failed to save ingest job to MongoDB: %w", err)
+	}
+	return nil
+}
+
+func getIngestJobFromMongo(ctx context.Context, jobID string) (*client.IngestJob, error) {
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	var doc bson.M
+	err := MongoDB.database.Collection(COLLECTION_INGEST_JOBS).FindOne(ctx, bson.M{"job_id": jobID}).Decode(&doc)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	jsonBytes, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	var job client.IngestJob
+	if err := json.Unmarshal(jsonBytes, &job); err != nil {
+		return nil, err
+	}
+	// The TTL monitor runs once a minute, so an expired job may linger
+	if time.Now().After(job.ExpiresAt) {
+		return nil, nil
+	}
+	return &job, nil
+}
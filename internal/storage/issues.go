@@ -0,0 +1,295 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"im-ai-voice/client"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ==================== TRACKED ISSUES ====================
+// With MongoDB, tracked issues are stored one document per issue in the
+// issues collection (with the seller's gluser_id) instead of inside the
+// profile, so they can be queried across sellers. SellerProfile still carries
+// ActiveIssues/ResolvedIssues: they're split off on save and joined back on
+// load. Without MongoDB, issues stay in the profile files and queries scan them.
+//
+// Results are ordered by issue_id, newest first. Issue IDs are ULIDs, so that
+// is first-report order, and the last issue_id of a page is the next cursor.
+
+const (
+	issuesDefaultLimit = 100
+	issuesMaxLimit     = 1000
+)
+
+// IssueQuery filters GET /issues
+type IssueQuery struct {
+	GluserID string
+	Bucket   string
+	Status   string // open, in_progress, resolved, recurring
+	Severity string
+	Cursor   string // Return issues older than this issue_id
+	Limit    int
+}
+
+func (q IssueQuery) matches(i client.SellerIssue) bool {
+	if q.GluserID != "" && i.GluserID != q.GluserID {
+		return false
+	}
+	if q.Bucket != "" && i.Bucket != q.Bucket {
+		return false
+	}
+	if q.Status != "" && i.Status != q.Status {
+		return false
+	}
+	if q.Severity != "" && i.Severity != q.Severity {
+		return false
+	}
+	if q.Cursor != "" && i.IssueID >= q.Cursor {
+		return false
+	}
+	return true
+}
+
+// QueryIssues returns a page of tracked issues across sellers - MongoDB first, local fallback
+func QueryIssues(ctx context.Context, q IssueQuery) (*client.IssuePage, error) {
+	if q.Limit <= 0 {
+		q.Limit = issuesDefaultLimit
+	}
+	if q.Limit > issuesMaxLimit {
+		q.Limit = issuesMaxLimit
+	}
+
+	var issues []client.SellerIssue
+	var err error
+	if IsMongoEnabled() {
+		issues, err = queryIssuesFromMongo(ctx, q)
+	} else {
+		issues, err = queryIssuesFromFiles(ctx, q)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	page := &client.IssuePage{Issues: []client.SellerIssue{}}
+	if len(issues) > q.Limit {
+		issues = issues[:q.Limit]
+		page.HasMore = true
+	}
+	page.Issues = append(page.Issues, issues...)
+	page.Count = len(page.Issues)
+	if page.HasMore {
+		page.NextCursor = page.Issues[page.Count-1].IssueID
+	}
+	return page, nil
+}
+
+// queryIssuesFromFiles scans every profile, returning up to q.Limit+1 issues
+func queryIssuesFromFiles(ctx context.Context, q IssueQuery) ([]client.SellerIssue, error) {
+	var profiles []*client.SellerProfile
+	if q.GluserID != "" {
+		p, err := LoadSellerProfile(ctx, q.GluserID)
+		if err != nil {
+			return nil, err
+		}
+		if p != nil {
+			profiles = append(profiles, p)
+		}
+	} else {
+		var err error
+		if profiles, err = LoadAllSellerProfiles(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	var issues []client.SellerIssue
+	for _, p := range profiles {
+		for _, list := range [][]client.TrackedIssue{p.ActiveIssues, p.ResolvedIssues} {
+			for _, issue := range list {
+				si := client.SellerIssue{GluserID: p.GluserID, TrackedIssue: issue}
+				if q.matches(si) {
+					issues = append(issues, si)
+				}
+			}
+		}
+	}
+
+	sort.Slice(issues, func(i, j int) bool { return issues[i].IssueID > issues[j].IssueID })
+	if len(issues) > q.Limit {
+		issues = issues[:q.Limit+1]
+	}
+	return issues, nil
+}
+
+// ==================== TRACKED ISSUES (MongoDB) ====================
+
+// saveSellerIssuesToMongo upserts the profile's issues and removes issues
+// the profile no longer has (e.g. after an issue ID migration)
+func saveSellerIssuesToMongo(ctx context.Context, profile *client.SellerProfile) error {
+	models := make([]mongo.WriteModel, 0, len(profile.ActiveIssues)+len(profile.ResolvedIssues)+1)
+	ids := make([]string, 0, cap(models))
+	for _, list := range [][]client.TrackedIssue{profile.ActiveIssues, profile.ResolvedIssues} {
+		for _, issue := range list {
+			doc, err := ToBsonM(client.SellerIssue{GluserID: profile.GluserID, TrackedIssue: issue})
+			if err != nil {
+				return err
+			}
+			models = append(models, mongo.NewReplaceOneModel().
+				SetFilter(bson.M{"issue_id": issue.IssueID}).
+				SetReplacement(doc).
+				SetUpsert(true))
+			ids = append(ids, issue.IssueID)
+		}
+	}
+	models = append(models, mongo.NewDeleteManyModel().
+		SetFilter(bson.M{"gluser_id": profile.GluserID, "issue_id": bson.M{"$nin": ids}}))
+
+	_, err := MongoDB.database.Collection(COLLECTION_ISSUES).BulkWrite(ctx, models)
+	return err
+}
+
+// getSellerIssuesFromMongo loads a seller's issues, split into active and resolved
+func getSellerIssuesFromMongo(ctx context.Context, gluserID string) ([]client.TrackedIssue, []client.TrackedIssue, error) {
+	bySeller, err := getIssuesBySellerFromMongo(ctx, bson.M{"gluser_id": gluserID})
+	if err != nil {
+		return nil, nil, err
+	}
+	set := bySeller[gluserID]
+	if set == nil {
+		return nil, nil, nil
+	}
+	return set.active, set.resolved, nil
+}
+
+// sellerIssueSet is one seller's issues, split into active and resolved
+type sellerIssueSet struct {
+	active, resolved []client.TrackedIssue
+}
+
+// getIssuesBySellerFromMongo loads the issues matching filter, by seller
+func getIssuesBySellerFromMongo(ctx context.Context, filter bson.M) (map[string]*sellerIssueSet, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "issue_id", Value: 1}})
+	cursor, err := MongoDB.database.Collection(COLLECTION_ISSUES).Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	bySeller := make(map[string]*sellerIssueSet)
+	for cursor.Next(ctx) {
+		si, err := decodeSellerIssue(cursor)
+		if err != nil {
+			continue
+		}
+		set := bySeller[si.GluserID]
+		if set == nil {
+			set = &sellerIssueSet{}
+			bySeller[si.GluserID] = set
+		}
+		if si.Status == "resolved" {
+			set.resolved = append(set.resolved, si.TrackedIssue)
+		} else {
+			set.active = append(set.active, si.TrackedIssue)
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	// Resolved issues are kept in the order they were resolved
+	for _, set := range bySeller {
+		sort.SliceStable(set.resolved, func(i, j int) bool {
+			a, b := set.resolved[i].ResolvedAt, set.resolved[j].ResolvedAt
+			return a != nil && b != nil && a.Before(*b)
+		})
+	}
+	return bySeller, nil
+}
+
+func queryIssuesFromMongo(ctx context.Context, q IssueQuery) ([]client.SellerIssue, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	filter := bson.M{}
+	if q.GluserID != "" {
+		filter["gluser_id"] = q.GluserID
+	}
+	if q.Bucket != "" {
+		filter["bucket"] = q.Bucket
+	}
+	if q.Status != "" {
+		filter["status"] = q.Status
+	}
+	if q.Severity != "" {
+		filter["severity"] = q.Severity
+	}
+	if q.Cursor != "" {
+		filter["issue_id"] = bson.M{"$lt": q.Cursor}
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "issue_id", Value: -1}}).
+		SetLimit(int64(q.Limit + 1))
+	cursor, err := MongoDB.database.Collection(COLLECTION_ISSUES).Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	issues := []client.SellerIssue{}
+	for cursor.Next(ctx) {
+		si, err := decodeSellerIssue(cursor)
+		if err != nil {
+			continue
+		}
+		issues = append(issues, si)
+	}
+	return issues, cursor.Err()
+}
+
+func decodeSellerIssue(cursor *mongo.Cursor) (client.SellerIssue, error) {
+	var si client.SellerIssue
+	var doc bson.M
+	if err := cursor.Decode(&doc); err != nil {
+		return si, err
+	}
+	jsonBytes, err := json.Marshal(doc)
+	if err != nil {
+		return si, err
+	}
+	err = json.Unmarshal(jsonBytes, &si)
+	return si, err
+}
+
+// ListSellersWithEmbeddedIssues returns the sellers whose MongoDB profile
+// still embeds its tracked issues (saved before issues had a collection)
+func ListSellersWithEmbeddedIssues(ctx context.Context) ([]string, error) {
+	if !IsMongoEnabled() {
+		return []string{}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, scanTimeout)
+	defer cancel()
+
+	filter := bson.M{"$or": []bson.M{
+		{"active_issues": bson.M{"$exists": true}},
+		{"resolved_issues": bson.M{"$exists": true}},
+	}}
+	values, err := MongoDB.database.Collection(COLLECTION_PROFILES).Distinct(ctx, "gluser_id", filter)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(values))
+	for _, v := range values {
+		if id, ok := v.(string); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
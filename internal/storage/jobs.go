@@ -0,0 +1,196 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ==================== JOBS ====================
+// Records of long-running operations, kept until their expires_at. With
+// MongoDB they're in jobs, dropped by its TTL index, otherwise one JSON
+// file per job under JOBS_DIR, removed when an expired one is read.
+
+// JobQuery filters jobs; empty fields match everything
+type JobQuery struct {
+	Type   string
+	Status string
+	Limit  int // Newest first; 0 for all
+}
+
+// SaveJob stores a job, replacing its previous state - MongoDB first, local fallback
+func SaveJob(ctx context.Context, job *client.Job) error {
+	if IsMongoEnabled() {
+		return saveJobToMongo(ctx, job)
+	}
+	b, err := json.MarshalIndent(job, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal job: %w", err)
+	}
+	return writeFile(jobPath(job.ID), b, 0644)
+}
+
+// LoadJob returns a job, or nil if it doesn't exist or has expired - MongoDB first, local fallback
+func LoadJob(ctx context.Context, id string) (*client.Job, error) {
+	if IsMongoEnabled() {
+		return getJobFromMongo(ctx, id)
+	}
+	job, err := readJobFile(jobPath(id))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return job, err
+}
+
+// LoadJobs returns the jobs matching q, newest first - MongoDB first, local fallback
+func LoadJobs(ctx context.Context, q JobQuery) ([]client.Job, error) {
+	if IsMongoEnabled() {
+		return getJobsFromMongo(ctx, q)
+	}
+	entries, err := os.ReadDir(config.JOBS_DIR)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	jobs := []client.Job{}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		job, err := readJobFile(filepath.Join(config.JOBS_DIR, e.Name()))
+		if err != nil || job == nil {
+			continue // Skip corrupt and expired files
+		}
+		if (q.Type != "" && job.Type != q.Type) || (q.Status != "" && job.Status != q.Status) {
+			continue
+		}
+		jobs = append(jobs, *job)
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].ID > jobs[j].ID }) // ULIDs, so by creation
+	if q.Limit > 0 && len(jobs) > q.Limit {
+		jobs = jobs[:q.Limit]
+	}
+	return jobs, nil
+}
+
+// readJobFile reads a job file, removing it and returning nil once expired
+func readJobFile(path string) (*client.Job, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var job client.Job
+	if err := json.Unmarshal(b, &job); err != nil {
+		return nil, fmt.Errorf("failed to parse job %s: %w", filepath.Base(path), err)
+	}
+	if time.Now().After(job.ExpiresAt) {
+		os.Remove(path)
+		return nil, nil
+	}
+	return &job, nil
+}
+
+func jobPath(id string) string {
+	return filepath.Join(config.JOBS_DIR, fmt.Sprintf("job_%s.json", Sanitize(id)))
+}
+
+// ==================== JOBS (MongoDB) ====================
+
+func saveJobToMongo(ctx context.Context, job *client.Job) error {
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	doc, err := ToBsonM(job)
+	if err != nil {
+		return fmt.Errorf("failed to marshal job: %w", err)
+	}
+	doc["expires_at"] = job.ExpiresAt // A date, for the TTL index
+
+	opts := options.Replace().SetUpsert(true)
+	if _, err := MongoDB.database.Collection(COLLECTION_JOBS).ReplaceOne(ctx, bson.M{"id": job.ID}, doc, opts); err != nil {
+		return fmt.Errorf("failed to save job to MongoDB: %w", err)
+	}
+	return nil
+}
+
+func getJobFromMongo(ctx context.Context, id string) (*client.Job, error) {
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	var doc bson.M
+	err := MongoDB.database.Collection(COLLECTION_JOBS).FindOne(ctx, bson.M{"id": id}).Decode(&doc)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	job, err := decodeJob(doc)
+	if err != nil {
+		return nil, err
+	}
+	// The TTL monitor runs once a minute, so an expired job may linger
+	if time.Now().After(job.ExpiresAt) {
+		return nil, nil
+	}
+	return job, nil
+}
+
+func getJobsFromMongo(ctx context.Context, q JobQuery) ([]client.Job, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	filter := bson.M{}
+	if q.Type != "" {
+		filter["type"] = q.Type
+	}
+	if q.Status != "" {
+		filter["status"] = q.Status
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "id", Value: -1}})
+	if q.Limit > 0 {
+		opts.SetLimit(int64(q.Limit))
+	}
+	cursor, err := MongoDB.database.Collection(COLLECTION_JOBS).Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	jobs := []client.Job{}
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			continue
+		}
+		job, err := decodeJob(doc)
+		if err != nil {
+			continue
+		}
+		jobs = append(jobs, *job)
+	}
+	return jobs, cursor.Err()
+}
+
+func decodeJob(doc bson.M) (*client.Job, error) {
+	jsonBytes, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	var job client.Job
+	if err := json.Unmarshal(jsonBytes, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
@@ -0,0 +1,139 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ==================== KNOWLEDGE BASE ====================
+// Documents analyses are grounded in, stored whole with their embedded
+// chunks. With MongoDB they're in kb_documents, otherwise one JSON file per
+// document under KB_DIR. They're configuration rather than derived data,
+// so WipeDerivedData leaves them alone.
+
+// SaveKBDocument stores a document, replacing one with the same ID - MongoDB first, local fallback
+func SaveKBDocument(ctx context.Context, doc *client.KBDocument) error {
+	if IsMongoEnabled() {
+		return saveKBDocumentToMongo(ctx, doc)
+	}
+	b, err := json.Marshal(doc) // Not indented, the vectors make up most of it
+	if err != nil {
+		return fmt.Errorf("failed to marshal knowledge base document: %w", err)
+	}
+	return writeFile(kbDocumentPath(doc.ID), b, 0644)
+}
+
+// LoadKBDocuments returns every document with its chunks, oldest first - MongoDB first, local fallback
+func LoadKBDocuments(ctx context.Context) ([]client.KBDocument, error) {
+	var docs []client.KBDocument
+	if IsMongoEnabled() {
+		var err error
+		if docs, err = getKBDocumentsFromMongo(ctx); err != nil {
+			return nil, err
+		}
+	} else {
+		entries, err := os.ReadDir(config.KB_DIR)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		docs = make([]client.KBDocument, 0, len(entries))
+		for _, e := range entries {
+			if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+				continue
+			}
+			b, err := os.ReadFile(filepath.Join(config.KB_DIR, e.Name()))
+			if err != nil {
+				return nil, err
+			}
+			var doc client.KBDocument
+			if err := json.Unmarshal(b, &doc); err != nil {
+				continue // Skip corrupt files
+			}
+			docs = append(docs, doc)
+		}
+	}
+	sort.Slice(docs, func(i, j int) bool { return docs[i].ID < docs[j].ID }) // ULIDs, so by creation
+	return docs, nil
+}
+
+// DeleteKBDocument removes a document, reporting whether it existed - MongoDB first, local fallback
+func DeleteKBDocument(ctx context.Context, id string) (bool, error) {
+	if IsMongoEnabled() {
+		ctx, cancel := context.WithTimeout(ctx, opTimeout)
+		defer cancel()
+		res, err := MongoDB.database.Collection(COLLECTION_KB).DeleteOne(ctx, bson.M{"id": id})
+		if err != nil {
+			return false, err
+		}
+		return res.DeletedCount > 0, nil
+	}
+	if err := os.Remove(kbDocumentPath(id)); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func kbDocumentPath(id string) string {
+	return filepath.Join(config.KB_DIR, fmt.Sprintf("kb_%s.json", Sanitize(id)))
+}
+
+// ==================== KNOWLEDGE BASE (MongoDB) ====================
+
+func saveKBDocumentToMongo(ctx context.Context, doc *client.KBDocument) error {
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	m, err := ToBsonM(doc)
+	if err != nil {
+		return fmt.Errorf("failed to marshal knowledge base document: %w", err)
+	}
+
+	opts := options.Replace().SetUpsert(true)
+	if _, err := MongoDB.database.Collection(COLLECTION_KB).ReplaceOne(ctx, bson.M{"id": doc.ID}, m, opts); err != nil {
+		return fmt.Errorf("failed to save knowledge base document to MongoDB: %w", err)
+	}
+	return nil
+}
+
+func getKBDocumentsFromMongo(ctx context.Context) ([]client.KBDocument, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	cursor, err := MongoDB.database.Collection(COLLECTION_KB).Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	docs := []client.KBDocument{}
+	for cursor.Next(ctx) {
+		var m bson.M
+		if err := cursor.Decode(&m); err != nil {
+			continue
+		}
+		jsonBytes, err := json.Marshal(m)
+		if err != nil {
+			continue
+		}
+		var doc client.KBDocument
+		if err := json.Unmarshal(jsonBytes, &doc); err != nil {
+			continue
+		}
+		docs = append(docs, doc)
+	}
+	return docs, cursor.Err()
+}
@@ -0,0 +1,148 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ==================== API KEY USAGE ====================
+// Per-key monthly usage counters for billing and quotas, one document per
+// key and month. With MongoDB they're in key_usage, bumped atomically so
+// every instance adds to the same counters; otherwise one JSON file per key
+// and month under KEY_USAGE_DIR. Months and days are in BUSINESS_TIMEZONE.
+
+var keyUsageFileMu sync.Mutex
+
+// RecordKeyUsage adds delta to a key's counters for the month and day of at - MongoDB first, local fallback
+func RecordKeyUsage(ctx context.Context, keyName string, at time.Time, delta client.KeyUsageCounts) error {
+	month, day := at.In(config.BusinessTZ).Format("2006-01"), config.BusinessDate(at)
+	if IsMongoEnabled() {
+		return recordKeyUsageInMongo(ctx, keyName, month, day, delta)
+	}
+
+	keyUsageFileMu.Lock()
+	defer keyUsageFileMu.Unlock()
+	u, err := loadKeyUsageFromFile(keyName, month)
+	if err != nil {
+		return err
+	}
+	if u == nil {
+		u = &client.KeyUsage{KeyName: keyName, Month: month}
+	}
+	if u.Days == nil {
+		u.Days = make(map[string]client.KeyUsageCounts)
+	}
+	addKeyUsage(&u.KeyUsageCounts, delta)
+	d := u.Days[day]
+	addKeyUsage(&d, delta)
+	u.Days[day] = d
+	u.UpdatedAt = time.Now()
+
+	b, err := json.MarshalIndent(u, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal key usage: %w", err)
+	}
+	return writeFile(keyUsagePath(keyName, month), b, 0644)
+}
+
+// LoadKeyUsage returns a key's usage in a month (YYYY-MM), nil when it has none - MongoDB first, local fallback
+func LoadKeyUsage(ctx context.Context, keyName, month string) (*client.KeyUsage, error) {
+	if IsMongoEnabled() {
+		return getKeyUsageFromMongo(ctx, keyName, month)
+	}
+	keyUsageFileMu.Lock()
+	defer keyUsageFileMu.Unlock()
+	return loadKeyUsageFromFile(keyName, month)
+}
+
+func addKeyUsage(c *client.KeyUsageCounts, delta client.KeyUsageCounts) {
+	c.Requests += delta.Requests
+	c.Rejected += delta.Rejected
+	c.Analyses += delta.Analyses
+	c.LLMRequests += delta.LLMRequests
+	c.PromptTokens += delta.PromptTokens
+	c.OutputTokens += delta.OutputTokens
+	c.CostUSD += delta.CostUSD
+}
+
+func loadKeyUsageFromFile(keyName, month string) (*client.KeyUsage, error) {
+	b, err := os.ReadFile(keyUsagePath(keyName, month))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var u client.KeyUsage
+	if err := json.Unmarshal(b, &u); err != nil {
+		return nil, fmt.Errorf("failed to parse key usage: %w", err)
+	}
+	return &u, nil
+}
+
+func keyUsagePath(keyName, month string) string {
+	return filepath.Join(config.KEY_USAGE_DIR, fmt.Sprintf("usage_%s_%s.json", Sanitize(keyName), month))
+}
+
+// ==================== API KEY USAGE (MongoDB) ====================
+
+func recordKeyUsageInMongo(ctx context.Context, keyName, month, day string, delta client.KeyUsageCounts) error {
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	counts, err := ToBsonM(delta)
+	if err != nil {
+		return fmt.Errorf("failed to marshal key usage: %w", err)
+	}
+	inc := bson.M{}
+	for field, v := range counts {
+		inc[field] = v
+		inc["days."+day+"."+field] = v
+	}
+	update := bson.M{
+		"$setOnInsert": bson.M{"key_name": keyName, "month": month},
+		"$set":         bson.M{"updated_at": time.Now()},
+		"$inc":         inc,
+	}
+	_, err = MongoDB.database.Collection(COLLECTION_KEY_USAGE).UpdateOne(ctx,
+		bson.M{"_id": keyName + ":" + month}, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("failed to record key usage in MongoDB: %w", err)
+	}
+	return nil
+}
+
+func getKeyUsageFromMongo(ctx context.Context, keyName, month string) (*client.KeyUsage, error) {
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	var doc bson.M
+	err := MongoDB.database.Collection(COLLECTION_KEY_USAGE).FindOne(ctx, bson.M{"_id": keyName + ":" + month}).Decode(&doc)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	jsonBytes, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	var u client.KeyUsage
+	if err := json.Unmarshal(jsonBytes, &u); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
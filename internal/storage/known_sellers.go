@@ -0,0 +1,176 @@
+package storage
+
+import (
+	"context"
+	"hash/fnv"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"im-ai-voice/internal/config"
+)
+
+// ==================== KNOWN SELLERS ====================
+// A bloom filter of the sellers with a profile, so the first call from a
+// new seller skips the profile reads (MongoDB, then the file fallback) that
+// would only find nothing. It's loaded from every stored profile ID on
+// first use, grows with each profile saved or loaded here and, with a
+// replica set, each one the seller_profiles change stream reports, and is
+// reloaded in the background after KNOWN_SELLERS_TTL. That bounds how long
+// a profile created by another process (another replica without a change
+// stream, imvoicectl) can go unseen, like PROFILE_CACHE_TTL bounds staleness
+// of cached profiles. Until it's loaded, every seller may have a profile.
+// False positives only cost the reads the filter would have saved.
+
+const (
+	knownSellersMinCapacity = 50_000
+	knownSellersBitsPerItem = 10 // ~1% false positives with knownSellersHashes
+	knownSellersHashes      = 7
+)
+
+type bloomFilter struct {
+	bits []uint64
+	m    uint64
+}
+
+func newBloomFilter(capacity int) *bloomFilter {
+	m := uint64(max(capacity, knownSellersMinCapacity) * knownSellersBitsPerItem)
+	return &bloomFilter{bits: make([]uint64, (m+63)/64), m: m}
+}
+
+// positions calls fn with each bit position of key (double hashing)
+func (f *bloomFilter) positions(key string, fn func(uint64)) {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	sum := h.Sum64()
+	h1, h2 := sum&0xffffffff, sum>>32|1
+	for i := uint64(0); i < knownSellersHashes; i++ {
+		fn((h1 + i*h2) % f.m)
+	}
+}
+
+func (f *bloomFilter) add(key string) {
+	f.positions(key, func(p uint64) { f.bits[p/64] |= 1 << (p % 64) })
+}
+
+func (f *bloomFilter) has(key string) bool {
+	found := true
+	f.positions(key, func(p uint64) {
+		if f.bits[p/64]&(1<<(p%64)) == 0 {
+			found = false
+		}
+	})
+	return found
+}
+
+type knownSellerSet struct {
+	mu       sync.Mutex
+	ttl      time.Duration // 0 disables the filter
+	filter   *bloomFilter  // nil until loaded
+	loadedAt time.Time
+	loading  bool
+	pending  []string // Added while loading, applied to the loaded filter
+}
+
+var knownSellers = &knownSellerSet{ttl: knownSellersTTL()}
+
+// knownSellersTTL returns KNOWN_SELLERS_TTL, 0 when the filter is disabled
+func knownSellersTTL() time.Duration {
+	if os.Getenv("KNOWN_SELLERS_TTL") == "0" {
+		return 0
+	}
+	return config.EnvDuration("KNOWN_SELLERS_TTL", config.DEFAULT_KNOWN_SELLERS_TTL)
+}
+
+// MayHaveSellerProfile reports whether gluserID may have a stored profile.
+// False means it certainly had none when the filter was loaded and none was
+// saved here since, so there's no need to read it.
+func MayHaveSellerProfile(gluserID string) bool {
+	return knownSellers.mayHave(gluserID)
+}
+
+func (s *knownSellerSet) mayHave(gluserID string) bool {
+	if s.ttl == 0 {
+		return true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.filter == nil || time.Since(s.loadedAt) > s.ttl {
+		if !s.loading {
+			s.loading = true
+			go s.load()
+		}
+		if s.filter == nil {
+			return true
+		}
+	}
+	return s.filter.has(gluserID)
+}
+
+// add records a seller with a profile
+func (s *knownSellerSet) add(gluserID string) {
+	if s.ttl == 0 || gluserID == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.filter != nil {
+		s.filter.add(gluserID)
+	}
+	if s.loading {
+		s.pending = append(s.pending, gluserID)
+	}
+}
+
+// reset empties the filter, for when every profile was deleted
+func (s *knownSellerSet) reset() {
+	if s.ttl == 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.filter, s.loadedAt, s.pending = newBloomFilter(0), time.Now(), nil
+}
+
+// load rebuilds the filter from the stored profile IDs. If they can't be
+// listed, the filter is dropped until a later check loads it.
+func (s *knownSellerSet) load() {
+	ids, err := listStoredSellerIDs(context.Background())
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.loading = false
+	if err != nil {
+		log.Printf("⚠️ Failed to load known sellers, reading every seller's profile: %v", err)
+		s.filter, s.pending = nil, nil
+		return
+	}
+	filter := newBloomFilter(2 * len(ids))
+	for _, id := range ids {
+		filter.add(id)
+	}
+	for _, id := range s.pending {
+		filter.add(id)
+	}
+	s.filter, s.loadedAt, s.pending = filter, time.Now(), nil
+}
+
+// listStoredSellerIDs returns the IDs of profiles in MongoDB and in files,
+// since LoadSellerProfile falls back to files even with MongoDB
+func listStoredSellerIDs(ctx context.Context) ([]string, error) {
+	ids, err := ListSellerProfiles()
+	if err != nil {
+		return nil, err
+	}
+	if IsMongoEnabled() {
+		ctx, cancel := context.WithTimeout(ctx, scanTimeout)
+		defer cancel()
+		mongoIDs, err := ListAllSellerIDsFromMongo(ctx)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, mongoIDs...)
+	}
+	return ids, nil
+}
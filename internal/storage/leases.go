@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ==================== LEASES ====================
+// Named leases in the leases collection, used for leader election between
+// replicas (see internal/leader). Expiry is compared against the MongoDB
+// server's clock, so instance clock skew doesn't matter. MongoDB only: a
+// lease guards against other instances, and without a shared database
+// there are none to see it.
+
+// Lease is the current holder of a named lease
+type Lease struct {
+	Name       string    `bson:"_id"`
+	Holder     string    `bson:"holder"`
+	AcquiredAt time.Time `bson:"acquired_at"` // When the holder took it, kept across renewals
+	RenewedAt  time.Time `bson:"renewed_at"`
+	ExpiresAt  time.Time `bson:"expires_at"`
+}
+
+// AcquireLease takes the lease for holder if it's free or expired, or renews
+// it if holder already has it. ok is false while another holder's lease is
+// unexpired; the returned lease is then theirs.
+func AcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (lease *Lease, ok bool, err error) {
+	if !IsMongoEnabled() {
+		return nil, false, fmt.Errorf("MongoDB not enabled")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	filter := bson.M{
+		"_id": name,
+		"$or": bson.A{
+			bson.M{"holder": holder},
+			bson.M{"$expr": bson.M{"$lt": bson.A{"$expires_at", "$$NOW"}}},
+		},
+	}
+	update := mongo.Pipeline{{{Key: "$set", Value: bson.M{
+		"holder":      holder,
+		"acquired_at": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$holder", holder}}, "$acquired_at", "$$NOW"}},
+		"renewed_at":  "$$NOW",
+		"expires_at":  bson.M{"$add": bson.A{"$$NOW", ttl.Milliseconds()}},
+	}}}}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var l Lease
+	err = MongoDB.database.Collection(COLLECTION_LEASES).FindOneAndUpdate(ctx, filter, update, opts).Decode(&l)
+	if mongo.IsDuplicateKeyError(err) {
+		// Held by someone else: the upsert tried to insert a second document
+		current, err := getLease(ctx, name)
+		return current, false, err
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to acquire lease %s: %w", name, err)
+	}
+	return &l, true, nil
+}
+
+// ReleaseLease gives up holder's lease so another instance can take it
+// without waiting for it to expire
+func ReleaseLease(ctx context.Context, name, holder string) error {
+	if !IsMongoEnabled() {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	_, err := MongoDB.database.Collection(COLLECTION_LEASES).DeleteOne(ctx, bson.M{"_id": name, "holder": holder})
+	return err
+}
+
+func getLease(ctx context.Context, name string) (*Lease, error) {
+	var l Lease
+	err := MongoDB.database.Collection(COLLECTION_LEASES).FindOne(ctx, bson.M{"_id": name}).Decode(&l)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
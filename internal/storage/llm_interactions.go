@@ -0,0 +1,226 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ==================== RECORDED LLM INTERACTIONS ====================
+// Analysis requests and responses recorded with LLM_RECORD=true, one per
+// call, prompt version and pass. With MongoDB they're in llm_interactions,
+// whose TTL index drops them after LLM_RAW_RETENTION_DAYS like raw
+// responses, otherwise in a directory per prompt version under
+// LLM_INTERACTIONS_DIR that PurgeExpiredLLMInteractions cleans up.
+
+// LLMInteractionQuery filters recorded interactions; empty fields match everything
+type LLMInteractionQuery struct {
+	CallID        string
+	PromptVersion string
+}
+
+func (q LLMInteractionQuery) matches(in client.LLMInteraction) bool {
+	return (q.CallID == "" || in.CallID == q.CallID) &&
+		(q.PromptVersion == "" || in.PromptVersion == q.PromptVersion)
+}
+
+// SaveLLMInteraction stores a recorded interaction, replacing the call's
+// earlier one for the same prompt version and pass - MongoDB first, local fallback
+func SaveLLMInteraction(ctx context.Context, in *client.LLMInteraction) error {
+	if IsMongoEnabled() {
+		return saveLLMInteractionToMongo(ctx, in)
+	}
+	b, err := json.MarshalIndent(in, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal LLM interaction: %w", err)
+	}
+	path := llmInteractionPath(in)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return writeFile(path, b, 0644)
+}
+
+// LoadLLMInteractions returns the recorded interactions matching q, by call
+// then pass - MongoDB first, local fallback
+func LoadLLMInteractions(ctx context.Context, q LLMInteractionQuery) ([]client.LLMInteraction, error) {
+	var list []client.LLMInteraction
+	if IsMongoEnabled() {
+		var err error
+		if list, err = getLLMInteractionsFromMongo(ctx, q); err != nil {
+			return nil, err
+		}
+	} else {
+		err := walkLLMInteractions(ctx, q.PromptVersion, func(path string, in client.LLMInteraction) {
+			if q.matches(in) {
+				list = append(list, in)
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	cutoff := time.Now().Add(-llmRawRetention())
+	kept := list[:0]
+	for _, in := range list {
+		if !in.RecordedAt.Before(cutoff) { // TTL monitor or purge hasn't run yet
+			kept = append(kept, in)
+		}
+	}
+	sort.Slice(kept, func(i, j int) bool {
+		if kept[i].CallID != kept[j].CallID {
+			return kept[i].CallID < kept[j].CallID
+		}
+		if kept[i].PromptVersion != kept[j].PromptVersion {
+			return kept[i].PromptVersion < kept[j].PromptVersion
+		}
+		return kept[i].Pass < kept[j].Pass
+	})
+	return kept, nil
+}
+
+// PurgeExpiredLLMInteractions deletes recorded interaction files past the
+// retention age. MongoDB's TTL index does this for llm_interactions on its own.
+func PurgeExpiredLLMInteractions(ctx context.Context) (int, error) {
+	if IsMongoEnabled() {
+		return 0, nil
+	}
+	cutoff := time.Now().Add(-llmRawRetention())
+	purged := 0
+	err := walkLLMInteractions(ctx, "", func(path string, in client.LLMInteraction) {
+		if in.RecordedAt.Before(cutoff) {
+			if err := os.Remove(path); err == nil {
+				purged++
+			}
+		}
+	})
+	if purged > 0 {
+		log.Printf("🗑️ Purged %d recorded LLM interactions (older than %d days)", purged, LLMRawRetentionDays())
+	}
+	return purged, err
+}
+
+// walkLLMInteractions calls fn with each recorded interaction file, those
+// of promptVersion only when it's set
+func walkLLMInteractions(ctx context.Context, promptVersion string, fn func(path string, in client.LLMInteraction)) error {
+	root := config.LLM_INTERACTIONS_DIR
+	if promptVersion != "" {
+		root = filepath.Join(root, Sanitize(promptVersion))
+	}
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if d.IsDir() || !strings.HasSuffix(d.Name(), ".json") {
+			return nil
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		var in client.LLMInteraction
+		if err := json.Unmarshal(b, &in); err != nil {
+			return nil // Skip corrupt files
+		}
+		fn(path, in)
+		return nil
+	})
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func llmInteractionPath(in *client.LLMInteraction) string {
+	return filepath.Join(config.LLM_INTERACTIONS_DIR, Sanitize(in.PromptVersion),
+		fmt.Sprintf("call_%s_%s.json", Sanitize(in.CallID), Sanitize(in.Pass)))
+}
+
+// ==================== RECORDED LLM INTERACTIONS (MongoDB) ====================
+
+func ensureLLMInteractionsTTL(ctx context.Context, db *mongo.Database) error {
+	seconds := int32(llmRawRetention() / time.Second)
+	_, err := db.Collection(COLLECTION_LLM_INTERACTIONS).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "recorded_at", Value: 1}},
+		Options: options.Index().SetName("recorded_at_ttl").SetExpireAfterSeconds(seconds),
+	})
+	var cmdErr mongo.CommandError
+	if !errors.As(err, &cmdErr) || (cmdErr.Name != "IndexOptionsConflict" && cmdErr.Name != "IndexKeySpecsConflict") {
+		return err
+	}
+	return db.RunCommand(ctx, bson.D{
+		{Key: "collMod", Value: COLLECTION_LLM_INTERACTIONS},
+		{Key: "index", Value: bson.D{{Key: "name", Value: "recorded_at_ttl"}, {Key: "expireAfterSeconds", Value: seconds}}},
+	}).Err()
+}
+
+func saveLLMInteractionToMongo(ctx context.Context, in *client.LLMInteraction) error {
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	doc, err := ToBsonM(in)
+	if err != nil {
+		return fmt.Errorf("failed to marshal LLM interaction: %w", err)
+	}
+	doc["recorded_at"] = in.RecordedAt // A date, for the TTL index
+
+	filter := bson.M{"call_id": in.CallID, "prompt_version": in.PromptVersion, "pass": in.Pass}
+	opts := options.Replace().SetUpsert(true)
+	if _, err := MongoDB.database.Collection(COLLECTION_LLM_INTERACTIONS).ReplaceOne(ctx, filter, doc, opts); err != nil {
+		return fmt.Errorf("failed to save LLM interaction to MongoDB: %w", err)
+	}
+	return nil
+}
+
+func getLLMInteractionsFromMongo(ctx context.Context, q LLMInteractionQuery) ([]client.LLMInteraction, error) {
+	ctx, cancel := context.WithTimeout(ctx, scanTimeout)
+	defer cancel()
+
+	filter := bson.M{}
+	if q.CallID != "" {
+		filter["call_id"] = q.CallID
+	}
+	if q.PromptVersion != "" {
+		filter["prompt_version"] = q.PromptVersion
+	}
+	cursor, err := MongoDB.database.Collection(COLLECTION_LLM_INTERACTIONS).Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	list := []client.LLMInteraction{}
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			continue
+		}
+		jsonBytes, err := json.Marshal(doc)
+		if err != nil {
+			continue
+		}
+		var in client.LLMInteraction
+		if err := json.Unmarshal(jsonBytes, &in); err != nil {
+			continue
+		}
+		list = append(list, in)
+	}
+	return list, cursor.Err()
+}
@@ -0,0 +1,299 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ==================== RAW LLM RESPONSES ====================
+// The analysis passes leave Gemini's raw responses in llm_raw_response
+// (raw_extraction, raw_scoring, and raw from before the two passes). They
+// make analyses huge, so they're split off on save, like tracked issues off
+// profiles: with MongoDB into llm_raw_responses, whose TTL index drops them
+// after LLM_RAW_RETENTION_DAYS, otherwise into files under LLM_RAW_DIR that
+// PurgeExpiredLLMRaw deletes. LoadLLMRaw reads them back for debugging.
+
+// rawLLMKeys maps the llm_raw_response keys holding raw responses to their names in LLMRawResponse
+var rawLLMKeys = map[string]string{
+	"raw_extraction": "extraction",
+	"raw_scoring":    "scoring",
+	"raw":            "response",
+}
+
+// LLMRawRetentionDays returns how long raw responses are kept
+func LLMRawRetentionDays() int {
+	if v := os.Getenv("LLM_RAW_RETENTION_DAYS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return n
+		}
+	}
+	return config.DEFAULT_LLM_RAW_RETENTION_DAYS
+}
+
+func llmRawRetention() time.Duration {
+	return time.Duration(LLMRawRetentionDays()) * 24 * time.Hour
+}
+
+// splitLLMRaw returns the analysis without its raw responses, and the raw
+// responses (nil without any). The caller's LLMRaw map isn't modified.
+func splitLLMRaw(ar client.AnalysisResult) (client.AnalysisResult, *client.LLMRawResponse) {
+	var raw *client.LLMRawResponse
+	for key, name := range rawLLMKeys {
+		v, ok := ar.LLMRaw[key].(string)
+		if !ok {
+			continue
+		}
+		if raw == nil {
+			raw = &client.LLMRawResponse{CallID: ar.CallID, Responses: map[string]string{}, CreatedAt: ar.AnalyzedAt}
+			lean := maps.Clone(ar.LLMRaw)
+			ar.LLMRaw = lean
+		}
+		raw.Responses[name] = v
+		delete(ar.LLMRaw, key)
+	}
+	if raw != nil && raw.CreatedAt.IsZero() {
+		raw.CreatedAt = time.Now()
+	}
+	return ar, raw
+}
+
+// saveSplitLLMRaw stores the raw responses split off an analysis. Losing
+// them only costs debugging, so failures are logged rather than returned.
+func saveSplitLLMRaw(ctx context.Context, raw *client.LLMRawResponse) {
+	if raw == nil {
+		return
+	}
+	if err := SaveLLMRaw(ctx, raw); err != nil {
+		log.Printf("⚠️ Failed to save raw LLM responses for call %s: %v", raw.CallID, err)
+	}
+}
+
+// SaveLLMRaw stores a call's raw responses, replacing any earlier ones - MongoDB first, local fallback
+func SaveLLMRaw(ctx context.Context, raw *client.LLMRawResponse) error {
+	if IsMongoEnabled() {
+		return saveLLMRawToMongo(ctx, raw)
+	}
+	b, err := json.MarshalIndent(raw, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal raw responses: %w", err)
+	}
+	return writeFile(llmRawPath(raw.CallID), b, 0644)
+}
+
+// LoadLLMRaw returns a call's raw responses, nil once they've expired or
+// if there were none - MongoDB first, local fallback
+func LoadLLMRaw(ctx context.Context, callID string) (*client.LLMRawResponse, error) {
+	var raw *client.LLMRawResponse
+	if IsMongoEnabled() {
+		var err error
+		if raw, err = getLLMRawFromMongo(ctx, callID); err != nil {
+			return nil, err
+		}
+	} else {
+		b, err := os.ReadFile(llmRawPath(callID))
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		raw = &client.LLMRawResponse{}
+		if err := json.Unmarshal(b, raw); err != nil {
+			return nil, err
+		}
+	}
+	if raw == nil {
+		return nil, nil
+	}
+	raw.ExpiresAt = raw.CreatedAt.Add(llmRawRetention())
+	if time.Now().After(raw.ExpiresAt) {
+		return nil, nil // TTL monitor or purge hasn't run yet
+	}
+	return raw, nil
+}
+
+// PurgeExpiredLLMRaw deletes raw response files past the retention age.
+// MongoDB's TTL index does this for llm_raw_responses on its own.
+func PurgeExpiredLLMRaw(ctx context.Context) (int, error) {
+	if IsMongoEnabled() {
+		return 0, nil
+	}
+	entries, err := os.ReadDir(config.LLM_RAW_DIR)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	cutoff := time.Now().Add(-llmRawRetention())
+	purged := 0
+	for _, e := range entries {
+		if ctx.Err() != nil {
+			return purged, ctx.Err()
+		}
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		path := filepath.Join(config.LLM_RAW_DIR, e.Name())
+		b, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var raw client.LLMRawResponse
+		if err := json.Unmarshal(b, &raw); err != nil || raw.CreatedAt.Before(cutoff) {
+			if err := os.Remove(path); err == nil {
+				purged++
+			}
+		}
+	}
+	if purged > 0 {
+		log.Printf("🗑️ Purged raw LLM responses of %d calls (older than %d days)", purged, LLMRawRetentionDays())
+	}
+	return purged, nil
+}
+
+func llmRawPath(callID string) string {
+	return filepath.Join(config.LLM_RAW_DIR, fmt.Sprintf("call_%s.json", Sanitize(callID)))
+}
+
+// ==================== RAW LLM RESPONSES (MongoDB) ====================
+// Written as native BSON rather than through ToBsonM: the TTL index only
+// expires documents whose created_at is a BSON date.
+
+// ensureLLMRawTTL creates the TTL index on created_at, or updates its
+// expiry when LLM_RAW_RETENTION_DAYS has changed
+func ensureLLMRawTTL(ctx context.Context, db *mongo.Database) error {
+	seconds := int32(llmRawRetention() / time.Second)
+	_, err := db.Collection(COLLECTION_LLM_RAW).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "created_at", Value: 1}},
+		Options: options.Index().SetName("created_at_ttl").SetExpireAfterSeconds(seconds),
+	})
+	var cmdErr mongo.CommandError
+	if !errors.As(err, &cmdErr) || (cmdErr.Name != "IndexOptionsConflict" && cmdErr.Name != "IndexKeySpecsConflict") {
+		return err
+	}
+	return db.RunCommand(ctx, bson.D{
+		{Key: "collMod", Value: COLLECTION_LLM_RAW},
+		{Key: "index", Value: bson.D{{Key: "name", Value: "created_at_ttl"}, {Key: "expireAfterSeconds", Value: seconds}}},
+	}).Err()
+}
+
+func saveLLMRawToMongo(ctx context.Context, raw *client.LLMRawResponse) error {
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	doc := bson.M{"call_id": raw.CallID, "responses": raw.Responses, "created_at": raw.CreatedAt}
+	filter := bson.M{"call_id": raw.CallID}
+	opts := options.Replace().SetUpsert(true)
+	if _, err := MongoDB.database.Collection(COLLECTION_LLM_RAW).ReplaceOne(ctx, filter, doc, opts); err != nil {
+		return fmt.Errorf("failed to save raw responses to MongoDB: %w", err)
+	}
+	return nil
+}
+
+func getLLMRawFromMongo(ctx context.Context, callID string) (*client.LLMRawResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	var doc struct {
+		CallID    string            `bson:"call_id"`
+		Responses map[string]string `bson:"responses"`
+		CreatedAt time.Time         `bson:"created_at"`
+	}
+	err := MongoDB.database.Collection(COLLECTION_LLM_RAW).FindOne(ctx, bson.M{"call_id": callID}).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &client.LLMRawResponse{CallID: doc.CallID, Responses: doc.Responses, CreatedAt: doc.CreatedAt}, nil
+}
+
+// ==================== MIGRATION ====================
+
+// LLMRawMigrationResult summarizes MigrateLLMRaw
+type LLMRawMigrationResult struct {
+	Analyses int `json:"analyses"` // Analyses that carried raw responses
+	Moved    int `json:"moved"`    // Raw responses moved to storage of their own
+	Dropped  int `json:"dropped"`  // Already past the retention age, so not kept
+}
+
+// MigrateLLMRaw splits the raw responses off analyses saved before they
+// were stored apart. Responses past the retention age are dropped.
+func MigrateLLMRaw(ctx context.Context, dryRun bool) (*LLMRawMigrationResult, error) {
+	result := &LLMRawMigrationResult{}
+	cutoff := time.Now().Add(-llmRawRetention())
+	migrate := func(ar client.AnalysisResult, save func(client.AnalysisResult) error) error {
+		lean, raw := splitLLMRaw(ar)
+		if raw == nil {
+			return nil
+		}
+		result.Analyses++
+		if raw.CreatedAt.Before(cutoff) {
+			result.Dropped++
+			raw = nil
+		} else {
+			result.Moved++
+		}
+		if dryRun {
+			return nil
+		}
+		if raw != nil {
+			if err := SaveLLMRaw(ctx, raw); err != nil {
+				return err
+			}
+		}
+		return save(lean)
+	}
+
+	if IsMongoEnabled() {
+		analyses, err := GetAllAnalysesFromMongo(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, ar := range analyses {
+			err := migrate(ar, func(lean client.AnalysisResult) error { return SaveAnalysisToMongo(ctx, &lean) })
+			if err != nil {
+				return result, fmt.Errorf("call %s: %w", ar.CallID, err)
+			}
+		}
+		return result, nil
+	}
+
+	files, err := ListAnalysisFiles()
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			continue
+		}
+		var ar client.AnalysisResult
+		if err := json.Unmarshal(b, &ar); err != nil {
+			continue
+		}
+		err = migrate(ar, func(lean client.AnalysisResult) error { return writeAnalysisFile(f, lean) })
+		if err != nil {
+			return result, fmt.Errorf("%s: %w", filepath.Base(f), err)
+		}
+	}
+	return result, nil
+}
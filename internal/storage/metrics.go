@@ -0,0 +1,369 @@
+package storage
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ==================== SELLER METRICS ====================
+// Per-call trend values for every call a seller has made. Profiles roll old
+// trend points up by day to stay small; this is where the full-resolution
+// history lives. With MongoDB it's the seller_metrics time-series collection
+// (meta field gluser_id, time field timestamp), otherwise one JSONL file per
+// seller under METRICS_DIR.
+//
+// Both stores are append-only: a call analyzed again gets a second metric,
+// and reads keep the most recently recorded one per call.
+
+var metricsFileMu sync.Mutex
+
+// MetricQuery selects a seller's metrics for LoadSellerMetrics
+type MetricQuery struct {
+	GluserID string
+	From     time.Time // Inclusive, zero for no lower bound
+	To       time.Time // Exclusive, zero for no upper bound
+}
+
+func (q MetricQuery) matches(m client.SellerMetric) bool {
+	if !q.From.IsZero() && m.Timestamp.Before(q.From) {
+		return false
+	}
+	if !q.To.IsZero() && !m.Timestamp.Before(q.To) {
+		return false
+	}
+	return true
+}
+
+// RecordSellerMetric stores one call's metric. Failures are logged, never
+// returned, so a metrics outage can't block profile updates.
+func RecordSellerMetric(ctx context.Context, m client.SellerMetric) {
+	if m.RecordedAt.IsZero() {
+		m.RecordedAt = time.Now()
+	}
+
+	if IsMongoEnabled() {
+		SyncSellerMetric(ctx, &m)
+		return
+	}
+
+	if err := appendSellerMetricToFile(m); err != nil {
+		log.Printf("⚠️ Failed to record metric for call %s: %v", m.CallID, err)
+	}
+}
+
+// SaveSellerMetric stores one call's metric synchronously - MongoDB first, local fallback
+func SaveSellerMetric(ctx context.Context, m client.SellerMetric) error {
+	if m.RecordedAt.IsZero() {
+		m.RecordedAt = time.Now()
+	}
+
+	if IsMongoEnabled() {
+		return SaveSellerMetricToMongo(ctx, &m)
+	}
+	return appendSellerMetricToFile(m)
+}
+
+// LoadSellerMetrics returns a seller's metrics in the query range, oldest first - MongoDB first, local fallback
+func LoadSellerMetrics(ctx context.Context, q MetricQuery) ([]client.SellerMetric, error) {
+	var metrics []client.SellerMetric
+	var err error
+	if IsMongoEnabled() {
+		metrics, err = getSellerMetricsFromMongo(ctx, q)
+	} else {
+		metrics, err = loadSellerMetricsFromFile(q)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	metrics = latestMetricPerCall(metrics)
+	sort.SliceStable(metrics, func(i, j int) bool { return metrics[i].Timestamp.Before(metrics[j].Timestamp) })
+	return metrics, nil
+}
+
+// latestMetricPerCall drops metrics superseded by a later recording of the same call
+func latestMetricPerCall(metrics []client.SellerMetric) []client.SellerMetric {
+	out := make([]client.SellerMetric, 0, len(metrics))
+	seen := make(map[string]int, len(metrics))
+	for _, m := range metrics {
+		if i, ok := seen[m.CallID]; ok {
+			if !m.RecordedAt.Before(out[i].RecordedAt) {
+				out[i] = m
+			}
+			continue
+		}
+		seen[m.CallID] = len(out)
+		out = append(out, m)
+	}
+	return out
+}
+
+func sellerMetricsPath(gluserID string) string {
+	return filepath.Join(config.METRICS_DIR, fmt.Sprintf("seller_%s.jsonl", Sanitize(gluserID)))
+}
+
+// appendSellerMetricToFile appends to the seller's JSONL file
+func appendSellerMetricToFile(m client.SellerMetric) error {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("failed to marshal metric: %w", err)
+	}
+
+	metricsFileMu.Lock()
+	defer metricsFileMu.Unlock()
+
+	f, err := openAppend(sellerMetricsPath(m.GluserID), 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(b, '\n'))
+	return err
+}
+
+// loadSellerMetricsFromFile reads the seller's JSONL file
+func loadSellerMetricsFromFile(q MetricQuery) ([]client.SellerMetric, error) {
+	f, err := os.Open(sellerMetricsPath(q.GluserID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []client.SellerMetric{}, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	metrics := []client.SellerMetric{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var m client.SellerMetric
+		if err := json.Unmarshal(scanner.Bytes(), &m); err != nil {
+			continue
+		}
+		if q.matches(m) {
+			metrics = append(metrics, m)
+		}
+	}
+	return metrics, scanner.Err()
+}
+
+// ==================== SELLER METRICS (MongoDB) ====================
+
+// sellerMetricsTimeSeries describes the seller_metrics collection
+var sellerMetricsTimeSeries = options.TimeSeries().
+	SetTimeField("timestamp").
+	SetMetaField("gluser_id").
+	SetGranularity("hours")
+
+// ensureSellerMetricsCollection creates seller_metrics as a time-series
+// collection. A regular collection left by older versions is converted.
+func ensureSellerMetricsCollection(ctx context.Context, db *mongo.Database) error {
+	specs, err := db.ListCollectionSpecifications(ctx, bson.M{"name": COLLECTION_SELLER_METRICS})
+	if err != nil {
+		return err
+	}
+	if len(specs) > 0 && specs[0].Type == "timeseries" {
+		return nil
+	}
+	if len(specs) > 0 {
+		return convertSellerMetricsCollection(ctx, db)
+	}
+	return createSellerMetricsCollection(ctx, db)
+}
+
+func createSellerMetricsCollection(ctx context.Context, db *mongo.Database) error {
+	opts := options.CreateCollection().SetTimeSeriesOptions(sellerMetricsTimeSeries)
+	err := db.CreateCollection(ctx, COLLECTION_SELLER_METRICS, opts)
+	var cmdErr mongo.CommandError
+	if errors.As(err, &cmdErr) && cmdErr.Name == "NamespaceExists" {
+		return nil // Created concurrently by another instance
+	}
+	return err
+}
+
+// convertSellerMetricsCollection moves metrics stored in a regular collection
+// (timestamps as strings) into a new time-series collection
+func convertSellerMetricsCollection(ctx context.Context, db *mongo.Database) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), scanTimeout)
+	defer cancel()
+
+	legacy := COLLECTION_SELLER_METRICS + "_legacy"
+	log.Printf("🔄 Converting %s to a time-series collection", COLLECTION_SELLER_METRICS)
+
+	rename := bson.D{
+		{Key: "renameCollection", Value: DB_NAME + "." + COLLECTION_SELLER_METRICS},
+		{Key: "to", Value: DB_NAME + "." + legacy},
+	}
+	if err := db.Client().Database("admin").RunCommand(ctx, rename).Err(); err != nil {
+		return fmt.Errorf("failed to rename %s: %w", COLLECTION_SELLER_METRICS, err)
+	}
+	if err := createSellerMetricsCollection(ctx, db); err != nil {
+		return err
+	}
+
+	cursor, err := db.Collection(legacy).Find(ctx, bson.M{})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	target := db.Collection(COLLECTION_SELLER_METRICS)
+	batch := make([]interface{}, 0, 500)
+	moved := 0
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if _, err := target.InsertMany(ctx, batch); err != nil {
+			return err
+		}
+		moved += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			continue
+		}
+		m, err := decodeSellerMetric(doc)
+		if err != nil {
+			continue
+		}
+		out, err := sellerMetricDoc(&m)
+		if err != nil {
+			continue
+		}
+		if batch = append(batch, out); len(batch) == cap(batch) {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+
+	log.Printf("   ✅ Moved %d metrics into %s", moved, COLLECTION_SELLER_METRICS)
+	return db.Collection(legacy).Drop(ctx)
+}
+
+// resetSellerMetricsCollection drops and recreates seller_metrics. Time-series
+// collections only support deleting by meta field before MongoDB 7.0.
+func resetSellerMetricsCollection(ctx context.Context) error {
+	if err := MongoDB.database.Collection(COLLECTION_SELLER_METRICS).Drop(ctx); err != nil {
+		return err
+	}
+	return createSellerMetricsCollection(ctx, MongoDB.database)
+}
+
+// sellerMetricDoc converts a metric to its document. Time-series collections
+// need a BSON date in the time field, so the timestamp isn't stored as a string.
+func sellerMetricDoc(m *client.SellerMetric) (bson.M, error) {
+	doc, err := ToBsonM(m)
+	if err != nil {
+		return nil, err
+	}
+	doc["timestamp"] = m.Timestamp
+	return doc, nil
+}
+
+func decodeSellerMetric(doc bson.M) (client.SellerMetric, error) {
+	var m client.SellerMetric
+	jsonBytes, err := json.Marshal(doc)
+	if err != nil {
+		return m, err
+	}
+	err = json.Unmarshal(jsonBytes, &m)
+	return m, err
+}
+
+// SyncSellerMetric pushes a call's metric to MongoDB
+func SyncSellerMetric(ctx context.Context, m *client.SellerMetric) {
+	if MongoDB == nil || !MongoDB.enabled {
+		return
+	}
+
+	go func() {
+		if err := SaveSellerMetricToMongo(context.WithoutCancel(ctx), m); err != nil {
+			log.Printf("⚠️  MongoDB sync failed for metric %s: %v", m.CallID, err)
+		}
+	}()
+}
+
+// SaveSellerMetricToMongo inserts a call's metric into MongoDB (synchronous)
+func SaveSellerMetricToMongo(ctx context.Context, m *client.SellerMetric) error {
+	if MongoDB == nil || !MongoDB.enabled {
+		return fmt.Errorf("MongoDB not enabled")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	doc, err := sellerMetricDoc(m)
+	if err != nil {
+		return fmt.Errorf("failed to marshal metric: %w", err)
+	}
+
+	if _, err := MongoDB.database.Collection(COLLECTION_SELLER_METRICS).InsertOne(ctx, doc); err != nil {
+		return fmt.Errorf("failed to save metric to MongoDB: %w", err)
+	}
+	return nil
+}
+
+func getSellerMetricsFromMongo(ctx context.Context, q MetricQuery) ([]client.SellerMetric, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	filter := bson.M{"gluser_id": q.GluserID}
+	timeRange := bson.M{}
+	if !q.From.IsZero() {
+		timeRange["$gte"] = q.From
+	}
+	if !q.To.IsZero() {
+		timeRange["$lt"] = q.To
+	}
+	if len(timeRange) > 0 {
+		filter["timestamp"] = timeRange
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "timestamp", Value: 1}})
+	cursor, err := MongoDB.database.Collection(COLLECTION_SELLER_METRICS).Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	metrics := []client.SellerMetric{}
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			continue
+		}
+		m, err := decodeSellerMetric(doc)
+		if err != nil {
+			continue
+		}
+		metrics = append(metrics, m)
+	}
+	return metrics, cursor.Err()
+}
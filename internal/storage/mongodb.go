@@ -0,0 +1,1228 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/chaos"
+	"im-ai-voice/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ToBsonM converts any struct to bson.M using JSON tags
+// This ensures field names match JSON tags (lowercase with underscores)
+func ToBsonM(v interface{}) (bson.M, error) {
+	// Convert to JSON first (uses json tags)
+	jsonBytes, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	// Convert JSON to bson.M
+	var doc bson.M
+	if err := json.Unmarshal(jsonBytes, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// MongoDB collections
+const (
+	DB_NAME                     = "indiamart_voice"
+	COLLECTION_PROFILES         = "seller_profiles"
+	COLLECTION_SELLER_SUMMARIES = "seller_summaries"
+	COLLECTION_ANALYSES         = "call_analyses"
+	COLLECTION_TICKETS          = "tickets"
+	COLLECTION_AGGREGATES       = "daily_aggregates"
+	COLLECTION_ALERTS           = "alerts"
+	COLLECTION_EVENTS           = "events"
+	COLLECTION_ISSUES           = "issues"
+	COLLECTION_AUDIT            = "audit_log"
+	COLLECTION_RECORDINGS       = "call_recordings"
+	COLLECTION_GITHUB           = "github_issues"
+	COLLECTION_LEASES           = "leases"
+	COLLECTION_ATTENTION        = "attention_acks"
+	COLLECTION_EXTRACTIONS      = "call_extractions"
+	COLLECTION_SYSTEMIC         = "systemic_issues"
+	COLLECTION_LLM_RAW          = "llm_raw_responses"
+	COLLECTION_LLM_INTERACTIONS = "llm_interactions"
+	COLLECTION_SUPPRESSIONS     = "ticket_suppressions"
+	COLLECTION_SHIFT_AGGS       = "shift_aggregates"
+	COLLECTION_KB               = "kb_documents"
+	COLLECTION_COMMITMENTS      = "commitments"
+	COLLECTION_PITCHES          = "upsell_pitches"
+	COLLECTION_RATE_LIMITS      = "rate_limits"
+	COLLECTION_IDEMPOTENCY      = "idempotency_keys"
+	COLLECTION_TRASH            = "trash"
+	COLLECTION_ANNOTATIONS      = "call_annotations"
+	COLLECTION_OWNERS           = "bucket_owners"
+	COLLECTION_VERSIONS         = "analysis_versions"
+	COLLECTION_SHADOW           = "shadow_analyses"
+	COLLECTION_ROLLOUTS         = "rollouts"
+	COLLECTION_THEMES           = "insight_themes"
+	COLLECTION_CALIBRATION      = "severity_calibration"
+	COLLECTION_KEY_USAGE        = "key_usage"
+	COLLECTION_WEBHOOKS         = "webhook_subscriptions"
+	COLLECTION_ALERT_SUBS       = "alert_subscriptions"
+	COLLECTION_VAULT            = "pii_vault"
+	COLLECTION_FLAGS            = "feature_flags"
+	COLLECTION_CUSTOM_FIELDS    = "custom_fields"
+	COLLECTION_SNAPSHOTS        = "dashboard_snapshots"
+	COLLECTION_SAMPLES          = "call_samples"
+	COLLECTION_RECLASSIFY       = "reclassifications"
+	COLLECTION_RECLASSIFY_LOG   = "reclassification_changes"
+	COLLECTION_QUOTES           = "seller_quotes"
+	COLLECTION_QUOTE_OPT_OUTS   = "quote_opt_outs"
+	COLLECTION_INGEST_JOBS      = "ingest_jobs"
+	COLLECTION_CALL_CHATS       = "call_chats"
+	COLLECTION_CHURN_OUTCOMES   = "churn_outcomes"
+	COLLECTION_JOBS             = "jobs"
+	COLLECTION_PROVISIONAL      = "provisional_calls"
+	COLLECTION_EVAL_RUNS        = "eval_runs"
+
+	COLLECTION_SELLER_METRICS = "seller_metrics"
+)
+
+// Per-operation deadlines, applied on top of the caller's context so a
+// cancelled request also cancels its MongoDB calls
+var (
+	opTimeout    = config.EnvDuration("MONGO_OP_TIMEOUT", config.DEFAULT_MONGO_OP_TIMEOUT)
+	queryTimeout = config.EnvDuration("MONGO_QUERY_TIMEOUT", config.DEFAULT_MONGO_QUERY_TIMEOUT)
+	scanTimeout  = config.EnvDuration("MONGO_SCAN_TIMEOUT", config.DEFAULT_MONGO_SCAN_TIMEOUT)
+)
+
+// MongoClient wraps the MongoDB client
+type MongoClient struct {
+	client   *mongo.Client
+	database *mongo.Database
+	enabled  bool
+}
+
+// Global MongoDB client instance
+var MongoDB *MongoClient
+
+// InitMongoDB initializes the MongoDB connection
+// Set MONGODB_URI environment variable to enable
+func InitMongoDB() error {
+	return ConnectMongoDB(os.Getenv("MONGODB_URI"))
+}
+
+// ConnectMongoDB initializes the MongoDB connection to uri, local JSON files
+// only when it's empty
+func ConnectMongoDB(uri string) error {
+	if uri == "" {
+		log.Println("⚠️  MONGODB_URI not set - MongoDB sync disabled")
+		log.Println("   Data will only be saved to local JSON files")
+		MongoDB = &MongoClient{enabled: false}
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Connect to MongoDB
+	clientOpts := options.Client().ApplyURI(uri)
+	client, err := mongo.Connect(ctx, clientOpts)
+	if err != nil {
+		return fmt.Errorf("failed to connect to MongoDB: %w", err)
+	}
+
+	// Ping to verify connection
+	if err := client.Ping(ctx, nil); err != nil {
+		client.Disconnect(ctx)
+		return fmt.Errorf("failed to ping MongoDB: %w", err)
+	}
+
+	database := client.Database(DB_NAME)
+
+	// Create indexes for better query performance
+	createIndexes(ctx, database)
+	applySchemaValidation(ctx, database)
+
+	MongoDB = &MongoClient{
+		client:   client,
+		database: database,
+		enabled:  true,
+	}
+
+	log.Println("✅ MongoDB connected successfully")
+	log.Printf("   Database: %s", DB_NAME)
+	return nil
+}
+
+// createIndexes creates indexes for collections
+func createIndexes(ctx context.Context, db *mongo.Database) {
+	// Seller profiles - index on gluser_id
+	db.Collection(COLLECTION_PROFILES).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "gluser_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "last_call_at", Value: -1}}}, // Recently active sellers, warmed at startup
+	})
+
+	// Seller summaries - the GET /sellers orders, and the attention filter
+	db.Collection(COLLECTION_SELLER_SUMMARIES).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "gluser_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "last_call_at", Value: -1}, {Key: "gluser_id", Value: 1}}},
+		{Keys: bson.D{{Key: "health_score", Value: 1}, {Key: "gluser_id", Value: 1}}},
+		{Keys: bson.D{{Key: "open_issues", Value: -1}, {Key: "gluser_id", Value: 1}}},
+		{Keys: bson.D{{Key: "needs_attention", Value: 1}, {Key: "health_score", Value: 1}}},
+	})
+
+	// Call analyses - index on call_id and seller_id, plus the GET /calls filters
+	db.Collection(COLLECTION_ANALYSES).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "call_id", Value: 1}}},
+		{Keys: bson.D{{Key: "seller_id", Value: 1}}},
+		{Keys: bson.D{{Key: "timestamp", Value: -1}}},
+		{Keys: bson.D{{Key: "analyzed_at", Value: -1}}},
+		{Keys: bson.D{{Key: "seller_id", Value: 1}, {Key: "timestamp", Value: -1}}},
+		{Keys: bson.D{{Key: "intent.sentiment", Value: 1}, {Key: "timestamp", Value: -1}}},
+		{Keys: bson.D{{Key: "issues.bucket", Value: 1}, {Key: "timestamp", Value: -1}}},
+		{Keys: bson.D{{Key: "origin.system", Value: 1}, {Key: "origin.region", Value: 1}, {Key: "timestamp", Value: -1}}},
+	})
+
+	// Tickets - index on date and status
+	db.Collection(COLLECTION_TICKETS).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "ticket_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "date", Value: 1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}}},
+		{Keys: bson.D{{Key: "feature_bucket", Value: 1}}},
+	})
+
+	// Events - time-ordered by event_id, filtered by type/seller/call
+	db.Collection(COLLECTION_EVENTS).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "event_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "type", Value: 1}, {Key: "event_id", Value: 1}}},
+		{Keys: bson.D{{Key: "gluser_id", Value: 1}, {Key: "event_id", Value: 1}}},
+	})
+
+	// Tracked issues - cross-seller queries by bucket/status/severity
+	db.Collection(COLLECTION_ISSUES).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "issue_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "gluser_id", Value: 1}}},
+		{Keys: bson.D{{Key: "bucket", Value: 1}, {Key: "status", Value: 1}, {Key: "issue_id", Value: -1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "severity", Value: 1}, {Key: "issue_id", Value: -1}}},
+	})
+
+	// Audit log - looked up by actor or resource over time
+	db.Collection(COLLECTION_AUDIT).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "audit_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "actor", Value: 1}, {Key: "audit_id", Value: 1}}},
+		{Keys: bson.D{{Key: "resource", Value: 1}, {Key: "audit_id", Value: 1}}},
+	})
+
+	// Recordings - one per call, scanned by call time for retention
+	db.Collection(COLLECTION_RECORDINGS).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "call_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "purged_at", Value: 1}, {Key: "timestamp", Value: 1}}},
+	})
+
+	// GitHub issue links - one per repo and bucket
+	db.Collection(COLLECTION_GITHUB).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "repo", Value: 1}, {Key: "feature_bucket", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+
+	// Attention acknowledgments - one per seller
+	db.Collection(COLLECTION_ATTENTION).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "gluser_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+
+	// Call extractions - one per call
+	db.Collection(COLLECTION_EXTRACTIONS).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "call_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+
+	// Systemic issues - replaced as a whole by each clustering run
+	db.Collection(COLLECTION_SYSTEMIC).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+
+	// Raw LLM responses - one per call, expiring after LLM_RAW_RETENTION_DAYS
+	db.Collection(COLLECTION_LLM_RAW).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "call_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err := ensureLLMRawTTL(ctx, db); err != nil {
+		log.Printf("⚠️  Failed to set up %s TTL index: %v", COLLECTION_LLM_RAW, err)
+	}
+
+	// Recorded LLM interactions - one per call, prompt version and pass, expiring like raw responses
+	db.Collection(COLLECTION_LLM_INTERACTIONS).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "call_id", Value: 1}, {Key: "prompt_version", Value: 1}, {Key: "pass", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err := ensureLLMInteractionsTTL(ctx, db); err != nil {
+		log.Printf("⚠️  Failed to set up %s TTL index: %v", COLLECTION_LLM_INTERACTIONS, err)
+	}
+
+	// Ticket suppressions - few, read whole at each aggregation
+	db.Collection(COLLECTION_SUPPRESSIONS).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+
+	// Webhook subscriptions - few, read whole when a call changes a seller's state
+	db.Collection(COLLECTION_WEBHOOKS).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+
+	// Alert subscriptions - few, read whole whenever an alert fires
+	db.Collection(COLLECTION_ALERT_SUBS).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+
+	// PII vault - one entry per token, looked up by token when rehydrating
+	db.Collection(COLLECTION_VAULT).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "token", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+
+	// Bucket owners - one per feature bucket, read whole at each aggregation
+	db.Collection(COLLECTION_OWNERS).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "bucket", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+
+	// Feature flags - a handful, read whole on each refresh
+	db.Collection(COLLECTION_FLAGS).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "name", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+
+	// Custom field definitions - a few per entity, read whole
+	db.Collection(COLLECTION_CUSTOM_FIELDS).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "entity", Value: 1}, {Key: "name", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+
+	// Dashboard snapshots - one per date, written once
+	db.Collection(COLLECTION_SNAPSHOTS).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "date", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+
+	// Call samples - one per source call, listed by review state and filters
+	db.Collection(COLLECTION_SAMPLES).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "call_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "feature_bucket", Value: 1}, {Key: "sentiment", Value: 1}}},
+		{Keys: bson.D{{Key: "gluser_id", Value: 1}}},
+	})
+
+	// Reclassification runs, newest first, and the records each changed
+	db.Collection(COLLECTION_RECLASSIFY).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "started_at", Value: -1}}},
+	})
+	db.Collection(COLLECTION_RECLASSIFY_LOG).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "run_id", Value: 1}, {Key: "kind", Value: 1}},
+	})
+
+	// Superseded analyses - listed per call, oldest version first
+	db.Collection(COLLECTION_VERSIONS).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "call_id", Value: 1}, {Key: "version", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+
+	// Shadow analyses - one per call, read by call date for the comparison report
+	db.Collection(COLLECTION_SHADOW).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "call_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	db.Collection(COLLECTION_SHADOW).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "timestamp", Value: 1}},
+	})
+
+	// Rollouts - few, read whole, the active one polled by every instance
+	db.Collection(COLLECTION_ROLLOUTS).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+
+	// Key insight themes - one report per week end date
+	db.Collection(COLLECTION_THEMES).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "to", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+
+	// Severity calibration runs - one per run date
+	db.Collection(COLLECTION_CALIBRATION).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "date", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+
+	// Knowledge base documents - few, read whole at startup and each refresh
+	db.Collection(COLLECTION_KB).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+
+	// Commitments - listed by seller, agent or status, swept for open ones past due
+	db.Collection(COLLECTION_COMMITMENTS).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "gluser_id", Value: 1}, {Key: "status", Value: 1}}},
+		{Keys: bson.D{{Key: "agent_id", Value: 1}, {Key: "status", Value: 1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "due_date", Value: 1}}},
+	})
+
+	// Upsell pitches - listed by seller, agent or status, swept for open ones past the conversion window
+	db.Collection(COLLECTION_PITCHES).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "gluser_id", Value: 1}, {Key: "status", Value: 1}}},
+		{Keys: bson.D{{Key: "agent_id", Value: 1}, {Key: "status", Value: 1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "call_time", Value: 1}}},
+	})
+
+	// Seller quotes - replaced per call, read by bucket and date or deleted per seller
+	db.Collection(COLLECTION_QUOTES).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "call_id", Value: 1}}},
+		{Keys: bson.D{{Key: "feature_bucket", Value: 1}, {Key: "date", Value: -1}}},
+		{Keys: bson.D{{Key: "gluser_id", Value: 1}}},
+	})
+	db.Collection(COLLECTION_QUOTE_OPT_OUTS).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "gluser_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+
+	// Annotations - read per call, rolled up by agent or seller
+	db.Collection(COLLECTION_ANNOTATIONS).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "call_id", Value: 1}}},
+		{Keys: bson.D{{Key: "agent_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "gluser_id", Value: 1}, {Key: "created_at", Value: -1}}},
+	})
+
+	// Seller metrics - time-series, read per seller over a time range
+	if err := ensureSellerMetricsCollection(ctx, db); err != nil {
+		log.Printf("⚠️  Failed to set up %s time-series collection: %v", COLLECTION_SELLER_METRICS, err)
+	}
+	db.Collection(COLLECTION_SELLER_METRICS).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "gluser_id", Value: 1}, {Key: "timestamp", Value: 1}},
+	})
+
+	// Idempotency keys - dropped by MongoDB once expired
+	db.Collection(COLLECTION_IDEMPOTENCY).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+
+	// Ingest jobs - read by ID, dropped by MongoDB once expired
+	db.Collection(COLLECTION_INGEST_JOBS).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "job_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+	})
+
+	// Call chats - read by session ID, listed per seller for data inventories, dropped by MongoDB once expired
+	db.Collection(COLLECTION_CALL_CHATS).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "session_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "gluser_id", Value: 1}}},
+		{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+	})
+
+	// Churn outcomes - one per seller and date, read by date range or seller
+	db.Collection(COLLECTION_CHURN_OUTCOMES).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "gluser_id", Value: 1}, {Key: "date", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "date", Value: 1}}},
+	})
+
+	// Jobs - read by ID, listed newest first by type and status, dropped by MongoDB once expired
+	db.Collection(COLLECTION_JOBS).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "type", Value: 1}, {Key: "status", Value: 1}, {Key: "id", Value: -1}}},
+		{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+	})
+
+	// Provisional calls - one per call, upgraded oldest first
+	db.Collection(COLLECTION_PROVISIONAL).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "call_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "queued_at", Value: 1}, {Key: "call_id", Value: 1}}},
+	})
+
+	// Eval runs - one per run, read newest first by creation time
+	db.Collection(COLLECTION_EVAL_RUNS).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "created_at", Value: -1}}},
+	})
+
+	// Trash - one item per kind and ID
+	db.Collection(COLLECTION_TRASH).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "kind", Value: 1}, {Key: "id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+
+	// Aggregates - index on date
+	db.Collection(COLLECTION_AGGREGATES).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "date", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+
+	// Shift aggregates - one per date and shift
+	db.Collection(COLLECTION_SHIFT_AGGS).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "date", Value: 1}, {Key: "shift", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+}
+
+// Close closes the MongoDB connection
+func (m *MongoClient) Close() error {
+	if m.client != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return m.client.Disconnect(ctx)
+	}
+	return nil
+}
+
+// ==================== SYNC FUNCTIONS ====================
+// These functions push data to MongoDB (called alongside local file saves).
+// The write runs in the background, so it keeps the caller's context values
+// but not its cancellation.
+
+// SyncSellerProfile pushes seller profile (and its tracked issues) to MongoDB
+func SyncSellerProfile(ctx context.Context, profile *client.SellerProfile) {
+	if MongoDB == nil || !MongoDB.enabled {
+		return
+	}
+
+	go func() {
+		if err := SaveSellerProfileToMongo(context.WithoutCancel(ctx), profile); err != nil {
+			log.Printf("⚠️  MongoDB sync failed for profile %s: %v", profile.GluserID, err)
+		}
+	}()
+}
+
+// SyncAnalysis pushes call analysis to MongoDB
+func SyncAnalysis(ctx context.Context, analysis *client.AnalysisResult) {
+	if MongoDB == nil || !MongoDB.enabled {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), opTimeout)
+		defer cancel()
+
+		collection := MongoDB.database.Collection(COLLECTION_ANALYSES)
+
+		// Convert to bson.M using JSON tags
+		doc, err := ToBsonM(analysis)
+		if err != nil {
+			log.Printf("⚠️  MongoDB marshal failed for analysis %s: %v", analysis.CallID, err)
+			return
+		}
+
+		// Upsert by call_id
+		filter := bson.M{"call_id": analysis.CallID}
+		opts := options.Replace().SetUpsert(true)
+
+		_, err = collection.ReplaceOne(ctx, filter, doc, opts)
+		if err != nil {
+			log.Printf("⚠️  MongoDB sync failed for analysis %s: %v", analysis.CallID, err)
+		}
+	}()
+}
+
+// SyncTicket pushes a ticket to MongoDB
+func SyncTicket(ctx context.Context, ticket *client.Ticket) {
+	if MongoDB == nil || !MongoDB.enabled {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), opTimeout)
+		defer cancel()
+
+		collection := MongoDB.database.Collection(COLLECTION_TICKETS)
+
+		// Convert to bson.M using JSON tags
+		doc, err := ToBsonM(ticket)
+		if err != nil {
+			log.Printf("⚠️  MongoDB marshal failed for ticket %s: %v", ticket.TicketID, err)
+			return
+		}
+
+		// Upsert by ticket_id
+		filter := bson.M{"ticket_id": ticket.TicketID}
+		opts := options.Replace().SetUpsert(true)
+
+		_, err = collection.ReplaceOne(ctx, filter, doc, opts)
+		dashboards.invalidate(ticket.Date)
+		if err != nil {
+			log.Printf("⚠️  MongoDB sync failed for ticket %s: %v", ticket.TicketID, err)
+		} else {
+			log.Printf("   📤 Synced ticket to MongoDB: %s", ticket.TicketID)
+		}
+	}()
+}
+
+// SyncAggregate pushes daily aggregate to MongoDB
+func SyncAggregate(ctx context.Context, aggregate *client.DailyAggregate) {
+	if MongoDB == nil || !MongoDB.enabled {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), opTimeout)
+		defer cancel()
+
+		collection := MongoDB.database.Collection(COLLECTION_AGGREGATES)
+
+		// Convert to bson.M using JSON tags
+		doc, err := ToBsonM(aggregate)
+		if err != nil {
+			log.Printf("⚠️  MongoDB marshal failed for aggregate %s: %v", aggregate.Date, err)
+			return
+		}
+
+		// Upsert by date
+		filter := bson.M{"date": aggregate.Date}
+		opts := options.Replace().SetUpsert(true)
+
+		_, err = collection.ReplaceOne(ctx, filter, doc, opts)
+		dashboards.invalidate(aggregate.Date)
+		if err != nil {
+			log.Printf("⚠️  MongoDB sync failed for aggregate %s: %v", aggregate.Date, err)
+		} else {
+			log.Printf("   📤 Synced aggregate to MongoDB: %s", aggregate.Date)
+		}
+	}()
+}
+
+// SyncAlert pushes an alert to MongoDB
+func SyncAlert(ctx context.Context, alert *client.Alert) {
+	if MongoDB == nil || !MongoDB.enabled {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), opTimeout)
+		defer cancel()
+
+		collection := MongoDB.database.Collection(COLLECTION_ALERTS)
+
+		// Convert to bson.M using JSON tags
+		doc, err := ToBsonM(alert)
+		if err != nil {
+			log.Printf("⚠️  MongoDB marshal failed for alert %s: %v", alert.AlertID, err)
+			return
+		}
+
+		// Upsert by alert_id
+		filter := bson.M{"alert_id": alert.AlertID}
+		opts := options.Replace().SetUpsert(true)
+
+		_, err = collection.ReplaceOne(ctx, filter, doc, opts)
+		if err != nil {
+			log.Printf("⚠️  MongoDB sync failed for alert %s: %v", alert.AlertID, err)
+		}
+	}()
+}
+
+// ==================== READ FUNCTIONS (MongoDB-first) ====================
+
+// GetSellerProfileFromMongo loads a seller profile from MongoDB
+func GetSellerProfileFromMongo(ctx context.Context, gluserID string) (*client.SellerProfile, error) {
+	if MongoDB == nil || !MongoDB.enabled {
+		return nil, fmt.Errorf("MongoDB not enabled")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	collection := MongoDB.database.Collection(COLLECTION_PROFILES)
+	filter := bson.M{"gluser_id": gluserID}
+
+	var doc bson.M
+	err := collection.FindOne(ctx, filter).Decode(&doc)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil // Not found
+		}
+		return nil, err
+	}
+
+	profile, err := decodeProfileDoc(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	active, resolved, err := getSellerIssuesFromMongo(ctx, gluserID)
+	if err != nil {
+		return nil, err
+	}
+	attachSellerIssues(profile, active, resolved)
+	return profile, nil
+}
+
+// decodeProfileDoc converts a seller_profiles document to a SellerProfile via JSON
+func decodeProfileDoc(doc bson.M) (*client.SellerProfile, error) {
+	jsonBytes, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var profile client.SellerProfile
+	if err := json.Unmarshal(jsonBytes, &profile); err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// attachSellerIssues sets a profile's issues from the issues collection.
+// Issues live in their own collection; profiles saved before the move
+// still embed them until they're next saved.
+func attachSellerIssues(profile *client.SellerProfile, active, resolved []client.TrackedIssue) {
+	if len(active)+len(resolved) > 0 {
+		profile.ActiveIssues = active
+		profile.ResolvedIssues = resolved
+	}
+	if profile.ActiveIssues == nil {
+		profile.ActiveIssues = []client.TrackedIssue{}
+	}
+	if profile.ResolvedIssues == nil {
+		profile.ResolvedIssues = []client.TrackedIssue{}
+	}
+}
+
+// GetAllAnalysesForDateFromMongo loads all analyses aggregated under a date from MongoDB
+func GetAllAnalysesForDateFromMongo(ctx context.Context, date string) ([]client.AnalysisResult, error) {
+	if MongoDB == nil || !MongoDB.enabled {
+		return nil, fmt.Errorf("MongoDB not enabled")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, scanTimeout)
+	defer cancel()
+
+	collection := MongoDB.database.Collection(COLLECTION_ANALYSES)
+
+	// Timestamps are stored as RFC3339 strings with whatever offset they were
+	// recorded in, so string comparison can't find the exact business-day
+	// boundaries. Fetch a day either side and keep the matches below.
+	day, err := config.ParseBusinessDate(date)
+	if err != nil {
+		return nil, err
+	}
+	filter := bson.M{
+		aggregateDateKey(): bson.M{
+			"$gte": day.AddDate(0, 0, -1).Format(config.DateLayout),
+			"$lt":  day.AddDate(0, 0, 2).Format(config.DateLayout),
+		},
+	}
+
+	cursor, err := collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []client.AnalysisResult
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			continue
+		}
+
+		// Convert to AnalysisResult via JSON
+		jsonBytes, err := json.Marshal(doc)
+		if err != nil {
+			continue
+		}
+
+		var ar client.AnalysisResult
+		if err := json.Unmarshal(jsonBytes, &ar); err != nil {
+			continue
+		}
+		if AggregationDate(&ar) == date {
+			results = append(results, ar)
+		}
+	}
+
+	return results, nil
+}
+
+// GetAllAnalysesFromMongo loads all analyses from MongoDB (for aggregation)
+func GetAllAnalysesFromMongo(ctx context.Context) ([]client.AnalysisResult, error) {
+	if MongoDB == nil || !MongoDB.enabled {
+		return nil, fmt.Errorf("MongoDB not enabled")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, scanTimeout)
+	defer cancel()
+
+	collection := MongoDB.database.Collection(COLLECTION_ANALYSES)
+
+	cursor, err := collection.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []client.AnalysisResult
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			continue
+		}
+
+		jsonBytes, err := json.Marshal(doc)
+		if err != nil {
+			continue
+		}
+
+		var ar client.AnalysisResult
+		if err := json.Unmarshal(jsonBytes, &ar); err != nil {
+			continue
+		}
+		results = append(results, ar)
+	}
+
+	return results, nil
+}
+
+// AnalyzedCall identifies the transcript a stored analysis is of
+type AnalyzedCall struct {
+	CallID   string `bson:"call_id"`
+	SellerID string `bson:"seller_id"`
+	Checksum string `bson:"transcript_checksum"`
+}
+
+// GetAnalyzedCallsFromMongo lists the call, seller and transcript checksum
+// of every stored analysis, reading only those fields
+func GetAnalyzedCallsFromMongo(ctx context.Context) ([]AnalyzedCall, error) {
+	if MongoDB == nil || !MongoDB.enabled {
+		return nil, fmt.Errorf("MongoDB not enabled")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, scanTimeout)
+	defer cancel()
+
+	opts := options.Find().SetProjection(bson.M{"_id": 0, "call_id": 1, "seller_id": 1, "transcript_checksum": 1})
+	cursor, err := MongoDB.database.Collection(COLLECTION_ANALYSES).Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var calls []AnalyzedCall
+	for cursor.Next(ctx) {
+		var c AnalyzedCall
+		if err := cursor.Decode(&c); err != nil {
+			continue
+		}
+		calls = append(calls, c)
+	}
+	return calls, cursor.Err()
+}
+
+// GetSellerAnalysesFromMongo loads all analyses of a seller
+func GetSellerAnalysesFromMongo(ctx context.Context, gluserID string) ([]client.AnalysisResult, error) {
+	if MongoDB == nil || !MongoDB.enabled {
+		return nil, fmt.Errorf("MongoDB not enabled")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	cursor, err := MongoDB.database.Collection(COLLECTION_ANALYSES).Find(ctx, bson.M{"seller_id": gluserID})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []client.AnalysisResult
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			continue
+		}
+
+		jsonBytes, err := json.Marshal(doc)
+		if err != nil {
+			continue
+		}
+
+		var ar client.AnalysisResult
+		if err := json.Unmarshal(jsonBytes, &ar); err != nil {
+			continue
+		}
+		results = append(results, ar)
+	}
+
+	return results, cursor.Err()
+}
+
+// GetAnalysesBeforeFromMongo loads all analyses with a timestamp before cutoff
+func GetAnalysesBeforeFromMongo(ctx context.Context, cutoff time.Time) ([]client.AnalysisResult, error) {
+	if MongoDB == nil || !MongoDB.enabled {
+		return nil, fmt.Errorf("MongoDB not enabled")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, scanTimeout)
+	defer cancel()
+
+	collection := MongoDB.database.Collection(COLLECTION_ANALYSES)
+	filter := bson.M{"timestamp": bson.M{"$lt": cutoff.Format(time.RFC3339)}}
+
+	cursor, err := collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []client.AnalysisResult
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			continue
+		}
+
+		jsonBytes, err := json.Marshal(doc)
+		if err != nil {
+			continue
+		}
+
+		var ar client.AnalysisResult
+		if err := json.Unmarshal(jsonBytes, &ar); err != nil {
+			continue
+		}
+		results = append(results, ar)
+	}
+
+	return results, nil
+}
+
+// DeleteAnalysesFromMongo removes analyses by call_id (used after archiving)
+func DeleteAnalysesFromMongo(ctx context.Context, callIDs []string) (int64, error) {
+	if MongoDB == nil || !MongoDB.enabled {
+		return 0, fmt.Errorf("MongoDB not enabled")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, scanTimeout)
+	defer cancel()
+
+	collection := MongoDB.database.Collection(COLLECTION_ANALYSES)
+	result, err := collection.DeleteMany(ctx, bson.M{"call_id": bson.M{"$in": callIDs}})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+// CountAnalysesFromMongo returns count of all analyses in MongoDB
+func CountAnalysesFromMongo(ctx context.Context) (int64, error) {
+	if MongoDB == nil || !MongoDB.enabled {
+		return 0, fmt.Errorf("MongoDB not enabled")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	collection := MongoDB.database.Collection(COLLECTION_ANALYSES)
+	return collection.CountDocuments(ctx, bson.M{})
+}
+
+// GetAnalysisFromMongo loads a single analysis by call_id
+func GetAnalysisFromMongo(ctx context.Context, callID string) (*client.AnalysisResult, error) {
+	if MongoDB == nil || !MongoDB.enabled {
+		return nil, fmt.Errorf("MongoDB not enabled")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	collection := MongoDB.database.Collection(COLLECTION_ANALYSES)
+	filter := bson.M{"call_id": callID}
+
+	var doc bson.M
+	err := collection.FindOne(ctx, filter).Decode(&doc)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	jsonBytes, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var ar client.AnalysisResult
+	if err := json.Unmarshal(jsonBytes, &ar); err != nil {
+		return nil, err
+	}
+
+	return &ar, nil
+}
+
+// AnalysisExistsInMongo checks if an analysis exists in MongoDB
+func AnalysisExistsInMongo(ctx context.Context, callID string) bool {
+	if MongoDB == nil || !MongoDB.enabled {
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	collection := MongoDB.database.Collection(COLLECTION_ANALYSES)
+	count, err := collection.CountDocuments(ctx, bson.M{"call_id": callID})
+	return err == nil && count > 0
+}
+
+// GetAggregateFromMongo loads a daily aggregate from MongoDB
+func GetAggregateFromMongo(ctx context.Context, date string) (*client.DailyAggregate, error) {
+	if MongoDB == nil || !MongoDB.enabled {
+		return nil, fmt.Errorf("MongoDB not enabled")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	collection := MongoDB.database.Collection(COLLECTION_AGGREGATES)
+	filter := bson.M{"date": date}
+
+	var doc bson.M
+	err := collection.FindOne(ctx, filter).Decode(&doc)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	jsonBytes, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var agg client.DailyAggregate
+	if err := json.Unmarshal(jsonBytes, &agg); err != nil {
+		return nil, err
+	}
+
+	return &agg, nil
+}
+
+// GetTicketsForDateFromMongo loads all tickets for a date from MongoDB
+func GetTicketsForDateFromMongo(ctx context.Context, date string) ([]client.Ticket, error) {
+	return findTicketsInMongo(ctx, bson.M{"date": date})
+}
+
+// GetTicketsThroughFromMongo loads the tickets of every date up to and
+// including date from MongoDB
+func GetTicketsThroughFromMongo(ctx context.Context, date string) ([]client.Ticket, error) {
+	return findTicketsInMongo(ctx, bson.M{"date": bson.M{"$lte": date}})
+}
+
+func findTicketsInMongo(ctx context.Context, filter bson.M) ([]client.Ticket, error) {
+	if MongoDB == nil || !MongoDB.enabled {
+		return nil, fmt.Errorf("MongoDB not enabled")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	collection := MongoDB.database.Collection(COLLECTION_TICKETS)
+	cursor, err := collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var tickets []client.Ticket
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			continue
+		}
+
+		jsonBytes, err := json.Marshal(doc)
+		if err != nil {
+			continue
+		}
+
+		var ticket client.Ticket
+		if err := json.Unmarshal(jsonBytes, &ticket); err != nil {
+			continue
+		}
+		tickets = append(tickets, ticket)
+	}
+
+	return tickets, nil
+}
+
+// ListAllSellerIDsFromMongo returns all seller IDs from MongoDB
+func ListAllSellerIDsFromMongo(ctx context.Context) ([]string, error) {
+	if MongoDB == nil || !MongoDB.enabled {
+		return nil, fmt.Errorf("MongoDB not enabled")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	collection := MongoDB.database.Collection(COLLECTION_PROFILES)
+
+	// Use distinct to get unique gluser_ids
+	ids, err := collection.Distinct(ctx, "gluser_id", bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	var result []string
+	for _, id := range ids {
+		if s, ok := id.(string); ok {
+			result = append(result, s)
+		}
+	}
+
+	return result, nil
+}
+
+// ListAggregateDatesFromMongo returns all aggregate dates from MongoDB
+func ListAggregateDatesFromMongo(ctx context.Context) ([]string, error) {
+	if MongoDB == nil || !MongoDB.enabled {
+		return nil, fmt.Errorf("MongoDB not enabled")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	collection := MongoDB.database.Collection(COLLECTION_AGGREGATES)
+
+	dates, err := collection.Distinct(ctx, "date", bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	var result []string
+	for _, d := range dates {
+		if s, ok := d.(string); ok {
+			result = append(result, s)
+		}
+	}
+
+	// Sort descending
+	sort.Sort(sort.Reverse(sort.StringSlice(result)))
+	return result, nil
+}
+
+// ListTicketDatesFromMongo returns all unique ticket dates from MongoDB
+func ListTicketDatesFromMongo(ctx context.Context) ([]string, error) {
+	if MongoDB == nil || !MongoDB.enabled {
+		return nil, fmt.Errorf("MongoDB not enabled")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	collection := MongoDB.database.Collection(COLLECTION_TICKETS)
+
+	dates, err := collection.Distinct(ctx, "date", bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	var result []string
+	for _, d := range dates {
+		if s, ok := d.(string); ok {
+			result = append(result, s)
+		}
+	}
+
+	sort.Sort(sort.Reverse(sort.StringSlice(result)))
+	return result, nil
+}
+
+// IsUnavailable reports whether err is MongoDB being unreachable or too slow
+// to answer, rather than a problem with the request or the data
+func IsUnavailable(err error) bool {
+	var injected *chaos.Error
+	if errors.As(err, &injected) {
+		return injected.Fault == chaos.MongoWrite
+	}
+	return mongo.IsNetworkError(err) || mongo.IsTimeout(err)
+}
+
+// IsMongoEnabled returns true if MongoDB is connected and enabled
+func IsMongoEnabled() bool {
+	return MongoDB != nil && MongoDB.enabled
+}
+
+// ==================== DIRECT WRITES (synchronous) ====================
+
+// SaveAggregateToMongo saves aggregate directly to MongoDB (synchronous)
+func SaveAggregateToMongo(ctx context.Context, agg *client.DailyAggregate) error {
+	if MongoDB == nil || !MongoDB.enabled {
+		return fmt.Errorf("MongoDB not enabled")
+	}
+
+	if err := chaos.MongoWriteError("save aggregate " + agg.Date); err != nil {
+		return err
+	}
+	defer dashboards.invalidate(agg.Date)
+
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	collection := MongoDB.database.Collection(COLLECTION_AGGREGATES)
+
+	doc, err := ToBsonM(agg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal aggregate: %w", err)
+	}
+
+	filter := bson.M{"date": agg.Date}
+	opts := options.Replace().SetUpsert(true)
+
+	_, err = collection.ReplaceOne(ctx, filter, doc, opts)
+	if err != nil {
+		return fmt.Errorf("failed to save aggregate to MongoDB: %w", err)
+	}
+
+	log.Printf("   📤 Saved aggregate to MongoDB: %s", agg.Date)
+	return nil
+}
+
+// SaveTicketToMongo saves ticket directly to MongoDB (synchronous)
+func SaveTicketToMongo(ctx context.Context, ticket *client.Ticket) error {
+	if MongoDB == nil || !MongoDB.enabled {
+		return fmt.Errorf("MongoDB not enabled")
+	}
+
+	if err := chaos.MongoWriteError("save ticket " + ticket.TicketID); err != nil {
+		return err
+	}
+	defer dashboards.invalidate(ticket.Date)
+
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	collection := MongoDB.database.Collection(COLLECTION_TICKETS)
+
+	doc, err := ToBsonM(ticket)
+	if err != nil {
+		return fmt.Errorf("failed to marshal ticket: %w", err)
+	}
+
+	filter := bson.M{"ticket_id": ticket.TicketID}
+	opts := options.Replace().SetUpsert(true)
+
+	_, err = collection.ReplaceOne(ctx, filter, doc, opts)
+	if err != nil {
+		return fmt.Errorf("failed to save ticket to MongoDB: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,139 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ==================== BUCKET OWNERS ====================
+// The directory of teams owning each feature bucket, read at each
+// aggregation to route tickets. With MongoDB it's in bucket_owners,
+// otherwise one JSON file per bucket under OWNERS_DIR. Like suppressions,
+// it's configuration, so WipeDerivedData leaves it alone.
+
+// SaveBucketOwner stores a bucket's owner, replacing the previous one - MongoDB first, local fallback
+func SaveBucketOwner(ctx context.Context, o *client.BucketOwner) error {
+	if IsMongoEnabled() {
+		return saveBucketOwnerToMongo(ctx, o)
+	}
+	b, err := json.MarshalIndent(o, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal bucket owner: %w", err)
+	}
+	return writeFile(bucketOwnerPath(o.Bucket), b, 0644)
+}
+
+// LoadBucketOwners returns every bucket's owner, by bucket - MongoDB first, local fallback
+func LoadBucketOwners(ctx context.Context) ([]client.BucketOwner, error) {
+	var owners []client.BucketOwner
+	if IsMongoEnabled() {
+		var err error
+		if owners, err = getBucketOwnersFromMongo(ctx); err != nil {
+			return nil, err
+		}
+	} else {
+		entries, err := os.ReadDir(config.OWNERS_DIR)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		owners = make([]client.BucketOwner, 0, len(entries))
+		for _, e := range entries {
+			if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+				continue
+			}
+			b, err := os.ReadFile(filepath.Join(config.OWNERS_DIR, e.Name()))
+			if err != nil {
+				return nil, err
+			}
+			var o client.BucketOwner
+			if err := json.Unmarshal(b, &o); err != nil {
+				continue // Skip corrupt files
+			}
+			owners = append(owners, o)
+		}
+	}
+	sort.Slice(owners, func(i, j int) bool { return owners[i].Bucket < owners[j].Bucket })
+	return owners, nil
+}
+
+// DeleteBucketOwner removes a bucket's owner, reporting whether it had one - MongoDB first, local fallback
+func DeleteBucketOwner(ctx context.Context, bucket string) (bool, error) {
+	if IsMongoEnabled() {
+		ctx, cancel := context.WithTimeout(ctx, opTimeout)
+		defer cancel()
+		res, err := MongoDB.database.Collection(COLLECTION_OWNERS).DeleteOne(ctx, bson.M{"bucket": bucket})
+		if err != nil {
+			return false, err
+		}
+		return res.DeletedCount > 0, nil
+	}
+	if err := os.Remove(bucketOwnerPath(bucket)); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func bucketOwnerPath(bucket string) string {
+	return filepath.Join(config.OWNERS_DIR, fmt.Sprintf("owner_%s.json", Sanitize(bucket)))
+}
+
+// ==================== BUCKET OWNERS (MongoDB) ====================
+
+func saveBucketOwnerToMongo(ctx context.Context, o *client.BucketOwner) error {
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	doc, err := ToBsonM(o)
+	if err != nil {
+		return fmt.Errorf("failed to marshal bucket owner: %w", err)
+	}
+
+	opts := options.Replace().SetUpsert(true)
+	if _, err := MongoDB.database.Collection(COLLECTION_OWNERS).ReplaceOne(ctx, bson.M{"bucket": o.Bucket}, doc, opts); err != nil {
+		return fmt.Errorf("failed to save bucket owner to MongoDB: %w", err)
+	}
+	return nil
+}
+
+func getBucketOwnersFromMongo(ctx context.Context) ([]client.BucketOwner, error) {
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	cursor, err := MongoDB.database.Collection(COLLECTION_OWNERS).Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	owners := []client.BucketOwner{}
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			continue
+		}
+		jsonBytes, err := json.Marshal(doc)
+		if err != nil {
+			continue
+		}
+		var o client.BucketOwner
+		if err := json.Unmarshal(jsonBytes, &o); err != nil {
+			continue
+		}
+		owners = append(owners, o)
+	}
+	return owners, cursor.Err()
+}
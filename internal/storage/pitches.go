@@ -0,0 +1,194 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ==================== UPSELL PITCHES ====================
+// Upsell pitches agents made sellers. With MongoDB they're in
+// upsell_pitches, otherwise one JSON file per pitch under PITCHES_DIR.
+// Whether a pitch converted is found by later calls and isn't in any
+// analysis, so WipeDerivedData leaves them alone; a replay updates them in
+// place.
+
+// PitchQuery filters upsell pitches; empty fields match everything
+type PitchQuery struct {
+	GluserID string
+	AgentID  string
+	Status   string
+}
+
+func (q PitchQuery) matches(c client.TrackedPitch) bool {
+	return (q.GluserID == "" || c.GluserID == q.GluserID) &&
+		(q.AgentID == "" || c.AgentID == q.AgentID) &&
+		(q.Status == "" || c.Status == q.Status)
+}
+
+// SavePitch stores an upsell pitch, replacing one with the same ID - MongoDB first, local fallback
+func SavePitch(ctx context.Context, c *client.TrackedPitch) error {
+	if IsMongoEnabled() {
+		return savePitchToMongo(ctx, c)
+	}
+	b, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal pitch: %w", err)
+	}
+	return writeFile(pitchPath(c.ID), b, 0644)
+}
+
+// LoadPitch returns an upsell pitch, nil if there's none with the ID - MongoDB first, local fallback
+func LoadPitch(ctx context.Context, id string) (*client.TrackedPitch, error) {
+	if IsMongoEnabled() {
+		return getPitchFromMongo(ctx, id)
+	}
+	b, err := os.ReadFile(pitchPath(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var c client.TrackedPitch
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// LoadPitches returns the upsell pitches matching q, newest call first - MongoDB first, local fallback
+func LoadPitches(ctx context.Context, q PitchQuery) ([]client.TrackedPitch, error) {
+	var list []client.TrackedPitch
+	if IsMongoEnabled() {
+		var err error
+		if list, err = getPitchesFromMongo(ctx, q); err != nil {
+			return nil, err
+		}
+	} else {
+		entries, err := os.ReadDir(config.PITCHES_DIR)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		list = make([]client.TrackedPitch, 0, len(entries))
+		for _, e := range entries {
+			if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+				continue
+			}
+			b, err := os.ReadFile(filepath.Join(config.PITCHES_DIR, e.Name()))
+			if err != nil {
+				return nil, err
+			}
+			var c client.TrackedPitch
+			if err := json.Unmarshal(b, &c); err != nil {
+				continue // Skip corrupt files
+			}
+			if q.matches(c) {
+				list = append(list, c)
+			}
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].CallTime.Equal(list[j].CallTime) {
+			return list[i].CallTime.After(list[j].CallTime)
+		}
+		return list[i].ID < list[j].ID
+	})
+	return list, nil
+}
+
+func pitchPath(id string) string {
+	return filepath.Join(config.PITCHES_DIR, fmt.Sprintf("pitch_%s.json", Sanitize(id)))
+}
+
+// ==================== UPSELL PITCHES (MongoDB) ====================
+
+func savePitchToMongo(ctx context.Context, c *client.TrackedPitch) error {
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	doc, err := ToBsonM(c)
+	if err != nil {
+		return fmt.Errorf("failed to marshal pitch: %w", err)
+	}
+
+	opts := options.Replace().SetUpsert(true)
+	if _, err := MongoDB.database.Collection(COLLECTION_PITCHES).ReplaceOne(ctx, bson.M{"id": c.ID}, doc, opts); err != nil {
+		return fmt.Errorf("failed to save pitch to MongoDB: %w", err)
+	}
+	return nil
+}
+
+func getPitchFromMongo(ctx context.Context, id string) (*client.TrackedPitch, error) {
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	var doc bson.M
+	if err := MongoDB.database.Collection(COLLECTION_PITCHES).FindOne(ctx, bson.M{"id": id}).Decode(&doc); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	jsonBytes, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	var c client.TrackedPitch
+	if err := json.Unmarshal(jsonBytes, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func getPitchesFromMongo(ctx context.Context, q PitchQuery) ([]client.TrackedPitch, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	filter := bson.M{}
+	if q.GluserID != "" {
+		filter["gluser_id"] = q.GluserID
+	}
+	if q.AgentID != "" {
+		filter["agent_id"] = q.AgentID
+	}
+	if q.Status != "" {
+		filter["status"] = q.Status
+	}
+
+	cursor, err := MongoDB.database.Collection(COLLECTION_PITCHES).Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	list := []client.TrackedPitch{}
+	for cursor.Next(ctx) {
+		var m bson.M
+		if err := cursor.Decode(&m); err != nil {
+			continue
+		}
+		jsonBytes, err := json.Marshal(m)
+		if err != nil {
+			continue
+		}
+		var c client.TrackedPitch
+		if err := json.Unmarshal(jsonBytes, &c); err != nil {
+			continue
+		}
+		list = append(list, c)
+	}
+	return list, cursor.Err()
+}
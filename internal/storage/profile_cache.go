@@ -0,0 +1,216 @@
+package storage
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ==================== SELLER PROFILE CACHE ====================
+// LRU cache of recently used seller profiles in front of LoadSellerProfile.
+// Saves write through (the saved profile replaces the cached one), and with
+// MongoDB the seller_profiles change stream evicts profiles written by other
+// instances. Entries also expire after PROFILE_CACHE_TTL, which bounds
+// staleness where change streams aren't available (standalone mongod).
+//
+// The cache holds its own copies: callers get a clone and may modify it.
+
+type profileCacheEntry struct {
+	gluserID string
+	profile  *client.SellerProfile
+	cachedAt time.Time
+}
+
+type profileCache struct {
+	mu       sync.Mutex
+	capacity int
+	ttl      time.Duration
+	order    *list.List // Front = most recently used
+	entries  map[string]*list.Element
+}
+
+var profiles = newProfileCache(profileCacheSize(), config.EnvDuration("PROFILE_CACHE_TTL", config.DEFAULT_PROFILE_CACHE_TTL))
+
+// profileCacheSize returns the configured cache capacity (0 disables the cache)
+func profileCacheSize() int {
+	if v := os.Getenv("PROFILE_CACHE_SIZE"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			return n
+		}
+	}
+	return config.DEFAULT_PROFILE_CACHE_SIZE
+}
+
+func newProfileCache(capacity int, ttl time.Duration) *profileCache {
+	return &profileCache{
+		capacity: capacity,
+		ttl:      ttl,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+// get returns a copy of the cached profile, if present and fresh
+func (c *profileCache) get(gluserID string) (*client.SellerProfile, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[gluserID]
+	if !ok {
+		return nil, false
+	}
+	entry := el.Value.(*profileCacheEntry)
+	if time.Since(entry.cachedAt) > c.ttl {
+		c.removeLocked(el)
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return entry.profile.Clone(), true
+}
+
+// put caches a copy of the profile, evicting the least recently used if full
+func (c *profileCache) put(profile *client.SellerProfile) {
+	if c.capacity == 0 || profile == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &profileCacheEntry{gluserID: profile.GluserID, profile: profile.Clone(), cachedAt: time.Now()}
+	if el, ok := c.entries[entry.gluserID]; ok {
+		el.Value = entry
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[entry.gluserID] = c.order.PushFront(entry)
+
+	for c.order.Len() > c.capacity {
+		c.removeLocked(c.order.Back())
+	}
+}
+
+// invalidate drops one profile
+func (c *profileCache) invalidate(gluserID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[gluserID]; ok {
+		c.removeLocked(el)
+	}
+}
+
+// purge drops every profile
+func (c *profileCache) purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.order.Init()
+	c.entries = make(map[string]*list.Element)
+}
+
+// updatedAt returns the UpdatedAt of the cached profile (zero if absent)
+func (c *profileCache) updatedAt(gluserID string) time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[gluserID]; ok {
+		return el.Value.(*profileCacheEntry).profile.UpdatedAt
+	}
+	return time.Time{}
+}
+
+func (c *profileCache) removeLocked(el *list.Element) {
+	c.order.Remove(el)
+	delete(c.entries, el.Value.(*profileCacheEntry).gluserID)
+}
+
+// ==================== CROSS-INSTANCE INVALIDATION ====================
+
+// StartProfileChangeStream evicts cached profiles when another instance
+// writes them, and adds their sellers to the known sellers. Requires MongoDB
+// running as a replica set; otherwise it logs once and the cache and known
+// sellers fall back to TTL expiry.
+func StartProfileChangeStream(ctx context.Context) {
+	if !IsMongoEnabled() || (profiles.capacity == 0 && knownSellers.ttl == 0) {
+		return
+	}
+
+	go func() {
+		for {
+			err := watchProfileChanges(ctx)
+			if ctx.Err() != nil {
+				return
+			}
+			if isChangeStreamUnsupported(err) {
+				log.Printf("⚠️ Profile change stream unavailable (%v) - cached profiles expire after %v", err, profiles.ttl)
+				return
+			}
+			log.Printf("⚠️ Profile change stream interrupted: %v (retrying in 5s)", err)
+
+			// Changes may have been missed while disconnected
+			profiles.purge()
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(5 * time.Second):
+			}
+		}
+	}()
+	log.Println("Profile cache invalidation started (MongoDB change stream)")
+}
+
+// watchProfileChanges consumes the seller_profiles change stream until it fails
+func watchProfileChanges(ctx context.Context) error {
+	opts := options.ChangeStream().SetFullDocument(options.UpdateLookup)
+	stream, err := MongoDB.database.Collection(COLLECTION_PROFILES).Watch(ctx, mongo.Pipeline{}, opts)
+	if err != nil {
+		return err
+	}
+	defer stream.Close(context.WithoutCancel(ctx))
+
+	for stream.Next(ctx) {
+		var change struct {
+			FullDocument struct {
+				GluserID  string `bson:"gluser_id"`
+				UpdatedAt string `bson:"updated_at"`
+			} `bson:"fullDocument"`
+		}
+		if err := stream.Decode(&change); err != nil {
+			continue
+		}
+
+		id := change.FullDocument.GluserID
+		if id == "" {
+			// Deletes only carry the _id, so we can't tell which seller changed
+			profiles.purge()
+			continue
+		}
+		knownSellers.add(id)
+		// Our own writes are already cached - skip them
+		if cached := profiles.updatedAt(id); !cached.IsZero() && cached.Format(time.RFC3339Nano) == change.FullDocument.UpdatedAt {
+			continue
+		}
+		profiles.invalidate(id)
+	}
+	return stream.Err()
+}
+
+// isChangeStreamUnsupported reports whether the server can't run change
+// streams at all (standalone mongod), as opposed to a transient failure
+func isChangeStreamUnsupported(err error) bool {
+	var cmdErr mongo.CommandError
+	if errors.As(err, &cmdErr) {
+		// 40573: "The $changeStream stage is only supported on replica sets"
+		return cmdErr.Code == 40573 || cmdErr.Name == "IllegalOperation"
+	}
+	return false
+}
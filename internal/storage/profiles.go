@@ -0,0 +1,280 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/chaos"
+	"im-ai-voice/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ==================== SELLER PROFILE STORAGE ====================
+
+// SaveSellerProfile saves a seller profile to MongoDB (primary) and the profile cache
+func SaveSellerProfile(ctx context.Context, profile *client.SellerProfile) error {
+	profile.UpdatedAt = time.Now()
+
+	var err error
+	if IsMongoEnabled() {
+		// MongoDB is primary storage
+		err = SaveSellerProfileToMongo(ctx, profile)
+	} else {
+		// Fallback to local file if MongoDB not available
+		err = saveSellerProfileToFile(profile)
+	}
+
+	if err != nil {
+		profiles.invalidate(profile.GluserID)
+		return err
+	}
+	profiles.put(profile)
+	knownSellers.add(profile.GluserID)
+	saveSellerSummary(ctx, profile)
+	return nil
+}
+
+// SaveSellerProfileToMongo saves profile and its tracked issues directly to MongoDB (synchronous)
+func SaveSellerProfileToMongo(ctx context.Context, profile *client.SellerProfile) error {
+	if MongoDB == nil || !MongoDB.enabled {
+		return fmt.Errorf("MongoDB not enabled")
+	}
+
+	if err := chaos.MongoWriteError("save profile " + profile.GluserID); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	collection := MongoDB.database.Collection(COLLECTION_PROFILES)
+
+	// Convert to bson.M using JSON tags
+	doc, err := ToBsonM(profile)
+	if err != nil {
+		return fmt.Errorf("failed to marshal profile: %w", err)
+	}
+
+	// Tracked issues go to the issues collection first, so anyone reloading
+	// the profile after the replace below sees them
+	if err := saveSellerIssuesToMongo(ctx, profile); err != nil {
+		return fmt.Errorf("failed to save issues to MongoDB: %w", err)
+	}
+	delete(doc, "active_issues")
+	delete(doc, "resolved_issues")
+
+	// Upsert
+	filter := bson.M{"gluser_id": profile.GluserID}
+	opts := options.Replace().SetUpsert(true)
+
+	_, err = collection.ReplaceOne(ctx, filter, doc, opts)
+	if err != nil {
+		return fmt.Errorf("failed to save profile to MongoDB: %w", err)
+	}
+
+	log.Printf("   📤 Saved profile to MongoDB: %s", profile.GluserID)
+	return nil
+}
+
+// saveSellerProfileToFile saves profile to local file (fallback)
+func saveSellerProfileToFile(profile *client.SellerProfile) error {
+	b, err := json.MarshalIndent(profile, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal profile: %w", err)
+	}
+
+	path := filepath.Join(config.PROFILES_DIR, fmt.Sprintf("seller_%s.json", profile.GluserID))
+	return writeFile(path, b, 0644)
+}
+
+// LoadSellerProfile loads a seller profile - cache first, then MongoDB, fallback to file
+func LoadSellerProfile(ctx context.Context, gluserID string) (*client.SellerProfile, error) {
+	if profile, ok := profiles.get(gluserID); ok {
+		return profile, nil
+	}
+
+	// Try MongoDB first
+	if IsMongoEnabled() {
+		profile, err := GetSellerProfileFromMongo(ctx, gluserID)
+		if err != nil {
+			log.Printf("⚠️ MongoDB load failed for %s: %v", gluserID, err)
+		}
+		if profile != nil {
+			profiles.put(profile)
+			knownSellers.add(gluserID)
+			return profile, nil
+		}
+	}
+
+	// Fallback to local file
+	profile, err := loadSellerProfileFromFile(gluserID)
+	if err == nil && profile != nil {
+		profiles.put(profile)
+		knownSellers.add(gluserID)
+	}
+	return profile, err
+}
+
+// PrefetchSellerProfiles loads the profiles of sellers about to be
+// processed into the profile cache, two MongoDB queries for the whole batch
+// rather than two per seller. Sellers already cached or known to have no
+// profile are skipped, as is everything without MongoDB or the cache.
+// Returns how many profiles were cached.
+func PrefetchSellerProfiles(ctx context.Context, gluserIDs []string) int {
+	if !IsMongoEnabled() || profiles.capacity == 0 {
+		return 0
+	}
+	var ids []string
+	seen := make(map[string]bool)
+	for _, id := range gluserIDs {
+		if id == "" || seen[id] || !MayHaveSellerProfile(id) {
+			continue
+		}
+		seen[id] = true
+		if _, ok := profiles.get(id); !ok {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) > profiles.capacity {
+		ids = ids[:profiles.capacity] // The rest would only evict these
+	}
+	if len(ids) == 0 {
+		return 0
+	}
+
+	fetched, err := getSellerProfilesFromMongo(ctx, ids)
+	if err != nil {
+		log.Printf("⚠️ Failed to prefetch %d seller profiles: %v", len(ids), err)
+		return 0
+	}
+	for _, profile := range fetched {
+		profiles.put(profile)
+		knownSellers.add(profile.GluserID)
+	}
+	return len(fetched)
+}
+
+// getSellerProfilesFromMongo loads the MongoDB profiles of several sellers with their issues
+func getSellerProfilesFromMongo(ctx context.Context, gluserIDs []string) ([]*client.SellerProfile, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	filter := bson.M{"gluser_id": bson.M{"$in": gluserIDs}}
+	cursor, err := MongoDB.database.Collection(COLLECTION_PROFILES).Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var fetched []*client.SellerProfile
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			continue
+		}
+		profile, err := decodeProfileDoc(doc)
+		if err != nil {
+			continue
+		}
+		fetched = append(fetched, profile)
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+	if len(fetched) == 0 {
+		return nil, nil
+	}
+
+	issues, err := getIssuesBySellerFromMongo(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	for _, profile := range fetched {
+		var active, resolved []client.TrackedIssue
+		if set := issues[profile.GluserID]; set != nil {
+			active, resolved = set.active, set.resolved
+		}
+		attachSellerIssues(profile, active, resolved)
+	}
+	return fetched, nil
+}
+
+// loadSellerProfileFromFile loads profile from local file (fallback)
+func loadSellerProfileFromFile(gluserID string) (*client.SellerProfile, error) {
+	path := filepath.Join(config.PROFILES_DIR, fmt.Sprintf("seller_%s.json", gluserID))
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil // Not found, will create new
+		}
+		return nil, err
+	}
+
+	var profile client.SellerProfile
+	if err := json.Unmarshal(b, &profile); err != nil {
+		return nil, err
+	}
+
+	return &profile, nil
+}
+
+// ListSellerProfiles returns all seller profile IDs
+func ListSellerProfiles() ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(config.PROFILES_DIR, "seller_*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for _, f := range files {
+		base := filepath.Base(f)
+		// Extract gluser_id from "seller_XXXXX.json"
+		id := base[7 : len(base)-5] // Remove "seller_" prefix and ".json" suffix
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// ListAllSellerIDs returns all known seller IDs - MongoDB first, fallback to files
+func ListAllSellerIDs(ctx context.Context) ([]string, error) {
+	if IsMongoEnabled() {
+		ids, err := ListAllSellerIDsFromMongo(ctx)
+		if err != nil {
+			log.Printf("⚠️ MongoDB list failed, falling back to local: %v", err)
+		}
+		if len(ids) > 0 {
+			return ids, nil
+		}
+	}
+	return ListSellerProfiles()
+}
+
+// LoadAllSellerProfiles loads every seller profile (skipping unreadable ones)
+func LoadAllSellerProfiles(ctx context.Context) ([]*client.SellerProfile, error) {
+	ids, err := ListAllSellerIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	profiles := make([]*client.SellerProfile, 0, len(ids))
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		profile, err := LoadSellerProfile(ctx, id)
+		if err != nil || profile == nil {
+			continue
+		}
+		profiles = append(profiles, profile)
+	}
+	return profiles, nil
+}
@@ -0,0 +1,148 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ==================== PROVISIONAL CALLS ====================
+// Calls analyzed provisionally while Gemini was unavailable, queued until
+// their full analysis replaces the provisional one. With MongoDB they're in
+// provisional_calls, otherwise one JSON file per call under PROVISIONAL_DIR.
+
+// SaveProvisionalCall queues a call, or updates its queue entry - MongoDB first, local fallback
+func SaveProvisionalCall(ctx context.Context, pc *client.ProvisionalCall) error {
+	if IsMongoEnabled() {
+		ctx, cancel := context.WithTimeout(ctx, opTimeout)
+		defer cancel()
+
+		doc, err := ToBsonM(pc)
+		if err != nil {
+			return fmt.Errorf("failed to marshal provisional call: %w", err)
+		}
+		doc["queued_at"] = pc.QueuedAt // A date, so the queue sorts by it
+		opts := options.Replace().SetUpsert(true)
+		if _, err := MongoDB.database.Collection(COLLECTION_PROVISIONAL).ReplaceOne(ctx, bson.M{"call_id": pc.CallID}, doc, opts); err != nil {
+			return fmt.Errorf("failed to save provisional call to MongoDB: %w", err)
+		}
+		return nil
+	}
+	b, err := json.MarshalIndent(pc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal provisional call: %w", err)
+	}
+	return writeFile(provisionalCallPath(pc.CallID), b, 0644)
+}
+
+// LoadProvisionalCalls returns the queued calls, oldest first, at most
+// limit of them (0 for all) - MongoDB first, local fallback
+func LoadProvisionalCalls(ctx context.Context, limit int) ([]client.ProvisionalCall, error) {
+	if IsMongoEnabled() {
+		return getProvisionalCallsFromMongo(ctx, limit)
+	}
+	entries, err := os.ReadDir(config.PROVISIONAL_DIR)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	calls := []client.ProvisionalCall{}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		b, err := os.ReadFile(filepath.Join(config.PROVISIONAL_DIR, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var pc client.ProvisionalCall
+		if err := json.Unmarshal(b, &pc); err != nil {
+			continue // Skip corrupt files
+		}
+		calls = append(calls, pc)
+	}
+	sort.Slice(calls, func(i, j int) bool {
+		if !calls[i].QueuedAt.Equal(calls[j].QueuedAt) {
+			return calls[i].QueuedAt.Before(calls[j].QueuedAt)
+		}
+		return calls[i].CallID < calls[j].CallID
+	})
+	if limit > 0 && len(calls) > limit {
+		calls = calls[:limit]
+	}
+	return calls, nil
+}
+
+// CountProvisionalCalls returns how many calls are queued - MongoDB first, local fallback
+func CountProvisionalCalls(ctx context.Context) (int, error) {
+	if IsMongoEnabled() {
+		ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+		defer cancel()
+		n, err := MongoDB.database.Collection(COLLECTION_PROVISIONAL).CountDocuments(ctx, bson.M{})
+		return int(n), err
+	}
+	calls, err := LoadProvisionalCalls(ctx, 0)
+	return len(calls), err
+}
+
+// DeleteProvisionalCall takes a call off the queue, if it's on it - MongoDB first, local fallback
+func DeleteProvisionalCall(ctx context.Context, callID string) error {
+	if IsMongoEnabled() {
+		ctx, cancel := context.WithTimeout(ctx, opTimeout)
+		defer cancel()
+		_, err := MongoDB.database.Collection(COLLECTION_PROVISIONAL).DeleteOne(ctx, bson.M{"call_id": callID})
+		return err
+	}
+	if err := os.Remove(provisionalCallPath(callID)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func provisionalCallPath(callID string) string {
+	return filepath.Join(config.PROVISIONAL_DIR, fmt.Sprintf("call_%s.json", Sanitize(callID)))
+}
+
+// ==================== PROVISIONAL CALLS (MongoDB) ====================
+
+func getProvisionalCallsFromMongo(ctx context.Context, limit int) ([]client.ProvisionalCall, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "queued_at", Value: 1}, {Key: "call_id", Value: 1}})
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+	cursor, err := MongoDB.database.Collection(COLLECTION_PROVISIONAL).Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	calls := []client.ProvisionalCall{}
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			continue
+		}
+		b, err := json.Marshal(doc)
+		if err != nil {
+			continue
+		}
+		var pc client.ProvisionalCall
+		if err := json.Unmarshal(b, &pc); err != nil {
+			continue
+		}
+		calls = append(calls, pc)
+	}
+	return calls, cursor.Err()
+}
@@ -0,0 +1,274 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ==================== SELLER QUOTES ====================
+// The voice-of-seller library. With MongoDB quotes are in seller_quotes and
+// opt-outs in quote_opt_outs, otherwise one JSON file of quotes per call
+// under QUOTES_DIR and one per opt-out under QUOTES_DIR/opt_outs.
+
+// QuoteQuery filters seller quotes; empty fields match everything
+type QuoteQuery struct {
+	Bucket    string
+	Sentiment string
+	GluserID  string
+	From      string // Call dates, YYYY-MM-DD, inclusive
+	To        string
+}
+
+func (q QuoteQuery) matches(s client.SellerQuote) bool {
+	return (q.Bucket == "" || s.FeatureBucket == q.Bucket) &&
+		(q.Sentiment == "" || strings.EqualFold(s.Sentiment, q.Sentiment)) &&
+		(q.GluserID == "" || s.GluserID == q.GluserID) &&
+		(q.From == "" || s.Date >= q.From) &&
+		(q.To == "" || s.Date <= q.To)
+}
+
+// ReplaceCallQuotes stores a call's quotes in place of those taken from it
+// before - MongoDB first, local fallback
+func ReplaceCallQuotes(ctx context.Context, callID string, quotes []client.SellerQuote) error {
+	if IsMongoEnabled() {
+		return replaceCallQuotesInMongo(ctx, callID, quotes)
+	}
+	path := callQuotesPath(callID)
+	if len(quotes) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	b, err := json.MarshalIndent(quotes, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal quotes: %w", err)
+	}
+	return writeFile(path, b, 0644)
+}
+
+// LoadQuotes returns the seller quotes matching q, newest call first - MongoDB first, local fallback
+func LoadQuotes(ctx context.Context, q QuoteQuery) ([]client.SellerQuote, error) {
+	var list []client.SellerQuote
+	if IsMongoEnabled() {
+		var err error
+		if list, err = getQuotesFromMongo(ctx, q); err != nil {
+			return nil, err
+		}
+	} else {
+		entries, err := os.ReadDir(config.QUOTES_DIR)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		list = []client.SellerQuote{}
+		for _, e := range entries {
+			if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+				continue
+			}
+			b, err := os.ReadFile(filepath.Join(config.QUOTES_DIR, e.Name()))
+			if err != nil {
+				return nil, err
+			}
+			var quotes []client.SellerQuote
+			if err := json.Unmarshal(b, &quotes); err != nil {
+				continue // Skip corrupt files
+			}
+			for _, s := range quotes {
+				if q.matches(s) {
+					list = append(list, s)
+				}
+			}
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].CallTime.Equal(list[j].CallTime) {
+			return list[i].CallTime.After(list[j].CallTime)
+		}
+		return list[i].ID < list[j].ID
+	})
+	return list, nil
+}
+
+// DeleteSellerQuotes deletes every quote of a seller, returning how many - MongoDB first, local fallback
+func DeleteSellerQuotes(ctx context.Context, gluserID string) (int, error) {
+	if IsMongoEnabled() {
+		ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+		defer cancel()
+		res, err := MongoDB.database.Collection(COLLECTION_QUOTES).DeleteMany(ctx, bson.M{"gluser_id": gluserID})
+		if err != nil {
+			return 0, err
+		}
+		return int(res.DeletedCount), nil
+	}
+	quotes, err := LoadQuotes(ctx, QuoteQuery{GluserID: gluserID})
+	if err != nil {
+		return 0, err
+	}
+	calls := make(map[string]bool)
+	for _, q := range quotes {
+		calls[q.CallID] = true
+	}
+	for callID := range calls {
+		if err := os.Remove(callQuotesPath(callID)); err != nil && !os.IsNotExist(err) {
+			return 0, err
+		}
+	}
+	return len(quotes), nil
+}
+
+// SaveQuoteOptOut records a seller's opt-out from quotes - MongoDB first, local fallback
+func SaveQuoteOptOut(ctx context.Context, o *client.QuoteOptOut) error {
+	if IsMongoEnabled() {
+		ctx, cancel := context.WithTimeout(ctx, opTimeout)
+		defer cancel()
+		doc, err := ToBsonM(o)
+		if err != nil {
+			return fmt.Errorf("failed to marshal quote opt-out: %w", err)
+		}
+		opts := options.Replace().SetUpsert(true)
+		if _, err := MongoDB.database.Collection(COLLECTION_QUOTE_OPT_OUTS).ReplaceOne(ctx, bson.M{"gluser_id": o.GluserID}, doc, opts); err != nil {
+			return fmt.Errorf("failed to save quote opt-out to MongoDB: %w", err)
+		}
+		return nil
+	}
+	b, err := json.MarshalIndent(o, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal quote opt-out: %w", err)
+	}
+	path := quoteOptOutPath(o.GluserID)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return writeFile(path, b, 0644)
+}
+
+// LoadQuoteOptOut returns a seller's opt-out from quotes, nil if they haven't - MongoDB first, local fallback
+func LoadQuoteOptOut(ctx context.Context, gluserID string) (*client.QuoteOptOut, error) {
+	var o client.QuoteOptOut
+	if IsMongoEnabled() {
+		ctx, cancel := context.WithTimeout(ctx, opTimeout)
+		defer cancel()
+		var doc bson.M
+		if err := MongoDB.database.Collection(COLLECTION_QUOTE_OPT_OUTS).FindOne(ctx, bson.M{"gluser_id": gluserID}).Decode(&doc); err != nil {
+			if err == mongo.ErrNoDocuments {
+				return nil, nil
+			}
+			return nil, err
+		}
+		jsonBytes, err := json.Marshal(doc)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(jsonBytes, &o); err != nil {
+			return nil, err
+		}
+		return &o, nil
+	}
+	b, err := os.ReadFile(quoteOptOutPath(gluserID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &o); err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+func callQuotesPath(callID string) string {
+	return filepath.Join(config.QUOTES_DIR, fmt.Sprintf("call_%s.json", Sanitize(callID)))
+}
+
+func quoteOptOutPath(gluserID string) string {
+	return filepath.Join(config.QUOTES_DIR, "opt_outs", fmt.Sprintf("optout_%s.json", Sanitize(gluserID)))
+}
+
+// ==================== SELLER QUOTES (MongoDB) ====================
+
+func replaceCallQuotesInMongo(ctx context.Context, callID string, quotes []client.SellerQuote) error {
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	coll := MongoDB.database.Collection(COLLECTION_QUOTES)
+	if _, err := coll.DeleteMany(ctx, bson.M{"call_id": callID}); err != nil {
+		return fmt.Errorf("failed to delete quotes from MongoDB: %w", err)
+	}
+	if len(quotes) == 0 {
+		return nil
+	}
+	docs := make([]interface{}, 0, len(quotes))
+	for _, q := range quotes {
+		doc, err := ToBsonM(q)
+		if err != nil {
+			return fmt.Errorf("failed to marshal quote: %w", err)
+		}
+		docs = append(docs, doc)
+	}
+	if _, err := coll.InsertMany(ctx, docs); err != nil {
+		return fmt.Errorf("failed to save quotes to MongoDB: %w", err)
+	}
+	return nil
+}
+
+func getQuotesFromMongo(ctx context.Context, q QuoteQuery) ([]client.SellerQuote, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	filter := bson.M{}
+	if q.Bucket != "" {
+		filter["feature_bucket"] = q.Bucket
+	}
+	if q.GluserID != "" {
+		filter["gluser_id"] = q.GluserID
+	}
+	date := bson.M{}
+	if q.From != "" {
+		date["$gte"] = q.From
+	}
+	if q.To != "" {
+		date["$lte"] = q.To
+	}
+	if len(date) > 0 {
+		filter["date"] = date
+	}
+
+	cursor, err := MongoDB.database.Collection(COLLECTION_QUOTES).Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	list := []client.SellerQuote{}
+	for cursor.Next(ctx) {
+		var m bson.M
+		if err := cursor.Decode(&m); err != nil {
+			continue
+		}
+		jsonBytes, err := json.Marshal(m)
+		if err != nil {
+			continue
+		}
+		var s client.SellerQuote
+		if err := json.Unmarshal(jsonBytes, &s); err != nil {
+			continue
+		}
+		if q.matches(s) { // Sentiment is matched ignoring case
+			list = append(list, s)
+		}
+	}
+	return list, cursor.Err()
+}
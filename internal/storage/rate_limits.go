@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ==================== RATE LIMITS ====================
+// Token buckets shared between instances, one document per bucket in
+// rate_limits (see internal/ratelimit). A bucket refills at rate tokens a
+// second up to burst. Each take refills and takes in one atomic update,
+// timed by the MongoDB server's clock like leases. MongoDB only.
+
+// TakeToken takes a token from the named bucket, returning 0 when it got one
+// or how long until the next token otherwise
+func TakeToken(ctx context.Context, name string, rate, burst float64) (time.Duration, error) {
+	if !IsMongoEnabled() {
+		return 0, fmt.Errorf("MongoDB not enabled")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	elapsedMs := bson.M{"$subtract": bson.A{"$$NOW", bson.M{"$ifNull": bson.A{"$updated_at", "$$NOW"}}}}
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"tokens": bson.M{"$min": bson.A{burst, bson.M{"$add": bson.A{
+				bson.M{"$ifNull": bson.A{"$tokens", burst}},
+				bson.M{"$multiply": bson.A{elapsedMs, rate / 1000}},
+			}}}},
+			"updated_at": "$$NOW",
+		}}},
+		{{Key: "$set", Value: bson.M{"granted": bson.M{"$gte": bson.A{"$tokens", 1}}}}},
+		{{Key: "$set", Value: bson.M{"tokens": bson.M{"$cond": bson.A{"$granted", bson.M{"$subtract": bson.A{"$tokens", 1}}, "$tokens"}}}}},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var bucket struct {
+		Tokens  float64 `bson:"tokens"`
+		Granted bool    `bson:"granted"`
+	}
+	coll := MongoDB.database.Collection(COLLECTION_RATE_LIMITS)
+	err := coll.FindOneAndUpdate(ctx, bson.M{"_id": name}, update, opts).Decode(&bucket)
+	if mongo.IsDuplicateKeyError(err) {
+		// Another instance created the bucket first
+		err = coll.FindOneAndUpdate(ctx, bson.M{"_id": name}, update, opts).Decode(&bucket)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to take from rate limit %s: %w", name, err)
+	}
+	if bucket.Granted {
+		return 0, nil
+	}
+	return time.Duration((1 - bucket.Tokens) / rate * float64(time.Second)), nil
+}
@@ -0,0 +1,332 @@
+package storage
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ==================== RECLASSIFICATIONS ====================
+// Runs migrating stored data to a changed bucket taxonomy, and every record
+// each one moved. With MongoDB the runs are in reclassifications and the
+// changes in reclassification_changes; otherwise each run is a JSON file
+// under RECLASSIFY_DIR with its changes in a JSON Lines file beside it. They
+// are the audit trail of the migrated data, so WipeDerivedData leaves them
+// alone.
+
+// reclassifyChangeBatch is how many changes are written to MongoDB at once
+const reclassifyChangeBatch = 1000
+
+// SaveReclassification stores a run without its changes, replacing its
+// previous version - MongoDB first, local fallback
+func SaveReclassification(ctx context.Context, run *client.Reclassification) error {
+	stored := *run
+	stored.Changes = nil
+	if IsMongoEnabled() {
+		ctx, cancel := context.WithTimeout(ctx, opTimeout)
+		defer cancel()
+		doc, err := ToBsonM(&stored)
+		if err != nil {
+			return fmt.Errorf("failed to marshal reclassification: %w", err)
+		}
+		opts := options.Replace().SetUpsert(true)
+		if _, err := MongoDB.database.Collection(COLLECTION_RECLASSIFY).ReplaceOne(ctx, bson.M{"id": run.ID}, doc, opts); err != nil {
+			return fmt.Errorf("failed to save reclassification to MongoDB: %w", err)
+		}
+		return nil
+	}
+	if err := os.MkdirAll(config.RECLASSIFY_DIR, 0755); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(stored, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal reclassification: %w", err)
+	}
+	return writeFile(reclassificationPath(run.ID), b, 0644)
+}
+
+// LoadReclassifications returns every run without its changes, newest
+// first - MongoDB first, local fallback
+func LoadReclassifications(ctx context.Context) ([]client.Reclassification, error) {
+	var runs []client.Reclassification
+	if IsMongoEnabled() {
+		ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+		defer cancel()
+		cursor, err := MongoDB.database.Collection(COLLECTION_RECLASSIFY).Find(ctx, bson.M{})
+		if err != nil {
+			return nil, err
+		}
+		defer cursor.Close(ctx)
+		runs = []client.Reclassification{}
+		for cursor.Next(ctx) {
+			var doc bson.M
+			if err := cursor.Decode(&doc); err != nil {
+				continue
+			}
+			b, err := json.Marshal(doc)
+			if err != nil {
+				continue
+			}
+			var run client.Reclassification
+			if err := json.Unmarshal(b, &run); err != nil {
+				continue
+			}
+			runs = append(runs, run)
+		}
+		if err := cursor.Err(); err != nil {
+			return nil, err
+		}
+	} else {
+		files, err := filepath.Glob(filepath.Join(config.RECLASSIFY_DIR, "*.json"))
+		if err != nil {
+			return nil, err
+		}
+		runs = make([]client.Reclassification, 0, len(files))
+		for _, f := range files {
+			b, err := os.ReadFile(f)
+			if err != nil {
+				return nil, err
+			}
+			var run client.Reclassification
+			if err := json.Unmarshal(b, &run); err != nil {
+				continue // Skip corrupt files
+			}
+			runs = append(runs, run)
+		}
+	}
+	sort.Slice(runs, func(i, j int) bool {
+		if !runs[i].StartedAt.Equal(runs[j].StartedAt) {
+			return runs[i].StartedAt.After(runs[j].StartedAt)
+		}
+		return runs[i].ID > runs[j].ID
+	})
+	return runs, nil
+}
+
+// LoadReclassification returns a run without its changes, nil if there's
+// none with the ID - MongoDB first, local fallback
+func LoadReclassification(ctx context.Context, id string) (*client.Reclassification, error) {
+	var b []byte
+	if IsMongoEnabled() {
+		ctx, cancel := context.WithTimeout(ctx, opTimeout)
+		defer cancel()
+		var doc bson.M
+		err := MongoDB.database.Collection(COLLECTION_RECLASSIFY).FindOne(ctx, bson.M{"id": id}).Decode(&doc)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if b, err = json.Marshal(doc); err != nil {
+			return nil, err
+		}
+	} else {
+		var err error
+		b, err = os.ReadFile(reclassificationPath(id))
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	var run client.Reclassification
+	if err := json.Unmarshal(b, &run); err != nil {
+		return nil, fmt.Errorf("failed to decode reclassification: %w", err)
+	}
+	return &run, nil
+}
+
+// AppendReclassifyChanges adds changes to a run's trail - MongoDB first,
+// local fallback
+func AppendReclassifyChanges(ctx context.Context, runID string, changes []client.ReclassifyChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	if IsMongoEnabled() {
+		for start := 0; start < len(changes); start += reclassifyChangeBatch {
+			end := min(start+reclassifyChangeBatch, len(changes))
+			docs := make([]any, 0, end-start)
+			for _, c := range changes[start:end] {
+				c.RunID = runID
+				doc, err := ToBsonM(&c)
+				if err != nil {
+					return fmt.Errorf("failed to marshal reclassification change: %w", err)
+				}
+				docs = append(docs, doc)
+			}
+			ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+			_, err := MongoDB.database.Collection(COLLECTION_RECLASSIFY_LOG).InsertMany(ctx, docs)
+			cancel()
+			if err != nil {
+				return fmt.Errorf("failed to save reclassification changes to MongoDB: %w", err)
+			}
+		}
+		return nil
+	}
+
+	if err := os.MkdirAll(config.RECLASSIFY_DIR, 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(reclassifyChangesPath(runID), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, c := range changes {
+		c.RunID = runID
+		if err := enc.Encode(c); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// LoadReclassifyChanges returns a run's changes of a kind (all with an
+// empty kind) in the order they were made, up to limit, and how many there
+// are - MongoDB first, local fallback
+func LoadReclassifyChanges(ctx context.Context, runID, kind string, limit int) ([]client.ReclassifyChange, int, error) {
+	changes := []client.ReclassifyChange{}
+	if IsMongoEnabled() {
+		ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+		defer cancel()
+		coll := MongoDB.database.Collection(COLLECTION_RECLASSIFY_LOG)
+		filter := bson.M{"run_id": runID}
+		if kind != "" {
+			filter["kind"] = kind
+		}
+		total, err := coll.CountDocuments(ctx, filter)
+		if err != nil {
+			return nil, 0, err
+		}
+		opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(int64(limit))
+		cursor, err := coll.Find(ctx, filter, opts)
+		if err != nil {
+			return nil, 0, err
+		}
+		defer cursor.Close(ctx)
+		for cursor.Next(ctx) {
+			var doc bson.M
+			if err := cursor.Decode(&doc); err != nil {
+				continue
+			}
+			b, err := json.Marshal(doc)
+			if err != nil {
+				continue
+			}
+			var c client.ReclassifyChange
+			if err := json.Unmarshal(b, &c); err != nil {
+				continue
+			}
+			changes = append(changes, c)
+		}
+		return changes, int(total), cursor.Err()
+	}
+
+	f, err := os.Open(reclassifyChangesPath(runID))
+	if os.IsNotExist(err) {
+		return changes, 0, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+	total := 0
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var c client.ReclassifyChange
+		if err := json.Unmarshal(scanner.Bytes(), &c); err != nil {
+			continue
+		}
+		if kind != "" && c.Kind != kind {
+			continue
+		}
+		total++
+		if len(changes) < limit {
+			changes = append(changes, c)
+		}
+	}
+	return changes, total, scanner.Err()
+}
+
+func reclassificationPath(id string) string {
+	return filepath.Join(config.RECLASSIFY_DIR, Sanitize(id)+".json")
+}
+
+func reclassifyChangesPath(id string) string {
+	return filepath.Join(config.RECLASSIFY_DIR, Sanitize(id)+".changes.jsonl")
+}
+
+// RewriteAnalyses calls rewrite with every stored analysis and saves, in
+// place, those it reports changed - MongoDB first, local fallback. Archived
+// analyses aren't included.
+func RewriteAnalyses(ctx context.Context, rewrite func(ar *client.AnalysisResult) bool) (int, error) {
+	rewritten := 0
+	if IsMongoEnabled() {
+		analyses, err := GetAllAnalysesFromMongo(ctx)
+		if err != nil {
+			return 0, err
+		}
+		for i := range analyses {
+			if err := ctx.Err(); err != nil {
+				return rewritten, err
+			}
+			if !rewrite(&analyses[i]) {
+				continue
+			}
+			if err := SaveAnalysisToMongo(ctx, &analyses[i]); err != nil {
+				return rewritten, fmt.Errorf("call %s: %w", analyses[i].CallID, err)
+			}
+			rewritten++
+		}
+		return rewritten, nil
+	}
+
+	files, err := ListAnalysisFiles()
+	if err != nil {
+		return 0, err
+	}
+	for _, f := range files {
+		if err := ctx.Err(); err != nil {
+			return rewritten, err
+		}
+		b, err := os.ReadFile(f)
+		if err != nil {
+			continue
+		}
+		var ar client.AnalysisResult
+		if err := json.Unmarshal(b, &ar); err != nil {
+			continue
+		}
+		if !rewrite(&ar) {
+			continue
+		}
+		// Analyses saved before raw responses were split off keep them, as MigrateLLMRaw moves them
+		if err := writeAnalysisFile(f, ar); err != nil {
+			return rewritten, fmt.Errorf("call %s: %w", strings.TrimSuffix(filepath.Base(f), ".analysis.json"), err)
+		}
+		rewritten++
+	}
+	return rewritten, nil
+}
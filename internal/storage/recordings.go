@@ -0,0 +1,191 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ==================== CALL RECORDINGS ====================
+// Records of downloaded call audio, one per call. The audio itself lives in
+// the recording store (see internal/recording); these records say where, and
+// stay behind with purged_at set once retention deletes the audio. With
+// MongoDB they're in call_recordings, otherwise one JSON file per call under
+// RECORDINGS_DIR.
+
+// SaveRecording stores a call's recording record - MongoDB first, local fallback
+func SaveRecording(ctx context.Context, rec *client.Recording) error {
+	if IsMongoEnabled() {
+		return saveRecordingToMongo(ctx, rec)
+	}
+	return saveRecordingToFile(rec)
+}
+
+// LoadRecording returns a call's recording record, nil if it has none - MongoDB first, local fallback
+func LoadRecording(ctx context.Context, callID string) (*client.Recording, error) {
+	if IsMongoEnabled() {
+		return getRecordingFromMongo(ctx, callID)
+	}
+	return loadRecordingFromFile(callID)
+}
+
+// ListRecordingsToPurge returns the recordings of calls before cutoff whose
+// audio hasn't been deleted yet, oldest first
+func ListRecordingsToPurge(ctx context.Context, cutoff time.Time) ([]client.Recording, error) {
+	var recs []client.Recording
+	var err error
+	if IsMongoEnabled() {
+		recs, err = listRecordingsToPurgeFromMongo(ctx, cutoff)
+	} else {
+		recs, err = listRecordingsToPurgeFromFiles(cutoff)
+	}
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(recs, func(i, j int) bool { return recs[i].Timestamp.Before(recs[j].Timestamp) })
+	return recs, nil
+}
+
+func recordingPath(callID string) string {
+	return filepath.Join(config.RECORDINGS_DIR, fmt.Sprintf("recording_%s.json", Sanitize(callID)))
+}
+
+func saveRecordingToFile(rec *client.Recording) error {
+	b, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal recording: %w", err)
+	}
+	return writeFile(recordingPath(rec.CallID), b, 0644)
+}
+
+func loadRecordingFromFile(callID string) (*client.Recording, error) {
+	b, err := os.ReadFile(recordingPath(callID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var rec client.Recording
+	if err := json.Unmarshal(b, &rec); err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+func listRecordingsToPurgeFromFiles(cutoff time.Time) ([]client.Recording, error) {
+	files, err := filepath.Glob(filepath.Join(config.RECORDINGS_DIR, "recording_*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	recs := []client.Recording{}
+	for _, f := range files {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			continue
+		}
+		var rec client.Recording
+		if err := json.Unmarshal(b, &rec); err != nil {
+			continue
+		}
+		if rec.PurgedAt == nil && rec.Timestamp.Before(cutoff) {
+			recs = append(recs, rec)
+		}
+	}
+	return recs, nil
+}
+
+// ==================== CALL RECORDINGS (MongoDB) ====================
+
+func saveRecordingToMongo(ctx context.Context, rec *client.Recording) error {
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	doc, err := ToBsonM(rec)
+	if err != nil {
+		return fmt.Errorf("failed to marshal recording: %w", err)
+	}
+
+	opts := options.Replace().SetUpsert(true)
+	_, err = MongoDB.database.Collection(COLLECTION_RECORDINGS).ReplaceOne(ctx, bson.M{"call_id": rec.CallID}, doc, opts)
+	if err != nil {
+		return fmt.Errorf("failed to save recording to MongoDB: %w", err)
+	}
+	return nil
+}
+
+func getRecordingFromMongo(ctx context.Context, callID string) (*client.Recording, error) {
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	var doc bson.M
+	err := MongoDB.database.Collection(COLLECTION_RECORDINGS).FindOne(ctx, bson.M{"call_id": callID}).Decode(&doc)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	rec, err := decodeRecording(doc)
+	if err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+func listRecordingsToPurgeFromMongo(ctx context.Context, cutoff time.Time) ([]client.Recording, error) {
+	ctx, cancel := context.WithTimeout(ctx, scanTimeout)
+	defer cancel()
+
+	// Call timestamps are RFC3339 strings in the offset they were recorded
+	// in, so compare a day early and check the exact cutoff below
+	filter := bson.M{
+		"purged_at": bson.M{"$exists": false},
+		"timestamp": bson.M{"$lt": cutoff.AddDate(0, 0, 1).UTC().Format(time.RFC3339)},
+	}
+	cursor, err := MongoDB.database.Collection(COLLECTION_RECORDINGS).Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	recs := []client.Recording{}
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			continue
+		}
+		rec, err := decodeRecording(doc)
+		if err != nil {
+			continue
+		}
+		if rec.Timestamp.Before(cutoff) {
+			recs = append(recs, rec)
+		}
+	}
+	return recs, cursor.Err()
+}
+
+func decodeRecording(doc bson.M) (client.Recording, error) {
+	var rec client.Recording
+	jsonBytes, err := json.Marshal(doc)
+	if err != nil {
+		return rec, err
+	}
+	err = json.Unmarshal(jsonBytes, &rec)
+	return rec, err
+}
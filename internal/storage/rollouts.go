@@ -0,0 +1,163 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ==================== ROLLOUTS ====================
+// Canary rollouts of prompt/model changes, polled by every instance for the
+// active one. With MongoDB they're in rollouts, otherwise one JSON file per
+// rollout under ROLLOUTS_DIR. Like bucket owners, they're configuration, so
+// WipeDerivedData leaves them alone.
+
+// SaveRollout stores a rollout, replacing its previous state - MongoDB first, local fallback
+func SaveRollout(ctx context.Context, r *client.Rollout) error {
+	if IsMongoEnabled() {
+		return saveRolloutToMongo(ctx, r)
+	}
+	b, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal rollout: %w", err)
+	}
+	return writeFile(rolloutPath(r.ID), b, 0644)
+}
+
+// LoadRollouts returns every rollout, newest first - MongoDB first, local fallback
+func LoadRollouts(ctx context.Context) ([]client.Rollout, error) {
+	var rollouts []client.Rollout
+	if IsMongoEnabled() {
+		var err error
+		if rollouts, err = getRolloutsFromMongo(ctx, bson.M{}); err != nil {
+			return nil, err
+		}
+	} else {
+		entries, err := os.ReadDir(config.ROLLOUTS_DIR)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		rollouts = make([]client.Rollout, 0, len(entries))
+		for _, e := range entries {
+			if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+				continue
+			}
+			b, err := os.ReadFile(filepath.Join(config.ROLLOUTS_DIR, e.Name()))
+			if err != nil {
+				return nil, err
+			}
+			var r client.Rollout
+			if err := json.Unmarshal(b, &r); err != nil {
+				continue // Skip corrupt files
+			}
+			rollouts = append(rollouts, r)
+		}
+	}
+	sort.Slice(rollouts, func(i, j int) bool { return rollouts[i].CreatedAt.After(rollouts[j].CreatedAt) })
+	return rollouts, nil
+}
+
+// LoadRollout returns a rollout by ID, nil if there's none - MongoDB first, local fallback
+func LoadRollout(ctx context.Context, id string) (*client.Rollout, error) {
+	if IsMongoEnabled() {
+		rollouts, err := getRolloutsFromMongo(ctx, bson.M{"id": id})
+		if err != nil || len(rollouts) == 0 {
+			return nil, err
+		}
+		return &rollouts[0], nil
+	}
+	b, err := os.ReadFile(rolloutPath(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var r client.Rollout
+	if err := json.Unmarshal(b, &r); err != nil || r.ID != id {
+		return nil, nil
+	}
+	return &r, nil
+}
+
+// LoadActiveRollout returns the rollout routing analyses now, nil if there's none
+func LoadActiveRollout(ctx context.Context) (*client.Rollout, error) {
+	if IsMongoEnabled() {
+		rollouts, err := getRolloutsFromMongo(ctx, bson.M{"status": client.RolloutActive})
+		if err != nil || len(rollouts) == 0 {
+			return nil, err
+		}
+		return &rollouts[0], nil
+	}
+	rollouts, err := LoadRollouts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range rollouts {
+		if rollouts[i].Status == client.RolloutActive {
+			return &rollouts[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func rolloutPath(id string) string {
+	return filepath.Join(config.ROLLOUTS_DIR, fmt.Sprintf("rollout_%s.json", Sanitize(id)))
+}
+
+// ==================== ROLLOUTS (MongoDB) ====================
+
+func saveRolloutToMongo(ctx context.Context, r *client.Rollout) error {
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	doc, err := ToBsonM(r)
+	if err != nil {
+		return fmt.Errorf("failed to marshal rollout: %w", err)
+	}
+
+	opts := options.Replace().SetUpsert(true)
+	if _, err := MongoDB.database.Collection(COLLECTION_ROLLOUTS).ReplaceOne(ctx, bson.M{"id": r.ID}, doc, opts); err != nil {
+		return fmt.Errorf("failed to save rollout to MongoDB: %w", err)
+	}
+	return nil
+}
+
+func getRolloutsFromMongo(ctx context.Context, filter bson.M) ([]client.Rollout, error) {
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	cursor, err := MongoDB.database.Collection(COLLECTION_ROLLOUTS).Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	rollouts := []client.Rollout{}
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			continue
+		}
+		jsonBytes, err := json.Marshal(doc)
+		if err != nil {
+			continue
+		}
+		var r client.Rollout
+		if err := json.Unmarshal(jsonBytes, &r); err != nil {
+			continue
+		}
+		rollouts = append(rollouts, r)
+	}
+	return rollouts, cursor.Err()
+}
@@ -0,0 +1,232 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ==================== CALL SAMPLES ====================
+// Anonymized example calls for training and demos. With MongoDB they're in
+// call_samples, otherwise one JSON file per sample under SAMPLES_DIR. A
+// sample is a reviewed copy rather than a view of its call, so
+// WipeDerivedData leaves them alone.
+
+// SampleQuery filters call samples; empty fields match everything
+type SampleQuery struct {
+	Status    string
+	Bucket    string
+	Sentiment string
+	GluserID  string
+	CallID    string
+}
+
+func (q SampleQuery) matches(s client.CallSample) bool {
+	return (q.Status == "" || s.Status == q.Status) &&
+		(q.Bucket == "" || s.FeatureBucket == q.Bucket) &&
+		(q.Sentiment == "" || strings.EqualFold(s.Sentiment, q.Sentiment)) &&
+		(q.GluserID == "" || s.GluserID == q.GluserID) &&
+		(q.CallID == "" || s.CallID == q.CallID)
+}
+
+// SaveCallSample stores a sample, replacing its previous version - MongoDB first, local fallback
+func SaveCallSample(ctx context.Context, s *client.CallSample) error {
+	if IsMongoEnabled() {
+		ctx, cancel := context.WithTimeout(ctx, opTimeout)
+		defer cancel()
+		doc, err := ToBsonM(s)
+		if err != nil {
+			return fmt.Errorf("failed to marshal call sample: %w", err)
+		}
+		opts := options.Replace().SetUpsert(true)
+		if _, err := MongoDB.database.Collection(COLLECTION_SAMPLES).ReplaceOne(ctx, bson.M{"id": s.ID}, doc, opts); err != nil {
+			return fmt.Errorf("failed to save call sample to MongoDB: %w", err)
+		}
+		return nil
+	}
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal call sample: %w", err)
+	}
+	return writeFile(samplePath(s.ID), b, 0644)
+}
+
+// LoadCallSamples returns the samples matching q, newest first - MongoDB first, local fallback
+func LoadCallSamples(ctx context.Context, q SampleQuery) ([]client.CallSample, error) {
+	var list []client.CallSample
+	if IsMongoEnabled() {
+		var err error
+		if list, err = getCallSamplesFromMongo(ctx, q); err != nil {
+			return nil, err
+		}
+	} else {
+		entries, err := os.ReadDir(config.SAMPLES_DIR)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		list = []client.CallSample{}
+		for _, e := range entries {
+			if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+				continue
+			}
+			b, err := os.ReadFile(filepath.Join(config.SAMPLES_DIR, e.Name()))
+			if err != nil {
+				return nil, err
+			}
+			var s client.CallSample
+			if err := json.Unmarshal(b, &s); err != nil {
+				continue // Skip corrupt files
+			}
+			if q.matches(s) {
+				list = append(list, s)
+			}
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].CreatedAt.Equal(list[j].CreatedAt) {
+			return list[i].CreatedAt.After(list[j].CreatedAt)
+		}
+		return list[i].ID > list[j].ID
+	})
+	return list, nil
+}
+
+// LoadCallSample returns a sample, nil if there's none with the ID - MongoDB first, local fallback
+func LoadCallSample(ctx context.Context, id string) (*client.CallSample, error) {
+	var b []byte
+	if IsMongoEnabled() {
+		ctx, cancel := context.WithTimeout(ctx, opTimeout)
+		defer cancel()
+		var doc bson.M
+		err := MongoDB.database.Collection(COLLECTION_SAMPLES).FindOne(ctx, bson.M{"id": id}).Decode(&doc)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if b, err = json.Marshal(doc); err != nil {
+			return nil, err
+		}
+	} else {
+		var err error
+		b, err = os.ReadFile(samplePath(id))
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	var s client.CallSample
+	if err := json.Unmarshal(b, &s); err != nil {
+		return nil, fmt.Errorf("failed to decode call sample: %w", err)
+	}
+	return &s, nil
+}
+
+// DeleteCallSample removes a sample, reporting whether it existed - MongoDB first, local fallback
+func DeleteCallSample(ctx context.Context, id string) (bool, error) {
+	if IsMongoEnabled() {
+		ctx, cancel := context.WithTimeout(ctx, opTimeout)
+		defer cancel()
+		res, err := MongoDB.database.Collection(COLLECTION_SAMPLES).DeleteOne(ctx, bson.M{"id": id})
+		if err != nil {
+			return false, err
+		}
+		return res.DeletedCount > 0, nil
+	}
+	if err := os.Remove(samplePath(id)); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// DeleteSellerSamples deletes every sample taken from a seller's calls,
+// returning how many - MongoDB first, local fallback
+func DeleteSellerSamples(ctx context.Context, gluserID string) (int, error) {
+	if IsMongoEnabled() {
+		ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+		defer cancel()
+		res, err := MongoDB.database.Collection(COLLECTION_SAMPLES).DeleteMany(ctx, bson.M{"gluser_id": gluserID})
+		if err != nil {
+			return 0, err
+		}
+		return int(res.DeletedCount), nil
+	}
+	samples, err := LoadCallSamples(ctx, SampleQuery{GluserID: gluserID})
+	if err != nil {
+		return 0, err
+	}
+	for _, s := range samples {
+		if err := os.Remove(samplePath(s.ID)); err != nil && !os.IsNotExist(err) {
+			return 0, err
+		}
+	}
+	return len(samples), nil
+}
+
+func samplePath(id string) string {
+	return filepath.Join(config.SAMPLES_DIR, Sanitize(id)+".json")
+}
+
+// ==================== CALL SAMPLES (MongoDB) ====================
+
+func getCallSamplesFromMongo(ctx context.Context, q SampleQuery) ([]client.CallSample, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	filter := bson.M{}
+	if q.Status != "" {
+		filter["status"] = q.Status
+	}
+	if q.Bucket != "" {
+		filter["feature_bucket"] = q.Bucket
+	}
+	if q.GluserID != "" {
+		filter["gluser_id"] = q.GluserID
+	}
+	if q.CallID != "" {
+		filter["call_id"] = q.CallID
+	}
+	cursor, err := MongoDB.database.Collection(COLLECTION_SAMPLES).Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	list := []client.CallSample{}
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			continue
+		}
+		jsonBytes, err := json.Marshal(doc)
+		if err != nil {
+			continue
+		}
+		var s client.CallSample
+		if err := json.Unmarshal(jsonBytes, &s); err != nil {
+			continue
+		}
+		if q.matches(s) { // Sentiment is matched ignoring case
+			list = append(list, s)
+		}
+	}
+	return list, cursor.Err()
+}
@@ -0,0 +1,433 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ==================== SELLER SUMMARIES ====================
+// Compact rows for GET /sellers, so listing thousands of sellers doesn't
+// load every profile. SaveSellerProfile writes the seller's summary along
+// with the profile: with MongoDB to seller_summaries, indexed for the
+// listing's filters and orders; otherwise into an in-memory index built from
+// the profile files on first use. SyncSellerSummaries fills in summaries
+// missing for profiles written before them, or that failed to save.
+
+const (
+	sellersDefaultPageSize = 100
+	sellersMaxPageSize     = 1000
+)
+
+// SellerQuery filters GET /sellers
+type SellerQuery struct {
+	Prefix         string // Seller IDs starting with this
+	CustomerType   string
+	City           string
+	Vertical       string
+	Stage          string // Journey stage
+	ChurnRisk      string
+	HealthLabel    string
+	NeedsAttention *bool          // nil for either
+	CustomFields   map[string]any // Custom field values, parsed by their definitions
+	Sort           string         // client.SellerSort*, last_call when empty
+	Page           int            // 1-based
+	PageSize       int
+}
+
+func (q SellerQuery) matches(s *client.SellerSummary) bool {
+	return strings.HasPrefix(s.GluserID, q.Prefix) &&
+		(q.CustomerType == "" || s.CustomerType == q.CustomerType) &&
+		(q.City == "" || strings.EqualFold(s.CityName, q.City)) &&
+		(q.Vertical == "" || strings.EqualFold(s.Vertical, q.Vertical)) &&
+		(q.Stage == "" || s.JourneyStage == q.Stage) &&
+		(q.ChurnRisk == "" || s.ChurnRisk == q.ChurnRisk) &&
+		(q.HealthLabel == "" || s.HealthLabel == q.HealthLabel) &&
+		(q.NeedsAttention == nil || s.NeedsAttention == *q.NeedsAttention) &&
+		client.CustomFieldsMatch(s.CustomFields, q.CustomFields)
+}
+
+// sortSummaries orders rows by the query's sort, seller ID breaking ties
+func (q SellerQuery) sortSummaries(rows []client.SellerSummary) {
+	sort.Slice(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		switch q.Sort {
+		case client.SellerSortHealth:
+			if a.HealthScore != b.HealthScore {
+				return a.HealthScore < b.HealthScore
+			}
+		case client.SellerSortOpenIssues:
+			if a.OpenIssues != b.OpenIssues {
+				return a.OpenIssues > b.OpenIssues
+			}
+		default:
+			if !a.LastCallAt.Equal(b.LastCallAt) {
+				return a.LastCallAt.After(b.LastCallAt)
+			}
+		}
+		return a.GluserID < b.GluserID
+	})
+}
+
+// QuerySellerSummaries returns a page of seller summaries - MongoDB first,
+// local fallback
+func QuerySellerSummaries(ctx context.Context, q SellerQuery) (*client.SellerPage, error) {
+	if q.Page <= 0 {
+		q.Page = 1
+	}
+	if q.PageSize <= 0 {
+		q.PageSize = sellersDefaultPageSize
+	}
+	if q.PageSize > sellersMaxPageSize {
+		q.PageSize = sellersMaxPageSize
+	}
+
+	if IsMongoEnabled() {
+		return querySellerSummariesFromMongo(ctx, q)
+	}
+
+	matched, err := summaries.match(q)
+	if err != nil {
+		return nil, err
+	}
+	page := &client.SellerPage{Sellers: []client.SellerSummary{}, TotalCount: len(matched), Page: q.Page, PageSize: q.PageSize}
+	for _, s := range matched {
+		if s.NeedsAttention {
+			page.NeedsAttentionCount++
+		}
+	}
+	start := (q.Page - 1) * q.PageSize
+	if start < len(matched) {
+		page.Sellers = append(page.Sellers, matched[start:min(start+q.PageSize, len(matched))]...)
+	}
+	page.Count = len(page.Sellers)
+	page.HasMore = start+page.Count < page.TotalCount
+	return page, nil
+}
+
+// EachSellerSummary calls fn with every seller summary matching the query,
+// in its order, ignoring paging, and stops at fn's first error - MongoDB
+// first, local fallback
+func EachSellerSummary(ctx context.Context, q SellerQuery, fn func(client.SellerSummary) error) error {
+	if IsMongoEnabled() {
+		ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+		defer cancel()
+		cursor, err := MongoDB.database.Collection(COLLECTION_SELLER_SUMMARIES).Find(ctx, sellerSummaryFilter(q), options.Find().SetSort(sellerSummarySort(q)))
+		if err != nil {
+			return err
+		}
+		defer cursor.Close(ctx)
+		for cursor.Next(ctx) {
+			s, err := decodeSellerSummary(cursor.Current)
+			if err != nil {
+				continue
+			}
+			if err := fn(*s); err != nil {
+				return err
+			}
+		}
+		return cursor.Err()
+	}
+
+	matched, err := summaries.match(q)
+	if err != nil {
+		return err
+	}
+	for _, s := range matched {
+		if err := fn(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// saveSellerSummary writes the profile's summary. A failure is logged, not
+// returned: the profile is saved, and SyncSellerSummaries repairs the row.
+func saveSellerSummary(ctx context.Context, p *client.SellerProfile) {
+	s := p.Summary()
+	if !IsMongoEnabled() {
+		summaries.put(s)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+	doc, err := ToBsonM(s)
+	if err == nil {
+		_, err = MongoDB.database.Collection(COLLECTION_SELLER_SUMMARIES).ReplaceOne(ctx, bson.M{"gluser_id": s.GluserID}, doc, options.Replace().SetUpsert(true))
+	}
+	if err != nil {
+		log.Printf("⚠️ Failed to save seller summary %s: %v", s.GluserID, err)
+	}
+}
+
+// removeSellerSummary drops a deleted seller's summary
+func removeSellerSummary(ctx context.Context, gluserID string) error {
+	summaries.remove(gluserID)
+	if !IsMongoEnabled() {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+	if _, err := MongoDB.database.Collection(COLLECTION_SELLER_SUMMARIES).DeleteOne(ctx, bson.M{"gluser_id": gluserID}); err != nil {
+		return fmt.Errorf("failed to delete seller summary: %w", err)
+	}
+	return nil
+}
+
+// SyncSellerSummaries writes the summaries of seller profiles, returning how
+// many it wrote. Unless full, it only writes them when seller_summaries and
+// seller_profiles differ in size. Without MongoDB it builds the in-memory
+// index if it isn't built yet.
+func SyncSellerSummaries(ctx context.Context, full bool) (int, error) {
+	if !IsMongoEnabled() {
+		if _, err := summaries.match(SellerQuery{}); err != nil {
+			return 0, err
+		}
+		return 0, nil
+	}
+
+	db := MongoDB.database
+	if !full {
+		countCtx, cancel := context.WithTimeout(ctx, queryTimeout)
+		defer cancel()
+		have, err := db.Collection(COLLECTION_SELLER_SUMMARIES).EstimatedDocumentCount(countCtx)
+		if err != nil {
+			return 0, err
+		}
+		want, err := db.Collection(COLLECTION_PROFILES).EstimatedDocumentCount(countCtx)
+		if err != nil {
+			return 0, err
+		}
+		if have == want {
+			return 0, nil
+		}
+	}
+
+	// Issues and history aren't needed: current_status has the issue count
+	opts := options.Find().SetProjection(bson.M{"call_history": 0, "trends": 0, "contact_attempts": 0})
+	cursor, err := db.Collection(COLLECTION_PROFILES).Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	written := 0
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			continue
+		}
+		p, err := decodeProfileDoc(doc)
+		if err != nil || p.GluserID == "" {
+			continue
+		}
+		saveSellerSummary(ctx, p)
+		written++
+	}
+	if err := cursor.Err(); err != nil {
+		return written, err
+	}
+	if written > 0 {
+		log.Printf("📇 Wrote %d seller summaries", written)
+	}
+	return written, nil
+}
+
+// ==================== SELLER SUMMARIES (MongoDB) ====================
+
+func querySellerSummariesFromMongo(ctx context.Context, q SellerQuery) (*client.SellerPage, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	collection := MongoDB.database.Collection(COLLECTION_SELLER_SUMMARIES)
+	filter := sellerSummaryFilter(q)
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	var attention int64
+	switch {
+	case q.NeedsAttention == nil:
+		withAttention := bson.M{"needs_attention": true}
+		for k, v := range filter {
+			withAttention[k] = v
+		}
+		if attention, err = collection.CountDocuments(ctx, withAttention); err != nil {
+			return nil, err
+		}
+	case *q.NeedsAttention:
+		attention = total
+	}
+
+	opts := options.Find().
+		SetSort(sellerSummarySort(q)).
+		SetSkip(int64((q.Page - 1) * q.PageSize)).
+		SetLimit(int64(q.PageSize))
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	page := &client.SellerPage{
+		Sellers:             []client.SellerSummary{},
+		TotalCount:          int(total),
+		NeedsAttentionCount: int(attention),
+		Page:                q.Page,
+		PageSize:            q.PageSize,
+	}
+	for cursor.Next(ctx) {
+		s, err := decodeSellerSummary(cursor.Current)
+		if err != nil {
+			continue
+		}
+		page.Sellers = append(page.Sellers, *s)
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+	page.Count = len(page.Sellers)
+	page.HasMore = (q.Page-1)*q.PageSize+page.Count < page.TotalCount
+	return page, nil
+}
+
+// sellerSummaryFilter translates a query into a seller_summaries filter
+func sellerSummaryFilter(q SellerQuery) bson.M {
+	filter := bson.M{}
+	if q.Prefix != "" {
+		filter["gluser_id"] = bson.M{"$regex": "^" + regexp.QuoteMeta(q.Prefix)}
+	}
+	for field, v := range map[string]string{"customer_type": q.CustomerType, "journey_stage": q.Stage, "churn_risk": q.ChurnRisk, "health_label": q.HealthLabel} {
+		if v != "" {
+			filter[field] = v
+		}
+	}
+	for field, v := range map[string]string{"city_name": q.City, "vertical": q.Vertical} {
+		if v != "" {
+			filter[field] = bson.M{"$regex": "^" + regexp.QuoteMeta(v) + "$", "$options": "i"}
+		}
+	}
+	if q.NeedsAttention != nil {
+		filter["needs_attention"] = *q.NeedsAttention
+	}
+	for name, v := range q.CustomFields {
+		filter["custom_fields."+name] = v
+	}
+	return filter
+}
+
+func sellerSummarySort(q SellerQuery) bson.D {
+	switch q.Sort {
+	case client.SellerSortHealth:
+		return bson.D{{Key: "health_score", Value: 1}, {Key: "gluser_id", Value: 1}}
+	case client.SellerSortOpenIssues:
+		return bson.D{{Key: "open_issues", Value: -1}, {Key: "gluser_id", Value: 1}}
+	default:
+		return bson.D{{Key: "last_call_at", Value: -1}, {Key: "gluser_id", Value: 1}}
+	}
+}
+
+func decodeSellerSummary(raw bson.Raw) (*client.SellerSummary, error) {
+	var doc bson.M
+	if err := bson.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	var s client.SellerSummary
+	if err := json.Unmarshal(b, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// ==================== SELLER SUMMARIES (local) ====================
+
+// summaryIndex holds every seller's summary in memory when MongoDB isn't
+// used, loaded from the profile files on first use
+type summaryIndex struct {
+	mu     sync.Mutex
+	loaded bool
+	rows   map[string]client.SellerSummary
+}
+
+var summaries = &summaryIndex{}
+
+// match returns the summaries matching q, in its order
+func (x *summaryIndex) match(q SellerQuery) ([]client.SellerSummary, error) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if !x.loaded {
+		if err := x.load(); err != nil {
+			return nil, err
+		}
+	}
+	var matched []client.SellerSummary
+	for _, s := range x.rows {
+		if q.matches(&s) {
+			matched = append(matched, s)
+		}
+	}
+	q.sortSummaries(matched)
+	return matched, nil
+}
+
+func (x *summaryIndex) load() error {
+	files, err := filepath.Glob(filepath.Join(config.PROFILES_DIR, "seller_*.json"))
+	if err != nil {
+		return err
+	}
+	x.rows = make(map[string]client.SellerSummary, len(files))
+	for _, f := range files {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			continue
+		}
+		var p client.SellerProfile
+		if err := json.Unmarshal(b, &p); err != nil || p.GluserID == "" {
+			continue // Skip corrupt files
+		}
+		x.rows[p.GluserID] = p.Summary()
+	}
+	x.loaded = true
+	return nil
+}
+
+// put records a saved profile's summary; before the index is loaded, the
+// load reads it from the file instead
+func (x *summaryIndex) put(s client.SellerSummary) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if x.loaded {
+		x.rows[s.GluserID] = s
+	}
+}
+
+func (x *summaryIndex) remove(gluserID string) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	delete(x.rows, gluserID)
+}
+
+// reset drops the index, to be reloaded from the files on next use
+func (x *summaryIndex) reset() {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.loaded, x.rows = false, nil
+}
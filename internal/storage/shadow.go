@@ -0,0 +1,168 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ==================== SHADOW ANALYSES ====================
+// Candidate analyses of sampled calls, kept apart from the real ones for
+// comparison. With MongoDB they're in shadow_analyses, otherwise one JSON
+// file per call under SHADOW_DIR/{date}, dated by the call. A call's latest
+// shadow analysis replaces any earlier one. They're evaluation data rather
+// than derived from transcripts, so WipeDerivedData leaves them alone.
+
+// SaveShadowAnalysis stores a call's shadow analysis - MongoDB first, local fallback
+func SaveShadowAnalysis(ctx context.Context, sa *client.ShadowAnalysis) error {
+	if IsMongoEnabled() {
+		return saveShadowAnalysisToMongo(ctx, sa)
+	}
+	b, err := json.MarshalIndent(sa, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal shadow analysis: %w", err)
+	}
+	// A rewritten transcript can date the call differently
+	for _, old := range shadowFiles(sa.CallID) {
+		os.Remove(old)
+	}
+	path := filepath.Join(config.SHADOW_DIR, config.BusinessDate(sa.Timestamp), Sanitize(sa.CallID)+".json")
+	return writeFile(path, b, 0644)
+}
+
+// LoadShadowAnalysis returns a call's shadow analysis, nil if it has none - MongoDB first, local fallback
+func LoadShadowAnalysis(ctx context.Context, callID string) (*client.ShadowAnalysis, error) {
+	if IsMongoEnabled() {
+		return getShadowAnalysisFromMongo(ctx, callID)
+	}
+	for _, f := range shadowFiles(callID) {
+		sa, err := readShadowFile(f)
+		if err != nil {
+			return nil, err
+		}
+		if sa != nil && sa.CallID == callID {
+			return sa, nil
+		}
+	}
+	return nil, nil
+}
+
+// LoadShadowAnalyses returns the shadow analyses of calls on the dates from
+// to to (inclusive), oldest call first - MongoDB first, local fallback
+func LoadShadowAnalyses(ctx context.Context, from, to time.Time) ([]client.ShadowAnalysis, error) {
+	var list []client.ShadowAnalysis
+	if IsMongoEnabled() {
+		var err error
+		if list, err = getShadowAnalysesFromMongo(ctx, from, to); err != nil {
+			return nil, err
+		}
+	} else {
+		for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+			dir := filepath.Join(config.SHADOW_DIR, d.Format(config.DateLayout))
+			files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+			if err != nil {
+				return nil, err
+			}
+			for _, f := range files {
+				sa, err := readShadowFile(f)
+				if err != nil {
+					return nil, err
+				}
+				if sa != nil {
+					list = append(list, *sa)
+				}
+			}
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Timestamp.Before(list[j].Timestamp) })
+	return list, nil
+}
+
+func shadowFiles(callID string) []string {
+	files, _ := filepath.Glob(filepath.Join(config.SHADOW_DIR, "*", Sanitize(callID)+".json"))
+	return files
+}
+
+// readShadowFile reads a shadow analysis file, nil if it's corrupt
+func readShadowFile(path string) (*client.ShadowAnalysis, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var sa client.ShadowAnalysis
+	if err := json.Unmarshal(b, &sa); err != nil {
+		return nil, nil
+	}
+	return &sa, nil
+}
+
+// ==================== SHADOW ANALYSES (MongoDB) ====================
+
+func saveShadowAnalysisToMongo(ctx context.Context, sa *client.ShadowAnalysis) error {
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	doc, err := ToBsonM(sa)
+	if err != nil {
+		return fmt.Errorf("failed to marshal shadow analysis: %w", err)
+	}
+
+	opts := options.Replace().SetUpsert(true)
+	if _, err := MongoDB.database.Collection(COLLECTION_SHADOW).ReplaceOne(ctx, bson.M{"call_id": sa.CallID}, doc, opts); err != nil {
+		return fmt.Errorf("failed to save shadow analysis to MongoDB: %w", err)
+	}
+	return nil
+}
+
+func getShadowAnalysisFromMongo(ctx context.Context, callID string) (*client.ShadowAnalysis, error) {
+	list, err := findShadowAnalyses(ctx, opTimeout, bson.M{"call_id": callID})
+	if err != nil || len(list) == 0 {
+		return nil, err
+	}
+	return &list[0], nil
+}
+
+func getShadowAnalysesFromMongo(ctx context.Context, from, to time.Time) ([]client.ShadowAnalysis, error) {
+	start, _ := config.ParseBusinessDate(from.Format(config.DateLayout))
+	end, _ := config.ParseBusinessDate(to.AddDate(0, 0, 1).Format(config.DateLayout))
+	return findShadowAnalyses(ctx, queryTimeout, bson.M{"timestamp": bson.M{"$gte": start.Format(time.RFC3339), "$lt": end.Format(time.RFC3339)}})
+}
+
+func findShadowAnalyses(ctx context.Context, timeout time.Duration, filter bson.M) ([]client.ShadowAnalysis, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cursor, err := MongoDB.database.Collection(COLLECTION_SHADOW).Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	list := []client.ShadowAnalysis{}
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			continue
+		}
+		jsonBytes, err := json.Marshal(doc)
+		if err != nil {
+			continue
+		}
+		var sa client.ShadowAnalysis
+		if err := json.Unmarshal(jsonBytes, &sa); err != nil {
+			continue
+		}
+		list = append(list, sa)
+	}
+	return list, cursor.Err()
+}
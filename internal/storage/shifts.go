@@ -0,0 +1,131 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/chaos"
+	"im-ai-voice/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ==================== SHIFT AGGREGATES ====================
+// Sub-daily aggregates, one per date and shift (see aggregate.Shifts). With
+// MongoDB they're in shift_aggregates, otherwise under AGGREGATES_DIR/shifts,
+// which WipeDerivedData clears along with the daily aggregates.
+
+var shiftAggregatesDir = filepath.Join(config.AGGREGATES_DIR, "shifts")
+
+// SaveShiftAggregate stores a shift aggregate, replacing the date's earlier one - MongoDB first, local fallback
+func SaveShiftAggregate(ctx context.Context, agg *client.DailyAggregate) error {
+	if IsMongoEnabled() {
+		return saveShiftAggregateToMongo(ctx, agg)
+	}
+	b, err := json.MarshalIndent(agg, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal shift aggregate: %w", err)
+	}
+	if err := os.MkdirAll(shiftAggregatesDir, 0755); err != nil {
+		return err
+	}
+	return writeFile(shiftAggregatePath(agg.Date, agg.Shift), b, 0644)
+}
+
+// LoadShiftAggregates returns a date's shift aggregates, earliest shift first - MongoDB first, local fallback
+func LoadShiftAggregates(ctx context.Context, date string) ([]client.DailyAggregate, error) {
+	var aggs []client.DailyAggregate
+	if IsMongoEnabled() {
+		var err error
+		if aggs, err = getShiftAggregatesFromMongo(ctx, date); err != nil {
+			return nil, err
+		}
+	} else {
+		files, err := filepath.Glob(filepath.Join(shiftAggregatesDir, Sanitize(date)+".*.aggregate.json"))
+		if err != nil {
+			return nil, err
+		}
+		aggs = make([]client.DailyAggregate, 0, len(files))
+		for _, f := range files {
+			b, err := os.ReadFile(f)
+			if err != nil {
+				return nil, err
+			}
+			var agg client.DailyAggregate
+			if err := json.Unmarshal(b, &agg); err != nil {
+				continue // Skip corrupt files
+			}
+			aggs = append(aggs, agg)
+		}
+	}
+	sort.Slice(aggs, func(i, j int) bool {
+		if aggs[i].WindowStart == nil || aggs[j].WindowStart == nil {
+			return aggs[i].Shift < aggs[j].Shift
+		}
+		return aggs[i].WindowStart.Before(*aggs[j].WindowStart)
+	})
+	return aggs, nil
+}
+
+func shiftAggregatePath(date, shift string) string {
+	return filepath.Join(shiftAggregatesDir, fmt.Sprintf("%s.%s.aggregate.json", Sanitize(date), Sanitize(strings.ToLower(shift))))
+}
+
+// ==================== SHIFT AGGREGATES (MongoDB) ====================
+
+func saveShiftAggregateToMongo(ctx context.Context, agg *client.DailyAggregate) error {
+	if err := chaos.MongoWriteError("save shift aggregate " + agg.Date + " " + agg.Shift); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	doc, err := ToBsonM(agg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal shift aggregate: %w", err)
+	}
+
+	filter := bson.M{"date": agg.Date, "shift": agg.Shift}
+	opts := options.Replace().SetUpsert(true)
+	if _, err := MongoDB.database.Collection(COLLECTION_SHIFT_AGGS).ReplaceOne(ctx, filter, doc, opts); err != nil {
+		return fmt.Errorf("failed to save shift aggregate to MongoDB: %w", err)
+	}
+	return nil
+}
+
+func getShiftAggregatesFromMongo(ctx context.Context, date string) ([]client.DailyAggregate, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	cursor, err := MongoDB.database.Collection(COLLECTION_SHIFT_AGGS).Find(ctx, bson.M{"date": date})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	aggs := []client.DailyAggregate{}
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			continue
+		}
+		jsonBytes, err := json.Marshal(doc)
+		if err != nil {
+			continue
+		}
+		var agg client.DailyAggregate
+		if err := json.Unmarshal(jsonBytes, &agg); err != nil {
+			continue
+		}
+		aggs = append(aggs, agg)
+	}
+	return aggs, cursor.Err()
+}
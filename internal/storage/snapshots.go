@@ -0,0 +1,109 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/chaos"
+	"im-ai-voice/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ==================== DASHBOARD SNAPSHOTS ====================
+// A date's aggregate and tickets as first computed once its day was over,
+// so numbers quoted from a dashboard can be reproduced after the date is
+// re-aggregated or its calls reprocessed. A snapshot is written once and
+// never replaced: with MongoDB to dashboard_snapshots, whose unique index
+// on date refuses a second; otherwise to a file under SNAPSHOTS_DIR created
+// exclusively. WipeDerivedData leaves them alone, as they're the record of
+// what was reported.
+
+// SaveDashboardSnapshot stores a date's snapshot unless it has one,
+// reporting whether it was stored - MongoDB first, local fallback
+func SaveDashboardSnapshot(ctx context.Context, snap *client.DashboardSnapshot) (bool, error) {
+	if IsMongoEnabled() {
+		ctx, cancel := context.WithTimeout(ctx, opTimeout)
+		defer cancel()
+		doc, err := ToBsonM(snap)
+		if err != nil {
+			return false, fmt.Errorf("failed to marshal dashboard snapshot: %w", err)
+		}
+		_, err = MongoDB.database.Collection(COLLECTION_SNAPSHOTS).InsertOne(ctx, doc)
+		if mongo.IsDuplicateKeyError(err) {
+			return false, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("failed to save dashboard snapshot to MongoDB: %w", err)
+		}
+		return true, nil
+	}
+
+	b, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal dashboard snapshot: %w", err)
+	}
+	chaos.DelayDisk()
+	f, err := os.OpenFile(snapshotPath(snap.Date), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if errors.Is(err, os.ErrExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		os.Remove(f.Name()) // Half a snapshot would be kept for good
+		return false, err
+	}
+	return true, f.Close()
+}
+
+// LoadDashboardSnapshot returns a date's snapshot, nil if it has none -
+// MongoDB first, local fallback
+func LoadDashboardSnapshot(ctx context.Context, date string) (*client.DashboardSnapshot, error) {
+	if IsMongoEnabled() {
+		ctx, cancel := context.WithTimeout(ctx, opTimeout)
+		defer cancel()
+		var doc bson.M
+		err := MongoDB.database.Collection(COLLECTION_SNAPSHOTS).FindOne(ctx, bson.M{"date": date}).Decode(&doc)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		b, err := json.Marshal(doc)
+		if err != nil {
+			return nil, err
+		}
+		var snap client.DashboardSnapshot
+		if err := json.Unmarshal(b, &snap); err != nil {
+			return nil, fmt.Errorf("failed to decode dashboard snapshot: %w", err)
+		}
+		return &snap, nil
+	}
+
+	b, err := os.ReadFile(snapshotPath(date))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var snap client.DashboardSnapshot
+	if err := json.Unmarshal(b, &snap); err != nil {
+		return nil, fmt.Errorf("failed to decode dashboard snapshot: %w", err)
+	}
+	return &snap, nil
+}
+
+func snapshotPath(date string) string {
+	return filepath.Join(config.SNAPSHOTS_DIR, Sanitize(date)+".snapshot.json")
+}
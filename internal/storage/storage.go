@@ -0,0 +1,453 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// ==================== INITIALIZATION ====================
+
+// InitStorageDirs ensures all storage directories exist and can be written
+// to, failing with the setting to change when one can't
+func InitStorageDirs() error {
+	for _, d := range config.DataDirs {
+		if err := os.MkdirAll(d.Path, 0755); err != nil {
+			return fmt.Errorf("failed to create directory %s: %w (set STORAGE_BASE, or %s for this directory alone, to a writable path)", d.Path, err, d.Env)
+		}
+		if err := checkWritable(d.Path); err != nil {
+			return fmt.Errorf("directory %s is not writable: %w (mount a writable volume there, or set STORAGE_BASE or %s to one)", d.Path, err, d.Env)
+		}
+	}
+	return nil
+}
+
+// checkWritable creates and removes a file in dir
+func checkWritable(dir string) error {
+	f, err := os.CreateTemp(dir, ".write-check-*")
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
+
+// ==================== TRANSCRIPT STORAGE ====================
+
+// SaveRawTranscript saves a raw transcript to disk
+func SaveRawTranscript(rt client.RawTranscript) (string, error) {
+	if rt.CallID == "" {
+		rt.CallID = generateCallID()
+	}
+	if rt.Timestamp.IsZero() {
+		rt.Timestamp = time.Now()
+	}
+
+	b, err := json.MarshalIndent(rt, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal transcript: %w", err)
+	}
+
+	path := filepath.Join(config.TRANSCRIPTS_DIR, rt.CallID+".json")
+	if err := writeFile(path, b, 0644); err != nil {
+		return "", fmt.Errorf("failed to write transcript: %w", err)
+	}
+
+	return rt.CallID, nil
+}
+
+// LoadRawTranscript loads a transcript by call ID, from TRANSCRIPTS_DIR or,
+// once the watcher moved it, PROCESSED_DIR
+func LoadRawTranscript(callID string) (*client.RawTranscript, error) {
+	path := filepath.Join(config.TRANSCRIPTS_DIR, callID+".json")
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		if moved, _ := filepath.Glob(filepath.Join(config.PROCESSED_DIR, "*", callID+".json")); len(moved) > 0 {
+			b, err = os.ReadFile(moved[len(moved)-1]) // The latest date
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transcript %s: %w", callID, err)
+	}
+
+	var rt client.RawTranscript
+	if err := json.Unmarshal(b, &rt); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal transcript: %w", err)
+	}
+
+	return &rt, nil
+}
+
+// ListTranscriptIDs returns all transcript IDs
+func ListTranscriptIDs() ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(config.TRANSCRIPTS_DIR, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(files))
+	for _, f := range files {
+		id := strings.TrimSuffix(filepath.Base(f), ".json")
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// ==================== ANALYSIS STORAGE ====================
+
+// SaveAnalysis saves an analysis result to disk
+func SaveAnalysis(ctx context.Context, ar client.AnalysisResult) error {
+	if ar.CallID == "" {
+		return fmt.Errorf("empty call id")
+	}
+
+	ar, raw := splitLLMRaw(ar)
+	saveSplitLLMRaw(ctx, raw)
+
+	path := filepath.Join(config.ANALYSIS_DIR, ar.CallID+".analysis.json")
+	return writeAnalysisFile(path, ar)
+}
+
+// LoadAnalysis loads an analysis by call ID, saved by SaveAnalysis or,
+// under its seller's file name, by SaveAnalysisWithGluserID
+func LoadAnalysis(callID string) (*client.AnalysisResult, error) {
+	path := filepath.Join(config.ANALYSIS_DIR, callID+".analysis.json")
+	b, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		pattern := filepath.Join(config.ANALYSIS_DIR, "gluser_*_call_"+callID+".analysis.json")
+		if matches, _ := filepath.Glob(pattern); len(matches) > 0 {
+			b, err = os.ReadFile(matches[0])
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var ar client.AnalysisResult
+	if err := json.Unmarshal(b, &ar); err != nil {
+		return nil, err
+	}
+
+	return &ar, nil
+}
+
+// AnalysisExists checks if a local analysis file exists for a call, under
+// either of the names LoadAnalysis reads
+func AnalysisExists(callID string) bool {
+	path := filepath.Join(config.ANALYSIS_DIR, callID+".analysis.json")
+	if _, err := os.Stat(path); err == nil {
+		return true
+	}
+	matches, _ := filepath.Glob(filepath.Join(config.ANALYSIS_DIR, "gluser_*_call_"+callID+".analysis.json"))
+	return len(matches) > 0
+}
+
+// ListAnalysisFiles returns all analysis file paths
+func ListAnalysisFiles() ([]string, error) {
+	return filepath.Glob(filepath.Join(config.ANALYSIS_DIR, "*.analysis.json"))
+}
+
+// LoadAllAnalysisForDate loads all analysis results for a specific date
+func LoadAllAnalysisForDate(date string) ([]client.AnalysisResult, error) {
+	files, err := ListAnalysisFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	var results []client.AnalysisResult
+	for _, f := range files {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			continue
+		}
+
+		var ar client.AnalysisResult
+		if err := json.Unmarshal(b, &ar); err != nil {
+			continue
+		}
+
+		// Filter by date
+		if AggregationDate(&ar) == date {
+			results = append(results, ar)
+		}
+	}
+
+	return results, nil
+}
+
+// LoadAnalysesBefore loads all local analyses with a timestamp before cutoff,
+// along with the file path of each (keyed by call ID) for later removal
+func LoadAnalysesBefore(cutoff time.Time) ([]client.AnalysisResult, map[string]string, error) {
+	files, err := ListAnalysisFiles()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var results []client.AnalysisResult
+	paths := make(map[string]string)
+	for _, f := range files {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			continue
+		}
+
+		var ar client.AnalysisResult
+		if err := json.Unmarshal(b, &ar); err != nil {
+			continue
+		}
+
+		if ar.Timestamp.Before(cutoff) {
+			results = append(results, ar)
+			paths[ar.CallID] = f
+		}
+	}
+
+	return results, paths, nil
+}
+
+// ==================== AGGREGATE STORAGE ====================
+
+// SaveAggregate saves a daily aggregate to disk
+func SaveAggregate(agg client.DailyAggregate) error {
+	b, err := json.MarshalIndent(agg, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal aggregate: %w", err)
+	}
+
+	path := filepath.Join(config.AGGREGATES_DIR, agg.Date+".aggregate.json")
+	defer dashboards.invalidate(agg.Date)
+	return writeFile(path, b, 0644)
+}
+
+// LoadAggregate loads a daily aggregate by date
+func LoadAggregate(date string) (*client.DailyAggregate, error) {
+	path := filepath.Join(config.AGGREGATES_DIR, date+".aggregate.json")
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var agg client.DailyAggregate
+	if err := json.Unmarshal(b, &agg); err != nil {
+		return nil, err
+	}
+
+	return &agg, nil
+}
+
+// SetAggregateStale marks a date's aggregate stale since the given time, or
+// fresh again with nil. It only touches the aggregate if it's still the one
+// built from fingerprint, so it can't undo an aggregation that ran meanwhile -
+// MongoDB first, local fallback.
+func SetAggregateStale(ctx context.Context, date, fingerprint string, since *time.Time) error {
+	defer dashboards.invalidate(date)
+	if IsMongoEnabled() {
+		ctx, cancel := context.WithTimeout(ctx, opTimeout)
+		defer cancel()
+		update := bson.M{"$unset": bson.M{"stale": "", "stale_since": ""}}
+		if since != nil {
+			update = bson.M{"$set": bson.M{"stale": true, "stale_since": since.Format(time.RFC3339Nano)}}
+		}
+		filter := bson.M{"date": date, "input_fingerprint": fingerprint}
+		if _, err := MongoDB.database.Collection(COLLECTION_AGGREGATES).UpdateOne(ctx, filter, update); err != nil {
+			return fmt.Errorf("failed to update aggregate staleness: %w", err)
+		}
+		return nil
+	}
+
+	agg, err := LoadAggregate(date)
+	if err != nil {
+		return err
+	}
+	if agg.InputFingerprint != fingerprint {
+		return nil
+	}
+	agg.Stale, agg.StaleSince = since != nil, since
+	return SaveAggregate(*agg)
+}
+
+// ListAggregates returns all available aggregate dates (sorted, newest first)
+func ListAggregates() ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(config.AGGREGATES_DIR, "*.aggregate.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	dates := make([]string, 0, len(files))
+	for _, f := range files {
+		date := strings.TrimSuffix(filepath.Base(f), ".aggregate.json")
+		dates = append(dates, date)
+	}
+
+	// Sort descending (newest first)
+	sort.Sort(sort.Reverse(sort.StringSlice(dates)))
+	return dates, nil
+}
+
+// ==================== TICKET STORAGE ====================
+
+// SaveTicket saves a ticket to disk
+func SaveTicket(ticket client.Ticket) error {
+	// Create date-specific directory
+	dateDir := filepath.Join(config.TICKETS_DIR, ticket.Date)
+	if err := os.MkdirAll(dateDir, 0755); err != nil {
+		return fmt.Errorf("failed to create ticket directory: %w", err)
+	}
+
+	b, err := json.MarshalIndent(ticket, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal ticket: %w", err)
+	}
+
+	path := filepath.Join(dateDir, ticket.TicketID+".json")
+	defer dashboards.invalidate(ticket.Date)
+	return writeFile(path, b, 0644)
+}
+
+// LoadTicket loads a ticket by ID and date
+func LoadTicket(date, ticketID string) (*client.Ticket, error) {
+	path := filepath.Join(config.TICKETS_DIR, date, ticketID+".json")
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var ticket client.Ticket
+	if err := json.Unmarshal(b, &ticket); err != nil {
+		return nil, err
+	}
+
+	return &ticket, nil
+}
+
+// LoadTicketsForDate loads all tickets for a specific date
+func LoadTicketsForDate(date string) ([]client.Ticket, error) {
+	dateDir := filepath.Join(config.TICKETS_DIR, date)
+	files, err := filepath.Glob(filepath.Join(dateDir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	tickets := make([]client.Ticket, 0, len(files))
+	for _, f := range files {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			continue
+		}
+
+		var ticket client.Ticket
+		if err := json.Unmarshal(b, &ticket); err != nil {
+			continue
+		}
+
+		tickets = append(tickets, ticket)
+	}
+
+	// Sort by priority
+	sort.Slice(tickets, func(i, j int) bool {
+		return tickets[i].Priority < tickets[j].Priority
+	})
+
+	return tickets, nil
+}
+
+// ListTicketDates returns all dates that have tickets
+func ListTicketDates() ([]string, error) {
+	entries, err := os.ReadDir(config.TICKETS_DIR)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []string{}, nil
+		}
+		return nil, err
+	}
+
+	dates := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if e.IsDir() {
+			dates = append(dates, e.Name())
+		}
+	}
+
+	// Sort descending (newest first)
+	sort.Sort(sort.Reverse(sort.StringSlice(dates)))
+	return dates, nil
+}
+
+// LoadTicketsThrough loads the tickets of every date up to and including
+// date - MongoDB first
+func LoadTicketsThrough(ctx context.Context, date string) ([]client.Ticket, error) {
+	if IsMongoEnabled() {
+		tickets, err := GetTicketsThroughFromMongo(ctx, date)
+		if err == nil && len(tickets) > 0 {
+			return tickets, nil
+		}
+		if err != nil {
+			log.Printf("⚠️ MongoDB load failed, falling back to local: %v", err)
+		}
+	}
+
+	dates, err := ListTicketDates()
+	if err != nil {
+		return nil, err
+	}
+	var tickets []client.Ticket
+	for _, d := range dates {
+		if d > date {
+			continue
+		}
+		dayTickets, err := LoadTicketsForDate(d)
+		if err != nil {
+			return nil, err
+		}
+		tickets = append(tickets, dayTickets...)
+	}
+	return tickets, nil
+}
+
+// ==================== MAINTENANCE ====================
+
+// WipeDerivedData removes analyses, profiles and their summaries, seller metrics, aggregates (daily and shift) and tickets, trashed ones included (MongoDB and local)
+func WipeDerivedData(ctx context.Context) error {
+	defer profiles.purge()
+	defer knownSellers.reset()
+	defer summaries.reset()
+	defer dashboards.purge()
+
+	if IsMongoEnabled() {
+		for _, coll := range []string{COLLECTION_ANALYSES, COLLECTION_PROFILES, COLLECTION_SELLER_SUMMARIES, COLLECTION_ISSUES, COLLECTION_AGGREGATES, COLLECTION_SHIFT_AGGS, COLLECTION_TICKETS, COLLECTION_SYSTEMIC, COLLECTION_THEMES, COLLECTION_TRASH} {
+			res, err := MongoDB.database.Collection(coll).DeleteMany(ctx, bson.M{})
+			if err != nil {
+				return fmt.Errorf("failed to clear %s: %w", coll, err)
+			}
+			log.Printf("   🗑️ Cleared %s: %d documents", coll, res.DeletedCount)
+		}
+		if err := resetSellerMetricsCollection(ctx); err != nil {
+			return fmt.Errorf("failed to clear %s: %w", COLLECTION_SELLER_METRICS, err)
+		}
+		log.Printf("   🗑️ Cleared %s", COLLECTION_SELLER_METRICS)
+	}
+
+	for _, dir := range []string{config.ANALYSIS_DIR, config.PROFILES_DIR, config.AGGREGATES_DIR, config.TICKETS_DIR, config.METRICS_DIR, config.SYSTEMIC_DIR, config.THEMES_DIR, config.TRASH_DIR} {
+		if err := os.RemoveAll(dir); err != nil {
+			return fmt.Errorf("failed to clear %s: %w", dir, err)
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	return nil
+}
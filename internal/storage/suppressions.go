@@ -0,0 +1,139 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ==================== TICKET SUPPRESSIONS ====================
+// Mute rules for ticket generation, applied at each aggregation. With
+// MongoDB they're in ticket_suppressions, otherwise one JSON file per rule
+// under SUPPRESSIONS_DIR. They're configuration rather than derived data,
+// so WipeDerivedData leaves them alone.
+
+// SaveTicketSuppression stores a rule, replacing one with the same ID - MongoDB first, local fallback
+func SaveTicketSuppression(ctx context.Context, s *client.TicketSuppression) error {
+	if IsMongoEnabled() {
+		return saveTicketSuppressionToMongo(ctx, s)
+	}
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal ticket suppression: %w", err)
+	}
+	return writeFile(ticketSuppressionPath(s.ID), b, 0644)
+}
+
+// LoadTicketSuppressions returns every rule, oldest first - MongoDB first, local fallback
+func LoadTicketSuppressions(ctx context.Context) ([]client.TicketSuppression, error) {
+	var rules []client.TicketSuppression
+	if IsMongoEnabled() {
+		var err error
+		if rules, err = getTicketSuppressionsFromMongo(ctx); err != nil {
+			return nil, err
+		}
+	} else {
+		entries, err := os.ReadDir(config.SUPPRESSIONS_DIR)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		rules = make([]client.TicketSuppression, 0, len(entries))
+		for _, e := range entries {
+			if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+				continue
+			}
+			b, err := os.ReadFile(filepath.Join(config.SUPPRESSIONS_DIR, e.Name()))
+			if err != nil {
+				return nil, err
+			}
+			var rule client.TicketSuppression
+			if err := json.Unmarshal(b, &rule); err != nil {
+				continue // Skip corrupt files
+			}
+			rules = append(rules, rule)
+		}
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID }) // ULIDs, so by creation
+	return rules, nil
+}
+
+// DeleteTicketSuppression removes a rule, reporting whether it existed - MongoDB first, local fallback
+func DeleteTicketSuppression(ctx context.Context, id string) (bool, error) {
+	if IsMongoEnabled() {
+		ctx, cancel := context.WithTimeout(ctx, opTimeout)
+		defer cancel()
+		res, err := MongoDB.database.Collection(COLLECTION_SUPPRESSIONS).DeleteOne(ctx, bson.M{"id": id})
+		if err != nil {
+			return false, err
+		}
+		return res.DeletedCount > 0, nil
+	}
+	if err := os.Remove(ticketSuppressionPath(id)); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func ticketSuppressionPath(id string) string {
+	return filepath.Join(config.SUPPRESSIONS_DIR, fmt.Sprintf("suppression_%s.json", Sanitize(id)))
+}
+
+// ==================== TICKET SUPPRESSIONS (MongoDB) ====================
+
+func saveTicketSuppressionToMongo(ctx context.Context, s *client.TicketSuppression) error {
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	doc, err := ToBsonM(s)
+	if err != nil {
+		return fmt.Errorf("failed to marshal ticket suppression: %w", err)
+	}
+
+	opts := options.Replace().SetUpsert(true)
+	if _, err := MongoDB.database.Collection(COLLECTION_SUPPRESSIONS).ReplaceOne(ctx, bson.M{"id": s.ID}, doc, opts); err != nil {
+		return fmt.Errorf("failed to save ticket suppression to MongoDB: %w", err)
+	}
+	return nil
+}
+
+func getTicketSuppressionsFromMongo(ctx context.Context) ([]client.TicketSuppression, error) {
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	cursor, err := MongoDB.database.Collection(COLLECTION_SUPPRESSIONS).Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	rules := []client.TicketSuppression{}
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			continue
+		}
+		jsonBytes, err := json.Marshal(doc)
+		if err != nil {
+			continue
+		}
+		var rule client.TicketSuppression
+		if err := json.Unmarshal(jsonBytes, &rule); err != nil {
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	return rules, cursor.Err()
+}
@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// ==================== SYSTEMIC ISSUES ====================
+// Each clustering run replaces the whole set. With MongoDB they're in
+// systemic_issues, otherwise in one JSON file under SYSTEMIC_DIR.
+
+var systemicIssuesFile = filepath.Join(config.SYSTEMIC_DIR, "systemic_issues.json")
+
+// SaveSystemicIssues replaces the stored systemic issues - MongoDB first, local fallback
+func SaveSystemicIssues(ctx context.Context, issues []client.SystemicIssue) error {
+	if IsMongoEnabled() {
+		return saveSystemicIssuesToMongo(ctx, issues)
+	}
+	b, err := json.MarshalIndent(issues, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal systemic issues: %w", err)
+	}
+	return writeFile(systemicIssuesFile, b, 0644)
+}
+
+// LoadSystemicIssues returns the stored systemic issues, most sellers first - MongoDB first, local fallback
+func LoadSystemicIssues(ctx context.Context) ([]client.SystemicIssue, error) {
+	var issues []client.SystemicIssue
+	if IsMongoEnabled() {
+		var err error
+		if issues, err = getSystemicIssuesFromMongo(ctx); err != nil {
+			return nil, err
+		}
+	} else {
+		b, err := os.ReadFile(systemicIssuesFile)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return []client.SystemicIssue{}, nil
+			}
+			return nil, err
+		}
+		if err := json.Unmarshal(b, &issues); err != nil {
+			return nil, fmt.Errorf("failed to parse systemic issues: %w", err)
+		}
+	}
+	sort.SliceStable(issues, func(i, j int) bool { return issues[i].SellerCount > issues[j].SellerCount })
+	return issues, nil
+}
+
+// ==================== SYSTEMIC ISSUES (MongoDB) ====================
+
+func saveSystemicIssuesToMongo(ctx context.Context, issues []client.SystemicIssue) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	docs := make([]interface{}, 0, len(issues))
+	for _, issue := range issues {
+		doc, err := ToBsonM(issue)
+		if err != nil {
+			return fmt.Errorf("failed to marshal systemic issue %s: %w", issue.ID, err)
+		}
+		docs = append(docs, doc)
+	}
+
+	collection := MongoDB.database.Collection(COLLECTION_SYSTEMIC)
+	if _, err := collection.DeleteMany(ctx, bson.M{}); err != nil {
+		return fmt.Errorf("failed to clear systemic issues: %w", err)
+	}
+	if len(docs) == 0 {
+		return nil
+	}
+	if _, err := collection.InsertMany(ctx, docs); err != nil {
+		return fmt.Errorf("failed to save systemic issues to MongoDB: %w", err)
+	}
+	return nil
+}
+
+func getSystemicIssuesFromMongo(ctx context.Context) ([]client.SystemicIssue, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	cursor, err := MongoDB.database.Collection(COLLECTION_SYSTEMIC).Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	issues := []client.SystemicIssue{}
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			continue
+		}
+		jsonBytes, err := json.Marshal(doc)
+		if err != nil {
+			continue
+		}
+		var issue client.SystemicIssue
+		if err := json.Unmarshal(jsonBytes, &issue); err != nil {
+			continue
+		}
+		issues = append(issues, issue)
+	}
+	return issues, cursor.Err()
+}
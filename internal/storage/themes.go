@@ -0,0 +1,126 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ==================== KEY INSIGHT THEMES ====================
+// One report per week, keyed by the week's last date. With MongoDB they're
+// in insight_themes, otherwise themes_{date}.json under THEMES_DIR.
+
+// SaveThemeReport stores a week's themes, replacing any for the same week - MongoDB first, local fallback
+func SaveThemeReport(ctx context.Context, r *client.ThemeReport) error {
+	if IsMongoEnabled() {
+		return saveThemeReportToMongo(ctx, r)
+	}
+	b, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal theme report: %w", err)
+	}
+	return writeFile(themeReportPath(r.To), b, 0644)
+}
+
+// LoadThemeReport returns the themes of the week ending on date, nil if there are none - MongoDB first, local fallback
+func LoadThemeReport(ctx context.Context, date string) (*client.ThemeReport, error) {
+	if IsMongoEnabled() {
+		return getThemeReportFromMongo(ctx, bson.M{"to": date})
+	}
+	return readThemeReport(themeReportPath(date))
+}
+
+// LoadLatestThemeReport returns the most recent week's themes, nil if there are none
+func LoadLatestThemeReport(ctx context.Context) (*client.ThemeReport, error) {
+	if IsMongoEnabled() {
+		return getThemeReportFromMongo(ctx, bson.M{})
+	}
+	entries, err := os.ReadDir(config.THEMES_DIR)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	latest := ""
+	for _, e := range entries {
+		if name := e.Name(); strings.HasPrefix(name, "themes_") && strings.HasSuffix(name, ".json") && name > latest {
+			latest = name
+		}
+	}
+	if latest == "" {
+		return nil, nil
+	}
+	return readThemeReport(filepath.Join(config.THEMES_DIR, latest))
+}
+
+func themeReportPath(date string) string {
+	return filepath.Join(config.THEMES_DIR, fmt.Sprintf("themes_%s.json", Sanitize(date)))
+}
+
+func readThemeReport(path string) (*client.ThemeReport, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var r client.ThemeReport
+	if err := json.Unmarshal(b, &r); err != nil {
+		return nil, fmt.Errorf("failed to parse theme report %s: %w", filepath.Base(path), err)
+	}
+	return &r, nil
+}
+
+// ==================== KEY INSIGHT THEMES (MongoDB) ====================
+
+func saveThemeReportToMongo(ctx context.Context, r *client.ThemeReport) error {
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	doc, err := ToBsonM(r)
+	if err != nil {
+		return fmt.Errorf("failed to marshal theme report: %w", err)
+	}
+
+	opts := options.Replace().SetUpsert(true)
+	if _, err := MongoDB.database.Collection(COLLECTION_THEMES).ReplaceOne(ctx, bson.M{"to": r.To}, doc, opts); err != nil {
+		return fmt.Errorf("failed to save theme report to MongoDB: %w", err)
+	}
+	return nil
+}
+
+// getThemeReportFromMongo returns the latest report matching filter, nil if none does
+func getThemeReportFromMongo(ctx context.Context, filter bson.M) (*client.ThemeReport, error) {
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	var doc bson.M
+	opts := options.FindOne().SetSort(bson.D{{Key: "to", Value: -1}})
+	if err := MongoDB.database.Collection(COLLECTION_THEMES).FindOne(ctx, filter, opts).Decode(&doc); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	jsonBytes, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	var r client.ThemeReport
+	if err := json.Unmarshal(jsonBytes, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
@@ -0,0 +1,261 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ==================== TRASH ====================
+// Soft-deleted analyses and tickets. Deleting one moves it out of its
+// collection into trash, stamped with deleted_at, so every reader stops
+// seeing it without filtering; restoring moves it back. The retention job
+// purges items past their purge_at. With MongoDB they're in trash, otherwise
+// one JSON file per item under TRASH_DIR.
+
+// SaveTrashItem stores a deleted item - MongoDB first, local fallback
+func SaveTrashItem(ctx context.Context, item *client.TrashItem) error {
+	if IsMongoEnabled() {
+		return saveTrashItemToMongo(ctx, item)
+	}
+	b, err := json.MarshalIndent(item, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal trash item: %w", err)
+	}
+	return writeFile(trashItemPath(item.Kind, item.ID), b, 0644)
+}
+
+// LoadTrashItem returns a deleted item, nil if it isn't in the trash - MongoDB first, local fallback
+func LoadTrashItem(ctx context.Context, kind, id string) (*client.TrashItem, error) {
+	if IsMongoEnabled() {
+		ctx, cancel := context.WithTimeout(ctx, opTimeout)
+		defer cancel()
+		var doc bson.M
+		err := MongoDB.database.Collection(COLLECTION_TRASH).FindOne(ctx, bson.M{"kind": kind, "id": id}).Decode(&doc)
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return trashItemFromDoc(doc)
+	}
+
+	b, err := os.ReadFile(trashItemPath(kind, id))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var item client.TrashItem
+	if err := json.Unmarshal(b, &item); err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// ListTrash returns the deleted items of a kind ("" for all), most recently
+// deleted first - MongoDB first, local fallback
+func ListTrash(ctx context.Context, kind string) ([]client.TrashItem, error) {
+	var items []client.TrashItem
+	if IsMongoEnabled() {
+		var err error
+		if items, err = getTrashFromMongo(ctx, kind); err != nil {
+			return nil, err
+		}
+	} else {
+		entries, err := os.ReadDir(config.TRASH_DIR)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		items = make([]client.TrashItem, 0, len(entries))
+		for _, e := range entries {
+			if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") || (kind != "" && !strings.HasPrefix(e.Name(), kind+"_")) {
+				continue
+			}
+			b, err := os.ReadFile(filepath.Join(config.TRASH_DIR, e.Name()))
+			if err != nil {
+				return nil, err
+			}
+			var item client.TrashItem
+			if err := json.Unmarshal(b, &item); err != nil {
+				continue // Skip corrupt files
+			}
+			items = append(items, item)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].DeletedAt.After(items[j].DeletedAt) })
+	return items, nil
+}
+
+// DeleteTrashItem removes an item from the trash, reporting whether it was
+// there - MongoDB first, local fallback
+func DeleteTrashItem(ctx context.Context, kind, id string) (bool, error) {
+	if IsMongoEnabled() {
+		ctx, cancel := context.WithTimeout(ctx, opTimeout)
+		defer cancel()
+		res, err := MongoDB.database.Collection(COLLECTION_TRASH).DeleteOne(ctx, bson.M{"kind": kind, "id": id})
+		if err != nil {
+			return false, err
+		}
+		return res.DeletedCount > 0, nil
+	}
+	if err := os.Remove(trashItemPath(kind, id)); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func trashItemPath(kind, id string) string {
+	return filepath.Join(config.TRASH_DIR, fmt.Sprintf("%s_%s.json", kind, Sanitize(id)))
+}
+
+// RemoveAnalysis deletes a call's analysis from MongoDB and local files,
+// leaving its raw LLM responses, extraction and transcript
+func RemoveAnalysis(ctx context.Context, ar *client.AnalysisResult) error {
+	if IsMongoEnabled() {
+		if _, err := DeleteAnalysesFromMongo(ctx, []string{ar.CallID}); err != nil {
+			return fmt.Errorf("failed to delete analysis from MongoDB: %w", err)
+		}
+	}
+	for _, name := range []string{fmt.Sprintf("gluser_%s_call_%s", ar.SellerID, ar.CallID), ar.CallID} {
+		path := filepath.Join(config.ANALYSIS_DIR, name+".analysis.json")
+		if err := removeAnalysisFile(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to delete analysis file: %w", err)
+		}
+	}
+	return nil
+}
+
+// RemoveTicket deletes a ticket from MongoDB and local files
+func RemoveTicket(ctx context.Context, date, ticketID string) error {
+	defer dashboards.invalidate(date)
+	if IsMongoEnabled() {
+		ctx, cancel := context.WithTimeout(ctx, opTimeout)
+		defer cancel()
+		if _, err := MongoDB.database.Collection(COLLECTION_TICKETS).DeleteOne(ctx, bson.M{"date": date, "ticket_id": ticketID}); err != nil {
+			return fmt.Errorf("failed to delete ticket from MongoDB: %w", err)
+		}
+	}
+	if err := os.Remove(filepath.Join(config.TICKETS_DIR, date, ticketID+".json")); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete ticket file: %w", err)
+	}
+	return nil
+}
+
+// RemoveAggregate deletes a date's aggregate from MongoDB and local files
+func RemoveAggregate(ctx context.Context, date string) error {
+	defer dashboards.invalidate(date)
+	if IsMongoEnabled() {
+		ctx, cancel := context.WithTimeout(ctx, opTimeout)
+		defer cancel()
+		if _, err := MongoDB.database.Collection(COLLECTION_AGGREGATES).DeleteOne(ctx, bson.M{"date": date}); err != nil {
+			return fmt.Errorf("failed to delete aggregate from MongoDB: %w", err)
+		}
+	}
+	if err := os.Remove(filepath.Join(config.AGGREGATES_DIR, date+".aggregate.json")); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete aggregate file: %w", err)
+	}
+	return nil
+}
+
+// RemoveSellerProfile deletes a seller's profile, summary and tracked issues
+// from MongoDB and local files, leaving their metrics and analyses
+func RemoveSellerProfile(ctx context.Context, gluserID string) error {
+	defer profiles.invalidate(gluserID)
+	if IsMongoEnabled() {
+		ctx, cancel := context.WithTimeout(ctx, opTimeout)
+		defer cancel()
+		for _, coll := range []string{COLLECTION_ISSUES, COLLECTION_PROFILES} {
+			if _, err := MongoDB.database.Collection(coll).DeleteMany(ctx, bson.M{"gluser_id": gluserID}); err != nil {
+				return fmt.Errorf("failed to delete from %s: %w", coll, err)
+			}
+		}
+	}
+	if err := os.Remove(filepath.Join(config.PROFILES_DIR, fmt.Sprintf("seller_%s.json", gluserID))); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete profile file: %w", err)
+	}
+	return removeSellerSummary(ctx, gluserID)
+}
+
+// RemoveRawTranscript deletes a transcript not yet moved to PROCESSED_DIR
+func RemoveRawTranscript(callID string) error {
+	if err := os.Remove(filepath.Join(config.TRANSCRIPTS_DIR, callID+".json")); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete transcript file: %w", err)
+	}
+	return nil
+}
+
+// ==================== TRASH (MongoDB) ====================
+
+func saveTrashItemToMongo(ctx context.Context, item *client.TrashItem) error {
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	doc, err := ToBsonM(item)
+	if err != nil {
+		return fmt.Errorf("failed to marshal trash item: %w", err)
+	}
+
+	opts := options.Replace().SetUpsert(true)
+	if _, err := MongoDB.database.Collection(COLLECTION_TRASH).ReplaceOne(ctx, bson.M{"kind": item.Kind, "id": item.ID}, doc, opts); err != nil {
+		return fmt.Errorf("failed to save trash item to MongoDB: %w", err)
+	}
+	return nil
+}
+
+func getTrashFromMongo(ctx context.Context, kind string) ([]client.TrashItem, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	filter := bson.M{}
+	if kind != "" {
+		filter["kind"] = kind
+	}
+	cursor, err := MongoDB.database.Collection(COLLECTION_TRASH).Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	items := []client.TrashItem{}
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			continue
+		}
+		item, err := trashItemFromDoc(doc)
+		if err != nil {
+			continue
+		}
+		items = append(items, *item)
+	}
+	return items, cursor.Err()
+}
+
+func trashItemFromDoc(doc bson.M) (*client.TrashItem, error) {
+	jsonBytes, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	var item client.TrashItem
+	if err := json.Unmarshal(jsonBytes, &item); err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"time"
+	"unicode"
+
+	"im-ai-voice/internal/chaos"
+	"im-ai-voice/internal/config"
+)
+
+func generateCallID() string {
+	return fmt.Sprintf("call_%s", time.Now().UTC().Format("20060102T150405Z"))
+}
+
+func Sanitize(s string) string {
+	out := make([]rune, 0, len(s))
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			out = append(out, r)
+		} else {
+			out = append(out, '_')
+		}
+	}
+	return string(out)
+}
+
+func timeNowDate() string {
+	return config.Today()
+}
+
+// writeFile is os.WriteFile for local storage, slowed by the slow_disk fault
+func writeFile(name string, data []byte, perm os.FileMode) error {
+	chaos.DelayDisk()
+	return os.WriteFile(name, data, perm)
+}
+
+// writeFileAtomic is writeFile through a temporary file next to name,
+// renamed over it, so readers never see a partly written file
+func writeFileAtomic(name string, data []byte, perm os.FileMode) error {
+	tmp := name + ".tmp"
+	if err := writeFile(tmp, data, perm); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, name); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// openAppend opens a local JSONL file for appending, slowed like writeFile
+func openAppend(name string, perm os.FileMode) (*os.File, error) {
+	chaos.DelayDisk()
+	return os.OpenFile(name, os.O_CREATE|os.O_APPEND|os.O_WRONLY, perm)
+}
@@ -0,0 +1,134 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ==================== SCHEMA VALIDATION ====================
+// JSON-schema validators on the core collections reject malformed documents
+// from buggy writers before they reach the dashboards. Documents are written
+// through ToBsonM, so times are strings and numbers doubles; the schemas only
+// check what's required and the enums. Validation is "moderate": documents
+// already in the collection that don't match can still be updated.
+// MONGO_SCHEMA_VALIDATION picks the action: error (default) rejects the
+// write, warn only logs it in MongoDB's log, off removes the validators.
+
+var (
+	severityEnum  = bson.A{"low", "medium", "high", "critical"}
+	sentimentEnum = bson.A{"Positive", "Neutral", "Negative", ""} // Empty for blocked and unparsed analyses
+	ticketEnum    = bson.A{"open", "in_progress", "resolved"}
+)
+
+// collectionSchemas are the $jsonSchema validators by collection
+var collectionSchemas = map[string]bson.M{
+	COLLECTION_ANALYSES: {
+		"bsonType": "object",
+		"required": bson.A{"call_id", "seller_id", "timestamp", "analyzed_at"},
+		"properties": bson.M{
+			"call_id":   bson.M{"bsonType": "string", "minLength": 1},
+			"seller_id": bson.M{"bsonType": "string"},
+			"intent": bson.M{
+				"bsonType":   "object",
+				"properties": bson.M{"sentiment": bson.M{"enum": sentimentEnum}},
+			},
+			"issues": bson.M{
+				"bsonType": bson.A{"array", "null"},
+				"items": bson.M{
+					"bsonType":   "object",
+					"required":   bson.A{"problem", "bucket", "severity"},
+					"properties": bson.M{"severity": bson.M{"enum": severityEnum}},
+				},
+			},
+		},
+	},
+	COLLECTION_PROFILES: {
+		"bsonType": "object",
+		"required": bson.A{"gluser_id", "current_status", "total_calls"},
+		"properties": bson.M{
+			"gluser_id":   bson.M{"bsonType": "string", "minLength": 1},
+			"total_calls": bson.M{"bsonType": "number", "minimum": 0},
+			"current_status": bson.M{
+				"bsonType":   "object",
+				"properties": bson.M{"sentiment": bson.M{"enum": sentimentEnum}},
+			},
+		},
+	},
+	COLLECTION_TICKETS: {
+		"bsonType": "object",
+		"required": bson.A{"ticket_id", "date", "feature_bucket", "severity", "status"},
+		"properties": bson.M{
+			"ticket_id": bson.M{"bsonType": "string", "minLength": 1},
+			"date":      bson.M{"bsonType": "string", "pattern": `^\d{4}-\d{2}-\d{2}$`},
+			"severity":  bson.M{"enum": severityEnum},
+			"status":    bson.M{"enum": ticketEnum},
+		},
+	},
+	COLLECTION_AGGREGATES: {
+		"bsonType": "object",
+		"required": bson.A{"date", "total_calls", "total_issues"},
+		"properties": bson.M{
+			"date":         bson.M{"bsonType": "string", "pattern": `^\d{4}-\d{2}-\d{2}$`},
+			"total_calls":  bson.M{"bsonType": "number", "minimum": 0},
+			"total_issues": bson.M{"bsonType": "number", "minimum": 0},
+		},
+	},
+}
+
+// applySchemaValidation sets the validators on the core collections,
+// creating the collections that don't exist yet. Failures (e.g. a user
+// without collMod rights) are logged, not fatal.
+func applySchemaValidation(ctx context.Context, db *mongo.Database) {
+	action := strings.ToLower(os.Getenv("MONGO_SCHEMA_VALIDATION"))
+	switch action {
+	case "":
+		action = "error"
+	case "error", "warn", "off":
+	default:
+		log.Printf("⚠️ Invalid MONGO_SCHEMA_VALIDATION=%q, using error", action)
+		action = "error"
+	}
+
+	for name, schema := range collectionSchemas {
+		if err := setValidator(ctx, db, name, schema, action); err != nil {
+			log.Printf("⚠️  Failed to set schema validation on %s: %v", name, err)
+		}
+	}
+	if action != "off" {
+		log.Printf("   Schema validation on %d collections (action: %s)", len(collectionSchemas), action)
+	}
+}
+
+func setValidator(ctx context.Context, db *mongo.Database, name string, schema bson.M, action string) error {
+	validator := bson.M{"$jsonSchema": schema}
+	level := "moderate"
+	if action == "off" {
+		validator, level, action = bson.M{}, "off", "error"
+	}
+
+	cmd := bson.D{
+		{Key: "collMod", Value: name},
+		{Key: "validator", Value: validator},
+		{Key: "validationLevel", Value: level},
+		{Key: "validationAction", Value: action},
+	}
+	err := db.RunCommand(ctx, cmd).Err()
+	var cmdErr mongo.CommandError
+	if !errors.As(err, &cmdErr) || cmdErr.Name != "NamespaceNotFound" {
+		return err
+	}
+
+	opts := options.CreateCollection().SetValidator(validator).SetValidationLevel(level).SetValidationAction(action)
+	err = db.CreateCollection(ctx, name, opts)
+	if errors.As(err, &cmdErr) && cmdErr.Name == "NamespaceExists" {
+		return db.RunCommand(ctx, cmd).Err() // Created concurrently by another instance
+	}
+	return err
+}
@@ -0,0 +1,126 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"im-ai-voice/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ==================== PII VAULT ====================
+// The encrypted values behind the tokens replacing PII in transcripts, kept
+// apart from everything else. With MongoDB they're in pii_vault, otherwise
+// one file per token under VAULT_DIR, readable by the service's user only.
+// Transcripts and analyses still point at them after a wipe, so
+// WipeDerivedData leaves them alone.
+
+// VaultEntry is a token's value, sealed with AES-GCM by the vault
+type VaultEntry struct {
+	Token      string    `json:"token"`
+	Kind       string    `json:"kind"`       // EMAIL, GSTIN, PAN, PHONE, NAME
+	Ciphertext string    `json:"ciphertext"` // Base64 nonce and sealed value
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// SaveVaultEntry stores a token's value, keeping the one already stored if any - MongoDB first, local fallback
+func SaveVaultEntry(ctx context.Context, e *VaultEntry) error {
+	if IsMongoEnabled() {
+		return saveVaultEntryToMongo(ctx, e)
+	}
+	path := vaultEntryPath(e.Token)
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	b, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal vault entry: %w", err)
+	}
+	return writeFile(path, b, 0600)
+}
+
+// LoadVaultEntries returns the stored entries of tokens, skipping unknown ones - MongoDB first, local fallback
+func LoadVaultEntries(ctx context.Context, tokens []string) ([]VaultEntry, error) {
+	if IsMongoEnabled() {
+		return getVaultEntriesFromMongo(ctx, tokens)
+	}
+	entries := make([]VaultEntry, 0, len(tokens))
+	seen := make(map[string]bool, len(tokens))
+	for _, t := range tokens {
+		if seen[t] {
+			continue
+		}
+		seen[t] = true
+		b, err := os.ReadFile(vaultEntryPath(t))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		var e VaultEntry
+		if err := json.Unmarshal(b, &e); err != nil {
+			continue // Skip corrupt files
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+func vaultEntryPath(token string) string {
+	return filepath.Join(config.VAULT_DIR, fmt.Sprintf("%s.json", Sanitize(strings.Trim(token, "[]"))))
+}
+
+// ==================== PII VAULT (MongoDB) ====================
+
+func saveVaultEntryToMongo(ctx context.Context, e *VaultEntry) error {
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	doc, err := ToBsonM(e)
+	if err != nil {
+		return fmt.Errorf("failed to marshal vault entry: %w", err)
+	}
+
+	opts := options.Update().SetUpsert(true)
+	if _, err := MongoDB.database.Collection(COLLECTION_VAULT).UpdateOne(ctx, bson.M{"token": e.Token}, bson.M{"$setOnInsert": doc}, opts); err != nil {
+		return fmt.Errorf("failed to save vault entry to MongoDB: %w", err)
+	}
+	return nil
+}
+
+func getVaultEntriesFromMongo(ctx context.Context, tokens []string) ([]VaultEntry, error) {
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	cursor, err := MongoDB.database.Collection(COLLECTION_VAULT).Find(ctx, bson.M{"token": bson.M{"$in": tokens}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	entries := []VaultEntry{}
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			continue
+		}
+		jsonBytes, err := json.Marshal(doc)
+		if err != nil {
+			continue
+		}
+		var e VaultEntry
+		if err := json.Unmarshal(jsonBytes, &e); err != nil {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries, cursor.Err()
+}
@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ==================== ANALYSIS VERSIONS ====================
+// When a call's transcript is rewritten with different text, the call is
+// analyzed again and its earlier analysis is kept here. With MongoDB it's in
+// analysis_versions, otherwise one JSON file per version under VERSIONS_DIR.
+// Replays can't rebuild earlier versions from the current transcripts, so
+// WipeDerivedData leaves them alone.
+
+// SaveAnalysisVersion stores a superseded analysis - MongoDB first, local fallback
+func SaveAnalysisVersion(ctx context.Context, v *client.AnalysisVersion) error {
+	if IsMongoEnabled() {
+		return saveAnalysisVersionToMongo(ctx, v)
+	}
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal analysis version: %w", err)
+	}
+	path := filepath.Join(config.VERSIONS_DIR, fmt.Sprintf("%s_v%d.json", Sanitize(v.CallID), v.Version))
+	return writeFile(path, b, 0644)
+}
+
+// LoadAnalysisVersions returns a call's superseded analyses, oldest first - MongoDB first, local fallback
+func LoadAnalysisVersions(ctx context.Context, callID string) ([]client.AnalysisVersion, error) {
+	var versions []client.AnalysisVersion
+	if IsMongoEnabled() {
+		var err error
+		if versions, err = getAnalysisVersionsFromMongo(ctx, callID); err != nil {
+			return nil, err
+		}
+	} else {
+		files, err := filepath.Glob(filepath.Join(config.VERSIONS_DIR, Sanitize(callID)+"_v*.json"))
+		if err != nil {
+			return nil, err
+		}
+		versions = make([]client.AnalysisVersion, 0, len(files))
+		for _, f := range files {
+			b, err := os.ReadFile(f)
+			if err != nil {
+				return nil, err
+			}
+			var v client.AnalysisVersion
+			if err := json.Unmarshal(b, &v); err != nil || v.CallID != callID {
+				continue // Skip corrupt files, and calls whose ID sanitizes the same
+			}
+			versions = append(versions, v)
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i].Version < versions[j].Version })
+	return versions, nil
+}
+
+// ==================== ANALYSIS VERSIONS (MongoDB) ====================
+
+func saveAnalysisVersionToMongo(ctx context.Context, v *client.AnalysisVersion) error {
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	doc, err := ToBsonM(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal analysis version: %w", err)
+	}
+
+	filter := bson.M{"call_id": v.CallID, "version": v.Version}
+	opts := options.Replace().SetUpsert(true)
+	if _, err := MongoDB.database.Collection(COLLECTION_VERSIONS).ReplaceOne(ctx, filter, doc, opts); err != nil {
+		return fmt.Errorf("failed to save analysis version to MongoDB: %w", err)
+	}
+	return nil
+}
+
+func getAnalysisVersionsFromMongo(ctx context.Context, callID string) ([]client.AnalysisVersion, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	cursor, err := MongoDB.database.Collection(COLLECTION_VERSIONS).Find(ctx, bson.M{"call_id": callID})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	versions := []client.AnalysisVersion{}
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			continue
+		}
+		jsonBytes, err := json.Marshal(doc)
+		if err != nil {
+			continue
+		}
+		var v client.AnalysisVersion
+		if err := json.Unmarshal(jsonBytes, &v); err != nil {
+			continue
+		}
+		versions = append(versions, v)
+	}
+	return versions, cursor.Err()
+}
@@ -0,0 +1,139 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ==================== WEBHOOK SUBSCRIPTIONS ====================
+// Consumers of seller profile transitions, read whenever a call changes a
+// seller's state. With MongoDB they're in webhook_subscriptions, otherwise
+// one JSON file per subscription under WEBHOOKS_DIR. They're configuration
+// rather than derived data, so WipeDerivedData leaves them alone.
+
+// SaveWebhookSubscription stores a subscription, replacing one with the same ID - MongoDB first, local fallback
+func SaveWebhookSubscription(ctx context.Context, s *client.WebhookSubscription) error {
+	if IsMongoEnabled() {
+		return saveWebhookSubscriptionToMongo(ctx, s)
+	}
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook subscription: %w", err)
+	}
+	return writeFile(webhookSubscriptionPath(s.ID), b, 0644)
+}
+
+// LoadWebhookSubscriptions returns every subscription, oldest first - MongoDB first, local fallback
+func LoadWebhookSubscriptions(ctx context.Context) ([]client.WebhookSubscription, error) {
+	var subs []client.WebhookSubscription
+	if IsMongoEnabled() {
+		var err error
+		if subs, err = getWebhookSubscriptionsFromMongo(ctx); err != nil {
+			return nil, err
+		}
+	} else {
+		entries, err := os.ReadDir(config.WEBHOOKS_DIR)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		subs = make([]client.WebhookSubscription, 0, len(entries))
+		for _, e := range entries {
+			if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+				continue
+			}
+			b, err := os.ReadFile(filepath.Join(config.WEBHOOKS_DIR, e.Name()))
+			if err != nil {
+				return nil, err
+			}
+			var sub client.WebhookSubscription
+			if err := json.Unmarshal(b, &sub); err != nil {
+				continue // Skip corrupt files
+			}
+			subs = append(subs, sub)
+		}
+	}
+	sort.Slice(subs, func(i, j int) bool { return subs[i].ID < subs[j].ID }) // ULIDs, so by creation
+	return subs, nil
+}
+
+// DeleteWebhookSubscription removes a subscription, reporting whether it existed - MongoDB first, local fallback
+func DeleteWebhookSubscription(ctx context.Context, id string) (bool, error) {
+	if IsMongoEnabled() {
+		ctx, cancel := context.WithTimeout(ctx, opTimeout)
+		defer cancel()
+		res, err := MongoDB.database.Collection(COLLECTION_WEBHOOKS).DeleteOne(ctx, bson.M{"id": id})
+		if err != nil {
+			return false, err
+		}
+		return res.DeletedCount > 0, nil
+	}
+	if err := os.Remove(webhookSubscriptionPath(id)); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func webhookSubscriptionPath(id string) string {
+	return filepath.Join(config.WEBHOOKS_DIR, fmt.Sprintf("webhook_%s.json", Sanitize(id)))
+}
+
+// ==================== WEBHOOK SUBSCRIPTIONS (MongoDB) ====================
+
+func saveWebhookSubscriptionToMongo(ctx context.Context, s *client.WebhookSubscription) error {
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	doc, err := ToBsonM(s)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook subscription: %w", err)
+	}
+
+	opts := options.Replace().SetUpsert(true)
+	if _, err := MongoDB.database.Collection(COLLECTION_WEBHOOKS).ReplaceOne(ctx, bson.M{"id": s.ID}, doc, opts); err != nil {
+		return fmt.Errorf("failed to save webhook subscription to MongoDB: %w", err)
+	}
+	return nil
+}
+
+func getWebhookSubscriptionsFromMongo(ctx context.Context) ([]client.WebhookSubscription, error) {
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	cursor, err := MongoDB.database.Collection(COLLECTION_WEBHOOKS).Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	subs := []client.WebhookSubscription{}
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			continue
+		}
+		jsonBytes, err := json.Marshal(doc)
+		if err != nil {
+			continue
+		}
+		var sub client.WebhookSubscription
+		if err := json.Unmarshal(jsonBytes, &sub); err != nil {
+			continue
+		}
+		subs = append(subs, sub)
+	}
+	return subs, cursor.Err()
+}
@@ -0,0 +1,80 @@
+package ticket
+
+import (
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+)
+
+// ==================== TREND EVIDENCE ====================
+// Each generated ticket carries its bucket's recent history as chart-ready
+// series, so ticketing systems can show whether the problem is new, steady
+// or growing without querying this service.
+
+// AttachEvidence adds trend charts to tickets. history holds the aggregates
+// of the TICKET_EVIDENCE_DAYS days ending on the tickets' date, oldest first,
+// with nil for days that have no aggregate.
+func AttachEvidence(tickets []client.Ticket, dates []string, history []*client.DailyAggregate) {
+	for i := range tickets {
+		tickets[i].Evidence = []client.TicketChart{
+			bucketCountChart(tickets[i].FeatureBucket, dates, history),
+			affectedSellersChart(tickets[i].FeatureBucket, dates, history),
+		}
+	}
+}
+
+// EvidenceDates returns the TICKET_EVIDENCE_DAYS business days ending on date, oldest first
+func EvidenceDates(date string) ([]string, error) {
+	end, err := config.ParseBusinessDate(date)
+	if err != nil {
+		return nil, err
+	}
+	dates := make([]string, config.TICKET_EVIDENCE_DAYS)
+	for i := range dates {
+		dates[i] = end.AddDate(0, 0, i-len(dates)+1).Format(config.DateLayout)
+	}
+	return dates, nil
+}
+
+func bucketCountChart(bucket string, dates []string, history []*client.DailyAggregate) client.TicketChart {
+	chart := client.TicketChart{
+		Chart:  client.ChartBucketCount,
+		Title:  bucket + " issues per day",
+		Kind:   "bar",
+		Unit:   "issues",
+		Points: make([]client.ChartPoint, len(dates)),
+	}
+	for i, date := range dates {
+		chart.Points[i].Date = date
+		if history[i] == nil {
+			chart.Points[i].NoData = true
+			continue
+		}
+		chart.Points[i].Value = float64(history[i].FeatureBuckets[bucket].TotalCount)
+	}
+	return chart
+}
+
+// affectedSellersChart counts distinct sellers reporting the bucket from the
+// start of the window up to each day, so growth shows as a rising line
+func affectedSellersChart(bucket string, dates []string, history []*client.DailyAggregate) client.TicketChart {
+	chart := client.TicketChart{
+		Chart:  client.ChartAffectedSellers,
+		Title:  "Sellers affected by " + bucket + " (cumulative)",
+		Kind:   "line",
+		Unit:   "sellers",
+		Points: make([]client.ChartPoint, len(dates)),
+	}
+	seen := make(map[string]bool)
+	for i, date := range dates {
+		chart.Points[i].Date = date
+		if history[i] == nil {
+			chart.Points[i].NoData = true
+		} else {
+			for _, id := range history[i].FeatureBuckets[bucket].AffectedSellerIDs {
+				seen[id] = true
+			}
+		}
+		chart.Points[i].Value = float64(len(seen)) // Carried across days without data
+	}
+	return chart
+}
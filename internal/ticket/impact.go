@@ -0,0 +1,165 @@
+package ticket
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/aggregate"
+	"im-ai-voice/internal/config"
+)
+
+// ==================== REVENUE IMPACT ====================
+// A problem hitting three Leader sellers costs more than one hitting ten
+// free listings, so tickets are ranked by the revenue they put at risk
+// rather than by issue counts. Each affected seller's annual plan value is
+// weighted by their churn risk (IMPACT_CHURN_WEIGHTS) and summed. Plan
+// values come from the plan catalog by customer tier: CATALOG at the MDC
+// price, STAR and LEADER at Star Pro's and Leader Pro's (unpriced unless
+// UPSELL_SKU_VALUES prices them), FREE at nothing. PLAN_VALUES sets them per
+// customer type instead, e.g. from the CRM's billing:
+//
+//	PLAN_VALUES="LEADER=240000,STAR=120000,TSCATALOG=50000"
+//
+// Sellers without a plan value are counted as unvalued and left out.
+// TICKET_RANKING=count ranks tickets by severity and issue count as before.
+
+// SellerValue is what the impact model needs of an affected seller's profile
+type SellerValue struct {
+	CustomerType string
+	ChurnRisk    string // low, medium, high; medium when unknown
+}
+
+// tierSKUs are the catalog plans a customer tier pays for
+var tierSKUs = map[string]string{client.TierCatalog: "mdc", client.TierStar: "star_pro", client.TierLeader: "leader_pro"}
+
+var (
+	planValues   = loadPlanValues()
+	churnWeights = loadChurnWeights()
+)
+
+// loadPlanValues reads PLAN_VALUES, keyed by upper-cased customer type
+func loadPlanValues() map[string]int {
+	values := make(map[string]int)
+	for _, entry := range strings.Split(os.Getenv("PLAN_VALUES"), ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		customerType, v, _ := strings.Cut(entry, "=")
+		rupees, err := strconv.Atoi(strings.TrimSpace(v))
+		customerType = strings.ToUpper(strings.TrimSpace(customerType))
+		if err != nil || rupees < 0 || customerType == "" {
+			log.Printf("⚠️ Ignoring malformed PLAN_VALUES entry %q (want customer_type=rupees)", entry)
+			continue
+		}
+		values[customerType] = rupees
+	}
+	return values
+}
+
+// loadChurnWeights reads IMPACT_CHURN_WEIGHTS, falling back to the default
+// for any churn risk it leaves out
+func loadChurnWeights() map[string]float64 {
+	weights := make(map[string]float64)
+	for _, spec := range []string{config.DEFAULT_IMPACT_CHURN_WEIGHTS, os.Getenv("IMPACT_CHURN_WEIGHTS")} {
+		for _, entry := range strings.Split(spec, ",") {
+			if strings.TrimSpace(entry) == "" {
+				continue
+			}
+			risk, v, _ := strings.Cut(entry, "=")
+			w, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil || w < 0 || w > 1 {
+				log.Printf("⚠️ Ignoring malformed IMPACT_CHURN_WEIGHTS entry %q (want risk=0-1)", entry)
+				continue
+			}
+			weights[strings.ToLower(strings.TrimSpace(risk))] = w
+		}
+	}
+	return weights
+}
+
+// PlanValue returns a customer type's annual plan value in rupees, and
+// whether it's known
+func PlanValue(customerType string) (int, bool) {
+	if v, ok := planValues[strings.ToUpper(strings.TrimSpace(customerType))]; ok {
+		return v, true
+	}
+	tier := aggregate.CustomerTier(customerType)
+	if tier == client.TierFree {
+		return 0, true
+	}
+	if p, ok := config.ProductBySKU(tierSKUs[tier]); ok && p.AnnualPrice > 0 {
+		return p.AnnualPrice, true
+	}
+	return 0, false
+}
+
+// Impact estimates the revenue the sellers put at risk; nil without values,
+// when impact isn't being estimated
+func Impact(sellerIDs []string, values map[string]SellerValue) *client.RevenueImpact {
+	if values == nil {
+		return nil
+	}
+	impact := &client.RevenueImpact{}
+	atRisk := 0.0
+	for _, id := range sellerIDs {
+		v, ok := values[id]
+		if !ok {
+			impact.UnvaluedSellers++
+			continue
+		}
+		plan, ok := PlanValue(v.CustomerType)
+		if !ok {
+			impact.UnvaluedSellers++
+			continue
+		}
+		weight, ok := churnWeights[strings.ToLower(v.ChurnRisk)]
+		if !ok {
+			weight = churnWeights["medium"]
+		}
+		impact.ValuedSellers++
+		impact.PlanValue += plan
+		atRisk += float64(plan) * weight
+	}
+	impact.RevenueAtRisk = int(math.Round(atRisk))
+	return impact
+}
+
+// RankByImpact reports whether tickets are ranked by revenue at risk
+// (TICKET_RANKING)
+func RankByImpact() bool {
+	ranking := os.Getenv("TICKET_RANKING")
+	switch ranking {
+	case "":
+		return config.DEFAULT_TICKET_RANKING == "impact"
+	case "impact", "count":
+		return ranking == "impact"
+	}
+	log.Printf("⚠️ Invalid TICKET_RANKING=%q, using %s", ranking, config.DEFAULT_TICKET_RANKING)
+	return config.DEFAULT_TICKET_RANKING == "impact"
+}
+
+// revenueAtRisk is an impact's rupees at risk, 0 when there's none
+func revenueAtRisk(impact *client.RevenueImpact) int {
+	if impact == nil {
+		return 0
+	}
+	return impact.RevenueAtRisk
+}
+
+// impactLine is a ticket description's summary line for its impact, empty
+// without one
+func impactLine(impact *client.RevenueImpact) string {
+	if impact == nil || impact.ValuedSellers == 0 {
+		return ""
+	}
+	line := fmt.Sprintf("- **Revenue at Risk:** Rs %d of Rs %d in annual plans (%d sellers valued", impact.RevenueAtRisk, impact.PlanValue, impact.ValuedSellers)
+	if impact.UnvaluedSellers > 0 {
+		line += fmt.Sprintf(", %d unknown", impact.UnvaluedSellers)
+	}
+	return line + ")\n"
+}
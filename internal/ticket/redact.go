@@ -0,0 +1,138 @@
+package ticket
+
+import (
+	"log"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/vault"
+)
+
+// ==================== EXAMPLE REDACTION ====================
+// Tickets quote the problems and actionable summaries of the calls behind
+// them, which sometimes name the seller or give a phone number. Tickets are
+// forwarded to external vendors, so their text is scrubbed of phone
+// numbers, email addresses, names introduced by an honorific or "ji", and
+// TICKET_REDACT_TERMS, and examples are capped in number and length.
+
+// examplePolicy decides what of the calls' text goes into tickets
+type examplePolicy struct {
+	MaxExamples int  // Examples per ticket; a bucket's ticket has at most the 3 its aggregate keeps
+	MaxChars    int  // Characters per example before it's cut, 0 for no limit
+	Redact      bool // Mask personal details in examples, problems and titles
+	terms       *regexp.Regexp
+}
+
+var (
+	namePatterns = []*regexp.Regexp{
+		regexp.MustCompile(`\b(?:Mr|Mrs|Ms|Miss|Dr|Shri|Shree|Sri|Smt)\.?\s+[A-Z][a-z]+(?:\s+[A-Z][a-z]+)?`),
+		regexp.MustCompile(`\b[A-Z][a-z]+\s+[Jj]i\b`),
+	}
+	namedPattern = regexp.MustCompile(`(\b(?i:name is|named|called)\s+)[A-Z][a-z]+(?:\s+[A-Z][a-z]+)?`)
+)
+
+var examples = examplePolicyFromEnv()
+
+// examplePolicyFromEnv reads TICKET_EXAMPLES, TICKET_EXAMPLE_MAX_CHARS,
+// TICKET_REDACT and TICKET_REDACT_TERMS
+func examplePolicyFromEnv() examplePolicy {
+	p := examplePolicy{
+		MaxExamples: config.DEFAULT_TICKET_EXAMPLES,
+		MaxChars:    config.DEFAULT_TICKET_EXAMPLE_MAX_CHARS,
+		Redact:      true,
+	}
+	if v := os.Getenv("TICKET_EXAMPLES"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			p.MaxExamples = n
+		} else {
+			log.Printf("⚠️ Invalid TICKET_EXAMPLES=%q, using %d", v, p.MaxExamples)
+		}
+	}
+	if v := os.Getenv("TICKET_EXAMPLE_MAX_CHARS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			p.MaxChars = n
+		} else {
+			log.Printf("⚠️ Invalid TICKET_EXAMPLE_MAX_CHARS=%q, using %d", v, p.MaxChars)
+		}
+	}
+	if v := os.Getenv("TICKET_REDACT"); v != "" {
+		if redact, err := strconv.ParseBool(v); err == nil {
+			p.Redact = redact
+		} else {
+			log.Printf("⚠️ Invalid TICKET_REDACT=%q, using %t", v, p.Redact)
+		}
+	}
+	var terms []string
+	for _, t := range strings.Split(os.Getenv("TICKET_REDACT_TERMS"), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			terms = append(terms, regexp.QuoteMeta(t))
+		}
+	}
+	if len(terms) > 0 {
+		p.terms = regexp.MustCompile(`(?i)` + strings.Join(terms, "|"))
+	}
+	return p
+}
+
+// scrub masks personal details in text when redaction is on
+func (p examplePolicy) scrub(text string) string {
+	if !p.Redact {
+		return text
+	}
+	text = vault.EmailPattern.ReplaceAllString(text, "[email]")
+	text = vault.PhonePattern.ReplaceAllString(text, "[phone]")
+	text = namedPattern.ReplaceAllString(text, "${1}[name]")
+	for _, re := range namePatterns {
+		text = re.ReplaceAllString(text, "[name]")
+	}
+	if p.terms != nil {
+		text = p.terms.ReplaceAllString(text, "[redacted]")
+	}
+	return text
+}
+
+// Redact masks personal details in text as tickets' examples are masked,
+// whatever TICKET_REDACT says
+func Redact(text string) string {
+	p := examples
+	p.Redact = true
+	return p.scrub(text)
+}
+
+// examples returns up to MaxExamples scrubbed examples, each cut to MaxChars
+func (p examplePolicy) examples(list []string) []string {
+	out := make([]string, 0, min(len(list), p.MaxExamples))
+	for _, e := range list {
+		if len(out) == p.MaxExamples {
+			break
+		}
+		out = append(out, truncate(p.scrub(e), p.MaxChars))
+	}
+	return out
+}
+
+// problems returns a scrubbed copy of a ticket's top problems
+func (p examplePolicy) problems(list []client.ProblemCount) []client.ProblemCount {
+	out := make([]client.ProblemCount, len(list))
+	for i, pc := range list {
+		pc.Problem = p.scrub(pc.Problem)
+		out[i] = pc
+	}
+	return out
+}
+
+// truncate cuts s to max characters, marking the cut; max 0 leaves it whole
+func truncate(s string, max int) string {
+	r := []rune(s)
+	if max <= 0 || len(r) <= max {
+		return s
+	}
+	if max <= 3 {
+		return string(r[:max])
+	}
+	return string(r[:max-3]) + "..."
+}
@@ -0,0 +1,149 @@
+package ticket
+
+import (
+	"log"
+	"regexp"
+	"sort"
+
+	"im-ai-voice/client"
+)
+
+// ==================== TICKET SUPPRESSION ====================
+
+// Suppressions are the ticket suppression rules that apply to one date's
+// aggregation. Issues they match are left out of ticket generation and
+// counted per rule instead.
+type Suppressions struct {
+	rules    []suppressionRule
+	counts   []*client.SuppressedIssues
+	sellers  []map[string]bool
+	problems []map[string]*client.ProblemCount
+}
+
+type suppressionRule struct {
+	client.TicketSuppression
+	pattern *regexp.Regexp // nil when the rule mutes the whole bucket
+}
+
+// NewSuppressions keeps the rules active on date. Rules whose pattern
+// doesn't compile (they're checked when created) are skipped.
+func NewSuppressions(date string, rules []client.TicketSuppression) *Suppressions {
+	s := &Suppressions{}
+	for _, r := range rules {
+		if !r.ActiveOn(date) {
+			continue
+		}
+		rule := suppressionRule{TicketSuppression: r}
+		if r.Pattern != "" {
+			re, err := CompileSuppressionPattern(r.Pattern)
+			if err != nil {
+				log.Printf("⚠️ Skipping ticket suppression %s: %v", r.ID, err)
+				continue
+			}
+			rule.pattern = re
+		}
+		s.rules = append(s.rules, rule)
+		s.counts = append(s.counts, &client.SuppressedIssues{SuppressionID: r.ID, Bucket: r.Bucket, Pattern: r.Pattern})
+		s.sellers = append(s.sellers, make(map[string]bool))
+		s.problems = append(s.problems, make(map[string]*client.ProblemCount))
+	}
+	return s
+}
+
+// CompileSuppressionPattern compiles a rule's pattern, case-insensitively
+func CompileSuppressionPattern(pattern string) (*regexp.Regexp, error) {
+	if _, err := regexp.Compile(pattern); err != nil {
+		return nil, err // Without the flag, so the error quotes the pattern as given
+	}
+	return regexp.Compile("(?i)" + pattern)
+}
+
+// match returns the index of the first rule matching an issue, or -1
+func (s *Suppressions) match(bucket, problem string) int {
+	for i, r := range s.rules {
+		if r.Bucket != "" && r.Bucket != bucket {
+			continue
+		}
+		if r.pattern != nil && !r.pattern.MatchString(problem) {
+			continue
+		}
+		return i
+	}
+	return -1
+}
+
+// Analyses returns the analyses with suppressed issues removed, for
+// building the aggregate tickets are generated from. The analyses passed
+// in are left as they are.
+func (s *Suppressions) Analyses(analyses []client.AnalysisResult) []client.AnalysisResult {
+	if len(s.rules) == 0 {
+		return analyses
+	}
+	out := make([]client.AnalysisResult, len(analyses))
+	for i, a := range analyses {
+		out[i] = a
+		if a.Blocked || len(a.Issues) == 0 {
+			continue
+		}
+		kept := make([]client.Issue, 0, len(a.Issues))
+		for _, issue := range a.Issues {
+			r := s.match(issue.Bucket, issue.Problem)
+			if r < 0 {
+				kept = append(kept, issue)
+				continue
+			}
+			s.counts[r].Issues++
+			s.sellers[r][a.SellerID] = true
+			p := s.problems[r][issue.Problem]
+			if p == nil {
+				p = &client.ProblemCount{Problem: issue.Problem, Severity: issue.Severity}
+				s.problems[r][issue.Problem] = p
+			}
+			p.Count++
+		}
+		out[i].Issues = kept
+	}
+	return out
+}
+
+// Systemic returns the systemic issues no rule matches
+func (s *Suppressions) Systemic(issues []client.SystemicIssue) []client.SystemicIssue {
+	if len(s.rules) == 0 {
+		return issues
+	}
+	var kept []client.SystemicIssue
+	for _, issue := range issues {
+		if r := s.match(issue.Bucket, issue.Problem); r >= 0 {
+			s.counts[r].SystemicIssues++
+			continue
+		}
+		kept = append(kept, issue)
+	}
+	return kept
+}
+
+// Summary returns what each rule suppressed, leaving out rules that matched nothing
+func (s *Suppressions) Summary() []client.SuppressedIssues {
+	var out []client.SuppressedIssues
+	for i := range s.counts {
+		c := *s.counts[i]
+		if c.Issues == 0 && c.SystemicIssues == 0 {
+			continue
+		}
+		c.Sellers = len(s.sellers[i])
+		for _, p := range s.problems[i] {
+			c.TopProblems = append(c.TopProblems, *p)
+		}
+		sort.Slice(c.TopProblems, func(a, b int) bool {
+			if c.TopProblems[a].Count != c.TopProblems[b].Count {
+				return c.TopProblems[a].Count > c.TopProblems[b].Count
+			}
+			return c.TopProblems[a].Problem < c.TopProblems[b].Problem
+		})
+		if len(c.TopProblems) > 3 {
+			c.TopProblems = c.TopProblems[:3]
+		}
+		out = append(out, c)
+	}
+	return out
+}
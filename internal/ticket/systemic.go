@@ -0,0 +1,120 @@
+package ticket
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== SYSTEMIC ISSUE TICKETS ====================
+
+// GenerateSystemic creates a ticket for each of the largest systemic issues
+// (see aggregate.ClusterIssues), or those putting the most revenue at risk
+// when ranking by impact, numbered from priority. values are the affected
+// sellers' (see Impact). Their IDs follow the systemic issue's, so the same
+// problem keeps its ticket across days' runs as long as its oldest member
+// stays open.
+func GenerateSystemic(date string, systemic []client.SystemicIssue, priority int, values map[string]SellerValue) []client.Ticket {
+	impacts := make(map[string]*client.RevenueImpact, len(systemic))
+	for _, s := range systemic {
+		impacts[s.ID] = Impact(SystemicSellers(s), values)
+	}
+	if values != nil && RankByImpact() {
+		systemic = slices.Clone(systemic)
+		sort.SliceStable(systemic, func(i, j int) bool {
+			return revenueAtRisk(impacts[systemic[i].ID]) > revenueAtRisk(impacts[systemic[j].ID])
+		})
+	}
+
+	var tickets []client.Ticket
+	for i, s := range systemic {
+		if i >= config.SYSTEMIC_MAX_TICKETS {
+			break
+		}
+
+		sellerIDs := SystemicSellers(s)
+		problemCounts := make(map[string]int)
+		var problems []string
+		var members []string
+		for _, m := range s.Members {
+			if problemCounts[m.Problem] == 0 {
+				problems = append(problems, m.Problem)
+			}
+			problemCounts[m.Problem]++
+			if len(members) < 20 {
+				members = append(members, fmt.Sprintf("- %s: %s (%s, %s)", m.GluserID, examples.scrub(m.Problem), m.Severity, m.IssueID))
+			}
+		}
+		if len(s.Members) > len(members) {
+			members = append(members, fmt.Sprintf("- ... and %d more", len(s.Members)-len(members)))
+		}
+
+		var topProblems []client.ProblemCount
+		for _, p := range problems {
+			if len(topProblems) == 3 {
+				break
+			}
+			topProblems = append(topProblems, client.ProblemCount{Problem: p, Count: problemCounts[p]})
+		}
+		topProblems = examples.problems(topProblems)
+
+		severity := s.Severity
+		if severity == "" {
+			severity = "medium"
+		}
+
+		tickets = append(tickets, client.Ticket{
+			TicketID:        fmt.Sprintf("%s-systemic-%s", date, storage.Sanitize(s.ID)),
+			Date:            date,
+			FeatureBucket:   s.Bucket,
+			Priority:        priority,
+			AffectedSellers: sellerIDs,
+			Title:           fmt.Sprintf("[Systemic] %s", examples.scrub(s.Title)),
+			Description: fmt.Sprintf(
+				"Auto-generated ticket for a problem reported independently by **%d sellers**.\n\n"+
+					"## Summary\n"+
+					"- **Problem:** %s\n"+
+					"- **Bucket:** %s\n"+
+					"- **Open Issues:** %d (mentioned in %d calls)\n"+
+					"- **Severity:** %s\n"+
+					"%s"+
+					"- **First Reported:** %s\n"+
+					"- **Last Mentioned:** %s\n\n"+
+					"## Member Issues\n%s\n\n"+
+					"_Grouped by similarity across sellers (systemic issue %s). See GET /systemic-issues for all members._",
+				s.SellerCount, examples.scrub(s.Problem), s.Bucket, s.IssueCount, s.MentionCount, severity, impactLine(impacts[s.ID]),
+				config.BusinessDate(s.FirstReportedAt), config.BusinessDate(s.LastMentionedAt),
+				strings.Join(members, "\n"), s.ID,
+			),
+			TopProblems:     topProblems,
+			AffectedCount:   s.IssueCount,
+			Examples:        examples.examples(problems),
+			Severity:        severity,
+			Status:          client.TicketOpen,
+			SystemicIssueID: s.ID,
+			Impact:          impacts[s.ID],
+			CreatedAt:       time.Now(),
+		})
+		priority++
+	}
+	return tickets
+}
+
+// SystemicSellers lists a systemic issue's sellers, in member order
+func SystemicSellers(s client.SystemicIssue) []string {
+	ids := make([]string, 0, s.SellerCount)
+	seen := make(map[string]bool, s.SellerCount)
+	for _, m := range s.Members {
+		if !seen[m.GluserID] {
+			seen[m.GluserID] = true
+			ids = append(ids, m.GluserID)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,194 @@
+package ticket
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/profile"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== TICKET GENERATION ====================
+
+// Generate creates tickets from aggregated data - smarter version
+// Groups similar problems by bucket and creates tickets for significant buckets
+// Maximum 5 tickets per aggregation to reduce noise. boosts holds the severity
+// levels each affected seller's value adds (profile.SellerValueBoost); a
+// ticket is raised by its most valuable seller's. values are the affected
+// sellers' for the revenue impact (see Impact), nil to leave it out.
+func Generate(date string, agg *client.DailyAggregate, boosts map[string]int, values map[string]SellerValue) []client.Ticket {
+	var tickets []client.Ticket
+	priority := 1
+	maxTickets := 5
+	minBucketCount := 3 // Only create tickets for buckets with 3+ total issues
+
+	// Collect buckets with significant issue counts
+	type bucketEntry struct {
+		bucket       string
+		summary      client.BucketSummary
+		severity     string
+		baseSeverity string // Set when seller value raised severity
+		valueSellers []string
+		impact       *client.RevenueImpact
+	}
+	var significantBuckets []bucketEntry
+
+	for bucket, summary := range agg.FeatureBuckets {
+		// Use bucket's TOTAL count (groups all similar problems together)
+		if summary.TotalCount < minBucketCount {
+			continue
+		}
+
+		// Determine severity based on total count in bucket
+		severity := "medium"
+		if summary.TotalCount >= 10 {
+			severity = "critical"
+		} else if summary.TotalCount >= 5 {
+			severity = "high"
+		}
+
+		// Then raise it for the most valuable seller affected
+		entry := bucketEntry{bucket: bucket, summary: summary, severity: severity, impact: Impact(summary.AffectedSellerIDs, values)}
+		boost := 0
+		for _, id := range summary.AffectedSellerIDs {
+			if b := boosts[id]; b > boost {
+				boost, entry.valueSellers = b, []string{id}
+			} else if b > 0 && b == boost {
+				entry.valueSellers = append(entry.valueSellers, id)
+			}
+		}
+		if raised := profile.RaiseSeverity(severity, boost); raised != severity {
+			entry.severity, entry.baseSeverity = raised, severity
+		} else {
+			entry.valueSellers = nil
+		}
+		significantBuckets = append(significantBuckets, entry)
+	}
+
+	// Sort by revenue at risk when ranking by impact, then severity, then
+	// total count (highest first) to prioritize most impactful buckets
+	byImpact := values != nil && RankByImpact()
+	sort.Slice(significantBuckets, func(i, j int) bool {
+		a, b := significantBuckets[i], significantBuckets[j]
+		if byImpact && revenueAtRisk(a.impact) != revenueAtRisk(b.impact) {
+			return revenueAtRisk(a.impact) > revenueAtRisk(b.impact)
+		}
+		if severityRank(a.severity) != severityRank(b.severity) {
+			return severityRank(a.severity) > severityRank(b.severity)
+		}
+		return a.summary.TotalCount > b.summary.TotalCount
+	})
+
+	for _, entry := range significantBuckets {
+		// Stop if we've reached max tickets
+		if len(tickets) >= maxTickets {
+			break
+		}
+		severity := entry.severity
+
+		// Check if it's a recurring issue (appears across multiple sellers)
+		isRecurring := entry.summary.AffectedSellers > 1
+
+		// Build a consolidated problem summary from all problems in this bucket
+		topProblems := examples.problems(entry.summary.TopProblems)
+		var problemSummaries []string
+		for i, p := range topProblems {
+			if i >= 3 { // Limit to top 3 problems in description
+				break
+			}
+			problemSummaries = append(problemSummaries, fmt.Sprintf("• %s (x%d)", p.Problem, p.Count))
+		}
+		consolidatedProblems := strings.Join(problemSummaries, "\n")
+
+		severityNote := ""
+		if entry.baseSeverity != "" {
+			severityNote = fmt.Sprintf(" (raised from %s for seller value: %s)", entry.baseSeverity, strings.Join(entry.valueSellers, ", "))
+		}
+
+		// Use most common problem as title
+		titleProblem := "Multiple issues reported"
+		if len(topProblems) > 0 {
+			titleProblem = truncate(topProblems[0].Problem, 60)
+		}
+
+		// Build seller IDs string for description
+		sellerIDsStr := strings.Join(entry.summary.AffectedSellerIDs, ", ")
+		if len(sellerIDsStr) > 200 {
+			sellerIDsStr = sellerIDsStr[:200] + "..."
+		}
+
+		ticket := client.Ticket{
+			TicketID:        fmt.Sprintf("%s-%s-01", date, storage.Sanitize(entry.bucket)),
+			Date:            date,
+			FeatureBucket:   entry.bucket,
+			Priority:        priority,
+			AffectedSellers: entry.summary.AffectedSellerIDs, // Include seller IDs for follow-up
+			Title: fmt.Sprintf("[%s] %s (%d issues from %d sellers)",
+				entry.bucket, titleProblem, entry.summary.TotalCount, entry.summary.AffectedSellers),
+			Description: fmt.Sprintf(
+				"Auto-generated ticket for **%s** issues.\n\n"+
+					"## Summary\n"+
+					"- **Total Issues:** %d\n"+
+					"- **Affected Sellers:** %d\n"+
+					"- **Recurring Across Sellers:** %v\n"+
+					"- **Severity:** %s%s\n"+
+					"%s"+
+					"- **Date:** %s\n\n"+
+					"## Affected Seller IDs\n%s\n\n"+
+					"## Top Problems in This Category\n%s\n\n"+
+					"## Severity Breakdown\n"+
+					"- Critical: %d\n"+
+					"- High: %d\n"+
+					"- Medium: %d\n"+
+					"- Low: %d\n\n"+
+					"_This ticket groups all %s issues together. Review individual analyses for details._",
+				entry.bucket,
+				entry.summary.TotalCount, entry.summary.AffectedSellers,
+				isRecurring, severity, severityNote, impactLine(entry.impact), date,
+				sellerIDsStr,
+				consolidatedProblems,
+				entry.summary.SeverityBreakdown["critical"],
+				entry.summary.SeverityBreakdown["high"],
+				entry.summary.SeverityBreakdown["medium"],
+				entry.summary.SeverityBreakdown["low"],
+				entry.bucket,
+			),
+			TopProblems:   topProblems,
+			AffectedCount: entry.summary.TotalCount,
+			Examples:      examples.examples(entry.summary.Examples),
+			Severity:      severity,
+			BaseSeverity:  entry.baseSeverity,
+			Status:        client.TicketOpen,
+			Impact:        entry.impact,
+			CreatedAt:     time.Now(),
+		}
+
+		tickets = append(tickets, ticket)
+		priority++
+	}
+
+	// Log ticket summary
+	log.Printf("🎫 Generated %d tickets (from %d buckets with %d+ issues)",
+		len(tickets), len(significantBuckets), minBucketCount)
+
+	return tickets
+}
+
+func severityRank(sev string) int {
+	switch sev {
+	case "critical":
+		return 4
+	case "high":
+		return 3
+	case "medium":
+		return 2
+	case "low":
+		return 1
+	default:
+		return 0
+	}
+}
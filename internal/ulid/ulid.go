@@ -0,0 +1,103 @@
+package ulid
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"sync"
+	"time"
+)
+
+// ==================== ULID ====================
+// Universally Unique Lexicographically Sortable Identifiers
+// (https://github.com/ulid/spec): 48-bit millisecond timestamp + 80 random
+// bits, encoded as 26 Crockford base32 characters. IDs created within the
+// same millisecond increment the random part, so they still sort in order.
+
+const (
+	encoding = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+	length   = 26
+	maxTime  = 1<<48 - 1
+)
+
+var (
+	mu       sync.Mutex
+	lastMS   uint64
+	lastRand [10]byte
+)
+
+// New returns a ULID for the current time
+func New() string {
+	return NewAt(time.Now())
+}
+
+// NewAt returns a ULID whose time component is t. Use it when an ID should
+// sort by when something happened rather than when it was recorded.
+func NewAt(t time.Time) string {
+	ms := uint64(max(t.UnixMilli(), 0))
+	if ms > maxTime {
+		ms = maxTime
+	}
+
+	mu.Lock()
+	// Same millisecond as the previous ID: take the next value after it
+	if ms != lastMS || !increment(&lastRand) {
+		rand.Read(lastRand[:])
+		lastMS = ms
+	}
+	entropy := lastRand
+	mu.Unlock()
+
+	var id [16]byte
+	binary.BigEndian.PutUint16(id[0:2], uint16(ms>>32))
+	binary.BigEndian.PutUint32(id[2:6], uint32(ms))
+	copy(id[6:], entropy[:])
+	return encode(id)
+}
+
+// IsValid reports whether s is a well-formed ULID
+func IsValid(s string) bool {
+	if len(s) != length || s[0] > '7' {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isEncodingChar(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// increment adds one to the random part, reporting false on overflow
+func increment(b *[10]byte) bool {
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i]++
+		if b[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func isEncodingChar(c byte) bool {
+	for i := 0; i < len(encoding); i++ {
+		if encoding[i] == c {
+			return true
+		}
+	}
+	return false
+}
+
+// encode writes the 128 bits as 26 base32 characters, 5 bits each
+// (the first character carries only the top 3 bits)
+func encode(id [16]byte) string {
+	hi := binary.BigEndian.Uint64(id[0:8])
+	lo := binary.BigEndian.Uint64(id[8:16])
+
+	var out [length]byte
+	for i := length - 1; i >= 0; i-- {
+		out[i] = encoding[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out[:])
+}
@@ -0,0 +1,235 @@
+package vault
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== PII VAULT ====================
+// Phone numbers, email addresses, GSTINs, PANs and names introduced by an
+// honorific or "ji" are replaced in transcripts with tokens before they're
+// stored or sent to Gemini, e.g. "call me on 98765 43210" becomes "call me
+// on [PHONE_3f9a2c1b0d4e]". A token is an HMAC of the normalized value, so
+// the same number gets the same token on every call and analytics can still
+// count, join and group on it. The value behind each token is kept apart
+// from transcripts, encrypted with AES-256-GCM, in pii_vault (data/vault/
+// without MongoDB), and only put back for API keys with the pii scope.
+//
+// The vault is on when PII_VAULT_KEY is set to a base64 32-byte key, which
+// every instance must share: tokens made with one key can't be rehydrated
+// with another.
+
+var ErrDisabled = errors.New("PII vault is off (set PII_VAULT_KEY)")
+
+// detector finds one kind of PII; the value is the pattern's first group
+// when it has one, so the words introducing a name stay readable
+type detector struct {
+	kind      string
+	pattern   *regexp.Regexp
+	normalize func(string) string
+}
+
+// The PII patterns every redaction path shares: the vault's tokenizer,
+// ticket redaction, call samples and LLM recordings
+var (
+	EmailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	PhonePattern = regexp.MustCompile(`\+?\b\d(?:[ -]?\d){9,12}\b`)
+	GSTINPattern = regexp.MustCompile(`\b\d{2}[A-Z]{5}\d{4}[A-Z][1-9A-Z]Z[0-9A-Z]\b`)
+	PANPattern   = regexp.MustCompile(`\b[A-Z]{5}\d{4}[A-Z]\b`)
+)
+
+var nonDigits = regexp.MustCompile(`\D`)
+
+var detectors = []detector{
+	{"EMAIL", EmailPattern, strings.ToLower},
+	{"GSTIN", GSTINPattern, strings.ToUpper},
+	{"PAN", PANPattern, strings.ToUpper},
+	{"PHONE", PhonePattern, func(v string) string {
+		// The last 10 digits, so +91 and 0 prefixes don't make another token
+		d := nonDigits.ReplaceAllString(v, "")
+		return d[max(0, len(d)-10):]
+	}},
+	{"NAME", regexp.MustCompile(`\b(?:Mr|Mrs|Ms|Miss|Dr|Shri|Shree|Sri|Smt)\.?\s+([A-Z][a-z]+(?:\s+[A-Z][a-z]+)?)`), normalizeName},
+	{"NAME", regexp.MustCompile(`\b([A-Z][a-z]+)\s+[Jj]i\b`), normalizeName},
+	{"NAME", regexp.MustCompile(`\b(?i:name is|named|called)\s+([A-Z][a-z]+(?:\s+[A-Z][a-z]+)?)`), normalizeName},
+}
+
+var tokenPattern = regexp.MustCompile(`\[(?:EMAIL|GSTIN|PAN|PHONE|NAME)_[0-9a-f]{12}\]`)
+
+func normalizeName(v string) string {
+	return strings.ToLower(strings.Join(strings.Fields(v), " "))
+}
+
+type keys struct {
+	token   []byte // HMAC key tokens are derived with
+	encrypt cipher.AEAD
+}
+
+var vaultKeys = loadKeys()
+
+// saved are the tokens known to be in the vault, so each value is written once per process
+var saved sync.Map
+
+// loadKeys derives the token and encryption keys from PII_VAULT_KEY, nil when unset or invalid
+func loadKeys() *keys {
+	v := os.Getenv("PII_VAULT_KEY")
+	if v == "" {
+		return nil
+	}
+	master, err := base64.StdEncoding.DecodeString(v)
+	if err != nil || len(master) != 32 {
+		log.Printf("⚠️ Invalid PII_VAULT_KEY (want 32 bytes, base64-encoded), PII vault is off")
+		return nil
+	}
+	block, err := aes.NewCipher(derive(master, "encrypt"))
+	if err != nil {
+		log.Printf("⚠️ PII vault is off: %v", err)
+		return nil
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		log.Printf("⚠️ PII vault is off: %v", err)
+		return nil
+	}
+	return &keys{token: derive(master, "token"), encrypt: aead}
+}
+
+func derive(master []byte, purpose string) []byte {
+	mac := hmac.New(sha256.New, master)
+	mac.Write([]byte("pii-vault/" + purpose))
+	return mac.Sum(nil)
+}
+
+// Enabled reports whether transcripts are tokenized
+func Enabled() bool {
+	return vaultKeys != nil
+}
+
+// Tokenize replaces the PII in text with tokens, storing the values new to
+// the vault, and returns the count replaced. Text is returned as is when the
+// vault is off. It fails rather than return text whose values weren't kept.
+func Tokenize(ctx context.Context, text string) (string, int, error) {
+	k := vaultKeys
+	if k == nil || text == "" {
+		return text, 0, nil
+	}
+	count := 0
+	for _, d := range detectors {
+		var out strings.Builder
+		last := 0
+		for _, m := range d.pattern.FindAllStringSubmatchIndex(text, -1) {
+			start, end := m[0], m[1]
+			if len(m) > 2 && m[2] >= 0 {
+				start, end = m[2], m[3]
+			}
+			value := text[start:end]
+			token := k.tokenFor(d.kind, d.normalize(value))
+			if err := k.save(ctx, token, d.kind, value); err != nil {
+				return "", 0, err
+			}
+			out.WriteString(text[last:start])
+			out.WriteString(token)
+			last = end
+			count++
+		}
+		if last > 0 {
+			out.WriteString(text[last:])
+			text = out.String()
+		}
+	}
+	return text, count, nil
+}
+
+func (k *keys) tokenFor(kind, normalized string) string {
+	mac := hmac.New(sha256.New, k.token)
+	mac.Write([]byte(kind + "\x00" + normalized))
+	return "[" + kind + "_" + hex.EncodeToString(mac.Sum(nil))[:12] + "]"
+}
+
+// save encrypts value into the vault under token, unless it's there already
+func (k *keys) save(ctx context.Context, token, kind, value string) error {
+	if _, ok := saved.Load(token); ok {
+		return nil
+	}
+	nonce := make([]byte, k.encrypt.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("failed to encrypt PII: %w", err)
+	}
+	sealed := k.encrypt.Seal(nonce, nonce, []byte(value), []byte(token))
+	err := storage.SaveVaultEntry(ctx, &storage.VaultEntry{
+		Token:      token,
+		Kind:       kind,
+		Ciphertext: base64.StdEncoding.EncodeToString(sealed),
+		CreatedAt:  time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store PII in the vault: %w", err)
+	}
+	saved.Store(token, true)
+	return nil
+}
+
+// Rehydrate puts the values back in place of the tokens in text. Tokens
+// missing from the vault are left in place.
+func Rehydrate(ctx context.Context, text string) (string, error) {
+	k := vaultKeys
+	if k == nil {
+		return "", ErrDisabled
+	}
+	tokens := tokenPattern.FindAllString(text, -1)
+	if len(tokens) == 0 {
+		return text, nil
+	}
+	entries, err := storage.LoadVaultEntries(ctx, tokens)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the PII vault: %w", err)
+	}
+	values := make(map[string]string, len(entries))
+	for _, e := range entries {
+		sealed, err := base64.StdEncoding.DecodeString(e.Ciphertext)
+		if err != nil || len(sealed) < k.encrypt.NonceSize() {
+			return "", fmt.Errorf("corrupt vault entry %s", e.Token)
+		}
+		n := k.encrypt.NonceSize()
+		value, err := k.encrypt.Open(nil, sealed[:n], sealed[n:], []byte(e.Token))
+		if err != nil {
+			return "", fmt.Errorf("failed to decrypt vault entry %s (was PII_VAULT_KEY changed?)", e.Token)
+		}
+		values[e.Token] = string(value)
+	}
+	return tokenPattern.ReplaceAllStringFunc(text, func(t string) string {
+		if v, ok := values[t]; ok {
+			return v
+		}
+		return t
+	}), nil
+}
+
+// Mask replaces the tokens in text with their kind, e.g. [phone], so text
+// shown outside the service can't be joined on them, returning how many it
+// replaced. It doesn't need the vault to be on.
+func Mask(text string) (string, int) {
+	n := 0
+	text = tokenPattern.ReplaceAllStringFunc(text, func(t string) string {
+		n++
+		kind, _, _ := strings.Cut(strings.TrimPrefix(t, "["), "_")
+		return "[" + strings.ToLower(kind) + "]"
+	})
+	return text, n
+}
@@ -0,0 +1,55 @@
+package watcher
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+
+	"im-ai-voice/client"
+)
+
+// ==================== CHANGED TRANSCRIPTS ====================
+// Upstream systems sometimes rewrite a transcript with corrected text. The
+// watcher remembers the checksum of each processed transcript's text (see
+// HackathonTranscript.Checksum) and, when a processed file turns up again
+// with other text, analyzes it again as a new version of the same call.
+// Files are only read again when their size or modification time changed.
+// After a restart the checksums come from the stored analyses; a transcript
+// analyzed before checksums were recorded takes the text it has when first
+// seen again as its baseline.
+
+// processedFile is what the watcher knows of a transcript it's done with
+type processedFile struct {
+	checksum string // Empty until known
+	modTime  time.Time
+	size     int64
+}
+
+// emptyChecksum is the checksum of transcripts skipped for having no text,
+// which are analyzed as new calls once they have some. Contact attempts are
+// marked with it too, so a rewrite of one is looked at as a new call.
+var emptyChecksum = (&client.HackathonTranscript{}).Checksum()
+
+// changed reports whether a processed file's text differs from what was
+// processed. Files whose text is unchanged are marked processed again, which
+// moves a rewritten copy out of the watched directory.
+func (w *TranscriptWatcher) changed(f TranscriptFile, pf processedFile) bool {
+	info, err := os.Stat(f.Path)
+	if err != nil || (info.ModTime().Equal(pf.modTime) && info.Size() == pf.size) {
+		return false
+	}
+	data, err := os.ReadFile(f.Path)
+	if err != nil {
+		return false
+	}
+	var ht client.HackathonTranscript
+	if err := json.Unmarshal(data, &ht); err != nil {
+		return false // Possibly still being written, looked at again next poll
+	}
+	checksum := ht.Checksum()
+	if pf.checksum != "" && pf.checksum != checksum {
+		return true
+	}
+	w.done(f, checksum, time.Now())
+	return false
+}
@@ -0,0 +1,208 @@
+package watcher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== PROCESSED AND FAILED FILES ====================
+// Once processed, a watched transcript moves out of its source into
+// PROCESSED_DIR/{date}/, dated by the business day it was processed on, so
+// the watched directories only hold work still to do. A transcript that
+// can't be read, parsed or analyzed is tried again on the next polls and,
+// after MaxAttempts failures, moves to FAILED_DIR next to a
+// {name}.error.json sidecar saying why. Moving it back into a watched
+// directory (and deleting the sidecar) retries it. With TRANSCRIPT_MOVE=false
+// files stay where they are and failures are retried indefinitely.
+
+// errorSidecarSuffix marks failure sidecars, which are never transcripts
+const errorSidecarSuffix = ".error.json"
+
+// FilePolicy decides what happens to watched files once they're done with
+type FilePolicy struct {
+	Move        bool // Move processed and failed files out of the watched directories
+	MaxAttempts int  // Failed attempts before a file moves to FAILED_DIR
+}
+
+// FilePolicyFromEnv reads TRANSCRIPT_MOVE and TRANSCRIPT_MAX_ATTEMPTS
+func FilePolicyFromEnv() FilePolicy {
+	p := FilePolicy{Move: true, MaxAttempts: config.DEFAULT_TRANSCRIPT_MAX_ATTEMPTS}
+	if v := os.Getenv("TRANSCRIPT_MOVE"); v != "" {
+		if move, err := strconv.ParseBool(v); err == nil {
+			p.Move = move
+		} else {
+			log.Printf("⚠️ Invalid TRANSCRIPT_MOVE=%q, using %t", v, p.Move)
+		}
+	}
+	if v := os.Getenv("TRANSCRIPT_MAX_ATTEMPTS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 1 {
+			p.MaxAttempts = n
+		} else {
+			log.Printf("⚠️ Invalid TRANSCRIPT_MAX_ATTEMPTS=%q, using %d", v, p.MaxAttempts)
+		}
+	}
+	return p
+}
+
+// transcriptFailure is the sidecar written next to a failed transcript
+type transcriptFailure struct {
+	File     string    `json:"file"`             // Where it was picked up from
+	System   string    `json:"system,omitempty"` // The source's system
+	Stage    string    `json:"stage"`            // read, parse, contact_attempt or analysis
+	Error    string    `json:"error"`
+	Attempts int       `json:"attempts"`
+	FailedAt time.Time `json:"failed_at"`
+}
+
+// done marks a file processed with the checksum of its text, moving it to PROCESSED_DIR
+func (w *TranscriptWatcher) done(f TranscriptFile, checksum string, now time.Time) {
+	pf := processedFile{checksum: checksum}
+	if info, err := os.Stat(f.Path); err == nil {
+		pf.modTime, pf.size = info.ModTime(), info.Size()
+	}
+	w.mu.Lock()
+	w.processedFiles[f.FileID] = pf
+	delete(w.failures, f.FileID)
+	w.mu.Unlock()
+
+	if !w.files.Move {
+		return
+	}
+	dst := filepath.Join(config.PROCESSED_DIR, config.BusinessDate(now), filepath.Base(f.Path))
+	if err := moveFile(f.Path, dst); err != nil {
+		log.Printf("   ⚠️ Failed to move %s to %s: %v", f.Path, dst, err)
+	}
+}
+
+// skip marks a file processed without an analysis, to be processed as a
+// new call if its text changes
+func (w *TranscriptWatcher) skip(f TranscriptFile) {
+	now := time.Now()
+	w.done(f, emptyChecksum, now)
+	w.mu.Lock()
+	w.lastProcessedAt = now
+	w.mu.Unlock()
+}
+
+// fail records a failed attempt at a file, moving it to FAILED_DIR once it
+// has failed MaxAttempts times. callID is empty when the file couldn't be
+// read or parsed.
+func (w *TranscriptWatcher) fail(f TranscriptFile, stage, callID string, cause error) {
+	w.mu.Lock()
+	w.failures[f.FileID]++
+	attempts := w.failures[f.FileID]
+	giveUp := w.files.Move && attempts >= w.files.MaxAttempts
+	if giveUp {
+		delete(w.failures, f.FileID)
+	}
+	w.mu.Unlock()
+
+	// The first failure and the last, so retries don't flood the event log
+	if attempts == 1 || giveUp {
+		storage.RecordEvent(context.Background(), client.Event{
+			Type:   client.EventAnalysisFailed,
+			CallID: callID,
+			Payload: client.AnalysisFailedPayload{
+				Source:      "watcher",
+				File:        f.FileID,
+				Stage:       stage,
+				Error:       cause.Error(),
+				Attempts:    attempts,
+				Quarantined: giveUp,
+				Origin:      f.Source.Origin(),
+			},
+		})
+	}
+	if !giveUp {
+		return
+	}
+	failure := transcriptFailure{
+		File:     f.Path,
+		System:   f.Source.System,
+		Stage:    stage,
+		Error:    cause.Error(),
+		Attempts: attempts,
+		FailedAt: time.Now(),
+	}
+	if err := moveToFailed(f, failure); err != nil {
+		log.Printf("   ⚠️ Failed to move %s to %s: %v", f.Path, config.FAILED_DIR, err)
+		return
+	}
+	log.Printf("   🗂️ Gave up on %s after %d attempts, moved to %s", f.FileID, attempts, config.FAILED_DIR)
+}
+
+func moveToFailed(f TranscriptFile, failure transcriptFailure) error {
+	b, err := json.MarshalIndent(failure, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(config.FAILED_DIR, 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(config.FAILED_DIR, f.FileID+errorSidecarSuffix), b, 0644); err != nil {
+		return err
+	}
+	return moveFile(f.Path, filepath.Join(config.FAILED_DIR, filepath.Base(f.Path)))
+}
+
+// moveFile renames src to dst, copying when they're on different
+// filesystems (e.g. a source on a mounted share)
+func moveFile(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	err := os.Rename(src, dst)
+	if !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	tmp := dst + ".tmp"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Remove(src); err != nil {
+		return fmt.Errorf("copied but couldn't remove the original: %w", err)
+	}
+	return nil
+}
+
+// isRawTranscript reports whether data is a transcript posted to the API
+// rather than an export, going by its transcript_text field
+func isRawTranscript(data []byte) bool {
+	var probe struct {
+		Transcript *string `json:"transcript_text"`
+	}
+	return json.Unmarshal(data, &probe) == nil && probe.Transcript != nil
+}
@@ -0,0 +1,204 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"strconv"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/notify"
+	"im-ai-voice/internal/scheduler"
+)
+
+// ==================== PIPELINE HEALTH ====================
+// The watcher checks on itself every minute and fires a critical alert when
+// transcripts are waiting but nothing has been processed for StallAfter, or
+// when at least FailureRate of the pipeline runs in the last FailureWindow
+// failed. Each condition alerts once when it starts and logs when it clears,
+// so a stall that lasts all night is one alert, not hundreds.
+
+// Alert types fired by the health check
+const (
+	AlertPipelineStalled     = "pipeline_stalled"
+	AlertPipelineFailureRate = "pipeline_failure_rate"
+)
+
+// HealthPolicy decides when the pipeline counts as unhealthy
+type HealthPolicy struct {
+	StallAfter    time.Duration // Waiting transcripts with no progress for this long are a stall, 0 disables
+	FailureRate   float64       // Share of failed runs in the window that alerts, 0 disables
+	FailureWindow time.Duration
+	MinAttempts   int // Runs the window needs before its failure rate counts
+}
+
+// HealthPolicyFromEnv reads PIPELINE_STALL_AFTER, PIPELINE_FAILURE_RATE,
+// PIPELINE_FAILURE_WINDOW and PIPELINE_FAILURE_MIN_ATTEMPTS
+func HealthPolicyFromEnv() HealthPolicy {
+	p := HealthPolicy{
+		StallAfter:    config.DEFAULT_PIPELINE_STALL_AFTER,
+		FailureRate:   config.DEFAULT_PIPELINE_FAILURE_RATE,
+		FailureWindow: config.EnvDuration("PIPELINE_FAILURE_WINDOW", config.DEFAULT_PIPELINE_FAILURE_WINDOW),
+		MinAttempts:   config.DEFAULT_PIPELINE_FAILURE_MIN_ATTEMPTS,
+	}
+
+	if v := os.Getenv("PIPELINE_STALL_AFTER"); v == "0" {
+		p.StallAfter = 0
+	} else {
+		p.StallAfter = config.EnvDuration("PIPELINE_STALL_AFTER", p.StallAfter)
+	}
+	if v := os.Getenv("PIPELINE_FAILURE_RATE"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil && f >= 0 && f <= 1 {
+			p.FailureRate = f
+		} else {
+			log.Printf("⚠️ Invalid PIPELINE_FAILURE_RATE=%q, using %g", v, p.FailureRate)
+		}
+	}
+	if v := os.Getenv("PIPELINE_FAILURE_MIN_ATTEMPTS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 1 {
+			p.MinAttempts = n
+		} else {
+			log.Printf("⚠️ Invalid PIPELINE_FAILURE_MIN_ATTEMPTS=%q, using %d", v, p.MinAttempts)
+		}
+	}
+	return p
+}
+
+// attempt is one pipeline run of a transcript
+type attempt struct {
+	at     time.Time
+	failed bool
+}
+
+// recordAttempt adds a run to the failure window. Callers hold w.mu.
+func (w *TranscriptWatcher) recordAttempt(at time.Time, failed bool) {
+	w.attempts = append(w.attempts, attempt{at: at, failed: failed})
+	w.pruneAttempts(at)
+}
+
+// pruneAttempts drops runs that fell out of the failure window. Callers hold w.mu.
+func (w *TranscriptWatcher) pruneAttempts(now time.Time) {
+	cutoff := now.Add(-w.health.FailureWindow)
+	i := 0
+	for i < len(w.attempts) && w.attempts[i].at.Before(cutoff) {
+		i++
+	}
+	w.attempts = w.attempts[i:]
+}
+
+// failureRate returns the runs in the window and the share that failed. Callers hold w.mu.
+func (w *TranscriptWatcher) failureRate(now time.Time) (int, float64) {
+	w.pruneAttempts(now)
+	if len(w.attempts) == 0 {
+		return 0, 0
+	}
+	failed := 0
+	for _, a := range w.attempts {
+		if a.failed {
+			failed++
+		}
+	}
+	return len(w.attempts), float64(failed) / float64(len(w.attempts))
+}
+
+// RegisterJobs adds the watcher's health check to sched. It runs on the
+// leader alongside the watcher.
+func (w *TranscriptWatcher) RegisterJobs(sched *scheduler.Scheduler) {
+	sched.Add(scheduler.Job{
+		Name:        "pipeline_health",
+		Description: "Alert when the watcher stalls or too many analyses fail",
+		Spec:        "* * * * *",
+		Run: func(ctx context.Context) error {
+			w.CheckHealth(ctx)
+			return nil
+		},
+	})
+}
+
+// CheckHealth fires a critical alert for each unhealthy condition that
+// started since the last check
+func (w *TranscriptWatcher) CheckHealth(ctx context.Context) {
+	stats := w.Stats()
+	now := stats.GeneratedAt
+
+	w.mu.Lock()
+	if !w.running {
+		w.mu.Unlock()
+		return
+	}
+	policy := w.health
+	// Progress is measured from the last processed transcript, the watcher's
+	// start or the oldest waiting file, whichever is latest
+	since := w.startedAt
+	if w.lastProcessedAt.After(since) {
+		since = w.lastProcessedAt
+	}
+	if stats.OldestPendingAt != nil && stats.OldestPendingAt.After(since) {
+		since = *stats.OldestPendingAt
+	}
+	attempts, rate := w.failureRate(now)
+	lastProcessed := w.lastProcessedAt
+	w.mu.Unlock()
+
+	idle := now.Sub(since)
+	stalled := policy.StallAfter > 0 && stats.PendingTranscripts > 0 && idle >= policy.StallAfter
+	failing := policy.FailureRate > 0 && attempts >= policy.MinAttempts && rate >= policy.FailureRate
+
+	if w.transition(&w.stallAlerted, stalled) {
+		if stalled {
+			details := map[string]interface{}{
+				"pending_transcripts":        stats.PendingTranscripts,
+				"oldest_pending_age_seconds": stats.OldestPendingSeconds,
+				"idle_seconds":               roundSeconds(idle.Seconds()),
+				"stall_after":                policy.StallAfter.String(),
+			}
+			if !lastProcessed.IsZero() {
+				details["last_processed_at"] = lastProcessed
+			}
+			notify.FireAlert(ctx, client.Alert{
+				Type:     AlertPipelineStalled,
+				Severity: "critical",
+				Message: fmt.Sprintf("Transcript pipeline stalled: %d transcripts waiting, none processed for %s",
+					stats.PendingTranscripts, idle.Round(time.Minute)),
+				Details: details,
+			})
+		} else {
+			log.Printf("✅ Transcript pipeline is processing again")
+		}
+	}
+
+	if w.transition(&w.failureAlerted, failing) {
+		if failing {
+			notify.FireAlert(ctx, client.Alert{
+				Type:     AlertPipelineFailureRate,
+				Severity: "critical",
+				Message: fmt.Sprintf("Transcript pipeline failing: %.0f%% of %d runs in the last %s failed",
+					rate*100, attempts, policy.FailureWindow),
+				Details: map[string]interface{}{
+					"attempts":       attempts,
+					"failure_rate":   math.Round(rate*1000) / 1000,
+					"threshold":      policy.FailureRate,
+					"failure_window": policy.FailureWindow.String(),
+				},
+			})
+		} else {
+			log.Printf("✅ Transcript pipeline failure rate is back under %.0f%%", policy.FailureRate*100)
+		}
+	}
+}
+
+// transition records whether a condition holds and reports whether that
+// changed since the last check
+func (w *TranscriptWatcher) transition(alerted *bool, holds bool) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if *alerted == holds {
+		return false
+	}
+	*alerted = holds
+	return true
+}
@@ -0,0 +1,65 @@
+package watcher
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/storage"
+)
+
+// ==================== AGGREGATION POLICY ====================
+// New analyses are counted per date. A date is aggregated as soon as it has
+// Threshold new analyses, or once no new analysis for it arrived for Debounce,
+// so a trickle of late transcripts still gets aggregated. With the analysis
+// date mode, a transcript from last week re-aggregates last week instead of
+// today.
+
+// AggregationPolicy decides when the watcher aggregates which date
+type AggregationPolicy struct {
+	Threshold int           // New analyses of a date that trigger its aggregation
+	Debounce  time.Duration // Quiet period before a date's pending analyses are aggregated, 0 disables
+	DateMode  string        // client.AggregateDateAnalysis or client.AggregateDateToday
+}
+
+// PolicyFromEnv reads AGGREGATE_THRESHOLD, AGGREGATE_DEBOUNCE and AGGREGATE_DATE
+func PolicyFromEnv() AggregationPolicy {
+	p := AggregationPolicy{
+		Threshold: config.DEFAULT_AGGREGATE_THRESHOLD,
+		Debounce:  config.DEFAULT_AGGREGATE_DEBOUNCE,
+		DateMode:  client.AggregateDateAnalysis,
+	}
+
+	if v := os.Getenv("AGGREGATE_THRESHOLD"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 1 {
+			p.Threshold = n
+		} else {
+			log.Printf("⚠️ Invalid AGGREGATE_THRESHOLD=%q, using %d", v, p.Threshold)
+		}
+	}
+	if v := os.Getenv("AGGREGATE_DEBOUNCE"); v == "0" {
+		p.Debounce = 0
+	} else {
+		p.Debounce = config.EnvDuration("AGGREGATE_DEBOUNCE", p.Debounce)
+	}
+	switch v := os.Getenv("AGGREGATE_DATE"); v {
+	case "", client.AggregateDateAnalysis:
+	case client.AggregateDateToday:
+		p.DateMode = v
+	default:
+		log.Printf("⚠️ Invalid AGGREGATE_DATE=%q, using %s", v, p.DateMode)
+	}
+	return p
+}
+
+// dateFor returns the date an analysis counts towards: the date its daily
+// aggregate groups it under (see storage.AggregationDate), or today
+func (p AggregationPolicy) dateFor(ar *client.AnalysisResult, now time.Time) string {
+	if p.DateMode == client.AggregateDateToday || ar.Timestamp.IsZero() {
+		return config.BusinessDate(now)
+	}
+	return storage.AggregationDate(ar)
+}
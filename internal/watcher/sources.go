@@ -0,0 +1,129 @@
+package watcher
+
+import (
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"im-ai-voice/client"
+)
+
+// ==================== TRANSCRIPT SOURCES ====================
+// Besides TRANSCRIPTS_DIR, the watcher picks up transcripts from the
+// directories in TRANSCRIPT_SOURCES, one per upstream system. Each is
+// tagged with its system and optionally a region, which every transcript
+// found under it carries into its analysis as the call's origin. Source
+// directories are watched with all their subfolders, so upstreams can drop
+// files into dated or per-team folders; dot folders are skipped.
+// TRANSCRIPTS_DIR itself stays flat, as API transcripts are looked up at
+// its top level.
+
+// Source is a watched directory and the tags its transcripts get
+type Source struct {
+	Dir    string
+	System string // Empty for TRANSCRIPTS_DIR
+	Region string
+}
+
+// Origin returns the tags transcripts from s get, nil if it has none
+func (s Source) Origin() *client.CallOrigin {
+	if s.System == "" && s.Region == "" {
+		return nil
+	}
+	return &client.CallOrigin{System: s.System, Region: s.Region}
+}
+
+// SourcesFromEnv returns transcriptsDir followed by the sources in
+// TRANSCRIPT_SOURCES: comma-separated system=dir or system:region=dir,
+// e.g. "crm:north=/mnt/crm/north,ivr=/mnt/ivr"
+func SourcesFromEnv(transcriptsDir string) []Source {
+	sources := []Source{{Dir: transcriptsDir}}
+	for _, entry := range strings.Split(os.Getenv("TRANSCRIPT_SOURCES"), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		tags, dir, ok := strings.Cut(entry, "=")
+		system, region, _ := strings.Cut(tags, ":")
+		s := Source{
+			Dir:    filepath.Clean(strings.TrimSpace(dir)),
+			System: strings.TrimSpace(system),
+			Region: strings.TrimSpace(region),
+		}
+		if !ok || s.System == "" || strings.TrimSpace(dir) == "" {
+			log.Printf("⚠️ Invalid TRANSCRIPT_SOURCES entry %q, use system=dir or system:region=dir", entry)
+			continue
+		}
+		sources = append(sources, s)
+	}
+	return sources
+}
+
+// Tag fills in what a transcript doesn't say about its origin from the
+// source's tags
+func (s Source) Tag(ht *client.HackathonTranscript) {
+	tags := s.Origin()
+	switch {
+	case tags == nil:
+	case ht.Origin == nil:
+		ht.Origin = tags
+	default:
+		merged := *ht.Origin
+		if merged.System == "" {
+			merged.System = tags.System
+		}
+		if merged.Region == "" {
+			merged.Region = tags.Region
+		}
+		ht.Origin = &merged
+	}
+}
+
+// TranscriptFile is a transcript found in a source
+type TranscriptFile struct {
+	Path   string
+	FileID string // Base name without .json, what processed files are tracked by
+	Source Source
+}
+
+// Scan lists the transcripts in every source. A file ID found in two
+// sources is the same call, so only the first is listed.
+func Scan(sources []Source) []TranscriptFile {
+	var files []TranscriptFile
+	seen := make(map[string]bool)
+	for i, src := range sources {
+		err := filepath.WalkDir(src.Dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if path == src.Dir {
+					return err
+				}
+				return nil // Skip unreadable subfolders
+			}
+			if d.IsDir() {
+				if path != src.Dir && strings.HasPrefix(d.Name(), ".") {
+					return filepath.SkipDir
+				}
+				if path != src.Dir && i == 0 {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !strings.HasSuffix(d.Name(), ".json") || strings.HasSuffix(d.Name(), errorSidecarSuffix) {
+				return nil
+			}
+			fileID := strings.TrimSuffix(d.Name(), ".json")
+			if seen[fileID] {
+				return nil
+			}
+			seen[fileID] = true
+			files = append(files, TranscriptFile{Path: path, FileID: fileID, Source: src})
+			return nil
+		})
+		if err != nil && !os.IsNotExist(err) {
+			log.Printf("Error scanning transcripts in %s: %v", src.Dir, err)
+		}
+	}
+	return files
+}
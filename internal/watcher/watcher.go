@@ -0,0 +1,571 @@
+package watcher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"im-ai-voice/client"
+	"im-ai-voice/internal/config"
+	"im-ai-voice/internal/storage"
+)
+
+// Pipeline is the processing the watcher hands new transcripts to
+type Pipeline interface {
+	ProcessHackathonTranscript(ctx context.Context, ht *client.HackathonTranscript) (*client.SellerProfile, *client.AnalysisResult, error)
+	ReanalyzeHackathonTranscript(ctx context.Context, ht *client.HackathonTranscript) (*client.AnalysisResult, error)
+	RecordContactAttempt(ctx context.Context, ht *client.HackathonTranscript) (*client.ContactAttempt, error)
+	RecordTranscriptReceived(ctx context.Context, ht *client.HackathonTranscript, revised bool)
+	RunAggregation(ctx context.Context, date string) (*client.DailyAggregate, error)
+}
+
+// TranscriptWatcher watches for new transcripts and triggers analysis
+type TranscriptWatcher struct {
+	pipeline        Pipeline
+	sources         []Source // TRANSCRIPTS_DIR first
+	pollInterval    time.Duration
+	processedFiles  map[string]processedFile // By file ID
+	reconciled      bool                     // processedFiles has what storage knows was processed
+	mu              sync.Mutex
+	running         bool
+	policy          AggregationPolicy
+	files           FilePolicy
+	failures        map[string]int                      // Failed attempts per file ID since its last success
+	pending         map[string]*client.PendingAggregate // New analyses per date since its last aggregation
+	lastAggregation *client.AggregationRun
+	processed       int             // Transcripts processed since startup
+	failed          int             // Pipeline failures since startup
+	durations       []time.Duration // Processing times of the latest transcripts, ring buffer
+	nextDuration    int
+	health          HealthPolicy
+	startedAt       time.Time
+	lastProcessedAt time.Time // Last transcript done with, analyzed or skipped
+	attempts        []attempt // Pipeline runs within the failure window, oldest first
+	stallAlerted    bool
+	failureAlerted  bool
+	cancel          context.CancelFunc
+}
+
+// NewTranscriptWatcher creates a new watcher of transcriptsDir and the
+// TRANSCRIPT_SOURCES, with the aggregation policy from the environment
+func NewTranscriptWatcher(pipeline Pipeline, transcriptsDir string) *TranscriptWatcher {
+	return &TranscriptWatcher{
+		pipeline:       pipeline,
+		sources:        SourcesFromEnv(transcriptsDir),
+		pollInterval:   5 * time.Second, // Check every 5 seconds
+		processedFiles: make(map[string]processedFile),
+		policy:         PolicyFromEnv(),
+		files:          FilePolicyFromEnv(),
+		failures:       make(map[string]int),
+		health:         HealthPolicyFromEnv(),
+		pending:        make(map[string]*client.PendingAggregate),
+	}
+}
+
+// Start begins watching for new transcripts. A stopped watcher can be
+// started again, e.g. when this instance becomes leader again.
+func (w *TranscriptWatcher) Start() {
+	w.mu.Lock()
+	if w.running {
+		w.mu.Unlock()
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+	w.mu.Unlock()
+
+	// First, mark the transcripts already done with as processed (including
+	// those another instance processed while this one wasn't watching).
+	// Until that succeeds, transcripts aren't picked up.
+	w.mu.Lock()
+	w.reconciled = false
+	w.mu.Unlock()
+	if err := w.loadExistingAnalyses(ctx); err != nil {
+		log.Printf("⚠️ Watcher waiting for processed transcripts to load: %v", err)
+	}
+
+	log.Printf("📡 Transcript Watcher started")
+	for _, src := range w.sources {
+		if o := src.Origin(); o != nil {
+			log.Printf("   - Watching: %s (%s)", src.Dir, o)
+		} else {
+			log.Printf("   - Watching: %s", src.Dir)
+		}
+	}
+	log.Printf("   - Poll interval: %v", w.pollInterval)
+	if w.files.Move {
+		log.Printf("   - Processed files move to %s, failed ones to %s after %d attempts", config.PROCESSED_DIR, config.FAILED_DIR, w.files.MaxAttempts)
+	}
+	log.Printf("   - Aggregate: %d new analyses per date, debounce %v, by %s date", w.policy.Threshold, w.policy.Debounce, w.policy.DateMode)
+
+	w.mu.Lock()
+	w.running = true
+	w.startedAt = time.Now()
+	w.stallAlerted, w.failureAlerted = false, false
+	w.mu.Unlock()
+
+	go w.watchLoop(ctx)
+}
+
+// Stop stops the watcher
+func (w *TranscriptWatcher) Stop() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.running {
+		return
+	}
+	w.cancel()
+	w.running = false
+	log.Println("📡 Transcript Watcher stopped")
+}
+
+// Status returns the aggregation policy and the pending per-date counters
+func (w *TranscriptWatcher) Status() client.WatcherStatus {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	status := client.WatcherStatus{
+		Running:     w.running,
+		Threshold:   w.policy.Threshold,
+		Debounce:    w.policy.Debounce.String(),
+		DateMode:    w.policy.DateMode,
+		DateField:   storage.AggregateDateField(),
+		MoveFiles:   w.files.Move,
+		MaxAttempts: w.files.MaxAttempts,
+		Pending:     make([]client.PendingAggregate, 0, len(w.pending)),
+		Sources:     make([]client.WatchedSource, 0, len(w.sources)),
+	}
+	for _, s := range w.sources {
+		status.Sources = append(status.Sources, client.WatchedSource{Dir: s.Dir, System: s.System, Region: s.Region})
+	}
+	for _, p := range w.pending {
+		status.Pending = append(status.Pending, *p)
+	}
+	sort.Slice(status.Pending, func(i, j int) bool { return status.Pending[i].Date < status.Pending[j].Date })
+	if w.lastAggregation != nil {
+		run := *w.lastAggregation
+		status.LastAggregation = &run
+	}
+	return status
+}
+
+// loadExistingAnalyses marks the transcripts already done with as
+// processed, so a fresh instance pointed at existing data doesn't analyze
+// them again: calls analyzed (in MongoDB when enabled, and in local
+// ANALYSIS_DIR files), deleted ones in the trash and, from the event log,
+// contact attempts, which leave no analysis. Entries this instance already
+// has are kept.
+func (w *TranscriptWatcher) loadExistingAnalyses(ctx context.Context) error {
+	found := make(map[string]processedFile)
+	var analyzed, local, attempts, deleted int
+
+	if storage.IsMongoEnabled() {
+		calls, err := storage.GetAnalyzedCallsFromMongo(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list analyzed calls in MongoDB: %w", err)
+		}
+		for _, c := range calls {
+			found[fmt.Sprintf("gluser_%s_call_%s", c.SellerID, c.CallID)] = processedFile{checksum: c.Checksum}
+		}
+		analyzed = len(calls)
+	}
+
+	files, err := filepath.Glob(filepath.Join(config.ANALYSIS_DIR, "*.analysis.json"))
+	if err != nil {
+		return fmt.Errorf("failed to list local analyses: %w", err)
+	}
+	for _, f := range files {
+		fileID := strings.TrimSuffix(filepath.Base(f), ".analysis.json")
+		if _, ok := found[fileID]; !ok {
+			found[fileID] = processedFile{}
+			local++
+		}
+	}
+
+	trash, err := storage.ListTrash(ctx, client.TrashAnalysis)
+	if err != nil {
+		return fmt.Errorf("failed to load deleted analyses: %w", err)
+	}
+	for _, item := range trash {
+		fileID := fmt.Sprintf("gluser_%s_call_%s", item.GluserID, item.ID)
+		if _, ok := found[fileID]; !ok {
+			found[fileID] = processedFile{}
+			deleted++
+		}
+	}
+
+	q := storage.EventQuery{Type: client.EventContactAttempt, Limit: 1000}
+	for {
+		page, err := storage.QueryEvents(ctx, q)
+		if err != nil {
+			return fmt.Errorf("failed to read contact attempts: %w", err)
+		}
+		for _, e := range page.Events {
+			fileID := fmt.Sprintf("gluser_%s_call_%s", e.GluserID, e.CallID)
+			if _, ok := found[fileID]; !ok {
+				found[fileID] = processedFile{checksum: emptyChecksum}
+				attempts++
+			}
+		}
+		if !page.HasMore {
+			break
+		}
+		q.Cursor = page.NextCursor
+	}
+
+	w.mu.Lock()
+	for fileID, pf := range found {
+		if _, ok := w.processedFiles[fileID]; !ok {
+			w.processedFiles[fileID] = pf
+		}
+	}
+	w.reconciled = true
+	w.mu.Unlock()
+
+	if storage.IsMongoEnabled() {
+		log.Printf("   - Already processed: %d analyzed (MongoDB), %d more analyzed (local files), %d contact attempts, %d deleted", analyzed, local, attempts, deleted)
+	} else {
+		log.Printf("   - Already processed: %d analyzed (local files), %d contact attempts, %d deleted", local, attempts, deleted)
+	}
+	return nil
+}
+
+// watchLoop continuously checks for new transcripts
+func (w *TranscriptWatcher) watchLoop(ctx context.Context) {
+	ticker := time.NewTicker(w.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.mu.Lock()
+			reconciled := w.reconciled
+			w.mu.Unlock()
+			if !reconciled {
+				if err := w.loadExistingAnalyses(ctx); err != nil {
+					log.Printf("⚠️ Watcher waiting for processed transcripts to load: %v", err)
+					continue
+				}
+			}
+			w.checkForNewTranscripts(ctx)
+			w.aggregateQuietDates(ctx)
+		}
+	}
+}
+
+// checkForNewTranscripts scans for unprocessed transcripts, and processed
+// ones whose text changed since
+func (w *TranscriptWatcher) checkForNewTranscripts(ctx context.Context) {
+	for _, f := range Scan(w.sources) {
+		w.mu.Lock()
+		pf, processed := w.processedFiles[f.FileID]
+		w.mu.Unlock()
+
+		switch {
+		case !processed:
+			w.processTranscript(ctx, f, false)
+		case w.changed(f, pf):
+			w.processTranscript(ctx, f, pf.checksum != emptyChecksum)
+		}
+	}
+}
+
+// processTranscript analyzes a single transcript file. A revised one was
+// analyzed before with other text, and gets a new analysis version.
+func (w *TranscriptWatcher) processTranscript(ctx context.Context, f TranscriptFile, revised bool) {
+	fileID := f.FileID
+	if revised {
+		log.Printf("🔄 Processing changed transcript: %s", fileID)
+	} else {
+		log.Printf("🔄 Processing new transcript: %s", fileID)
+	}
+
+	// Read the transcript file
+	data, err := os.ReadFile(f.Path)
+	if err != nil {
+		log.Printf("   ❌ Failed to read file: %v", err)
+		w.fail(f, "read", "", err)
+		return
+	}
+
+	// Parse as hackathon transcript format
+	var ht client.HackathonTranscript
+	if err := json.Unmarshal(data, &ht); err != nil {
+		log.Printf("   ❌ Failed to parse JSON: %v", err)
+		w.fail(f, "parse", "", err)
+		return
+	}
+	f.Source.Tag(&ht)
+
+	// Check the transcript's quality once, not on every retry. API
+	// transcripts saved to TRANSCRIPTS_DIR were checked when posted.
+	w.mu.Lock()
+	retry := w.failures[fileID] > 0
+	w.mu.Unlock()
+	if !retry && !isRawTranscript(data) {
+		w.pipeline.RecordTranscriptReceived(ctx, &ht, revised)
+	}
+
+	// Missed and zero-length calls go on the seller's timeline unanalyzed,
+	// with or without text
+	if !revised {
+		attempt, err := w.pipeline.RecordContactAttempt(ctx, &ht)
+		if err != nil {
+			log.Printf("   ❌ Failed to record contact attempt: %v", err)
+			w.fail(f, "contact_attempt", ht.ClickToCallID, err)
+			return
+		}
+		if attempt != nil {
+			log.Printf("   📵 Contact attempt (%s), not analyzed: gluser_%s", attempt.Reason, ht.GluserID)
+			w.skip(f)
+			return
+		}
+	}
+
+	// Skip if no transcript text
+	if strings.TrimSpace(ht.Transcript) == "" {
+		log.Printf("   ⏭️ Skipping: empty transcript")
+		w.skip(f)
+		return
+	}
+
+	// Run analysis with seller context, under the deadline the pipeline sets
+	// for the transcript's length
+	started := time.Now()
+	var profile *client.SellerProfile
+	var analysis *client.AnalysisResult
+	if revised {
+		analysis, err = w.pipeline.ReanalyzeHackathonTranscript(ctx, &ht)
+	} else {
+		profile, analysis, err = w.pipeline.ProcessHackathonTranscript(ctx, &ht)
+	}
+	if err != nil {
+		w.mu.Lock()
+		w.failed++
+		w.recordAttempt(time.Now(), true)
+		w.mu.Unlock()
+		log.Printf("   ❌ %v", err)
+		w.fail(f, "analysis", ht.ClickToCallID, err)
+		return
+	}
+
+	// Mark as processed and count it towards its date
+	now := time.Now()
+	w.done(f, analysis.Checksum, now)
+	w.mu.Lock()
+	w.recordDuration(now.Sub(started))
+	w.recordAttempt(now, false)
+	w.lastProcessedAt = now
+	date, currentCount := w.countPending(analysis, now)
+	w.mu.Unlock()
+
+	switch {
+	case analysis.Blocked:
+		log.Printf("   🚫 Blocked by Gemini safety filters: gluser_%s (%s)", ht.GluserID, analysis.SafetyBlock.Reason)
+	case analysis.Provisional:
+		log.Printf("   🪫 Provisional analysis complete: gluser_%s, queued for the full analysis", ht.GluserID)
+	case profile == nil:
+		log.Printf("   ✅ Analysis version %d complete: gluser_%s", analysis.Version, ht.GluserID)
+	default:
+		log.Printf("   ✅ Analysis complete: gluser_%s (call #%d, health: %d%%)",
+			ht.GluserID, profile.TotalCalls, profile.CurrentStatus.HealthScore)
+	}
+	log.Printf("   📊 New analyses for %s since its last aggregate: %d/%d", date, currentCount, w.policy.Threshold)
+
+	// Check if we should trigger aggregation
+	if currentCount >= w.policy.Threshold {
+		w.triggerAggregation(ctx, date, client.AggregateTriggerThreshold)
+	}
+}
+
+// countPending counts a new analysis towards its date's aggregation,
+// returning the date and its count. Callers hold w.mu.
+func (w *TranscriptWatcher) countPending(analysis *client.AnalysisResult, now time.Time) (string, int) {
+	date := w.policy.dateFor(analysis, now)
+	p := w.pending[date]
+	if p == nil {
+		p = &client.PendingAggregate{Date: date}
+		w.pending[date] = p
+	}
+	p.NewAnalyses++
+	p.LastAnalysisAt = now
+	if w.policy.Debounce > 0 {
+		due := now.Add(w.policy.Debounce)
+		p.DueAt = &due
+	}
+	return date, p.NewAnalyses
+}
+
+// CountAnalysis counts an analysis made outside the watcher, e.g. of a call
+// ingested through the API, towards its date's aggregation like the
+// watcher's own. A date reaching the threshold is aggregated in the
+// background. A stopped watcher, on an instance that isn't leading, leaves
+// the date to the leader's nightly aggregation.
+func (w *TranscriptWatcher) CountAnalysis(analysis *client.AnalysisResult) {
+	if analysis == nil {
+		return
+	}
+	w.mu.Lock()
+	if !w.running {
+		w.mu.Unlock()
+		return
+	}
+	date, count := w.countPending(analysis, time.Now())
+	w.mu.Unlock()
+
+	log.Printf("   📊 New analyses for %s since its last aggregate: %d/%d", date, count, w.policy.Threshold)
+	if count >= w.policy.Threshold {
+		go w.triggerAggregation(context.Background(), date, client.AggregateTriggerThreshold)
+	}
+}
+
+// recordDuration adds a processing time to the window. Callers hold w.mu.
+func (w *TranscriptWatcher) recordDuration(d time.Duration) {
+	w.processed++
+	if len(w.durations) < config.PIPELINE_STATS_WINDOW {
+		w.durations = append(w.durations, d)
+		return
+	}
+	w.durations[w.nextDuration] = d
+	w.nextDuration = (w.nextDuration + 1) % len(w.durations)
+}
+
+// Stats reports the transcript backlog and how fast it's being worked off.
+// Transcripts are processed one at a time, so capacity is the inverse of the
+// average processing time. LLM stats are filled in by the caller.
+func (w *TranscriptWatcher) Stats() client.PipelineStats {
+	now := time.Now()
+	stats := client.PipelineStats{GeneratedAt: now}
+
+	files := Scan(w.sources)
+
+	w.mu.Lock()
+	stats.WatcherRunning = w.running
+	stats.Processed = w.processed
+	stats.Failed = w.failed
+	if !w.lastProcessedAt.IsZero() {
+		last := w.lastProcessedAt
+		stats.LastProcessedAt = &last
+	}
+	attempts, rate := w.failureRate(now)
+	stats.RecentAttempts = attempts
+	stats.RecentFailureRate = math.Round(rate*1000) / 1000
+	var total time.Duration
+	for _, d := range w.durations {
+		total += d
+	}
+	if len(w.durations) > 0 {
+		stats.AvgProcessingSeconds = roundSeconds(total.Seconds() / float64(len(w.durations)))
+	}
+	pending := make(map[string]bool, len(files))
+	for _, f := range files {
+		_, processed := w.processedFiles[f.FileID]
+		pending[f.Path] = !processed
+	}
+	w.mu.Unlock()
+
+	for f, unprocessed := range pending {
+		info, err := os.Stat(f)
+		if err != nil {
+			continue
+		}
+		mod := info.ModTime()
+		if now.Sub(mod) <= time.Hour {
+			stats.ArrivalsLastHour++
+		}
+		if !unprocessed {
+			continue
+		}
+		stats.PendingTranscripts++
+		if stats.OldestPendingAt == nil || mod.Before(*stats.OldestPendingAt) {
+			stats.OldestPendingAt = &mod
+		}
+	}
+	if stats.OldestPendingAt != nil {
+		stats.OldestPendingSeconds = roundSeconds(now.Sub(*stats.OldestPendingAt).Seconds())
+	}
+
+	// Catch-up time: the backlog over the capacity left after new arrivals
+	if stats.AvgProcessingSeconds > 0 {
+		stats.CapacityPerHour = roundSeconds(3600 / stats.AvgProcessingSeconds)
+		spare := stats.CapacityPerHour - float64(stats.ArrivalsLastHour)
+		switch {
+		case stats.PendingTranscripts == 0:
+			zero := 0.0
+			stats.CatchUpSeconds = &zero
+		case spare <= 0:
+			stats.FallingBehind = true
+		default:
+			secs := roundSeconds(float64(stats.PendingTranscripts) / spare * 3600)
+			stats.CatchUpSeconds = &secs
+		}
+	}
+	return stats
+}
+
+func roundSeconds(v float64) float64 {
+	return math.Round(v*10) / 10
+}
+
+// aggregateQuietDates aggregates dates whose debounce period has passed
+func (w *TranscriptWatcher) aggregateQuietDates(ctx context.Context) {
+	now := time.Now()
+	var due []string
+	w.mu.Lock()
+	for date, p := range w.pending {
+		if p.DueAt != nil && !now.Before(*p.DueAt) {
+			due = append(due, date)
+		}
+	}
+	w.mu.Unlock()
+
+	sort.Strings(due)
+	for _, date := range due {
+		w.triggerAggregation(ctx, date, client.AggregateTriggerDebounce)
+	}
+}
+
+// triggerAggregation runs aggregation and ticket generation for a date
+func (w *TranscriptWatcher) triggerAggregation(ctx context.Context, date, trigger string) {
+	// Reset the date's counter
+	w.mu.Lock()
+	run := &client.AggregationRun{Date: date, Trigger: trigger, StartedAt: time.Now()}
+	if p := w.pending[date]; p != nil {
+		run.NewAnalyses = p.NewAnalyses
+	}
+	delete(w.pending, date)
+	w.mu.Unlock()
+
+	log.Printf("🔔 Aggregating %s (%s, %d new analyses)...", date, trigger, run.NewAnalyses)
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	agg, err := w.pipeline.RunAggregation(ctx, date)
+	run.Duration = time.Since(run.StartedAt).Round(time.Millisecond).String()
+	if err != nil {
+		run.Error = err.Error()
+	}
+	w.mu.Lock()
+	w.lastAggregation = run
+	w.mu.Unlock()
+
+	if err != nil {
+		log.Printf("   ❌ Aggregation failed: %v", err)
+		return
+	}
+
+	log.Printf("   ✅ Aggregation complete for %s", date)
+	log.Printf("   📈 Total calls: %d, Issues: %d, Upsell opportunities: %d",
+		agg.TotalCalls, agg.TotalIssues, agg.UpsellOpportunities)
+}
@@ -146,7 +146,7 @@ function switchTab(tabId) {
 async function checkConnection() {
     const status = document.getElementById('connectionStatus');
     try {
-        const response = await fetch('/sellers');
+        const response = await fetch('/sellers?page_size=1');
         if (response.ok) {
             status.className = 'connection-status connected';
             status.innerHTML = '<span class="status-dot"></span><span>Connected to API</span>';
@@ -440,7 +440,7 @@ function displayAnalysisResult(result) {
 // ===== Load Sellers =====
 async function loadSellers() {
     try {
-        const response = await fetch('/sellers');
+        const response = await fetch('/sellers?page_size=1000');
         if (!response.ok) throw new Error('Failed to load sellers');
         
         const data = await response.json();
@@ -772,8 +772,8 @@ async function loadDashboardData() {
     console.log('Loading dashboard data...');
     
     try {
-        // Load sellers data
-        const sellersRes = await fetch('/sellers');
+        // Load seller totals and the least healthy sellers
+        const sellersRes = await fetch('/sellers?sort=health&page_size=50');
         const sellersData = await sellersRes.json();
         const sellers = sellersData.sellers || sellersData;
         const totalSellers = sellersData.total_count || sellers.length;
@@ -804,7 +804,13 @@ async function loadDashboardData() {
                 document.getElementById('totalIssues').textContent = aggregate.total_issues || 0;
                 document.getElementById('avgSatisfaction').textContent = `Avg Satisfaction: ${(aggregate.avg_satisfaction_score || 0).toFixed(1)}/5`;
                 document.getElementById('upsellOpps').textContent = `${aggregate.upsell_opportunities || 0} upsell opportunities`;
-                document.getElementById('callsTrend').textContent = `Data from ${latestDate}`;
+                let trend = `Data from ${latestDate}`;
+                const proc = aggregate.processing;
+                if (proc && proc.attempted > 0) {
+                    trend += ` · ${proc.succeeded}/${proc.attempted} analyzed`;
+                    if (proc.failed > 0) trend += `, ${proc.failed} failed`;
+                }
+                document.getElementById('callsTrend').textContent = trend;
                 
                 // Render charts with correct field names
                 renderIssueCategories(aggregate.feature_buckets || {});
@@ -1132,6 +1138,7 @@ function renderTickets(tickets) {
                     <span>🆔 ${ticket.ticket_id || 'N/A'}</span>
                     <span>📊 ${affectedCount} issues</span>
                     <span>👥 ${affectedSellers.length} sellers</span>
+                    ${ticket.impact && ticket.impact.valued_sellers > 0 ? `<span title="Of ₹${ticket.impact.plan_value.toLocaleString('en-IN')} in annual plans">💰 ₹${ticket.impact.revenue_at_risk.toLocaleString('en-IN')} at risk</span>` : ''}
                 </div>
                 <p class="ticket-description">${ticket.title || ticket.description?.substring(0, 200) || 'No summary'}</p>
                 ${affectedSellers.length > 0 ? `